@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// PurgeChunks removes the chunks holding findings from st, keeping the
+// other chunks of the same files, and returns how many chunks were removed.
+// Only the purged chunks are deleted; the others are left untouched, since
+// chunks read back from some stores lack their vectors. The documents keep
+// their hash, so the files are not re-indexed until they change. The caller
+// persists st.
+func PurgeChunks(ctx context.Context, st store.VectorStore, findings []Finding) (int, error) {
+	purge := make(map[string]map[string]bool)
+	for _, f := range findings {
+		if purge[f.File] == nil {
+			purge[f.File] = make(map[string]bool)
+		}
+		for _, id := range f.ChunkIDs {
+			purge[f.File][id] = true
+		}
+	}
+
+	removed := 0
+	for file, purged := range purge {
+		ids := make([]string, 0, len(purged))
+		for id := range purged {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		if err := st.DeleteChunks(ctx, ids); err != nil {
+			return removed, fmt.Errorf("failed to delete chunks of %s: %w", file, err)
+		}
+		removed += len(ids)
+
+		doc, err := st.GetDocument(ctx, file)
+		if err != nil {
+			return removed, fmt.Errorf("failed to read document %s: %w", file, err)
+		}
+		if doc == nil {
+			continue
+		}
+		kept := make([]string, 0, len(doc.ChunkIDs))
+		for _, id := range doc.ChunkIDs {
+			if !purged[id] {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) == len(doc.ChunkIDs) {
+			continue
+		}
+		doc.ChunkIDs = kept
+		if err := st.SaveDocument(ctx, *doc); err != nil {
+			return removed, fmt.Errorf("failed to save document %s: %w", file, err)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,192 @@
+// Package audit inspects the contents of an existing index.
+package audit
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// Finding is a probable secret in an indexed chunk.
+type Finding struct {
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Rule  string `json:"rule"`
+	Match string `json:"match"` // Redacted
+	// ChunkIDs lists every chunk holding the line, as chunks may overlap.
+	ChunkIDs []string `json:"chunk_ids"`
+}
+
+// secretRule detects one kind of credential. When the pattern has a group,
+// the first group is the secret value.
+type secretRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+var secretRules = []secretRule{
+	{"private-key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----`)},
+	{"aws-access-key-id", regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`)},
+	{"github-token", regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+	{"gitlab-token", regexp.MustCompile(`\b(glpat-[A-Za-z0-9_\-]{20,})\b`)},
+	{"slack-token", regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})\b`)},
+	{"stripe-key", regexp.MustCompile(`\b((?:sk|rk)_live_[A-Za-z0-9]{20,})\b`)},
+	{"google-api-key", regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{35})\b`)},
+	{"openai-key", regexp.MustCompile(`\b(sk-(?:proj-)?[A-Za-z0-9_\-]{32,})\b`)},
+	{"jwt", regexp.MustCompile(`\b(eyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,})\b`)},
+	{"connection-string-password", regexp.MustCompile(`\b[a-z][a-z0-9+.\-]*://[^\s:/@'"]+:([^\s@/'"]{6,})@`)},
+	{"password-assignment", regexp.MustCompile(`(?i)\b[a-z0-9_.\-]*(?:password|passwd|secret|api_?key|access_?token|auth_?token)[a-z0-9_.\-]*\s*[:=]\s*["']([^"'\s]{8,})["']`)},
+}
+
+// quotedToken finds quoted string literals examined for high entropy.
+var quotedToken = regexp.MustCompile(`["'` + "`" + `]([A-Za-z0-9+/=_\-]{20,})["'` + "`" + `]`)
+
+// placeholderWords mark example values that are not real secrets.
+var placeholderWords = []string{"example", "changeme", "placeholder", "dummy", "xxxx", "your_", "your-", "<", "${", "{{", "redacted", "test"}
+
+// Scanner finds probable secrets in chunk contents.
+type Scanner struct {
+	// MinEntropy is the Shannon entropy, in bits per character, above which
+	// a long quoted string is reported. Zero disables entropy detection.
+	MinEntropy float64
+}
+
+// DefaultMinEntropy flags random-looking base64 strings while leaving most
+// identifiers and prose alone.
+const DefaultMinEntropy = 4.5
+
+// NewScanner returns a scanner with entropy detection at DefaultMinEntropy.
+func NewScanner() *Scanner {
+	return &Scanner{MinEntropy: DefaultMinEntropy}
+}
+
+// ScanChunks scans chunks and returns findings sorted by file and line.
+// Findings repeated by overlapping chunks are reported once.
+func (s *Scanner) ScanChunks(chunks []store.Chunk) []Finding {
+	type location struct {
+		file string
+		line int
+	}
+	index := make(map[location]int)
+	var findings []Finding
+	for _, chunk := range chunks {
+		for _, f := range s.ScanChunk(chunk) {
+			loc := location{f.File, f.Line}
+			if i, ok := index[loc]; ok {
+				findings[i].ChunkIDs = append(findings[i].ChunkIDs, chunk.ID)
+				continue
+			}
+			index[loc] = len(findings)
+			findings = append(findings, f)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// ScanChunk returns the probable secrets in chunk, at most one per line.
+func (s *Scanner) ScanChunk(chunk store.Chunk) []Finding {
+	var findings []Finding
+	for i, line := range strings.Split(chunk.Content, "\n") {
+		rule, value, ok := s.scanLine(line)
+		if !ok {
+			continue
+		}
+		findings = append(findings, Finding{
+			File:     chunk.FilePath,
+			Line:     chunk.StartLine + i,
+			Rule:     rule,
+			Match:    Redact(value),
+			ChunkIDs: []string{chunk.ID},
+		})
+	}
+	return findings
+}
+
+func (s *Scanner) scanLine(line string) (rule, value string, ok bool) {
+	for _, r := range secretRules {
+		m := r.pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value = m[0]
+		if len(m) > 1 {
+			value = m[1]
+		}
+		if isPlaceholder(value) {
+			continue
+		}
+		return r.name, value, true
+	}
+	if s.MinEntropy > 0 {
+		for _, m := range quotedToken.FindAllStringSubmatch(line, -1) {
+			if !isPlaceholder(m[1]) && looksRandom(m[1]) && ShannonEntropy(m[1]) >= s.MinEntropy {
+				return "high-entropy-string", m[1], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, word := range placeholderWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksRandom rejects identifiers and paths: a random token mixes letter
+// cases and digits.
+func looksRandom(value string) bool {
+	var upper, lower, digit bool
+	for _, r := range value {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// ShannonEntropy returns the entropy of value in bits per character.
+func ShannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Redact keeps the first four characters of a secret, so findings can be
+// told apart without printing the secret itself.
+func Redact(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:4] + strings.Repeat("*", min(len(value)-4, 12))
+}
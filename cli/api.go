@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+var (
+	apiRoutesMethod string
+	apiRoutesPath   string
+)
+
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Inspect the API surface of the codebase",
+}
+
+var apiRoutesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "List the HTTP endpoints declared in the code",
+	Long: `List the HTTP endpoints recorded in the symbol index, with the file and
+line declaring them and their handler.
+
+Routes are found in:
+  Go          net/http (including "GET /path" patterns), gin, echo, chi, fiber
+  JS/TS       express-style routers: app.get('/path', handler)
+  Python      FastAPI and Flask decorators
+  Java/Kotlin Spring @GetMapping, @PostMapping, @RequestMapping, with the
+              controller's class-level mapping as prefix
+
+Routes that serve every method are listed as ANY. In workspace mode, routes
+from every selected project are listed.
+
+Symbol indexes built before routes were recorded have none; the next
+'grepai watch' extracts every file again to add them.
+
+Examples:
+  grepai api routes
+  grepai api routes --method POST
+  grepai api routes --path /users --json`,
+	Args: cobra.NoArgs,
+	RunE: runAPIRoutes,
+}
+
+func init() {
+	apiRoutesCmd.Flags().BoolVar(&traceJSON, "json", false, "Output results in JSON format")
+	apiRoutesCmd.Flags().BoolVarP(&traceTOON, "toon", "t", false, "Output results in TOON format (token-efficient for AI agents)")
+	apiRoutesCmd.MarkFlagsMutuallyExclusive("json", "toon")
+	apiRoutesCmd.Flags().StringVar(&traceWorkspace, "workspace", "", "Workspace name for cross-project routes")
+	apiRoutesCmd.Flags().StringVar(&traceProject, "project", "", "Project name within workspace (requires --workspace)")
+	apiRoutesCmd.Flags().StringVar(&apiRoutesMethod, "method", "", "Only list routes of this HTTP method, such as GET or ANY")
+	apiRoutesCmd.Flags().StringVar(&apiRoutesPath, "path", "", "Only list routes whose path contains this text")
+	apiRoutesCmd.Flags().IntVar(&traceLimit, "limit", 0, "Maximum number of routes to return (0 = no limit)")
+	apiRoutesCmd.Flags().IntVar(&traceOffset, "offset", 0, "Number of routes to skip")
+
+	apiCmd.AddCommand(apiRoutesCmd)
+	rootCmd.AddCommand(apiCmd)
+}
+
+func runAPIRoutes(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	stores, projectRoot, err := loadTraceStores(ctx)
+	if err != nil {
+		return err
+	}
+	defer trace.CloseSymbolStores(stores)
+
+	result, err := trace.FindRoutes(ctx, stores, apiRoutesMethod, apiRoutesPath, trace.PageOptions{Limit: traceLimit, Offset: traceOffset})
+	if err != nil {
+		return fmt.Errorf("failed to find routes: %w", err)
+	}
+
+	if traceJSON || traceTOON {
+		return outputTraceValue(result)
+	}
+
+	return displayRoutesResult(result, projectRoot)
+}
+
+func displayRoutesResult(result *trace.RoutesResult, projectRoot string) error {
+	fmt.Printf("Routes (%d)", result.Total)
+	if len(result.Counts) > 0 {
+		methods := make([]string, 0, len(result.Counts))
+		for method := range result.Counts {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		parts := make([]string, len(methods))
+		for i, method := range methods {
+			parts[i] = fmt.Sprintf("%s: %d", method, result.Counts[method])
+		}
+		fmt.Printf(" [%s]", strings.Join(parts, ", "))
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", 60))
+
+	if result.Total == 0 {
+		fmt.Println("No routes found.")
+		return nil
+	}
+
+	for i, route := range result.Routes {
+		file := route.File
+		if projectRoot != "" {
+			if rel, err := filepath.Rel(projectRoot, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+		if route.Project != "" {
+			file = "[" + route.Project + "] " + file
+		}
+		fmt.Printf("%d. %-7s %s\n", result.Offset+i+1, route.Method, route.Path)
+		fmt.Printf("   %s:%d", file, route.Line)
+		if route.Handler != "" {
+			fmt.Printf(" -> %s", route.Handler)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
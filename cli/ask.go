@@ -0,0 +1,381 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+const (
+	// askMaxTokens is the length limit of answers.
+	askMaxTokens = 1024
+	// askMaxSymbols is how many symbols mentioned in a question get trace
+	// context.
+	askMaxSymbols = 3
+	// askMaxRefs is how many callers and callees are listed per symbol.
+	askMaxRefs = 5
+)
+
+var (
+	askLimit   int
+	askPath    string
+	askNoTrace bool
+	askJSON    bool
+	askTimeout time.Duration
+)
+
+// askSource is a chunk retrieved for a question, numbered as in the prompt.
+type askSource struct {
+	Index     int     `json:"index"`
+	File      string  `json:"file"`
+	StartLine int     `json:"start_line"`
+	EndLine   int     `json:"end_line"`
+	Score     float32 `json:"score"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Content   string  `json:"-"`
+}
+
+// askSymbol is the trace context of a symbol mentioned in a question.
+type askSymbol struct {
+	Symbol  trace.Symbol `json:"symbol"`
+	Callers []string     `json:"callers,omitempty"` // "name (file:line)"
+	Callees []string     `json:"callees,omitempty"` // "name (file:line)"
+}
+
+// askCitation is a file:line reference of an answer to a file given to the
+// LLM.
+type askCitation struct {
+	File      string `json:"file"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// askResult is the output of 'grepai ask'.
+type askResult struct {
+	Question  string        `json:"question"`
+	Answer    string        `json:"answer"`
+	Citations []askCitation `json:"citations"`
+	Sources   []askSource   `json:"sources"`
+	Symbols   []askSymbol   `json:"symbols,omitempty"`
+}
+
+var askCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Answer a question about the codebase with an LLM",
+	Long: `Answer a question about the codebase from its most relevant code.
+
+The question is searched in the index like 'grepai search'. The best chunks,
+with the definition, callers and callees of the symbols the question names,
+are sent to the LLM set by rpg.llm_endpoint and rpg.llm_model, which answers
+citing file:line references. The chunks it was given are listed after the
+answer.
+
+Examples:
+  grepai ask "how does session refresh work?"
+  grepai ask "where is RefreshToken retried?" --limit 12
+  grepai ask "how are webhooks signed?" --path internal/ --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAsk,
+}
+
+func init() {
+	askCmd.Flags().IntVarP(&askLimit, "limit", "n", 8, "Number of chunks given to the LLM")
+	askCmd.Flags().StringVar(&askPath, "path", "", "Path prefix to filter the chunks")
+	askCmd.Flags().BoolVar(&askNoTrace, "no-trace", false, "Leave out the callers and callees of the symbols the question names")
+	askCmd.Flags().BoolVar(&askJSON, "json", false, "Output the answer in JSON format")
+	askCmd.Flags().DurationVar(&askTimeout, "timeout", 2*time.Minute, "Time limit of the LLM request")
+	rootCmd.AddCommand(askCmd)
+}
+
+func runAsk(cmd *cobra.Command, args []string) error {
+	question := args[0]
+	ctx := context.Background()
+
+	if askLimit <= 0 {
+		return fmt.Errorf("limit must be > 0")
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.RPG.LLMEndpoint == "" || cfg.RPG.LLMModel == "" {
+		return fmt.Errorf("grepai ask needs an LLM: set rpg.llm_endpoint and rpg.llm_model in .grepai/config.yaml")
+	}
+	if err := config.CheckProjectIndexMetadata(projectRoot, cfg); err != nil {
+		return err
+	}
+	pathPrefix, err := search.NormalizeProjectPathPrefix(askPath, projectRoot)
+	if err != nil {
+		return fmt.Errorf("invalid --path value: %w", err)
+	}
+
+	emb, err := embedder.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	defer emb.Close()
+	docsEmb, err := embedder.NewDocsFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize docs embedder: %w", err)
+	}
+	if docsEmb != nil {
+		defer docsEmb.Close()
+	}
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	searcher := search.NewProjectSearcher(st, emb, docsEmb, cfg)
+	symbols := loadSearchSymbols(ctx, projectRoot)
+	if symbols != nil {
+		searcher.SetSymbolStore(symbols)
+	}
+	searcher.SetConfidenceObserver(search.NewMissObserver(projectRoot, cfg.Insights))
+	results, err := searcher.Search(ctx, question, askLimit, pathPrefix)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no indexed code matches the question: check that 'grepai watch' has indexed the project")
+	}
+
+	result := askResult{Question: question, Sources: askSources(results)}
+	if !askNoTrace && symbols != nil {
+		result.Symbols = askSymbolContext(ctx, symbols, mentionedSymbols(question))
+	}
+
+	llm := rpg.NewLLMExtractor(rpg.LLMExtractorConfig{
+		Provider: cfg.RPG.LLMProvider,
+		Model:    cfg.RPG.LLMModel,
+		Endpoint: cfg.RPG.LLMEndpoint,
+		APIKey:   cfg.RPG.LLMAPIKey,
+		Timeout:  askTimeout,
+	})
+	result.Answer, err = llm.Complete(ctx, askSystemPrompt, buildAskPrompt(question, result.Sources, result.Symbols), askMaxTokens)
+	if err != nil {
+		return fmt.Errorf("failed to get an answer from %s: %w", cfg.RPG.LLMModel, err)
+	}
+	result.Citations = extractCitations(result.Answer, result.Sources, result.Symbols)
+
+	if askJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	fmt.Print(renderAskResult(result))
+	return nil
+}
+
+const askSystemPrompt = "You are a senior engineer answering questions about a codebase. Answer only from the provided code, which is numbered by line. " +
+	"Cite the code supporting each statement as path:line or path:start-end, using the paths and line numbers shown. " +
+	"If the code does not answer the question, say so. Be concise."
+
+// askSources converts search results to numbered sources.
+func askSources(results []store.SearchResult) []askSource {
+	sources := make([]askSource, len(results))
+	for i, r := range results {
+		sources[i] = askSource{
+			Index:     i + 1,
+			File:      r.Chunk.FilePath,
+			StartLine: r.Chunk.StartLine,
+			EndLine:   r.Chunk.EndLine,
+			Score:     r.Score,
+			Symbol:    r.SymbolName,
+			Content:   r.Chunk.Content,
+		}
+	}
+	return sources
+}
+
+// identifierPattern matches identifiers, possibly qualified or called.
+var identifierPattern = regexp.MustCompile("`?[A-Za-z_][A-Za-z0-9_]*(?:\\.[A-Za-z_][A-Za-z0-9_]*)*(?:\\(\\))?`?")
+
+// mentionedSymbols returns the words of question that look like code
+// identifiers rather than prose: quoted in backticks, called, qualified, in
+// snake_case or in camelCase. Qualified names are reduced to their last
+// part, which is how symbols are indexed.
+func mentionedSymbols(question string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, word := range identifierPattern.FindAllString(question, -1) {
+		quoted := strings.HasPrefix(word, "`") && strings.HasSuffix(word, "`") && len(word) > 1
+		word = strings.Trim(word, "`")
+		called := strings.HasSuffix(word, "()")
+		word = strings.TrimSuffix(word, "()")
+		qualified := strings.Contains(word, ".")
+		if i := strings.LastIndex(word, "."); i >= 0 {
+			word = word[i+1:]
+		}
+		codeLike := quoted || called || qualified || strings.Contains(strings.Trim(word, "_"), "_") ||
+			strings.ToLower(word[1:]) != word[1:]
+		if !codeLike || seen[word] {
+			continue
+		}
+		seen[word] = true
+		names = append(names, word)
+	}
+	return names
+}
+
+// askSymbolContext returns the definition, callers and callees of the first
+// askMaxSymbols names of symbols that are defined in the project.
+func askSymbolContext(ctx context.Context, symbols trace.SymbolStore, names []string) []askSymbol {
+	var found []askSymbol
+	for _, name := range names {
+		if len(found) == askMaxSymbols {
+			break
+		}
+		defs, err := symbols.LookupSymbol(ctx, name)
+		if err != nil || len(defs) == 0 {
+			continue
+		}
+		s := askSymbol{Symbol: defs[0]}
+		s.Callers, s.Callees = symbolNeighbors(ctx, symbols, defs[0])
+		found = append(found, s)
+	}
+	return found
+}
+
+// symbolNeighbors returns the first askMaxRefs callers and callees of sym,
+// as "name (file:line)".
+func symbolNeighbors(ctx context.Context, symbols trace.SymbolStore, sym trace.Symbol) (callers, callees []string) {
+	if refs, err := symbols.LookupCallers(ctx, sym.Name); err == nil {
+		for _, ref := range refs[:min(askMaxRefs, len(refs))] {
+			callers = append(callers, fmt.Sprintf("%s (%s:%d)", ref.CallerName, ref.File, ref.Line))
+		}
+	}
+	if refs, err := symbols.LookupCallees(ctx, sym.Name, sym.File); err == nil {
+		for _, ref := range refs[:min(askMaxRefs, len(refs))] {
+			callees = append(callees, fmt.Sprintf("%s (%s:%d)", ref.SymbolName, ref.File, ref.Line))
+		}
+	}
+	return callers, callees
+}
+
+// buildAskPrompt returns the user prompt of question, listing sources with
+// line numbers and the trace context of symbols.
+func buildAskPrompt(question string, sources []askSource, symbols []askSymbol) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Question: %s\n\n", question)
+	for _, s := range sources {
+		fmt.Fprintf(&sb, "[%d] %s:%d-%d\n", s.Index, s.File, s.StartLine, s.EndLine)
+		sb.WriteString(numberLines(s.Content, s.StartLine))
+		sb.WriteString("\n")
+	}
+	for _, s := range symbols {
+		fmt.Fprintf(&sb, "Symbol %s defined at %s:%d", s.Symbol.Name, s.Symbol.File, s.Symbol.Line)
+		if s.Symbol.Signature != "" {
+			fmt.Fprintf(&sb, ": %s", s.Symbol.Signature)
+		}
+		sb.WriteString("\n")
+		if len(s.Callers) > 0 {
+			fmt.Fprintf(&sb, "  Called by: %s\n", strings.Join(s.Callers, ", "))
+		}
+		if len(s.Callees) > 0 {
+			fmt.Fprintf(&sb, "  Calls: %s\n", strings.Join(s.Callees, ", "))
+		}
+	}
+	if len(symbols) > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Answer the question, citing path:line references.")
+	return sb.String()
+}
+
+// numberLines prefixes the lines of chunk content, without its file header,
+// with their line numbers from startLine.
+func numberLines(content string, startLine int) string {
+	var sb strings.Builder
+	for i, line := range strings.Split(strings.TrimRight(chunkBody(content), "\n"), "\n") {
+		fmt.Fprintf(&sb, "%d: %s\n", startLine+i, line)
+	}
+	return sb.String()
+}
+
+// citationPattern matches path:line and path:start-end references.
+var citationPattern = regexp.MustCompile(`([\w./-]+):(\d+)(?:-(\d+))?`)
+
+// extractCitations returns the file:line references of answer to files of
+// sources or symbols, in order of appearance. References to other files,
+// which the LLM was not given, are left out.
+func extractCitations(answer string, sources []askSource, symbols []askSymbol) []askCitation {
+	known := make(map[string]bool)
+	for _, s := range sources {
+		known[s.File] = true
+	}
+	for _, s := range symbols {
+		known[s.Symbol.File] = true
+	}
+
+	citations := []askCitation{}
+	seen := make(map[askCitation]bool)
+	for _, m := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		file := strings.TrimPrefix(m[1], "./")
+		if !known[file] {
+			continue
+		}
+		start, _ := strconv.Atoi(m[2])
+		end := start
+		if m[3] != "" {
+			end, _ = strconv.Atoi(m[3])
+		}
+		c := askCitation{File: file, StartLine: start, EndLine: max(start, end)}
+		if !seen[c] {
+			seen[c] = true
+			citations = append(citations, c)
+		}
+	}
+	return citations
+}
+
+// renderAskResult renders an answer followed by its sources, marking the
+// sources it cites.
+func renderAskResult(result askResult) string {
+	cited := make(map[int]bool)
+	for _, c := range result.Citations {
+		for _, s := range result.Sources {
+			if c.File == s.File && c.StartLine <= s.EndLine && c.EndLine >= s.StartLine {
+				cited[s.Index] = true
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(result.Answer))
+	sb.WriteString("\n\nSources:\n")
+	for _, s := range result.Sources {
+		mark := " "
+		if cited[s.Index] {
+			mark = "*"
+		}
+		fmt.Fprintf(&sb, " %s[%d] %s:%d-%d (score: %.4f)", mark, s.Index, s.File, s.StartLine, s.EndLine, s.Score)
+		if s.Symbol != "" {
+			fmt.Fprintf(&sb, " %s", s.Symbol)
+		}
+		sb.WriteString("\n")
+	}
+	if len(cited) > 0 {
+		sb.WriteString("\n* cited in the answer\n")
+	}
+	return sb.String()
+}
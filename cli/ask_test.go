@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func TestMentionedSymbols(t *testing.T) {
+	got := mentionedSymbols("How does `refresh` call auth.RefreshToken and load_config()? Why is HTTP slow. refresh")
+	want := []string{"refresh", "RefreshToken", "load_config", "HTTP"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mentionedSymbols() = %v, want %v", got, want)
+	}
+	if got := mentionedSymbols("how does session refresh work?"); len(got) != 0 {
+		t.Errorf("expected no symbols in a prose question, got %v", got)
+	}
+}
+
+func TestBuildAskPrompt(t *testing.T) {
+	sources := []askSource{{
+		Index:     1,
+		File:      "auth/session.go",
+		StartLine: 10,
+		EndLine:   11,
+		Content:   "File: auth/session.go\n\nfunc Refresh() {\n}\n",
+	}}
+	symbols := []askSymbol{{
+		Symbol:  trace.Symbol{Name: "Refresh", File: "auth/session.go", Line: 10, Signature: "func Refresh()"},
+		Callers: []string{"Middleware (api/mw.go:42)"},
+	}}
+	prompt := buildAskPrompt("how does Refresh work?", sources, symbols)
+	for _, want := range []string{
+		"Question: how does Refresh work?\n",
+		"[1] auth/session.go:10-11\n10: func Refresh() {\n11: }\n",
+		"Symbol Refresh defined at auth/session.go:10: func Refresh()\n  Called by: Middleware (api/mw.go:42)\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "File: auth/session.go") {
+		t.Errorf("expected the chunk header to be left out, got:\n%s", prompt)
+	}
+}
+
+func TestExtractCitations(t *testing.T) {
+	sources := []askSource{{Index: 1, File: "auth/session.go", StartLine: 10, EndLine: 30}}
+	symbols := []askSymbol{{Symbol: trace.Symbol{Name: "Middleware", File: "api/mw.go", Line: 42}}}
+	answer := "Sessions refresh in auth/session.go:12-18 (see ./auth/session.go:12-18), called from api/mw.go:42. See also vendor/x.go:3."
+
+	got := extractCitations(answer, sources, symbols)
+	want := []askCitation{
+		{File: "auth/session.go", StartLine: 12, EndLine: 18},
+		{File: "api/mw.go", StartLine: 42, EndLine: 42},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractCitations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRenderAskResult(t *testing.T) {
+	out := renderAskResult(askResult{
+		Answer: "  Sessions refresh in auth/session.go:12.\n",
+		Citations: []askCitation{
+			{File: "auth/session.go", StartLine: 12, EndLine: 12},
+		},
+		Sources: []askSource{
+			{Index: 1, File: "auth/session.go", StartLine: 10, EndLine: 30, Score: 0.82, Symbol: "Refresh"},
+			{Index: 2, File: "auth/token.go", StartLine: 1, EndLine: 20, Score: 0.61},
+		},
+	})
+	for _, want := range []string{
+		"Sessions refresh in auth/session.go:12.\n\nSources:\n",
+		" *[1] auth/session.go:10-30 (score: 0.8200) Refresh\n",
+		"  [2] auth/token.go:1-20 (score: 0.6100)\n",
+		"* cited in the answer",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/audit"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+var (
+	auditJSON      bool
+	auditPurge     bool
+	auditNoEntropy bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the contents of the index",
+}
+
+var auditSecretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Report credentials stored in the index",
+	Long: `Scan the indexed chunks for credentials and report where they are.
+
+Detects private keys, cloud and SaaS tokens (AWS, GitHub, GitLab, Slack,
+Stripe, Google, OpenAI), JWTs, passwords in connection strings and
+assignments, and random-looking string literals. Matches are redacted in the
+output.
+
+With --purge, removes the chunks holding findings from the index. Other
+chunks of the same files are kept. A purged file is indexed again when it
+changes, so add it to ignore patterns to keep it out for good. Stop the
+watcher before purging a GOB index, or it will write the chunks back.
+
+Exits with status 1 when secrets are found and not purged.
+
+Examples:
+  grepai audit secrets
+  grepai audit secrets --json
+  grepai audit secrets --purge`,
+	RunE: runAuditSecrets,
+}
+
+func init() {
+	auditSecretsCmd.Flags().BoolVar(&auditJSON, "json", false, "Output findings in JSON format")
+	auditSecretsCmd.Flags().BoolVar(&auditPurge, "purge", false, "Remove the chunks holding findings from the index")
+	auditSecretsCmd.Flags().BoolVar(&auditNoEntropy, "no-entropy", false, "Only report known credential formats, not random-looking strings")
+	auditCmd.AddCommand(auditSecretsCmd)
+	rootCmd.AddCommand(auditCmd)
+}
+
+func runAuditSecrets(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if auditPurge && cfg.Store.Backend == "gob" && resolveWatcherRuntimeStatus(projectRoot).running {
+		return errors.New("the watcher is running and would restore purged chunks; stop it with: grepai watch --stop")
+	}
+
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	chunks, err := st.GetAllChunks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read chunks: %w", err)
+	}
+	scanner := audit.NewScanner()
+	if auditNoEntropy {
+		scanner.MinEntropy = 0
+	}
+	findings := scanner.ScanChunks(chunks)
+
+	purged := 0
+	if auditPurge && len(findings) > 0 {
+		purged, err = audit.PurgeChunks(ctx, st, findings)
+		if err != nil {
+			return err
+		}
+		if err := st.Persist(ctx); err != nil {
+			return fmt.Errorf("failed to save index: %w", err)
+		}
+	}
+
+	if auditJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if findings == nil {
+			findings = []audit.Finding{}
+		}
+		if err := enc.Encode(findings); err != nil {
+			return err
+		}
+	} else {
+		printAuditFindings(findings, len(chunks), purged)
+	}
+
+	if len(findings) > 0 && !auditPurge {
+		return fmt.Errorf("%d secrets found", len(findings))
+	}
+	return nil
+}
+
+func printAuditFindings(findings []audit.Finding, scanned, purged int) {
+	if len(findings) == 0 {
+		fmt.Printf("No secrets found in %d chunks\n", scanned)
+		return
+	}
+	for _, f := range findings {
+		fmt.Printf("%s:%d  %s  %s\n", f.File, f.Line, f.Rule, f.Match)
+	}
+	fmt.Printf("\n%d secrets found in %d chunks\n", len(findings), scanned)
+	if purged > 0 {
+		fmt.Printf("Purged %d chunks from the index\n", purged)
+	} else {
+		fmt.Println("Run with --purge to remove them from the index")
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+var (
+	cleanWorktrees bool
+	cleanDryRun    bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove leftover grepai data",
+	Long: `Remove grepai data that is no longer used.
+
+With --worktrees, deletes the .grepai directories left behind by linked
+worktrees that the watcher saw removed from the repository, regardless of
+watch.worktree_retention. Worktrees that have been linked again are kept.
+
+Examples:
+  grepai clean --worktrees
+  grepai clean --worktrees --dry-run`,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().BoolVar(&cleanWorktrees, "worktrees", false, "Remove the indexes of removed linked worktrees")
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "List what would be removed without removing it")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	if !cleanWorktrees {
+		return errors.New("nothing to clean: use --worktrees")
+	}
+
+	projectRoot, gitInfo, err := config.FindProjectRootWithGit()
+	if err != nil {
+		return err
+	}
+	if gitInfo == nil {
+		return errors.New("not in a git repository")
+	}
+	mainRoot := projectRoot
+	if gitInfo.IsWorktree {
+		mainRoot = gitInfo.MainWorktree
+	}
+
+	purged, err := purgeRemovedWorktrees(mainRoot, time.Now(), cleanDryRun)
+	verb := "Removed"
+	if cleanDryRun {
+		verb = "Would remove"
+	}
+	for _, dir := range purged {
+		fmt.Printf("%s %s\n", verb, dir)
+	}
+	if err != nil {
+		return err
+	}
+	if len(purged) == 0 {
+		fmt.Println("No leftover worktree indexes")
+	}
+	return nil
+}
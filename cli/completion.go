@@ -105,6 +105,9 @@ func registerCompletions() {
 			"gob\tLocal file-based storage",
 			"postgres\tPostgreSQL with pgvector",
 			"qdrant\tQdrant vector database",
+			"weaviate\tWeaviate vector database",
+			"redis\tRedis Stack with RediSearch",
+			"opensearch\tOpenSearch with server-side hybrid search",
 		}, cobra.ShellCompDirectiveNoFileComp
 	})
 
@@ -113,6 +116,9 @@ func registerCompletions() {
 		return []string{
 			"postgres\tPostgreSQL with pgvector",
 			"qdrant\tQdrant vector database",
+			"weaviate\tWeaviate vector database",
+			"redis\tRedis Stack with RediSearch",
+			"opensearch\tOpenSearch with server-side hybrid search",
 		}, cobra.ShellCompDirectiveNoFileComp
 	})
 	_ = workspaceCreateCmd.RegisterFlagCompletionFunc("provider", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/framework"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/stats"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+var (
+	costReportJSON     bool
+	costReportMaxDepth int
+)
+
+// projectSpend is the spend of one project found by 'grepai cost report'.
+type projectSpend struct {
+	Path    string             `json:"path"`
+	Tokens  int64              `json:"tokens"`
+	CostUSD float64            `json:"cost_usd"`
+	Months  []stats.MonthSpend `json:"months"`
+	Error   string             `json:"error,omitempty"`
+}
+
+var costCmd = &cobra.Command{
+	Use:   "cost",
+	Short: "Report the estimated spend on paid embedding providers",
+	Long: `Commands for the spend recorded by watchers of projects embedding with a
+paid provider (openai, openrouter, synthetic).`,
+}
+
+var costReportCmd = &cobra.Command{
+	Use:   "report [dir]",
+	Short: "Summarize embedding spend per project and per month",
+	Long: `Summarize the embedding spend recorded in .grepai/cost.json by the projects
+found under a directory, the current directory by default.
+
+Tokens are estimated from the text sent to the provider (about 4 characters
+per token) and priced with embedder.price_per_million_tokens, or the list
+price of known OpenAI models. Query embeddings are not counted.
+
+Examples:
+  grepai cost report
+  grepai cost report ~/src --max-depth 2
+  grepai cost report --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCostReport,
+}
+
+func init() {
+	costReportCmd.Flags().BoolVar(&costReportJSON, "json", false, "Output spend in JSON format")
+	costReportCmd.Flags().IntVar(&costReportMaxDepth, "max-depth", 4, "Directory levels below dir searched for projects")
+
+	costCmd.AddCommand(costReportCmd)
+	rootCmd.AddCommand(costCmd)
+}
+
+func runCostReport(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	roots, err := findProjectRoots(root, costReportMaxDepth)
+	if err != nil {
+		return err
+	}
+	projects := make([]projectSpend, 0, len(roots))
+	for _, projectRoot := range roots {
+		projects = append(projects, readProjectSpend(projectRoot))
+	}
+
+	if costReportJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(projects)
+	}
+	if len(projects) == 0 {
+		fmt.Printf("No grepai projects found under %s\n", root)
+		return nil
+	}
+	fmt.Print(renderCostReport(root, projects))
+	return nil
+}
+
+func readProjectSpend(projectRoot string) projectSpend {
+	p := projectSpend{Path: projectRoot, Months: []stats.MonthSpend{}}
+	spend, err := stats.ReadSpend(stats.CostPath(projectRoot))
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	p.Months = stats.SummarizeSpendByMonth(spend)
+	for _, m := range p.Months {
+		p.Tokens += m.Tokens
+		p.CostUSD += m.CostUSD
+	}
+	return p
+}
+
+// renderCostReport renders the spend of each project, then the spend of all
+// projects per month.
+func renderCostReport(root string, projects []projectSpend) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-40s %14s %10s\n", "PROJECT", "TOKENS", "COST"))
+	var tokens int64
+	var cost float64
+	months := make(map[string]*stats.MonthSpend)
+	for _, p := range projects {
+		name := p.Path
+		if rel, err := filepath.Rel(root, p.Path); err == nil {
+			name = rel
+		}
+		if p.Error != "" {
+			sb.WriteString(fmt.Sprintf("%-40s error: %s\n", truncatePath(name, 40), p.Error))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%-40s %14d %10s\n", truncatePath(name, 40), p.Tokens, formatUSD(p.CostUSD)))
+		tokens += p.Tokens
+		cost += p.CostUSD
+		for _, m := range p.Months {
+			total, ok := months[m.Month]
+			if !ok {
+				total = &stats.MonthSpend{Month: m.Month}
+				months[m.Month] = total
+			}
+			total.Tokens += m.Tokens
+			total.CostUSD += m.CostUSD
+		}
+	}
+	sb.WriteString(fmt.Sprintf("%-40s %14d %10s\n", "TOTAL", tokens, formatUSD(cost)))
+
+	if len(months) > 0 {
+		names := make([]string, 0, len(months))
+		for month := range months {
+			names = append(names, month)
+		}
+		sort.Strings(names)
+		sb.WriteString(fmt.Sprintf("\n%-40s %14s %10s\n", "MONTH", "TOKENS", "COST"))
+		for _, month := range names {
+			m := months[month]
+			sb.WriteString(fmt.Sprintf("%-40s %14d %10s\n", m.Month, m.Tokens, formatUSD(m.CostUSD)))
+		}
+	}
+	return sb.String()
+}
+
+// formatUSD formats an amount of dollars, keeping the precision of the
+// small amounts embedding costs usually are.
+func formatUSD(amount float64) string {
+	if amount < 1 {
+		return fmt.Sprintf("$%.4f", amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// costTracker estimates the spend of a watch session or an indexing run on
+// a paid embedder and records it in the project's spend file. A nil *costTracker tracks nothing,
+// which is how free providers are handled.
+type costTracker struct {
+	usage       embedder.TokenUsage
+	recorder    *stats.CostRecorder // nil when the embedder is free
+	docs        *costTracker        // tracks a paid docs embedder
+	projectRoot string
+
+	mu      sync.Mutex
+	pending int64 // tokens not yet recorded
+}
+
+// newCostTracker returns a tracker of the spend of projectRoot on cfg's
+// embedders, or nil when they are free.
+func newCostTracker(projectRoot string, cfg *config.Config) *costTracker {
+	c := newEmbedderCostTracker(projectRoot, cfg.Embedder)
+	if cfg.Embedder.Docs == nil {
+		return c
+	}
+	if docs := newEmbedderCostTracker(projectRoot, *cfg.Embedder.Docs); docs != nil {
+		if c == nil {
+			c = &costTracker{projectRoot: projectRoot}
+		}
+		c.docs = docs
+	}
+	return c
+}
+
+// newEmbedderCostTracker returns a tracker of the spend on emb, or nil when
+// it is free.
+func newEmbedderCostTracker(projectRoot string, emb config.EmbedderConfig) *costTracker {
+	price, ok := emb.Price()
+	recorder := stats.NewCostRecorder(projectRoot, emb.Provider, emb.Model, price)
+	if recorder == nil {
+		return nil
+	}
+	if !ok {
+		log.Printf("No price known for embedding model %s of %s: set price_per_million_tokens to estimate its cost", emb.Model, emb.Provider)
+	}
+	return &costTracker{recorder: recorder, projectRoot: projectRoot}
+}
+
+// newIndexer returns an indexer of projectRoot embedding with emb and, when
+// docsEmb is not nil, with docsEmb for documentation, whose tokens are
+// counted by c. Every command indexing with cfg's embedders builds its
+// indexer here, so that no paid embedding goes unrecorded.
+func (c *costTracker) newIndexer(projectRoot string, cfg *config.Config, st store.VectorStore, emb, docsEmb embedder.Embedder, chunker *indexer.Chunker, scanner *indexer.Scanner, lastIndexTime time.Time, processors ...*framework.ProcessorRegistry) *indexer.Indexer {
+	idx := indexer.NewIndexer(projectRoot, st, c.wrap(emb), chunker, scanner, lastIndexTime, processors...)
+	if docsEmb != nil && cfg.Embedder.Docs != nil {
+		idx.SetDocsEmbedder(c.wrapDocs(docsEmb), cfg.Embedder.Docs.Model)
+	}
+	return idx
+}
+
+// wrap returns emb counting the tokens it is sent.
+func (c *costTracker) wrap(emb embedder.Embedder) embedder.Embedder {
+	if c == nil || c.recorder == nil {
+		return emb
+	}
+	return embedder.WithTokenUsage(emb, &c.usage)
+}
+
+// wrapDocs returns the docs embedder emb counting the tokens it is sent.
+func (c *costTracker) wrapDocs(emb embedder.Embedder) embedder.Embedder {
+	if c == nil {
+		return emb
+	}
+	return c.docs.wrap(emb)
+}
+
+// take returns the tokens sent since the last call and their cost, and adds
+// them to the tokens to record.
+func (c *costTracker) take() (int64, float64) {
+	if c == nil {
+		return 0, 0
+	}
+	tokens := c.usage.Take()
+	c.mu.Lock()
+	c.pending += tokens
+	c.mu.Unlock()
+	cost := c.recorder.Cost(tokens)
+	if docsTokens, docsCost := c.docs.take(); docsTokens > 0 {
+		tokens += docsTokens
+		cost += docsCost
+	}
+	return tokens, cost
+}
+
+// emit reports the tokens sent since the last call to onStats.
+func (c *costTracker) emit(onStats watchStatsObserver) {
+	tokens, cost := c.take()
+	if tokens > 0 && onStats != nil {
+		onStats(c.projectRoot, watchStatsDelta{EmbedTokens: tokens, CostUSD: cost})
+	}
+}
+
+// flush records the tokens sent and not yet recorded as watch spend.
+func (c *costTracker) flush() {
+	c.take()
+	c.record("watch")
+}
+
+// scanStarted records the tokens taken before a full scan as watch spend,
+// so that the tokens taken when it ends are those of the scan.
+func (c *costTracker) scanStarted() {
+	c.record("watch")
+}
+
+// scanComplete logs, reports and records the spend of the full scan that
+// just ended.
+func (c *costTracker) scanComplete(onStats watchStatsObserver) {
+	if c == nil {
+		return
+	}
+	tokens, cost := c.take()
+	if tokens > 0 {
+		log.Printf("Scan of %s sent ~%d tokens to paid embedders (~%s)", c.projectRoot, tokens, formatUSD(cost))
+		if onStats != nil {
+			onStats(c.projectRoot, watchStatsDelta{EmbedTokens: tokens, CostUSD: cost})
+		}
+	}
+	c.record("scan")
+}
+
+// record records the tokens taken and not yet recorded as spent by
+// operation. It runs on its own context so spend is recorded on shutdown.
+func (c *costTracker) record(operation string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	tokens := c.pending
+	c.pending = 0
+	c.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.recorder.Record(ctx, operation, tokens); err != nil {
+		log.Printf("Warning: failed to record embedding cost for %s: %v", c.projectRoot, err)
+	}
+	c.docs.record(operation)
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/stats"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestRenderCostReport(t *testing.T) {
+	out := renderCostReport("/src", []projectSpend{
+		{Path: "/src/api", Tokens: 3_000_000, CostUSD: 0.06, Months: []stats.MonthSpend{
+			{Month: "2026-01", Tokens: 1_000_000, CostUSD: 0.02},
+			{Month: "2026-02", Tokens: 2_000_000, CostUSD: 0.04},
+		}},
+		{Path: "/src/web", Tokens: 500_000, CostUSD: 1.5, Months: []stats.MonthSpend{
+			{Month: "2026-02", Tokens: 500_000, CostUSD: 1.5},
+		}},
+		{Path: "/src/broken", Error: "permission denied"},
+	})
+
+	for _, want := range []string{"api", "3000000", "$0.0600", "web", "$1.50", "error: permission denied", "TOTAL", "3500000", "$1.56", "2026-01", "2026-02", "2500000", "$1.54"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+type costTestEmbedder struct{}
+
+func (costTestEmbedder) Embed(context.Context, string) ([]float32, error) { return []float32{1}, nil }
+func (costTestEmbedder) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+func (costTestEmbedder) Dimensions() int { return 1 }
+func (costTestEmbedder) Close() error    { return nil }
+
+func TestCostTrackerDocsEmbedder(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	docs := config.DefaultEmbedderForProvider("openai")
+	cfg.Embedder.Docs = &docs
+
+	c := newCostTracker(root, cfg)
+	if c == nil {
+		t.Fatal("expected a tracker for a paid docs embedder")
+	}
+	var code embedder.Embedder = costTestEmbedder{}
+	if c.wrap(code) != code {
+		t.Error("expected the free code embedder not to be counted")
+	}
+	ctx := context.Background()
+	_, _ = c.wrap(code).Embed(ctx, strings.Repeat("x", 4000))
+	_, _ = c.wrapDocs(costTestEmbedder{}).Embed(ctx, strings.Repeat("x", 400))
+
+	c.scanComplete(nil)
+	spend, err := stats.ReadSpend(stats.CostPath(root))
+	if err != nil {
+		t.Fatalf("ReadSpend: %v", err)
+	}
+	if len(spend) != 1 || spend[0].Model != docs.Model || spend[0].Tokens != 100 || spend[0].Operation != "scan" {
+		t.Fatalf("expected the docs embedder's scan spend only, got %+v", spend)
+	}
+}
+
+func TestCostTrackerNewIndexerCountsIndexing(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Embedder = config.DefaultEmbedderForProvider("openai")
+
+	c := newCostTracker(root, cfg)
+	if c == nil {
+		t.Fatal("expected a tracker for a paid embedder")
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ignore, err := indexer.NewIgnoreMatcher(root, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := store.NewGOBStore(filepath.Join(root, ".grepai", "index.gob"))
+	idx := c.newIndexer(root, cfg, st, costTestEmbedder{}, nil, indexer.NewChunker(512, 50), indexer.NewScanner(root, ignore), time.Time{})
+	if _, err := idx.IndexAll(context.Background()); err != nil {
+		t.Fatalf("IndexAll: %v", err)
+	}
+
+	c.scanComplete(nil)
+	spend, err := stats.ReadSpend(stats.CostPath(root))
+	if err != nil {
+		t.Fatalf("ReadSpend: %v", err)
+	}
+	if len(spend) != 1 || spend[0].Operation != "scan" || spend[0].Tokens == 0 {
+		t.Fatalf("expected the spend of the indexing run, got %+v", spend)
+	}
+}
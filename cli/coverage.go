@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/coverage"
+)
+
+var coverageFormat string
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "Manage the test coverage shown with search and trace results",
+}
+
+var coverageImportCmd = &cobra.Command{
+	Use:   "import <profile>",
+	Short: "Import a Go or lcov coverage profile",
+	Long: `Import a test coverage profile into the project. Search and trace
+results then show the line coverage of each result, and 'grepai search
+--uncovered' keeps only code no test runs.
+
+Accepted formats, detected from the content unless --format is given:
+  go    profiles written by 'go test -coverprofile'
+  lcov  tracefiles written by Jest, Vitest, c8, coverage.py (coverage lcov),
+        gcov and most other tools
+
+Paths in the profile are made relative to the project root: Go import paths
+by removing the module path of go.mod, absolute paths when inside the project.
+Importing replaces the previous profile. Coverage is not updated as files
+change, so import a fresh profile after running the tests again.
+
+Examples:
+  go test -coverprofile=cover.out ./... && grepai coverage import cover.out
+  grepai coverage import coverage/lcov.info`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCoverageImport,
+}
+
+func init() {
+	coverageImportCmd.Flags().StringVar(&coverageFormat, "format", "", "Profile format: go or lcov (default: detected)")
+	coverageCmd.AddCommand(coverageImportCmd)
+	rootCmd.AddCommand(coverageCmd)
+}
+
+func runCoverageImport(cmd *cobra.Command, args []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	profile, err := coverage.ParseFile(args[0], strings.ToLower(coverageFormat), projectRoot)
+	if err != nil {
+		return err
+	}
+	if len(profile.Files) == 0 {
+		return fmt.Errorf("no coverage found in %s", args[0])
+	}
+	if err := profile.Save(config.GetCoveragePath(projectRoot)); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %s coverage of %d files: %s\n", profile.Format, len(profile.Files), profile.Total())
+	return nil
+}
+
+// loadProjectCoverage loads the coverage profile imported into projectRoot.
+// It returns nil without a project or profile.
+func loadProjectCoverage(projectRoot string) *coverage.Profile {
+	if projectRoot == "" {
+		return nil
+	}
+	profile, err := coverage.Load(config.GetCoveragePath(projectRoot))
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return profile
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// writeClipboard puts text on the system clipboard. Tests replace it.
+var writeClipboard = clipboard.WriteAll
+
+// chunkBody returns chunk content without the "File: ..." header the
+// chunker prepends.
+func chunkBody(content string) string {
+	if strings.HasPrefix(content, "File: ") {
+		if idx := strings.Index(content, "\n\n"); idx != -1 {
+			content = content[idx+2:]
+		}
+	}
+	return content
+}
+
+// fenceCode wraps content of the file at path in a Markdown code fence
+// tagged with its language, or its extension when the language has no name.
+// The fence is longer than any backtick run in content, so content with
+// fences of its own stays intact.
+func fenceCode(path, content string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	ext := langdetect.Ext(path, "")
+	lang := langdetect.Language(ext)
+	if lang == "" {
+		lang = strings.TrimPrefix(ext, ".")
+	}
+	return fence + lang + "\n" + strings.TrimRight(content, "\n") + "\n" + fence + "\n"
+}
+
+// fencedResult renders a search result as a file:line header, with its
+// enclosing symbol, followed by its whole content in a code fence.
+func fencedResult(r store.SearchResult) string {
+	header := fmt.Sprintf("%s:%d-%d", r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+	if r.SymbolName != "" {
+		header += fmt.Sprintf(" (%s)", r.SymbolName)
+	}
+	return header + "\n\n" + fenceCode(r.Chunk.FilePath, chunkBody(r.Chunk.Content))
+}
+
+// renderFencedResults renders results as Markdown blocks separated by blank
+// lines, ready to paste into a chat.
+func renderFencedResults(results []store.SearchResult) string {
+	blocks := make([]string, len(results))
+	for i, r := range results {
+		blocks[i] = fencedResult(r)
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// copyResult puts result n (1-based) of results on the clipboard as a
+// Markdown block, and returns its file:line header.
+func copyResult(results []store.SearchResult, n int) (string, error) {
+	if n < 1 || n > len(results) {
+		return "", fmt.Errorf("cannot copy result %d: the search returned %d results", n, len(results))
+	}
+	r := results[n-1]
+	if err := writeClipboard(fencedResult(r)); err != nil {
+		return "", fmt.Errorf("failed to copy to the clipboard: %w", err)
+	}
+	return fmt.Sprintf("%s:%d-%d", r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine), nil
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestFenceCode(t *testing.T) {
+	if got := fenceCode("main.py", "print(1)\n"); got != "```python\nprint(1)\n```\n" {
+		t.Errorf("unexpected fence %q", got)
+	}
+	if got := fenceCode("README.md", "```go\nx\n```"); !strings.HasPrefix(got, "````markdown\n") || !strings.HasSuffix(got, "\n````\n") {
+		t.Errorf("expected a longer fence around fenced content, got %q", got)
+	}
+	if got := fenceCode("Cargo.toml", "[package]\n"); !strings.HasPrefix(got, "```toml\n") {
+		t.Errorf("expected the extension for a language without a name, got %q", got)
+	}
+}
+
+func fencedTestResults() []store.SearchResult {
+	return []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "auth/session.go", StartLine: 10, EndLine: 12, Content: "File: auth/session.go\n\nfunc Refresh() {\n\treturn\n}\n"}, SymbolName: "Refresh"},
+		{Chunk: store.Chunk{FilePath: "Makefile", StartLine: 1, EndLine: 2, Content: "File: Makefile\n\nbuild:\n\tgo build ./...\n"}},
+	}
+}
+
+func TestRenderFencedResults(t *testing.T) {
+	want := "auth/session.go:10-12 (Refresh)\n\n```go\nfunc Refresh() {\n\treturn\n}\n```\n" +
+		"\n" +
+		"Makefile:1-2\n\n```make\nbuild:\n\tgo build ./...\n```\n"
+	if got := renderFencedResults(fencedTestResults()); got != want {
+		t.Errorf("renderFencedResults() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCopyResult(t *testing.T) {
+	var copied string
+	orig := writeClipboard
+	writeClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	defer func() { writeClipboard = orig }()
+
+	results := fencedTestResults()
+	location, err := copyResult(results, 2)
+	if err != nil {
+		t.Fatalf("copyResult failed: %v", err)
+	}
+	if location != "Makefile:1-2" || copied != fencedResult(results[1]) {
+		t.Errorf("expected result 2 to be copied, got %q: %q", location, copied)
+	}
+
+	if _, err := copyResult(results, 3); err == nil || !strings.Contains(err.Error(), "returned 2 results") {
+		t.Errorf("expected an error for a result out of range, got %v", err)
+	}
+
+	writeClipboard = func(string) error { return errors.New("no clipboard utility") }
+	if _, err := copyResult(results, 1); err == nil || !strings.Contains(err.Error(), "no clipboard utility") {
+		t.Errorf("expected the clipboard error, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+var findLiteralKind string
+
+var findLiteralCmd = &cobra.Command{
+	Use:   "find-literal [query]",
+	Short: "Find string literals such as routes, SQL tables and env vars",
+	Long: `Find notable string literals recorded in the symbol index, with the
+functions that use them:
+
+  route  URL routes, such as "/api/users/{id}" or "GET /health"
+  sql    tables read or written by SQL queries in strings
+  env    environment variable names, as in os.Getenv("DATABASE_URL")
+  flag   feature flag keys, as in isEnabled("new-checkout")
+
+The query matches any part of a literal, case-insensitively; without a query
+every literal is listed. In workspace mode, literals from every selected
+project are listed.
+
+Symbol indexes built before literals were recorded have none; the next
+'grepai watch' extracts every file again to add them.
+
+Examples:
+  grepai find-literal /api/users
+  grepai find-literal --kind env
+  grepai find-literal orders --kind sql --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runFindLiteral,
+}
+
+func init() {
+	findLiteralCmd.Flags().BoolVar(&traceJSON, "json", false, "Output results in JSON format")
+	findLiteralCmd.Flags().BoolVarP(&traceTOON, "toon", "t", false, "Output results in TOON format (token-efficient for AI agents)")
+	findLiteralCmd.MarkFlagsMutuallyExclusive("json", "toon")
+	findLiteralCmd.Flags().StringVar(&traceWorkspace, "workspace", "", "Workspace name for cross-project literals")
+	findLiteralCmd.Flags().StringVar(&traceProject, "project", "", "Project name within workspace (requires --workspace)")
+	findLiteralCmd.Flags().StringVar(&findLiteralKind, "kind", "", "Literal kind: route, sql, env, flag (default: all)")
+	findLiteralCmd.Flags().IntVar(&traceLimit, "limit", 0, "Maximum number of literals to return (0 = no limit)")
+	findLiteralCmd.Flags().IntVar(&traceOffset, "offset", 0, "Number of literals to skip")
+
+	rootCmd.AddCommand(findLiteralCmd)
+}
+
+func runFindLiteral(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	category, err := trace.ParseLiteralCategory(findLiteralKind)
+	if err != nil {
+		return err
+	}
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	stores, projectRoot, err := loadTraceStores(ctx)
+	if err != nil {
+		return err
+	}
+	defer trace.CloseSymbolStores(stores)
+
+	result, err := trace.FindLiterals(ctx, stores, query, category, trace.PageOptions{Limit: traceLimit, Offset: traceOffset})
+	if err != nil {
+		return fmt.Errorf("failed to find literals: %w", err)
+	}
+
+	if traceJSON || traceTOON {
+		return outputTraceValue(result)
+	}
+
+	return displayLiteralsResult(result, projectRoot)
+}
+
+func displayLiteralsResult(result *trace.LiteralsResult, projectRoot string) error {
+	if result.Query != "" {
+		fmt.Printf("Literals matching %q (%d)", result.Query, result.Total)
+	} else {
+		fmt.Printf("Literals (%d)", result.Total)
+	}
+	if len(result.Counts) > 0 {
+		var parts []string
+		for _, category := range trace.LiteralCategories {
+			if n := result.Counts[category]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%s: %d", category, n))
+			}
+		}
+		fmt.Printf(" [%s]", strings.Join(parts, ", "))
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", 60))
+
+	if result.Total == 0 {
+		fmt.Println("No literals found.")
+		return nil
+	}
+
+	for i, lit := range result.Literals {
+		uses := "uses"
+		if len(lit.Usages) == 1 {
+			uses = "use"
+		}
+		fmt.Printf("%d. %-5s %s (%d %s)\n", result.Offset+i+1, lit.Category, lit.Value, len(lit.Usages), uses)
+		for _, usage := range lit.Usages {
+			file := usage.File
+			if projectRoot != "" {
+				if rel, err := filepath.Rel(projectRoot, file); err == nil && !strings.HasPrefix(rel, "..") {
+					file = rel
+				}
+			}
+			if usage.Project != "" {
+				file = "[" + usage.Project + "] " + file
+			}
+			fmt.Printf("   %s:%d", file, usage.Line)
+			if usage.Caller != "" {
+				fmt.Printf(" in %s", usage.Caller)
+			}
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
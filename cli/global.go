@@ -0,0 +1,507 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/global"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+var (
+	globalInitRoots    []string
+	globalInitMaxDepth int
+	globalInitForce    bool
+	globalStatusJSON   bool
+)
+
+var globalCmd = &cobra.Command{
+	Use:   "global",
+	Short: "Manage the personal index spanning your repositories",
+	Long: `Manage a personal index spanning every git repository found under a list of
+directories (~/src by default), searchable with 'grepai search --global'.
+
+Unlike workspaces, the global index needs no per-project setup and no shared
+database: it is a single file-based index in ~/.grepai/global/, configured in
+~/.grepai/global.yaml.
+
+Examples:
+  grepai global init --root ~/src --root ~/work
+  grepai global index
+  grepai search --global "retry with exponential backoff"`,
+}
+
+var globalInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the global index configuration",
+	Args:  cobra.NoArgs,
+	RunE:  runGlobalInit,
+}
+
+var globalAddCmd = &cobra.Command{
+	Use:   "add <dir>",
+	Short: "Add a directory to search for repositories",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGlobalAdd,
+}
+
+var globalRemoveCmd = &cobra.Command{
+	Use:   "remove <dir>",
+	Short: "Remove a directory from the global index",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGlobalRemove,
+}
+
+var globalIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Index every repository found under the configured directories",
+	Long: `Index every git repository found under the configured directories.
+
+Only files changed since the last run are re-embedded. Files deleted from a
+repository, and repositories that are no longer found, are removed from the
+index.`,
+	Args: cobra.NoArgs,
+	RunE: runGlobalIndex,
+}
+
+var globalStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the repositories in the global index",
+	Args:  cobra.NoArgs,
+	RunE:  runGlobalStatus,
+}
+
+func init() {
+	globalInitCmd.Flags().StringArrayVar(&globalInitRoots, "root", nil, "Directory to search for repositories (repeatable, default: ~/src)")
+	globalInitCmd.Flags().IntVar(&globalInitMaxDepth, "max-depth", config.DefaultGlobalMaxDepth, "Directory levels below each root searched for repositories")
+	globalInitCmd.Flags().BoolVar(&globalInitForce, "force", false, "Overwrite an existing global configuration")
+	globalStatusCmd.Flags().BoolVar(&globalStatusJSON, "json", false, "Output status in JSON format")
+	addProfileFlags(globalIndexCmd)
+
+	globalCmd.AddCommand(globalInitCmd)
+	globalCmd.AddCommand(globalAddCmd)
+	globalCmd.AddCommand(globalRemoveCmd)
+	globalCmd.AddCommand(globalIndexCmd)
+	globalCmd.AddCommand(globalStatusCmd)
+	rootCmd.AddCommand(globalCmd)
+}
+
+// loadGlobalConfig loads the global index configuration, failing when it
+// has not been created yet.
+func loadGlobalConfig() (*config.GlobalConfig, error) {
+	cfg, err := config.LoadGlobalConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("global index not configured; run 'grepai global init' first")
+	}
+	return cfg, nil
+}
+
+func globalIndexPaths() (indexPath, reposPath string, err error) {
+	dir, err := config.GetGlobalIndexDir()
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.Join(dir, config.IndexFileName), filepath.Join(dir, config.GlobalReposFileName), nil
+}
+
+func runGlobalInit(cmd *cobra.Command, args []string) error {
+	existing, err := config.LoadGlobalConfig()
+	if err != nil && !globalInitForce {
+		return err
+	}
+	if existing != nil && !globalInitForce {
+		path, _ := config.GetGlobalConfigPath()
+		return fmt.Errorf("global index already configured in %s; edit it or use --force", path)
+	}
+
+	cfg := config.DefaultGlobalConfig()
+	if len(globalInitRoots) > 0 {
+		cfg.Roots = nil
+		for _, root := range globalInitRoots {
+			cfg.Roots = appendGlobalRoot(cfg.Roots, root)
+		}
+	}
+	if globalInitMaxDepth > 0 {
+		cfg.MaxDepth = globalInitMaxDepth
+	}
+	if err := config.SaveGlobalConfig(cfg); err != nil {
+		return err
+	}
+
+	path, _ := config.GetGlobalConfigPath()
+	fmt.Printf("Global index configured in %s\n", path)
+	fmt.Printf("Roots: %s\n", strings.Join(cfg.Roots, ", "))
+	fmt.Println("Run 'grepai global index' to build it.")
+	return nil
+}
+
+// appendGlobalRoot adds root to roots as an absolute path unless it starts
+// with ~, skipping duplicates.
+func appendGlobalRoot(roots []string, root string) []string {
+	if !strings.HasPrefix(root, "~") {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+	}
+	for _, r := range roots {
+		if r == root {
+			return roots
+		}
+	}
+	return append(roots, root)
+}
+
+func runGlobalAdd(cmd *cobra.Command, args []string) error {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	before := len(cfg.Roots)
+	cfg.Roots = appendGlobalRoot(cfg.Roots, args[0])
+	if len(cfg.Roots) == before {
+		fmt.Printf("%s is already a global index root\n", args[0])
+		return nil
+	}
+	if err := config.SaveGlobalConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Added %s; run 'grepai global index' to index its repositories.\n", cfg.Roots[len(cfg.Roots)-1])
+	return nil
+}
+
+func runGlobalRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	target := appendGlobalRoot(nil, args[0])[0]
+	roots := cfg.Roots[:0]
+	for _, r := range cfg.Roots {
+		if r != target && r != args[0] {
+			roots = append(roots, r)
+		}
+	}
+	if len(roots) == len(cfg.Roots) {
+		return fmt.Errorf("%s is not a global index root", args[0])
+	}
+	cfg.Roots = roots
+	if err := config.SaveGlobalConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s; its repositories are dropped on the next 'grepai global index'.\n", args[0])
+	return nil
+}
+
+func runGlobalIndex(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	stopProfiling, err := startProfiling("global-index")
+	if err != nil {
+		return err
+	}
+	defer stopProfiling()
+
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	indexPath, reposPath, err := globalIndexPaths()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(indexPath), 0755); err != nil {
+		return fmt.Errorf("failed to create global index directory: %w", err)
+	}
+
+	paths, err := global.Discover(cfg.ExpandedRoots(), cfg.MaxDepth, cfg.Exclude)
+	if err != nil {
+		return err
+	}
+	previous, err := global.LoadRepos(reposPath)
+	if err != nil {
+		return err
+	}
+	names := global.AssignNames(paths, previous)
+	kept := make(map[string]global.Repo, len(previous))
+	for _, r := range previous {
+		if names[r.Path] == r.Name {
+			kept[r.Path] = r
+		}
+	}
+
+	emb, err := embedder.NewFromConfig(&config.Config{Embedder: cfg.Embedder})
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	defer emb.Close()
+
+	gobStore := store.NewGOBStore(indexPath)
+	if err := gobStore.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load global index: %w", err)
+	}
+	defer gobStore.Close()
+	if err := stampGlobalIndexMetadata(ctx, gobStore, cfg); err != nil {
+		return err
+	}
+
+	// The spend of the global index is recorded in ~/.grepai/cost.json.
+	embCfg := &config.Config{Embedder: cfg.Embedder}
+	cost := newCostTracker(filepath.Dir(filepath.Dir(indexPath)), embCfg)
+	defer cost.scanComplete(nil)
+
+	removed, err := removeGlobalRepos(ctx, gobStore, names)
+	if removed > 0 {
+		fmt.Printf("Removed %d files of repositories no longer found\n", removed)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Indexing %d repositories\n", len(paths))
+	repos := make([]global.Repo, 0, len(paths))
+	var errs []error
+	for i, path := range paths {
+		name := names[path]
+		ignoreMatcher, err := indexer.NewIgnoreMatcher(path, cfg.Ignore, "")
+		if err != nil {
+			fmt.Printf("[%d/%d] %s: skipped: %v\n", i+1, len(paths), name, err)
+			continue
+		}
+		scanner := indexer.NewScanner(path, ignoreMatcher)
+		chunker := indexer.NewChunker(cfg.Chunking.Size, cfg.Chunking.Overlap)
+		repoStore := newGlobalRepoStore(gobStore, name, path)
+		idx := cost.newIndexer(path, embCfg, repoStore, emb, nil, chunker, scanner, kept[path].LastIndexed)
+		idx.SetChangeDetection(cfg.ChangeDetection)
+
+		stats, err := idx.IndexAll(ctx)
+		if err != nil {
+			// Keep what the index held of it and go on with the others;
+			// its files are checked again on the next run.
+			fmt.Printf("[%d/%d] %s: failed: %v\n", i+1, len(paths), name, err)
+			errs = append(errs, fmt.Errorf("failed to index %s: %w", path, err))
+			repo := kept[path]
+			repo.Name, repo.Path = name, path
+			repos = append(repos, repo)
+			_, _ = cost.take() // Leave its spend out of the next repository's
+			continue
+		}
+		if err := gobStore.Persist(ctx); err != nil {
+			return fmt.Errorf("failed to save global index: %w", err)
+		}
+		spend := ""
+		if tokens, usd := cost.take(); tokens > 0 {
+			spend = fmt.Sprintf(", ~%d tokens (~%s)", tokens, formatUSD(usd))
+		}
+		fmt.Printf("[%d/%d] %s: %d files indexed, %d removed, %d chunks%s (%s)\n",
+			i+1, len(paths), name, stats.FilesIndexed, stats.FilesRemoved, stats.ChunksCreated, spend, stats.Duration.Round(time.Millisecond))
+
+		remote, branch := global.ReadGitInfo(path)
+		repos = append(repos, global.Repo{Name: name, Path: path, Remote: remote, Branch: branch, LastIndexed: time.Now()})
+	}
+
+	if err := gobStore.Persist(ctx); err != nil {
+		return fmt.Errorf("failed to save global index: %w", err)
+	}
+	if err := countGlobalRepoFiles(ctx, gobStore, repos); err != nil {
+		return err
+	}
+	if err := global.SaveRepos(reposPath, repos); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// stampGlobalIndexMetadata stamps the global index held by st with the
+// embedder of cfg.
+func stampGlobalIndexMetadata(ctx context.Context, st store.VectorStore, cfg *config.GlobalConfig) error {
+	path, err := config.GetGlobalIndexMetadataPath()
+	if err != nil {
+		return err
+	}
+	if err := stampIndexMetadata(ctx, st, path, cfg.Embedder); !errors.Is(err, config.ErrEmbedderMismatch) {
+		return err
+	}
+	// Refused: report it with the rebuild instructions.
+	return config.CheckGlobalIndexMetadata(cfg)
+}
+
+// removeGlobalRepos deletes the files of repositories that are not in
+// names from the global index and returns how many were removed, also when
+// it fails partway.
+func removeGlobalRepos(ctx context.Context, st store.VectorStore, names map[string]string) (int, error) {
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	docs, err := st.ListDocuments(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list global index documents: %w", err)
+	}
+	var repos []string
+	files := make(map[string]int)
+	for _, doc := range docs {
+		repo, _, _ := strings.Cut(doc, "/")
+		if keep[repo] {
+			continue
+		}
+		if files[repo] == 0 {
+			repos = append(repos, repo)
+		}
+		files[repo]++
+	}
+	removed := 0
+	for _, repo := range repos {
+		if err := st.DeleteByProject(ctx, repo); err != nil {
+			return removed, fmt.Errorf("failed to remove %s from the global index: %w", repo, err)
+		}
+		removed += files[repo]
+	}
+	return removed, nil
+}
+
+// countGlobalRepoFiles sets the file and chunk counts of repos from the
+// global index.
+func countGlobalRepoFiles(ctx context.Context, st store.VectorStore, repos []global.Repo) error {
+	files, err := st.ListFilesWithStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list global index files: %w", err)
+	}
+	byName := make(map[string]*global.Repo, len(repos))
+	for i := range repos {
+		byName[repos[i].Name] = &repos[i]
+	}
+	for _, f := range files {
+		name, _, _ := strings.Cut(f.Path, "/")
+		if r, ok := byName[name]; ok {
+			r.Files++
+			r.Chunks += f.ChunkCount
+		}
+	}
+	return nil
+}
+
+func runGlobalStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	_, reposPath, err := globalIndexPaths()
+	if err != nil {
+		return err
+	}
+	repos, err := global.LoadRepos(reposPath)
+	if err != nil {
+		return err
+	}
+
+	if globalStatusJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]any{"roots": cfg.Roots, "repositories": repos})
+	}
+
+	fmt.Printf("Roots: %s\n", strings.Join(cfg.Roots, ", "))
+	if len(repos) == 0 {
+		fmt.Println("No repositories indexed yet; run 'grepai global index'.")
+		return nil
+	}
+	fmt.Printf("Repositories (%d):\n", len(repos))
+	for _, r := range repos {
+		fmt.Printf("  %-24s %5d files %7d chunks  %s  %s\n", r.Name, r.Files, r.Chunks, r.LastIndexed.Format("2006-01-02 15:04"), r.Path)
+	}
+	return nil
+}
+
+// runGlobalSearch searches the global index. pathOpt filters results by a
+// path prefix starting with the repository name, e.g. "api/internal".
+func runGlobalSearch(ctx context.Context, query, pathOpt string) error {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	indexPath, _, err := globalIndexPaths()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(indexPath); err != nil {
+		return fmt.Errorf("global index not built yet; run 'grepai global index' first")
+	}
+	if err := config.CheckGlobalIndexMetadata(cfg); err != nil {
+		return err
+	}
+
+	emb, err := embedder.NewFromConfig(&config.Config{Embedder: cfg.Embedder})
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	defer emb.Close()
+
+	// The store is only read, so it is not closed: closing a GOB store
+	// writes it back to disk.
+	gobStore := store.NewGOBStore(indexPath)
+	if err := gobStore.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load global index: %w", err)
+	}
+
+	searcher := search.NewSearcher(gobStore, emb, config.DefaultConfig().Search)
+	pathPrefix := strings.TrimPrefix(filepath.ToSlash(pathOpt), "./")
+	results, err := searcher.Search(ctx, query, searchLimit, pathPrefix)
+	if err != nil {
+		if searchJSON {
+			return outputSearchErrorJSON(err)
+		}
+		if searchTOON {
+			return outputSearchErrorTOON(err)
+		}
+		return fmt.Errorf("search failed: %w", err)
+	}
+
+	projectRoot, _ := config.FindProjectRoot()
+	enrichments := make([]rpgEnrichment, len(results))
+	return outputSearchResults(projectRoot, fmt.Sprintf("Found %d results for: %q in the global index", len(results), query), results, enrichments)
+}
+
+// globalRepoStore is the view of one repository in the global index. Paths
+// are prefixed with the repository name, and ListDocuments only returns the
+// repository's own files, relative to it, so the indexer removes files
+// deleted from the repository without touching other repositories.
+type globalRepoStore struct {
+	*projectPrefixStore
+}
+
+func newGlobalRepoStore(st store.VectorStore, name, path string) *globalRepoStore {
+	return &globalRepoStore{&projectPrefixStore{store: st, projectName: name, projectPath: path}}
+}
+
+func (g *globalRepoStore) ListDocuments(ctx context.Context) ([]string, error) {
+	docs, err := g.store.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefix := g.getPrefix() + "/"
+	own := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if rel, ok := strings.CutPrefix(doc, prefix); ok {
+			own = append(own, rel)
+		}
+	}
+	return own, nil
+}
+
+func (g *globalRepoStore) GetChunksForFile(ctx context.Context, filePath string) ([]store.Chunk, error) {
+	return g.store.GetChunksForFile(ctx, g.getPrefix()+"/"+g.toRelSlash(filePath))
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestGlobalRepoStore_ScopesDocumentsToRepository(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	repoPath := filepath.Join(t.TempDir(), "api")
+	api := newGlobalRepoStore(st, "api", repoPath)
+
+	if err := api.SaveChunks(ctx, []store.Chunk{{ID: "main.go_0", FilePath: "main.go", Content: "package main"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.SaveDocument(ctx, store.Document{Path: "main.go", ChunkIDs: []string{"main.go_0"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SaveDocument(ctx, store.Document{Path: "web/app.ts"}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := api.ListDocuments(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(docs, []string{"main.go"}) {
+		t.Errorf("ListDocuments = %v, want [main.go]", docs)
+	}
+
+	all, _ := st.ListDocuments(ctx)
+	sort.Strings(all)
+	if !reflect.DeepEqual(all, []string{"api/main.go", "web/app.ts"}) {
+		t.Errorf("documents = %v, want repository-prefixed paths", all)
+	}
+
+	chunks, err := api.GetChunksForFile(ctx, filepath.Join(repoPath, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || chunks[0].FilePath != "api/main.go" {
+		t.Errorf("GetChunksForFile = %+v, want the api/main.go chunk", chunks)
+	}
+}
+
+func TestRemoveGlobalRepos(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	for _, path := range []string{"api/main.go", "old/lib.go", "old/util.go"} {
+		if err := st.SaveChunks(ctx, []store.Chunk{{ID: path + "_0", FilePath: path}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.SaveDocument(ctx, store.Document{Path: path, ChunkIDs: []string{path + "_0"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := removeGlobalRepos(ctx, st, map[string]string{"/src/api": "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+	docs, _ := st.ListDocuments(ctx)
+	if !reflect.DeepEqual(docs, []string{"api/main.go"}) {
+		t.Errorf("documents = %v, want [api/main.go]", docs)
+	}
+}
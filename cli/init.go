@@ -2,9 +2,11 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yoanbernabeu/grepai/config"
@@ -19,6 +21,8 @@ var (
 	initNonInteractive bool
 	initInherit        bool
 	initUI             bool
+	initSeedIndex      bool
+	initCheck          bool
 )
 
 const (
@@ -34,17 +38,23 @@ This command will:
 - Create .grepai/config.yaml with default settings
 - Prompt for embedding provider (Ollama or OpenAI)
 - Prompt for storage backend (GOB file or PostgreSQL)
-- Add .grepai/ to .gitignore if present`,
+- Add .grepai/ to .gitignore if present
+
+With --check, init embeds a test string and connects to the storage backend
+before saving, and saves nothing if either fails. In an initialized project,
+--check tests the existing configuration instead.`,
 	RunE: runInit,
 }
 
 func init() {
 	initCmd.Flags().StringVarP(&initProvider, "provider", "p", "", "Embedding provider (ollama, lmstudio, openai, synthetic, or openrouter)")
 	initCmd.Flags().StringVarP(&initModel, "model", "m", "", "Embedding model (for openai/openrouter: text-embedding-3-small, text-embedding-3-large; openrouter also supports qwen3-embedding-8b)")
-	initCmd.Flags().StringVarP(&initBackend, "backend", "b", "", "Storage backend (gob, postgres, or qdrant)")
+	initCmd.Flags().StringVarP(&initBackend, "backend", "b", "", "Storage backend (gob, postgres, qdrant, weaviate, redis, or opensearch)")
 	initCmd.Flags().BoolVar(&initNonInteractive, "yes", false, "Use defaults without prompting")
 	initCmd.Flags().BoolVar(&initInherit, "inherit", false, "Inherit configuration from main worktree (for git worktrees)")
 	initCmd.Flags().BoolVar(&initUI, "ui", false, "Run interactive Bubble Tea UI wizard")
+	initCmd.Flags().BoolVar(&initCheck, "check", false, "Test the embedder and storage backend before saving the configuration")
+	initCmd.Flags().BoolVar(&initSeedIndex, "seed-index", true, "In a git worktree, seed the index from the main worktree so only the branch delta is embedded")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -55,6 +65,13 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	// Check if already initialized
 	if config.Exists(cwd) {
+		if initCheck {
+			cfg, err := config.Load(cwd)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			return printConnectivityReport(initConnectivityCheck(context.Background(), cfg, cwd))
+		}
 		fmt.Println("grepai is already initialized in this directory.")
 		fmt.Printf("Configuration: %s\n", config.GetConfigPath(cwd))
 		return nil
@@ -102,6 +119,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	checked := false
 	if initUI && !initNonInteractive {
 		uiCfg, uiErr := runInitWizardUI(cwd, cfg, detectedGitInfo, detectedMainCfg, initInherit)
 		if uiErr != nil {
@@ -109,6 +127,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 		cfg = uiCfg
 		skipPrompts = true
+		// The wizard ends with its own connectivity check.
+		checked = true
 	}
 
 	// Interactive mode
@@ -217,6 +237,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 			fmt.Println("  1) gob (local file, recommended for most projects)")
 			fmt.Println("  2) postgres (pgvector, for large monorepos or shared index)")
 			fmt.Println("  3) qdrant (Docker-based vector database)")
+			fmt.Println("  4) weaviate (Weaviate vector database)")
+			fmt.Println("  5) redis (Redis Stack with RediSearch)")
+			fmt.Println("  6) opensearch (OpenSearch with k-NN, server-side hybrid search)")
 			fmt.Print("Choice [1]: ")
 
 			input, _ := reader.ReadString('\n')
@@ -230,40 +253,40 @@ func runInit(cmd *cobra.Command, args []string) error {
 				cfg.Store.Postgres.DSN = strings.TrimSpace(dsn)
 			case "3", "qdrant":
 				cfg.Store.Backend = "qdrant"
-				fmt.Print("Qdrant endpoint [localhost]: ")
-				endpoint, _ := reader.ReadString('\n')
-				endpoint = strings.TrimSpace(endpoint)
-				if endpoint == "" {
-					endpoint = "localhost"
+				if err := promptQdrantConfig(reader, &cfg.Store.Qdrant, config.DefaultQdrantEndpoint); err != nil {
+					return err
 				}
-				cfg.Store.Qdrant.Endpoint = endpoint
-
-				fmt.Print("Qdrant port [6334]: ")
-				port, _ := reader.ReadString('\n')
-				port = strings.TrimSpace(port)
-				if port == "" {
-					cfg.Store.Qdrant.Port = 6334
-				} else {
-					var portInt int
-					_, err := fmt.Sscanf(port, "%d", &portInt)
-					if err != nil {
-						return fmt.Errorf("invalid port number: %w", err)
-					}
-					cfg.Store.Qdrant.Port = portInt
+				if !initCheck {
+					offerConnectionTest(reader, "Qdrant", func(ctx context.Context) error {
+						return checkStore(ctx, cfg, cwd)
+					})
+				}
+			case "4", "weaviate":
+				cfg.Store.Backend = "weaviate"
+				promptWeaviateConfig(reader, &cfg.Store.Weaviate)
+				if !initCheck {
+					offerConnectionTest(reader, "Weaviate", func(ctx context.Context) error {
+						return checkStore(ctx, cfg, cwd)
+					})
+				}
+			case "5", "redis":
+				cfg.Store.Backend = "redis"
+				if err := promptRedisConfig(reader, &cfg.Store.Redis); err != nil {
+					return err
+				}
+				if !initCheck {
+					offerConnectionTest(reader, "Redis", func(ctx context.Context) error {
+						return checkStore(ctx, cfg, cwd)
+					})
+				}
+			case "6", "opensearch":
+				cfg.Store.Backend = "opensearch"
+				promptOpenSearchConfig(reader, &cfg.Store.OpenSearch)
+				if !initCheck {
+					offerConnectionTest(reader, "OpenSearch", func(ctx context.Context) error {
+						return checkStore(ctx, cfg, cwd)
+					})
 				}
-
-				fmt.Print("Use TLS? (y/n) [n]: ")
-				useTLS, _ := reader.ReadString('\n')
-				useTLS = strings.TrimSpace(strings.ToLower(useTLS))
-				cfg.Store.Qdrant.UseTLS = useTLS == "y" || useTLS == "yes"
-
-				fmt.Print("Collection name (optional, defaults to sanitized project path): ")
-				collection, _ := reader.ReadString('\n')
-				cfg.Store.Qdrant.Collection = strings.TrimSpace(collection)
-
-				fmt.Print("API key (optional, for Qdrant Cloud): ")
-				apiKey, _ := reader.ReadString('\n')
-				cfg.Store.Qdrant.APIKey = strings.TrimSpace(apiKey)
 			default:
 				cfg.Store.Backend = "gob"
 			}
@@ -302,6 +325,16 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// An inherited configuration carries the main worktree's last index
+	// time, which does not apply to this worktree's files.
+	cfg.Watch.LastIndexTime = time.Time{}
+
+	if initCheck && !checked {
+		if err := printConnectivityReport(initConnectivityCheck(context.Background(), cfg, cwd)); err != nil {
+			return fmt.Errorf("%w; configuration not saved", err)
+		}
+	}
+
 	// Save configuration
 	if err := cfg.Save(cwd); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
@@ -309,6 +342,24 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("\nCreated configuration at %s\n", config.GetConfigPath(cwd))
 
+	if detectedGitInfo != nil && canSeedWorktreeIndex(cfg, detectedMainCfg) {
+		seed := initSeedIndex
+		if seed && !cmd.Flags().Changed("seed-index") && !initNonInteractive && !initUI {
+			reader := bufio.NewReader(os.Stdin)
+			fmt.Print("\nSeed the index from the main worktree? Only files that differ will be embedded. [Y/n]: ")
+			input, _ := reader.ReadString('\n')
+			input = strings.TrimSpace(strings.ToLower(input))
+			seed = input == "" || input == "y" || input == "yes"
+		}
+		if seed {
+			if err := config.SeedWorktreeIndex(cwd, detectedGitInfo.MainWorktree); err != nil {
+				fmt.Printf("Warning: could not seed the index from the main worktree: %v\n", err)
+			} else {
+				fmt.Println("Seeded the index from the main worktree")
+			}
+		}
+	}
+
 	// Add .grepai/ to .gitignore
 	gitignorePath := cwd + "/.gitignore"
 	if _, err := os.Stat(gitignorePath); err == nil {
@@ -345,6 +396,20 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// canSeedWorktreeIndex reports whether the main worktree's local index can
+// seed a worktree configured with cfg: both use the GOB backend, and the
+// same embedding model and chunking, so the seeded vectors stay valid.
+func canSeedWorktreeIndex(cfg, mainCfg *config.Config) bool {
+	if cfg == nil || mainCfg == nil {
+		return false
+	}
+	return cfg.Store.Backend == "gob" && mainCfg.Store.Backend == "gob" &&
+		cfg.Embedder.Provider == mainCfg.Embedder.Provider &&
+		cfg.Embedder.Model == mainCfg.Embedder.Model &&
+		cfg.Embedder.GetDimensions() == mainCfg.Embedder.GetDimensions() &&
+		cfg.Chunking == mainCfg.Chunking
+}
+
 func shouldPromptInheritChoice(shouldInherit, nonInteractive, uiMode bool) bool {
 	return !shouldInherit && !nonInteractive && !uiMode
 }
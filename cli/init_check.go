@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// connectivityCheckTimeout bounds each half of the connectivity check.
+const connectivityCheckTimeout = 30 * time.Second
+
+const connectivityCheckText = "grepai connectivity check"
+
+// connectivityReport is the outcome of checking that a configuration's
+// embedder and storage backend are reachable.
+type connectivityReport struct {
+	provider     string
+	model        string
+	dimensions   int
+	embedLatency time.Duration
+	embedErr     error
+
+	backend      string
+	storeLatency time.Duration
+	storeErr     error
+}
+
+func (r connectivityReport) ok() bool {
+	return r.embedErr == nil && r.storeErr == nil
+}
+
+// lines describes the report, one line per checked component.
+func (r connectivityReport) lines() []string {
+	embed := fmt.Sprintf("Embedder %s (%s): ", r.provider, r.model)
+	if r.embedErr != nil {
+		embed += "FAILED: " + r.embedErr.Error()
+	} else {
+		embed += fmt.Sprintf("ok, %d dimensions in %s", r.dimensions, r.embedLatency.Round(time.Millisecond))
+	}
+	backend := fmt.Sprintf("Backend %s: ", r.backend)
+	if r.storeErr != nil {
+		backend += "FAILED: " + r.storeErr.Error()
+	} else {
+		backend += fmt.Sprintf("ok in %s", r.storeLatency.Round(time.Millisecond))
+	}
+	return []string{embed, backend}
+}
+
+// initConnectivityCheck is replaced in tests to avoid network calls.
+var initConnectivityCheck = checkConnectivity
+
+// checkConnectivity embeds a test string with cfg's embedder and connects to
+// cfg's storage backend for projectRoot. The embedder must return as many
+// dimensions as the store will be created with.
+func checkConnectivity(ctx context.Context, cfg *config.Config, projectRoot string) connectivityReport {
+	report := connectivityReport{
+		provider: cfg.Embedder.Provider,
+		model:    cfg.Embedder.Model,
+		backend:  cfg.Store.Backend,
+	}
+
+	embedCtx, cancel := context.WithTimeout(ctx, connectivityCheckTimeout)
+	start := time.Now()
+	report.dimensions, report.embedErr = checkEmbedder(embedCtx, cfg)
+	report.embedLatency = time.Since(start)
+	cancel()
+
+	storeCtx, cancel := context.WithTimeout(ctx, connectivityCheckTimeout)
+	start = time.Now()
+	report.storeErr = checkStore(storeCtx, cfg, projectRoot)
+	report.storeLatency = time.Since(start)
+	cancel()
+
+	return report
+}
+
+func checkEmbedder(ctx context.Context, cfg *config.Config) (int, error) {
+	emb, err := initializeEmbedder(ctx, cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer emb.Close()
+
+	vec, err := emb.Embed(ctx, connectivityCheckText)
+	if err != nil {
+		return 0, err
+	}
+	if want := cfg.Embedder.GetDimensions(); len(vec) != want {
+		return len(vec), fmt.Errorf("model returned %d dimensions but the configuration expects %d; set embedder.dimensions to %d", len(vec), want, len(vec))
+	}
+	return len(vec), nil
+}
+
+func checkStore(ctx context.Context, cfg *config.Config, projectRoot string) error {
+	if cfg.Store.Backend == "gob" {
+		// The index is a local file: check that it can be written.
+		f, err := os.CreateTemp(projectRoot, ".grepai-check-*")
+		if err != nil {
+			return fmt.Errorf("project directory is not writable: %w", err)
+		}
+		f.Close()
+		return os.Remove(f.Name())
+	}
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	return st.Close()
+}
+
+// printConnectivityReport prints r and returns an error when a check failed.
+func printConnectivityReport(r connectivityReport) error {
+	fmt.Println("\nChecking connectivity...")
+	for _, line := range r.lines() {
+		fmt.Printf("  %s\n", line)
+	}
+	if !r.ok() {
+		var failed []string
+		if r.embedErr != nil {
+			failed = append(failed, "embedder")
+		}
+		if r.storeErr != nil {
+			failed = append(failed, "backend")
+		}
+		return fmt.Errorf("connectivity check failed: %s", strings.Join(failed, " and "))
+	}
+	return nil
+}
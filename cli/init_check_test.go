@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestCheckConnectivity_ReportsEachComponent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	srv.Close()
+
+	projectRoot := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Embedder.Endpoint = srv.URL
+	cfg.Store.Backend = "gob"
+
+	report := checkConnectivity(context.Background(), cfg, projectRoot)
+	if report.embedErr == nil {
+		t.Fatal("expected an embedder error for an unreachable endpoint")
+	}
+	if report.storeErr != nil {
+		t.Fatalf("gob backend check failed: %v", report.storeErr)
+	}
+	if report.ok() {
+		t.Fatal("report should not be ok")
+	}
+	entries, err := os.ReadDir(projectRoot)
+	if err != nil {
+		t.Fatalf("failed to read project dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("check left %d files behind", len(entries))
+	}
+
+	err = printConnectivityReport(report)
+	if err == nil || !strings.Contains(err.Error(), "embedder") || strings.Contains(err.Error(), "backend") {
+		t.Fatalf("printConnectivityReport() error = %v, want an embedder failure", err)
+	}
+}
@@ -95,3 +95,22 @@ func TestRunInit_OpenAIDefaultsToOpenAISmallModel(t *testing.T) {
 		t.Fatalf("parallelism = %d, want %d", cfg.Embedder.Parallelism, config.DefaultOpenAIParallelism)
 	}
 }
+
+func TestCanSeedWorktreeIndex(t *testing.T) {
+	mainCfg := config.DefaultConfig()
+	cfg := config.DefaultConfig()
+	if !canSeedWorktreeIndex(cfg, mainCfg) {
+		t.Fatal("expected identical GOB configs to allow seeding")
+	}
+
+	cfg.Embedder.Model = "other-model"
+	if canSeedWorktreeIndex(cfg, mainCfg) {
+		t.Error("a different embedding model must not reuse the seed")
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.Store.Backend = "postgres"
+	if canSeedWorktreeIndex(cfg, mainCfg) {
+		t.Error("only the GOB backend has a local index to seed")
+	}
+}
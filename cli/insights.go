@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/stats"
+	"github.com/yoanbernabeu/grepai/watcher"
+)
+
+var (
+	insightsJSON  bool
+	insightsLimit int
+)
+
+// insightsReport is the output of 'grepai insights'.
+type insightsReport struct {
+	MinScore    float64              `json:"min_score"`
+	Misses      []stats.QueryMiss    `json:"misses"`
+	Files       []stats.FilePriority `json:"files"`
+	Suggestions []string             `json:"suggestions"`
+}
+
+var insightsCmd = &cobra.Command{
+	Use:   "insights",
+	Short: "Show searches the index answers poorly and what to do about them",
+	Long: `Show the searches recorded as misses, the files edited after them, and
+suggestions to improve the index.
+
+When insights.enabled is set in the config, searches whose nearest chunk
+scores below insights.min_score are recorded in .grepai/insights.json with
+their query. 'grepai watch' then records the files edited within 30 minutes
+of a miss, which are likely what the search was looking for. The next scan of
+the watcher re-indexes those files first, even when unchanged, once within a
+week of their edit. Workspace searches and watchers record the misses and
+edits of each project with insights enabled.
+
+Examples:
+  grepai insights
+  grepai insights --limit 5
+  grepai insights --json`,
+	Args: cobra.NoArgs,
+	RunE: runInsights,
+}
+
+func init() {
+	insightsCmd.Flags().BoolVar(&insightsJSON, "json", false, "Output insights in JSON format")
+	insightsCmd.Flags().IntVarP(&insightsLimit, "limit", "l", 20, "Max misses and files shown")
+	rootCmd.AddCommand(insightsCmd)
+}
+
+func runInsights(cmd *cobra.Command, args []string) error {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	signals, err := stats.ReadSignals(stats.InsightsPath(projectRoot))
+	if err != nil {
+		return fmt.Errorf("failed to read insights: %w", err)
+	}
+	insights := stats.SummarizeInsights(signals)
+	report := insightsReport{
+		MinScore:    cfg.Insights.GetMinScore(),
+		Misses:      insights.Misses,
+		Files:       insights.Files,
+		Suggestions: suggestIndexChanges(projectRoot, cfg, insights),
+	}
+	if insightsLimit > 0 {
+		report.Misses = report.Misses[:min(insightsLimit, len(report.Misses))]
+		report.Files = report.Files[:min(insightsLimit, len(report.Files))]
+	}
+
+	if insightsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	if len(report.Misses) == 0 {
+		if !cfg.Insights.Enabled {
+			fmt.Println("Insights are disabled.")
+			fmt.Println("Set insights.enabled: true in .grepai/config.yaml to record search misses.")
+		} else {
+			fmt.Println("No search misses recorded yet.")
+		}
+		return nil
+	}
+	fmt.Print(renderInsights(report))
+	return nil
+}
+
+// renderInsights renders an insights report as plain text tables.
+func renderInsights(report insightsReport) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Search misses (nearest chunk below %.2f)\n\n", report.MinScore)
+	fmt.Fprintf(&sb, "%-40s %7s %11s  %s\n", "QUERY", "MISSES", "CONFIDENCE", "EDITED AFTER")
+	for _, m := range report.Misses {
+		edited := strings.Join(m.Edited, ", ")
+		if edited == "" {
+			edited = "-"
+		}
+		fmt.Fprintf(&sb, "%-40s %7d %11.2f  %s\n", truncateText(m.Query, 40), m.Count, m.Confidence, edited)
+	}
+
+	if len(report.Files) > 0 {
+		sb.WriteString("\nRe-index priority (files edited after misses, indexed first by grepai watch)\n\n")
+		fmt.Fprintf(&sb, "%-50s %7s %7s\n", "FILE", "MISSES", "FOUND")
+		for _, f := range report.Files {
+			fmt.Fprintf(&sb, "%-50s %7d %7d\n", truncatePath(f.Path, 50), f.Misses, f.Found)
+		}
+	}
+
+	if len(report.Suggestions) > 0 {
+		sb.WriteString("\nSuggestions\n\n")
+		for _, s := range report.Suggestions {
+			fmt.Fprintf(&sb, "- %s\n", s)
+		}
+	}
+	return sb.String()
+}
+
+// truncateText shortens s to maxLen characters, ending it with an ellipsis.
+func truncateText(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
+
+// suggestIndexChanges suggests changes to the chunking and search settings
+// of cfg from the files edited after misses. Files the misses found, with a
+// low score, lack context in their chunks. Files they did not find are either
+// diluted in large chunks or worded differently from the queries.
+func suggestIndexChanges(projectRoot string, cfg *config.Config, insights stats.Insights) []string {
+	var found, missed int
+	var missedTokens int64
+	for _, f := range insights.Files {
+		if f.Found > 0 {
+			found++
+			continue
+		}
+		missed++
+		if info, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(f.Path))); err == nil {
+			missedTokens += info.Size() / 4
+		}
+	}
+
+	suggestions := []string{}
+	if found > 0 && found >= missed && !cfg.Chunking.Contextual {
+		suggestions = append(suggestions, fmt.Sprintf("%d file(s) edited after misses were in the results with a low score: set chunking.contextual: true so chunks carry a summary of their file", found))
+	}
+	if missed > 0 {
+		if avg := missedTokens / int64(missed); avg > int64(4*cfg.Chunking.Size) {
+			suggestions = append(suggestions, fmt.Sprintf("%d file(s) edited after misses were not in the results and span many chunks (~%d tokens on average): try a smaller chunking.size, such as %d", missed, avg, max(cfg.Chunking.Size/2, 128)))
+		}
+		if !cfg.Search.Hybrid.Enabled {
+			suggestions = append(suggestions, fmt.Sprintf("%d file(s) edited after misses were not in the results: set search.hybrid.enabled: true to also match the words of queries", missed))
+		}
+	}
+	if len(insights.Files) > 0 {
+		suggestions = append(suggestions, "files edited after misses are re-indexed first by the next scan of grepai watch; rebuild the index after changing chunking settings")
+	}
+	return suggestions
+}
+
+// missFollowUps records, for a watch session, the files edited shortly after
+// search misses. A nil *missFollowUps records nothing.
+type missFollowUps struct {
+	rec *stats.InsightsRecorder
+
+	mu      sync.Mutex
+	modTime time.Time
+	misses  []stats.SearchSignal
+	linked  map[string]time.Time // query and path pairs recorded, with when
+}
+
+// newMissFollowUps returns the follow-up recorder of projectRoot, or nil when
+// insights are disabled.
+func newMissFollowUps(projectRoot string, cfg *config.Config) *missFollowUps {
+	rec := stats.NewInsightsRecorder(projectRoot, cfg.Insights.Enabled)
+	if rec == nil {
+		return nil
+	}
+	return &missFollowUps{rec: rec, linked: make(map[string]time.Time)}
+}
+
+// observe records the edit of the file of event after recent misses.
+func (m *missFollowUps) observe(event watcher.FileEvent) {
+	if m == nil || event.Type == watcher.EventDelete {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refresh()
+	now := time.Now()
+	since := now.Add(-stats.MissFollowUpWindow)
+	// Pairs linked before the window can only be linked again by a new
+	// miss, so they are forgotten.
+	for key, at := range m.linked {
+		if at.Before(since) {
+			delete(m.linked, key)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	for _, miss := range stats.RecentMisses(m.misses, since) {
+		key := miss.Query + "\x00" + event.Path
+		if _, ok := m.linked[key]; ok {
+			continue
+		}
+		m.linked[key] = now
+		if err := m.rec.RecordEdit(ctx, miss.Query, event.Path); err != nil {
+			log.Printf("Warning: failed to record edit of %s after a search miss: %v", event.Path, err)
+		}
+	}
+}
+
+// chain returns an event observer calling observe, then next.
+func (m *missFollowUps) chain(next watchEventObserver) watchEventObserver {
+	if m == nil {
+		return next
+	}
+	return func(projectRoot string, event watcher.FileEvent) {
+		m.observe(event)
+		if next != nil {
+			next(projectRoot, event)
+		}
+	}
+}
+
+// refresh reloads the misses when searches recorded new ones.
+func (m *missFollowUps) refresh() {
+	info, err := os.Stat(m.rec.Path())
+	if err != nil || info.ModTime().Equal(m.modTime) {
+		return
+	}
+	signals, err := stats.ReadSignals(m.rec.Path())
+	if err != nil {
+		return
+	}
+	m.modTime = info.ModTime()
+	m.misses = stats.RecentMisses(signals, time.Now().Add(-stats.MissFollowUpWindow))
+}
+
+// missPriorityFiles returns the files edited after search misses of
+// projectRoot within stats.PriorityMaxAge and not re-indexed since, which
+// its indexer re-indexes first.
+func missPriorityFiles(projectRoot string, cfg *config.Config) []string {
+	if !cfg.Insights.Enabled {
+		return nil
+	}
+	signals, err := stats.ReadSignals(stats.InsightsPath(projectRoot))
+	if err != nil {
+		return nil
+	}
+	return stats.PriorityFiles(signals, time.Now().Add(-stats.PriorityMaxAge))
+}
+
+// prioritizeMissFiles makes the next pass of idx re-index first the priority
+// files of projectRoot. The returned function, called with the stats of that
+// pass, records the files it re-indexed so they stop being priorities, and
+// clears the priority files of idx.
+func prioritizeMissFiles(idx *indexer.Indexer, projectRoot string, cfg *config.Config) func(*indexer.IndexStats) {
+	idx.SetPriorityFiles(missPriorityFiles(projectRoot, cfg))
+	return func(passStats *indexer.IndexStats) {
+		idx.SetPriorityFiles(nil)
+		if passStats == nil || len(passStats.Prioritized) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		rec := stats.NewInsightsRecorder(projectRoot, cfg.Insights.Enabled)
+		if err := rec.RecordReindex(ctx, passStats.Prioritized); err != nil {
+			log.Printf("Warning: failed to record re-indexed priority files of %s: %v", projectRoot, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/stats"
+	"github.com/yoanbernabeu/grepai/watcher"
+)
+
+func TestMissFollowUps(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	if newMissFollowUps(root, cfg) != nil {
+		t.Fatal("expected no follow-ups when insights are disabled")
+	}
+	cfg.Insights.Enabled = true
+
+	followUps := newMissFollowUps(root, cfg)
+	var seen []string
+	onEvent := followUps.chain(func(projectRoot string, event watcher.FileEvent) {
+		seen = append(seen, event.Path)
+	})
+
+	// Edits before any miss are not recorded.
+	onEvent(root, watcher.FileEvent{Type: watcher.EventModify, Path: "a.go"})
+	rec := stats.NewInsightsRecorder(root, true)
+	if err := rec.RecordMiss(context.Background(), "rate limiting", 0.2, []string{"b.go"}); err != nil {
+		t.Fatalf("RecordMiss failed: %v", err)
+	}
+	onEvent(root, watcher.FileEvent{Type: watcher.EventModify, Path: "api/limit.go"})
+	onEvent(root, watcher.FileEvent{Type: watcher.EventModify, Path: "api/limit.go"})
+	onEvent(root, watcher.FileEvent{Type: watcher.EventDelete, Path: "old.go"})
+
+	if len(seen) != 4 {
+		t.Errorf("expected every event to reach the next observer, got %v", seen)
+	}
+	signals, err := stats.ReadSignals(stats.InsightsPath(root))
+	if err != nil {
+		t.Fatalf("ReadSignals failed: %v", err)
+	}
+	var edits []string
+	for _, s := range signals {
+		if s.Type == stats.SignalEdit {
+			edits = append(edits, s.Query+" "+s.Path)
+		}
+	}
+	if len(edits) != 1 || edits[0] != "rate limiting api/limit.go" {
+		t.Errorf("expected one edit after the miss, got %v", edits)
+	}
+	if got := missPriorityFiles(root, cfg); len(got) != 1 || got[0] != "api/limit.go" {
+		t.Errorf("expected api/limit.go to be indexed first, got %v", got)
+	}
+
+	// Links older than the follow-up window are forgotten.
+	followUps.linked["stale\x00a.go"] = time.Now().Add(-2 * stats.MissFollowUpWindow)
+	onEvent(root, watcher.FileEvent{Type: watcher.EventModify, Path: "api/limit.go"})
+	if _, ok := followUps.linked["stale\x00a.go"]; ok || len(followUps.linked) != 1 {
+		t.Errorf("expected only the recent link kept, got %v", followUps.linked)
+	}
+
+	// Once re-indexed, a file stops being a priority.
+	idx := indexer.NewIndexer(root, nil, nil, nil, nil, time.Time{})
+	prioritized := prioritizeMissFiles(idx, root, cfg)
+	prioritized(&indexer.IndexStats{Prioritized: []string{"api/limit.go"}})
+	if got := missPriorityFiles(root, cfg); len(got) != 0 {
+		t.Errorf("expected no priority files after their re-index, got %v", got)
+	}
+}
+
+func TestSuggestIndexChanges(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "big.go"), []byte(strings.Repeat("x", 40_000)), 0644); err != nil {
+		t.Fatalf("failed to write big.go: %v", err)
+	}
+	cfg := config.DefaultConfig()
+
+	suggestions := suggestIndexChanges(root, cfg, stats.Insights{Files: []stats.FilePriority{
+		{Path: "big.go", Misses: 2},
+	}})
+	joined := strings.Join(suggestions, "\n")
+	if !strings.Contains(joined, "smaller chunking.size, such as 256") || !strings.Contains(joined, "search.hybrid.enabled") {
+		t.Errorf("expected chunk size and hybrid suggestions for a large file the misses did not find, got %q", joined)
+	}
+
+	suggestions = suggestIndexChanges(root, cfg, stats.Insights{Files: []stats.FilePriority{
+		{Path: "small.go", Misses: 1, Found: 1},
+	}})
+	if joined := strings.Join(suggestions, "\n"); !strings.Contains(joined, "chunking.contextual") {
+		t.Errorf("expected a contextual chunks suggestion for a file found with a low score, got %q", joined)
+	}
+
+	if suggestions := suggestIndexChanges(root, cfg, stats.Insights{}); len(suggestions) != 0 {
+		t.Errorf("expected no suggestions without signals, got %v", suggestions)
+	}
+}
+
+func TestRenderInsights(t *testing.T) {
+	out := renderInsights(insightsReport{
+		MinScore: 0.5,
+		Misses: []stats.QueryMiss{
+			{Query: "rate limiting", Count: 2, Confidence: 0.41, Edited: []string{"api/limit.go"}},
+			{Query: "session expiry", Count: 1, Confidence: 0.2},
+		},
+		Files:       []stats.FilePriority{{Path: "api/limit.go", Misses: 1}},
+		Suggestions: []string{"set search.hybrid.enabled: true"},
+	})
+	for _, want := range []string{"below 0.50", "rate limiting", "0.41  api/limit.go", "0.20  -", "Re-index priority", "- set search.hybrid.enabled: true"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
@@ -1,13 +1,20 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
 	"github.com/yoanbernabeu/grepai/mcp"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
 )
 
 var mcpServeCmd = &cobra.Command{
@@ -67,6 +74,7 @@ Configuration for Cursor with explicit path (recommended for Windows):
 
 func init() {
 	mcpServeCmd.Flags().String("workspace", "", "Workspace name for workspace-only mode (no local .grepai/ required)")
+	mcpServeCmd.Flags().StringArray("path-map", nil, "Map a host directory to its container path, as HOST=CONTAINER (repeatable; adds to mcp.path_mappings)")
 	rootCmd.AddCommand(mcpServeCmd)
 }
 
@@ -169,6 +177,118 @@ func runMCPServe(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
+	if projectRoot != "" {
+		srv.SetAutoIndexer(autoIndexProject)
+	}
+
+	mappings, err := mcpPathMappings(cmd, projectRoot)
+	if err != nil {
+		return err
+	}
+	srv.SetPathMappings(mappings)
 
 	return srv.Serve()
 }
+
+// mcpPathMappings returns the project's mcp.path_mappings followed by the
+// --path-map flags.
+func mcpPathMappings(cmd *cobra.Command, projectRoot string) ([]config.PathMapping, error) {
+	var mappings []config.PathMapping
+	if projectRoot != "" {
+		if cfg, err := config.Load(projectRoot); err == nil {
+			mappings = append(mappings, cfg.MCP.PathMappings...)
+		}
+	}
+	flags, _ := cmd.Flags().GetStringArray("path-map")
+	for _, flag := range flags {
+		host, container, ok := strings.Cut(flag, "=")
+		if !ok || host == "" || container == "" {
+			return nil, fmt.Errorf("invalid --path-map %q: expected HOST=CONTAINER", flag)
+		}
+		mappings = append(mappings, config.PathMapping{HostRoot: host, ContainerRoot: container})
+	}
+	return mappings, nil
+}
+
+// autoIndexProject builds a project's index and symbol index once, the way
+// the initial scan of 'grepai watch' does. mcp-serve runs it in the
+// background when mcp.auto_index is enabled and a search finds no index.
+func autoIndexProject(ctx context.Context, projectRoot string, onProgress func(mcp.IndexProgress)) error {
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	emb, err := initializeEmbedder(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer emb.Close()
+	docsEmb, err := initializeDocsEmbedder(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if docsEmb != nil {
+		defer docsEmb.Close()
+	}
+
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	if err := stampProjectIndexMetadata(ctx, st, projectRoot, cfg); err != nil {
+		return err
+	}
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Ignore, cfg.ExternalGitignore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	scanner := indexer.NewScanner(projectRoot, ignoreMatcher)
+	scanner.SetFollowSymlinks(cfg.Indexer.FollowSymlinks)
+	scanner.SetFileHandlers(buildFileHandlers(cfg))
+	scanner.SetContentRules(buildContentRules(cfg))
+	scanner.SetSparseCheckout(loadSparseCheckout(projectRoot, cfg))
+	chunker := indexer.NewChunker(cfg.Chunking.Size, cfg.Chunking.Overlap)
+	processorRegistry := buildFrameworkRegistry(cfg)
+	cost := newCostTracker(projectRoot, cfg)
+	idx := cost.newIndexer(projectRoot, cfg, st, emb, docsEmb, chunker, scanner, cfg.Watch.LastIndexTime, processorRegistry)
+	idx.SetContextualizer(newContextualizerFromConfig(cfg, projectRoot))
+	idx.SetBudget(buildIndexBudget(cfg))
+	idx.SetChangeDetection(cfg.Indexer.ChangeDetection)
+	idx.SetTodoStore(loadTodoStore(ctx, projectRoot))
+
+	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
+	if err := symbolStore.Load(ctx); err != nil {
+		log.Printf("Warning: failed to load symbol index for %s: %v", projectRoot, err)
+	}
+	defer symbolStore.Close()
+
+	// Stdout carries MCP messages, so progress is only logged.
+	stats, err := runInitialScan(ctx, idx, scanner, trace.NewRegexExtractor(), symbolStore, watchTracedLanguages(cfg), symbolScanIndexTime(cfg), true,
+		func(current, total int, _ string) {
+			onProgress(mcp.IndexProgress{Phase: "files", Current: current, Total: total})
+		},
+		func(info indexer.BatchProgressInfo) {
+			onProgress(mcp.IndexProgress{Phase: "embeddings", Current: info.CompletedChunks, Total: info.TotalChunks})
+		},
+		nil, nil, processorRegistry)
+	cost.scanComplete(nil)
+	if err != nil {
+		return err
+	}
+	saveBudgetExclusions(projectRoot, stats)
+
+	if stats.FilesIndexed > 0 || stats.ChunksCreated > 0 {
+		cfg.Watch.LastIndexTime = time.Now()
+		if err := cfg.Save(projectRoot); err != nil {
+			log.Printf("Warning: failed to save config: %v", err)
+		}
+	}
+	if err := st.Persist(ctx); err != nil {
+		return fmt.Errorf("failed to persist index: %w", err)
+	}
+	newIndexUpdateSignal(projectRoot, cfg).send()
+	return nil
+}
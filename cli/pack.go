@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// packCodeShare is the percentage of the budget of a pack that the first
+// pass over the chunks may use, keeping the rest for outlines and the call
+// graph. Chunks fill what those leave over.
+const packCodeShare = 70
+
+// Section headings of a pack.
+const (
+	packCodeHeading     = "## Relevant code\n\n"
+	packOutlinesHeading = "## Symbol outlines\n\n"
+	packGraphHeading    = "## Call graph\n\n"
+)
+
+var (
+	packBudget int
+	packOutput string
+	packLimit  int
+	packPath   string
+)
+
+// packOutline is the symbols of a file in a pack.
+type packOutline struct {
+	File    string
+	Symbols []trace.Symbol
+}
+
+// contextPack is the content selected for a task within a token budget.
+type contextPack struct {
+	Task     string
+	Project  string
+	Budget   int
+	Chunks   []store.SearchResult // in rank order
+	Omitted  int                  // retrieved chunks left out for the budget
+	Outlines []packOutline
+	Graph    []askSymbol
+}
+
+var packCmd = &cobra.Command{
+	Use:   "pack <task>",
+	Short: "Bundle the code relevant to a task into a Markdown context pack",
+	Long: `Bundle the code relevant to a task into a Markdown document for an LLM
+without access to grepai, such as a chat window.
+
+The task is searched in the index like 'grepai search'. The pack holds the
+best chunks, the outline of the symbols of their files, and the callers and
+callees of the symbols they are in, all within --budget tokens (estimated
+at 4 characters per token). Chunks get the larger part of the budget; the
+outlines and call graph need a symbol index, built by 'grepai watch'.
+
+Examples:
+  grepai pack "add rate limiting to the login endpoint"
+  grepai pack "fix the flaky session refresh test" --budget 4000 -o context.md
+  grepai pack "migrate the billing jobs" --path internal/billing | pbcopy`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPack,
+}
+
+func init() {
+	packCmd.Flags().IntVarP(&packBudget, "budget", "b", 8000, "Maximum size of the pack in tokens")
+	packCmd.Flags().StringVarP(&packOutput, "output", "o", "", "Write the pack to a file instead of stdout")
+	packCmd.Flags().IntVarP(&packLimit, "limit", "n", 30, "Number of chunks retrieved to choose from")
+	packCmd.Flags().StringVar(&packPath, "path", "", "Path prefix to filter the chunks")
+	rootCmd.AddCommand(packCmd)
+}
+
+func runPack(cmd *cobra.Command, args []string) error {
+	task := args[0]
+	ctx := context.Background()
+
+	if packBudget <= 0 {
+		return fmt.Errorf("budget must be > 0")
+	}
+	if packLimit <= 0 {
+		return fmt.Errorf("limit must be > 0")
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := config.CheckProjectIndexMetadata(projectRoot, cfg); err != nil {
+		return err
+	}
+	pathPrefix, err := search.NormalizeProjectPathPrefix(packPath, projectRoot)
+	if err != nil {
+		return fmt.Errorf("invalid --path value: %w", err)
+	}
+
+	emb, err := embedder.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	defer emb.Close()
+	docsEmb, err := embedder.NewDocsFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize docs embedder: %w", err)
+	}
+	if docsEmb != nil {
+		defer docsEmb.Close()
+	}
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	searcher := search.NewProjectSearcher(st, emb, docsEmb, cfg)
+	symbols := loadSearchSymbols(ctx, projectRoot)
+	if symbols != nil {
+		searcher.SetSymbolStore(symbols)
+	}
+	searcher.SetConfidenceObserver(search.NewMissObserver(projectRoot, cfg.Insights))
+	results, err := searcher.Search(ctx, task, packLimit, pathPrefix)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no indexed code matches the task: check that 'grepai watch' has indexed the project")
+	}
+
+	pack := assemblePack(ctx, task, filepath.Base(projectRoot), packBudget, results, symbols)
+	output := renderPack(pack)
+	if packOutput == "" {
+		fmt.Print(output)
+		return nil
+	}
+	if err := os.WriteFile(packOutput, []byte(output), 0644); err != nil {
+		return fmt.Errorf("failed to write pack: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d chunks, %d outlines and %d call graph entries (~%d tokens) to %s\n",
+		len(pack.Chunks), len(pack.Outlines), len(pack.Graph), embedder.EstimateTokens(output), packOutput)
+	return nil
+}
+
+// assemblePack selects the content of the pack of task from results, best
+// first, within budget tokens. Chunks first fill packCodeShare percent of
+// the budget. The call graph of the symbols enclosing them, then the
+// outlines of their files, are added while they fit, and the remaining
+// chunks fill what is left. symbols may be nil, leaving out outlines and
+// the call graph.
+func assemblePack(ctx context.Context, task, project string, budget int, results []store.SearchResult, symbols trace.SymbolStore) contextPack {
+	pack := contextPack{Task: task, Project: project, Budget: budget}
+	// The introduction, at its longest, and the section headings.
+	used := embedder.EstimateTokens(renderPack(contextPack{Task: task, Project: project, Budget: budget, Omitted: len(results)}) +
+		packCodeHeading + packOutlinesHeading + packGraphHeading)
+	fits := func(text string, limit int) bool {
+		tokens := embedder.EstimateTokens(text)
+		if used+tokens > limit {
+			return false
+		}
+		used += tokens
+		return true
+	}
+
+	included := make([]bool, len(results))
+	for i, r := range results {
+		included[i] = fits(renderPackChunk(r), budget*packCodeShare/100)
+	}
+
+	if symbols != nil {
+		var files []string
+		fileSymbols := make(map[string][]trace.Symbol)
+		var roots []trace.Symbol
+		seenRoots := make(map[string]bool)
+		for i, r := range results {
+			if !included[i] {
+				continue
+			}
+			file := r.Chunk.FilePath
+			if _, ok := fileSymbols[file]; !ok {
+				syms, _ := symbols.GetSymbolsForFile(ctx, file)
+				fileSymbols[file] = syms
+				files = append(files, file)
+			}
+			if sym := trace.EnclosingSymbol(fileSymbols[file], r.Chunk.StartLine, r.Chunk.EndLine); sym != nil {
+				key := sym.File + "\x00" + sym.Name
+				if !seenRoots[key] {
+					seenRoots[key] = true
+					roots = append(roots, *sym)
+				}
+			}
+		}
+
+		for _, sym := range roots {
+			entry := askSymbol{Symbol: sym}
+			entry.Callers, entry.Callees = symbolNeighbors(ctx, symbols, sym)
+			if len(entry.Callers)+len(entry.Callees) > 0 && fits(renderPackGraphEntry(entry), budget) {
+				pack.Graph = append(pack.Graph, entry)
+			}
+		}
+		for _, file := range files {
+			outline := packOutline{File: file, Symbols: outlineSymbols(fileSymbols[file])}
+			if len(outline.Symbols) > 0 && fits(renderPackOutline(outline), budget) {
+				pack.Outlines = append(pack.Outlines, outline)
+			}
+		}
+	}
+
+	for i, r := range results {
+		if !included[i] {
+			included[i] = fits(renderPackChunk(r), budget)
+		}
+		if included[i] {
+			pack.Chunks = append(pack.Chunks, r)
+		} else {
+			pack.Omitted++
+		}
+	}
+	return pack
+}
+
+// outlineSymbols returns the symbols of an outline: definitions, without
+// variables and constants.
+func outlineSymbols(symbols []trace.Symbol) []trace.Symbol {
+	var outline []trace.Symbol
+	for _, sym := range symbols {
+		if sym.Kind == trace.KindVariable || sym.Kind == trace.KindConstant {
+			continue
+		}
+		outline = append(outline, sym)
+	}
+	return outline
+}
+
+// renderPack renders pack as Markdown.
+func renderPack(pack contextPack) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Context: %s\n\n", pack.Task)
+	fmt.Fprintf(&sb, "Code of the %s project relevant to the task, selected by grepai within ~%d tokens.", pack.Project, pack.Budget)
+	if pack.Omitted > 0 {
+		fmt.Fprintf(&sb, " %d less relevant chunks were left out.", pack.Omitted)
+	}
+	sb.WriteString(" Line numbers are those of the files.\n\n")
+
+	if len(pack.Chunks) > 0 {
+		sb.WriteString(packCodeHeading)
+		for _, r := range pack.Chunks {
+			sb.WriteString(renderPackChunk(r))
+		}
+	}
+	if len(pack.Outlines) > 0 {
+		sb.WriteString(packOutlinesHeading)
+		for _, o := range pack.Outlines {
+			sb.WriteString(renderPackOutline(o))
+		}
+	}
+	if len(pack.Graph) > 0 {
+		sb.WriteString(packGraphHeading)
+		for _, entry := range pack.Graph {
+			sb.WriteString(renderPackGraphEntry(entry))
+		}
+	}
+	return sb.String()
+}
+
+func renderPackChunk(r store.SearchResult) string {
+	return "### " + fencedResult(r) + "\n"
+}
+
+func renderPackOutline(o packOutline) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s\n\n", o.File)
+	for _, sym := range o.Symbols {
+		sig := sym.Declaration()
+		fmt.Fprintf(&sb, "- `%s` (line %d)\n", sig, sym.Line)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func renderPackGraphEntry(entry askSymbol) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "- `%s` (%s:%d)\n", entry.Symbol.Name, entry.Symbol.File, entry.Symbol.Line)
+	if len(entry.Callers) > 0 {
+		fmt.Fprintf(&sb, "  - called by: %s\n", strings.Join(entry.Callers, ", "))
+	}
+	if len(entry.Callees) > 0 {
+		fmt.Fprintf(&sb, "  - calls: %s\n", strings.Join(entry.Callees, ", "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func packTestResults() []store.SearchResult {
+	body := strings.Repeat("\tlog.Println(\"serving\")\n", 20)
+	return []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "api/server.go", StartLine: 10, EndLine: 31, Content: "File: api/server.go\n\nfunc Serve(addr string) error {\n" + body + "}\n"}, Score: 0.9, SymbolName: "Serve"},
+		{Chunk: store.Chunk{FilePath: "api/routes.go", StartLine: 1, EndLine: 21, Content: "File: api/routes.go\n\n" + body}, Score: 0.8},
+		{Chunk: store.Chunk{FilePath: "docs/api.md", StartLine: 1, EndLine: 21, Content: "File: docs/api.md\n\n" + body}, Score: 0.7},
+	}
+}
+
+func packTestSymbols(t *testing.T) trace.SymbolStore {
+	t.Helper()
+	ctx := context.Background()
+	symbols := trace.NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	err := symbols.SaveFile(ctx, "api/server.go",
+		[]trace.Symbol{
+			{Name: "Serve", Kind: trace.KindFunction, File: "api/server.go", Line: 10, EndLine: 31, Signature: "func Serve(addr string) error {", Language: "go"},
+			{Name: "defaultAddr", Kind: trace.KindConstant, File: "api/server.go", Line: 5, Language: "go"},
+		},
+		[]trace.Reference{{SymbolName: "listen", File: "api/server.go", Line: 12, CallerName: "Serve", CallerFile: "api/server.go", CallerLine: 10}},
+	)
+	if err == nil {
+		err = symbols.SaveFile(ctx, "cmd/main.go",
+			[]trace.Symbol{{Name: "main", Kind: trace.KindFunction, File: "cmd/main.go", Line: 3, Language: "go"}},
+			[]trace.Reference{{SymbolName: "Serve", File: "cmd/main.go", Line: 4, CallerName: "main", CallerFile: "cmd/main.go", CallerLine: 3}},
+		)
+	}
+	if err != nil {
+		t.Fatalf("failed to save symbols: %v", err)
+	}
+	return symbols
+}
+
+func TestAssemblePack(t *testing.T) {
+	ctx := context.Background()
+	pack := assemblePack(ctx, "add rate limiting", "api", 8000, packTestResults(), packTestSymbols(t))
+	if len(pack.Chunks) != 3 || pack.Omitted != 0 {
+		t.Fatalf("expected all chunks within a large budget, got %d (%d omitted)", len(pack.Chunks), pack.Omitted)
+	}
+	if len(pack.Outlines) != 1 || len(pack.Outlines[0].Symbols) != 1 || pack.Outlines[0].Symbols[0].Name != "Serve" {
+		t.Errorf("expected the outline of api/server.go without its constant, got %+v", pack.Outlines)
+	}
+	if len(pack.Graph) != 1 || len(pack.Graph[0].Callers) != 1 || len(pack.Graph[0].Callees) != 1 {
+		t.Errorf("expected the callers and callees of Serve, got %+v", pack.Graph)
+	}
+
+	out := renderPack(pack)
+	for _, want := range []string{
+		"# Context: add rate limiting\n",
+		"### api/server.go:10-31 (Serve)\n\n```go\nfunc Serve(addr string) error {\n",
+		"```markdown\n",
+		"## Symbol outlines\n\n### api/server.go\n\n- `func Serve(addr string) error` (line 10)\n",
+		"## Call graph\n\n- `Serve` (api/server.go:10)\n  - called by: main (cmd/main.go:4)\n  - calls: listen (api/server.go:12)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "File: api/server.go") {
+		t.Errorf("expected chunk headers to be left out, got:\n%s", out)
+	}
+}
+
+func TestAssemblePack_Budget(t *testing.T) {
+	ctx := context.Background()
+	results := packTestResults()
+	chunkTokens := embedder.EstimateTokens(renderPackChunk(results[0]))
+	budget := chunkTokens*2 + 100
+
+	pack := assemblePack(ctx, "add rate limiting", "api", budget, results, packTestSymbols(t))
+	if len(pack.Chunks) == 0 || pack.Omitted == 0 {
+		t.Fatalf("expected some chunks to be left out, got %d (%d omitted)", len(pack.Chunks), pack.Omitted)
+	}
+	if pack.Chunks[0].Chunk.FilePath != "api/server.go" {
+		t.Errorf("expected the best chunk first, got %s", pack.Chunks[0].Chunk.FilePath)
+	}
+	out := renderPack(pack)
+	if tokens := embedder.EstimateTokens(out); tokens > budget {
+		t.Errorf("expected the pack within %d tokens, got %d", budget, tokens)
+	}
+	if !strings.Contains(out, "less relevant chunks were left out") {
+		t.Errorf("expected the pack to say chunks were left out, got:\n%s", out)
+	}
+
+	// Without a symbol index, the pack is chunks only.
+	pack = assemblePack(ctx, "add rate limiting", "api", 8000, results, nil)
+	if len(pack.Chunks) != 3 || pack.Outlines != nil || pack.Graph != nil {
+		t.Errorf("expected chunks only without symbols, got %+v", pack)
+	}
+}
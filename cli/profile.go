@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"runtime/trace"
+
+	"github.com/spf13/cobra"
+)
+
+// pprofEnv names a directory where watch, search and global index write
+// CPU, heap and execution trace files when no profiling flag is set. Unlike
+// the flags, it reaches processes started by other tools, such as
+// background watchers.
+const pprofEnv = "GREPAI_PPROF"
+
+// profileOptions holds the profiling flags of the running command.
+type profileOptions struct {
+	cpu    string
+	mem    string
+	trace  string
+	listen string
+}
+
+var profiling profileOptions
+
+// addProfileFlags registers the profiling flags on cmd.
+func addProfileFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&profiling.cpu, "cpuprofile", "", "Write a CPU profile to this file")
+	cmd.Flags().StringVar(&profiling.mem, "memprofile", "", "Write a heap profile to this file on exit")
+	cmd.Flags().StringVar(&profiling.trace, "trace", "", "Write an execution trace to this file")
+}
+
+// set reports whether any profiling flag is set.
+func (p profileOptions) set() bool {
+	return p.cpu != "" || p.mem != "" || p.trace != "" || p.listen != ""
+}
+
+// args returns the flags reproducing p for a background child, with
+// absolute paths since the child may not share the working directory.
+func (p profileOptions) args() []string {
+	var args []string
+	for _, f := range []struct{ name, value string }{
+		{"--cpuprofile", p.cpu},
+		{"--memprofile", p.mem},
+		{"--trace", p.trace},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(f.value); err == nil {
+			f.value = abs
+		}
+		args = append(args, f.name, f.value)
+	}
+	if p.listen != "" {
+		args = append(args, "--pprof-listen", p.listen)
+	}
+	return args
+}
+
+// fromEnv fills the file paths from GREPAI_PPROF when no flag is set. Files
+// are named after name and the process ID so concurrent runs do not clobber
+// each other.
+func (p profileOptions) fromEnv(name string) profileOptions {
+	dir := os.Getenv(pprofEnv)
+	if dir == "" || p.cpu != "" || p.mem != "" || p.trace != "" {
+		return p
+	}
+	prefix := filepath.Join(dir, fmt.Sprintf("grepai-%s-%d", name, os.Getpid()))
+	p.cpu = prefix + ".cpu.pprof"
+	p.mem = prefix + ".mem.pprof"
+	p.trace = prefix + ".trace"
+	return p
+}
+
+// startProfiling starts the profiles requested by the profiling flags or
+// GREPAI_PPROF for the command name. The returned function stops them and
+// writes the heap profile; call it when the command ends.
+func startProfiling(name string) (func(), error) {
+	p := profiling.fromEnv(name)
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if p.dirNeeded() {
+		if err := os.MkdirAll(os.Getenv(pprofEnv), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s directory: %w", pprofEnv, err)
+		}
+	}
+
+	if p.cpu != "" {
+		f, err := os.Create(p.cpu)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := runtimepprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		stops = append(stops, func() {
+			runtimepprof.StopCPUProfile()
+			f.Close()
+			log.Printf("CPU profile written to %s", p.cpu)
+		})
+	}
+
+	if p.trace != "" {
+		f, err := os.Create(p.trace)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to create execution trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			stop()
+			return nil, fmt.Errorf("failed to start execution trace: %w", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			f.Close()
+			log.Printf("Execution trace written to %s", p.trace)
+		})
+	}
+
+	if p.mem != "" {
+		stops = append(stops, func() {
+			if err := writeHeapProfile(p.mem); err != nil {
+				log.Printf("Warning: %v", err)
+				return
+			}
+			log.Printf("Heap profile written to %s", p.mem)
+		})
+	}
+
+	if p.listen != "" {
+		srv, err := startPprofServer(p.listen)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		stops = append(stops, func() { srv.Close() })
+	}
+
+	return stop, nil
+}
+
+// dirNeeded reports whether p writes into the GREPAI_PPROF directory.
+func (p profileOptions) dirNeeded() bool {
+	dir := os.Getenv(pprofEnv)
+	return dir != "" && filepath.Dir(p.cpu) == filepath.Clean(dir)
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create heap profile: %w", err)
+	}
+	defer f.Close()
+	runtime.GC()
+	if err := runtimepprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write heap profile: %w", err)
+	}
+	return nil
+}
+
+// startPprofServer serves the net/http/pprof handlers on addr. The returned
+// server's Addr is the address actually listened on.
+func startPprofServer(addr string) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for pprof on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	srv := &http.Server{Addr: ln.Addr().String(), Handler: mux}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("pprof server stopped: %v", err)
+		}
+	}()
+	log.Printf("pprof endpoint listening on http://%s/debug/pprof/", srv.Addr)
+	return srv, nil
+}
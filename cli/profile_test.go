@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStartProfiling_WritesFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := profiling
+	t.Cleanup(func() { profiling = old })
+	profiling = profileOptions{
+		cpu:   filepath.Join(dir, "cpu.pprof"),
+		mem:   filepath.Join(dir, "mem.pprof"),
+		trace: filepath.Join(dir, "run.trace"),
+	}
+
+	stop, err := startProfiling("search")
+	if err != nil {
+		t.Fatalf("failed to start profiling: %v", err)
+	}
+	stop()
+
+	for _, name := range []string{"cpu.pprof", "mem.pprof", "run.trace"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", name, err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("expected %s to be non-empty", name)
+		}
+	}
+}
+
+func TestStartProfiling_FromEnv(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+	t.Setenv(pprofEnv, dir)
+	old := profiling
+	t.Cleanup(func() { profiling = old })
+	profiling = profileOptions{}
+
+	stop, err := startProfiling("watch")
+	if err != nil {
+		t.Fatalf("failed to start profiling: %v", err)
+	}
+	stop()
+
+	prefix := filepath.Join(dir, fmt.Sprintf("grepai-watch-%d", os.Getpid()))
+	for _, suffix := range []string{".cpu.pprof", ".mem.pprof", ".trace"} {
+		if _, err := os.Stat(prefix + suffix); err != nil {
+			t.Errorf("expected %s to be written: %v", prefix+suffix, err)
+		}
+	}
+}
+
+func TestStartPprofServer(t *testing.T) {
+	srv, err := startPprofServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start pprof server: %v", err)
+	}
+	defer srv.Close()
+
+	resp, err := http.Get("http://" + srv.Addr + "/debug/pprof/goroutine?debug=1")
+	if err != nil {
+		t.Fatalf("failed to query pprof: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || len(body) == 0 {
+		t.Errorf("expected a goroutine profile, got status %d", resp.StatusCode)
+	}
+}
+
+func TestProfileOptions_Args(t *testing.T) {
+	p := profileOptions{cpu: "cpu.pprof", listen: "localhost:6060"}
+	args := p.args()
+	if len(args) != 4 || args[0] != "--cpuprofile" || !filepath.IsAbs(args[1]) || args[2] != "--pprof-listen" || args[3] != "localhost:6060" {
+		t.Errorf("unexpected args %v", args)
+	}
+	if len((profileOptions{}).args()) != 0 {
+		t.Error("expected no args without profiling flags")
+	}
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/git"
+)
+
+var (
+	projectsJSON     bool
+	projectsMaxDepth int
+)
+
+// projectInfo is the overview of one project found by 'grepai projects'.
+type projectInfo struct {
+	Path        string    `json:"path"`
+	Backend     string    `json:"backend"`
+	IndexBytes  int64     `json:"index_bytes"` // size of the local index files; 0 for remote backends
+	LastUpdated time.Time `json:"last_updated,omitzero"`
+	Watching    bool      `json:"watching"`
+	WatcherPID  int       `json:"watcher_pid,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects [dir]",
+	Short: "List the grepai projects found under a directory",
+	Long: `List the grepai projects (directories containing .grepai/config.yaml) found
+under a directory, the current directory by default.
+
+For each project, shows its storage backend, the size of its local index,
+when the index was last updated, and whether a watcher is running.
+
+Examples:
+  grepai projects
+  grepai projects ~/src --max-depth 2
+  grepai projects ~/src --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runProjects,
+}
+
+func init() {
+	projectsCmd.Flags().BoolVar(&projectsJSON, "json", false, "Output projects in JSON format")
+	projectsCmd.Flags().IntVar(&projectsMaxDepth, "max-depth", 4, "Directory levels below dir searched for projects")
+	rootCmd.AddCommand(projectsCmd)
+}
+
+func runProjects(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	roots, err := findProjectRoots(root, projectsMaxDepth)
+	if err != nil {
+		return err
+	}
+	projects := make([]projectInfo, 0, len(roots))
+	for _, projectRoot := range roots {
+		projects = append(projects, describeProject(projectRoot))
+	}
+
+	if projectsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(projects)
+	}
+	if len(projects) == 0 {
+		fmt.Printf("No grepai projects found under %s\n", root)
+		return nil
+	}
+	fmt.Print(renderProjects(root, projects, time.Now()))
+	return nil
+}
+
+// findProjectRoots returns the directories under root, at most maxDepth
+// levels deep, that contain a grepai config, sorted by path. Hidden
+// directories, node_modules and vendor are not searched.
+func findProjectRoots(root string, maxDepth int) ([]string, error) {
+	rootDepth := strings.Count(filepath.Clean(root), string(filepath.Separator))
+	var roots []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+			return fs.SkipDir
+		}
+		if _, err := os.Stat(config.GetConfigPath(path)); err == nil {
+			roots = append(roots, path)
+		}
+		if strings.Count(path, string(filepath.Separator))-rootDepth >= maxDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+	sort.Strings(roots)
+	return roots, nil
+}
+
+// describeProject gathers the overview of a project without opening its
+// index, so listing many projects stays fast.
+func describeProject(projectRoot string) projectInfo {
+	info := projectInfo{Path: projectRoot}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Backend = cfg.Store.Backend
+
+	// Remote backends have no local index files; the signal file written
+	// after each index update still tells when the index last changed.
+	indexFiles := []string{config.GetIndexPath(projectRoot), config.GetSymbolIndexPath(projectRoot), config.GetRPGIndexPath(projectRoot)}
+	for _, path := range append(indexFiles, config.GetIndexSignalPath(projectRoot)) {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if cfg.Store.Backend == "gob" && path != config.GetIndexSignalPath(projectRoot) {
+			info.IndexBytes += fi.Size()
+		}
+		if fi.ModTime().After(info.LastUpdated) {
+			info.LastUpdated = fi.ModTime()
+		}
+	}
+
+	var worktreeID string
+	if gitInfo, err := git.Detect(projectRoot); err == nil {
+		worktreeID = gitInfo.WorktreeID
+	}
+	watch := resolveWatcherRuntimeStatusFor(projectRoot, worktreeID)
+	info.Watching = watch.running
+	info.WatcherPID = watch.pid
+	return info
+}
+
+func renderProjects(root string, projects []projectInfo, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-40s %-9s %10s  %-16s %s\n", "PROJECT", "BACKEND", "INDEX", "UPDATED", "WATCHER"))
+	for _, p := range projects {
+		name := p.Path
+		if rel, err := filepath.Rel(root, p.Path); err == nil {
+			name = rel
+		}
+		if p.Error != "" {
+			sb.WriteString(fmt.Sprintf("%-40s error: %s\n", truncatePath(name, 40), p.Error))
+			continue
+		}
+		size := "remote"
+		if p.Backend == "gob" {
+			size = formatBytes(p.IndexBytes)
+		}
+		updated := "never"
+		if !p.LastUpdated.IsZero() {
+			updated = formatAge(now.Sub(p.LastUpdated)) + " ago"
+		}
+		watcher := "stopped"
+		if p.Watching {
+			watcher = fmt.Sprintf("running (PID %d)", p.WatcherPID)
+		}
+		sb.WriteString(fmt.Sprintf("%-40s %-9s %10s  %-16s %s\n", truncatePath(name, 40), p.Backend, size, updated, watcher))
+	}
+	return sb.String()
+}
+
+// formatAge formats a duration in its largest whole unit, e.g. "3h" or "12d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
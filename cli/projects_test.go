@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func writeTestProject(t *testing.T, root string) {
+	t.Helper()
+	if err := config.DefaultConfig().Save(root); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindProjectRoots(t *testing.T) {
+	root := t.TempDir()
+	writeTestProject(t, filepath.Join(root, "api"))
+	writeTestProject(t, filepath.Join(root, "api", "services", "billing"))
+	writeTestProject(t, filepath.Join(root, "node_modules", "dep"))
+	writeTestProject(t, filepath.Join(root, "a", "b", "c", "too-deep"))
+
+	roots, err := findProjectRoots(root, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(root, "api"), filepath.Join(root, "api", "services", "billing")}
+	if !reflect.DeepEqual(roots, want) {
+		t.Errorf("findProjectRoots = %v, want %v", roots, want)
+	}
+}
+
+func TestDescribeProject(t *testing.T) {
+	defer setTestHomeDirCLI(t, t.TempDir())()
+	projectRoot := t.TempDir()
+	writeTestProject(t, projectRoot)
+	if err := os.WriteFile(config.GetIndexPath(projectRoot), make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info := describeProject(projectRoot)
+	if info.Error != "" {
+		t.Fatalf("unexpected error: %s", info.Error)
+	}
+	if info.Backend != "gob" || info.IndexBytes != 2048 {
+		t.Errorf("describeProject = %+v, want a 2048-byte gob index", info)
+	}
+	if info.LastUpdated.IsZero() || info.Watching {
+		t.Errorf("describeProject = %+v, want an update time and no watcher", info)
+	}
+}
+
+func TestRenderProjects(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	out := renderProjects("/src", []projectInfo{
+		{Path: "/src/api", Backend: "gob", IndexBytes: 2048, LastUpdated: now.Add(-3 * time.Hour), Watching: true, WatcherPID: 42},
+		{Path: "/src/web", Backend: "qdrant"},
+		{Path: "/src/broken", Error: "invalid config"},
+	}, now)
+
+	for _, want := range []string{"api", "2.0 KB", "3h ago", "running (PID 42)", "web", "remote", "never", "stopped", "broken", "error: invalid config"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/mcp"
+	"github.com/yoanbernabeu/grepai/remote"
+	gstats "github.com/yoanbernabeu/grepai/stats"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// remoteFlagUsage is the help of the --remote flag of search and trace.
+var remoteFlagUsage = fmt.Sprintf("URL of a 'grepai serve --listen' server to query instead of the local index (default $%s; token in $%s)", remote.URLEnv, remote.TokenEnv)
+
+// remoteURL returns the server URL from the --remote flag, or from the
+// environment when the flag is not set.
+func remoteURL(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return os.Getenv(remote.URLEnv)
+}
+
+// newRemoteClient returns a client for url, reading the token from the
+// environment.
+func newRemoteClient(url string) (*remote.Client, error) {
+	token := os.Getenv(remote.TokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("--remote requires a token in %s", remote.TokenEnv)
+	}
+	return remote.NewClient(url, token), nil
+}
+
+// runRemoteSearch runs grepai search against a remote server.
+func runRemoteSearch(ctx context.Context, url, query string) error {
+	switch {
+	case searchGlobal:
+		return fmt.Errorf("--global is not supported with --remote")
+	case searchRefine:
+		return fmt.Errorf("--refine-last is not supported with --remote")
+	case searchBlame:
+		return fmt.Errorf("--blame is not supported with --remote")
+	}
+	client, err := newRemoteClient(url)
+	if err != nil {
+		return err
+	}
+
+	args := map[string]any{
+		"query":   query,
+		"limit":   searchLimit,
+		"compact": false,
+		"format":  "json",
+	}
+	for key, value := range map[string]string{
+		"path":      searchPath,
+		"workspace": searchWorkspace,
+		"projects":  strings.Join(searchProjects, ","),
+		"feature":   searchFeature,
+		"scope":     searchScope,
+	} {
+		if value != "" {
+			args[key] = value
+		}
+	}
+
+	output, err := client.CallTool(ctx, "grepai_search", args)
+	if err != nil {
+		if searchJSON {
+			return outputSearchErrorJSON(err)
+		}
+		if searchTOON {
+			return outputSearchErrorTOON(err)
+		}
+		return fmt.Errorf("remote search failed: %w", err)
+	}
+	var remoteResults []mcp.SearchResult
+	if err := json.Unmarshal([]byte(output), &remoteResults); err != nil {
+		return fmt.Errorf("invalid search results from remote server: %w", err)
+	}
+
+	results := make([]store.SearchResult, len(remoteResults))
+	enrichments := make([]rpgEnrichment, len(remoteResults))
+	for i, r := range remoteResults {
+		results[i] = store.SearchResult{
+			Chunk: store.Chunk{
+				ID:        r.ID,
+				FilePath:  r.FilePath,
+				StartLine: r.StartLine,
+				EndLine:   r.EndLine,
+				Content:   r.Content,
+			},
+			Score: r.Score,
+		}
+		enrichments[i] = rpgEnrichment{
+			FeaturePath: r.FeaturePath,
+			SymbolName:  r.SymbolName,
+			SymbolKind:  r.SymbolKind,
+			Permalink:   r.Permalink,
+			Owners:      r.Owners,
+		}
+	}
+
+	projectRoot, _ := config.FindProjectRoot()
+	return outputSearchResults(projectRoot, fmt.Sprintf("Found %d results for: %q on %s", len(results), query, url), results, enrichments)
+}
+
+// runRemoteTrace runs a trace subcommand against a remote server through
+// the matching MCP tool.
+func runRemoteTrace(ctx context.Context, url, tool, symbol string, view traceViewKind) error {
+	if traceBlame {
+		return fmt.Errorf("--blame is not supported with --remote")
+	}
+	client, err := newRemoteClient(url)
+	if err != nil {
+		return err
+	}
+
+	args := map[string]any{
+		"symbol":  symbol,
+		"compact": false,
+		"format":  "json",
+	}
+	if traceWorkspace != "" {
+		args["workspace"] = traceWorkspace
+	}
+	if traceProject != "" {
+		args["project"] = traceProject
+	}
+	if traceScope != "" {
+		args["scope"] = traceScope
+	}
+	commandType := gstats.TraceGraph
+	switch view {
+	case traceViewGraph:
+		args["depth"] = traceDepth
+	default:
+		args["limit"] = traceLimit
+		args["offset"] = traceOffset
+		args["sort"] = traceSort
+		commandType = gstats.TraceCallers
+		if view == traceViewCallees {
+			commandType = gstats.TraceCallees
+		}
+	}
+
+	output, err := client.CallTool(ctx, tool, args)
+	if err != nil {
+		return fmt.Errorf("remote trace failed: %w", err)
+	}
+	var result trace.TraceResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return fmt.Errorf("invalid trace result from remote server: %w", err)
+	}
+
+	resultCount := len(result.Callers) + len(result.Callees)
+	if result.Graph != nil {
+		resultCount = len(result.Graph.Nodes)
+	}
+	projectRoot, _ := config.FindProjectRoot()
+	return outputAndRecord(result, view, projectRoot, commandType, resultCount, nil)
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -28,7 +29,10 @@ func SetVersion(v string) {
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	recordCommandTelemetry(cmd, start, err)
+	return err
 }
 
 // GetRootCmd returns the root command for documentation generation
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+var (
+	rpgExportFormat  string
+	rpgExportOutput  string
+	rpgExportFeature string
+	rpgExportChunks  bool
+)
+
+var rpgCmd = &cobra.Command{
+	Use:   "rpg",
+	Short: "Inspect and maintain the Repository Planning Graph",
+	Long: `Commands for working with the Repository Planning Graph (RPG), the
+feature hierarchy built on top of the symbol index when rpg.enabled is set.`,
+}
+
+var rpgExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the RPG as a DOT, Mermaid or JSON diagram",
+	Long: `Render the dual-layer RPG graph: feature areas, categories and subcategories
+become nested clusters, and the files and symbols implementing them are drawn
+inside with their contains, invokes, imports and semantic_sim edges.
+
+Examples:
+  grepai rpg export > architecture.dot
+  grepai rpg export --format mermaid --feature auth -o docs/auth.mmd
+  grepai rpg export --format json --chunks`,
+	Args: cobra.NoArgs,
+	RunE: runRPGExport,
+}
+
+var rpgRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild the RPG from scratch",
+	Long: `Discard the current RPG and rebuild it from the vector and symbol indexes.
+
+Use this when the incrementally maintained graph has drifted, for example after
+a large refactor, or to update the graph under the manual rebuild policy
+(rpg.rebuild: manual). A background watcher using another policy keeps its own
+copy of the graph; restart it instead, as the watcher rebuilds on startup.
+
+Examples:
+  grepai rpg rebuild`,
+	Args: cobra.NoArgs,
+	RunE: runRPGRebuild,
+}
+
+func init() {
+	rpgExportCmd.Flags().StringVarP(&rpgExportFormat, "format", "f", rpg.ExportDOT, "Output format: dot, mermaid or json")
+	rpgExportCmd.Flags().StringVarP(&rpgExportOutput, "output", "o", "", "Write to file instead of stdout")
+	rpgExportCmd.Flags().StringVar(&rpgExportFeature, "feature", "", "Only export the subtree under this feature path (e.g. auth/session)")
+	rpgExportCmd.Flags().BoolVar(&rpgExportChunks, "chunks", false, "Include chunk nodes")
+
+	rpgCmd.AddCommand(rpgExportCmd)
+	rpgCmd.AddCommand(rpgRebuildCmd)
+	rootCmd.AddCommand(rpgCmd)
+}
+
+func runRPGExport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	switch rpgExportFormat {
+	case rpg.ExportDOT, rpg.ExportMermaid, rpg.ExportJSON:
+	default:
+		return fmt.Errorf("invalid --format %q: must be dot, mermaid or json", rpgExportFormat)
+	}
+
+	graph, err := loadRPGGraph(ctx)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if rpgExportOutput != "" {
+		f, err := os.Create(rpgExportOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	opts := rpg.ExportOptions{Feature: rpgExportFeature, IncludeChunks: rpgExportChunks}
+	if err := rpg.Export(w, graph, rpgExportFormat, opts); err != nil {
+		return fmt.Errorf("failed to export RPG: %w", err)
+	}
+	if rpgExportOutput != "" {
+		fmt.Fprintf(os.Stderr, "RPG exported to %s\n", rpgExportOutput)
+	}
+	return nil
+}
+
+// loadRPGGraph loads the RPG of the current project, failing when RPG is
+// disabled or has not been built yet.
+func loadRPGGraph(ctx context.Context) (*rpg.Graph, error) {
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !cfg.RPG.Enabled {
+		return nil, fmt.Errorf("RPG is disabled; set rpg.enabled: true in .grepai/config.yaml and run 'grepai watch'")
+	}
+
+	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(projectRoot))
+	if err := rpgStore.Load(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load RPG index: %w", err)
+	}
+	// Not closed: Close persists the graph, which a read-only command must not
+	// race with a running watcher.
+
+	graph := rpgStore.GetGraph()
+	if graph == nil || len(graph.Nodes) == 0 {
+		return nil, fmt.Errorf("RPG index is empty; run 'grepai watch' to build it")
+	}
+	return graph, nil
+}
+
+func runRPGRebuild(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !cfg.RPG.Enabled {
+		return fmt.Errorf("RPG is disabled; set rpg.enabled: true in .grepai/config.yaml")
+	}
+	if cfg.RPG.Rebuild != config.RPGRebuildManual {
+		if status := resolveWatcherRuntimeStatus(projectRoot); status.running {
+			return fmt.Errorf("watcher is running in background (PID %d) and would overwrite the rebuilt graph\nRestart it with 'grepai watch --stop' and 'grepai watch --background', which rebuilds the RPG on startup", status.pid)
+		}
+	}
+
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	docs, err := st.ListDocuments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	if len(docs) == 0 {
+		return fmt.Errorf("index is empty; run 'grepai watch' to index the project first")
+	}
+
+	// The symbol store is only read, so it is not closed (Close persists).
+	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
+	if err := symbolStore.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load symbol index: %w", err)
+	}
+
+	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(projectRoot))
+	encoder := newRPGEncoderFromConfig(rpgStore, cfg.RPG, projectRoot)
+
+	fmt.Printf("Rebuilding RPG for %d files...\n", len(docs))
+	start := time.Now()
+	if err := encoder.BuildFull(ctx, symbolStore, st, nil); err != nil {
+		return fmt.Errorf("failed to rebuild RPG: %w", err)
+	}
+
+	rpgStats := encoder.Stats()
+	fmt.Printf("RPG rebuilt in %s: %d nodes, %d edges\n", time.Since(start).Round(time.Millisecond), rpgStats.TotalNodes, rpgStats.TotalEdges)
+	return nil
+}
+
+// newContextualizerFromConfig returns the contextualizer of chunks of the
+// project at projectRoot, or nil when chunking.contextual is off or no LLM is
+// configured to summarize files.
+func newContextualizerFromConfig(cfg *config.Config, projectRoot string) *indexer.Contextualizer {
+	if !cfg.Chunking.Contextual {
+		return nil
+	}
+	rpgCfg := cfg.RPG
+	if rpgCfg.LLMEndpoint == "" || rpgCfg.LLMModel == "" {
+		log.Printf("Warning: chunking.contextual is on but rpg.llm_endpoint or rpg.llm_model is empty for %s, indexing chunks without context", projectRoot)
+		return nil
+	}
+	summarizer := rpg.NewLLMExtractor(rpg.LLMExtractorConfig{
+		Provider: rpgCfg.LLMProvider,
+		Model:    rpgCfg.LLMModel,
+		Endpoint: rpgCfg.LLMEndpoint,
+		APIKey:   rpgCfg.LLMAPIKey,
+		Timeout:  time.Duration(rpgCfg.LLMTimeoutMs) * time.Millisecond,
+	})
+	return indexer.NewContextualizer(summarizer, config.GetSummaryCachePath(projectRoot))
+}
+
+// newRPGEncoderFromConfig creates the RPG encoder for a project, falling back
+// to the local feature extractor when the LLM settings are incomplete.
+func newRPGEncoderFromConfig(rpgStore rpg.RPGStore, rpgCfg config.RPGConfig, projectRoot string) *rpg.RPGEncoder {
+	var featureExtractor rpg.FeatureExtractor
+	switch rpgCfg.FeatureMode {
+	case "llm", "hybrid":
+		if rpgCfg.LLMEndpoint == "" || rpgCfg.LLMModel == "" {
+			log.Printf("Warning: RPG feature_mode=%q but llm_endpoint or llm_model is empty for %s, falling back to local extractor", rpgCfg.FeatureMode, projectRoot)
+			featureExtractor = rpg.NewLocalExtractor()
+		} else {
+			featureExtractor = rpg.NewLLMExtractor(rpg.LLMExtractorConfig{
+				Provider: rpgCfg.LLMProvider,
+				Model:    rpgCfg.LLMModel,
+				Endpoint: rpgCfg.LLMEndpoint,
+				APIKey:   rpgCfg.LLMAPIKey,
+				Timeout:  time.Duration(rpgCfg.LLMTimeoutMs) * time.Millisecond,
+			})
+		}
+	default:
+		featureExtractor = rpg.NewLocalExtractor()
+	}
+
+	return rpg.NewRPGEncoder(rpgStore, featureExtractor, projectRoot, rpg.RPGEncoderConfig{
+		DriftThreshold:       rpgCfg.DriftThreshold,
+		MaxTraversalDepth:    rpgCfg.MaxTraversalDepth,
+		FeatureGroupStrategy: rpgCfg.FeatureGroupStrategy,
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"strings"
 	"time"
@@ -12,11 +13,14 @@ import (
 	"github.com/alpkeskin/gotoon"
 	"github.com/spf13/cobra"
 	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/coverage"
 	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/git"
 	"github.com/yoanbernabeu/grepai/rpg"
 	"github.com/yoanbernabeu/grepai/search"
 	"github.com/yoanbernabeu/grepai/stats"
 	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
 )
 
 var (
@@ -27,6 +31,16 @@ var (
 	searchWorkspace string
 	searchProjects  []string
 	searchPath      string
+	searchFeature   string
+	searchBlame     bool
+	searchRefine    bool
+	searchGlobal    bool
+	searchRemote    string
+	searchUncovered bool
+	searchScope     string
+	searchFenced    bool
+	searchCopy      int
+	searchUI        bool
 )
 
 // SearchResultJSON is a lightweight struct for JSON output (excludes vector, hash, updated_at)
@@ -38,6 +52,7 @@ type SearchResultJSON struct {
 	Content     string  `json:"content"`
 	FeaturePath string  `json:"feature_path,omitempty"`
 	SymbolName  string  `json:"symbol_name,omitempty"`
+	SymbolKind  string  `json:"symbol_kind,omitempty"`
 }
 
 // SearchResultCompactJSON is a minimal struct for compact JSON output (no content field)
@@ -48,6 +63,28 @@ type SearchResultCompactJSON struct {
 	Score       float32 `json:"score"`
 	FeaturePath string  `json:"feature_path,omitempty"`
 	SymbolName  string  `json:"symbol_name,omitempty"`
+	SymbolKind  string  `json:"symbol_kind,omitempty"`
+}
+
+// annotatedSearchResultJSON adds the permalink, owners, last change and
+// coverage to a JSON result. They are kept out of SearchResultJSON because
+// TOON encodes every field as a column, even when it is empty.
+type annotatedSearchResultJSON struct {
+	SearchResultJSON
+	Permalink  string             `json:"permalink,omitempty"`
+	Owners     string             `json:"owners,omitempty"`
+	LastChange *git.LastChange    `json:"last_change,omitempty"`
+	Coverage   *coverage.Coverage `json:"coverage,omitempty"`
+}
+
+// annotatedSearchResultCompactJSON adds the permalink, owners, last change
+// and coverage to a compact JSON result.
+type annotatedSearchResultCompactJSON struct {
+	SearchResultCompactJSON
+	Permalink  string             `json:"permalink,omitempty"`
+	Owners     string             `json:"owners,omitempty"`
+	LastChange *git.LastChange    `json:"last_change,omitempty"`
+	Coverage   *coverage.Coverage `json:"coverage,omitempty"`
 }
 
 var searchCmd = &cobra.Command{
@@ -58,8 +95,54 @@ var searchCmd = &cobra.Command{
 The search will:
 - Vectorize your query using the configured embedding provider
 - Calculate cosine similarity against indexed code chunks
-- Return the most relevant results with file path, line numbers, and score`,
-	Args: cobra.ExactArgs(1),
+- Return the most relevant results with file path, line numbers, and score
+
+With --refine-last, the query re-ranks the results of the previous search in
+the project instead of searching the whole index, so a broad search can be
+narrowed step by step:
+
+  grepai search "http client" -n 30
+  grepai search --refine-last "only the retry logic" -n 5
+  grepai search --global "retry with exponential backoff"
+
+The query may filter chunks with field terms, combined with the text to
+search for. Terms of one field match if any does; -field:value excludes:
+
+  lang:go          language name or extension (lang:py, lang:typescript)
+  path:internal/   path prefix, of the file or any of its directories
+  symbol:Handle*   an identifier in the chunk, with * and ? wildcards
+
+  grepai search 'lang:go path:internal/ symbol:Handle* "retry budget"'
+  grepai search 'rate limiting -path:vendor/'
+
+After 'grepai coverage import', results show their line coverage, and
+--uncovered keeps only code with statements no test runs:
+
+  grepai search --uncovered "payment refund"
+
+Set search.boost.uncovered to rank untested code higher (above 1) or
+lower (below 1).
+
+--fenced prints whole results as Markdown code blocks under a file:line
+header, ready to paste into a chat, and --copy puts one of them on the
+clipboard (the first, or the one given with --copy=N):
+
+  grepai search --fenced "session refresh" -n 3
+  grepai search --copy=2 "session refresh"
+
+--ui opens an interactive search: results follow the query as it is typed,
+with a highlighted preview of the selected one. tab edits the path prefix
+results are filtered by and ctrl+f turns the filter on and off; enter opens
+the result in $VISUAL or $EDITOR at its first line:
+
+  grepai search --ui
+  grepai search --ui "session refresh" --path internal/auth`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if searchUI {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: runSearch,
 }
 
@@ -71,13 +154,32 @@ func init() {
 	searchCmd.Flags().StringVar(&searchWorkspace, "workspace", "", "Workspace name for cross-project search")
 	searchCmd.Flags().StringArrayVar(&searchProjects, "project", nil, "Project name(s) to search (requires --workspace, can be repeated)")
 	searchCmd.Flags().StringVar(&searchPath, "path", "", "Path prefix to filter search results")
+	searchCmd.Flags().StringVar(&searchFeature, "feature", "", "RPG feature path to search within (e.g. \"auth/session\"; requires rpg.enabled)")
+	searchCmd.Flags().BoolVar(&searchBlame, "blame", false, "Annotate results with the last author and commit date (git blame)")
+	searchCmd.Flags().BoolVar(&searchRefine, "refine-last", false, "Re-rank the results of the previous search with this query instead of searching the whole index")
+	searchCmd.Flags().BoolVar(&searchGlobal, "global", false, "Search the personal index of all your repositories (see 'grepai global')")
+	searchCmd.Flags().StringVar(&searchRemote, "remote", "", remoteFlagUsage)
+	searchCmd.Flags().BoolVar(&searchUncovered, "uncovered", false, "Only return code that no test runs (requires 'grepai coverage import')")
+	searchCmd.Flags().StringVar(&searchScope, "scope", "", "Named scope from config.yaml restricting results to its paths and languages")
+	searchCmd.Flags().BoolVar(&searchFenced, "fenced", false, "Output results as Markdown code blocks with a file:line header")
+	searchCmd.Flags().IntVar(&searchCopy, "copy", 0, "Copy result N (default 1) to the clipboard as a Markdown code block")
+	searchCmd.Flags().Lookup("copy").NoOptDefVal = "1"
+	searchCmd.Flags().BoolVar(&searchUI, "ui", false, "Search interactively, with results updated as you type and a preview")
 	searchCmd.MarkFlagsMutuallyExclusive("json", "toon")
+	searchCmd.MarkFlagsMutuallyExclusive("json", "fenced")
+	searchCmd.MarkFlagsMutuallyExclusive("toon", "fenced")
+	addProfileFlags(searchCmd)
 }
 
 // rpgEnrichment holds RPG context for a search result
 type rpgEnrichment struct {
 	FeaturePath string
 	SymbolName  string
+	SymbolKind  string
+	Permalink   string
+	Owners      string
+	LastChange  *git.LastChange
+	Coverage    *coverage.Coverage
 }
 
 // enrichWithRPG enriches search results with RPG feature paths and symbol names
@@ -127,6 +229,91 @@ func enrichWithRPG(projectRoot string, cfg *config.Config, results []store.Searc
 	return enrichments
 }
 
+// applySymbolAnnotations prefers the enclosing symbol found in the symbol
+// index over the RPG symbol name, since it also carries the symbol kind.
+func applySymbolAnnotations(results []store.SearchResult, enrichments []rpgEnrichment) {
+	for i, r := range results {
+		if r.SymbolName != "" {
+			enrichments[i].SymbolName = r.SymbolName
+			enrichments[i].SymbolKind = r.SymbolKind
+		}
+	}
+}
+
+// applyPermalinks sets the link to hosted code for each result.
+func applyPermalinks(results []store.SearchResult, enrichments []rpgEnrichment, links *search.Permalinks) {
+	for i, r := range results {
+		enrichments[i].Permalink = links.Link(r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+	}
+}
+
+// applyOwners sets the CODEOWNERS owners of each result.
+func applyOwners(results []store.SearchResult, enrichments []rpgEnrichment, owners *search.CodeOwners) {
+	for i, r := range results {
+		enrichments[i].Owners = owners.Owners(r.Chunk.FilePath)
+	}
+}
+
+// applyCoverage sets the line coverage of each result.
+func applyCoverage(results []store.SearchResult, enrichments []rpgEnrichment, profile *coverage.Profile) {
+	for i, r := range results {
+		enrichments[i].Coverage = profile.Range(r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+	}
+}
+
+// applyBlame sets the last change to each result's lines and saves the
+// blame cache.
+func applyBlame(ctx context.Context, results []store.SearchResult, enrichments []rpgEnrichment, blames *search.Blames) {
+	for i, r := range results {
+		enrichments[i].LastChange = blames.LastChange(ctx, r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+	}
+	if err := blames.Save(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// lastChangeLabel formats a last change as "Author, 2006-01-02 (abc1234)".
+func lastChangeLabel(c *git.LastChange) string {
+	return fmt.Sprintf("%s, %s (%s)", c.Author, c.Date.Format("2006-01-02"), c.ShortCommit())
+}
+
+func symbolLabel(e rpgEnrichment) string {
+	if e.SymbolKind == "" {
+		return e.SymbolName
+	}
+	return fmt.Sprintf("%s %s", e.SymbolKind, e.SymbolName)
+}
+
+// loadSearchSymbols loads the project's symbol index for annotating search
+// results. It returns nil when no index exists. The store is read-only here
+// and must not be closed, since closing persists it.
+func loadSearchSymbols(ctx context.Context, projectRoot string) trace.SymbolStore {
+	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
+	if err := symbolStore.Load(ctx); err != nil {
+		return nil
+	}
+	return symbolStore
+}
+
+// featureChunkFilter returns a filter keeping chunks from files that the RPG
+// graph places under the given feature path.
+func featureChunkFilter(ctx context.Context, projectRoot string, cfg *config.Config, feature string) (func(store.Chunk) bool, error) {
+	if !cfg.RPG.Enabled {
+		return nil, fmt.Errorf("--feature requires RPG to be enabled (rpg.enabled: true in .grepai/config.yaml)")
+	}
+	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(projectRoot))
+	if err := rpgStore.Load(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load RPG index: %w", err)
+	}
+	defer rpgStore.Close()
+
+	files := rpg.NewQueryEngine(rpgStore.GetGraph()).FilesInFeature(feature)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files found under feature %q", feature)
+	}
+	return func(c store.Chunk) bool { return files[c.FilePath] }, nil
+}
+
 func findBestOverlappingSymbolNode(nodes []*rpg.Node, chunkStart, chunkEnd int) *rpg.Node {
 	chunkStart, chunkEnd = normalizeLineRange(chunkStart, chunkEnd)
 
@@ -193,7 +380,10 @@ func lineOverlap(aStart, aEnd, bStart, bEnd int) int {
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
-	query := args[0]
+	var query string
+	if len(args) > 0 {
+		query = args[0]
+	}
 	ctx := context.Background()
 
 	// Validate flag combination
@@ -201,13 +391,68 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--compact flag requires --json or --toon flag")
 	}
 
+	stopProfiling, err := startProfiling("search")
+	if err != nil {
+		return err
+	}
+	defer stopProfiling()
+
 	// Validate workspace-related flags
 	if len(searchProjects) > 0 && searchWorkspace == "" {
 		return fmt.Errorf("--project flag requires --workspace flag")
 	}
 
+	if searchUncovered && (searchRemote != "" || searchGlobal || searchWorkspace != "") {
+		return fmt.Errorf("--uncovered is only supported when searching the current project")
+	}
+	if searchScope != "" && (searchGlobal || searchWorkspace != "") {
+		return fmt.Errorf("--scope is not supported with --global or --workspace")
+	}
+	if (searchFenced || searchCopy != 0) && (searchRemote != "" || searchGlobal || searchWorkspace != "") {
+		return fmt.Errorf("--fenced and --copy are only supported when searching the current project")
+	}
+	if searchCopy < 0 {
+		return fmt.Errorf("--copy must be >= 1")
+	}
+	if searchUI {
+		switch {
+		case searchJSON || searchTOON || searchFenced || searchCopy != 0:
+			return fmt.Errorf("--ui cannot be combined with --json, --toon, --fenced or --copy")
+		case searchRemote != "" || searchGlobal || searchWorkspace != "":
+			return fmt.Errorf("--ui is only supported when searching the current project")
+		case searchRefine:
+			return fmt.Errorf("--ui cannot be combined with --refine-last")
+		case !isInteractiveTerminal():
+			return fmt.Errorf("--ui requires an interactive terminal")
+		}
+	}
+
+	// Remote server mode
+	if url := remoteURL(searchRemote); url != "" {
+		return runRemoteSearch(ctx, url, query)
+	}
+
+	// Global index mode
+	if searchGlobal {
+		switch {
+		case searchWorkspace != "":
+			return fmt.Errorf("--global cannot be combined with --workspace")
+		case searchFeature != "":
+			return fmt.Errorf("--feature is not supported with --global")
+		case searchRefine:
+			return fmt.Errorf("--refine-last is not supported with --global")
+		}
+		return runGlobalSearch(ctx, query, searchPath)
+	}
+
 	// Workspace mode
 	if searchWorkspace != "" {
+		if searchFeature != "" {
+			return fmt.Errorf("--feature is not supported with --workspace")
+		}
+		if searchRefine {
+			return fmt.Errorf("--refine-last is not supported with --workspace")
+		}
 		return runWorkspaceSearch(ctx, query, searchProjects, searchPath)
 	}
 
@@ -223,54 +468,92 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Queries embedded with another model than the index's vectors would
+	// return meaningless results.
+	if err := config.CheckProjectIndexMetadata(projectRoot, cfg); err != nil {
+		return err
+	}
+
 	// Initialize embedder
 	emb, err := embedder.NewFromConfig(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to initialize embedder: %w", err)
 	}
 	defer emb.Close()
+	docsEmb, err := embedder.NewDocsFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize docs embedder: %w", err)
+	}
+	if docsEmb != nil {
+		defer docsEmb.Close()
+	}
 
 	// Initialize store
-	var st store.VectorStore
-	switch cfg.Store.Backend {
-	case "gob":
-		indexPath := config.GetIndexPath(projectRoot)
-		gobStore := store.NewGOBStore(indexPath)
-		if err := gobStore.Load(ctx); err != nil {
-			return fmt.Errorf("failed to load index: %w", err)
-		}
-		st = gobStore
-	case "postgres":
-		var err error
-		st, err = store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+
+	defer st.Close()
+
+	// Create searcher with boost config
+	searcher := search.NewProjectSearcher(st, emb, docsEmb, cfg)
+	if symbols := loadSearchSymbols(ctx, projectRoot); symbols != nil {
+		searcher.SetSymbolStore(symbols)
+	}
+	// Queries typed in the UI are searched at every pause, half-written ones
+	// included, which would record misses no one made.
+	if !searchUI {
+		searcher.SetConfidenceObserver(search.NewMissObserver(projectRoot, cfg.Insights))
+	}
+	var keep func(store.Chunk) bool
+	if searchFeature != "" {
+		keep, err = featureChunkFilter(ctx, projectRoot, cfg, searchFeature)
 		if err != nil {
-			return fmt.Errorf("failed to connect to postgres: %w", err)
+			return err
 		}
-	case "qdrant":
-		collectionName := cfg.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = store.SanitizeCollectionName(projectRoot)
+	}
+	profile := loadProjectCoverage(projectRoot)
+	if searchUncovered {
+		if profile == nil {
+			return fmt.Errorf("--uncovered requires coverage: run 'grepai coverage import <profile>' first")
 		}
-		var err error
-		st, err = store.NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
+		keep = search.UncoveredFilter(profile, keep)
+	}
+	searcher.SetCoverage(profile)
+	if searchScope != "" {
+		scope, err := cfg.GetScope(searchScope)
 		if err != nil {
-			return fmt.Errorf("failed to connect to qdrant: %w", err)
+			return err
 		}
-	default:
-		return fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
+		keep = search.AndChunkFilters(keep, search.ScopeFilter(scope))
+	}
+	if keep != nil {
+		searcher.SetChunkFilter(keep)
 	}
-	defer st.Close()
-
-	// Create searcher with boost config
-	searcher := search.NewSearcher(st, emb, cfg.Search)
 
 	normalizedPath, err := search.NormalizeProjectPathPrefix(searchPath, projectRoot)
 	if err != nil {
 		return fmt.Errorf("invalid --path value: %w", err)
 	}
 
-	// Search with boosting
-	results, err := searcher.Search(ctx, query, searchLimit, normalizedPath)
+	if searchUI {
+		return runSearchUI(projectRoot, query, searcher)
+	}
+
+	// Search with boosting, or re-rank the previous results
+	queries := []string{query}
+	var results []store.SearchResult
+	if searchRefine {
+		last, lastErr := loadLastSearch(projectRoot)
+		if lastErr != nil {
+			return lastErr
+		}
+		queries = append(last.Queries, query)
+		results, err = searcher.Refine(ctx, query, last.ChunkIDs, searchLimit, normalizedPath)
+	} else {
+		results, err = searcher.Search(ctx, query, searchLimit, normalizedPath)
+	}
 	if err != nil {
 		if searchJSON {
 			return outputSearchErrorJSON(err)
@@ -281,9 +564,41 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("search failed: %w", err)
 	}
 
+	if err := saveLastSearch(projectRoot, queries, results); err != nil && !searchJSON && !searchTOON {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save results for --refine-last: %v\n", err)
+	}
+
 	// Enrich results with RPG context
 	enrichments := enrichWithRPG(projectRoot, cfg, results)
+	applySymbolAnnotations(results, enrichments)
+	applyPermalinks(results, enrichments, search.NewProjectPermalinks(projectRoot, cfg))
+	applyOwners(results, enrichments, search.NewProjectCodeOwners(projectRoot))
+	applyCoverage(results, enrichments, profile)
+	if searchBlame {
+		applyBlame(ctx, results, enrichments, search.NewProjectBlames(projectRoot))
+	}
 
+	header := fmt.Sprintf("Found %d results for: %q", len(results), query)
+	if searchRefine {
+		header = fmt.Sprintf("Refined to %d results for: %q", len(results), strings.Join(queries, " > "))
+	}
+	if err := outputSearchResults(projectRoot, header, results, enrichments); err != nil {
+		return err
+	}
+	if searchCopy > 0 {
+		location, err := copyResult(results, searchCopy)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Copied result %d (%s) to the clipboard\n", searchCopy, location)
+	}
+	return nil
+}
+
+// outputSearchResults prints search results in the format selected by the
+// --json, --toon and --compact flags, under header in plain text, and
+// records search stats for projectRoot.
+func outputSearchResults(projectRoot, header string, results []store.SearchResult, enrichments []rpgEnrichment) error {
 	// JSON output mode
 	if searchJSON {
 		var err error
@@ -324,9 +639,16 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if searchFenced {
+		outputStr := renderFencedResults(results)
+		fmt.Print(outputStr)
+		recordSearchStats(projectRoot, stats.Search, stats.Full, len(results), outputStr)
+		return nil
+	}
+
 	// Display results (plain text — build output string for token estimation)
 	var buf strings.Builder
-	fmt.Fprintf(&buf, "Found %d results for: %q\n\n", len(results), query)
+	fmt.Fprintf(&buf, "%s\n\n", header)
 
 	for i, result := range results {
 		fmt.Fprintf(&buf, "─── Result %d (score: %.4f) ───\n", i+1, result.Score)
@@ -335,7 +657,16 @@ func runSearch(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(&buf, "Feature: %s\n", enrichments[i].FeaturePath)
 		}
 		if enrichments[i].SymbolName != "" {
-			fmt.Fprintf(&buf, "Symbol: %s\n", enrichments[i].SymbolName)
+			fmt.Fprintf(&buf, "Symbol: %s\n", symbolLabel(enrichments[i]))
+		}
+		if enrichments[i].Owners != "" {
+			fmt.Fprintf(&buf, "Owners: %s\n", enrichments[i].Owners)
+		}
+		if c := enrichments[i].Coverage; c != nil {
+			fmt.Fprintf(&buf, "Coverage: %s\n", c)
+		}
+		if c := enrichments[i].LastChange; c != nil {
+			fmt.Fprintf(&buf, "Last change: %s\n", lastChangeLabel(c))
 		}
 		buf.WriteString("\n")
 
@@ -393,16 +724,23 @@ func recordSearchStats(projectRoot, commandType, outputMode string, resultCount
 
 // captureSearchJSON returns JSON-encoded results as a string.
 func captureSearchJSON(results []store.SearchResult, enrichments []rpgEnrichment) (string, error) {
-	jsonResults := make([]SearchResultJSON, len(results))
+	jsonResults := make([]annotatedSearchResultJSON, len(results))
 	for i, r := range results {
-		jsonResults[i] = SearchResultJSON{
-			FilePath:    r.Chunk.FilePath,
-			StartLine:   r.Chunk.StartLine,
-			EndLine:     r.Chunk.EndLine,
-			Score:       r.Score,
-			Content:     r.Chunk.Content,
-			FeaturePath: enrichments[i].FeaturePath,
-			SymbolName:  enrichments[i].SymbolName,
+		jsonResults[i] = annotatedSearchResultJSON{
+			SearchResultJSON: SearchResultJSON{
+				FilePath:    r.Chunk.FilePath,
+				StartLine:   r.Chunk.StartLine,
+				EndLine:     r.Chunk.EndLine,
+				Score:       r.Score,
+				Content:     r.Chunk.Content,
+				FeaturePath: enrichments[i].FeaturePath,
+				SymbolName:  enrichments[i].SymbolName,
+				SymbolKind:  enrichments[i].SymbolKind,
+			},
+			Permalink:  enrichments[i].Permalink,
+			Owners:     enrichments[i].Owners,
+			LastChange: enrichments[i].LastChange,
+			Coverage:   enrichments[i].Coverage,
 		}
 	}
 	var buf bytes.Buffer
@@ -416,15 +754,22 @@ func captureSearchJSON(results []store.SearchResult, enrichments []rpgEnrichment
 
 // captureSearchCompactJSON returns compact JSON-encoded results as a string.
 func captureSearchCompactJSON(results []store.SearchResult, enrichments []rpgEnrichment) (string, error) {
-	jsonResults := make([]SearchResultCompactJSON, len(results))
+	jsonResults := make([]annotatedSearchResultCompactJSON, len(results))
 	for i, r := range results {
-		jsonResults[i] = SearchResultCompactJSON{
-			FilePath:    r.Chunk.FilePath,
-			StartLine:   r.Chunk.StartLine,
-			EndLine:     r.Chunk.EndLine,
-			Score:       r.Score,
-			FeaturePath: enrichments[i].FeaturePath,
-			SymbolName:  enrichments[i].SymbolName,
+		jsonResults[i] = annotatedSearchResultCompactJSON{
+			SearchResultCompactJSON: SearchResultCompactJSON{
+				FilePath:    r.Chunk.FilePath,
+				StartLine:   r.Chunk.StartLine,
+				EndLine:     r.Chunk.EndLine,
+				Score:       r.Score,
+				FeaturePath: enrichments[i].FeaturePath,
+				SymbolName:  enrichments[i].SymbolName,
+				SymbolKind:  enrichments[i].SymbolKind,
+			},
+			Permalink:  enrichments[i].Permalink,
+			Owners:     enrichments[i].Owners,
+			LastChange: enrichments[i].LastChange,
+			Coverage:   enrichments[i].Coverage,
 		}
 	}
 	var buf bytes.Buffer
@@ -448,6 +793,7 @@ func captureSearchTOON(results []store.SearchResult, enrichments []rpgEnrichment
 			Content:     r.Chunk.Content,
 			FeaturePath: enrichments[i].FeaturePath,
 			SymbolName:  enrichments[i].SymbolName,
+			SymbolKind:  enrichments[i].SymbolKind,
 		}
 	}
 	output, err := gotoon.Encode(toonResults)
@@ -468,6 +814,7 @@ func captureSearchCompactTOON(results []store.SearchResult, enrichments []rpgEnr
 			Score:       r.Score,
 			FeaturePath: enrichments[i].FeaturePath,
 			SymbolName:  enrichments[i].SymbolName,
+			SymbolKind:  enrichments[i].SymbolKind,
 		}
 	}
 	output, err := gotoon.Encode(toonResults)
@@ -503,33 +850,32 @@ func SearchJSON(projectRoot string, query string, limit int) ([]store.SearchResu
 	if err != nil {
 		return nil, err
 	}
+	if err := config.CheckProjectIndexMetadata(projectRoot, cfg); err != nil {
+		return nil, err
+	}
 
 	emb, err := embedder.NewFromConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 	defer emb.Close()
+	docsEmb, err := embedder.NewDocsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if docsEmb != nil {
+		defer docsEmb.Close()
+	}
 
-	var st store.VectorStore
-	switch cfg.Store.Backend {
-	case "gob":
-		gobStore := store.NewGOBStore(config.GetIndexPath(projectRoot))
-		if err := gobStore.Load(ctx); err != nil {
-			return nil, err
-		}
-		st = gobStore
-	case "postgres":
-		var err error
-		st, err = store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
-		if err != nil {
-			return nil, err
-		}
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return nil, err
 	}
+
 	defer st.Close()
 
 	// Create searcher with boost config
-	searcher := search.NewSearcher(st, emb, cfg.Search)
-
+	searcher := search.NewProjectSearcher(st, emb, docsEmb, cfg)
 	return searcher.Search(ctx, query, limit, "")
 }
 
@@ -563,6 +909,9 @@ func runWorkspaceSearch(ctx context.Context, query string, projects []string, pa
 	if err := config.ValidateWorkspaceBackend(ws); err != nil {
 		return err
 	}
+	if err := config.CheckWorkspaceIndexMetadata(ws); err != nil {
+		return err
+	}
 
 	// Initialize embedder
 	emb, err := embedder.NewFromWorkspaceConfig(ws)
@@ -572,51 +921,25 @@ func runWorkspaceSearch(ctx context.Context, query string, projects []string, pa
 	defer emb.Close()
 
 	// Initialize store
-	var st store.VectorStore
-	projectID := "workspace:" + ws.Name
-
-	switch ws.Store.Backend {
-	case "postgres":
-		st, err = store.NewPostgresStore(ctx, ws.Store.Postgres.DSN, projectID, ws.Embedder.GetDimensions())
-		if err != nil {
-			return fmt.Errorf("failed to connect to postgres: %w", err)
-		}
-	case "qdrant":
-		collectionName := ws.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = "workspace_" + ws.Name
-		}
-		st, err = store.NewQdrantStore(ctx, ws.Store.Qdrant.Endpoint, ws.Store.Qdrant.Port, ws.Store.Qdrant.UseTLS, collectionName, ws.Store.Qdrant.APIKey, ws.Embedder.GetDimensions())
-		if err != nil {
-			return fmt.Errorf("failed to connect to qdrant: %w", err)
-		}
-	default:
-		return fmt.Errorf("unsupported backend for workspace: %s", ws.Store.Backend)
+	st, err := store.NewFromWorkspaceConfig(ctx, ws)
+	if err != nil {
+		return err
 	}
 	defer st.Close()
 
-	// Create searcher with default search config
-	searchCfg := config.SearchConfig{
-		Hybrid: config.HybridConfig{Enabled: false, K: 60},
-		Boost:  config.DefaultConfig().Search.Boost,
-	}
-	searcher := search.NewSearcher(st, emb, searchCfg)
-
-	// Construct full path prefix for database query
-	// Database stores paths as: workspaceName/projectName/relativePath
-	// When a single project is specified, include it in the path prefix to push filtering to database level
-	fullPathPrefix := ws.Name + "/"
-	if len(resolvedProjects) == 1 {
-		// If exactly one project specified, include it in the path prefix for database-level filtering
-		// This ensures file_path LIKE 'workspace/project/%' filter is applied
-		fullPathPrefix += resolvedProjects[0] + "/"
-	}
-	if normalizedPath != "" {
-		fullPathPrefix += normalizedPath
+	searcher, err := search.NewWorkspaceSearcher(st, emb, ws)
+	if err != nil {
+		return err
 	}
+	searcher.SetConfidenceObserver(search.NewWorkspaceMissObserver(ws))
 
-	// Search
-	results, err := searcher.Search(ctx, query, searchLimit, fullPathPrefix)
+	// Search the selected projects, or route searches of large workspaces
+	// to their most relevant projects.
+	results, routes, err := searcher.SearchWorkspace(ctx, query, searchLimit, search.WorkspaceSearchOptions{
+		Projects:   resolvedProjects,
+		PathPrefix: normalizedPath,
+		Route:      true,
+	})
 	if err != nil {
 		if searchJSON {
 			return outputSearchErrorJSON(err)
@@ -626,104 +949,20 @@ func runWorkspaceSearch(ctx context.Context, query string, projects []string, pa
 		}
 		return fmt.Errorf("search failed: %w", err)
 	}
-
-	// Filter by projects if specified (additional client-side filtering for multiple projects)
-	// File paths are stored as: workspaceName/projectName/relativePath
-	if len(resolvedProjects) > 0 {
-		filteredResults := make([]store.SearchResult, 0)
-		for _, r := range results {
-			for _, projectName := range resolvedProjects {
-				// Match workspace/project/ prefix
-				expectedPrefix := ws.Name + "/" + projectName + "/"
-				if strings.HasPrefix(r.Chunk.FilePath, expectedPrefix) {
-					filteredResults = append(filteredResults, r)
-					break
-				}
-			}
-		}
-		results = filteredResults
-	}
+	routed := search.RoutedProjects(routes)
 
 	// Workspace mode doesn't have RPG enrichment (no single projectRoot)
 	enrichments := make([]rpgEnrichment, len(results))
-
-	projectRoot, _ := config.FindProjectRoot()
-
-	// JSON output mode
-	if searchJSON {
-		var outputStr string
-		var err error
-		if searchCompact {
-			outputStr, err = captureSearchCompactJSON(results, enrichments)
-		} else {
-			outputStr, err = captureSearchJSON(results, enrichments)
-		}
-		if err != nil {
-			return err
-		}
-		fmt.Print(outputStr)
-		recordSearchStats(projectRoot, stats.Search, outputModeFromFlags(searchJSON, searchTOON, searchCompact), len(results), outputStr)
-		return nil
+	applyPermalinks(results, enrichments, search.NewWorkspacePermalinks(ws))
+	applyOwners(results, enrichments, search.NewWorkspaceCodeOwners(ws))
+	if searchBlame {
+		applyBlame(ctx, results, enrichments, search.NewWorkspaceBlames(ws))
 	}
 
-	// TOON output mode
-	if searchTOON {
-		var outputStr string
-		var err error
-		if searchCompact {
-			outputStr, err = captureSearchCompactTOON(results, enrichments)
-		} else {
-			outputStr, err = captureSearchTOON(results, enrichments)
-		}
-		if err != nil {
-			return err
-		}
-		fmt.Print(outputStr)
-		recordSearchStats(projectRoot, stats.Search, outputModeFromFlags(searchJSON, searchTOON, searchCompact), len(results), outputStr)
-		return nil
-	}
-
-	if len(results) == 0 {
-		fmt.Println("No results found.")
-		recordSearchStats(projectRoot, stats.Search, stats.Full, 0, "")
-		return nil
-	}
-
-	// Display results
-	var buf strings.Builder
-	fmt.Fprintf(&buf, "Found %d results for: %q in workspace %q\n\n", len(results), query, searchWorkspace)
-
-	for i, result := range results {
-		fmt.Fprintf(&buf, "─── Result %d (score: %.4f) ───\n", i+1, result.Score)
-		fmt.Fprintf(&buf, "File: %s:%d-%d\n", result.Chunk.FilePath, result.Chunk.StartLine, result.Chunk.EndLine)
-		if enrichments[i].FeaturePath != "" {
-			fmt.Fprintf(&buf, "Feature: %s\n", enrichments[i].FeaturePath)
-		}
-		if enrichments[i].SymbolName != "" {
-			fmt.Fprintf(&buf, "Symbol: %s\n", enrichments[i].SymbolName)
-		}
-		buf.WriteString("\n")
-
-		// Display content with line numbers
-		lines := strings.Split(result.Chunk.Content, "\n")
-		startIdx := 0
-		if len(lines) > 0 && strings.HasPrefix(lines[0], "File: ") {
-			startIdx = 2
-		}
-
-		lineNum := result.Chunk.StartLine
-		for j := startIdx; j < len(lines) && j < startIdx+15; j++ {
-			fmt.Fprintf(&buf, "%4d │ %s\n", lineNum, lines[j])
-			lineNum++
-		}
-		if len(lines)-startIdx > 15 {
-			fmt.Fprintf(&buf, "     │ ... (%d more lines)\n", len(lines)-startIdx-15)
-		}
-		buf.WriteString("\n")
+	projectRoot, _ := config.FindProjectRoot()
+	header := fmt.Sprintf("Found %d results for: %q in workspace %q", len(results), query, searchWorkspace)
+	if len(routed) > 0 {
+		header += fmt.Sprintf(" (routed to %s)", strings.Join(routed, ", "))
 	}
-
-	outputStr := buf.String()
-	fmt.Print(outputStr)
-	recordSearchStats(projectRoot, stats.Search, stats.Full, len(results), outputStr)
-	return nil
+	return outputSearchResults(projectRoot, header, results, enrichments)
 }
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -37,6 +38,29 @@ func (m *MockStore) DeleteByFile(ctx context.Context, filePath string) error {
 	return nil
 }
 
+func (m *MockStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	for _, filePath := range filePaths {
+		_ = m.DeleteByFile(ctx, filePath)
+	}
+	return nil
+}
+
+func (m *MockStore) DeleteChunks(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(m.chunks, id)
+	}
+	return nil
+}
+
+func (m *MockStore) DeleteByProject(ctx context.Context, project string) error {
+	for id, chunk := range m.chunks {
+		if strings.HasPrefix(chunk.FilePath, project+"/") {
+			delete(m.chunks, id)
+		}
+	}
+	return nil
+}
+
 func (m *MockStore) Search(ctx context.Context, queryVector []float32, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
 	results := make([]store.SearchResult, 0)
 	for _, chunk := range m.chunks {
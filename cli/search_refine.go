@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// lastSearch is the result set of the last 'grepai search' in a project,
+// re-ranked by 'grepai search --refine-last'.
+type lastSearch struct {
+	Queries  []string  `json:"queries"` // the original query followed by each refinement
+	ChunkIDs []string  `json:"chunk_ids"`
+	Time     time.Time `json:"time"`
+}
+
+// saveLastSearch records results as the project's last search. A refined
+// search keeps the queries it refined so they can be chained.
+func saveLastSearch(projectRoot string, queries []string, results []store.SearchResult) error {
+	last := lastSearch{Queries: queries, ChunkIDs: make([]string, len(results)), Time: time.Now().UTC()}
+	for i, r := range results {
+		last.ChunkIDs[i] = r.Chunk.ID
+	}
+	data, err := json.MarshalIndent(last, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(config.GetLastSearchPath(projectRoot), data, 0644)
+}
+
+// loadLastSearch reads the project's last search.
+func loadLastSearch(projectRoot string) (*lastSearch, error) {
+	data, err := os.ReadFile(config.GetLastSearchPath(projectRoot))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no previous search to refine; run 'grepai search' first")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last search: %w", err)
+	}
+	var last lastSearch
+	if err := json.Unmarshal(data, &last); err != nil {
+		return nil, fmt.Errorf("failed to parse last search: %w", err)
+	}
+	if len(last.ChunkIDs) == 0 {
+		return nil, fmt.Errorf("the previous search returned no results to refine")
+	}
+	return &last, nil
+}
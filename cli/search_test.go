@@ -3,10 +3,14 @@ package cli
 import (
 	"bytes"
 	"encoding/json"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alpkeskin/gotoon"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/git"
 	"github.com/yoanbernabeu/grepai/rpg"
 	"github.com/yoanbernabeu/grepai/store"
 )
@@ -444,3 +448,95 @@ func TestFindFileNode_PrefersExactPath(t *testing.T) {
 		t.Fatalf("expected exact path b.go, got %s", fileNode.Path)
 	}
 }
+
+func TestCaptureSearchJSON_Permalink(t *testing.T) {
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "a.go", StartLine: 1, EndLine: 5}, Score: 0.9},
+		{Chunk: store.Chunk{FilePath: "b.go", StartLine: 2, EndLine: 3}, Score: 0.8},
+	}
+	enrichments := make([]rpgEnrichment, len(results))
+	applyPermalinks(results, enrichments, nil)
+	enrichments[0].Permalink = "https://example.com/a.go#L1-L5"
+
+	for name, capture := range map[string]func([]store.SearchResult, []rpgEnrichment) (string, error){
+		"full":    captureSearchJSON,
+		"compact": captureSearchCompactJSON,
+	} {
+		out, err := capture(results, enrichments)
+		if err != nil {
+			t.Fatalf("%s: capture failed: %v", name, err)
+		}
+		var decoded []map[string]any
+		if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", name, err)
+		}
+		if decoded[0]["permalink"] != "https://example.com/a.go#L1-L5" || decoded[0]["file_path"] != "a.go" {
+			t.Errorf("%s: unexpected first result %v", name, decoded[0])
+		}
+		if _, ok := decoded[1]["permalink"]; ok {
+			t.Errorf("%s: expected permalink omitted without a link, got %v", name, decoded[1])
+		}
+	}
+}
+
+func TestCaptureSearchJSON_LastChange(t *testing.T) {
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "a.go", StartLine: 1, EndLine: 5}, Score: 0.9},
+	}
+	change := &git.LastChange{Author: "Alice", Commit: "0123456789abcdef", Date: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)}
+	enrichments := []rpgEnrichment{{LastChange: change}}
+
+	out, err := captureSearchCompactJSON(results, enrichments)
+	if err != nil {
+		t.Fatalf("capture failed: %v", err)
+	}
+	var decoded []struct {
+		LastChange *git.LastChange `json:"last_change"`
+	}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded[0].LastChange == nil || decoded[0].LastChange.Author != "Alice" {
+		t.Errorf("expected last_change for Alice, got %s", out)
+	}
+
+	if got := lastChangeLabel(change); got != "Alice, 2024-01-02 (0123456)" {
+		t.Errorf("lastChangeLabel = %q", got)
+	}
+}
+
+func TestLastSearchRoundTrip(t *testing.T) {
+	projectRoot := t.TempDir()
+	if err := os.MkdirAll(config.GetConfigDir(projectRoot), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadLastSearch(projectRoot); err == nil || !strings.Contains(err.Error(), "no previous search") {
+		t.Fatalf("expected a missing last search error, got %v", err)
+	}
+
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{ID: "net/retry.go_0"}},
+		{Chunk: store.Chunk{ID: "net/dial.go_2"}},
+	}
+	if err := saveLastSearch(projectRoot, []string{"http client", "retry"}, results); err != nil {
+		t.Fatalf("saveLastSearch failed: %v", err)
+	}
+	last, err := loadLastSearch(projectRoot)
+	if err != nil {
+		t.Fatalf("loadLastSearch failed: %v", err)
+	}
+	if len(last.Queries) != 2 || last.Queries[1] != "retry" {
+		t.Errorf("unexpected queries: %v", last.Queries)
+	}
+	if len(last.ChunkIDs) != 2 || last.ChunkIDs[0] != "net/retry.go_0" {
+		t.Errorf("unexpected chunk IDs: %v", last.ChunkIDs)
+	}
+
+	if err := saveLastSearch(projectRoot, []string{"nothing"}, nil); err != nil {
+		t.Fatalf("saveLastSearch failed: %v", err)
+	}
+	if _, err := loadLastSearch(projectRoot); err == nil {
+		t.Error("expected an error refining an empty result set")
+	}
+}
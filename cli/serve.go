@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/grpcapi"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/mcp"
+	"github.com/yoanbernabeu/grepai/remote"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+	"github.com/yoanbernabeu/grepai/watcher"
+	"golang.org/x/sync/errgroup"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [project-path]",
+	Short: "Watch a project and serve it over MCP in one process",
+	Long: `Run the watch daemon and the MCP server in a single long-lived process.
+
+The MCP server reads the index, symbol index and embedder loaded by the
+watcher instead of loading its own copies, which halves memory use and avoids
+file-lock contention compared to running 'grepai watch' and 'grepai mcp-serve'
+side by side. Linked git worktrees are watched too, as with 'grepai watch'.
+
+The MCP server communicates via stdio; watch logs are written to stderr.
+Do not run 'grepai watch' for the same project at the same time.
+
+With --listen, the tools are served over HTTP instead of stdio, so search
+and trace commands on other machines can use this index with --remote.
+Clients must present the token set in $GREPAI_REMOTE_TOKEN:
+
+  GREPAI_REMOTE_TOKEN=... grepai serve --listen :8765
+  GREPAI_REMOTE_TOKEN=... grepai search --remote http://indexer:8765 "auth flow"
+
+With --grpc-listen, the same search, trace and index status are served as
+the gRPC service defined in api/grepai/v1/grepai.proto, for programmatic
+integrations. Both listeners can run together.
+
+Arguments:
+  project-path  Optional path to the grepai project directory.
+                If not provided, searches for .grepai from current directory.
+
+Configuration for Claude Code:
+  claude mcp add grepai -- grepai serve`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().String("listen", "", "Serve tools over HTTP on this address (e.g. :8765) for 'grepai search --remote' instead of MCP over stdio")
+	serveCmd.Flags().String("grpc-listen", "", "Serve the gRPC API on this address (e.g. :8766) instead of MCP over stdio")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	var explicitPath string
+	if len(args) > 0 {
+		explicitPath = args[0]
+	}
+	listen, _ := cmd.Flags().GetString("listen")
+	grpcListen, _ := cmd.Flags().GetString("grpc-listen")
+	token := os.Getenv(remote.TokenEnv)
+	if (listen != "" || grpcListen != "") && token == "" {
+		return fmt.Errorf("--listen and --grpc-listen require a token in %s", remote.TokenEnv)
+	}
+
+	projectRoot, _, err := resolveMCPTarget(explicitPath, "")
+	if err != nil {
+		return err
+	}
+	if projectRoot == "" {
+		return fmt.Errorf("grepai serve requires a project (run 'grepai init' first, or use 'grepai mcp-serve --workspace')")
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Stdout carries MCP messages, so watch output only goes to the log.
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds)
+	log.SetPrefix("[grepai-serve] ")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	emb, err := initializeEmbedder(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer emb.Close()
+
+	srv, err := mcp.NewServer(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to create MCP server: %w", err)
+	}
+	srv.UseEmbedder(emb)
+	srv.SetPathMappings(cfg.MCP.PathMappings)
+
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	watchDone := make(chan error, 1)
+	go func() {
+		watchDone <- runDynamicWatchSupervisor(
+			watchCtx,
+			projectRoot,
+			emb,
+			withWatchSupervisorBackgroundChild(true),
+			withWatchSupervisorNotifier(watcher.NewNotifier(cfg.Watch.Notifications)),
+			withWatchSupervisorThrottle(startWatchPowerThrottle(watchCtx, cfg.Watch.PowerProfile, logWatchPowerThrottle("", true))),
+			withWatchSupervisorSessionRunner(serveSessionRunner(serveStoresObserver(projectRoot, srv))),
+		)
+	}()
+
+	var serveErr error
+	if listen != "" || grpcListen != "" {
+		log.Printf("Serving %s to remote clients (provider: %s, backend: %s)", projectRoot, cfg.Embedder.Provider, cfg.Store.Backend)
+		serveErr = serveRemote(ctx, srv, token, remoteLimits(cfg.MCP.RateLimit), listen, grpcListen)
+	} else {
+		log.Printf("Serving %s over MCP (provider: %s, backend: %s)", projectRoot, cfg.Embedder.Provider, cfg.Store.Backend)
+		serveErr = srv.ServeContext(ctx)
+	}
+
+	// Stop watching once the MCP client disconnects, or the remote server
+	// is interrupted, letting sessions persist their stores.
+	stopWatch()
+	if err := <-watchDone; err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("Warning: watcher stopped: %v", err)
+	}
+	return serveErr
+}
+
+// serveRemote serves tools over HTTP on listen and over gRPC on grpcListen,
+// whichever are set, until ctx is done or one of the servers fails. limits
+// apply to each token across both APIs.
+func serveRemote(ctx context.Context, tools remote.ToolCaller, token string, limits remote.Limits, listen, grpcListen string) error {
+	limiter := remote.NewLimiter(limits)
+	g, ctx := errgroup.WithContext(ctx)
+	if listen != "" {
+		g.Go(func() error {
+			return remote.ListenAndServe(ctx, listen, remote.NewHandler(tools, token, limiter))
+		})
+	}
+	if grpcListen != "" {
+		g.Go(func() error {
+			return grpcapi.ListenAndServe(ctx, grpcListen, grpcapi.NewServer(tools, token, limiter))
+		})
+	}
+	return g.Wait()
+}
+
+// remoteLimits converts mcp.rate_limit to the remote server's limits.
+func remoteLimits(cfg config.RateLimitConfig) remote.Limits {
+	return remote.Limits{
+		RequestsPerMinute: cfg.RequestsPerMinute,
+		Burst:             cfg.Burst,
+		MaxConcurrent:     cfg.MaxConcurrent,
+	}
+}
+
+// watcherIndexUser is the part of the MCP server serve hands watch sessions'
+// stores to.
+type watcherIndexUser interface {
+	UseWatcherIndex(st store.VectorStore, symbols trace.SymbolStore)
+}
+
+// serveStoresObserver publishes the stores of the main project's watch
+// session to the MCP server. Linked worktrees are watched but not served.
+func serveStoresObserver(mainRoot string, srv watcherIndexUser) watchStoresObserver {
+	return func(projectRoot string, st store.VectorStore, symbolStore *trace.GOBSymbolStore) {
+		if !sameProjectPath(mainRoot, projectRoot) {
+			return
+		}
+		if st == nil || symbolStore == nil {
+			srv.UseWatcherIndex(nil, nil)
+			return
+		}
+		srv.UseWatcherIndex(st, symbolStore)
+	}
+}
+
+// serveSessionRunner runs watch sessions that report their stores to
+// onStores.
+func serveSessionRunner(onStores watchStoresObserver) watchSupervisorSessionRunner {
+	return func(
+		ctx context.Context,
+		projectRoot string,
+		emb embedder.Embedder,
+		isBackgroundChild bool,
+		onReady func(),
+		onEvent watchSessionEventObserver,
+		onScan func(current, total int, file string),
+		onEmbed func(info indexer.BatchProgressInfo),
+		onRPG func(step string, current, total int),
+		onActivity watchActivityObserver,
+		onStats watchStatsObserver,
+		throttle *watcher.Throttle,
+	) error {
+		var observer watchEventObserver
+		if onEvent != nil {
+			observer = watchEventObserver(onEvent)
+		}
+		return watchProjectWithOptions(ctx, projectRoot, emb, isBackgroundChild, onReady, watchOptions{
+			onEvent:    observer,
+			onScan:     onScan,
+			onEmbed:    onEmbed,
+			onRPG:      onRPG,
+			onActivity: onActivity,
+			onStats:    onStats,
+			onStores:   onStores,
+			throttle:   throttle,
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+type recordingIndexUser struct {
+	store   store.VectorStore
+	symbols trace.SymbolStore
+	calls   int
+}
+
+func (r *recordingIndexUser) UseWatcherIndex(st store.VectorStore, symbols trace.SymbolStore) {
+	r.store, r.symbols = st, symbols
+	r.calls++
+}
+
+func TestServeStoresObserver(t *testing.T) {
+	mainRoot := t.TempDir()
+	user := &recordingIndexUser{}
+	observe := serveStoresObserver(mainRoot, user)
+
+	st := store.NewGOBStore(filepath.Join(mainRoot, "index.gob"))
+	symbols := trace.NewGOBSymbolStore(filepath.Join(mainRoot, "symbols.gob"))
+
+	observe(t.TempDir(), st, symbols)
+	if user.calls != 0 {
+		t.Fatal("expected linked worktree stores not to be served")
+	}
+
+	observe(mainRoot, st, symbols)
+	if user.store != st || user.symbols != symbols {
+		t.Fatalf("expected the main project's stores to be served, got %+v", user)
+	}
+
+	observe(mainRoot, nil, nil)
+	if user.store != nil || user.symbols != nil {
+		t.Fatalf("expected the stores to be released when the session ends, got %+v", user)
+	}
+}
+
+func TestServeSessionRunner_SharesSessionStores(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write main.go: %v", err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Store.Backend = "gob"
+	cfg.RPG.Enabled = false
+	dim := 8
+	cfg.Embedder.Dimensions = &dim
+	if err := cfg.Save(root); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	user := &recordingIndexUser{}
+	run := serveSessionRunner(serveStoresObserver(root, user))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		var emb embedder.Embedder = &stubEmbedder{dim: dim}
+		errCh <- run(ctx, root, emb, true, func() { close(ready) }, nil, nil, nil, nil, nil, nil, nil)
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(20 * time.Second):
+		cancel()
+		t.Fatal("timeout waiting for the watch session")
+	}
+	if user.store == nil || user.symbols == nil {
+		cancel()
+		t.Fatal("expected the session's stores to be shared once it is ready")
+	}
+	if chunks, err := user.store.(*store.GOBStore).GetAllChunks(ctx); err != nil || len(chunks) == 0 {
+		cancel()
+		t.Fatalf("expected the shared store to hold the indexed project, got %d chunks (%v)", len(chunks), err)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("watch session returned error: %v", err)
+	}
+	if user.store != nil {
+		t.Fatal("expected the stores to be released when the session stops")
+	}
+}
@@ -17,6 +17,7 @@ var (
 	statsHistory bool
 	statsLimit   int
 	statsNoUI    bool
+	statsTiming  bool
 )
 
 var statsCmd = &cobra.Command{
@@ -27,7 +28,11 @@ a traditional grep-based workflow.
 
 Every successful search and trace command records an entry locally in
 .grepai/stats.json. This command aggregates those entries and shows
-how many tokens (and optionally dollars) have been saved.`,
+how many tokens (and optionally dollars) have been saved.
+
+With --telemetry, shows the operation counts and timings recorded in
+.grepai/telemetry.json when telemetry.enabled is set in the config. These
+metrics stay on this machine.`,
 	RunE: runStats,
 }
 
@@ -37,6 +42,7 @@ func init() {
 	statsCmd.Flags().BoolVar(&statsHistory, "history", false, "Show per-day history breakdown")
 	statsCmd.Flags().IntVarP(&statsLimit, "limit", "l", 30, "Max days shown with --history")
 	statsCmd.Flags().BoolVar(&statsNoUI, "no-ui", false, "Print plain text instead of interactive UI")
+	statsCmd.Flags().BoolVar(&statsTiming, "telemetry", false, "Show local usage counts and timings instead of token savings")
 }
 
 func runStats(cmd *cobra.Command, args []string) error {
@@ -50,6 +56,10 @@ func runStats(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	if statsTiming {
+		return runTelemetryStats(projectRoot, cfg)
+	}
+
 	statsPath := stats.StatsPath(projectRoot)
 	entries, err := stats.ReadAll(statsPath)
 	if err != nil {
@@ -68,7 +78,7 @@ func runStats(cmd *cobra.Command, args []string) error {
 		return outputStatsJSON(summary, entries)
 	}
 
-	if shouldUseStatsUI(isInteractiveTerminal(), statsNoUI) && !statsHistory {
+	if shouldUseStatsUI(isInteractiveTerminal(), statsNoUI || plainUIEnabled()) && !statsHistory {
 		return runStatsUI(summary, entries, cfg.Embedder.Provider)
 	}
 
@@ -103,14 +113,12 @@ func outputStatsJSON(summary stats.Summary, entries []stats.Entry) error {
 // outputStatsHuman renders the summary using lipgloss styles.
 func outputStatsHuman(summary stats.Summary, entries []stats.Entry, provider string) error {
 	// Styles
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
-	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Width(22)
-	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Bold(true)
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
-		Padding(1, 2)
+	theme := newTUITheme()
+	headerStyle := theme.title
+	labelStyle := theme.text.Width(22)
+	valueStyle := theme.subtitle
+	dimStyle := theme.muted
+	boxStyle := theme.panel.Border(lipgloss.RoundedBorder()).Padding(1, 2)
 
 	content := headerStyle.Render("grepai stats — Token Savings Report") + "\n\n"
 
@@ -144,7 +152,7 @@ func outputStatsHuman(summary stats.Summary, entries []stats.Entry, provider str
 	}
 	content += dimStyle.Render(strings.TrimSuffix(modeLine, " · ")) + "\n"
 
-	fmt.Println(boxStyle.Render(content))
+	printlnStyled(boxStyle.Render(content))
 
 	if statsHistory {
 		printHistoryTable(entries, dimStyle, valueStyle)
@@ -171,8 +179,8 @@ func printHistoryTable(entries []stats.Entry, dimStyle, valueStyle lipgloss.Styl
 			colPct.Render("Savings"),
 	)
 	sep := dimStyle.Render(fmt.Sprintf("%-14s %-10s %-16s %-10s", "──────────────", "─────────", "───────────────", "────────"))
-	fmt.Println(header)
-	fmt.Println(sep)
+	printlnStyled(header)
+	printlnStyled(sep)
 
 	for _, d := range days {
 		pct := 0.0
@@ -183,7 +191,7 @@ func printHistoryTable(entries []stats.Entry, dimStyle, valueStyle lipgloss.Styl
 			colNum.Render(fmt.Sprintf("%d", d.QueryCount)) +
 			colSaved.Render(formatInt(d.TokensSaved)) +
 			colPct.Render(fmt.Sprintf("%.1f%%", pct))
-		fmt.Println(valueStyle.Render(row))
+		printlnStyled(valueStyle.Render(row))
 	}
 }
 
@@ -201,3 +209,50 @@ func formatInt(n int) string {
 	}
 	return result
 }
+
+// runTelemetryStats shows the timings recorded by opt-in telemetry.
+func runTelemetryStats(projectRoot string, cfg *config.Config) error {
+	timings, err := stats.ReadTimings(stats.TelemetryPath(projectRoot))
+	if err != nil {
+		return fmt.Errorf("failed to read telemetry: %w", err)
+	}
+	summaries := stats.SummarizeTimings(timings)
+
+	if statsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summaries)
+	}
+
+	if len(summaries) == 0 {
+		if !cfg.Telemetry.Enabled {
+			fmt.Println("Telemetry is disabled.")
+			fmt.Println("Set telemetry.enabled: true in .grepai/config.yaml to record local usage metrics.")
+		} else {
+			fmt.Println("No telemetry recorded yet.")
+		}
+		return nil
+	}
+	fmt.Print(renderTelemetryTable(summaries))
+	return nil
+}
+
+// renderTelemetryTable renders operation summaries as a plain text table.
+func renderTelemetryTable(summaries []stats.OperationSummary) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-28s %8s %8s %10s %10s %10s %10s\n", "Operation", "Count", "Failed", "Avg", "p50", "p95", "Max")
+	for _, s := range summaries {
+		fmt.Fprintf(&sb, "%-28s %8d %8d %10s %10s %10s %10s\n",
+			s.Operation, s.Count, s.Failures,
+			formatMillis(s.AvgMs), formatMillis(s.P50Ms), formatMillis(s.P95Ms), formatMillis(s.MaxMs))
+	}
+	return sb.String()
+}
+
+// formatMillis formats a duration in milliseconds for the telemetry table.
+func formatMillis(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.2fs", ms/1000)
+	}
+	return fmt.Sprintf("%.1fms", ms)
+}
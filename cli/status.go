@@ -10,6 +10,7 @@ import (
 
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/spf13/cobra"
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/daemon"
@@ -18,7 +19,14 @@ import (
 	"github.com/yoanbernabeu/grepai/store"
 )
 
-var statusNoUI bool
+var (
+	statusNoUI      bool
+	statusBreakdown bool
+)
+
+// statusBreakdownRows is the number of directories and languages listed in
+// the status breakdown.
+const statusBreakdownRows = 8
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
@@ -62,36 +70,37 @@ type model struct {
 	savingsSummary  *stats.Summary
 	savingsDays     []stats.DaySummary
 	savingsSelected int
+	excluded        []config.BudgetExclusion
+	styles          statusStyles
 }
 
 func init() {
 	statusCmd.Flags().BoolVar(&statusNoUI, "no-ui", false, "Print plain text summary instead of interactive UI")
+	statusCmd.Flags().BoolVar(&statusBreakdown, "breakdown", false, "Include chunk and file counts by top-level directory and language in the plain text summary")
 }
 
-// Styles
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205"))
-
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("229")).
-			Background(lipgloss.Color("57"))
-
-	normalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
-
-	dimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
+// statusStyles holds the status browser styles, derived from the TUI theme.
+type statusStyles struct {
+	title    lipgloss.Style
+	selected lipgloss.Style
+	normal   lipgloss.Style
+	dim      lipgloss.Style
+	help     lipgloss.Style
+	box      lipgloss.Style
+	syntax   string
+}
 
-	boxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("62")).
-			Padding(1, 2)
-)
+func newStatusStyles(theme tuiTheme) statusStyles {
+	return statusStyles{
+		title:    theme.title,
+		selected: theme.highlight,
+		normal:   theme.text,
+		dim:      theme.muted,
+		help:     theme.help,
+		box:      theme.panel.Border(lipgloss.RoundedBorder()).Padding(1, 2),
+		syntax:   theme.syntax,
+	}
+}
 
 func (m model) Init() tea.Cmd {
 	return nil
@@ -201,51 +210,60 @@ func (m model) View() string {
 func (m model) viewStats() string {
 	var sb strings.Builder
 
-	sb.WriteString(titleStyle.Render("grepai index status"))
+	sb.WriteString(m.styles.title.Render("grepai index status"))
 	sb.WriteString("\n\n")
 
-	sb.WriteString(normalStyle.Render("Files indexed:    "))
+	sb.WriteString(m.styles.normal.Render("Files indexed:    "))
 	sb.WriteString(fmt.Sprintf("%d\n", m.stats.TotalFiles))
 
-	sb.WriteString(normalStyle.Render("Total chunks:     "))
+	sb.WriteString(m.styles.normal.Render("Total chunks:     "))
 	sb.WriteString(fmt.Sprintf("%d\n", m.stats.TotalChunks))
 
-	sb.WriteString(normalStyle.Render("Index size:       "))
+	sb.WriteString(m.styles.normal.Render("Index size:       "))
 	sb.WriteString(fmt.Sprintf("%s\n", formatBytes(m.stats.IndexSize)))
 
-	sb.WriteString(normalStyle.Render("Last updated:     "))
+	sb.WriteString(m.styles.normal.Render("Last updated:     "))
 	if m.stats.LastUpdated.IsZero() {
 		sb.WriteString("Never\n")
 	} else {
 		sb.WriteString(fmt.Sprintf("%s\n", m.stats.LastUpdated.Format("2006-01-02 15:04:05")))
 	}
 
-	sb.WriteString(normalStyle.Render("Provider:         "))
+	sb.WriteString(m.styles.normal.Render("Provider:         "))
 	sb.WriteString(fmt.Sprintf("%s (%s)\n", m.cfg.Embedder.Provider, m.cfg.Embedder.Model))
 
-	sb.WriteString(normalStyle.Render("Watcher status:   "))
+	sb.WriteString(m.styles.normal.Render("Watcher status:   "))
 	if m.watchRunning {
 		sb.WriteString(fmt.Sprintf("running (PID %d)\n", m.watchPID))
 	} else {
 		sb.WriteString("not running\n")
 	}
-	sb.WriteString(normalStyle.Render("Watcher logs:     "))
+	sb.WriteString(m.styles.normal.Render("Watcher logs:     "))
 	if m.watchLogFile == "" {
 		sb.WriteString("N/A\n")
 	} else {
 		sb.WriteString(fmt.Sprintf("%s\n", m.watchLogFile))
 	}
 
+	if len(m.stats.Directories) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.normal.Render(renderStatusBreakdown(m.stats)))
+	}
+	if len(m.excluded) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.dim.Render(renderBudgetExclusions(m.excluded)))
+	}
+
 	sb.WriteString("\n")
-	sb.WriteString(helpStyle.Render("[Enter] Browse files  [s] Token savings  [q] Quit"))
+	sb.WriteString(m.styles.help.Render("[Enter] Browse files  [s] Token savings  [q] Quit"))
 
-	return boxStyle.Render(sb.String())
+	return m.styles.box.Render(sb.String())
 }
 
 func (m model) viewFiles() string {
 	var sb strings.Builder
 
-	sb.WriteString(titleStyle.Render(fmt.Sprintf("Indexed Files (%d)", len(m.files))))
+	sb.WriteString(m.styles.title.Render(fmt.Sprintf("Indexed Files (%d)", len(m.files))))
 	sb.WriteString("\n\n")
 
 	// Calculate visible range
@@ -271,55 +289,47 @@ func (m model) viewFiles() string {
 		line := fmt.Sprintf("%-50s %3d chunks", truncatePath(f.Path, 50), f.ChunkCount)
 
 		if i == m.selectedFile {
-			sb.WriteString(selectedStyle.Render("> " + line))
+			sb.WriteString(m.styles.selected.Render("> " + line))
 		} else {
-			sb.WriteString(normalStyle.Render("  " + line))
+			sb.WriteString(m.styles.normal.Render("  " + line))
 		}
 		sb.WriteString("\n")
 	}
 
 	if len(m.files) > maxVisible {
-		sb.WriteString(dimStyle.Render(fmt.Sprintf("\n... showing %d-%d of %d files", start+1, end, len(m.files))))
+		sb.WriteString(m.styles.dim.Render(fmt.Sprintf("\n... showing %d-%d of %d files", start+1, end, len(m.files))))
 		sb.WriteString("\n")
 	}
 
 	sb.WriteString("\n")
-	sb.WriteString(helpStyle.Render("[Up/Down] Navigate  [Enter] View chunks  [Esc] Back  [q] Quit"))
+	sb.WriteString(m.styles.help.Render("[Up/Down] Navigate  [Enter] View chunks  [Esc] Back  [q] Quit"))
 
-	return boxStyle.Render(sb.String())
+	return m.styles.box.Render(sb.String())
 }
 
 func (m model) viewChunks() string {
 	var sb strings.Builder
 
 	if len(m.chunks) == 0 {
-		sb.WriteString(titleStyle.Render("No chunks"))
+		sb.WriteString(m.styles.title.Render("No chunks"))
 		sb.WriteString("\n\n")
-		sb.WriteString(helpStyle.Render("[Esc] Back  [q] Quit"))
-		return boxStyle.Render(sb.String())
+		sb.WriteString(m.styles.help.Render("[Esc] Back  [q] Quit"))
+		return m.styles.box.Render(sb.String())
 	}
 
 	filePath := m.files[m.selectedFile].Path
-	sb.WriteString(titleStyle.Render(fmt.Sprintf("%s (%d chunks)", filePath, len(m.chunks))))
+	sb.WriteString(m.styles.title.Render(fmt.Sprintf("%s (%d chunks)", filePath, len(m.chunks))))
 	sb.WriteString("\n\n")
 
 	chunk := m.chunks[m.selectedChunk]
-	sb.WriteString(normalStyle.Render(fmt.Sprintf("Chunk %d/%d  [Lines %d-%d]",
+	sb.WriteString(m.styles.normal.Render(fmt.Sprintf("Chunk %d/%d  [Lines %d-%d]",
 		m.selectedChunk+1, len(m.chunks), chunk.StartLine, chunk.EndLine)))
 	sb.WriteString("\n")
-	sb.WriteString(dimStyle.Render(strings.Repeat("-", 50)))
+	sb.WriteString(m.styles.dim.Render(strings.Repeat("-", 50)))
 	sb.WriteString("\n\n")
 
 	// Show chunk content (truncated)
-	content := chunk.Content
-	// Remove "File: xxx" prefix if present
-	if strings.HasPrefix(content, "File: ") {
-		if idx := strings.Index(content, "\n\n"); idx != -1 {
-			content = content[idx+2:]
-		}
-	}
-
-	lines := strings.Split(content, "\n")
+	lines := highlightLines(filePath, chunkBody(chunk.Content), m.styles.syntax)
 	maxLines := 12
 	if m.height > 0 {
 		maxLines = m.height - 15
@@ -330,39 +340,40 @@ func (m model) viewChunks() string {
 
 	for i, line := range lines {
 		if i >= maxLines {
-			sb.WriteString(dimStyle.Render("..."))
+			sb.WriteString(m.styles.dim.Render("..."))
 			sb.WriteString("\n")
 			break
 		}
 		// Truncate long lines
-		if len(line) > 70 {
-			line = line[:67] + "..."
+		line = ansi.Truncate(line, 70, "...")
+		if m.styles.syntax == "" {
+			line = m.styles.dim.Render(line)
 		}
-		sb.WriteString(dimStyle.Render(line))
+		sb.WriteString(line)
 		sb.WriteString("\n")
 	}
 
 	sb.WriteString("\n")
-	sb.WriteString(helpStyle.Render("[Up/Down] Navigate chunks  [Esc] Back to files  [q] Quit"))
+	sb.WriteString(m.styles.help.Render("[Up/Down] Navigate chunks  [Esc] Back to files  [q] Quit"))
 
-	return boxStyle.Render(sb.String())
+	return m.styles.box.Render(sb.String())
 }
 
 func (m model) viewTokenSavingsView() string {
 	var sb strings.Builder
 
-	sb.WriteString(titleStyle.Render("Token Savings"))
+	sb.WriteString(m.styles.title.Render("Token Savings"))
 	sb.WriteString("\n\n")
 
 	if m.savingsSummary == nil {
-		sb.WriteString(dimStyle.Render("No stats recorded yet."))
+		sb.WriteString(m.styles.dim.Render("No stats recorded yet."))
 		sb.WriteString("\n\n")
-		sb.WriteString(helpStyle.Render("[Esc] Back  [q] Quit"))
-		return boxStyle.Render(sb.String())
+		sb.WriteString(m.styles.help.Render("[Esc] Back  [q] Quit"))
+		return m.styles.box.Render(sb.String())
 	}
 
 	s := m.savingsSummary
-	label := normalStyle.Width(20)
+	label := m.styles.normal.Width(20)
 
 	sb.WriteString(label.Render("Queries"))
 	sb.WriteString(fmt.Sprintf("%d\n", s.TotalQueries))
@@ -373,7 +384,7 @@ func (m model) viewTokenSavingsView() string {
 	if s.CostSavedUSD != nil {
 		sb.WriteString(label.Render("Cost saved"))
 		sb.WriteString(fmt.Sprintf("~$%.4f", *s.CostSavedUSD))
-		sb.WriteString(dimStyle.Render("  (cloud provider)"))
+		sb.WriteString(m.styles.dim.Render("  (cloud provider)"))
 		sb.WriteString("\n")
 	}
 
@@ -383,10 +394,10 @@ func (m model) viewTokenSavingsView() string {
 		colQ := 10
 		colSaved := 16
 		colPct := 10
-		sb.WriteString(dimStyle.Render(fmt.Sprintf("%-*s %-*s %-*s %-*s",
+		sb.WriteString(m.styles.dim.Render(fmt.Sprintf("%-*s %-*s %-*s %-*s",
 			colDate, "Date", colQ, "Queries", colSaved, "Tokens saved", colPct, "Savings")))
 		sb.WriteString("\n")
-		sb.WriteString(dimStyle.Render(fmt.Sprintf("%-*s %-*s %-*s %-*s",
+		sb.WriteString(m.styles.dim.Render(fmt.Sprintf("%-*s %-*s %-*s %-*s",
 			colDate, "────────────────", colQ, "─────────", colSaved, "───────────────", colPct, "────────")))
 		sb.WriteString("\n")
 
@@ -419,18 +430,18 @@ func (m model) viewTokenSavingsView() string {
 				colPct-1, pct,
 			)
 			if i == m.savingsSelected {
-				sb.WriteString(selectedStyle.Render("> " + row))
+				sb.WriteString(m.styles.selected.Render("> " + row))
 			} else {
-				sb.WriteString(normalStyle.Render("  " + row))
+				sb.WriteString(m.styles.normal.Render("  " + row))
 			}
 			sb.WriteString("\n")
 		}
 	}
 
 	sb.WriteString("\n")
-	sb.WriteString(helpStyle.Render("[↑/↓] Navigate  [Esc] Back  [q] Quit"))
+	sb.WriteString(m.styles.help.Render("[↑/↓] Navigate  [Esc] Back  [q] Quit"))
 
-	return boxStyle.Render(sb.String())
+	return m.styles.box.Render(sb.String())
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -449,34 +460,11 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize store
-	var st store.VectorStore
-	switch cfg.Store.Backend {
-	case "gob":
-		indexPath := config.GetIndexPath(projectRoot)
-		gobStore := store.NewGOBStore(indexPath)
-		if err := gobStore.Load(ctx); err != nil {
-			return fmt.Errorf("failed to load index: %w", err)
-		}
-		st = gobStore
-	case "postgres":
-		var err error
-		st, err = store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
-		if err != nil {
-			return fmt.Errorf("failed to connect to postgres: %w", err)
-		}
-	case "qdrant":
-		collectionName := cfg.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = store.SanitizeCollectionName(projectRoot)
-		}
-		var err error
-		st, err = store.NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
-		if err != nil {
-			return fmt.Errorf("failed to connect to qdrant: %w", err)
-		}
-	default:
-		return fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
 	}
+
 	defer st.Close()
 
 	// Get index stats
@@ -485,6 +473,43 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get stats: %w", err)
 	}
 
+	watchStatus := resolveWatcherRuntimeStatus(projectRoot)
+	useUI := shouldUseStatusUI(isInteractiveTerminal(), statusNoUI || plainUIEnabled())
+
+	if !useUI {
+		if statusBreakdown {
+			files, err := st.ListFilesWithStats(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list files: %w", err)
+			}
+			indexStats.AddBreakdown(files)
+		}
+		fmt.Print(renderStatusSummary(cfg, indexStats, watchStatus))
+		if excluded := loadStatusBudgetExclusions(projectRoot); len(excluded) > 0 {
+			fmt.Print("\n" + renderBudgetExclusions(excluded))
+		}
+		return nil
+	}
+
+	m, err := newStatusModel(ctx, st, cfg, projectRoot, indexStats, watchStatus)
+	if err != nil {
+		return err
+	}
+
+	// Run TUI
+	_, err = runTUIProgram(m)
+	return err
+}
+
+// newStatusModel builds the interactive status browser for a loaded index.
+func newStatusModel(
+	ctx context.Context,
+	st store.VectorStore,
+	cfg *config.Config,
+	projectRoot string,
+	indexStats *store.IndexStats,
+	watchStatus watcherRuntimeStatus,
+) (model, error) {
 	// Load token savings stats (non-fatal)
 	var savingsSummary *stats.Summary
 	var savingsDays []stats.DaySummary
@@ -495,21 +520,13 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		savingsDays = stats.HistoryByDay(entries)
 	}
 
-	watchStatus := resolveWatcherRuntimeStatus(projectRoot)
-	useUI := shouldUseStatusUI(isInteractiveTerminal(), statusNoUI)
-
-	if !useUI {
-		fmt.Print(renderStatusSummary(cfg, indexStats, watchStatus))
-		return nil
-	}
-
-	files, err := loadStatusFiles(ctx, useUI, st.ListFilesWithStats)
+	files, err := loadStatusFiles(ctx, true, st.ListFilesWithStats)
 	if err != nil {
-		return fmt.Errorf("failed to list files: %w", err)
+		return model{}, fmt.Errorf("failed to list files: %w", err)
 	}
+	indexStats.AddBreakdown(files)
 
-	// Create model
-	m := model{
+	return model{
 		st:             st,
 		cfg:            cfg,
 		state:          viewStats,
@@ -522,12 +539,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		worktreeID:     watchStatus.worktreeID,
 		savingsSummary: savingsSummary,
 		savingsDays:    savingsDays,
-	}
-
-	// Run TUI
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err = p.Run()
-	return err
+		excluded:       loadStatusBudgetExclusions(projectRoot),
+		styles:         newStatusStyles(newTUITheme()),
+	}, nil
 }
 
 func formatBytes(b int64) string {
@@ -562,6 +576,20 @@ type watcherRuntimeStatus struct {
 }
 
 func resolveWatcherRuntimeStatus(projectRoot string) watcherRuntimeStatus {
+	cwd, err := os.Getwd()
+	var worktreeID string
+	if err == nil {
+		gitInfo, gitErr := git.Detect(cwd)
+		if gitErr == nil && gitInfo.WorktreeID != "" {
+			worktreeID = gitInfo.WorktreeID
+		}
+	}
+	return resolveWatcherRuntimeStatusFor(projectRoot, worktreeID)
+}
+
+// resolveWatcherRuntimeStatusFor reports the watcher of projectRoot, looked
+// up by worktreeID when the project is in a git repository.
+func resolveWatcherRuntimeStatusFor(projectRoot, worktreeID string) watcherRuntimeStatus {
 	status := watcherRuntimeStatus{}
 
 	logDirs, err := resolveWatcherCandidateLogDirs(projectRoot)
@@ -572,15 +600,6 @@ func resolveWatcherRuntimeStatus(projectRoot string) watcherRuntimeStatus {
 		return status
 	}
 
-	cwd, err := os.Getwd()
-	var worktreeID string
-	if err == nil {
-		gitInfo, gitErr := git.Detect(cwd)
-		if gitErr == nil && gitInfo.WorktreeID != "" {
-			worktreeID = gitInfo.WorktreeID
-		}
-	}
-
 	for idx, logDir := range logDirs {
 		status.logDir = logDir
 		status.worktreeID = worktreeID
@@ -652,6 +671,65 @@ func renderStatusSummary(cfg *config.Config, stats *store.IndexStats, watch watc
 	if watch.logFile != "" {
 		sb.WriteString(fmt.Sprintf("Watcher log: %s\n", watch.logFile))
 	}
+	if len(stats.Directories) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(renderStatusBreakdown(stats))
+	}
+	return sb.String()
+}
+
+// renderStatusBreakdown lists the largest top-level directories and
+// languages of the index by chunk count.
+func renderStatusBreakdown(stats *store.IndexStats) string {
+	var sb strings.Builder
+	writeGroups := func(title string, groups []store.GroupStats) {
+		sb.WriteString(title + ":\n")
+		for i, g := range groups {
+			if i == statusBreakdownRows {
+				sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(groups)-i))
+				break
+			}
+			share := 0.0
+			if stats.TotalChunks > 0 {
+				share = float64(g.Chunks) * 100 / float64(stats.TotalChunks)
+			}
+			sb.WriteString(fmt.Sprintf("  %-24s %6d chunks %5.1f%%  %5d files\n", truncatePath(g.Name, 24), g.Chunks, share, g.Files))
+		}
+	}
+	writeGroups("By directory", stats.Directories)
+	writeGroups("By language", stats.Languages)
+	return sb.String()
+}
+
+// loadStatusBudgetExclusions returns the files the last full index pass
+// left out to stay within indexer.budget. Read errors are non-fatal.
+func loadStatusBudgetExclusions(projectRoot string) []config.BudgetExclusion {
+	excluded, err := config.LoadBudgetExclusions(projectRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return excluded
+}
+
+// renderBudgetExclusions summarizes the files left out by the index budget,
+// listing the largest ones.
+func renderBudgetExclusions(excluded []config.BudgetExclusion) string {
+	chunks := 0
+	for _, e := range excluded {
+		chunks += e.Chunks
+	}
+	largest := append([]config.BudgetExclusion(nil), excluded...)
+	sort.SliceStable(largest, func(i, j int) bool { return largest[i].Chunks > largest[j].Chunks })
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Excluded by index budget: %d files (%d chunks)\n", len(excluded), chunks))
+	for i, e := range largest {
+		if i == statusBreakdownRows {
+			sb.WriteString(fmt.Sprintf("  ... and %d more\n", len(largest)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("  %-40s %6d chunks  %s\n", truncatePath(e.Path, 40), e.Chunks, e.Reason))
+	}
 	return sb.String()
 }
 
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// promptQdrantConfig asks for the Qdrant connection settings, offering
+// defaultEndpoint when none is entered. TLS defaults on for https and
+// Qdrant Cloud endpoints, and Qdrant Cloud clusters are told that an API
+// key is required.
+func promptQdrantConfig(reader *bufio.Reader, q *config.QdrantConfig, defaultEndpoint string) error {
+	fmt.Printf("Qdrant endpoint (host, URL, or Qdrant Cloud cluster URL) [%s]: ", defaultEndpoint)
+	endpoint, _ := reader.ReadString('\n')
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	q.Endpoint = endpoint
+	cloud := config.IsQdrantCloudEndpoint(endpoint)
+
+	fmt.Printf("Qdrant gRPC port [%d]: ", config.DefaultQdrantPort)
+	port, _ := reader.ReadString('\n')
+	port = strings.TrimSpace(port)
+	if port == "" {
+		q.Port = config.DefaultQdrantPort
+	} else {
+		portInt, err := strconv.Atoi(port)
+		if err != nil || portInt <= 0 {
+			return fmt.Errorf("invalid port number: %q", port)
+		}
+		q.Port = portInt
+	}
+
+	q.UseTLS = false
+	if q.TLSEnabled() {
+		// https and Qdrant Cloud endpoints always use TLS.
+		q.UseTLS = true
+		fmt.Println("TLS: enabled for this endpoint")
+	} else {
+		fmt.Print("Use TLS? (y/n) [n]: ")
+		useTLS, _ := reader.ReadString('\n')
+		useTLS = strings.TrimSpace(strings.ToLower(useTLS))
+		q.UseTLS = useTLS == "y" || useTLS == "yes"
+	}
+
+	fmt.Print("Collection name (optional, defaults to sanitized project path): ")
+	collection, _ := reader.ReadString('\n')
+	q.Collection = strings.TrimSpace(collection)
+
+	if cloud {
+		fmt.Print("API key (required for Qdrant Cloud): ")
+	} else {
+		fmt.Print("API key (optional): ")
+	}
+	apiKey, _ := reader.ReadString('\n')
+	q.APIKey = strings.TrimSpace(apiKey)
+	if cloud && q.APIKey == "" {
+		fmt.Println("Warning: Qdrant Cloud rejects requests without an API key; set store.qdrant.api_key before indexing.")
+	}
+	return nil
+}
+
+// promptWeaviateConfig asks for the Weaviate connection settings.
+func promptWeaviateConfig(reader *bufio.Reader, w *config.WeaviateConfig) {
+	fmt.Printf("Weaviate endpoint [%s]: ", config.DefaultWeaviateEndpoint)
+	endpoint, _ := reader.ReadString('\n')
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		endpoint = config.DefaultWeaviateEndpoint
+	}
+	w.Endpoint = endpoint
+
+	fmt.Print("Class name (optional, defaults to sanitized project path): ")
+	class, _ := reader.ReadString('\n')
+	w.Class = strings.TrimSpace(class)
+
+	fmt.Print("API key (optional, for Weaviate Cloud): ")
+	apiKey, _ := reader.ReadString('\n')
+	w.APIKey = strings.TrimSpace(apiKey)
+}
+
+// promptRedisConfig asks for the Redis connection settings.
+func promptRedisConfig(reader *bufio.Reader, r *config.RedisConfig) error {
+	fmt.Printf("Redis URL (redis://[:password@]host:port[/db], rediss:// for TLS) [%s]: ", config.DefaultRedisURL)
+	redisURL, _ := reader.ReadString('\n')
+	redisURL = strings.TrimSpace(redisURL)
+	if redisURL == "" {
+		redisURL = config.DefaultRedisURL
+	}
+	r.URL = redisURL
+
+	fmt.Print("Index name (optional, defaults to sanitized project path): ")
+	index, _ := reader.ReadString('\n')
+	r.Index = strings.TrimSpace(index)
+
+	fmt.Print("Expire keys after (e.g. 24h, empty to keep them): ")
+	ttl, _ := reader.ReadString('\n')
+	ttl = strings.TrimSpace(ttl)
+	r.TTLSeconds = 0
+	if ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d < time.Second {
+			return fmt.Errorf("invalid expiry %q: use a duration of at least 1s, such as 24h", ttl)
+		}
+		r.TTLSeconds = int(d / time.Second)
+	}
+	return nil
+}
+
+// promptOpenSearchConfig asks for the OpenSearch connection settings.
+func promptOpenSearchConfig(reader *bufio.Reader, o *config.OpenSearchConfig) {
+	fmt.Printf("OpenSearch endpoint [%s]: ", config.DefaultOpenSearchEndpoint)
+	endpoint, _ := reader.ReadString('\n')
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		endpoint = config.DefaultOpenSearchEndpoint
+	}
+	o.Endpoint = endpoint
+
+	fmt.Print("Index name (optional, defaults to sanitized project path): ")
+	index, _ := reader.ReadString('\n')
+	o.Index = strings.TrimSpace(index)
+
+	fmt.Print("Username (optional, for basic auth): ")
+	username, _ := reader.ReadString('\n')
+	o.Username = strings.TrimSpace(username)
+	o.Password = ""
+	if o.Username != "" {
+		fmt.Print("Password: ")
+		password, _ := reader.ReadString('\n')
+		o.Password = strings.TrimSpace(password)
+	}
+}
+
+// storeConnectionTimeout bounds the connection test run by the wizards.
+const storeConnectionTimeout = 15 * time.Second
+
+// offerConnectionTest asks whether to connect to the configured backend now
+// and reports the outcome. connect opens and closes the store; a failure is
+// only a warning since the server may not be running yet.
+func offerConnectionTest(reader *bufio.Reader, backend string, connect func(ctx context.Context) error) {
+	fmt.Printf("Test the %s connection now? [Y/n]: ", backend)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+	if input != "" && input != "y" && input != "yes" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeConnectionTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := connect(ctx); err != nil {
+		fmt.Printf("%s connection FAILED: %v\n", backend, err)
+		fmt.Println("The configuration is saved anyway; fix the settings before indexing.")
+		return
+	}
+	fmt.Printf("%s connection ok in %s\n", backend, time.Since(start).Round(time.Millisecond))
+}
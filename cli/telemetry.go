@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/stats"
+)
+
+// untimedCommands run until stopped, so their duration says nothing about
+// performance. The watcher records its own scans instead.
+var untimedCommands = map[string]bool{
+	"watch":     true,
+	"mcp-serve": true,
+	"serve":     true,
+	"tui":       true,
+}
+
+// recordCommandTelemetry records how long cmd took when the project in the
+// current directory has opted into telemetry.
+func recordCommandTelemetry(cmd *cobra.Command, start time.Time, err error) {
+	if cmd == nil || !cmd.HasParent() || untimedCommands[cmd.Name()] {
+		return
+	}
+	projectRoot, findErr := config.FindProjectRoot()
+	if findErr != nil {
+		return
+	}
+	cfg, loadErr := config.Load(projectRoot)
+	if loadErr != nil {
+		return
+	}
+	operation := "cli." + strings.ReplaceAll(strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" "), " ", ".")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_ = stats.NewTelemetryRecorder(projectRoot, cfg.Telemetry.Enabled).Record(ctx, operation, start, err != nil)
+}
+
+// recordTelemetry fires a goroutine to record the timing of operation
+// without blocking, when cfg opts into telemetry.
+func recordTelemetry(projectRoot string, cfg *config.Config, operation string, start time.Time, err error) {
+	if cfg == nil || !cfg.Telemetry.Enabled {
+		return
+	}
+	rec := stats.NewTelemetryRecorder(projectRoot, true)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_ = rec.Record(ctx, operation, start, err != nil)
+	}()
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// similarTestsLimit is the number of test chunks a semantic search for the
+// target returns.
+const similarTestsLimit = 20
+
+var (
+	testsForLimit      int
+	testsForNoSemantic bool
+)
+
+var testsForCmd = &cobra.Command{
+	Use:   "tests-for <symbol|file>",
+	Short: "List the tests most likely covering a symbol or file",
+	Long: `List the tests most likely covering a function, method, type or file,
+to decide which tests to run after an edit. Each test is scored by:
+
+  name      the test is named after the target, as TestServer_Start
+  file      the test file is named after the target's file, as server_test.go
+  calls     the test references the target
+  indirect  the test calls a function that calls the target
+  similar   the test code is semantically similar to the target
+
+The target is a file path relative to the project root when the file is
+indexed, or a symbol name matched like trace commands do. The semantic
+signal uses the configured embedder and vector index; it is skipped with
+--no-semantic and in workspace mode. The best 10 tests are shown unless
+--limit says otherwise.
+
+Examples:
+  grepai tests-for Server.Start
+  grepai tests-for internal/auth/session.go
+  grepai tests-for ParseConfig --no-semantic --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTestsFor,
+}
+
+func init() {
+	testsForCmd.Flags().BoolVar(&traceJSON, "json", false, "Output results in JSON format")
+	testsForCmd.Flags().BoolVarP(&traceTOON, "toon", "t", false, "Output results in TOON format (token-efficient for AI agents)")
+	testsForCmd.MarkFlagsMutuallyExclusive("json", "toon")
+	testsForCmd.Flags().StringVar(&traceWorkspace, "workspace", "", "Workspace name for cross-project tests")
+	testsForCmd.Flags().StringVar(&traceProject, "project", "", "Project name within workspace (requires --workspace)")
+	testsForCmd.Flags().BoolVar(&testsForNoSemantic, "no-semantic", false, "Skip semantic similarity, using names and references only")
+	testsForCmd.Flags().IntVar(&testsForLimit, "limit", 10, "Maximum number of tests to return (0 = no limit)")
+	testsForCmd.Flags().IntVar(&traceOffset, "offset", 0, "Number of tests to skip")
+
+	rootCmd.AddCommand(testsForCmd)
+}
+
+func runTestsFor(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	page := trace.PageOptions{Limit: testsForLimit, Offset: traceOffset}
+	if err := page.Validate(); err != nil {
+		return err
+	}
+
+	stores, projectRoot, err := loadTraceStores(ctx)
+	if err != nil {
+		return err
+	}
+	defer trace.CloseSymbolStores(stores)
+
+	query := args[0]
+	if projectRoot != "" && filepath.IsAbs(query) {
+		if rel, err := filepath.Rel(projectRoot, query); err == nil {
+			query = rel
+		}
+	}
+	target, err := trace.ResolveTestTarget(ctx, stores, query)
+	if err != nil {
+		return err
+	}
+
+	var similar []trace.SimilarTest
+	if !testsForNoSemantic && projectRoot != "" {
+		similar, err = findSimilarTests(ctx, projectRoot, target.Query())
+		if err != nil {
+			log.Printf("Warning: semantic similarity skipped: %v", err)
+		}
+	}
+
+	result, err := trace.FindTestsFor(ctx, stores, target, similar, page)
+	if err != nil {
+		return fmt.Errorf("failed to find tests: %w", err)
+	}
+
+	if traceJSON || traceTOON {
+		return outputTraceValue(result)
+	}
+	return displayTestsForResult(result)
+}
+
+// findSimilarTests searches the project index for test code similar to
+// query. Boosting is left out, since it penalizes test files.
+func findSimilarTests(ctx context.Context, projectRoot, query string) ([]trace.SimilarTest, error) {
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := config.CheckProjectIndexMetadata(projectRoot, cfg); err != nil {
+		return nil, err
+	}
+	emb, err := embedder.NewFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	defer emb.Close()
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+	docsEmb, err := embedder.NewDocsFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize docs embedder: %w", err)
+	}
+
+	searcher := search.NewSearcher(st, emb, config.SearchConfig{})
+	if docsEmb != nil {
+		defer docsEmb.Close()
+		searcher.SetDocsEmbedder(docsEmb, cfg.Embedder.Docs.Model)
+	}
+	searcher.SetChunkFilter(func(c store.Chunk) bool {
+		return trace.IsTestFile(c.FilePath)
+	})
+	results, err := searcher.Search(ctx, query, similarTestsLimit, "")
+	if err != nil {
+		return nil, err
+	}
+	return similarTests(results), nil
+}
+
+// similarTests converts search results to similar tests.
+func similarTests(results []store.SearchResult) []trace.SimilarTest {
+	similar := make([]trace.SimilarTest, len(results))
+	for i, r := range results {
+		similar[i] = trace.SimilarTest{File: r.Chunk.FilePath, StartLine: r.Chunk.StartLine, EndLine: r.Chunk.EndLine, Score: r.Score}
+	}
+	return similar
+}
+
+func displayTestsForResult(result *trace.TestsForResult) error {
+	fmt.Printf("Tests for %s (%d)\n", result.Target, result.Total)
+	fmt.Println(strings.Repeat("-", 60))
+
+	if result.Total == 0 {
+		fmt.Println("No tests found.")
+		return nil
+	}
+
+	for i, test := range result.Tests {
+		location := fmt.Sprintf("%s:%d", test.File, test.Line)
+		name := test.Name
+		if name == "" {
+			location, name = test.File, "(whole file)"
+		}
+		if test.Project != "" {
+			location = "[" + test.Project + "] " + location
+		}
+		fmt.Printf("%d. %s  %s\n", result.Offset+i+1, name, location)
+		fmt.Printf("   score %.2f: %s\n", test.Score, strings.Join(test.Reasons, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// defaultTodoSearchLimit is the number of todos a search returns without
+// --limit.
+const defaultTodoSearchLimit = 10
+
+// uncommittedOwner groups todos on lines git blame has no commit for.
+const uncommittedOwner = "(uncommitted)"
+
+var (
+	todoTags    string
+	todoByOwner bool
+	todoLimit   int
+)
+
+// todoResult is the output of the todo command.
+type todoResult struct {
+	Query  string           `json:"query,omitempty"`
+	Total  int              `json:"total"`
+	Counts map[string]int   `json:"counts"` // todos per tag
+	Todos  []trace.Todo     `json:"todos,omitempty"`
+	Owners []todoOwnerGroup `json:"owners,omitempty"`
+}
+
+// todoOwnerGroup is the todos of one owner, with --by-owner.
+type todoOwnerGroup struct {
+	Owner string       `json:"owner"`
+	Todos []trace.Todo `json:"todos"`
+}
+
+var todoCmd = &cobra.Command{
+	Use:   "todo [query]",
+	Short: "List or search TODO, FIXME, HACK and XXX comments",
+	Long: `List the TODO, FIXME, HACK and XXX comments of the project with the
+function they are in. 'grepai watch' collects them from every indexed file
+and embeds them once.
+
+With a query, todos are ranked by semantic similarity to it, embedding only
+the query with the configured embedder, and the best 10 are shown unless
+--limit says otherwise.
+--by-owner groups todos by the last author of their line (git blame).
+
+Examples:
+  grepai todo
+  grepai todo --tag FIXME,HACK
+  grepai todo "flaky retry logic"
+  grepai todo --by-owner --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTodo,
+}
+
+func init() {
+	todoCmd.Flags().StringVar(&todoTags, "tag", "", "Comma-separated tags: TODO, FIXME, HACK, XXX (default: all)")
+	todoCmd.Flags().BoolVar(&todoByOwner, "by-owner", false, "Group todos by the last author of their line (git blame)")
+	todoCmd.Flags().IntVar(&todoLimit, "limit", 0, "Maximum number of todos to return (0 = all, or 10 with a query)")
+	todoCmd.Flags().BoolVar(&traceJSON, "json", false, "Output results in JSON format")
+	todoCmd.Flags().BoolVarP(&traceTOON, "toon", "t", false, "Output results in TOON format (token-efficient for AI agents)")
+	todoCmd.MarkFlagsMutuallyExclusive("json", "toon")
+
+	rootCmd.AddCommand(todoCmd)
+}
+
+func runTodo(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if todoLimit < 0 {
+		return fmt.Errorf("limit must be >= 0")
+	}
+	tags, err := trace.ParseTodoTags(todoTags)
+	if err != nil {
+		return err
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	todoStore := trace.NewTodoStore(config.GetTodoIndexPath(projectRoot))
+	if err := todoStore.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load todo index: %w", err)
+	}
+	if !todoStore.Exists() {
+		return fmt.Errorf("todo index is empty. Run 'grepai watch' first to build the index")
+	}
+	todos := todoStore.Todos(tags)
+
+	result := todoResult{Counts: make(map[string]int)}
+	limit := todoLimit
+	if len(args) > 0 {
+		result.Query = args[0]
+		if limit == 0 {
+			limit = defaultTodoSearchLimit
+		}
+		if err := rankTodos(ctx, projectRoot, result.Query, todos); err != nil {
+			return err
+		}
+	}
+	if limit > 0 && len(todos) > limit {
+		todos = todos[:limit]
+	}
+	result.Total = len(todos)
+	for _, todo := range todos {
+		result.Counts[todo.Tag]++
+	}
+
+	if todoByOwner {
+		result.Owners = groupTodosByOwner(ctx, projectRoot, todos)
+	} else {
+		result.Todos = todos
+	}
+
+	if traceJSON || traceTOON {
+		return outputTraceValue(result)
+	}
+	return displayTodoResult(result, projectRoot)
+}
+
+// loadTodoStore loads the todo index of a project for an indexer to fill,
+// starting from an empty one when it cannot be read.
+func loadTodoStore(ctx context.Context, projectRoot string) *trace.TodoStore {
+	todos := trace.NewTodoStore(config.GetTodoIndexPath(projectRoot))
+	if err := todos.Load(ctx); err != nil {
+		log.Printf("Warning: failed to load todo index for %s: %v", projectRoot, err)
+	}
+	return todos
+}
+
+// rankTodos scores todos by semantic similarity to query, using the
+// embeddings of the todo index, and sorts them, most similar first.
+func rankTodos(ctx context.Context, projectRoot, query string, todos []trace.Todo) error {
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	emb, err := embedder.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	defer emb.Close()
+
+	vectors := make([][]float32, len(todos))
+	for i, todo := range todos {
+		vectors[i] = todo.Vector
+	}
+	scores, err := search.RankVectors(ctx, emb, query, vectors)
+	if err != nil {
+		return fmt.Errorf("failed to rank todos: %w", err)
+	}
+	for i := range todos {
+		todos[i].Score = scores[i]
+	}
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].Score > todos[j].Score
+	})
+	return nil
+}
+
+// groupTodosByOwner sets the owner of each todo from git blame and groups
+// them, owners with the most todos first.
+func groupTodosByOwner(ctx context.Context, projectRoot string, todos []trace.Todo) []todoOwnerGroup {
+	blames := search.NewProjectBlames(projectRoot)
+	byOwner := make(map[string]*todoOwnerGroup)
+	var groups []*todoOwnerGroup
+	for _, todo := range todos {
+		todo.Owner = uncommittedOwner
+		if change := blames.LastChange(ctx, todo.File, todo.Line, todo.Line); change != nil {
+			todo.Owner = change.Author
+		}
+		group := byOwner[todo.Owner]
+		if group == nil {
+			group = &todoOwnerGroup{Owner: todo.Owner}
+			byOwner[todo.Owner] = group
+			groups = append(groups, group)
+		}
+		group.Todos = append(group.Todos, todo)
+	}
+	if err := blames.Save(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Todos) != len(groups[j].Todos) {
+			return len(groups[i].Todos) > len(groups[j].Todos)
+		}
+		return groups[i].Owner < groups[j].Owner
+	})
+	result := make([]todoOwnerGroup, len(groups))
+	for i, group := range groups {
+		result[i] = *group
+	}
+	return result
+}
+
+func displayTodoResult(result todoResult, projectRoot string) error {
+	if result.Query != "" {
+		fmt.Printf("Todos matching %q (%d)", result.Query, result.Total)
+	} else {
+		fmt.Printf("Todos (%d)", result.Total)
+	}
+	if len(result.Counts) > 0 {
+		var parts []string
+		for _, tag := range trace.TodoTags {
+			if n := result.Counts[tag]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%s: %d", tag, n))
+			}
+		}
+		fmt.Printf(" [%s]", strings.Join(parts, ", "))
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", 60))
+
+	if result.Total == 0 {
+		fmt.Println("No todos found.")
+		return nil
+	}
+
+	if len(result.Owners) > 0 {
+		for _, group := range result.Owners {
+			fmt.Printf("\n%s (%d)\n", group.Owner, len(group.Todos))
+			displayTodos(group.Todos, projectRoot, "  ")
+		}
+		return nil
+	}
+	displayTodos(result.Todos, projectRoot, "")
+	return nil
+}
+
+func displayTodos(todos []trace.Todo, projectRoot, indent string) {
+	for i, todo := range todos {
+		file := todo.File
+		if projectRoot != "" {
+			if rel, err := filepath.Rel(projectRoot, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+		fmt.Printf("%s%d. %-5s %s:%d", indent, i+1, todo.Tag, file, todo.Line)
+		if todo.Symbol != "" {
+			fmt.Printf(" in %s", todo.Symbol)
+		}
+		if todo.Score > 0 {
+			fmt.Printf(" (%.2f)", todo.Score)
+		}
+		fmt.Println()
+		if todo.Text != todo.Tag {
+			fmt.Printf("%s   %s\n", indent, todo.Text)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/embedder"
 	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/search"
 	gstats "github.com/yoanbernabeu/grepai/stats"
 	"github.com/yoanbernabeu/grepai/trace"
 )
@@ -80,6 +82,12 @@ var (
 	traceUI        bool
 	traceWorkspace string
 	traceProject   string
+	traceLimit     int
+	traceOffset    int
+	traceSort      string
+	traceBlame     bool
+	traceRemote    string
+	traceScope     string
 )
 
 var runTraceActionCardUIRunner = runTraceActionCardUI
@@ -106,7 +114,8 @@ var traceCallersCmd = &cobra.Command{
 Examples:
   grepai trace callers "Login"
   grepai trace callers "HandleRequest" --json
-  grepai trace callers "ProcessOrder" --mode precise`,
+  grepai trace callers "ProcessOrder" --mode precise
+  grepai trace callers "Log" --sort count --limit 20 --offset 20`,
 	Args: cobra.ExactArgs(1),
 	RunE: runTraceCallers,
 }
@@ -118,7 +127,8 @@ var traceCalleesCmd = &cobra.Command{
 
 Examples:
   grepai trace callees "Login"
-  grepai trace callees "HandleRequest" --json`,
+  grepai trace callees "HandleRequest" --json
+  grepai trace callees "HandleRequest" --sort line --limit 10`,
 	Args: cobra.ExactArgs(1),
 	RunE: runTraceCallees,
 }
@@ -147,6 +157,14 @@ func init() {
 		cmd.MarkFlagsMutuallyExclusive("toon", "ui")
 		cmd.Flags().StringVar(&traceWorkspace, "workspace", "", "Workspace name for cross-project trace")
 		cmd.Flags().StringVar(&traceProject, "project", "", "Project name within workspace (requires --workspace)")
+		cmd.Flags().BoolVar(&traceBlame, "blame", false, "Annotate symbols with the last author and commit date (git blame)")
+		cmd.Flags().StringVar(&traceRemote, "remote", "", remoteFlagUsage)
+		cmd.Flags().StringVar(&traceScope, "scope", "", "Named scope from config.yaml keeping only results in its files")
+	}
+	for _, cmd := range []*cobra.Command{traceCallersCmd, traceCalleesCmd} {
+		cmd.Flags().IntVar(&traceLimit, "limit", 0, "Maximum number of results to return (0 = no limit)")
+		cmd.Flags().IntVar(&traceOffset, "offset", 0, "Number of results to skip")
+		cmd.Flags().StringVar(&traceSort, "sort", trace.SortByFile, "Sort results by: file, line, project, count")
 	}
 	traceGraphCmd.Flags().IntVarP(&traceDepth, "depth", "d", 2, "Maximum depth for graph traversal")
 
@@ -164,6 +182,19 @@ func runTraceCallers(cmd *cobra.Command, args []string) error {
 	if traceProject != "" && traceWorkspace == "" {
 		return fmt.Errorf("--project requires --workspace")
 	}
+	if url := remoteURL(traceRemote); url != "" {
+		return runRemoteTrace(ctx, url, "grepai_trace_callers", symbolName, traceViewCallers)
+	}
+	if traceBlame && traceWorkspace != "" {
+		return fmt.Errorf("--blame is not supported with --workspace")
+	}
+	if traceScope != "" && traceWorkspace != "" {
+		return fmt.Errorf("--scope is not supported with --workspace")
+	}
+	page := tracePageOptions()
+	if err := page.Validate(); err != nil {
+		return err
+	}
 
 	// Workspace mode: aggregate across projects
 	if traceWorkspace != "" {
@@ -173,56 +204,33 @@ func runTraceCallers(cmd *cobra.Command, args []string) error {
 		}
 		defer trace.CloseSymbolStores(stores)
 
-		result := trace.TraceResult{Query: symbolName, Mode: traceMode}
-		for _, ss := range stores {
-			refs, err := ss.LookupCallers(ctx, symbolName)
-			if err != nil {
-				log.Printf("Warning: failed to lookup callers of %q: %v", symbolName, err)
-			}
-			symbols, err := ss.LookupSymbol(ctx, symbolName)
-			if err != nil {
-				log.Printf("Warning: failed to lookup symbol %q: %v", symbolName, err)
-			}
-			if len(symbols) > 0 && result.Symbol == nil {
-				result.Symbol = pickBestTargetSymbol(symbols, refs)
-			}
-			for _, ref := range refs {
-				callerSyms, err := ss.LookupSymbol(ctx, ref.CallerName)
-				if err != nil {
-					log.Printf("Warning: failed to lookup caller symbol %q: %v", ref.CallerName, err)
-				}
-				var callerSym trace.Symbol
-				if len(callerSyms) > 0 {
-					if picked := pickBestSymbolForFile(callerSyms, ref.CallerFile); picked != nil {
-						callerSym = *picked
-					} else {
-						callerSym = callerSyms[0]
-					}
-				} else {
-					callerSym = trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
-				}
-				result.Callers = append(result.Callers, trace.CallerInfo{
-					Symbol: callerSym,
-					CallSite: trace.CallSite{
-						File:    ref.File,
-						Line:    ref.Line,
-						Context: ref.Context,
-					},
-				})
-			}
+		res, err := resolveTraceQuery(ctx, stores, symbolName)
+		if err != nil {
+			return err
+		}
+		if res.Ambiguous() {
+			return outputTraceResult(ambiguousTraceResult(symbolName, res), traceViewCallers, nil)
 		}
 
-		if result.Symbol == nil {
-			return outputTraceResult(result, traceViewCallers)
+		result := trace.TraceResult{Query: symbolName, Mode: traceMode}
+		for _, ss := range stores {
+			collectCallers(ctx, ss, res.Name, &result)
 		}
+		trace.ApplyResolution(&result, res)
+		collectImplementations(ctx, trace.DispatchStores(stores), res, &result)
+		trace.ApplyPage(&result, page)
 
-		return outputTraceResult(result, traceViewCallers)
+		return outputTraceResult(result, traceViewCallers, newTraceExpander(ctx, stores))
 	}
 
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
 		return err
 	}
+	keepFile, err := traceScopeFilter(projectRoot)
+	if err != nil {
+		return err
+	}
 
 	// Initialize symbol store
 	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
@@ -253,19 +261,27 @@ func runTraceCallers(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("symbol index is empty. Run 'grepai watch' first to build the index")
 	}
 
+	res, err := resolveTraceQuery(ctx, []trace.SymbolStore{symbolStore}, symbolName)
+	if err != nil {
+		return err
+	}
+	if res.Ambiguous() {
+		return outputTraceResult(ambiguousTraceResult(symbolName, res), traceViewCallers, nil)
+	}
+
 	// Lookup symbol
-	symbols, err := symbolStore.LookupSymbol(ctx, symbolName)
+	symbols, err := symbolStore.LookupSymbol(ctx, res.Name)
 	if err != nil {
 		return fmt.Errorf("failed to lookup symbol: %w", err)
 	}
 
 	if len(symbols) == 0 {
 		emptyResult := trace.TraceResult{Query: symbolName, Mode: traceMode}
-		return outputTraceResult(emptyResult, traceViewCallers)
+		return outputTraceResult(emptyResult, traceViewCallers, nil)
 	}
 
 	// Find callers
-	refs, err := symbolStore.LookupCallers(ctx, symbolName)
+	refs, err := symbolStore.LookupCallers(ctx, res.Name)
 	if err != nil {
 		return fmt.Errorf("failed to lookup callers: %w", err)
 	}
@@ -307,6 +323,8 @@ func runTraceCallers(cmd *cobra.Command, args []string) error {
 		})
 	}
 
+	trace.ApplyResolution(&result, res)
+
 	// Enrich with RPG feature paths
 	cfg, err := config.Load(projectRoot)
 	if err != nil {
@@ -315,8 +333,18 @@ func runTraceCallers(cmd *cobra.Command, args []string) error {
 	if cfg != nil {
 		enrichTraceWithRPG(projectRoot, cfg, &result)
 	}
+	if cfg == nil || trace.DispatchEnabled(cfg.Trace.Dispatch) {
+		collectImplementations(ctx, []trace.SymbolStore{symbolStore}, res, &result)
+	}
 
-	return outputAndRecord(result, traceViewCallers, projectRoot, gstats.TraceCallers, len(result.Callers))
+	trace.FilterFiles(&result, keepFile)
+	trace.ApplyPage(&result, page)
+	enrichTraceWithOwners(projectRoot, &result)
+	enrichTraceWithCoverage(projectRoot, &result)
+	if traceBlame {
+		enrichTraceWithBlame(ctx, projectRoot, &result)
+	}
+	return outputAndRecord(result, traceViewCallers, projectRoot, gstats.TraceCallers, len(result.Callers), newTraceExpander(ctx, []trace.SymbolStore{symbolStore}))
 }
 
 func runTraceCallees(cmd *cobra.Command, args []string) error {
@@ -326,6 +354,19 @@ func runTraceCallees(cmd *cobra.Command, args []string) error {
 	if traceProject != "" && traceWorkspace == "" {
 		return fmt.Errorf("--project requires --workspace")
 	}
+	if url := remoteURL(traceRemote); url != "" {
+		return runRemoteTrace(ctx, url, "grepai_trace_callees", symbolName, traceViewCallees)
+	}
+	if traceBlame && traceWorkspace != "" {
+		return fmt.Errorf("--blame is not supported with --workspace")
+	}
+	if traceScope != "" && traceWorkspace != "" {
+		return fmt.Errorf("--scope is not supported with --workspace")
+	}
+	page := tracePageOptions()
+	if err := page.Validate(); err != nil {
+		return err
+	}
 
 	// Workspace mode: aggregate across projects
 	if traceWorkspace != "" {
@@ -335,54 +376,32 @@ func runTraceCallees(cmd *cobra.Command, args []string) error {
 		}
 		defer trace.CloseSymbolStores(stores)
 
-		result := trace.TraceResult{Query: symbolName, Mode: traceMode}
-		for _, ss := range stores {
-			symbols, err := ss.LookupSymbol(ctx, symbolName)
-			if err != nil {
-				log.Printf("Warning: failed to lookup symbol %q: %v", symbolName, err)
-			}
-			if len(symbols) > 0 && result.Symbol == nil {
-				result.Symbol = &symbols[0]
-			}
-			if len(symbols) > 0 {
-				refs, err := ss.LookupCallees(ctx, symbolName, symbols[0].File)
-				if err != nil {
-					log.Printf("Warning: failed to lookup callees of %q: %v", symbolName, err)
-				}
-				for _, ref := range refs {
-					calleeSyms, err := ss.LookupSymbol(ctx, ref.SymbolName)
-					if err != nil {
-						log.Printf("Warning: failed to lookup callee symbol %q: %v", ref.SymbolName, err)
-					}
-					var calleeSym trace.Symbol
-					if len(calleeSyms) > 0 {
-						calleeSym = calleeSyms[0]
-					} else {
-						calleeSym = trace.Symbol{Name: ref.SymbolName}
-					}
-					result.Callees = append(result.Callees, trace.CalleeInfo{
-						Symbol: calleeSym,
-						CallSite: trace.CallSite{
-							File:    ref.File,
-							Line:    ref.Line,
-							Context: ref.Context,
-						},
-					})
-				}
-			}
+		res, err := resolveTraceQuery(ctx, stores, symbolName)
+		if err != nil {
+			return err
+		}
+		if res.Ambiguous() {
+			return outputTraceResult(ambiguousTraceResult(symbolName, res), traceViewCallees, nil)
 		}
 
-		if result.Symbol == nil {
-			return outputTraceResult(result, traceViewCallees)
+		result := trace.TraceResult{Query: symbolName, Mode: traceMode}
+		for _, ss := range stores {
+			collectCallees(ctx, ss, res.Name, &result)
 		}
+		trace.ApplyResolution(&result, res)
+		trace.ApplyPage(&result, page)
 
-		return outputTraceResult(result, traceViewCallees)
+		return outputTraceResult(result, traceViewCallees, newTraceExpander(ctx, stores))
 	}
 
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
 		return err
 	}
+	keepFile, err := traceScopeFilter(projectRoot)
+	if err != nil {
+		return err
+	}
 
 	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
 	if err := symbolStore.Load(ctx); err != nil {
@@ -412,19 +431,27 @@ func runTraceCallees(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("symbol index is empty. Run 'grepai watch' first to build the index")
 	}
 
+	res, err := resolveTraceQuery(ctx, []trace.SymbolStore{symbolStore}, symbolName)
+	if err != nil {
+		return err
+	}
+	if res.Ambiguous() {
+		return outputTraceResult(ambiguousTraceResult(symbolName, res), traceViewCallees, nil)
+	}
+
 	// Lookup symbol
-	symbols, err := symbolStore.LookupSymbol(ctx, symbolName)
+	symbols, err := symbolStore.LookupSymbol(ctx, res.Name)
 	if err != nil {
 		return fmt.Errorf("failed to lookup symbol: %w", err)
 	}
 
 	if len(symbols) == 0 {
 		emptyResult := trace.TraceResult{Query: symbolName, Mode: traceMode}
-		return outputTraceResult(emptyResult, traceViewCallees)
+		return outputTraceResult(emptyResult, traceViewCallees, nil)
 	}
 
 	// Find callees
-	refs, err := symbolStore.LookupCallees(ctx, symbolName, symbols[0].File)
+	refs, err := symbolStore.LookupCallees(ctx, res.Name, symbols[0].File)
 	if err != nil {
 		return fmt.Errorf("failed to lookup callees: %w", err)
 	}
@@ -456,6 +483,8 @@ func runTraceCallees(cmd *cobra.Command, args []string) error {
 		})
 	}
 
+	trace.ApplyResolution(&result, res)
+
 	// Enrich with RPG feature paths
 	cfg, err := config.Load(projectRoot)
 	if err != nil {
@@ -465,7 +494,14 @@ func runTraceCallees(cmd *cobra.Command, args []string) error {
 		enrichTraceWithRPG(projectRoot, cfg, &result)
 	}
 
-	return outputAndRecord(result, traceViewCallees, projectRoot, gstats.TraceCallees, len(result.Callees))
+	trace.FilterFiles(&result, keepFile)
+	trace.ApplyPage(&result, page)
+	enrichTraceWithOwners(projectRoot, &result)
+	enrichTraceWithCoverage(projectRoot, &result)
+	if traceBlame {
+		enrichTraceWithBlame(ctx, projectRoot, &result)
+	}
+	return outputAndRecord(result, traceViewCallees, projectRoot, gstats.TraceCallees, len(result.Callees), newTraceExpander(ctx, []trace.SymbolStore{symbolStore}))
 }
 
 func runTraceGraph(cmd *cobra.Command, args []string) error {
@@ -475,6 +511,15 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 	if traceProject != "" && traceWorkspace == "" {
 		return fmt.Errorf("--project requires --workspace")
 	}
+	if url := remoteURL(traceRemote); url != "" {
+		return runRemoteTrace(ctx, url, "grepai_trace_graph", symbolName, traceViewGraph)
+	}
+	if traceBlame && traceWorkspace != "" {
+		return fmt.Errorf("--blame is not supported with --workspace")
+	}
+	if traceScope != "" && traceWorkspace != "" {
+		return fmt.Errorf("--scope is not supported with --workspace")
+	}
 
 	// Workspace mode: aggregate call graphs across projects
 	if traceWorkspace != "" {
@@ -484,9 +529,17 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 		}
 		defer trace.CloseSymbolStores(stores)
 
+		res, err := resolveTraceQuery(ctx, stores, symbolName)
+		if err != nil {
+			return err
+		}
+		if res.Ambiguous() {
+			return outputTraceResult(ambiguousTraceResult(symbolName, res), traceViewGraph, nil)
+		}
+
 		// Merge graphs from all project stores
 		merged := &trace.CallGraph{
-			Root:  symbolName,
+			Root:  res.Name,
 			Nodes: make(map[string]trace.Symbol),
 			Edges: []trace.CallEdge{},
 			Depth: traceDepth,
@@ -494,7 +547,7 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 		edgeSeen := make(map[string]bool)
 
 		for _, ss := range stores {
-			graph, graphErr := ss.GetCallGraph(ctx, symbolName, traceDepth)
+			graph, graphErr := ss.GetCallGraph(ctx, res.Name, traceDepth)
 			if graphErr != nil {
 				continue
 			}
@@ -517,14 +570,19 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 			Mode:  traceMode,
 			Graph: merged,
 		}
+		trace.ApplyResolution(&result, res)
 
-		return outputTraceResult(result, traceViewGraph)
+		return outputTraceResult(result, traceViewGraph, newTraceExpander(ctx, stores))
 	}
 
 	projectRoot, err := config.FindProjectRoot()
 	if err != nil {
 		return err
 	}
+	keepFile, err := traceScopeFilter(projectRoot)
+	if err != nil {
+		return err
+	}
 
 	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
 	if err := symbolStore.Load(ctx); err != nil {
@@ -554,7 +612,15 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("symbol index is empty. Run 'grepai watch' first to build the index")
 	}
 
-	graph, err := symbolStore.GetCallGraph(ctx, symbolName, traceDepth)
+	res, err := resolveTraceQuery(ctx, []trace.SymbolStore{symbolStore}, symbolName)
+	if err != nil {
+		return err
+	}
+	if res.Ambiguous() {
+		return outputTraceResult(ambiguousTraceResult(symbolName, res), traceViewGraph, nil)
+	}
+
+	graph, err := symbolStore.GetCallGraph(ctx, res.Name, traceDepth)
 	if err != nil {
 		return fmt.Errorf("failed to build call graph: %w", err)
 	}
@@ -565,6 +631,9 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 		Graph: graph,
 	}
 
+	trace.ApplyResolution(&result, res)
+	trace.FilterFiles(&result, keepFile)
+
 	// Enrich with RPG feature paths
 	cfg, err := config.Load(projectRoot)
 	if err != nil {
@@ -574,15 +643,21 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 		enrichTraceWithRPG(projectRoot, cfg, &result)
 	}
 
+	enrichTraceWithOwners(projectRoot, &result)
+	enrichTraceWithCoverage(projectRoot, &result)
+	if traceBlame {
+		enrichTraceWithBlame(ctx, projectRoot, &result)
+	}
+
 	nodeCount := 0
 	if result.Graph != nil {
 		nodeCount = len(result.Graph.Nodes)
 	}
 
-	return outputAndRecord(result, traceViewGraph, projectRoot, gstats.TraceGraph, nodeCount)
+	return outputAndRecord(result, traceViewGraph, projectRoot, gstats.TraceGraph, nodeCount, newTraceExpander(ctx, []trace.SymbolStore{symbolStore}))
 }
 
-func outputAndRecord(result trace.TraceResult, view traceViewKind, projectRoot, commandType string, resultCount int) error {
+func outputAndRecord(result trace.TraceResult, view traceViewKind, projectRoot, commandType string, resultCount int, expand traceExpandFunc) error {
 	if traceJSON {
 		outputStr := captureJSON(result)
 		fmt.Print(outputStr)
@@ -598,13 +673,164 @@ func outputAndRecord(result trace.TraceResult, view traceViewKind, projectRoot,
 		recordTraceStats(projectRoot, commandType, resultCount, outputStr)
 		return nil
 	}
-	if err := outputTraceResult(result, view); err != nil {
+	if err := outputTraceResult(result, view, expand); err != nil {
 		return err
 	}
 	recordTraceStats(projectRoot, commandType, resultCount, "")
 	return nil
 }
 
+// collectCallers appends the callers of symbolName found in ss to result,
+// setting result.Symbol from the first store that defines the symbol.
+func collectCallers(ctx context.Context, ss trace.SymbolStore, symbolName string, result *trace.TraceResult) {
+	refs, err := ss.LookupCallers(ctx, symbolName)
+	if err != nil {
+		log.Printf("Warning: failed to lookup callers of %q: %v", symbolName, err)
+	}
+	symbols, err := ss.LookupSymbol(ctx, symbolName)
+	if err != nil {
+		log.Printf("Warning: failed to lookup symbol %q: %v", symbolName, err)
+	}
+	if len(symbols) > 0 && result.Symbol == nil {
+		result.Symbol = pickBestTargetSymbol(symbols, refs)
+	}
+	for _, ref := range refs {
+		callerSyms, err := ss.LookupSymbol(ctx, ref.CallerName)
+		if err != nil {
+			log.Printf("Warning: failed to lookup caller symbol %q: %v", ref.CallerName, err)
+		}
+		var callerSym trace.Symbol
+		if len(callerSyms) > 0 {
+			if picked := pickBestSymbolForFile(callerSyms, ref.CallerFile); picked != nil {
+				callerSym = *picked
+			} else {
+				callerSym = callerSyms[0]
+			}
+		} else {
+			callerSym = trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
+		}
+		result.Callers = append(result.Callers, trace.CallerInfo{
+			Symbol: callerSym,
+			CallSite: trace.CallSite{
+				File:    ref.File,
+				Line:    ref.Line,
+				Context: ref.Context,
+			},
+			Project: trace.ProjectOf(ss),
+		})
+	}
+}
+
+// collectImplementations lists the concrete methods reached through the
+// interface method being traced, unless the query pinned a concrete method.
+func collectImplementations(ctx context.Context, stores []trace.SymbolStore, res trace.SymbolResolution, result *trace.TraceResult) {
+	if res.Symbol != nil && !res.Symbol.Abstract {
+		return
+	}
+	impls, err := trace.CollectImplementations(ctx, stores, res.Name)
+	if err != nil {
+		log.Printf("Warning: failed to resolve implementations of %q: %v", res.Name, err)
+		return
+	}
+	result.Implementations = impls
+}
+
+// collectCallees appends the callees of symbolName found in ss to result,
+// setting result.Symbol from the first store that defines the symbol.
+func collectCallees(ctx context.Context, ss trace.SymbolStore, symbolName string, result *trace.TraceResult) {
+	symbols, err := ss.LookupSymbol(ctx, symbolName)
+	if err != nil {
+		log.Printf("Warning: failed to lookup symbol %q: %v", symbolName, err)
+	}
+	if len(symbols) == 0 {
+		return
+	}
+	if result.Symbol == nil {
+		result.Symbol = &symbols[0]
+	}
+	refs, err := ss.LookupCallees(ctx, symbolName, symbols[0].File)
+	if err != nil {
+		log.Printf("Warning: failed to lookup callees of %q: %v", symbolName, err)
+	}
+	for _, ref := range refs {
+		calleeSyms, err := ss.LookupSymbol(ctx, ref.SymbolName)
+		if err != nil {
+			log.Printf("Warning: failed to lookup callee symbol %q: %v", ref.SymbolName, err)
+		}
+		var calleeSym trace.Symbol
+		if len(calleeSyms) > 0 {
+			calleeSym = calleeSyms[0]
+		} else {
+			calleeSym = trace.Symbol{Name: ref.SymbolName}
+		}
+		result.Callees = append(result.Callees, trace.CalleeInfo{
+			Symbol: calleeSym,
+			CallSite: trace.CallSite{
+				File:    ref.File,
+				Line:    ref.Line,
+				Context: ref.Context,
+			},
+			Project: trace.ProjectOf(ss),
+		})
+	}
+}
+
+// newTraceExpander returns a traceExpandFunc that re-queries the given stores
+// so the trace UI can expand nodes in place.
+func newTraceExpander(ctx context.Context, stores []trace.SymbolStore) traceExpandFunc {
+	return func(symbol string, view traceViewKind) (trace.TraceResult, error) {
+		result := trace.TraceResult{Query: symbol, Mode: traceMode}
+		for _, ss := range stores {
+			switch view {
+			case traceViewCallers:
+				collectCallers(ctx, ss, symbol, &result)
+			case traceViewCallees:
+				collectCallees(ctx, ss, symbol, &result)
+			case traceViewGraph:
+				graph, err := ss.GetCallGraph(ctx, symbol, 1)
+				if err != nil {
+					return result, fmt.Errorf("failed to build call graph: %w", err)
+				}
+				result.Graph = mergeTraceGraphs(result.Graph, graph)
+			}
+		}
+		if view != traceViewGraph {
+			trace.ApplyPage(&result, tracePageOptions())
+		}
+		return result, nil
+	}
+}
+
+// resolveTraceQuery maps a user query to an indexed symbol name using exact,
+// qualified and fuzzy matching. Unmatched queries resolve to themselves.
+func resolveTraceQuery(ctx context.Context, stores []trace.SymbolStore, query string) (trace.SymbolResolution, error) {
+	res, err := trace.ResolveSymbol(ctx, stores, query)
+	if err != nil {
+		return res, fmt.Errorf("failed to resolve symbol: %w", err)
+	}
+	if res.Name == "" && !res.Ambiguous() {
+		res.Name = query
+	}
+	return res, nil
+}
+
+func ambiguousTraceResult(query string, res trace.SymbolResolution) trace.TraceResult {
+	return trace.TraceResult{
+		Query:      query,
+		Mode:       traceMode,
+		Match:      res.Match,
+		Candidates: res.Candidates,
+	}
+}
+
+func tracePageOptions() trace.PageOptions {
+	return trace.PageOptions{
+		Sort:   strings.ToLower(strings.TrimSpace(traceSort)),
+		Limit:  traceLimit,
+		Offset: traceOffset,
+	}
+}
+
 // enrichTraceWithRPG enriches all symbols in a TraceResult with RPG feature paths.
 func enrichTraceWithRPG(projectRoot string, cfg *config.Config, result *trace.TraceResult) {
 	if !cfg.RPG.Enabled {
@@ -661,6 +887,90 @@ func enrichTraceWithRPG(projectRoot string, cfg *config.Config, result *trace.Tr
 	}
 }
 
+// enrichTraceWithOwners sets the CODEOWNERS owners of each symbol in a
+// TraceResult.
+func enrichTraceWithOwners(projectRoot string, result *trace.TraceResult) {
+	owners := search.NewProjectCodeOwners(projectRoot)
+	if owners == nil {
+		return
+	}
+	forEachTraceSymbol(result, func(sym *trace.Symbol) {
+		if sym.File != "" {
+			sym.Owners = owners.Owners(sym.File)
+		}
+	})
+}
+
+// enrichTraceWithCoverage sets the line coverage of each symbol in a
+// TraceResult from the imported coverage profile.
+func enrichTraceWithCoverage(projectRoot string, result *trace.TraceResult) {
+	profile := loadProjectCoverage(projectRoot)
+	if profile == nil {
+		return
+	}
+	forEachTraceSymbol(result, func(sym *trace.Symbol) {
+		if sym.File != "" && sym.Line > 0 {
+			sym.Coverage = profile.Range(sym.File, sym.Line, sym.EndLine)
+		}
+	})
+}
+
+// enrichTraceWithBlame sets the last change to each symbol in a TraceResult.
+// It runs after pagination so only the symbols shown are blamed.
+func enrichTraceWithBlame(ctx context.Context, projectRoot string, result *trace.TraceResult) {
+	blames := search.NewProjectBlames(projectRoot)
+	forEachTraceSymbol(result, func(sym *trace.Symbol) {
+		if sym.File == "" || sym.Line == 0 {
+			return
+		}
+		endLine := sym.EndLine
+		if endLine < sym.Line {
+			endLine = sym.Line
+		}
+		sym.LastChange = blames.LastChange(ctx, sym.File, sym.Line, endLine)
+	})
+	if err := blames.Save(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// traceScopeFilter returns a filter keeping the files in the --scope scope
+// of the project, or nil without --scope.
+func traceScopeFilter(projectRoot string) (func(string) bool, error) {
+	if traceScope == "" {
+		return nil, nil
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	scope, err := cfg.GetScope(traceScope)
+	if err != nil {
+		return nil, err
+	}
+	return search.ScopeFileFilter(scope), nil
+}
+
+// forEachTraceSymbol calls fn for the target symbol, callers, callees and
+// graph nodes of a TraceResult.
+func forEachTraceSymbol(result *trace.TraceResult, fn func(*trace.Symbol)) {
+	if result.Symbol != nil {
+		fn(result.Symbol)
+	}
+	for i := range result.Callers {
+		fn(&result.Callers[i].Symbol)
+	}
+	for i := range result.Callees {
+		fn(&result.Callees[i].Symbol)
+	}
+	if result.Graph != nil {
+		for name, sym := range result.Graph.Nodes {
+			fn(&sym)
+			result.Graph.Nodes[name] = sym
+		}
+	}
+}
+
 type traceViewKind int
 
 const (
@@ -669,7 +979,7 @@ const (
 	traceViewGraph
 )
 
-func outputTraceResult(result trace.TraceResult, view traceViewKind) error {
+func outputTraceResult(result trace.TraceResult, view traceViewKind, expand traceExpandFunc) error {
 	if traceJSON {
 		return outputJSON(result)
 	}
@@ -677,7 +987,11 @@ func outputTraceResult(result trace.TraceResult, view traceViewKind) error {
 		return outputTOON(result)
 	}
 	if traceUI {
-		return runTraceResultUI(result, view)
+		return runTraceResultUI(result, view, expand)
+	}
+
+	if len(result.Candidates) > 0 {
+		return displayCandidates(result)
 	}
 
 	if result.Symbol == nil && view != traceViewGraph {
@@ -736,6 +1050,56 @@ func captureTOON(result trace.TraceResult) (string, error) {
 	return output + "\n", nil
 }
 
+// outputTraceValue prints any trace output value as JSON or TOON, following
+// the --json/--toon flags.
+func outputTraceValue(v any) error {
+	if traceTOON {
+		output, err := gotoon.Encode(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode TOON: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// loadTraceStores loads the symbol stores selected by --workspace/--project,
+// or the current project's store otherwise. Callers must close the stores.
+func loadTraceStores(ctx context.Context) ([]trace.SymbolStore, string, error) {
+	if traceProject != "" && traceWorkspace == "" {
+		return nil, "", fmt.Errorf("--project requires --workspace")
+	}
+
+	if traceWorkspace != "" {
+		stores, err := trace.LoadWorkspaceSymbolStores(ctx, traceWorkspace, traceProject)
+		if err != nil {
+			return nil, "", err
+		}
+		return stores, "", nil
+	}
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return nil, "", err
+	}
+
+	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
+	if err := symbolStore.Load(ctx); err != nil {
+		return nil, "", fmt.Errorf("failed to load symbol index: %w", err)
+	}
+
+	stats, err := symbolStore.GetStats(ctx)
+	if err != nil || stats.TotalSymbols == 0 {
+		symbolStore.Close()
+		return nil, "", fmt.Errorf("symbol index is empty. Run 'grepai watch' first to build the index")
+	}
+
+	return []trace.SymbolStore{symbolStore}, projectRoot, nil
+}
+
 // recordTraceStats fires a goroutine to record a trace stats entry without blocking.
 func recordTraceStats(projectRoot, commandType string, resultCount int, outputStr string) {
 	rec := gstats.NewRecorder(projectRoot)
@@ -754,13 +1118,46 @@ func recordTraceStats(projectRoot, commandType string, resultCount int, outputSt
 	}()
 }
 
+func displayCandidates(result trace.TraceResult) error {
+	fmt.Printf("Multiple symbols match %q (%s match):\n", result.Query, strings.ReplaceAll(result.Match, "_", "-"))
+	fmt.Println(strings.Repeat("-", 60))
+	for i, c := range result.Candidates {
+		name := c.Symbol.Name
+		if c.Symbol.Receiver != "" {
+			name = strings.TrimLeft(c.Symbol.Receiver, "*") + "." + name
+		}
+		fmt.Printf("%d. %s (%s) @ %s:%d\n", i+1, name, c.Symbol.Kind, c.Symbol.File, c.Symbol.Line)
+	}
+	fmt.Println("\nRe-run with an exact or qualified name (e.g. Receiver.Method or pkg.Func).")
+	return nil
+}
+
 func displayCallersResult(result trace.TraceResult) error {
 	fmt.Printf("Symbol: %s (%s)\n", result.Symbol.Name, result.Symbol.Kind)
 	fmt.Printf("File: %s:%d\n", result.Symbol.File, result.Symbol.Line)
 	if result.Symbol.FeaturePath != "" {
 		fmt.Printf("Feature: %s\n", result.Symbol.FeaturePath)
 	}
-	fmt.Printf("\nCallers (%d):\n", len(result.Callers))
+	if result.Symbol.Owners != "" {
+		fmt.Printf("Owners: %s\n", result.Symbol.Owners)
+	}
+	if result.Symbol.Coverage != nil {
+		fmt.Printf("Coverage: %s\n", result.Symbol.Coverage)
+	}
+	if c := result.Symbol.LastChange; c != nil {
+		fmt.Printf("Last change: %s\n", lastChangeLabel(c))
+	}
+	if len(result.Implementations) > 0 {
+		fmt.Printf("\nImplementations (%d):\n", len(result.Implementations))
+		for _, impl := range result.Implementations {
+			fmt.Printf("   %s.%s via %s @ %s:%d", strings.TrimLeft(impl.Method.Receiver, "*"), impl.Method.Name, impl.Interface, impl.Method.File, impl.Method.Line)
+			if impl.Project != "" {
+				fmt.Printf(" [%s]", impl.Project)
+			}
+			fmt.Println()
+		}
+	}
+	fmt.Printf("\nCallers (%s):\n", pageCountLabel(len(result.Callers), result))
 	fmt.Println(strings.Repeat("-", 60))
 
 	if len(result.Callers) == 0 {
@@ -769,13 +1166,25 @@ func displayCallersResult(result trace.TraceResult) error {
 	}
 
 	for i, caller := range result.Callers {
-		fmt.Printf("\n%d. %s\n", i+1, caller.Symbol.Name)
+		fmt.Printf("\n%d. %s\n", result.Offset+i+1, caller.Symbol.Name)
+		if caller.Project != "" {
+			fmt.Printf("   Project: %s\n", caller.Project)
+		}
 		if caller.Symbol.File != "" {
 			fmt.Printf("   Defined: %s:%d\n", caller.Symbol.File, caller.Symbol.Line)
 		}
 		if caller.Symbol.FeaturePath != "" {
 			fmt.Printf("   Feature: %s\n", caller.Symbol.FeaturePath)
 		}
+		if caller.Symbol.Owners != "" {
+			fmt.Printf("   Owners: %s\n", caller.Symbol.Owners)
+		}
+		if caller.Symbol.Coverage != nil {
+			fmt.Printf("   Coverage: %s\n", caller.Symbol.Coverage)
+		}
+		if c := caller.Symbol.LastChange; c != nil {
+			fmt.Printf("   Last change: %s\n", lastChangeLabel(c))
+		}
 		fmt.Printf("   Calls at: %s:%d\n", caller.CallSite.File, caller.CallSite.Line)
 		if caller.CallSite.Context != "" {
 			fmt.Printf("   Context: %s\n", truncate(caller.CallSite.Context, 80))
@@ -791,7 +1200,16 @@ func displayCalleesResult(result trace.TraceResult) error {
 	if result.Symbol.FeaturePath != "" {
 		fmt.Printf("Feature: %s\n", result.Symbol.FeaturePath)
 	}
-	fmt.Printf("\nCallees (%d):\n", len(result.Callees))
+	if result.Symbol.Owners != "" {
+		fmt.Printf("Owners: %s\n", result.Symbol.Owners)
+	}
+	if result.Symbol.Coverage != nil {
+		fmt.Printf("Coverage: %s\n", result.Symbol.Coverage)
+	}
+	if c := result.Symbol.LastChange; c != nil {
+		fmt.Printf("Last change: %s\n", lastChangeLabel(c))
+	}
+	fmt.Printf("\nCallees (%s):\n", pageCountLabel(len(result.Callees), result))
 	fmt.Println(strings.Repeat("-", 60))
 
 	if len(result.Callees) == 0 {
@@ -800,30 +1218,56 @@ func displayCalleesResult(result trace.TraceResult) error {
 	}
 
 	for i, callee := range result.Callees {
-		fmt.Printf("\n%d. %s\n", i+1, callee.Symbol.Name)
+		fmt.Printf("\n%d. %s\n", result.Offset+i+1, callee.Symbol.Name)
+		if callee.Project != "" {
+			fmt.Printf("   Project: %s\n", callee.Project)
+		}
 		if callee.Symbol.File != "" {
 			fmt.Printf("   Defined: %s:%d\n", callee.Symbol.File, callee.Symbol.Line)
 		}
 		if callee.Symbol.FeaturePath != "" {
 			fmt.Printf("   Feature: %s\n", callee.Symbol.FeaturePath)
 		}
+		if callee.Symbol.Owners != "" {
+			fmt.Printf("   Owners: %s\n", callee.Symbol.Owners)
+		}
+		if callee.Symbol.Coverage != nil {
+			fmt.Printf("   Coverage: %s\n", callee.Symbol.Coverage)
+		}
+		if c := callee.Symbol.LastChange; c != nil {
+			fmt.Printf("   Last change: %s\n", lastChangeLabel(c))
+		}
 		fmt.Printf("   Called at: %s:%d\n", callee.CallSite.File, callee.CallSite.Line)
 	}
 
 	return nil
 }
 
+// pageCountLabel renders "n" or "first-last of total" when results are paginated.
+func pageCountLabel(shown int, result trace.TraceResult) string {
+	if result.Total <= shown {
+		return fmt.Sprintf("%d", shown)
+	}
+	if shown == 0 {
+		return fmt.Sprintf("0 of %d", result.Total)
+	}
+	return fmt.Sprintf("%d-%d of %d", result.Offset+1, result.Offset+shown, result.Total)
+}
+
 func displayGraphResult(result trace.TraceResult) error {
 	fmt.Printf("Call Graph for: %s (depth: %d)\n", result.Query, result.Graph.Depth)
 	fmt.Println(strings.Repeat("=", 60))
 
 	fmt.Printf("\nNodes (%d):\n", len(result.Graph.Nodes))
 	for name, sym := range result.Graph.Nodes {
+		fmt.Printf("  - %s (%s) @ %s:%d", name, sym.Kind, sym.File, sym.Line)
 		if sym.FeaturePath != "" {
-			fmt.Printf("  - %s (%s) @ %s:%d [%s]\n", name, sym.Kind, sym.File, sym.Line, sym.FeaturePath)
-		} else {
-			fmt.Printf("  - %s (%s) @ %s:%d\n", name, sym.Kind, sym.File, sym.Line)
+			fmt.Printf(" [%s]", sym.FeaturePath)
+		}
+		if sym.LastChange != nil {
+			fmt.Printf(" (%s)", lastChangeLabel(sym.LastChange))
 		}
+		fmt.Println()
 	}
 
 	fmt.Printf("\nEdges (%d):\n", len(result.Graph.Edges))
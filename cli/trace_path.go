@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+var (
+	tracePathDepth int
+	tracePathLimit int
+)
+
+var tracePathCmd = &cobra.Command{
+	Use:   "path <from> <to>",
+	Short: "Find call paths between two symbols",
+	Long: `Search the call graph for chains of calls leading from one symbol to another.
+
+The shortest paths are returned first, each with the call site of every step.
+Both symbols accept the same qualified, case-insensitive, prefix and fuzzy
+names as the other trace commands.
+
+Examples:
+  grepai trace path HandleRequest QueryRow
+  grepai trace path Server.ServeHTTP db.Exec --depth 8 --limit 3
+  grepai trace path HandleRequest SaveOrder --workspace my-fullstack --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTracePath,
+}
+
+func init() {
+	tracePathCmd.Flags().BoolVar(&traceJSON, "json", false, "Output results in JSON format")
+	tracePathCmd.Flags().BoolVarP(&traceTOON, "toon", "t", false, "Output results in TOON format (token-efficient for AI agents)")
+	tracePathCmd.MarkFlagsMutuallyExclusive("json", "toon")
+	tracePathCmd.Flags().StringVar(&traceWorkspace, "workspace", "", "Workspace name for cross-project trace")
+	tracePathCmd.Flags().StringVar(&traceProject, "project", "", "Project name within workspace (requires --workspace)")
+	tracePathCmd.Flags().IntVarP(&tracePathDepth, "depth", "d", trace.DefaultPathDepth, "Maximum number of calls in a path")
+	tracePathCmd.Flags().IntVar(&tracePathLimit, "limit", trace.DefaultPathLimit, "Maximum number of paths to return")
+
+	traceCmd.AddCommand(tracePathCmd)
+}
+
+func runTracePath(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if tracePathDepth <= 0 {
+		return fmt.Errorf("--depth must be > 0")
+	}
+	if tracePathLimit <= 0 {
+		return fmt.Errorf("--limit must be > 0")
+	}
+
+	stores, _, err := loadTraceStores(ctx)
+	if err != nil {
+		return err
+	}
+	defer trace.CloseSymbolStores(stores)
+
+	names := make([]string, len(args))
+	for i, query := range args {
+		res, err := resolveTraceQuery(ctx, stores, query)
+		if err != nil {
+			return err
+		}
+		if res.Ambiguous() {
+			return outputTraceResult(ambiguousTraceResult(query, res), traceViewGraph, nil)
+		}
+		names[i] = res.Name
+	}
+
+	result, err := trace.FindCallPaths(ctx, stores, names[0], names[1], trace.PathOptions{
+		MaxDepth: tracePathDepth,
+		Limit:    tracePathLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find call paths: %w", err)
+	}
+
+	if traceJSON || traceTOON {
+		return outputTraceValue(result)
+	}
+	return displayPathResult(result)
+}
+
+func displayPathResult(result trace.PathResult) error {
+	fmt.Printf("Call paths from %s to %s (%d found, max depth %d):\n", result.From, result.To, len(result.Paths), result.MaxDepth)
+	fmt.Println(strings.Repeat("-", 60))
+
+	if len(result.Paths) == 0 {
+		fmt.Printf("No call path found within %d calls.\n", result.MaxDepth)
+		return nil
+	}
+
+	for i, p := range result.Paths {
+		fmt.Printf("\n%d. %s (%d calls)\n", i+1, strings.Join(p.Symbols, " -> "), p.Length)
+		for _, step := range p.Steps {
+			project := ""
+			if step.Project != "" {
+				project = fmt.Sprintf(" [%s]", step.Project)
+			}
+			fmt.Printf("   %s -> %s @ %s:%d%s\n", step.Caller, step.Callee, step.File, step.Line, project)
+		}
+	}
+
+	if result.Truncated {
+		fmt.Println("\nSearch stopped early on a dense call graph; narrow it with a lower --depth.")
+	}
+	return nil
+}
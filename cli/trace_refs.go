@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+var traceRefsKinds string
+
+var traceRefsCmd = &cobra.Command{
+	Use:   "refs <symbol>",
+	Short: "List every reference to a symbol",
+	Long: `List every reference to a symbol, not only call sites:
+
+  call         the symbol is called
+  read/write   a property or field is read or assigned
+  type         the symbol is used as a type (parameters, fields, conversions, extends/implements)
+  instantiate  a struct literal or new expression creates the symbol
+  import       an import brings the symbol or package into scope
+
+Qualified names (Receiver.Method, pkg.Type) match references to the last
+segment. In workspace mode, references from every selected project are listed.
+
+Symbol indexes built before type, instantiate and import references were
+recorded only contain calls, reads and writes; re-run 'grepai watch' to
+rebuild them.
+
+Examples:
+  grepai trace refs Options
+  grepai trace refs Server --kind type,instantiate
+  grepai trace refs UserService --workspace my-fullstack --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTraceRefs,
+}
+
+func init() {
+	traceRefsCmd.Flags().BoolVar(&traceJSON, "json", false, "Output results in JSON format")
+	traceRefsCmd.Flags().BoolVarP(&traceTOON, "toon", "t", false, "Output results in TOON format (token-efficient for AI agents)")
+	traceRefsCmd.MarkFlagsMutuallyExclusive("json", "toon")
+	traceRefsCmd.Flags().StringVar(&traceWorkspace, "workspace", "", "Workspace name for cross-project references")
+	traceRefsCmd.Flags().StringVar(&traceProject, "project", "", "Project name within workspace (requires --workspace)")
+	traceRefsCmd.Flags().StringVar(&traceRefsKinds, "kind", "", "Comma-separated reference kinds: call, read, write, type, instantiate, import (default: all)")
+	traceRefsCmd.Flags().IntVar(&traceLimit, "limit", 0, "Maximum number of results to return (0 = no limit)")
+	traceRefsCmd.Flags().IntVar(&traceOffset, "offset", 0, "Number of results to skip")
+	traceRefsCmd.Flags().StringVar(&traceSort, "sort", trace.SortByFile, "Sort results by: file, line, project, count")
+
+	traceCmd.AddCommand(traceRefsCmd)
+}
+
+func runTraceRefs(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	kinds, err := trace.ParseReferenceKinds(traceRefsKinds)
+	if err != nil {
+		return err
+	}
+
+	stores, projectRoot, err := loadTraceStores(ctx)
+	if err != nil {
+		return err
+	}
+	defer trace.CloseSymbolStores(stores)
+
+	result, err := trace.FindReferences(ctx, stores, args[0], kinds, tracePageOptions())
+	if err != nil {
+		return fmt.Errorf("failed to find references: %w", err)
+	}
+
+	if traceJSON || traceTOON {
+		return outputTraceValue(result)
+	}
+
+	return displayReferencesResult(result, projectRoot)
+}
+
+func displayReferencesResult(result *trace.ReferencesResult, projectRoot string) error {
+	fmt.Printf("References to %s (%d)", result.Query, result.Total)
+	if len(result.Counts) > 0 {
+		var parts []string
+		for _, kind := range trace.ReferenceKinds {
+			if n := result.Counts[kind]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%s: %d", kind, n))
+			}
+		}
+		fmt.Printf(" [%s]", strings.Join(parts, ", "))
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", 60))
+
+	if result.Total == 0 {
+		fmt.Println("No references found.")
+		return nil
+	}
+
+	currentProject := ""
+	for i, ref := range result.References {
+		if ref.Project != "" && ref.Project != currentProject {
+			currentProject = ref.Project
+			fmt.Printf("\n[%s]\n", currentProject)
+		}
+		file := ref.File
+		if projectRoot != "" {
+			if rel, err := filepath.Rel(projectRoot, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+		fmt.Printf("%d. %-11s %s:%d", result.Offset+i+1, ref.Kind, file, ref.Line)
+		if ref.Caller != "" {
+			fmt.Printf(" in %s", ref.Caller)
+		}
+		fmt.Println()
+		if ref.Context != "" {
+			fmt.Printf("   %s\n", strings.TrimSpace(ref.Context))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+var (
+	traceUnusedIncludeUnexported  bool
+	traceUnusedIncludeEntryPoints bool
+	traceUnusedIgnore             []string
+)
+
+var traceUnusedCmd = &cobra.Command{
+	Use:   "unused",
+	Short: "Report exported functions and methods that are never referenced",
+	Long: `Report exported functions and methods that have no references in the symbol index.
+
+Entry points that are invoked by the runtime or frameworks are skipped by default:
+main/init, test, benchmark, example and fuzz functions, symbols in test files,
+HTTP handlers and methods commonly called through interfaces (String, Error,
+ServeHTTP, MarshalJSON, ...).
+
+In workspace mode, references from every selected project are considered, so a
+symbol used only by another project is not reported.
+
+Examples:
+  grepai trace unused
+  grepai trace unused --ignore "Handle*" --ignore "internal/generated/*"
+  grepai trace unused --workspace my-fullstack --json`,
+	Args: cobra.NoArgs,
+	RunE: runTraceUnused,
+}
+
+func init() {
+	traceUnusedCmd.Flags().BoolVar(&traceJSON, "json", false, "Output results in JSON format")
+	traceUnusedCmd.Flags().BoolVarP(&traceTOON, "toon", "t", false, "Output results in TOON format (token-efficient for AI agents)")
+	traceUnusedCmd.MarkFlagsMutuallyExclusive("json", "toon")
+	traceUnusedCmd.Flags().StringVar(&traceWorkspace, "workspace", "", "Workspace name for cross-project analysis")
+	traceUnusedCmd.Flags().StringVar(&traceProject, "project", "", "Project name within workspace (requires --workspace)")
+	traceUnusedCmd.Flags().BoolVar(&traceUnusedIncludeUnexported, "include-unexported", false, "Also report unexported symbols")
+	traceUnusedCmd.Flags().BoolVar(&traceUnusedIncludeEntryPoints, "include-entry-points", false, "Do not skip main/init, tests, HTTP handlers and interface methods")
+	traceUnusedCmd.Flags().StringArrayVar(&traceUnusedIgnore, "ignore", nil, "Glob pattern for symbol names or file paths to skip (repeatable)")
+
+	traceCmd.AddCommand(traceUnusedCmd)
+}
+
+func runTraceUnused(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	stores, projectRoot, err := loadTraceStores(ctx)
+	if err != nil {
+		return err
+	}
+	defer trace.CloseSymbolStores(stores)
+
+	result, err := trace.FindUnused(ctx, stores, trace.UnusedOptions{
+		IncludeUnexported:  traceUnusedIncludeUnexported,
+		IncludeEntryPoints: traceUnusedIncludeEntryPoints,
+		IgnorePatterns:     traceUnusedIgnore,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to find unused symbols: %w", err)
+	}
+
+	if traceJSON || traceTOON {
+		return outputTraceValue(result)
+	}
+
+	return displayUnusedResult(result, projectRoot)
+}
+
+func displayUnusedResult(result trace.UnusedResult, projectRoot string) error {
+	fmt.Printf("Unused symbols (%d):\n", result.Total)
+	fmt.Println(strings.Repeat("-", 60))
+
+	if result.Total == 0 {
+		fmt.Println("No unused symbols found.")
+		return nil
+	}
+
+	currentProject := ""
+	for i, u := range result.Symbols {
+		if u.Project != "" && u.Project != currentProject {
+			currentProject = u.Project
+			fmt.Printf("\n[%s]\n", currentProject)
+		}
+		name := u.Symbol.Name
+		if u.Symbol.Receiver != "" {
+			name = strings.TrimLeft(u.Symbol.Receiver, "*") + "." + name
+		}
+		file := u.Symbol.File
+		if projectRoot != "" {
+			if rel, err := filepath.Rel(projectRoot, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+		fmt.Printf("%d. %s (%s) @ %s:%d\n", i+1, name, u.Symbol.Kind, file, u.Symbol.Line)
+	}
+
+	return nil
+}
@@ -25,7 +25,7 @@ func renderLifecycleRail(theme tuiTheme, phases []string, current int) string {
 		if i < len(phases)-1 {
 			connector := theme.railPending.Render("->")
 			if i < current {
-				connector = theme.railDone.Render("->")
+				connector = theme.railDone.UnsetTransform().Render("->")
 			}
 			segments = append(segments, connector)
 		}
@@ -0,0 +1,509 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+const (
+	dashboardRefreshInterval = 2 * time.Second
+	dashboardLogLines        = 12
+	dashboardSearchLimit     = 20
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Open the interactive grepai dashboard",
+	Long: `Open a single interactive dashboard combining the index status browser,
+watcher health, the workspace overview and a search box, with tabbed navigation.
+
+Keys:
+  tab / shift+tab  switch tabs (1-4 jump directly)
+  /                focus the search box
+  esc              leave the search box
+  s, r             on the Workspaces tab, start/stop the workspace watcher
+                   and reindex the selected project
+  q, ctrl+c        quit
+
+Outside a grepai project, only the Watch and Workspaces tabs are populated.`,
+	Args: cobra.NoArgs,
+	RunE: runTUI,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+type dashboardTab int
+
+const (
+	dashboardTabStatus dashboardTab = iota
+	dashboardTabWatch
+	dashboardTabWorkspaces
+	dashboardTabSearch
+)
+
+var dashboardTabNames = []string{"Status", "Watch", "Workspaces", "Search"}
+
+type dashboardTickMsg time.Time
+
+type dashboardSearchMsg struct {
+	query   string
+	results []store.SearchResult
+	err     error
+}
+
+type dashboardSearchFunc func(ctx context.Context, query string) ([]store.SearchResult, error)
+
+type dashboardModel struct {
+	theme tuiTheme
+	tab   dashboardTab
+
+	width  int
+	height int
+
+	projectRoot string
+	hasProject  bool
+	status      model
+
+	watchFn  func() watcherRuntimeStatus
+	watch    watcherRuntimeStatus
+	watchLog []string
+
+	workspaces workspaceStatusModel
+
+	input          textinput.Model
+	searchFn       dashboardSearchFunc
+	searching      bool
+	searchQuery    string
+	searchErr      error
+	results        []store.SearchResult
+	selectedResult int
+}
+
+func newDashboardModel(projectRoot string, status *model, wsCfg *config.WorkspaceConfig, watchFn func() watcherRuntimeStatus, searchFn dashboardSearchFunc) dashboardModel {
+	if wsCfg == nil {
+		wsCfg = &config.WorkspaceConfig{}
+	}
+	input := textinput.New()
+	input.Placeholder = "Search the codebase..."
+	input.Prompt = "> "
+
+	m := dashboardModel{
+		theme:       newTUITheme(),
+		projectRoot: projectRoot,
+		watchFn:     watchFn,
+		workspaces:  newWorkspaceStatusModel(wsCfg, ""),
+		input:       input,
+		searchFn:    searchFn,
+	}
+	if status != nil {
+		m.status = *status
+		m.hasProject = true
+	} else {
+		m.tab = dashboardTabWatch
+	}
+	m.refreshWatch()
+	return m
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return dashboardTick()
+}
+
+func dashboardTick() tea.Cmd {
+	return tea.Tick(dashboardRefreshInterval, func(t time.Time) tea.Msg {
+		return dashboardTickMsg(t)
+	})
+}
+
+func (m *dashboardModel) refreshWatch() {
+	if m.watchFn == nil {
+		return
+	}
+	m.watch = m.watchFn()
+	m.watchLog = readLogTail(m.watch.logFile, dashboardLogLines)
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		child := tea.WindowSizeMsg{Width: msg.Width, Height: msg.Height - 3}
+		status, _ := m.status.Update(child)
+		m.status = status.(model)
+		workspaces, _ := m.workspaces.Update(child)
+		m.workspaces = workspaces.(workspaceStatusModel)
+		m.input.Width = msg.Width - 8
+		return m, nil
+
+	case dashboardTickMsg:
+		m.refreshWatch()
+		m.workspaces.refresh()
+		return m, dashboardTick()
+
+	case workspaceStatusActionMsg:
+		workspaces, cmd := m.workspaces.Update(msg)
+		m.workspaces = workspaces.(workspaceStatusModel)
+		return m, cmd
+
+	case dashboardSearchMsg:
+		if msg.query != m.searchQuery {
+			return m, nil
+		}
+		m.searching = false
+		m.searchErr = msg.err
+		m.results = msg.results
+		m.selectedResult = 0
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	if m.tab == dashboardTabSearch && m.input.Focused() {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m dashboardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "tab":
+		return m.switchTab((m.tab + 1) % dashboardTab(len(dashboardTabNames)))
+	case "shift+tab":
+		return m.switchTab((m.tab + dashboardTab(len(dashboardTabNames)) - 1) % dashboardTab(len(dashboardTabNames)))
+	}
+
+	if m.tab == dashboardTabSearch && m.input.Focused() {
+		switch msg.String() {
+		case "esc":
+			m.input.Blur()
+			return m, nil
+		case "enter":
+			return m.startSearch()
+		case "up", "down":
+			m.moveResult(msg.String())
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "1", "2", "3", "4":
+		return m.switchTab(dashboardTab(msg.String()[0] - '1'))
+	}
+
+	switch m.tab {
+	case dashboardTabStatus:
+		if m.hasProject {
+			status, cmd := m.status.Update(msg)
+			m.status = status.(model)
+			return m, cmd
+		}
+	case dashboardTabWorkspaces:
+		workspaces, cmd := m.workspaces.Update(msg)
+		m.workspaces = workspaces.(workspaceStatusModel)
+		return m, cmd
+	case dashboardTabSearch:
+		switch msg.String() {
+		case "/", "i":
+			return m, m.input.Focus()
+		case "up", "k":
+			m.moveResult("up")
+		case "down", "j":
+			m.moveResult("down")
+		}
+	}
+	return m, nil
+}
+
+func (m dashboardModel) switchTab(tab dashboardTab) (tea.Model, tea.Cmd) {
+	m.tab = tab
+	if tab == dashboardTabSearch {
+		return m, m.input.Focus()
+	}
+	m.input.Blur()
+	return m, nil
+}
+
+func (m *dashboardModel) moveResult(direction string) {
+	switch direction {
+	case "up":
+		if m.selectedResult > 0 {
+			m.selectedResult--
+		}
+	case "down":
+		if m.selectedResult < len(m.results)-1 {
+			m.selectedResult++
+		}
+	}
+}
+
+func (m dashboardModel) startSearch() (tea.Model, tea.Cmd) {
+	query := strings.TrimSpace(m.input.Value())
+	if query == "" || m.searchFn == nil {
+		return m, nil
+	}
+	m.searching = true
+	m.searchQuery = query
+	m.searchErr = nil
+	searchFn := m.searchFn
+	return m, func() tea.Msg {
+		results, err := searchFn(context.Background(), query)
+		return dashboardSearchMsg{query: query, results: results, err: err}
+	}
+}
+
+func (m dashboardModel) View() string {
+	if m.width == 0 {
+		return "Loading grepai dashboard..."
+	}
+
+	var body string
+	switch m.tab {
+	case dashboardTabStatus:
+		body = m.viewStatus()
+	case dashboardTabWatch:
+		body = m.viewWatch()
+	case dashboardTabWorkspaces:
+		body = m.workspaces.View()
+	case dashboardTabSearch:
+		body = m.viewSearch()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, m.renderTabBar(), "", body)
+}
+
+func (m dashboardModel) renderTabBar() string {
+	tabs := make([]string, 0, len(dashboardTabNames))
+	for i, name := range dashboardTabNames {
+		label := fmt.Sprintf(" %d %s ", i+1, name)
+		if dashboardTab(i) == m.tab {
+			tabs = append(tabs, m.theme.highlight.Render(label))
+		} else {
+			tabs = append(tabs, m.theme.muted.Render(label))
+		}
+	}
+	title := m.theme.title.Render("grepai")
+	help := m.theme.help.Render("tab switch | q quit")
+	return title + "  " + strings.Join(tabs, " ") + "  " + help
+}
+
+func (m dashboardModel) viewStatus() string {
+	if !m.hasProject {
+		return renderActionCard(
+			m.theme,
+			"No grepai project",
+			"The current directory is not inside an initialized grepai project.",
+			"Run: grepai init",
+			m.width-2,
+		)
+	}
+	return m.status.View()
+}
+
+func (m dashboardModel) viewWatch() string {
+	lines := []string{m.theme.subtitle.Render("Watcher Health"), ""}
+	if m.projectRoot != "" {
+		lines = append(lines, m.theme.text.Render(fmt.Sprintf("Project: %s", m.projectRoot)))
+	}
+	if m.watch.running {
+		lines = append(lines, m.theme.ok.Render(fmt.Sprintf("Watcher: running (PID %d)", m.watch.pid)))
+	} else {
+		lines = append(lines, m.theme.warn.Render("Watcher: not running"))
+		lines = append(lines, m.theme.muted.Render("Start it with: grepai watch --background"))
+	}
+	if m.watch.worktreeID != "" {
+		lines = append(lines, m.theme.text.Render(fmt.Sprintf("Worktree: %s", m.watch.worktreeID)))
+	}
+	if m.watch.logFile != "" {
+		lines = append(lines, m.theme.text.Render(fmt.Sprintf("Log file: %s", m.watch.logFile)))
+	}
+
+	lines = append(lines, "", m.theme.subtitle.Render("Recent log"))
+	if len(m.watchLog) == 0 {
+		lines = append(lines, m.theme.muted.Render("No log output yet."))
+	}
+	for _, line := range m.watchLog {
+		lines = append(lines, m.theme.muted.Render(truncateRunes(line, m.width-6)))
+	}
+	return m.theme.panel.Width(m.width - 2).Render(strings.Join(lines, "\n"))
+}
+
+func (m dashboardModel) viewSearch() string {
+	width := m.width - 2
+	var header strings.Builder
+	header.WriteString(m.input.View())
+	header.WriteString("\n")
+	switch {
+	case m.searchFn == nil:
+		header.WriteString(m.theme.muted.Render("Search requires a grepai project (run: grepai init)."))
+	case m.searching:
+		header.WriteString(m.theme.info.Render(fmt.Sprintf("Searching for %q...", m.searchQuery)))
+	case m.searchErr != nil:
+		header.WriteString(m.theme.danger.Render(fmt.Sprintf("Search failed: %v", m.searchErr)))
+	case m.searchQuery != "":
+		header.WriteString(m.theme.muted.Render(fmt.Sprintf("%d results for %q", len(m.results), m.searchQuery)))
+	default:
+		header.WriteString(m.theme.help.Render("enter search | up/down select | esc leave input"))
+	}
+	headerPanel := m.theme.panel.Width(width).Render(header.String())
+
+	if len(m.results) == 0 {
+		return headerPanel
+	}
+
+	entries := make([]string, len(m.results))
+	for i, r := range m.results {
+		entries[i] = fmt.Sprintf("%.2f %s:%d-%d", r.Score, r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+	}
+	contentHeight := m.height - 10
+	if contentHeight < 6 {
+		contentHeight = 6
+	}
+	topH, bottomH := panelHeights(contentHeight)
+	list := renderSelectableList(m.theme, "Results", entries, m.selectedResult, width, topH)
+	return lipgloss.JoinVertical(lipgloss.Left, headerPanel, list, m.renderResultDetail(width, bottomH))
+}
+
+func (m dashboardModel) renderResultDetail(width, height int) string {
+	r := m.results[m.selectedResult]
+	title := fmt.Sprintf("%s:%d-%d", r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+	if r.SymbolName != "" {
+		title = fmt.Sprintf("%s (%s %s)", title, r.SymbolKind, r.SymbolName)
+	}
+	lines := []string{m.theme.subtitle.Render(truncateRunes(title, width-4))}
+	for i, line := range highlightLines(r.Chunk.FilePath, chunkBody(r.Chunk.Content), m.theme.syntax) {
+		if i >= height-3 {
+			break
+		}
+		if m.theme.syntax == "" {
+			line = m.theme.text.Render(truncateRunes(line, width-4))
+		} else {
+			line = ansi.Truncate(line, width-4, "...")
+		}
+		lines = append(lines, line)
+	}
+	return m.theme.panel.Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+// readLogTail returns up to n trailing lines of the file at path, or nil when
+// it cannot be read.
+func readLogTail(path string, n int) []string {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	if !isInteractiveTerminal() {
+		return errors.New("grepai tui requires an interactive terminal; use 'grepai status --no-ui' for a plain summary")
+	}
+	ctx := context.Background()
+
+	var (
+		status   *model
+		searchFn dashboardSearchFunc
+	)
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		projectRoot = ""
+	} else {
+		cfg, err := config.Load(projectRoot)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+
+		indexStats, err := st.GetStats(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get stats: %w", err)
+		}
+		m, err := newStatusModel(ctx, st, cfg, projectRoot, indexStats, resolveWatcherRuntimeStatus(projectRoot))
+		if err != nil {
+			return err
+		}
+		status = &m
+
+		if metaErr := config.CheckProjectIndexMetadata(projectRoot, cfg); metaErr != nil {
+			searchFn = func(context.Context, string) ([]store.SearchResult, error) {
+				return nil, metaErr
+			}
+		} else if emb, embErr := embedder.NewFromConfig(cfg); embErr != nil {
+			searchFn = func(context.Context, string) ([]store.SearchResult, error) {
+				return nil, fmt.Errorf("failed to initialize embedder: %w", embErr)
+			}
+		} else if docsEmb, docsErr := embedder.NewDocsFromConfig(cfg); docsErr != nil {
+			emb.Close()
+			searchFn = func(context.Context, string) ([]store.SearchResult, error) {
+				return nil, fmt.Errorf("failed to initialize docs embedder: %w", docsErr)
+			}
+		} else {
+			defer emb.Close()
+			if docsEmb != nil {
+				defer docsEmb.Close()
+			}
+			searcher := search.NewProjectSearcher(st, emb, docsEmb, cfg)
+			if symbols := loadSearchSymbols(ctx, projectRoot); symbols != nil {
+				searcher.SetSymbolStore(symbols)
+			}
+			searchFn = func(ctx context.Context, query string) ([]store.SearchResult, error) {
+				return searcher.Search(ctx, query, dashboardSearchLimit, "")
+			}
+		}
+	}
+
+	wsCfg, err := config.LoadWorkspaceConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load workspace config: %w", err)
+	}
+
+	watchFn := func() watcherRuntimeStatus { return resolveWatcherRuntimeStatus(projectRoot) }
+	dashboard := newDashboardModel(projectRoot, status, wsCfg, watchFn, searchFn)
+
+	// Workspace reindexing logs would draw over the UI.
+	restoreLog := silenceLog()
+	defer restoreLog()
+	_, err = runTUIProgram(dashboard)
+	return err
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func updateDashboard(t *testing.T, m dashboardModel, msg tea.Msg) (dashboardModel, tea.Cmd) {
+	t.Helper()
+	next, cmd := m.Update(msg)
+	return next.(dashboardModel), cmd
+}
+
+func TestDashboardModel_TabNavigation(t *testing.T) {
+	status := model{cfg: config.DefaultConfig(), stats: &store.IndexStats{}}
+	m := newDashboardModel("/tmp/project", &status, nil, nil, nil)
+	m, _ = updateDashboard(t, m, tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	if m.tab != dashboardTabStatus {
+		t.Fatalf("initial tab = %d, want status", m.tab)
+	}
+	m, _ = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.tab != dashboardTabWatch {
+		t.Fatalf("tab after tab key = %d, want watch", m.tab)
+	}
+	m, _ = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	m, _ = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.tab != dashboardTabSearch {
+		t.Fatalf("shift+tab should wrap to search, got %d", m.tab)
+	}
+	if !m.input.Focused() {
+		t.Fatal("search input should be focused on the search tab")
+	}
+
+	// While the input is focused, q and digits are typed, not commands.
+	m, cmd := updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q3")})
+	if cmd != nil {
+		if _, ok := cmd().(tea.QuitMsg); ok {
+			t.Fatal("q must not quit while typing a query")
+		}
+	}
+	if m.input.Value() != "q3" || m.tab != dashboardTabSearch {
+		t.Fatalf("input = %q tab = %d, want typed text on search tab", m.input.Value(), m.tab)
+	}
+
+	m, _ = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	if m.tab != dashboardTabWorkspaces {
+		t.Fatalf("tab after 3 = %d, want workspaces", m.tab)
+	}
+	if !strings.Contains(m.View(), "No workspaces") {
+		t.Error("workspaces tab should render the empty state")
+	}
+
+	_, cmd = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if cmd == nil {
+		t.Fatal("q should quit outside the search input")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatal("q should quit outside the search input")
+	}
+}
+
+func TestDashboardModel_NoProject(t *testing.T) {
+	m := newDashboardModel("", nil, nil, nil, nil)
+	m, _ = updateDashboard(t, m, tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	if m.tab != dashboardTabWatch {
+		t.Fatalf("without a project the dashboard should open on the watch tab, got %d", m.tab)
+	}
+	m, _ = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	if !strings.Contains(m.View(), "grepai init") {
+		t.Error("status tab should suggest grepai init without a project")
+	}
+}
+
+func TestDashboardModel_Search(t *testing.T) {
+	var gotQuery string
+	searchFn := func(_ context.Context, query string) ([]store.SearchResult, error) {
+		gotQuery = query
+		return []store.SearchResult{
+			{Chunk: store.Chunk{FilePath: "auth/login.go", StartLine: 3, EndLine: 9, Content: "func Login() {}"}, Score: 0.91},
+		}, nil
+	}
+	status := model{cfg: config.DefaultConfig(), stats: &store.IndexStats{}}
+	m := newDashboardModel("/tmp/project", &status, nil, nil, searchFn)
+	m, _ = updateDashboard(t, m, tea.WindowSizeMsg{Width: 100, Height: 30})
+	m, _ = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+	m, _ = updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("user login")})
+
+	m, cmd := updateDashboard(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.searching || cmd == nil {
+		t.Fatal("enter should start a search")
+	}
+	m, _ = updateDashboard(t, m, cmd())
+	if gotQuery != "user login" {
+		t.Fatalf("search query = %q, want %q", gotQuery, "user login")
+	}
+	if m.searching || len(m.results) != 1 {
+		t.Fatalf("expected one result, got %d (searching=%v)", len(m.results), m.searching)
+	}
+	// The code may be highlighted.
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"auth/login.go:3-9", "func Login() {}"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("search view missing %q", want)
+		}
+	}
+
+	// Results of a superseded query are ignored.
+	m, _ = updateDashboard(t, m, dashboardSearchMsg{query: "stale"})
+	if len(m.results) != 1 {
+		t.Error("stale search results should be ignored")
+	}
+}
+
+func TestDashboardModel_WatchTab(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "grepai-watch.log")
+	var log strings.Builder
+	for i := 0; i < dashboardLogLines+5; i++ {
+		log.WriteString("line\n")
+	}
+	log.WriteString("last indexing event\n")
+	if err := os.WriteFile(logFile, []byte(log.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	watchFn := func() watcherRuntimeStatus {
+		return watcherRuntimeStatus{running: true, pid: 4242, logFile: logFile}
+	}
+	m := newDashboardModel("", nil, nil, watchFn, nil)
+	m, _ = updateDashboard(t, m, tea.WindowSizeMsg{Width: 100, Height: 40})
+
+	if len(m.watchLog) != dashboardLogLines {
+		t.Fatalf("expected %d log lines, got %d", dashboardLogLines, len(m.watchLog))
+	}
+	view := m.View()
+	for _, want := range []string{"running (PID 4242)", "last indexing event"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("watch view missing %q", want)
+		}
+	}
+}
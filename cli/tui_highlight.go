@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
+)
+
+// highlightLines splits code, from the file at path, into lines colored with
+// the chroma style named style for a 256-color terminal. Tabs are expanded
+// to 4 spaces so the lines can be measured and truncated. The lines are
+// returned uncolored when style is empty or the language is unknown.
+func highlightLines(path, code, style string) []string {
+	code = strings.ReplaceAll(code, "\t", "    ")
+	plain := strings.Split(code, "\n")
+	if style == "" {
+		return plain
+	}
+	lexer := lexers.Match(path)
+	if lexer == nil {
+		if lang := langdetect.Language(langdetect.Ext(path, code)); lang != "" {
+			lexer = lexers.Get(lang)
+		}
+	}
+	if lexer == nil {
+		return plain
+	}
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return plain
+	}
+
+	formatter := formatters.Get("terminal256")
+	chromaStyle := styles.Get(style)
+	lines := make([]string, 0, len(plain))
+	for _, lineTokens := range chroma.SplitTokensIntoLines(tokens.Tokens()) {
+		var sb strings.Builder
+		if err := formatter.Format(&sb, chromaStyle, chroma.Literator(lineTokens...)); err != nil {
+			return plain
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), "\n"))
+	}
+	// Lexers may add or drop a trailing newline.
+	for len(lines) < len(plain) {
+		lines = append(lines, "")
+	}
+	return lines[:len(plain)]
+}
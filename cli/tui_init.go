@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,11 +25,19 @@ const (
 	initStepRPG
 	initStepRPGMode
 	initStepRPGConfig
+	initStepAdvanced
+	initStepAdvancedConfig
 	initStepReview
+	initStepCheck
 )
 
+// initCheckMsg carries the result of the wizard's connectivity check.
+type initCheckMsg struct {
+	report connectivityReport
+}
+
 var initProviderOptions = []string{"ollama", "lmstudio", "openai"}
-var initBackendOptions = []string{"gob", "postgres", "qdrant"}
+var initBackendOptions = []string{"gob", "postgres", "qdrant", "weaviate", "redis", "opensearch"}
 
 type initUIModel struct {
 	theme tuiTheme
@@ -51,15 +61,25 @@ type initUIModel struct {
 	rpgEnabled bool
 	rpgUseLLM  bool
 
+	// Advanced options
+	advanced bool
+
 	// Inputs for Provider Config
 	providerInputs []textinput.Model
 	// Inputs for Backend Config
 	backendInputs []textinput.Model
 	// Inputs for RPG Config
 	rpgInputs []textinput.Model
+	// Inputs for Advanced options
+	advancedInputs []textinput.Model
 
 	focusIndex int
 
+	// Connectivity check of the reviewed configuration
+	pending  *config.Config
+	checking bool
+	report   *connectivityReport
+
 	canceled bool
 	done     bool
 	result   *config.Config
@@ -117,10 +137,18 @@ func (m initUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.done = true
 			return m, tea.Quit
 		}
+	case initCheckMsg:
+		m.checking = false
+		m.report = &msg.report
+		return m, nil
+	}
+
+	if m.step == initStepCheck {
+		return m.updateCheck(msg)
 	}
 
 	// Handle inputs if in config step
-	if m.step == initStepProviderConfig || m.step == initStepBackendConfig || m.step == initStepRPGConfig {
+	if m.isInputStep() {
 		return m.updateInputs(msg)
 	}
 
@@ -141,12 +169,12 @@ func (m initUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.step == initStepReview {
 				cfg, err := m.buildConfig()
 				if err != nil {
-					m.canceled = true
-				} else {
-					m.result = cfg
+					// The review step shows the error.
+					return m, nil
 				}
-				m.done = true
-				return m, tea.Quit
+				m.pending = cfg
+				m.step = initStepCheck
+				return m, m.startCheck()
 			}
 			m.stepForward()
 		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
@@ -158,14 +186,68 @@ func (m initUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// startCheck tests the pending configuration in the background.
+func (m *initUIModel) startCheck() tea.Cmd {
+	m.checking = true
+	m.report = nil
+	cfg := m.pending
+	cwd := m.cwd
+	return func() tea.Msg {
+		return initCheckMsg{report: initConnectivityCheck(context.Background(), cfg, cwd)}
+	}
+}
+
+// updateCheck handles keys on the check step: a passing configuration is
+// applied with Enter, a failing one only with A.
+func (m initUIModel) updateCheck(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "q":
+		m.canceled = true
+		m.done = true
+		return m, tea.Quit
+	}
+	if m.checking {
+		return m, nil
+	}
+	switch key.String() {
+	case "b", "esc":
+		m.stepBack()
+	case "r":
+		return m, m.startCheck()
+	case "enter", "a":
+		if key.String() == "enter" && !m.report.ok() {
+			return m, nil
+		}
+		m.result = m.pending
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m initUIModel) isInputStep() bool {
+	switch m.step {
+	case initStepProviderConfig, initStepBackendConfig, initStepRPGConfig, initStepAdvancedConfig:
+		return true
+	}
+	return false
+}
+
 func (m initUIModel) updateInputs(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var inputs []textinput.Model
 
-	if m.step == initStepProviderConfig {
+	switch m.step {
+	case initStepProviderConfig:
 		inputs = m.providerInputs
-	} else if m.step == initStepBackendConfig {
+	case initStepBackendConfig:
 		inputs = m.backendInputs
-	} else {
+	case initStepAdvancedConfig:
+		inputs = m.advancedInputs
+	default:
 		inputs = m.rpgInputs
 	}
 
@@ -228,11 +310,14 @@ func (m initUIModel) updateInputs(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
-	if m.step == initStepProviderConfig {
+	switch m.step {
+	case initStepProviderConfig:
 		m.providerInputs = inputs
-	} else if m.step == initStepBackendConfig {
+	case initStepBackendConfig:
 		m.backendInputs = inputs
-	} else {
+	case initStepAdvancedConfig:
+		m.advancedInputs = inputs
+	default:
 		m.rpgInputs = inputs
 	}
 
@@ -244,12 +329,12 @@ func (m initUIModel) View() string {
 		return "Loading init wizard..."
 	}
 
-	phases := []string{"Env", "Inherit", "Provider", "Config", "Backend", "Config", "RPG", "Mode", "Config", "Review"}
+	phases := []string{"Env", "Inherit", "Provider", "Config", "Backend", "Config", "RPG", "Mode", "Config", "Advanced", "Config", "Review", "Check"}
 	current := int(m.step)
 	// Adjust phase display based on skipped steps
 	if !m.allowInherit {
 		// If inherit is skipped
-		phases = []string{"Env", "Provider", "Config", "Backend", "Config", "RPG", "Mode", "Config", "Review"}
+		phases = []string{"Env", "Provider", "Config", "Backend", "Config", "RPG", "Mode", "Config", "Advanced", "Config", "Review", "Check"}
 		if m.step > initStepEnv {
 			current = int(m.step) - 1
 		}
@@ -267,9 +352,12 @@ func (m initUIModel) View() string {
 	body := m.theme.panel.Width(m.width - 2).Height(m.height - 10).Render(m.renderStepContent())
 
 	help := "up/down choose | enter next | b back | q cancel"
-	if m.step == initStepProviderConfig || m.step == initStepBackendConfig || m.step == initStepRPGConfig {
+	if m.isInputStep() {
 		help = "tab/shift+tab nav | enter next/submit | esc back | ctrl+c cancel"
 	}
+	if m.step == initStepCheck {
+		help = "enter apply | a apply anyway | r retry | b back | q cancel"
+	}
 	footer := m.theme.panel.Width(m.width - 2).Render(m.theme.help.Render(help))
 	return strings.Join([]string{header, rail, body, footer}, "\n")
 }
@@ -289,6 +377,8 @@ func (m *initUIModel) moveSelection(delta int) {
 		m.rpgEnabled = !m.rpgEnabled
 	case initStepRPGMode:
 		m.rpgUseLLM = !m.rpgUseLLM
+	case initStepAdvanced:
+		m.advanced = !m.advanced
 	}
 }
 
@@ -333,27 +423,46 @@ func (m *initUIModel) stepForward() {
 		if m.rpgEnabled {
 			m.step = initStepRPGMode
 		} else {
-			m.step = initStepReview
+			m.step = initStepAdvanced
 		}
 	case initStepRPGMode:
 		if m.rpgUseLLM {
 			m.initRPGInputs()
 			m.step = initStepRPGConfig
 		} else {
-			m.step = initStepReview
+			m.step = initStepAdvanced
 		}
 	case initStepRPGConfig:
+		m.step = initStepAdvanced
+	case initStepAdvanced:
+		if m.advanced {
+			m.initAdvancedInputs()
+			m.step = initStepAdvancedConfig
+		} else {
+			m.step = initStepReview
+		}
+	case initStepAdvancedConfig:
 		m.step = initStepReview
 	}
 }
 
 func (m *initUIModel) stepBack() {
 	switch m.step {
+	case initStepCheck:
+		m.step = initStepReview
 	case initStepReview:
 		if m.allowInherit && m.inherit {
 			m.step = initStepInherit
 			return
 		}
+		if m.advanced {
+			m.step = initStepAdvancedConfig
+		} else {
+			m.step = initStepAdvanced
+		}
+	case initStepAdvancedConfig:
+		m.step = initStepAdvanced
+	case initStepAdvanced:
 		if m.rpgEnabled {
 			if m.rpgUseLLM {
 				m.step = initStepRPGConfig
@@ -427,7 +536,7 @@ func (m *initUIModel) initBackendInputs() {
 		m.backendInputs = append(m.backendInputs, tiDSN)
 	case "qdrant":
 		tiEndpoint := textinput.New()
-		tiEndpoint.Placeholder = "localhost"
+		tiEndpoint.Placeholder = "localhost or https://<cluster>.cloud.qdrant.io"
 		tiEndpoint.SetValue(backendDefaults.Qdrant.Endpoint)
 
 		tiPort := textinput.New()
@@ -438,10 +547,54 @@ func (m *initUIModel) initBackendInputs() {
 		tiCollection.Placeholder = "Collection Name (optional)"
 
 		tiAPIKey := textinput.New()
-		tiAPIKey.Placeholder = "API Key (optional)"
+		tiAPIKey.Placeholder = "API Key (required for Qdrant Cloud)"
 		tiAPIKey.EchoMode = textinput.EchoPassword
 
 		m.backendInputs = append(m.backendInputs, tiEndpoint, tiPort, tiCollection, tiAPIKey)
+	case "weaviate":
+		tiEndpoint := textinput.New()
+		tiEndpoint.Placeholder = config.DefaultWeaviateEndpoint
+		tiEndpoint.SetValue(backendDefaults.Weaviate.Endpoint)
+		tiEndpoint.Width = 60
+
+		tiClass := textinput.New()
+		tiClass.Placeholder = "Class Name (optional)"
+
+		tiAPIKey := textinput.New()
+		tiAPIKey.Placeholder = "API Key (optional)"
+		tiAPIKey.EchoMode = textinput.EchoPassword
+
+		m.backendInputs = append(m.backendInputs, tiEndpoint, tiClass, tiAPIKey)
+	case "redis":
+		tiURL := textinput.New()
+		tiURL.Placeholder = "redis://[:password@]host:port[/db]"
+		tiURL.SetValue(backendDefaults.Redis.URL)
+		tiURL.Width = 60
+
+		tiIndex := textinput.New()
+		tiIndex.Placeholder = "Index Name (optional)"
+
+		tiTTL := textinput.New()
+		tiTTL.Placeholder = "Key Expiry, e.g. 24h (optional)"
+
+		m.backendInputs = append(m.backendInputs, tiURL, tiIndex, tiTTL)
+	case "opensearch":
+		tiEndpoint := textinput.New()
+		tiEndpoint.Placeholder = config.DefaultOpenSearchEndpoint
+		tiEndpoint.SetValue(backendDefaults.OpenSearch.Endpoint)
+		tiEndpoint.Width = 60
+
+		tiIndex := textinput.New()
+		tiIndex.Placeholder = "Index Name (optional)"
+
+		tiUsername := textinput.New()
+		tiUsername.Placeholder = "Username (optional)"
+
+		tiPassword := textinput.New()
+		tiPassword.Placeholder = "Password (optional)"
+		tiPassword.EchoMode = textinput.EchoPassword
+
+		m.backendInputs = append(m.backendInputs, tiEndpoint, tiIndex, tiUsername, tiPassword)
 	}
 }
 
@@ -496,6 +649,91 @@ func (m *initUIModel) initRPGInputs() {
 	m.rpgInputs = append(m.rpgInputs, tiProvider, tiEndpoint, tiModel, tiKey)
 }
 
+// advancedLabels names the advanced inputs, in order.
+var advancedLabels = []string{"Dimensions (empty = model default)", "Parallel embedding batches", "Chunk size (tokens)", "Chunk overlap (tokens)", "Ignore patterns (comma-separated)"}
+
+// initAdvancedInputs prefills the advanced inputs with the defaults of the
+// selected provider.
+func (m *initUIModel) initAdvancedInputs() {
+	m.focusIndex = 0
+
+	defaults := config.DefaultConfig()
+	embedder := config.DefaultEmbedderForProvider(initProviderOptions[m.providerIdx])
+	dimensions := ""
+	if embedder.Dimensions != nil {
+		dimensions = strconv.Itoa(*embedder.Dimensions)
+	}
+	parallelism := embedder.Parallelism
+	if parallelism == 0 {
+		parallelism = config.DefaultOpenAIParallelism
+	}
+
+	values := []string{
+		dimensions,
+		strconv.Itoa(parallelism),
+		strconv.Itoa(defaults.Chunking.Size),
+		strconv.Itoa(defaults.Chunking.Overlap),
+		strings.Join(defaults.Ignore, ", "),
+	}
+	m.advancedInputs = make([]textinput.Model, len(values))
+	for i, v := range values {
+		ti := textinput.New()
+		ti.Width = 50
+		ti.SetValue(v)
+		m.advancedInputs[i] = ti
+	}
+	m.advancedInputs[0].Placeholder = "model default"
+	m.advancedInputs[4].CharLimit = 1000
+}
+
+// applyAdvanced sets the advanced options entered in the wizard on cfg.
+func (m initUIModel) applyAdvanced(cfg *config.Config) error {
+	if !m.advanced || len(m.advancedInputs) != len(advancedLabels) {
+		return nil
+	}
+	value := func(i int) string { return strings.TrimSpace(m.advancedInputs[i].Value()) }
+	positive := func(i int) (int, error) {
+		n, err := strconv.Atoi(value(i))
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%s must be a positive number, got %q", advancedLabels[i], value(i))
+		}
+		return n, nil
+	}
+
+	if value(0) == "" {
+		cfg.Embedder.Dimensions = nil
+	} else {
+		dim, err := positive(0)
+		if err != nil {
+			return err
+		}
+		cfg.Embedder.Dimensions = &dim
+	}
+	parallelism, err := positive(1)
+	if err != nil {
+		return err
+	}
+	cfg.Embedder.Parallelism = parallelism
+	size, err := positive(2)
+	if err != nil {
+		return err
+	}
+	overlap, err := strconv.Atoi(value(3))
+	if err != nil || overlap < 0 || overlap >= size {
+		return fmt.Errorf("chunk overlap must be between 0 and the chunk size (%d), got %q", size, value(3))
+	}
+	cfg.Chunking.Size = size
+	cfg.Chunking.Overlap = overlap
+
+	cfg.Ignore = nil
+	for _, pattern := range strings.Split(value(4), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			cfg.Ignore = append(cfg.Ignore, pattern)
+		}
+	}
+	return nil
+}
+
 func (m initUIModel) renderStepContent() string {
 	switch m.step {
 	case initStepEnv:
@@ -534,6 +772,12 @@ func (m initUIModel) renderStepContent() string {
 			labels = []string{"DSN"}
 		} else if initBackendOptions[m.backendIdx] == "qdrant" {
 			labels = []string{"Endpoint", "Port", "Collection", "API Key"}
+		} else if initBackendOptions[m.backendIdx] == "weaviate" {
+			labels = []string{"Endpoint", "Class", "API Key"}
+		} else if initBackendOptions[m.backendIdx] == "redis" {
+			labels = []string{"URL", "Index", "Expiry"}
+		} else if initBackendOptions[m.backendIdx] == "opensearch" {
+			labels = []string{"Endpoint", "Index", "Username", "Password"}
 		} else {
 			return m.theme.text.Render("No configuration needed for GOB backend.\n\nPress Enter to continue.")
 		}
@@ -580,9 +824,32 @@ func (m initUIModel) renderStepContent() string {
 		return strings.Join(lines, "\n")
 	case initStepRPGConfig:
 		return m.renderInputs("RPG AI Configuration", []string{"Provider", "Endpoint", "Model", "API Key"}, m.rpgInputs)
+	case initStepAdvanced:
+		choice := "No (use defaults)"
+		if m.advanced {
+			choice = "Yes"
+		}
+		lines := []string{
+			m.theme.subtitle.Render("Advanced Options"),
+			"",
+			m.theme.text.Render("Tune embedding dimensions, embedding parallelism, chunk size and overlap,"),
+			m.theme.text.Render("and ignore patterns now instead of editing .grepai/config.yaml later."),
+			"",
+			m.theme.text.Render(fmt.Sprintf("Customize advanced options: %s", choice)),
+			"",
+			m.theme.muted.Render("Use up/down to toggle, Enter to continue."),
+		}
+		return strings.Join(lines, "\n")
+	case initStepAdvancedConfig:
+		return m.renderInputs("Advanced Configuration", advancedLabels, m.advancedInputs)
 	case initStepReview:
-		cfg, _ := m.buildConfig()
+		cfg, err := m.buildConfig()
+		if err != nil {
+			return m.theme.danger.Render(err.Error()) + "\n\n" + m.theme.muted.Render("Press B to go back and fix it.")
+		}
 		return m.renderReview(cfg)
+	case initStepCheck:
+		return m.renderCheck()
 	default:
 		return ""
 	}
@@ -641,6 +908,17 @@ func (m initUIModel) renderReview(cfg *config.Config) string {
 		m.theme.text.Render(fmt.Sprintf("Backend:  %s", cfg.Store.Backend)),
 		m.theme.text.Render(fmt.Sprintf("RPG Mode: %s", rpgStatus)),
 	}
+	if m.advanced {
+		dimensions := "model default"
+		if cfg.Embedder.Dimensions != nil {
+			dimensions = strconv.Itoa(*cfg.Embedder.Dimensions)
+		}
+		lines = append(lines,
+			m.theme.text.Render(fmt.Sprintf("Dimensions: %s", dimensions)),
+			m.theme.text.Render(fmt.Sprintf("Chunking: %d tokens, %d overlap", cfg.Chunking.Size, cfg.Chunking.Overlap)),
+			m.theme.text.Render(fmt.Sprintf("Ignore:   %d patterns", len(cfg.Ignore))),
+		)
+	}
 
 	if cfg.RPG.Enabled && cfg.RPG.FeatureMode != "local" {
 		lines = append(lines, m.theme.muted.Render(fmt.Sprintf("  AI: %s @ %s", cfg.RPG.LLMModel, cfg.RPG.LLMProvider)))
@@ -652,9 +930,45 @@ func (m initUIModel) renderReview(cfg *config.Config) string {
 		lines = append(lines,
 			m.theme.text.Render(fmt.Sprintf("Qdrant endpoint: %s", cfg.Store.Qdrant.Endpoint)),
 			m.theme.text.Render(fmt.Sprintf("Qdrant port:     %d", cfg.Store.Qdrant.Port)),
+			m.theme.text.Render(fmt.Sprintf("Qdrant TLS:      %t", cfg.Store.Qdrant.TLSEnabled())),
 		)
+		if config.IsQdrantCloudEndpoint(cfg.Store.Qdrant.Endpoint) && cfg.Store.Qdrant.APIKey == "" {
+			lines = append(lines, m.theme.warn.Render("Qdrant Cloud requires an API key."))
+		}
+	case "weaviate":
+		lines = append(lines, m.theme.text.Render(fmt.Sprintf("Weaviate endpoint: %s", cfg.Store.Weaviate.Endpoint)))
+	case "redis":
+		lines = append(lines, m.theme.text.Render(fmt.Sprintf("Redis URL: %s", maskDSN(cfg.Store.Redis.URL))))
+		if cfg.Store.Redis.TTLSeconds > 0 {
+			lines = append(lines, m.theme.text.Render(fmt.Sprintf("Key expiry: %s", cfg.Store.Redis.TTL())))
+		}
+	case "opensearch":
+		lines = append(lines, m.theme.text.Render(fmt.Sprintf("OpenSearch endpoint: %s", cfg.Store.OpenSearch.Endpoint)))
+	}
+	lines = append(lines, "", m.theme.info.Render("Press A (or Enter) to test the connection and apply configuration."))
+	return strings.Join(lines, "\n")
+}
+
+func (m initUIModel) renderCheck() string {
+	lines := []string{m.theme.subtitle.Render("Connectivity Check"), ""}
+	if m.checking || m.report == nil {
+		lines = append(lines, m.theme.info.Render("Embedding a test string and connecting to the backend..."))
+		return strings.Join(lines, "\n")
+	}
+	results := m.report.lines()
+	for i, line := range results {
+		style := m.theme.ok
+		if (i == 0 && m.report.embedErr != nil) || (i == 1 && m.report.storeErr != nil) {
+			style = m.theme.danger
+		}
+		lines = append(lines, style.Render(line))
+	}
+	lines = append(lines, "")
+	if m.report.ok() {
+		lines = append(lines, m.theme.info.Render("Press Enter to save the configuration."))
+	} else {
+		lines = append(lines, m.theme.warn.Render("Press B to fix the configuration, R to retry, or A to save it anyway."))
 	}
-	lines = append(lines, "", m.theme.info.Render("Press A (or Enter) to apply configuration."))
 	return strings.Join(lines, "\n")
 }
 
@@ -720,15 +1034,43 @@ func (m initUIModel) buildConfig() (*config.Config, error) {
 		if len(m.backendInputs) >= 4 {
 			cfg.Store.Qdrant.APIKey = m.backendInputs[3].Value()
 		}
+		cfg.Store.Qdrant.UseTLS = cfg.Store.Qdrant.TLSEnabled()
+	case "weaviate":
+		if len(m.backendInputs) >= 3 {
+			cfg.Store.Weaviate.Endpoint = m.backendInputs[0].Value()
+			cfg.Store.Weaviate.Class = m.backendInputs[1].Value()
+			cfg.Store.Weaviate.APIKey = m.backendInputs[2].Value()
+		}
+	case "redis":
+		if len(m.backendInputs) >= 3 {
+			cfg.Store.Redis.URL = m.backendInputs[0].Value()
+			cfg.Store.Redis.Index = m.backendInputs[1].Value()
+			if ttl := strings.TrimSpace(m.backendInputs[2].Value()); ttl != "" {
+				d, err := time.ParseDuration(ttl)
+				if err != nil || d < time.Second {
+					return nil, fmt.Errorf("invalid Redis key expiry %q: use a duration of at least 1s, such as 24h", ttl)
+				}
+				cfg.Store.Redis.TTLSeconds = int(d / time.Second)
+			}
+		}
+	case "opensearch":
+		if len(m.backendInputs) >= 4 {
+			cfg.Store.OpenSearch.Endpoint = m.backendInputs[0].Value()
+			cfg.Store.OpenSearch.Index = m.backendInputs[1].Value()
+			cfg.Store.OpenSearch.Username = m.backendInputs[2].Value()
+			cfg.Store.OpenSearch.Password = m.backendInputs[3].Value()
+		}
 	}
 
+	if err := m.applyAdvanced(cfg); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
 func runInitWizardUI(cwd string, baseCfg *config.Config, gitInfo *git.DetectInfo, mainCfg *config.Config, forceInherit bool) (*config.Config, error) {
 	model := newInitUIModel(cwd, baseCfg, gitInfo, mainCfg, forceInherit)
-	program := tea.NewProgram(model, tea.WithAltScreen())
-	finalModel, err := program.Run()
+	finalModel, err := runTUIProgram(model)
 	if err != nil {
 		return nil, err
 	}
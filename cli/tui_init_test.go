@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -53,3 +56,99 @@ func TestInitWizardNumberInput(t *testing.T) {
 		t.Fatalf("providerIdx after pressing '2' = %d, want 1", m.providerIdx)
 	}
 }
+
+func TestInitWizardConnectivityCheck(t *testing.T) {
+	original := initConnectivityCheck
+	defer func() { initConnectivityCheck = original }()
+	var checked *config.Config
+	fail := true
+	initConnectivityCheck = func(ctx context.Context, cfg *config.Config, projectRoot string) connectivityReport {
+		checked = cfg
+		report := connectivityReport{provider: cfg.Embedder.Provider, backend: cfg.Store.Backend, dimensions: 768}
+		if fail {
+			report.embedErr = errors.New("connection refused")
+		}
+		return report
+	}
+
+	m := newInitUIModel("/tmp/project", nil, nil, nil, false)
+	m.step = initStepReview
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(initUIModel)
+	if m.step != initStepCheck || !m.checking || cmd == nil {
+		t.Fatalf("step = %d checking = %v, want a running check", m.step, m.checking)
+	}
+	next, _ = m.Update(cmd())
+	m = next.(initUIModel)
+	if checked == nil || checked.Embedder.Provider != "ollama" {
+		t.Fatalf("checked config = %+v, want the reviewed config", checked)
+	}
+	if !strings.Contains(m.renderCheck(), "connection refused") {
+		t.Fatal("check step should show the embedder error")
+	}
+
+	// Enter does not save a failing configuration; retry runs the check again.
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(initUIModel)
+	if m.done {
+		t.Fatal("enter must not apply a configuration that failed the check")
+	}
+	fail = false
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = next.(initUIModel)
+	next, _ = m.Update(cmd())
+	m = next.(initUIModel)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(initUIModel)
+	if !m.done || m.result != checked {
+		t.Fatalf("done = %v result = %p, want the checked config applied", m.done, m.result)
+	}
+}
+
+func TestInitWizardAdvancedOptions(t *testing.T) {
+	m := newInitUIModel("/tmp/project", nil, nil, nil, false)
+	m.step = initStepRPG
+	m.stepForward()
+	if m.step != initStepAdvanced {
+		t.Fatalf("step after RPG = %d, want advanced", m.step)
+	}
+	m.moveSelection(1)
+	m.stepForward()
+	if m.step != initStepAdvancedConfig || len(m.advancedInputs) != len(advancedLabels) {
+		t.Fatalf("step = %d inputs = %d, want advanced config inputs", m.step, len(m.advancedInputs))
+	}
+
+	m.advancedInputs[0].SetValue("1024")
+	m.advancedInputs[1].SetValue("8")
+	m.advancedInputs[2].SetValue("256")
+	m.advancedInputs[3].SetValue("32")
+	m.advancedInputs[4].SetValue("node_modules, dist ,,*.min.js")
+	cfg, err := m.buildConfig()
+	if err != nil {
+		t.Fatalf("buildConfig failed: %v", err)
+	}
+	if cfg.Embedder.Dimensions == nil || *cfg.Embedder.Dimensions != 1024 {
+		t.Fatalf("dimensions = %v, want 1024", cfg.Embedder.Dimensions)
+	}
+	if cfg.Embedder.Parallelism != 8 || cfg.Chunking.Size != 256 || cfg.Chunking.Overlap != 32 {
+		t.Fatalf("parallelism = %d chunking = %+v", cfg.Embedder.Parallelism, cfg.Chunking)
+	}
+	if strings.Join(cfg.Ignore, "|") != "node_modules|dist|*.min.js" {
+		t.Fatalf("ignore = %v", cfg.Ignore)
+	}
+
+	m.advancedInputs[3].SetValue("256")
+	if _, err := m.buildConfig(); err == nil || !strings.Contains(err.Error(), "overlap") {
+		t.Fatalf("buildConfig() error = %v, want an overlap error", err)
+	}
+	m.step = initStepReview
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(initUIModel)
+	if m.step != initStepReview || m.done {
+		t.Fatal("an invalid configuration must stay on the review step")
+	}
+	m.stepBack()
+	if m.step != initStepAdvancedConfig {
+		t.Fatalf("step back from review = %d, want advanced config", m.step)
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var uiPlain bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&uiPlain, "plain", false, "Plain ASCII output without colors, box drawing or full-screen UIs (screen-reader friendly)")
+}
+
+// plainUIEnabled reports whether plain mode was requested with --plain or
+// ui.ascii in the project config.
+func plainUIEnabled() bool {
+	return uiPlain || loadUIConfig().ASCII
+}
+
+// plainGlyphs maps the non-ASCII glyphs used by the TUIs to ASCII.
+var plainGlyphs = strings.NewReplacer(
+	"─", "-", "━", "-", "│", "|", "┃", "|",
+	"┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+",
+	"├", "+", "┤", "+", "┬", "+", "┴", "+", "┼", "+",
+	"█", "#", "░", "-", "▌", "#", "▏", "|",
+	"—", "-", "–", "-", "·", "|", "…", "...",
+	"↑", "up", "↓", "down", "←", "left", "→", "->",
+	"▲", "+", "▼", "-", "●", "*", "○", "o",
+	"✓", "ok", "✔", "ok", "✗", "x", "✘", "x",
+)
+
+// toPlainText strips terminal styling from s and replaces drawing glyphs with
+// ASCII so the output reads linearly in screen readers and basic terminals.
+func toPlainText(s string) string {
+	return plainGlyphs.Replace(ansi.Strip(s))
+}
+
+// printlnStyled prints styled text, as plain text in plain mode.
+func printlnStyled(s string) {
+	if plainUIEnabled() {
+		s = toPlainText(s)
+	}
+	fmt.Println(s)
+}
+
+// plainTUIModel wraps a TUI model so its views are rendered as plain text.
+type plainTUIModel struct {
+	tea.Model
+}
+
+func (m plainTUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.Model.Update(msg)
+	return plainTUIModel{next}, cmd
+}
+
+func (m plainTUIModel) View() string {
+	return toPlainText(m.Model.View())
+}
+
+// newTUIProgram creates the program for a TUI: full screen normally, inline
+// and plain text in plain mode.
+func newTUIProgram(m tea.Model) *tea.Program {
+	if plainUIEnabled() {
+		return tea.NewProgram(plainTUIModel{m})
+	}
+	return tea.NewProgram(m, tea.WithAltScreen())
+}
+
+// runTUIProgram runs m to completion and returns its final model.
+func runTUIProgram(m tea.Model) (tea.Model, error) {
+	final, err := newTUIProgram(m).Run()
+	if plain, ok := final.(plainTUIModel); ok {
+		final = plain.Model
+	}
+	return final, err
+}
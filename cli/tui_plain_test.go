@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestToPlainText(t *testing.T) {
+	styled := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Foreground(lipgloss.Color("#FF0000")).
+		Render("Token Savings — grepai\n[↑/↓] navigate · [q] quit")
+
+	got := toPlainText(styled)
+	for _, r := range got {
+		if r > 127 {
+			t.Fatalf("plain output contains non-ASCII rune %q:\n%s", r, got)
+		}
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Fatalf("plain output contains escape sequences: %q", got)
+	}
+	for _, want := range []string{"Token Savings - grepai", "[up/down] navigate | [q] quit", "+-"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("plain output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPlainTUITheme_SpellsOutStates(t *testing.T) {
+	theme := plainTUITheme()
+
+	rail := toPlainText(renderLifecycleRail(theme, []string{"Scan", "Embed", "Ready"}, 1))
+	if rail != "[Scan] done -> [Embed] current -> [Ready]" {
+		t.Errorf("rail = %q", rail)
+	}
+
+	list := toPlainText(renderSelectableList(theme, "Files", []string{"a.go", "b.go"}, 1, 30, 6))
+	if !strings.Contains(list, "> [b.go]") || !strings.Contains(list, "  a.go") {
+		t.Errorf("selected item should be marked in plain mode:\n%s", list)
+	}
+}
+
+func TestPlainTUIModel_UnwrapsFinalModel(t *testing.T) {
+	inner := newWorkspaceStatusModel(&config.WorkspaceConfig{}, "")
+	var m tea.Model = plainTUIModel{inner}
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	wrapped, ok := m.(plainTUIModel)
+	if !ok {
+		t.Fatalf("Update should keep the plain wrapper, got %T", m)
+	}
+	if _, ok := wrapped.Model.(workspaceStatusModel); !ok {
+		t.Fatalf("wrapped model type = %T", wrapped.Model)
+	}
+	if view := m.View(); strings.ContainsAny(view, "─│┌") {
+		t.Errorf("plain view still contains box drawing:\n%s", view)
+	}
+}
@@ -0,0 +1,365 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// searchUIDebounce is how long the search UI waits after the last keystroke
+// before searching, so typing a query does not embed every prefix of it.
+const searchUIDebounce = 250 * time.Millisecond
+
+// searchUIFunc searches the index for query, within pathPrefix when it is not
+// empty.
+type searchUIFunc func(ctx context.Context, query, pathPrefix string) ([]store.SearchResult, error)
+
+// searchUIDebounceMsg fires searchUIDebounce after an edit of the inputs.
+// Only the one of the latest edit starts a search.
+type searchUIDebounceMsg struct {
+	seq int
+}
+
+type searchUIResultsMsg struct {
+	seq     int
+	results []store.SearchResult
+	err     error
+}
+
+type searchUIEditorMsg struct {
+	err error
+}
+
+// searchUIModel is the interactive search of 'grepai search --ui': results
+// follow the query as it is typed, with a preview of the selected one.
+type searchUIModel struct {
+	theme tuiTheme
+
+	width  int
+	height int
+
+	projectRoot string
+	searchFn    searchUIFunc
+
+	query       textinput.Model
+	path        textinput.Model
+	pathFocused bool
+	pathFilter  bool
+
+	seq       int // incremented by each edit of the inputs
+	cancel    context.CancelFunc
+	searching bool
+	searchErr error
+	editorErr error
+	results   []store.SearchResult
+	selected  int
+	preview   []string // lines of the selected result, highlighted
+}
+
+// runSearchUI runs the interactive search of projectRoot with searcher,
+// starting from query and the path prefix of --path.
+func runSearchUI(projectRoot, query string, searcher *search.Searcher) error {
+	searchFn := func(ctx context.Context, query, pathPrefix string) ([]store.SearchResult, error) {
+		normalized, err := search.NormalizeProjectPathPrefix(pathPrefix, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		return searcher.Search(ctx, query, searchLimit, normalized)
+	}
+
+	// Logs of the searcher would draw over the UI.
+	restoreLog := silenceLog()
+	defer restoreLog()
+	_, err := runTUIProgram(newSearchUIModel(projectRoot, query, searchPath, searchFn))
+	return err
+}
+
+func newSearchUIModel(projectRoot, query, pathPrefix string, searchFn searchUIFunc) searchUIModel {
+	queryInput := textinput.New()
+	queryInput.Placeholder = "Search the codebase..."
+	queryInput.Prompt = "> "
+	queryInput.SetValue(query)
+	queryInput.Focus()
+
+	pathInput := textinput.New()
+	pathInput.Placeholder = "path/prefix/"
+	pathInput.Prompt = "path: "
+	pathInput.SetValue(pathPrefix)
+
+	return searchUIModel{
+		theme:       newTUITheme(),
+		projectRoot: projectRoot,
+		searchFn:    searchFn,
+		query:       queryInput,
+		path:        pathInput,
+		pathFilter:  pathPrefix != "",
+	}
+}
+
+func (m searchUIModel) Init() tea.Cmd {
+	seq := m.seq
+	return tea.Batch(textinput.Blink, func() tea.Msg {
+		return searchUIDebounceMsg{seq: seq}
+	})
+}
+
+func (m searchUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.query.Width = msg.Width - 8
+		m.path.Width = msg.Width - 12
+		return m, nil
+
+	case searchUIDebounceMsg:
+		if msg.seq != m.seq {
+			return m, nil
+		}
+		return m.startSearch()
+
+	case searchUIResultsMsg:
+		if msg.seq != m.seq {
+			return m, nil
+		}
+		m.searching = false
+		m.searchErr = msg.err
+		m.results = msg.results
+		m.selected = 0
+		m.refreshPreview()
+		return m, nil
+
+	case searchUIEditorMsg:
+		m.editorErr = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	return m.updateInput(msg)
+}
+
+func (m searchUIModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		if m.cancel != nil {
+			m.cancel()
+		}
+		return m, tea.Quit
+	case "enter":
+		return m.openSelected()
+	case "up", "ctrl+p":
+		if m.selected > 0 {
+			m.selected--
+			m.refreshPreview()
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if m.selected < len(m.results)-1 {
+			m.selected++
+			m.refreshPreview()
+		}
+		return m, nil
+	case "tab", "shift+tab":
+		m.pathFocused = !m.pathFocused
+		if m.pathFocused {
+			m.query.Blur()
+			return m, m.path.Focus()
+		}
+		m.path.Blur()
+		return m, m.query.Focus()
+	case "ctrl+f":
+		m.pathFilter = !m.pathFilter
+		return m.debounce()
+	}
+	return m.updateInput(msg)
+}
+
+// updateInput passes msg to the focused input, searching again after a
+// pause when it changes the query or the active path filter.
+func (m searchUIModel) updateInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if m.pathFocused {
+		before := m.path.Value()
+		m.path, cmd = m.path.Update(msg)
+		if m.path.Value() == before || !m.pathFilter {
+			return m, cmd
+		}
+	} else {
+		before := m.query.Value()
+		m.query, cmd = m.query.Update(msg)
+		if m.query.Value() == before {
+			return m, cmd
+		}
+	}
+	m, debounce := m.debounce()
+	return m, tea.Batch(cmd, debounce)
+}
+
+// debounce invalidates the search in flight and schedules the next one.
+func (m searchUIModel) debounce() (searchUIModel, tea.Cmd) {
+	m.seq++
+	seq := m.seq
+	return m, tea.Tick(searchUIDebounce, func(time.Time) tea.Msg {
+		return searchUIDebounceMsg{seq: seq}
+	})
+}
+
+// pathPrefix returns the path prefix results are filtered by, "" when the
+// filter is off.
+func (m searchUIModel) pathPrefix() string {
+	if !m.pathFilter {
+		return ""
+	}
+	return strings.TrimSpace(m.path.Value())
+}
+
+func (m searchUIModel) startSearch() (tea.Model, tea.Cmd) {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	query := strings.TrimSpace(m.query.Value())
+	if query == "" || m.searchFn == nil {
+		m.searching = false
+		m.searchErr = nil
+		m.results = nil
+		m.refreshPreview()
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.searching = true
+	m.editorErr = nil
+	seq, pathPrefix, searchFn := m.seq, m.pathPrefix(), m.searchFn
+	return m, func() tea.Msg {
+		results, err := searchFn(ctx, query, pathPrefix)
+		return searchUIResultsMsg{seq: seq, results: results, err: err}
+	}
+}
+
+func (m *searchUIModel) refreshPreview() {
+	m.preview = nil
+	if m.selected >= len(m.results) {
+		return
+	}
+	chunk := m.results[m.selected].Chunk
+	m.preview = highlightLines(chunk.FilePath, chunkBody(chunk.Content), m.theme.syntax)
+}
+
+// openSelected suspends the UI to open the selected result in the editor of
+// $VISUAL or $EDITOR, and resumes it when the editor exits.
+func (m searchUIModel) openSelected() (tea.Model, tea.Cmd) {
+	if m.selected >= len(m.results) {
+		return m, nil
+	}
+	chunk := m.results[m.selected].Chunk
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := editorCommand(editor, filepath.Join(m.projectRoot, filepath.FromSlash(chunk.FilePath)), chunk.StartLine)
+	cmd := exec.Command(args[0], args[1:]...)
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return searchUIEditorMsg{err: err}
+	})
+}
+
+// editorCommand returns the command line opening file at line with editor,
+// a command such as "vim" or "code --wait", or vi when it is empty.
+func editorCommand(editor, file string, line int) []string {
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	switch strings.TrimSuffix(filepath.Base(args[0]), ".exe") {
+	case "code", "code-insiders", "codium", "cursor":
+		return append(args, "--goto", fmt.Sprintf("%s:%d", file, line))
+	case "subl", "zed", "hx", "micro":
+		return append(args, fmt.Sprintf("%s:%d", file, line))
+	default:
+		// vi, vim, nvim, nano, emacs, kak and most terminal editors.
+		return append(args, fmt.Sprintf("+%d", line), file)
+	}
+}
+
+func (m searchUIModel) View() string {
+	if m.width == 0 {
+		return "Loading grepai search..."
+	}
+	width := m.width - 2
+
+	filter := m.theme.muted.Render("[off]")
+	if m.pathFilter {
+		filter = m.theme.ok.Render("[on]")
+	}
+	var header strings.Builder
+	header.WriteString(m.query.View())
+	header.WriteString("\n")
+	header.WriteString(filter + " " + m.path.View())
+	header.WriteString("\n")
+	switch {
+	case m.searchErr != nil:
+		header.WriteString(m.theme.danger.Render(fmt.Sprintf("Search failed: %v", m.searchErr)))
+	case m.editorErr != nil:
+		header.WriteString(m.theme.danger.Render(fmt.Sprintf("Editor failed: %v", m.editorErr)))
+	case m.searching:
+		header.WriteString(m.theme.info.Render("Searching..."))
+	case strings.TrimSpace(m.query.Value()) == "":
+		header.WriteString(m.theme.muted.Render("Type to search; results update as you type."))
+	default:
+		header.WriteString(m.theme.muted.Render(fmt.Sprintf("%d results", len(m.results))))
+	}
+	header.WriteString("\n")
+	header.WriteString(m.theme.help.Render("enter open | up/down select | tab edit path | ctrl+f path filter on/off | esc quit"))
+	headerPanel := m.theme.panel.Width(width).Render(header.String())
+
+	if len(m.results) == 0 {
+		return headerPanel
+	}
+
+	entries := make([]string, len(m.results))
+	for i, r := range m.results {
+		entries[i] = fmt.Sprintf("%.2f %s:%d-%d", r.Score, r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+		if r.SymbolName != "" {
+			entries[i] += " " + r.SymbolName
+		}
+	}
+	contentHeight := m.height - lipgloss.Height(headerPanel)
+	if contentHeight < 12 {
+		contentHeight = 12
+	}
+	topH, bottomH := panelHeights(contentHeight)
+	list := renderSelectableList(m.theme, "Results", entries, m.selected, width, topH)
+	return lipgloss.JoinVertical(lipgloss.Left, headerPanel, list, m.renderPreview(width, bottomH))
+}
+
+func (m searchUIModel) renderPreview(width, height int) string {
+	r := m.results[m.selected]
+	title := fmt.Sprintf("%s:%d-%d", r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+	if r.SymbolName != "" {
+		title = fmt.Sprintf("%s (%s %s)", title, r.SymbolKind, r.SymbolName)
+	}
+	lines := []string{m.theme.subtitle.Render(truncateRunes(title, width-4))}
+	for i, line := range m.preview {
+		if i >= height-3 {
+			break
+		}
+		number := m.theme.muted.Render(fmt.Sprintf("%4d │ ", r.Chunk.StartLine+i))
+		lines = append(lines, number+ansi.Truncate(line, width-11, ""))
+	}
+	return m.theme.panel.Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
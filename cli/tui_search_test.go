@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func updateSearchUI(t *testing.T, m searchUIModel, msg tea.Msg) (searchUIModel, tea.Cmd) {
+	t.Helper()
+	next, cmd := m.Update(msg)
+	return next.(searchUIModel), cmd
+}
+
+func TestSearchUIModel_Debounce(t *testing.T) {
+	var searched []string
+	searchFn := func(_ context.Context, query, pathPrefix string) ([]store.SearchResult, error) {
+		searched = append(searched, query+"|"+pathPrefix)
+		return []store.SearchResult{{Chunk: store.Chunk{FilePath: "auth/session.go", StartLine: 10, EndLine: 12, Content: "func Refresh() {\n}"}, Score: 0.8}}, nil
+	}
+	m := newSearchUIModel("/tmp/project", "", "", searchFn)
+	m, _ = updateSearchUI(t, m, tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	m, _ = updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ses")})
+	first := m.seq
+	m, _ = updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("sion")})
+
+	// The pause after the first keystrokes is superseded by the next ones.
+	m, cmd := updateSearchUI(t, m, searchUIDebounceMsg{seq: first})
+	if cmd != nil || m.searching {
+		t.Fatal("a stale debounce must not search")
+	}
+	m, cmd = updateSearchUI(t, m, searchUIDebounceMsg{seq: m.seq})
+	if cmd == nil || !m.searching {
+		t.Fatal("the latest debounce should search")
+	}
+	msg := cmd()
+	if !reflect.DeepEqual(searched, []string{"session|"}) {
+		t.Fatalf("searched = %v, want [session|]", searched)
+	}
+
+	// Results of a search the query has moved on from are dropped.
+	m, _ = updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	stale, _ := updateSearchUI(t, m, msg)
+	if len(stale.results) != 0 {
+		t.Fatal("stale results must be ignored")
+	}
+
+	m, _ = updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyBackspace})
+	m, cmd = updateSearchUI(t, m, searchUIDebounceMsg{seq: m.seq})
+	m, _ = updateSearchUI(t, m, cmd())
+	if m.searching || len(m.results) != 1 {
+		t.Fatalf("searching = %v, results = %d, want 1 result", m.searching, len(m.results))
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"auth/session.go:10-12", "  10 │ func Refresh() {"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestSearchUIModel_PathFilter(t *testing.T) {
+	var prefixes []string
+	searchFn := func(_ context.Context, _, pathPrefix string) ([]store.SearchResult, error) {
+		prefixes = append(prefixes, pathPrefix)
+		return nil, nil
+	}
+	m := newSearchUIModel("/tmp/project", "refresh", "", searchFn)
+	m, _ = updateSearchUI(t, m, tea.WindowSizeMsg{Width: 100, Height: 30})
+	search := func() {
+		t.Helper()
+		var cmd tea.Cmd
+		m, cmd = updateSearchUI(t, m, searchUIDebounceMsg{seq: m.seq})
+		m, _ = updateSearchUI(t, m, cmd())
+	}
+
+	// Typing a path while the filter is off does not search again.
+	m, _ = updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	seq := m.seq
+	m, _ = updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("auth/")})
+	if m.seq != seq || m.query.Value() != "refresh" {
+		t.Fatalf("seq = %d, query = %q: path edits should not touch the query", m.seq, m.query.Value())
+	}
+	search()
+
+	m, _ = updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyCtrlF})
+	search()
+	m, _ = updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyCtrlF})
+	search()
+
+	if want := []string{"", "auth/", ""}; !reflect.DeepEqual(prefixes, want) {
+		t.Fatalf("prefixes = %q, want %q", prefixes, want)
+	}
+}
+
+func TestSearchUIModel_Quit(t *testing.T) {
+	m := newSearchUIModel("/tmp/project", "", "", nil)
+	_, cmd := updateSearchUI(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("esc should quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatal("esc should quit")
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		editor string
+		want   []string
+	}{
+		{"", []string{"vi", "+12", "/p/a.go"}},
+		{"nvim", []string{"nvim", "+12", "/p/a.go"}},
+		{"code --wait", []string{"code", "--wait", "--goto", "/p/a.go:12"}},
+		{"/usr/local/bin/subl", []string{"/usr/local/bin/subl", "/p/a.go:12"}},
+	}
+	for _, tt := range tests {
+		if got := editorCommand(tt.editor, "/p/a.go", 12); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("editorCommand(%q) = %q, want %q", tt.editor, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightLines(t *testing.T) {
+	code := "package main\n\nfunc main() {\n\treturn\n}\n"
+
+	plain := highlightLines("main.go", code, "")
+	if want := []string{"package main", "", "func main() {", "    return", "}", ""}; !reflect.DeepEqual(plain, want) {
+		t.Fatalf("plain = %q, want %q", plain, want)
+	}
+
+	colored := highlightLines("main.go", code, "github-dark")
+	if len(colored) != len(plain) {
+		t.Fatalf("got %d highlighted lines, want %d", len(colored), len(plain))
+	}
+	if colored[0] == plain[0] {
+		t.Error("keywords should be colored")
+	}
+	for i := range colored {
+		if got := ansi.Strip(colored[i]); got != plain[i] {
+			t.Errorf("line %d = %q without colors, want %q", i, got, plain[i])
+		}
+	}
+
+	if got := highlightLines("notes.unknownext", "plain text", "github-dark"); !reflect.DeepEqual(got, []string{"plain text"}) {
+		t.Errorf("unknown language = %q, want uncolored lines", got)
+	}
+}
@@ -233,7 +233,6 @@ func (m statsUIModel) viewHistory() string {
 
 func runStatsUI(summary stats.Summary, entries []stats.Entry, provider string) error {
 	m := newStatsUIModel(summary, entries, provider)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err := p.Run()
+	_, err := runTUIProgram(m)
 	return err
 }
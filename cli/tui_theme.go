@@ -1,6 +1,12 @@
 package cli
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yoanbernabeu/grepai/config"
+)
 
 type tuiTheme struct {
 	canvas      lipgloss.Style
@@ -18,48 +24,225 @@ type tuiTheme struct {
 	railDone    lipgloss.Style
 	railCurrent lipgloss.Style
 	railPending lipgloss.Style
+	syntax      string // chroma style of code, "" for none
+}
+
+// tuiPalette is the set of colors a theme is built from.
+type tuiPalette struct {
+	foreground string
+	background string
+	border     string
+	title      string
+	subtitle   string
+	muted      string
+	help       string
+	accent     string
+	success    string
+	warning    string
+	danger     string
+	syntax     string
+}
+
+var darkTUIPalette = tuiPalette{
+	foreground: "#D7DBE0",
+	background: "#0E1116",
+	border:     "#3D4752",
+	title:      "#9FD3FF",
+	subtitle:   "#C0C8D4",
+	muted:      "#6E7B88",
+	help:       "#8FA0B3",
+	accent:     "#65B5FF",
+	success:    "#63C17A",
+	warning:    "#E7B65A",
+	danger:     "#E06B75",
+	syntax:     "github-dark",
 }
 
+var lightTUIPalette = tuiPalette{
+	foreground: "#1F2328",
+	background: "#FFFFFF",
+	border:     "#C8D1DA",
+	title:      "#0550AE",
+	subtitle:   "#32383F",
+	muted:      "#6E7781",
+	help:       "#57606A",
+	accent:     "#0969DA",
+	success:    "#1A7F37",
+	warning:    "#9A6700",
+	danger:     "#CF222E",
+	syntax:     "github",
+}
+
+var (
+	tuiThemeOnce   sync.Once
+	tuiThemeCached tuiTheme
+
+	uiConfigOnce   sync.Once
+	uiConfigCached config.UIConfig
+)
+
+// newTUITheme returns the theme selected by the ui section of the current
+// project's config (auto outside a project). It is resolved once per process
+// since auto detection queries the terminal.
 func newTUITheme() tuiTheme {
+	tuiThemeOnce.Do(func() {
+		tuiThemeCached = buildTUITheme(loadUIConfig(), plainUIEnabled(), os.Getenv("NO_COLOR") != "", lipgloss.HasDarkBackground)
+	})
+	return tuiThemeCached
+}
+
+// loadUIConfig returns the ui section of the current project's config, or the
+// defaults outside a project.
+func loadUIConfig() config.UIConfig {
+	uiConfigOnce.Do(func() {
+		uiConfigCached = config.DefaultConfig().UI
+		projectRoot, err := config.FindProjectRoot()
+		if err != nil {
+			return
+		}
+		cfg, err := config.Load(projectRoot)
+		if err != nil {
+			return
+		}
+		uiConfigCached = cfg.UI
+	})
+	return uiConfigCached
+}
+
+// buildTUITheme resolves cfg into a theme. Plain mode and NO_COLOR (noColor)
+// win over any configured theme; hasDarkBackground is only consulted for auto
+// and custom. Code is highlighted when cfg.SyntaxHighlight is set.
+func buildTUITheme(cfg config.UIConfig, plain, noColor bool, hasDarkBackground func() bool) tuiTheme {
+	if plain {
+		return plainTUITheme()
+	}
+	if noColor {
+		return monochromeTUITheme()
+	}
+
+	var palette tuiPalette
+	switch cfg.Theme {
+	case config.UIThemeDark:
+		palette = darkTUIPalette
+	case config.UIThemeLight:
+		palette = lightTUIPalette
+	default:
+		palette = darkTUIPalette
+		if !hasDarkBackground() {
+			palette = lightTUIPalette
+		}
+	}
+	if cfg.Theme == config.UIThemeCustom {
+		palette = palette.withOverrides(cfg.Palette)
+	}
+	if !cfg.SyntaxHighlight {
+		palette.syntax = ""
+	}
+	return themeFromPalette(palette)
+}
+
+func (p tuiPalette) withOverrides(o config.UIPalette) tuiPalette {
+	for _, override := range []struct {
+		dst *string
+		src string
+	}{
+		{&p.foreground, o.Foreground},
+		{&p.background, o.Background},
+		{&p.border, o.Border},
+		{&p.title, o.Title},
+		{&p.subtitle, o.Subtitle},
+		{&p.muted, o.Muted},
+		{&p.help, o.Help},
+		{&p.accent, o.Accent},
+		{&p.success, o.Success},
+		{&p.warning, o.Warning},
+		{&p.danger, o.Danger},
+	} {
+		if override.src != "" {
+			*override.dst = override.src
+		}
+	}
+	return p
+}
+
+func themeFromPalette(p tuiPalette) tuiTheme {
 	return tuiTheme{
 		canvas: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#D7DBE0")).
-			Background(lipgloss.Color("#0E1116")),
+			Foreground(lipgloss.Color(p.foreground)).
+			Background(lipgloss.Color(p.background)),
 		panel: lipgloss.NewStyle().
 			Border(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color("#3D4752")).
+			BorderForeground(lipgloss.Color(p.border)).
 			Padding(0, 1),
 		title: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#9FD3FF")),
+			Foreground(lipgloss.Color(p.title)),
 		subtitle: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#C0C8D4")),
+			Foreground(lipgloss.Color(p.subtitle)),
 		text: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#D7DBE0")),
+			Foreground(lipgloss.Color(p.foreground)),
 		muted: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6E7B88")),
+			Foreground(lipgloss.Color(p.muted)),
 		ok: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#63C17A")),
+			Foreground(lipgloss.Color(p.success)),
 		warn: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#E7B65A")),
+			Foreground(lipgloss.Color(p.warning)),
 		danger: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#E06B75")),
+			Foreground(lipgloss.Color(p.danger)),
 		info: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#65B5FF")),
+			Foreground(lipgloss.Color(p.accent)),
 		highlight: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#0E1116")).
-			Background(lipgloss.Color("#65B5FF")),
+			Foreground(lipgloss.Color(p.background)).
+			Background(lipgloss.Color(p.accent)),
 		help: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#8FA0B3")),
+			Foreground(lipgloss.Color(p.help)),
 		railDone: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#63C17A")),
+			Foreground(lipgloss.Color(p.success)),
 		railCurrent: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#65B5FF")),
+			Foreground(lipgloss.Color(p.accent)),
 		railPending: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6E7B88")),
+			Foreground(lipgloss.Color(p.muted)),
+		syntax: p.syntax,
+	}
+}
+
+// monochromeTUITheme is used when NO_COLOR is set: emphasis relies on bold,
+// faint and reverse video instead of colors.
+func monochromeTUITheme() tuiTheme {
+	return tuiTheme{
+		canvas: lipgloss.NewStyle(),
+		panel: lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			Padding(0, 1),
+		title:       lipgloss.NewStyle().Bold(true),
+		subtitle:    lipgloss.NewStyle().Bold(true),
+		text:        lipgloss.NewStyle(),
+		muted:       lipgloss.NewStyle().Faint(true),
+		ok:          lipgloss.NewStyle(),
+		warn:        lipgloss.NewStyle().Bold(true),
+		danger:      lipgloss.NewStyle().Bold(true),
+		info:        lipgloss.NewStyle(),
+		highlight:   lipgloss.NewStyle().Bold(true).Reverse(true),
+		help:        lipgloss.NewStyle().Faint(true),
+		railDone:    lipgloss.NewStyle().Bold(true),
+		railCurrent: lipgloss.NewStyle().Bold(true).Underline(true),
+		railPending: lipgloss.NewStyle().Faint(true),
 	}
 }
+
+// plainTUITheme is used in plain mode. Styling is stripped from the output
+// there, so states that other themes show with color are spelled out instead.
+func plainTUITheme() tuiTheme {
+	theme := monochromeTUITheme()
+	theme.panel = lipgloss.NewStyle().
+		Border(lipgloss.ASCIIBorder()).
+		Padding(0, 1)
+	theme.highlight = lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+	theme.railDone = lipgloss.NewStyle().Transform(func(s string) string { return s + " done" })
+	theme.railCurrent = lipgloss.NewStyle().Transform(func(s string) string { return s + " current" })
+	return theme
+}
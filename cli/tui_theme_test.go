@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestBuildTUITheme(t *testing.T) {
+	dark := func() bool { return true }
+	light := func() bool { return false }
+	never := func() bool {
+		t.Fatal("background detection should not run for explicit themes")
+		return true
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.UIConfig
+		detect   func() bool
+		wantText string
+		wantInfo string
+	}{
+		{"auto on dark terminal", config.UIConfig{Theme: config.UIThemeAuto}, dark, darkTUIPalette.foreground, darkTUIPalette.accent},
+		{"auto on light terminal", config.UIConfig{}, light, lightTUIPalette.foreground, lightTUIPalette.accent},
+		{"explicit dark", config.UIConfig{Theme: config.UIThemeDark}, never, darkTUIPalette.foreground, darkTUIPalette.accent},
+		{"explicit light", config.UIConfig{Theme: config.UIThemeLight}, never, lightTUIPalette.foreground, lightTUIPalette.accent},
+		{
+			"custom overrides detected base",
+			config.UIConfig{Theme: config.UIThemeCustom, Palette: config.UIPalette{Accent: "#FF8800"}},
+			light,
+			lightTUIPalette.foreground,
+			"#FF8800",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme := buildTUITheme(tt.cfg, false, false, tt.detect)
+			if got := theme.text.GetForeground(); got != lipgloss.Color(tt.wantText) {
+				t.Errorf("text foreground = %v, want %s", got, tt.wantText)
+			}
+			if got := theme.info.GetForeground(); got != lipgloss.Color(tt.wantInfo) {
+				t.Errorf("info foreground = %v, want %s", got, tt.wantInfo)
+			}
+		})
+	}
+}
+
+func TestBuildTUITheme_CustomSubtitleAndHelp(t *testing.T) {
+	cfg := config.UIConfig{Theme: config.UIThemeCustom, Palette: config.UIPalette{Subtitle: "#112233", Help: "245"}}
+	theme := buildTUITheme(cfg, false, false, func() bool { return true })
+
+	if got := theme.subtitle.GetForeground(); got != lipgloss.Color("#112233") {
+		t.Errorf("subtitle foreground = %v, want #112233", got)
+	}
+	if got := theme.help.GetForeground(); got != lipgloss.Color("245") {
+		t.Errorf("help foreground = %v, want 245", got)
+	}
+}
+
+func TestBuildTUITheme_NoColor(t *testing.T) {
+	cfg := config.UIConfig{Theme: config.UIThemeCustom, Palette: config.UIPalette{Accent: "#FF8800"}}
+	theme := buildTUITheme(cfg, false, true, func() bool { return true })
+
+	if _, ok := theme.info.GetForeground().(lipgloss.NoColor); !ok {
+		t.Errorf("NO_COLOR theme should not set colors, got %v", theme.info.GetForeground())
+	}
+	if !theme.highlight.GetReverse() {
+		t.Error("NO_COLOR highlight should use reverse video")
+	}
+}
+
+func TestBuildTUITheme_Syntax(t *testing.T) {
+	dark := func() bool { return true }
+	tests := []struct {
+		name  string
+		cfg   config.UIConfig
+		plain bool
+		want  string
+	}{
+		{"dark", config.UIConfig{Theme: config.UIThemeDark, SyntaxHighlight: true}, false, darkTUIPalette.syntax},
+		{"light", config.UIConfig{Theme: config.UIThemeLight, SyntaxHighlight: true}, false, lightTUIPalette.syntax},
+		{"turned off", config.UIConfig{Theme: config.UIThemeDark}, false, ""},
+		{"plain", config.UIConfig{Theme: config.UIThemeDark, SyntaxHighlight: true}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildTUITheme(tt.cfg, tt.plain, false, dark).syntax; got != tt.want {
+				t.Errorf("syntax = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,7 +12,29 @@ import (
 
 type traceRow struct {
 	title  string
+	symbol string
 	detail []string
+	// code is shown highlighted below detail: the line of the call site, or
+	// the signature of the symbol. codeFile tells its language.
+	code     string
+	codeFile string
+}
+
+// traceExpandFunc re-queries the trace index around a symbol for the given view.
+// For graph views it is expected to return a one-level graph centred on symbol.
+type traceExpandFunc func(symbol string, view traceViewKind) (trace.TraceResult, error)
+
+type traceExpandedMsg struct {
+	symbol string
+	result trace.TraceResult
+	err    error
+}
+
+// traceCrumb records the state before an expansion so it can be restored.
+type traceCrumb struct {
+	symbol   string
+	result   trace.TraceResult
+	selected int
 }
 
 type traceUIModel struct {
@@ -26,6 +48,11 @@ type traceUIModel struct {
 	rows   []traceRow
 
 	selected int
+
+	expand    traceExpandFunc
+	crumbs    []traceCrumb
+	expanding string
+	status    string
 }
 
 func newTraceUIModel(result trace.TraceResult, view traceViewKind) traceUIModel {
@@ -40,6 +67,138 @@ func newTraceUIModel(result trace.TraceResult, view traceViewKind) traceUIModel
 
 func (m traceUIModel) Init() tea.Cmd { return nil }
 
+// focus returns the symbol currently at the centre of the view.
+func (m traceUIModel) focus() string {
+	if len(m.crumbs) > 0 {
+		return m.crumbs[len(m.crumbs)-1].symbol
+	}
+	if m.result.Symbol != nil {
+		return m.result.Symbol.Name
+	}
+	return m.result.Query
+}
+
+func (m traceUIModel) breadcrumb() string {
+	parts := []string{m.result.Query}
+	if len(m.crumbs) > 0 {
+		parts = []string{m.crumbs[0].result.Query}
+	}
+	for _, c := range m.crumbs {
+		parts = append(parts, c.symbol)
+	}
+	return strings.Join(parts, " > ")
+}
+
+func (m traceUIModel) startExpand() (traceUIModel, tea.Cmd) {
+	if m.expand == nil || m.expanding != "" || len(m.rows) == 0 {
+		return m, nil
+	}
+	symbol := m.rows[m.selected].symbol
+	if symbol == "" {
+		return m, nil
+	}
+	if m.view != traceViewGraph && symbol == m.focus() {
+		return m, nil
+	}
+	m.expanding = symbol
+	m.status = fmt.Sprintf("Expanding %s...", symbol)
+	expand := m.expand
+	view := m.view
+	return m, func() tea.Msg {
+		result, err := expand(symbol, view)
+		return traceExpandedMsg{symbol: symbol, result: result, err: err}
+	}
+}
+
+func (m traceUIModel) applyExpansion(msg traceExpandedMsg) traceUIModel {
+	m.expanding = ""
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Expand %s failed: %v", msg.symbol, msg.err)
+		return m
+	}
+
+	m.crumbs = append(m.crumbs, traceCrumb{
+		symbol:   msg.symbol,
+		result:   m.result,
+		selected: m.selected,
+	})
+
+	if m.view == traceViewGraph && len(m.result.Candidates) == 0 {
+		before := 0
+		if m.result.Graph != nil {
+			before = len(m.result.Graph.Nodes)
+		}
+		m.result.Graph = mergeTraceGraphs(m.result.Graph, msg.result.Graph)
+		m.rows = buildTraceRows(m.result, m.view)
+		m.selected = 0
+		for i, row := range m.rows {
+			if row.symbol == msg.symbol {
+				m.selected = i
+				break
+			}
+		}
+		m.status = fmt.Sprintf("Expanded %s (+%d nodes)", msg.symbol, len(m.result.Graph.Nodes)-before)
+		return m
+	}
+
+	m.result = msg.result
+	m.rows = buildTraceRows(m.result, m.view)
+	m.selected = 0
+	m.status = fmt.Sprintf("Showing %s", msg.symbol)
+	return m
+}
+
+func (m traceUIModel) collapse() traceUIModel {
+	if len(m.crumbs) == 0 {
+		return m
+	}
+	last := m.crumbs[len(m.crumbs)-1]
+	m.crumbs = m.crumbs[:len(m.crumbs)-1]
+	m.result = last.result
+	m.rows = buildTraceRows(m.result, m.view)
+	m.selected = last.selected
+	if m.selected >= len(m.rows) {
+		m.selected = 0
+	}
+	m.status = ""
+	return m
+}
+
+// mergeTraceGraphs returns a new graph containing the nodes and edges of both
+// inputs. The base graph is not modified so it can be restored on collapse.
+func mergeTraceGraphs(base, extra *trace.CallGraph) *trace.CallGraph {
+	merged := &trace.CallGraph{
+		Nodes: make(map[string]trace.Symbol),
+		Edges: []trace.CallEdge{},
+	}
+	if base != nil {
+		merged.Root = base.Root
+		merged.Depth = base.Depth
+	}
+	edgeSeen := make(map[string]bool)
+	for _, g := range []*trace.CallGraph{base, extra} {
+		if g == nil {
+			continue
+		}
+		if merged.Root == "" {
+			merged.Root = g.Root
+		}
+		for name, sym := range g.Nodes {
+			if _, exists := merged.Nodes[name]; !exists {
+				merged.Nodes[name] = sym
+			}
+		}
+		for _, edge := range g.Edges {
+			key := edge.Caller + "->" + edge.Callee
+			if !edgeSeen[key] {
+				merged.Edges = append(merged.Edges, edge)
+				edgeSeen[key] = true
+			}
+		}
+	}
+	return merged
+}
+
 func (m traceUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -57,11 +216,24 @@ func (m traceUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.selected < len(m.rows)-1 {
 				m.selected++
 			}
+		case "enter":
+			return m.startExpand()
+		case "backspace", "esc":
+			return m.collapse(), nil
 		}
+	case traceExpandedMsg:
+		return m.applyExpansion(msg), nil
 	}
 	return m, nil
 }
 
+func (m traceUIModel) helpText() string {
+	if m.expand == nil {
+		return "up/down select | q quit"
+	}
+	return "up/down select | enter expand | backspace back | q quit"
+}
+
 func (m traceUIModel) View() string {
 	if m.width == 0 {
 		return "Loading trace UI..."
@@ -81,8 +253,23 @@ func (m traceUIModel) View() string {
 		m.theme.title.Render(title),
 		m.theme.text.Render(fmt.Sprintf("Query: %s  Mode: %s", m.result.Query, m.result.Mode)),
 	}
+	if len(m.result.Candidates) > 0 {
+		headerLines = append(headerLines, m.theme.warn.Render(fmt.Sprintf("%d symbols match; select one and press enter", len(m.result.Candidates))))
+	} else if m.result.Match != "" {
+		headerLines = append(headerLines, m.theme.muted.Render(fmt.Sprintf("Resolved by %s match", strings.ReplaceAll(m.result.Match, "_", "-"))))
+	}
 	if m.result.Graph != nil {
-		headerLines = append(headerLines, m.theme.text.Render(fmt.Sprintf("Depth: %d", m.result.Graph.Depth)))
+		depth := fmt.Sprintf("Depth: %d", m.result.Graph.Depth)
+		if len(m.crumbs) > 0 {
+			depth = fmt.Sprintf("Depth: %d (+%d expanded)", m.result.Graph.Depth, len(m.crumbs))
+		}
+		headerLines = append(headerLines, m.theme.text.Render(depth))
+	}
+	if len(m.crumbs) > 0 {
+		headerLines = append(headerLines, m.theme.muted.Render("Path: "+m.breadcrumb()))
+	}
+	if m.status != "" {
+		headerLines = append(headerLines, m.theme.info.Render(m.status))
 	}
 	header := m.theme.panel.Width(m.width - 2).Render(strings.Join(headerLines, "\n"))
 
@@ -97,12 +284,12 @@ func (m traceUIModel) View() string {
 				emptyAction,
 				m.width-2,
 			),
-			m.theme.panel.Width(m.width-2).Render(m.theme.help.Render("q quit")),
+			m.theme.panel.Width(m.width-2).Render(m.theme.help.Render(m.emptyHelpText())),
 		)
 	}
 
 	contentWidth := m.width - 2
-	contentHeight := m.height - 6
+	contentHeight := m.height - 6 - (len(headerLines) - 2)
 	if contentHeight < 6 {
 		contentHeight = 6
 	}
@@ -114,7 +301,7 @@ func (m traceUIModel) View() string {
 		}
 		list := renderSelectableList(m.theme, "Symbols/Nodes", items, m.selected, contentWidth, topH)
 		detail := m.renderDetailPanel(contentWidth, bottomH)
-		footer := m.theme.panel.Width(contentWidth).Render(m.theme.help.Render("up/down select | q quit"))
+		footer := m.theme.panel.Width(contentWidth).Render(m.theme.help.Render(m.helpText()))
 		return lipgloss.JoinVertical(lipgloss.Left, header, list, detail, footer)
 	}
 
@@ -133,10 +320,17 @@ func (m traceUIModel) View() string {
 	}
 	list := renderSelectableList(m.theme, "Symbols/Nodes", items, m.selected, leftW, contentHeight)
 	detail := m.renderDetailPanel(rightW, contentHeight)
-	footer := m.theme.panel.Width(contentWidth).Render(m.theme.help.Render("up/down select | q quit"))
+	footer := m.theme.panel.Width(contentWidth).Render(m.theme.help.Render(m.helpText()))
 	return lipgloss.JoinVertical(lipgloss.Left, header, lipgloss.JoinHorizontal(lipgloss.Top, list, detail), footer)
 }
 
+func (m traceUIModel) emptyHelpText() string {
+	if len(m.crumbs) > 0 {
+		return "backspace back | q quit"
+	}
+	return "q quit"
+}
+
 func (m traceUIModel) renderDetailPanel(width, height int) string {
 	row := m.rows[m.selected]
 	lines := []string{
@@ -146,57 +340,92 @@ func (m traceUIModel) renderDetailPanel(width, height int) string {
 		"",
 	}
 	lines = append(lines, row.detail...)
+	if strings.TrimSpace(row.code) != "" {
+		lines = append(lines, "")
+		lines = append(lines, highlightLines(row.codeFile, strings.TrimSpace(row.code), m.theme.syntax)...)
+	}
 	return m.theme.panel.Width(width).Height(height).Render(strings.Join(lines, "\n"))
 }
 
 func buildTraceRows(result trace.TraceResult, view traceViewKind) []traceRow {
 	rows := make([]traceRow, 0)
 
+	if len(result.Candidates) > 0 {
+		for _, c := range result.Candidates {
+			title := c.Symbol.Name
+			if c.Symbol.Receiver != "" {
+				title = strings.TrimLeft(c.Symbol.Receiver, "*") + "." + title
+			}
+			rows = append(rows, traceRow{
+				title:  title,
+				symbol: c.Symbol.Name,
+				detail: []string{
+					fmt.Sprintf("kind: %s", c.Symbol.Kind),
+					fmt.Sprintf("defined: %s:%d", c.Symbol.File, c.Symbol.Line),
+					fmt.Sprintf("match: %s", c.Match),
+				},
+				code:     c.Symbol.Signature,
+				codeFile: c.Symbol.File,
+			})
+		}
+		return rows
+	}
+
 	switch view {
 	case traceViewCallers:
 		if result.Symbol != nil {
 			rows = append(rows, traceRow{
-				title: fmt.Sprintf("target: %s", result.Symbol.Name),
+				title:  fmt.Sprintf("target: %s", result.Symbol.Name),
+				symbol: result.Symbol.Name,
 				detail: []string{
 					fmt.Sprintf("kind: %s", result.Symbol.Kind),
 					fmt.Sprintf("defined: %s:%d", result.Symbol.File, result.Symbol.Line),
 					fmt.Sprintf("feature: %s", safeValue(result.Symbol.FeaturePath)),
 					fmt.Sprintf("callers: %d", len(result.Callers)),
 				},
+				code:     result.Symbol.Signature,
+				codeFile: result.Symbol.File,
 			})
 		}
 		for _, c := range result.Callers {
 			rows = append(rows, traceRow{
-				title: c.Symbol.Name,
+				title:  c.Symbol.Name,
+				symbol: c.Symbol.Name,
 				detail: []string{
 					fmt.Sprintf("defined: %s:%d", c.Symbol.File, c.Symbol.Line),
 					fmt.Sprintf("feature: %s", safeValue(c.Symbol.FeaturePath)),
 					fmt.Sprintf("callsite: %s:%d", c.CallSite.File, c.CallSite.Line),
-					fmt.Sprintf("context: %s", safeValue(c.CallSite.Context)),
 				},
+				code:     c.CallSite.Context,
+				codeFile: c.CallSite.File,
 			})
 		}
 	case traceViewCallees:
 		if result.Symbol != nil {
 			rows = append(rows, traceRow{
-				title: fmt.Sprintf("target: %s", result.Symbol.Name),
+				title:  fmt.Sprintf("target: %s", result.Symbol.Name),
+				symbol: result.Symbol.Name,
 				detail: []string{
 					fmt.Sprintf("kind: %s", result.Symbol.Kind),
 					fmt.Sprintf("defined: %s:%d", result.Symbol.File, result.Symbol.Line),
 					fmt.Sprintf("feature: %s", safeValue(result.Symbol.FeaturePath)),
 					fmt.Sprintf("callees: %d", len(result.Callees)),
 				},
+				code:     result.Symbol.Signature,
+				codeFile: result.Symbol.File,
 			})
 		}
 		for _, c := range result.Callees {
 			rows = append(rows, traceRow{
-				title: c.Symbol.Name,
+				title:  c.Symbol.Name,
+				symbol: c.Symbol.Name,
 				detail: []string{
 					fmt.Sprintf("defined: %s:%d", c.Symbol.File, c.Symbol.Line),
 					fmt.Sprintf("feature: %s", safeValue(c.Symbol.FeaturePath)),
 					fmt.Sprintf("callsite: %s:%d", c.CallSite.File, c.CallSite.Line),
-					fmt.Sprintf("context: %s", safeValue(c.CallSite.Context)),
 				},
+				code:     c.CallSite.Context,
+				codeFile: c.CallSite.File,
 			})
 		}
 	case traceViewGraph:
@@ -228,8 +457,11 @@ func buildTraceRows(result trace.TraceResult, view traceViewKind) []traceRow {
 			}
 			detail = append(detail, edges...)
 			rows = append(rows, traceRow{
-				title:  name,
-				detail: detail,
+				title:    name,
+				symbol:   name,
+				detail:   detail,
+				code:     sym.Signature,
+				codeFile: sym.File,
 			})
 		}
 		if len(rows) == 0 && len(result.Graph.Edges) > 0 {
@@ -257,6 +489,7 @@ func buildTraceRows(result trace.TraceResult, view traceViewKind) []traceRow {
 				}
 				rows = append(rows, traceRow{
 					title:  fmt.Sprintf("%s -> %s", edge.Caller, edge.Callee),
+					symbol: edge.Callee,
 					detail: detail,
 				})
 			}
@@ -287,10 +520,10 @@ func safeValue(v string) string {
 	return v
 }
 
-func runTraceResultUI(result trace.TraceResult, view traceViewKind) error {
+func runTraceResultUI(result trace.TraceResult, view traceViewKind, expand traceExpandFunc) error {
 	model := newTraceUIModel(result, view)
-	program := tea.NewProgram(model, tea.WithAltScreen())
-	_, err := program.Run()
+	model.expand = expand
+	_, err := runTUIProgram(model)
 	return err
 }
 
@@ -335,7 +568,6 @@ func runTraceActionCardUI(title, why, action string) error {
 		why:    why,
 		action: action,
 	}
-	program := tea.NewProgram(model, tea.WithAltScreen())
-	_, err := program.Run()
+	_, err := runTUIProgram(model)
 	return err
 }
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/yoanbernabeu/grepai/trace"
 )
 
@@ -65,3 +66,31 @@ func TestBuildTraceRows_GraphWithEdgesWithoutNodes(t *testing.T) {
 		t.Fatalf("expected callsite detail in row: %+v", rows[0].detail)
 	}
 }
+
+func TestTraceDetailPanel_HighlightsCallSite(t *testing.T) {
+	result := trace.TraceResult{
+		Query:  "Refresh",
+		Symbol: &trace.Symbol{Name: "Refresh", File: "auth/session.go", Line: 40, Signature: "func Refresh(ctx context.Context) error {"},
+		Callers: []trace.CallerInfo{{
+			Symbol:   trace.Symbol{Name: "Middleware", File: "api/mw.go", Line: 10},
+			CallSite: trace.CallSite{File: "api/mw.go", Line: 18, Context: "\tif err := Refresh(ctx); err != nil {"},
+		}},
+	}
+	m := newTraceUIModel(result, traceViewCallers)
+	m.selected = 1
+
+	m.theme.syntax = ""
+	plain := m.renderDetailPanel(80, 12)
+	if !strings.Contains(plain, "if err := Refresh(ctx); err != nil {") {
+		t.Fatalf("detail panel should show the call site:\n%s", plain)
+	}
+
+	m.theme.syntax = darkTUIPalette.syntax
+	colored := m.renderDetailPanel(80, 12)
+	if colored == plain {
+		t.Error("call site should be highlighted")
+	}
+	if ansi.Strip(colored) != ansi.Strip(plain) {
+		t.Errorf("highlighting should not change the text:\n%s\nwant:\n%s", ansi.Strip(colored), ansi.Strip(plain))
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func TestTraceUIExpandGraphMergesNodesInPlace(t *testing.T) {
+	result := trace.TraceResult{
+		Query: "A",
+		Graph: &trace.CallGraph{
+			Root: "A",
+			Nodes: map[string]trace.Symbol{
+				"A": {Name: "A", File: "a.go", Line: 1},
+				"B": {Name: "B", File: "b.go", Line: 1},
+			},
+			Edges: []trace.CallEdge{{Caller: "A", Callee: "B", File: "a.go", Line: 3}},
+			Depth: 1,
+		},
+	}
+
+	var expanded string
+	m := newTraceUIModel(result, traceViewGraph)
+	m.width = 120
+	m.height = 30
+	m.expand = func(symbol string, view traceViewKind) (trace.TraceResult, error) {
+		expanded = symbol
+		return trace.TraceResult{
+			Query: symbol,
+			Graph: &trace.CallGraph{
+				Root: symbol,
+				Nodes: map[string]trace.Symbol{
+					"B": {Name: "B", File: "b.go", Line: 1},
+					"C": {Name: "C", File: "c.go", Line: 1},
+				},
+				Edges: []trace.CallEdge{{Caller: "B", Callee: "C", File: "b.go", Line: 4}},
+				Depth: 1,
+			},
+		}, nil
+	}
+
+	// Select "B" (rows are sorted by node name).
+	m.selected = 1
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected expand command on enter")
+	}
+	updated, _ = updated.(traceUIModel).Update(cmd())
+	m = updated.(traceUIModel)
+
+	if expanded != "B" {
+		t.Fatalf("expanded symbol = %q, want %q", expanded, "B")
+	}
+	if len(m.result.Graph.Nodes) != 3 {
+		t.Fatalf("node count = %d, want 3", len(m.result.Graph.Nodes))
+	}
+	if len(m.result.Graph.Edges) != 2 {
+		t.Fatalf("edge count = %d, want 2", len(m.result.Graph.Edges))
+	}
+	if m.rows[m.selected].symbol != "B" {
+		t.Fatalf("selection should stay on expanded node, got %q", m.rows[m.selected].symbol)
+	}
+
+	out := m.View()
+	if !strings.Contains(out, "Path: A > B") {
+		t.Fatalf("expected breadcrumb in view: %q", out)
+	}
+	if !strings.Contains(out, "+1 expanded") {
+		t.Fatalf("expected depth indicator in view: %q", out)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(traceUIModel)
+	if len(m.result.Graph.Nodes) != 2 {
+		t.Fatalf("collapse should restore previous graph, got %d nodes", len(m.result.Graph.Nodes))
+	}
+	if len(m.crumbs) != 0 {
+		t.Fatalf("crumbs = %d, want 0", len(m.crumbs))
+	}
+}
+
+func TestTraceUIExpandCallersNavigatesToSelectedSymbol(t *testing.T) {
+	result := trace.TraceResult{
+		Query:  "Login",
+		Symbol: &trace.Symbol{Name: "Login", File: "auth.go", Line: 10},
+		Callers: []trace.CallerInfo{
+			{Symbol: trace.Symbol{Name: "HandleRequest", File: "handler.go", Line: 5}},
+		},
+	}
+
+	m := newTraceUIModel(result, traceViewCallers)
+	m.width = 120
+	m.height = 30
+	m.expand = func(symbol string, view traceViewKind) (trace.TraceResult, error) {
+		if view != traceViewCallers {
+			t.Fatalf("view = %v, want callers", view)
+		}
+		return trace.TraceResult{
+			Query:  symbol,
+			Symbol: &trace.Symbol{Name: symbol, File: "handler.go", Line: 5},
+			Callers: []trace.CallerInfo{
+				{Symbol: trace.Symbol{Name: "main", File: "main.go", Line: 1}},
+			},
+		}, nil
+	}
+
+	// Enter on the target row is a no-op.
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatal("expected no expand command for the focused symbol")
+	}
+
+	m.selected = 1
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	updated, _ = updated.(traceUIModel).Update(cmd())
+	m = updated.(traceUIModel)
+
+	if m.result.Symbol.Name != "HandleRequest" {
+		t.Fatalf("focused symbol = %q, want HandleRequest", m.result.Symbol.Name)
+	}
+	if got := m.breadcrumb(); got != "Login > HandleRequest" {
+		t.Fatalf("breadcrumb = %q", got)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(traceUIModel)
+	if m.result.Symbol.Name != "Login" || m.selected != 1 {
+		t.Fatalf("collapse should restore previous view, got %q selected=%d", m.result.Symbol.Name, m.selected)
+	}
+}
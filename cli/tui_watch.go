@@ -78,6 +78,7 @@ type watchUISessionMsg struct {
 type watchUIHealthMsg struct {
 	totalEvents int
 	lastSuccess time.Time
+	throttled   string
 }
 
 type watchUIScopeMsg struct {
@@ -134,11 +135,14 @@ type watchUIModel struct {
 	chunksCreated int
 	filesRemoved  int
 	symbolCount   int
+	embedTokens   int64   // estimated tokens sent to a paid embedder
+	costUSD       float64 // estimated cost of embedTokens
 	snapshots     map[string]watchStatsDelta
 	snapshotDrift map[string]watchStatsDelta
 
 	totalEvents int
 	lastSuccess time.Time
+	throttled   string
 
 	totalProjects int
 	readyProjects int
@@ -299,6 +303,7 @@ func (m watchUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case watchUIHealthMsg:
 		m.totalEvents = msg.totalEvents
 		m.lastSuccess = msg.lastSuccess
+		m.throttled = msg.throttled
 
 	case watchUIActivityMsg:
 		activity := msg.state
@@ -371,6 +376,8 @@ func (m *watchUIModel) applyStatsDelta(delta watchStatsDelta) {
 	m.filesRemoved += delta.FilesRemoved
 	m.chunksCreated += delta.ChunksCreated - delta.ChunksRemoved
 	m.symbolCount += delta.SymbolsFound - delta.SymbolsLost
+	m.embedTokens += delta.EmbedTokens
+	m.costUSD += delta.CostUSD
 }
 
 func (m *watchUIModel) applyIncrementalStats(projectRoot string, delta watchStatsDelta) {
@@ -845,6 +852,9 @@ func (m watchUIModel) renderHealthPanel(width, height int) string {
 	}
 
 	state := m.theme.ok.Render("steady")
+	if m.throttled != "" {
+		state = m.theme.warn.Render("throttled (" + m.throttled + ")")
+	}
 	if m.stopping {
 		state = m.theme.warn.Render("stopping")
 	}
@@ -865,6 +875,9 @@ func (m watchUIModel) renderHealthPanel(width, height int) string {
 		m.theme.text.Render(fmt.Sprintf("Chunks created: %d", m.chunksCreated)),
 		m.theme.text.Render(fmt.Sprintf("Symbols: %d", m.symbolCount)),
 	}
+	if m.embedTokens > 0 {
+		lines = append(lines, m.theme.text.Render(fmt.Sprintf("Embed cost: ~$%.4f (%d tokens)", m.costUSD, m.embedTokens)))
+	}
 
 	if m.err != nil {
 		lines = append(lines, m.theme.danger.Render("Error: "+truncateRunes(m.err.Error(), width-8)))
@@ -920,7 +933,7 @@ func runWatchForegroundUI() error {
 	}()
 
 	model := newWatchUIModel(cancel)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	p := newTUIProgram(model)
 
 	workerErrCh := make(chan error, 1)
 	go func() {
@@ -1124,21 +1137,34 @@ func runWatchUIWorker(ctx context.Context, p *tea.Program) (err error) {
 	totalEvents := 0
 	var lastSuccess time.Time
 	var healthMu sync.Mutex
+	var throttle *watcher.Throttle
 	emitHealth := func() {
 		healthMu.Lock()
 		defer healthMu.Unlock()
 		p.Send(watchUIHealthMsg{
 			totalEvents: totalEvents,
 			lastSuccess: lastSuccess,
+			throttled:   throttle.Reason(),
 		})
 	}
 
+	throttle = startWatchPowerThrottle(watchCtx, cfg.Watch.PowerProfile, func(reason string) {
+		if reason != "" {
+			sendWatchUILedger(p, projectRoot, "warn", "Throttling background indexing: "+reason)
+		} else {
+			sendWatchUILedger(p, projectRoot, "info", "Resuming background indexing")
+		}
+		emitHealth()
+	})
+
 	return runDynamicWatchSupervisor(
 		watchCtx,
 		projectRoot,
 		emb,
 		withWatchSupervisorBackgroundChild(true),
 		withWatchSupervisorInitialLinkedWorktrees(initialLinked),
+		withWatchSupervisorNotifier(watcher.NewNotifier(cfg.Watch.Notifications)),
+		withWatchSupervisorThrottle(throttle),
 		withWatchSupervisorScopeObserver(func(totalProjects int) {
 			p.Send(watchUIScopeMsg{totalProjects: totalProjects})
 		}),
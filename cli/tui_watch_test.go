@@ -406,6 +406,23 @@ func TestWatchUIModelRenderProgressPanelDoesNotShowSkipped(t *testing.T) {
 	}
 }
 
+func TestWatchUIModelHealthPanelShowsThrottled(t *testing.T) {
+	m := newWatchUIModel(nil)
+	m.currentStep = len(m.phases) - 1
+
+	updated, _ := m.Update(watchUIHealthMsg{throttled: "on battery"})
+	m = updated.(watchUIModel)
+	if panel := m.renderHealthPanel(80, 12); !strings.Contains(panel, "throttled (on battery)") {
+		t.Fatalf("health panel should show throttled state: %q", panel)
+	}
+
+	updated, _ = m.Update(watchUIHealthMsg{})
+	m = updated.(watchUIModel)
+	if panel := m.renderHealthPanel(80, 12); !strings.Contains(panel, "steady") {
+		t.Fatalf("health panel should return to steady: %q", panel)
+	}
+}
+
 func TestRenderStatusSummaryIncludesWatcherInfo(t *testing.T) {
 	cfg := config.DefaultConfig()
 	stats := &store.IndexStats{
@@ -432,6 +449,26 @@ func TestRenderStatusSummaryIncludesWatcherInfo(t *testing.T) {
 	}
 }
 
+func TestRenderStatusSummaryIncludesBreakdown(t *testing.T) {
+	stats := &store.IndexStats{TotalFiles: 3, TotalChunks: 10}
+	out := renderStatusSummary(config.DefaultConfig(), stats, watcherRuntimeStatus{})
+	if strings.Contains(out, "By directory") {
+		t.Fatalf("summary should omit the breakdown unless requested: %q", out)
+	}
+
+	files := []store.FileStats{{Path: "cli/status.go", ChunkCount: 8}, {Path: "README.md", ChunkCount: 2}}
+	for i := 0; i < statusBreakdownRows+2; i++ {
+		files = append(files, store.FileStats{Path: fmt.Sprintf("pkg%d/a.go", i)})
+	}
+	stats.AddBreakdown(files)
+	out = renderStatusSummary(config.DefaultConfig(), stats, watcherRuntimeStatus{})
+	for _, want := range []string{"By directory:", "cli", "80.0%", "... and 4 more", "By language:", "markdown"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q: %q", want, out)
+		}
+	}
+}
+
 func TestWatchUILogLevel(t *testing.T) {
 	tests := []struct {
 		line string
@@ -468,3 +505,43 @@ func TestWatchUILogSourceResolver(t *testing.T) {
 		t.Fatalf("resolve(fallback) = %q, want %q", got, watchUILogSystem)
 	}
 }
+
+func TestRenderBudgetExclusions(t *testing.T) {
+	excluded := []config.BudgetExclusion{
+		{Path: "vendor/a.go", Chunks: 4, Reason: "vendor/ cap of 10 chunks"},
+		{Path: "testdata/big.json", Chunks: 40, Reason: "total cap of 100 chunks"},
+	}
+	out := renderBudgetExclusions(excluded)
+	if !strings.Contains(out, "Excluded by index budget: 2 files (44 chunks)") {
+		t.Fatalf("missing exclusion totals: %q", out)
+	}
+	if strings.Index(out, "testdata/big.json") > strings.Index(out, "vendor/a.go") {
+		t.Errorf("expected the largest exclusion first: %q", out)
+	}
+	if !strings.Contains(out, "vendor/ cap of 10 chunks") {
+		t.Errorf("missing exclusion reason: %q", out)
+	}
+}
+
+func TestWatchUIModelAccumulatesEmbeddingCost(t *testing.T) {
+	m := newWatchUIModel(nil)
+	root := "/tmp/main"
+
+	next, _ := m.Update(watchUIContextMsg{projectRoot: root})
+	m = next.(watchUIModel)
+	for _, delta := range []watchStatsDelta{
+		{EmbedTokens: 1000, CostUSD: 0.02},
+		{Snapshot: true, FilesIndexed: 10},
+		{FilesIndexed: 1, EmbedTokens: 500, CostUSD: 0.01},
+	} {
+		next, _ = m.Update(watchUIStatsMsg{projectRoot: root, delta: delta})
+		m = next.(watchUIModel)
+	}
+
+	if m.embedTokens != 1500 || m.costUSD < 0.0299 || m.costUSD > 0.0301 {
+		t.Fatalf("embedTokens=%d costUSD=%v, want 1500 and 0.03", m.embedTokens, m.costUSD)
+	}
+	if panel := m.renderHealthPanel(60, 20); !strings.Contains(panel, "Embed cost: ~$0.0300 (1500 tokens)") {
+		t.Errorf("health panel missing embedding cost:\n%s", panel)
+	}
+}
@@ -1,18 +1,25 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/daemon"
+	"github.com/yoanbernabeu/grepai/indexer"
 )
 
 type workspaceCreateStep int
 
+var workspaceBackendOptions = []string{"postgres", "qdrant", "weaviate", "redis", "opensearch"}
+
 const (
 	workspaceStepBackend workspaceCreateStep = iota
 	workspaceStepProvider
@@ -60,13 +67,13 @@ func (m workspaceCreateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "up", "k":
 			if m.step == workspaceStepBackend {
-				m.backendIdx = wrapIndex(m.backendIdx-1, 2)
+				m.backendIdx = wrapIndex(m.backendIdx-1, len(workspaceBackendOptions))
 			} else if m.step == workspaceStepProvider {
 				m.providerIdx = wrapIndex(m.providerIdx-1, 3)
 			}
 		case "down", "j":
 			if m.step == workspaceStepBackend {
-				m.backendIdx = wrapIndex(m.backendIdx+1, 2)
+				m.backendIdx = wrapIndex(m.backendIdx+1, len(workspaceBackendOptions))
 			} else if m.step == workspaceStepProvider {
 				m.providerIdx = wrapIndex(m.providerIdx+1, 3)
 			}
@@ -105,9 +112,8 @@ func (m workspaceCreateModel) View() string {
 func (m workspaceCreateModel) renderStep() string {
 	switch m.step {
 	case workspaceStepBackend:
-		options := []string{"postgres", "qdrant"}
 		lines := []string{m.theme.subtitle.Render("Select storage backend"), ""}
-		for i, opt := range options {
+		for i, opt := range workspaceBackendOptions {
 			prefix := "  "
 			style := m.theme.text
 			if i == m.backendIdx {
@@ -147,6 +153,12 @@ func (m workspaceCreateModel) renderStep() string {
 				m.theme.text.Render(fmt.Sprintf("Qdrant endpoint: %s", ws.Store.Qdrant.Endpoint)),
 				m.theme.text.Render(fmt.Sprintf("Qdrant port:     %d", ws.Store.Qdrant.Port)),
 			)
+		case "weaviate":
+			lines = append(lines, m.theme.text.Render(fmt.Sprintf("Weaviate endpoint: %s", ws.Store.Weaviate.Endpoint)))
+		case "redis":
+			lines = append(lines, m.theme.text.Render(fmt.Sprintf("Redis URL: %s", ws.Store.Redis.URL)))
+		case "opensearch":
+			lines = append(lines, m.theme.text.Render(fmt.Sprintf("OpenSearch endpoint: %s", ws.Store.OpenSearch.Endpoint)))
 		}
 		lines = append(lines, "", m.theme.info.Render("Press A (or Enter) to create workspace."))
 		return strings.Join(lines, "\n")
@@ -156,10 +168,7 @@ func (m workspaceCreateModel) renderStep() string {
 }
 
 func buildWorkspaceFromSelection(name string, backendIdx, providerIdx int) *config.Workspace {
-	backend := "postgres"
-	if backendIdx == 1 {
-		backend = "qdrant"
-	}
+	backend := workspaceBackendOptions[wrapIndex(backendIdx, len(workspaceBackendOptions))]
 
 	provider := "ollama"
 	switch providerIdx {
@@ -181,8 +190,7 @@ func buildWorkspaceFromSelection(name string, backendIdx, providerIdx int) *conf
 
 func createWorkspaceTUI(workspaceName string) (*config.Workspace, error) {
 	model := newWorkspaceCreateModel(workspaceName)
-	program := tea.NewProgram(model, tea.WithAltScreen())
-	finalModel, err := program.Run()
+	finalModel, err := runTUIProgram(model)
 	if err != nil {
 		return nil, err
 	}
@@ -196,6 +204,40 @@ func createWorkspaceTUI(workspaceName string) (*config.Workspace, error) {
 	return result.result, nil
 }
 
+// workspaceStatusRefreshInterval is how often the workspace status UI polls
+// watcher PIDs and project paths.
+const workspaceStatusRefreshInterval = 2 * time.Second
+
+type workspaceStatusTickMsg time.Time
+
+// workspaceStatusActionMsg reports the outcome of an action started from the
+// workspace status UI.
+type workspaceStatusActionMsg struct {
+	text string
+	err  error
+}
+
+// workspaceStatusActions are the operations the workspace status UI can run.
+type workspaceStatusActions struct {
+	start   func(logDir string, ws *config.Workspace) (int, error)
+	stop    func(logDir, workspaceName string) (int, error)
+	reindex func(ctx context.Context, ws *config.Workspace, project config.ProjectEntry) (*indexer.IndexStats, error)
+}
+
+func defaultWorkspaceStatusActions() workspaceStatusActions {
+	return workspaceStatusActions{
+		start: func(logDir string, ws *config.Workspace) (int, error) {
+			if err := config.ValidateWorkspaceBackend(ws); err != nil {
+				return 0, err
+			}
+			pid, _, err := spawnBackgroundWorkspaceWatch(logDir, ws)
+			return pid, err
+		},
+		stop:    stopWorkspaceWatcher,
+		reindex: reindexWorkspaceProject,
+	}
+}
+
 type workspaceStatusModel struct {
 	theme tuiTheme
 
@@ -205,13 +247,22 @@ type workspaceStatusModel struct {
 	workspaces []config.Workspace
 	entries    []string
 	selected   int
+	project    int
 	logDir     string
+
+	actions workspaceStatusActions
+	pids    map[string]int  // workspace name -> watcher PID
+	missing map[string]bool // project path -> path does not exist
+	busy    string          // description of the running action
+	message string
+	failed  bool
 }
 
 func newWorkspaceStatusModel(cfg *config.WorkspaceConfig, onlyName string) workspaceStatusModel {
 	model := workspaceStatusModel{
 		theme:    newTUITheme(),
 		selected: 0,
+		actions:  defaultWorkspaceStatusActions(),
 	}
 
 	for _, ws := range cfg.Workspaces {
@@ -227,16 +278,53 @@ func newWorkspaceStatusModel(cfg *config.WorkspaceConfig, onlyName string) works
 		model.logDir = logDir
 	}
 
+	model.refresh()
 	return model
 }
 
-func (m workspaceStatusModel) Init() tea.Cmd { return nil }
+func (m workspaceStatusModel) Init() tea.Cmd { return workspaceStatusTick() }
+
+func workspaceStatusTick() tea.Cmd {
+	return tea.Tick(workspaceStatusRefreshInterval, func(t time.Time) tea.Msg {
+		return workspaceStatusTickMsg(t)
+	})
+}
+
+// refresh polls the watcher PID of every workspace and whether each project
+// path still exists.
+func (m *workspaceStatusModel) refresh() {
+	m.pids = make(map[string]int, len(m.workspaces))
+	m.missing = make(map[string]bool)
+	for _, ws := range m.workspaces {
+		if m.logDir != "" {
+			if pid, _ := daemon.GetRunningWorkspacePID(m.logDir, ws.Name); pid > 0 {
+				m.pids[ws.Name] = pid
+			}
+		}
+		for _, p := range ws.Projects {
+			if _, err := os.Stat(p.Path); os.IsNotExist(err) {
+				m.missing[p.Path] = true
+			}
+		}
+	}
+}
 
 func (m workspaceStatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+	case workspaceStatusTickMsg:
+		m.refresh()
+		return m, workspaceStatusTick()
+	case workspaceStatusActionMsg:
+		m.busy = ""
+		m.failed = msg.err != nil
+		m.message = msg.text
+		if msg.err != nil {
+			m.message = msg.err.Error()
+		}
+		m.refresh()
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "q", "ctrl+c":
@@ -244,16 +332,88 @@ func (m workspaceStatusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "up", "k":
 			if m.selected > 0 {
 				m.selected--
+				m.project = 0
 			}
 		case "down", "j":
 			if m.selected < len(m.entries)-1 {
 				m.selected++
+				m.project = 0
+			}
+		case "left", "h":
+			if m.project > 0 {
+				m.project--
 			}
+		case "right", "l":
+			if len(m.workspaces) > 0 && m.project < len(m.workspaces[m.selected].Projects)-1 {
+				m.project++
+			}
+		case "s":
+			return m.toggleWatcher()
+		case "r":
+			return m.reindexProject()
 		}
 	}
 	return m, nil
 }
 
+// toggleWatcher starts the selected workspace's background watcher, or stops
+// it when it is running.
+func (m workspaceStatusModel) toggleWatcher() (tea.Model, tea.Cmd) {
+	if m.busy != "" || len(m.workspaces) == 0 || m.logDir == "" {
+		return m, nil
+	}
+	ws := m.workspaces[m.selected]
+	logDir := m.logDir
+	actions := m.actions
+	m.message = ""
+	if m.pids[ws.Name] > 0 {
+		m.busy = fmt.Sprintf("Stopping watcher for %s...", ws.Name)
+		return m, func() tea.Msg {
+			pid, err := actions.stop(logDir, ws.Name)
+			if err != nil {
+				return workspaceStatusActionMsg{err: fmt.Errorf("failed to stop watcher for %s: %w", ws.Name, err)}
+			}
+			return workspaceStatusActionMsg{text: fmt.Sprintf("Watcher for %s stopped (PID %d)", ws.Name, pid)}
+		}
+	}
+	m.busy = fmt.Sprintf("Starting watcher for %s...", ws.Name)
+	return m, func() tea.Msg {
+		pid, err := actions.start(logDir, &ws)
+		if err != nil {
+			return workspaceStatusActionMsg{err: fmt.Errorf("failed to start watcher for %s: %w", ws.Name, err)}
+		}
+		return workspaceStatusActionMsg{text: fmt.Sprintf("Watcher for %s started (PID %d)", ws.Name, pid)}
+	}
+}
+
+// reindexProject indexes the selected project into its workspace store.
+func (m workspaceStatusModel) reindexProject() (tea.Model, tea.Cmd) {
+	if m.busy != "" || len(m.workspaces) == 0 {
+		return m, nil
+	}
+	ws := m.workspaces[m.selected]
+	if m.project >= len(ws.Projects) {
+		return m, nil
+	}
+	project := ws.Projects[m.project]
+	if m.missing[project.Path] {
+		m.failed = true
+		m.message = fmt.Sprintf("Cannot reindex %s: %s does not exist", project.Name, project.Path)
+		return m, nil
+	}
+	actions := m.actions
+	m.message = ""
+	m.busy = fmt.Sprintf("Reindexing %s...", project.Name)
+	return m, func() tea.Msg {
+		stats, err := actions.reindex(context.Background(), &ws, project)
+		if err != nil {
+			return workspaceStatusActionMsg{err: err}
+		}
+		return workspaceStatusActionMsg{text: fmt.Sprintf("Reindexed %s: %d files indexed, %d chunks created, %d files removed",
+			project.Name, stats.FilesIndexed, stats.ChunksCreated, stats.FilesRemoved)}
+	}
+}
+
 func (m workspaceStatusModel) View() string {
 	if m.width == 0 {
 		return "Loading workspace status UI..."
@@ -273,11 +433,11 @@ func (m workspaceStatusModel) View() string {
 	if contentHeight < 6 {
 		contentHeight = 6
 	}
+	footer := m.theme.panel.Width(contentWidth).Render(m.theme.help.Render("up/down workspace | left/right project | s start/stop watcher | r reindex project | q quit"))
 	if contentWidth < 60 {
 		topH, bottomH := panelHeights(contentHeight)
 		listPanel := renderSelectableList(m.theme, "Workspaces", m.entries, m.selected, contentWidth, topH)
 		detailPanel := m.renderWorkspaceDetail(contentWidth, bottomH)
-		footer := m.theme.panel.Width(contentWidth).Render(m.theme.help.Render("up/down select | q quit"))
 		return lipgloss.JoinVertical(lipgloss.Left, listPanel, detailPanel, footer)
 	}
 
@@ -293,7 +453,6 @@ func (m workspaceStatusModel) View() string {
 
 	listPanel := renderSelectableList(m.theme, "Workspaces", m.entries, m.selected, leftW, contentHeight)
 	detailPanel := m.renderWorkspaceDetail(rightW, contentHeight)
-	footer := m.theme.panel.Width(contentWidth).Render(m.theme.help.Render("up/down select | q quit"))
 	return lipgloss.JoinVertical(lipgloss.Left, lipgloss.JoinHorizontal(lipgloss.Top, listPanel, detailPanel), footer)
 }
 
@@ -309,8 +468,7 @@ func (m workspaceStatusModel) renderWorkspaceDetail(width, height int) string {
 	}
 
 	if m.logDir != "" {
-		pid, _ := daemon.GetRunningWorkspacePID(m.logDir, ws.Name)
-		if pid > 0 {
+		if pid := m.pids[ws.Name]; pid > 0 {
 			lines = append(lines, m.theme.ok.Render(fmt.Sprintf("Watcher: running (PID %d)", pid)))
 			lines = append(lines, m.theme.text.Render(fmt.Sprintf("Log file: %s", daemon.GetWorkspaceLogFile(m.logDir, ws.Name))))
 		} else {
@@ -319,16 +477,31 @@ func (m workspaceStatusModel) renderWorkspaceDetail(width, height int) string {
 	}
 
 	lines = append(lines, "")
-	for _, p := range ws.Projects {
+	for i, p := range ws.Projects {
 		exists := "ok"
 		style := m.theme.ok
-		if _, err := os.Stat(p.Path); os.IsNotExist(err) {
+		if m.missing[p.Path] {
 			exists = "missing"
 			style = m.theme.danger
 		}
-		lines = append(lines, fmt.Sprintf("- %s", p.Name))
+		prefix := "  "
+		name := m.theme.text.Render(p.Name)
+		if i == m.project {
+			prefix = "> "
+			name = m.theme.highlight.Render(p.Name)
+		}
+		lines = append(lines, prefix+name)
 		lines = append(lines, fmt.Sprintf("  %s %s", style.Render(exists), m.theme.muted.Render(p.Path)))
 	}
+
+	switch {
+	case m.busy != "":
+		lines = append(lines, "", m.theme.info.Render(m.busy))
+	case m.message != "" && m.failed:
+		lines = append(lines, "", m.theme.danger.Render(m.message))
+	case m.message != "":
+		lines = append(lines, "", m.theme.ok.Render(m.message))
+	}
 	return m.theme.panel.Width(width).Height(height).Render(strings.Join(lines, "\n"))
 }
 
@@ -337,8 +510,20 @@ func runWorkspaceStatusUI(cfg *config.WorkspaceConfig, args []string) error {
 	if len(args) > 0 {
 		onlyName = args[0]
 	}
+	// Indexing logs would draw over the UI.
+	restoreLog := silenceLog()
+	defer restoreLog()
 	model := newWorkspaceStatusModel(cfg, onlyName)
-	program := tea.NewProgram(model, tea.WithAltScreen())
-	_, err := program.Run()
+	_, err := runTUIProgram(model)
 	return err
 }
+
+// silenceLog discards the standard logger's output until the returned
+// function is called.
+func silenceLog() func() {
+	oldWriter := log.Writer()
+	log.SetOutput(io.Discard)
+	return func() {
+		log.SetOutput(oldWriter)
+	}
+}
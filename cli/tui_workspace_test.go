@@ -1,6 +1,18 @@
 package cli
 
-import "testing"
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/daemon"
+	"github.com/yoanbernabeu/grepai/indexer"
+)
 
 func TestBuildWorkspaceFromSelectionMatchesFlagsBuilder(t *testing.T) {
 	ws := buildWorkspaceFromSelection("demo", 1, 0) // qdrant + ollama
@@ -20,3 +32,120 @@ func TestBuildWorkspaceFromSelectionMatchesFlagsBuilder(t *testing.T) {
 		t.Fatalf("qdrant port = %d, want %d", ws.Store.Qdrant.Port, ref.Store.Qdrant.Port)
 	}
 }
+
+func newTestWorkspaceStatusModel(t *testing.T, projectPath string) workspaceStatusModel {
+	t.Helper()
+	cfg := &config.WorkspaceConfig{Workspaces: map[string]config.Workspace{
+		"demo": {
+			Name:     "demo",
+			Store:    config.StoreConfig{Backend: "qdrant"},
+			Projects: []config.ProjectEntry{{Name: "api", Path: projectPath}, {Name: "web", Path: filepath.Join(projectPath, "missing")}},
+		},
+	}}
+	m := newWorkspaceStatusModel(cfg, "")
+	m.logDir = t.TempDir()
+	m.refresh()
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	return next.(workspaceStatusModel)
+}
+
+func updateWorkspaceStatus(m workspaceStatusModel, msg tea.Msg) (workspaceStatusModel, tea.Cmd) {
+	next, cmd := m.Update(msg)
+	return next.(workspaceStatusModel), cmd
+}
+
+func TestWorkspaceStatusModel_TickRefreshesWatcherAndPaths(t *testing.T) {
+	projectPath := t.TempDir()
+	m := newTestWorkspaceStatusModel(t, projectPath)
+
+	if !m.missing[filepath.Join(projectPath, "missing")] || m.missing[projectPath] {
+		t.Fatalf("missing = %v, want only the web project missing", m.missing)
+	}
+	if !strings.Contains(m.View(), "Watcher: not running") {
+		t.Fatal("view should show the watcher as not running")
+	}
+
+	if err := daemon.WriteWorkspacePIDFile(m.logDir, "demo"); err != nil {
+		t.Fatalf("failed to write PID file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(projectPath, "missing"), 0755); err != nil {
+		t.Fatalf("failed to create project dir: %v", err)
+	}
+	m, cmd := updateWorkspaceStatus(m, workspaceStatusTickMsg(time.Now()))
+	if cmd == nil {
+		t.Fatal("tick should schedule the next refresh")
+	}
+	if m.pids["demo"] != os.Getpid() {
+		t.Fatalf("pid = %d, want %d", m.pids["demo"], os.Getpid())
+	}
+	if len(m.missing) != 0 {
+		t.Fatalf("missing = %v, want none after the path was created", m.missing)
+	}
+	if !strings.Contains(m.View(), "Watcher: running") {
+		t.Fatal("view should show the watcher as running")
+	}
+}
+
+func TestWorkspaceStatusModel_ToggleWatcher(t *testing.T) {
+	m := newTestWorkspaceStatusModel(t, t.TempDir())
+	var started, stopped string
+	m.actions.start = func(logDir string, ws *config.Workspace) (int, error) {
+		started = ws.Name
+		return 42, nil
+	}
+	m.actions.stop = func(logDir, name string) (int, error) {
+		stopped = name
+		return 42, nil
+	}
+
+	m, cmd := updateWorkspaceStatus(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if cmd == nil || m.busy == "" {
+		t.Fatal("s should start the watcher in the background")
+	}
+	if _, again := updateWorkspaceStatus(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}); again != nil {
+		t.Fatal("actions must not run while another is in progress")
+	}
+	m, _ = updateWorkspaceStatus(m, cmd())
+	if started != "demo" || stopped != "" {
+		t.Fatalf("started = %q stopped = %q, want demo started", started, stopped)
+	}
+	if m.busy != "" || !strings.Contains(m.message, "started (PID 42)") {
+		t.Fatalf("busy = %q message = %q", m.busy, m.message)
+	}
+
+	m.pids["demo"] = 42
+	m, cmd = updateWorkspaceStatus(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m, _ = updateWorkspaceStatus(m, cmd())
+	if stopped != "demo" || !strings.Contains(m.message, "stopped") {
+		t.Fatalf("stopped = %q message = %q, want demo stopped", stopped, m.message)
+	}
+}
+
+func TestWorkspaceStatusModel_ReindexSelectedProject(t *testing.T) {
+	projectPath := t.TempDir()
+	m := newTestWorkspaceStatusModel(t, projectPath)
+	var reindexed string
+	m.actions.reindex = func(ctx context.Context, ws *config.Workspace, project config.ProjectEntry) (*indexer.IndexStats, error) {
+		reindexed = project.Name
+		return &indexer.IndexStats{FilesIndexed: 3, ChunksCreated: 7}, nil
+	}
+
+	m, cmd := updateWorkspaceStatus(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil {
+		t.Fatal("r should reindex the selected project")
+	}
+	m, _ = updateWorkspaceStatus(m, cmd())
+	if reindexed != "api" || !strings.Contains(m.message, "3 files indexed") {
+		t.Fatalf("reindexed = %q message = %q", reindexed, m.message)
+	}
+
+	// The second project's path does not exist.
+	m, _ = updateWorkspaceStatus(m, tea.KeyMsg{Type: tea.KeyRight})
+	m, cmd = updateWorkspaceStatus(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd != nil {
+		t.Fatal("a missing project must not be reindexed")
+	}
+	if !m.failed || !strings.Contains(m.message, "does not exist") {
+		t.Fatalf("failed = %v message = %q", m.failed, m.message)
+	}
+}
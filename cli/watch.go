@@ -7,7 +7,6 @@ import (
 	"io"
 	"log"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"sort"
@@ -23,6 +22,8 @@ import (
 	"github.com/yoanbernabeu/grepai/framework"
 	"github.com/yoanbernabeu/grepai/git"
 	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
 	"github.com/yoanbernabeu/grepai/rpg"
 	"github.com/yoanbernabeu/grepai/store"
 	"github.com/yoanbernabeu/grepai/trace"
@@ -31,12 +32,15 @@ import (
 )
 
 var (
-	watchBackground bool
-	watchLogDir     string
-	watchStatus     bool
-	watchStop       bool
-	watchWorkspace  string
-	watchNoUI       bool
+	watchBackground  bool
+	watchLogDir      string
+	watchStatus      bool
+	watchStop        bool
+	watchWorkspace   string
+	watchNoUI        bool
+	watchProgress    string
+	watchAll         bool
+	watchAllMaxDepth int
 )
 
 var (
@@ -73,6 +77,13 @@ Background mode:
   grepai watch --status                  Check if background watcher is running
   grepai watch --stop                    Stop the background watcher
 
+All projects:
+  grepai watch --all                     Watch every workspace and every project under the
+                                         current directory in a single process
+  grepai watch --all --background        Run that process in background
+  grepai watch --all --status            Show the status of each of them
+  grepai watch --all --stop              Stop all of them
+
 Default log directories:
   Linux:   ~/.local/state/grepai/logs/grepai-watch.log (or $XDG_STATE_HOME)
   macOS:   ~/Library/Logs/grepai/grepai-watch.log
@@ -93,6 +104,11 @@ func init() {
 	watchCmd.Flags().BoolVar(&watchStop, "stop", false, "Stop the background watcher")
 	watchCmd.Flags().StringVar(&watchWorkspace, "workspace", "", "Workspace name for multi-project mode")
 	watchCmd.Flags().BoolVar(&watchNoUI, "no-ui", false, "Disable interactive UI in foreground mode")
+	watchCmd.Flags().BoolVar(&watchAll, "all", false, "Watch, check (--status) or stop (--stop) all workspaces and the projects under the current directory")
+	watchCmd.Flags().IntVar(&watchAllMaxDepth, "max-depth", 4, "Directory levels below the current directory searched for projects with --all")
+	watchCmd.Flags().StringVar(&watchProgress, "progress", watchProgressAuto, "Initial scan progress: auto (bar on a terminal, plain otherwise), plain or none")
+	addProfileFlags(watchCmd)
+	watchCmd.Flags().StringVar(&profiling.listen, "pprof-listen", "", "Serve net/http/pprof on this address, e.g. localhost:6060")
 }
 
 func runWatch(cmd *cobra.Command, args []string) error {
@@ -110,6 +126,9 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	if activeFlags > 1 {
 		return fmt.Errorf("flags --background, --status, and --stop are mutually exclusive")
 	}
+	if err := validateWatchProgressMode(watchProgress); err != nil {
+		return err
+	}
 
 	// Determine log directory
 	logDir := watchLogDir
@@ -121,6 +140,17 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// All workspaces and projects
+	if watchAll {
+		if watchWorkspace != "" {
+			return fmt.Errorf("--all cannot be combined with --workspace")
+		}
+		if profiling.set() {
+			return fmt.Errorf("profiling flags cannot be combined with --all; set %s instead", pprofEnv)
+		}
+		return runWatchAll(logDir)
+	}
+
 	// Workspace mode
 	if watchWorkspace != "" {
 		return runWorkspaceWatch(logDir)
@@ -195,9 +225,15 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("watcher is already running in background (PID %d)\nUse 'grepai watch --stop' to stop it", pid)
 	}
 
+	stopProfiling, err := startProfiling("watch")
+	if err != nil {
+		return err
+	}
+	defer stopProfiling()
+
 	if watchUseUISelector(
 		watchIsInteractiveTerminal(),
-		watchNoUI,
+		watchNoUI || plainUIEnabled(),
 		watchBackground,
 		watchStatus,
 		watchStop,
@@ -362,6 +398,13 @@ func startBackgroundWatch(logDir, worktreeID string) error {
 	if watchLogDir != "" {
 		args = append(args, "--log-dir", watchLogDir)
 	}
+	if watchProgress == watchProgressNone {
+		args = append(args, "--progress", watchProgressNone)
+	}
+	if watchQuickStart {
+		args = append(args, "--quick-start")
+	}
+	args = append(args, profiling.args()...)
 
 	// Spawn background process
 	var childPID int
@@ -444,26 +487,57 @@ func initializeEmbedder(ctx context.Context, cfg *config.Config) (embedder.Embed
 	return emb, nil
 }
 
-func initializeStore(ctx context.Context, cfg *config.Config, projectRoot string) (store.VectorStore, error) {
-	switch cfg.Store.Backend {
-	case "gob":
-		indexPath := config.GetIndexPath(projectRoot)
-		gobStore := store.NewGOBStore(indexPath)
-		if err := gobStore.Load(ctx); err != nil {
-			return nil, fmt.Errorf("failed to load index: %w", err)
-		}
-		return gobStore, nil
-	case "postgres":
-		return store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
-	case "qdrant":
-		collectionName := cfg.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = store.SanitizeCollectionName(projectRoot)
+// initializeDocsEmbedder creates and checks the docs embedder of cfg. It
+// returns nil when documentation is embedded with the main embedder.
+func initializeDocsEmbedder(ctx context.Context, cfg *config.Config) (embedder.Embedder, error) {
+	if cfg.Embedder.Docs == nil {
+		return nil, nil
+	}
+	emb, err := initializeEmbedder(ctx, &config.Config{Embedder: *cfg.Embedder.Docs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize docs embedder: %w", err)
+	}
+	return emb, nil
+}
+
+// stampIndexMetadata records emb as the embedder of the index held by st,
+// whose metadata file is at path, when the index is empty. An index built
+// with another embedder is refused unless it is empty, as it is once
+// deleted to be rebuilt with the new embedder. An index built before
+// metadata was recorded is left unstamped with a warning: the embedder
+// that built it is unknown.
+func stampIndexMetadata(ctx context.Context, st store.VectorStore, path string, emb config.EmbedderConfig) error {
+	meta, err := config.LoadIndexMetadata(path)
+	if err != nil {
+		return err
+	}
+	current := emb.IndexMetadata()
+	if meta != nil && meta.Matches(current) {
+		return nil
+	}
+	files, err := st.ListDocuments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list indexed files: %w", err)
+	}
+	if len(files) > 0 {
+		if meta == nil {
+			log.Printf("Warning: the index does not record the embedder that built it (%s is missing), so a change of embedder cannot be detected; rebuild the index to record %s", path, current)
+			return nil
 		}
-		return store.NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
-	default:
-		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
+		return meta.Verify(current)
+	}
+	current.CreatedAt = time.Now().UTC()
+	return config.SaveIndexMetadata(path, current)
+}
+
+// stampProjectIndexMetadata stamps the index of the project at projectRoot
+// with the embedder of cfg.
+func stampProjectIndexMetadata(ctx context.Context, st store.VectorStore, projectRoot string, cfg *config.Config) error {
+	if err := stampIndexMetadata(ctx, st, config.GetIndexMetadataPath(projectRoot), cfg.Embedder); !errors.Is(err, config.ErrEmbedderMismatch) {
+		return err
 	}
+	// Refused: report it with the rebuild instructions.
+	return config.CheckProjectIndexMetadata(projectRoot, cfg)
 }
 
 const configWriteThrottle = 30 * time.Second
@@ -597,7 +671,85 @@ func (m *rpgRealtimeManager) Snapshot() (dirtyFiles int, dirtyPersist bool, last
 	return len(m.dirtyFiles), m.dirtyPersist, m.lastDerivedRun, m.lastPersistRun
 }
 
-func startRPGRealtimeWorkers(ctx context.Context, projectLabel string, symbolStore trace.SymbolStore, rpgEncoder *rpg.RPGEncoder, rpgStore rpg.RPGStore, watchCfg config.WatchConfig, manager *rpgRealtimeManager) {
+// TakePendingRebuild reports whether files changed since the last call and
+// clears the dirty state. Used by the scheduled rebuild policy.
+func (m *rpgRealtimeManager) TakePendingRebuild() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending := m.dirtyDerived || m.forceFull
+	m.dirtyDerived = false
+	m.forceFull = false
+	m.dirtyFiles = make(map[string]struct{})
+	return pending
+}
+
+// startRPGMaintenance starts the background RPG upkeep for the rpg.rebuild
+// policy. It returns the manager file events mark dirty files on, and the
+// encoder they apply incremental updates with, which is nil under the
+// scheduled policy where changes wait for the next full rebuild.
+func startRPGMaintenance(ctx context.Context, projectLabel string, symbolStore trace.SymbolStore, vectorStore store.VectorStore, rpgEncoder *rpg.RPGEncoder, rpgStore rpg.RPGStore, cfg *config.Config) (*rpgRealtimeManager, *rpg.RPGEncoder) {
+	if rpgEncoder == nil || rpgStore == nil {
+		return nil, nil
+	}
+
+	manager := newRPGRealtimeManager(cfg.Watch.RPGMaxDirtyFilesPerBatch)
+	window, _ := config.ParseTimeWindow(cfg.Watch.MaintenanceWindow)
+	if cfg.RPG.Rebuild == config.RPGRebuildScheduled {
+		interval := time.Duration(cfg.RPG.RebuildIntervalMin) * time.Minute
+		startRPGScheduledRebuilds(ctx, projectLabel, symbolStore, vectorStore, rpgEncoder, interval, window, manager)
+		return manager, nil
+	}
+	startRPGRealtimeWorkers(ctx, projectLabel, symbolStore, rpgEncoder, rpgStore, cfg.Watch, window, manager)
+	return manager, rpgEncoder
+}
+
+// startRPGScheduledRebuilds rebuilds the whole RPG graph every interval when
+// files changed since the previous build. Outside the maintenance window,
+// rebuilds stay pending until it opens.
+func startRPGScheduledRebuilds(ctx context.Context, projectLabel string, symbolStore trace.SymbolStore, vectorStore store.VectorStore, rpgEncoder *rpg.RPGEncoder, interval time.Duration, window *config.TimeWindow, manager *rpgRealtimeManager) {
+	if interval <= 0 {
+		interval = time.Duration(config.DefaultRPGRebuildIntervalMin) * time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				if !window.Contains(time.Now()) || !manager.TakePendingRebuild() {
+					continue
+				}
+
+				start := time.Now()
+				if err := rpgEncoder.BuildFull(ctx, symbolStore, vectorStore, nil); err != nil {
+					// Keep the rebuild pending so the next tick retries it.
+					manager.ScheduleFullReconcile()
+					log.Printf("Warning: rpg_rebuild_ms=%d project=%s mode=scheduled err=%v", time.Since(start).Milliseconds(), projectLabel, err)
+					continue
+				}
+				manager.MarkDerivedSuccess()
+				manager.MarkPersisted()
+				rpgStats := rpgEncoder.Stats()
+				log.Printf("rpg_rebuild_ms=%d project=%s mode=scheduled nodes=%d edges=%d",
+					time.Since(start).Milliseconds(),
+					projectLabel,
+					rpgStats.TotalNodes,
+					rpgStats.TotalEdges,
+				)
+			}
+		}
+	}()
+}
+
+// startRPGRealtimeWorkers applies incremental graph updates as files change.
+// Periodic full reconciles only run inside the maintenance window.
+func startRPGRealtimeWorkers(ctx context.Context, projectLabel string, symbolStore trace.SymbolStore, rpgEncoder *rpg.RPGEncoder, rpgStore rpg.RPGStore, watchCfg config.WatchConfig, window *config.TimeWindow, manager *rpgRealtimeManager) {
 	if manager == nil || rpgEncoder == nil || rpgStore == nil || symbolStore == nil {
 		return
 	}
@@ -616,6 +768,9 @@ func startRPGRealtimeWorkers(ctx context.Context, projectLabel string, symbolSto
 				return
 
 			case <-reconcileTicker.C:
+				if !window.Contains(time.Now()) {
+					continue
+				}
 				manager.ScheduleFullReconcile()
 				log.Printf("rpg_full_reconcile_triggered=true project=%s reason=periodic", projectLabel)
 
@@ -742,12 +897,12 @@ func runWatchLoop(ctx context.Context, st store.VectorStore, symbolStore *trace.
 			}
 
 		case event := <-w.Events():
-			handleFileEvent(ctx, idx, scanner, extractor, symbolStore, nil, nil, tracedLanguages, projectRoot, cfg, &lastConfigWrite, nil, event, nil, nil, processors...)
+			handleFileEvent(ctx, idx, scanner, extractor, symbolStore, nil, nil, tracedLanguages, projectRoot, cfg, &lastConfigWrite, nil, nil, event, nil, nil, processors...)
 		}
 	}
 }
 
-func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore *trace.GOBSymbolStore, tracedLanguages []string, lastIndexTime time.Time, isBackgroundChild bool, onScan func(current, total int, file string), onEmbed func(info indexer.BatchProgressInfo), processors ...*framework.ProcessorRegistry) (*indexer.IndexStats, error) {
+func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore *trace.GOBSymbolStore, tracedLanguages []string, lastIndexTime time.Time, isBackgroundChild bool, onScan func(current, total int, file string), onEmbed func(info indexer.BatchProgressInfo), events *scanEventSource, throttle *watcher.Throttle, processors ...*framework.ProcessorRegistry) (*indexer.IndexStats, error) {
 	// Initial scan with progress
 	if !isBackgroundChild {
 		fmt.Println("\nPerforming initial scan...")
@@ -755,39 +910,12 @@ func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.
 		log.Println("Performing initial scan...")
 	}
 
-	var stats *indexer.IndexStats
-	var err error
-	if !isBackgroundChild {
-		stats, err = idx.IndexAllWithBatchProgress(ctx,
-			func(info indexer.ProgressInfo) {
-				if onScan != nil {
-					onScan(info.Current, info.Total, info.CurrentFile)
-				} else {
-					printProgress(info.Current, info.Total, info.CurrentFile)
-				}
-			},
-			func(info indexer.BatchProgressInfo) {
-				if onEmbed != nil {
-					onEmbed(info)
-				} else {
-					printBatchProgress(info)
-				}
-			},
-		)
-		watchProgressOutput.clear()
-		fmt.Println()
-	} else {
-		stats, err = idx.IndexAllWithBatchProgress(ctx, func(info indexer.ProgressInfo) {
-			if onScan != nil {
-				onScan(info.Current, info.Total, info.CurrentFile)
-			}
-		}, func(info indexer.BatchProgressInfo) {
-			if onEmbed != nil {
-				onEmbed(info)
-			}
-		})
-	}
-
+	stats, err := runIndexWithProgress(isBackgroundChild, onScan, onEmbed, func(onProgress indexer.ProgressCallback, onBatchProgress indexer.BatchProgressCallback) (*indexer.IndexStats, error) {
+		if events != nil {
+			return indexAllWithEventPriority(ctx, idx, scanner, events, throttle, onProgress, onBatchProgress)
+		}
+		return idx.IndexAllWithBatchProgress(ctx, onProgress, onBatchProgress)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("initial indexing failed: %w", err)
 	}
@@ -810,8 +938,9 @@ func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.
 	files := stats.ScannedFiles
 
 	for _, file := range files {
+		// Extensionless files are read to detect their language.
 		ext := strings.ToLower(filepath.Ext(file.Path))
-		if !isTracedLanguage(ext, tracedLanguages) {
+		if ext != "" && langdetect.NameExt(file.Path) == "" && !isTracedLanguage(ext, tracedLanguages) {
 			continue
 		}
 
@@ -828,7 +957,7 @@ func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.
 			log.Printf("Warning: failed to scan %s for symbols: %v", file.Path, err)
 			continue
 		}
-		if fileInfo == nil {
+		if fileInfo == nil || !isTracedLanguage(langdetect.Ext(fileInfo.Path, fileInfo.Content), tracedLanguages) {
 			continue
 		}
 
@@ -861,9 +990,8 @@ func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.
 
 // discoverWorktreesForWatch discovers linked worktrees and auto-initializes them.
 // Only discovers from the main worktree; returns nil for linked worktrees.
+// With watch.include_submodules, submodule checkouts are included too.
 func discoverWorktreesForWatch(projectRoot string) []string {
-	projectRootCanonical := canonicalPath(projectRoot)
-
 	gitInfo, err := git.Detect(projectRoot)
 	if err != nil {
 		return nil
@@ -874,49 +1002,91 @@ func discoverWorktreesForWatch(projectRoot string) []string {
 		return nil
 	}
 
-	// Run git worktree list
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// On error there are no linked worktrees to watch, but submodules may
+	// still be.
+	linked, _ := listLinkedWorktrees(projectRoot)
+
+	worktrees := make([]string, 0, len(linked))
+	for _, wtPathCanonical := range linked {
+		// Auto-init .grepai/ if needed (FindProjectRoot does this when called
+		// from within the worktree, but we're not in it, so init manually)
+		localGrepai := filepath.Join(wtPathCanonical, ".grepai")
+		if _, statErr := os.Stat(localGrepai); os.IsNotExist(statErr) {
+			// Auto-init from main
+			if initErr := config.AutoInitWorktree(wtPathCanonical, canonicalPath(projectRoot)); initErr != nil {
+				log.Printf("Warning: failed to auto-init worktree %s: %v", wtPathCanonical, initErr)
+				continue
+			}
+			log.Printf("Auto-initialized worktree: %s", wtPathCanonical)
+		}
+		worktrees = append(worktrees, wtPathCanonical)
+	}
+	return append(worktrees, discoverSubmodulesForWatch(projectRoot)...)
+}
 
-	cmd := exec.CommandContext(ctx, "git", "-C", projectRoot, "worktree", "list", "--porcelain")
-	output, err := cmd.Output()
+// discoverSubmodulesForWatch returns the initialized submodule checkouts of
+// projectRoot when its watch.include_submodules is set, auto-initializing
+// each with the project's configuration.
+func discoverSubmodulesForWatch(projectRoot string) []string {
+	cfg, err := config.Load(projectRoot)
+	if err != nil || !cfg.Watch.IncludeSubmodules {
+		return nil
+	}
+	paths, err := git.ListSubmodules(projectRoot)
 	if err != nil {
 		return nil
 	}
 
-	var worktrees []string
-	seen := make(map[string]bool)
-	for _, line := range strings.Split(string(output), "\n") {
-		if strings.HasPrefix(line, "worktree ") {
-			wtPath := strings.TrimPrefix(line, "worktree ")
-			wtPathCanonical := canonicalPath(wtPath)
-			// Skip the main worktree itself
-			if wtPathCanonical == projectRootCanonical {
-				continue
-			}
-			// Guard against duplicated aliases pointing to the same path.
-			if seen[wtPathCanonical] {
+	submodules := make([]string, 0, len(paths))
+	for _, path := range paths {
+		subRoot := canonicalPath(path)
+		if !config.Exists(subRoot) {
+			if initErr := config.AutoInitSubmodule(subRoot, projectRoot); initErr != nil {
+				log.Printf("Warning: failed to auto-init submodule %s: %v", subRoot, initErr)
 				continue
 			}
-			seen[wtPathCanonical] = true
-			// Auto-init .grepai/ if needed (FindProjectRoot does this when called
-			// from within the worktree, but we're not in it, so init manually)
-			localGrepai := filepath.Join(wtPathCanonical, ".grepai")
-			if _, statErr := os.Stat(localGrepai); os.IsNotExist(statErr) {
-				// Auto-init from main
-				if initErr := config.AutoInitWorktree(wtPathCanonical, projectRootCanonical); initErr != nil {
-					log.Printf("Warning: failed to auto-init worktree %s: %v", wtPathCanonical, initErr)
-					continue
-				}
-				log.Printf("Auto-initialized worktree: %s", wtPathCanonical)
-			}
-			worktrees = append(worktrees, wtPathCanonical)
+			log.Printf("Auto-initialized submodule: %s", subRoot)
+		}
+		submodules = append(submodules, subRoot)
+	}
+	return submodules
+}
+
+// listLinkedWorktrees returns the canonical paths of the linked worktrees
+// of the repository whose main worktree is projectRoot. The bare repository
+// of a bare clone is not a worktree and is skipped.
+func listLinkedWorktrees(projectRoot string) ([]string, error) {
+	projectRootCanonical := canonicalPath(projectRoot)
+
+	entries, err := git.ListWorktrees(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Bare {
+			continue
+		}
+		wtPathCanonical := canonicalPath(entry.Path)
+		// Skip the main worktree itself
+		if sameProjectPath(wtPathCanonical, projectRootCanonical) {
+			continue
+		}
+		// Guard against duplicated aliases pointing to the same path.
+		wtKey := fileutil.PathKey(wtPathCanonical)
+		if seen[wtKey] {
+			continue
 		}
+		seen[wtKey] = true
+		worktrees = append(worktrees, wtPathCanonical)
 	}
-	return worktrees
+	return worktrees, nil
 }
 
 func canonicalPath(path string) string {
+	path = fileutil.StripLongPathPrefix(path)
 	if resolved, err := filepath.EvalSymlinks(path); err == nil {
 		path = resolved
 	}
@@ -926,6 +1096,18 @@ func canonicalPath(path string) string {
 	return filepath.Clean(path)
 }
 
+// projectPathKey returns the key used to compare and deduplicate project
+// roots. It folds case on Windows so differently cased spellings of the same
+// worktree map to one watch session.
+func projectPathKey(path string) string {
+	return fileutil.PathKey(canonicalPath(path))
+}
+
+// sameProjectPath reports whether a and b refer to the same project root.
+func sameProjectPath(a, b string) bool {
+	return projectPathKey(a) == projectPathKey(b)
+}
+
 func buildFrameworkRegistry(cfg *config.Config) *framework.ProcessorRegistry {
 	regCfg := framework.RegistryConfig{
 		Enabled:      cfg.Framework.Enabled,
@@ -946,6 +1128,98 @@ func buildFrameworkRegistry(cfg *config.Config) *framework.ProcessorRegistry {
 	)
 }
 
+func buildFileHandlers(cfg *config.Config) *indexer.FileHandlers {
+	var handlers []indexer.FileHandler
+	if cfg.Indexer.FileHandlers.Notebooks.Enabled {
+		handlers = append(handlers, &indexer.NotebookHandler{})
+	}
+	if cfg.Indexer.FileHandlers.Schemas.Enabled {
+		handlers = append(handlers, &indexer.SchemaHandler{})
+	}
+	if cfg.Indexer.FileHandlers.Minified.Enabled {
+		handlers = append(handlers, &indexer.MinifiedHandler{})
+	}
+	if cfg.Indexer.FileHandlers.Documents.Enabled {
+		handlers = append(handlers, &indexer.DocumentHandler{Paths: cfg.Indexer.FileHandlers.Documents.Paths})
+	}
+	return indexer.NewFileHandlers(handlers...)
+}
+
+// buildIndexBudget returns the indexer budget of cfg. Files are ranked by
+// the indexer.budget.priorities of their directories.
+func buildIndexBudget(cfg *config.Config) indexer.Budget {
+	budget := indexer.Budget{MaxChunks: cfg.Indexer.Budget.MaxChunks}
+	if len(cfg.Indexer.Budget.Paths) > 0 {
+		budget.DirCaps = make(map[string]int, len(cfg.Indexer.Budget.Paths))
+		for _, p := range cfg.Indexer.Budget.Paths {
+			budget.DirCaps[strings.Trim(filepath.ToSlash(p.Path), "/")] = p.MaxChunks
+		}
+	}
+	if len(cfg.Indexer.Budget.Priorities) > 0 {
+		budget.Priority = cfg.Indexer.Budget.Priority
+	}
+	return budget
+}
+
+// symbolScanIndexTime returns the last index time the initial scan's symbol
+// pass compares modification times with. Only the mtime+hash change
+// detection mode trusts it; the others check the hash of every file.
+func symbolScanIndexTime(cfg *config.Config) time.Time {
+	if mode := cfg.Indexer.ChangeDetection; mode != "" && mode != config.ChangeDetectionMtimeHash {
+		return time.Time{}
+	}
+	return cfg.Watch.LastIndexTime
+}
+
+// saveBudgetExclusions records the files a full pass left out to stay
+// within the budget, for 'grepai status'.
+func saveBudgetExclusions(projectRoot string, stats *indexer.IndexStats) {
+	excluded := make([]config.BudgetExclusion, len(stats.Excluded))
+	for i, e := range stats.Excluded {
+		excluded[i] = config.BudgetExclusion{Path: e.Path, Chunks: e.Chunks, Reason: e.Reason}
+	}
+	if err := config.SaveBudgetExclusions(projectRoot, excluded); err != nil {
+		log.Printf("Warning: failed to save budget exclusions: %v", err)
+	}
+	if len(excluded) > 0 {
+		log.Printf("Index budget: %d files left out of %s", len(excluded), projectRoot)
+	}
+}
+
+// buildContentRules returns the scanner content rules of cfg.
+func buildContentRules(cfg *config.Config) indexer.ContentRules {
+	filters := cfg.Indexer.ContentFilters
+	rules := indexer.ContentRules{
+		Binary:    filters.Binary.Enabled,
+		Generated: filters.Generated.Enabled,
+	}
+	if len(filters.TextExtensions) > 0 {
+		rules.TextExtensions = make(map[string]bool, len(filters.TextExtensions))
+		for _, ext := range filters.TextExtensions {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			rules.TextExtensions[ext] = true
+		}
+	}
+	return rules
+}
+
+// loadSparseCheckout returns the sparse-checkout of projectRoot the scanner
+// honors, or nil when it is disabled or indexer.ignore_sparse_checkout is set.
+func loadSparseCheckout(projectRoot string, cfg *config.Config) *git.SparseCheckout {
+	if cfg.Indexer.IgnoreSparseCheckout {
+		return nil
+	}
+	sparse, err := git.LoadSparseCheckout(projectRoot)
+	if err != nil {
+		log.Printf("Warning: failed to read sparse-checkout patterns: %v", err)
+		return nil
+	}
+	return sparse
+}
+
 // watchProject runs the full watch lifecycle for a single project.
 // The embedder is shared across all projects to avoid duplicate connections.
 // If onReady is non-nil, it is called once after initial indexing and watcher start.
@@ -960,11 +1234,26 @@ const (
 	maxSessionRetryBackoff    = 30 * time.Second
 )
 
+// watchOptions are the observers and throttle of a watch session. Unset
+// fields are ignored.
+type watchOptions struct {
+	onEvent    watchEventObserver
+	onScan     func(current, total int, file string)
+	onEmbed    func(info indexer.BatchProgressInfo)
+	onRPG      func(step string, current, total int)
+	onActivity watchActivityObserver
+	onStats    watchStatsObserver
+	onStores   watchStoresObserver
+	throttle   *watcher.Throttle
+}
+
 func watchProject(ctx context.Context, projectRoot string, emb embedder.Embedder, isBackgroundChild bool, onReady func()) error {
-	return watchProjectWithEventObserver(ctx, projectRoot, emb, isBackgroundChild, onReady, nil, nil, nil, nil, nil, nil)
+	return watchProjectWithOptions(ctx, projectRoot, emb, isBackgroundChild, onReady, watchOptions{})
 }
 
-func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb embedder.Embedder, isBackgroundChild bool, onReady func(), onEvent watchEventObserver, onScan func(current, total int, file string), onEmbed func(info indexer.BatchProgressInfo), onRPG func(step string, current, total int), onActivity watchActivityObserver, onStats watchStatsObserver) error {
+// watchProjectWithOptions is watchProject reporting to the observers of
+// opts and throttled by its throttle.
+func watchProjectWithOptions(ctx context.Context, projectRoot string, emb embedder.Embedder, isBackgroundChild bool, onReady func(), opts watchOptions) error {
 	// Load configuration
 	cfg, err := config.Load(projectRoot)
 	if err != nil {
@@ -974,11 +1263,15 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 	log.Printf("Watching project: %s (backend: %s)", projectRoot, cfg.Store.Backend)
 
 	// Initialize store
-	st, err := initializeStore(ctx, cfg, projectRoot)
+	rawStore, err := store.NewFromConfig(ctx, cfg, projectRoot)
 	if err != nil {
 		return err
 	}
-	defer st.Close()
+	defer rawStore.Close()
+	if err := stampProjectIndexMetadata(ctx, rawStore, projectRoot, cfg); err != nil {
+		return err
+	}
+	st := newWatchSessionStore(rawStore, projectRoot)
 
 	// Initialize ignore matcher
 	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Ignore, cfg.ExternalGitignore)
@@ -988,13 +1281,34 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 
 	// Initialize scanner
 	scanner := indexer.NewScanner(projectRoot, ignoreMatcher)
+	scanner.SetFollowSymlinks(cfg.Indexer.FollowSymlinks)
+	scanner.SetFileHandlers(buildFileHandlers(cfg))
+	scanner.SetContentRules(buildContentRules(cfg))
+	scanner.SetSparseCheckout(loadSparseCheckout(projectRoot, cfg))
 
 	// Initialize chunker
 	chunker := indexer.NewChunker(cfg.Chunking.Size, cfg.Chunking.Overlap)
 	processorRegistry := buildFrameworkRegistry(cfg)
 
-	// Initialize indexer
-	idx := indexer.NewIndexer(projectRoot, st, emb, chunker, scanner, cfg.Watch.LastIndexTime, processorRegistry)
+	// Initialize indexer. Tokens sent to a paid embedder are counted for
+	// the cost report, and the notifier watches the embedder for outages.
+	docsEmb, err := initializeDocsEmbedder(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if docsEmb != nil {
+		defer docsEmb.Close()
+	}
+	notifier := watcher.NewNotifier(cfg.Watch.Notifications)
+	cost := newCostTracker(projectRoot, cfg)
+	idx := cost.newIndexer(projectRoot, cfg, st, notifier.WrapEmbedder(projectRoot, emb), docsEmb, chunker, scanner, cfg.Watch.LastIndexTime, processorRegistry)
+	idx.SetContextualizer(newContextualizerFromConfig(cfg, projectRoot))
+	idx.SetBudget(buildIndexBudget(cfg))
+	idx.SetChangeDetection(cfg.Indexer.ChangeDetection)
+	idx.SetTodoStore(loadTodoStore(ctx, projectRoot))
+	if opts.throttle != nil {
+		idx.SetSerialEmbedding(opts.throttle.Throttled)
+	}
 
 	// Initialize symbol store and extractor
 	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
@@ -1003,76 +1317,149 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 	}
 	defer symbolStore.Close()
 
+	// Searches served alongside the session fail fast instead of waiting
+	// for a reconnection.
+	if opts.onStores != nil {
+		opts.onStores(projectRoot, rawStore, symbolStore)
+		defer opts.onStores(projectRoot, nil, nil)
+	}
+
 	extractor := trace.NewRegexExtractor()
 
-	// Initialize RPG if enabled.
+	// Initialize RPG if enabled. Under the manual rebuild policy the watcher
+	// leaves the graph to 'grepai rpg rebuild'.
 	var rpgEncoder *rpg.RPGEncoder
 	var rpgStore rpg.RPGStore
-	if cfg.RPG.Enabled {
+	if cfg.RPG.Enabled && cfg.RPG.Rebuild == config.RPGRebuildManual {
+		log.Printf("RPG rebuild policy is manual for %s; run 'grepai rpg rebuild' to update the graph", projectRoot)
+	} else if cfg.RPG.Enabled {
 		rpgStore = rpg.NewGOBRPGStore(config.GetRPGIndexPath(projectRoot))
 		if err := rpgStore.Load(ctx); err != nil {
 			log.Printf("Warning: failed to load RPG index for %s: %v", projectRoot, err)
 		}
-
-		var featureExtractor rpg.FeatureExtractor
-		switch cfg.RPG.FeatureMode {
-		case "llm", "hybrid":
-			if cfg.RPG.LLMEndpoint == "" || cfg.RPG.LLMModel == "" {
-				log.Printf("Warning: RPG feature_mode=%q but llm_endpoint or llm_model is empty, falling back to local extractor", cfg.RPG.FeatureMode)
-				featureExtractor = rpg.NewLocalExtractor()
-			} else {
-				featureExtractor = rpg.NewLLMExtractor(rpg.LLMExtractorConfig{
-					Provider: cfg.RPG.LLMProvider,
-					Model:    cfg.RPG.LLMModel,
-					Endpoint: cfg.RPG.LLMEndpoint,
-					APIKey:   cfg.RPG.LLMAPIKey,
-					Timeout:  time.Duration(cfg.RPG.LLMTimeoutMs) * time.Millisecond,
-				})
-			}
-		default:
-			featureExtractor = rpg.NewLocalExtractor()
-		}
-
-		rpgEncoder = rpg.NewRPGEncoder(rpgStore, featureExtractor, projectRoot, rpg.RPGEncoderConfig{
-			DriftThreshold:       cfg.RPG.DriftThreshold,
-			MaxTraversalDepth:    cfg.RPG.MaxTraversalDepth,
-			FeatureGroupStrategy: cfg.RPG.FeatureGroupStrategy,
-		})
+		rpgEncoder = newRPGEncoderFromConfig(rpgStore, cfg.RPG, projectRoot)
 	}
 
 	if rpgStore != nil {
 		defer rpgStore.Close()
 	}
 
-	tracedLanguages := cfg.Trace.EnabledLanguages
-	if len(tracedLanguages) == 0 {
-		tracedLanguages = []string{".go", ".js", ".ts", ".jsx", ".tsx", ".vue", ".py", ".php", ".lua", ".java", ".cs", ".fs", ".fsx", ".fsi"}
-	}
+	tracedLanguages := watchTracedLanguages(cfg)
 	// In multi-worktree mode callers pass isBackgroundChild=true for non-interactive output.
 	// Run initial scan and build symbol index.
-	// In multi-worktree mode callers pass isBackgroundChild=true for non-interactive output.
-	stats, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, tracedLanguages, cfg.Watch.LastIndexTime, isBackgroundChild, onScan, onEmbed, processorRegistry)
+	hooks := watcher.NewHooks(cfg.Watch.Hooks, notifier)
+	defer hooks.Wait()
+
+	quickStarted := false
+	if watchQuickStart {
+		quickStarted, err = runQuickStartScan(ctx, idx, scanner, st, isBackgroundChild, opts.onScan, opts.onEmbed, opts.onActivity)
+		if err != nil {
+			hooks.Error(projectRoot, "", err)
+			return err
+		}
+	}
+
+	// Start watching before the initial scan so files saved while it runs
+	// are indexed ahead of the remaining backfill.
+	w, err := watcher.NewWatcher(projectRoot, ignoreMatcher, cfg.Watch.DebounceMs)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to initialize watcher for %s: %w", projectRoot, err)
 	}
+	w.SetBackend(cfg.Watch.Backend, time.Duration(cfg.Watch.PollIntervalMs)*time.Millisecond, cfg.Watch.MaxWatches)
+	w.SetFollowSymlinks(cfg.Indexer.FollowSymlinks)
+	defer w.Close()
 
-	if stats.FilesIndexed > 0 || stats.ChunksCreated > 0 {
-		cfg.Watch.LastIndexTime = time.Now()
-		if err := cfg.Save(projectRoot); err != nil {
-			log.Printf("Warning: failed to save config: %v", err)
+	// Events are journaled until the index holding their changes is
+	// persisted, so those of a crashed session are replayed below.
+	journal, err := watcher.OpenJournal(config.GetWatchJournalPath(projectRoot))
+	if err != nil {
+		log.Printf("Warning: failed to open watch journal for %s: %v", projectRoot, err)
+	}
+	defer journal.Close()
+	w.SetJournal(journal)
+
+	if err := w.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start watcher for %s: %w", projectRoot, err)
+	}
+
+	// Events handled during the scan must not advance last_index_time, or
+	// files the backfill has not reached yet would be skipped after a restart.
+	scanConfigWrite := time.Unix(1<<62, 0)
+	// Files edited shortly after a search miss are recorded for insights.
+	onEvent := newMissFollowUps(projectRoot, cfg).chain(opts.onEvent)
+	scanEvents := &scanEventSource{
+		events: w.Events(),
+		handle: func(event watcher.FileEvent) {
+			if onEvent != nil {
+				onEvent(projectRoot, event)
+			}
+			handleFileEvent(ctx, idx, scanner, extractor, symbolStore, nil, st, tracedLanguages, projectRoot, cfg, &scanConfigWrite, nil, hooks, event, opts.onActivity, opts.onStats, processorRegistry)
+			journal.Done(event)
+		},
+	}
+
+	// fullScan reconciles the whole project with the index, removing deleted
+	// files, then rebuilds the RPG graph.
+	fullScan := func() error {
+		scanStart := time.Now()
+		cost.scanStarted()
+		prioritized := prioritizeMissFiles(idx, projectRoot, cfg)
+		stats, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, tracedLanguages, symbolScanIndexTime(cfg), isBackgroundChild, opts.onScan, opts.onEmbed, scanEvents, opts.throttle, processorRegistry)
+		prioritized(stats)
+		recordTelemetry(projectRoot, cfg, "watch.full_scan", scanStart, err)
+		cost.scanComplete(opts.onStats)
+		if err != nil {
+			hooks.Error(projectRoot, "", err)
+			return err
+		}
+		saveBudgetExclusions(projectRoot, stats)
+		if quickStarted && opts.onActivity != nil {
+			opts.onActivity("steady", "")
+		}
+		hooks.ScanComplete(projectRoot, stats.FilesIndexed, stats.FilesRemoved, stats.ChunksCreated)
+
+		if stats.FilesIndexed > 0 || stats.ChunksCreated > 0 {
+			cfg.Watch.LastIndexTime = time.Now()
+			if err := cfg.Save(projectRoot); err != nil {
+				log.Printf("Warning: failed to save config: %v", err)
+			}
+		}
+
+		if rpgEncoder != nil {
+			if err := rpgEncoder.BuildFull(ctx, symbolStore, st, opts.onRPG); err != nil {
+				log.Printf("Warning: failed to build RPG graph for %s: %v", projectRoot, err)
+			} else {
+				rpgStats := rpgEncoder.Stats()
+				log.Printf("RPG graph built for %s: %d nodes, %d edges", projectRoot, rpgStats.TotalNodes, rpgStats.TotalEdges)
+			}
 		}
+		return nil
 	}
 
-	if rpgEncoder != nil {
-		if err := rpgEncoder.BuildFull(ctx, symbolStore, st, onRPG); err != nil {
-			log.Printf("Warning: failed to build RPG graph for %s: %v", projectRoot, err)
-		} else {
-			rpgStats := rpgEncoder.Stats()
-			log.Printf("RPG graph built for %s: %d nodes, %d edges", projectRoot, rpgStats.TotalNodes, rpgStats.TotalEdges)
+	// Outside the maintenance window, an already indexed project skips the
+	// full scan until the window opens; file events are indexed meanwhile.
+	var deferred *deferredFullScan
+	window, _ := config.ParseTimeWindow(cfg.Watch.MaintenanceWindow)
+	if now := time.Now(); !window.Contains(now) && !cfg.Watch.LastIndexTime.IsZero() {
+		deferred = &deferredFullScan{at: window.NextStart(now), run: fullScan}
+		log.Printf("Outside maintenance window %s for %s: full scan deferred until %s", window, projectRoot, deferred.at.Format("2006-01-02 15:04"))
+	} else if err := fullScan(); err != nil {
+		return err
+	}
+
+	// The scan skips files whose modification time is not after
+	// last_index_time, which misses edits made within the same second, so
+	// the events a crashed session had not persisted are handled again.
+	if replay := journal.Replay(); len(replay) > 0 {
+		log.Printf("Replaying %d file events not persisted by the previous session of %s", len(replay), projectRoot)
+		for _, event := range replay {
+			handleFileEvent(ctx, idx, scanner, extractor, symbolStore, nil, st, tracedLanguages, projectRoot, cfg, &scanConfigWrite, nil, hooks, replayedEvent(projectRoot, event), opts.onActivity, opts.onStats, processorRegistry)
+			journal.Done(event)
+			cost.emit(opts.onStats)
 		}
 	}
 
-	emitInitialStatsSnapshot(ctx, st, symbolStore, projectRoot, onStats)
+	emitInitialStatsSnapshot(ctx, st, symbolStore, projectRoot, opts.onStats)
 
 	if err := st.Persist(ctx); err != nil {
 		log.Printf("Warning: failed to persist index: %v", err)
@@ -1082,24 +1469,22 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 			log.Printf("Warning: failed to persist RPG graph: %v", err)
 		}
 	}
-
-	// Initialize watcher
-	w, err := watcher.NewWatcher(projectRoot, ignoreMatcher, cfg.Watch.DebounceMs)
-	if err != nil {
-		return fmt.Errorf("failed to initialize watcher for %s: %w", projectRoot, err)
-	}
-	defer w.Close()
-
-	if err := w.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start watcher for %s: %w", projectRoot, err)
-	}
+	updates := newIndexUpdateSignal(projectRoot, cfg)
+	updates.send()
 
 	if onReady != nil {
 		onReady()
 	}
 
 	// Run watch loop (responds to ctx.Done() for graceful shutdown)
-	return runProjectWatchLoop(ctx, st, symbolStore, w, idx, scanner, extractor, rpgEncoder, rpgStore, tracedLanguages, projectRoot, cfg, onEvent, onActivity, onStats, processorRegistry)
+	return runProjectWatchLoop(ctx, st, symbolStore, w, journal, cost, idx, scanner, extractor, rpgEncoder, rpgStore, hooks, updates, deferred, tracedLanguages, projectRoot, cfg, onEvent, opts.onActivity, opts.onStats, processorRegistry)
+}
+
+// deferredFullScan is a full scan postponed to the start of the watch
+// maintenance window.
+type deferredFullScan struct {
+	at  time.Time
+	run func() error
 }
 
 func emitInitialStatsSnapshot(ctx context.Context, vectorStore store.VectorStore, symbolStore trace.SymbolStore, projectRoot string, onStats watchStatsObserver) {
@@ -1137,55 +1522,113 @@ func emitInitialStatsSnapshot(ctx context.Context, vectorStore store.VectorStore
 	}
 }
 
-func runProjectWatchLoop(ctx context.Context, st store.VectorStore, symbolStore *trace.GOBSymbolStore, w *watcher.Watcher, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, rpgEncoder *rpg.RPGEncoder, rpgStore rpg.RPGStore, tracedLanguages []string, projectRoot string, cfg *config.Config, onEvent watchEventObserver, onActivity watchActivityObserver, onStats watchStatsObserver, processors ...*framework.ProcessorRegistry) error {
+func runProjectWatchLoop(ctx context.Context, st store.VectorStore, symbolStore *trace.GOBSymbolStore, w *watcher.Watcher, journal *watcher.Journal, cost *costTracker, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, rpgEncoder *rpg.RPGEncoder, rpgStore rpg.RPGStore, hooks *watcher.Hooks, updates *indexUpdateSignal, deferred *deferredFullScan, tracedLanguages []string, projectRoot string, cfg *config.Config, onEvent watchEventObserver, onActivity watchActivityObserver, onStats watchStatsObserver, processors ...*framework.ProcessorRegistry) error {
 	persistTicker := time.NewTicker(30 * time.Second)
 	defer persistTicker.Stop()
 
-	var lastConfigWrite time.Time
-	var rpgManager *rpgRealtimeManager
-	if rpgEncoder != nil && rpgStore != nil {
-		rpgManager = newRPGRealtimeManager(cfg.Watch.RPGMaxDirtyFilesPerBatch)
-		startRPGRealtimeWorkers(ctx, projectRoot, symbolStore, rpgEncoder, rpgStore, cfg.Watch, rpgManager)
+	var deferredScan <-chan time.Time
+	if deferred != nil {
+		timer := time.NewTimer(time.Until(deferred.at))
+		defer timer.Stop()
+		deferredScan = timer.C
 	}
 
+	var lastConfigWrite time.Time
+	rpgManager, rpgEventEncoder := startRPGMaintenance(ctx, projectRoot, symbolStore, st, rpgEncoder, rpgStore, cfg)
+
 	for {
 		select {
 		case <-ctx.Done():
+			persisted := true
 			if err := st.Persist(ctx); err != nil {
 				log.Printf("Warning: failed to persist index on shutdown for %s: %v", projectRoot, err)
+				persisted = false
 			}
 			if err := symbolStore.Persist(ctx); err != nil {
 				log.Printf("Warning: failed to persist symbol index on shutdown for %s: %v", projectRoot, err)
+				persisted = false
+			}
+			if err := idx.PersistTodos(ctx); err != nil {
+				log.Printf("Warning: failed to persist todo index on shutdown for %s: %v", projectRoot, err)
+			}
+			if persisted {
+				checkpointWatchJournal(journal, projectRoot)
 			}
+			cost.flush()
 			if rpgStore != nil {
 				if err := rpgStore.Persist(ctx); err != nil {
 					log.Printf("Warning: failed to persist RPG graph on shutdown for %s: %v", projectRoot, err)
 				}
 			}
+			updates.persisted()
 			return nil
 
 		case <-persistTicker.C:
+			persisted := true
 			if err := st.Persist(ctx); err != nil {
 				log.Printf("Warning: failed to persist index for %s: %v", projectRoot, err)
+				persisted = false
 			}
 			if err := symbolStore.Persist(ctx); err != nil {
 				log.Printf("Warning: failed to persist symbol index for %s: %v", projectRoot, err)
+				persisted = false
 			}
+			if err := idx.PersistTodos(ctx); err != nil {
+				log.Printf("Warning: failed to persist todo index for %s: %v", projectRoot, err)
+			}
+			if persisted {
+				checkpointWatchJournal(journal, projectRoot)
+			}
+			cost.flush()
 			if rpgStore != nil {
 				if err := rpgStore.Persist(ctx); err != nil {
 					log.Printf("Warning: failed to persist RPG graph for %s: %v", projectRoot, err)
 				}
 			}
+			updates.persisted()
+
+		case <-deferredScan:
+			deferredScan = nil
+			log.Printf("Maintenance window open for %s: running deferred full scan", projectRoot)
+			if err := deferred.run(); err != nil {
+				return err
+			}
+			updates.changed()
 
 		case event := <-w.Events():
+			events := queuedEvents(event, w.Events(), isRemovalEvent)
 			if onEvent != nil {
-				onEvent(projectRoot, event)
+				for _, event := range events {
+					onEvent(projectRoot, event)
+				}
+			}
+			handleFileEvents(ctx, idx, scanner, extractor, symbolStore, rpgEventEncoder, st, tracedLanguages, projectRoot, cfg, &lastConfigWrite, rpgManager, hooks, events, onActivity, onStats, processors...)
+			for _, event := range events {
+				journal.Done(event)
 			}
-			handleFileEvent(ctx, idx, scanner, extractor, symbolStore, rpgEncoder, st, tracedLanguages, projectRoot, cfg, &lastConfigWrite, rpgManager, event, onActivity, onStats, processors...)
+			cost.emit(onStats)
+			updates.changed()
 		}
 	}
 }
 
+// checkpointWatchJournal drops the handled events from the journal once the
+// index holding their changes has been persisted.
+func checkpointWatchJournal(journal *watcher.Journal, projectRoot string) {
+	if err := journal.Checkpoint(); err != nil {
+		log.Printf("Warning: failed to checkpoint watch journal for %s: %v", projectRoot, err)
+	}
+}
+
+// replayedEvent turns a journaled event into one matching the file as it is
+// now: a modification if it exists, a deletion otherwise.
+func replayedEvent(projectRoot string, event watcher.FileEvent) watcher.FileEvent {
+	if _, err := os.Stat(filepath.Join(projectRoot, event.Path)); err == nil {
+		return watcher.FileEvent{Type: watcher.EventModify, Path: event.Path}
+	}
+	return watcher.FileEvent{Type: watcher.EventDelete, Path: event.Path}
+}
+
 type watchProjectRunner func(ctx context.Context, projectRoot string, emb embedder.Embedder, isBackgroundChild bool, onReady func()) error
 
 func startProjectWatch(g *errgroup.Group, gCtx context.Context, projectRoot string, emb embedder.Embedder, makeOnReady func() func(), startFn watchProjectRunner) {
@@ -1220,6 +1663,7 @@ type watchSupervisorSessionRunner func(
 	onRPG func(step string, current, total int),
 	onActivity watchActivityObserver,
 	onStats watchStatsObserver,
+	throttle *watcher.Throttle,
 ) error
 
 type watchInitialReadySelector func(mainRoot, projectRoot string) bool
@@ -1230,12 +1674,14 @@ type dynamicWatchSupervisorConfig struct {
 	discoverWorktrees     func(projectRoot string) []string
 	sessionRunner         watchSupervisorSessionRunner
 	lifecycleObserver     watchSessionLifecycleObserver
+	notifier              *watcher.Notifier
 	eventObserver         watchSessionEventObserver
 	scanObserver          func(current, total int, file string)
 	embedObserver         func(info indexer.BatchProgressInfo)
 	rpgObserver           func(step string, current, total int)
 	activityObserver      watchActivityObserver
 	statsObserver         watchStatsObserver
+	throttle              *watcher.Throttle
 	scopeObserver         func(totalProjects int)
 	initialReadyObserver  func(totalProjects int)
 	initialReadySelector  watchInitialReadySelector
@@ -1279,6 +1725,14 @@ func withWatchSupervisorLifecycleObserver(observer watchSessionLifecycleObserver
 	}
 }
 
+// withWatchSupervisorNotifier sends a desktop notification when a session
+// enters the error state.
+func withWatchSupervisorNotifier(notifier *watcher.Notifier) dynamicWatchSupervisorOption {
+	return func(cfg *dynamicWatchSupervisorConfig) {
+		cfg.notifier = notifier
+	}
+}
+
 func withWatchSupervisorEventObserver(observer watchSessionEventObserver) dynamicWatchSupervisorOption {
 	return func(cfg *dynamicWatchSupervisorConfig) {
 		cfg.eventObserver = observer
@@ -1341,6 +1795,14 @@ func withWatchSupervisorStatsObserver(observer watchStatsObserver) dynamicWatchS
 	}
 }
 
+// withWatchSupervisorThrottle makes every session slow down background
+// indexing while throttle is engaged.
+func withWatchSupervisorThrottle(throttle *watcher.Throttle) dynamicWatchSupervisorOption {
+	return func(cfg *dynamicWatchSupervisorConfig) {
+		cfg.throttle = throttle
+	}
+}
+
 func withWatchSupervisorRetryBackoff(backoff func(attempt int) time.Duration) dynamicWatchSupervisorOption {
 	return func(cfg *dynamicWatchSupervisorConfig) {
 		cfg.retryBackoff = backoff
@@ -1362,12 +1824,21 @@ func newDynamicWatchSupervisorConfig() dynamicWatchSupervisorConfig {
 			onRPG func(step string, current, total int),
 			onActivity watchActivityObserver,
 			onStats watchStatsObserver,
+			throttle *watcher.Throttle,
 		) error {
 			var observer watchEventObserver
 			if onEvent != nil {
 				observer = watchEventObserver(onEvent)
 			}
-			return watchProjectWithEventObserver(ctx, projectRoot, emb, isBackgroundChild, onReady, observer, onScan, onEmbed, onRPG, onActivity, onStats)
+			return watchProjectWithOptions(ctx, projectRoot, emb, isBackgroundChild, onReady, watchOptions{
+				onEvent:    observer,
+				onScan:     onScan,
+				onEmbed:    onEmbed,
+				onRPG:      onRPG,
+				onActivity: onActivity,
+				onStats:    onStats,
+				throttle:   throttle,
+			})
 		},
 		reconcileInterval: worktreeReconcileInterval,
 		retryBackoff:      computeWatchSessionRetryBackoff,
@@ -1405,10 +1876,16 @@ type watchStatsDelta struct {
 	ChunksRemoved int
 	SymbolsFound  int
 	SymbolsLost   int
+	EmbedTokens   int64   // estimated tokens sent to a paid embedder
+	CostUSD       float64 // estimated cost of EmbedTokens
 	Snapshot      bool
 }
 
 type watchActivityObserver func(state, file string)
+
+// watchStoresObserver receives a project's live stores once its watch
+// session has loaded them, and nil stores when the session ends.
+type watchStoresObserver func(projectRoot string, st store.VectorStore, symbolStore *trace.GOBSymbolStore)
 type watchStatsObserver func(projectRoot string, delta watchStatsDelta)
 
 func runDynamicWatchSupervisor(ctx context.Context, mainRoot string, emb embedder.Embedder, opts ...dynamicWatchSupervisorOption) error {
@@ -1432,12 +1909,21 @@ func runDynamicWatchSupervisor(ctx context.Context, mainRoot string, emb embedde
 			onRPG func(step string, current, total int),
 			onActivity watchActivityObserver,
 			onStats watchStatsObserver,
+			throttle *watcher.Throttle,
 		) error {
 			var observer watchEventObserver
 			if onEvent != nil {
 				observer = watchEventObserver(onEvent)
 			}
-			return watchProjectWithEventObserver(ctx, projectRoot, emb, isBackgroundChild, onReady, observer, onScan, onEmbed, onRPG, onActivity, onStats)
+			return watchProjectWithOptions(ctx, projectRoot, emb, isBackgroundChild, onReady, watchOptions{
+				onEvent:    observer,
+				onScan:     onScan,
+				onEmbed:    onEmbed,
+				onRPG:      onRPG,
+				onActivity: onActivity,
+				onStats:    onStats,
+				throttle:   throttle,
+			})
 		}
 	}
 	if cfg.reconcileInterval <= 0 {
@@ -1474,6 +1960,9 @@ func runDynamicWatchSupervisor(ctx context.Context, mainRoot string, emb embedde
 		if cfg.lifecycleObserver != nil {
 			cfg.lifecycleObserver(projectRoot, state, note)
 		}
+		if state == "error" {
+			cfg.notifier.SessionError(projectRoot, note)
+		}
 	}
 
 	markInitialReady := func(projectRoot string) {
@@ -1546,6 +2035,7 @@ func runDynamicWatchSupervisor(ctx context.Context, mainRoot string, emb embedde
 				cfg.rpgObserver,
 				cfg.activityObserver,
 				cfg.statsObserver,
+				cfg.throttle,
 			)
 			sessionResults <- watchSessionResult{
 				projectRoot: project,
@@ -1590,9 +2080,16 @@ func runDynamicWatchSupervisor(ctx context.Context, mainRoot string, emb embedde
 			delete(scheduledRetry, root)
 			emitLifecycle(root, "removed", "worktree removed")
 			markInitialReady(root)
+			if err := recordRemovedWorktree(mainRoot, root, time.Now()); err != nil {
+				log.Printf("Warning: failed to record removed worktree %s: %v", root, err)
+			}
 			if handle, ok := managed[root]; ok {
+				// Retention is applied once the session has stopped
+				// writing to the worktree's index.
 				handle.markedClose = true
 				handle.cancel()
+			} else {
+				applyWorktreeRetention(mainRoot)
 			}
 		}
 
@@ -1640,11 +2137,17 @@ func runDynamicWatchSupervisor(ctx context.Context, mainRoot string, emb embedde
 	reconcileTicker := time.NewTicker(cfg.reconcileInterval)
 	defer reconcileTicker.Stop()
 
+	applyWorktreeRetention(mainRoot)
+	retentionTicker := time.NewTicker(worktreeRetentionCheckInterval)
+	defer retentionTicker.Stop()
+
 	for {
 		select {
 		case <-supervisorCtx.Done():
 			shutdownSessions("context canceled")
 			return nil
+		case <-retentionTicker.C:
+			applyWorktreeRetention(mainRoot)
 		case <-reconcileTicker.C:
 			nextDesired := buildWatchDesiredProjects(mainRoot, cfg.discoverWorktrees(mainRoot))
 			applyDesired(nextDesired)
@@ -1677,6 +2180,8 @@ func runDynamicWatchSupervisor(ctx context.Context, mainRoot string, emb embedde
 				if desired[result.projectRoot] {
 					emitLifecycle(result.projectRoot, "queued", watchSessionRole(mainRoot, result.projectRoot))
 					startSession(result.projectRoot, false)
+				} else {
+					applyWorktreeRetention(mainRoot)
 				}
 				continue
 			}
@@ -1733,15 +2238,20 @@ func runDynamicWatchSupervisor(ctx context.Context, mainRoot string, emb embedde
 }
 
 func buildWatchDesiredProjects(mainRoot string, linked []string) map[string]bool {
+	mainCanonical := canonicalPath(mainRoot)
 	desired := map[string]bool{
-		canonicalPath(mainRoot): true,
+		mainCanonical: true,
+	}
+	seen := map[string]bool{
+		fileutil.PathKey(mainCanonical): true,
 	}
-	mainCanonical := canonicalPath(mainRoot)
 	for _, root := range linked {
 		canonical := canonicalPath(root)
-		if canonical == mainCanonical {
+		key := fileutil.PathKey(canonical)
+		if seen[key] {
 			continue
 		}
+		seen[key] = true
 		desired[canonical] = true
 	}
 	return desired
@@ -1756,6 +2266,15 @@ func sortedWatchProjectRoots(projects map[string]bool) []string {
 	return roots
 }
 
+// newWatchSessionStore wraps the store of a watch session so that a
+// restart of its database server pauses the session until the server is
+// back, instead of failing the changes indexed meanwhile.
+func newWatchSessionStore(st store.VectorStore, label string) store.VectorStore {
+	return store.NewReconnectingStore(st, computeWatchSessionRetryBackoff, func(attempt int, wait time.Duration, err error) {
+		log.Printf("Store for %s is unreachable (%v); reconnecting in %s (attempt %d)", label, err, wait, attempt)
+	})
+}
+
 func computeWatchSessionRetryBackoff(attempt int) time.Duration {
 	if attempt < 1 {
 		attempt = 1
@@ -1774,7 +2293,7 @@ func computeWatchSessionRetryBackoff(attempt int) time.Duration {
 }
 
 func watchSessionRole(mainRoot, projectRoot string) string {
-	if canonicalPath(mainRoot) == canonicalPath(projectRoot) {
+	if sameProjectPath(mainRoot, projectRoot) {
 		return "primary"
 	}
 	return "linked"
@@ -1911,6 +2430,8 @@ func runWatchForeground() error {
 	watchCtx, watchCancel := context.WithCancel(ctx)
 	defer watchCancel()
 
+	throttle := startWatchPowerThrottle(watchCtx, cfg.Watch.PowerProfile, logWatchPowerThrottle("", isBackgroundChild))
+
 	stopCh := daemon.StopChannel()
 	go func() {
 		select {
@@ -1984,11 +2505,13 @@ func runWatchForeground() error {
 		emb,
 		withWatchSupervisorBackgroundChild(isBackgroundChild),
 		withWatchSupervisorInitialLinkedWorktrees(linkedWorktrees),
+		withWatchSupervisorNotifier(watcher.NewNotifier(cfg.Watch.Notifications)),
+		withWatchSupervisorThrottle(throttle),
 		withWatchSupervisorInitialReadySelector(func(mainRoot, currentRoot string) bool {
 			if !isBackgroundChild {
 				return true
 			}
-			return canonicalPath(mainRoot) == canonicalPath(currentRoot)
+			return sameProjectPath(mainRoot, currentRoot)
 		}),
 		withWatchSupervisorInitialReadyObserver(func(_ int) {
 			initialReadyObserver(initialTotalProjects)
@@ -2046,13 +2569,128 @@ func extractSymbolsWithFramework(ctx context.Context, extractor trace.SymbolExtr
 	return symbols, refs, nil
 }
 
-func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore *trace.GOBSymbolStore, rpgEncoder *rpg.RPGEncoder, vectorStore store.VectorStore, enabledLanguages []string, projectRoot string, cfg *config.Config, lastConfigWrite *time.Time, rpgManager *rpgRealtimeManager, event watcher.FileEvent, onActivity watchActivityObserver, onStats watchStatsObserver, processors ...*framework.ProcessorRegistry) {
+// handleFileEvents handles events in order, removing each run of deleted
+// or renamed files from the index in one batch.
+func handleFileEvents(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore *trace.GOBSymbolStore, rpgEncoder *rpg.RPGEncoder, vectorStore store.VectorStore, enabledLanguages []string, projectRoot string, cfg *config.Config, lastConfigWrite *time.Time, rpgManager *rpgRealtimeManager, hooks *watcher.Hooks, events []watcher.FileEvent, onActivity watchActivityObserver, onStats watchStatsObserver, processors ...*framework.ProcessorRegistry) {
+	for len(events) > 0 {
+		n := 0
+		for n < len(events) && isRemovalEvent(events[n]) {
+			n++
+		}
+		if n == 0 {
+			handleFileEvent(ctx, idx, scanner, extractor, symbolStore, rpgEncoder, vectorStore, enabledLanguages, projectRoot, cfg, lastConfigWrite, rpgManager, hooks, events[0], onActivity, onStats, processors...)
+			n = 1
+		} else {
+			removeFileEvents(ctx, idx, symbolStore, rpgEncoder, vectorStore, projectRoot, rpgManager, hooks, events[:n], onActivity, onStats)
+		}
+		events = events[n:]
+	}
+}
+
+// maxRemovalBatch bounds the files queuedEvents gathers for one batch
+// removal.
+const maxRemovalBatch = 256
+
+// isRemovalEvent reports whether event removes its file from the index.
+func isRemovalEvent(event watcher.FileEvent) bool {
+	return event.Type == watcher.EventDelete || event.Type == watcher.EventRename
+}
+
+// queuedEvents returns first and, when it is a removal, the removals queued
+// right behind it on events, so they are removed from the index in one
+// batch. It stops at the first other event, which it returns last.
+func queuedEvents[T any](first T, events <-chan T, isRemoval func(T) bool) []T {
+	batch := []T{first}
+	for isRemoval(batch[len(batch)-1]) && len(batch) < maxRemovalBatch {
+		select {
+		case next := <-events:
+			batch = append(batch, next)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// removeFileEvents removes the files of deletion and rename events from the
+// index, deleting their chunks in one batch, then from the symbol index and
+// the RPG graph.
+func removeFileEvents(ctx context.Context, idx *indexer.Indexer, symbolStore *trace.GOBSymbolStore, rpgEncoder *rpg.RPGEncoder, vectorStore store.VectorStore, projectRoot string, rpgManager *rpgRealtimeManager, hooks *watcher.Hooks, events []watcher.FileEvent, onActivity watchActivityObserver, onStats watchStatsObserver) {
 	if onActivity != nil {
-		op := "processing"
-		if event.Type == watcher.EventDelete {
-			op = "removing"
+		onActivity("removing", events[0].Path)
+		defer onActivity("steady", "")
+	}
+	start := time.Now()
+
+	// Capture previous state for stats deltas
+	paths := make([]string, len(events))
+	oldChunkCounts := make([]int, len(events))
+	oldSymbolCounts := make([]int, len(events))
+	for i, event := range events {
+		paths[i] = event.Path
+		if vectorStore != nil {
+			if doc, err := vectorStore.GetDocument(ctx, event.Path); err == nil && doc != nil {
+				oldChunkCounts[i] = len(doc.ChunkIDs)
+			}
+		}
+		if symbolStore != nil {
+			if syms, err := symbolStore.GetSymbolsForFile(ctx, event.Path); err == nil {
+				oldSymbolCounts[i] = len(syms)
+			}
+		}
+	}
+
+	if _, err := idx.RemoveFiles(ctx, paths); err != nil {
+		for _, path := range paths {
+			log.Printf("Failed to remove %s from index: %v", path, err)
+			hooks.Error(projectRoot, path, err)
+		}
+		return
+	}
+
+	for i, event := range events {
+		// Also remove from symbol index
+		if err := symbolStore.DeleteFile(ctx, event.Path); err != nil {
+			log.Printf("Failed to remove symbols for %s: %v", event.Path, err)
+		}
+
+		if onStats != nil {
+			onStats(projectRoot, watchStatsDelta{
+				FilesRemoved:  1,
+				ChunksRemoved: oldChunkCounts[i],
+				SymbolsLost:   oldSymbolCounts[i],
+			})
+		}
+
+		rpgApplied := true
+		if rpgEncoder != nil {
+			if err := rpgEncoder.HandleFileEvent(ctx, "delete", event.Path, nil); err != nil {
+				log.Printf("Warning: failed to update RPG for deleted %s: %v", event.Path, err)
+				rpgApplied = false
+			}
+		}
+		if rpgApplied && rpgManager != nil {
+			rpgManager.MarkFileDirty(event.Path)
+			dirtyCount, _, _, _ := rpgManager.Snapshot()
+			log.Printf("rpg_event_applied_ms=%d file=%s event=%s rpg_dirty_files_count=%d",
+				time.Since(start).Milliseconds(),
+				event.Path,
+				event.Type.String(),
+				dirtyCount,
+			)
 		}
-		onActivity(op, event.Path)
+		log.Printf("Removed %s from index", event.Path)
+		hooks.FileIndexed(projectRoot, event.Path, "removed", 0)
+	}
+}
+
+func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore *trace.GOBSymbolStore, rpgEncoder *rpg.RPGEncoder, vectorStore store.VectorStore, enabledLanguages []string, projectRoot string, cfg *config.Config, lastConfigWrite *time.Time, rpgManager *rpgRealtimeManager, hooks *watcher.Hooks, event watcher.FileEvent, onActivity watchActivityObserver, onStats watchStatsObserver, processors ...*framework.ProcessorRegistry) {
+	if isRemovalEvent(event) {
+		removeFileEvents(ctx, idx, symbolStore, rpgEncoder, vectorStore, projectRoot, rpgManager, hooks, []watcher.FileEvent{event}, onActivity, onStats)
+		return
+	}
+	if onActivity != nil {
+		onActivity("processing", event.Path)
 		defer onActivity("steady", "")
 	}
 
@@ -2079,6 +2717,7 @@ func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer
 		fileInfo, err := scanner.ScanFile(event.Path)
 		if err != nil {
 			log.Printf("Failed to scan %s: %v", event.Path, err)
+			hooks.Error(projectRoot, event.Path, err)
 			return
 		}
 		if fileInfo == nil {
@@ -2088,6 +2727,7 @@ func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer
 		needsReindex, err := idx.NeedsReindex(ctx, fileInfo.Path, fileInfo.Hash)
 		if err != nil {
 			log.Printf("Failed to check reindex status for %s: %v", event.Path, err)
+			hooks.Error(projectRoot, event.Path, err)
 			return
 		}
 		if !needsReindex {
@@ -2095,12 +2735,17 @@ func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer
 			return
 		}
 
+		indexStart := time.Now()
 		chunks, err := idx.IndexFile(ctx, *fileInfo)
+		recordTelemetry(projectRoot, cfg, "watch.index_file", indexStart, err)
 		if err != nil {
 			log.Printf("Failed to index %s: %v", event.Path, err)
+			hooks.Error(projectRoot, event.Path, err)
 			return
 		}
 		log.Printf("Indexed %s (%d chunks)", event.Path, chunks)
+		// Fired once symbols and the RPG are updated too.
+		defer hooks.FileIndexed(projectRoot, event.Path, "indexed", chunks)
 
 		// Report stats (files/chunks)
 		if onStats != nil {
@@ -2125,8 +2770,7 @@ func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer
 		}
 
 		// Extract symbols if language is supported
-		ext := strings.ToLower(filepath.Ext(event.Path))
-		if isTracedLanguage(ext, enabledLanguages) {
+		if isTracedLanguage(langdetect.Ext(fileInfo.Path, fileInfo.Content), enabledLanguages) {
 			symbols, refs, err := extractSymbolsWithFramework(ctx, extractor, fileInfo.Path, fileInfo.Content, processors...)
 			if err != nil {
 				log.Printf("Failed to extract symbols from %s: %v", event.Path, err)
@@ -2142,7 +2786,8 @@ func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer
 					})
 				}
 
-				// Update RPG graph.
+				// Update RPG graph. Without an encoder (scheduled rebuild policy)
+				// the change only marks the graph for the next full rebuild.
 				if rpgEncoder != nil {
 					eventType := "create"
 					if event.Type == watcher.EventModify {
@@ -2158,55 +2803,30 @@ func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer
 							}
 						}
 					}
-					if rpgManager != nil {
-						rpgManager.MarkFileDirty(fileInfo.Path)
-						dirtyCount, _, _, _ := rpgManager.Snapshot()
-						log.Printf("rpg_event_applied_ms=%d file=%s event=%s rpg_dirty_files_count=%d",
-							time.Since(start).Milliseconds(),
-							fileInfo.Path,
-							event.Type.String(),
-							dirtyCount,
-						)
-					}
+				}
+				if rpgManager != nil {
+					rpgManager.MarkFileDirty(fileInfo.Path)
+					dirtyCount, _, _, _ := rpgManager.Snapshot()
+					log.Printf("rpg_event_applied_ms=%d file=%s event=%s rpg_dirty_files_count=%d",
+						time.Since(start).Milliseconds(),
+						fileInfo.Path,
+						event.Type.String(),
+						dirtyCount,
+					)
 				}
 			}
 		}
 
-	case watcher.EventDelete, watcher.EventRename:
-		start := time.Now()
-		if err := idx.RemoveFile(ctx, event.Path); err != nil {
-			log.Printf("Failed to remove %s from index: %v", event.Path, err)
-			return
-		}
-		// Also remove from symbol index
-		if err := symbolStore.DeleteFile(ctx, event.Path); err != nil {
-			log.Printf("Failed to remove symbols for %s: %v", event.Path, err)
-		}
-
-		if onStats != nil {
-			onStats(projectRoot, watchStatsDelta{
-				FilesRemoved:  1,
-				ChunksRemoved: oldChunkCount,
-				SymbolsLost:   oldSymbolCount,
-			})
-		}
+	}
+}
 
-		if rpgEncoder != nil {
-			if err := rpgEncoder.HandleFileEvent(ctx, "delete", event.Path, nil); err != nil {
-				log.Printf("Warning: failed to update RPG for deleted %s: %v", event.Path, err)
-			} else if rpgManager != nil {
-				rpgManager.MarkFileDirty(event.Path)
-				dirtyCount, _, _, _ := rpgManager.Snapshot()
-				log.Printf("rpg_event_applied_ms=%d file=%s event=%s rpg_dirty_files_count=%d",
-					time.Since(start).Milliseconds(),
-					event.Path,
-					event.Type.String(),
-					dirtyCount,
-				)
-			}
-		}
-		log.Printf("Removed %s from index", event.Path)
+// watchTracedLanguages returns the file extensions whose symbols are
+// indexed for a project.
+func watchTracedLanguages(cfg *config.Config) []string {
+	if len(cfg.Trace.EnabledLanguages) > 0 {
+		return cfg.Trace.EnabledLanguages
 	}
+	return []string{".go", ".js", ".ts", ".jsx", ".tsx", ".vue", ".py", ".php", ".lua", ".java", ".cs", ".fs", ".fsx", ".fsi", ".mk", ".dockerfile", ".tf", ".sql"}
 }
 
 // isTracedLanguage checks if a file extension is in the enabled languages list.
@@ -2219,6 +2839,86 @@ func isTracedLanguage(ext string, enabledLanguages []string) bool {
 	return false
 }
 
+// runIndexWithProgress runs an indexing pass, reporting progress to the watch
+// UI observers when set, and otherwise as a progress bar or plain summaries
+// depending on --progress and the output.
+func runIndexWithProgress(isBackgroundChild bool, onScan func(current, total int, file string), onEmbed func(info indexer.BatchProgressInfo), index func(indexer.ProgressCallback, indexer.BatchProgressCallback) (*indexer.IndexStats, error)) (*indexer.IndexStats, error) {
+	var stats *indexer.IndexStats
+	var err error
+	if !isBackgroundChild {
+		mode := resolveWatchProgressMode(watchProgress, isTerminalFD(os.Stdout) && !plainUIEnabled())
+		fileProgress, batchProgress := initialScanProgressCallbacks(mode, func(format string, args ...any) {
+			fmt.Printf(format+"\n", args...)
+		})
+		stats, err = index(
+			func(info indexer.ProgressInfo) {
+				if onScan != nil {
+					onScan(info.Current, info.Total, info.CurrentFile)
+				} else {
+					fileProgress(info)
+				}
+			},
+			func(info indexer.BatchProgressInfo) {
+				if onEmbed != nil {
+					onEmbed(info)
+				} else {
+					batchProgress(info)
+				}
+			},
+		)
+		if mode == watchProgressBar {
+			watchProgressOutput.clear()
+			fmt.Println()
+		}
+	} else {
+		// Daemon logs cannot redraw a bar, so progress is logged as periodic
+		// summaries unless disabled.
+		fileProgress, batchProgress := initialScanProgressCallbacks(resolveWatchProgressMode(watchProgress, false), log.Printf)
+		stats, err = index(func(info indexer.ProgressInfo) {
+			if onScan != nil {
+				onScan(info.Current, info.Total, info.CurrentFile)
+			}
+			fileProgress(info)
+		}, func(info indexer.BatchProgressInfo) {
+			if onEmbed != nil {
+				onEmbed(info)
+			}
+			batchProgress(info)
+		})
+	}
+
+	return stats, err
+}
+
+// initialScanProgressCallbacks returns the file and embedding progress
+// callbacks of the initial scan for a resolved progress mode. Plain summaries
+// and retry notices are written with printf.
+func initialScanProgressCallbacks(mode string, printf func(format string, args ...any)) (func(indexer.ProgressInfo), func(indexer.BatchProgressInfo)) {
+	switch mode {
+	case watchProgressBar:
+		onFile := func(info indexer.ProgressInfo) {
+			printProgress(info.Current, info.Total, info.CurrentFile)
+		}
+		return onFile, printBatchProgress
+	case watchProgressPlain:
+		files := newPlainProgressReporter("Indexing", "files", printf)
+		chunks := newPlainProgressReporter("Embedding", "chunks", printf)
+		onFile := func(info indexer.ProgressInfo) {
+			files.update(info.Current, info.Total)
+		}
+		onBatch := func(info indexer.BatchProgressInfo) {
+			if info.Retrying {
+				printf("%s - Retrying batch %d (attempt %d/5)...", describeRetryReason(info.StatusCode), info.BatchIndex+1, info.Attempt)
+				return
+			}
+			chunks.update(info.CompletedChunks, info.TotalChunks)
+		}
+		return onFile, onBatch
+	default:
+		return func(indexer.ProgressInfo) {}, func(indexer.BatchProgressInfo) {}
+	}
+}
+
 // printProgress displays a progress bar for indexing
 func printProgress(current, total int, filePath string) {
 	if total == 0 {
@@ -2382,6 +3082,11 @@ func runWorkspaceWatch(logDir string) error {
 	}
 
 	// Run in foreground mode
+	stopProfiling, err := startProfiling("watch")
+	if err != nil {
+		return err
+	}
+	defer stopProfiling()
 	return runWorkspaceWatchForeground(logDir, ws)
 }
 
@@ -2421,8 +3126,28 @@ func stopWorkspaceWatchDaemon(logDir, workspaceName string) error {
 	}
 
 	fmt.Printf("Stopping workspace watcher %s (PID %d)...\n", workspaceName, pid)
+	if _, err := stopWorkspaceWatcher(logDir, workspaceName); err != nil {
+		return err
+	}
+
+	fmt.Printf("Workspace watcher %s stopped\n", workspaceName)
+	return nil
+}
+
+// stopWorkspaceWatcher stops the background watcher of workspaceName and
+// waits for it to exit. It returns the stopped watcher's PID, or 0 when none
+// was running.
+func stopWorkspaceWatcher(logDir, workspaceName string) (int, error) {
+	pid, err := daemon.GetRunningWorkspacePID(logDir, workspaceName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
+	}
+	if pid == 0 {
+		return 0, nil
+	}
+
 	if err := daemon.StopProcess(pid); err != nil {
-		return fmt.Errorf("failed to stop process: %w", err)
+		return 0, fmt.Errorf("failed to stop process: %w", err)
 	}
 
 	// Wait for process to stop
@@ -2438,25 +3163,37 @@ func stopWorkspaceWatchDaemon(logDir, workspaceName string) error {
 	}
 
 	if daemon.IsProcessRunning(pid) {
-		return fmt.Errorf("process did not stop within %v", shutdownTimeout)
+		return 0, fmt.Errorf("process did not stop within %v", shutdownTimeout)
 	}
 
 	if err := daemon.RemoveWorkspacePIDFile(logDir, workspaceName); err != nil {
-		return fmt.Errorf("failed to remove PID file: %w", err)
+		return 0, fmt.Errorf("failed to remove PID file: %w", err)
 	}
+	return pid, nil
+}
 
-	fmt.Printf("Workspace watcher %s stopped\n", workspaceName)
+func startBackgroundWorkspaceWatch(logDir string, ws *config.Workspace) error {
+	childPID, wsLogFile, err := spawnBackgroundWorkspaceWatch(logDir, ws)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Workspace watcher %s started (PID %d)\n", ws.Name, childPID)
+	fmt.Printf("Logs: %s\n", wsLogFile)
+	fmt.Printf("\nUse 'grepai watch --workspace %s --status' to check status\n", ws.Name)
+	fmt.Printf("Use 'grepai watch --workspace %s --stop' to stop the watcher\n", ws.Name)
 	return nil
 }
 
-func startBackgroundWorkspaceWatch(logDir string, ws *config.Workspace) error {
+// spawnBackgroundWorkspaceWatch starts a background watcher for ws and
+// waits until it is ready. It returns the watcher's PID and log file.
+func spawnBackgroundWorkspaceWatch(logDir string, ws *config.Workspace) (int, string, error) {
 	// Check if already running
 	pid, err := daemon.GetRunningWorkspacePID(logDir, ws.Name)
 	if err != nil {
-		return fmt.Errorf("failed to check running status: %w", err)
+		return 0, "", fmt.Errorf("failed to check running status: %w", err)
 	}
 	if pid > 0 {
-		return fmt.Errorf("workspace watcher %s is already running (PID %d)", ws.Name, pid)
+		return 0, "", fmt.Errorf("workspace watcher %s is already running (PID %d)", ws.Name, pid)
 	}
 
 	// Build extra args
@@ -2464,11 +3201,12 @@ func startBackgroundWorkspaceWatch(logDir string, ws *config.Workspace) error {
 	if watchLogDir != "" {
 		extraArgs = append(extraArgs, "--log-dir", watchLogDir)
 	}
+	extraArgs = append(extraArgs, profiling.args()...)
 
 	// Spawn background process
 	childPID, exitCh, err := daemon.SpawnWorkspaceBackground(logDir, ws.Name, extraArgs)
 	if err != nil {
-		return fmt.Errorf("failed to start background process: %w", err)
+		return 0, "", fmt.Errorf("failed to start background process: %w", err)
 	}
 
 	// Wait for process to become ready
@@ -2480,23 +3218,19 @@ func startBackgroundWorkspaceWatch(logDir string, ws *config.Workspace) error {
 
 	for time.Now().Before(deadline) {
 		if daemon.IsWorkspaceReady(logDir, ws.Name) {
-			fmt.Printf("Workspace watcher %s started (PID %d)\n", ws.Name, childPID)
-			fmt.Printf("Logs: %s\n", wsLogFile)
-			fmt.Printf("\nUse 'grepai watch --workspace %s --status' to check status\n", ws.Name)
-			fmt.Printf("Use 'grepai watch --workspace %s --stop' to stop the watcher\n", ws.Name)
-			return nil
+			return childPID, wsLogFile, nil
 		}
 
 		select {
 		case <-exitCh:
-			return fmt.Errorf("background process failed to start (check logs at %s)", wsLogFile)
+			return 0, "", fmt.Errorf("background process failed to start (check logs at %s)", wsLogFile)
 		default:
 		}
 
 		time.Sleep(pollInterval)
 	}
 
-	return fmt.Errorf("timeout waiting for process to become ready (check logs at %s)", wsLogFile)
+	return 0, "", fmt.Errorf("timeout waiting for process to become ready (check logs at %s)", wsLogFile)
 }
 
 func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
@@ -2517,9 +3251,48 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 			if err := daemon.RemoveWorkspacePIDFile(logDir, ws.Name); err != nil {
 				log.Printf("Warning: failed to remove PID file on exit: %v", err)
 			}
+			if err := daemon.RemoveWorkspaceReadyFile(logDir, ws.Name); err != nil {
+				log.Printf("Warning: failed to remove ready file on exit: %v", err)
+			}
 		}()
 	}
 
+	// Handle signals
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	wsStopCh := daemon.StopChannel()
+	go func() {
+		select {
+		case <-sigChan:
+			if !isBackgroundChild {
+				fmt.Println("\nShutting down...")
+			} else {
+				log.Println("Shutting down...")
+			}
+		case <-wsStopCh:
+			log.Println("Stop file detected, shutting down...")
+		case <-ctx.Done():
+			return
+		}
+		cancel()
+	}()
+
+	return runWorkspaceWatchSession(ctx, ws, isBackgroundChild, func() error {
+		if !isBackgroundChild {
+			return nil
+		}
+		if err := daemon.WriteWorkspaceReadyFile(logDir, ws.Name); err != nil {
+			return fmt.Errorf("failed to write ready file: %w", err)
+		}
+		return nil
+	})
+}
+
+// runWorkspaceWatchSession indexes the projects of ws and keeps their index
+// up to date until ctx is canceled, then persists it. onReady is called once
+// the initial scans are done.
+func runWorkspaceWatchSession(ctx context.Context, ws *config.Workspace, isBackgroundChild bool, onReady func() error) error {
 	if !isBackgroundChild {
 		fmt.Printf("Starting workspace watcher: %s\n", ws.Name)
 		fmt.Printf("Backend: %s\n", ws.Store.Backend)
@@ -2548,11 +3321,12 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 	defer emb.Close()
 
 	// Initialize shared store with workspace-specific project ID
-	st, err := initializeWorkspaceStore(ctx, ws)
+	rawStore, err := openWorkspaceStore(ctx, ws)
 	if err != nil {
-		return fmt.Errorf("failed to initialize store: %w", err)
+		return err
 	}
-	defer st.Close()
+	defer rawStore.Close()
+	st := newWatchSessionStore(rawStore, ws.Name)
 
 	runtimes := make(map[string]*workspaceProjectRuntime, len(ws.Projects))
 	watchers := make([]*watcher.Watcher, 0, len(ws.Projects))
@@ -2570,7 +3344,7 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 			continue
 		}
 
-		projectKey := canonicalPath(project.Path)
+		projectKey := projectPathKey(project.Path)
 		runtimes[projectKey] = runtime
 		watchers = append(watchers, w)
 	}
@@ -2580,6 +3354,10 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 			w.Close()
 		}
 		for _, runtime := range runtimes {
+			runtime.hooks.Wait()
+			if err := runtime.journal.Close(); err != nil {
+				log.Printf("Warning: failed to close watch journal for %s: %v", runtime.project.Path, err)
+			}
 			if runtime.symbolStore != nil {
 				if err := runtime.symbolStore.Close(); err != nil {
 					log.Printf("Warning: failed to close symbol store for %s: %v", runtime.project.Path, err)
@@ -2593,23 +3371,10 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 		}
 	}()
 
-	// Write ready file
-	if isBackgroundChild {
-		if err := daemon.WriteWorkspaceReadyFile(logDir, ws.Name); err != nil {
-			return fmt.Errorf("failed to write ready file: %w", err)
-		}
-		defer func() {
-			if err := daemon.RemoveWorkspaceReadyFile(logDir, ws.Name); err != nil {
-				log.Printf("Warning: failed to remove ready file on exit: %v", err)
-			}
-		}()
+	if err := onReady(); err != nil {
+		return err
 	}
 
-	// Handle signals
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	wsStopCh := daemon.StopChannel()
-
 	if !isBackgroundChild {
 		fmt.Printf("\nWatching %d projects for changes... (Press Ctrl+C to stop)\n", len(runtimes))
 	} else {
@@ -2633,83 +3398,95 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 		}()
 	}
 
-	// persistAndShutdown persists all stores before returning from the event loop.
-	persistAndShutdown := func() {
-		if err := st.Persist(ctx); err != nil {
-			log.Printf("Warning: failed to persist index on shutdown: %v", err)
+	// persistAll persists all stores, then drops from each project's journal
+	// the events whose changes were persisted. It also runs once ctx is
+	// canceled, so it does not inherit the cancellation.
+	persistCtx := context.WithoutCancel(ctx)
+	persistAll := func(when string) {
+		indexPersisted := true
+		if err := st.Persist(persistCtx); err != nil {
+			log.Printf("Warning: failed to persist index%s: %v", when, err)
+			indexPersisted = false
 		}
 		for _, runtime := range runtimes {
-			if err := runtime.symbolStore.Persist(ctx); err != nil {
-				log.Printf("Warning: failed to persist symbol index on shutdown for %s: %v", runtime.project.Name, err)
+			persisted := indexPersisted
+			if err := runtime.symbolStore.Persist(persistCtx); err != nil {
+				log.Printf("Warning: failed to persist symbol index%s for %s: %v", when, runtime.project.Name, err)
+				persisted = false
+			}
+			if err := runtime.idx.PersistTodos(persistCtx); err != nil {
+				log.Printf("Warning: failed to persist todo index%s for %s: %v", when, runtime.project.Name, err)
+			}
+			if persisted {
+				checkpointWatchJournal(runtime.journal, runtime.project.Path)
 			}
 			if runtime.rpgStore != nil {
-				if err := runtime.rpgStore.Persist(ctx); err != nil {
-					log.Printf("Warning: failed to persist RPG graph on shutdown for %s: %v", runtime.project.Name, err)
+				if err := runtime.rpgStore.Persist(persistCtx); err != nil {
+					log.Printf("Warning: failed to persist RPG graph%s for %s: %v", when, runtime.project.Name, err)
 				}
 			}
+			runtime.cost.flush()
 		}
 	}
 
+	// persistAndShutdown persists all stores before returning from the event loop.
+	persistAndShutdown := func() {
+		persistAll(" on shutdown")
+	}
+
 	// Event loop
 	persistTicker := time.NewTicker(30 * time.Second)
 	defer persistTicker.Stop()
 
 	for {
 		select {
-		case <-sigChan:
-			if !isBackgroundChild {
-				fmt.Println("\nShutting down...")
-			} else {
-				log.Println("Shutting down...")
-			}
-			persistAndShutdown()
-			return nil
-
-		case <-wsStopCh:
-			log.Println("Stop file detected, shutting down...")
+		case <-ctx.Done():
 			persistAndShutdown()
 			return nil
 
 		case <-persistTicker.C:
-			if err := st.Persist(ctx); err != nil {
-				log.Printf("Warning: failed to persist index: %v", err)
-			}
-			for _, runtime := range runtimes {
-				if err := runtime.symbolStore.Persist(ctx); err != nil {
-					log.Printf("Warning: failed to persist symbol index for %s: %v", runtime.project.Name, err)
-				}
-				if runtime.rpgStore != nil {
-					if err := runtime.rpgStore.Persist(ctx); err != nil {
-						log.Printf("Warning: failed to persist RPG graph for %s: %v", runtime.project.Name, err)
-					}
-				}
-			}
+			persistAll("")
 
 		case event := <-eventChan:
-			projectKey := canonicalPath(event.projectPath)
-			runtime := runtimes[projectKey]
-			if runtime == nil {
-				log.Printf("Warning: received event for unknown runtime: %s", event.projectPath)
-				continue
+			queued := queuedEvents(event, eventChan, func(e workspaceWatchEvent) bool { return isRemovalEvent(e.event) })
+			for len(queued) > 0 {
+				// Events of one project are handled together.
+				projectPath := queued[0].projectPath
+				projectKey := projectPathKey(projectPath)
+				var events []watcher.FileEvent
+				for len(queued) > 0 && projectPathKey(queued[0].projectPath) == projectKey {
+					events = append(events, queued[0].event)
+					queued = queued[1:]
+				}
+				runtime := runtimes[projectKey]
+				if runtime == nil {
+					log.Printf("Warning: received event for unknown runtime: %s", projectPath)
+					continue
+				}
+				handleFileEvents(
+					ctx,
+					runtime.idx,
+					runtime.scanner,
+					runtime.extractor,
+					runtime.symbolStore,
+					runtime.rpgEncoder,
+					runtime.vectorStore,
+					runtime.tracedLanguages,
+					runtime.project.Path,
+					runtime.cfg,
+					&runtime.lastConfigWrite,
+					runtime.manager,
+					runtime.hooks,
+					events,
+					nil,
+					nil,
+					runtime.processor,
+				)
+				for _, event := range events {
+					runtime.followUps.observe(event)
+					runtime.journal.Done(event)
+				}
 			}
-			handleFileEvent(
-				ctx,
-				runtime.idx,
-				runtime.scanner,
-				runtime.extractor,
-				runtime.symbolStore,
-				runtime.rpgEncoder,
-				runtime.vectorStore,
-				runtime.tracedLanguages,
-				runtime.project.Path,
-				runtime.cfg,
-				&runtime.lastConfigWrite,
-				runtime.manager,
-				event.event,
-				nil,
-				nil,
-				runtime.processor,
-			)
 		}
 	}
 }
@@ -2733,7 +3510,11 @@ type workspaceProjectRuntime struct {
 	tracedLanguages []string
 	lastConfigWrite time.Time
 	manager         *rpgRealtimeManager
+	hooks           *watcher.Hooks
 	watcher         *watcher.Watcher
+	journal         *watcher.Journal
+	cost            *costTracker
+	followUps       *missFollowUps
 }
 
 func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, project config.ProjectEntry, emb embedder.Embedder, sharedStore store.VectorStore, isBackgroundChild bool) (*workspaceProjectRuntime, *watcher.Watcher, error) {
@@ -2746,38 +3527,72 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 			projectCfg = loadedCfg
 		}
 	}
+	// projectCfg is saved back to the project, so it keeps no overrides.
+	indexCfg := project.WithOverrides(projectCfg)
+	notifier := watcher.NewNotifier(projectCfg.Watch.Notifications)
+	hooks := watcher.NewHooks(projectCfg.Watch.Hooks, notifier)
 
-	ignoreMatcher, err := indexer.NewIgnoreMatcher(project.Path, projectCfg.Ignore, projectCfg.ExternalGitignore)
+	pi, err := newWorkspaceProjectIndexer(ws, project, projectCfg, notifier.WrapEmbedder(project.Path, emb), sharedStore, projectCfg.Watch.LastIndexTime)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to initialize ignore matcher: %w", err)
+		hooks.Error(project.Path, "", err)
+		return nil, nil, err
 	}
-
-	scanner := indexer.NewScanner(project.Path, ignoreMatcher)
-	chunker := indexer.NewChunker(projectCfg.Chunking.Size, projectCfg.Chunking.Overlap)
-	processorRegistry := buildFrameworkRegistry(projectCfg)
-	vectorStore := &projectPrefixStore{
-		store:         sharedStore,
-		workspaceName: ws.Name,
-		projectName:   project.Name,
-		projectPath:   project.Path,
+	// Each project follows its own watch.power_profile.
+	throttle := startWatchPowerThrottle(ctx, projectCfg.Watch.PowerProfile, logWatchPowerThrottle(project.Name, isBackgroundChild))
+	if throttle != nil {
+		pi.idx.SetSerialEmbedding(throttle.Throttled)
 	}
-	idx := indexer.NewIndexer(project.Path, vectorStore, emb, chunker, scanner, projectCfg.Watch.LastIndexTime, processorRegistry)
 	extractor := trace.NewRegexExtractor()
 	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(project.Path))
 	if err := symbolStore.Load(ctx); err != nil {
 		log.Printf("Warning: failed to load symbol index for %s: %v", project.Path, err)
 	}
 
-	tracedLanguages := projectCfg.Trace.EnabledLanguages
-	if len(tracedLanguages) == 0 {
-		tracedLanguages = []string{".go", ".js", ".ts", ".jsx", ".tsx", ".vue", ".py", ".php", ".lua", ".java", ".cs", ".fs", ".fsx", ".fsi"}
+	tracedLanguages := watchTracedLanguages(indexCfg)
+
+	// Events are journaled until the index holding their changes is
+	// persisted, so those of a crashed session are replayed below.
+	var journal *watcher.Journal
+	if journalPath, err := config.GetWorkspaceWatchJournalPath(ws.Name, project.Name); err != nil {
+		log.Printf("Warning: failed to locate watch journal for %s: %v", project.Name, err)
+	} else if journal, err = watcher.OpenJournal(journalPath); err != nil {
+		log.Printf("Warning: failed to open watch journal for %s: %v", project.Name, err)
+	}
+	closeStores := func() {
+		_ = journal.Close()
+		_ = symbolStore.Close()
 	}
 
-	stats, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, tracedLanguages, projectCfg.Watch.LastIndexTime, isBackgroundChild, nil, nil, processorRegistry)
+	if watchQuickStart {
+		if _, err := runQuickStartScan(ctx, pi.idx, pi.scanner, pi.vectorStore, isBackgroundChild, nil, nil, nil); err != nil {
+			pi.cost.scanComplete(nil)
+			hooks.Error(project.Path, "", err)
+			closeStores()
+			return nil, nil, err
+		}
+	}
+	prioritized := prioritizeMissFiles(pi.idx, project.Path, projectCfg)
+	stats, err := runInitialScan(ctx, pi.idx, pi.scanner, extractor, symbolStore, tracedLanguages, symbolScanIndexTime(projectCfg), isBackgroundChild, nil, nil, nil, throttle, pi.processor)
+	prioritized(stats)
+	pi.cost.scanComplete(nil)
 	if err != nil {
-		_ = symbolStore.Close()
+		hooks.Error(project.Path, "", err)
+		closeStores()
 		return nil, nil, err
 	}
+
+	// The scan skips files whose modification time is not after
+	// last_index_time, which misses edits made within the same second.
+	var replayConfigWrite time.Time
+	if replay := journal.Replay(); len(replay) > 0 {
+		log.Printf("Replaying %d file events not persisted by the previous session of %s", len(replay), project.Path)
+		for _, event := range replay {
+			handleFileEvent(ctx, pi.idx, pi.scanner, extractor, symbolStore, nil, pi.vectorStore, tracedLanguages, project.Path, projectCfg, &replayConfigWrite, nil, hooks, replayedEvent(project.Path, event), nil, nil, pi.processor)
+			journal.Done(event)
+		}
+	}
+	saveBudgetExclusions(project.Path, stats)
+	hooks.ScanComplete(project.Path, stats.FilesIndexed, stats.FilesRemoved, stats.ChunksCreated)
 	if stats.FilesIndexed > 0 || stats.ChunksCreated > 0 {
 		projectCfg.Watch.LastIndexTime = time.Now()
 		if err := projectCfg.Save(project.Path); err != nil {
@@ -2788,100 +3603,71 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 	var rpgStore rpg.RPGStore
 	var rpgEncoder *rpg.RPGEncoder
 	var manager *rpgRealtimeManager
-	if projectCfg.RPG.Enabled {
+	if projectCfg.RPG.Enabled && projectCfg.RPG.Rebuild == config.RPGRebuildManual {
+		log.Printf("RPG rebuild policy is manual for %s; run 'grepai rpg rebuild' to update the graph", project.Path)
+	} else if projectCfg.RPG.Enabled {
 		rpgStore = rpg.NewGOBRPGStore(config.GetRPGIndexPath(project.Path))
 		if err := rpgStore.Load(ctx); err != nil {
 			log.Printf("Warning: failed to load RPG index for %s: %v", project.Path, err)
 		}
 
-		var featureExtractor rpg.FeatureExtractor
-		switch projectCfg.RPG.FeatureMode {
-		case "llm", "hybrid":
-			if projectCfg.RPG.LLMEndpoint == "" || projectCfg.RPG.LLMModel == "" {
-				log.Printf("Warning: RPG feature_mode=%q but llm_endpoint or llm_model is empty for %s, falling back to local extractor", projectCfg.RPG.FeatureMode, project.Path)
-				featureExtractor = rpg.NewLocalExtractor()
-			} else {
-				featureExtractor = rpg.NewLLMExtractor(rpg.LLMExtractorConfig{
-					Provider: projectCfg.RPG.LLMProvider,
-					Model:    projectCfg.RPG.LLMModel,
-					Endpoint: projectCfg.RPG.LLMEndpoint,
-					APIKey:   projectCfg.RPG.LLMAPIKey,
-					Timeout:  time.Duration(projectCfg.RPG.LLMTimeoutMs) * time.Millisecond,
-				})
-			}
-		default:
-			featureExtractor = rpg.NewLocalExtractor()
-		}
-
-		rpgEncoder = rpg.NewRPGEncoder(rpgStore, featureExtractor, project.Path, rpg.RPGEncoderConfig{
-			DriftThreshold:       projectCfg.RPG.DriftThreshold,
-			MaxTraversalDepth:    projectCfg.RPG.MaxTraversalDepth,
-			FeatureGroupStrategy: projectCfg.RPG.FeatureGroupStrategy,
-		})
-		if err := rpgEncoder.BuildFull(ctx, symbolStore, vectorStore, nil); err != nil {
+		rpgEncoder = newRPGEncoderFromConfig(rpgStore, projectCfg.RPG, project.Path)
+		if err := rpgEncoder.BuildFull(ctx, symbolStore, pi.vectorStore, nil); err != nil {
 			log.Printf("Warning: failed to build RPG graph for %s: %v", project.Path, err)
 		}
 		if err := rpgStore.Persist(ctx); err != nil {
 			log.Printf("Warning: failed to persist RPG graph for %s: %v", project.Path, err)
 		}
 
-		manager = newRPGRealtimeManager(projectCfg.Watch.RPGMaxDirtyFilesPerBatch)
-		startRPGRealtimeWorkers(ctx, fmt.Sprintf("workspace:%s/%s", ws.Name, project.Name), symbolStore, rpgEncoder, rpgStore, projectCfg.Watch, manager)
+		manager, rpgEncoder = startRPGMaintenance(ctx, fmt.Sprintf("workspace:%s/%s", ws.Name, project.Name), symbolStore, pi.vectorStore, rpgEncoder, rpgStore, projectCfg)
 	}
 
-	w, err := watcher.NewWatcher(project.Path, ignoreMatcher, projectCfg.Watch.DebounceMs)
+	w, err := watcher.NewWatcher(project.Path, pi.ignore, projectCfg.Watch.DebounceMs)
 	if err != nil {
 		if rpgStore != nil {
 			_ = rpgStore.Close()
 		}
-		_ = symbolStore.Close()
-		return nil, nil, fmt.Errorf("failed to create watcher: %w", err)
+		closeStores()
+		err = fmt.Errorf("failed to create watcher: %w", err)
+		hooks.Error(project.Path, "", err)
+		return nil, nil, err
 	}
+	w.SetBackend(projectCfg.Watch.Backend, time.Duration(projectCfg.Watch.PollIntervalMs)*time.Millisecond, projectCfg.Watch.MaxWatches)
+	w.SetFollowSymlinks(projectCfg.Indexer.FollowSymlinks)
+	w.SetJournal(journal)
 	if err := w.Start(ctx); err != nil {
 		w.Close()
 		if rpgStore != nil {
 			_ = rpgStore.Close()
 		}
-		_ = symbolStore.Close()
-		return nil, nil, fmt.Errorf("failed to start watcher: %w", err)
+		closeStores()
+		err = fmt.Errorf("failed to start watcher: %w", err)
+		hooks.Error(project.Path, "", err)
+		return nil, nil, err
 	}
 
 	runtime := &workspaceProjectRuntime{
 		project:         project,
 		cfg:             projectCfg,
-		idx:             idx,
-		scanner:         scanner,
+		idx:             pi.idx,
+		scanner:         pi.scanner,
 		extractor:       extractor,
-		processor:       processorRegistry,
+		processor:       pi.processor,
 		symbolStore:     symbolStore,
 		rpgEncoder:      rpgEncoder,
 		rpgStore:        rpgStore,
-		vectorStore:     vectorStore,
+		vectorStore:     pi.vectorStore,
 		tracedLanguages: tracedLanguages,
 		manager:         manager,
+		hooks:           hooks,
 		watcher:         w,
+		journal:         journal,
+		cost:            pi.cost,
+		followUps:       newMissFollowUps(project.Path, projectCfg),
 	}
 	return runtime, w, nil
 }
 
-func initializeWorkspaceStore(ctx context.Context, ws *config.Workspace) (store.VectorStore, error) {
-	// Use workspace name as project ID for shared store
-	projectID := "workspace:" + ws.Name
-
-	switch ws.Store.Backend {
-	case "postgres":
-		return store.NewPostgresStore(ctx, ws.Store.Postgres.DSN, projectID, ws.Embedder.GetDimensions())
-	case "qdrant":
-		collectionName := ws.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = "workspace_" + ws.Name
-		}
-		return store.NewQdrantStore(ctx, ws.Store.Qdrant.Endpoint, ws.Store.Qdrant.Port, ws.Store.Qdrant.UseTLS, collectionName, ws.Store.Qdrant.APIKey, ws.Embedder.GetDimensions())
-	default:
-		return nil, fmt.Errorf("unsupported backend for workspace: %s", ws.Store.Backend)
-	}
-}
-
 // projectPrefixStore wraps a VectorStore to prefix file paths with workspace and project name
 type projectPrefixStore struct {
 	store         store.VectorStore
@@ -2890,8 +3676,12 @@ type projectPrefixStore struct {
 	projectPath   string
 }
 
-// getPrefix returns the full prefix for this project (workspace/project)
+// getPrefix returns the full prefix for this project (workspace/project),
+// or the project name alone when there is no workspace.
 func (p *projectPrefixStore) getPrefix() string {
+	if p.workspaceName == "" {
+		return p.projectName
+	}
 	return p.workspaceName + "/" + p.projectName
 }
 
@@ -2909,19 +3699,35 @@ func (p *projectPrefixStore) toRelSlash(absPath string) string {
 }
 
 func (p *projectPrefixStore) SaveChunks(ctx context.Context, chunks []store.Chunk) error {
+	return p.store.SaveChunks(ctx, p.prefixChunks(chunks))
+}
+
+func (p *projectPrefixStore) prefixChunks(chunks []store.Chunk) []store.Chunk {
 	prefixedChunks := make([]store.Chunk, len(chunks))
 	for i, c := range chunks {
 		prefixedChunks[i] = c
 		relPath := p.toRelSlash(c.FilePath)
 		prefixedPath := p.getPrefix() + "/" + relPath
 		prefixedChunks[i].FilePath = prefixedPath
-		// Also update the chunk ID to include project prefix
-		// Original ID format is "filePath_index", we need to replace the filePath part
-		if idx := strings.LastIndex(c.ID, "_"); idx >= 0 {
-			prefixedChunks[i].ID = prefixedPath + c.ID[idx:]
-		}
+		prefixedChunks[i].ID = prefixChunkID(c.ID, relPath, prefixedPath)
+	}
+	return prefixedChunks
+}
+
+func (p *projectPrefixStore) ReplaceFile(ctx context.Context, doc store.Document, chunks []store.Chunk) error {
+	return store.ReplaceFile(ctx, p.store, p.prefixDocument(doc), p.prefixChunks(chunks))
+}
+
+// prefixChunkID replaces the file path part of a chunk ID, whose format is
+// "filePath_index_hash" (see indexer.ChunkID), with the prefixed file path.
+func prefixChunkID(id, relPath, prefixedPath string) string {
+	if strings.HasPrefix(id, relPath+"_") {
+		return prefixedPath + id[len(relPath):]
 	}
-	return p.store.SaveChunks(ctx, prefixedChunks)
+	if paths := indexer.ChunkIDFilePaths(id); len(paths) > 0 {
+		return prefixedPath + id[len(paths[len(paths)-1]):]
+	}
+	return id
 }
 
 func (p *projectPrefixStore) DeleteByFile(ctx context.Context, filePath string) error {
@@ -2929,6 +3735,24 @@ func (p *projectPrefixStore) DeleteByFile(ctx context.Context, filePath string)
 	return p.store.DeleteByFile(ctx, prefixedPath)
 }
 
+func (p *projectPrefixStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	prefixedPaths := make([]string, len(filePaths))
+	for i, filePath := range filePaths {
+		prefixedPaths[i] = p.getPrefix() + "/" + p.toRelSlash(filePath)
+	}
+	return p.store.DeleteByFiles(ctx, prefixedPaths)
+}
+
+// DeleteChunks deletes chunks by the prefixed IDs the store returns them
+// with.
+func (p *projectPrefixStore) DeleteChunks(ctx context.Context, ids []string) error {
+	return p.store.DeleteChunks(ctx, ids)
+}
+
+func (p *projectPrefixStore) DeleteByProject(ctx context.Context, project string) error {
+	return p.store.DeleteByProject(ctx, p.getPrefix()+"/"+p.toRelSlash(project))
+}
+
 func (p *projectPrefixStore) Search(ctx context.Context, queryVector []float32, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
 	return p.store.Search(ctx, queryVector, limit, opts)
 }
@@ -2939,8 +3763,22 @@ func (p *projectPrefixStore) GetDocument(ctx context.Context, filePath string) (
 }
 
 func (p *projectPrefixStore) SaveDocument(ctx context.Context, doc store.Document) error {
-	doc.Path = p.getPrefix() + "/" + p.toRelSlash(doc.Path)
-	return p.store.SaveDocument(ctx, doc)
+	return p.store.SaveDocument(ctx, p.prefixDocument(doc))
+}
+
+func (p *projectPrefixStore) prefixDocument(doc store.Document) store.Document {
+	relPath := p.toRelSlash(doc.Path)
+	doc.Path = p.getPrefix() + "/" + relPath
+	// Chunk IDs must match the prefixed IDs written by SaveChunks, since the
+	// GOB store finds and deletes a file's chunks through them.
+	if len(doc.ChunkIDs) > 0 {
+		ids := make([]string, len(doc.ChunkIDs))
+		for i, id := range doc.ChunkIDs {
+			ids[i] = prefixChunkID(id, relPath, doc.Path)
+		}
+		doc.ChunkIDs = ids
+	}
+	return doc
 }
 
 func (p *projectPrefixStore) DeleteDocument(ctx context.Context, filePath string) error {
@@ -2983,3 +3821,120 @@ func (p *projectPrefixStore) GetChunksForFile(ctx context.Context, filePath stri
 func (p *projectPrefixStore) GetAllChunks(ctx context.Context) ([]store.Chunk, error) {
 	return p.store.GetAllChunks(ctx)
 }
+
+// reindexWorkspaceProject checks every file of project against the workspace
+// store and indexes the ones that changed, regardless of the project's last
+// index time. Files deleted from the project are removed from the store.
+func reindexWorkspaceProject(ctx context.Context, ws *config.Workspace, project config.ProjectEntry) (*indexer.IndexStats, error) {
+	if _, err := os.Stat(project.Path); err != nil {
+		return nil, fmt.Errorf("project path is not accessible: %s (%s)", project.Name, project.Path)
+	}
+
+	projectCfg := config.DefaultConfig()
+	if config.Exists(project.Path) {
+		loadedCfg, err := config.Load(project.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config for %s: %w", project.Name, err)
+		}
+		projectCfg = loadedCfg
+	}
+
+	emb, err := initializeEmbedder(ctx, &config.Config{Embedder: ws.Embedder})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	defer emb.Close()
+
+	st, err := openWorkspaceStore(ctx, ws)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	pi, err := newWorkspaceProjectIndexer(ws, project, projectCfg, emb, st, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	stats, err := pi.idx.IndexAll(ctx)
+	pi.cost.scanComplete(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reindex %s: %w", project.Name, err)
+	}
+	saveBudgetExclusions(project.Path, stats)
+	if err := st.Persist(ctx); err != nil {
+		return nil, fmt.Errorf("failed to persist index: %w", err)
+	}
+	return stats, nil
+}
+
+// workspaceProjectIndexer is the scanner and indexer of a workspace project,
+// which index it into the workspace store.
+type workspaceProjectIndexer struct {
+	ignore      *indexer.IgnoreMatcher
+	scanner     *indexer.Scanner
+	processor   *framework.ProcessorRegistry
+	vectorStore store.VectorStore
+	cost        *costTracker
+	idx         *indexer.Indexer
+}
+
+// newWorkspaceProjectIndexer builds the indexer of project from cfg, its own
+// configuration, and its workspace overrides. It embeds with emb, the
+// workspace embedder, into sharedStore, the workspace store.
+func newWorkspaceProjectIndexer(ws *config.Workspace, project config.ProjectEntry, cfg *config.Config, emb embedder.Embedder, sharedStore store.VectorStore, lastIndexTime time.Time) (*workspaceProjectIndexer, error) {
+	indexCfg := project.WithOverrides(cfg)
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(project.Path, indexCfg.Ignore, indexCfg.ExternalGitignore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+
+	scanner := indexer.NewScanner(project.Path, ignoreMatcher)
+	scanner.SetFollowSymlinks(indexCfg.Indexer.FollowSymlinks)
+	scanner.SetFileHandlers(buildFileHandlers(indexCfg))
+	scanner.SetContentRules(buildContentRules(indexCfg))
+	scanner.SetSparseCheckout(loadSparseCheckout(project.Path, indexCfg))
+	chunker := indexer.NewChunker(indexCfg.Chunking.Size, indexCfg.Chunking.Overlap)
+	processorRegistry := buildFrameworkRegistry(indexCfg)
+	vectorStore := &projectPrefixStore{
+		store:         sharedStore,
+		workspaceName: ws.Name,
+		projectName:   project.Name,
+		projectPath:   project.Path,
+	}
+	// The shared embedder is counted per project, whose spend file records
+	// the workspace embedder's spend on it.
+	cost := newCostTracker(project.Path, &config.Config{Embedder: ws.Embedder})
+	idx := cost.newIndexer(project.Path, indexCfg, vectorStore, emb, nil, chunker, scanner, lastIndexTime, processorRegistry)
+	idx.SetContextualizer(newContextualizerFromConfig(indexCfg, project.Path))
+	idx.SetBudget(buildIndexBudget(indexCfg))
+	idx.SetChangeDetection(indexCfg.Indexer.ChangeDetection)
+	idx.SetTodoStore(loadTodoStore(context.Background(), project.Path))
+
+	return &workspaceProjectIndexer{
+		ignore:      ignoreMatcher,
+		scanner:     scanner,
+		processor:   processorRegistry,
+		vectorStore: vectorStore,
+		cost:        cost,
+		idx:         idx,
+	}, nil
+}
+
+// openWorkspaceStore opens the store of ws and records the embedder its
+// vectors come from, refusing a store filled by another embedder.
+func openWorkspaceStore(ctx context.Context, ws *config.Workspace) (store.VectorStore, error) {
+	st, err := store.NewFromWorkspaceConfig(ctx, ws)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize store: %w", err)
+	}
+	metaPath, err := config.GetWorkspaceIndexMetadataPath(ws.Name)
+	if err != nil {
+		st.Close()
+		return nil, err
+	}
+	if err := stampIndexMetadata(ctx, st, metaPath, ws.Embedder); err != nil {
+		st.Close()
+		return nil, fmt.Errorf("workspace %s: %w", ws.Name, err)
+	}
+	return st, nil
+}
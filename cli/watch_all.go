@@ -0,0 +1,443 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/daemon"
+	"github.com/yoanbernabeu/grepai/git"
+	"github.com/yoanbernabeu/grepai/watcher"
+)
+
+// watchAllTarget is one watch session of 'grepai watch --all': either a
+// workspace or a standalone project, whose session also watches its linked
+// worktrees.
+type watchAllTarget struct {
+	workspace   *config.Workspace
+	projectRoot string
+	worktreeID  string
+}
+
+func (t watchAllTarget) label() string {
+	if t.workspace != nil {
+		return "workspace " + t.workspace.Name
+	}
+	return t.projectRoot
+}
+
+// collectWatchAllTargets returns every workspace with a supported backend,
+// followed by the projects found under root, at most maxDepth levels deep.
+// Projects that belong to a workspace are left to its session. Linked
+// worktrees resolve to their main worktree, whose session covers them, so
+// each repository is watched once.
+func collectWatchAllTargets(root string, maxDepth int) ([]watchAllTarget, error) {
+	var targets []watchAllTarget
+	members := make(map[string]bool)
+
+	wsCfg, err := config.LoadWorkspaceConfig()
+	if err != nil {
+		return nil, err
+	}
+	if wsCfg != nil {
+		names := wsCfg.ListWorkspaces()
+		sort.Strings(names)
+		for _, name := range names {
+			ws, err := wsCfg.GetWorkspace(name)
+			if err != nil {
+				return nil, err
+			}
+			if err := config.ValidateWorkspaceBackend(ws); err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping workspace %s: %v\n", name, err)
+				continue
+			}
+			for _, project := range ws.Projects {
+				members[projectPathKey(project.Path)] = true
+			}
+			targets = append(targets, watchAllTarget{workspace: ws})
+		}
+	}
+
+	roots, err := findProjectRoots(root, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	seenRoots := make(map[string]bool, len(roots))
+	seenRepos := make(map[string]bool, len(roots))
+	for _, projectRoot := range roots {
+		if members[projectPathKey(projectRoot)] {
+			continue
+		}
+		var worktreeID string
+		if info, err := git.Detect(projectRoot); err == nil {
+			worktreeID = info.WorktreeID
+			if info.IsWorktree {
+				if _, err := os.Stat(config.GetConfigPath(info.MainWorktree)); err == nil {
+					projectRoot = info.MainWorktree
+				}
+			}
+		}
+		key := projectPathKey(projectRoot)
+		if seenRoots[key] || (worktreeID != "" && seenRepos[worktreeID]) {
+			continue
+		}
+		seenRoots[key] = true
+		if worktreeID != "" {
+			seenRepos[worktreeID] = true
+		}
+		targets = append(targets, watchAllTarget{projectRoot: projectRoot, worktreeID: worktreeID})
+	}
+
+	return targets, nil
+}
+
+func runWatchAll(logDir string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	targets, err := collectWatchAllTargets(cwd, watchAllMaxDepth)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println("No workspaces or projects to watch.")
+		fmt.Printf("Projects are searched for under %s.\n", cwd)
+		return nil
+	}
+
+	switch {
+	case watchStatus:
+		for _, t := range targets {
+			if pid := watchAllTargetPID(logDir, t); pid > 0 {
+				fmt.Printf("running (PID %d)  %s\n", pid, t.label())
+			} else {
+				fmt.Printf("stopped           %s\n", t.label())
+			}
+		}
+		return nil
+	case watchStop:
+		return stopWatchAll(logDir, targets)
+	case watchBackground:
+		return startBackgroundWatchAll(logDir, targets)
+	}
+
+	return runWatchAllForeground(logDir, targets)
+}
+
+// stopWatchAll stops the processes watching targets: the 'grepai watch
+// --all' process, along with watchers started separately in a project or
+// workspace. A process failing to stop does not prevent the others from
+// being stopped.
+func stopWatchAll(logDir string, targets []watchAllTarget) error {
+	var pids []int
+	labels := make(map[int][]string)
+	for _, t := range targets {
+		pid := watchAllTargetPID(logDir, t)
+		if pid == 0 {
+			continue
+		}
+		if _, ok := labels[pid]; !ok {
+			pids = append(pids, pid)
+		}
+		labels[pid] = append(labels[pid], t.label())
+	}
+	if len(pids) == 0 {
+		fmt.Println("No background watcher is running")
+		return nil
+	}
+
+	var errs []error
+	for _, pid := range pids {
+		fmt.Printf("Stopping watcher (PID %d): %s\n", pid, strings.Join(labels[pid], ", "))
+		if err := stopWatchAllProcess(pid); err != nil {
+			errs = append(errs, fmt.Errorf("PID %d: %w", pid, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	fmt.Println("Background watchers stopped")
+	return nil
+}
+
+// stopWatchAllProcess asks the process to stop and waits for it to exit.
+func stopWatchAllProcess(pid int) error {
+	if err := daemon.StopProcess(pid); err != nil {
+		return fmt.Errorf("failed to stop process: %w", err)
+	}
+	const stopTimeout = 30 * time.Second
+	const pollInterval = 250 * time.Millisecond
+	deadline := time.Now().Add(stopTimeout)
+	for time.Now().Before(deadline) {
+		if !daemon.IsProcessRunning(pid) {
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+	return fmt.Errorf("process did not stop within %v", stopTimeout)
+}
+
+// startBackgroundWatchAll starts a background 'grepai watch --all' process
+// and waits until it watches every target that was not already watched.
+func startBackgroundWatchAll(logDir string, targets []watchAllTarget) error {
+	var pending []watchAllTarget
+	for _, t := range targets {
+		if pid := watchAllTargetPID(logDir, t); pid > 0 {
+			fmt.Printf("already running (PID %d)  %s\n", pid, t.label())
+			continue
+		}
+		pending = append(pending, t)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var args []string
+	if watchLogDir != "" {
+		args = append(args, "--log-dir", watchLogDir)
+	}
+	args = append(args, "--max-depth", fmt.Sprint(watchAllMaxDepth))
+	if watchProgress == watchProgressNone {
+		args = append(args, "--progress", watchProgressNone)
+	}
+	if watchQuickStart {
+		args = append(args, "--quick-start")
+	}
+	childPID, exitCh, err := daemon.SpawnWatchAllBackground(logDir, args)
+	if err != nil {
+		return fmt.Errorf("failed to start background process: %w", err)
+	}
+	logFile := daemon.GetWatchAllLogFile(logDir)
+
+	// Wait until the child has claimed every pending target. Targets it
+	// skips, such as a second project outside a git repository, never are.
+	const startupTimeout = 30 * time.Second
+	const pollInterval = 250 * time.Millisecond
+	deadline := time.Now().Add(startupTimeout)
+	for time.Now().Before(deadline) {
+		claimed := 0
+		for _, t := range pending {
+			if watchAllTargetPID(logDir, t) == childPID {
+				claimed++
+			}
+		}
+		if claimed == len(pending) {
+			break
+		}
+		select {
+		case <-exitCh:
+			return fmt.Errorf("background process failed to start (check logs at %s)", logFile)
+		default:
+		}
+		time.Sleep(pollInterval)
+	}
+
+	for _, t := range pending {
+		if watchAllTargetPID(logDir, t) == childPID {
+			fmt.Printf("started (PID %d)  %s\n", childPID, t.label())
+		} else {
+			fmt.Printf("not started (see %s)  %s\n", logFile, t.label())
+		}
+	}
+	fmt.Printf("\nLogs: %s\n", logFile)
+	fmt.Printf("Use 'grepai watch --all --status' to check status\n")
+	fmt.Printf("Use 'grepai watch --all --stop' to stop all watchers\n")
+	return nil
+}
+
+// runWatchAllForeground watches every target not already watched by
+// another process, each in its own session sharing this process, until it
+// is interrupted. A session that fails is restarted with a backoff without
+// affecting the others.
+func runWatchAllForeground(logDir string, targets []watchAllTarget) error {
+	isBackgroundChild := os.Getenv("GREPAI_BACKGROUND") == "1"
+	if isBackgroundChild {
+		log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds)
+		log.SetPrefix("[grepai-watch-all] ")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	stopCh := daemon.StopChannel()
+	go func() {
+		select {
+		case <-sigChan:
+			if !isBackgroundChild {
+				fmt.Println("\nShutting down...")
+			} else {
+				log.Println("Shutting down...")
+			}
+		case <-stopCh:
+			log.Println("Stop file detected, shutting down...")
+		case <-ctx.Done():
+			return
+		}
+		cancel()
+	}()
+
+	var wg sync.WaitGroup
+	watched := 0
+	legacyClaimed := false
+	for _, t := range targets {
+		if pid := watchAllTargetPID(logDir, t); pid > 0 {
+			log.Printf("Skipping %s: already watched by PID %d", t.label(), pid)
+			continue
+		}
+		// Projects outside a git repository share a single PID file.
+		if t.workspace == nil && t.worktreeID == "" {
+			if legacyClaimed {
+				log.Printf("Skipping %s: only one project outside a git repository can be watched", t.label())
+				continue
+			}
+			legacyClaimed = true
+		}
+		release, err := claimWatchAllTarget(logDir, t)
+		if err != nil {
+			log.Printf("Skipping %s: %v", t.label(), err)
+			continue
+		}
+		watched++
+		wg.Add(1)
+		go func(t watchAllTarget) {
+			defer wg.Done()
+			defer release()
+			superviseWatchAllTarget(ctx, logDir, t, isBackgroundChild)
+		}(t)
+	}
+	if watched == 0 {
+		return fmt.Errorf("no workspace or project left to watch")
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// superviseWatchAllTarget runs the watch session of t until ctx is
+// canceled, restarting it with a backoff whenever it fails.
+func superviseWatchAllTarget(ctx context.Context, logDir string, t watchAllTarget, isBackgroundChild bool) {
+	for attempt := 1; ; attempt++ {
+		err := runWatchAllSession(ctx, logDir, t, isBackgroundChild)
+		if ctx.Err() != nil {
+			return
+		}
+		backoff := computeWatchSessionRetryBackoff(attempt)
+		log.Printf("Watcher of %s stopped: %v; restarting in %s", t.label(), err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// runWatchAllSession watches t until ctx is canceled or the session fails.
+func runWatchAllSession(ctx context.Context, logDir string, t watchAllTarget, isBackgroundChild bool) error {
+	if t.workspace != nil {
+		return runWorkspaceWatchSession(ctx, t.workspace, isBackgroundChild, func() error {
+			return daemon.WriteWorkspaceReadyFile(logDir, t.workspace.Name)
+		})
+	}
+
+	cfg, err := config.Load(t.projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	emb, err := initializeEmbedder(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer emb.Close()
+
+	var readyOnce sync.Once
+	err = runDynamicWatchSupervisor(
+		ctx,
+		t.projectRoot,
+		emb,
+		withWatchSupervisorBackgroundChild(isBackgroundChild),
+		withWatchSupervisorNotifier(watcher.NewNotifier(cfg.Watch.Notifications)),
+		withWatchSupervisorThrottle(startWatchPowerThrottle(ctx, cfg.Watch.PowerProfile, logWatchPowerThrottle(t.label(), isBackgroundChild))),
+		withWatchSupervisorInitialReadyObserver(func(int) {
+			readyOnce.Do(func() {
+				var readyErr error
+				if t.worktreeID != "" {
+					readyErr = daemon.WriteWorktreeReadyFile(logDir, t.worktreeID)
+				} else {
+					readyErr = daemon.WriteReadyFile(logDir)
+				}
+				if readyErr != nil {
+					log.Printf("Warning: failed to write ready file for %s: %v", t.label(), readyErr)
+				}
+			})
+		}),
+	)
+	if err == nil && ctx.Err() == nil {
+		err = errors.New("watcher exited")
+	}
+	return err
+}
+
+// claimWatchAllTarget writes the PID file of t's session, so that 'grepai
+// watch --status' and '--stop' run in the project or workspace find this
+// process. The returned function removes it and the session's ready file.
+func claimWatchAllTarget(logDir string, t watchAllTarget) (func(), error) {
+	var write func() error
+	var remove func() error
+	switch {
+	case t.workspace != nil:
+		name := t.workspace.Name
+		write = func() error { return daemon.WriteWorkspacePIDFile(logDir, name) }
+		remove = func() error {
+			_ = daemon.RemoveWorkspaceReadyFile(logDir, name)
+			return daemon.RemoveWorkspacePIDFile(logDir, name)
+		}
+	case t.worktreeID != "":
+		write = func() error { return daemon.WriteWorktreePIDFile(logDir, t.worktreeID) }
+		remove = func() error {
+			_ = daemon.RemoveWorktreeReadyFile(logDir, t.worktreeID)
+			return daemon.RemoveWorktreePIDFile(logDir, t.worktreeID)
+		}
+	default:
+		write = func() error { return daemon.WritePIDFile(logDir) }
+		remove = func() error {
+			_ = daemon.RemoveReadyFile(logDir)
+			return daemon.RemovePIDFile(logDir)
+		}
+	}
+	if err := write(); err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := remove(); err != nil {
+			log.Printf("Warning: failed to remove PID file of %s on exit: %v", t.label(), err)
+		}
+	}, nil
+}
+
+// watchAllTargetPID returns the PID of the process watching the target, or
+// 0 when it is not watched.
+func watchAllTargetPID(logDir string, t watchAllTarget) int {
+	var pid int
+	switch {
+	case t.workspace != nil:
+		pid, _ = daemon.GetRunningWorkspacePID(logDir, t.workspace.Name)
+	case t.worktreeID != "":
+		pid, _ = daemon.GetRunningWorktreePID(logDir, t.worktreeID)
+	default:
+		pid, _ = daemon.GetRunningPID(logDir)
+	}
+	return pid
+}
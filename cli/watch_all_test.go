@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestCollectWatchAllTargets(t *testing.T) {
+	defer setTestHomeDirCLI(t, t.TempDir())()
+
+	root := t.TempDir()
+	standalone := filepath.Join(root, "api")
+	member := filepath.Join(root, "web")
+	writeTestProject(t, standalone)
+	writeTestProject(t, member)
+
+	wsCfg := config.DefaultWorkspaceConfig()
+	wsCfg.Workspaces["shared"] = config.Workspace{
+		Name:     "shared",
+		Store:    config.StoreConfig{Backend: "qdrant"},
+		Projects: []config.ProjectEntry{{Name: "web", Path: member}},
+	}
+	wsCfg.Workspaces["broken"] = config.Workspace{Name: "broken", Store: config.StoreConfig{Backend: "gob"}}
+	if err := config.SaveWorkspaceConfig(wsCfg); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := collectWatchAllTargets(root, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, target := range targets {
+		labels = append(labels, target.label())
+	}
+	if want := []string{"workspace shared", standalone}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("targets = %v, want %v", labels, want)
+	}
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/watcher"
+)
+
+// initialScanSliceFiles is how many files the initial scan indexes per
+// queue task. File events wait for at most one slice.
+const initialScanSliceFiles = 32
+
+// scanEventSource feeds file events into the initial scan so that files
+// saved while it runs are indexed ahead of the remaining backfill.
+type scanEventSource struct {
+	events <-chan watcher.FileEvent
+	handle func(event watcher.FileEvent)
+}
+
+// queueEvents pushes the events waiting on the source as high-priority
+// tasks of queue and reports whether there were any.
+func (s *scanEventSource) queueEvents(queue *watcher.WorkQueue) bool {
+	if s == nil {
+		return false
+	}
+	queued := false
+	for {
+		select {
+		case event := <-s.events:
+			queue.Push(watcher.PriorityHigh, func(context.Context) {
+				s.handle(event)
+			})
+			queued = true
+		default:
+			return queued
+		}
+	}
+}
+
+// indexAllWithEventPriority performs a full index as low-priority slices on
+// a work queue. Before each slice, the events waiting on source are pushed
+// as high-priority tasks and handled first. Events still waiting when the
+// backfill finishes are handled before it returns; later ones stay on the
+// channel for the watch loop.
+func indexAllWithEventPriority(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, source *scanEventSource, throttle *watcher.Throttle, onProgress indexer.ProgressCallback, onBatchProgress indexer.BatchProgressCallback) (*indexer.IndexStats, error) {
+	start := time.Now()
+	files, skipped, err := scanner.ScanMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan files: %w", err)
+	}
+	stats := &indexer.IndexStats{
+		FilesSkipped: len(skipped),
+		ScannedFiles: files,
+	}
+
+	// Removing deleted files first keeps the removal independent of files
+	// that events create or delete while the backfill runs.
+	removed, err := idx.RemoveMissingFiles(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+	stats.FilesRemoved = removed
+
+	files, stats.Excluded, err = idx.ApplyBudget(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := watcher.NewWorkQueue(watcher.DefaultHighPriorityBurst)
+	var sliceErr error
+	completedChunks := 0
+	// Slices are taken in order by whichever low-priority task runs next,
+	// so a task that yields while the power throttle is engaged does not
+	// reorder the backfill.
+	nextOffset := 0
+	var indexSlice func(ctx context.Context)
+	indexSlice = func(ctx context.Context) {
+		if sliceErr != nil {
+			return
+		}
+		if source.queueEvents(queue) {
+			// Yield to the events just queued.
+			queue.Push(watcher.PriorityLow, indexSlice)
+			return
+		}
+		if throttle.Throttled() {
+			// Yield to file events and check again shortly.
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchThrottleRecheckInterval):
+			}
+			queue.Push(watcher.PriorityLow, indexSlice)
+			return
+		}
+		sliceOffset := nextOffset
+		end := min(sliceOffset+initialScanSliceFiles, len(files))
+		slice := files[sliceOffset:end]
+		nextOffset = end
+		sliceStats, err := idx.IndexFilesWithBatchProgress(ctx, slice,
+			func(info indexer.ProgressInfo) {
+				if onProgress != nil {
+					info.Current += sliceOffset
+					info.Total = len(files)
+					onProgress(info)
+				}
+			},
+			func(info indexer.BatchProgressInfo) {
+				if onBatchProgress != nil {
+					info.CompletedChunks += completedChunks
+					info.TotalChunks += completedChunks
+					onBatchProgress(info)
+				}
+			},
+		)
+		if err != nil {
+			sliceErr = err
+			return
+		}
+		stats.FilesIndexed += sliceStats.FilesIndexed
+		stats.FilesSkipped += sliceStats.FilesSkipped
+		stats.ChunksCreated += sliceStats.ChunksCreated
+		completedChunks += sliceStats.ChunksCreated
+	}
+	for offset := 0; offset < len(files); offset += initialScanSliceFiles {
+		queue.Push(watcher.PriorityLow, indexSlice)
+	}
+
+	runErr := queue.Run(ctx)
+	if runErr == nil && source.queueEvents(queue) {
+		// Events received while the last slice ran.
+		runErr = queue.Run(ctx)
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+	if sliceErr != nil {
+		return nil, sliceErr
+	}
+
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
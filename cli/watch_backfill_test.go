@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/watcher"
+)
+
+func TestIndexAllWithEventPriority_EventPreemptsBackfill(t *testing.T) {
+	ctx := context.Background()
+	projectRoot := t.TempDir()
+
+	fileCount := initialScanSliceFiles*2 + 1
+	for i := 0; i < fileCount; i++ {
+		path := filepath.Join(projectRoot, fmt.Sprintf("file%03d.go", i))
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("package main\n\nfunc F%d() {}\n", i)), 0644); err != nil {
+			t.Fatalf("failed to create source file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := indexer.NewScanner(projectRoot, ignoreMatcher)
+	vecStore := store.NewGOBStore(filepath.Join(projectRoot, "index.gob"))
+	if err := vecStore.SaveDocument(ctx, store.Document{Path: "deleted.go", Hash: "hash"}); err != nil {
+		t.Fatalf("failed to seed store: %v", err)
+	}
+	idx := indexer.NewIndexer(projectRoot, vecStore, &noOpEmbedder{}, indexer.NewChunker(512, 50), scanner, time.Time{})
+
+	events := make(chan watcher.FileEvent, 1)
+
+	progressed := 0
+	handledAt := -1
+	source := &scanEventSource{
+		events: events,
+		handle: func(event watcher.FileEvent) {
+			handledAt = progressed
+		},
+	}
+	onProgress := func(info indexer.ProgressInfo) {
+		if progressed == 0 {
+			// A file saved while the first slice runs.
+			events <- watcher.FileEvent{Type: watcher.EventModify, Path: "saved.go"}
+		}
+		progressed = info.Current
+		if info.Total != fileCount {
+			t.Errorf("expected progress total %d, got %d", fileCount, info.Total)
+		}
+	}
+
+	stats, err := indexAllWithEventPriority(ctx, idx, scanner, source, nil, onProgress, nil)
+	if err != nil {
+		t.Fatalf("indexAllWithEventPriority failed: %v", err)
+	}
+	if handledAt != initialScanSliceFiles {
+		t.Errorf("expected the event to be handled right after the first slice (%d files), handled after %d/%d files", initialScanSliceFiles, handledAt, fileCount)
+	}
+	if stats.FilesIndexed != fileCount {
+		t.Errorf("expected %d files indexed, got %d", fileCount, stats.FilesIndexed)
+	}
+	if stats.FilesRemoved != 1 {
+		t.Errorf("expected 1 file removed, got %d", stats.FilesRemoved)
+	}
+	if progressed != fileCount {
+		t.Errorf("expected progress to reach %d, got %d", fileCount, progressed)
+	}
+}
+
+func TestIndexAllWithEventPriority_HandlesEventsOfLastSlice(t *testing.T) {
+	ctx := context.Background()
+	projectRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectRoot, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := indexer.NewScanner(projectRoot, ignoreMatcher)
+	vecStore := store.NewGOBStore(filepath.Join(projectRoot, "index.gob"))
+	idx := indexer.NewIndexer(projectRoot, vecStore, &noOpEmbedder{}, indexer.NewChunker(512, 50), scanner, time.Time{})
+
+	events := make(chan watcher.FileEvent, 1)
+	handled := 0
+	source := &scanEventSource{
+		events: events,
+		handle: func(event watcher.FileEvent) { handled++ },
+	}
+	onProgress := func(info indexer.ProgressInfo) {
+		if handled == 0 && len(events) == 0 {
+			events <- watcher.FileEvent{Type: watcher.EventModify, Path: "saved.go"}
+		}
+	}
+
+	if _, err := indexAllWithEventPriority(ctx, idx, scanner, source, nil, onProgress, nil); err != nil {
+		t.Fatalf("indexAllWithEventPriority failed: %v", err)
+	}
+	if handled != 1 || len(events) != 0 {
+		t.Errorf("expected the event received during the last slice to be handled before returning, handled %d", handled)
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/embedder"
 	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/watcher"
 )
 
 type watchLifecycleEvent struct {
@@ -63,6 +65,7 @@ func steadyWatchSessionRunner(
 	_ func(step string, current, total int),
 	_ watchActivityObserver,
 	_ watchStatsObserver,
+	_ *watcher.Throttle,
 ) error {
 	onReady()
 	<-ctx.Done()
@@ -137,6 +140,7 @@ func TestDynamicWatch_RemoveLinkedWorktreeDuringRun(t *testing.T) {
 		_ func(step string, current, total int),
 		_ watchActivityObserver,
 		_ watchStatsObserver,
+		_ *watcher.Throttle,
 	) error {
 		onReady()
 		<-ctx.Done()
@@ -251,6 +255,7 @@ func TestDynamicWatch_LinkedFailureIsIsolatedWithRetry(t *testing.T) {
 		_ func(step string, current, total int),
 		_ watchActivityObserver,
 		_ watchStatsObserver,
+		_ *watcher.Throttle,
 	) error {
 		if projectRoot == mainRoot {
 			onReady()
@@ -351,6 +356,7 @@ func TestDynamicWatch_MainFailureStopsAll(t *testing.T) {
 		_ func(step string, current, total int),
 		_ watchActivityObserver,
 		_ watchStatsObserver,
+		_ *watcher.Throttle,
 	) error {
 		if projectRoot == mainRoot {
 			onReady()
@@ -404,6 +410,7 @@ func TestDynamicWatch_InitialReadySelector_MainOnly(t *testing.T) {
 		_ func(step string, current, total int),
 		_ watchActivityObserver,
 		_ watchStatsObserver,
+		_ *watcher.Throttle,
 	) error {
 		if projectRoot == mainRoot {
 			onReady()
@@ -491,3 +498,53 @@ func TestDynamicWatch_ContextCancelRemainsGracefulUnderRace(t *testing.T) {
 		}
 	}
 }
+
+func TestDynamicWatch_SessionsShareThrottle(t *testing.T) {
+	mainRoot := canonicalPath("/tmp/main")
+	linkedRoot := canonicalPath("/tmp/wt-t")
+	throttle := watcher.NewThrottle(config.PowerProfileSaver)
+
+	var mu sync.Mutex
+	received := make(map[string]*watcher.Throttle)
+	runner := func(
+		_ context.Context,
+		projectRoot string,
+		_ embedder.Embedder,
+		_ bool,
+		onReady func(),
+		_ watchSessionEventObserver,
+		_ func(current, total int, file string),
+		_ func(info indexer.BatchProgressInfo),
+		_ func(step string, current, total int),
+		_ watchActivityObserver,
+		_ watchStatsObserver,
+		throttle *watcher.Throttle,
+	) error {
+		mu.Lock()
+		received[projectRoot] = throttle
+		mu.Unlock()
+		onReady()
+		return errors.New("session done")
+	}
+
+	_ = runDynamicWatchSupervisor(
+		context.Background(),
+		mainRoot,
+		nil,
+		withWatchSupervisorSessionRunner(runner),
+		withWatchSupervisorDiscoverWorktrees(func(string) []string {
+			return []string{linkedRoot}
+		}),
+		withWatchSupervisorInitialLinkedWorktrees([]string{linkedRoot}),
+		withWatchSupervisorThrottle(throttle),
+	)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received[mainRoot] != throttle {
+		t.Errorf("expected the main session to get the supervisor's throttle, got %v", received[mainRoot])
+	}
+	if got, ok := received[linkedRoot]; ok && got != throttle {
+		t.Errorf("expected the linked session to get the supervisor's throttle, got %v", got)
+	}
+}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -100,7 +102,7 @@ func TestRunInitialScan_SkipsSymbolExtractionWhenContentHashMatches(t *testing.T
 	}
 
 	extractor := trace.NewRegexExtractor()
-	if _, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, []string{".go"}, time.Time{}, true, nil, nil); err != nil {
+	if _, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, []string{".go"}, time.Time{}, true, nil, nil, nil, nil); err != nil {
 		t.Fatalf("runInitialScan failed: %v", err)
 	}
 
@@ -169,7 +171,7 @@ func TestRunInitialScan_SkipsIndexedFileByLastIndexTime(t *testing.T) {
 
 	lastIndexTime := time.Now().Add(1 * time.Hour)
 	extractor := trace.NewRegexExtractor()
-	if _, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, []string{".go"}, lastIndexTime, true, nil, nil); err != nil {
+	if _, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, []string{".go"}, lastIndexTime, true, nil, nil, nil, nil); err != nil {
 		t.Fatalf("runInitialScan failed: %v", err)
 	}
 
@@ -257,6 +259,7 @@ func TestHandleFileEvent_SkipsUnchangedFile(t *testing.T) {
 		cfg,
 		&lastWrite,
 		nil,
+		nil,
 		watcher.FileEvent{Type: watcher.EventModify, Path: "main.go"},
 		nil,
 		nil,
@@ -341,7 +344,7 @@ func TestHandleWorkspaceFileEvent_SkipsUnchangedFile(t *testing.T) {
 	cfg := config.DefaultConfig()
 	var lastConfigWrite time.Time
 
-	handleFileEvent(ctx, idx, scanner, extractor, nil, nil, wrappedStore, nil, projectPath, cfg, &lastConfigWrite, nil, watcher.FileEvent{
+	handleFileEvent(ctx, idx, scanner, extractor, nil, nil, wrappedStore, nil, projectPath, cfg, &lastConfigWrite, nil, nil, watcher.FileEvent{
 		Type: watcher.EventModify,
 		Path: "proj/main.go",
 	}, nil, nil)
@@ -411,6 +414,7 @@ func TestHandleFileEvent_IndexesChangedFileAndUpdatesSymbols(t *testing.T) {
 		cfg,
 		&lastWrite,
 		nil,
+		nil,
 		watcher.FileEvent{Type: watcher.EventModify, Path: "main.go"},
 		nil,
 		nil,
@@ -486,6 +490,7 @@ func TestHandleFileEvent_DeleteRemovesIndexAndSymbols(t *testing.T) {
 		cfg,
 		&lastWrite,
 		nil,
+		nil,
 		watcher.FileEvent{Type: watcher.EventDelete, Path: "main.go"},
 		nil,
 		nil,
@@ -503,6 +508,64 @@ func TestHandleFileEvent_DeleteRemovesIndexAndSymbols(t *testing.T) {
 	}
 }
 
+// deleteRecordingStore records the batches of files whose chunks are
+// deleted.
+type deleteRecordingStore struct {
+	*store.GOBStore
+	batches [][]string
+}
+
+func (s *deleteRecordingStore) DeleteByFile(ctx context.Context, filePath string) error {
+	s.batches = append(s.batches, []string{filePath})
+	return s.GOBStore.DeleteByFile(ctx, filePath)
+}
+
+func (s *deleteRecordingStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	s.batches = append(s.batches, filePaths)
+	return s.GOBStore.DeleteByFiles(ctx, filePaths)
+}
+
+func TestHandleFileEvents_RemovesQueuedDeletionsInOneBatch(t *testing.T) {
+	ctx := context.Background()
+	projectRoot := t.TempDir()
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := indexer.NewScanner(projectRoot, ignoreMatcher)
+	vecStore := &deleteRecordingStore{GOBStore: store.NewGOBStore(filepath.Join(projectRoot, "index.gob"))}
+	idx := indexer.NewIndexer(projectRoot, vecStore, &countingEmbedder{}, indexer.NewChunker(512, 50), scanner, time.Time{})
+	for _, path := range []string{"a.go", "b.go", "c.go"} {
+		if err := vecStore.SaveDocument(ctx, store.Document{Path: path, Hash: "hash"}); err != nil {
+			t.Fatalf("failed to seed document: %v", err)
+		}
+	}
+	symbolStore := trace.NewGOBSymbolStore(filepath.Join(projectRoot, "symbols.gob"))
+	defer symbolStore.Close()
+
+	queue := make(chan watcher.FileEvent, 4)
+	queue <- watcher.FileEvent{Type: watcher.EventRename, Path: "b.go"}
+	queue <- watcher.FileEvent{Type: watcher.EventModify, Path: "d.go"}
+	queue <- watcher.FileEvent{Type: watcher.EventDelete, Path: "c.go"}
+	events := queuedEvents(watcher.FileEvent{Type: watcher.EventDelete, Path: "a.go"}, queue, isRemovalEvent)
+	if len(events) != 3 || events[2].Path != "d.go" || len(queue) != 1 {
+		t.Fatalf("expected the removals up to the first other event, got %+v", events)
+	}
+
+	lastWrite := time.Time{}
+	handleFileEvents(ctx, idx, scanner, trace.NewRegexExtractor(), symbolStore, nil, vecStore, []string{".go"}, projectRoot, config.DefaultConfig(), &lastWrite, nil, nil, events, nil, nil)
+
+	if len(vecStore.batches) != 1 || strings.Join(vecStore.batches[0], ",") != "a.go,b.go" {
+		t.Errorf("expected one batch deletion of a.go and b.go, got %v", vecStore.batches)
+	}
+	for _, path := range []string{"a.go", "b.go"} {
+		if doc, _ := vecStore.GetDocument(ctx, path); doc != nil {
+			t.Errorf("expected %s to be removed", path)
+		}
+	}
+}
+
 func TestEmitInitialStatsSnapshot_ReportsExistingTotals(t *testing.T) {
 	ctx := context.Background()
 	projectRoot := t.TempDir()
@@ -566,3 +629,53 @@ func TestEmitInitialStatsSnapshot_ReportsExistingTotals(t *testing.T) {
 		t.Fatal("expected snapshot delta to be marked as Snapshot")
 	}
 }
+
+func TestWatchProject_DefersFullScanOutsideMaintenanceWindow(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write main.go: %v", err)
+	}
+
+	now := time.Now()
+	cfg := config.DefaultConfig()
+	cfg.Store.Backend = "gob"
+	dim := 8
+	cfg.Embedder.Dimensions = &dim
+	cfg.Watch.LastIndexTime = now.Add(-time.Hour)
+	cfg.Watch.MaintenanceWindow = now.Add(2*time.Hour).Format("15:04") + "-" + now.Add(3*time.Hour).Format("15:04")
+	if err := cfg.Save(root); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watchProject(ctx, root, &stubEmbedder{dim: dim}, true, func() {
+			close(ready)
+		})
+	}()
+
+	select {
+	case <-ready:
+		cancel()
+	case <-time.After(20 * time.Second):
+		cancel()
+		t.Fatal("timeout waiting for watchProject readiness")
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("watchProject returned error: %v", err)
+	}
+
+	st := store.NewGOBStore(config.GetIndexPath(root))
+	if err := st.Load(context.Background()); err != nil {
+		t.Fatalf("failed to load index: %v", err)
+	}
+	docs, err := st.ListDocuments(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 0 {
+		t.Fatalf("expected the full scan to wait for the maintenance window, got %v indexed", docs)
+	}
+}
@@ -9,10 +9,8 @@ import (
 	"github.com/yoanbernabeu/grepai/daemon"
 )
 
-const watchLogDirHintFileName = "watch-log-dir"
-
 func watchLogDirHintPath(projectRoot string) string {
-	return filepath.Join(config.GetConfigDir(projectRoot), watchLogDirHintFileName)
+	return config.GetWatchLogDirPath(projectRoot)
 }
 
 func readWatchLogDirHint(projectRoot string) (string, error) {
@@ -269,3 +269,93 @@ func TestDiscoverWorktreesForWatch_DiscoversLinkedOnly(t *testing.T) {
 		t.Fatalf("discoverWorktreesForWatch() = %v, want [%s]", discovered, wtRoot)
 	}
 }
+
+func TestWatchProject_RPGManualPolicyLeavesGraphToRebuild(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write main.go: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Store.Backend = "gob"
+	cfg.RPG.Enabled = true
+	cfg.RPG.Rebuild = config.RPGRebuildManual
+	dim := 8
+	cfg.Embedder.Dimensions = &dim
+	if err := cfg.Save(root); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watchProject(ctx, root, &stubEmbedder{dim: dim}, true, func() {
+			close(ready)
+		})
+	}()
+
+	select {
+	case <-ready:
+		cancel()
+	case <-time.After(20 * time.Second):
+		cancel()
+		t.Fatal("timeout waiting for watchProject readiness")
+	}
+
+	err := <-errCh
+	if err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatalf("watchProject returned error: %v", err)
+	}
+
+	if _, err := os.Stat(config.GetRPGIndexPath(root)); !os.IsNotExist(err) {
+		t.Fatalf("expected no RPG index under manual policy, stat err=%v", err)
+	}
+
+	t.Chdir(root)
+	if err := runRPGRebuild(rpgRebuildCmd, nil); err != nil {
+		t.Fatalf("rpg rebuild failed: %v", err)
+	}
+
+	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(root))
+	if err := rpgStore.Load(context.Background()); err != nil {
+		t.Fatalf("failed to load rebuilt RPG index: %v", err)
+	}
+	if rpgStore.GetGraph().Stats().TotalNodes == 0 {
+		t.Fatal("rebuilt RPG graph has no nodes")
+	}
+}
+
+func TestStartRPGMaintenance_ScheduledPolicyDefersEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.DefaultConfig()
+	cfg.RPG.Rebuild = config.RPGRebuildScheduled
+	rpgStore := rpg.NewGOBRPGStore(filepath.Join(t.TempDir(), "rpg.gob"))
+	encoder := newRPGEncoderFromConfig(rpgStore, cfg.RPG, t.TempDir())
+
+	manager, eventEncoder := startRPGMaintenance(ctx, "test", nil, nil, encoder, rpgStore, cfg)
+	if manager == nil {
+		t.Fatal("expected a manager under the scheduled policy")
+	}
+	if eventEncoder != nil {
+		t.Fatal("file events must not update the graph incrementally under the scheduled policy")
+	}
+
+	if manager.TakePendingRebuild() {
+		t.Fatal("expected no pending rebuild before any change")
+	}
+	manager.MarkFileDirty("main.go")
+	if !manager.TakePendingRebuild() {
+		t.Fatal("expected a pending rebuild after a change")
+	}
+	if manager.TakePendingRebuild() {
+		t.Fatal("pending rebuild should be cleared once taken")
+	}
+
+	cfg.RPG.Rebuild = config.RPGRebuildOnChange
+	if _, eventEncoder := startRPGMaintenance(ctx, "test", nil, nil, encoder, rpgStore, cfg); eventEncoder != encoder {
+		t.Fatal("expected incremental updates under the on_change policy")
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/watcher"
+)
+
+// watchThrottleRecheckInterval is how long a throttled backfill slice waits
+// before checking the power throttle again.
+const watchThrottleRecheckInterval = time.Second
+
+// startWatchPowerThrottle returns a throttle slowing down background
+// indexing according to the power profile, sampling the power state until
+// ctx is done. It returns nil when the profile never throttles. report is
+// called whenever the throttle engages or, with an empty reason, releases.
+func startWatchPowerThrottle(ctx context.Context, profile string, report func(reason string)) *watcher.Throttle {
+	throttle := watcher.NewThrottle(profile)
+	throttle.OnChange(report)
+	throttle.Start(ctx)
+	return throttle
+}
+
+// logWatchPowerThrottle returns a throttle report logging the changes of
+// the throttle of label, or printing them when not a background child.
+func logWatchPowerThrottle(label string, isBackgroundChild bool) func(reason string) {
+	return func(reason string) {
+		message := "Resuming background indexing"
+		if reason != "" {
+			message = "Throttling background indexing: " + reason
+		}
+		if label != "" {
+			message = fmt.Sprintf("%s (%s)", message, label)
+		}
+		if isBackgroundChild {
+			log.Println(message)
+			return
+		}
+		fmt.Println(message)
+	}
+}
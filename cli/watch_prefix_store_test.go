@@ -12,6 +12,9 @@ import (
 type mockVectorStore struct {
 	savedChunks           []store.Chunk
 	deletedByFilePath     string
+	deletedByFilePaths    []string
+	deletedProject        string
+	deletedChunkIDs       []string
 	searchVector          []float32
 	searchLimit           int
 	searchPathPrefix      string
@@ -38,6 +41,21 @@ func (m *mockVectorStore) DeleteByFile(_ context.Context, filePath string) error
 	return nil
 }
 
+func (m *mockVectorStore) DeleteByFiles(_ context.Context, filePaths []string) error {
+	m.deletedByFilePaths = filePaths
+	return nil
+}
+
+func (m *mockVectorStore) DeleteChunks(_ context.Context, ids []string) error {
+	m.deletedChunkIDs = ids
+	return nil
+}
+
+func (m *mockVectorStore) DeleteByProject(_ context.Context, project string) error {
+	m.deletedProject = project
+	return nil
+}
+
 func (m *mockVectorStore) Search(_ context.Context, queryVector []float32, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
 	m.searchVector = queryVector
 	m.searchLimit = limit
@@ -190,6 +208,28 @@ func TestProjectPrefixStore_PathMappedMethods(t *testing.T) {
 		t.Errorf("DeleteByFile(abs) path = %q, want %q", mock.deletedByFilePath, prefixed)
 	}
 
+	if err := wrapped.DeleteByFiles(ctx, []string{abs}); err != nil {
+		t.Fatalf("DeleteByFiles(abs) failed: %v", err)
+	}
+	if len(mock.deletedByFilePaths) != 1 || mock.deletedByFilePaths[0] != prefixed {
+		t.Errorf("DeleteByFiles(abs) paths = %q, want [%q]", mock.deletedByFilePaths, prefixed)
+	}
+
+	if err := wrapped.DeleteByProject(ctx, filepath.Join(projectRoot, "pkg")); err != nil {
+		t.Fatalf("DeleteByProject(abs) failed: %v", err)
+	}
+	if want := wrapped.getPrefix() + "/pkg"; mock.deletedProject != want {
+		t.Errorf("DeleteByProject(abs) project = %q, want %q", mock.deletedProject, want)
+	}
+
+	ids := []string{prefixed + "_0_abc"}
+	if err := wrapped.DeleteChunks(ctx, ids); err != nil {
+		t.Fatalf("DeleteChunks failed: %v", err)
+	}
+	if len(mock.deletedChunkIDs) != 1 || mock.deletedChunkIDs[0] != ids[0] {
+		t.Errorf("DeleteChunks ids = %q, want %q", mock.deletedChunkIDs, ids)
+	}
+
 	if _, err := wrapped.GetDocument(ctx, abs); err != nil {
 		t.Fatalf("GetDocument(abs) failed: %v", err)
 	}
@@ -283,3 +323,20 @@ func TestProjectPrefixStore_PassThroughAndGetChunks(t *testing.T) {
 		t.Errorf("GetChunksForFile(rel) path = %q, want %q", mock.getChunksForFilePath, "relative.go")
 	}
 }
+
+func TestPrefixChunkID(t *testing.T) {
+	tests := []struct {
+		id, relPath, want string
+	}{
+		{"src/a.go_2_0123456789ab", "src/a.go", "proj/src/a.go_2_0123456789ab"},
+		{`src\a.go_2_0123456789ab`, "src/a.go", "proj/src/a.go_2_0123456789ab"},
+		{`src\a.go_0_1_0123456789ab`, "src/a.go", "proj/src/a.go_0_1_0123456789ab"},
+		{`src\a.go_3`, "src/a.go", "proj/src/a.go_3"},
+		{"opaque", "src/a.go", "opaque"},
+	}
+	for _, tt := range tests {
+		if got := prefixChunkID(tt.id, tt.relPath, "proj/"+tt.relPath); got != tt.want {
+			t.Errorf("prefixChunkID(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
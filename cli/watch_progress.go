@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+)
+
+// Initial scan progress modes (watch --progress).
+const (
+	watchProgressAuto  = "auto"  // progress bar on a terminal, plain summaries otherwise
+	watchProgressPlain = "plain" // periodic single-line summaries
+	watchProgressNone  = "none"  // no progress output
+
+	// watchProgressBar is what auto resolves to on a terminal.
+	watchProgressBar = "bar"
+)
+
+const defaultPlainProgressInterval = 5 * time.Second
+
+func validateWatchProgressMode(mode string) error {
+	switch mode {
+	case watchProgressAuto, watchProgressPlain, watchProgressNone:
+		return nil
+	default:
+		return fmt.Errorf("invalid --progress %q: must be auto, plain or none", mode)
+	}
+}
+
+// resolveWatchProgressMode turns auto into bar or plain depending on whether
+// stdout is a terminal that can redraw a progress line.
+func resolveWatchProgressMode(mode string, isTTY bool) string {
+	if mode != watchProgressAuto {
+		return mode
+	}
+	if isTTY {
+		return watchProgressBar
+	}
+	return watchProgressPlain
+}
+
+// plainProgressReporter prints periodic single-line progress summaries
+// (percentage, rate and ETA) where a redrawn progress bar would end up as raw
+// control characters, such as CI logs and daemon log files.
+type plainProgressReporter struct {
+	label    string
+	unit     string
+	interval time.Duration
+	printf   func(format string, args ...any)
+	now      func() time.Time
+
+	start    time.Time
+	lastEmit time.Time
+	finished bool
+}
+
+func newPlainProgressReporter(label, unit string, printf func(format string, args ...any)) *plainProgressReporter {
+	return &plainProgressReporter{
+		label:    label,
+		unit:     unit,
+		interval: defaultPlainProgressInterval,
+		printf:   printf,
+		now:      time.Now,
+	}
+}
+
+// update records progress and prints a summary at most once per interval,
+// plus once on completion.
+func (r *plainProgressReporter) update(current, total int) {
+	if total <= 0 || r.finished {
+		return
+	}
+	now := r.now()
+	if r.start.IsZero() {
+		r.start = now
+		r.lastEmit = now
+	}
+	done := current >= total
+	if !done && now.Sub(r.lastEmit) < r.interval {
+		return
+	}
+	r.lastEmit = now
+	r.finished = done
+	r.printf("%s", r.summary(current, total, now.Sub(r.start)))
+}
+
+func (r *plainProgressReporter) summary(current, total int, elapsed time.Duration) string {
+	percent := float64(current) / float64(total) * 100
+	line := fmt.Sprintf("%s: %.0f%% (%d/%d %s", r.label, percent, current, total, r.unit)
+	if elapsed <= 0 {
+		return line + ")"
+	}
+	rate := float64(current) / elapsed.Seconds()
+	line += fmt.Sprintf(", %.1f %s/s", rate, r.unit)
+	switch {
+	case current >= total:
+		line += fmt.Sprintf(", took %s", elapsed.Round(time.Second))
+	case rate > 0:
+		eta := time.Duration(float64(total-current) / rate * float64(time.Second))
+		line += fmt.Sprintf(", ETA %s", eta.Round(time.Second))
+	}
+	return line + ")"
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/indexer"
+)
+
+func TestResolveWatchProgressMode(t *testing.T) {
+	tests := []struct {
+		mode  string
+		isTTY bool
+		want  string
+	}{
+		{watchProgressAuto, true, watchProgressBar},
+		{watchProgressAuto, false, watchProgressPlain},
+		{watchProgressPlain, true, watchProgressPlain},
+		{watchProgressNone, false, watchProgressNone},
+	}
+	for _, tt := range tests {
+		if got := resolveWatchProgressMode(tt.mode, tt.isTTY); got != tt.want {
+			t.Errorf("resolveWatchProgressMode(%q, %v) = %q, want %q", tt.mode, tt.isTTY, got, tt.want)
+		}
+	}
+
+	if err := validateWatchProgressMode("bar"); err == nil {
+		t.Error("bar is not a user-selectable mode")
+	}
+}
+
+func TestPlainProgressReporter(t *testing.T) {
+	var lines []string
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newPlainProgressReporter("Indexing", "files", func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	})
+	r.now = func() time.Time { return clock }
+
+	r.update(0, 100)
+	clock = clock.Add(2 * time.Second)
+	r.update(20, 100) // within the interval: suppressed
+	if len(lines) != 0 {
+		t.Fatalf("expected no output before the interval elapsed, got %v", lines)
+	}
+
+	clock = clock.Add(3 * time.Second)
+	r.update(50, 100)
+	if len(lines) != 1 || lines[0] != "Indexing: 50% (50/100 files, 10.0 files/s, ETA 5s)" {
+		t.Fatalf("unexpected summary: %v", lines)
+	}
+
+	clock = clock.Add(time.Second)
+	r.update(100, 100) // completion is always reported
+	r.update(100, 100)
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "Indexing: 100% (100/100 files") || !strings.Contains(lines[1], "took 6s") {
+		t.Fatalf("unexpected completion output: %v", lines)
+	}
+}
+
+func TestInitialScanProgressCallbacks_Plain(t *testing.T) {
+	var lines []string
+	onFile, onBatch := initialScanProgressCallbacks(watchProgressPlain, func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	})
+
+	onFile(indexer.ProgressInfo{Current: 3, Total: 3, CurrentFile: "main.go"})
+	onBatch(indexer.BatchProgressInfo{Retrying: true, BatchIndex: 0, Attempt: 2, StatusCode: 429})
+	onBatch(indexer.BatchProgressInfo{CompletedChunks: 10, TotalChunks: 10})
+
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %v", lines)
+	}
+	for _, line := range lines {
+		if strings.ContainsAny(line, "\r█") {
+			t.Errorf("plain progress should not redraw or draw bars: %q", line)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "Indexing: 100%") || !strings.Contains(lines[1], "attempt 2/5") || !strings.HasPrefix(lines[2], "Embedding: 100%") {
+		t.Errorf("unexpected plain progress: %v", lines)
+	}
+
+	onFile, onBatch = initialScanProgressCallbacks(watchProgressNone, nil)
+	onFile(indexer.ProgressInfo{Current: 1, Total: 1})
+	onBatch(indexer.BatchProgressInfo{CompletedChunks: 1, TotalChunks: 1})
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+var watchQuickStart bool
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchQuickStart, "quick-start", false, "Index the most important files first so search is usable quickly, then backfill the rest")
+}
+
+// runQuickStartScan indexes an importance-ranked subset of the project and
+// persists it, so search works while the initial scan backfills the rest.
+// It reports whether a quick-start pass ran; small projects that fit in the
+// budget skip straight to the initial scan.
+func runQuickStartScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, st store.VectorStore, isBackgroundChild bool, onScan func(current, total int, file string), onEmbed func(info indexer.BatchProgressInfo), onActivity watchActivityObserver) (bool, error) {
+	files, _, err := scanner.ScanMetadata()
+	if err != nil {
+		return false, fmt.Errorf("quick start scan failed: %w", err)
+	}
+	subset := indexer.QuickStartFiles(files, indexer.DefaultQuickStartFiles)
+	if len(subset) == 0 {
+		return false, nil
+	}
+
+	report := func(format string, args ...any) {
+		if isBackgroundChild {
+			log.Printf(format, args...)
+		} else {
+			fmt.Printf(format+"\n", args...)
+		}
+	}
+	setActivity := func(state string) {
+		if onActivity != nil {
+			onActivity(state, "")
+		}
+	}
+
+	report("\nQuick start: indexing %d of %d files first...", len(subset), len(files))
+	setActivity(fmt.Sprintf("quick start 1/2: %d of %d files", len(subset), len(files)))
+
+	stats, err := runIndexWithProgress(isBackgroundChild, onScan, onEmbed, func(onProgress indexer.ProgressCallback, onBatchProgress indexer.BatchProgressCallback) (*indexer.IndexStats, error) {
+		return idx.IndexFilesWithBatchProgress(ctx, subset, onProgress, onBatchProgress)
+	})
+	if err != nil {
+		return true, fmt.Errorf("quick start indexing failed: %w", err)
+	}
+	if err := st.Persist(ctx); err != nil {
+		log.Printf("Warning: failed to persist quick start index: %v", err)
+	}
+
+	report("Quick start ready: %d files indexed (took %s); search is usable while the remaining %d files are backfilled",
+		stats.FilesIndexed, stats.Duration.Round(time.Millisecond), len(files)-len(subset))
+	setActivity(fmt.Sprintf("backfill 2/2: %d remaining files", len(files)-len(subset)))
+	return true, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"log"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// indexUpdateSignal pings readers of a project's index, such as a running
+// MCP server, once index changes are visible to them. Writes to a GOB index
+// only become visible when the index is persisted; other backends apply
+// them immediately.
+type indexUpdateSignal struct {
+	projectRoot string
+	immediate   bool
+	pending     bool
+}
+
+func newIndexUpdateSignal(projectRoot string, cfg *config.Config) *indexUpdateSignal {
+	return &indexUpdateSignal{
+		projectRoot: projectRoot,
+		immediate:   cfg.Store.Backend != "gob",
+	}
+}
+
+// changed records that a file event may have updated the index.
+func (s *indexUpdateSignal) changed() {
+	if s.immediate {
+		s.send()
+		return
+	}
+	s.pending = true
+}
+
+// persisted records that the index was written to disk.
+func (s *indexUpdateSignal) persisted() {
+	if s.pending {
+		s.send()
+	}
+}
+
+func (s *indexUpdateSignal) send() {
+	s.pending = false
+	if err := config.SignalIndexUpdated(s.projectRoot); err != nil {
+		log.Printf("Warning: failed to signal index update: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestIndexUpdateSignal(t *testing.T) {
+	signaled := func(root string) bool {
+		_, err := os.Stat(config.GetIndexSignalPath(root))
+		return err == nil
+	}
+
+	t.Run("gob waits for persist", func(t *testing.T) {
+		root := t.TempDir()
+		if err := os.MkdirAll(config.GetConfigDir(root), 0755); err != nil {
+			t.Fatal(err)
+		}
+		updates := newIndexUpdateSignal(root, &config.Config{Store: config.StoreConfig{Backend: "gob"}})
+
+		updates.persisted()
+		if signaled(root) {
+			t.Fatal("expected no signal without changes")
+		}
+		updates.changed()
+		if signaled(root) {
+			t.Fatal("expected GOB changes to wait for the next persist")
+		}
+		updates.persisted()
+		if !signaled(root) {
+			t.Fatal("expected a signal once changes are persisted")
+		}
+	})
+
+	t.Run("remote backends signal immediately", func(t *testing.T) {
+		root := t.TempDir()
+		if err := os.MkdirAll(config.GetConfigDir(root), 0755); err != nil {
+			t.Fatal(err)
+		}
+		updates := newIndexUpdateSignal(root, &config.Config{Store: config.StoreConfig{Backend: "postgres"}})
+
+		updates.changed()
+		if !signaled(root) {
+			t.Fatal("expected an immediate signal")
+		}
+	})
+}
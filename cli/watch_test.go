@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/daemon"
+	"github.com/yoanbernabeu/grepai/store"
 	"github.com/yoanbernabeu/grepai/watcher"
 )
 
@@ -638,3 +640,46 @@ func TestWorkspaceWatchEvent_should_carry_project_path(t *testing.T) {
 		t.Errorf("project path = %q, want %q", evt.projectPath, "/home/user/projects/myapp")
 	}
 }
+
+func TestStampIndexMetadata(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, config.IndexMetadataFileName)
+	st := store.NewGOBStore(filepath.Join(dir, "index.gob"))
+	ollama := config.DefaultEmbedderForProvider("ollama")
+	openai := config.DefaultEmbedderForProvider("openai")
+
+	if err := stampIndexMetadata(ctx, st, path, ollama); err != nil {
+		t.Fatalf("failed to stamp a new index: %v", err)
+	}
+	meta, err := config.LoadIndexMetadata(path)
+	if err != nil || meta == nil || !meta.Matches(ollama.IndexMetadata()) || meta.CreatedAt.IsZero() {
+		t.Fatalf("expected the index to be stamped with %s, got %+v (err %v)", ollama.IndexMetadata(), meta, err)
+	}
+
+	// An empty index is stamped again with a new embedder, as it is once
+	// deleted to be rebuilt.
+	if err := stampIndexMetadata(ctx, st, path, openai); err != nil {
+		t.Fatalf("failed to stamp an empty index: %v", err)
+	}
+	if err := st.SaveDocument(ctx, store.Document{Path: "a.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stampIndexMetadata(ctx, st, path, openai); err != nil {
+		t.Fatalf("expected the same embedder to pass, got %v", err)
+	}
+	if err := stampIndexMetadata(ctx, st, path, ollama); !errors.Is(err, config.ErrEmbedderMismatch) {
+		t.Fatalf("expected an indexed project to refuse another embedder, got %v", err)
+	}
+	// An index built before metadata was recorded is not stamped: the
+	// embedder that built it is unknown.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := stampIndexMetadata(ctx, st, path, ollama); err != nil {
+		t.Fatalf("expected an index without metadata to pass with a warning, got %v", err)
+	}
+	if meta, err := config.LoadIndexMetadata(path); err != nil || meta != nil {
+		t.Errorf("expected an index without metadata not to be stamped, got %+v (err %v)", meta, err)
+	}
+}
@@ -9,10 +9,13 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/daemon"
 	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/watcher"
 )
 
 func withWatchGlobals(t *testing.T, workspace string, status, stop, background bool) {
@@ -216,36 +219,6 @@ func TestInitializeEmbedderUnknownProvider(t *testing.T) {
 	}
 }
 
-func TestInitializeStoreUnknownBackend(t *testing.T) {
-	cfg := config.DefaultConfig()
-	cfg.Store.Backend = "unknown"
-
-	_, err := initializeStore(context.Background(), cfg, t.TempDir())
-	if err == nil {
-		t.Fatal("initializeStore() should fail for unknown backend")
-	}
-	if !strings.Contains(err.Error(), "unknown storage backend") {
-		t.Fatalf("initializeStore() error = %q, want message containing %q", err.Error(), "unknown storage backend")
-	}
-}
-
-func TestInitializeWorkspaceStoreUnsupportedBackend(t *testing.T) {
-	ws := &config.Workspace{
-		Name: "ws",
-		Store: config.StoreConfig{
-			Backend: "gob",
-		},
-	}
-
-	_, err := initializeWorkspaceStore(context.Background(), ws)
-	if err == nil {
-		t.Fatal("initializeWorkspaceStore() should fail for unsupported backend")
-	}
-	if !strings.Contains(err.Error(), "unsupported backend for workspace") {
-		t.Fatalf("initializeWorkspaceStore() error = %q, want message containing %q", err.Error(), "unsupported backend for workspace")
-	}
-}
-
 func TestDiscoverWorktreesForWatchNonGitDirectory(t *testing.T) {
 	got := discoverWorktreesForWatch(t.TempDir())
 	if len(got) != 0 {
@@ -317,3 +290,63 @@ func TestPrintProgressAndBatchProgress_NoUIModeUsesNewlines(t *testing.T) {
 		t.Fatalf("expected inline progress output, got %q", out)
 	}
 }
+
+func TestInitializeWorkspaceRuntime_ReplaysJournal(t *testing.T) {
+	ctx := context.Background()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "a.go"), []byte("package a\n\nfunc Edited() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The edit happened within the second of the last index, so the scan
+	// skips the file and only the journal knows about it.
+	projectCfg := config.DefaultConfig()
+	projectCfg.Watch.LastIndexTime = time.Now().Add(time.Hour)
+	if err := projectCfg.Save(projectDir); err != nil {
+		t.Fatal(err)
+	}
+
+	ws := &config.Workspace{Name: "ws"}
+	project := config.ProjectEntry{Name: "api", Path: projectDir}
+	shared := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := shared.SaveChunks(ctx, []store.Chunk{{ID: "ws/api/a.go_0", FilePath: "ws/api/a.go", Content: "func Old() {}"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := shared.SaveDocument(ctx, store.Document{Path: "ws/api/a.go", Hash: "old", ChunkIDs: []string{"ws/api/a.go_0"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	journalPath, err := config.GetWorkspaceWatchJournalPath(ws.Name, project.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal, err := watcher.OpenJournal(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Record(watcher.FileEvent{Type: watcher.EventModify, Path: "a.go"}); err != nil {
+		t.Fatal(err)
+	}
+	journal.Close()
+
+	runtime, w, err := initializeWorkspaceRuntime(ctx, ws, project, &countingEmbedder{}, shared, true)
+	if err != nil {
+		t.Fatalf("initializeWorkspaceRuntime failed: %v", err)
+	}
+	defer func() {
+		w.Close()
+		runtime.journal.Close()
+		runtime.symbolStore.Close()
+	}()
+
+	chunks, err := shared.GetChunksForFile(ctx, "ws/api/a.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) == 0 || !strings.Contains(chunks[0].Content, "Edited") {
+		t.Fatalf("expected the journaled edit to be indexed, got %+v", chunks)
+	}
+}
@@ -85,3 +85,74 @@ func TestDiscoverWorktreesForWatch_LinkedWorktreeDoesNotDiscoverSiblings(t *test
 		t.Fatalf("discoverWorktreesForWatch() returned %d worktrees for linked worktree, want 0", len(got))
 	}
 }
+
+func TestBuildWatchDesiredProjects_DedupesAliases(t *testing.T) {
+	root := t.TempDir()
+	mainRepo := filepath.Join(root, "main")
+	worktree := filepath.Join(root, "wt")
+	for _, dir := range []string{mainRepo, worktree} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+	}
+	alias := filepath.Join(root, "wt-alias")
+	if err := os.Symlink(worktree, alias); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	desired := buildWatchDesiredProjects(mainRepo, []string{
+		mainRepo,
+		worktree,
+		alias,
+		filepath.Join(worktree, "."),
+	})
+	if len(desired) != 2 {
+		t.Fatalf("expected main and one linked worktree, got %v", sortedWatchProjectRoots(desired))
+	}
+	if !desired[canonicalPath(mainRepo)] || !desired[canonicalPath(worktree)] {
+		t.Fatalf("unexpected desired projects: %v", sortedWatchProjectRoots(desired))
+	}
+	if watchSessionRole(mainRepo, filepath.Join(mainRepo, ".")) != "primary" {
+		t.Fatal("expected main root spelled differently to be primary")
+	}
+}
+
+func TestDiscoverWorktreesForWatch_IncludesSubmodulesWhenEnabled(t *testing.T) {
+	mainRepo, worktreePath := setupMainRepoForWorktreeDiscovery(t)
+
+	subRepo := filepath.Join(t.TempDir(), "sub")
+	if err := os.MkdirAll(subRepo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	runGitDiscovery(t, subRepo, "init")
+	runGitDiscovery(t, subRepo, "config", "user.email", "test@example.com")
+	runGitDiscovery(t, subRepo, "config", "user.name", "Test User")
+	if err := os.WriteFile(filepath.Join(subRepo, "lib.go"), []byte("package lib\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGitDiscovery(t, subRepo, "add", "lib.go")
+	runGitDiscovery(t, subRepo, "commit", "-m", "init")
+	runGitDiscovery(t, mainRepo, "-c", "protocol.file.allow=always", "submodule", "add", subRepo, "libs/sub")
+	submodule := canonicalPath(filepath.Join(mainRepo, "libs", "sub"))
+
+	if got := discoverWorktreesForWatch(mainRepo); len(got) != 1 {
+		t.Fatalf("submodules must not be watched by default, got %v", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(mainRepo, ".grepai", "config.yaml"), []byte("watch:\n  debounce_ms: 500\n  include_submodules: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := discoverWorktreesForWatch(mainRepo)
+	if len(got) != 2 || got[0] != canonicalPath(worktreePath) || got[1] != submodule {
+		t.Fatalf("discoverWorktreesForWatch() = %v, want [%s %s]", got, canonicalPath(worktreePath), submodule)
+	}
+	if _, err := os.Stat(filepath.Join(submodule, ".grepai", "config.yaml")); err != nil {
+		t.Fatalf("expected submodule to be auto-initialized: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(submodule, ".grepai", "index.gob")); !os.IsNotExist(err) {
+		t.Errorf("the superproject index must not seed a submodule, stat err=%v", err)
+	}
+	if status := runGitDiscovery(t, submodule, "status", "--porcelain"); status != "" {
+		t.Errorf("auto-init must leave the submodule clean, got %q", status)
+	}
+}
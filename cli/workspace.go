@@ -2,20 +2,32 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/global"
+	"github.com/yoanbernabeu/grepai/store"
 	"gopkg.in/yaml.v3"
 )
 
 var (
 	workspaceCreateUI bool
 	workspaceStatusUI bool
+
+	workspaceAddRecursive bool
+	workspaceAddMatch     string
+	workspaceAddMaxDepth  int
+	workspaceAddYes       bool
+	workspaceAddInherit   bool
+	workspaceAddIgnore    []string
+	workspaceAddLanguages []string
 )
 
 var (
@@ -69,7 +81,28 @@ var workspaceAddCmd = &cobra.Command{
 	Long: `Add a project to an existing workspace.
 
 The project name will be derived from the directory name.
-The project path must be an absolute path to a directory.`,
+The project path must be an absolute path to a directory.
+
+With --recursive, path is a parent directory: every git repository found
+under it is added as a project, after confirmation. With --match, the
+directories matching a glob relative to the parent are added instead, git
+repositories or not. Projects already in the workspace are skipped, and
+names are prefixed with their parent directory when they would collide.
+
+A project whose own .grepai/config.yaml uses another embedding provider,
+model or dimensions than the workspace is reported, and grepai offers to
+copy the workspace embedder settings into it. --inherit-embedder copies
+them without asking.
+
+--ignore and --languages tune how the workspace indexes the added projects
+without touching their own configuration: ignore patterns are added to the
+project's, and languages replace its traced file extensions. They are
+stored in workspace.yaml.
+
+Examples:
+  grepai workspace add my-ws ~/src/api
+  grepai workspace add my-ws ~/src --recursive
+  grepai workspace add my-ws ~/src/monorepo --recursive --match 'services/*'`,
 	Args: cobra.ExactArgs(2),
 	RunE: runWorkspaceAdd,
 }
@@ -98,18 +131,34 @@ func init() {
 	workspaceCmd.AddCommand(workspaceDeleteCmd)
 
 	// Non-interactive workspace create flags
-	workspaceCreateCmd.Flags().String("backend", "", "Storage backend: postgres, qdrant")
+	workspaceCreateCmd.Flags().String("backend", "", "Storage backend: postgres, qdrant, weaviate, redis, opensearch")
 	workspaceCreateCmd.Flags().String("provider", "", "Embedding provider: ollama, openai, lmstudio")
 	workspaceCreateCmd.Flags().String("model", "", "Embedding model name")
 	workspaceCreateCmd.Flags().String("endpoint", "", "Embedder endpoint URL")
 	workspaceCreateCmd.Flags().String("dsn", "", "PostgreSQL DSN (when backend=postgres)")
 	workspaceCreateCmd.Flags().String("qdrant-endpoint", "", "Qdrant endpoint (default: http://localhost)")
 	workspaceCreateCmd.Flags().Int("qdrant-port", 0, "Qdrant gRPC port (default: 6334)")
-	workspaceCreateCmd.Flags().String("collection", "", "Qdrant collection name (empty = auto)")
+	workspaceCreateCmd.Flags().String("collection", "", "Qdrant collection, Weaviate class, or Redis or OpenSearch index name (empty = auto)")
+	workspaceCreateCmd.Flags().String("qdrant-api-key", "", "Qdrant API key (required for Qdrant Cloud)")
+	workspaceCreateCmd.Flags().Bool("qdrant-tls", false, "Connect to Qdrant over TLS (default for https and Qdrant Cloud endpoints)")
+	workspaceCreateCmd.Flags().String("weaviate-endpoint", "", "Weaviate endpoint (default: http://localhost:8080)")
+	workspaceCreateCmd.Flags().String("weaviate-api-key", "", "Weaviate API key (for Weaviate Cloud)")
+	workspaceCreateCmd.Flags().String("redis-url", "", "Redis URL (default: redis://localhost:6379)")
+	workspaceCreateCmd.Flags().Duration("redis-ttl", 0, "Expire Redis keys after this duration, e.g. 24h (default: never)")
+	workspaceCreateCmd.Flags().String("opensearch-endpoint", "", "OpenSearch endpoint (default: http://localhost:9200)")
+	workspaceCreateCmd.Flags().String("opensearch-username", "", "OpenSearch username for basic auth")
+	workspaceCreateCmd.Flags().String("opensearch-password", "", "OpenSearch password for basic auth")
 	workspaceCreateCmd.Flags().String("from", "", "Path to JSON/YAML file with workspace config")
 	workspaceCreateCmd.Flags().Bool("yes", false, "Use defaults for unspecified values, skip prompts")
 	workspaceCreateCmd.Flags().BoolVar(&workspaceCreateUI, "ui", false, "Run interactive Bubble Tea UI wizard")
 	workspaceStatusCmd.Flags().BoolVar(&workspaceStatusUI, "ui", false, "Show workspace status in interactive UI")
+	workspaceAddCmd.Flags().BoolVarP(&workspaceAddRecursive, "recursive", "r", false, "Add every git repository found under the path")
+	workspaceAddCmd.Flags().StringVar(&workspaceAddMatch, "match", "", "With --recursive, add the directories matching this glob relative to the path instead of git repositories")
+	workspaceAddCmd.Flags().IntVar(&workspaceAddMaxDepth, "max-depth", config.DefaultGlobalMaxDepth, "With --recursive, how many directory levels to search for git repositories")
+	workspaceAddCmd.Flags().BoolVar(&workspaceAddYes, "yes", false, "With --recursive, add the projects found without asking for confirmation")
+	workspaceAddCmd.Flags().BoolVar(&workspaceAddInherit, "inherit-embedder", false, "Copy the workspace embedder settings into projects whose config differs")
+	workspaceAddCmd.Flags().StringSliceVar(&workspaceAddIgnore, "ignore", nil, "Extra ignore patterns for the project when indexed by the workspace")
+	workspaceAddCmd.Flags().StringSliceVar(&workspaceAddLanguages, "languages", nil, "File extensions to trace for the project when indexed by the workspace (e.g. .go,.ts)")
 }
 
 func runWorkspaceList(cmd *cobra.Command, args []string) error {
@@ -162,9 +211,31 @@ func runWorkspaceShow(cmd *cobra.Command, args []string) error {
 	case "qdrant":
 		fmt.Printf("  Endpoint: %s\n", ws.Store.Qdrant.Endpoint)
 		fmt.Printf("  Port: %d\n", ws.Store.Qdrant.Port)
+		fmt.Printf("  TLS: %t\n", ws.Store.Qdrant.TLSEnabled())
 		if ws.Store.Qdrant.Collection != "" {
 			fmt.Printf("  Collection: %s\n", ws.Store.Qdrant.Collection)
 		}
+	case "weaviate":
+		fmt.Printf("  Endpoint: %s\n", ws.Store.Weaviate.Endpoint)
+		if ws.Store.Weaviate.Class != "" {
+			fmt.Printf("  Class: %s\n", ws.Store.Weaviate.Class)
+		}
+	case "redis":
+		fmt.Printf("  URL: %s\n", maskDSN(ws.Store.Redis.URL))
+		if ws.Store.Redis.Index != "" {
+			fmt.Printf("  Index: %s\n", ws.Store.Redis.Index)
+		}
+		if ws.Store.Redis.TTLSeconds > 0 {
+			fmt.Printf("  Key expiry: %s\n", ws.Store.Redis.TTL())
+		}
+	case "opensearch":
+		fmt.Printf("  Endpoint: %s\n", ws.Store.OpenSearch.Endpoint)
+		if ws.Store.OpenSearch.Index != "" {
+			fmt.Printf("  Index: %s\n", ws.Store.OpenSearch.Index)
+		}
+		if ws.Store.OpenSearch.Username != "" {
+			fmt.Printf("  Username: %s\n", ws.Store.OpenSearch.Username)
+		}
 	}
 
 	fmt.Printf("\nEmbedder:\n")
@@ -180,6 +251,12 @@ func runWorkspaceShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("\nProjects (%d):\n", len(ws.Projects))
 	for _, p := range ws.Projects {
 		fmt.Printf("  - %s: %s\n", p.Name, p.Path)
+		if len(p.Ignore) > 0 {
+			fmt.Printf("      Extra ignore: %s\n", strings.Join(p.Ignore, ", "))
+		}
+		if len(p.Languages) > 0 {
+			fmt.Printf("      Languages: %s\n", strings.Join(p.Languages, ", "))
+		}
 	}
 
 	return nil
@@ -234,7 +311,7 @@ func showWorkspaceStatus(ws *config.Workspace) error {
 		if _, err := os.Stat(p.Path); os.IsNotExist(err) {
 			exists = "✗ (path not found)"
 		}
-		fmt.Printf("    - %s: %s %s\n", p.Name, p.Path, exists)
+		printlnStyled(fmt.Sprintf("    - %s: %s %s", p.Name, p.Path, exists))
 	}
 
 	return nil
@@ -282,8 +359,18 @@ func buildWorkspaceFromFlags(name, backend, provider, model, dsn, endpoint, qdra
 		storeConfig.Qdrant.Endpoint = qdrantEndpoint
 		storeConfig.Qdrant.Port = qdrantPort
 		storeConfig.Qdrant.Collection = collection
+		storeConfig.Qdrant.UseTLS = storeConfig.Qdrant.TLSEnabled()
+	case "weaviate":
+		storeConfig.Weaviate.Endpoint = config.DefaultWeaviateEndpoint
+		storeConfig.Weaviate.Class = collection
+	case "redis":
+		storeConfig.Redis.URL = config.DefaultRedisURL
+		storeConfig.Redis.Index = collection
+	case "opensearch":
+		storeConfig.OpenSearch.Endpoint = config.DefaultOpenSearchEndpoint
+		storeConfig.OpenSearch.Index = collection
 	default:
-		return nil, fmt.Errorf("unsupported backend: %s (use postgres or qdrant)", backend)
+		return nil, fmt.Errorf("unsupported backend: %s (use postgres, qdrant, weaviate, redis or opensearch)", backend)
 	}
 
 	var embedderConfig config.EmbedderConfig
@@ -367,7 +454,9 @@ func buildWorkspaceFromFile(name, filePath string) (*config.Workspace, error) {
 // hasNonInteractiveFlags checks if any non-interactive flag was explicitly set.
 func hasNonInteractiveFlags(cmd *cobra.Command) bool {
 	flags := []string{"backend", "provider", "model", "endpoint", "dsn",
-		"qdrant-endpoint", "qdrant-port", "collection", "from", "yes"}
+		"qdrant-endpoint", "qdrant-port", "qdrant-api-key", "qdrant-tls", "weaviate-endpoint", "weaviate-api-key",
+		"redis-url", "redis-ttl", "opensearch-endpoint", "opensearch-username", "opensearch-password",
+		"collection", "from", "yes"}
 	for _, f := range flags {
 		if cmd.Flags().Changed(f) {
 			return true
@@ -417,6 +506,35 @@ func runWorkspaceCreate(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return err
 		}
+		if ws.Store.Backend == "qdrant" {
+			ws.Store.Qdrant.APIKey, _ = cmd.Flags().GetString("qdrant-api-key")
+			if useTLS, _ := cmd.Flags().GetBool("qdrant-tls"); useTLS {
+				ws.Store.Qdrant.UseTLS = true
+			}
+			if config.IsQdrantCloudEndpoint(ws.Store.Qdrant.Endpoint) && ws.Store.Qdrant.APIKey == "" {
+				return fmt.Errorf("--qdrant-api-key is required for Qdrant Cloud endpoints")
+			}
+		}
+		if ws.Store.Backend == "weaviate" {
+			if endpoint, _ := cmd.Flags().GetString("weaviate-endpoint"); endpoint != "" {
+				ws.Store.Weaviate.Endpoint = endpoint
+			}
+			ws.Store.Weaviate.APIKey, _ = cmd.Flags().GetString("weaviate-api-key")
+		}
+		if ws.Store.Backend == "redis" {
+			if redisURL, _ := cmd.Flags().GetString("redis-url"); redisURL != "" {
+				ws.Store.Redis.URL = redisURL
+			}
+			ttl, _ := cmd.Flags().GetDuration("redis-ttl")
+			ws.Store.Redis.TTLSeconds = int(ttl / time.Second)
+		}
+		if ws.Store.Backend == "opensearch" {
+			if endpoint, _ := cmd.Flags().GetString("opensearch-endpoint"); endpoint != "" {
+				ws.Store.OpenSearch.Endpoint = endpoint
+			}
+			ws.Store.OpenSearch.Username, _ = cmd.Flags().GetString("opensearch-username")
+			ws.Store.OpenSearch.Password, _ = cmd.Flags().GetString("opensearch-password")
+		}
 	} else {
 		if workspaceCreateUI {
 			ws, err = createWorkspaceTUI(workspaceName)
@@ -460,6 +578,9 @@ func createWorkspaceInteractive(workspaceName string) (*config.Workspace, error)
 	fmt.Println("Select storage backend:")
 	fmt.Println("  1. PostgreSQL (recommended for production)")
 	fmt.Println("  2. Qdrant (for advanced vector search)")
+	fmt.Println("  3. Weaviate")
+	fmt.Println("  4. Redis (Redis Stack with RediSearch)")
+	fmt.Println("  5. OpenSearch (server-side hybrid search for large workspaces)")
 	fmt.Print("Choice [1]: ")
 	backendChoice, _ := reader.ReadString('\n')
 	backendChoice = strings.TrimSpace(backendChoice)
@@ -480,24 +601,20 @@ func createWorkspaceInteractive(workspaceName string) (*config.Workspace, error)
 		storeConfig.Postgres.DSN = dsn
 	case "2":
 		storeConfig.Backend = "qdrant"
-		fmt.Print("Qdrant endpoint [http://localhost]: ")
-		endpoint, _ := reader.ReadString('\n')
-		endpoint = strings.TrimSpace(endpoint)
-		if endpoint == "" {
-			endpoint = "http://localhost"
-		}
-		storeConfig.Qdrant.Endpoint = endpoint
-		fmt.Print("Qdrant port [6334]: ")
-		portStr, _ := reader.ReadString('\n')
-		portStr = strings.TrimSpace(portStr)
-		port := 6334
-		if portStr != "" {
-			_, _ = fmt.Sscanf(portStr, "%d", &port)
-		}
-		storeConfig.Qdrant.Port = port
-		fmt.Print("Collection name (leave empty for auto): ")
-		collection, _ := reader.ReadString('\n')
-		storeConfig.Qdrant.Collection = strings.TrimSpace(collection)
+		if err := promptQdrantConfig(reader, &storeConfig.Qdrant, "http://localhost"); err != nil {
+			return nil, err
+		}
+	case "3":
+		storeConfig.Backend = "weaviate"
+		promptWeaviateConfig(reader, &storeConfig.Weaviate)
+	case "4":
+		storeConfig.Backend = "redis"
+		if err := promptRedisConfig(reader, &storeConfig.Redis); err != nil {
+			return nil, err
+		}
+	case "5":
+		storeConfig.Backend = "opensearch"
+		promptOpenSearchConfig(reader, &storeConfig.OpenSearch)
 	default:
 		return nil, fmt.Errorf("invalid choice: %s", backendChoice)
 	}
@@ -569,12 +686,22 @@ func createWorkspaceInteractive(workspaceName string) (*config.Workspace, error)
 		return nil, fmt.Errorf("invalid choice: %s", embedderChoice)
 	}
 
-	return &config.Workspace{
+	ws := &config.Workspace{
 		Name:     workspaceName,
 		Store:    storeConfig,
 		Embedder: embedderConfig,
 		Projects: []config.ProjectEntry{},
-	}, nil
+	}
+	if name, ok := map[string]string{"qdrant": "Qdrant", "weaviate": "Weaviate", "redis": "Redis", "opensearch": "OpenSearch"}[storeConfig.Backend]; ok {
+		offerConnectionTest(reader, name, func(ctx context.Context) error {
+			st, err := store.NewFromWorkspaceConfig(ctx, ws)
+			if err != nil {
+				return err
+			}
+			return st.Close()
+		})
+	}
+	return ws, nil
 }
 
 func runWorkspaceAdd(cmd *cobra.Command, args []string) error {
@@ -595,6 +722,9 @@ func runWorkspaceAdd(cmd *cobra.Command, args []string) error {
 	if !info.IsDir() {
 		return fmt.Errorf("path is not a directory: %s", absPath)
 	}
+	if workspaceAddMatch != "" && !workspaceAddRecursive {
+		return fmt.Errorf("--match requires --recursive")
+	}
 
 	// Load config
 	cfg, err := config.LoadWorkspaceConfig()
@@ -605,14 +735,19 @@ func runWorkspaceAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no workspaces configured; create one first with: grepai workspace create <name>")
 	}
 
+	if workspaceAddRecursive {
+		return addWorkspaceProjectsRecursive(cfg, workspaceName, absPath)
+	}
+
 	// Derive project name from directory
 	projectName := filepath.Base(absPath)
 
 	// Add project
-	if err := cfg.AddProject(workspaceName, config.ProjectEntry{
+	project := withProjectOverrides(config.ProjectEntry{
 		Name: projectName,
 		Path: absPath,
-	}); err != nil {
+	})
+	if err := cfg.AddProject(workspaceName, project); err != nil {
 		return err
 	}
 
@@ -622,6 +757,89 @@ func runWorkspaceAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("Added project %q (%s) to workspace %q\n", projectName, absPath, workspaceName)
+	ws, err := cfg.GetWorkspace(workspaceName)
+	if err != nil {
+		return err
+	}
+	return reconcileProjectEmbedder(ws, project)
+}
+
+// withProjectOverrides sets the --ignore and --languages overrides on p.
+// Languages are file extensions, so a missing leading dot is added.
+func withProjectOverrides(p config.ProjectEntry) config.ProjectEntry {
+	p.Ignore = append([]string(nil), workspaceAddIgnore...)
+	p.Languages = nil
+	for _, lang := range workspaceAddLanguages {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang == "" {
+			continue
+		}
+		if !strings.HasPrefix(lang, ".") {
+			lang = "." + lang
+		}
+		p.Languages = append(p.Languages, lang)
+	}
+	return p
+}
+
+// embedderMismatch lists how the embedder of a project's own configuration
+// differs from the workspace embedder, in the settings that make vectors
+// incompatible: provider, model and dimensions.
+func embedderMismatch(ws config.EmbedderConfig, project config.EmbedderConfig) []string {
+	var diffs []string
+	if project.Provider != ws.Provider {
+		diffs = append(diffs, fmt.Sprintf("provider %s (workspace: %s)", project.Provider, ws.Provider))
+	}
+	if project.Model != ws.Model {
+		diffs = append(diffs, fmt.Sprintf("model %s (workspace: %s)", project.Model, ws.Model))
+	}
+	if project.GetDimensions() != ws.GetDimensions() {
+		diffs = append(diffs, fmt.Sprintf("%d dimensions (workspace: %d)", project.GetDimensions(), ws.GetDimensions()))
+	}
+	return diffs
+}
+
+// reconcileProjectEmbedder warns when project has its own configuration with
+// an embedder incompatible with ws, and copies the workspace embedder into
+// it with --inherit-embedder or when the user agrees.
+func reconcileProjectEmbedder(ws *config.Workspace, project config.ProjectEntry) error {
+	if !config.Exists(project.Path) {
+		return nil
+	}
+	projectCfg, err := config.Load(project.Path)
+	if err != nil {
+		fmt.Printf("Warning: could not read the configuration of %s: %v\n", project.Name, err)
+		return nil
+	}
+	diffs := embedderMismatch(ws.Embedder, projectCfg.Embedder)
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	fmt.Printf("Warning: project %q has its own embedder settings that differ from workspace %q:\n", project.Name, ws.Name)
+	for _, d := range diffs {
+		fmt.Printf("  - %s\n", d)
+	}
+	inherit := workspaceAddInherit
+	if !inherit && isInteractiveTerminal() {
+		fmt.Print("Use the workspace embedder settings in this project? [Y/n]: ")
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		inherit = input == "" || input == "y" || input == "yes"
+	}
+	if !inherit {
+		fmt.Printf("Kept the embedder settings of %q\n", project.Name)
+		return nil
+	}
+
+	projectCfg.Embedder = ws.Embedder
+	if err := projectCfg.Save(project.Path); err != nil {
+		return fmt.Errorf("failed to save configuration of %s: %w", project.Name, err)
+	}
+	fmt.Printf("Updated the embedder settings of %q to match the workspace\n", project.Name)
+	if projectCfg.Store.Backend == "gob" {
+		fmt.Printf("Its local index was built with the previous embedder; delete %s to rebuild it\n", config.GetIndexPath(project.Path))
+	}
 	return nil
 }
 
@@ -638,6 +856,22 @@ func runWorkspaceRemove(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no workspaces configured")
 	}
 
+	ws, err := cfg.GetWorkspace(workspaceName)
+	if err != nil {
+		return err
+	}
+	for _, p := range ws.Projects {
+		if p.Name != projectName {
+			continue
+		}
+		// Delete the project's chunks first, so that a failure leaves the
+		// project in the workspace and the command can be retried.
+		if err := deleteWorkspaceProjectChunks(context.Background(), ws, projectName); err != nil {
+			return err
+		}
+		break
+	}
+
 	// Remove project
 	if err := cfg.RemoveProject(workspaceName, projectName); err != nil {
 		return err
@@ -652,6 +886,20 @@ func runWorkspaceRemove(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// deleteWorkspaceProjectChunks removes the chunks and documents of a project
+// from the workspace store in one batch.
+func deleteWorkspaceProjectChunks(ctx context.Context, ws *config.Workspace, projectName string) error {
+	st, err := store.NewFromWorkspaceConfig(ctx, ws)
+	if err != nil {
+		return fmt.Errorf("failed to open workspace store: %w", err)
+	}
+	defer st.Close()
+	if err := st.DeleteByProject(ctx, ws.Name+"/"+projectName); err != nil {
+		return fmt.Errorf("failed to delete the chunks of project %q: %w", projectName, err)
+	}
+	return nil
+}
+
 func runWorkspaceDelete(cmd *cobra.Command, args []string) error {
 	workspaceName := args[0]
 
@@ -693,3 +941,111 @@ func maskDSN(dsn string) string {
 	}
 	return dsn
 }
+
+// addWorkspaceProjectsRecursive adds the projects found under parent to the
+// workspace, after listing them and asking for confirmation.
+func addWorkspaceProjectsRecursive(cfg *config.WorkspaceConfig, workspaceName, parent string) error {
+	ws, err := cfg.GetWorkspace(workspaceName)
+	if err != nil {
+		return err
+	}
+	projects, skipped, err := findWorkspaceProjects(ws, parent, workspaceAddMatch, workspaceAddMaxDepth)
+	if err != nil {
+		return err
+	}
+	for _, path := range skipped {
+		fmt.Printf("Skipping %s: already in workspace %q\n", path, workspaceName)
+	}
+	if len(projects) == 0 {
+		fmt.Printf("No new projects found under %s\n", parent)
+		return nil
+	}
+
+	fmt.Printf("Found %d projects under %s:\n", len(projects), parent)
+	for _, p := range projects {
+		fmt.Printf("  %-24s %s\n", p.Name, p.Path)
+	}
+	if !workspaceAddYes {
+		if !isInteractiveTerminal() {
+			return fmt.Errorf("confirmation required; rerun with --yes to add these projects")
+		}
+		fmt.Printf("\nAdd these %d projects to workspace %q? [y/N]: ", len(projects), workspaceName)
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "y" && input != "yes" {
+			fmt.Println("No projects added")
+			return nil
+		}
+	}
+
+	for i := range projects {
+		projects[i] = withProjectOverrides(projects[i])
+	}
+	for _, p := range projects {
+		if err := cfg.AddProject(workspaceName, p); err != nil {
+			return err
+		}
+	}
+	if err := config.SaveWorkspaceConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save workspace config: %w", err)
+	}
+	fmt.Printf("Added %d projects to workspace %q\n", len(projects), workspaceName)
+	ws, err = cfg.GetWorkspace(workspaceName)
+	if err != nil {
+		return err
+	}
+	for _, p := range projects {
+		if err := reconcileProjectEmbedder(ws, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findWorkspaceProjects returns the projects under parent that are not yet in
+// ws: the git repositories at most maxDepth levels deep, or the directories
+// matching the glob match when it is set. Paths already in ws are returned
+// as skipped. New projects get unique names that leave existing ones intact.
+func findWorkspaceProjects(ws *config.Workspace, parent, match string, maxDepth int) (projects []config.ProjectEntry, skipped []string, err error) {
+	var found []string
+	if match != "" {
+		matches, err := filepath.Glob(filepath.Join(parent, match))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --match pattern: %w", err)
+		}
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.IsDir() {
+				found = append(found, m)
+			}
+		}
+	} else {
+		found, err = global.Discover([]string{parent}, maxDepth, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	existing := make(map[string]bool, len(ws.Projects))
+	paths := make([]string, 0, len(ws.Projects)+len(found))
+	previous := make([]global.Repo, 0, len(ws.Projects))
+	for _, p := range ws.Projects {
+		existing[p.Path] = true
+		paths = append(paths, p.Path)
+		previous = append(previous, global.Repo{Name: p.Name, Path: p.Path})
+	}
+	var added []string
+	for _, path := range found {
+		if existing[path] {
+			skipped = append(skipped, path)
+			continue
+		}
+		added = append(added, path)
+		paths = append(paths, path)
+	}
+
+	names := global.AssignNames(paths, previous)
+	for _, path := range added {
+		projects = append(projects, config.ProjectEntry{Name: names[path], Path: path})
+	}
+	return projects, skipped, nil
+}
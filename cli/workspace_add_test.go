@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func mkdirs(t *testing.T, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", p, err)
+		}
+	}
+}
+
+func TestFindWorkspaceProjects_GitRepositories(t *testing.T) {
+	parent := t.TempDir()
+	mkdirs(t,
+		filepath.Join(parent, "api", ".git"),
+		filepath.Join(parent, "team", "web", ".git"),
+		filepath.Join(parent, "other", "web", ".git"),
+		filepath.Join(parent, "notes"),
+	)
+	ws := &config.Workspace{Projects: []config.ProjectEntry{
+		{Name: "web", Path: filepath.Join(parent, "team", "web")},
+	}}
+
+	projects, skipped, err := findWorkspaceProjects(ws, parent, "", config.DefaultGlobalMaxDepth)
+	if err != nil {
+		t.Fatalf("failed to find projects: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != filepath.Join(parent, "team", "web") {
+		t.Fatalf("skipped = %v, want the existing web project", skipped)
+	}
+	got := make(map[string]string)
+	for _, p := range projects {
+		got[p.Path] = p.Name
+	}
+	want := map[string]string{
+		filepath.Join(parent, "api"):          "api",
+		filepath.Join(parent, "other", "web"): "other-web",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("projects = %v, want %v", got, want)
+	}
+	for path, name := range want {
+		if got[path] != name {
+			t.Errorf("project %s named %q, want %q", path, got[path], name)
+		}
+	}
+}
+
+func TestFindWorkspaceProjects_Match(t *testing.T) {
+	parent := t.TempDir()
+	mkdirs(t, filepath.Join(parent, "services", "billing"), filepath.Join(parent, "services", "auth"), filepath.Join(parent, "docs"))
+	if err := os.WriteFile(filepath.Join(parent, "services", "README.md"), nil, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	projects, _, err := findWorkspaceProjects(&config.Workspace{}, parent, "services/*", 1)
+	if err != nil {
+		t.Fatalf("failed to find projects: %v", err)
+	}
+	if len(projects) != 2 || projects[0].Name != "auth" || projects[1].Name != "billing" {
+		t.Fatalf("projects = %+v, want auth and billing directories only", projects)
+	}
+}
+
+func TestRunWorkspaceAdd_Recursive(t *testing.T) {
+	cleanup := setTestHomeDirCLI(t, t.TempDir())
+	defer cleanup()
+
+	wsCfg := config.DefaultWorkspaceConfig()
+	if err := wsCfg.AddWorkspace(config.Workspace{Name: "demo", Store: config.StoreConfig{Backend: "qdrant"}}); err != nil {
+		t.Fatalf("failed to add workspace: %v", err)
+	}
+	if err := config.SaveWorkspaceConfig(wsCfg); err != nil {
+		t.Fatalf("failed to save workspace config: %v", err)
+	}
+
+	parent := t.TempDir()
+	mkdirs(t, filepath.Join(parent, "a", ".git"), filepath.Join(parent, "b", ".git"))
+
+	defer func() {
+		workspaceAddRecursive = false
+		workspaceAddYes = false
+	}()
+	workspaceAddRecursive = true
+	workspaceAddYes = true
+	if err := runWorkspaceAdd(nil, []string{"demo", parent}); err != nil {
+		t.Fatalf("runWorkspaceAdd() failed: %v", err)
+	}
+
+	loaded, err := config.LoadWorkspaceConfig()
+	if err != nil {
+		t.Fatalf("failed to load workspace config: %v", err)
+	}
+	ws, err := loaded.GetWorkspace("demo")
+	if err != nil {
+		t.Fatalf("failed to get workspace: %v", err)
+	}
+	if len(ws.Projects) != 2 {
+		t.Fatalf("projects = %+v, want 2", ws.Projects)
+	}
+}
+
+func TestEmbedderMismatch(t *testing.T) {
+	ws := config.DefaultEmbedderForProvider("ollama")
+	if diffs := embedderMismatch(ws, ws); len(diffs) != 0 {
+		t.Fatalf("identical embedders reported as %v", diffs)
+	}
+
+	project := config.DefaultEmbedderForProvider("openai")
+	if diffs := embedderMismatch(ws, project); len(diffs) != 3 {
+		t.Fatalf("diffs = %v, want provider, model and dimensions", diffs)
+	}
+
+	// Another endpoint for the same model keeps vectors compatible.
+	project = ws
+	project.Endpoint = "http://gpu-box:11434"
+	dim := 1024
+	project.Dimensions = &dim
+	diffs := embedderMismatch(ws, project)
+	if len(diffs) != 1 || !strings.Contains(diffs[0], "1024 dimensions") {
+		t.Fatalf("diffs = %v, want only the dimensions", diffs)
+	}
+}
+
+func TestReconcileProjectEmbedder_Inherit(t *testing.T) {
+	projectDir := t.TempDir()
+	projectCfg := config.DefaultConfig()
+	projectCfg.Embedder = config.DefaultEmbedderForProvider("openai")
+	if err := projectCfg.Save(projectDir); err != nil {
+		t.Fatalf("failed to save project config: %v", err)
+	}
+	ws := &config.Workspace{Name: "demo", Embedder: config.DefaultEmbedderForProvider("ollama")}
+	project := config.ProjectEntry{Name: "api", Path: projectDir}
+
+	defer func() { workspaceAddInherit = false }()
+	workspaceAddInherit = false
+	if err := reconcileProjectEmbedder(ws, project); err != nil {
+		t.Fatalf("reconcileProjectEmbedder() failed: %v", err)
+	}
+	loaded, err := config.Load(projectDir)
+	if err != nil {
+		t.Fatalf("failed to load project config: %v", err)
+	}
+	if loaded.Embedder.Provider != "openai" {
+		t.Fatal("project settings must be kept without --inherit-embedder outside a terminal")
+	}
+
+	workspaceAddInherit = true
+	if err := reconcileProjectEmbedder(ws, project); err != nil {
+		t.Fatalf("reconcileProjectEmbedder() failed: %v", err)
+	}
+	loaded, err = config.Load(projectDir)
+	if err != nil {
+		t.Fatalf("failed to load project config: %v", err)
+	}
+	if len(embedderMismatch(ws.Embedder, loaded.Embedder)) != 0 {
+		t.Fatalf("project embedder = %+v, want the workspace embedder", loaded.Embedder)
+	}
+}
+
+func TestWithProjectOverrides(t *testing.T) {
+	defer func() {
+		workspaceAddIgnore = nil
+		workspaceAddLanguages = nil
+	}()
+	workspaceAddIgnore = []string{"fixtures/**"}
+	workspaceAddLanguages = []string{"go", ".TS", " "}
+
+	p := withProjectOverrides(config.ProjectEntry{Name: "api", Path: "/src/api"})
+	if len(p.Ignore) != 1 || p.Ignore[0] != "fixtures/**" {
+		t.Fatalf("ignore = %v", p.Ignore)
+	}
+	if strings.Join(p.Languages, ",") != ".go,.ts" {
+		t.Fatalf("languages = %v, want [.go .ts]", p.Languages)
+	}
+}
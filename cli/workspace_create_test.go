@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"bufio"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/yoanbernabeu/grepai/config"
@@ -250,3 +252,115 @@ func TestIntegration_WorkspaceCreateAndMCPResolve(t *testing.T) {
 	}
 	_ = projectRoot
 }
+
+func TestBuildWorkspaceFromFlags_QdrantCloudUsesTLS(t *testing.T) {
+	ws, err := buildWorkspaceFromFlags("cloud-ws", "qdrant", "ollama", "", "", "", "https://abc-123.eu-central.aws.cloud.qdrant.io", 0, "", false)
+	if err != nil {
+		t.Fatalf("buildWorkspaceFromFlags error: %v", err)
+	}
+	if !ws.Store.Qdrant.UseTLS {
+		t.Error("expected TLS to be enabled for a Qdrant Cloud endpoint")
+	}
+	if ws.Store.Qdrant.Port != 6334 {
+		t.Errorf("expected default port 6334, got %d", ws.Store.Qdrant.Port)
+	}
+}
+
+func TestPromptQdrantConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var q config.QdrantConfig
+		reader := bufio.NewReader(strings.NewReader("\n\n\n\n\n"))
+		if err := promptQdrantConfig(reader, &q, "localhost"); err != nil {
+			t.Fatalf("failed to prompt: %v", err)
+		}
+		if q.Endpoint != "localhost" || q.Port != config.DefaultQdrantPort || q.UseTLS || q.APIKey != "" {
+			t.Errorf("unexpected config: %+v", q)
+		}
+	})
+
+	t.Run("cloud endpoint skips the TLS question", func(t *testing.T) {
+		var q config.QdrantConfig
+		input := "https://abc-123.eu-central.aws.cloud.qdrant.io\n\nrepos\nsecret\n"
+		if err := promptQdrantConfig(bufio.NewReader(strings.NewReader(input)), &q, "localhost"); err != nil {
+			t.Fatalf("failed to prompt: %v", err)
+		}
+		if !q.UseTLS {
+			t.Error("expected TLS to be enabled")
+		}
+		if q.Collection != "repos" || q.APIKey != "secret" {
+			t.Errorf("unexpected config: %+v", q)
+		}
+	})
+
+	t.Run("invalid port", func(t *testing.T) {
+		var q config.QdrantConfig
+		if err := promptQdrantConfig(bufio.NewReader(strings.NewReader("localhost\nabc\n")), &q, "localhost"); err == nil {
+			t.Fatal("expected an error for an invalid port")
+		}
+	})
+}
+
+func TestBuildWorkspaceFromFlags_Weaviate(t *testing.T) {
+	ws, err := buildWorkspaceFromFlags("team", "weaviate", "ollama", "", "", "", "", 0, "TeamCode", false)
+	if err != nil {
+		t.Fatalf("buildWorkspaceFromFlags error: %v", err)
+	}
+	if ws.Store.Backend != "weaviate" {
+		t.Errorf("expected weaviate backend, got %s", ws.Store.Backend)
+	}
+	if ws.Store.Weaviate.Endpoint != config.DefaultWeaviateEndpoint {
+		t.Errorf("expected default endpoint, got %s", ws.Store.Weaviate.Endpoint)
+	}
+	if ws.Store.Weaviate.Class != "TeamCode" {
+		t.Errorf("expected class TeamCode, got %s", ws.Store.Weaviate.Class)
+	}
+	if err := config.ValidateWorkspaceBackend(ws); err != nil {
+		t.Errorf("expected weaviate workspace to validate: %v", err)
+	}
+}
+
+func TestBuildWorkspaceFromFlags_Redis(t *testing.T) {
+	ws, err := buildWorkspaceFromFlags("team", "redis", "ollama", "", "", "", "", 0, "team_code", false)
+	if err != nil {
+		t.Fatalf("buildWorkspaceFromFlags error: %v", err)
+	}
+	if ws.Store.Redis.URL != config.DefaultRedisURL {
+		t.Errorf("expected default URL, got %s", ws.Store.Redis.URL)
+	}
+	if ws.Store.Redis.Index != "team_code" {
+		t.Errorf("expected index team_code, got %s", ws.Store.Redis.Index)
+	}
+	if err := config.ValidateWorkspaceBackend(ws); err != nil {
+		t.Errorf("expected redis workspace to validate: %v", err)
+	}
+}
+
+func TestPromptRedisConfig(t *testing.T) {
+	var r config.RedisConfig
+	if err := promptRedisConfig(bufio.NewReader(strings.NewReader("\nci_index\n24h\n")), &r); err != nil {
+		t.Fatalf("promptRedisConfig error: %v", err)
+	}
+	if r.URL != config.DefaultRedisURL || r.Index != "ci_index" || r.TTLSeconds != 86400 {
+		t.Errorf("unexpected config: %+v", r)
+	}
+
+	if err := promptRedisConfig(bufio.NewReader(strings.NewReader("\n\nsoon\n")), &r); err == nil {
+		t.Error("expected an error for an invalid expiry")
+	}
+}
+
+func TestBuildWorkspaceFromFlags_OpenSearch(t *testing.T) {
+	ws, err := buildWorkspaceFromFlags("team", "opensearch", "ollama", "", "", "", "", 0, "team-code", false)
+	if err != nil {
+		t.Fatalf("buildWorkspaceFromFlags error: %v", err)
+	}
+	if ws.Store.OpenSearch.Endpoint != config.DefaultOpenSearchEndpoint {
+		t.Errorf("expected default endpoint, got %s", ws.Store.OpenSearch.Endpoint)
+	}
+	if ws.Store.OpenSearch.Index != "team-code" {
+		t.Errorf("expected index team-code, got %s", ws.Store.OpenSearch.Index)
+	}
+	if err := config.ValidateWorkspaceBackend(ws); err != nil {
+		t.Errorf("expected opensearch workspace to validate: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/git"
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+)
+
+// worktreeRetentionCheckInterval is how often the watch supervisor purges
+// the indexes of removed worktrees whose retention period has expired.
+const worktreeRetentionCheckInterval = time.Hour
+
+// recordRemovedWorktree remembers that the linked worktree at root was
+// removed from the repository whose main worktree is mainRoot.
+func recordRemovedWorktree(mainRoot, root string, now time.Time) error {
+	if _, err := os.Stat(config.GetConfigDir(mainRoot)); err != nil {
+		return nil
+	}
+	removed, err := config.LoadRemovedWorktrees(mainRoot)
+	if err != nil {
+		return err
+	}
+	removed[root] = now
+	return config.SaveRemovedWorktrees(mainRoot, removed)
+}
+
+// worktreeRetentionPeriod returns how long the index of a removed worktree
+// is kept under cfg, and false when it is kept until cleaned by hand.
+func worktreeRetentionPeriod(cfg config.WatchConfig) (time.Duration, bool) {
+	switch cfg.WorktreeRetention {
+	case config.WorktreeRetentionDelete:
+		return 0, true
+	case config.WorktreeRetentionDays:
+		days := cfg.WorktreeRetentionDays
+		if days == 0 {
+			days = config.DefaultWorktreeRetentionDays
+		}
+		return time.Duration(days) * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// applyWorktreeRetention purges the indexes of removed worktrees of mainRoot
+// whose retention period under the project's watch.worktree_retention has
+// expired.
+func applyWorktreeRetention(mainRoot string) {
+	cfg, err := config.Load(mainRoot)
+	if err != nil {
+		return
+	}
+	period, ok := worktreeRetentionPeriod(cfg.Watch)
+	if !ok {
+		return
+	}
+	purged, err := purgeRemovedWorktrees(mainRoot, time.Now().Add(-period), false)
+	if err != nil {
+		log.Printf("Warning: failed to purge removed worktree indexes: %v", err)
+	}
+	for _, dir := range purged {
+		log.Printf("Removed index of removed worktree: %s", dir)
+	}
+}
+
+// purgeRemovedWorktrees deletes the .grepai directory of each recorded
+// removed worktree of mainRoot removed at or before cutoff, and forgets it.
+// Worktrees that are linked again are forgotten without deleting anything.
+// With dryRun, nothing is deleted or forgotten. It returns the directories
+// deleted, or that would be.
+func purgeRemovedWorktrees(mainRoot string, cutoff time.Time, dryRun bool) ([]string, error) {
+	removed, err := config.LoadRemovedWorktrees(mainRoot)
+	if err != nil || len(removed) == 0 {
+		return nil, err
+	}
+	linked, err := listLinkedWorktrees(mainRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	// Submodules dropped from the watch by watch.include_submodules are
+	// still in use.
+	submodules, _ := git.ListSubmodules(mainRoot)
+	active := make(map[string]bool, len(linked)+len(submodules))
+	for _, root := range append(linked, submodules...) {
+		active[fileutil.PathKey(canonicalPath(root))] = true
+	}
+
+	roots := make([]string, 0, len(removed))
+	for root := range removed {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	var purged []string
+	var firstErr error
+	for _, root := range roots {
+		if active[fileutil.PathKey(canonicalPath(root))] {
+			delete(removed, root)
+			continue
+		}
+		if removed[root].After(cutoff) {
+			continue
+		}
+		dir := config.GetConfigDir(root)
+		if _, err := os.Stat(dir); err == nil {
+			if dryRun {
+				purged = append(purged, dir)
+				continue
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to remove %s: %w", dir, err)
+				}
+				continue
+			}
+			purged = append(purged, dir)
+		}
+		delete(removed, root)
+	}
+	if dryRun {
+		return purged, nil
+	}
+	if err := config.SaveRemovedWorktrees(mainRoot, removed); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return purged, firstErr
+}
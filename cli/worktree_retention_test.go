@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestPurgeRemovedWorktrees(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	root := canonicalPath(t.TempDir())
+	runGit(t, root, "init")
+	runGit(t, root, "config", "user.email", "test@example.com")
+	runGit(t, root, "config", "user.name", "test")
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\nfunc main(){}\n"), 0644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+	runGit(t, root, "add", ".")
+	runGit(t, root, "commit", "-m", "init")
+	if err := config.DefaultConfig().Save(root); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	live := filepath.Join(canonicalPath(t.TempDir()), "live")
+	runGit(t, root, "worktree", "add", "-b", "live", live)
+	if err := os.MkdirAll(config.GetConfigDir(live), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Leftovers of worktrees that are no longer linked.
+	leftover := func() string {
+		dir := canonicalPath(t.TempDir())
+		if err := os.MkdirAll(config.GetConfigDir(dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	old, recent := leftover(), leftover()
+
+	now := time.Now()
+	for wtRoot, at := range map[string]time.Time{live: now.Add(-48 * time.Hour), old: now.Add(-48 * time.Hour), recent: now} {
+		if err := recordRemovedWorktree(root, wtRoot, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purged, err := purgeRemovedWorktrees(root, now.Add(-24*time.Hour), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(purged) != 1 || purged[0] != config.GetConfigDir(old) {
+		t.Fatalf("dry run purged = %v, want [%s]", purged, config.GetConfigDir(old))
+	}
+	if _, err := os.Stat(config.GetConfigDir(old)); err != nil {
+		t.Fatal("dry run must not delete anything")
+	}
+
+	if _, err := purgeRemovedWorktrees(root, now.Add(-24*time.Hour), false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(config.GetConfigDir(old)); !os.IsNotExist(err) {
+		t.Errorf("expected expired leftover to be deleted, stat err=%v", err)
+	}
+	for _, dir := range []string{live, recent} {
+		if _, err := os.Stat(config.GetConfigDir(dir)); err != nil {
+			t.Errorf("expected %s to be kept: %v", dir, err)
+		}
+	}
+
+	removed, err := config.LoadRemovedWorktrees(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 {
+		t.Errorf("expected only the recent removal to stay recorded, got %v", removed)
+	}
+	if _, ok := removed[recent]; !ok {
+		t.Errorf("expected %s to stay recorded, got %v", recent, removed)
+	}
+}
+
+func TestWorktreeRetentionPeriod(t *testing.T) {
+	if _, ok := worktreeRetentionPeriod(config.WatchConfig{}); ok {
+		t.Error("default policy must keep indexes")
+	}
+	if period, ok := worktreeRetentionPeriod(config.WatchConfig{WorktreeRetention: config.WorktreeRetentionDelete}); !ok || period != 0 {
+		t.Errorf("delete policy = %v, %v; want 0, true", period, ok)
+	}
+	if period, _ := worktreeRetentionPeriod(config.WatchConfig{WorktreeRetention: config.WorktreeRetentionDays}); period != 7*24*time.Hour {
+		t.Errorf("days policy default = %v, want 7 days", period)
+	}
+	if period, _ := worktreeRetentionPeriod(config.WatchConfig{WorktreeRetention: config.WorktreeRetentionDays, WorktreeRetentionDays: 2}); period != 48*time.Hour {
+		t.Errorf("days policy = %v, want 2 days", period)
+	}
+}
@@ -0,0 +1,337 @@
+// Package client is the Go API of grepai. It opens the index of a project
+// or workspace the way the CLI does — loading its configuration, connecting
+// the configured store and embedder — so Go programs can search and trace
+// code without shelling out to grepai or copying its wiring.
+//
+//	c, err := client.NewClient(ctx, client.Options{ProjectRoot: "/src/app"})
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	results, err := c.Search(ctx, "where are sessions invalidated", client.SearchOptions{Limit: 5})
+//
+// The index must have been built by 'grepai watch' (or 'grepai serve').
+// A client reads a snapshot of GOB indexes taken when it was created;
+// create a new client to see later updates.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// DefaultSearchLimit is the number of results Search returns when
+// SearchOptions.Limit is not set.
+const DefaultSearchLimit = 10
+
+// Options selects the index a Client opens. Exactly one of ProjectRoot and
+// Workspace must be set.
+type Options struct {
+	// ProjectRoot is a directory initialized with 'grepai init'.
+	ProjectRoot string
+	// Workspace is the name of a workspace in ~/.grepai/workspace.yaml.
+	Workspace string
+
+	// Embedder replaces the configured embedder, e.g. to share one between
+	// clients. The client does not close it.
+	Embedder embedder.Embedder
+}
+
+// SearchOptions refine a search.
+type SearchOptions struct {
+	// Limit is the maximum number of results (DefaultSearchLimit when 0).
+	Limit int
+	// Path only returns results under this path prefix, relative to the
+	// project root, or to the workspace ("project/dir") in workspace mode.
+	Path string
+}
+
+// IndexStatus summarizes the state of the opened index.
+type IndexStatus struct {
+	TotalFiles   int
+	TotalChunks  int
+	IndexSize    int64 // bytes
+	LastUpdated  time.Time
+	TotalSymbols int
+	Provider     string
+	Model        string
+}
+
+// Client searches and traces one project or workspace index. It is safe for
+// concurrent use.
+type Client struct {
+	projectRoot string
+	workspace   *config.Workspace
+	provider    string
+	model       string
+
+	emb      embedder.Embedder
+	ownsEmb  bool
+	docsEmb  embedder.Embedder // embeds queries for documentation chunks
+	store    store.VectorStore
+	symbols  []trace.SymbolStore
+	searcher *search.Searcher
+	// indexErr refuses searches of an index built with another embedder.
+	indexErr error
+}
+
+// NewClient opens the index selected by opts.
+func NewClient(ctx context.Context, opts Options) (*Client, error) {
+	switch {
+	case opts.ProjectRoot != "" && opts.Workspace != "":
+		return nil, errors.New("set either ProjectRoot or Workspace, not both")
+	case opts.ProjectRoot != "":
+		return newProjectClient(ctx, opts)
+	case opts.Workspace != "":
+		return newWorkspaceClient(ctx, opts)
+	default:
+		return nil, errors.New("ProjectRoot or Workspace is required")
+	}
+}
+
+func newProjectClient(ctx context.Context, opts Options) (*Client, error) {
+	if !config.Exists(opts.ProjectRoot) {
+		return nil, fmt.Errorf("no grepai project found at %s (run 'grepai init' first)", opts.ProjectRoot)
+	}
+	cfg, err := config.Load(opts.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	c := &Client{
+		projectRoot: opts.ProjectRoot,
+		provider:    cfg.Embedder.Provider,
+		model:       cfg.Embedder.Model,
+		indexErr:    config.CheckProjectIndexMetadata(opts.ProjectRoot, cfg),
+	}
+	if err := c.useEmbedder(opts.Embedder, func() (embedder.Embedder, error) { return embedder.NewFromConfig(cfg) }); err != nil {
+		return nil, err
+	}
+	c.docsEmb, err = embedder.NewDocsFromConfig(cfg)
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to initialize docs embedder: %w", err)
+	}
+	c.store, err = store.NewFromConfig(ctx, cfg, opts.ProjectRoot)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(opts.ProjectRoot))
+	if err := symbolStore.Load(ctx); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to load symbol index: %w", err)
+	}
+	c.symbols = []trace.SymbolStore{symbolStore}
+	c.searcher = search.NewProjectSearcher(c.store, c.emb, c.docsEmb, cfg)
+	return c, nil
+}
+
+func newWorkspaceClient(ctx context.Context, opts Options) (*Client, error) {
+	wsCfg, err := config.LoadWorkspaceConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace config: %w", err)
+	}
+	if wsCfg == nil {
+		return nil, fmt.Errorf("no workspaces configured; create one with: grepai workspace create <name>")
+	}
+	ws, err := wsCfg.GetWorkspace(opts.Workspace)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.ValidateWorkspaceBackend(ws); err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		workspace: ws,
+		provider:  ws.Embedder.Provider,
+		model:     ws.Embedder.Model,
+		indexErr:  config.CheckWorkspaceIndexMetadata(ws),
+	}
+	if err := c.useEmbedder(opts.Embedder, func() (embedder.Embedder, error) { return embedder.NewFromWorkspaceConfig(ws) }); err != nil {
+		return nil, err
+	}
+	c.store, err = store.NewFromWorkspaceConfig(ctx, ws)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.symbols, err = trace.LoadWorkspaceSymbolStores(ctx, ws.Name, "")
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.searcher, err = search.NewWorkspaceSearcher(c.store, c.emb, ws)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// useEmbedder sets emb, or the embedder built by create when emb is nil.
+func (c *Client) useEmbedder(emb embedder.Embedder, create func() (embedder.Embedder, error)) error {
+	if emb != nil {
+		c.emb = emb
+		return nil
+	}
+	emb, err := create()
+	if err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
+	}
+	c.emb = emb
+	c.ownsEmb = true
+	return nil
+}
+
+// Search returns the chunks most relevant to query. In workspace mode, file
+// paths are prefixed with "workspace/project/".
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) ([]store.SearchResult, error) {
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+	if c.indexErr != nil {
+		return nil, c.indexErr
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	if c.workspace != nil {
+		// Absolute paths resolve to a path inside one project; relative
+		// ones start with the project name.
+		normalized, projects, err := search.NormalizeWorkspacePathPrefix(opts.Path, c.workspace, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path: %w", err)
+		}
+		if len(projects) == 0 && normalized != "" {
+			project, rest, _ := strings.Cut(normalized, "/")
+			projects, normalized = []string{project}, rest
+		}
+		results, _, err := c.searcher.SearchWorkspace(ctx, query, limit, search.WorkspaceSearchOptions{
+			Projects:   projects,
+			PathPrefix: normalized,
+		})
+		return results, err
+	}
+	normalized, err := search.NormalizeProjectPathPrefix(opts.Path, c.projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	return c.searcher.Search(ctx, query, limit, normalized)
+}
+
+// TraceCallers returns the functions calling symbol. Qualified
+// ("Type.Method") and fuzzy queries are resolved like 'grepai trace
+// callers'; an ambiguous query returns the candidates and no callers.
+func (c *Client) TraceCallers(ctx context.Context, symbol string) (*trace.TraceResult, error) {
+	res, err := trace.ResolveSymbol(ctx, c.symbols, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve symbol: %w", err)
+	}
+	result := &trace.TraceResult{Query: symbol, Mode: "fast"}
+	if res.Ambiguous() {
+		result.Match = res.Match
+		result.Candidates = res.Candidates
+		return result, nil
+	}
+	name := res.Name
+	if name == "" {
+		name = symbol
+	}
+	if res.Match != "" && res.Match != trace.MatchExact {
+		result.Match = res.Match
+	}
+
+	for _, ss := range c.symbols {
+		symbols, err := ss.LookupSymbol(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup symbol: %w", err)
+		}
+		if len(symbols) > 0 && result.Symbol == nil {
+			result.Symbol = &symbols[0]
+		}
+		refs, err := ss.LookupCallers(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup callers: %w", err)
+		}
+		for _, ref := range refs {
+			caller := trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
+			if callerSyms, err := ss.LookupSymbol(ctx, ref.CallerName); err == nil {
+				for _, sym := range callerSyms {
+					if sym.File == ref.CallerFile {
+						caller = sym
+						break
+					}
+				}
+			}
+			result.Callers = append(result.Callers, trace.CallerInfo{
+				Symbol:   caller,
+				CallSite: trace.CallSite{File: ref.File, Line: ref.Line, Context: ref.Context},
+				Project:  trace.ProjectOf(ss),
+			})
+		}
+	}
+	if res.Symbol != nil && result.Symbol != nil {
+		sym := *res.Symbol
+		result.Symbol = &sym
+	}
+	result.Total = len(result.Callers)
+	return result, nil
+}
+
+// IndexStatus reports statistics of the vector and symbol indexes.
+func (c *Client) IndexStatus(ctx context.Context) (*IndexStatus, error) {
+	stats, err := c.store.GetStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index stats: %w", err)
+	}
+	status := &IndexStatus{
+		TotalFiles:  stats.TotalFiles,
+		TotalChunks: stats.TotalChunks,
+		IndexSize:   stats.IndexSize,
+		LastUpdated: stats.LastUpdated,
+		Provider:    c.provider,
+		Model:       c.model,
+	}
+	for _, ss := range c.symbols {
+		symbolStats, err := ss.GetStats(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get symbol stats: %w", err)
+		}
+		status.TotalSymbols += symbolStats.TotalSymbols
+	}
+	return status, nil
+}
+
+// Close releases the stores and the docs embedder, and the embedder unless
+// it was passed in Options. GOB indexes are released without persisting
+// them, so the snapshot does not overwrite later updates.
+func (c *Client) Close() error {
+	var errs []error
+	trace.ReleaseSymbolStores(c.symbols)
+	if gs, ok := c.store.(*store.GOBStore); ok {
+		gs.Release()
+	} else if c.store != nil {
+		errs = append(errs, c.store.Close())
+	}
+	if c.emb != nil && c.ownsEmb {
+		errs = append(errs, c.emb.Close())
+	}
+	if c.docsEmb != nil {
+		errs = append(errs, c.docsEmb.Close())
+	}
+	return errors.Join(errs...)
+}
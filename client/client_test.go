@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// fakeEmbedder embeds every text as the same unit vector.
+type fakeEmbedder struct{ closed bool }
+
+func (f *fakeEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return []float32{1, 0, 0}, nil
+}
+
+func (f *fakeEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{1, 0, 0}
+	}
+	return vectors, nil
+}
+
+func (f *fakeEmbedder) Dimensions() int { return 3 }
+
+func (f *fakeEmbedder) Close() error {
+	f.closed = true
+	return nil
+}
+
+// newTestProject creates a project with a GOB index of two chunks and a
+// symbol index where Handle calls Login.
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".grepai"), 0755); err != nil {
+		t.Fatalf("failed to create .grepai: %v", err)
+	}
+	if err := config.DefaultConfig().Save(root); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	st := store.NewGOBStore(config.GetIndexPath(root))
+	chunks := []store.Chunk{
+		{ID: "auth/login.go_0", FilePath: "auth/login.go", StartLine: 1, EndLine: 5, Content: "func Login() {}", Vector: []float32{1, 0, 0}},
+		{ID: "api/handler.go_0", FilePath: "api/handler.go", StartLine: 1, EndLine: 8, Content: "func Handle() { Login() }", Vector: []float32{0.5, 0.5, 0}},
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	for _, c := range chunks {
+		if err := st.SaveDocument(ctx, store.Document{Path: c.FilePath, ChunkIDs: []string{c.ID}}); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+	if err := st.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist index: %v", err)
+	}
+
+	ss := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(root))
+	if err := ss.SaveFile(ctx, "auth/login.go", []trace.Symbol{{Name: "Login", Kind: trace.KindFunction, File: "auth/login.go", Line: 1, Language: "go"}}, nil); err != nil {
+		t.Fatalf("failed to save symbols: %v", err)
+	}
+	if err := ss.SaveFile(ctx, "api/handler.go",
+		[]trace.Symbol{{Name: "Handle", Kind: trace.KindFunction, File: "api/handler.go", Line: 1, Language: "go"}},
+		[]trace.Reference{{SymbolName: "Login", Kind: trace.RefKindCall, File: "api/handler.go", Line: 2, Context: "Login()", CallerName: "Handle", CallerFile: "api/handler.go", CallerLine: 1}},
+	); err != nil {
+		t.Fatalf("failed to save symbols: %v", err)
+	}
+	if err := ss.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist symbols: %v", err)
+	}
+	return root
+}
+
+func TestNewClient_RequiresOneTarget(t *testing.T) {
+	ctx := context.Background()
+	if _, err := NewClient(ctx, Options{}); err == nil {
+		t.Error("expected an error without ProjectRoot or Workspace")
+	}
+	if _, err := NewClient(ctx, Options{ProjectRoot: "/tmp", Workspace: "ws"}); err == nil {
+		t.Error("expected an error with both ProjectRoot and Workspace")
+	}
+	if _, err := NewClient(ctx, Options{ProjectRoot: t.TempDir()}); err == nil {
+		t.Error("expected an error for a directory without .grepai")
+	}
+}
+
+func TestClient_Project(t *testing.T) {
+	ctx := context.Background()
+	emb := &fakeEmbedder{}
+	c, err := NewClient(ctx, Options{ProjectRoot: newTestProject(t), Embedder: emb})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	results, err := c.Search(ctx, "login", SearchOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "auth/login.go" {
+		t.Errorf("expected auth/login.go first, got %+v", results)
+	}
+
+	results, err = c.Search(ctx, "login", SearchOptions{Path: "api/"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "api/handler.go" {
+		t.Errorf("expected only api/handler.go under api/, got %+v", results)
+	}
+
+	result, err := c.TraceCallers(ctx, "Login")
+	if err != nil {
+		t.Fatalf("TraceCallers failed: %v", err)
+	}
+	if result.Symbol == nil || result.Symbol.File != "auth/login.go" {
+		t.Errorf("expected the Login symbol, got %+v", result.Symbol)
+	}
+	if len(result.Callers) != 1 || result.Callers[0].Symbol.Name != "Handle" || result.Callers[0].CallSite.Line != 2 {
+		t.Errorf("expected Handle calling at line 2, got %+v", result.Callers)
+	}
+
+	status, err := c.IndexStatus(ctx)
+	if err != nil {
+		t.Fatalf("IndexStatus failed: %v", err)
+	}
+	if status.TotalChunks != 2 || status.TotalSymbols != 2 {
+		t.Errorf("expected 2 chunks and 2 symbols, got %+v", status)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if emb.closed {
+		t.Error("expected a caller-provided embedder to stay open")
+	}
+}
+
+func TestClient_CloseDoesNotRewriteIndexes(t *testing.T) {
+	ctx := context.Background()
+	root := newTestProject(t)
+	c, err := NewClient(ctx, Options{ProjectRoot: root, Embedder: &fakeEmbedder{}})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// A watcher saves the indexes while the client is open.
+	updated := []byte("saved by grepai watch")
+	paths := []string{config.GetIndexPath(root), config.GetSymbolIndexPath(root)}
+	for _, path := range paths {
+		if err := os.WriteFile(path, updated, 0644); err != nil {
+			t.Fatalf("failed to update %s: %v", path, err)
+		}
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		if string(data) != string(updated) {
+			t.Errorf("expected Close not to rewrite %s", filepath.Base(path))
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// BudgetExclusionsFileName records, in the .grepai directory, the files the
+// last full index pass left out to stay within indexer.budget.
+const BudgetExclusionsFileName = "budget-excluded.json"
+
+// BudgetExclusion is a file left out of the index by the budget.
+type BudgetExclusion struct {
+	Path   string `json:"path"`
+	Chunks int    `json:"chunks"`
+	Reason string `json:"reason"`
+}
+
+// GetBudgetExclusionsPath returns the path to the budget exclusions file of
+// the project at projectRoot.
+func GetBudgetExclusionsPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), BudgetExclusionsFileName)
+}
+
+// LoadBudgetExclusions returns the files excluded by the budget in the last
+// full index pass of projectRoot. Returns nil if none are recorded.
+func LoadBudgetExclusions(projectRoot string) ([]BudgetExclusion, error) {
+	data, err := os.ReadFile(GetBudgetExclusionsPath(projectRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read budget exclusions: %w", err)
+	}
+	var excluded []BudgetExclusion
+	if err := json.Unmarshal(data, &excluded); err != nil {
+		return nil, fmt.Errorf("failed to parse budget exclusions: %w", err)
+	}
+	return excluded, nil
+}
+
+// SaveBudgetExclusions writes the budget exclusions for projectRoot. An
+// empty list deletes the file.
+func SaveBudgetExclusions(projectRoot string, excluded []BudgetExclusion) error {
+	path := GetBudgetExclusionsPath(projectRoot)
+	if len(excluded) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove budget exclusions file: %w", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(excluded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal budget exclusions: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write budget exclusions: %w", err)
+	}
+	return nil
+}
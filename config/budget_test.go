@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestBudgetExclusionsRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(GetConfigDir(root), 0755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+
+	excluded, err := LoadBudgetExclusions(root)
+	if err != nil || excluded != nil {
+		t.Fatalf("expected no exclusions, got %v (err %v)", excluded, err)
+	}
+
+	want := []BudgetExclusion{{Path: "vendor/a.go", Chunks: 12, Reason: "vendor/ cap of 10 chunks"}}
+	if err := SaveBudgetExclusions(root, want); err != nil {
+		t.Fatalf("failed to save exclusions: %v", err)
+	}
+	got, err := LoadBudgetExclusions(root)
+	if err != nil {
+		t.Fatalf("failed to load exclusions: %v", err)
+	}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	if err := SaveBudgetExclusions(root, nil); err != nil {
+		t.Fatalf("failed to clear exclusions: %v", err)
+	}
+	if _, err := os.Stat(GetBudgetExclusionsPath(root)); !os.IsNotExist(err) {
+		t.Errorf("expected exclusions file to be removed, got %v", err)
+	}
+}
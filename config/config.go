@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,11 +14,19 @@ import (
 )
 
 const (
-	ConfigDir           = ".grepai"
-	ConfigFileName      = "config.yaml"
-	IndexFileName       = "index.gob"
-	SymbolIndexFileName = "symbols.gob"
-	RPGIndexFileName    = "rpg.gob"
+	ConfigDir            = ".grepai"
+	ConfigFileName       = "config.yaml"
+	IndexFileName        = "index.gob"
+	SymbolIndexFileName  = "symbols.gob"
+	TodoIndexFileName    = "todos.gob"
+	RPGIndexFileName     = "rpg.gob"
+	BlameCacheFileName   = "blame.json"
+	IndexSignalFileName  = "index.updated"
+	WatchLogDirFileName  = "watch-log-dir"
+	LastSearchFileName   = "last-search.json"
+	CoverageFileName     = "coverage.json"
+	WatchJournalFileName = "watch-journal.jsonl"
+	SummaryCacheFileName = "summaries.jsonl"
 
 	DefaultEmbedderProvider         = "ollama"
 	DefaultOllamaEmbeddingModel     = "nomic-embed-text"
@@ -44,12 +54,65 @@ const (
 	DefaultQdrantEndpoint = "localhost"
 	DefaultQdrantPort     = 6334
 
+	DefaultWeaviateEndpoint   = "http://localhost:8080"
+	DefaultRedisURL           = "redis://localhost:6379"
+	DefaultOpenSearchEndpoint = "http://localhost:9200"
+
 	// RPG default configuration values.
 	DefaultRPGDriftThreshold       = 0.35
 	DefaultRPGMaxTraversalDepth    = 3
 	DefaultRPGLLMTimeoutMs         = 8000
 	DefaultRPGFeatureMode          = "local"
 	DefaultRPGFeatureGroupStrategy = "sample"
+	DefaultRPGRebuildIntervalMin   = 60
+
+	// DefaultMCPAutoCompactTokens is the response size, in estimated tokens,
+	// above which MCP search and trace responses switch to compact output.
+	DefaultMCPAutoCompactTokens = 8000
+	// DefaultMCPCacheTTLSeconds is how long the MCP server caches query
+	// embeddings and search results.
+	DefaultMCPCacheTTLSeconds = 300
+
+	// RPG rebuild policies (rpg.rebuild).
+	RPGRebuildOnChange  = "on_change" // incremental updates on every file change
+	RPGRebuildScheduled = "scheduled" // full rebuild every rebuild_interval_min when files changed
+	RPGRebuildManual    = "manual"    // only rebuilt by 'grepai rpg rebuild'
+
+	// TUI color themes (ui.theme).
+	UIThemeAuto   = "auto" // dark or light, from the terminal background
+	UIThemeDark   = "dark"
+	UIThemeLight  = "light"
+	UIThemeCustom = "custom" // ui.palette applied over the detected base theme
+
+	// Symlinked directory follow policies (indexer.follow_symlinks).
+	FollowSymlinksFalse = "false" // never descend into symlinked directories
+	FollowSymlinksSafe  = "safe"  // only links whose target is inside the project
+	FollowSymlinksAll   = "all"   // every link, including targets outside the project
+
+	// Change detection modes (indexer.change_detection).
+	ChangeDetectionMtimeHash = "mtime+hash" // skip files not modified since the last index run, hash the others
+	ChangeDetectionMtime     = "mtime"      // skip files whose modification time is the one they were indexed with
+	ChangeDetectionHash      = "hash"       // hash every file, ignoring modification times
+
+	// Watch power profiles (watch.power_profile).
+	PowerProfilePerformance = "performance" // never throttle background indexing
+	PowerProfileBalanced    = "balanced"    // throttle on battery or under high CPU load
+	PowerProfileSaver       = "saver"       // throttle on battery or under moderate CPU load
+
+	// File event backends (watch.backend).
+	WatchBackendAuto   = "auto"   // native events, falling back to polling when the watch limit is hit
+	WatchBackendNative = "native" // inotify, FSEvents/kqueue or ReadDirectoryChangesW
+	WatchBackendPoll   = "poll"   // periodic directory scans, for network filesystems and containers
+
+	DefaultWatchPollIntervalMs = 2000
+
+	// Retention policies for the index of a removed linked worktree
+	// (watch.worktree_retention).
+	WorktreeRetentionKeep   = "keep"   // leave it until 'grepai clean --worktrees'
+	WorktreeRetentionDelete = "delete" // delete it as soon as the worktree is removed
+	WorktreeRetentionDays   = "days"   // delete it worktree_retention_days after removal
+
+	DefaultWorktreeRetentionDays = 7
 
 	// Watch defaults for RPG realtime updates.
 	DefaultWatchRPGPersistIntervalMs      = 1000
@@ -59,18 +122,112 @@ const (
 )
 
 type Config struct {
-	Version           int             `yaml:"version"`
-	Embedder          EmbedderConfig  `yaml:"embedder"`
-	Store             StoreConfig     `yaml:"store"`
-	Chunking          ChunkingConfig  `yaml:"chunking"`
-	Framework         FrameworkConfig `yaml:"framework_processing"`
-	Watch             WatchConfig     `yaml:"watch"`
-	Search            SearchConfig    `yaml:"search"`
-	Trace             TraceConfig     `yaml:"trace"`
-	RPG               RPGConfig       `yaml:"rpg"`
-	Update            UpdateConfig    `yaml:"update"`
-	Ignore            []string        `yaml:"ignore"`
-	ExternalGitignore string          `yaml:"external_gitignore,omitempty"`
+	Version           int              `yaml:"version"`
+	Embedder          EmbedderConfig   `yaml:"embedder"`
+	Store             StoreConfig      `yaml:"store"`
+	Chunking          ChunkingConfig   `yaml:"chunking"`
+	Indexer           IndexerConfig    `yaml:"indexer"`
+	Framework         FrameworkConfig  `yaml:"framework_processing"`
+	Watch             WatchConfig      `yaml:"watch"`
+	Search            SearchConfig     `yaml:"search"`
+	Trace             TraceConfig      `yaml:"trace"`
+	RPG               RPGConfig        `yaml:"rpg"`
+	Update            UpdateConfig     `yaml:"update"`
+	UI                UIConfig         `yaml:"ui"`
+	Repository        RepositoryConfig `yaml:"repository,omitempty"`
+	MCP               MCPConfig        `yaml:"mcp,omitempty"`
+	Telemetry         TelemetryConfig  `yaml:"telemetry,omitempty"`
+	Insights          InsightsConfig   `yaml:"insights,omitempty"`
+	Scopes            map[string]Scope `yaml:"scopes,omitempty"`
+	Ignore            []string         `yaml:"ignore"`
+	ExternalGitignore string           `yaml:"external_gitignore,omitempty"`
+}
+
+// Scope is a named set of file filters that search and trace apply with
+// --scope. Paths and languages use the search query syntax: paths match the
+// start of the file path or any of its directories, and languages are names
+// or extensions. Empty lists keep every file.
+type Scope struct {
+	Paths        []string `yaml:"paths,omitempty"`
+	Languages    []string `yaml:"languages,omitempty"`
+	ExcludePaths []string `yaml:"exclude_paths,omitempty"`
+	ExcludeTests bool     `yaml:"exclude_tests,omitempty"`
+}
+
+// GetScope returns the scope with the given name.
+func (c *Config) GetScope(name string) (Scope, error) {
+	if scope, ok := c.Scopes[name]; ok {
+		return scope, nil
+	}
+	if len(c.Scopes) == 0 {
+		return Scope{}, fmt.Errorf("scope %q not found: no scopes are defined in %s", name, ConfigFileName)
+	}
+	names := make([]string, 0, len(c.Scopes))
+	for n := range c.Scopes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return Scope{}, fmt.Errorf("scope %q not found; defined scopes: %s", name, strings.Join(names, ", "))
+}
+
+// RepositoryConfig describes where the project's code is hosted.
+type RepositoryConfig struct {
+	// URLTemplate builds permalinks for search results, e.g.
+	// "https://github.com/org/repo/blob/{commit}/{path}#L{start_line}-L{end_line}".
+	// Placeholders: {commit}, {branch}, {path}, {start_line}, {end_line}.
+	URLTemplate string `yaml:"url_template,omitempty"`
+}
+
+// MCPConfig holds MCP server settings.
+type MCPConfig struct {
+	// AutoCompactTokens switches search and trace responses to compact
+	// output when the full response would exceed this many tokens, unless
+	// the caller sets compact or max_tokens. A negative value disables it.
+	AutoCompactTokens int `yaml:"auto_compact_tokens,omitempty"`
+
+	// CacheTTLSeconds is how long the server reuses query embeddings and
+	// search results. Cached results are also dropped as soon as the index
+	// changes. A negative value disables caching.
+	CacheTTLSeconds int `yaml:"cache_ttl_seconds,omitempty"`
+
+	// AutoIndex makes the server build the index in the background when a
+	// search finds the project has none, instead of only reporting it.
+	AutoIndex bool `yaml:"auto_index,omitempty"`
+
+	// PathMappings translates absolute paths between the server's and the
+	// client's filesystems when one of them runs in a container.
+	PathMappings []PathMapping `yaml:"path_mappings,omitempty"`
+
+	// RateLimit caps each token of 'grepai serve --listen' so one client
+	// cannot starve the others.
+	RateLimit RateLimitConfig `yaml:"rate_limit,omitempty"`
+
+	// Access hides paths from the search and trace tools, so sensitive
+	// directories can stay indexed for people without reaching agents.
+	Access AccessConfig `yaml:"access,omitempty"`
+}
+
+// AccessConfig selects the project paths the MCP tools may return, as
+// gitignore-style patterns. Deny wins over Allow; an empty Allow allows
+// every path not denied.
+type AccessConfig struct {
+	Allow []string `yaml:"allow,omitempty"`
+	Deny  []string `yaml:"deny,omitempty"`
+}
+
+// RateLimitConfig limits the requests of each remote client token. Zero
+// values disable the corresponding limit.
+type RateLimitConfig struct {
+	RequestsPerMinute int `yaml:"requests_per_minute,omitempty"`
+	Burst             int `yaml:"burst,omitempty"`
+	MaxConcurrent     int `yaml:"max_concurrent,omitempty"`
+}
+
+// PathMapping pairs the same directory as seen from the host and from inside
+// a container or devcontainer.
+type PathMapping struct {
+	HostRoot      string `yaml:"host_root"`
+	ContainerRoot string `yaml:"container_root"`
 }
 
 // UpdateConfig holds auto-update settings
@@ -78,6 +235,202 @@ type UpdateConfig struct {
 	CheckOnStartup bool `yaml:"check_on_startup"` // Check for updates when running commands
 }
 
+// TelemetryConfig holds the opt-in local usage metrics settings.
+type TelemetryConfig struct {
+	// Enabled records operation counts and timings to .grepai/telemetry.json,
+	// shown by grepai stats --telemetry. Nothing is sent over the network.
+	Enabled bool `yaml:"enabled,omitempty"`
+}
+
+// DefaultInsightsMinScore is the similarity below which the nearest chunk
+// makes a search a miss.
+const DefaultInsightsMinScore = 0.5
+
+// InsightsConfig holds the opt-in search miss recording settings.
+type InsightsConfig struct {
+	// Enabled records searches with low-confidence results, and the files
+	// edited after them, to .grepai/insights.json, shown by grepai insights.
+	// Unlike telemetry, it records queries.
+	Enabled bool `yaml:"enabled,omitempty"`
+	// MinScore is the similarity below which the chunk nearest to the query
+	// makes a search a miss (default 0.5).
+	MinScore float64 `yaml:"min_score,omitempty"`
+}
+
+// GetMinScore returns the miss threshold, or its default when unset.
+func (c InsightsConfig) GetMinScore() float64 {
+	if c.MinScore <= 0 {
+		return DefaultInsightsMinScore
+	}
+	return c.MinScore
+}
+
+// UIConfig holds settings shared by the interactive terminal UIs.
+type UIConfig struct {
+	Theme   string    `yaml:"theme"` // auto | dark | light | custom
+	Palette UIPalette `yaml:"palette,omitempty"`
+	// ASCII enables plain mode, like --plain: linear ASCII output without
+	// colors or full-screen UIs, for screen readers and limited terminals.
+	ASCII bool `yaml:"ascii,omitempty"`
+	// SyntaxHighlight colors the code shown by the TUIs by language. Turning
+	// it off speeds up rendering on slow terminals.
+	SyntaxHighlight    bool `yaml:"syntax_highlight"`
+	syntaxHighlightSet bool `yaml:"-"`
+}
+
+func (c *UIConfig) UnmarshalYAML(value *yaml.Node) error {
+	type raw UIConfig
+	var aux raw
+	if err := value.Decode(&aux); err != nil {
+		return err
+	}
+	*c = UIConfig(aux)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == "syntax_highlight" {
+			c.syntaxHighlightSet = true
+			break
+		}
+	}
+	return nil
+}
+
+// UIPalette overrides theme colors when ui.theme is custom. Colors are hex
+// values (#RGB or #RRGGBB) or ANSI 256 color numbers; empty keeps the base color.
+type UIPalette struct {
+	Foreground string `yaml:"foreground,omitempty"`
+	Background string `yaml:"background,omitempty"`
+	Border     string `yaml:"border,omitempty"`
+	Title      string `yaml:"title,omitempty"`
+	Subtitle   string `yaml:"subtitle,omitempty"`
+	Muted      string `yaml:"muted,omitempty"`
+	Help       string `yaml:"help,omitempty"`
+	Accent     string `yaml:"accent,omitempty"`
+	Success    string `yaml:"success,omitempty"`
+	Warning    string `yaml:"warning,omitempty"`
+	Danger     string `yaml:"danger,omitempty"`
+}
+
+// IndexerConfig controls which files the scanner walks into.
+type IndexerConfig struct {
+	// FollowSymlinks sets how symlinked directories are handled: false, safe
+	// or all. Cycles and links to already indexed directories are skipped.
+	FollowSymlinks string `yaml:"follow_symlinks"`
+	// IgnoreSparseCheckout indexes every file on disk, including directories
+	// excluded by the git sparse-checkout patterns.
+	IgnoreSparseCheckout bool `yaml:"ignore_sparse_checkout,omitempty"`
+	// FileHandlers toggles the preprocessors applied to specific file types.
+	FileHandlers FileHandlersConfig `yaml:"file_handlers"`
+	// ContentFilters toggles the rules that skip binary, minified and
+	// generated files from their content.
+	ContentFilters ContentFiltersConfig `yaml:"content_filters"`
+	// Budget caps the number of chunks kept in the index. Disabled by default.
+	Budget BudgetConfig `yaml:"budget,omitempty"`
+	// ChangeDetection sets how changed files are found: mtime+hash (the
+	// default), mtime or hash, for file systems with unreliable mtimes.
+	ChangeDetection string `yaml:"change_detection,omitempty"`
+}
+
+// BudgetConfig limits the size of the index. When a full index pass goes
+// over a limit, the lowest-priority files are left out.
+type BudgetConfig struct {
+	// MaxChunks caps the total number of chunks. Zero means no limit.
+	MaxChunks int `yaml:"max_chunks,omitempty"`
+	// Paths caps the number of chunks under specific directories.
+	Paths []PathBudget `yaml:"paths,omitempty"`
+	// Priorities ranks the files kept when a cap is reached: files under a
+	// directory with a higher priority are kept first.
+	Priorities []PathPriority `yaml:"priorities,omitempty"`
+}
+
+// PathBudget caps the chunks of the files under Path, a directory relative
+// to the project root.
+type PathBudget struct {
+	Path      string `yaml:"path"`
+	MaxChunks int    `yaml:"max_chunks"`
+}
+
+// PathPriority sets the budget priority of the files under Path, a
+// directory relative to the project root.
+type PathPriority struct {
+	Path     string `yaml:"path"`
+	Priority int    `yaml:"priority"`
+}
+
+// Enabled reports whether any budget limit is set.
+func (b BudgetConfig) Enabled() bool {
+	return b.MaxChunks > 0 || len(b.Paths) > 0
+}
+
+// Priority returns the budget priority of the project-relative path: that
+// of the longest listed directory containing it, or 0.
+func (b BudgetConfig) Priority(path string) float32 {
+	path = filepath.ToSlash(path)
+	priority, longest := 0, -1
+	for _, p := range b.Priorities {
+		dir := strings.Trim(filepath.ToSlash(p.Path), "/")
+		if len(dir) > longest && strings.HasPrefix(path, dir+"/") {
+			priority, longest = p.Priority, len(dir)
+		}
+	}
+	return float32(priority)
+}
+
+// FileHandlersConfig toggles the built-in file handlers.
+type FileHandlersConfig struct {
+	// Notebooks indexes code and markdown cells of .ipynb files, without outputs.
+	Notebooks FileHandlerConfig `yaml:"notebooks"`
+	// Schemas chunks .proto and .graphql files at top-level definitions.
+	Schemas FileHandlerConfig `yaml:"schemas"`
+	// Minified skips code and data files, such as .js, .css and .json,
+	// that look minified from their content.
+	Minified FileHandlerConfig `yaml:"minified"`
+	// Documents extracts text from PDF and DOCX files. Disabled by default.
+	Documents DocumentsHandlerConfig `yaml:"documents"`
+}
+
+// ContentFiltersConfig toggles the content rules. Every rule is enabled by
+// default.
+type ContentFiltersConfig struct {
+	// Binary samples the start of each file for NUL bytes, invalid UTF-8
+	// and control characters. Disabled, only files with a NUL byte or
+	// invalid UTF-8 anywhere are skipped.
+	Binary FileHandlerConfig `yaml:"binary"`
+	// Generated skips files with a marker such as "DO NOT EDIT" or
+	// "@generated" in their header.
+	Generated FileHandlerConfig `yaml:"generated"`
+	// TextExtensions are always indexed as text: Latin-1 content in them
+	// is converted to UTF-8 and other invalid UTF-8 is replaced.
+	TextExtensions []string `yaml:"text_extensions,omitempty"`
+}
+
+// DocumentsHandlerConfig enables PDF and DOCX extraction under Paths, or in
+// the whole project when Paths is empty.
+type DocumentsHandlerConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Paths   []string `yaml:"paths,omitempty"`
+}
+
+type FileHandlerConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	enabledSet bool `yaml:"-"`
+}
+
+func (c *FileHandlerConfig) UnmarshalYAML(value *yaml.Node) error {
+	type raw FileHandlerConfig
+	var aux raw
+	if err := value.Decode(&aux); err != nil {
+		return err
+	}
+	*c = FileHandlerConfig(aux)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == "enabled" {
+			c.enabledSet = true
+			break
+		}
+	}
+	return nil
+}
+
 type SearchConfig struct {
 	Boost  BoostConfig  `yaml:"boost"`
 	Hybrid HybridConfig `yaml:"hybrid"`
@@ -98,6 +451,11 @@ type BoostConfig struct {
 	Enabled   bool        `yaml:"enabled"`
 	Penalties []BoostRule `yaml:"penalties"`
 	Bonuses   []BoostRule `yaml:"bonuses"`
+	// Uncovered, when set, multiplies the score of code no test runs once a
+	// coverage profile is imported: above 1 it surfaces untested code, below
+	// 1 it demotes it. Partly covered code gets the factor in proportion to
+	// its uncovered lines. 0 leaves scores alone.
+	Uncovered float32 `yaml:"uncovered,omitempty"`
 }
 
 type BoostRule struct {
@@ -105,6 +463,23 @@ type BoostRule struct {
 	Factor  float32 `yaml:"factor"`
 }
 
+// Factor returns the combined boost factor of filePath: the product of the
+// factors of every penalty and bonus whose pattern it contains.
+func (b BoostConfig) Factor(filePath string) float32 {
+	factor := float32(1.0)
+	for _, rule := range b.Penalties {
+		if strings.Contains(filePath, rule.Pattern) {
+			factor *= rule.Factor
+		}
+	}
+	for _, rule := range b.Bonuses {
+		if strings.Contains(filePath, rule.Pattern) {
+			factor *= rule.Factor
+		}
+	}
+	return factor
+}
+
 type EmbedderConfig struct {
 	Provider    string `yaml:"provider"` // ollama | lmstudio | openai | synthetic | openrouter
 	Model       string `yaml:"model"`
@@ -112,6 +487,38 @@ type EmbedderConfig struct {
 	APIKey      string `yaml:"api_key,omitempty"`
 	Dimensions  *int   `yaml:"dimensions,omitempty"`
 	Parallelism int    `yaml:"parallelism"` // Number of parallel workers for batch embedding (default: 4)
+	// PricePerMillionTokens is the price in USD of a million input tokens,
+	// used to estimate the cost of indexing with a paid provider. Known
+	// OpenAI models default to their list price.
+	PricePerMillionTokens float64 `yaml:"price_per_million_tokens,omitempty"`
+	// Docs, when set, embeds documentation files (Markdown, text, PDF...)
+	// with its own model, while this embedder keeps embedding code. Searches
+	// embed the query with both and fuse the two rankings.
+	Docs *EmbedderConfig `yaml:"docs,omitempty"`
+}
+
+// embeddingPrices are the list prices, in USD per million input tokens, of
+// known paid embedding models.
+var embeddingPrices = map[string]float64{
+	DefaultOpenAIEmbeddingModel:     0.02,
+	OpenAIEmbeddingModelLarge:       0.13,
+	"text-embedding-ada-002":        0.10,
+	DefaultOpenRouterEmbeddingModel: 0.02,
+	OpenRouterEmbeddingModelLarge:   0.13,
+}
+
+// Price returns the price in USD of a million input tokens sent to the
+// embedder, and false when it is not known.
+func (e *EmbedderConfig) Price() (float64, bool) {
+	if e.PricePerMillionTokens > 0 {
+		return e.PricePerMillionTokens, true
+	}
+	switch e.Provider {
+	case "openai", "openrouter":
+		price, ok := embeddingPrices[strings.TrimSpace(e.Model)]
+		return price, ok
+	}
+	return 0, false
 }
 
 // GetDimensions returns the configured dimensions or a default value.
@@ -183,9 +590,21 @@ func DefaultEmbedderForProvider(provider string) EmbedderConfig {
 }
 
 type StoreConfig struct {
-	Backend  string         `yaml:"backend"` // gob | postgres | qdrant
-	Postgres PostgresConfig `yaml:"postgres,omitempty"`
-	Qdrant   QdrantConfig   `yaml:"qdrant,omitempty"`
+	Backend    string           `yaml:"backend"` // gob | postgres | qdrant | weaviate | redis | opensearch
+	GOB        GOBConfig        `yaml:"gob,omitempty"`
+	Postgres   PostgresConfig   `yaml:"postgres,omitempty"`
+	Qdrant     QdrantConfig     `yaml:"qdrant,omitempty"`
+	Weaviate   WeaviateConfig   `yaml:"weaviate,omitempty"`
+	Redis      RedisConfig      `yaml:"redis,omitempty"`
+	OpenSearch OpenSearchConfig `yaml:"opensearch,omitempty"`
+}
+
+// GOBConfig configures the gob backend.
+type GOBConfig struct {
+	// LazyContent keeps chunk content on disk once the index is loaded and
+	// reads it only for the results returned, so searches hold little more
+	// than the vectors in memory.
+	LazyContent bool `yaml:"lazy_content,omitempty"`
 }
 
 type PostgresConfig struct {
@@ -200,9 +619,64 @@ type QdrantConfig struct {
 	UseTLS     bool   `yaml:"use_tls,omitempty"`    // Enable TLS (for Qdrant Cloud)
 }
 
+// QdrantCloudDomain is the domain of Qdrant Cloud cluster endpoints.
+const QdrantCloudDomain = "cloud.qdrant.io"
+
+// IsQdrantCloudEndpoint reports whether endpoint is a Qdrant Cloud cluster,
+// with or without a scheme and port.
+func IsQdrantCloudEndpoint(endpoint string) bool {
+	host := strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
+	if idx := strings.IndexAny(host, ":/"); idx != -1 {
+		host = host[:idx]
+	}
+	host = strings.ToLower(host)
+	return host == QdrantCloudDomain || strings.HasSuffix(host, "."+QdrantCloudDomain)
+}
+
+// TLSEnabled reports whether connections to the endpoint use TLS: when
+// use_tls is set, the endpoint is an https URL, or it is a Qdrant Cloud
+// cluster, which only accepts TLS.
+func (q QdrantConfig) TLSEnabled() bool {
+	return q.UseTLS || strings.HasPrefix(q.Endpoint, "https://") || IsQdrantCloudEndpoint(q.Endpoint)
+}
+
+type WeaviateConfig struct {
+	Endpoint string `yaml:"endpoint"`          // e.g., "http://localhost:8080"
+	Class    string `yaml:"class,omitempty"`   // Optional, defaults from project path
+	APIKey   string `yaml:"api_key,omitempty"` // Optional, for Weaviate Cloud
+}
+
+// RedisConfig configures the redis backend, which needs the RediSearch
+// module (Redis Stack or Redis 8).
+type RedisConfig struct {
+	URL   string `yaml:"url"`             // e.g., "redis://:password@localhost:6379/0", rediss:// for TLS
+	Index string `yaml:"index,omitempty"` // Optional, defaults from project path
+	// TTLSeconds makes every key written by grepai expire after this many
+	// seconds, for throwaway indexes such as CI runs. 0 keeps keys forever.
+	TTLSeconds int `yaml:"ttl_seconds,omitempty"`
+}
+
+// TTL returns the key expiry as a duration.
+func (r RedisConfig) TTL() time.Duration {
+	return time.Duration(r.TTLSeconds) * time.Second
+}
+
+// OpenSearchConfig configures the opensearch backend, which needs the k-NN
+// plugin (bundled with OpenSearch 2.x).
+type OpenSearchConfig struct {
+	Endpoint string `yaml:"endpoint"`           // e.g., "https://search.example.com:9200"
+	Index    string `yaml:"index,omitempty"`    // Optional, defaults from project path
+	Username string `yaml:"username,omitempty"` // Optional, for basic auth
+	Password string `yaml:"password,omitempty"` // Optional, for basic auth
+}
+
 type ChunkingConfig struct {
 	Size    int `yaml:"size"`
 	Overlap int `yaml:"overlap"`
+	// Contextual prepends to each chunk, before embedding it, a summary of
+	// its file generated by the rpg.llm_* model and the signature of its
+	// enclosing symbol.
+	Contextual bool `yaml:"contextual,omitempty"`
 }
 
 func DefaultStoreForBackend(backend string) StoreConfig {
@@ -217,6 +691,18 @@ func DefaultStoreForBackend(backend string) StoreConfig {
 			Endpoint: DefaultQdrantEndpoint,
 			Port:     DefaultQdrantPort,
 		}
+	case "weaviate":
+		cfg.Weaviate = WeaviateConfig{
+			Endpoint: DefaultWeaviateEndpoint,
+		}
+	case "redis":
+		cfg.Redis = RedisConfig{
+			URL: DefaultRedisURL,
+		}
+	case "opensearch":
+		cfg.OpenSearch = OpenSearchConfig{
+			Endpoint: DefaultOpenSearchEndpoint,
+		}
 	}
 	return cfg
 }
@@ -278,18 +764,52 @@ func (c *FrameworkFeatureConfig) UnmarshalYAML(value *yaml.Node) error {
 }
 
 type WatchConfig struct {
-	DebounceMs                  int       `yaml:"debounce_ms"`
-	LastIndexTime               time.Time `yaml:"last_index_time,omitempty"`
-	RPGPersistIntervalMs        int       `yaml:"rpg_persist_interval_ms,omitempty"`
-	RPGDerivedDebounceMs        int       `yaml:"rpg_derived_debounce_ms,omitempty"`
-	RPGFullReconcileIntervalSec int       `yaml:"rpg_full_reconcile_interval_sec,omitempty"`
-	RPGMaxDirtyFilesPerBatch    int       `yaml:"rpg_max_dirty_files_per_batch,omitempty"`
+	DebounceMs                  int                      `yaml:"debounce_ms"`
+	LastIndexTime               time.Time                `yaml:"last_index_time,omitempty"`
+	RPGPersistIntervalMs        int                      `yaml:"rpg_persist_interval_ms,omitempty"`
+	RPGDerivedDebounceMs        int                      `yaml:"rpg_derived_debounce_ms,omitempty"`
+	RPGFullReconcileIntervalSec int                      `yaml:"rpg_full_reconcile_interval_sec,omitempty"`
+	RPGMaxDirtyFilesPerBatch    int                      `yaml:"rpg_max_dirty_files_per_batch,omitempty"`
+	Hooks                       WatchHooksConfig         `yaml:"hooks,omitempty"`
+	Notifications               WatchNotificationsConfig `yaml:"notifications,omitempty"`
+	PowerProfile                string                   `yaml:"power_profile,omitempty"`      // performance (default), balanced or saver
+	MaintenanceWindow           string                   `yaml:"maintenance_window,omitempty"` // local time range for heavy re-indexing, e.g. "02:00-05:00"
+	WorktreeRetention           string                   `yaml:"worktree_retention,omitempty"` // keep (default), delete or days
+	WorktreeRetentionDays       int                      `yaml:"worktree_retention_days,omitempty"`
+	IncludeSubmodules           bool                     `yaml:"include_submodules,omitempty"` // watch submodule checkouts as linked sessions
+	Backend                     string                   `yaml:"backend,omitempty"`            // auto (default), native or poll
+	PollIntervalMs              int                      `yaml:"poll_interval_ms,omitempty"`   // scan interval of the poll backend (default 2000)
+	MaxWatches                  int                      `yaml:"max_watches,omitempty"`        // directories watched natively before auto falls back to polling; 0 means the OS limit
+}
+
+// WatchNotificationsConfig controls desktop notifications for watch failures.
+type WatchNotificationsConfig struct {
+	Enabled          bool `yaml:"enabled"`
+	FailureThreshold int  `yaml:"failure_threshold,omitempty"` // embedding requests failed in a row before the circuit breaker opens (default 3)
+	CooldownSec      int  `yaml:"cooldown_sec,omitempty"`      // minimum delay between similar notifications (default 300)
+}
+
+// WatchHooksConfig lists the hooks run when the watcher updates the index.
+type WatchHooksConfig struct {
+	OnFileIndexed  []WatchHook `yaml:"on_file_indexed,omitempty"`
+	OnScanComplete []WatchHook `yaml:"on_scan_complete,omitempty"`
+	OnError        []WatchHook `yaml:"on_error,omitempty"`
+}
+
+// WatchHook is a single hook: either a URL the event is POSTed to as JSON,
+// or a shell command receiving the event JSON on stdin.
+type WatchHook struct {
+	URL       string            `yaml:"url,omitempty"`
+	Command   string            `yaml:"command,omitempty"`
+	Headers   map[string]string `yaml:"headers,omitempty"`    // extra HTTP headers (url hooks only)
+	TimeoutMs int               `yaml:"timeout_ms,omitempty"` // default 10000
 }
 
 type TraceConfig struct {
-	Mode             string   `yaml:"mode"`              // fast or precise
-	EnabledLanguages []string `yaml:"enabled_languages"` // File extensions to index
-	ExcludePatterns  []string `yaml:"exclude_patterns"`  // Patterns to exclude
+	Mode             string   `yaml:"mode"`               // fast or precise
+	EnabledLanguages []string `yaml:"enabled_languages"`  // File extensions to index
+	ExcludePatterns  []string `yaml:"exclude_patterns"`   // Patterns to exclude
+	Dispatch         string   `yaml:"dispatch,omitempty"` // Interface dispatch resolution: conservative (default) or none
 }
 
 type RPGConfig struct {
@@ -304,6 +824,8 @@ type RPGConfig struct {
 	LLMAPIKey            string  `yaml:"llm_api_key,omitempty"`
 	LLMTimeoutMs         int     `yaml:"llm_timeout_ms,omitempty"`
 	FeatureGroupStrategy string  `yaml:"feature_group_strategy,omitempty"`
+	Rebuild              string  `yaml:"rebuild,omitempty"`              // on_change | scheduled | manual
+	RebuildIntervalMin   int     `yaml:"rebuild_interval_min,omitempty"` // scheduled rebuild interval
 }
 
 // ValidateRPGConfig checks RPG configuration values for validity.
@@ -326,6 +848,15 @@ func ValidateRPGConfig(cfg RPGConfig) error {
 	default:
 		return fmt.Errorf("rpg.feature_group_strategy must be one of: sample, split; got %q", cfg.FeatureGroupStrategy)
 	}
+	switch cfg.Rebuild {
+	case "", RPGRebuildOnChange, RPGRebuildScheduled, RPGRebuildManual:
+		// valid (empty means on_change)
+	default:
+		return fmt.Errorf("rpg.rebuild must be one of: on_change, scheduled, manual; got %q", cfg.Rebuild)
+	}
+	if cfg.RebuildIntervalMin < 0 {
+		return fmt.Errorf("rpg.rebuild_interval_min must be >= 0, got %d", cfg.RebuildIntervalMin)
+	}
 	return nil
 }
 
@@ -343,9 +874,230 @@ func ValidateWatchConfig(cfg WatchConfig) error {
 	if cfg.RPGMaxDirtyFilesPerBatch < 1 {
 		return fmt.Errorf("watch.rpg_max_dirty_files_per_batch must be >= 1, got %d", cfg.RPGMaxDirtyFilesPerBatch)
 	}
+	for _, group := range []struct {
+		name  string
+		hooks []WatchHook
+	}{
+		{"on_file_indexed", cfg.Hooks.OnFileIndexed},
+		{"on_scan_complete", cfg.Hooks.OnScanComplete},
+		{"on_error", cfg.Hooks.OnError},
+	} {
+		for i, hook := range group.hooks {
+			if (hook.URL == "") == (hook.Command == "") {
+				return fmt.Errorf("watch.hooks.%s[%d] must set exactly one of url or command", group.name, i)
+			}
+			if hook.TimeoutMs < 0 {
+				return fmt.Errorf("watch.hooks.%s[%d].timeout_ms must be >= 0, got %d", group.name, i, hook.TimeoutMs)
+			}
+		}
+	}
+	if cfg.Notifications.FailureThreshold < 0 {
+		return fmt.Errorf("watch.notifications.failure_threshold must be >= 0, got %d", cfg.Notifications.FailureThreshold)
+	}
+	if cfg.Notifications.CooldownSec < 0 {
+		return fmt.Errorf("watch.notifications.cooldown_sec must be >= 0, got %d", cfg.Notifications.CooldownSec)
+	}
+	switch cfg.PowerProfile {
+	case "", PowerProfilePerformance, PowerProfileBalanced, PowerProfileSaver:
+		// valid (empty means performance)
+	default:
+		return fmt.Errorf("watch.power_profile must be one of: performance, balanced, saver; got %q", cfg.PowerProfile)
+	}
+	if _, err := ParseTimeWindow(cfg.MaintenanceWindow); err != nil {
+		return fmt.Errorf("watch.maintenance_window: %w", err)
+	}
+	switch cfg.WorktreeRetention {
+	case "", WorktreeRetentionKeep, WorktreeRetentionDelete, WorktreeRetentionDays:
+		// valid (empty means keep)
+	default:
+		return fmt.Errorf("watch.worktree_retention must be one of: keep, delete, days; got %q", cfg.WorktreeRetention)
+	}
+	if cfg.WorktreeRetentionDays < 0 {
+		return fmt.Errorf("watch.worktree_retention_days must be >= 0, got %d", cfg.WorktreeRetentionDays)
+	}
+	switch cfg.Backend {
+	case "", WatchBackendAuto, WatchBackendNative, WatchBackendPoll:
+		// valid (empty means auto)
+	default:
+		return fmt.Errorf("watch.backend must be one of: auto, native, poll; got %q", cfg.Backend)
+	}
+	if cfg.PollIntervalMs != 0 && cfg.PollIntervalMs < 100 {
+		return fmt.Errorf("watch.poll_interval_ms must be >= 100, got %d", cfg.PollIntervalMs)
+	}
+	if cfg.MaxWatches < 0 {
+		return fmt.Errorf("watch.max_watches must be >= 0, got %d", cfg.MaxWatches)
+	}
+	return nil
+}
+
+// ValidateIndexerConfig checks the symlink follow policy, the change
+// detection mode and the index budget.
+func ValidateIndexerConfig(cfg IndexerConfig) error {
+	switch cfg.FollowSymlinks {
+	case "", FollowSymlinksFalse, FollowSymlinksSafe, FollowSymlinksAll:
+	default:
+		return fmt.Errorf("indexer.follow_symlinks must be one of: false, safe, all; got %q", cfg.FollowSymlinks)
+	}
+	switch cfg.ChangeDetection {
+	case "", ChangeDetectionMtimeHash, ChangeDetectionMtime, ChangeDetectionHash:
+	default:
+		return fmt.Errorf("indexer.change_detection must be one of: mtime+hash, mtime, hash; got %q", cfg.ChangeDetection)
+	}
+	if cfg.Budget.MaxChunks < 0 {
+		return fmt.Errorf("indexer.budget.max_chunks must be >= 0, got %d", cfg.Budget.MaxChunks)
+	}
+	for _, p := range cfg.Budget.Paths {
+		if strings.Trim(filepath.ToSlash(p.Path), "/.") == "" {
+			return fmt.Errorf("indexer.budget.paths entries need a directory path")
+		}
+		if p.MaxChunks <= 0 {
+			return fmt.Errorf("indexer.budget.paths: max_chunks for %q must be > 0, got %d", p.Path, p.MaxChunks)
+		}
+	}
+	for _, p := range cfg.Budget.Priorities {
+		if strings.Trim(filepath.ToSlash(p.Path), "/.") == "" {
+			return fmt.Errorf("indexer.budget.priorities entries need a directory path")
+		}
+	}
+	return nil
+}
+
+// ValidateEmbedderConfig checks the price of an embedder and its optional
+// docs embedder, whose vectors share the index with the code vectors and
+// must therefore have the same dimensions.
+func ValidateEmbedderConfig(cfg EmbedderConfig) error {
+	if cfg.PricePerMillionTokens < 0 {
+		return fmt.Errorf("price_per_million_tokens must be >= 0, got %g", cfg.PricePerMillionTokens)
+	}
+	docs := cfg.Docs
+	if docs == nil {
+		return nil
+	}
+	if docs.Docs != nil {
+		return fmt.Errorf("docs embedder cannot have a docs embedder of its own")
+	}
+	if strings.TrimSpace(docs.Provider) == "" || strings.TrimSpace(docs.Model) == "" {
+		return fmt.Errorf("docs embedder needs a provider and a model")
+	}
+	if docs.PricePerMillionTokens < 0 {
+		return fmt.Errorf("docs.price_per_million_tokens must be >= 0, got %g", docs.PricePerMillionTokens)
+	}
+	if docs.GetDimensions() != cfg.GetDimensions() {
+		return fmt.Errorf("docs embedder has %d dimensions but the embedder has %d; both must produce vectors of the same size", docs.GetDimensions(), cfg.GetDimensions())
+	}
+	return nil
+}
+
+// ValidateRepositoryConfig checks that the URL template uses {path} and only
+// known placeholders.
+func ValidateRepositoryConfig(cfg RepositoryConfig) error {
+	if cfg.URLTemplate == "" {
+		return nil
+	}
+	if !strings.Contains(cfg.URLTemplate, git.PlaceholderPath) {
+		return fmt.Errorf("repository.url_template must contain %s", git.PlaceholderPath)
+	}
+	rest := cfg.URLTemplate
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			return nil
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return fmt.Errorf("repository.url_template has an unclosed placeholder: %q", rest[start:])
+		}
+		switch placeholder := rest[start : start+end+1]; placeholder {
+		case git.PlaceholderCommit, git.PlaceholderBranch, git.PlaceholderPath, git.PlaceholderStartLine, git.PlaceholderEndLine:
+		default:
+			return fmt.Errorf("repository.url_template has unknown placeholder %s (supported: {commit}, {branch}, {path}, {start_line}, {end_line})", placeholder)
+		}
+		rest = rest[start+end+1:]
+	}
+}
+
+// ValidateInsightsConfig checks that the miss threshold is a similarity.
+func ValidateInsightsConfig(cfg InsightsConfig) error {
+	if cfg.MinScore < 0 || cfg.MinScore > 1 {
+		return fmt.Errorf("insights.min_score must be between 0.0 and 1.0, got %.2f", cfg.MinScore)
+	}
+	return nil
+}
+
+// ValidateSearchConfig checks that the coverage boost is not negative.
+func ValidateSearchConfig(cfg SearchConfig) error {
+	if cfg.Boost.Uncovered < 0 {
+		return fmt.Errorf("search.boost.uncovered must be >= 0, got %.2f", cfg.Boost.Uncovered)
+	}
+	return nil
+}
+
+// ValidateMCPConfig checks that every path mapping sets both roots.
+func ValidateMCPConfig(cfg MCPConfig) error {
+	for i, m := range cfg.PathMappings {
+		if m.HostRoot == "" || m.ContainerRoot == "" {
+			return fmt.Errorf("mcp.path_mappings[%d] must set both host_root and container_root", i)
+		}
+	}
+	if cfg.RateLimit.RequestsPerMinute < 0 || cfg.RateLimit.Burst < 0 || cfg.RateLimit.MaxConcurrent < 0 {
+		return fmt.Errorf("mcp.rate_limit values must not be negative")
+	}
+	for _, patterns := range []struct {
+		key    string
+		values []string
+	}{{"allow", cfg.Access.Allow}, {"deny", cfg.Access.Deny}} {
+		for i, pattern := range patterns.values {
+			if strings.TrimSpace(pattern) == "" || strings.HasPrefix(strings.TrimSpace(pattern), "!") {
+				return fmt.Errorf("mcp.access.%s[%d] must be a non-empty pattern without negation", patterns.key, i)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateUIConfig checks the theme name and custom palette colors.
+func ValidateUIConfig(cfg UIConfig) error {
+	switch cfg.Theme {
+	case "", UIThemeAuto, UIThemeDark, UIThemeLight, UIThemeCustom:
+		// valid (empty means auto)
+	default:
+		return fmt.Errorf("ui.theme must be one of: auto, dark, light, custom; got %q", cfg.Theme)
+	}
+	for _, color := range []struct {
+		name  string
+		value string
+	}{
+		{"foreground", cfg.Palette.Foreground},
+		{"background", cfg.Palette.Background},
+		{"border", cfg.Palette.Border},
+		{"title", cfg.Palette.Title},
+		{"subtitle", cfg.Palette.Subtitle},
+		{"muted", cfg.Palette.Muted},
+		{"help", cfg.Palette.Help},
+		{"accent", cfg.Palette.Accent},
+		{"success", cfg.Palette.Success},
+		{"warning", cfg.Palette.Warning},
+		{"danger", cfg.Palette.Danger},
+	} {
+		if color.value != "" && !isValidUIColor(color.value) {
+			return fmt.Errorf("ui.palette.%s must be a hex color (#RGB or #RRGGBB) or an ANSI color number 0-255, got %q", color.name, color.value)
+		}
+	}
 	return nil
 }
 
+func isValidUIColor(value string) bool {
+	if hex, ok := strings.CutPrefix(value, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0 && n <= 255
+}
+
 func DefaultConfig() *Config {
 	return &Config{
 		Version:  1,
@@ -424,6 +1176,8 @@ func DefaultConfig() *Config {
 				".rs", ".zig", ".cs", ".java",
 				".fs", ".fsx", ".fsi", // F#
 				".pas", ".dpr", // Pascal/Delphi
+				".mk", ".dockerfile", ".tf", // Makefiles, Dockerfiles, Terraform
+				".sql",
 			},
 			ExcludePatterns: []string{
 				"*_test.go",
@@ -444,10 +1198,32 @@ func DefaultConfig() *Config {
 			LLMEndpoint:          "http://localhost:11434/v1",
 			LLMTimeoutMs:         DefaultRPGLLMTimeoutMs,
 			FeatureGroupStrategy: DefaultRPGFeatureGroupStrategy,
+			Rebuild:              RPGRebuildOnChange,
+			RebuildIntervalMin:   DefaultRPGRebuildIntervalMin,
 		},
 		Update: UpdateConfig{
 			CheckOnStartup: false, // Opt-in by default for privacy
 		},
+		Indexer: IndexerConfig{
+			FollowSymlinks: FollowSymlinksFalse,
+			FileHandlers: FileHandlersConfig{
+				Notebooks: FileHandlerConfig{Enabled: true},
+				Schemas:   FileHandlerConfig{Enabled: true},
+				Minified:  FileHandlerConfig{Enabled: true},
+			},
+			ContentFilters: ContentFiltersConfig{
+				Binary:    FileHandlerConfig{Enabled: true},
+				Generated: FileHandlerConfig{Enabled: true},
+			},
+		},
+		UI: UIConfig{
+			Theme:           UIThemeAuto,
+			SyntaxHighlight: true,
+		},
+		MCP: MCPConfig{
+			AutoCompactTokens: DefaultMCPAutoCompactTokens,
+			CacheTTLSeconds:   DefaultMCPCacheTTLSeconds,
+		},
 		Ignore: []string{
 			".git",
 			".grepai",
@@ -484,10 +1260,73 @@ func GetSymbolIndexPath(projectRoot string) string {
 	return filepath.Join(GetConfigDir(projectRoot), SymbolIndexFileName)
 }
 
+// GetTodoIndexPath returns the path of the todo index, which holds the todo
+// comments of the project with their embeddings.
+func GetTodoIndexPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), TodoIndexFileName)
+}
+
 func GetRPGIndexPath(projectRoot string) string {
 	return filepath.Join(GetConfigDir(projectRoot), RPGIndexFileName)
 }
 
+func GetBlameCachePath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), BlameCacheFileName)
+}
+
+func GetIndexSignalPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), IndexSignalFileName)
+}
+
+// GetWatchLogDirPath returns the path of the file recording the log
+// directory of a watcher started with a non-default --log-dir.
+func GetWatchLogDirPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), WatchLogDirFileName)
+}
+
+// GetLastSearchPath returns the path of the file recording the results of
+// the last 'grepai search', used by --refine-last.
+func GetLastSearchPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), LastSearchFileName)
+}
+
+// GetCoveragePath returns the path of the coverage profile imported by
+// 'grepai coverage import'.
+func GetCoveragePath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), CoverageFileName)
+}
+
+// GetWatchJournalPath returns the path of the journal of file events that
+// 'grepai watch' received but has not persisted yet.
+func GetWatchJournalPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), WatchJournalFileName)
+}
+
+// GetWorkspaceWatchJournalPath returns the path of the watch journal of a
+// project watched by a workspace watcher. It is kept apart from the
+// project's own journal, as the two watchers update different indexes.
+func GetWorkspaceWatchJournalPath(workspaceName, projectName string) (string, error) {
+	globalDir, err := GetGlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(globalDir, "watch-journal", workspaceName, projectName+".jsonl"), nil
+}
+
+// GetSummaryCachePath returns the path of the cache of the file summaries
+// generated for contextual chunks.
+func GetSummaryCachePath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), SummaryCacheFileName)
+}
+
+// SignalIndexUpdated tells readers of the project's index, such as a running
+// MCP server, that index changes are now visible. It writes the current time
+// to the index signal file, so every signal has distinct content.
+func SignalIndexUpdated(projectRoot string) error {
+	stamp := time.Now().UTC().Format(time.RFC3339Nano)
+	return os.WriteFile(GetIndexSignalPath(projectRoot), []byte(stamp+"\n"), 0644)
+}
+
 func Load(projectRoot string) (*Config, error) {
 	configPath := GetConfigPath(projectRoot)
 
@@ -509,6 +1348,34 @@ func Load(projectRoot string) (*Config, error) {
 		return nil, fmt.Errorf("invalid watch configuration: %w", err)
 	}
 
+	if err := ValidateUIConfig(cfg.UI); err != nil {
+		return nil, fmt.Errorf("invalid ui configuration: %w", err)
+	}
+
+	if err := ValidateIndexerConfig(cfg.Indexer); err != nil {
+		return nil, fmt.Errorf("invalid indexer configuration: %w", err)
+	}
+
+	if err := ValidateEmbedderConfig(cfg.Embedder); err != nil {
+		return nil, fmt.Errorf("invalid embedder configuration: %w", err)
+	}
+
+	if err := ValidateRepositoryConfig(cfg.Repository); err != nil {
+		return nil, fmt.Errorf("invalid repository configuration: %w", err)
+	}
+
+	if err := ValidateMCPConfig(cfg.MCP); err != nil {
+		return nil, fmt.Errorf("invalid mcp configuration: %w", err)
+	}
+
+	if err := ValidateSearchConfig(cfg.Search); err != nil {
+		return nil, fmt.Errorf("invalid search configuration: %w", err)
+	}
+
+	if err := ValidateInsightsConfig(cfg.Insights); err != nil {
+		return nil, fmt.Errorf("invalid insights configuration: %w", err)
+	}
+
 	// Validate RPG config when enabled
 	if cfg.RPG.Enabled {
 		if err := ValidateRPGConfig(cfg.RPG); err != nil {
@@ -519,30 +1386,39 @@ func Load(projectRoot string) (*Config, error) {
 	return &cfg, nil
 }
 
-// applyDefaults fills in missing configuration values with sensible defaults.
-// This ensures backward compatibility with older config files that may not
-// have newer fields like dimensions or endpoint.
-func (c *Config) applyDefaults() {
-	defaults := DefaultConfig()
-
-	// Embedder defaults
-	if c.Embedder.Endpoint == "" {
-		c.Embedder.Endpoint = DefaultEmbedderForProvider(c.Embedder.Provider).Endpoint
+// applyDefaults fills in the endpoint, dimensions and parallelism of an
+// embedder configured without them.
+func (e *EmbedderConfig) applyDefaults() {
+	if e.Endpoint == "" {
+		e.Endpoint = DefaultEmbedderForProvider(e.Provider).Endpoint
 	}
 
 	// Only set default dimensions for local embedders.
 	// For OpenAI/OpenRouter, leave nil to let the API use the model's native dimensions.
-	if c.Embedder.Dimensions == nil {
-		switch cfg := DefaultEmbedderForProvider(c.Embedder.Provider); {
+	if e.Dimensions == nil {
+		switch cfg := DefaultEmbedderForProvider(e.Provider); {
 		case cfg.Dimensions != nil:
 			dim := *cfg.Dimensions
-			c.Embedder.Dimensions = &dim
+			e.Dimensions = &dim
 		}
 	}
 
 	// Parallelism default (only used by OpenAI embedder)
-	if c.Embedder.Parallelism <= 0 {
-		c.Embedder.Parallelism = 4
+	if e.Parallelism <= 0 {
+		e.Parallelism = 4
+	}
+}
+
+// applyDefaults fills in missing configuration values with sensible defaults.
+// This ensures backward compatibility with older config files that may not
+// have newer fields like dimensions or endpoint.
+func (c *Config) applyDefaults() {
+	defaults := DefaultConfig()
+
+	// Embedder defaults
+	c.Embedder.applyDefaults()
+	if c.Embedder.Docs != nil {
+		c.Embedder.Docs.applyDefaults()
 	}
 
 	// Chunking defaults
@@ -602,6 +1478,23 @@ func (c *Config) applyDefaults() {
 	if c.Store.Backend == "qdrant" && c.Store.Qdrant.Port <= 0 {
 		c.Store.Qdrant.Port = DefaultStoreForBackend("qdrant").Qdrant.Port
 	}
+	if c.Store.Backend == "weaviate" && c.Store.Weaviate.Endpoint == "" {
+		c.Store.Weaviate.Endpoint = DefaultWeaviateEndpoint
+	}
+	if c.Store.Backend == "redis" && c.Store.Redis.URL == "" {
+		c.Store.Redis.URL = DefaultRedisURL
+	}
+	if c.Store.Backend == "opensearch" && c.Store.OpenSearch.Endpoint == "" {
+		c.Store.OpenSearch.Endpoint = DefaultOpenSearchEndpoint
+	}
+
+	// MCP defaults
+	if c.MCP.AutoCompactTokens == 0 {
+		c.MCP.AutoCompactTokens = defaults.MCP.AutoCompactTokens
+	}
+	if c.MCP.CacheTTLSeconds == 0 {
+		c.MCP.CacheTTLSeconds = defaults.MCP.CacheTTLSeconds
+	}
 
 	// RPG defaults
 	if c.RPG.FeatureMode == "" {
@@ -628,6 +1521,40 @@ func (c *Config) applyDefaults() {
 	if c.RPG.FeatureGroupStrategy == "" {
 		c.RPG.FeatureGroupStrategy = DefaultRPGFeatureGroupStrategy
 	}
+	if c.RPG.Rebuild == "" {
+		c.RPG.Rebuild = RPGRebuildOnChange
+	}
+	if c.RPG.RebuildIntervalMin == 0 {
+		c.RPG.RebuildIntervalMin = DefaultRPGRebuildIntervalMin
+	}
+
+	// Indexer defaults
+	if c.Indexer.FollowSymlinks == "" {
+		c.Indexer.FollowSymlinks = defaults.Indexer.FollowSymlinks
+	}
+	if !c.Indexer.FileHandlers.Notebooks.enabledSet {
+		c.Indexer.FileHandlers.Notebooks.Enabled = defaults.Indexer.FileHandlers.Notebooks.Enabled
+	}
+	if !c.Indexer.FileHandlers.Schemas.enabledSet {
+		c.Indexer.FileHandlers.Schemas.Enabled = defaults.Indexer.FileHandlers.Schemas.Enabled
+	}
+	if !c.Indexer.FileHandlers.Minified.enabledSet {
+		c.Indexer.FileHandlers.Minified.Enabled = defaults.Indexer.FileHandlers.Minified.Enabled
+	}
+	if !c.Indexer.ContentFilters.Binary.enabledSet {
+		c.Indexer.ContentFilters.Binary.Enabled = defaults.Indexer.ContentFilters.Binary.Enabled
+	}
+	if !c.Indexer.ContentFilters.Generated.enabledSet {
+		c.Indexer.ContentFilters.Generated.Enabled = defaults.Indexer.ContentFilters.Generated.Enabled
+	}
+
+	// UI defaults
+	if c.UI.Theme == "" {
+		c.UI.Theme = UIThemeAuto
+	}
+	if !c.UI.syntaxHighlightSet {
+		c.UI.SyntaxHighlight = defaults.UI.SyntaxHighlight
+	}
 }
 
 func providerOrDefault(provider string) string {
@@ -740,17 +1667,8 @@ func autoInitFromMainWorktree(worktreeRoot, mainWorktree string) error {
 		return fmt.Errorf("config.yaml not found in main worktree: %s", srcConfig)
 	}
 
-	// Copy index.gob as seed (search works immediately)
-	_ = copyFileIfExists(
-		filepath.Join(mainGrepai, "index.gob"),
-		filepath.Join(localGrepai, "index.gob"),
-	)
-
-	// Copy symbols.gob as seed (trace works immediately)
-	_ = copyFileIfExists(
-		filepath.Join(mainGrepai, "symbols.gob"),
-		filepath.Join(localGrepai, "symbols.gob"),
-	)
+	// Seed the indexes so search and trace work immediately
+	_ = SeedWorktreeIndex(worktreeRoot, mainWorktree)
 
 	// Ensure .grepai/ is in .gitignore
 	ensureGitignoreEntry(worktreeRoot, ".grepai/")
@@ -758,6 +1676,54 @@ func autoInitFromMainWorktree(worktreeRoot, mainWorktree string) error {
 	return nil
 }
 
+// AutoInitSubmodule creates a .grepai/ in submoduleRoot with the
+// configuration of the superproject at superRoot. Unlike a linked worktree,
+// a submodule holds a different repository, so no index is copied, and
+// .grepai/ is ignored through the submodule's info/exclude rather than its
+// tracked .gitignore.
+func AutoInitSubmodule(submoduleRoot, superRoot string) error {
+	cfg, err := Load(superRoot)
+	if err != nil {
+		return err
+	}
+	cfg.Watch.LastIndexTime = time.Time{}
+	if err := cfg.Save(submoduleRoot); err != nil {
+		return err
+	}
+
+	excludePath, err := git.ExcludeFilePath(submoduleRoot)
+	if err != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return nil
+	}
+	ensureIgnoreFileEntry(excludePath, ".grepai/")
+	return nil
+}
+
+// SeedWorktreeIndex copies the local index files (vector, symbol and RPG
+// indexes) of mainWorktree into worktreeRoot's .grepai/ directory, and
+// clears the worktree's last index time. The first scan in the worktree then
+// checks every file's hash against the seed and only re-embeds the files
+// that differ on its branch.
+func SeedWorktreeIndex(worktreeRoot, mainWorktree string) error {
+	for _, name := range []string{IndexFileName, SymbolIndexFileName, RPGIndexFileName} {
+		if err := copyFileIfExists(filepath.Join(GetConfigDir(mainWorktree), name), filepath.Join(GetConfigDir(worktreeRoot), name)); err != nil {
+			return err
+		}
+	}
+
+	// The main worktree's last index time says nothing about the files
+	// checked out in the worktree.
+	cfg, err := Load(worktreeRoot)
+	if err != nil || cfg.Watch.LastIndexTime.IsZero() {
+		return nil
+	}
+	cfg.Watch.LastIndexTime = time.Time{}
+	return cfg.Save(worktreeRoot)
+}
+
 // copyFileIfExists copies src to dst if src exists. Returns error only if src
 // exists but copy fails. Returns nil if src doesn't exist.
 func copyFileIfExists(src, dst string) error {
@@ -773,7 +1739,12 @@ func copyFileIfExists(src, dst string) error {
 
 // ensureGitignoreEntry adds an entry to .gitignore if not already present.
 func ensureGitignoreEntry(dir, entry string) {
-	gitignorePath := filepath.Join(dir, ".gitignore")
+	ensureIgnoreFileEntry(filepath.Join(dir, ".gitignore"), entry)
+}
+
+// ensureIgnoreFileEntry adds an entry to the ignore file at path if not
+// already present.
+func ensureIgnoreFileEntry(gitignorePath, entry string) {
 	content, err := os.ReadFile(gitignorePath)
 	if err == nil {
 		// Check if entry already exists
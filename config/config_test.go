@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -668,6 +670,23 @@ func TestApplyDefaults_FeatureGroupStrategy(t *testing.T) {
 	}
 }
 
+func TestApplyDefaults_MCPAutoCompact(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.MCP.AutoCompactTokens != DefaultMCPAutoCompactTokens {
+		t.Errorf("expected mcp.auto_compact_tokens=%d, got %d", DefaultMCPAutoCompactTokens, cfg.MCP.AutoCompactTokens)
+	}
+	if cfg.MCP.CacheTTLSeconds != DefaultMCPCacheTTLSeconds {
+		t.Errorf("expected mcp.cache_ttl_seconds=%d, got %d", DefaultMCPCacheTTLSeconds, cfg.MCP.CacheTTLSeconds)
+	}
+
+	cfg = &Config{MCP: MCPConfig{AutoCompactTokens: -1}}
+	cfg.applyDefaults()
+	if cfg.MCP.AutoCompactTokens != -1 {
+		t.Errorf("expected a negative threshold to be kept, got %d", cfg.MCP.AutoCompactTokens)
+	}
+}
+
 func TestApplyDefaults_WatchRealtimeFields(t *testing.T) {
 	cfg := &Config{}
 	cfg.applyDefaults()
@@ -732,6 +751,40 @@ func TestValidateWatchConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid maintenance window",
+			cfg: WatchConfig{
+				RPGPersistIntervalMs:        1000,
+				RPGDerivedDebounceMs:        300,
+				RPGFullReconcileIntervalSec: 300,
+				RPGMaxDirtyFilesPerBatch:    128,
+				MaintenanceWindow:           "2am-5am",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid backend",
+			cfg: WatchConfig{
+				RPGPersistIntervalMs:        1000,
+				RPGDerivedDebounceMs:        300,
+				RPGFullReconcileIntervalSec: 300,
+				RPGMaxDirtyFilesPerBatch:    128,
+				Backend:                     "inotify",
+			},
+			wantErr: true,
+		},
+		{
+			name: "poll interval too low",
+			cfg: WatchConfig{
+				RPGPersistIntervalMs:        1000,
+				RPGDerivedDebounceMs:        300,
+				RPGFullReconcileIntervalSec: 300,
+				RPGMaxDirtyFilesPerBatch:    128,
+				Backend:                     WatchBackendPoll,
+				PollIntervalMs:              50,
+			},
+			wantErr: true,
+		},
 		{
 			name: "max dirty files too low",
 			cfg: WatchConfig{
@@ -753,3 +806,388 @@ func TestValidateWatchConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRPGConfig_Rebuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		rebuild  string
+		interval int
+		wantErr  bool
+	}{
+		{"empty defaults to on_change", "", 0, false},
+		{"on_change is valid", RPGRebuildOnChange, 0, false},
+		{"scheduled is valid", RPGRebuildScheduled, 30, false},
+		{"manual is valid", RPGRebuildManual, 0, false},
+		{"unknown is invalid", "nightly", 0, true},
+		{"negative interval is invalid", RPGRebuildScheduled, -5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := RPGConfig{
+				DriftThreshold:       DefaultRPGDriftThreshold,
+				MaxTraversalDepth:    DefaultRPGMaxTraversalDepth,
+				FeatureMode:          DefaultRPGFeatureMode,
+				FeatureGroupStrategy: DefaultRPGFeatureGroupStrategy,
+				Rebuild:              tt.rebuild,
+				RebuildIntervalMin:   tt.interval,
+			}
+			err := ValidateRPGConfig(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRPGConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults_RPGRebuild(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.RPG.Rebuild != RPGRebuildOnChange {
+		t.Errorf("expected Rebuild=%q, got %q", RPGRebuildOnChange, cfg.RPG.Rebuild)
+	}
+	if cfg.RPG.RebuildIntervalMin != DefaultRPGRebuildIntervalMin {
+		t.Errorf("expected RebuildIntervalMin=%d, got %d", DefaultRPGRebuildIntervalMin, cfg.RPG.RebuildIntervalMin)
+	}
+}
+
+func TestValidateWatchConfig_Hooks(t *testing.T) {
+	base := DefaultConfig().Watch
+
+	valid := base
+	valid.Hooks.OnFileIndexed = []WatchHook{{URL: "http://localhost:9000/hook"}}
+	valid.Hooks.OnError = []WatchHook{{Command: "notify-send grepai error"}}
+	if err := ValidateWatchConfig(valid); err != nil {
+		t.Fatalf("expected valid hooks, got %v", err)
+	}
+
+	both := base
+	both.Hooks.OnScanComplete = []WatchHook{{URL: "http://x", Command: "echo"}}
+	if err := ValidateWatchConfig(both); err == nil || !strings.Contains(err.Error(), "on_scan_complete[0]") {
+		t.Fatalf("expected error for hook with url and command, got %v", err)
+	}
+
+	neither := base
+	neither.Hooks.OnError = []WatchHook{{TimeoutMs: 100}}
+	if err := ValidateWatchConfig(neither); err == nil {
+		t.Fatal("expected error for hook without url or command")
+	}
+}
+
+func TestValidateUIConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     UIConfig
+		wantErr string
+	}{
+		{"empty defaults to auto", UIConfig{}, ""},
+		{"light is valid", UIConfig{Theme: UIThemeLight}, ""},
+		{"custom palette is valid", UIConfig{Theme: UIThemeCustom, Palette: UIPalette{Accent: "#ff8800", Muted: "244", Border: "#333"}}, ""},
+		{"unknown theme is invalid", UIConfig{Theme: "solarized"}, "ui.theme"},
+		{"bad hex is invalid", UIConfig{Theme: UIThemeCustom, Palette: UIPalette{Accent: "#12345"}}, "ui.palette.accent"},
+		{"out of range ansi is invalid", UIConfig{Theme: UIThemeCustom, Palette: UIPalette{Danger: "256"}}, "ui.palette.danger"},
+		{"color name is invalid", UIConfig{Theme: UIThemeCustom, Palette: UIPalette{Title: "red"}}, "ui.palette.title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUIConfig(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateUIConfig() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateUIConfig() error = %v, want mention of %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.UI.Theme != UIThemeAuto {
+		t.Errorf("expected default ui.theme %q, got %q", UIThemeAuto, cfg.UI.Theme)
+	}
+	if !cfg.UI.SyntaxHighlight {
+		t.Error("expected ui.syntax_highlight to default to true")
+	}
+
+	cfg = &Config{}
+	if err := yaml.Unmarshal([]byte("ui:\n  theme: dark\n  syntax_highlight: false\n"), cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	cfg.applyDefaults()
+	if cfg.UI.SyntaxHighlight || cfg.UI.Theme != UIThemeDark {
+		t.Errorf("expected ui.syntax_highlight false with theme dark, got %+v", cfg.UI)
+	}
+}
+
+func TestValidateIndexerConfig(t *testing.T) {
+	for _, policy := range []string{"", FollowSymlinksFalse, FollowSymlinksSafe, FollowSymlinksAll} {
+		if err := ValidateIndexerConfig(IndexerConfig{FollowSymlinks: policy}); err != nil {
+			t.Errorf("ValidateIndexerConfig(%q) unexpected error: %v", policy, err)
+		}
+	}
+	err := ValidateIndexerConfig(IndexerConfig{FollowSymlinks: "true"})
+	if err == nil || !strings.Contains(err.Error(), "indexer.follow_symlinks") {
+		t.Errorf("expected follow_symlinks error, got %v", err)
+	}
+
+	for _, budget := range []BudgetConfig{
+		{MaxChunks: -1},
+		{Paths: []PathBudget{{Path: "", MaxChunks: 10}}},
+		{Paths: []PathBudget{{Path: "vendor", MaxChunks: 0}}},
+		{Priorities: []PathPriority{{Path: "/", Priority: 1}}},
+	} {
+		if err := ValidateIndexerConfig(IndexerConfig{Budget: budget}); err == nil || !strings.Contains(err.Error(), "indexer.budget") {
+			t.Errorf("expected budget error for %+v, got %v", budget, err)
+		}
+	}
+	if err := ValidateIndexerConfig(IndexerConfig{Budget: BudgetConfig{MaxChunks: 1000, Paths: []PathBudget{{Path: "vendor/", MaxChunks: 100}}}}); err != nil {
+		t.Errorf("unexpected budget error: %v", err)
+	}
+	budget := BudgetConfig{Priorities: []PathPriority{{Path: "src", Priority: 2}, {Path: "src/gen/", Priority: -1}}}
+	for path, want := range map[string]float32{"src/main.go": 2, "src/gen/api.go": -1, "docs/a.md": 0, "srcx/a.go": 0} {
+		if got := budget.Priority(path); got != want {
+			t.Errorf("Priority(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	// An unquoted YAML false must decode into the string policy.
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("indexer:\n  follow_symlinks: false\n"), &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if cfg.Indexer.FollowSymlinks != FollowSymlinksFalse {
+		t.Errorf("expected follow_symlinks %q, got %q", FollowSymlinksFalse, cfg.Indexer.FollowSymlinks)
+	}
+
+	cfg = Config{}
+	cfg.applyDefaults()
+	if cfg.Indexer.FollowSymlinks != FollowSymlinksFalse {
+		t.Errorf("expected default follow_symlinks %q, got %q", FollowSymlinksFalse, cfg.Indexer.FollowSymlinks)
+	}
+}
+
+func TestApplyDefaults_FileHandlers(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("indexer:\n  file_handlers:\n    notebooks:\n      enabled: false\n"), &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	cfg.applyDefaults()
+
+	handlers := cfg.Indexer.FileHandlers
+	if handlers.Notebooks.Enabled {
+		t.Error("expected explicitly disabled notebooks handler to stay disabled")
+	}
+	if !handlers.Schemas.Enabled || !handlers.Minified.Enabled {
+		t.Errorf("expected unset handlers to default to enabled, got %+v", handlers)
+	}
+}
+
+func TestApplyDefaults_ContentFilters(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("indexer:\n  content_filters:\n    generated:\n      enabled: false\n    text_extensions: [.pas]\n"), &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	cfg.applyDefaults()
+
+	filters := cfg.Indexer.ContentFilters
+	if filters.Generated.Enabled {
+		t.Error("expected explicitly disabled generated rule to stay disabled")
+	}
+	if !filters.Binary.Enabled {
+		t.Errorf("expected unset rules to default to enabled, got %+v", filters)
+	}
+	if len(filters.TextExtensions) != 1 || filters.TextExtensions[0] != ".pas" {
+		t.Errorf("expected text_extensions [.pas], got %v", filters.TextExtensions)
+	}
+}
+
+func TestValidateMCPConfig(t *testing.T) {
+	valid := MCPConfig{PathMappings: []PathMapping{{HostRoot: "/Users/me/src/app", ContainerRoot: "/workspaces/app"}}}
+	if err := ValidateMCPConfig(valid); err != nil {
+		t.Errorf("ValidateMCPConfig() unexpected error: %v", err)
+	}
+	invalid := MCPConfig{PathMappings: []PathMapping{{HostRoot: "/Users/me/src/app"}}}
+	if err := ValidateMCPConfig(invalid); err == nil {
+		t.Error("expected an error for a mapping without container_root")
+	}
+	negative := MCPConfig{RateLimit: RateLimitConfig{MaxConcurrent: -1}}
+	if err := ValidateMCPConfig(negative); err == nil {
+		t.Error("expected an error for a negative rate limit")
+	}
+	emptyPattern := MCPConfig{Access: AccessConfig{Deny: []string{"secrets/**", " "}}}
+	if err := ValidateMCPConfig(emptyPattern); err == nil {
+		t.Error("expected an error for an empty access pattern")
+	}
+}
+
+func TestValidateSearchConfig(t *testing.T) {
+	if err := ValidateSearchConfig(SearchConfig{Boost: BoostConfig{Uncovered: 1.3}}); err != nil {
+		t.Errorf("ValidateSearchConfig() unexpected error: %v", err)
+	}
+	if err := ValidateSearchConfig(SearchConfig{Boost: BoostConfig{Uncovered: -1}}); err == nil {
+		t.Error("expected an error for a negative uncovered factor")
+	}
+}
+
+func TestValidateRepositoryConfig(t *testing.T) {
+	valid := []string{
+		"",
+		"https://github.com/org/repo/blob/{commit}/{path}#L{start_line}-L{end_line}",
+		"https://gitlab.example.com/org/repo/-/blob/{branch}/{path}",
+	}
+	for _, tmpl := range valid {
+		if err := ValidateRepositoryConfig(RepositoryConfig{URLTemplate: tmpl}); err != nil {
+			t.Errorf("ValidateRepositoryConfig(%q) unexpected error: %v", tmpl, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"https://github.com/org/repo/blob/{commit}":         "must contain {path}",
+		"https://github.com/org/repo/blob/{sha}/{path}":     "unknown placeholder {sha}",
+		"https://github.com/org/repo/blob/{commit/{path}":   "unknown placeholder",
+		"https://github.com/org/repo/blob/main/{path}#L{st": "unclosed placeholder",
+	}
+	for tmpl, want := range invalid {
+		err := ValidateRepositoryConfig(RepositoryConfig{URLTemplate: tmpl})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateRepositoryConfig(%q) = %v, want error containing %q", tmpl, err, want)
+		}
+	}
+}
+
+func TestQdrantConfigTLSEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  QdrantConfig
+		want bool
+	}{
+		{"local host", QdrantConfig{Endpoint: "localhost"}, false},
+		{"http URL", QdrantConfig{Endpoint: "http://qdrant.internal"}, false},
+		{"explicit TLS", QdrantConfig{Endpoint: "qdrant.internal", UseTLS: true}, true},
+		{"https URL", QdrantConfig{Endpoint: "https://qdrant.internal"}, true},
+		{"cloud cluster", QdrantConfig{Endpoint: "abc-123.eu-central.aws.cloud.qdrant.io"}, true},
+		{"cloud cluster with http scheme and port", QdrantConfig{Endpoint: "http://abc-123.us-east.aws.cloud.qdrant.io:6334"}, true},
+		{"lookalike host", QdrantConfig{Endpoint: "notcloud.qdrant.io.example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.TLSEnabled(); got != tt.want {
+				t.Errorf("TLSEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigGetScope(t *testing.T) {
+	cfg := DefaultConfig()
+	if _, err := cfg.GetScope("backend"); err == nil || !strings.Contains(err.Error(), "no scopes are defined") {
+		t.Errorf("expected an error without scopes, got %v", err)
+	}
+
+	cfg.Scopes = map[string]Scope{
+		"backend":  {Paths: []string{"internal/"}, ExcludeTests: true},
+		"frontend": {Languages: []string{"ts"}},
+	}
+	scope, err := cfg.GetScope("backend")
+	if err != nil || scope.Paths[0] != "internal/" || !scope.ExcludeTests {
+		t.Errorf("expected the backend scope, got %+v, %v", scope, err)
+	}
+	if _, err := cfg.GetScope("mobile"); err == nil || !strings.Contains(err.Error(), "backend, frontend") {
+		t.Errorf("expected an error listing the scopes, got %v", err)
+	}
+}
+
+func TestEmbedderConfigPrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    EmbedderConfig
+		want   float64
+		wantOK bool
+	}{
+		{"openai list price", EmbedderConfig{Provider: "openai", Model: "text-embedding-3-large"}, 0.13, true},
+		{"openrouter list price", EmbedderConfig{Provider: "openrouter", Model: "openai/text-embedding-3-small"}, 0.02, true},
+		{"configured price wins", EmbedderConfig{Provider: "openai", Model: "text-embedding-3-small", PricePerMillionTokens: 0.5}, 0.5, true},
+		{"unknown model", EmbedderConfig{Provider: "synthetic", Model: "hf:nomic-ai/nomic-embed-text-v1.5"}, 0, false},
+		{"local provider", EmbedderConfig{Provider: "ollama", Model: "text-embedding-3-small"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.cfg.Price()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Price() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateEmbedderConfigDocs(t *testing.T) {
+	dims := func(n int) *int { return &n }
+	base := EmbedderConfig{Provider: "ollama", Model: "nomic-embed-text", Dimensions: dims(768)}
+	tests := []struct {
+		name    string
+		docs    *EmbedderConfig
+		wantErr string
+	}{
+		{"no docs embedder", nil, ""},
+		{"same dimensions", &EmbedderConfig{Provider: "ollama", Model: "mxbai-embed-large", Dimensions: dims(768)}, ""},
+		{"other dimensions", &EmbedderConfig{Provider: "openai", Model: "text-embedding-3-small"}, "1536 dimensions"},
+		{"missing model", &EmbedderConfig{Provider: "ollama", Dimensions: dims(768)}, "provider and a model"},
+		{"nested docs", &EmbedderConfig{Provider: "ollama", Model: "m", Dimensions: dims(768), Docs: &EmbedderConfig{}}, "docs embedder of its own"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			cfg.Docs = tt.docs
+			err := ValidateEmbedderConfig(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error mentioning %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadAppliesDocsEmbedderDefaults(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(GetConfigDir(root), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "embedder:\n  provider: ollama\n  model: nomic-embed-text\n  docs:\n    provider: ollama\n    model: mxbai-embed-large\n"
+	if err := os.WriteFile(GetConfigPath(root), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	docs := cfg.Embedder.Docs
+	if docs == nil {
+		t.Fatal("expected a docs embedder")
+	}
+	if docs.Endpoint != DefaultOllamaEndpoint || docs.GetDimensions() != 768 {
+		t.Errorf("expected ollama defaults on the docs embedder, got %+v", docs)
+	}
+}
+
+func TestInsightsConfig(t *testing.T) {
+	if got := (InsightsConfig{}).GetMinScore(); got != DefaultInsightsMinScore {
+		t.Errorf("GetMinScore() = %v, want the default %v", got, DefaultInsightsMinScore)
+	}
+	if got := (InsightsConfig{MinScore: 0.3}).GetMinScore(); got != 0.3 {
+		t.Errorf("GetMinScore() = %v, want 0.3", got)
+	}
+	if err := ValidateInsightsConfig(InsightsConfig{MinScore: 1.5}); err == nil {
+		t.Error("expected an error for min_score above 1")
+	}
+	if err := ValidateInsightsConfig(InsightsConfig{Enabled: true, MinScore: 0.4}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
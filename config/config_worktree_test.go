@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCopyFileIfExists(t *testing.T) {
@@ -144,3 +145,38 @@ func TestAutoInitFromMainWorktree(t *testing.T) {
 		}
 	})
 }
+
+func TestSeedWorktreeIndex(t *testing.T) {
+	mainDir := t.TempDir()
+	worktreeDir := t.TempDir()
+
+	mainCfg := DefaultConfig()
+	mainCfg.Watch.LastIndexTime = time.Now()
+	if err := mainCfg.Save(mainDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := mainCfg.Save(worktreeDir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{IndexFileName, SymbolIndexFileName, RPGIndexFileName} {
+		os.WriteFile(filepath.Join(GetConfigDir(mainDir), name), []byte(name), 0644)
+	}
+
+	if err := SeedWorktreeIndex(worktreeDir, mainDir); err != nil {
+		t.Fatalf("SeedWorktreeIndex failed: %v", err)
+	}
+	for _, name := range []string{IndexFileName, SymbolIndexFileName, RPGIndexFileName} {
+		data, err := os.ReadFile(filepath.Join(GetConfigDir(worktreeDir), name))
+		if err != nil || string(data) != name {
+			t.Errorf("%s not seeded: %q %v", name, data, err)
+		}
+	}
+
+	cfg, err := Load(worktreeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Watch.LastIndexTime.IsZero() {
+		t.Errorf("expected last index time to be cleared so the first scan revalidates hashes, got %v", cfg.Watch.LastIndexTime)
+	}
+}
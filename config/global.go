@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	GlobalConfigFileName = "global.yaml"
+	GlobalIndexDirName   = "global"
+	GlobalReposFileName  = "repos.json"
+
+	// DefaultGlobalMaxDepth is how many directory levels below a root are
+	// searched for repositories.
+	DefaultGlobalMaxDepth = 3
+)
+
+// GlobalConfig configures the personal index spanning every repository
+// found under a list of directories.
+// Stored at ~/.grepai/global.yaml
+type GlobalConfig struct {
+	Version  int            `yaml:"version"`
+	Roots    []string       `yaml:"roots"`
+	MaxDepth int            `yaml:"max_depth,omitempty"`
+	Exclude  []string       `yaml:"exclude,omitempty"` // glob patterns matched against repository directory names
+	Embedder EmbedderConfig `yaml:"embedder"`
+	Chunking ChunkingConfig `yaml:"chunking"`
+	Ignore   []string       `yaml:"ignore"`
+	// ChangeDetection sets how changed files are found, as
+	// indexer.change_detection does for a project.
+	ChangeDetection string `yaml:"change_detection,omitempty"`
+}
+
+// DefaultGlobalConfig returns the global index configuration used by
+// 'grepai global init'.
+func DefaultGlobalConfig() *GlobalConfig {
+	defaults := DefaultConfig()
+	return &GlobalConfig{
+		Version:  1,
+		Roots:    []string{"~/src"},
+		MaxDepth: DefaultGlobalMaxDepth,
+		Embedder: defaults.Embedder,
+		Chunking: defaults.Chunking,
+		Ignore:   defaults.Ignore,
+	}
+}
+
+// GetGlobalConfigPath returns the path to the global index config file.
+func GetGlobalConfigPath() (string, error) {
+	globalDir, err := GetGlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(globalDir, GlobalConfigFileName), nil
+}
+
+// GetGlobalIndexDir returns the directory holding the global index and its
+// repository metadata.
+func GetGlobalIndexDir() (string, error) {
+	globalDir, err := GetGlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(globalDir, GlobalIndexDirName), nil
+}
+
+// LoadGlobalConfig loads the global index configuration from
+// ~/.grepai/global.yaml. Returns nil, nil if the file doesn't exist.
+func LoadGlobalConfig() (*GlobalConfig, error) {
+	configPath, err := GetGlobalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read global config: %w", err)
+	}
+
+	var cfg GlobalConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse global config: %w", err)
+	}
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = DefaultGlobalMaxDepth
+	}
+	if cfg.Chunking.Size <= 0 {
+		cfg.Chunking = DefaultConfig().Chunking
+	}
+	switch cfg.ChangeDetection {
+	case "", ChangeDetectionMtimeHash, ChangeDetectionMtime, ChangeDetectionHash:
+	default:
+		return nil, fmt.Errorf("invalid global config: change_detection must be one of: mtime+hash, mtime, hash; got %q", cfg.ChangeDetection)
+	}
+	return &cfg, nil
+}
+
+// SaveGlobalConfig saves the global index configuration to
+// ~/.grepai/global.yaml.
+func SaveGlobalConfig(cfg *GlobalConfig) error {
+	configPath, err := GetGlobalConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create global config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal global config: %w", err)
+	}
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write global config: %w", err)
+	}
+	return nil
+}
+
+// ExpandedRoots returns the configured roots as absolute paths, with a
+// leading ~ replaced by the home directory.
+func (c *GlobalConfig) ExpandedRoots() []string {
+	home, _ := os.UserHomeDir()
+	roots := make([]string, 0, len(c.Roots))
+	for _, root := range c.Roots {
+		if home != "" && (root == "~" || strings.HasPrefix(root, "~/")) {
+			root = filepath.Join(home, strings.TrimPrefix(root, "~"))
+		}
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		roots = append(roots, filepath.Clean(root))
+	}
+	return roots
+}
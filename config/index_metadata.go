@@ -0,0 +1,178 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+)
+
+// IndexMetadataFileName records, in the .grepai directory, the embedder
+// that built the project's index.
+const IndexMetadataFileName = "index-meta.json"
+
+// ErrEmbedderMismatch is wrapped by the error returned when an index was
+// built with another embedder than the configured one. Vectors of different
+// models are not comparable, so searching such an index returns meaningless
+// results.
+var ErrEmbedderMismatch = errors.New("index was built with a different embedder")
+
+// IndexMetadata identifies the embedder whose vectors an index holds.
+type IndexMetadata struct {
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions"`
+	// Docs identifies the embedder of documentation chunks, when they have
+	// their own.
+	Docs      *IndexMetadata `json:"docs,omitempty"`
+	CreatedAt time.Time      `json:"created_at,omitzero"`
+}
+
+// IndexMetadata returns the metadata of an index built with e.
+func (e *EmbedderConfig) IndexMetadata() IndexMetadata {
+	meta := IndexMetadata{Provider: e.Provider, Model: e.Model, Dimensions: e.GetDimensions()}
+	if e.Docs != nil {
+		docs := e.Docs.IndexMetadata()
+		meta.Docs = &docs
+	}
+	return meta
+}
+
+// Matches reports whether m and other identify the same embedders.
+func (m IndexMetadata) Matches(other IndexMetadata) bool {
+	if m.Provider != other.Provider || m.Model != other.Model || m.Dimensions != other.Dimensions {
+		return false
+	}
+	if m.Docs == nil || other.Docs == nil {
+		return m.Docs == nil && other.Docs == nil
+	}
+	return m.Docs.Matches(*other.Docs)
+}
+
+func (m IndexMetadata) String() string {
+	s := fmt.Sprintf("%s model %q (%d dimensions)", m.Provider, m.Model, m.Dimensions)
+	if m.Docs != nil {
+		s += fmt.Sprintf(" with docs embedded by %s", m.Docs)
+	}
+	return s
+}
+
+// GetIndexMetadataPath returns the path to the index metadata file of the
+// project at projectRoot.
+func GetIndexMetadataPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), IndexMetadataFileName)
+}
+
+// GetWorkspaceIndexMetadataPath returns the path to the index metadata file
+// of a workspace's shared index.
+func GetWorkspaceIndexMetadataPath(workspaceName string) (string, error) {
+	globalDir, err := GetGlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(globalDir, "index-meta", workspaceName+".json"), nil
+}
+
+// GetGlobalIndexMetadataPath returns the path to the index metadata file of
+// the global index.
+func GetGlobalIndexMetadataPath() (string, error) {
+	dir, err := GetGlobalIndexDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, IndexMetadataFileName), nil
+}
+
+// LoadIndexMetadata reads the index metadata file at path. Returns nil if
+// the index has none, as indexes built before it was recorded do.
+func LoadIndexMetadata(path string) (*IndexMetadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read index metadata: %w", err)
+	}
+	var meta IndexMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse index metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+// SaveIndexMetadata writes the index metadata file at path.
+func SaveIndexMetadata(path string, meta IndexMetadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index metadata: %w", err)
+	}
+	if err := fileutil.EnsureParentDir(path); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write index metadata: %w", err)
+	}
+	return nil
+}
+
+// Verify returns an error wrapping ErrEmbedderMismatch when m, the
+// metadata of an index, identifies another embedder than current. A nil m,
+// for an index without metadata, passes.
+func (m *IndexMetadata) Verify(current IndexMetadata) error {
+	if m == nil || m.Matches(current) {
+		return nil
+	}
+	return fmt.Errorf("%w: the index holds vectors of %s but the configured embedder is %s; restore the embedder settings, or rebuild the index with the new embedder", ErrEmbedderMismatch, m, current)
+}
+
+// CheckIndexMetadata verifies that the index whose metadata file is at path
+// was built with emb.
+func CheckIndexMetadata(path string, emb EmbedderConfig) error {
+	meta, err := LoadIndexMetadata(path)
+	if err != nil {
+		return err
+	}
+	return meta.Verify(emb.IndexMetadata())
+}
+
+// CheckProjectIndexMetadata verifies that the index of the project at
+// projectRoot was built with the embedder of cfg. The error for a local
+// index tells how to rebuild it.
+func CheckProjectIndexMetadata(projectRoot string, cfg *Config) error {
+	err := CheckIndexMetadata(GetIndexMetadataPath(projectRoot), cfg.Embedder)
+	if errors.Is(err, ErrEmbedderMismatch) && cfg.Store.Backend == "gob" {
+		return fmt.Errorf("%w (delete %s, then run 'grepai watch' to rebuild it)", err, GetIndexPath(projectRoot))
+	}
+	return err
+}
+
+// CheckWorkspaceIndexMetadata verifies that the shared index of ws was built
+// with the workspace embedder.
+func CheckWorkspaceIndexMetadata(ws *Workspace) error {
+	path, err := GetWorkspaceIndexMetadataPath(ws.Name)
+	if err != nil {
+		return err
+	}
+	if err := CheckIndexMetadata(path, ws.Embedder); err != nil {
+		return fmt.Errorf("workspace %s: %w", ws.Name, err)
+	}
+	return nil
+}
+
+// CheckGlobalIndexMetadata verifies that the global index was built with
+// the embedder of cfg. The error tells how to rebuild it.
+func CheckGlobalIndexMetadata(cfg *GlobalConfig) error {
+	dir, err := GetGlobalIndexDir()
+	if err != nil {
+		return err
+	}
+	err = CheckIndexMetadata(filepath.Join(dir, IndexMetadataFileName), cfg.Embedder)
+	if errors.Is(err, ErrEmbedderMismatch) {
+		return fmt.Errorf("global index: %w (delete %s, then run 'grepai global index' to rebuild it)", err, filepath.Join(dir, IndexFileName))
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckProjectIndexMetadata(t *testing.T) {
+	root := t.TempDir()
+	cfg := DefaultConfig()
+
+	if err := CheckProjectIndexMetadata(root, cfg); err != nil {
+		t.Fatalf("expected an index without metadata to pass, got %v", err)
+	}
+
+	if err := SaveIndexMetadata(GetIndexMetadataPath(root), cfg.Embedder.IndexMetadata()); err != nil {
+		t.Fatalf("failed to save index metadata: %v", err)
+	}
+	if err := CheckProjectIndexMetadata(root, cfg); err != nil {
+		t.Fatalf("expected the same embedder to pass, got %v", err)
+	}
+
+	cfg.Embedder = DefaultEmbedderForProvider("openai")
+	err := CheckProjectIndexMetadata(root, cfg)
+	if !errors.Is(err, ErrEmbedderMismatch) {
+		t.Fatalf("expected ErrEmbedderMismatch, got %v", err)
+	}
+	for _, want := range []string{`ollama model "nomic-embed-text" (768 dimensions)`, `openai model "text-embedding-3-small" (1536 dimensions)`, GetIndexPath(root)} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestIndexMetadataMatchesDocsEmbedder(t *testing.T) {
+	emb := DefaultEmbedderForProvider("ollama")
+	withDocs := emb
+	docs := DefaultEmbedderForProvider("ollama")
+	docs.Model = "mxbai-embed-large"
+	withDocs.Docs = &docs
+
+	if emb.IndexMetadata().Matches(withDocs.IndexMetadata()) {
+		t.Error("expected adding a docs embedder to change the index metadata")
+	}
+	if !withDocs.IndexMetadata().Matches(withDocs.IndexMetadata()) {
+		t.Error("expected the same embedders to match")
+	}
+	other := docs
+	other.Model = "all-minilm"
+	otherDocs := emb
+	otherDocs.Docs = &other
+	if withDocs.IndexMetadata().Matches(otherDocs.IndexMetadata()) {
+		t.Error("expected another docs model to change the index metadata")
+	}
+	if got := withDocs.IndexMetadata().String(); !strings.Contains(got, `docs embedded by ollama model "mxbai-embed-large"`) {
+		t.Errorf("unexpected description %q", got)
+	}
+}
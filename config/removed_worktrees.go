@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RemovedWorktreesFileName records, in the main worktree's .grepai
+// directory, the linked worktrees the watcher saw removed and when, so
+// their leftover index directories can be purged.
+const RemovedWorktreesFileName = "removed-worktrees.json"
+
+// GetRemovedWorktreesPath returns the path to the removed worktrees file of
+// the main worktree at projectRoot.
+func GetRemovedWorktreesPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), RemovedWorktreesFileName)
+}
+
+// LoadRemovedWorktrees returns the removed worktrees recorded for
+// projectRoot, keyed by path. Returns an empty map if none are recorded.
+func LoadRemovedWorktrees(projectRoot string) (map[string]time.Time, error) {
+	removed := make(map[string]time.Time)
+	data, err := os.ReadFile(GetRemovedWorktreesPath(projectRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return removed, nil
+		}
+		return nil, fmt.Errorf("failed to read removed worktrees: %w", err)
+	}
+	if err := json.Unmarshal(data, &removed); err != nil {
+		return nil, fmt.Errorf("failed to parse removed worktrees: %w", err)
+	}
+	return removed, nil
+}
+
+// SaveRemovedWorktrees writes the removed worktrees for projectRoot. An
+// empty map deletes the file.
+func SaveRemovedWorktrees(projectRoot string, removed map[string]time.Time) error {
+	path := GetRemovedWorktreesPath(projectRoot)
+	if len(removed) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove removed worktrees file: %w", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(removed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal removed worktrees: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write removed worktrees: %w", err)
+	}
+	return nil
+}
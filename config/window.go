@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeWindow is a daily range of local time, such as 02:00-05:00. A window
+// whose end is before its start spans midnight. A nil *TimeWindow covers
+// the whole day.
+type TimeWindow struct {
+	start time.Duration // offset from midnight
+	end   time.Duration
+	text  string
+}
+
+// ParseTimeWindow parses a "HH:MM-HH:MM" range. It returns nil for an empty
+// string.
+func ParseTimeWindow(s string) (*TimeWindow, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid time window %q: expected HH:MM-HH:MM", s)
+	}
+	start, err := parseClock(strings.TrimSpace(from))
+	if err != nil {
+		return nil, fmt.Errorf("invalid time window %q: %w", s, err)
+	}
+	end, err := parseClock(strings.TrimSpace(to))
+	if err != nil {
+		return nil, fmt.Errorf("invalid time window %q: %w", s, err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("invalid time window %q: start and end are equal", s)
+	}
+	return &TimeWindow{start: start, end: end, text: s}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String returns the window as configured.
+func (w *TimeWindow) String() string {
+	if w == nil {
+		return ""
+	}
+	return w.text
+}
+
+// Contains reports whether t falls inside the window.
+func (w *TimeWindow) Contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	offset := t.Sub(midnight(t))
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// NextStart returns t if it falls inside the window, and otherwise the time
+// the window next opens.
+func (w *TimeWindow) NextStart(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	day := midnight(t)
+	next := day.Add(w.start)
+	if !next.After(t) {
+		next = day.AddDate(0, 0, 1).Add(w.start)
+	}
+	return next
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeWindow(t *testing.T) {
+	if w, err := ParseTimeWindow(""); w != nil || err != nil {
+		t.Fatalf("ParseTimeWindow(\"\") = %v, %v; want nil, nil", w, err)
+	}
+	for _, bad := range []string{"02:00", "2am-5am", "02:00-25:00", "03:00-03:00"} {
+		if _, err := ParseTimeWindow(bad); err == nil {
+			t.Errorf("ParseTimeWindow(%q) should fail", bad)
+		}
+	}
+}
+
+func TestTimeWindowContainsAndNextStart(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.Local)
+	}
+
+	night, err := ParseTimeWindow("02:00-05:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !night.Contains(at(10, 2, 0)) || !night.Contains(at(10, 4, 59)) {
+		t.Error("expected 02:00 and 04:59 inside 02:00-05:00")
+	}
+	if night.Contains(at(10, 5, 0)) || night.Contains(at(10, 14, 0)) {
+		t.Error("expected 05:00 and 14:00 outside 02:00-05:00")
+	}
+	if got, want := night.NextStart(at(10, 14, 0)), at(11, 2, 0); !got.Equal(want) {
+		t.Errorf("NextStart(14:00) = %v, want %v", got, want)
+	}
+	if got, want := night.NextStart(at(10, 1, 0)), at(10, 2, 0); !got.Equal(want) {
+		t.Errorf("NextStart(01:00) = %v, want %v", got, want)
+	}
+	if got := night.NextStart(at(10, 3, 0)); !got.Equal(at(10, 3, 0)) {
+		t.Errorf("NextStart inside the window = %v, want now", got)
+	}
+
+	overnight, err := ParseTimeWindow("22:00-02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !overnight.Contains(at(10, 23, 0)) || !overnight.Contains(at(10, 1, 0)) || overnight.Contains(at(10, 12, 0)) {
+		t.Error("unexpected membership for window spanning midnight")
+	}
+
+	var always *TimeWindow
+	if !always.Contains(at(10, 12, 0)) {
+		t.Error("nil window should cover the whole day")
+	}
+}
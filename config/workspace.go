@@ -11,6 +11,15 @@ import (
 
 const (
 	WorkspaceConfigFileName = "workspace.yaml"
+
+	// DefaultRouterTopProjects is the number of projects a workspace search
+	// is routed to when the router sets none.
+	DefaultRouterTopProjects = 5
+
+	// Score normalizations of workspace search results, per project.
+	ScoreNormalizationNone   = "none"
+	ScoreNormalizationZScore = "zscore"
+	ScoreNormalizationMinMax = "minmax"
 )
 
 // WorkspaceConfig holds global workspace configuration.
@@ -26,12 +35,65 @@ type Workspace struct {
 	Store    StoreConfig    `yaml:"store"`
 	Embedder EmbedderConfig `yaml:"embedder"`
 	Projects []ProjectEntry `yaml:"projects"`
+	// Router narrows searches over many projects to the most relevant ones.
+	Router RouterConfig `yaml:"router,omitempty"`
+	// ScoreNormalization rescales search scores within each project before
+	// results are merged: none (default), zscore or minmax.
+	ScoreNormalization string `yaml:"score_normalization,omitempty"`
+}
+
+// RouterConfig configures project routing for workspace searches. When
+// enabled, a search that selects no projects is first matched against a
+// summary of each project (its README and CODEOWNERS owners, or the summary
+// set on its entry) and only searches the best TopProjects of them.
+type RouterConfig struct {
+	Enabled     bool `yaml:"enabled"`
+	TopProjects int  `yaml:"top_projects,omitempty"`
+}
+
+// Top returns the number of projects searches are routed to.
+func (r RouterConfig) Top() int {
+	if r.TopProjects > 0 {
+		return r.TopProjects
+	}
+	return DefaultRouterTopProjects
+}
+
+// Routes reports whether searches of a workspace with the given number of
+// projects are routed: the router is enabled and there are more projects
+// than it keeps.
+func (r RouterConfig) Routes(projects int) bool {
+	return r.Enabled && projects > r.Top()
 }
 
 // ProjectEntry represents a single project within a workspace.
 type ProjectEntry struct {
 	Name string `yaml:"name"`
 	Path string `yaml:"path"`
+	// Ignore adds ignore patterns to the project's own when the workspace
+	// indexes it.
+	Ignore []string `yaml:"ignore,omitempty"`
+	// Languages replaces the project's traced languages (file extensions)
+	// when the workspace indexes it.
+	Languages []string `yaml:"languages,omitempty"`
+	// Summary describes the project to the workspace router, instead of
+	// its README.
+	Summary string `yaml:"summary,omitempty"`
+}
+
+// WithOverrides returns a copy of cfg, the project's own configuration,
+// with the project's workspace overrides applied. cfg is left unchanged so
+// it can be saved back without them. Embedder settings are never
+// overridden, as every project of a workspace shares its embedder.
+func (p ProjectEntry) WithOverrides(cfg *Config) *Config {
+	out := *cfg
+	if len(p.Ignore) > 0 {
+		out.Ignore = append(append([]string(nil), cfg.Ignore...), p.Ignore...)
+	}
+	if len(p.Languages) > 0 {
+		out.Trace.EnabledLanguages = append([]string(nil), p.Languages...)
+	}
+	return &out
 }
 
 // GetGlobalConfigDir returns the global grepai config directory path.
@@ -44,6 +106,16 @@ func GetGlobalConfigDir() (string, error) {
 	return filepath.Join(homeDir, ".grepai"), nil
 }
 
+// GetRouterCachePath returns the path of the cache of a workspace's project
+// summary embeddings, used by the workspace router.
+func GetRouterCachePath(workspaceName string) (string, error) {
+	globalDir, err := GetGlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(globalDir, "router", workspaceName+".json"), nil
+}
+
 // GetWorkspaceConfigPath returns the path to the workspace config file.
 func GetWorkspaceConfigPath() (string, error) {
 	globalDir, err := GetGlobalConfigDir()
@@ -202,11 +274,11 @@ func (c *WorkspaceConfig) RemoveProject(workspaceName, projectName string) error
 // GOB backend is not supported for workspaces (file-based, can't be shared).
 func ValidateWorkspaceBackend(ws *Workspace) error {
 	if ws.Store.Backend == "gob" || ws.Store.Backend == "" {
-		return fmt.Errorf("workspace %q uses GOB backend which is not supported for multi-project workspaces; use 'postgres' or 'qdrant' instead", ws.Name)
+		return fmt.Errorf("workspace %q uses GOB backend which is not supported for multi-project workspaces; use 'postgres', 'qdrant', 'weaviate', 'redis' or 'opensearch' instead", ws.Name)
 	}
 
-	if ws.Store.Backend != "postgres" && ws.Store.Backend != "qdrant" {
-		return fmt.Errorf("unknown backend %q for workspace %q; supported backends: postgres, qdrant", ws.Store.Backend, ws.Name)
+	if ws.Store.Backend != "postgres" && ws.Store.Backend != "qdrant" && ws.Store.Backend != "weaviate" && ws.Store.Backend != "redis" && ws.Store.Backend != "opensearch" {
+		return fmt.Errorf("unknown backend %q for workspace %q; supported backends: postgres, qdrant, weaviate, redis, opensearch", ws.Store.Backend, ws.Name)
 	}
 
 	return nil
@@ -334,6 +334,30 @@ func TestValidateWorkspaceBackend(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "weaviate_valid",
+			workspace: Workspace{
+				Name:  "test",
+				Store: StoreConfig{Backend: "weaviate"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "redis_valid",
+			workspace: Workspace{
+				Name:  "test",
+				Store: StoreConfig{Backend: "redis"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "opensearch_valid",
+			workspace: Workspace{
+				Name:  "test",
+				Store: StoreConfig{Backend: "opensearch"},
+			},
+			wantErr: false,
+		},
 		{
 			name: "gob_invalid",
 			workspace: Workspace{
@@ -516,3 +540,52 @@ func TestGetWorkspaceConfigPath(t *testing.T) {
 		t.Errorf("expected %s, got %s", expected, path)
 	}
 }
+
+func TestProjectEntry_WithOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Trace.EnabledLanguages = []string{".go"}
+	ignoreCount := len(cfg.Ignore)
+
+	p := ProjectEntry{Name: "api", Path: "/src/api", Ignore: []string{"fixtures"}, Languages: []string{".ts", ".tsx"}}
+	out := p.WithOverrides(cfg)
+
+	if len(out.Ignore) != ignoreCount+1 || out.Ignore[len(out.Ignore)-1] != "fixtures" {
+		t.Fatalf("ignore = %v, want the project's patterns plus fixtures", out.Ignore)
+	}
+	if len(out.Trace.EnabledLanguages) != 2 || out.Trace.EnabledLanguages[0] != ".ts" {
+		t.Fatalf("languages = %v, want [.ts .tsx]", out.Trace.EnabledLanguages)
+	}
+	if len(cfg.Ignore) != ignoreCount || len(cfg.Trace.EnabledLanguages) != 1 {
+		t.Fatal("WithOverrides must not modify the project's configuration")
+	}
+
+	if got := (ProjectEntry{Name: "web"}).WithOverrides(cfg); len(got.Ignore) != ignoreCount || got.Trace.EnabledLanguages[0] != ".go" {
+		t.Fatal("a project without overrides should keep its configuration")
+	}
+}
+
+func TestWorkspaceConfig_ProjectOverridesRoundTrip(t *testing.T) {
+	cleanup := setTestHomeDir(t, t.TempDir())
+	defer cleanup()
+
+	cfg := DefaultWorkspaceConfig()
+	ws := Workspace{
+		Name:     "demo",
+		Store:    StoreConfig{Backend: "qdrant"},
+		Projects: []ProjectEntry{{Name: "api", Path: "/src/api", Ignore: []string{"fixtures"}, Languages: []string{".go"}}},
+	}
+	if err := cfg.AddWorkspace(ws); err != nil {
+		t.Fatalf("failed to add workspace: %v", err)
+	}
+	if err := SaveWorkspaceConfig(cfg); err != nil {
+		t.Fatalf("failed to save workspace config: %v", err)
+	}
+	loaded, err := LoadWorkspaceConfig()
+	if err != nil {
+		t.Fatalf("failed to load workspace config: %v", err)
+	}
+	got := loaded.Workspaces["demo"].Projects[0]
+	if len(got.Ignore) != 1 || got.Ignore[0] != "fixtures" || len(got.Languages) != 1 || got.Languages[0] != ".go" {
+		t.Fatalf("project = %+v, want overrides preserved", got)
+	}
+}
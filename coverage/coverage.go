@@ -0,0 +1,124 @@
+// Package coverage imports test coverage profiles and reports the line
+// coverage of code ranges.
+package coverage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Coverage is the line coverage of a range of a file.
+type Coverage struct {
+	Covered int `json:"covered"` // lines run by tests
+	Lines   int `json:"lines"`   // lines with statements
+}
+
+// Percent returns the share of lines covered, from 0 to 100.
+func (c Coverage) Percent() float64 {
+	if c.Lines == 0 {
+		return 0
+	}
+	return 100 * float64(c.Covered) / float64(c.Lines)
+}
+
+// String formats the coverage as "75% (6/8 lines)".
+func (c Coverage) String() string {
+	return fmt.Sprintf("%.0f%% (%d/%d lines)", c.Percent(), c.Covered, c.Lines)
+}
+
+// Profile is the line coverage of a project, keyed by file path relative to
+// the project root. A nil *Profile covers nothing.
+type Profile struct {
+	Source     string    `json:"source"` // path of the imported profile
+	Format     string    `json:"format"`
+	ImportedAt time.Time `json:"imported_at"`
+	// Files maps each file to the hit count of its lines with statements.
+	Files map[string]map[int]int `json:"files"`
+}
+
+// Range returns the coverage of lines start to end of file, or nil when the
+// profile has no lines with statements there.
+func (p *Profile) Range(file string, start, end int) *Coverage {
+	if p == nil {
+		return nil
+	}
+	lines := p.Files[filepath.ToSlash(file)]
+	if len(lines) == 0 {
+		return nil
+	}
+	if end < start {
+		end = start
+	}
+	var c Coverage
+	for line := start; line <= end; line++ {
+		hits, ok := lines[line]
+		if !ok {
+			continue
+		}
+		c.Lines++
+		if hits > 0 {
+			c.Covered++
+		}
+	}
+	if c.Lines == 0 {
+		return nil
+	}
+	return &c
+}
+
+// Uncovered reports whether lines start to end of file have statements and
+// none of them was run by tests.
+func (p *Profile) Uncovered(file string, start, end int) bool {
+	c := p.Range(file, start, end)
+	return c != nil && c.Covered == 0
+}
+
+// Total returns the coverage of every file of the profile.
+func (p *Profile) Total() Coverage {
+	var c Coverage
+	if p == nil {
+		return c
+	}
+	for _, lines := range p.Files {
+		for _, hits := range lines {
+			c.Lines++
+			if hits > 0 {
+				c.Covered++
+			}
+		}
+	}
+	return c
+}
+
+// Load reads the profile saved at path. It returns nil without error when
+// no profile was imported.
+func Load(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage: %w", err)
+	}
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse coverage %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Save writes the profile to path.
+func (p *Profile) Save(path string) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode coverage: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write coverage: %w", err)
+	}
+	return nil
+}
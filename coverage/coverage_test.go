@@ -0,0 +1,108 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGo(t *testing.T) {
+	profile := `mode: set
+example.com/app/server/server.go:10.30,12.2 2 1
+example.com/app/server/server.go:14.30,18.2 3 0
+example.com/app/server/server.go:16.10,16.20 1 1
+github.com/other/lib/lib.go:3.1,4.2 1 1
+`
+	p, err := ParseGo(strings.NewReader(profile), "example.com/app", "/src/app")
+	if err != nil {
+		t.Fatalf("ParseGo failed: %v", err)
+	}
+	if len(p.Files) != 2 || p.Files["github.com/other/lib/lib.go"] == nil {
+		t.Fatalf("expected the project file and the foreign import path, got %v", p.Files)
+	}
+
+	if c := p.Range("server/server.go", 10, 12); c == nil || *c != (Coverage{Covered: 3, Lines: 3}) {
+		t.Errorf("expected the first function covered, got %+v", c)
+	}
+	if c := p.Range("server/server.go", 14, 18); c == nil || *c != (Coverage{Covered: 1, Lines: 5}) {
+		t.Errorf("expected one covered line in the second function, got %+v", c)
+	}
+	if !p.Uncovered("server/server.go", 17, 18) || p.Uncovered("server/server.go", 10, 18) {
+		t.Error("expected only lines 17-18 to be uncovered")
+	}
+	if c := p.Range("server/server.go", 1, 9); c != nil {
+		t.Errorf("expected no coverage without statements, got %+v", c)
+	}
+	if c := p.Range("missing.go", 1, 9); c != nil {
+		t.Errorf("expected no coverage for an unknown file, got %+v", c)
+	}
+
+	if _, err := ParseGo(strings.NewReader("mode: set\nbroken line\n"), "", ""); err == nil {
+		t.Error("expected an error for an invalid profile")
+	}
+}
+
+func TestParseLCOV(t *testing.T) {
+	tracefile := `TN:
+SF:/src/app/web/app.ts
+FN:1,boot
+DA:1,4
+DA:2,0
+DA:3,2
+end_of_record
+SF:lib/util.js
+DA:5,0
+end_of_record
+`
+	p, err := ParseLCOV(strings.NewReader(tracefile), "/src/app")
+	if err != nil {
+		t.Fatalf("ParseLCOV failed: %v", err)
+	}
+	if c := p.Range("web/app.ts", 1, 3); c == nil || *c != (Coverage{Covered: 2, Lines: 3}) {
+		t.Errorf("expected 2 of 3 lines covered, got %+v", c)
+	}
+	if !p.Uncovered("lib/util.js", 1, 10) {
+		t.Error("expected lib/util.js to be uncovered")
+	}
+	if total := p.Total(); total != (Coverage{Covered: 2, Lines: 4}) || total.String() != "50% (2/4 lines)" {
+		t.Errorf("unexpected total coverage %v", total)
+	}
+}
+
+func TestParseFileAndSave(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.24\n"), 0644); err != nil {
+		t.Fatalf("failed to write go.mod: %v", err)
+	}
+	profilePath := filepath.Join(dir, "cover.out")
+	if err := os.WriteFile(profilePath, []byte("mode: atomic\nexample.com/app/main.go:3.13,5.2 1 7\n"), 0644); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	p, err := ParseFile(profilePath, "", dir)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if p.Format != FormatGo || p.Source != profilePath || p.Range("main.go", 3, 5) == nil {
+		t.Fatalf("expected main.go from a detected Go profile, got %+v", p)
+	}
+	if _, err := ParseFile(profilePath, "cobertura", dir); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+
+	savePath := filepath.Join(dir, "coverage.json")
+	if err := p.Save(savePath); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	loaded, err := Load(savePath)
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if c := loaded.Range("main.go", 3, 5); c == nil || c.Covered != 3 {
+		t.Errorf("expected the saved coverage, got %+v", c)
+	}
+	if missing, err := Load(filepath.Join(dir, "none.json")); missing != nil || err != nil {
+		t.Errorf("expected no profile and no error, got %v, %v", missing, err)
+	}
+}
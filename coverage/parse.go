@@ -0,0 +1,200 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile formats accepted by Parse.
+const (
+	FormatGo   = "go"   // go test -coverprofile
+	FormatLCOV = "lcov" // lcov tracefiles, written by most JavaScript, Python and C tools
+)
+
+// ParseFile reads the coverage profile at path in format, or in the format
+// detected from its content when format is empty. File paths are made
+// relative to projectRoot: Go import paths by removing the module path of
+// the project's go.mod, absolute paths by making them relative.
+func ParseFile(path, format, projectRoot string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+	if format == "" {
+		format = DetectFormat(data)
+	}
+
+	var p *Profile
+	switch format {
+	case FormatGo:
+		p, err = ParseGo(bytes.NewReader(data), goModulePath(projectRoot), projectRoot)
+	case FormatLCOV:
+		p, err = ParseLCOV(bytes.NewReader(data), projectRoot)
+	default:
+		return nil, fmt.Errorf("invalid coverage format %q: must be %s or %s", format, FormatGo, FormatLCOV)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	p.Source = path
+	p.ImportedAt = time.Now().UTC()
+	return p, nil
+}
+
+// DetectFormat returns the format of a coverage profile: Go profiles start
+// with a "mode:" line, anything else is read as lcov.
+func DetectFormat(data []byte) string {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("mode:")) {
+		return FormatGo
+	}
+	return FormatLCOV
+}
+
+// ParseGo parses a Go coverage profile. Each block marks its lines as lines
+// with statements; a line is covered when a block over it ran. Blocks of
+// files outside module are kept under their import path unless absolute.
+func ParseGo(r io.Reader, module, projectRoot string) (*Profile, error) {
+	p := &Profile{Format: FormatGo, Files: make(map[string]map[int]int)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// name.go:startLine.startCol,endLine.endCol numStmts count
+		colon := strings.LastIndexByte(line, ':')
+		if colon < 0 {
+			return nil, fmt.Errorf("invalid Go coverage line %d: %q", lineNum, line)
+		}
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid Go coverage line %d: %q", lineNum, line)
+		}
+		start, end, ok := parseGoBlock(fields[0])
+		count, err := strconv.Atoi(fields[2])
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid Go coverage line %d: %q", lineNum, line)
+		}
+		lines := p.fileLines(goProfilePath(line[:colon], module, projectRoot))
+		for l := start; l <= end; l++ {
+			if hits, seen := lines[l]; !seen || count > hits {
+				lines[l] = count
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Go coverage: %w", err)
+	}
+	return p, nil
+}
+
+// parseGoBlock parses "startLine.startCol,endLine.endCol".
+func parseGoBlock(block string) (start, end int, ok bool) {
+	from, to, found := strings.Cut(block, ",")
+	if !found {
+		return 0, 0, false
+	}
+	startLine, _, _ := strings.Cut(from, ".")
+	endLine, _, _ := strings.Cut(to, ".")
+	start, err1 := strconv.Atoi(startLine)
+	end, err2 := strconv.Atoi(endLine)
+	return start, end, err1 == nil && err2 == nil && start > 0 && end >= start
+}
+
+// ParseLCOV parses an lcov tracefile, reading the SF (source file) and DA
+// (line hits) records.
+func ParseLCOV(r io.Reader, projectRoot string) (*Profile, error) {
+	p := &Profile{Format: FormatLCOV, Files: make(map[string]map[int]int)}
+	var lines map[int]int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			lines = p.fileLines(relativePath(strings.TrimPrefix(line, "SF:"), projectRoot))
+		case strings.HasPrefix(line, "DA:"):
+			if lines == nil {
+				return nil, fmt.Errorf("invalid lcov line %d: DA record outside of a source file", lineNum)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("invalid lcov line %d: %q", lineNum, line)
+			}
+			l, err1 := strconv.Atoi(fields[0])
+			hits, err2 := strconv.Atoi(fields[1])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid lcov line %d: %q", lineNum, line)
+			}
+			lines[l] += hits
+		case line == "end_of_record":
+			lines = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read lcov coverage: %w", err)
+	}
+	return p, nil
+}
+
+// fileLines returns the line hits of file, adding the file when needed.
+func (p *Profile) fileLines(file string) map[int]int {
+	lines := p.Files[file]
+	if lines == nil {
+		lines = make(map[int]int)
+		p.Files[file] = lines
+	}
+	return lines
+}
+
+// goProfilePath maps the file of a Go coverage block to a path relative to
+// the project root.
+func goProfilePath(name, module, projectRoot string) string {
+	if module != "" {
+		if rel, ok := strings.CutPrefix(name, module+"/"); ok {
+			return rel
+		}
+	}
+	return relativePath(name, projectRoot)
+}
+
+// relativePath makes an absolute path relative to projectRoot when it is
+// inside it, and cleans it to slash form.
+func relativePath(path, projectRoot string) string {
+	if filepath.IsAbs(path) && projectRoot != "" {
+		if rel, err := filepath.Rel(projectRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// goModulePath returns the module path declared by projectRoot/go.mod, or
+// "" without one.
+func goModulePath(projectRoot string) string {
+	data, err := os.ReadFile(filepath.Join(projectRoot, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
@@ -61,6 +61,7 @@ const (
 	pidFileName         = "grepai-watch.pid"
 	logFileName         = "grepai-watch.log"
 	readyFileName       = "grepai-watch.ready"
+	watchAllLogFileName = "grepai-watch-all.log"
 	worktreePIDPrefix   = "grepai-worktree-"
 	worktreePIDSuffix   = ".pid"
 	worktreeLogPrefix   = "grepai-worktree-"
@@ -453,6 +454,23 @@ func SpawnWorktreeBackground(logDir, worktreeID string, extraArgs []string) (int
 	return spawnBackgroundWithLog(logDir, logPath, extraArgs)
 }
 
+// GetWatchAllLogFile returns the path to the log file of 'grepai watch --all'.
+func GetWatchAllLogFile(logDir string) string {
+	return filepath.Join(logDir, watchAllLogFileName)
+}
+
+// SpawnWatchAllBackground re-executes the current binary for 'grepai watch --all' in background.
+func SpawnWatchAllBackground(logDir string, extraArgs []string) (int, <-chan struct{}, error) {
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return 0, nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	args := []string{"watch", "--all"}
+	args = append(args, extraArgs...)
+
+	return spawnBackgroundWithLog(logDir, GetWatchAllLogFile(logDir), args)
+}
+
 // spawnBackgroundWithLog spawns a background process with a custom log file.
 // Returns the child PID and a channel that is closed when the child exits.
 // Uses platform-specific liveness detection (pipe on Unix, polling on Windows).
@@ -428,6 +428,14 @@ func TestWorktreePathHelpers(t *testing.T) {
 	}
 }
 
+func TestGetWatchAllLogFile(t *testing.T) {
+	logDir := t.TempDir()
+	want := filepath.Join(logDir, "grepai-watch-all.log")
+	if got := GetWatchAllLogFile(logDir); got != want {
+		t.Fatalf("GetWatchAllLogFile() = %q, want %q", got, want)
+	}
+}
+
 func TestReadWorktreePIDFileNotExists(t *testing.T) {
 	logDir := t.TempDir()
 	pid, err := ReadWorktreePIDFile(logDir, "missing")
@@ -0,0 +1,76 @@
+// Package docextract converts PDF and DOCX documents into plain text, one
+// entry per page, so design docs can be indexed next to the code.
+//
+// Extraction is best effort and uses only the standard library: text drawn
+// with simple fonts is recovered, while scanned pages and text drawn with
+// embedded CID fonts come back empty.
+package docextract
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Page is the text of one page of a document.
+type Page struct {
+	Number int // 1-indexed
+	Text   string
+}
+
+// Supported reports whether path has an extension Extract understands.
+func Supported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf", ".docx":
+		return true
+	}
+	return false
+}
+
+// Extract returns the text of each page of a PDF or DOCX document. Pages
+// without text are omitted.
+func Extract(path string, data []byte) ([]Page, error) {
+	var pages []Page
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pdf":
+		pages, err = ExtractPDF(data)
+	case ".docx":
+		pages, err = ExtractDOCX(data)
+	default:
+		return nil, fmt.Errorf("unsupported document type: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonEmpty := pages[:0]
+	for _, p := range pages {
+		p.Text = cleanText(p.Text)
+		if p.Text != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return nonEmpty, nil
+}
+
+// cleanText trims trailing spaces, collapses runs of spaces and drops
+// repeated blank lines.
+func cleanText(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
@@ -0,0 +1,122 @@
+package docextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF writes a minimal PDF with one page per content stream. Streams
+// after the first are Flate-compressed.
+func buildPDF(t *testing.T, contents ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	n := len(contents)
+	kids := make([]string, n)
+	for i := range contents {
+		kids[i] = fmt.Sprintf("%d 0 R", 3+i)
+	}
+	fmt.Fprintf(&buf, "1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), n)
+	for i := range contents {
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>\nendobj\n", 3+i, 3+n+i)
+	}
+	for i, content := range contents {
+		data := []byte(content)
+		filter := ""
+		if i > 0 {
+			var z bytes.Buffer
+			zw := zlib.NewWriter(&z)
+			if _, err := zw.Write(data); err != nil {
+				t.Fatalf("compress: %v", err)
+			}
+			zw.Close()
+			data = z.Bytes()
+			filter = " /Filter /FlateDecode"
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Length %d%s >>\nstream\n", 3+n+i, len(data), filter)
+		buf.Write(data)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func TestExtractPDF(t *testing.T) {
+	data := buildPDF(t,
+		"BT /F1 12 Tf 72 720 Td (Design: the \\(new\\) indexer) Tj 0 -14 Td [(Chunks are) -250 (hashed)] TJ ET",
+		"BT /F1 12 Tf 72 720 Td (Page two text) Tj ET",
+		"q 0 0 1 rg Q", // a page without text
+	)
+
+	pages, err := Extract("design.pdf", data)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages with text, got %d: %+v", len(pages), pages)
+	}
+	if pages[0].Number != 1 || pages[0].Text != "Design: the (new) indexer\nChunks are hashed" {
+		t.Errorf("unexpected page 1: %+v", pages[0])
+	}
+	if pages[1].Number != 2 || pages[1].Text != "Page two text" {
+		t.Errorf("unexpected page 2: %+v", pages[1])
+	}
+}
+
+func TestExtractPDF_Invalid(t *testing.T) {
+	if _, err := ExtractPDF([]byte("not a pdf")); err == nil {
+		t.Error("expected error for missing header")
+	}
+}
+
+func buildDOCX(t *testing.T, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>%s</w:body></w:document>`, body)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractDOCX(t *testing.T) {
+	data := buildDOCX(t,
+		`<w:p><w:r><w:t>Overview</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:t xml:space="preserve">Search uses </w:t></w:r><w:r><w:t>vectors.</w:t></w:r></w:p>`+
+			`<w:p><w:r><w:br w:type="page"/><w:t>Appendix</w:t></w:r></w:p>`)
+
+	pages, err := Extract("spec.docx", data)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d: %+v", len(pages), pages)
+	}
+	if pages[0].Text != "Overview\nSearch uses vectors." {
+		t.Errorf("unexpected page 1 text %q", pages[0].Text)
+	}
+	if pages[1].Number != 2 || pages[1].Text != "Appendix" {
+		t.Errorf("unexpected page 2: %+v", pages[1])
+	}
+}
+
+func TestExtractDOCX_MissingBody(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	zw.Close()
+	if _, err := ExtractDOCX(buf.Bytes()); err == nil {
+		t.Error("expected error for docx without word/document.xml")
+	}
+}
@@ -0,0 +1,99 @@
+package docextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExtractDOCX returns the text of a Word document. DOCX files do not store
+// a layout, so pages are counted from explicit page breaks and the breaks
+// Word records when it last rendered the document.
+func ExtractDOCX(data []byte) ([]Page, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid docx: %w", err)
+	}
+
+	var body *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			body = f
+			break
+		}
+	}
+	if body == nil {
+		return nil, errors.New("invalid docx: word/document.xml not found")
+	}
+
+	rc, err := body.Open()
+	if err != nil {
+		return nil, fmt.Errorf("invalid docx: %w", err)
+	}
+	defer rc.Close()
+
+	pages := []Page{{Number: 1}}
+	var text strings.Builder
+	newPage := func() {
+		pages[len(pages)-1].Text = text.String()
+		text.Reset()
+		pages = append(pages, Page{Number: len(pages) + 1})
+	}
+
+	dec := xml.NewDecoder(rc)
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid docx: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteString("\t")
+			case "br":
+				if xmlAttr(t, "type") == "page" {
+					newPage()
+				} else {
+					text.WriteString("\n")
+				}
+			case "lastRenderedPageBreak":
+				if strings.TrimSpace(text.String()) != "" {
+					newPage()
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+	pages[len(pages)-1].Text = text.String()
+	return pages, nil
+}
+
+func xmlAttr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,462 @@
+package docextract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxPDFStreamSize bounds the size of a decompressed PDF stream.
+const maxPDFStreamSize = 64 << 20
+
+var (
+	pdfObjHeader   = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfRef         = regexp.MustCompile(`(\d+)\s+\d+\s+R\b`)
+	pdfRootRef     = regexp.MustCompile(`/Root\s+(\d+)\s+\d+\s+R\b`)
+	pdfKidsRefs    = regexp.MustCompile(`/Kids\s*\[([^\]]*)\]`)
+	pdfContentsRef = regexp.MustCompile(`/Contents\s*(\[[^\]]*\]|\d+\s+\d+\s+R)`)
+	pdfLengthValue = regexp.MustCompile(`/Length\s+(\d+)(\s+\d+\s+R)?`)
+	pdfTypePage    = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfTypePages   = regexp.MustCompile(`/Type\s*/Pages\b`)
+	pdfTypeObjStm  = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	pdfPagesRef    = regexp.MustCompile(`/Pages\s+(\d+)\s+\d+\s+R\b`)
+	pdfNValue      = regexp.MustCompile(`/N\s+(\d+)`)
+	pdfFirstValue  = regexp.MustCompile(`/First\s+(\d+)`)
+)
+
+// pdfObject is an indirect object: its dictionary (or other value) and the
+// raw bytes of its stream, if it has one.
+type pdfObject struct {
+	dict   string
+	stream []byte
+}
+
+// ExtractPDF returns the text of each page of a PDF. Pages are found by
+// walking the page tree from the document catalog, falling back to every
+// page object in object-number order when the catalog cannot be read.
+func ExtractPDF(data []byte) ([]Page, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF")) {
+		return nil, errors.New("invalid pdf: missing header")
+	}
+	objects := parsePDFObjects(data)
+	if len(objects) == 0 {
+		return nil, errors.New("invalid pdf: no objects found")
+	}
+
+	pageIDs := pdfPageOrder(data, objects)
+	pages := make([]Page, 0, len(pageIDs))
+	for i, id := range pageIDs {
+		var text strings.Builder
+		for _, contentID := range pdfContentRefs(objects[id].dict) {
+			content, ok := objects[contentID]
+			if !ok || content.stream == nil {
+				continue
+			}
+			decoded, err := decodePDFStream(content)
+			if err != nil {
+				continue // unsupported filter; the page may have other streams
+			}
+			text.WriteString(pdfContentText(decoded))
+			text.WriteString("\n")
+		}
+		pages = append(pages, Page{Number: i + 1, Text: text.String()})
+	}
+	return pages, nil
+}
+
+// parsePDFObjects reads every indirect object in the file, including the
+// ones packed into object streams. Later definitions win, which matches
+// incremental updates appended to the end of the file.
+func parsePDFObjects(data []byte) map[int]pdfObject {
+	objects := make(map[int]pdfObject)
+	var objStreams []pdfObject
+
+	for _, m := range pdfObjHeader.FindAllSubmatchIndex(data, -1) {
+		id, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		obj, ok := parsePDFObjectBody(data[m[1]:])
+		if !ok {
+			continue
+		}
+		objects[id] = obj
+		if obj.stream != nil && pdfTypeObjStm.MatchString(obj.dict) {
+			objStreams = append(objStreams, obj)
+		}
+	}
+
+	for _, stm := range objStreams {
+		for id, dict := range parsePDFObjectStream(stm) {
+			if _, exists := objects[id]; !exists {
+				objects[id] = pdfObject{dict: dict}
+			}
+		}
+	}
+	return objects
+}
+
+func parsePDFObjectBody(rest []byte) (pdfObject, bool) {
+	end := bytes.Index(rest, []byte("endobj"))
+	streamAt := bytes.Index(rest, []byte("stream"))
+	if streamAt < 0 || (end >= 0 && streamAt > end) {
+		if end < 0 {
+			return pdfObject{}, false
+		}
+		return pdfObject{dict: string(rest[:end])}, true
+	}
+
+	dict := string(rest[:streamAt])
+	start := streamAt + len("stream")
+	if start < len(rest) && rest[start] == '\r' {
+		start++
+	}
+	if start < len(rest) && rest[start] == '\n' {
+		start++
+	}
+
+	// A direct /Length is exact; an indirect one would need another lookup,
+	// so fall back to searching for the end marker.
+	stop := -1
+	if m := pdfLengthValue.FindStringSubmatch(dict); m != nil && m[2] == "" {
+		if n, err := strconv.Atoi(m[1]); err == nil && start+n <= len(rest) {
+			stop = start + n
+		}
+	}
+	if stop < 0 {
+		idx := bytes.Index(rest[start:], []byte("endstream"))
+		if idx < 0 {
+			return pdfObject{}, false
+		}
+		stop = start + idx
+	}
+	return pdfObject{dict: dict, stream: rest[start:stop]}, true
+}
+
+// parsePDFObjectStream unpacks the objects stored in an /ObjStm stream.
+func parsePDFObjectStream(stm pdfObject) map[int]string {
+	decoded, err := decodePDFStream(stm)
+	if err != nil {
+		return nil
+	}
+	n, first := pdfDictInt(stm.dict, pdfNValue), pdfDictInt(stm.dict, pdfFirstValue)
+	if n <= 0 || first <= 0 || first > len(decoded) {
+		return nil
+	}
+
+	header := strings.Fields(string(decoded[:first]))
+	type entry struct{ id, offset int }
+	entries := make([]entry, 0, n)
+	for i := 0; i+1 < len(header) && len(entries) < n; i += 2 {
+		id, err1 := strconv.Atoi(header[i])
+		off, err2 := strconv.Atoi(header[i+1])
+		if err1 != nil || err2 != nil {
+			return nil
+		}
+		entries = append(entries, entry{id, off})
+	}
+
+	objects := make(map[int]string, len(entries))
+	for i, e := range entries {
+		start := first + e.offset
+		end := len(decoded)
+		if i+1 < len(entries) {
+			end = first + entries[i+1].offset
+		}
+		if start < 0 || start > end || end > len(decoded) {
+			continue
+		}
+		objects[e.id] = string(decoded[start:end])
+	}
+	return objects
+}
+
+func pdfDictInt(dict string, re *regexp.Regexp) int {
+	m := re.FindStringSubmatch(dict)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// pdfPageOrder returns page object IDs in reading order.
+func pdfPageOrder(data []byte, objects map[int]pdfObject) []int {
+	// The catalog is referenced from the trailer or, in PDF 1.5+ files, from
+	// the cross-reference stream dictionary. The last reference wins.
+	var pages []int
+	if roots := pdfRootRef.FindAllSubmatch(data, -1); len(roots) > 0 {
+		rootID, _ := strconv.Atoi(string(roots[len(roots)-1][1]))
+		if root, ok := objects[rootID]; ok {
+			if m := pdfPagesRef.FindStringSubmatch(root.dict); m != nil {
+				treeID, _ := strconv.Atoi(m[1])
+				pages = walkPDFPageTree(objects, treeID, make(map[int]bool))
+			}
+		}
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	for id, obj := range objects {
+		if pdfTypePage.MatchString(obj.dict) && !pdfTypePages.MatchString(obj.dict) {
+			pages = append(pages, id)
+		}
+	}
+	sort.Ints(pages)
+	return pages
+}
+
+func walkPDFPageTree(objects map[int]pdfObject, id int, seen map[int]bool) []int {
+	if seen[id] {
+		return nil
+	}
+	seen[id] = true
+	obj, ok := objects[id]
+	if !ok {
+		return nil
+	}
+	if !pdfTypePages.MatchString(obj.dict) {
+		if pdfTypePage.MatchString(obj.dict) {
+			return []int{id}
+		}
+		return nil
+	}
+
+	var pages []int
+	if m := pdfKidsRefs.FindStringSubmatch(obj.dict); m != nil {
+		for _, ref := range pdfRef.FindAllStringSubmatch(m[1], -1) {
+			kid, _ := strconv.Atoi(ref[1])
+			pages = append(pages, walkPDFPageTree(objects, kid, seen)...)
+		}
+	}
+	return pages
+}
+
+func pdfContentRefs(dict string) []int {
+	m := pdfContentsRef.FindStringSubmatch(dict)
+	if m == nil {
+		return nil
+	}
+	var ids []int
+	for _, ref := range pdfRef.FindAllStringSubmatch(m[1], -1) {
+		id, _ := strconv.Atoi(ref[1])
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// decodePDFStream applies the stream's filter. Only a single FlateDecode
+// filter without predictor parameters is supported, which covers content
+// streams written by common tools.
+func decodePDFStream(obj pdfObject) ([]byte, error) {
+	if !strings.Contains(obj.dict, "/Filter") {
+		return obj.stream, nil
+	}
+	if !strings.Contains(obj.dict, "/FlateDecode") || strings.Count(obj.dict, "Decode") > 1 {
+		return nil, errors.New("unsupported pdf stream filter")
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(obj.stream))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pdf stream: %w", err)
+	}
+	defer zr.Close()
+	// Truncated streams are common; keep whatever decompressed cleanly.
+	decoded, err := io.ReadAll(io.LimitReader(zr, maxPDFStreamSize))
+	if err != nil && len(decoded) == 0 {
+		return nil, fmt.Errorf("invalid pdf stream: %w", err)
+	}
+	return decoded, nil
+}
+
+// pdfContentText extracts the strings shown by text operators in a content
+// stream. Text positioning operators become line breaks, and large gaps in
+// TJ arrays become spaces.
+func pdfContentText(content []byte) string {
+	var out strings.Builder
+	var operands []string // string operands since the last operator
+	var array []string    // strings of the TJ array being read
+	inArray := false
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, next := readPDFLiteral(content, i)
+			if inArray {
+				array = append(array, s)
+			} else {
+				operands = append(operands, s)
+			}
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			s, next := readPDFHex(content, i)
+			if inArray {
+				array = append(array, s)
+			} else {
+				operands = append(operands, s)
+			}
+			i = next
+		case c == '[':
+			inArray = true
+			array = array[:0]
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case isPDFDelimiterOrSpace(c):
+			i++
+		default:
+			start := i
+			for i < len(content) && !isPDFDelimiterOrSpace(content[i]) {
+				i++
+			}
+			token := string(content[start:i])
+			if inArray {
+				// Kerning adjustments in thousandths of a text unit; a
+				// large negative value is a visible gap between words.
+				if n, err := strconv.ParseFloat(token, 64); err == nil && n < -200 {
+					array = append(array, " ")
+				}
+				continue
+			}
+			switch token {
+			case "Tj":
+				out.WriteString(strings.Join(operands, ""))
+			case "'", "\"":
+				out.WriteString("\n")
+				out.WriteString(strings.Join(operands, ""))
+			case "TJ":
+				out.WriteString(strings.Join(array, ""))
+				array = array[:0]
+			case "Td", "TD", "T*", "Tm", "ET":
+				out.WriteString("\n")
+			}
+			if _, err := strconv.ParseFloat(token, 64); err != nil {
+				operands = operands[:0]
+			}
+		}
+	}
+	return out.String()
+}
+
+func isPDFDelimiterOrSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// readPDFLiteral reads a (...) string starting at content[i] and returns it
+// decoded as Latin-1, along with the index after the closing parenthesis.
+func readPDFLiteral(content []byte, i int) (string, int) {
+	var b []byte
+	depth := 0
+	for i++; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(content) {
+				break
+			}
+			switch e := content[i]; e {
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation.
+				if e == '\r' && i+1 < len(content) && content[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					for k := 0; k < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; k++ {
+						n = n*8 + int(content[i]-'0')
+						i++
+					}
+					i--
+					b = append(b, byte(n))
+				} else {
+					b = append(b, e)
+				}
+			}
+		case '(':
+			depth++
+			b = append(b, c)
+		case ')':
+			if depth == 0 {
+				return pdfBytesToText(b), i + 1
+			}
+			depth--
+			b = append(b, c)
+		default:
+			b = append(b, c)
+		}
+	}
+	return pdfBytesToText(b), i
+}
+
+// readPDFHex reads a <...> string starting at content[i].
+func readPDFHex(content []byte, i int) (string, int) {
+	end := bytes.IndexByte(content[i:], '>')
+	if end < 0 {
+		return "", len(content)
+	}
+	var digits []byte
+	for _, c := range content[i+1 : i+end] {
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	b := make([]byte, len(digits)/2)
+	for k := range b {
+		n, _ := strconv.ParseUint(string(digits[2*k:2*k+2]), 16, 8)
+		b[k] = byte(n)
+	}
+	return pdfBytesToText(b), i + end + 1
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// pdfBytesToText maps string bytes to text, treating them as Latin-1 (close
+// enough to PDFDocEncoding and WinAnsiEncoding for indexing). Strings with a
+// UTF-16 byte order mark are decoded as UTF-16BE. Control bytes, typical of
+// CID-keyed fonts, are dropped.
+func pdfBytesToText(b []byte) string {
+	var sb strings.Builder
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		for k := 2; k+1 < len(b); k += 2 {
+			sb.WriteRune(rune(b[k])<<8 | rune(b[k+1]))
+		}
+		return sb.String()
+	}
+	for _, c := range b {
+		if c < 0x20 && c != '\n' && c != '\t' {
+			continue
+		}
+		sb.WriteRune(rune(c))
+	}
+	return sb.String()
+}
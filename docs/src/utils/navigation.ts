@@ -31,6 +31,8 @@ export const navigation: NavSection[] = [
       { label: 'Hybrid Search', href: '/grepai/hybrid-search/', order: 8 },
       { label: 'Git Worktrees', href: '/grepai/git-worktrees/', order: 9 },
       { label: 'Workspace Management', href: '/grepai/workspace/', order: 10 },
+      { label: 'Global Index', href: '/grepai/global/', order: 11 },
+      { label: 'Go Library', href: '/grepai/go-library/', order: 12 },
     ],
   },
   {
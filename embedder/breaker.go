@@ -0,0 +1,145 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without calling the embedder, while a circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("embedder unavailable: circuit breaker open")
+
+// CircuitBreaker stops sending requests to an embedder that keeps failing,
+// typically because it is unreachable. It opens after threshold requests in
+// a row failed and then fails every request with ErrCircuitOpen. Once
+// openFor has elapsed, a single request is let through: its success closes
+// the breaker, its failure keeps it open for another openFor. Errors caused
+// by the input, such as a ContextLengthError, or by the caller canceling its
+// context are not counted. It is safe for concurrent use.
+type CircuitBreaker struct {
+	threshold int
+	openFor   time.Duration
+	now       func() time.Time
+	onChange  func(open bool, err error)
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker returns a closed breaker. now defaults to time.Now.
+// onChange, which may be nil, is called with the failure that opened the
+// breaker when it opens, and with a nil error when it closes again.
+func NewCircuitBreaker(threshold int, openFor time.Duration, now func() time.Time, onChange func(open bool, err error)) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &CircuitBreaker{threshold: threshold, openFor: openFor, now: now, onChange: onChange}
+}
+
+// Open reports whether the breaker is open.
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// allow returns ErrCircuitOpen when a request must not be sent.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return nil
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.openFor {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record counts the outcome of a request sent with ctx.
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	b.probing = false
+	if err != nil && (ctx.Err() != nil || IsContextLengthError(err)) {
+		b.mu.Unlock()
+		return
+	}
+
+	changed := false
+	if err == nil {
+		b.failures = 0
+		changed = b.open
+		b.open = false
+	} else {
+		b.failures++
+		if b.open {
+			b.openedAt = b.now()
+		} else if b.failures >= b.threshold {
+			b.open = true
+			b.openedAt = b.now()
+			changed = true
+		}
+	}
+	open := b.open
+	b.mu.Unlock()
+
+	if changed && b.onChange != nil {
+		b.onChange(open, err)
+	}
+}
+
+// WithCircuitBreaker returns emb sending its requests through breaker. The
+// returned embedder is a BatchEmbedder when emb is one.
+func WithCircuitBreaker(emb Embedder, breaker *CircuitBreaker) Embedder {
+	guarded := &breakerEmbedder{Embedder: emb, breaker: breaker}
+	if batch, ok := emb.(BatchEmbedder); ok {
+		return &breakerBatchEmbedder{breakerEmbedder: guarded, batch: batch}
+	}
+	return guarded
+}
+
+type breakerEmbedder struct {
+	Embedder
+	breaker *CircuitBreaker
+}
+
+func (e *breakerEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if err := e.breaker.allow(); err != nil {
+		return nil, err
+	}
+	vector, err := e.Embedder.Embed(ctx, text)
+	e.breaker.record(ctx, err)
+	return vector, err
+}
+
+func (e *breakerEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := e.breaker.allow(); err != nil {
+		return nil, err
+	}
+	vectors, err := e.Embedder.EmbedBatch(ctx, texts)
+	e.breaker.record(ctx, err)
+	return vectors, err
+}
+
+type breakerBatchEmbedder struct {
+	*breakerEmbedder
+	batch BatchEmbedder
+}
+
+func (e *breakerBatchEmbedder) EmbedBatches(ctx context.Context, batches []Batch, progress BatchProgress) ([]BatchResult, error) {
+	if err := e.breaker.allow(); err != nil {
+		return nil, err
+	}
+	results, err := e.batch.EmbedBatches(ctx, batches, progress)
+	e.breaker.record(ctx, err)
+	return results, err
+}
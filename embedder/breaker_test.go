@@ -0,0 +1,99 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type failingEmbedder struct {
+	stubEmbedder
+	err   error
+	calls int
+}
+
+func (e *failingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	return []float32{1}, nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var changes []bool
+	breaker := NewCircuitBreaker(2, time.Minute, func() time.Time { return now }, func(open bool, err error) {
+		changes = append(changes, open)
+	})
+	inner := &failingEmbedder{err: errors.New("connection refused")}
+	emb := WithCircuitBreaker(inner, breaker)
+
+	// Input errors do not count.
+	inner.err = NewContextLengthError(0, 9000, 8192, "too long")
+	_, _ = emb.Embed(ctx, "a")
+	inner.err = errors.New("connection refused")
+	_, _ = emb.Embed(ctx, "a")
+	if breaker.Open() {
+		t.Fatal("expected the breaker to stay closed below the threshold")
+	}
+	_, _ = emb.Embed(ctx, "a")
+	if !breaker.Open() {
+		t.Fatal("expected the breaker to open at the threshold")
+	}
+
+	calls := inner.calls
+	if _, err := emb.Embed(ctx, "a"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if inner.calls != calls {
+		t.Error("expected an open breaker not to call the embedder")
+	}
+
+	// A failed probe keeps it open for another period.
+	now = now.Add(time.Minute)
+	_, _ = emb.Embed(ctx, "a")
+	if inner.calls != calls+1 || !breaker.Open() {
+		t.Fatalf("expected one failed probe, got %d calls, open=%v", inner.calls-calls, breaker.Open())
+	}
+	if _, err := emb.Embed(ctx, "a"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after a failed probe, got %v", err)
+	}
+
+	// A successful probe closes it.
+	now = now.Add(time.Minute)
+	inner.err = nil
+	if _, err := emb.Embed(ctx, "a"); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if breaker.Open() {
+		t.Fatal("expected a successful probe to close the breaker")
+	}
+	if want := []bool{true, false}; len(changes) != 2 || changes[0] != want[0] || changes[1] != want[1] {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestCircuitBreaker_IgnoresCanceledRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	breaker := NewCircuitBreaker(1, time.Minute, nil, nil)
+	emb := WithCircuitBreaker(&failingEmbedder{err: context.Canceled}, breaker)
+
+	_, _ = emb.Embed(ctx, "a")
+	if breaker.Open() {
+		t.Error("expected a canceled request not to open the breaker")
+	}
+}
+
+func TestWithCircuitBreaker_KeepsBatchEmbedder(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Minute, nil, nil)
+	if _, ok := WithCircuitBreaker(stubEmbedder{}, breaker).(BatchEmbedder); ok {
+		t.Error("expected a plain embedder to stay a plain embedder")
+	}
+	if _, ok := WithCircuitBreaker(stubBatchEmbedder{}, breaker).(BatchEmbedder); !ok {
+		t.Error("expected a batch embedder to stay a batch embedder")
+	}
+}
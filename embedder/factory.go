@@ -79,3 +79,13 @@ func NewFromWorkspaceConfig(ws *config.Workspace) (Embedder, error) {
 	}
 	return NewFromConfig(cfg)
 }
+
+// NewDocsFromConfig creates the embedder of documentation files configured
+// in cfg.Embedder.Docs. It returns nil when documentation is embedded with
+// the main embedder.
+func NewDocsFromConfig(cfg *config.Config) (Embedder, error) {
+	if cfg.Embedder.Docs == nil {
+		return nil, nil
+	}
+	return NewFromConfig(&config.Config{Embedder: *cfg.Embedder.Docs})
+}
@@ -0,0 +1,68 @@
+package embedder
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// TokenUsage counts the tokens sent to an embedder, estimated with
+// EstimateTokens. It is safe for concurrent use.
+type TokenUsage struct {
+	tokens atomic.Int64
+}
+
+// Add counts n more tokens.
+func (u *TokenUsage) Add(n int64) {
+	u.tokens.Add(n)
+}
+
+// Take returns the tokens counted since the last call and resets the count.
+func (u *TokenUsage) Take() int64 {
+	return u.tokens.Swap(0)
+}
+
+// WithTokenUsage returns emb counting the tokens of every text it embeds in
+// usage. Texts of failed requests are counted too, as providers may bill
+// them. The returned embedder is a BatchEmbedder when emb is one.
+func WithTokenUsage(emb Embedder, usage *TokenUsage) Embedder {
+	counting := &usageEmbedder{Embedder: emb, usage: usage}
+	if batch, ok := emb.(BatchEmbedder); ok {
+		return &usageBatchEmbedder{usageEmbedder: counting, batch: batch}
+	}
+	return counting
+}
+
+type usageEmbedder struct {
+	Embedder
+	usage *TokenUsage
+}
+
+func (e *usageEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.usage.Add(int64(EstimateTokens(text)))
+	return e.Embedder.Embed(ctx, text)
+}
+
+func (e *usageEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e.count(texts)
+	return e.Embedder.EmbedBatch(ctx, texts)
+}
+
+func (e *usageEmbedder) count(texts []string) {
+	var tokens int64
+	for _, text := range texts {
+		tokens += int64(EstimateTokens(text))
+	}
+	e.usage.Add(tokens)
+}
+
+type usageBatchEmbedder struct {
+	*usageEmbedder
+	batch BatchEmbedder
+}
+
+func (e *usageBatchEmbedder) EmbedBatches(ctx context.Context, batches []Batch, progress BatchProgress) ([]BatchResult, error) {
+	for i := range batches {
+		e.count(batches[i].Contents())
+	}
+	return e.batch.EmbedBatches(ctx, batches, progress)
+}
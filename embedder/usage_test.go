@@ -0,0 +1,53 @@
+package embedder
+
+import (
+	"context"
+	"testing"
+)
+
+type stubEmbedder struct{}
+
+func (stubEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return []float32{1}, nil
+}
+
+func (stubEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+
+func (stubEmbedder) Dimensions() int { return 1 }
+
+func (stubEmbedder) Close() error { return nil }
+
+type stubBatchEmbedder struct{ stubEmbedder }
+
+func (stubBatchEmbedder) EmbedBatches(ctx context.Context, batches []Batch, progress BatchProgress) ([]BatchResult, error) {
+	return nil, nil
+}
+
+func TestWithTokenUsage(t *testing.T) {
+	ctx := context.Background()
+	var usage TokenUsage
+
+	emb := WithTokenUsage(stubEmbedder{}, &usage)
+	if _, ok := emb.(BatchEmbedder); ok {
+		t.Error("expected a plain embedder to stay a plain embedder")
+	}
+	_, _ = emb.Embed(ctx, "12345678")
+	_, _ = emb.EmbedBatch(ctx, []string{"1234", "12345"})
+	if got := usage.Take(); got != 5 {
+		t.Errorf("expected 5 tokens, got %d", got)
+	}
+	if got := usage.Take(); got != 0 {
+		t.Errorf("expected Take to reset the count, got %d", got)
+	}
+
+	batch, ok := WithTokenUsage(stubBatchEmbedder{}, &usage).(BatchEmbedder)
+	if !ok {
+		t.Fatal("expected a batch embedder to stay a batch embedder")
+	}
+	_, _ = batch.EmbedBatches(ctx, []Batch{{Entries: []BatchEntry{{Content: "12345678"}}}, {Entries: []BatchEntry{{Content: "1234"}}}}, nil)
+	if got := usage.Take(); got != 3 {
+		t.Errorf("expected 3 tokens, got %d", got)
+	}
+}
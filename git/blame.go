@@ -0,0 +1,233 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LastChange describes the most recent commit touching a line range.
+type LastChange struct {
+	Author string    `json:"author"`
+	Email  string    `json:"email,omitempty"`
+	Commit string    `json:"commit"`
+	Date   time.Time `json:"date"`
+}
+
+// ShortCommit returns the abbreviated commit hash.
+func (c LastChange) ShortCommit() string {
+	if len(c.Commit) > 7 {
+		return c.Commit[:7]
+	}
+	return c.Commit
+}
+
+// FileBlame is the blame of a single file: the commit of each line and the
+// details of every commit involved.
+type FileBlame struct {
+	Lines   []string              `json:"lines"` // commit hash per line, indexed from line 1
+	Commits map[string]LastChange `json:"commits"`
+}
+
+// Range returns the most recent committed change to lines start..end
+// (1-based, inclusive), or nil when none of the lines are committed.
+func (b *FileBlame) Range(start, end int) *LastChange {
+	if b == nil {
+		return nil
+	}
+	if start < 1 {
+		start = 1
+	}
+	if end > len(b.Lines) {
+		end = len(b.Lines)
+	}
+	var latest *LastChange
+	for line := start; line <= end; line++ {
+		c, ok := b.Commits[b.Lines[line-1]]
+		if !ok {
+			continue
+		}
+		if latest == nil || c.Date.After(latest.Date) {
+			latest = &c
+		}
+	}
+	return latest
+}
+
+// uncommittedHash is the commit git blame reports for lines that only exist
+// in the working tree.
+const uncommittedHash = "0000000000000000000000000000000000000000"
+
+// BlameFile runs git blame on a file of the repository containing dir.
+// Lines that are not committed yet are left without a commit.
+func BlameFile(ctx context.Context, dir, filePath string) (*FileBlame, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "blame", "--line-porcelain", "--", filePath).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w", filePath, err)
+	}
+	return parseLinePorcelain(out)
+}
+
+// parseLinePorcelain parses `git blame --line-porcelain` output, where every
+// line is preceded by a header and the full details of its commit.
+func parseLinePorcelain(out []byte) (*FileBlame, error) {
+	blame := &FileBlame{Commits: make(map[string]LastChange)}
+
+	var commit string
+	var change LastChange
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "\t") {
+			// The line content ends each entry.
+			if commit == uncommittedHash {
+				blame.Lines = append(blame.Lines, "")
+			} else {
+				blame.Lines = append(blame.Lines, commit)
+				change.Commit = commit
+				blame.Commits[commit] = change
+			}
+			commit = ""
+			change = LastChange{}
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch {
+		case commit == "":
+			if len(key) != len(uncommittedHash) {
+				return nil, fmt.Errorf("unexpected git blame header: %q", line)
+			}
+			commit = key
+		case key == "author":
+			change.Author = value
+		case key == "author-mail":
+			change.Email = strings.Trim(value, "<>")
+		case key == "author-time":
+			secs, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid author-time %q: %w", value, err)
+			}
+			change.Date = time.Unix(secs, 0).UTC()
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return blame, nil
+}
+
+// Blamer looks up the last change to line ranges of a project's files. Blame
+// output is cached per file content hash, in memory and in an optional cache
+// file, so unchanged files are only blamed once.
+type Blamer struct {
+	root      string
+	cachePath string
+	files     map[string]blameCacheEntry
+	loaded    bool
+	dirty     bool
+}
+
+type blameCacheEntry struct {
+	Hash  string     `json:"hash"`
+	Blame *FileBlame `json:"blame"`
+}
+
+// NewBlamer returns a Blamer for the project at projectRoot. When cachePath
+// is empty, blame results are only cached for the Blamer's lifetime.
+func NewBlamer(projectRoot, cachePath string) *Blamer {
+	return &Blamer{
+		root:      projectRoot,
+		cachePath: cachePath,
+		files:     make(map[string]blameCacheEntry),
+	}
+}
+
+// LastChange returns the most recent committed change to a line range of a
+// project-relative file, or nil when it cannot be determined (the project is
+// not a git repository, or the lines are not committed yet).
+func (b *Blamer) LastChange(ctx context.Context, filePath string, startLine, endLine int) *LastChange {
+	if b == nil {
+		return nil
+	}
+	blame := b.fileBlame(ctx, filePath)
+	return blame.Range(startLine, endLine)
+}
+
+func (b *Blamer) fileBlame(ctx context.Context, filePath string) *FileBlame {
+	b.load()
+
+	content, err := os.ReadFile(filepath.Join(b.root, filepath.FromSlash(filePath)))
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	if entry, ok := b.files[filePath]; ok && entry.Hash == hash {
+		return entry.Blame
+	}
+
+	blame, err := BlameFile(ctx, b.root, filePath)
+	if err != nil {
+		return nil
+	}
+	// Uncommitted lines are attributed once they are committed, without the
+	// content changing, so only fully committed files are cached.
+	for _, commit := range blame.Lines {
+		if commit == "" {
+			delete(b.files, filePath)
+			return blame
+		}
+	}
+	b.files[filePath] = blameCacheEntry{Hash: hash, Blame: blame}
+	b.dirty = true
+	return blame
+}
+
+func (b *Blamer) load() {
+	if b.loaded {
+		return
+	}
+	b.loaded = true
+	if b.cachePath == "" {
+		return
+	}
+	data, err := os.ReadFile(b.cachePath)
+	if err != nil {
+		return
+	}
+	var files map[string]blameCacheEntry
+	if err := json.Unmarshal(data, &files); err == nil && files != nil {
+		b.files = files
+	}
+}
+
+// Save writes newly blamed files to the cache file.
+func (b *Blamer) Save() error {
+	if b == nil || !b.dirty || b.cachePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(b.files)
+	if err != nil {
+		return fmt.Errorf("failed to encode blame cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(b.cachePath), 0755); err != nil {
+		return fmt.Errorf("failed to create blame cache directory: %w", err)
+	}
+	if err := os.WriteFile(b.cachePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write blame cache: %w", err)
+	}
+	b.dirty = false
+	return nil
+}
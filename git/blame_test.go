@@ -0,0 +1,97 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func commitFile(t *testing.T, repo, name, content, author, date string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	for _, args := range [][]string{
+		{"add", name},
+		{"-c", "user.name=" + author, "commit", "-m", "update " + name},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestBlamer_LastChange(t *testing.T) {
+	repo := t.TempDir()
+	setupGitRepo(t, repo)
+	commitFile(t, repo, "main.go", "package main\n\nfunc a() {}\n\nfunc b() {}\n", "Alice", "2024-01-02T10:00:00Z")
+	commitFile(t, repo, "main.go", "package main\n\nfunc a() {}\n\nfunc b() { println() }\n", "Bob", "2024-03-04T10:00:00Z")
+
+	ctx := context.Background()
+	cachePath := filepath.Join(repo, ".grepai", "blame.json")
+	blamer := NewBlamer(repo, cachePath)
+
+	if c := blamer.LastChange(ctx, "main.go", 1, 3); c == nil || c.Author != "Alice" {
+		t.Errorf("lines 1-3: expected Alice, got %+v", c)
+	}
+	c := blamer.LastChange(ctx, "main.go", 1, 5)
+	if c == nil || c.Author != "Bob" || c.Email != "test@test.com" || len(c.Commit) != 40 || c.Date.Format("2006-01-02") != "2024-03-04" {
+		t.Fatalf("lines 1-5: expected Bob's commit, got %+v", c)
+	}
+	if blamer.LastChange(ctx, "missing.go", 1, 1) != nil {
+		t.Error("expected nil for a missing file")
+	}
+
+	if err := blamer.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("expected blame cache file: %v", err)
+	}
+
+	// A fresh blamer answers from the cache without running git.
+	t.Setenv("PATH", "")
+	cached := NewBlamer(repo, cachePath)
+	if c := cached.LastChange(ctx, "main.go", 5, 5); c == nil || c.Author != "Bob" {
+		t.Errorf("cached lookup: expected Bob, got %+v", c)
+	}
+}
+
+func TestBlamer_UncommittedLines(t *testing.T) {
+	repo := t.TempDir()
+	setupGitRepo(t, repo)
+	commitFile(t, repo, "main.go", "package main\n", "Alice", "2024-01-02T10:00:00Z")
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n\nfunc added() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	blamer := NewBlamer(repo, "")
+	ctx := context.Background()
+	if c := blamer.LastChange(ctx, "main.go", 3, 3); c != nil {
+		t.Errorf("expected nil for uncommitted lines, got %+v", c)
+	}
+	if c := blamer.LastChange(ctx, "main.go", 1, 3); c == nil || c.Author != "Alice" {
+		t.Errorf("expected the last committed change, got %+v", c)
+	}
+	if _, ok := blamer.files["main.go"]; ok {
+		t.Error("files with uncommitted lines must not be cached")
+	}
+}
+
+func TestFileBlame_RangeClamps(t *testing.T) {
+	var nilBlame *FileBlame
+	if nilBlame.Range(1, 10) != nil {
+		t.Error("expected nil from nil blame")
+	}
+	b := &FileBlame{
+		Lines:   []string{"c1", "c1"},
+		Commits: map[string]LastChange{"c1": {Author: "Alice", Commit: "c1"}},
+	}
+	if c := b.Range(0, 100); c == nil || c.Author != "Alice" {
+		t.Errorf("expected out-of-range lines to be clamped, got %+v", c)
+	}
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// CodeownersLocations are the repository-relative paths searched for a
+// CODEOWNERS file, in the order GitHub uses them.
+var CodeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Codeowners maps paths to their owners following a CODEOWNERS file. A nil
+// *Codeowners owns nothing.
+type Codeowners struct {
+	// File is the CODEOWNERS file the rules were read from.
+	File string
+
+	rules  []ownerRule
+	prefix string // project directory within the repository, slash-terminated
+}
+
+type ownerRule struct {
+	pattern string
+	matcher *ignore.GitIgnore
+	owners  []string
+}
+
+// ParseCodeowners parses CODEOWNERS content. Each rule is a gitignore-style
+// pattern followed by owners (@user, @org/team or an email address).
+// GitLab section headers are skipped.
+func ParseCodeowners(data []byte) *Codeowners {
+	c := &Codeowners{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		rule := ownerRule{pattern: fields[0], matcher: ignore.CompileIgnoreLines(fields[0])}
+		if len(fields) > 1 {
+			rule.owners = fields[1:]
+		}
+		c.rules = append(c.rules, rule)
+	}
+	return c
+}
+
+// LoadCodeowners reads the CODEOWNERS file of the repository containing
+// projectRoot. It returns nil when the repository has none.
+func LoadCodeowners(projectRoot string) (*Codeowners, error) {
+	repoRoot := findRepoRoot(projectRoot)
+	for _, loc := range CodeownersLocations {
+		path := filepath.Join(repoRoot, filepath.FromSlash(loc))
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		c := ParseCodeowners(data)
+		c.File = path
+		if rel, err := filepath.Rel(repoRoot, projectRoot); err == nil && rel != "." {
+			c.prefix = filepath.ToSlash(rel) + "/"
+		}
+		return c, nil
+	}
+	return nil, nil
+}
+
+// findRepoRoot returns the closest directory at or above dir containing a
+// .git entry, or dir itself when there is none.
+func findRepoRoot(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for d := abs; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return abs
+		}
+		d = parent
+	}
+}
+
+// Owners returns the owners of a project-relative path. The last matching
+// rule wins; a matching rule without owners leaves the path unowned.
+func (c *Codeowners) Owners(filePath string) []string {
+	if rule := c.match(filePath); rule != nil {
+		return rule.owners
+	}
+	return nil
+}
+
+// Rule returns the pattern of the rule that decides a path's owners, or an
+// empty string when no rule matches.
+func (c *Codeowners) Rule(filePath string) string {
+	if rule := c.match(filePath); rule != nil {
+		return rule.pattern
+	}
+	return ""
+}
+
+func (c *Codeowners) match(filePath string) *ownerRule {
+	if c == nil {
+		return nil
+	}
+	repoPath := c.prefix + strings.TrimPrefix(filepath.ToSlash(filePath), "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].matcher.MatchesPath(repoPath) {
+			return &c.rules[i]
+		}
+	}
+	return nil
+}
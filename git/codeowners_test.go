@@ -0,0 +1,80 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodeowners_Owners(t *testing.T) {
+	c := ParseCodeowners([]byte(`# Default owners
+* @org/core
+
+/docs/ @org/docs docs@example.com
+apps/ @org/apps
+*.js @org/frontend # trailing comment
+src/*.go @alice
+/generated/
+
+[Optional section]
+`))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"main.go", []string{"@org/core"}},
+		{"docs/guide.md", []string{"@org/docs", "docs@example.com"}},
+		{"lib/docs/guide.md", []string{"@org/core"}}, // /docs/ is anchored
+		{"services/apps/main.go", []string{"@org/apps"}},
+		{"web/app.js", []string{"@org/frontend"}},
+		{"src/main.go", []string{"@alice"}},
+		{"src/sub/main.go", []string{"@org/core"}}, // * does not cross directories
+		{"generated/api.go", nil},                  // explicitly unowned
+	}
+	for _, tt := range tests {
+		if got := c.Owners(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	if rule := c.Rule("docs/guide.md"); rule != "/docs/" {
+		t.Errorf("Rule = %q, want /docs/", rule)
+	}
+
+	var none *Codeowners
+	if none.Owners("main.go") != nil {
+		t.Error("expected nil owners from nil Codeowners")
+	}
+}
+
+func TestLoadCodeowners_ProjectInSubdirectory(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".github", "CODEOWNERS"), []byte("/services/api/ @org/api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project := filepath.Join(repo, "services", "api")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadCodeowners(project)
+	if err != nil {
+		t.Fatalf("LoadCodeowners failed: %v", err)
+	}
+	if got := c.Owners("handler.go"); !reflect.DeepEqual(got, []string{"@org/api"}) {
+		t.Errorf("expected project paths to resolve from the repository root, got %v", got)
+	}
+
+	empty := t.TempDir()
+	if c, err := LoadCodeowners(empty); err != nil || c != nil {
+		t.Errorf("expected nil without CODEOWNERS, got %v, %v", c, err)
+	}
+}
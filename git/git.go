@@ -17,8 +17,15 @@ type DetectInfo struct {
 	GitRoot      string // Worktree root from: git rev-parse --show-toplevel
 	GitCommonDir string // Shared .git directory (absolute): git rev-parse --git-common-dir
 	IsWorktree   bool   // true if this is a linked worktree (not the main one)
-	MainWorktree string // Path to main worktree (parent of GitCommonDir)
+	MainWorktree string // Path to main worktree (parent of GitCommonDir, or the first worktree of a bare repository)
 	WorktreeID   string // Stable ID: hex(sha256(GitCommonDir))[:12]
+	IsBare       bool   // true if the worktree belongs to a bare repository
+}
+
+// Worktree is one entry of 'git worktree list'.
+type Worktree struct {
+	Path string
+	Bare bool // the bare repository itself, which has no checkout
 }
 
 // Detect detects git worktree information for the given path.
@@ -75,6 +82,25 @@ func Detect(path string) (*DetectInfo, error) {
 		mainWorktree = filepath.Dir(filepath.Dir(gitCommonDir))
 	}
 
+	// A bare repository has no main worktree: its first linked worktree
+	// plays that role.
+	isBare := false
+	if out, err := exec.CommandContext(ctx, "git", "-C", path, "config", "--bool", "core.bare").Output(); err == nil && strings.TrimSpace(string(out)) == "true" {
+		isBare = true
+		worktrees, err := listWorktrees(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list worktrees of bare repository: %w", err)
+		}
+		mainWorktree = gitRoot
+		for _, wt := range worktrees {
+			if !wt.Bare {
+				mainWorktree = wt.Path
+				break
+			}
+		}
+		isWorktree = !samePath(mainWorktree, gitRoot)
+	}
+
 	// Calculate WorktreeID as hex(sha256(GitCommonDir))[:12]
 	hash := sha256.Sum256([]byte(gitCommonDir))
 	worktreeID := hex.EncodeToString(hash[:])[:12]
@@ -85,9 +111,104 @@ func Detect(path string) (*DetectInfo, error) {
 		IsWorktree:   isWorktree,
 		MainWorktree: mainWorktree,
 		WorktreeID:   worktreeID,
+		IsBare:       isBare,
 	}, nil
 }
 
+// ListWorktrees returns the worktrees of the repository containing path, as
+// listed by 'git worktree list'. The first entry is the main worktree, or
+// the bare repository itself.
+func ListWorktrees(path string) ([]Worktree, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return listWorktrees(ctx, path)
+}
+
+func listWorktrees(ctx context.Context, path string) ([]Worktree, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", path, "worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return nil, err
+	}
+	var worktrees []Worktree
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			worktrees = append(worktrees, Worktree{Path: strings.TrimPrefix(line, "worktree ")})
+		case line == "bare" && len(worktrees) > 0:
+			worktrees[len(worktrees)-1].Bare = true
+		}
+	}
+	return worktrees, nil
+}
+
+// ListSubmodules returns the absolute paths of the initialized submodules
+// of the repository at path, including nested ones.
+func ListSubmodules(path string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "git", "-C", path, "submodule", "status", "--recursive").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseSubmoduleStatus(path, string(out)), nil
+}
+
+// parseSubmoduleStatus parses 'git submodule status' lines such as
+// " 3f2a... libs/parser (v1.2.0)". Uninitialized submodules, prefixed
+// with '-', have no checkout and are skipped.
+func parseSubmoduleStatus(root, out string) []string {
+	var paths []string
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 2 || line[0] == '-' {
+			continue
+		}
+		_, rest, ok := strings.Cut(line[1:], " ")
+		if !ok {
+			continue
+		}
+		if i := strings.LastIndex(rest, " ("); i >= 0 && strings.HasSuffix(rest, ")") {
+			rest = rest[:i]
+		}
+		if rest == "" {
+			continue
+		}
+		paths = append(paths, filepath.Join(root, filepath.FromSlash(rest)))
+	}
+	return paths
+}
+
+// ExcludeFilePath returns the path of the info/exclude file of the
+// repository containing dir, which ignores files without touching the
+// tracked .gitignore.
+func ExcludeFilePath(dir string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--git-path", "info/exclude").Output()
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
+// samePath reports whether a and b name the same directory, resolving
+// symlinks when possible.
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
 // IsGitRepo returns true if the given path is within a git repository.
 // Returns false on any error (git not installed, not a repo, etc.).
 func IsGitRepo(path string) bool {
@@ -154,6 +154,69 @@ func TestDetect_LinkedWorktree(t *testing.T) {
 	}
 }
 
+func TestDetect_BareRepoWorktrees(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	source := t.TempDir()
+	setupGitRepo(t, source)
+	root := t.TempDir()
+	bare := filepath.Join(root, "repo.git")
+	if out, err := exec.Command("git", "clone", "--bare", source, bare).CombinedOutput(); err != nil {
+		t.Fatalf("failed to clone bare repo: %v\n%s", err, out)
+	}
+	first := filepath.Join(root, "a-main")
+	second := filepath.Join(root, "b-feature")
+	for _, args := range [][]string{{"worktree", "add", first}, {"worktree", "add", "-b", "feature", second}} {
+		if out, err := exec.Command("git", append([]string{"-C", bare}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	firstInfo, err := Detect(first)
+	if err != nil {
+		t.Fatalf("Detect first worktree failed: %v", err)
+	}
+	secondInfo, err := Detect(second)
+	if err != nil {
+		t.Fatalf("Detect second worktree failed: %v", err)
+	}
+
+	if !firstInfo.IsBare || !secondInfo.IsBare {
+		t.Error("expected worktrees of a bare repository to report IsBare")
+	}
+	if firstInfo.IsWorktree {
+		t.Error("the first worktree of a bare repository should act as the main worktree")
+	}
+	if !secondInfo.IsWorktree {
+		t.Error("other worktrees of a bare repository should be linked")
+	}
+	assertSamePath(t, "second MainWorktree", secondInfo.MainWorktree, first)
+	if firstInfo.WorktreeID != secondInfo.WorktreeID {
+		t.Errorf("WorktreeID mismatch: %q vs %q", firstInfo.WorktreeID, secondInfo.WorktreeID)
+	}
+
+	worktrees, err := ListWorktrees(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(worktrees) != 3 || !worktrees[0].Bare || worktrees[1].Bare || worktrees[2].Bare {
+		t.Errorf("ListWorktrees = %+v, want the bare repository then two worktrees", worktrees)
+	}
+}
+
+func TestParseSubmoduleStatus(t *testing.T) {
+	out := " 3f2a1b libs/parser (v1.2.0)\n" +
+		"+9c8d7e third party/ui (heads/main)\n" +
+		"-0a1b2c libs/unused\n"
+	got := parseSubmoduleStatus("/repo", out)
+	want := []string{filepath.Join("/repo", "libs", "parser"), filepath.Join("/repo", "third party", "ui")}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("parseSubmoduleStatus = %q, want %q", got, want)
+	}
+}
+
 func TestDetect_NotGitRepo(t *testing.T) {
 	if _, err := exec.LookPath("git"); err != nil {
 		t.Skip("git not available")
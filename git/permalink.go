@@ -0,0 +1,92 @@
+package git
+
+import (
+	"context"
+	"net/url"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Permalink template placeholders.
+const (
+	PlaceholderCommit    = "{commit}"
+	PlaceholderBranch    = "{branch}"
+	PlaceholderPath      = "{path}"
+	PlaceholderStartLine = "{start_line}"
+	PlaceholderEndLine   = "{end_line}"
+)
+
+// Permalinker renders links to hosted code from a URL template such as
+// "https://github.com/org/repo/blob/{commit}/{path}#L{start_line}-L{end_line}".
+// Paths are project-relative; when the project is a subdirectory of its
+// repository, the directory is prepended so {path} is repository-relative.
+type Permalinker struct {
+	template string
+	commit   string
+	branch   string
+	prefix   string // project directory within the repository, slash-terminated
+}
+
+// NewPermalinker resolves the current commit, branch and repository prefix
+// of projectRoot. It returns nil when template is empty. Outside a git
+// repository, links that need {commit} or {branch} are not rendered.
+func NewPermalinker(template, projectRoot string) *Permalinker {
+	if template == "" {
+		return nil
+	}
+	p := &Permalinker{template: template}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Each argument prints one line: the commit, the branch name ("HEAD"
+	// when detached) and the project's path within the repository.
+	out, err := exec.CommandContext(ctx, "git", "-C", projectRoot, "rev-parse", "HEAD", "--abbrev-ref", "HEAD", "--show-prefix").Output()
+	if err == nil {
+		lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+		if len(lines) >= 2 {
+			p.commit = lines[0]
+			if lines[1] != "HEAD" {
+				p.branch = lines[1]
+			}
+		}
+		if len(lines) >= 3 {
+			p.prefix = lines[2]
+		}
+	}
+	return p
+}
+
+// Link returns the URL for a line range of a project-relative file, or an
+// empty string when the template needs a value that is not known.
+func (p *Permalinker) Link(filePath string, startLine, endLine int) string {
+	if p == nil {
+		return ""
+	}
+	if strings.Contains(p.template, PlaceholderCommit) && p.commit == "" {
+		return ""
+	}
+	if strings.Contains(p.template, PlaceholderBranch) && p.branch == "" {
+		return ""
+	}
+
+	repoPath := path.Join(p.prefix, strings.ReplaceAll(filePath, "\\", "/"))
+	return strings.NewReplacer(
+		PlaceholderCommit, p.commit,
+		PlaceholderBranch, escapePath(p.branch),
+		PlaceholderPath, escapePath(repoPath),
+		PlaceholderStartLine, strconv.Itoa(startLine),
+		PlaceholderEndLine, strconv.Itoa(endLine),
+	).Replace(p.template)
+}
+
+// escapePath escapes each segment of a slash-separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
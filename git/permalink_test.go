@@ -0,0 +1,57 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPermalinker_Link(t *testing.T) {
+	repo := t.TempDir()
+	setupGitRepo(t, repo)
+	project := filepath.Join(repo, "services", "api")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := exec.Command("git", "-C", repo, "checkout", "-q", "-b", "feature/links").Run(); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	out, err := exec.Command("git", "-C", repo, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse: %v", err)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	p := NewPermalinker("https://example.com/blob/{commit}/{path}#L{start_line}-L{end_line}", project)
+	got := p.Link("cmd/my server.go", 10, 20)
+	want := "https://example.com/blob/" + commit + "/services/api/cmd/my%20server.go#L10-L20"
+	if got != want {
+		t.Errorf("Link() = %q, want %q", got, want)
+	}
+
+	p = NewPermalinker("https://example.com/tree/{branch}/{path}", project)
+	if got := p.Link("main.go", 1, 1); got != "https://example.com/tree/feature/links/services/api/main.go" {
+		t.Errorf("branch Link() = %q", got)
+	}
+}
+
+func TestPermalinker_OutsideRepository(t *testing.T) {
+	dir := t.TempDir()
+	if p := NewPermalinker("", dir); p != nil {
+		t.Error("expected nil permalinker for empty template")
+	}
+	if got := (*Permalinker)(nil).Link("a.go", 1, 2); got != "" {
+		t.Errorf("nil Link() = %q, want empty", got)
+	}
+
+	p := NewPermalinker("https://example.com/blob/{commit}/{path}", dir)
+	if got := p.Link("a.go", 1, 2); got != "" {
+		t.Errorf("expected no link without a commit, got %q", got)
+	}
+	p = NewPermalinker("https://example.com/src/{path}?line={start_line}", dir)
+	if got := p.Link("a.go", 3, 4); got != "https://example.com/src/a.go?line=3" {
+		t.Errorf("Link() = %q", got)
+	}
+}
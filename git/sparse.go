@@ -0,0 +1,160 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ignore "github.com/sabhiram/go-gitignore"
+)
+
+// SparseCheckout holds the sparse-checkout patterns of a worktree and
+// reports which paths of a project inside it are part of the checkout.
+type SparseCheckout struct {
+	prefix string // project directory relative to the worktree root, "" or ending in "/"
+
+	cone      bool
+	all       bool            // cone: everything is included
+	recursive map[string]bool // cone: directories included with all their contents
+	parents   map[string]bool // cone: directories whose direct files are included
+
+	patterns *ignore.GitIgnore // non-cone: gitignore-style patterns selecting included paths
+}
+
+// LoadSparseCheckout returns the sparse-checkout of the worktree containing
+// dir, with paths relative to dir. It returns nil when sparse checkout is
+// not enabled.
+func LoadSparseCheckout(dir string) (*SparseCheckout, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if gitOutput(ctx, dir, "config", "--bool", "core.sparseCheckout") != "true" {
+		return nil, nil
+	}
+	cone := gitOutput(ctx, dir, "config", "--bool", "core.sparseCheckoutCone") == "true"
+	prefix := gitOutput(ctx, dir, "rev-parse", "--show-prefix")
+
+	file := gitOutput(ctx, dir, "rev-parse", "--git-path", "info/sparse-checkout")
+	if file == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	sc := ParseSparseCheckout(string(data), cone)
+	sc.prefix = prefix
+	return sc, nil
+}
+
+func gitOutput(ctx context.Context, dir string, args ...string) string {
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// ParseSparseCheckout parses the content of an info/sparse-checkout file.
+// In cone mode, "/dir/" includes a directory recursively, unless followed by
+// "!/dir/*/", which limits it to its direct files; files at the root are
+// always included.
+func ParseSparseCheckout(data string, cone bool) *SparseCheckout {
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	if !cone {
+		return &SparseCheckout{patterns: ignore.CompileIgnoreLines(lines...)}
+	}
+
+	sc := &SparseCheckout{
+		cone:      true,
+		all:       true,
+		recursive: make(map[string]bool),
+		parents:   make(map[string]bool),
+	}
+	for _, line := range lines {
+		switch {
+		case line == "/*":
+		case line == "!/*/":
+			sc.all = false
+		case strings.HasPrefix(line, "!/") && strings.HasSuffix(line, "/*/"):
+			dir := strings.TrimSuffix(strings.TrimPrefix(line, "!/"), "/*/")
+			sc.parents[dir] = true
+		case strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/"):
+			sc.recursive[strings.Trim(line, "/")] = true
+		}
+	}
+	for dir := range sc.parents {
+		delete(sc.recursive, dir)
+	}
+	return sc
+}
+
+// IncludesFile reports whether the file at relPath, relative to the project
+// directory, is part of the sparse checkout.
+func (s *SparseCheckout) IncludesFile(relPath string) bool {
+	if s == nil {
+		return true
+	}
+	full := s.prefix + filepath.ToSlash(relPath)
+	if !s.cone {
+		return s.patterns.MatchesPath(full)
+	}
+	if s.all {
+		return true
+	}
+	dir := path.Dir(full)
+	return dir == "." || s.parents[dir] || s.underRecursive(dir)
+}
+
+// IncludesDir reports whether any file under the directory at relPath,
+// relative to the project directory, can be part of the sparse checkout.
+// Outside cone mode, patterns may select files at any depth, so every
+// directory may contain included files.
+func (s *SparseCheckout) IncludesDir(relPath string) bool {
+	if s == nil || !s.cone || s.all {
+		return true
+	}
+	dir := path.Clean(s.prefix + filepath.ToSlash(relPath))
+	if dir == "." || s.parents[dir] || s.underRecursive(dir) {
+		return true
+	}
+	for included := range s.recursive {
+		if strings.HasPrefix(included, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// underRecursive reports whether dir is, or is inside, a recursively
+// included directory.
+func (s *SparseCheckout) underRecursive(dir string) bool {
+	for {
+		if s.recursive[dir] {
+			return true
+		}
+		parent := path.Dir(dir)
+		if parent == dir || parent == "." || parent == "/" {
+			return false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,73 @@
+package git
+
+import "testing"
+
+func TestParseSparseCheckout_Cone(t *testing.T) {
+	sc := ParseSparseCheckout("/*\n!/*/\n/services/\n!/services/*/\n/services/api/\n/docs/\n", true)
+
+	files := map[string]bool{
+		"README.md":                 true,
+		"services/go.mod":           true,
+		"services/api/main.go":      true,
+		"services/api/handler/h.go": true,
+		"services/billing/main.go":  false,
+		"docs/guide/intro.md":       true,
+		"vendor/github.com/x/x.go":  false,
+	}
+	for path, want := range files {
+		if got := sc.IncludesFile(path); got != want {
+			t.Errorf("IncludesFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	dirs := map[string]bool{
+		".":                true,
+		"services":         true,
+		"services/api":     true,
+		"services/billing": false,
+		"vendor":           false,
+	}
+	for path, want := range dirs {
+		if got := sc.IncludesDir(path); got != want {
+			t.Errorf("IncludesDir(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestParseSparseCheckout_ConeWithPrefix(t *testing.T) {
+	sc := ParseSparseCheckout("/*\n!/*/\n/services/\n!/services/*/\n/services/api/\n", true)
+	sc.prefix = "services/"
+
+	if !sc.IncludesFile("go.mod") || !sc.IncludesFile("api/main.go") {
+		t.Error("expected files of the project and services/api to be included")
+	}
+	if sc.IncludesFile("billing/main.go") || sc.IncludesDir("billing") {
+		t.Error("expected services/billing to be excluded")
+	}
+}
+
+func TestParseSparseCheckout_NonCone(t *testing.T) {
+	sc := ParseSparseCheckout("# comment\n/src/\n*.md\n!/src/generated/\n", false)
+
+	files := map[string]bool{
+		"src/main.go":           true,
+		"docs/intro.md":         true,
+		"src/generated/pb.go":   false,
+		"internal/tool/tool.go": false,
+	}
+	for path, want := range files {
+		if got := sc.IncludesFile(path); got != want {
+			t.Errorf("IncludesFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if !sc.IncludesDir("internal") {
+		t.Error("expected every directory to be walked outside cone mode")
+	}
+}
+
+func TestSparseCheckout_Nil(t *testing.T) {
+	var sc *SparseCheckout
+	if !sc.IncludesFile("any/file.go") || !sc.IncludesDir("any") {
+		t.Error("expected a nil sparse checkout to include every path")
+	}
+}
@@ -0,0 +1,187 @@
+// Package global discovers the repositories indexed by the personal global
+// index and keeps lightweight metadata about them.
+package global
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Repo is the metadata kept for an indexed repository.
+type Repo struct {
+	Name        string    `json:"name"` // path prefix of the repository's files in the global index
+	Path        string    `json:"path"`
+	Remote      string    `json:"remote,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	Files       int       `json:"files"`
+	Chunks      int       `json:"chunks"`
+	LastIndexed time.Time `json:"last_indexed"`
+}
+
+// skipDirs are never searched for repositories.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	".cache":       true,
+	"Library":      true,
+}
+
+// Discover returns the git repositories found under roots, at most
+// maxDepth directory levels deep, sorted by path. Repositories are not
+// searched for nested repositories, and hidden directories are skipped.
+// Directories whose name matches one of the exclude glob patterns are
+// skipped too. Missing roots are ignored.
+func Discover(roots []string, maxDepth int, exclude []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var repos []string
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		rootDepth := strings.Count(filepath.Clean(root), string(filepath.Separator))
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == root {
+					return err
+				}
+				return fs.SkipDir
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || skipDirs[name] || excluded(name, exclude)) {
+				return fs.SkipDir
+			}
+			if isRepo(path) {
+				if !seen[path] {
+					seen[path] = true
+					repos = append(repos, path)
+				}
+				return fs.SkipDir
+			}
+			if strings.Count(path, string(filepath.Separator))-rootDepth >= maxDepth {
+				return fs.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+		}
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+func isRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+func excluded(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignNames returns a unique name for each repository path: its directory
+// name, prefixed with parent directories when several repositories share
+// it. Names already assigned in previous are kept, so index paths stay
+// stable as repositories come and go.
+func AssignNames(paths []string, previous []Repo) map[string]string {
+	names := make(map[string]string, len(paths))
+	used := make(map[string]bool, len(paths))
+	for _, r := range previous {
+		for _, p := range paths {
+			if p == r.Path && r.Name != "" && !used[r.Name] {
+				names[p] = r.Name
+				used[r.Name] = true
+			}
+		}
+	}
+	for _, p := range paths {
+		if _, ok := names[p]; ok {
+			continue
+		}
+		segments := strings.Split(filepath.ToSlash(filepath.Clean(p)), "/")
+		name := ""
+		for i := len(segments) - 1; i >= 0; i-- {
+			if segments[i] == "" {
+				continue
+			}
+			if name == "" {
+				name = segments[i]
+			} else {
+				name = segments[i] + "-" + name
+			}
+			if !used[name] {
+				break
+			}
+		}
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		names[p] = name
+		used[name] = true
+	}
+	return names
+}
+
+// ReadGitInfo returns the origin remote URL and current branch of a
+// repository. Values git cannot provide are left empty.
+func ReadGitInfo(dir string) (remote, branch string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "git", "-C", dir, "remote", "get-url", "origin").Output(); err == nil {
+		remote = strings.TrimSpace(string(out))
+	}
+	if out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output(); err == nil {
+		if b := strings.TrimSpace(string(out)); b != "HEAD" {
+			branch = b
+		}
+	}
+	return remote, branch
+}
+
+// LoadRepos reads the repository metadata file. A missing file yields no
+// repositories.
+func LoadRepos(path string) ([]Repo, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository metadata: %w", err)
+	}
+	var repos []Repo
+	if err := json.Unmarshal(data, &repos); err != nil {
+		return nil, fmt.Errorf("failed to parse repository metadata: %w", err)
+	}
+	return repos, nil
+}
+
+// SaveRepos writes the repository metadata file, sorted by name.
+func SaveRepos(path string, repos []Repo) error {
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Name < repos[j].Name })
+	data, err := json.MarshalIndent(repos, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create global index directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,68 @@
+package global
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func mkRepo(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	mkRepo(t, filepath.Join(root, "app"))
+	mkRepo(t, filepath.Join(root, "app", "nested"))
+	mkRepo(t, filepath.Join(root, "acme", "api"))
+	mkRepo(t, filepath.Join(root, "acme", "web", "deep", "too-deep"))
+	mkRepo(t, filepath.Join(root, "node_modules", "dep"))
+	mkRepo(t, filepath.Join(root, ".hidden", "repo"))
+	mkRepo(t, filepath.Join(root, "scratch-tmp"))
+
+	repos, err := Discover([]string{root, filepath.Join(root, "missing")}, 3, []string{"*-tmp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(root, "acme", "api"), filepath.Join(root, "app")}
+	if !reflect.DeepEqual(repos, want) {
+		t.Errorf("Discover = %v, want %v", repos, want)
+	}
+}
+
+func TestAssignNames(t *testing.T) {
+	paths := []string{"/src/acme/api", "/src/other/api", "/src/web", "/src/old/web"}
+	previous := []Repo{{Name: "web", Path: "/src/old/web"}}
+
+	got := AssignNames(paths, previous)
+	want := map[string]string{
+		"/src/acme/api":  "api",
+		"/src/other/api": "other-api",
+		"/src/web":       "src-web",
+		"/src/old/web":   "web",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AssignNames = %v, want %v", got, want)
+	}
+}
+
+func TestReposRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "global", "repos.json")
+	if repos, err := LoadRepos(path); err != nil || repos != nil {
+		t.Fatalf("expected no repositories, got %v %v", repos, err)
+	}
+	if err := SaveRepos(path, []Repo{{Name: "web", Path: "/src/web"}, {Name: "api", Path: "/src/api", Files: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	repos, err := LoadRepos(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 2 || repos[0].Name != "api" || repos[0].Files != 3 {
+		t.Errorf("unexpected repositories: %+v", repos)
+	}
+}
@@ -0,0 +1,181 @@
+package grpcapi
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoFile is the path of the API definition, relative to the repository
+// root. fileDescriptor must be kept in sync with it, which
+// TestFileDescriptor_MatchesProto checks.
+const protoFile = "api/grepai/v1/grepai.proto"
+
+const (
+	protoPackage = "grepai.v1"
+	serviceName  = protoPackage + ".GrepaiService"
+)
+
+// apiFile describes api/grepai/v1/grepai.proto. It is registered globally so
+// that gRPC server reflection can serve it.
+var apiFile = mustRegisterFile(fileDescriptor())
+
+// fileDescriptor mirrors api/grepai/v1/grepai.proto, so the server speaks
+// the protobuf wire format of clients generated from it without checking
+// generated code in.
+func fileDescriptor() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(protoFile),
+		Package: proto.String(protoPackage),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("github.com/yoanbernabeu/grepai/grpcapi"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("SearchRequest",
+				scalar("query", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("limit", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("path", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			),
+			message("SearchResult",
+				scalar("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("file_path", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("start_line", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("end_line", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("score", 5, descriptorpb.FieldDescriptorProto_TYPE_FLOAT),
+				scalar("content", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("symbol_name", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("symbol_kind", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("feature_path", 9, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			),
+			message("SearchResponse",
+				repeated(messageField("results", 1, "SearchResult")),
+			),
+			message("TraceRequest",
+				scalar("symbol", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("limit", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("offset", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			),
+			message("Symbol",
+				scalar("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("kind", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("file", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("line", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("end_line", 5, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("signature", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("receiver", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("package", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("language", 9, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			),
+			message("CallSite",
+				scalar("file", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("line", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("context", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			),
+			message("Reference",
+				messageField("symbol", 1, "Symbol"),
+				messageField("call_site", 2, "CallSite"),
+			),
+			message("TraceResponse",
+				messageField("symbol", 1, "Symbol"),
+				repeated(messageField("references", 2, "Reference")),
+				scalar("total", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			),
+			message("IndexStatusRequest"),
+			message("IndexStatusResponse",
+				scalar("total_files", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("total_chunks", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				scalar("index_size", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("last_updated", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("provider", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("model", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("symbols_ready", 7, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+			),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("GrepaiService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Search", "SearchRequest", "SearchResponse"),
+				method("TraceCallers", "TraceRequest", "TraceResponse"),
+				method("TraceCallees", "TraceRequest", "TraceResponse"),
+				method("IndexStatus", "IndexStatusRequest", "IndexStatusResponse"),
+			},
+		}},
+	}
+}
+
+func message(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+}
+
+func scalar(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+}
+
+func messageField(name string, number int32, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := scalar(name, number, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	field.TypeName = proto.String("." + protoPackage + "." + typeName)
+	return field
+}
+
+func repeated(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return field
+}
+
+func method(name, input, output string) *descriptorpb.MethodDescriptorProto {
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String("." + protoPackage + "." + input),
+		OutputType: proto.String("." + protoPackage + "." + output),
+	}
+}
+
+func mustRegisterFile(fd *descriptorpb.FileDescriptorProto) protoreflect.FileDescriptor {
+	file, err := protodesc.NewFile(fd, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(fmt.Sprintf("grpcapi: invalid descriptor for %s: %v", protoFile, err))
+	}
+	if err := protoregistry.GlobalFiles.RegisterFile(file); err != nil {
+		panic(fmt.Sprintf("grpcapi: failed to register %s: %v", protoFile, err))
+	}
+	return file
+}
+
+// newMessage returns an empty message of the API's type name.
+func newMessage(name protoreflect.Name) *dynamicpb.Message {
+	return dynamicpb.NewMessage(apiFile.Messages().ByName(name))
+}
+
+// set sets the field name of m, leaving zero values unset as proto3 does.
+func set(m *dynamicpb.Message, name protoreflect.Name, value protoreflect.Value) {
+	field := m.Descriptor().Fields().ByName(name)
+	if field == nil {
+		panic(fmt.Sprintf("grpcapi: %s has no field %s", m.Descriptor().FullName(), name))
+	}
+	if value.Equal(field.Default()) {
+		return
+	}
+	m.Set(field, value)
+}
+
+// get returns the field name of m, or its zero value when unset.
+func get(m *dynamicpb.Message, name protoreflect.Name) protoreflect.Value {
+	return m.Get(m.Descriptor().Fields().ByName(name))
+}
+
+// appendMessage appends item to the repeated message field name of m.
+func appendMessage(m *dynamicpb.Message, name protoreflect.Name, item *dynamicpb.Message) {
+	list := m.Mutable(m.Descriptor().Fields().ByName(name)).List()
+	list.Append(protoreflect.ValueOfMessage(item))
+}
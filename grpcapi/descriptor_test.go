@@ -0,0 +1,89 @@
+package grpcapi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	protoComment = regexp.MustCompile(`//[^\n]*`)
+	protoPkg     = regexp.MustCompile(`(?m)^package\s+([\w.]+)\s*;`)
+	protoMessage = regexp.MustCompile(`message\s+(\w+)\s*\{([^}]*)\}`)
+	protoField   = regexp.MustCompile(`(repeated\s+)?(\w+)\s+(\w+)\s*=\s*(\d+)\s*;`)
+	protoRPC     = regexp.MustCompile(`rpc\s+(\w+)\s*\(\s*(\w+)\s*\)\s*returns\s*\(\s*(\w+)\s*\)`)
+)
+
+var protoScalars = map[descriptorpb.FieldDescriptorProto_Type]string{
+	descriptorpb.FieldDescriptorProto_TYPE_STRING: "string",
+	descriptorpb.FieldDescriptorProto_TYPE_INT32:  "int32",
+	descriptorpb.FieldDescriptorProto_TYPE_FLOAT:  "float",
+	descriptorpb.FieldDescriptorProto_TYPE_BOOL:   "bool",
+}
+
+// TestFileDescriptor_MatchesProto keeps the hand-written descriptor in sync
+// with the proto file clients are generated from: every message, field and
+// RPC must match, in the proto's spelling.
+func TestFileDescriptor_MatchesProto(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", filepath.FromSlash(protoFile)))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", protoFile, err)
+	}
+	src := protoComment.ReplaceAllString(string(data), "")
+
+	if m := protoPkg.FindStringSubmatch(src); m == nil || m[1] != protoPackage {
+		t.Errorf("proto package = %v, want %s", m, protoPackage)
+	}
+
+	var want []string
+	for _, m := range protoMessage.FindAllStringSubmatch(src, -1) {
+		want = append(want, "message "+m[1])
+		for _, f := range protoField.FindAllStringSubmatch(m[2], -1) {
+			want = append(want, fmt.Sprintf("%s.%s%s %s = %s", m[1], f[1], f[2], f[3], f[4]))
+		}
+	}
+	for _, m := range protoRPC.FindAllStringSubmatch(src, -1) {
+		want = append(want, fmt.Sprintf("rpc %s(%s) returns (%s)", m[1], m[2], m[3]))
+	}
+
+	fd := fileDescriptor()
+	var got []string
+	for _, msg := range fd.GetMessageType() {
+		got = append(got, "message "+msg.GetName())
+		for _, f := range msg.GetField() {
+			typ := strings.TrimPrefix(f.GetTypeName(), "."+protoPackage+".")
+			if typ == "" {
+				typ = protoScalars[f.GetType()]
+			}
+			repeated := ""
+			if f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+				repeated = "repeated "
+			}
+			got = append(got, fmt.Sprintf("%s.%s%s %s = %d", msg.GetName(), repeated, typ, f.GetName(), f.GetNumber()))
+		}
+	}
+	for _, svc := range fd.GetService() {
+		for _, m := range svc.GetMethod() {
+			got = append(got, fmt.Sprintf("rpc %s(%s) returns (%s)", m.GetName(),
+				strings.TrimPrefix(m.GetInputType(), "."+protoPackage+"."),
+				strings.TrimPrefix(m.GetOutputType(), "."+protoPackage+".")))
+		}
+	}
+
+	for _, line := range want {
+		if !slices.Contains(got, line) {
+			t.Errorf("descriptor lacks %q from %s", line, protoFile)
+		}
+	}
+	for _, line := range got {
+		if !slices.Contains(want, line) {
+			t.Errorf("descriptor has %q, which %s lacks", line, protoFile)
+		}
+	}
+}
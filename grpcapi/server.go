@@ -0,0 +1,272 @@
+// Package grpcapi serves the grepai searcher, symbol index and index status
+// as the versioned gRPC service defined in api/grepai/v1/grepai.proto, for
+// tools that integrate with grepai programmatically rather than through the
+// CLI or MCP.
+//
+// Like the HTTP remote API, calls are answered by the MCP tools of the
+// served project, must carry the shared token as "authorization: Bearer
+// <token>" metadata and are held to mcp.rate_limit per token.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+
+	"github.com/yoanbernabeu/grepai/mcp"
+	"github.com/yoanbernabeu/grepai/remote"
+	"github.com/yoanbernabeu/grepai/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grepaiServiceServer is the handler type of the service description.
+type grepaiServiceServer interface {
+	search(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error)
+	traceCallers(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error)
+	traceCallees(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error)
+	indexStatus(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*grepaiServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("Search", "SearchRequest", grepaiServiceServer.search),
+		unaryMethod("TraceCallers", "TraceRequest", grepaiServiceServer.traceCallers),
+		unaryMethod("TraceCallees", "TraceRequest", grepaiServiceServer.traceCallees),
+		unaryMethod("IndexStatus", "IndexStatusRequest", grepaiServiceServer.indexStatus),
+	},
+	Metadata: protoFile,
+}
+
+// unaryMethod describes the RPC name, decoding its request as the API
+// message input.
+func unaryMethod(name string, input protoreflect.Name, call func(grepaiServiceServer, context.Context, *dynamicpb.Message) (*dynamicpb.Message, error)) grpc.MethodDesc {
+	fullMethod := "/" + serviceName + "/" + name
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := newMessage(input)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				return call(srv.(grepaiServiceServer), ctx, req.(*dynamicpb.Message))
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+		},
+	}
+}
+
+// service answers RPCs with the MCP tools of the served project.
+type service struct {
+	tools remote.ToolCaller
+}
+
+// NewServer returns a gRPC server answering the grepai service with tools,
+// for clients presenting one of the comma-separated tokens, within the
+// limits of limiter per token.
+func NewServer(tools remote.ToolCaller, tokens string, limiter *remote.Limiter) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(tokens, limiter)))
+	srv.RegisterService(&serviceDesc, &service{tools: tools})
+	reflection.Register(srv)
+	return srv
+}
+
+// authInterceptor rejects calls not carrying one of tokens as a bearer
+// token, and those over the token's limits with ResourceExhausted and a
+// retry-after header, as the HTTP API answers 429. Reflection is a
+// streaming service and stays open, so clients can discover the API before
+// configuring the token.
+func authInterceptor(tokens string, limiter *remote.Limiter) grpc.UnaryServerInterceptor {
+	accepted := remote.Tokens(tokens)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, header := range md.Get("authorization") {
+			token, ok := remote.MatchToken(header, accepted)
+			if !ok {
+				continue
+			}
+			release, retryAfter, ok := limiter.Acquire(token)
+			if !ok {
+				seconds := remote.RetryAfterSeconds(retryAfter)
+				_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+				return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded; retry in %ds", seconds)
+			}
+			defer release()
+			return handler(ctx, req)
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "invalid or missing token; check %s", remote.TokenEnv)
+	}
+}
+
+// callTool runs tool and decodes its JSON output into out, mapping tool
+// errors to gRPC statuses.
+func (s *service) callTool(ctx context.Context, tool string, args map[string]any, out any) error {
+	args["format"] = "json"
+	args["compact"] = false
+	output, isError, err := s.tools.CallTool(ctx, tool, args)
+	switch {
+	case err != nil:
+		return status.Error(codes.Internal, err.Error())
+	case isError:
+		return status.Error(codes.FailedPrecondition, output)
+	}
+	if err := json.Unmarshal([]byte(output), out); err != nil {
+		return status.Errorf(codes.Internal, "invalid %s output: %v", tool, err)
+	}
+	return nil
+}
+
+func (s *service) search(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+	query := get(req, "query").String()
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	args := map[string]any{"query": query}
+	if limit := get(req, "limit").Int(); limit > 0 {
+		args["limit"] = int(limit)
+	}
+	if path := get(req, "path").String(); path != "" {
+		args["path"] = path
+	}
+
+	var results []mcp.SearchResult
+	if err := s.callTool(ctx, "grepai_search", args, &results); err != nil {
+		return nil, err
+	}
+
+	resp := newMessage("SearchResponse")
+	for _, r := range results {
+		item := newMessage("SearchResult")
+		set(item, "id", protoreflect.ValueOfString(r.ID))
+		set(item, "file_path", protoreflect.ValueOfString(r.FilePath))
+		set(item, "start_line", protoreflect.ValueOfInt32(int32(r.StartLine)))
+		set(item, "end_line", protoreflect.ValueOfInt32(int32(r.EndLine)))
+		set(item, "score", protoreflect.ValueOfFloat32(r.Score))
+		set(item, "content", protoreflect.ValueOfString(r.Content))
+		set(item, "symbol_name", protoreflect.ValueOfString(r.SymbolName))
+		set(item, "symbol_kind", protoreflect.ValueOfString(r.SymbolKind))
+		set(item, "feature_path", protoreflect.ValueOfString(r.FeaturePath))
+		appendMessage(resp, "results", item)
+	}
+	return resp, nil
+}
+
+func (s *service) traceCallers(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+	return s.trace(ctx, "grepai_trace_callers", req)
+}
+
+func (s *service) traceCallees(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+	return s.trace(ctx, "grepai_trace_callees", req)
+}
+
+func (s *service) trace(ctx context.Context, tool string, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+	symbol := get(req, "symbol").String()
+	if symbol == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+	args := map[string]any{"symbol": symbol}
+	if limit := get(req, "limit").Int(); limit > 0 {
+		args["limit"] = int(limit)
+	}
+	if offset := get(req, "offset").Int(); offset > 0 {
+		args["offset"] = int(offset)
+	}
+
+	var result trace.TraceResult
+	if err := s.callTool(ctx, tool, args, &result); err != nil {
+		return nil, err
+	}
+
+	resp := newMessage("TraceResponse")
+	if result.Symbol != nil {
+		resp.Set(resp.Descriptor().Fields().ByName("symbol"), protoreflect.ValueOfMessage(symbolMessage(*result.Symbol)))
+	}
+	for _, c := range result.Callers {
+		appendMessage(resp, "references", referenceMessage(c.Symbol, c.CallSite))
+	}
+	for _, c := range result.Callees {
+		appendMessage(resp, "references", referenceMessage(c.Symbol, c.CallSite))
+	}
+	total := result.Total
+	if total == 0 {
+		total = len(result.Callers) + len(result.Callees)
+	}
+	set(resp, "total", protoreflect.ValueOfInt32(int32(total)))
+	return resp, nil
+}
+
+func symbolMessage(sym trace.Symbol) *dynamicpb.Message {
+	m := newMessage("Symbol")
+	set(m, "name", protoreflect.ValueOfString(sym.Name))
+	set(m, "kind", protoreflect.ValueOfString(string(sym.Kind)))
+	set(m, "file", protoreflect.ValueOfString(sym.File))
+	set(m, "line", protoreflect.ValueOfInt32(int32(sym.Line)))
+	set(m, "end_line", protoreflect.ValueOfInt32(int32(sym.EndLine)))
+	set(m, "signature", protoreflect.ValueOfString(sym.Signature))
+	set(m, "receiver", protoreflect.ValueOfString(sym.Receiver))
+	set(m, "package", protoreflect.ValueOfString(sym.Package))
+	set(m, "language", protoreflect.ValueOfString(sym.Language))
+	return m
+}
+
+func referenceMessage(sym trace.Symbol, site trace.CallSite) *dynamicpb.Message {
+	callSite := newMessage("CallSite")
+	set(callSite, "file", protoreflect.ValueOfString(site.File))
+	set(callSite, "line", protoreflect.ValueOfInt32(int32(site.Line)))
+	set(callSite, "context", protoreflect.ValueOfString(site.Context))
+
+	m := newMessage("Reference")
+	m.Set(m.Descriptor().Fields().ByName("symbol"), protoreflect.ValueOfMessage(symbolMessage(sym)))
+	m.Set(m.Descriptor().Fields().ByName("call_site"), protoreflect.ValueOfMessage(callSite))
+	return m
+}
+
+func (s *service) indexStatus(ctx context.Context, _ *dynamicpb.Message) (*dynamicpb.Message, error) {
+	var st mcp.IndexStatus
+	if err := s.callTool(ctx, "grepai_index_status", map[string]any{}, &st); err != nil {
+		return nil, err
+	}
+
+	resp := newMessage("IndexStatusResponse")
+	set(resp, "total_files", protoreflect.ValueOfInt32(int32(st.TotalFiles)))
+	set(resp, "total_chunks", protoreflect.ValueOfInt32(int32(st.TotalChunks)))
+	set(resp, "index_size", protoreflect.ValueOfString(st.IndexSize))
+	set(resp, "last_updated", protoreflect.ValueOfString(st.LastUpdated))
+	set(resp, "provider", protoreflect.ValueOfString(st.Provider))
+	set(resp, "model", protoreflect.ValueOfString(st.Model))
+	set(resp, "symbols_ready", protoreflect.ValueOfBool(st.SymbolsReady))
+	return resp, nil
+}
+
+// ListenAndServe serves srv on addr until ctx is done, then stops
+// gracefully.
+func ListenAndServe(ctx context.Context, addr string, srv *grpc.Server) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+	log.Printf("Listening for gRPC clients on %s", listener.Addr())
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/remote"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type fakeTools struct {
+	calls []string
+	args  []map[string]any
+}
+
+func (f *fakeTools) CallTool(ctx context.Context, name string, args map[string]any) (string, bool, error) {
+	f.calls = append(f.calls, name)
+	f.args = append(f.args, args)
+	switch name {
+	case "grepai_search":
+		return `[{"id":"c1","file_path":"auth/login.go","start_line":3,"end_line":9,"score":0.5,"content":"func Login()","symbol_name":"Login"}]`, false, nil
+	case "grepai_trace_callers":
+		return `{"symbol":{"name":"Login","kind":"function","file":"auth/login.go","line":3,"language":"go"},` +
+			`"callers":[{"symbol":{"name":"Handle","kind":"function","file":"api/handler.go","line":12,"language":"go"},"call_site":{"file":"api/handler.go","line":14,"context":"Login()"}}],"total":4}`, false, nil
+	case "grepai_index_status":
+		return `{"total_files":2,"total_chunks":5,"provider":"ollama","symbols_ready":true}`, false, nil
+	}
+	return "unknown tool", true, nil
+}
+
+func dial(t *testing.T, tools *fakeTools, limits remote.Limits) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := NewServer(tools, "secret", remote.NewLimiter(limits))
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestServer_Search(t *testing.T) {
+	tools := &fakeTools{}
+	conn := dial(t, tools, remote.Limits{})
+
+	req := newMessage("SearchRequest")
+	set(req, "query", protoreflect.ValueOfString("login"))
+	set(req, "limit", protoreflect.ValueOfInt32(3))
+	resp := newMessage("SearchResponse")
+	if err := conn.Invoke(withToken("secret"), "/"+serviceName+"/Search", req, resp); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	results := get(resp, "results").List()
+	if results.Len() != 1 {
+		t.Fatalf("expected 1 result, got %d", results.Len())
+	}
+	result := results.Get(0).Message()
+	if got := result.Get(result.Descriptor().Fields().ByName("file_path")).String(); got != "auth/login.go" {
+		t.Errorf("file_path = %q, want auth/login.go", got)
+	}
+	if got := result.Get(result.Descriptor().Fields().ByName("start_line")).Int(); got != 3 {
+		t.Errorf("start_line = %d, want 3", got)
+	}
+	if tools.args[0]["limit"] != 3 {
+		t.Errorf("expected limit 3 to reach the tool, got %v", tools.args[0]["limit"])
+	}
+}
+
+func TestServer_TraceCallers(t *testing.T) {
+	conn := dial(t, &fakeTools{}, remote.Limits{})
+
+	req := newMessage("TraceRequest")
+	set(req, "symbol", protoreflect.ValueOfString("Login"))
+	resp := newMessage("TraceResponse")
+	if err := conn.Invoke(withToken("secret"), "/"+serviceName+"/TraceCallers", req, resp); err != nil {
+		t.Fatalf("TraceCallers failed: %v", err)
+	}
+
+	if got := get(resp, "total").Int(); got != 4 {
+		t.Errorf("total = %d, want 4", got)
+	}
+	refs := get(resp, "references").List()
+	if refs.Len() != 1 {
+		t.Fatalf("expected 1 reference, got %d", refs.Len())
+	}
+	ref := refs.Get(0).Message()
+	site := ref.Get(ref.Descriptor().Fields().ByName("call_site")).Message()
+	if got := site.Get(site.Descriptor().Fields().ByName("line")).Int(); got != 14 {
+		t.Errorf("call site line = %d, want 14", got)
+	}
+}
+
+func TestServer_IndexStatus(t *testing.T) {
+	conn := dial(t, &fakeTools{}, remote.Limits{})
+
+	resp := newMessage("IndexStatusResponse")
+	if err := conn.Invoke(withToken("secret"), "/"+serviceName+"/IndexStatus", newMessage("IndexStatusRequest"), resp); err != nil {
+		t.Fatalf("IndexStatus failed: %v", err)
+	}
+	if got := get(resp, "total_chunks").Int(); got != 5 {
+		t.Errorf("total_chunks = %d, want 5", got)
+	}
+	if !get(resp, "symbols_ready").Bool() {
+		t.Error("expected symbols_ready")
+	}
+}
+
+func TestServer_RejectsWrongToken(t *testing.T) {
+	tools := &fakeTools{}
+	conn := dial(t, tools, remote.Limits{})
+
+	req := newMessage("SearchRequest")
+	set(req, "query", protoreflect.ValueOfString("login"))
+	err := conn.Invoke(withToken("guess"), "/"+serviceName+"/Search", req, newMessage("SearchResponse"))
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+	if len(tools.calls) != 0 {
+		t.Errorf("expected no tool call without a valid token, got %v", tools.calls)
+	}
+}
+
+func TestServer_RateLimitsPerToken(t *testing.T) {
+	tools := &fakeTools{}
+	conn := dial(t, tools, remote.Limits{RequestsPerMinute: 1, Burst: 1})
+
+	invoke := func() (metadata.MD, error) {
+		var header metadata.MD
+		err := conn.Invoke(withToken("secret"), "/"+serviceName+"/IndexStatus", newMessage("IndexStatusRequest"), newMessage("IndexStatusResponse"), grpc.Header(&header))
+		return header, err
+	}
+	if _, err := invoke(); err != nil {
+		t.Fatalf("expected the first call to be admitted, got %v", err)
+	}
+	header, err := invoke()
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if got := header.Get("retry-after"); len(got) != 1 || got[0] == "" {
+		t.Errorf("expected a retry-after header, got %v", got)
+	}
+	if len(tools.calls) != 1 {
+		t.Errorf("expected the limited call not to reach the tools, got %v", tools.calls)
+	}
+}
@@ -0,0 +1,277 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Budget limits the number of chunks a full index pass keeps. Files that do
+// not fit are left out, lowest priority first.
+type Budget struct {
+	// MaxChunks caps the total number of chunks. Zero means no limit.
+	MaxChunks int
+	// DirCaps caps the chunks under directories, keyed by slash-separated
+	// paths relative to the project root.
+	DirCaps map[string]int
+	// Priority ranks paths: higher values are kept first. Nil ranks every
+	// path the same, leaving ties in path order.
+	Priority func(path string) float32
+}
+
+func (b Budget) enabled() bool {
+	return b.MaxChunks > 0 || len(b.DirCaps) > 0
+}
+
+// ExcludedFile is a file a full index pass left out to stay within the budget.
+type ExcludedFile struct {
+	Path   string
+	Chunks int
+	Reason string
+}
+
+// countedFile holds the chunks ApplyBudget made to count those of a changed
+// file, so that the pass indexing it does not chunk it again.
+type countedFile struct {
+	hash    string
+	chunks  []ChunkInfo
+	lineMap []int
+}
+
+// budgetUsage is the number of chunks of the files the last full pass
+// kept, kept up to date as files are indexed and removed until the next.
+type budgetUsage struct {
+	total int
+	dirs  map[string]int
+	files map[string]int // nil until a full pass counted the index
+}
+
+// SetBudget limits the chunks kept by full index passes. Files indexed
+// between passes, such as those the watcher reports, are indexed while
+// they fit in the budget and left out until the next pass otherwise.
+func (idx *Indexer) SetBudget(budget Budget) {
+	idx.budget = budget
+}
+
+// ApplyBudget returns the files of a full scan that fit in the budget, in
+// their scan order, and the files left out. Excluded files are removed from
+// the index, and IndexFile skips them until the next pass. Chunk counts come
+// from the index for unchanged files and from chunking the others.
+func (idx *Indexer) ApplyBudget(ctx context.Context, files []FileMeta) ([]FileMeta, []ExcludedFile, error) {
+	if !idx.budget.enabled() {
+		return files, nil, nil
+	}
+
+	type candidate struct {
+		index    int
+		chunks   int
+		priority float32
+		indexed  bool
+		counted  *countedFile
+	}
+	candidates := make([]candidate, len(files))
+	for i, f := range files {
+		chunks, indexed, counted, err := idx.countChunks(ctx, f)
+		if err != nil {
+			return nil, nil, err
+		}
+		priority := float32(0)
+		if idx.budget.Priority != nil {
+			priority = idx.budget.Priority(f.Path)
+		}
+		candidates[i] = candidate{index: i, chunks: chunks, priority: priority, indexed: indexed, counted: counted}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority > candidates[j].priority
+		}
+		return files[candidates[i].index].Path < files[candidates[j].index].Path
+	})
+
+	// Once a limit is reached, every lower-priority file under it is left
+	// out, even one small enough to fit, so exclusions follow priority.
+	total := 0
+	totalFull := false
+	dirChunks := make(map[string]int)
+	dirFull := make(map[string]bool)
+	keep := make([]bool, len(files))
+	fileChunks := make(map[string]int, len(files))
+	counted := make(map[string]countedFile)
+	var excluded []ExcludedFile
+	for _, c := range candidates {
+		path := files[c.index].Path
+		reason := ""
+		dirs := idx.budgetDirs(path)
+		for _, dir := range dirs {
+			if dirFull[dir] || dirChunks[dir]+c.chunks > idx.budget.DirCaps[dir] {
+				dirFull[dir] = true
+				reason = fmt.Sprintf("%s/ cap of %d chunks", dir, idx.budget.DirCaps[dir])
+				break
+			}
+		}
+		if reason == "" && idx.budget.MaxChunks > 0 && (totalFull || total+c.chunks > idx.budget.MaxChunks) {
+			totalFull = true
+			reason = fmt.Sprintf("total cap of %d chunks", idx.budget.MaxChunks)
+		}
+		if reason != "" {
+			excluded = append(excluded, ExcludedFile{Path: path, Chunks: c.chunks, Reason: reason})
+			if c.indexed {
+				if err := idx.RemoveFile(ctx, path); err != nil {
+					log.Printf("Failed to remove %s: %v", path, err)
+				}
+			}
+			continue
+		}
+		keep[c.index] = true
+		total += c.chunks
+		for _, dir := range dirs {
+			dirChunks[dir] += c.chunks
+		}
+		fileChunks[path] = c.chunks
+		if c.counted != nil {
+			counted[path] = *c.counted
+		}
+	}
+
+	kept := make([]FileMeta, 0, len(files)-len(excluded))
+	for i, f := range files {
+		if keep[i] {
+			kept = append(kept, f)
+		}
+	}
+	sort.Slice(excluded, func(i, j int) bool { return excluded[i].Path < excluded[j].Path })
+
+	excludedPaths := make(map[string]bool, len(excluded))
+	for _, e := range excluded {
+		excludedPaths[e.Path] = true
+	}
+	idx.mu.Lock()
+	idx.excluded = excludedPaths
+	idx.counted = counted
+	idx.usage = budgetUsage{total: total, dirs: dirChunks, files: fileChunks}
+	idx.mu.Unlock()
+
+	return kept, excluded, nil
+}
+
+// budgetDirs returns the capped directories that contain path.
+func (idx *Indexer) budgetDirs(path string) []string {
+	var dirs []string
+	for dir := range idx.budget.DirCaps {
+		if strings.HasPrefix(path, dir+"/") {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// countChunks returns how many chunks file has, or would have once indexed,
+// whether it is already in the index and, for a changed file, the chunks
+// made to count them.
+func (idx *Indexer) countChunks(ctx context.Context, meta FileMeta) (int, bool, *countedFile, error) {
+	doc, err := idx.store.GetDocument(ctx, meta.Path)
+	if err != nil {
+		return 0, false, nil, fmt.Errorf("failed to get document %s: %w", meta.Path, err)
+	}
+	indexed := doc != nil
+	if idx.unchangedByModTime(doc, meta) {
+		return len(doc.ChunkIDs), true, nil, nil
+	}
+
+	file, err := idx.scanner.ScanFile(meta.Path)
+	if err != nil || file == nil {
+		// Unreadable files are skipped by the pass itself.
+		return 0, indexed, nil, nil
+	}
+	if indexed && doc.Hash == file.Hash && len(doc.ChunkIDs) > 0 {
+		return len(doc.ChunkIDs), true, nil, nil
+	}
+	// Contextualizing only adds text to chunks, so the count is taken
+	// without summarizing the file.
+	chunks, lineMap := idx.chunkContent(ctx, *file)
+	return len(chunks), indexed, &countedFile{hash: file.Hash, chunks: chunks, lineMap: lineMap}, nil
+}
+
+// takeCounted returns the chunks ApplyBudget made for file, if its content
+// is unchanged since, and forgets them.
+func (idx *Indexer) takeCounted(file FileInfo) ([]ChunkInfo, []int, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	counted, ok := idx.counted[file.Path]
+	if !ok {
+		return nil, nil, false
+	}
+	delete(idx.counted, file.Path)
+	return counted.chunks, counted.lineMap, counted.hash == file.Hash
+}
+
+// admitChunks records that path now has n chunks and returns "" if they fit
+// in the budget. Otherwise path is left out until the next full pass, and
+// the cap it would exceed is returned. Everything fits until a full pass
+// has counted the index.
+func (idx *Indexer) admitChunks(path string, n int) string {
+	if !idx.budget.enabled() {
+		return ""
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.usage.files == nil {
+		return ""
+	}
+
+	delta := n - idx.usage.files[path]
+	dirs := idx.budgetDirs(path)
+	if delta > 0 {
+		reason := ""
+		for _, dir := range dirs {
+			if idx.usage.dirs[dir]+delta > idx.budget.DirCaps[dir] {
+				reason = fmt.Sprintf("%s/ cap of %d chunks", dir, idx.budget.DirCaps[dir])
+				break
+			}
+		}
+		if reason == "" && idx.budget.MaxChunks > 0 && idx.usage.total+delta > idx.budget.MaxChunks {
+			reason = fmt.Sprintf("total cap of %d chunks", idx.budget.MaxChunks)
+		}
+		if reason != "" {
+			if idx.excluded == nil {
+				idx.excluded = make(map[string]bool)
+			}
+			idx.excluded[path] = true
+			return reason
+		}
+	}
+
+	idx.usage.files[path] = n
+	idx.usage.total += delta
+	for _, dir := range dirs {
+		idx.usage.dirs[dir] += delta
+	}
+	return ""
+}
+
+// releaseChunks removes the chunks of removed files from the budget usage.
+func (idx *Indexer) releaseChunks(paths ...string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, path := range paths {
+		n, ok := idx.usage.files[path]
+		if !ok {
+			continue
+		}
+		delete(idx.usage.files, path)
+		idx.usage.total -= n
+		for _, dir := range idx.budgetDirs(path) {
+			idx.usage.dirs[dir] -= n
+		}
+	}
+}
+
+// budgetExcluded reports whether the last full pass left path out.
+func (idx *Indexer) budgetExcluded(path string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.excluded[path]
+}
@@ -0,0 +1,145 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestIndexAll_BudgetExcludesLowestPriority(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "z.go", "vendor/x.go", "vendor/y.go"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package main\n\nfunc F() {}\n"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	mockStore := newMockStore()
+	mockStore.documents["vendor/y.go"] = store.Document{Path: "vendor/y.go", Hash: "old", ChunkIDs: []string{"vendor/y.go_0"}}
+	mockStore.chunks["vendor/y.go_0"] = store.Chunk{ID: "vendor/y.go_0", FilePath: "vendor/y.go"}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	idx := NewIndexer(tmpDir, mockStore, newMockEmbedder(), NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Time{})
+	idx.SetBudget(Budget{
+		MaxChunks: 4,
+		DirCaps:   map[string]int{"vendor": 1},
+		Priority: func(path string) float32 {
+			if strings.HasPrefix(path, "vendor/") {
+				return 0.5
+			}
+			return 1
+		},
+	})
+
+	stats, err := idx.IndexAll(context.Background())
+	if err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if len(stats.Excluded) != 1 {
+		t.Fatalf("expected 1 excluded file, got %+v", stats.Excluded)
+	}
+	if got := stats.Excluded[0]; got.Path != "vendor/y.go" || got.Chunks != 1 || got.Reason != "vendor/ cap of 1 chunks" {
+		t.Errorf("unexpected exclusion: %+v", got)
+	}
+	if stats.FilesIndexed != 4 {
+		t.Errorf("expected 4 files indexed, got %d", stats.FilesIndexed)
+	}
+	if _, ok := mockStore.documents["vendor/y.go"]; ok {
+		t.Error("the excluded file should be removed from the index")
+	}
+
+	// Watcher updates skip excluded files until the next full pass.
+	chunks, err := idx.IndexFile(context.Background(), FileInfo{Path: "vendor/y.go", Content: "package main\n"})
+	if err != nil || chunks != 0 {
+		t.Errorf("expected excluded file to be skipped, got %d chunks (err %v)", chunks, err)
+	}
+
+	// New files are indexed between passes only while they fit.
+	newFile := FileInfo{Path: "c.go", Hash: "c", Content: "package main\n\nfunc C() {}\n"}
+	if chunks, err := idx.IndexFile(context.Background(), newFile); err != nil || chunks != 0 {
+		t.Errorf("expected a file over the total cap to be left out, got %d chunks (err %v)", chunks, err)
+	}
+	if err := idx.RemoveFile(context.Background(), "a.go"); err != nil {
+		t.Fatalf("RemoveFile failed: %v", err)
+	}
+	newFile.Path = "d.go"
+	if chunks, err := idx.IndexFile(context.Background(), newFile); err != nil || chunks != 1 {
+		t.Errorf("expected a file to fit once another was removed, got %d chunks (err %v)", chunks, err)
+	}
+
+	// Over the total cap, the lowest-priority files go first.
+	idx.SetBudget(Budget{MaxChunks: 2})
+	stats, err = idx.IndexAll(context.Background())
+	if err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	var excluded []string
+	for _, e := range stats.Excluded {
+		excluded = append(excluded, e.Path)
+	}
+	if strings.Join(excluded, ",") != "vendor/x.go,vendor/y.go,z.go" {
+		t.Errorf("expected path order to break ties, got %v", excluded)
+	}
+}
+
+func TestCountChunks_SkipsContextualizer(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "a.go"), []byte("package main\n\nfunc F() {}\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	idx := NewIndexer(tmpDir, newMockStore(), newMockEmbedder(), NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Time{})
+	summarizer := &mockSummarizer{}
+	idx.SetContextualizer(NewContextualizer(summarizer, ""))
+
+	chunks, indexed, counted, err := idx.countChunks(context.Background(), FileMeta{Path: "a.go"})
+	if err != nil {
+		t.Fatalf("countChunks failed: %v", err)
+	}
+	if chunks != 1 || indexed || counted == nil {
+		t.Fatalf("expected 1 chunk of an unindexed file, got %d (indexed %v, counted %v)", chunks, indexed, counted)
+	}
+	if summarizer.calls != 0 {
+		t.Errorf("expected counting not to summarize the file, got %d calls", summarizer.calls)
+	}
+
+	// The pass reuses the chunks made to count them.
+	idx.counted = map[string]countedFile{"a.go": *counted}
+	file, err := idx.scanner.ScanFile("a.go")
+	if err != nil {
+		t.Fatalf("ScanFile failed: %v", err)
+	}
+	if reused, _ := idx.chunkFile(context.Background(), *file); len(reused) != 1 || &reused[0] != &counted.chunks[0] {
+		t.Error("expected the counted chunks to be reused")
+	}
+	if len(idx.counted) != 0 {
+		t.Error("expected the counted chunks to be forgotten once used")
+	}
+}
+
+func TestApplyBudget_Disabled(t *testing.T) {
+	idx := NewIndexer(t.TempDir(), newMockStore(), newMockEmbedder(), NewChunker(512, 50), nil, time.Time{})
+	files := []FileMeta{{Path: "a.go"}, {Path: "b.go"}}
+	kept, excluded, err := idx.ApplyBudget(context.Background(), files)
+	if err != nil {
+		t.Fatalf("ApplyBudget failed: %v", err)
+	}
+	if len(kept) != 2 || excluded != nil {
+		t.Errorf("expected every file kept without a budget, got %v and %v", kept, excluded)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"unicode/utf8"
 )
@@ -23,6 +24,86 @@ type ChunkInfo struct {
 	EmbedContent string // Content used for embeddings and content hash.
 	Hash         string
 	ContentHash  string // SHA256 of raw content text (without file path prefix)
+	Context      string // Generated context prepended to EmbedContent when embedded.
+}
+
+// embeddingText returns the text embedded for the chunk.
+func (c ChunkInfo) embeddingText() string {
+	text := c.EmbedContent
+	if text == "" {
+		text = c.Content
+	}
+	if c.Context != "" {
+		text = c.Context + "\n\n" + text
+	}
+	return text
+}
+
+// chunkIDHashLen is how many hex digits of the content hash a chunk ID keeps.
+const chunkIDHashLen = 12
+
+// ChunkID returns the ID of the chunk at index in filePath, a project-relative
+// path, with the given content hash. It depends on nothing else, so machines
+// indexing the same commit produce the same IDs: "dir/file.go_3_1a2b3c4d5e6f".
+func ChunkID(filePath string, index int, contentHash string) string {
+	id := fmt.Sprintf("%s_%d", filepath.ToSlash(filePath), index)
+	if len(contentHash) >= chunkIDHashLen {
+		id += "_" + contentHash[:chunkIDHashLen]
+	}
+	return id
+}
+
+// ChunkIDFilePaths returns the file paths a chunk ID may belong to: the ID
+// without its content hash and chunk index, then also without the parent
+// index of a split chunk ("file.go_0_1_<hash>"). IDs written before chunk
+// IDs carried a content hash ("file.go_3", "file.go_0_1") are parsed too.
+func ChunkIDFilePaths(id string) []string {
+	if i := strings.LastIndexByte(id, '_'); i > 0 && isChunkIDHash(id[i+1:]) {
+		if paths := chunkIDIndexPrefixes(id[:i]); len(paths) > 0 {
+			return paths
+		}
+	}
+	return chunkIDIndexPrefixes(id)
+}
+
+// chunkIDIndexPrefixes returns id without its last _<n> suffix, then
+// without its last two.
+func chunkIDIndexPrefixes(id string) []string {
+	var prefixes []string
+	for range 2 {
+		i := strings.LastIndexByte(id, '_')
+		if i <= 0 || !isDigits(id[i+1:]) {
+			break
+		}
+		id = id[:i]
+		prefixes = append(prefixes, id)
+	}
+	return prefixes
+}
+
+// isChunkIDHash reports whether s is the content hash part of a chunk ID.
+func isChunkIDHash(s string) bool {
+	if len(s) != chunkIDHashLen {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 type Chunker struct {
@@ -103,7 +184,7 @@ func (c *Chunker) Chunk(filePath string, content string) []ChunkInfo {
 		// Generate chunk ID
 		hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d:%s", filePath, pos, end, chunkContent)))
 		contentHash := sha256.Sum256([]byte(chunkContent))
-		chunkID := fmt.Sprintf("%s_%d", filePath, chunkIndex)
+		chunkID := ChunkID(filePath, chunkIndex, hex.EncodeToString(contentHash[:]))
 
 		chunks = append(chunks, ChunkInfo{
 			ID:           chunkID,
@@ -158,20 +239,86 @@ func getLineNumber(lineStarts []int, pos int) int {
 
 // ChunkWithContext adds surrounding context to improve embedding quality
 func (c *Chunker) ChunkWithContext(filePath string, content string) []ChunkInfo {
+	return c.chunkWithHeader(filePath, "File: "+filePath, content)
+}
+
+// ChunkWithLanguage is ChunkWithContext for files whose extension does not
+// tell their language, such as extensionless scripts: the context names the
+// language too.
+func (c *Chunker) ChunkWithLanguage(filePath, language string, content string) []ChunkInfo {
+	return c.chunkWithHeader(filePath, fmt.Sprintf("File: %s (%s)", filePath, language), content)
+}
+
+func (c *Chunker) chunkWithHeader(filePath, header string, content string) []ChunkInfo {
 	chunks := c.Chunk(filePath, content)
 
 	// Add file path context to each chunk
 	for i := range chunks {
-		chunks[i].Content = fmt.Sprintf("File: %s\n\n%s", filePath, chunks[i].Content)
+		chunks[i].Content = header + "\n\n" + chunks[i].Content
 		chunks[i].EmbedContent = chunks[i].Content
 	}
 
 	return chunks
 }
 
+// ChunkSections chunks a file that a handler split into sections.
+// Consecutive sections are packed into one chunk while they fit, and a chunk
+// never spans the boundary between two packs, so a section is only split when
+// it is larger than a chunk on its own. Chunks carry the file path context
+// like ChunkWithContext.
+func (c *Chunker) ChunkSections(filePath string, sections []Section) []ChunkInfo {
+	maxChars := c.chunkSize * CharsPerToken
+
+	var chunks []ChunkInfo
+	var pack []Section
+	packSize := 0
+	flush := func() {
+		if len(pack) == 0 {
+			return
+		}
+		// lines maps each line of the packed text to its source line.
+		var text strings.Builder
+		var lines []int
+		for _, section := range pack {
+			body := strings.TrimRight(section.Text, "\n")
+			if text.Len() > 0 {
+				text.WriteString("\n")
+			}
+			text.WriteString(body)
+			for i := 0; i <= strings.Count(body, "\n"); i++ {
+				lines = append(lines, min(section.StartLine+i, section.EndLine))
+			}
+		}
+		for _, chunk := range c.Chunk(filePath, text.String()) {
+			chunk.StartLine = lines[min(chunk.StartLine, len(lines))-1]
+			chunk.EndLine = lines[min(chunk.EndLine, len(lines))-1]
+			chunk.ID = ChunkID(filePath, len(chunks), chunk.ContentHash)
+			chunk.Content = fmt.Sprintf("File: %s\n\n%s", filePath, chunk.Content)
+			chunk.EmbedContent = chunk.Content
+			chunks = append(chunks, chunk)
+		}
+		pack = nil
+		packSize = 0
+	}
+
+	for _, section := range sections {
+		if strings.TrimSpace(section.Text) == "" {
+			continue
+		}
+		if packSize > 0 && packSize+len(section.Text) > maxChars {
+			flush()
+		}
+		pack = append(pack, section)
+		packSize += len(section.Text)
+	}
+	flush()
+
+	return chunks
+}
+
 // ReChunk splits a single chunk into smaller sub-chunks when it exceeds the embedder's context limit.
 // It uses half the original chunk size to ensure the new chunks fit within limits.
-// The parentIndex is used to generate unique sub-chunk IDs (e.g., "file.go_0_0", "file.go_0_1").
+// The parentIndex is used to generate unique sub-chunk IDs (e.g., "file.go_0_0_<hash>", "file.go_0_1_<hash>").
 func (c *Chunker) ReChunk(parent ChunkInfo, parentIndex int) []ChunkInfo {
 	// Strip the file context prefix if present (we'll re-add it later)
 	content := parent.EmbedContent
@@ -238,10 +385,14 @@ func (c *Chunker) ReChunk(parent ChunkInfo, parentIndex int) []ChunkInfo {
 		absoluteStartLine := parent.StartLine + subStartLine - 1
 		absoluteEndLine := parent.StartLine + subEndLine - 1
 
-		// Generate sub-chunk ID: file.go_parentIndex_subIndex
+		// Generate sub-chunk ID: file.go_parentIndex_subIndex_hash
 		hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d:%d:%s", parent.FilePath, parentIndex, subIndex, pos, chunkContent)))
 		contentHash := sha256.Sum256([]byte(chunkContent))
-		subChunkID := fmt.Sprintf("%s_%d_%d", parent.FilePath, parentIndex, subIndex)
+		subChunkID := ChunkID(fmt.Sprintf("%s_%d", parent.FilePath, parentIndex), subIndex, hex.EncodeToString(contentHash[:]))
+		subContentHash := hex.EncodeToString(contentHash[:])
+		if parent.Context != "" {
+			subContentHash = saltContentHash(subContentHash, parent.Context)
+		}
 
 		// Re-add file context if it was present in the parent
 		finalContent := chunkContent
@@ -257,7 +408,8 @@ func (c *Chunker) ReChunk(parent ChunkInfo, parentIndex int) []ChunkInfo {
 			Content:      finalContent,
 			EmbedContent: finalContent,
 			Hash:         hex.EncodeToString(hash[:8]),
-			ContentHash:  hex.EncodeToString(contentHash[:]),
+			ContentHash:  subContentHash,
+			Context:      parent.Context,
 		})
 
 		subIndex++
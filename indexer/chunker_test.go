@@ -1,6 +1,8 @@
 package indexer
 
 import (
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"unicode/utf8"
@@ -36,6 +38,72 @@ func TestChunker_Chunk(t *testing.T) {
 	}
 }
 
+func TestChunkID(t *testing.T) {
+	hash := "1a2b3c4d5e6f7a8b9c0d"
+	tests := []struct {
+		name     string
+		filePath string
+		index    int
+		hash     string
+		want     string
+	}{
+		{"with hash", "auth/login.go", 3, hash, "auth/login.go_3_1a2b3c4d5e6f"},
+		{"os separators", filepath.Join("auth", "login.go"), 3, hash, "auth/login.go_3_1a2b3c4d5e6f"},
+		{"without hash", "main.go", 0, "", "main.go_0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChunkID(tt.filePath, tt.index, tt.hash); got != tt.want {
+				t.Errorf("ChunkID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkIDFilePaths(t *testing.T) {
+	tests := map[string][]string{
+		"main.go_3":                                {"main.go"},
+		"a/b_c.go_12_4":                            {"a/b_c.go_12", "a/b_c.go"},
+		"no-index":                                 nil,
+		"file_name.go_x1":                          nil,
+		"auth/login.go_3_1a2b3c4d5e6f":             {"auth/login.go"},
+		"net/retry.go_1_0_abcdef012345":            {"net/retry.go_1", "net/retry.go"},
+		"report_2024_0_abcdef012345":               {"report_2024", "report"},
+		"main.go_123456789012":                     {"main.go"},
+		ChunkID("pkg/x.go", 7, "0123456789abcdef"): {"pkg/x.go"},
+	}
+	for id, want := range tests {
+		if got := ChunkIDFilePaths(id); !reflect.DeepEqual(got, want) {
+			t.Errorf("ChunkIDFilePaths(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestChunker_ChunkIDsAreDeterministic(t *testing.T) {
+	content := strings.Repeat("line of code\n", 50)
+	first := NewChunker(100, 10).Chunk("pkg/test.go", content)
+	second := NewChunker(100, 10).Chunk("pkg/test.go", content)
+	if len(first) != len(second) || len(first) < 2 {
+		t.Fatalf("expected the same chunks twice, got %d and %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i].ID != second[i].ID {
+			t.Errorf("chunk %d: IDs differ between runs: %s and %s", i, first[i].ID, second[i].ID)
+		}
+		if want := ChunkID("pkg/test.go", i, first[i].ContentHash); first[i].ID != want {
+			t.Errorf("chunk %d: ID = %s, want %s", i, first[i].ID, want)
+		}
+	}
+
+	edited := NewChunker(100, 10).Chunk("pkg/test.go", "edited line\n"+content[len("line of code\n"):])
+	if edited[0].ID == first[0].ID {
+		t.Error("expected an edited chunk to get a new ID")
+	}
+	if edited[len(edited)-1].ID != first[len(first)-1].ID {
+		t.Error("expected an unchanged chunk at the same position to keep its ID")
+	}
+}
+
 func TestChunker_ChunkEmptyContent(t *testing.T) {
 	chunker := NewChunker(512, 50)
 	chunks := chunker.Chunk("empty.go", "")
@@ -418,3 +486,14 @@ func TestChunker_ReChunkUTF8Boundaries(t *testing.T) {
 		}
 	}
 }
+
+func TestChunker_ChunkWithLanguage(t *testing.T) {
+	chunker := NewChunker(512, 50)
+	chunks := chunker.ChunkWithLanguage("bin/deploy", "python", "print('deploy')\n")
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	if !strings.HasPrefix(chunks[0].Content, "File: bin/deploy (python)\n\n") {
+		t.Errorf("expected language in the file context, got %q", chunks[0].Content)
+	}
+}
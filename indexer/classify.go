@@ -0,0 +1,139 @@
+package indexer
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Content skip reasons reported by the classifier.
+const (
+	SkipBinary    = "binary"
+	SkipGenerated = "generated"
+)
+
+// binarySampleSize is how much of a file the binary rule inspects.
+const binarySampleSize = 8 * 1024
+
+// binaryControlShare is the share of control characters in the sample above
+// which a file is treated as binary.
+const binaryControlShare = 0.1
+
+// generatedHeaderSize is how much of a file is searched for generated-code
+// markers. Tools write them in the first comment of the file.
+const generatedHeaderSize = 2 * 1024
+
+// generatedMarkers are matched case-sensitively in the header of a file.
+var generatedMarkers = []string{
+	"DO NOT EDIT",
+	"@generated",
+	"<auto-generated",
+	"Code generated by",
+	"AUTO-GENERATED FILE",
+	"This file was automatically generated",
+	"Autogenerated by",
+}
+
+// ContentRules toggles the rules that keep binary and generated files out of
+// the index, judged from their content. Minified files are skipped by the
+// MinifiedHandler.
+type ContentRules struct {
+	// Binary samples the start of a file for NUL bytes, invalid UTF-8 and
+	// control characters. When disabled, only files that contain a NUL
+	// byte or are not valid UTF-8 as a whole are skipped.
+	Binary bool
+	// Generated skips files with a generated-code marker, such as
+	// "DO NOT EDIT", in their header.
+	Generated bool
+	// TextExtensions are always treated as text, even with control
+	// characters. Content that is not UTF-8 in them is read as Latin-1
+	// when it looks like 8-bit text and has no NUL byte; otherwise its
+	// invalid UTF-8 is replaced. Either way the file is not skipped as
+	// binary. Extensions are lowercase and start with a dot.
+	TextExtensions map[string]bool
+}
+
+// DefaultContentRules enables every rule.
+func DefaultContentRules() ContentRules {
+	return ContentRules{Binary: true, Generated: true}
+}
+
+// classify returns why a file should not be indexed, or "" to index it,
+// with the content to index. Content that is not UTF-8 but reads as Latin-1
+// text is converted when the binary rule is on. Invalid UTF-8 the rules let
+// through is replaced, so the returned content is always valid UTF-8.
+func (r ContentRules) classify(path string, content []byte) (string, []byte) {
+	ext := strings.ToLower(filepath.Ext(path))
+	// Legacy 8-bit text, such as Latin-1 sources from Windows toolchains, is
+	// converted rather than taken for binary.
+	if (r.Binary || r.TextExtensions[ext]) && !utf8.Valid(content) && bytes.IndexByte(content, 0) < 0 && looksLatin1(content) {
+		content = decodeText(content, CharsetLatin1)
+	}
+	switch {
+	case r.TextExtensions[ext]:
+	case r.Binary:
+		if looksBinary(content) {
+			return SkipBinary, nil
+		}
+	case !utf8.Valid(content) || bytes.IndexByte(content, 0) >= 0:
+		return SkipBinary, nil
+	}
+	if !utf8.Valid(content) {
+		content = bytes.ToValidUTF8(content, []byte("\uFFFD"))
+	}
+
+	if r.Generated && hasGeneratedMarker(content) {
+		return SkipGenerated, nil
+	}
+	return "", content
+}
+
+// looksBinary reports whether content is binary: it has a NUL byte, or its
+// first bytes are not UTF-8 or are mostly control characters.
+func looksBinary(content []byte) bool {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return true
+	}
+	sample := content
+	if len(sample) > binarySampleSize {
+		sample = sample[:binarySampleSize]
+		// Drop a rune cut off by the end of the sample.
+		for i := 1; i < utf8.UTFMax; i++ {
+			if utf8.RuneStart(sample[len(sample)-i]) {
+				if !utf8.FullRune(sample[len(sample)-i:]) {
+					sample = sample[:len(sample)-i]
+				}
+				break
+			}
+		}
+	}
+	if !utf8.Valid(sample) {
+		return true
+	}
+	if len(sample) == 0 {
+		return false
+	}
+	control := 0
+	for _, b := range sample {
+		if (b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != 0x1b) || b == 0x7f {
+			control++
+		}
+	}
+	return float64(control)/float64(len(sample)) > binaryControlShare
+}
+
+// hasGeneratedMarker reports whether the header of content carries a
+// generated-code marker.
+func hasGeneratedMarker(content []byte) bool {
+	header := content
+	if len(header) > generatedHeaderSize {
+		header = header[:generatedHeaderSize]
+	}
+	for _, marker := range generatedMarkers {
+		if bytes.Contains(header, []byte(marker)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContentRulesClassify(t *testing.T) {
+	minified := "var a=1;" + strings.Repeat("x", 2000)
+	generated := "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pb\n"
+
+	tests := []struct {
+		name    string
+		rules   ContentRules
+		path    string
+		content string
+		reason  string
+	}{
+		{"text", DefaultContentRules(), "main.go", "package main\n", ""},
+		{"binary", DefaultContentRules(), "data.json", "\x01\x02\x03\x04{}", SkipBinary},
+		{"minified is left to the handler", DefaultContentRules(), "app.css", minified, ""},
+		{"generated", DefaultContentRules(), "api.pb.go", generated, SkipGenerated},
+		{"generated rule off", ContentRules{Binary: true}, "api.pb.go", generated, ""},
+		{"binary rule off keeps control characters", ContentRules{}, "data.json", "\x01\x02\x03\x04{}", ""},
+		{"binary rule off still skips NUL", ContentRules{}, "data.json", "a\x00b", SkipBinary},
+		{"latin-1 source", DefaultContentRules(), "unit.pas", "{ caf\xe9 }", ""},
+		{"latin-1 source binary rule off", ContentRules{}, "unit.pas", "{ caf\xe9 }", SkipBinary},
+		{"corrupt utf-8 source", DefaultContentRules(), "unit.pas", "{ d\xc3\xa9j\xc3\xa0 vu \xe9 }", SkipBinary},
+		{"latin-1 text extension", ContentRules{Binary: true, TextExtensions: map[string]bool{".pas": true}}, "unit.pas", "{ caf\xe9 }", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, content := tt.rules.classify(tt.path, []byte(tt.content))
+			if reason != tt.reason {
+				t.Fatalf("expected reason %q, got %q", tt.reason, reason)
+			}
+			if reason == "" && !strings.ContainsAny(string(content), "�é") && string(content) != tt.content {
+				t.Errorf("content changed: %q", content)
+			}
+		})
+	}
+
+	_, content := ContentRules{TextExtensions: map[string]bool{".pas": true}}.classify("unit.pas", []byte("caf\xe9"))
+	if string(content) != "café" {
+		t.Errorf("expected invalid UTF-8 to be read as Latin-1, got %q", content)
+	}
+	_, content = ContentRules{TextExtensions: map[string]bool{".pas": true}}.classify("unit.pas", []byte("caf\xe9\x00"))
+	if string(content) != "caf�\x00" {
+		t.Errorf("expected invalid UTF-8 to be replaced, got %q", content)
+	}
+}
+
+func TestScanner_SkipsGeneratedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":   "package main\n",
+		"api.pb.go": "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage main\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := NewScanner(tmpDir, ignoreMatcher)
+
+	scanned, skipped, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	if len(scanned) != 1 || scanned[0].Path != "main.go" {
+		t.Errorf("expected only main.go, got %+v", scanned)
+	}
+	if len(skipped) != 1 || skipped[0] != "api.pb.go (generated)" {
+		t.Errorf("expected api.pb.go skipped as generated, got %v", skipped)
+	}
+
+	scanner.SetContentRules(ContentRules{Binary: true})
+	file, err := scanner.ScanFile("api.pb.go")
+	if err != nil {
+		t.Fatalf("failed to scan file: %v", err)
+	}
+	if file == nil {
+		t.Error("expected generated file to be indexed with the rule off")
+	}
+}
@@ -0,0 +1,196 @@
+package indexer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/yoanbernabeu/grepai/framework"
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// FileSummarizer describes what a file is for, usually with an LLM.
+type FileSummarizer interface {
+	SummarizeFile(ctx context.Context, path, content string) (string, error)
+}
+
+// Contextualizer enriches chunks before they are embedded with the context
+// they lose once cut out of their file: a summary of the file's purpose and
+// the signature of the symbol they belong to. Summaries are generated once
+// per file content and cached in a file, so only changed files are
+// summarized again. It is safe for concurrent use.
+type Contextualizer struct {
+	summarizer FileSummarizer
+	symbols    *trace.RegexExtractor
+	cachePath  string
+
+	mu        sync.Mutex
+	summaries map[string]summaryCacheEntry
+	loaded    bool
+}
+
+type summaryCacheEntry struct {
+	Path    string `json:"path"`
+	Hash    string `json:"hash"`
+	Summary string `json:"summary"`
+}
+
+// NewContextualizer returns a Contextualizer summarizing files with
+// summarizer. When cachePath is empty, summaries are only cached for the
+// Contextualizer's lifetime.
+func NewContextualizer(summarizer FileSummarizer, cachePath string) *Contextualizer {
+	return &Contextualizer{
+		summarizer: summarizer,
+		symbols:    trace.NewRegexExtractor(),
+		cachePath:  cachePath,
+		summaries:  make(map[string]summaryCacheEntry),
+	}
+}
+
+// SetContextualizer makes the indexer prepend the context c generates to
+// each chunk before embedding it. A nil c turns contextual chunks off.
+func (idx *Indexer) SetContextualizer(c *Contextualizer) {
+	idx.contextualizer = c
+}
+
+// contextualize sets the context of chunks of file, whose line numbers map
+// to source lines through lineMap. The context is part of the content hash
+// of a chunk, so vectors embedded without it, or with another summary, are
+// not reused.
+func (c *Contextualizer) contextualize(ctx context.Context, file FileInfo, chunks []ChunkInfo, lineMap []int) {
+	if c == nil || len(chunks) == 0 {
+		return
+	}
+	summary := c.summary(ctx, file)
+	symbols, _ := c.symbols.ExtractSymbols(ctx, file.Path, file.Content)
+
+	for i := range chunks {
+		var lines []string
+		if summary != "" {
+			lines = append(lines, "Summary: "+summary)
+		}
+		startLine, endLine := framework.RemapLineRange(lineMap, chunks[i].StartLine, chunks[i].EndLine)
+		if sym := trace.EnclosingSymbol(symbols, startLine, endLine); sym != nil {
+			lines = append(lines, "Symbol: "+sym.Declaration())
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		chunks[i].Context = strings.Join(lines, "\n")
+		chunks[i].ContentHash = saltContentHash(chunks[i].ContentHash, chunks[i].Context)
+	}
+}
+
+// summary returns the summary of file, generating it when the cache has none
+// for its current content. Failures are logged and leave the file without a
+// summary until it is indexed again.
+func (c *Contextualizer) summary(ctx context.Context, file FileInfo) string {
+	if strings.TrimSpace(file.Content) == "" {
+		return ""
+	}
+	c.mu.Lock()
+	c.load()
+	entry, ok := c.summaries[file.Path]
+	c.mu.Unlock()
+	if ok && entry.Hash == file.Hash {
+		return entry.Summary
+	}
+
+	summary, err := c.summarizer.SummarizeFile(ctx, file.Path, file.Content)
+	if err != nil {
+		log.Printf("Warning: failed to summarize %s for contextual chunks: %v", file.Path, err)
+		return ""
+	}
+	summary = strings.Join(strings.Fields(summary), " ")
+
+	entry = summaryCacheEntry{Path: file.Path, Hash: file.Hash, Summary: summary}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.summaries[file.Path] = entry
+	if err := c.appendEntry(entry); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return summary
+}
+
+// load reads the summaries cached by previous sessions, keeping the last
+// summary of each file. When the cache holds superseded summaries or lines
+// cut short by a crash, it is rewritten without them.
+func (c *Contextualizer) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	if c.cachePath == "" {
+		return
+	}
+	f, err := os.Open(c.cachePath)
+	if err != nil {
+		return
+	}
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines++
+		var entry summaryCacheEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Path == "" {
+			continue
+		}
+		c.summaries[entry.Path] = entry
+	}
+	f.Close()
+
+	if lines != len(c.summaries) {
+		if err := c.rewrite(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// appendEntry appends entry to the cache file.
+func (c *Contextualizer) appendEntry(entry summaryCacheEntry) error {
+	if c.cachePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode summary cache entry: %w", err)
+	}
+	if err := fileutil.EnsureParentDir(c.cachePath); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.cachePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open summary cache: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write summary cache: %w", err)
+	}
+	return nil
+}
+
+// rewrite replaces the cache file with the summaries in memory.
+func (c *Contextualizer) rewrite() error {
+	var sb strings.Builder
+	for _, entry := range c.summaries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode summary cache entry: %w", err)
+		}
+		sb.Write(data)
+		sb.WriteByte('\n')
+	}
+	tempPath := c.cachePath + ".tmp"
+	if err := os.WriteFile(tempPath, []byte(sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write summary cache: %w", err)
+	}
+	return fileutil.ReplaceFileAtomically(tempPath, c.cachePath)
+}
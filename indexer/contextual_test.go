@@ -0,0 +1,132 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockSummarizer struct {
+	calls int
+	err   error
+}
+
+func (m *mockSummarizer) SummarizeFile(ctx context.Context, path, content string) (string, error) {
+	m.calls++
+	if m.err != nil {
+		return "", m.err
+	}
+	return "Handles\n  the " + path + " entry point.", nil
+}
+
+func TestContextualizer_SummaryAndSymbol(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "summaries.jsonl")
+	summarizer := &mockSummarizer{}
+	c := NewContextualizer(summarizer, cachePath)
+
+	file := FileInfo{
+		Path:    "main.go",
+		Hash:    "h1",
+		Content: "package main\n\nfunc Serve(addr string) error {\n\treturn nil\n}\n",
+	}
+	chunks := []ChunkInfo{{StartLine: 3, EndLine: 5, ContentHash: "abc"}}
+	c.contextualize(context.Background(), file, chunks, nil)
+
+	want := "Summary: Handles the main.go entry point.\nSymbol: func Serve(addr string) error"
+	if chunks[0].Context != want {
+		t.Fatalf("unexpected context %q, want %q", chunks[0].Context, want)
+	}
+	if chunks[0].ContentHash == "abc" {
+		t.Error("expected the context to change the content hash")
+	}
+	if got := chunks[0].embeddingText(); !strings.HasPrefix(got, want+"\n\n") {
+		t.Errorf("expected the embedded text to start with the context, got %q", got)
+	}
+
+	// The summary of unchanged content comes from the cache file, also for
+	// a new contextualizer.
+	reloaded := NewContextualizer(summarizer, cachePath)
+	again := []ChunkInfo{{StartLine: 3, EndLine: 5, ContentHash: "abc"}}
+	reloaded.contextualize(context.Background(), file, again, nil)
+	if summarizer.calls != 1 {
+		t.Fatalf("expected 1 summary, got %d", summarizer.calls)
+	}
+	if again[0].Context != want || again[0].ContentHash != chunks[0].ContentHash {
+		t.Errorf("expected the cached summary to give the same context and hash, got %+v", again[0])
+	}
+
+	// Changed content is summarized again.
+	file.Hash = "h2"
+	reloaded.contextualize(context.Background(), file, []ChunkInfo{{StartLine: 1, EndLine: 1}}, nil)
+	if summarizer.calls != 2 {
+		t.Errorf("expected changed content to be summarized again, got %d summaries", summarizer.calls)
+	}
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("failed to read summary cache: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("expected 2 cache lines, got %d", lines)
+	}
+
+	// Loading compacts superseded summaries.
+	NewContextualizer(summarizer, cachePath).load()
+	data, err = os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("failed to read summary cache: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 || !strings.Contains(string(data), `"hash":"h2"`) {
+		t.Errorf("expected the cache to keep the last summary only, got %q", data)
+	}
+}
+
+func TestContextualizer_SummaryFailure(t *testing.T) {
+	summarizer := &mockSummarizer{err: errors.New("connection refused")}
+	c := NewContextualizer(summarizer, "")
+
+	file := FileInfo{Path: "notes.txt", Hash: "h1", Content: "some notes\n"}
+	chunks := []ChunkInfo{{StartLine: 1, EndLine: 1, ContentHash: "abc"}}
+	c.contextualize(context.Background(), file, chunks, nil)
+	if chunks[0].Context != "" || chunks[0].ContentHash != "abc" {
+		t.Errorf("expected a chunk without context, got %+v", chunks[0])
+	}
+
+	c.contextualize(context.Background(), file, chunks, nil)
+	if summarizer.calls != 2 {
+		t.Errorf("expected failed summaries to be retried, got %d calls", summarizer.calls)
+	}
+}
+
+func TestIndexFile_Contextualizer(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "package main\n\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write main.go: %v", err)
+	}
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	st := newMockStore()
+	emb := newMockEmbedder()
+	idx := NewIndexer(tmpDir, st, emb, NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Time{})
+	idx.SetContextualizer(NewContextualizer(&mockSummarizer{}, ""))
+
+	file := FileInfo{Path: "main.go", Hash: "h1", Content: content}
+	if _, err := idx.IndexFile(context.Background(), file); err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+	if len(emb.lastBatch) == 0 || !strings.HasPrefix(emb.lastBatch[0], "Summary: Handles the main.go entry point.\nSymbol: func main()") {
+		t.Fatalf("expected the chunk to be embedded with its context, got %q", emb.lastBatch)
+	}
+	for _, chunk := range st.chunks {
+		if strings.Contains(chunk.Content, "Summary:") {
+			t.Errorf("expected the stored content to leave out the context, got %q", chunk.Content)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/embedder"
+)
+
+// docsExtensions are the extensions of documentation files, which a docs
+// embedder embeds instead of the main one.
+var docsExtensions = map[string]bool{
+	".md":       true,
+	".mdx":      true,
+	".markdown": true,
+	".rst":      true,
+	".adoc":     true,
+	".txt":      true,
+	".pdf":      true,
+	".docx":     true,
+}
+
+// IsDocsFile reports whether path is a documentation file.
+func IsDocsFile(path string) bool {
+	return docsExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// SetDocsEmbedder makes the indexer embed documentation files with emb
+// instead of the main embedder, tagging their chunks with model so searches
+// can tell which embedding of the query to compare them with.
+func (idx *Indexer) SetDocsEmbedder(emb embedder.Embedder, model string) {
+	idx.docsEmbedder = emb
+	idx.docsModel = model
+}
+
+// embedderFor returns the embedder of the chunks of path and the model tag
+// they are stored with, empty for the main embedder.
+func (idx *Indexer) embedderFor(path string) (embedder.Embedder, string) {
+	if idx.docsEmbedder != nil && IsDocsFile(path) {
+		return idx.docsEmbedder, idx.docsModel
+	}
+	return idx.embedder, ""
+}
+
+// embeddingGroup is a set of files embedded with the same embedder.
+type embeddingGroup struct {
+	emb   embedder.Embedder
+	files []FileInfo
+}
+
+// embeddingGroups splits files between the main embedder and the docs
+// embedder, keeping their order and leaving out empty groups.
+func (idx *Indexer) embeddingGroups(files []FileInfo) []embeddingGroup {
+	if idx.docsEmbedder == nil {
+		return []embeddingGroup{{emb: idx.embedder, files: files}}
+	}
+	var code, docs []FileInfo
+	for _, file := range files {
+		if IsDocsFile(file.Path) {
+			docs = append(docs, file)
+		} else {
+			code = append(code, file)
+		}
+	}
+	var groups []embeddingGroup
+	if len(code) > 0 {
+		groups = append(groups, embeddingGroup{emb: idx.embedder, files: code})
+	}
+	if len(docs) > 0 {
+		groups = append(groups, embeddingGroup{emb: idx.docsEmbedder, files: docs})
+	}
+	return groups
+}
+
+// tagContentHashes derives the content hashes of chunks embedded with the
+// docs model from their content and the model, so that their vectors are
+// only ever reused for chunks of the same model.
+func tagContentHashes(chunks []ChunkInfo, model string) {
+	if model == "" {
+		return
+	}
+	for i := range chunks {
+		if chunks[i].ContentHash == "" {
+			continue
+		}
+		chunks[i].ContentHash = saltContentHash(chunks[i].ContentHash, model)
+	}
+}
+
+// saltContentHash derives a content hash from hash and salt, for chunks whose
+// vectors depend on more than their content.
+func saltContentHash(hash, salt string) string {
+	sum := sha256.Sum256([]byte(salt + "\x00" + hash))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,98 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsDocsFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"README.md":         true,
+		"docs/guide.MDX":    true,
+		"manual.pdf":        true,
+		"notes.txt":         true,
+		"main.go":           false,
+		"config.yaml":       false,
+		"Makefile":          false,
+		"docs/markdown.tsx": false,
+	} {
+		if got := IsDocsFile(path); got != want {
+			t.Errorf("IsDocsFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestIndexAll_DocsEmbedder(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":   "package main\n\nfunc main() {}\n",
+		"README.md": "# Project\n\nHow to run the project.\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	for _, batched := range []bool{true, false} {
+		st := newMockStore()
+		codeEmb := newMockBatchEmbedder()
+		docsEmb := newMockEmbedder()
+		idx := NewIndexer(tmpDir, st, codeEmb, NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Time{})
+		idx.SetDocsEmbedder(docsEmb, "docs-model")
+		idx.SetSerialEmbedding(func() bool { return !batched })
+
+		if _, err := idx.IndexAll(context.Background()); err != nil {
+			t.Fatalf("IndexAll failed: %v", err)
+		}
+		if !docsEmb.embedCalled {
+			t.Errorf("batched=%v: expected the docs embedder to embed README.md", batched)
+		}
+		for _, text := range docsEmb.lastBatch {
+			if strings.Contains(text, "func main") {
+				t.Errorf("batched=%v: code was embedded with the docs embedder", batched)
+			}
+		}
+		var code, docs int
+		for _, chunk := range st.chunks {
+			switch chunk.FilePath {
+			case "main.go":
+				code++
+				if chunk.Model != "" {
+					t.Errorf("batched=%v: expected an untagged code chunk, got model %q", batched, chunk.Model)
+				}
+			case "README.md":
+				docs++
+				if chunk.Model != "docs-model" {
+					t.Errorf("batched=%v: expected a docs chunk tagged docs-model, got %q", batched, chunk.Model)
+				}
+			}
+		}
+		if code == 0 || docs == 0 {
+			t.Fatalf("batched=%v: expected code and docs chunks, got %d and %d", batched, code, docs)
+		}
+	}
+}
+
+func TestTagContentHashes(t *testing.T) {
+	chunks := []ChunkInfo{{ContentHash: "abc"}, {}}
+	tagContentHashes(chunks, "")
+	if chunks[0].ContentHash != "abc" {
+		t.Fatalf("expected chunks of the main embedder to keep their hash, got %q", chunks[0].ContentHash)
+	}
+	tagContentHashes(chunks, "docs-model")
+	if chunks[0].ContentHash == "abc" || len(chunks[0].ContentHash) != 64 {
+		t.Errorf("expected a model-tagged hash, got %q", chunks[0].ContentHash)
+	}
+	if chunks[1].ContentHash != "" {
+		t.Errorf("expected an empty hash to stay empty, got %q", chunks[1].ContentHash)
+	}
+}
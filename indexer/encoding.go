@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/yoanbernabeu/grepai/internal/editorconfig"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Text encodings the scanner converts to UTF-8, named as .editorconfig
+// charsets.
+const (
+	CharsetUTF8    = "utf-8"
+	CharsetUTF8BOM = "utf-8-bom"
+	CharsetUTF16LE = "utf-16le"
+	CharsetUTF16BE = "utf-16be"
+	CharsetLatin1  = "latin1"
+)
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// bomCharset returns the encoding of content told by its byte order mark,
+// and the length of the mark, or "" when it has none.
+func bomCharset(content []byte) (string, int) {
+	switch {
+	case bytes.HasPrefix(content, bomUTF8):
+		return CharsetUTF8BOM, len(bomUTF8)
+	case bytes.HasPrefix(content, bomUTF16LE):
+		return CharsetUTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(content, bomUTF16BE):
+		return CharsetUTF16BE, len(bomUTF16BE)
+	}
+	return "", 0
+}
+
+// decodeFile converts content of the file at path to UTF-8 when its byte
+// order mark, or the charset .editorconfig files read through configs give
+// it, tells another encoding, such as UTF-16 from Windows toolchains. The
+// charset is only looked up for content that is not plain UTF-8.
+func decodeFile(path string, content []byte, configs *editorconfig.Cache) []byte {
+	if charset, n := bomCharset(content); charset != "" {
+		return decodeText(content[n:], charset)
+	}
+	if utf8.Valid(content) && bytes.IndexByte(content, 0) < 0 {
+		return content
+	}
+	return decodeText(content, configs.Charset(path))
+}
+
+// decodeText converts content, without a byte order mark, from charset to
+// UTF-8. Content in UTF-8 or an unknown charset is returned as is.
+func decodeText(content []byte, charset string) []byte {
+	var enc encoding.Encoding
+	switch charset {
+	case CharsetUTF16LE:
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case CharsetUTF16BE:
+		enc = unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case CharsetLatin1:
+		// Bytes 0x80 to 0x9F are control characters in Latin-1; text from
+		// Windows editors means them as in Windows-1252, such as curly
+		// quotes.
+		enc = charmap.Windows1252
+	default:
+		return content
+	}
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return content
+	}
+	return decoded
+}
+
+// looksLatin1 reports whether content, which is not valid UTF-8, is more
+// likely 8-bit text than UTF-8 with a few corrupt bytes: it has more bytes
+// outside UTF-8 sequences than multi-byte UTF-8 characters.
+func looksLatin1(content []byte) bool {
+	invalid, multiByte := 0, 0
+	for len(content) > 0 {
+		r, size := utf8.DecodeRune(content)
+		switch {
+		case r == utf8.RuneError && size == 1:
+			invalid++
+		case size > 1:
+			multiByte++
+		}
+		content = content[size:]
+	}
+	return invalid > multiByte
+}
@@ -0,0 +1,80 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeFile(t *testing.T) {
+	dir := t.TempDir()
+	editorConfig := "root = true\n\n[*.rc]\ncharset = utf-16le\n\n[*.bas]\ncharset = latin1\n"
+	if err := os.WriteFile(filepath.Join(dir, ".editorconfig"), []byte(editorConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{"utf-8", "main.go", "package main // café\n", "package main // café\n"},
+		{"utf-8 bom", "main.go", "\xef\xbb\xbfpackage main\n", "package main\n"},
+		{"utf-16le bom", "build.ps1", "\xff\xfeW\x00r\x00i\x00t\x00e\x00 \x00\xe9\x00\n\x00", "Write é\n"},
+		{"utf-16be bom", "build.ps1", "\xfe\xff\x00W\x00r\x00i\x00t\x00e\x00 \x00\xe9\x00\n", "Write é\n"},
+		{"utf-16le from editorconfig", "app.rc", "I\x00D\x00I\x00\n\x00", "IDI\n"},
+		{"latin1 from editorconfig", "main.bas", "PRINT \"caf\xe9\"\n", "PRINT \"café\"\n"},
+		{"windows-1252 quotes", "main.bas", "' \x93quoted\x94\n", "' “quoted”\n"},
+		{"no charset", "data.bin", "\x00\x01\x02", "\x00\x01\x02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(decodeFile(filepath.Join(dir, tt.path), []byte(tt.content), nil)); got != tt.want {
+				t.Errorf("decodeFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_IndexesWindowsEncodings(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"Program.cs": "\xff\xfec\x00l\x00a\x00s\x00s\x00 \x00A\x00{\x00}\x00\r\x00\n\x00",
+		"unit.pas":   "{ caf\xe9 cr\xe8me }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := NewScanner(tmpDir, ignoreMatcher)
+
+	scanned, _, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	got := make(map[string]string)
+	for _, f := range scanned {
+		got[f.Path] = f.Content
+	}
+	want := map[string]string{
+		"Program.cs": "class A{}\r\n",
+		"unit.pas":   "{ café crème }\n",
+	}
+	for path, content := range want {
+		if got[path] != content {
+			t.Errorf("content of %s = %q, want %q", path, got[path], content)
+		}
+	}
+
+	file, err := scanner.ScanFile("Program.cs")
+	if err != nil || file == nil || file.Content != want["Program.cs"] {
+		t.Errorf("ScanFile(Program.cs) = %+v, %v; want UTF-8 content", file, err)
+	}
+}
@@ -0,0 +1,450 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/docextract"
+)
+
+// File handler names, matching the keys of the indexer.file_handlers config.
+const (
+	HandlerNotebooks = "notebooks"
+	HandlerSchemas   = "schemas"
+	HandlerMinified  = "minified"
+	HandlerDocuments = "documents"
+)
+
+// HandlerExtensions lists extensions that are only indexed through a file
+// handler. Unlike SupportedExtensions, their raw content is never chunked.
+var HandlerExtensions = map[string]bool{
+	".ipynb":   true,
+	".graphql": true,
+	".gql":     true,
+	".pdf":     true,
+	".docx":    true,
+}
+
+// FileHandler preprocesses one kind of file before it is chunked.
+type FileHandler interface {
+	Name() string
+	Supports(path string) bool
+	Prepare(path, content string) (PreparedFile, error)
+}
+
+// PreparedFile is what a handler makes of a file.
+type PreparedFile struct {
+	// Skip is the reason the file should not be indexed, if any.
+	Skip string
+	// Sections replace the file content for chunking. Nil means the content
+	// is chunked as is.
+	Sections []Section
+}
+
+// Section is a self-contained part of a file, such as a notebook cell or a
+// schema definition. Chunks never span two sections unless they are packed
+// together whole.
+type Section struct {
+	Text      string
+	StartLine int // source line of the first line of Text
+	EndLine   int // last source line the section covers
+}
+
+// BinaryFileHandler is a FileHandler for binary document formats. Its files
+// skip the scanner's binary content check, and MaxFileSize replaces the
+// limit for source files.
+type BinaryFileHandler interface {
+	FileHandler
+	MaxFileSize() int64
+}
+
+// FileHandlers picks the handler for a file. The first handler that supports
+// a path handles it.
+type FileHandlers struct {
+	handlers []FileHandler
+}
+
+func NewFileHandlers(handlers ...FileHandler) *FileHandlers {
+	return &FileHandlers{handlers: handlers}
+}
+
+func (h *FileHandlers) find(path string) FileHandler {
+	if h == nil {
+		return nil
+	}
+	for _, handler := range h.handlers {
+		if handler.Supports(path) {
+			return handler
+		}
+	}
+	return nil
+}
+
+// Handles reports whether a handler supports path.
+func (h *FileHandlers) Handles(path string) bool {
+	return h.find(path) != nil
+}
+
+// Prepare runs the handler for path, if there is one.
+func (h *FileHandlers) Prepare(path, content string) (PreparedFile, error) {
+	handler := h.find(path)
+	if handler == nil {
+		return PreparedFile{}, nil
+	}
+	prepared, err := handler.Prepare(path, content)
+	if err != nil {
+		return PreparedFile{}, fmt.Errorf("%s handler: %w", handler.Name(), err)
+	}
+	return prepared, nil
+}
+
+func hasExtension(path string, exts ...string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// NotebookHandler indexes the code and markdown cells of Jupyter notebooks.
+// Outputs, attachments and metadata are dropped.
+type NotebookHandler struct{}
+
+func (h *NotebookHandler) Name() string { return HandlerNotebooks }
+
+func (h *NotebookHandler) Supports(path string) bool { return hasExtension(path, ".ipynb") }
+
+func (h *NotebookHandler) Prepare(path, content string) (PreparedFile, error) {
+	sections, err := notebookSections(content)
+	if err != nil {
+		return PreparedFile{}, err
+	}
+	if len(sections) == 0 {
+		return PreparedFile{Skip: "no code or markdown cells"}, nil
+	}
+	return PreparedFile{Sections: sections}, nil
+}
+
+// notebookSections walks the notebook JSON with a streaming decoder so each
+// cell's source can be mapped back to its lines in the file. Pretty-printed
+// notebooks store one source line per JSON line, right after the "source"
+// key.
+func notebookSections(content string) ([]Section, error) {
+	lineStarts := buildLineStarts(content)
+	lineAt := func(offset int64) int { return getLineNumber(lineStarts, int(offset)) }
+
+	dec := json.NewDecoder(strings.NewReader(content))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var sections []Section
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid notebook: %w", err)
+		}
+		if key != "cells" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, fmt.Errorf("invalid notebook: %w", err)
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			section, ok, err := notebookCell(dec, lineAt)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				sections = append(sections, section)
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+func notebookCell(dec *json.Decoder, lineAt func(int64) int) (Section, bool, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return Section{}, false, err
+	}
+
+	var cellType string
+	var source json.RawMessage
+	var sourceLine, sourceEnd int
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return Section{}, false, fmt.Errorf("invalid notebook cell: %w", err)
+		}
+		switch key {
+		case "cell_type":
+			err = dec.Decode(&cellType)
+		case "source":
+			sourceLine = lineAt(dec.InputOffset())
+			err = dec.Decode(&source)
+			sourceEnd = lineAt(dec.InputOffset() - 1)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return Section{}, false, fmt.Errorf("invalid notebook cell: %w", err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return Section{}, false, err
+	}
+
+	if cellType != "code" && cellType != "markdown" {
+		return Section{}, false, nil
+	}
+	text, err := notebookSource(source)
+	if err != nil {
+		return Section{}, false, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return Section{}, false, nil
+	}
+	return Section{
+		Text:      text,
+		StartLine: min(sourceLine+1, sourceEnd),
+		EndLine:   sourceEnd,
+	}, true, nil
+}
+
+// notebookSource decodes a cell source, which nbformat allows to be either a
+// string or a list of lines.
+func notebookSource(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return "", nil
+	}
+	if raw[0] == '"' {
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return "", fmt.Errorf("invalid cell source: %w", err)
+		}
+		return text, nil
+	}
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err != nil {
+		return "", fmt.Errorf("invalid cell source: %w", err)
+	}
+	return strings.Join(lines, ""), nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid notebook: %w", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("invalid notebook: expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// SchemaHandler splits Protocol Buffers and GraphQL schemas at top-level
+// definitions, so a message, service or type is kept in one chunk whenever
+// it fits.
+type SchemaHandler struct{}
+
+func (h *SchemaHandler) Name() string { return HandlerSchemas }
+
+func (h *SchemaHandler) Supports(path string) bool {
+	return hasExtension(path, ".proto", ".graphql", ".gql")
+}
+
+func (h *SchemaHandler) Prepare(path, content string) (PreparedFile, error) {
+	comment := "#"
+	if hasExtension(path, ".proto") {
+		comment = "//"
+	}
+	return PreparedFile{Sections: schemaSections(content, comment)}, nil
+}
+
+// schemaSections groups lines into top-level definitions. A definition runs
+// from its first line, including comments directly above it, to the line
+// that closes its braces. Other top-level lines (syntax, package, imports,
+// scalars) are grouped until the next blank line.
+func schemaSections(content, lineComment string) []Section {
+	lines := strings.SplitAfter(content, "\n")
+	var sections []Section
+	start := -1
+	depth := 0
+	opened := false
+
+	flush := func(end int) {
+		text := strings.Join(lines[start:end+1], "")
+		if strings.TrimSpace(text) != "" {
+			sections = append(sections, Section{Text: text, StartLine: start + 1, EndLine: end + 1})
+		}
+		start = -1
+		opened = false
+	}
+
+	for i, line := range lines {
+		if depth == 0 && strings.TrimSpace(line) == "" {
+			if start >= 0 && !opened {
+				flush(i - 1)
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		opens, closes := countBraces(line, lineComment)
+		if opens > 0 {
+			opened = true
+		}
+		depth = max(depth+opens-closes, 0)
+		if depth == 0 && opened {
+			flush(i)
+		}
+	}
+	if start >= 0 {
+		flush(len(lines) - 1)
+	}
+	return sections
+}
+
+// countBraces counts braces outside string literals and line comments.
+func countBraces(line, lineComment string) (opens, closes int) {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case strings.HasPrefix(line[i:], lineComment):
+			return opens, closes
+		case c == '{':
+			opens++
+		case c == '}':
+			closes++
+		}
+	}
+	return opens, closes
+}
+
+// Minified content heuristics. Name patterns such as .min.js catch most
+// bundles; these catch the ones that are not named that way.
+const (
+	minifiedMinSize       = 1024 // smaller files are cheap to index either way
+	minifiedAvgLineLength = 300
+	minifiedLongLine      = 1000
+	minifiedLongLineShare = 0.5 // share of bytes in lines over minifiedLongLine
+)
+
+// MinifiedHandler skips code and data files that look minified or generated
+// from their content rather than their name. Prose formats such as Markdown
+// are left alone, as they often have one long line per paragraph.
+type MinifiedHandler struct{}
+
+func (h *MinifiedHandler) Name() string { return HandlerMinified }
+
+func (h *MinifiedHandler) Supports(path string) bool {
+	return hasExtension(path, ".js", ".jsx", ".ts", ".tsx", ".css", ".scss", ".less", ".json", ".html", ".xml")
+}
+
+func (h *MinifiedHandler) Prepare(path, content string) (PreparedFile, error) {
+	if looksMinified(content) {
+		return PreparedFile{Skip: "minified"}, nil
+	}
+	return PreparedFile{}, nil
+}
+
+func looksMinified(content string) bool {
+	if len(content) < minifiedMinSize {
+		return false
+	}
+	nonBlank := 0
+	longBytes := 0
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		nonBlank++
+		if len(line) > minifiedLongLine {
+			longBytes += len(line)
+		}
+	}
+	if nonBlank == 0 {
+		return false
+	}
+	if len(content)/nonBlank > minifiedAvgLineLength {
+		return true
+	}
+	return float64(longBytes)/float64(len(content)) > minifiedLongLineShare
+}
+
+// maxDocumentSize is the size limit for PDF and DOCX files, which are much
+// larger than the text they hold.
+const maxDocumentSize = 20 * 1024 * 1024 // 20 MB
+
+// DocumentHandler extracts the text of PDF and DOCX files under Paths, or
+// anywhere in the project when Paths is empty. Each page becomes a section
+// that starts with its page number, and the page number stands in for line
+// numbers in search results.
+type DocumentHandler struct {
+	Paths []string // project-relative directories
+}
+
+func (h *DocumentHandler) Name() string { return HandlerDocuments }
+
+func (h *DocumentHandler) MaxFileSize() int64 { return maxDocumentSize }
+
+func (h *DocumentHandler) Supports(path string) bool {
+	if !docextract.Supported(path) {
+		return false
+	}
+	if len(h.Paths) == 0 {
+		return true
+	}
+	rel := filepath.ToSlash(path)
+	for _, dir := range h.Paths {
+		dir = strings.Trim(filepath.ToSlash(filepath.Clean(dir)), "/")
+		if dir == "." || dir == "" || rel == dir || strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *DocumentHandler) Prepare(path, content string) (PreparedFile, error) {
+	pages, err := docextract.Extract(path, []byte(content))
+	if err != nil {
+		return PreparedFile{}, err
+	}
+	if len(pages) == 0 {
+		return PreparedFile{Skip: "no extractable text"}, nil
+	}
+	sections := make([]Section, len(pages))
+	for i, page := range pages {
+		sections[i] = Section{
+			Text:      fmt.Sprintf("[Page %d]\n%s", page.Number, page.Text),
+			StartLine: page.Number,
+			EndLine:   page.Number,
+		}
+	}
+	return PreparedFile{Sections: sections}, nil
+}
@@ -0,0 +1,285 @@
+package indexer
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": [
+    "# Training\n",
+    "Fits the model."
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 1,
+   "metadata": {},
+   "outputs": [
+    {
+     "name": "stdout",
+     "output_type": "stream",
+     "text": ["OUTPUT_SHOULD_NOT_BE_INDEXED\n"]
+    }
+   ],
+   "source": [
+    "import numpy as np\n",
+    "model.fit(x)"
+   ]
+  },
+  {
+   "cell_type": "raw",
+   "metadata": {},
+   "source": "raw cell"
+  }
+ ],
+ "metadata": {"kernelspec": {"name": "python3"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestNotebookHandler_Prepare(t *testing.T) {
+	prepared, err := (&NotebookHandler{}).Prepare("train.ipynb", testNotebook)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if len(prepared.Sections) != 2 {
+		t.Fatalf("expected 2 sections (markdown and code), got %d: %+v", len(prepared.Sections), prepared.Sections)
+	}
+
+	md, code := prepared.Sections[0], prepared.Sections[1]
+	if md.Text != "# Training\nFits the model." {
+		t.Errorf("unexpected markdown text %q", md.Text)
+	}
+	if md.StartLine != 7 || md.EndLine != 9 {
+		t.Errorf("expected markdown lines 7-9, got %d-%d", md.StartLine, md.EndLine)
+	}
+	if code.Text != "import numpy as np\nmodel.fit(x)" {
+		t.Errorf("unexpected code text %q", code.Text)
+	}
+	if code.StartLine != 23 {
+		t.Errorf("expected code to start on line 23, got %d", code.StartLine)
+	}
+	for _, section := range prepared.Sections {
+		if strings.Contains(section.Text, "OUTPUT_SHOULD_NOT_BE_INDEXED") || strings.Contains(section.Text, "raw cell") {
+			t.Errorf("outputs and raw cells must not be indexed, got %q", section.Text)
+		}
+	}
+}
+
+func TestNotebookHandler_InvalidAndEmpty(t *testing.T) {
+	h := &NotebookHandler{}
+	if _, err := h.Prepare("bad.ipynb", "{not json"); err == nil {
+		t.Error("expected error for invalid notebook")
+	}
+	prepared, err := h.Prepare("empty.ipynb", `{"cells": [], "nbformat": 4}`)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if prepared.Skip == "" {
+		t.Error("expected notebook without cells to be skipped")
+	}
+}
+
+func TestSchemaHandler_SplitsTopLevelDefinitions(t *testing.T) {
+	proto := `syntax = "proto3";
+package demo;
+
+// User is a person.
+message User {
+  string name = 1; // "}" in a comment
+  Address address = 2;
+}
+
+service Users {
+  rpc Get(GetRequest) returns (User) {}
+}
+`
+	prepared, err := (&SchemaHandler{}).Prepare("demo.proto", proto)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	want := []struct{ start, end int }{{1, 2}, {4, 8}, {10, 12}}
+	if len(prepared.Sections) != len(want) {
+		t.Fatalf("expected %d sections, got %d: %+v", len(want), len(prepared.Sections), prepared.Sections)
+	}
+	for i, w := range want {
+		got := prepared.Sections[i]
+		if got.StartLine != w.start || got.EndLine != w.end {
+			t.Errorf("section %d: expected lines %d-%d, got %d-%d", i, w.start, w.end, got.StartLine, got.EndLine)
+		}
+	}
+	if !strings.HasPrefix(prepared.Sections[1].Text, "// User is a person.") {
+		t.Errorf("expected leading comment to stay with its message, got %q", prepared.Sections[1].Text)
+	}
+
+	graphql := "scalar Date\n\n# A query root.\ntype Query {\n  user(id: ID!): User\n}\n"
+	prepared, err = (&SchemaHandler{}).Prepare("schema.graphql", graphql)
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if len(prepared.Sections) != 2 || prepared.Sections[1].StartLine != 3 || prepared.Sections[1].EndLine != 6 {
+		t.Errorf("unexpected graphql sections: %+v", prepared.Sections)
+	}
+}
+
+func TestMinifiedHandler_Heuristics(t *testing.T) {
+	h := &MinifiedHandler{}
+	minified := "!function(){" + strings.Repeat("var a=1,b=2;", 400) + "}();\n"
+	prepared, _ := h.Prepare("vendor/lib.js", minified)
+	if prepared.Skip == "" {
+		t.Error("expected single-line bundle to be skipped")
+	}
+
+	var readable strings.Builder
+	for i := 0; i < 200; i++ {
+		readable.WriteString("function handler() { return 1; }\n")
+	}
+	prepared, _ = h.Prepare("src/app.js", readable.String())
+	if prepared.Skip != "" {
+		t.Errorf("expected readable source to be indexed, got skip %q", prepared.Skip)
+	}
+}
+
+func TestMinifiedHandler_Supports(t *testing.T) {
+	h := &MinifiedHandler{}
+	for _, path := range []string{"app.js", "app.ts", "site.css", "data.json", "page.html"} {
+		if !h.Supports(path) {
+			t.Errorf("expected %s to be checked for minification", path)
+		}
+	}
+	// Prose often has one long line per paragraph.
+	if h.Supports("README.md") {
+		t.Error("expected Markdown not to be checked for minification")
+	}
+}
+
+func TestChunker_ChunkSectionsPacksWholeSections(t *testing.T) {
+	c := NewChunker(16, 0) // 64 chars per chunk
+	sections := []Section{
+		{Text: "message A {\n  int32 a = 1;\n}\n", StartLine: 1, EndLine: 3},
+		{Text: "message B {\n  int32 b = 1;\n}\n", StartLine: 5, EndLine: 7},
+		{Text: "message C {\n  int32 c = 1;\n}\n", StartLine: 9, EndLine: 11},
+	}
+	chunks := c.ChunkSections("demo.proto", sections)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[0].StartLine != 1 || chunks[0].EndLine != 7 {
+		t.Errorf("expected first chunk to pack A and B (lines 1-7), got %d-%d", chunks[0].StartLine, chunks[0].EndLine)
+	}
+	if chunks[1].StartLine != 9 || chunks[1].EndLine != 11 {
+		t.Errorf("expected second chunk to hold C (lines 9-11), got %d-%d", chunks[1].StartLine, chunks[1].EndLine)
+	}
+	if !strings.HasPrefix(chunks[0].ID, "demo.proto_0_") || !strings.HasPrefix(chunks[1].ID, "demo.proto_1_") {
+		t.Errorf("expected sequential IDs, got %s and %s", chunks[0].ID, chunks[1].ID)
+	}
+	if !strings.HasPrefix(chunks[1].Content, "File: demo.proto\n\n") {
+		t.Errorf("expected file context prefix, got %q", chunks[1].Content)
+	}
+}
+
+func TestScanner_FileHandlers(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"train.ipynb":    testNotebook,
+		"schema.graphql": "type Query {\n  ping: String\n}\n",
+		"bundle.js":      strings.Repeat("x", 4000),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	ignore, err := NewIgnoreMatcher(tmpDir, nil, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	scanner := NewScanner(tmpDir, ignore)
+	scanned, _, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(scanned) != 1 || scanned[0].Path != "bundle.js" {
+		t.Fatalf("without handlers expected only bundle.js, got %+v", scanned)
+	}
+
+	scanner.SetFileHandlers(NewFileHandlers(&NotebookHandler{}, &SchemaHandler{}, &MinifiedHandler{}))
+	scanned, skipped, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	byPath := make(map[string]FileInfo)
+	for _, f := range scanned {
+		byPath[f.Path] = f
+	}
+	if len(byPath) != 2 || len(byPath["train.ipynb"].Sections) != 2 || len(byPath["schema.graphql"].Sections) != 1 {
+		t.Errorf("expected notebook and schema with sections, got %+v", scanned)
+	}
+	if len(skipped) != 1 || skipped[0] != "bundle.js (minified)" {
+		t.Errorf("expected bundle.js skipped as minified, got %v", skipped)
+	}
+
+	file, err := scanner.ScanFile("train.ipynb")
+	if err != nil || file == nil || len(file.Sections) != 2 {
+		t.Errorf("expected ScanFile to prepare the notebook, got %+v, %v", file, err)
+	}
+}
+
+func TestDocumentHandler_ScansDocsUnderConfiguredPaths(t *testing.T) {
+	var docx bytes.Buffer
+	zw := zip.NewWriter(&docx)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	w.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:r><w:t>Retry policy design</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:br w:type="page"/><w:t>Backoff details</w:t></w:r></w:p></w:body></w:document>`))
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+
+	tmpDir := t.TempDir()
+	for _, rel := range []string{"design/retry.docx", "vendor/other.docx"} {
+		path := filepath.Join(tmpDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, docx.Bytes(), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	ignore, err := NewIgnoreMatcher(tmpDir, nil, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	scanner := NewScanner(tmpDir, ignore)
+	scanner.SetFileHandlers(NewFileHandlers(&DocumentHandler{Paths: []string{"./design/"}}))
+	scanned, _, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(scanned) != 1 || scanned[0].Path != filepath.Join("design", "retry.docx") {
+		t.Fatalf("expected only design/retry.docx, got %+v", scanned)
+	}
+
+	sections := scanned[0].Sections
+	if len(sections) != 2 {
+		t.Fatalf("expected one section per page, got %+v", sections)
+	}
+	if sections[1].StartLine != 2 || sections[1].Text != "[Page 2]\nBackoff details" {
+		t.Errorf("unexpected page 2 section: %+v", sections[1])
+	}
+}
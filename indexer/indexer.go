@@ -4,21 +4,41 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"runtime"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/embedder"
 	"github.com/yoanbernabeu/grepai/framework"
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
 	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+	"golang.org/x/sync/errgroup"
 )
 
 type Indexer struct {
-	root          string
-	store         store.VectorStore
-	embedder      embedder.Embedder
-	chunker       *Chunker
-	scanner       *Scanner
-	processor     *framework.ProcessorRegistry
-	lastIndexTime time.Time
+	root           string
+	store          store.VectorStore
+	embedder       embedder.Embedder
+	docsEmbedder   embedder.Embedder // embeds documentation files when set
+	docsModel      string
+	contextualizer *Contextualizer // prepends generated context to chunks when set
+	chunker        *Chunker
+	scanner        *Scanner
+	processor      *framework.ProcessorRegistry
+	lastIndexTime  time.Time
+	serial         func() bool
+	budget         Budget
+	changeMode     string
+	priority       map[string]bool // files a pass indexes first
+	todos          *trace.TodoStore
+
+	mu       sync.Mutex
+	excluded map[string]bool        // files the budget left out of the last full pass
+	counted  map[string]countedFile // files the budget chunked, not yet indexed
+	usage    budgetUsage
 }
 
 type IndexStats struct {
@@ -27,7 +47,9 @@ type IndexStats struct {
 	ChunksCreated int
 	FilesRemoved  int
 	Duration      time.Duration
-	ScannedFiles  []FileMeta // All files found during scan (for reuse by callers)
+	ScannedFiles  []FileMeta     // All files found during scan (for reuse by callers)
+	Excluded      []ExcludedFile // Files left out to stay within the budget
+	Prioritized   []string       // Priority files re-indexed (see SetPriorityFiles)
 }
 
 // ProgressInfo contains progress information for indexing
@@ -79,6 +101,71 @@ func NewIndexer(
 	}
 }
 
+// SetSerialEmbedding makes the indexer embed files one at a time, instead of
+// in concurrent cross-file batches, whenever serial returns true. The watcher
+// uses it to lower embedding load while throttled.
+func (idx *Indexer) SetSerialEmbedding(serial func() bool) {
+	idx.serial = serial
+}
+
+// SetChangeDetection sets how a pass tells which files changed since they
+// were indexed. Files that modification times do not rule out are read and
+// hashed, and only those whose content changed are indexed again. The hash
+// mode is for file systems with unreliable modification times, such as
+// Docker bind mounts or some NFS mounts. mode is one of the
+// config.ChangeDetection modes; empty means mtime+hash.
+func (idx *Indexer) SetChangeDetection(mode string) {
+	if mode == "" {
+		mode = config.ChangeDetectionMtimeHash
+	}
+	idx.changeMode = mode
+}
+
+// SetPriorityFiles makes passes re-index the given files, even when they
+// are unchanged, before the changed files, so that they are chunked and
+// embedded with the current settings. The watcher uses it for files the
+// index seems to represent poorly. Passing nil clears the priority files.
+func (idx *Indexer) SetPriorityFiles(paths []string) {
+	if len(paths) == 0 {
+		idx.priority = nil
+		return
+	}
+	priority := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		priority[path] = true
+	}
+	idx.priority = priority
+}
+
+// prioritize moves the priority files of files first, keeping the order of
+// the others.
+func (idx *Indexer) prioritize(files []FileInfo) {
+	if len(idx.priority) == 0 {
+		return
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return idx.priority[files[i].Path] && !idx.priority[files[j].Path]
+	})
+}
+
+// unchangedByModTime reports whether the modification time of a file shows
+// it unchanged since doc was indexed, so its content need not be read.
+// Documents without chunks are always checked again: a prior run may have
+// created the document but failed to embed it.
+func (idx *Indexer) unchangedByModTime(doc *store.Document, meta FileMeta) bool {
+	if doc == nil || len(doc.ChunkIDs) == 0 || idx.priority[meta.Path] {
+		return false
+	}
+	switch idx.changeMode {
+	case config.ChangeDetectionHash:
+		return false
+	case config.ChangeDetectionMtime:
+		return !doc.ModTime.IsZero() && doc.ModTime.Unix() == meta.ModTime
+	default:
+		return !idx.lastIndexTime.IsZero() && !time.Unix(meta.ModTime, 0).After(idx.lastIndexTime)
+	}
+}
+
 // IndexAll performs a full index of the project (no progress reporting)
 func (idx *Indexer) IndexAll(ctx context.Context) (*IndexStats, error) {
 	return idx.IndexAllWithProgress(ctx, nil)
@@ -103,19 +190,95 @@ func (idx *Indexer) IndexAllWithBatchProgress(ctx context.Context, onProgress Pr
 	stats.FilesSkipped = len(skipped)
 	stats.ScannedFiles = fileMetas
 
-	// Get existing documents
+	kept, excluded, err := idx.ApplyBudget(ctx, fileMetas)
+	if err != nil {
+		return nil, err
+	}
+	stats.Excluded = excluded
+
+	if err := idx.indexFileMetas(ctx, kept, stats, onProgress, onBatchProgress); err != nil {
+		return nil, err
+	}
+
+	removed, err := idx.RemoveMissingFiles(ctx, fileMetas)
+	if err != nil {
+		return nil, err
+	}
+	stats.FilesRemoved = removed
+	idx.persistTodos(ctx)
+
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// RemoveMissingFiles removes indexed documents whose files are not in files,
+// the result of a full scan, and returns how many were removed.
+func (idx *Indexer) RemoveMissingFiles(ctx context.Context, files []FileMeta) (int, error) {
 	existingDocs, err := idx.store.ListDocuments(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list documents: %w", err)
+		return 0, fmt.Errorf("failed to list documents: %w", err)
 	}
 
 	existingMap := make(map[string]bool)
 	for _, doc := range existingDocs {
 		existingMap[doc] = true
 	}
+	for _, file := range files {
+		delete(existingMap, file.Path)
+	}
+
+	paths := make([]string, 0, len(existingMap))
+	for path := range existingMap {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return idx.RemoveFiles(ctx, paths)
+}
 
-	// Filter files that need indexing
-	filesToIndex := make([]FileInfo, 0, len(fileMetas))
+// RemoveFiles removes files from the index, deleting their chunks in one
+// batch, and returns how many were removed.
+func (idx *Indexer) RemoveFiles(ctx context.Context, paths []string) (int, error) {
+	if len(paths) == 0 {
+		return 0, nil
+	}
+	if err := idx.store.DeleteByFiles(ctx, paths); err != nil {
+		return 0, fmt.Errorf("failed to delete chunks: %w", err)
+	}
+
+	idx.removeTodos(paths...)
+	idx.releaseChunks(paths...)
+
+	removed := 0
+	for _, path := range paths {
+		if err := idx.store.DeleteDocument(ctx, path); err != nil {
+			log.Printf("Failed to remove %s: %v", path, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// IndexFilesWithBatchProgress indexes the given files when they are new or
+// changed, without removing documents for files that are not listed. It is
+// used for partial passes such as the watch quick start.
+func (idx *Indexer) IndexFilesWithBatchProgress(ctx context.Context, files []FileMeta, onProgress ProgressCallback, onBatchProgress BatchProgressCallback) (*IndexStats, error) {
+	start := time.Now()
+	stats := &IndexStats{ScannedFiles: files}
+	if err := idx.indexFileMetas(ctx, files, stats, onProgress, onBatchProgress); err != nil {
+		return nil, err
+	}
+	idx.persistTodos(ctx)
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// indexFileMetas loads the files that need indexing and embeds them,
+// accumulating counts into stats.
+func (idx *Indexer) indexFileMetas(ctx context.Context, fileMetas []FileMeta, stats *IndexStats, onProgress ProgressCallback, onBatchProgress BatchProgressCallback) error {
+	// Filter out files whose modification time shows them unchanged
+	candidates := make([]FileMeta, 0, len(fileMetas))
+	docs := make([]*store.Document, 0, len(fileMetas))
 	for i, fileMeta := range fileMetas {
 		// Report progress for scanning phase
 		if onProgress != nil {
@@ -126,59 +289,56 @@ func (idx *Indexer) IndexAllWithBatchProgress(ctx context.Context, onProgress Pr
 			})
 		}
 
-		// Fetch the document once — used by both the mod-time gate and hash check.
 		doc, err := idx.store.GetDocument(ctx, fileMeta.Path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get document %s: %w", fileMeta.Path, err)
+			return fmt.Errorf("failed to get document %s: %w", fileMeta.Path, err)
 		}
-
-		// Skip files modified before lastIndexTime — but only if they have chunks.
-		// Files with no chunks need re-indexing even if their mod_time is old
-		// (e.g., a prior indexing run created the document but failed to embed).
-		if !idx.lastIndexTime.IsZero() && doc != nil && len(doc.ChunkIDs) > 0 {
-			fileModTime := time.Unix(fileMeta.ModTime, 0)
-			if fileModTime.Before(idx.lastIndexTime) || fileModTime.Equal(idx.lastIndexTime) {
-				stats.FilesSkipped++
-				delete(existingMap, fileMeta.Path)
-				continue
-			}
-		}
-
-		// Load file content and hash only after metadata filtering.
-		file, err := idx.scanner.ScanFile(fileMeta.Path)
-		if err != nil {
-			log.Printf("Failed to scan %s: %v", fileMeta.Path, err)
+		if idx.unchangedByModTime(doc, fileMeta) && idx.todosCurrent(fileMeta.Path) {
 			stats.FilesSkipped++
-			delete(existingMap, fileMeta.Path)
-			continue
-		}
-		if file == nil {
-			stats.FilesSkipped++
-			delete(existingMap, fileMeta.Path)
 			continue
 		}
+		candidates = append(candidates, fileMeta)
+		docs = append(docs, doc)
+	}
 
-		if doc != nil && doc.Hash == file.Hash && len(doc.ChunkIDs) > 0 {
-			delete(existingMap, fileMeta.Path)
-			continue // File unchanged and has chunks
+	// Load content and hash only after metadata filtering.
+	filesToIndex, todoFiles, skipped := idx.scanChangedFiles(ctx, candidates, docs)
+	stats.FilesSkipped += skipped
+	idx.prioritize(filesToIndex)
+	for _, file := range filesToIndex {
+		if idx.priority[file.Path] {
+			stats.Prioritized = append(stats.Prioritized, file.Path)
 		}
+	}
 
-		filesToIndex = append(filesToIndex, *file)
-		delete(existingMap, fileMeta.Path)
+	for _, group := range idx.embeddingGroups(filesToIndex) {
+		if err := idx.embedFiles(ctx, group.emb, group.files, stats, onBatchProgress); err != nil {
+			return err
+		}
 	}
+	idx.indexTodos(ctx, append(filesToIndex, todoFiles...))
 
-	// Index files using batch processing if available, otherwise sequentially
-	if batchEmbedder, ok := idx.embedder.(embedder.BatchEmbedder); ok && len(filesToIndex) > 0 {
-		indexed, chunks, err := idx.indexFilesBatched(ctx, filesToIndex, batchEmbedder, onBatchProgress)
+	return nil
+}
+
+// embedFiles indexes files whose chunks are embedded with emb, using batch
+// processing if emb supports it, otherwise sequentially.
+func (idx *Indexer) embedFiles(ctx context.Context, emb embedder.Embedder, files []FileInfo, stats *IndexStats, onBatchProgress BatchProgressCallback) error {
+	batchEmbedder, batched := emb.(embedder.BatchEmbedder)
+	if batched && idx.serial != nil && idx.serial() {
+		batched = false
+	}
+	if batched && len(files) > 0 {
+		indexed, chunks, err := idx.indexFilesBatched(ctx, files, batchEmbedder, onBatchProgress)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		stats.FilesIndexed = indexed
-		stats.ChunksCreated = chunks
-	} else if len(filesToIndex) > 0 {
+		stats.FilesIndexed += indexed
+		stats.ChunksCreated += chunks
+	} else if len(files) > 0 {
 		// Sequential indexing for non-batch embedders (e.g., Ollama)
-		total := len(filesToIndex)
-		for i, file := range filesToIndex {
+		total := len(files)
+		for i, file := range files {
 			if onBatchProgress != nil {
 				onBatchProgress(BatchProgressInfo{
 					BatchIndex:      i,
@@ -205,17 +365,7 @@ func (idx *Indexer) IndexAllWithBatchProgress(ctx context.Context, onProgress Pr
 		}
 	}
 
-	// Remove deleted files
-	for path := range existingMap {
-		if err := idx.RemoveFile(ctx, path); err != nil {
-			log.Printf("Failed to remove %s: %v", path, err)
-			continue
-		}
-		stats.FilesRemoved++
-	}
-
-	stats.Duration = time.Since(start)
-	return stats, nil
+	return nil
 }
 
 // fileChunkData holds chunking information for a single file during batch processing.
@@ -225,9 +375,13 @@ type fileChunkData struct {
 	chunkInfos []ChunkInfo
 	lineMap    []int
 	source     string
+	previous   map[string]store.Chunk // chunks of the replaced version, by content hash
+	model      string                 // docs model tag, empty for the main embedder
 }
 
-// prepareFileChunks processes files by deleting existing chunks and creating new chunks.
+// prepareFileChunks chunks files for embedding. The existing chunks of a file
+// stay in the store until its new chunks replace them, except for files that
+// no longer produce any chunks, whose chunks are deleted here.
 // Returns the file data for storage and the file chunks for embedding.
 func (idx *Indexer) prepareFileChunks(
 	ctx context.Context,
@@ -237,27 +391,30 @@ func (idx *Indexer) prepareFileChunks(
 	fileChunks := make([]embedder.FileChunks, 0, len(files))
 
 	for i, file := range files {
-		if err := idx.store.DeleteByFile(ctx, file.Path); err != nil {
-			return nil, nil, fmt.Errorf("failed to delete existing chunks for %s: %w", file.Path, err)
-		}
+		previous := idx.previousChunks(ctx, file.Path)
 
-		embedContent, lineMap := idx.embeddingContent(ctx, file)
-		chunkInfos := idx.chunker.ChunkWithContext(file.Path, embedContent)
+		chunkInfos, lineMap := idx.chunkFile(ctx, file)
 		if len(chunkInfos) == 0 {
+			if err := idx.store.DeleteByFile(ctx, file.Path); err != nil {
+				return nil, nil, fmt.Errorf("failed to delete existing chunks for %s: %w", file.Path, err)
+			}
 			continue
 		}
 
 		contents := make([]string, len(chunkInfos))
 		for j, c := range chunkInfos {
-			contents[j] = c.EmbedContent
+			contents[j] = c.embeddingText()
 		}
 
+		_, model := idx.embedderFor(file.Path)
 		fileData = append(fileData, fileChunkData{
 			fileIndex:  i,
 			file:       file,
 			chunkInfos: chunkInfos,
 			lineMap:    lineMap,
 			source:     file.Content,
+			previous:   previous,
+			model:      model,
 		})
 
 		fileChunks = append(fileChunks, embedder.FileChunks{
@@ -269,8 +426,66 @@ func (idx *Indexer) prepareFileChunks(
 	return fileData, fileChunks, nil
 }
 
-// createStoreChunks creates store.Chunk objects from chunk info and embeddings.
-func createStoreChunks(chunkInfos []ChunkInfo, embeddings [][]float32, now time.Time) ([]store.Chunk, []string) {
+// scanChangedFiles reads and hashes files concurrently and returns those
+// whose content differs from their document, or that have no chunks yet,
+// in the order of files, then the unchanged files whose todos are not in
+// the todo store, and the number of unreadable or skipped files. Other
+// unchanged content is released as soon as it is hashed.
+func (idx *Indexer) scanChangedFiles(ctx context.Context, files []FileMeta, docs []*store.Document) ([]FileInfo, []FileInfo, int) {
+	scanned := make([]*FileInfo, len(files))
+	todoOnly := make([]*FileInfo, len(files))
+	skipped := make([]bool, len(files))
+
+	var g errgroup.Group
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for i, meta := range files {
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+			file, err := idx.scanner.ScanFile(meta.Path)
+			if err != nil {
+				log.Printf("Failed to scan %s: %v", meta.Path, err)
+				skipped[i] = true
+				return nil
+			}
+			if file == nil {
+				skipped[i] = true
+				return nil
+			}
+			if doc := docs[i]; doc != nil && doc.Hash == file.Hash && len(doc.ChunkIDs) > 0 && !idx.priority[meta.Path] {
+				// File unchanged and has chunks
+				if idx.todos != nil && !idx.todos.Current(file.Path, file.Hash) {
+					todoOnly[i] = file
+				}
+				return nil
+			}
+			scanned[i] = file
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	changed := make([]FileInfo, 0, len(files))
+	var todoFiles []FileInfo
+	count := 0
+	for i, file := range scanned {
+		if skipped[i] {
+			count++
+		}
+		if file != nil {
+			changed = append(changed, *file)
+		}
+		if todoOnly[i] != nil {
+			todoFiles = append(todoFiles, *todoOnly[i])
+		}
+	}
+	return changed, todoFiles, count
+}
+
+// createStoreChunks creates store.Chunk objects from chunk info and
+// embeddings, tagged with the docs model when they were embedded with it.
+func createStoreChunks(chunkInfos []ChunkInfo, embeddings [][]float32, model string, now time.Time) ([]store.Chunk, []string) {
 	chunks := make([]store.Chunk, len(chunkInfos))
 	chunkIDs := make([]string, len(chunkInfos))
 
@@ -284,6 +499,7 @@ func createStoreChunks(chunkInfos []ChunkInfo, embeddings [][]float32, now time.
 			Vector:      embeddings[i],
 			Hash:        info.Hash,
 			ContentHash: info.ContentHash,
+			Model:       model,
 			UpdatedAt:   now,
 		}
 		chunkIDs[i] = info.ID
@@ -292,12 +508,8 @@ func createStoreChunks(chunkInfos []ChunkInfo, embeddings [][]float32, now time.
 	return chunks, chunkIDs
 }
 
-// saveFileData saves chunks and document metadata for a single file.
+// saveFileData replaces the chunks and document metadata of a single file.
 func (idx *Indexer) saveFileData(ctx context.Context, fd fileChunkData, chunks []store.Chunk, chunkIDs []string) error {
-	if err := idx.store.SaveChunks(ctx, chunks); err != nil {
-		return fmt.Errorf("failed to save chunks for %s: %w", fd.file.Path, err)
-	}
-
 	doc := store.Document{
 		Path:     fd.file.Path,
 		Hash:     fd.file.Hash,
@@ -305,8 +517,8 @@ func (idx *Indexer) saveFileData(ctx context.Context, fd fileChunkData, chunks [
 		ChunkIDs: chunkIDs,
 	}
 
-	if err := idx.store.SaveDocument(ctx, doc); err != nil {
-		return fmt.Errorf("failed to save document for %s: %w", fd.file.Path, err)
+	if err := store.ReplaceFile(ctx, idx.store, doc, chunks); err != nil {
+		return fmt.Errorf("failed to save chunks for %s: %w", fd.file.Path, err)
 	}
 
 	return nil
@@ -348,77 +560,58 @@ func (idx *Indexer) indexFilesBatched(
 		return 0, 0, nil
 	}
 
-	// Check embedding cache for content-addressed deduplication
-	cache, hasCache := idx.store.(store.EmbeddingCache)
-	var totalCacheHits int
-
-	// Pre-fill cached embeddings and filter out fully-cached files
-	type preFilled struct {
-		fdIndex   int
-		vectors   [][]float32
-		allCached bool
+	// Reuse embeddings of unchanged chunks and cached content, and embed
+	// only the remaining chunks.
+	cache, _ := idx.store.(store.EmbeddingCache)
+	type pendingFile struct {
+		fd      fileChunkData
+		vectors [][]float32
+		missing []int // chunk indexes that still need embedding
 	}
 
-	var preFilledFiles []preFilled
-	var remainingFileData []fileChunkData
-	var remainingFileChunks []embedder.FileChunks
+	var pending []pendingFile
+	var toEmbed []embedder.FileChunks
+	var totalReused int
+	now := time.Now()
 
 	for i, fd := range fileData {
-		if !hasCache {
-			remainingFileData = append(remainingFileData, fd)
-			remainingFileChunks = append(remainingFileChunks, fileChunks[i])
-			continue
-		}
-
 		vecs := make([][]float32, len(fd.chunkInfos))
-		allCached := true
+		var missing []int
 		for j, chunk := range fd.chunkInfos {
-			if chunk.ContentHash == "" {
-				allCached = false
-				continue
-			}
-			vec, found, err := cache.LookupByContentHash(ctx, chunk.ContentHash)
-			if err != nil {
-				log.Printf("Warning: cache lookup failed: %v", err)
-				allCached = false
-				continue
-			}
-			if found {
+			if vec, ok := idx.reusableVector(ctx, chunk, fd.previous, cache); ok {
 				vecs[j] = vec
-				totalCacheHits++
+				totalReused++
 			} else {
-				allCached = false
+				missing = append(missing, j)
 			}
 		}
 
-		if allCached {
-			preFilledFiles = append(preFilledFiles, preFilled{fdIndex: i, vectors: vecs, allCached: true})
-		} else {
-			remainingFileData = append(remainingFileData, fd)
-			remainingFileChunks = append(remainingFileChunks, fileChunks[i])
+		if len(missing) == 0 {
+			// Save fully reused files immediately
+			n, err := idx.saveEmbeddedFile(ctx, fd, vecs, now)
+			if err != nil {
+				return filesIndexed, chunksCreated, err
+			}
+			filesIndexed++
+			chunksCreated += n
+			continue
 		}
-	}
 
-	if totalCacheHits > 0 {
-		log.Printf("Reused %d cached embeddings across %d files", totalCacheHits, len(preFilledFiles))
+		contents := make([]string, len(missing))
+		for k, j := range missing {
+			contents[k] = fileChunks[i].Chunks[j]
+		}
+		pending = append(pending, pendingFile{fd: fd, vectors: vecs, missing: missing})
+		toEmbed = append(toEmbed, embedder.FileChunks{FileIndex: fd.fileIndex, Chunks: contents})
 	}
 
-	// Save fully-cached files immediately
-	now := time.Now()
-	for _, pf := range preFilledFiles {
-		fd := fileData[pf.fdIndex]
-		idx.remapChunksToSource(fd.chunkInfos, fd.file.Path, fd.source, fd.lineMap)
-		chunks, chunkIDs := createStoreChunks(fd.chunkInfos, pf.vectors, now)
-		if err := idx.saveFileData(ctx, fd, chunks, chunkIDs); err != nil {
-			return filesIndexed, chunksCreated, err
-		}
-		filesIndexed++
-		chunksCreated += len(chunks)
+	if totalReused > 0 {
+		log.Printf("Reused %d existing embeddings across %d files", totalReused, len(fileData))
 	}
 
-	// Embed remaining (non-cached) files
-	if len(remainingFileChunks) > 0 {
-		batches := embedder.FormBatches(remainingFileChunks)
+	// Embed remaining chunks
+	if len(toEmbed) > 0 {
+		batches := embedder.FormBatches(toEmbed)
 		results, err := batchEmb.EmbedBatches(ctx, batches, wrapBatchProgress(onProgress))
 		if err != nil {
 			return filesIndexed, chunksCreated, fmt.Errorf("failed to embed batches: %w", err)
@@ -426,49 +619,77 @@ func (idx *Indexer) indexFilesBatched(
 
 		fileEmbeddings := embedder.MapResultsToFiles(batches, results, len(files))
 
-		for _, fd := range remainingFileData {
-			embeddings := fileEmbeddings[fd.fileIndex]
-			if len(embeddings) != len(fd.chunkInfos) {
+		for _, pf := range pending {
+			embeddings := fileEmbeddings[pf.fd.fileIndex]
+			if len(embeddings) != len(pf.missing) {
 				log.Printf("Warning: embedding count mismatch for %s: got %d, expected %d",
-					fd.file.Path, len(embeddings), len(fd.chunkInfos))
+					pf.fd.file.Path, len(embeddings), len(pf.missing))
 				continue
 			}
-			idx.remapChunksToSource(fd.chunkInfos, fd.file.Path, fd.source, fd.lineMap)
-			chunks, chunkIDs := createStoreChunks(fd.chunkInfos, embeddings, now)
-			if err := idx.saveFileData(ctx, fd, chunks, chunkIDs); err != nil {
+			for k, j := range pf.missing {
+				pf.vectors[j] = embeddings[k]
+			}
+			n, err := idx.saveEmbeddedFile(ctx, pf.fd, pf.vectors, now)
+			if err != nil {
 				return filesIndexed, chunksCreated, err
 			}
 			filesIndexed++
-			chunksCreated += len(chunks)
+			chunksCreated += n
 		}
 	}
 
 	return filesIndexed, chunksCreated, nil
 }
 
+// saveEmbeddedFile maps a file's chunks back to its source, keeps the IDs of
+// unchanged chunks and saves the chunks and document.
+func (idx *Indexer) saveEmbeddedFile(ctx context.Context, fd fileChunkData, vectors [][]float32, now time.Time) (int, error) {
+	if fd.file.Sections == nil {
+		idx.remapChunksToSource(fd.chunkInfos, fd.file.Path, fd.source, fd.lineMap)
+	}
+	preserveChunkIDs(fd.chunkInfos, fd.previous)
+	chunks, chunkIDs := createStoreChunks(fd.chunkInfos, vectors, fd.model, now)
+	if err := idx.saveFileData(ctx, fd, chunks, chunkIDs); err != nil {
+		return 0, err
+	}
+	return len(chunks), nil
+}
+
 // maxReChunkAttempts is the maximum number of times we'll try to re-chunk
 // before giving up on a file.
 const maxReChunkAttempts = 3
 
 // IndexFile indexes a single file
 func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
-	// Remove existing chunks for this file
-	if err := idx.store.DeleteByFile(ctx, file.Path); err != nil {
-		return 0, fmt.Errorf("failed to delete existing chunks: %w", err)
+	if idx.budgetExcluded(file.Path) {
+		return 0, nil
+	}
+	idx.indexTodos(ctx, []FileInfo{file})
+
+	previous := idx.previousChunks(ctx, file.Path)
+	emb, model := idx.embedderFor(file.Path)
+
+	// Chunk the file. Its existing chunks are replaced once the new ones are
+	// embedded, or removed now if there are none.
+	chunkInfos, lineMap := idx.chunkFile(ctx, file)
+	if reason := idx.admitChunks(file.Path, len(chunkInfos)); reason != "" {
+		log.Printf("Index budget: leaving out %s (%d chunks) until the next full pass: %s", file.Path, len(chunkInfos), reason)
+		if err := idx.RemoveFile(ctx, file.Path); err != nil {
+			return 0, err
+		}
+		return 0, nil
 	}
-
-	embedContent, lineMap := idx.embeddingContent(ctx, file)
-
-	// Chunk the file
-	chunkInfos := idx.chunker.ChunkWithContext(file.Path, embedContent)
 	if len(chunkInfos) == 0 {
+		if err := idx.store.DeleteByFile(ctx, file.Path); err != nil {
+			return 0, fmt.Errorf("failed to delete existing chunks: %w", err)
+		}
 		return 0, nil
 	}
 
-	// Check embedding cache for content-addressed deduplication
-	cachedVectors, cacheHits := idx.lookupCachedEmbeddings(ctx, chunkInfos)
+	// Reuse embeddings of unchanged chunks and cached content
+	cachedVectors, cacheHits := idx.lookupCachedEmbeddings(ctx, chunkInfos, previous)
 	if cacheHits > 0 {
-		log.Printf("Reused %d cached embeddings for %s", cacheHits, file.Path)
+		log.Printf("Reused %d existing embeddings for %s", cacheHits, file.Path)
 	}
 
 	// Separate cached and uncached chunks
@@ -484,7 +705,7 @@ func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
 	var finalUncachedChunks []ChunkInfo
 	if len(uncachedChunks) > 0 {
 		var err error
-		uncachedVectors, finalUncachedChunks, err = idx.embedWithReChunking(ctx, uncachedChunks)
+		uncachedVectors, finalUncachedChunks, err = idx.embedWithReChunking(ctx, emb, model, uncachedChunks)
 		if err != nil {
 			return 0, fmt.Errorf("failed to embed chunks: %w", err)
 		}
@@ -535,34 +756,15 @@ func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
 		}
 	}
 
-	idx.remapChunksToSource(finalChunks, file.Path, file.Content, lineMap)
-
-	// Create store chunks
-	now := time.Now()
-	chunks := make([]store.Chunk, len(finalChunks))
-	chunkIDs := make([]string, len(finalChunks))
-
-	for i, info := range finalChunks {
-		chunks[i] = store.Chunk{
-			ID:          info.ID,
-			FilePath:    info.FilePath,
-			StartLine:   info.StartLine,
-			EndLine:     info.EndLine,
-			Content:     info.Content,
-			Vector:      vectors[i],
-			Hash:        info.Hash,
-			ContentHash: info.ContentHash,
-			UpdatedAt:   now,
-		}
-		chunkIDs[i] = info.ID
+	if file.Sections == nil {
+		idx.remapChunksToSource(finalChunks, file.Path, file.Content, lineMap)
 	}
+	preserveChunkIDs(finalChunks, previous)
 
-	// Save chunks
-	if err := idx.store.SaveChunks(ctx, chunks); err != nil {
-		return 0, fmt.Errorf("failed to save chunks: %w", err)
-	}
+	// Create store chunks
+	chunks, chunkIDs := createStoreChunks(finalChunks, vectors, model, time.Now())
 
-	// Save document metadata
+	// Replace the file's chunks and document metadata
 	doc := store.Document{
 		Path:     file.Path,
 		Hash:     file.Hash,
@@ -570,16 +772,17 @@ func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
 		ChunkIDs: chunkIDs,
 	}
 
-	if err := idx.store.SaveDocument(ctx, doc); err != nil {
-		return 0, fmt.Errorf("failed to save document: %w", err)
+	if err := store.ReplaceFile(ctx, idx.store, doc, chunks); err != nil {
+		return 0, fmt.Errorf("failed to save chunks: %w", err)
 	}
 
 	return len(chunks), nil
 }
 
-// embedWithReChunking attempts to embed chunks, automatically re-chunking
-// any chunks that exceed the embedder's context limit.
-func (idx *Indexer) embedWithReChunking(ctx context.Context, chunks []ChunkInfo) ([][]float32, []ChunkInfo, error) {
+// embedWithReChunking attempts to embed chunks with emb, automatically
+// re-chunking any chunks that exceed the embedder's context limit. Sub-chunks
+// are tagged with the docs model of emb, if any.
+func (idx *Indexer) embedWithReChunking(ctx context.Context, emb embedder.Embedder, model string, chunks []ChunkInfo) ([][]float32, []ChunkInfo, error) {
 	currentChunks := chunks
 	var allVectors [][]float32
 	var finalChunks []ChunkInfo
@@ -587,14 +790,10 @@ func (idx *Indexer) embedWithReChunking(ctx context.Context, chunks []ChunkInfo)
 	for attempt := 0; attempt < maxReChunkAttempts; attempt++ {
 		contents := make([]string, len(currentChunks))
 		for i, c := range currentChunks {
-			if c.EmbedContent != "" {
-				contents[i] = c.EmbedContent
-			} else {
-				contents[i] = c.Content
-			}
+			contents[i] = c.embeddingText()
 		}
 
-		vectors, err := idx.embedder.EmbedBatch(ctx, contents)
+		vectors, err := emb.EmbedBatch(ctx, contents)
 		if err == nil {
 			// Success! Append all results
 			allVectors = append(allVectors, vectors...)
@@ -623,13 +822,9 @@ func (idx *Indexer) embedWithReChunking(ctx context.Context, chunks []ChunkInfo)
 		if failedIndex > 0 {
 			beforeContents := make([]string, failedIndex)
 			for i := 0; i < failedIndex; i++ {
-				if currentChunks[i].EmbedContent != "" {
-					beforeContents[i] = currentChunks[i].EmbedContent
-				} else {
-					beforeContents[i] = currentChunks[i].Content
-				}
+				beforeContents[i] = currentChunks[i].embeddingText()
 			}
-			beforeVectors, err := idx.embedder.EmbedBatch(ctx, beforeContents)
+			beforeVectors, err := emb.EmbedBatch(ctx, beforeContents)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to embed chunks before failed index: %w", err)
 			}
@@ -644,6 +839,7 @@ func (idx *Indexer) embedWithReChunking(ctx context.Context, chunks []ChunkInfo)
 		}
 
 		log.Printf("Split chunk into %d sub-chunks", len(subChunks))
+		tagContentHashes(subChunks, model)
 
 		// Prepare for next iteration: sub-chunks + remaining chunks
 		currentChunks = append(subChunks, currentChunks[failedIndex+1:]...)
@@ -652,6 +848,33 @@ func (idx *Indexer) embedWithReChunking(ctx context.Context, chunks []ChunkInfo)
 	return nil, nil, fmt.Errorf("exceeded maximum re-chunk attempts (%d) for file", maxReChunkAttempts)
 }
 
+// chunkFile chunks a file, using the sections a file handler prepared when
+// there are any. The line map is nil for sectioned files, whose chunks
+// already carry source line numbers. The content hashes of documentation
+// chunks embedded with the docs embedder are tagged with its model.
+func (idx *Indexer) chunkFile(ctx context.Context, file FileInfo) ([]ChunkInfo, []int) {
+	chunks, lineMap, ok := idx.takeCounted(file)
+	if !ok {
+		chunks, lineMap = idx.chunkContent(ctx, file)
+	}
+	idx.contextualizer.contextualize(ctx, file, chunks, lineMap)
+	_, model := idx.embedderFor(file.Path)
+	tagContentHashes(chunks, model)
+	return chunks, lineMap
+}
+
+// chunkContent splits the content of file into chunks.
+func (idx *Indexer) chunkContent(ctx context.Context, file FileInfo) ([]ChunkInfo, []int) {
+	if file.Sections != nil {
+		return idx.chunker.ChunkSections(file.Path, file.Sections), nil
+	}
+	embedContent, lineMap := idx.embeddingContent(ctx, file)
+	if language := langdetect.Language(file.LanguageExt); language != "" {
+		return idx.chunker.ChunkWithLanguage(file.Path, language, embedContent), lineMap
+	}
+	return idx.chunker.ChunkWithContext(file.Path, embedContent), lineMap
+}
+
 func (idx *Indexer) embeddingContent(ctx context.Context, file FileInfo) (string, []int) {
 	if idx.processor == nil {
 		return file.Content, nil
@@ -682,26 +905,40 @@ func (idx *Indexer) remapChunksToSource(chunks []ChunkInfo, filePath, source str
 	}
 }
 
-// lookupCachedEmbeddings checks if the store implements EmbeddingCache and returns
-// cached vectors for chunks with matching content hashes. The returned map maps
-// chunk index to cached vector. Chunks not in the map need fresh embedding.
-func (idx *Indexer) lookupCachedEmbeddings(ctx context.Context, chunks []ChunkInfo) (map[int][]float32, int) {
-	cache, ok := idx.store.(store.EmbeddingCache)
-	if !ok {
-		return nil, 0
+// previousChunks returns the stored chunks of a file by content hash, read
+// before they are replaced, so unchanged chunks of a modified file keep their
+// IDs and vectors instead of being embedded again.
+func (idx *Indexer) previousChunks(ctx context.Context, path string) map[string]store.Chunk {
+	chunks, err := idx.store.GetChunksForFile(ctx, path)
+	if err != nil {
+		log.Printf("Warning: failed to load previous chunks for %s: %v", path, err)
+		return nil
 	}
-
-	cached := make(map[int][]float32)
-	for i, chunk := range chunks {
+	if len(chunks) == 0 {
+		return nil
+	}
+	previous := make(map[string]store.Chunk, len(chunks))
+	for _, chunk := range chunks {
 		if chunk.ContentHash == "" {
 			continue
 		}
-		vec, found, err := cache.LookupByContentHash(ctx, chunk.ContentHash)
-		if err != nil {
-			log.Printf("Warning: cache lookup failed for content hash %s: %v", chunk.ContentHash[:8], err)
-			continue
+		if _, exists := previous[chunk.ContentHash]; !exists {
+			previous[chunk.ContentHash] = chunk
 		}
-		if found {
+	}
+	return previous
+}
+
+// lookupCachedEmbeddings returns existing vectors for chunks, from the file's
+// previous chunks or, when the store implements EmbeddingCache, from chunks
+// with the same content anywhere in the store. The returned map maps chunk
+// index to vector. Chunks not in the map need fresh embedding.
+func (idx *Indexer) lookupCachedEmbeddings(ctx context.Context, chunks []ChunkInfo, previous map[string]store.Chunk) (map[int][]float32, int) {
+	cache, _ := idx.store.(store.EmbeddingCache)
+
+	cached := make(map[int][]float32)
+	for i, chunk := range chunks {
+		if vec, ok := idx.reusableVector(ctx, chunk, previous, cache); ok {
 			cached[i] = vec
 		}
 	}
@@ -709,11 +946,66 @@ func (idx *Indexer) lookupCachedEmbeddings(ctx context.Context, chunks []ChunkIn
 	return cached, len(cached)
 }
 
+// reusableVector returns an existing embedding for chunk: the vector of an
+// identical chunk in the file's previous version, or a cached one.
+func (idx *Indexer) reusableVector(ctx context.Context, chunk ChunkInfo, previous map[string]store.Chunk, cache store.EmbeddingCache) ([]float32, bool) {
+	if chunk.ContentHash == "" {
+		return nil, false
+	}
+	if prev, ok := previous[chunk.ContentHash]; ok && len(prev.Vector) > 0 {
+		return prev.Vector, true
+	}
+	if cache == nil {
+		return nil, false
+	}
+	vec, found, err := cache.LookupByContentHash(ctx, chunk.ContentHash)
+	if err != nil {
+		log.Printf("Warning: cache lookup failed for content hash %s: %v", chunk.ContentHash[:8], err)
+		return nil, false
+	}
+	return vec, found
+}
+
+// preserveChunkIDs gives chunks whose content is unchanged the ID they had in
+// the previous version of the file. Other chunks keep their new IDs, renumbered
+// past the end when one is already taken by a preserved chunk.
+func preserveChunkIDs(chunks []ChunkInfo, previous map[string]store.Chunk) {
+	if len(previous) == 0 {
+		return
+	}
+
+	used := make(map[string]bool, len(chunks))
+	preserved := make([]bool, len(chunks))
+	for i := range chunks {
+		prev, ok := previous[chunks[i].ContentHash]
+		if !ok || used[prev.ID] {
+			continue
+		}
+		chunks[i].ID = prev.ID
+		used[prev.ID] = true
+		preserved[i] = true
+	}
+
+	next := len(chunks)
+	for i := range chunks {
+		if preserved[i] {
+			continue
+		}
+		for used[chunks[i].ID] {
+			chunks[i].ID = ChunkID(chunks[i].FilePath, next, chunks[i].ContentHash)
+			next++
+		}
+		used[chunks[i].ID] = true
+	}
+}
+
 // RemoveFile removes a file from the index
 func (idx *Indexer) RemoveFile(ctx context.Context, path string) error {
 	if err := idx.store.DeleteByFile(ctx, path); err != nil {
 		return fmt.Errorf("failed to delete chunks: %w", err)
 	}
+	idx.removeTodos(path)
+	idx.releaseChunks(path)
 
 	if err := idx.store.DeleteDocument(ctx, path); err != nil {
 		return fmt.Errorf("failed to delete document: %w", err)
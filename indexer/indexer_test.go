@@ -2,6 +2,8 @@ package indexer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/embedder"
 	"github.com/yoanbernabeu/grepai/framework"
 	"github.com/yoanbernabeu/grepai/store"
@@ -55,6 +58,32 @@ func (m *mockStore) DeleteByFile(ctx context.Context, filePath string) error {
 	return nil
 }
 
+func (m *mockStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	for _, filePath := range filePaths {
+		if err := m.DeleteByFile(ctx, filePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockStore) DeleteChunks(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(m.chunks, id)
+	}
+	return nil
+}
+
+func (m *mockStore) DeleteByProject(ctx context.Context, project string) error {
+	for path := range m.documents {
+		if strings.HasPrefix(path, project+"/") {
+			_ = m.DeleteByFile(ctx, path)
+			delete(m.documents, path)
+		}
+	}
+	return nil
+}
+
 func (m *mockStore) Search(ctx context.Context, queryVector []float32, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
 	results := make([]store.SearchResult, 0, len(m.chunks))
 	for _, chunk := range m.chunks {
@@ -257,6 +286,55 @@ func TestIndexAllWithProgress_UnchangedFilesSkipped(t *testing.T) {
 	}
 }
 
+func TestIndexAllWithProgress_ChangeDetection(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.go")
+	if err := os.WriteFile(testFile, []byte("package main\n\nfunc main() {}"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("failed to get file info: %v", err)
+	}
+	fileModTime := time.Unix(info.ModTime().Unix(), 0)
+
+	// The file changed since it was indexed, but was last indexed after its
+	// modification time as the host clock saw it.
+	for _, tc := range []struct {
+		mode       string
+		docModTime time.Time
+		reindexed  bool
+	}{
+		{config.ChangeDetectionMtimeHash, fileModTime, false},
+		{config.ChangeDetectionMtimeHash, fileModTime.Add(-time.Hour), false},
+		{config.ChangeDetectionMtime, fileModTime, false},
+		{config.ChangeDetectionMtime, fileModTime.Add(-time.Hour), true},
+		{config.ChangeDetectionHash, fileModTime, true},
+	} {
+		mockStore := newMockStore()
+		mockStore.documents["test.go"] = store.Document{
+			Path:     "test.go",
+			Hash:     "stale-hash",
+			ModTime:  tc.docModTime,
+			ChunkIDs: []string{"chunk1"},
+		}
+		ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+		if err != nil {
+			t.Fatalf("failed to create ignore matcher: %v", err)
+		}
+		idx := NewIndexer(tmpDir, mockStore, newMockEmbedder(), NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Now().Add(time.Hour))
+		idx.SetChangeDetection(tc.mode)
+
+		stats, err := idx.IndexAllWithProgress(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("IndexAllWithProgress failed: %v", err)
+		}
+		if got := stats.FilesIndexed == 1; got != tc.reindexed {
+			t.Errorf("%s with document mtime %v: expected reindexed=%v, got %d files indexed", tc.mode, tc.docModTime, tc.reindexed, stats.FilesIndexed)
+		}
+	}
+}
+
 // TestIndexAllWithProgress_ChangedFilesIndexed tests that files with different ModTimes are re-indexed
 func TestIndexAllWithProgress_ChangedFilesIndexed(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -798,9 +876,9 @@ func TestPrepareFileChunks(t *testing.T) {
 			}
 		}
 
-		// Verify DeleteByFile was called
-		if !mockStore.delByFileCalled {
-			t.Error("expected DeleteByFile to be called")
+		// Existing chunks stay in the store until the new ones replace them
+		if mockStore.delByFileCalled {
+			t.Error("expected DeleteByFile not to be called before embedding")
 		}
 	})
 
@@ -852,7 +930,7 @@ func TestCreateStoreChunks(t *testing.T) {
 			{0.4, 0.5, 0.6},
 		}
 
-		chunks, chunkIDs := createStoreChunks(chunkInfos, embeddings, now)
+		chunks, chunkIDs := createStoreChunks(chunkInfos, embeddings, "", now)
 
 		if len(chunks) != 2 {
 			t.Fatalf("expected 2 chunks, got %d", len(chunks))
@@ -891,7 +969,7 @@ func TestCreateStoreChunks(t *testing.T) {
 	})
 
 	t.Run("handles empty input", func(t *testing.T) {
-		chunks, chunkIDs := createStoreChunks([]ChunkInfo{}, [][]float32{}, now)
+		chunks, chunkIDs := createStoreChunks([]ChunkInfo{}, [][]float32{}, "", now)
 
 		if len(chunks) != 0 {
 			t.Errorf("expected 0 chunks, got %d", len(chunks))
@@ -1118,7 +1196,7 @@ func TestEmbedWithReChunking_Success(t *testing.T) {
 		{ID: "chunk2", FilePath: "test.go", Content: "more content", EmbedContent: "more content", StartLine: 6, EndLine: 10},
 	}
 
-	vectors, finalChunks, err := indexer.embedWithReChunking(context.Background(), chunks)
+	vectors, finalChunks, err := indexer.embedWithReChunking(context.Background(), indexer.embedder, "", chunks)
 	if err != nil {
 		t.Fatalf("embedWithReChunking failed: %v", err)
 	}
@@ -1153,7 +1231,7 @@ func TestEmbedWithReChunking_ReChunksOnError(t *testing.T) {
 		{ID: "test.go_0", FilePath: "test.go", Content: largeContent, EmbedContent: largeContent, StartLine: 1, EndLine: 50},
 	}
 
-	vectors, finalChunks, err := indexer.embedWithReChunking(context.Background(), chunks)
+	vectors, finalChunks, err := indexer.embedWithReChunking(context.Background(), indexer.embedder, "", chunks)
 	if err != nil {
 		t.Fatalf("embedWithReChunking failed: %v", err)
 	}
@@ -1230,3 +1308,254 @@ func TestIndexFile_UsesTransformedContentAndStoresSourceSnippet(t *testing.T) {
 		t.Fatalf("expected remapped start line 2, got %d", chunks[0].StartLine)
 	}
 }
+
+// chunkedSource returns a file whose blocks land in separate chunks with a
+// 16-token chunker, with block edited replaced when edited >= 0.
+func chunkedSource(blocks, edited int) string {
+	var b strings.Builder
+	for i := 0; i < blocks; i++ {
+		if i == edited {
+			fmt.Fprintf(&b, "// edited block %02d, new body here\n", i)
+		} else {
+			fmt.Fprintf(&b, "// block %02d keeps its original body\n", i)
+		}
+		b.WriteString("func f() {}\n")
+	}
+	return b.String()
+}
+
+func storedChunkIDs(t *testing.T, st *mockStore, path string) map[string]string {
+	t.Helper()
+	ids := make(map[string]string)
+	for _, id := range st.documents[path].ChunkIDs {
+		chunk, ok := st.chunks[id]
+		if !ok {
+			t.Fatalf("chunk %s missing from store", id)
+		}
+		ids[chunk.ContentHash] = id
+	}
+	return ids
+}
+
+func TestIndexFile_ReembedsOnlyChangedChunks(t *testing.T) {
+	ctx := context.Background()
+	st := newMockStore()
+	emb := newMockEmbedder()
+	idx := &Indexer{store: st, embedder: emb, chunker: NewChunker(16, 0)}
+
+	original := FileInfo{Path: "big.go", Content: chunkedSource(4, -1), Hash: "v1"}
+	total, err := idx.IndexFile(ctx, original)
+	if err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+	if total < 4 {
+		t.Fatalf("expected at least 4 chunks, got %d", total)
+	}
+	before := storedChunkIDs(t, st, "big.go")
+
+	emb.lastBatch = nil
+	edited := FileInfo{Path: "big.go", Content: chunkedSource(4, 2), Hash: "v2"}
+	if _, err := idx.IndexFile(ctx, edited); err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+	if len(emb.lastBatch) != 1 || !strings.Contains(emb.lastBatch[0], "edited block 02") {
+		t.Fatalf("expected only the edited chunk to be embedded, got %q", emb.lastBatch)
+	}
+
+	after := storedChunkIDs(t, st, "big.go")
+	if len(after) != len(before) {
+		t.Fatalf("expected %d chunks after edit, got %d", len(before), len(after))
+	}
+	kept := 0
+	seen := make(map[string]bool)
+	for hash, id := range after {
+		if seen[id] {
+			t.Fatalf("duplicate chunk ID %s", id)
+		}
+		seen[id] = true
+		if beforeID, ok := before[hash]; ok {
+			if beforeID != id {
+				t.Errorf("unchanged chunk changed ID from %s to %s", beforeID, id)
+			}
+			kept++
+		}
+	}
+	if kept != len(before)-1 {
+		t.Errorf("expected %d unchanged chunks, got %d", len(before)-1, kept)
+	}
+	if len(st.chunks) != len(after) {
+		t.Errorf("expected stale chunks to be removed, store has %d chunks for %d IDs", len(st.chunks), len(after))
+	}
+}
+
+// failingEmbedder fails every embedding request.
+type failingEmbedder struct {
+	mockEmbedder
+}
+
+func (e *failingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return nil, errors.New("embedder unavailable")
+}
+
+func (e *failingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, errors.New("embedder unavailable")
+}
+
+func TestIndexFile_FailedEmbeddingKeepsExistingChunks(t *testing.T) {
+	ctx := context.Background()
+	st := newMockStore()
+	idx := &Indexer{store: st, embedder: newMockEmbedder(), chunker: NewChunker(16, 0)}
+
+	total, err := idx.IndexFile(ctx, FileInfo{Path: "big.go", Content: chunkedSource(4, -1), Hash: "v1"})
+	if err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+
+	idx.embedder = &failingEmbedder{}
+	if _, err := idx.IndexFile(ctx, FileInfo{Path: "big.go", Content: chunkedSource(4, 2), Hash: "v2"}); err == nil {
+		t.Fatal("expected IndexFile to fail")
+	}
+	if len(st.chunks) != total || st.documents["big.go"].Hash != "v1" {
+		t.Errorf("expected the indexed version to be kept, got %d chunks and hash %q", len(st.chunks), st.documents["big.go"].Hash)
+	}
+}
+
+func TestIndexFilesBatched_EmbedsOnlyChangedChunks(t *testing.T) {
+	ctx := context.Background()
+	st := newMockStore()
+	emb := &countingBatchEmbedder{}
+	idx := &Indexer{store: st, embedder: emb, chunker: NewChunker(16, 0)}
+
+	files := []FileInfo{{Path: "big.go", Content: chunkedSource(4, -1), Hash: "v1"}}
+	if _, _, err := idx.indexFilesBatched(ctx, files, emb, nil); err != nil {
+		t.Fatalf("indexFilesBatched failed: %v", err)
+	}
+	firstPass := emb.embedded
+
+	emb.embedded = 0
+	files = []FileInfo{{Path: "big.go", Content: chunkedSource(4, 1), Hash: "v2"}}
+	indexed, chunks, err := idx.indexFilesBatched(ctx, files, emb, nil)
+	if err != nil {
+		t.Fatalf("indexFilesBatched failed: %v", err)
+	}
+	if emb.embedded != 1 {
+		t.Errorf("expected 1 chunk embedded after a one-block edit, got %d (first pass embedded %d)", emb.embedded, firstPass)
+	}
+	if indexed != 1 || chunks != firstPass {
+		t.Errorf("expected 1 file with %d chunks, got %d files with %d chunks", firstPass, indexed, chunks)
+	}
+}
+
+// countingBatchEmbedder counts chunks sent for embedding.
+type countingBatchEmbedder struct {
+	mockBatchEmbedder
+	embedded int
+}
+
+func (m *countingBatchEmbedder) EmbedBatches(ctx context.Context, batches []embedder.Batch, progress embedder.BatchProgress) ([]embedder.BatchResult, error) {
+	for _, batch := range batches {
+		m.embedded += batch.Size()
+	}
+	return m.mockBatchEmbedder.EmbedBatches(ctx, batches, progress)
+}
+
+func TestPreserveChunkIDs_RenumbersCollisions(t *testing.T) {
+	previous := map[string]store.Chunk{
+		"b": {ID: "f.go_0", ContentHash: "b"},
+	}
+	chunks := []ChunkInfo{
+		{ID: "f.go_0", FilePath: "f.go", ContentHash: "a"},
+		{ID: "f.go_1", FilePath: "f.go", ContentHash: "b"},
+	}
+	preserveChunkIDs(chunks, previous)
+	if chunks[1].ID != "f.go_0" {
+		t.Errorf("expected unchanged chunk to keep f.go_0, got %s", chunks[1].ID)
+	}
+	if chunks[0].ID == "f.go_0" || chunks[0].ID == "" {
+		t.Errorf("expected new chunk to be renumbered, got %s", chunks[0].ID)
+	}
+}
+
+func TestSetSerialEmbedding_SkipsCrossFileBatches(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	emb := &countingBatchEmbedder{}
+	idx := NewIndexer(tmpDir, newMockStore(), emb, NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Time{})
+	idx.SetSerialEmbedding(func() bool { return true })
+
+	stats, err := idx.IndexAll(context.Background())
+	if err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if stats.FilesIndexed != 2 {
+		t.Errorf("FilesIndexed = %d, want 2", stats.FilesIndexed)
+	}
+	if emb.embedded != 0 {
+		t.Errorf("expected serial embedding to bypass EmbedBatches, got %d chunks batched", emb.embedded)
+	}
+}
+
+func TestPrioritize(t *testing.T) {
+	idx := &Indexer{}
+	files := []FileInfo{{Path: "a.go"}, {Path: "b.go"}, {Path: "c.go"}, {Path: "d.go"}}
+	idx.prioritize(files)
+	if files[0].Path != "a.go" {
+		t.Fatalf("expected files to keep their order without priority files, got %v", files)
+	}
+
+	idx.SetPriorityFiles([]string{"c.go", "d.go"})
+	idx.prioritize(files)
+	var order []string
+	for _, f := range files {
+		order = append(order, f.Path)
+	}
+	if strings.Join(order, ",") != "c.go,d.go,a.go,b.go" {
+		t.Errorf("expected priority files first, got %v", order)
+	}
+}
+
+func TestIndexAll_ReindexesUnchangedPriorityFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("package main\n\nfunc "+strings.TrimSuffix(name, ".go")+"() {}"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	mockStore := newMockStore()
+	idx := NewIndexer(tmpDir, mockStore, newMockEmbedder(), NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Time{})
+	if _, err := idx.IndexAll(context.Background()); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+
+	// Both files are unchanged, but b.go is a priority file.
+	idx.SetPriorityFiles([]string{"b.go"})
+	stats, err := idx.IndexAll(context.Background())
+	if err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if stats.FilesIndexed != 1 || len(stats.Prioritized) != 1 || stats.Prioritized[0] != "b.go" {
+		t.Errorf("expected the unchanged priority file re-indexed, got %d files indexed, prioritized %v", stats.FilesIndexed, stats.Prioritized)
+	}
+
+	idx.SetPriorityFiles(nil)
+	stats, err = idx.IndexAll(context.Background())
+	if err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if stats.FilesIndexed != 0 || len(stats.Prioritized) != 0 {
+		t.Errorf("expected no file re-indexed once priority files are cleared, got %d, prioritized %v", stats.FilesIndexed, stats.Prioritized)
+	}
+}
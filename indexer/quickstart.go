@@ -0,0 +1,101 @@
+package indexer
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultQuickStartFiles is how many files the quick-start pass indexes
+// before the backfill.
+const DefaultQuickStartFiles = 500
+
+// quickStartRecentWindow is how close to the newest file a modification must
+// be for a file to count as recently changed.
+const quickStartRecentWindow = 7 * 24 * time.Hour
+
+// entryPointNames are file names (without extension) that usually hold a
+// program's entry point or public surface.
+var entryPointNames = map[string]bool{
+	"main":     true,
+	"__main__": true,
+	"index":    true,
+	"app":      true,
+	"server":   true,
+	"lib":      true,
+	"mod":      true,
+	"cli":      true,
+	"manage":   true,
+	"program":  true,
+}
+
+// QuickStartFiles returns up to limit files ranked by how useful they are for
+// a first search: READMEs and docs, entry points and recently changed files
+// first, shallower paths before deeper ones. Ties keep the newest file first.
+// It returns nil when every file fits in the limit, since there is nothing
+// to backfill then.
+func QuickStartFiles(files []FileMeta, limit int) []FileMeta {
+	if limit <= 0 || len(files) <= limit {
+		return nil
+	}
+
+	var newest int64
+	for _, f := range files {
+		if f.ModTime > newest {
+			newest = f.ModTime
+		}
+	}
+	recentSince := newest - int64(quickStartRecentWindow/time.Second)
+
+	type ranked struct {
+		meta  FileMeta
+		score int
+	}
+	candidates := make([]ranked, len(files))
+	for i, f := range files {
+		candidates[i] = ranked{meta: f, score: quickStartScore(f, recentSince)}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.meta.ModTime != b.meta.ModTime {
+			return a.meta.ModTime > b.meta.ModTime
+		}
+		return a.meta.Path < b.meta.Path
+	})
+
+	subset := make([]FileMeta, limit)
+	for i := range subset {
+		subset[i] = candidates[i].meta
+	}
+	return subset
+}
+
+func quickStartScore(f FileMeta, recentSince int64) int {
+	p := filepath.ToSlash(f.Path)
+	base := path.Base(p)
+	name := strings.ToLower(strings.TrimSuffix(base, path.Ext(base)))
+	depth := strings.Count(p, "/")
+
+	score := 0
+	switch {
+	case name == "readme":
+		score += 3
+	case strings.HasPrefix(p, "docs/") || strings.HasPrefix(p, "doc/"):
+		score += 2
+	}
+	if entryPointNames[name] || strings.HasPrefix(p, "cmd/") {
+		score += 3
+	}
+	if f.ModTime >= recentSince {
+		score += 2
+	}
+	if depth <= 1 {
+		score++
+	}
+	return score
+}
@@ -0,0 +1,86 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestQuickStartFiles(t *testing.T) {
+	now := time.Now().Unix()
+	old := now - int64(30*24*time.Hour/time.Second)
+
+	files := []FileMeta{
+		{Path: "internal/deep/pkg/util.go", ModTime: old},
+		{Path: "internal/deep/pkg/recent.go", ModTime: now},
+		{Path: "README.md", ModTime: old},
+		{Path: "cmd/tool/main.go", ModTime: old},
+		{Path: "internal/deep/pkg/other.go", ModTime: old},
+	}
+
+	got := QuickStartFiles(files, 3)
+	want := []string{"README.md", "cmd/tool/main.go", "internal/deep/pkg/recent.go"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d files, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Path != want[i] {
+			t.Errorf("rank %d: expected %s, got %s", i, want[i], got[i].Path)
+		}
+	}
+
+	if QuickStartFiles(files, len(files)) != nil {
+		t.Error("expected nil when every file fits in the limit")
+	}
+}
+
+func TestIndexFilesWithBatchProgress_KeepsUnlistedDocuments(t *testing.T) {
+	tmpDir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(tmpDir, fmt.Sprintf("file%d.go", i))
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("package main\n\nfunc F%d() {}", i)), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	mockStore := newMockStore()
+	mockStore.documents["gone.go"] = store.Document{Path: "gone.go", Hash: "hash", ChunkIDs: []string{"chunk"}}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := NewScanner(tmpDir, ignoreMatcher)
+	idx := NewIndexer(tmpDir, mockStore, newMockEmbedder(), NewChunker(512, 50), scanner, time.Time{})
+
+	stats, err := idx.IndexFilesWithBatchProgress(context.Background(), []FileMeta{{Path: "file1.go"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("IndexFilesWithBatchProgress failed: %v", err)
+	}
+	if stats.FilesIndexed != 1 {
+		t.Errorf("expected 1 file indexed, got %d", stats.FilesIndexed)
+	}
+	if _, ok := mockStore.documents["file1.go"]; !ok {
+		t.Error("file1.go should be indexed")
+	}
+	if _, ok := mockStore.documents["file0.go"]; ok {
+		t.Error("file0.go is not in the subset and should not be indexed")
+	}
+	if _, ok := mockStore.documents["gone.go"]; !ok {
+		t.Error("a partial pass must not remove documents outside the subset")
+	}
+
+	// The full pass then indexes the rest and skips the unchanged subset.
+	stats, err = idx.IndexAll(context.Background())
+	if err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if stats.FilesIndexed != 2 || stats.FilesRemoved != 1 {
+		t.Errorf("expected backfill to index 2 files and remove 1, got %d indexed, %d removed", stats.FilesIndexed, stats.FilesRemoved)
+	}
+}
@@ -5,10 +5,16 @@ import (
 	"encoding/hex"
 	"io"
 	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
-	"unicode/utf8"
+
+	"github.com/yoanbernabeu/grepai/git"
+	"github.com/yoanbernabeu/grepai/internal/editorconfig"
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
+	"github.com/yoanbernabeu/grepai/trace"
 )
 
 const (
@@ -36,65 +42,68 @@ func isMinifiedFile(path string) bool {
 
 // SupportedExtensions lists file extensions to index
 var SupportedExtensions = map[string]bool{
-	".go":     true,
-	".js":     true,
-	".ts":     true,
-	".jsx":    true,
-	".tsx":    true,
-	".py":     true,
-	".rb":     true,
-	".java":   true,
-	".c":      true,
-	".cpp":    true,
-	".cc":     true,
-	".h":      true,
-	".hpp":    true,
-	".cs":     true,
-	".php":    true,
-	".rs":     true,
-	".swift":  true,
-	".kt":     true,
-	".scala":  true,
-	".vue":    true,
-	".svelte": true,
-	".html":   true,
-	".css":    true,
-	".scss":   true,
-	".less":   true,
-	".sql":    true,
-	".sh":     true,
-	".bash":   true,
-	".zsh":    true,
-	".yaml":   true,
-	".yml":    true,
-	".json":   true,
-	".xml":    true,
-	".md":     true,
-	".txt":    true,
-	".toml":   true,
-	".ini":    true,
-	".cfg":    true,
-	".conf":   true,
-	".env":    true,
-	".lua":    true,
-	".r":      true,
-	".R":      true,
-	".dart":   true,
-	".ex":     true,
-	".exs":    true,
-	".erl":    true,
-	".clj":    true,
-	".hs":     true,
-	".ml":     true,
-	".fs":     true,
-	".elm":    true,
-	".nim":    true,
-	".zig":    true,
-	".proto":  true,
-	".tf":     true,
-	".hcl":    true,
-	".pas":    true, // Pascal source file
-	".dpr":    true, // Delphi project file
+	".go":         true,
+	".js":         true,
+	".ts":         true,
+	".jsx":        true,
+	".tsx":        true,
+	".py":         true,
+	".rb":         true,
+	".java":       true,
+	".c":          true,
+	".cpp":        true,
+	".cc":         true,
+	".h":          true,
+	".hpp":        true,
+	".cs":         true,
+	".php":        true,
+	".rs":         true,
+	".swift":      true,
+	".kt":         true,
+	".scala":      true,
+	".vue":        true,
+	".svelte":     true,
+	".html":       true,
+	".css":        true,
+	".scss":       true,
+	".less":       true,
+	".sql":        true,
+	".sh":         true,
+	".bash":       true,
+	".zsh":        true,
+	".yaml":       true,
+	".yml":        true,
+	".json":       true,
+	".xml":        true,
+	".md":         true,
+	".txt":        true,
+	".toml":       true,
+	".ini":        true,
+	".cfg":        true,
+	".conf":       true,
+	".env":        true,
+	".lua":        true,
+	".r":          true,
+	".R":          true,
+	".dart":       true,
+	".ex":         true,
+	".exs":        true,
+	".erl":        true,
+	".clj":        true,
+	".hs":         true,
+	".ml":         true,
+	".fs":         true,
+	".elm":        true,
+	".nim":        true,
+	".zig":        true,
+	".proto":      true,
+	".tf":         true,
+	".mk":         true, // Makefiles, also detected by name
+	".dockerfile": true, // Dockerfiles, also detected by name
+	".groovy":     true,
+	".hcl":        true,
+	".pas":        true, // Pascal source file
+	".dpr":        true, // Delphi project file
 }
 
 type FileInfo struct {
@@ -103,6 +112,15 @@ type FileInfo struct {
 	ModTime int64
 	Hash    string
 	Content string
+	// Sections are set when a file handler prepared the file for chunking.
+	Sections []Section
+	// LanguageExt is the language detected from the file's name or content
+	// when its extension does not tell, such as ".py" for a script with a
+	// python shebang. Empty otherwise.
+	LanguageExt string
+	// Todos are the TODO, FIXME, HACK and XXX comments of the file, in any
+	// language.
+	Todos []trace.Todo
 }
 
 type FileMeta struct {
@@ -111,16 +129,125 @@ type FileMeta struct {
 	ModTime int64
 }
 
+// Symlinked directory follow policies (indexer.follow_symlinks).
+const (
+	FollowSymlinksNone = "false" // never descend into symlinked directories
+	FollowSymlinksSafe = "safe"  // follow links whose target is inside the project
+	FollowSymlinksAll  = "all"   // follow every link, including targets outside the project
+)
+
 type Scanner struct {
-	root   string
-	ignore *IgnoreMatcher
+	root           string
+	ignore         *IgnoreMatcher
+	followSymlinks string
+	handlers       *FileHandlers
+	sparse         *git.SparseCheckout
+	rules          ContentRules
+	// editorconfig caches the .editorconfig files giving file charsets.
+	editorconfig *editorconfig.Cache
+	// todos extracts the todo comments of scanned files.
+	todos *trace.RegexExtractor
 }
 
 func NewScanner(root string, ignore *IgnoreMatcher) *Scanner {
 	return &Scanner{
-		root:   root,
-		ignore: ignore,
+		root:           root,
+		ignore:         ignore,
+		followSymlinks: FollowSymlinksNone,
+		rules:          DefaultContentRules(),
+		editorconfig:   editorconfig.NewCache(),
+		todos:          trace.NewRegexExtractor(),
+	}
+}
+
+// SetFollowSymlinks sets how symlinked directories are handled during scans.
+// Files under a followed link are indexed under the link's path; a directory
+// reachable through several links, or through its real path, is indexed once.
+func (s *Scanner) SetFollowSymlinks(policy string) {
+	if policy == "" {
+		policy = FollowSymlinksNone
+	}
+	s.followSymlinks = policy
+}
+
+// SetFileHandlers sets the handlers that preprocess files before chunking.
+// Extensions in HandlerExtensions are only indexed when a handler supports
+// them.
+func (s *Scanner) SetFileHandlers(handlers *FileHandlers) {
+	s.handlers = handlers
+}
+
+// SetSparseCheckout restricts scans to the paths of a sparse checkout, so
+// directories excluded from it are not indexed even when present on disk.
+// A nil checkout scans every path.
+func (s *Scanner) SetSparseCheckout(sparse *git.SparseCheckout) {
+	s.sparse = sparse
+}
+
+// SetContentRules sets the rules that skip binary, minified and generated
+// files from their content.
+func (s *Scanner) SetContentRules(rules ContentRules) {
+	s.rules = rules
+}
+
+// supported reports whether path has an indexable extension, or is a
+// well-known file such as a Makefile or an extensionless script whose
+// language is detected and indexable. Only extensionless files that no
+// name tells about are read.
+func (s *Scanner) supported(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if HandlerExtensions[ext] {
+		return s.handlers.Handles(path)
+	}
+	if detected := langdetect.NameExt(path); detected != "" {
+		return SupportedExtensions[detected]
+	}
+	if SupportedExtensions[ext] {
+		return true
+	}
+	if ext != "" || langdetect.PlainName(path) {
+		return false
+	}
+	return SupportedExtensions[langdetect.Detect(path, s.readHeader(path))]
+}
+
+// readHeader returns the first bytes of the file at relPath that language
+// detection looks at, or "" if it cannot be read.
+func (s *Scanner) readHeader(relPath string) string {
+	f, err := os.Open(filepath.Join(s.root, relPath))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	buf := make([]byte, langdetect.HeaderSize)
+	n, _ := io.ReadFull(f, buf)
+	return string(buf[:n])
+}
+
+// maxSize returns the size limit for path: the handler's limit for binary
+// documents, maxFileSize otherwise.
+func (s *Scanner) maxSize(path string) int64 {
+	if doc, ok := s.handlers.find(path).(BinaryFileHandler); ok {
+		return doc.MaxFileSize()
+	}
+	return maxFileSize
+}
+
+// binary reports whether path is a binary document read by a handler.
+func (s *Scanner) binary(path string) bool {
+	_, ok := s.handlers.find(path).(BinaryFileHandler)
+	return ok
+}
+
+// prepare runs the file handler for file. It returns the reason to skip
+// the file, if any.
+func (s *Scanner) prepare(file *FileInfo) string {
+	prepared, err := s.handlers.Prepare(file.Path, file.Content)
+	if err != nil {
+		return err.Error()
 	}
+	file.Sections = prepared.Sections
+	return prepared.Skip
 }
 
 // ScanMetadata scans indexable files and returns only file metadata.
@@ -129,32 +256,14 @@ func (s *Scanner) ScanMetadata() ([]FileMeta, []string, error) {
 	var files []FileMeta
 	var skipped []string
 
-	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return nil // Skip files we can't access
-		}
-
-		relPath, err := filepath.Rel(s.root, path)
-		if err != nil {
-			return nil
-		}
-
-		// Handle directories: use ShouldSkipDir to respect .grepaiignore negations
-		if d.IsDir() {
-			if s.ignore.ShouldSkipDir(relPath) {
-				return filepath.SkipDir
-			}
-			return nil // Descend into the directory
-		}
-
+	linkSkipped, err := s.walk(func(path, relPath string, d fs.DirEntry) error {
 		// Skip ignored files
 		if s.ignore.ShouldIgnore(relPath) {
 			return nil
 		}
 
 		// Check extension
-		ext := strings.ToLower(filepath.Ext(path))
-		if !SupportedExtensions[ext] {
+		if !s.supported(relPath) {
 			return nil
 		}
 
@@ -170,7 +279,7 @@ func (s *Scanner) ScanMetadata() ([]FileMeta, []string, error) {
 		}
 
 		// Skip large files
-		if info.Size() > maxFileSize {
+		if info.Size() > s.maxSize(relPath) {
 			skipped = append(skipped, relPath+" (too large)")
 			return nil
 		}
@@ -184,39 +293,21 @@ func (s *Scanner) ScanMetadata() ([]FileMeta, []string, error) {
 		return nil
 	})
 
-	return files, skipped, err
+	return files, append(skipped, linkSkipped...), err
 }
 
 func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 	var files []FileInfo
 	var skipped []string
 
-	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return nil // Skip files we can't access
-		}
-
-		relPath, err := filepath.Rel(s.root, path)
-		if err != nil {
-			return nil
-		}
-
-		// Handle directories: use ShouldSkipDir to respect .grepaiignore negations
-		if d.IsDir() {
-			if s.ignore.ShouldSkipDir(relPath) {
-				return filepath.SkipDir
-			}
-			return nil // Descend into the directory
-		}
-
+	linkSkipped, err := s.walk(func(path, relPath string, d fs.DirEntry) error {
 		// Skip ignored files
 		if s.ignore.ShouldIgnore(relPath) {
 			return nil
 		}
 
 		// Check extension
-		ext := strings.ToLower(filepath.Ext(path))
-		if !SupportedExtensions[ext] {
+		if !s.supported(relPath) {
 			return nil
 		}
 
@@ -232,7 +323,7 @@ func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 		}
 
 		// Skip large files
-		if info.Size() > maxFileSize {
+		if info.Size() > s.maxSize(relPath) {
 			skipped = append(skipped, relPath+" (too large)")
 			return nil
 		}
@@ -243,31 +334,132 @@ func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 			return nil
 		}
 
-		// Skip binary files
-		if !utf8.Valid(content) || containsNull(content) {
-			return nil
-		}
-
 		// Calculate hash
 		hash := sha256.Sum256(content)
 
-		files = append(files, FileInfo{
+		// Skip binary, minified and generated files
+		if !s.binary(relPath) {
+			var reason string
+			if reason, content = s.rules.classify(relPath, decodeFile(path, content, s.editorconfig)); reason != "" {
+				if reason != SkipBinary {
+					skipped = append(skipped, relPath+" ("+reason+")")
+				}
+				return nil
+			}
+		}
+
+		file := FileInfo{
 			Path:    relPath,
 			Size:    info.Size(),
 			ModTime: info.ModTime().Unix(),
 			Hash:    hex.EncodeToString(hash[:]),
 			Content: string(content),
-		})
+		}
+		file.LanguageExt = langdetect.Detect(relPath, file.Content)
+		if reason := s.prepare(&file); reason != "" {
+			skipped = append(skipped, relPath+" ("+reason+")")
+			return nil
+		}
+		file.Todos = s.todos.ExtractTodos(relPath, file.Content)
+		files = append(files, file)
 
 		return nil
 	})
 
-	return files, skipped, err
+	return files, append(skipped, linkSkipped...), err
+}
+
+// symlinkedDir is a symlink to a directory found during a walk. Links are
+// followed after the regular tree so real paths take precedence.
+type symlinkedDir struct {
+	relPath string
+	target  string // resolved target path
+}
+
+// walk calls visit for every entry under the root that is not a directory or
+// an ignored directory's content, following symlinked directories according
+// to the follow policy. Each real directory is walked at most once, which
+// deduplicates links to the same target and stops symlink cycles. It returns
+// the links that were not followed because of the safe policy.
+func (s *Scanner) walk(visit func(path, relPath string, d fs.DirEntry) error) ([]string, error) {
+	rootReal := s.root
+	if resolved, err := filepath.EvalSymlinks(s.root); err == nil {
+		rootReal = resolved
+	}
+	visited := make(map[string]bool)
+	var pending []symlinkedDir
+
+	walkTree := func(dir, realDir, relDir string) error {
+		cycles := fileutil.NewDirCycleGuard()
+		return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // Skip files we can't access
+			}
+
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return nil
+			}
+			relPath := filepath.Join(relDir, rel)
+
+			// Handle directories: use ShouldSkipDir to respect .grepaiignore negations
+			if d.IsDir() {
+				if s.ignore.ShouldSkipDir(relPath) || !s.sparse.IncludesDir(relPath) {
+					return filepath.SkipDir
+				}
+				if cycles.IsCycle(path) {
+					return filepath.SkipDir
+				}
+				visited[fileutil.PathKey(filepath.Join(realDir, rel))] = true
+				return nil // Descend into the directory
+			}
+
+			if d.Type()&fs.ModeSymlink != 0 && s.followSymlinks != FollowSymlinksNone {
+				if target, ok := fileutil.SymlinkedDirTarget(path); ok {
+					if !s.ignore.ShouldSkipDir(relPath) {
+						pending = append(pending, symlinkedDir{relPath: relPath, target: target})
+					}
+					return nil
+				}
+			}
+
+			if !s.sparse.IncludesFile(relPath) {
+				return nil
+			}
+
+			return visit(path, relPath, d)
+		})
+	}
+
+	if err := walkTree(s.root, rootReal, ""); err != nil {
+		return nil, err
+	}
+
+	var skipped []string
+	for len(pending) > 0 {
+		link := pending[0]
+		pending = pending[1:]
+		if visited[fileutil.PathKey(link.target)] {
+			continue // Already indexed under its real path or another link
+		}
+		if s.followSymlinks == FollowSymlinksSafe && !fileutil.IsWithinDir(rootReal, link.target) {
+			skipped = append(skipped, link.relPath+" (symlink outside project)")
+			continue
+		}
+		if err := walkTree(link.target, link.target, link.relPath); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
 }
 
 func (s *Scanner) ScanFile(relPath string) (*FileInfo, error) {
 	absPath := filepath.Join(s.root, relPath)
 
+	if !s.supported(relPath) || !s.sparse.IncludesFile(relPath) {
+		return nil, nil
+	}
+
 	// Skip minified files
 	if isMinifiedFile(relPath) {
 		return nil, nil
@@ -278,7 +470,7 @@ func (s *Scanner) ScanFile(relPath string) (*FileInfo, error) {
 		return nil, err
 	}
 
-	if info.Size() > maxFileSize {
+	if info.Size() > s.maxSize(relPath) {
 		return nil, nil // Skip large files
 	}
 
@@ -287,28 +479,32 @@ func (s *Scanner) ScanFile(relPath string) (*FileInfo, error) {
 		return nil, err
 	}
 
-	if !utf8.Valid(content) || containsNull(content) {
-		return nil, nil // Skip binary files
-	}
-
 	hash := sha256.Sum256(content)
 
-	return &FileInfo{
+	if !s.binary(relPath) {
+		var reason string
+		if reason, content = s.rules.classify(relPath, decodeFile(absPath, content, s.editorconfig)); reason != "" {
+			if reason != SkipBinary {
+				log.Printf("Skipping %s (%s)", relPath, reason)
+			}
+			return nil, nil
+		}
+	}
+
+	file := &FileInfo{
 		Path:    relPath,
 		Size:    info.Size(),
 		ModTime: info.ModTime().Unix(),
 		Hash:    hex.EncodeToString(hash[:]),
 		Content: string(content),
-	}, nil
-}
-
-func containsNull(data []byte) bool {
-	for _, b := range data {
-		if b == 0 {
-			return true
-		}
 	}
-	return false
+	file.LanguageExt = langdetect.Detect(relPath, file.Content)
+	if reason := s.prepare(file); reason != "" {
+		log.Printf("Skipping %s (%s)", relPath, reason)
+		return nil, nil
+	}
+	file.Todos = s.todos.ExtractTodos(relPath, file.Content)
+	return file, nil
 }
 
 func HashFile(path string) (string, error) {
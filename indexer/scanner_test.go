@@ -1,9 +1,14 @@
 package indexer
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
+
+	"github.com/yoanbernabeu/grepai/git"
 )
 
 func TestSupportedExtensions(t *testing.T) {
@@ -202,7 +207,7 @@ func TestScanner_ScanFile(t *testing.T) {
 	}
 }
 
-func TestContainsNull(t *testing.T) {
+func TestLooksBinary(t *testing.T) {
 	tests := []struct {
 		data     []byte
 		expected bool
@@ -211,12 +216,17 @@ func TestContainsNull(t *testing.T) {
 		{[]byte("hello\x00world"), true},
 		{[]byte{}, false},
 		{[]byte{0}, true},
+		{[]byte("caf\xe9"), true},
+		{[]byte("\x01\x02\x03\x04abc"), true},
+		{[]byte("\x1b[31mred\x1b[0m log line\n"), false},
+		// A rune cut by the end of the sample, and invalid UTF-8 past it.
+		{append(append(bytes.Repeat([]byte("a"), binarySampleSize-1), "é"...), 0xff), false},
 	}
 
 	for _, tt := range tests {
-		result := containsNull(tt.data)
+		result := looksBinary(tt.data)
 		if result != tt.expected {
-			t.Errorf("containsNull(%v) = %v, expected %v", tt.data, result, tt.expected)
+			t.Errorf("looksBinary(%q) = %v, expected %v", tt.data, result, tt.expected)
 		}
 	}
 }
@@ -352,3 +362,195 @@ func TestScanner_ScanFile_SkipsMinified(t *testing.T) {
 		t.Error("expected nil for minified file, got file info")
 	}
 }
+
+func TestScanner_FollowSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	outside := t.TempDir()
+
+	write := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	write(filepath.Join(tmpDir, "packages", "lib", "lib.go"))
+	write(filepath.Join(outside, "shared", "shared.go"))
+
+	links := map[string]string{
+		filepath.Join(tmpDir, "app", "lib"):            filepath.Join(tmpDir, "packages", "lib"),
+		filepath.Join(tmpDir, "app", "lib-again"):      filepath.Join(tmpDir, "packages", "lib"),
+		filepath.Join(tmpDir, "packages", "lib", "up"): filepath.Join(tmpDir, "packages"),
+		filepath.Join(tmpDir, "shared"):                filepath.Join(outside, "shared"),
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "app"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{FollowSymlinksNone, []string{"packages/lib/lib.go"}},
+		{FollowSymlinksSafe, []string{"packages/lib/lib.go"}},
+		{FollowSymlinksAll, []string{"packages/lib/lib.go", "shared/shared.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			scanner := NewScanner(tmpDir, ignoreMatcher)
+			scanner.SetFollowSymlinks(tt.policy)
+			files, _, err := scanner.ScanMetadata()
+			if err != nil {
+				t.Fatalf("scan metadata failed: %v", err)
+			}
+			var got []string
+			for _, f := range files {
+				got = append(got, filepath.ToSlash(f.Path))
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScanner_FollowSymlinksIntoIgnoredTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "node_modules", ".pnpm", "util")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "util.ts"), []byte("export {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Symlink(target, filepath.Join(tmpDir, "util")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{"node_modules/"}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := NewScanner(tmpDir, ignoreMatcher)
+	scanner.SetFollowSymlinks(FollowSymlinksSafe)
+	files, _, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if len(files) != 1 || filepath.ToSlash(files[0].Path) != "util/util.ts" {
+		t.Fatalf("expected util/util.ts indexed through the link, got %+v", files)
+	}
+}
+
+func TestScanner_SparseCheckout(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, path := range []string{"main.go", "services/api/api.go", "services/billing/billing.go"} {
+		full := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte("package x\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := NewScanner(tmpDir, ignoreMatcher)
+	scanner.SetSparseCheckout(git.ParseSparseCheckout("/*\n!/*/\n/services/\n!/services/*/\n/services/api/\n", true))
+
+	files, _, err := scanner.Scan()
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, filepath.ToSlash(f.Path))
+	}
+	if len(paths) != 2 || paths[0] != "main.go" || paths[1] != "services/api/api.go" {
+		t.Errorf("expected only sparse-checkout paths, got %v", paths)
+	}
+
+	file, err := scanner.ScanFile(filepath.Join("services", "billing", "billing.go"))
+	if err != nil || file != nil {
+		t.Errorf("expected ScanFile to skip a path outside the sparse checkout, got %+v, %v", file, err)
+	}
+}
+
+func TestScanner_DetectsLanguageBeyondExtension(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"Dockerfile":  "FROM golang:1.22\n",
+		"Makefile":    "build:\n\tgo build ./...\n",
+		"bin/deploy":  "#!/usr/bin/env python3\nprint('deploy')\n",
+		"bin/helper":  "#!/usr/bin/env unknown\n",
+		"LICENSE":     "MIT License\n",
+		"scripts/run": "#!/bin/bash\necho run\n",
+		"makefile.py": "import os\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := NewScanner(tmpDir, ignoreMatcher)
+
+	metas, _, err := scanner.ScanMetadata()
+	if err != nil {
+		t.Fatalf("failed to scan metadata: %v", err)
+	}
+	var paths []string
+	for _, m := range metas {
+		paths = append(paths, filepath.ToSlash(m.Path))
+	}
+	sort.Strings(paths)
+	if strings.Join(paths, ",") != "Dockerfile,Makefile,bin/deploy,makefile.py,scripts/run" {
+		t.Errorf("unexpected scanned files: %v", paths)
+	}
+
+	file, err := scanner.ScanFile(filepath.Join("bin", "deploy"))
+	if err != nil || file == nil {
+		t.Fatalf("failed to scan bin/deploy: %v", err)
+	}
+	if file.LanguageExt != ".py" {
+		t.Errorf("expected detected language .py, got %q", file.LanguageExt)
+	}
+	file, err = scanner.ScanFile("Dockerfile")
+	if err != nil || file == nil {
+		t.Fatalf("failed to scan Dockerfile: %v", err)
+	}
+	if file.LanguageExt != ".dockerfile" {
+		t.Errorf("expected detected language .dockerfile, got %q", file.LanguageExt)
+	}
+	file, err = scanner.ScanFile("makefile.py")
+	if err != nil || file == nil {
+		t.Fatalf("failed to scan makefile.py: %v", err)
+	}
+	if file.LanguageExt != "" {
+		t.Errorf("expected the .py extension to be kept, got %q", file.LanguageExt)
+	}
+}
@@ -0,0 +1,97 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// todoEmbedBatchSize is the number of todos embedded per request.
+const todoEmbedBatchSize = 100
+
+// SetTodoStore makes the indexer keep the todos the scanner finds in the
+// files it indexes in todos, embedded once with the main embedder, so that
+// todo searches only embed their query. Full passes persist the store;
+// callers indexing single files persist it with PersistTodos.
+func (idx *Indexer) SetTodoStore(todos *trace.TodoStore) {
+	idx.todos = todos
+}
+
+// PersistTodos writes the todo store, if any, when it changed.
+func (idx *Indexer) PersistTodos(ctx context.Context) error {
+	if idx.todos == nil {
+		return nil
+	}
+	return idx.todos.Persist(ctx)
+}
+
+// todosCurrent reports whether the todo store, if any, holds the todos of
+// a file. Files it lacks are read again even when unchanged.
+func (idx *Indexer) todosCurrent(path string) bool {
+	return idx.todos == nil || idx.todos.HasFile(path)
+}
+
+// indexTodos embeds the todos of the files whose content the todo store has
+// not seen and saves them. A file whose todos cannot be embedded is left
+// out, to be tried again by the next pass.
+func (idx *Indexer) indexTodos(ctx context.Context, files []FileInfo) {
+	if idx.todos == nil {
+		return
+	}
+
+	var pending []FileInfo
+	var texts []string
+	for _, file := range files {
+		if idx.todos.Current(file.Path, file.Hash) {
+			continue
+		}
+		pending = append(pending, file)
+		for _, todo := range file.Todos {
+			texts = append(texts, todo.EmbeddingText())
+		}
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += todoEmbedBatchSize {
+		end := min(start+todoEmbedBatchSize, len(texts))
+		batch, err := idx.embedder.EmbedBatch(ctx, texts[start:end])
+		if err == nil && len(batch) != end-start {
+			err = fmt.Errorf("got %d embeddings for %d todos", len(batch), end-start)
+		}
+		if err != nil {
+			log.Printf("Warning: failed to embed todos: %v", err)
+			return
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	next := 0
+	for _, file := range pending {
+		todos := make([]trace.Todo, len(file.Todos))
+		for i, todo := range file.Todos {
+			todo.Vector = vectors[next]
+			todos[i] = todo
+			next++
+		}
+		idx.todos.SaveFile(file.Path, file.Hash, todos)
+	}
+}
+
+// removeTodos removes the todos of removed files.
+func (idx *Indexer) removeTodos(paths ...string) {
+	if idx.todos == nil {
+		return
+	}
+	for _, path := range paths {
+		idx.todos.DeleteFile(path)
+	}
+}
+
+// persistTodos persists the todo store after a pass, logging failures.
+func (idx *Indexer) persistTodos(ctx context.Context) {
+	if err := idx.PersistTodos(ctx); err != nil {
+		log.Printf("Warning: failed to persist todo index: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// todoCountingEmbedder counts the todo texts it embeds.
+type todoCountingEmbedder struct {
+	mockEmbedder
+	todos int
+}
+
+func (m *todoCountingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	for _, text := range texts {
+		if strings.HasPrefix(text, "TODO: ") || strings.HasPrefix(text, "FIXME: ") {
+			m.todos++
+		}
+	}
+	return m.mockEmbedder.EmbedBatch(ctx, texts)
+}
+
+func TestIndexAll_EmbedsTodosOnce(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":     "package main\n\n// TODO: parse flags\nfunc main() {}\n",
+		"deploy.yaml": "replicas: 2 # FIXME: read from the environment\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	ctx := context.Background()
+	mockStore := newMockStore()
+	emb := &todoCountingEmbedder{}
+	newIdx := func(todos *trace.TodoStore) *Indexer {
+		idx := NewIndexer(tmpDir, mockStore, emb, NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Time{})
+		idx.SetTodoStore(todos)
+		return idx
+	}
+
+	// Todos of languages without symbol extraction are indexed too, and
+	// the pass persists them.
+	todoPath := filepath.Join(tmpDir, "todos.gob")
+	if _, err := newIdx(trace.NewTodoStore(todoPath)).IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	todos := trace.NewTodoStore(todoPath)
+	if err := todos.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	found := todos.Todos(nil)
+	if len(found) != 2 || found[0].File != "deploy.yaml" || len(found[0].Vector) == 0 || found[1].Symbol != "" {
+		t.Fatalf("expected both todos with their vectors, got %+v", found)
+	}
+	if emb.todos != 2 {
+		t.Fatalf("expected 2 todos embedded, got %d", emb.todos)
+	}
+
+	// Unchanged files are not embedded again.
+	if _, err := newIdx(todos).IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if emb.todos != 2 {
+		t.Errorf("expected unchanged todos not to be embedded again, got %d embedded", emb.todos)
+	}
+
+	// A new todo index is filled from unchanged files.
+	if err := os.Remove(todoPath); err != nil {
+		t.Fatalf("failed to remove todo index: %v", err)
+	}
+	fresh := trace.NewTodoStore(todoPath)
+	if _, err := newIdx(fresh).IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if len(fresh.Todos(nil)) != 2 {
+		t.Errorf("expected the todos of unchanged files to be backfilled, got %+v", fresh.Todos(nil))
+	}
+
+	// Removed files lose their todos.
+	if err := os.Remove(filepath.Join(tmpDir, "deploy.yaml")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if _, err := newIdx(fresh).IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+	if found := fresh.Todos(nil); len(found) != 1 || found[0].File != "main.go" {
+		t.Errorf("expected only the todo of main.go, got %+v", found)
+	}
+}
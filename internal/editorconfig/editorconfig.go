@@ -0,0 +1,339 @@
+// Package editorconfig reads the properties .editorconfig files give a file,
+// such as the charset of sources written by Windows toolchains.
+//
+// The .editorconfig files of the directory of the file and its parents
+// apply, the closer ones overriding the others, up to the first one with
+// root = true. Section globs follow the EditorConfig specification: *, **,
+// ?, [chars], [!chars], {a,b} and {1..9}; a glob without a slash matches
+// the file name in any directory below the .editorconfig file.
+package editorconfig
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the name of EditorConfig files.
+const FileName = ".editorconfig"
+
+// maxRangeSize is the largest {n1..n2} range expanded into alternatives.
+const maxRangeSize = 1000
+
+// section is a [glob] section of an EditorConfig file.
+type section struct {
+	pattern *regexp.Regexp
+	props   map[string]string
+}
+
+// file is a parsed EditorConfig file.
+type file struct {
+	root     bool
+	sections []section
+}
+
+// Cache keeps the EditorConfig files it parsed by directory, so that the
+// files of a tree are parsed once rather than for every file below them. A
+// cached file is parsed again once it changes on disk. It is safe for
+// concurrent use, and a nil *Cache reads the files on every lookup.
+type Cache struct {
+	mu    sync.Mutex
+	files map[string]cachedFile
+}
+
+// cachedFile is a parsed EditorConfig file and the state of the file it was
+// parsed from.
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	file    *file
+}
+
+// NewCache returns an empty cache.
+func NewCache() *Cache {
+	return &Cache{files: make(map[string]cachedFile)}
+}
+
+// Properties returns the properties the .editorconfig files give the file
+// at path, with lowercased names and values. It returns nil when none
+// applies.
+func Properties(path string) map[string]string {
+	return (*Cache)(nil).Properties(path)
+}
+
+// Charset returns the charset the .editorconfig files give the file at
+// path: latin1, utf-8, utf-8-bom, utf-16be or utf-16le, or "" when unset.
+func Charset(path string) string {
+	return (*Cache)(nil).Charset(path)
+}
+
+// Properties is like the package-level Properties, with the EditorConfig
+// files read through c.
+func (c *Cache) Properties(path string) map[string]string {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+
+	// Nearest first, up to a root file.
+	var files []*file
+	var dirs []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if f := c.load(dir); f != nil {
+			files = append(files, f)
+			dirs = append(dirs, dir)
+			if f.root {
+				break
+			}
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	var props map[string]string
+	for i := len(files) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(dirs[i], path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, s := range files[i].sections {
+			if !s.pattern.MatchString(rel) {
+				continue
+			}
+			if props == nil {
+				props = make(map[string]string)
+			}
+			for k, v := range s.props {
+				props[k] = v
+			}
+		}
+	}
+	return props
+}
+
+// Charset is like the package-level Charset, with the EditorConfig files
+// read through c.
+func (c *Cache) Charset(path string) string {
+	charset := c.Properties(path)["charset"]
+	if charset == "unset" {
+		return ""
+	}
+	return charset
+}
+
+// load returns the parsed EditorConfig file of dir, or nil when it has none.
+func (c *Cache) load(dir string) *file {
+	path := filepath.Join(dir, FileName)
+	if c == nil {
+		return load(path)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+	c.mu.Lock()
+	cached, ok := c.files[dir]
+	c.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.file
+	}
+
+	f := load(path)
+	c.mu.Lock()
+	c.files[dir] = cachedFile{modTime: info.ModTime(), size: info.Size(), file: f}
+	c.mu.Unlock()
+	return f
+}
+
+// load parses the EditorConfig file at path, or returns nil when it cannot
+// be read.
+func load(path string) *file {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return parse(data)
+}
+
+// parse parses the content of an EditorConfig file. Sections with an
+// invalid glob are left out.
+func parse(data []byte) *file {
+	f := &file{}
+	var current *section
+	skip := false
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				continue
+			}
+			pattern, err := globRegexp(line[1:end])
+			if err != nil {
+				current, skip = nil, true
+				continue
+			}
+			f.sections = append(f.sections, section{pattern: pattern, props: make(map[string]string)})
+			current, skip = &f.sections[len(f.sections)-1], false
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || skip {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if current == nil {
+			// Preamble: only root is defined there.
+			if key == "root" {
+				f.root = value == "true"
+			}
+			continue
+		}
+		current.props[key] = value
+	}
+	return f
+}
+
+var rangePattern = regexp.MustCompile(`^([+-]?\d+)\.\.([+-]?\d+)$`)
+
+// globRegexp compiles an EditorConfig section glob into a regular
+// expression matching slash-separated paths relative to the directory of
+// the EditorConfig file.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	prefix := "^(?:.*/)?"
+	if strings.Contains(glob, "/") {
+		prefix = "^"
+		glob = strings.TrimPrefix(glob, "/")
+	}
+	return regexp.Compile(prefix + translate(glob) + "$")
+}
+
+// translate converts glob to the body of a regular expression.
+func translate(glob string) string {
+	var sb strings.Builder
+	// Whether each open brace starts alternatives, or is literal.
+	var braces []bool
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '{':
+			end := matchingBrace(glob, i)
+			if end < 0 {
+				sb.WriteString(`\{`)
+				continue
+			}
+			inner := glob[i+1 : end]
+			if m := rangePattern.FindStringSubmatch(inner); m != nil {
+				sb.WriteString(numberRange(m[1], m[2]))
+				i = end
+				continue
+			}
+			alternatives := strings.Contains(inner, ",")
+			braces = append(braces, alternatives)
+			if alternatives {
+				sb.WriteString("(?:")
+			} else {
+				// A single word in braces is literal.
+				sb.WriteString(`\{`)
+			}
+		case ',':
+			if len(braces) > 0 && braces[len(braces)-1] {
+				sb.WriteString("|")
+			} else {
+				sb.WriteString(",")
+			}
+		case '}':
+			if len(braces) > 0 && braces[len(braces)-1] {
+				sb.WriteString(")")
+			} else {
+				sb.WriteString(`\}`)
+			}
+			if len(braces) > 0 {
+				braces = braces[:len(braces)-1]
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return sb.String()
+}
+
+// matchingBrace returns the index of the brace closing the one at start,
+// or -1.
+func matchingBrace(glob string, start int) int {
+	depth := 0
+	for i := start; i < len(glob); i++ {
+		switch glob[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// numberRange returns an expression matching the integers from lo to hi,
+// or any integer when the range is too large to list.
+func numberRange(lo, hi string) string {
+	from, errLo := strconv.Atoi(lo)
+	to, errHi := strconv.Atoi(hi)
+	if errLo != nil || errHi != nil {
+		return `[+-]?\d+`
+	}
+	if from > to {
+		from, to = to, from
+	}
+	if to-from > maxRangeSize {
+		return `[+-]?\d+`
+	}
+	alternatives := make([]string, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		alternatives = append(alternatives, regexp.QuoteMeta(strconv.Itoa(n)))
+	}
+	return "(?:" + strings.Join(alternatives, "|") + ")"
+}
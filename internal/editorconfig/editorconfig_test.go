@@ -0,0 +1,122 @@
+package editorconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*", "main.go", true},
+		{"*", "src/main.go", true},
+		{"*.pas", "src/unit.pas", true},
+		{"*.pas", "src/unit.pp", false},
+		{"*.{pas,pp}", "src/unit.pp", true},
+		{"*.{pas,{inc,pp}}", "src/unit.inc", true},
+		{"{Makefile}", "{Makefile}", true},
+		{"unit?.pas", "unit1.pas", true},
+		{"unit[0-9].pas", "unit7.pas", true},
+		{"unit[!0-9].pas", "unit7.pas", false},
+		{"unit{1..3}.pas", "unit2.pas", true},
+		{"unit{1..3}.pas", "unit4.pas", false},
+		{"legacy/*.bas", "legacy/main.bas", true},
+		{"legacy/*.bas", "src/legacy/main.bas", false},
+		{"legacy/*.bas", "legacy/sub/main.bas", false},
+		{"/legacy/**.bas", "legacy/sub/main.bas", true},
+		{"*.c++", "main.c++", true},
+	}
+	for _, tt := range tests {
+		re, err := globRegexp(tt.glob)
+		if err != nil {
+			t.Errorf("globRegexp(%q) error: %v", tt.glob, err)
+			continue
+		}
+		if got := re.MatchString(tt.path); got != tt.match {
+			t.Errorf("glob %q on %q = %v, want %v (%s)", tt.glob, tt.path, got, tt.match, re)
+		}
+	}
+}
+
+func TestCharset(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".editorconfig", `root = true
+
+[*]
+charset = utf-8
+indent_style = space
+
+# Windows resource files
+[*.{rc,reg}]
+charset = UTF-16LE
+
+[legacy/**]
+charset = latin1
+`)
+	write("legacy/tools/.editorconfig", `[*.bas]
+charset = unset
+`)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", "utf-8"},
+		{"win/app.rc", "utf-16le"},
+		{"legacy/unit.pas", "latin1"},
+		{"legacy/tools/old.pas", "latin1"},
+		{"legacy/tools/main.bas", ""},
+	}
+	for _, tt := range tests {
+		if got := Charset(filepath.Join(root, filepath.FromSlash(tt.path))); got != tt.want {
+			t.Errorf("Charset(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if got := Properties(filepath.Join(root, "main.go"))["indent_style"]; got != "space" {
+		t.Errorf("indent_style = %q, want space", got)
+	}
+}
+
+func TestCache(t *testing.T) {
+	root := t.TempDir()
+	config := filepath.Join(root, FileName)
+	if err := os.WriteFile(config, []byte("root = true\n\n[*.pas]\ncharset = latin1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewCache()
+	unit := filepath.Join(root, "src", "unit.pas")
+
+	if got := cache.Charset(unit); got != "latin1" {
+		t.Fatalf("Charset() = %q, want latin1", got)
+	}
+	first := cache.files[root].file
+	if got := cache.Charset(filepath.Join(root, "src", "other.pas")); got != "latin1" {
+		t.Fatalf("Charset() = %q, want latin1", got)
+	}
+	if cache.files[root].file != first {
+		t.Error("expected the unchanged file to be parsed once")
+	}
+
+	// A changed file is parsed again.
+	if err := os.WriteFile(config, []byte("root = true\n\n[*.pas]\ncharset = utf-16le\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.Charset(unit); got != "utf-16le" {
+		t.Errorf("Charset() after the change = %q, want utf-16le", got)
+	}
+}
@@ -0,0 +1,95 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// StripLongPathPrefix removes the Windows extended-length prefix from path:
+// \\?\C:\repo becomes C:\repo and \\?\UNC\server\share becomes \\server\share.
+// The Go runtime adds the prefix itself when needed, while prefixed and plain
+// spellings of the same directory would otherwise compare as different paths.
+func StripLongPathPrefix(path string) string {
+	if rest, ok := strings.CutPrefix(path, `\\?\UNC\`); ok {
+		return `\\` + rest
+	}
+	if rest, ok := strings.CutPrefix(path, `\\?\`); ok {
+		return rest
+	}
+	return path
+}
+
+// PathKey returns the form of an absolute, cleaned path used to compare it
+// with other paths. On Windows, where file systems are case-insensitive, it
+// folds case and separators, so C:/Repo and c:\repo are the same project.
+func PathKey(path string) string {
+	return pathKeyFor(runtime.GOOS, path)
+}
+
+func pathKeyFor(goos, path string) string {
+	if goos != "windows" {
+		return path
+	}
+	return strings.ToLower(strings.ReplaceAll(StripLongPathPrefix(path), "/", `\`))
+}
+
+// IsWithinDir reports whether path is dir or one of its descendants. Both
+// must be absolute and cleaned; they are compared with PathKey.
+func IsWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(PathKey(dir), PathKey(path))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// SymlinkedDirTarget resolves a symlink and reports whether it points to a
+// directory.
+func SymlinkedDirTarget(path string) (string, bool) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	info, err := os.Stat(target)
+	if err != nil || !info.IsDir() {
+		return "", false
+	}
+	return target, true
+}
+
+// DirCycleGuard detects directories that lead back to one of their ancestors
+// through a symlink or a Windows junction, which directory walks would
+// otherwise descend into forever. Recent Go versions report junctions as
+// plain directories, so filepath.Walk and WalkDir do descend into them.
+type DirCycleGuard struct {
+	dirs map[string]os.FileInfo
+}
+
+// NewDirCycleGuard returns a guard for one directory walk.
+func NewDirCycleGuard() *DirCycleGuard {
+	return &DirCycleGuard{dirs: make(map[string]os.FileInfo)}
+}
+
+// IsCycle records the directory at path and reports whether it resolves to
+// the same directory as one of its ancestors recorded earlier in the walk.
+// Directories must be passed parents first, as walks visit them.
+func (g *DirCycleGuard) IsCycle(path string) bool {
+	info, err := os.Stat(path) // follows links, unlike the walk's Lstat
+	if err != nil {
+		return false
+	}
+	for dir := path; ; {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		if ancestor, ok := g.dirs[parent]; ok && os.SameFile(info, ancestor) {
+			return true
+		}
+		dir = parent
+	}
+	g.dirs[path] = info
+	return false
+}
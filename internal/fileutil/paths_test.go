@@ -0,0 +1,61 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStripLongPathPrefix(t *testing.T) {
+	tests := map[string]string{
+		`\\?\C:\repo\src`:        `C:\repo\src`,
+		`\\?\UNC\server\share\x`: `\\server\share\x`,
+		`C:\repo`:                `C:\repo`,
+		`\\server\share`:         `\\server\share`,
+		"/home/user/repo":        "/home/user/repo",
+	}
+	for in, want := range tests {
+		if got := StripLongPathPrefix(in); got != want {
+			t.Errorf("StripLongPathPrefix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPathKeyFor(t *testing.T) {
+	if pathKeyFor("windows", `C:/Users/Dev/Repo`) != pathKeyFor("windows", `\\?\c:\users\dev\repo`) {
+		t.Error("windows keys should ignore case, separators and the long path prefix")
+	}
+	if pathKeyFor("linux", "/src/Repo") == pathKeyFor("linux", "/src/repo") {
+		t.Error("keys on case-sensitive systems must keep case")
+	}
+}
+
+func TestDirCycleGuard(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	loop := filepath.Join(sub, "loop")
+	if err := os.Symlink(filepath.Join(root, "a"), loop); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	sibling := filepath.Join(root, "c")
+	if err := os.Symlink(sub, sibling); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewDirCycleGuard()
+	for _, dir := range []string{root, filepath.Join(root, "a"), sub} {
+		if g.IsCycle(dir) {
+			t.Fatalf("%s wrongly reported as a cycle", dir)
+		}
+	}
+	if !g.IsCycle(loop) {
+		t.Error("link back to an ancestor should be reported as a cycle")
+	}
+	// A link to a directory elsewhere in the tree is a duplicate, not a cycle.
+	if g.IsCycle(sibling) {
+		t.Error("link to a non-ancestor should not be reported as a cycle")
+	}
+}
@@ -0,0 +1,46 @@
+// Package indexformat versions the index files grepai writes with
+// encoding/gob, so that newer builds upgrade older files when loading them
+// and older builds refuse files they cannot read instead of misreading them.
+package indexformat
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTooNew is wrapped by the error returned for a file written by a newer
+// version of grepai. A store that loaded such a file keeps the error and
+// returns it from Persist instead of writing, so that the older build never
+// overwrites data it could not read.
+var ErrTooNew = errors.New("index written by a newer version of grepai")
+
+// Upgrade migrates decoded data from one format version to the next.
+type Upgrade[T any] func(data *T) error
+
+// Check returns an error wrapping ErrTooNew when version is newer than
+// current. name describes the file in the error.
+func Check(name string, version, current int) error {
+	if version > current {
+		return fmt.Errorf("%s has format version %d but this grepai reads up to version %d; upgrade grepai, or delete the file to rebuild it: %w", name, version, current, ErrTooNew)
+	}
+	return nil
+}
+
+// Migrate brings data, decoded from a file of the given format version, to
+// current by running upgrades[v] for each version v from version up to
+// current-1. A missing step means versions v and v+1 decode identically.
+func Migrate[T any](name string, data *T, version, current int, upgrades map[int]Upgrade[T]) error {
+	if err := Check(name, version, current); err != nil {
+		return err
+	}
+	for v := version; v < current; v++ {
+		step := upgrades[v]
+		if step == nil {
+			continue
+		}
+		if err := step(data); err != nil {
+			return fmt.Errorf("failed to upgrade %s from format version %d: %w", name, v, err)
+		}
+	}
+	return nil
+}
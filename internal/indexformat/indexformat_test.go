@@ -0,0 +1,53 @@
+package indexformat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMigrate_RunsStepsInOrder(t *testing.T) {
+	var steps []int
+	upgrades := map[int]Upgrade[[]int]{
+		0: func(data *[]int) error { *data = append(*data, 0); return nil },
+		2: func(data *[]int) error { *data = append(*data, 2); return nil },
+	}
+	if err := Migrate("test index", &steps, 0, 3, upgrades); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(steps) != 2 || steps[0] != 0 || steps[1] != 2 {
+		t.Errorf("expected steps 0 and 2, got %v", steps)
+	}
+
+	steps = nil
+	if err := Migrate("test index", &steps, 3, 3, upgrades); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(steps) != 0 {
+		t.Errorf("expected no steps for a current file, got %v", steps)
+	}
+}
+
+func TestMigrate_StepError(t *testing.T) {
+	data := 0
+	upgrades := map[int]Upgrade[int]{
+		1: func(*int) error { return errors.New("boom") },
+	}
+	err := Migrate("test index", &data, 0, 2, upgrades)
+	if err == nil || !strings.Contains(err.Error(), "from format version 1") {
+		t.Errorf("expected the failing step in the error, got %v", err)
+	}
+}
+
+func TestCheck_TooNew(t *testing.T) {
+	err := Check("vector index", 5, 1)
+	if !errors.Is(err, ErrTooNew) {
+		t.Fatalf("expected ErrTooNew, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "vector index has format version 5") {
+		t.Errorf("expected the file and version in the error, got %v", err)
+	}
+	if err := Check("vector index", 1, 1); err != nil {
+		t.Errorf("expected no error for the current version, got %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+// Package langdetect detects the language of a file when its extension does
+// not tell: well-known file names such as Dockerfile and Makefile, editor
+// modelines, and shebang lines of extensionless scripts.
+//
+// Languages are identified by their usual extension, such as ".py", so
+// callers keyed by extension can treat detected files like any other.
+package langdetect
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Extensions of languages without a usual extension of their own.
+const (
+	ExtDockerfile = ".dockerfile"
+	ExtMakefile   = ".mk"
+)
+
+// HeaderSize is how much of a file Detect reads: shebangs are on the first
+// line, and modelines in the first few lines.
+const HeaderSize = 512
+
+// languageByExt names the language of each extension. Extensions of the
+// same language share a name.
+var languageByExt = map[string]string{
+	".go":         "go",
+	".js":         "javascript",
+	".jsx":        "javascript",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".ts":         "typescript",
+	".tsx":        "typescript",
+	".mts":        "typescript",
+	".py":         "python",
+	".pyi":        "python",
+	".rb":         "ruby",
+	".rs":         "rust",
+	".java":       "java",
+	".kt":         "kotlin",
+	".kts":        "kotlin",
+	".c":          "c",
+	".h":          "c",
+	".cpp":        "cpp",
+	".cc":         "cpp",
+	".cxx":        "cpp",
+	".hpp":        "cpp",
+	".hxx":        "cpp",
+	".cs":         "csharp",
+	".fs":         "fsharp",
+	".fsx":        "fsharp",
+	".fsi":        "fsharp",
+	".pas":        "pascal",
+	".dpr":        "pascal",
+	".sh":         "shell",
+	".bash":       "shell",
+	".zsh":        "shell",
+	".pl":         "perl",
+	".pm":         "perl",
+	".php":        "php",
+	".lua":        "lua",
+	".r":          "r",
+	".groovy":     "groovy",
+	".md":         "markdown",
+	".mdx":        "markdown",
+	".yml":        "yaml",
+	".yaml":       "yaml",
+	".htm":        "html",
+	".html":       "html",
+	".scss":       "css",
+	".css":        "css",
+	".proto":      "protobuf",
+	".tf":         "terraform",
+	".sql":        "sql",
+	ExtDockerfile: "dockerfile",
+	ExtMakefile:   "make",
+}
+
+// extByName maps well-known file names, lowercased, to their language.
+var extByName = map[string]string{
+	"dockerfile":    ExtDockerfile,
+	"containerfile": ExtDockerfile,
+	"makefile":      ExtMakefile,
+	"gnumakefile":   ExtMakefile,
+	"jenkinsfile":   ".groovy",
+	"rakefile":      ".rb",
+	"gemfile":       ".rb",
+	"vagrantfile":   ".rb",
+	"podfile":       ".rb",
+	"brewfile":      ".rb",
+	"guardfile":     ".rb",
+}
+
+// plainNames are well-known extensionless files, lowercased, that are not
+// code, so their content need not be read to tell.
+var plainNames = map[string]bool{
+	"license":      true,
+	"licence":      true,
+	"copying":      true,
+	"notice":       true,
+	"patents":      true,
+	"authors":      true,
+	"contributors": true,
+	"maintainers":  true,
+	"codeowners":   true,
+	"owners":       true,
+	"readme":       true,
+	"changelog":    true,
+	"changes":      true,
+	"history":      true,
+	"news":         true,
+	"todo":         true,
+	"version":      true,
+}
+
+// extByInterpreter maps shebang interpreters, without version suffixes, to
+// their language.
+var extByInterpreter = map[string]string{
+	"sh":      ".sh",
+	"bash":    ".sh",
+	"zsh":     ".sh",
+	"dash":    ".sh",
+	"ksh":     ".sh",
+	"ash":     ".sh",
+	"python":  ".py",
+	"pypy":    ".py",
+	"node":    ".js",
+	"nodejs":  ".js",
+	"deno":    ".ts",
+	"bun":     ".js",
+	"ts-node": ".ts",
+	"tsx":     ".ts",
+	"ruby":    ".rb",
+	"perl":    ".pl",
+	"php":     ".php",
+	"lua":     ".lua",
+	"luajit":  ".lua",
+	"rscript": ".r",
+	"groovy":  ".groovy",
+	"make":    ExtMakefile,
+}
+
+// extByModeline maps modeline file types to their language.
+var extByModeline = map[string]string{
+	"sh":           ".sh",
+	"bash":         ".sh",
+	"shell":        ".sh",
+	"shell-script": ".sh",
+	"zsh":          ".sh",
+	"python":       ".py",
+	"ruby":         ".rb",
+	"perl":         ".pl",
+	"php":          ".php",
+	"lua":          ".lua",
+	"javascript":   ".js",
+	"js":           ".js",
+	"typescript":   ".ts",
+	"groovy":       ".groovy",
+	"make":         ExtMakefile,
+	"makefile":     ExtMakefile,
+	"dockerfile":   ExtDockerfile,
+	"go":           ".go",
+	"c":            ".c",
+	"cpp":          ".cpp",
+	"c++":          ".cpp",
+	"java":         ".java",
+	"rust":         ".rs",
+	"yaml":         ".yml",
+}
+
+// Language returns the name of the language of ext, such as "python" for
+// ".py", or "" when it is not known.
+func Language(ext string) string {
+	return languageByExt[strings.ToLower(ext)]
+}
+
+// NameExt returns the language of a well-known file name such as Makefile,
+// Dockerfile.dev or app.dockerfile, or "" for other names.
+func NameExt(path string) string {
+	base := strings.ToLower(filepath.Base(filepath.ToSlash(path)))
+	if ext, ok := extByName[base]; ok {
+		return ext
+	}
+	// Variants such as Dockerfile.prod and Makefile.linux, but not
+	// dockerfile.go, whose extension names a language of its own.
+	if i := strings.IndexByte(base, '.'); i > 0 && languageByExt[filepath.Ext(base)] == "" {
+		if ext, ok := extByName[base[:i]]; ok && (ext == ExtDockerfile || ext == ExtMakefile) {
+			return ext
+		}
+	}
+	if strings.HasSuffix(base, ".dockerfile") {
+		return ExtDockerfile
+	}
+	return ""
+}
+
+// PlainName reports whether path is a well-known file that is not code,
+// such as LICENSE or CODEOWNERS, so detection need not read it.
+func PlainName(path string) bool {
+	return plainNames[strings.ToLower(filepath.Base(filepath.ToSlash(path)))]
+}
+
+// Detect returns the language of a file its extension does not tell, or ""
+// when the extension is right or nothing is detected. It checks, in order,
+// well-known file names, editor modelines and, for files without a known
+// language extension, shebang lines. header is the start of the file; only
+// its first HeaderSize bytes are used.
+func Detect(path, header string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	detected := NameExt(path)
+	if detected == "" {
+		if len(header) > HeaderSize {
+			header = header[:HeaderSize]
+		}
+		detected = modelineExt(header)
+		// A shebang names the interpreter, which says less than the
+		// extension: a .ts script may well run with node.
+		if detected == "" && languageByExt[ext] == "" {
+			detected = shebangExt(header)
+		}
+	}
+	if detected == ext {
+		return ""
+	}
+	return detected
+}
+
+// Ext returns the language of the file at path: the detected one when
+// there is one, and otherwise its lowercased extension.
+func Ext(path, content string) string {
+	if detected := Detect(path, content); detected != "" {
+		return detected
+	}
+	return strings.ToLower(filepath.Ext(path))
+}
+
+// shebangExt returns the language of the interpreter on a #! first line.
+func shebangExt(header string) string {
+	if !strings.HasPrefix(header, "#!") {
+		return ""
+	}
+	line := header[2:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, f := range fields[1:] {
+			// Skip env flags and variable assignments, as in "env -S node".
+			if strings.HasPrefix(f, "-") || strings.Contains(f, "=") {
+				continue
+			}
+			interpreter = filepath.Base(f)
+			break
+		}
+	}
+	// python3.12 and similar name the same language.
+	interpreter = strings.TrimRight(strings.ToLower(interpreter), "0123456789.")
+	return extByInterpreter[interpreter]
+}
+
+// modelineExt returns the language named by an Emacs "-*- mode: x -*-" or
+// Vim "vim: set ft=x" modeline in the first lines of header.
+func modelineExt(header string) string {
+	lines := strings.SplitN(header, "\n", 6)
+	if len(lines) > 5 {
+		lines = lines[:5]
+	}
+	for _, line := range lines {
+		if name := emacsMode(line); name != "" {
+			return extByModeline[name]
+		}
+		if name := vimFiletype(line); name != "" {
+			return extByModeline[name]
+		}
+	}
+	return ""
+}
+
+func emacsMode(line string) string {
+	start := strings.Index(line, "-*-")
+	if start < 0 {
+		return ""
+	}
+	rest := line[start+3:]
+	end := strings.Index(rest, "-*-")
+	if end < 0 {
+		return ""
+	}
+	vars := strings.TrimSpace(rest[:end])
+	if !strings.Contains(vars, ":") {
+		return strings.ToLower(vars) // -*- python -*-
+	}
+	for _, v := range strings.Split(vars, ";") {
+		key, value, ok := strings.Cut(v, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "mode") {
+			return strings.ToLower(strings.TrimSpace(value))
+		}
+	}
+	return ""
+}
+
+func vimFiletype(line string) string {
+	i := strings.Index(line, "vim:")
+	if i < 0 {
+		i = strings.Index(line, "vi:")
+		if i < 0 {
+			return ""
+		}
+	}
+	for _, f := range strings.FieldsFunc(line[i:], func(r rune) bool { return r == ' ' || r == ':' || r == '\t' }) {
+		for _, key := range []string{"ft=", "filetype=", "syntax="} {
+			if strings.HasPrefix(f, key) {
+				return strings.ToLower(strings.TrimSpace(f[len(key):]))
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		path     string
+		header   string
+		expected string
+	}{
+		{"Dockerfile", "FROM golang:1.22\n", ExtDockerfile},
+		{"build/Dockerfile.prod", "FROM alpine\n", ExtDockerfile},
+		{"app.dockerfile", "FROM alpine\n", ""}, // the extension tells
+		{"dockerfile.go", "package main\n", ""},
+		{"makefile.py", "import os\n", ""},
+		{"Makefile.linux", "all:\n", ExtMakefile},
+		{"Makefile", "all:\n\tgo build\n", ExtMakefile},
+		{"GNUmakefile", "", ExtMakefile},
+		{"Jenkinsfile", "pipeline {}\n", ".groovy"},
+		{"Gemfile", "source 'https://rubygems.org'\n", ".rb"},
+		{"bin/deploy", "#!/usr/bin/env python3\nimport sys\n", ".py"},
+		{"bin/serve", "#!/usr/bin/env -S node --no-warnings\n", ".js"},
+		{"bin/setup", "#!/bin/bash\nset -e\n", ".sh"},
+		{"scripts/run", "#!/usr/bin/python3.12\n", ".py"},
+		{"scripts/notes.txt", "#!/bin/sh\necho hi\n", ".sh"},
+		{"scripts/tool", "# -*- mode: ruby -*-\nputs 1\n", ".rb"},
+		{"scripts/task", "#!/bin/sh\n# vim: set ft=python:\n", ".py"},
+		// A shebang does not override a language extension.
+		{"cli.ts", "#!/usr/bin/env node\n", ""},
+		{"main.py", "#!/usr/bin/env python\n", ""},
+		// The extension is right, or nothing tells.
+		{"main.go", "package main\n", ""},
+		{"LICENSE", "MIT License\n", ""},
+		{"bin/tool", "#!/usr/bin/env unknown-interpreter\n", ""},
+		{"lib.py", "# -*- coding: utf-8 -*-\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Detect(tt.path, tt.header); got != tt.expected {
+			t.Errorf("Detect(%q, %q) = %q, expected %q", tt.path, tt.header, got, tt.expected)
+		}
+	}
+}
+
+func TestExt(t *testing.T) {
+	if got := Ext("bin/deploy", "#!/bin/sh\n"); got != ".sh" {
+		t.Errorf("expected detected .sh, got %q", got)
+	}
+	if got := Ext("Main.GO", "package main\n"); got != ".go" {
+		t.Errorf("expected lowercased extension .go, got %q", got)
+	}
+}
+
+func TestPlainName(t *testing.T) {
+	for _, path := range []string{"LICENSE", "docs/CODEOWNERS", "Readme"} {
+		if !PlainName(path) {
+			t.Errorf("expected %q to be a plain name", path)
+		}
+	}
+	for _, path := range []string{"Makefile", "bin/deploy", "license.go"} {
+		if PlainName(path) {
+			t.Errorf("expected %q not to be a plain name", path)
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	if got := Language(ExtDockerfile); got != "dockerfile" {
+		t.Errorf("expected dockerfile, got %q", got)
+	}
+	if got := Language(".TSX"); got != "typescript" {
+		t.Errorf("expected typescript, got %q", got)
+	}
+	if got := Language(".unknown"); got != "" {
+		t.Errorf("expected no language, got %q", got)
+	}
+}
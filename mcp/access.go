@@ -0,0 +1,300 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	ignore "github.com/sabhiram/go-gitignore"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// accessPolicy hides project paths from the tools following mcp.access.
+// A nil *accessPolicy allows everything.
+type accessPolicy struct {
+	allow *ignore.GitIgnore
+	deny  *ignore.GitIgnore
+}
+
+// newAccessPolicy compiles cfg, returning nil when it restricts nothing.
+func newAccessPolicy(cfg config.AccessConfig) *accessPolicy {
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 {
+		return nil
+	}
+	p := &accessPolicy{}
+	if len(cfg.Allow) > 0 {
+		p.allow = ignore.CompileIgnoreLines(cfg.Allow...)
+	}
+	if len(cfg.Deny) > 0 {
+		p.deny = ignore.CompileIgnoreLines(cfg.Deny...)
+	}
+	return p
+}
+
+// projectAccessPolicy returns the policy of the project at projectRoot.
+func projectAccessPolicy(projectRoot string) *accessPolicy {
+	if projectRoot == "" {
+		return nil
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil
+	}
+	return newAccessPolicy(cfg.MCP.Access)
+}
+
+// allowed reports whether tools may show the project-relative filePath.
+func (p *accessPolicy) allowed(filePath string) bool {
+	if p == nil || filePath == "" {
+		return true
+	}
+	filePath = strings.TrimPrefix(filepath.ToSlash(filePath), "/")
+	if p.deny != nil && p.deny.MatchesPath(filePath) {
+		return false
+	}
+	return p.allow == nil || p.allow.MatchesPath(filePath)
+}
+
+// keepChunk is a search chunk filter applying p, combined with keep when
+// set.
+func (p *accessPolicy) keepChunk(keep func(store.Chunk) bool) func(store.Chunk) bool {
+	if p == nil {
+		return keep
+	}
+	return func(c store.Chunk) bool {
+		return p.allowed(c.FilePath) && (keep == nil || keep(c))
+	}
+}
+
+// restrictStore returns ss hiding the symbols and references located in
+// paths p denies.
+func (p *accessPolicy) restrictStore(ss trace.SymbolStore) trace.SymbolStore {
+	if p == nil || ss == nil {
+		return ss
+	}
+	return &accessSymbolStore{SymbolStore: ss, policy: p}
+}
+
+// restrictGraph returns a copy of g without the nodes located in paths p
+// denies and their edges. g itself is left untouched: the RPG store it
+// belongs to persists it on close.
+func (p *accessPolicy) restrictGraph(g *rpg.Graph) *rpg.Graph {
+	if p == nil || g == nil {
+		return g
+	}
+	restricted := rpg.NewGraph()
+	for _, n := range g.Nodes {
+		if p.allowed(n.Path) {
+			restricted.AddNode(n)
+		}
+	}
+	for _, e := range g.Edges {
+		if restricted.Nodes[e.From] != nil && restricted.Nodes[e.To] != nil {
+			restricted.AddEdge(e)
+		}
+	}
+	return restricted
+}
+
+// restrictWorkspaceStores applies each workspace project's own policy to
+// stores loaded by trace.LoadWorkspaceSymbolStores.
+func restrictWorkspaceStores(workspaceName string, stores []trace.SymbolStore) []trace.SymbolStore {
+	wsCfg, err := config.LoadWorkspaceConfig()
+	if err != nil || wsCfg == nil {
+		return stores
+	}
+	ws, err := wsCfg.GetWorkspace(workspaceName)
+	if err != nil {
+		return stores
+	}
+	roots := make(map[string]string, len(ws.Projects))
+	for _, p := range ws.Projects {
+		roots[p.Name] = p.Path
+	}
+	for i, ss := range stores {
+		if ps, ok := ss.(*trace.ProjectSymbolStore); ok {
+			policy := projectAccessPolicy(roots[ps.Project])
+			stores[i] = &trace.ProjectSymbolStore{SymbolStore: policy.restrictStore(ps.SymbolStore), Project: ps.Project, Root: ps.Root}
+		}
+	}
+	return stores
+}
+
+// workspaceChunkFilter returns a search chunk filter for a workspace store,
+// whose paths are "workspace/project/path", applying each project's policy.
+// It returns nil when no project restricts access.
+func workspaceChunkFilter(ws *config.Workspace) func(store.Chunk) bool {
+	policies := make(map[string]*accessPolicy)
+	for _, p := range ws.Projects {
+		if policy := projectAccessPolicy(p.Path); policy != nil {
+			policies[p.Name] = policy
+		}
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	return func(c store.Chunk) bool {
+		rest := strings.TrimPrefix(c.FilePath, ws.Name+"/")
+		project, filePath, _ := strings.Cut(rest, "/")
+		return policies[project].allowed(filePath)
+	}
+}
+
+// accessSymbolStore filters the reads of a symbol store through a policy.
+type accessSymbolStore struct {
+	trace.SymbolStore
+	policy *accessPolicy
+}
+
+func (s *accessSymbolStore) symbols(symbols []trace.Symbol, err error) ([]trace.Symbol, error) {
+	if err != nil {
+		return nil, err
+	}
+	kept := symbols[:0:0]
+	for _, sym := range symbols {
+		if s.policy.allowed(sym.File) {
+			kept = append(kept, sym)
+		}
+	}
+	return kept, nil
+}
+
+func (s *accessSymbolStore) references(refs []trace.Reference, err error) ([]trace.Reference, error) {
+	if err != nil {
+		return nil, err
+	}
+	kept := refs[:0:0]
+	for _, ref := range refs {
+		if s.policy.allowed(ref.File) && s.policy.allowed(ref.CallerFile) {
+			kept = append(kept, ref)
+		}
+	}
+	return kept, nil
+}
+
+func (s *accessSymbolStore) LookupSymbol(ctx context.Context, name string) ([]trace.Symbol, error) {
+	return s.symbols(s.SymbolStore.LookupSymbol(ctx, name))
+}
+
+func (s *accessSymbolStore) SearchSymbols(ctx context.Context, query string, limit int) ([]trace.SymbolMatch, error) {
+	// Fetch every match so denied ones do not use up the limit.
+	matches, err := s.SymbolStore.SearchSymbols(ctx, query, 0)
+	if err != nil {
+		return nil, err
+	}
+	kept := matches[:0:0]
+	for _, m := range matches {
+		if s.policy.allowed(m.Symbol.File) {
+			kept = append(kept, m)
+		}
+	}
+	if limit > 0 && len(kept) > limit {
+		kept = kept[:limit]
+	}
+	return kept, nil
+}
+
+func (s *accessSymbolStore) LookupCallers(ctx context.Context, symbolName string) ([]trace.Reference, error) {
+	return s.references(s.SymbolStore.LookupCallers(ctx, symbolName))
+}
+
+func (s *accessSymbolStore) LookupCallees(ctx context.Context, symbolName string, file string) ([]trace.Reference, error) {
+	if !s.policy.allowed(file) {
+		return nil, nil
+	}
+	return s.references(s.SymbolStore.LookupCallees(ctx, symbolName, file))
+}
+
+func (s *accessSymbolStore) LookupReaders(ctx context.Context, symbolName string) ([]trace.Reference, error) {
+	return s.references(s.SymbolStore.LookupReaders(ctx, symbolName))
+}
+
+func (s *accessSymbolStore) LookupWriters(ctx context.Context, symbolName string) ([]trace.Reference, error) {
+	return s.references(s.SymbolStore.LookupWriters(ctx, symbolName))
+}
+
+func (s *accessSymbolStore) LookupReferences(ctx context.Context, symbolName string) ([]trace.Reference, error) {
+	return s.references(s.SymbolStore.LookupReferences(ctx, symbolName))
+}
+
+func (s *accessSymbolStore) LookupImplementations(ctx context.Context, methodName string) ([]trace.Implementation, error) {
+	impls, err := s.SymbolStore.LookupImplementations(ctx, methodName)
+	if err != nil {
+		return nil, err
+	}
+	kept := impls[:0:0]
+	for _, impl := range impls {
+		if s.policy.allowed(impl.Method.File) {
+			kept = append(kept, impl)
+		}
+	}
+	return kept, nil
+}
+
+func (s *accessSymbolStore) ListSymbols(ctx context.Context) ([]trace.Symbol, error) {
+	return s.symbols(s.SymbolStore.ListSymbols(ctx))
+}
+
+func (s *accessSymbolStore) ListLiterals(ctx context.Context) ([]trace.Reference, error) {
+	return s.references(s.SymbolStore.ListLiterals(ctx))
+}
+
+func (s *accessSymbolStore) ListRoutes(ctx context.Context) ([]trace.Reference, error) {
+	return s.references(s.SymbolStore.ListRoutes(ctx))
+}
+
+func (s *accessSymbolStore) GetSymbolsForFile(ctx context.Context, filePath string) ([]trace.Symbol, error) {
+	if !s.policy.allowed(filePath) {
+		return nil, nil
+	}
+	return s.SymbolStore.GetSymbolsForFile(ctx, filePath)
+}
+
+func (s *accessSymbolStore) GetCallEdges(ctx context.Context) ([]trace.CallEdge, error) {
+	edges, err := s.SymbolStore.GetCallEdges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	kept := edges[:0:0]
+	for _, e := range edges {
+		if s.policy.allowed(e.File) {
+			kept = append(kept, e)
+		}
+	}
+	return kept, nil
+}
+
+func (s *accessSymbolStore) GetCallGraph(ctx context.Context, symbolName string, depth int) (*trace.CallGraph, error) {
+	graph, err := s.SymbolStore.GetCallGraph(ctx, symbolName, depth)
+	if err != nil || graph == nil {
+		return graph, err
+	}
+	filtered := &trace.CallGraph{Root: graph.Root, Depth: graph.Depth, Nodes: make(map[string]trace.Symbol, len(graph.Nodes))}
+	for name, sym := range graph.Nodes {
+		if s.policy.allowed(sym.File) {
+			filtered.Nodes[name] = sym
+		}
+	}
+	if root, ok := graph.Nodes[graph.Root]; ok && !s.policy.allowed(root.File) {
+		filtered.Nodes = map[string]trace.Symbol{}
+		return filtered, nil
+	}
+	for _, e := range graph.Edges {
+		_, callerKept := filtered.Nodes[e.Caller]
+		_, calleeKept := filtered.Nodes[e.Callee]
+		callerKnown := callerKept || !hasNode(graph, e.Caller)
+		calleeKnown := calleeKept || !hasNode(graph, e.Callee)
+		if s.policy.allowed(e.File) && callerKnown && calleeKnown {
+			filtered.Edges = append(filtered.Edges, e)
+		}
+	}
+	return filtered, nil
+}
+
+func hasNode(graph *trace.CallGraph, name string) bool {
+	_, ok := graph.Nodes[name]
+	return ok
+}
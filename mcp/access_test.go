@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func TestAccessPolicy_Allowed(t *testing.T) {
+	policy := newAccessPolicy(config.AccessConfig{
+		Allow: []string{"src/**", "infra/**"},
+		Deny:  []string{"secrets/**", "infra/prod/**"},
+	})
+	tests := map[string]bool{
+		"src/auth/login.go":     true,
+		"infra/dev/main.tf":     true,
+		"infra/prod/main.tf":    false,
+		"secrets/db.yaml":       false,
+		"README.md":             false,
+		"/src/auth/login.go":    true,
+		"src\\auth\\session.go": filepath.Separator == '\\',
+	}
+	for path, want := range tests {
+		if got := policy.allowed(path); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	if newAccessPolicy(config.AccessConfig{}) != nil {
+		t.Error("expected no policy for an empty config")
+	}
+	var none *accessPolicy
+	if !none.allowed("secrets/db.yaml") {
+		t.Error("expected a nil policy to allow everything")
+	}
+}
+
+func TestAccessPolicy_KeepChunk(t *testing.T) {
+	policy := newAccessPolicy(config.AccessConfig{Deny: []string{"secrets/**"}})
+	keep := policy.keepChunk(func(c store.Chunk) bool { return c.StartLine > 1 })
+
+	if keep(store.Chunk{FilePath: "secrets/db.go", StartLine: 5}) {
+		t.Error("expected denied chunks to be dropped")
+	}
+	if keep(store.Chunk{FilePath: "src/db.go", StartLine: 1}) {
+		t.Error("expected the feature filter to still apply")
+	}
+	if !keep(store.Chunk{FilePath: "src/db.go", StartLine: 5}) {
+		t.Error("expected allowed chunks to be kept")
+	}
+}
+
+func TestAccessSymbolStore(t *testing.T) {
+	ctx := context.Background()
+	ss := trace.NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	if err := ss.SaveFile(ctx, "src/handler.go",
+		[]trace.Symbol{{Name: "Handle", Kind: trace.KindFunction, File: "src/handler.go", Line: 1}},
+		[]trace.Reference{{SymbolName: "LoadKey", Kind: trace.RefKindCall, File: "src/handler.go", Line: 2, CallerName: "Handle", CallerFile: "src/handler.go", CallerLine: 1}},
+	); err != nil {
+		t.Fatalf("failed to save symbols: %v", err)
+	}
+	if err := ss.SaveFile(ctx, "secrets/keys.go",
+		[]trace.Symbol{{Name: "LoadKey", Kind: trace.KindFunction, File: "secrets/keys.go", Line: 1}},
+		[]trace.Reference{{SymbolName: "Handle", Kind: trace.RefKindCall, File: "secrets/keys.go", Line: 3, CallerName: "LoadKey", CallerFile: "secrets/keys.go", CallerLine: 1}},
+	); err != nil {
+		t.Fatalf("failed to save symbols: %v", err)
+	}
+
+	restricted := newAccessPolicy(config.AccessConfig{Deny: []string{"secrets/**"}}).restrictStore(ss)
+
+	symbols, err := restricted.LookupSymbol(ctx, "LoadKey")
+	if err != nil {
+		t.Fatalf("LookupSymbol failed: %v", err)
+	}
+	if len(symbols) != 0 {
+		t.Errorf("expected LoadKey to be hidden, got %+v", symbols)
+	}
+
+	callers, err := restricted.LookupCallers(ctx, "Handle")
+	if err != nil {
+		t.Fatalf("LookupCallers failed: %v", err)
+	}
+	if len(callers) != 0 {
+		t.Errorf("expected the call from secrets/ to be hidden, got %+v", callers)
+	}
+
+	graph, err := restricted.GetCallGraph(ctx, "Handle", 2)
+	if err != nil {
+		t.Fatalf("GetCallGraph failed: %v", err)
+	}
+	if _, ok := graph.Nodes["LoadKey"]; ok {
+		t.Errorf("expected LoadKey to be dropped from the graph, got %+v", graph.Nodes)
+	}
+	for _, e := range graph.Edges {
+		if e.Callee == "LoadKey" || e.Caller == "LoadKey" {
+			t.Errorf("expected edges to LoadKey to be dropped, got %+v", e)
+		}
+	}
+}
+
+func TestAccessPolicy_RestrictGraph(t *testing.T) {
+	g := rpg.NewGraph()
+	g.AddNode(&rpg.Node{ID: "area:auth", Kind: rpg.KindArea, Feature: "auth"})
+	g.AddNode(&rpg.Node{ID: "file:src/auth.go", Kind: rpg.KindFile, Path: "src/auth.go"})
+	g.AddNode(&rpg.Node{ID: "file:secrets/keys.go", Kind: rpg.KindFile, Path: "secrets/keys.go"})
+	g.AddEdge(&rpg.Edge{From: "area:auth", To: "file:src/auth.go", Type: rpg.EdgeContains})
+	g.AddEdge(&rpg.Edge{From: "area:auth", To: "file:secrets/keys.go", Type: rpg.EdgeContains})
+
+	restricted := newAccessPolicy(config.AccessConfig{Deny: []string{"secrets/**"}}).restrictGraph(g)
+	if restricted.GetNode("file:secrets/keys.go") != nil || restricted.GetNode("file:src/auth.go") == nil || restricted.GetNode("area:auth") == nil {
+		t.Errorf("expected only the denied file to be dropped, got %+v", restricted.Nodes)
+	}
+	if len(restricted.Edges) != 1 || restricted.Edges[0].To != "file:src/auth.go" {
+		t.Errorf("expected the edge to the denied file to be dropped, got %+v", restricted.Edges)
+	}
+	if len(g.Nodes) != 3 || len(g.Edges) != 2 {
+		t.Error("expected the original graph to be left untouched")
+	}
+}
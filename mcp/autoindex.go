@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// IndexProgress reports how far a background index build has got.
+type IndexProgress struct {
+	Phase   string // "files" while files are scanned and chunked, "embeddings" while chunks are embedded
+	Current int
+	Total   int
+}
+
+// IndexFunc builds the index of the project at projectRoot, reporting
+// progress to onProgress. It must not write to stdout, which carries MCP
+// messages.
+type IndexFunc func(ctx context.Context, projectRoot string, onProgress func(IndexProgress)) error
+
+// AutoIndexStatus is the state of the background index build started when
+// a search finds the project has no index.
+type AutoIndexStatus struct {
+	State      string `json:"state"` // running | completed | failed
+	Phase      string `json:"phase,omitempty"`
+	Current    int    `json:"current,omitempty"`
+	Total      int    `json:"total,omitempty"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// IndexMissingError is the structured error returned by grepai_search when
+// the project has not been indexed yet.
+type IndexMissingError struct {
+	Error      string           `json:"error"`
+	Message    string           `json:"message"`
+	Suggestion string           `json:"suggestion"`
+	AutoIndex  *AutoIndexStatus `json:"auto_index,omitempty"`
+}
+
+const indexMissingCode = "index_missing"
+
+type autoIndexer struct {
+	mu     sync.Mutex
+	index  IndexFunc
+	status *AutoIndexStatus
+}
+
+// SetAutoIndexer sets the function used to build a missing project index
+// in the background when mcp.auto_index is enabled.
+func (s *Server) SetAutoIndexer(fn IndexFunc) {
+	s.autoIndex.mu.Lock()
+	defer s.autoIndex.mu.Unlock()
+	s.autoIndex.index = fn
+}
+
+// autoIndexStatus returns a copy of the background index build state, or
+// nil if none was started.
+func (s *Server) autoIndexStatus() *AutoIndexStatus {
+	s.autoIndex.mu.Lock()
+	defer s.autoIndex.mu.Unlock()
+	if s.autoIndex.status == nil {
+		return nil
+	}
+	status := *s.autoIndex.status
+	return &status
+}
+
+// startAutoIndex starts a background index build unless one is running or
+// has completed, and returns its state. It returns nil when no index
+// function is set.
+func (s *Server) startAutoIndex() *AutoIndexStatus {
+	s.autoIndex.mu.Lock()
+	defer s.autoIndex.mu.Unlock()
+	if s.autoIndex.index == nil {
+		return nil
+	}
+	if st := s.autoIndex.status; st != nil && st.State != "failed" {
+		status := *st
+		return &status
+	}
+
+	s.autoIndex.status = &AutoIndexStatus{State: "running", StartedAt: time.Now().Format(time.RFC3339)}
+	status := *s.autoIndex.status
+	go s.runAutoIndex(s.autoIndex.index)
+	return &status
+}
+
+func (s *Server) runAutoIndex(index IndexFunc) {
+	log.Printf("Index missing for %s; building it in the background", s.projectRoot)
+	err := index(context.Background(), s.projectRoot, func(p IndexProgress) {
+		s.autoIndex.mu.Lock()
+		defer s.autoIndex.mu.Unlock()
+		s.autoIndex.status.Phase, s.autoIndex.status.Current, s.autoIndex.status.Total = p.Phase, p.Current, p.Total
+	})
+
+	// Drop everything computed from the empty index.
+	s.cache.invalidateResults()
+	s.sharedStoreMu.Lock()
+	s.releaseStoreLocked()
+	s.sharedStoreMu.Unlock()
+
+	s.autoIndex.mu.Lock()
+	defer s.autoIndex.mu.Unlock()
+	s.autoIndex.status.FinishedAt = time.Now().Format(time.RFC3339)
+	if err != nil {
+		log.Printf("Warning: background indexing failed: %v", err)
+		s.autoIndex.status.State = "failed"
+		s.autoIndex.status.Error = err.Error()
+		return
+	}
+	log.Printf("Background indexing complete for %s", s.projectRoot)
+	s.autoIndex.status.State = "completed"
+}
+
+// indexMissing reports whether st holds no chunks at all.
+func indexMissing(ctx context.Context, st store.VectorStore) bool {
+	stats, err := st.GetStats(ctx)
+	return err == nil && stats.TotalChunks == 0
+}
+
+// indexMissingResult builds the grepai_search error for a project without
+// an index, starting a background build when the config allows it.
+func (s *Server) indexMissingResult(cfg *config.Config) *mcp.CallToolResult {
+	result := IndexMissingError{
+		Error:      indexMissingCode,
+		Message:    "the project has not been indexed yet",
+		Suggestion: "Run 'grepai watch' in the project directory (or 'grepai watch --background') to build the index, then retry.",
+	}
+	switch {
+	case s.usesWatcherIndex():
+		result.Message = "the index is still being built by 'grepai serve'"
+		result.Suggestion = "Retry once the initial scan completes."
+	case cfg.MCP.AutoIndex:
+		if status := s.startAutoIndex(); status != nil {
+			result.AutoIndex = status
+			result.Suggestion = "The index is being built in the background. Check progress with grepai_index_status and retry once its auto_index state is 'completed'."
+			if status.State == "completed" {
+				result.Suggestion = "The background index build completed without indexing any files. Check the ignore patterns in .grepai/config.yaml."
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(result.Message)
+	}
+	return mcp.NewToolResultError(string(data))
+}
@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func newUnindexedProject(t *testing.T, autoIndex bool) string {
+	t.Helper()
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"embedding":[1,0,0]}`))
+	}))
+	t.Cleanup(ollama.Close)
+
+	root := t.TempDir()
+	dims := 3
+	cfg := config.DefaultConfig()
+	cfg.Embedder = config.EmbedderConfig{Provider: "ollama", Endpoint: ollama.URL, Model: "test", Dimensions: &dims}
+	cfg.MCP.AutoIndex = autoIndex
+	if err := cfg.Save(root); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func searchIndexMissing(t *testing.T, s *Server) IndexMissingError {
+	t.Helper()
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"query": "func A"}}}
+	result, err := s.handleSearch(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Fatalf("expected an error result, got %+v", result)
+	}
+	var missing IndexMissingError
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &missing); err != nil {
+		t.Fatalf("expected a structured error: %v", err)
+	}
+	if missing.Error != indexMissingCode || missing.Suggestion == "" {
+		t.Fatalf("unexpected error: %+v", missing)
+	}
+	return missing
+}
+
+func TestHandleSearch_IndexMissing(t *testing.T) {
+	s, err := NewServer(newUnindexedProject(t, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := false
+	s.SetAutoIndexer(func(ctx context.Context, projectRoot string, onProgress func(IndexProgress)) error {
+		started = true
+		return nil
+	})
+
+	if missing := searchIndexMissing(t, s); missing.AutoIndex != nil {
+		t.Errorf("expected no background index without mcp.auto_index, got %+v", missing.AutoIndex)
+	}
+	if started {
+		t.Error("the index must not be built without mcp.auto_index")
+	}
+}
+
+func TestHandleSearch_AutoIndex(t *testing.T) {
+	root := newUnindexedProject(t, true)
+	s, err := NewServer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	runs := 0
+	s.SetAutoIndexer(func(ctx context.Context, projectRoot string, onProgress func(IndexProgress)) error {
+		runs++
+		if projectRoot != root {
+			t.Errorf("expected project root %s, got %s", root, projectRoot)
+		}
+		onProgress(IndexProgress{Phase: "files", Current: 3, Total: 10})
+		<-release
+		return nil
+	})
+
+	if missing := searchIndexMissing(t, s); missing.AutoIndex == nil || missing.AutoIndex.State != "running" {
+		t.Fatalf("expected a running background index, got %+v", missing.AutoIndex)
+	}
+	// A second search reports the same build instead of starting another.
+	searchIndexMissing(t, s)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status := s.autoIndexStatus()
+		if status.Phase == "files" && status.Current == 3 && status.Total == 10 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("progress not reported, got %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(release)
+	for s.autoIndexStatus().State == "running" {
+		if time.Now().After(deadline) {
+			t.Fatal("background index did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status := s.autoIndexStatus(); status.State != "completed" || status.FinishedAt == "" {
+		t.Fatalf("unexpected final state: %+v", status)
+	}
+	if runs != 1 {
+		t.Fatalf("expected one background index, got %d", runs)
+	}
+}
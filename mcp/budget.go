@@ -0,0 +1,269 @@
+package mcp
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// Detail dropped from tool output to fit a max_tokens budget, in the order
+// it is dropped.
+const (
+	omitContent = "content"
+	omitContext = "context"
+)
+
+// budgetStage renders a tool's output keeping its first n results. Later
+// stages drop more detail than earlier ones.
+type budgetStage struct {
+	omits  string // detail this stage drops, empty for the full output
+	render func(n int) any
+}
+
+// BudgetedOutput wraps tool output that was trimmed to fit max_tokens.
+type BudgetedOutput struct {
+	Truncated bool     `json:"truncated"`
+	Omitted   []string `json:"omitted,omitempty"` // detail dropped from every result
+	Returned  int      `json:"returned"`          // results kept
+	Available int      `json:"available"`         // results before trimming
+	Results   any      `json:"results"`
+}
+
+// Hints returned with auto-compacted output, telling the agent how to get
+// the detail that was dropped.
+const (
+	searchCompactHint = "content omitted because the full response exceeded the auto-compact threshold; " +
+		"read the files at the returned lines, or repeat the search with compact=false (or a lower limit) to get full content"
+	traceCompactHint = "call-site context omitted because the full response exceeded the auto-compact threshold; " +
+		"repeat the call with compact=false, or use limit/offset to page through full results"
+)
+
+// responseLimits bounds the size of a tool response.
+type responseLimits struct {
+	maxTokens   int // hard budget requested by the caller, 0 for none
+	autoCompact int // size above which output switches to compact, 0 to never
+}
+
+// AutoCompactedOutput wraps output that was switched to compact structs
+// because the full output exceeded the auto-compact threshold.
+type AutoCompactedOutput struct {
+	Compacted bool   `json:"compacted"`
+	Hint      string `json:"hint"`
+	Results   any    `json:"results"`
+}
+
+// responseLimits returns the size limits for a tool call. Auto-compaction
+// only applies when the caller neither set max_tokens nor chose compact
+// explicitly; its threshold comes from the project's mcp.auto_compact_tokens.
+func (s *Server) responseLimits(request mcp.CallToolRequest) responseLimits {
+	limits := responseLimits{maxTokens: request.GetInt("max_tokens", 0)}
+	if limits.maxTokens > 0 {
+		return limits
+	}
+	if _, ok := request.GetArguments()["compact"]; ok {
+		return limits
+	}
+
+	limits.autoCompact = config.DefaultMCPAutoCompactTokens
+	if s.projectRoot != "" {
+		if cfg, err := config.Load(s.projectRoot); err == nil {
+			limits.autoCompact = cfg.MCP.AutoCompactTokens
+		}
+	}
+	if limits.autoCompact < 0 {
+		limits.autoCompact = 0
+	}
+	return limits
+}
+
+// encodeWithinLimits encodes tool output within limits. A max_tokens budget
+// is applied by encodeWithinBudget. Otherwise, when the full output exceeds
+// the auto-compact threshold, the second stage is returned with all results,
+// wrapped in an AutoCompactedOutput carrying hint.
+func encodeWithinLimits(format string, limits responseLimits, total int, hint string, stages ...budgetStage) (string, error) {
+	if limits.maxTokens > 0 || limits.autoCompact <= 0 || len(stages) < 2 {
+		return encodeWithinBudget(format, limits.maxTokens, total, stages...)
+	}
+	full, err := encodeOutput(stages[0].render(total), format)
+	if err != nil || embedder.EstimateTokens(full) <= limits.autoCompact {
+		return full, err
+	}
+	return encodeOutput(AutoCompactedOutput{
+		Compacted: true,
+		Hint:      hint,
+		Results:   stages[1].render(total),
+	}, format)
+}
+
+// encodeWithinBudget encodes the first stage that fits maxTokens with all
+// total results. When none does, results are dropped from the end of the
+// last stage until the output fits. Trimmed output is wrapped in a
+// BudgetedOutput so callers can tell it is incomplete. A maxTokens of zero
+// or less disables the budget.
+func encodeWithinBudget(format string, maxTokens, total int, stages ...budgetStage) (string, error) {
+	full, err := encodeOutput(stages[0].render(total), format)
+	if err != nil || maxTokens <= 0 || embedder.EstimateTokens(full) <= maxTokens {
+		return full, err
+	}
+
+	var omitted []string
+	encode := func(stage budgetStage, n int) (string, error) {
+		return encodeOutput(BudgetedOutput{
+			Truncated: true,
+			Omitted:   omitted,
+			Returned:  n,
+			Available: total,
+			Results:   stage.render(n),
+		}, format)
+	}
+
+	for _, stage := range stages[1:] {
+		if stage.omits != "" {
+			omitted = append(omitted, stage.omits)
+		}
+		out, err := encode(stage, total)
+		if err != nil || embedder.EstimateTokens(out) <= maxTokens {
+			return out, err
+		}
+	}
+
+	// Binary search for the most results that fit; with none fitting, the
+	// empty output is returned regardless.
+	last := stages[len(stages)-1]
+	lo, hi := 0, total-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		out, err := encode(last, mid)
+		if err != nil {
+			return "", err
+		}
+		if embedder.EstimateTokens(out) <= maxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return encode(last, lo)
+}
+
+// searchBudgetStages returns the stages for search results: as requested,
+// without content, then without annotations.
+func searchBudgetStages(results []SearchResult, compact bool) []budgetStage {
+	stages := []budgetStage{{render: func(n int) any {
+		if compact {
+			return compactSearchResults(results[:n])
+		}
+		return results[:n]
+	}}}
+	if !compact {
+		stages = append(stages, budgetStage{omits: omitContent, render: func(n int) any {
+			return compactSearchResults(results[:n])
+		}})
+	}
+	return append(stages, budgetStage{omits: omitContext, render: func(n int) any {
+		bare := compactSearchResults(results[:n])
+		for i := range bare {
+			bare[i].FeaturePath = ""
+			bare[i].Permalink = ""
+			bare[i].Owners = ""
+			bare[i].Coverage = nil
+		}
+		return bare
+	}})
+}
+
+// compactSearchResults drops the content of search results.
+func compactSearchResults(results []SearchResult) []SearchResultCompact {
+	compact := make([]SearchResultCompact, len(results))
+	for i, r := range results {
+		compact[i] = SearchResultCompact{
+			ID:          r.ID,
+			FilePath:    r.FilePath,
+			StartLine:   r.StartLine,
+			EndLine:     r.EndLine,
+			Score:       r.Score,
+			FeaturePath: r.FeaturePath,
+			SymbolName:  r.SymbolName,
+			SymbolKind:  r.SymbolKind,
+			Permalink:   r.Permalink,
+			Owners:      r.Owners,
+			Coverage:    r.Coverage,
+		}
+	}
+	return compact
+}
+
+// traceBudgetStages returns the stages for a callers or callees result:
+// as requested, then without call-site context and docstrings. render
+// builds the tool output for a result.
+func traceBudgetStages(result trace.TraceResult, compact bool, render func(trace.TraceResult, bool) any) []budgetStage {
+	page := func(n int) trace.TraceResult {
+		r := result
+		if r.Callers != nil {
+			r.Callers = r.Callers[:n]
+		}
+		if r.Callees != nil {
+			r.Callees = r.Callees[:n]
+		}
+		return r
+	}
+	return []budgetStage{
+		{render: func(n int) any { return render(page(n), compact) }},
+		{omits: omitContext, render: func(n int) any {
+			r := page(n)
+			r.Callers = append([]trace.CallerInfo(nil), r.Callers...)
+			for i := range r.Callers {
+				r.Callers[i].Symbol.Docstring = ""
+			}
+			r.Callees = append([]trace.CalleeInfo(nil), r.Callees...)
+			for i := range r.Callees {
+				r.Callees[i].Symbol.Docstring = ""
+			}
+			return render(r, true)
+		}},
+	}
+}
+
+// graphBudgetStages returns the stages for a call graph: as built, without
+// docstrings, then keeping the first n edges and the nodes they reach.
+func graphBudgetStages(result trace.TraceResult) []budgetStage {
+	strip := func(n int) trace.TraceResult {
+		r := result
+		if r.Graph == nil {
+			return r
+		}
+		g := *r.Graph
+		g.Edges = g.Edges[:n]
+		keep := map[string]bool{g.Root: true}
+		for _, e := range g.Edges {
+			keep[e.Caller] = true
+			keep[e.Callee] = true
+		}
+		g.Nodes = make(map[string]trace.Symbol, len(keep))
+		for name, sym := range r.Graph.Nodes {
+			if keep[name] || n == len(r.Graph.Edges) {
+				sym.Docstring = ""
+				g.Nodes[name] = sym
+			}
+		}
+		r.Graph = &g
+		return r
+	}
+	return []budgetStage{
+		{render: func(n int) any { return result }},
+		{omits: omitContext, render: func(n int) any { return strip(n) }},
+	}
+}
+
+// traceResultCount returns the number of results a trace budget trims.
+func traceResultCount(result trace.TraceResult) int {
+	switch {
+	case result.Graph != nil:
+		return len(result.Graph.Edges)
+	case result.Callers != nil:
+		return len(result.Callers)
+	default:
+		return len(result.Callees)
+	}
+}
@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func budgetTestResults(n int) []SearchResult {
+	results := make([]SearchResult, n)
+	for i := range results {
+		results[i] = SearchResult{
+			FilePath:    fmt.Sprintf("pkg/file%d.go", i),
+			StartLine:   1,
+			EndLine:     40,
+			Score:       0.9,
+			Content:     strings.Repeat("func body() { return }\n", 40),
+			FeaturePath: "core/indexing/chunking",
+			SymbolName:  "body",
+		}
+	}
+	return results
+}
+
+func decodeBudgeted(t *testing.T, out string) BudgetedOutput {
+	t.Helper()
+	var b BudgetedOutput
+	if err := json.Unmarshal([]byte(out), &b); err != nil {
+		t.Fatalf("expected budgeted output, got %s: %v", out, err)
+	}
+	return b
+}
+
+func TestEncodeWithinBudget_Search(t *testing.T) {
+	results := budgetTestResults(5)
+	stages := searchBudgetStages(results, false)
+
+	t.Run("fits", func(t *testing.T) {
+		out, err := encodeWithinBudget("json", 1_000_000, len(results), stages...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var plain []SearchResult
+		if err := json.Unmarshal([]byte(out), &plain); err != nil || len(plain) != 5 || plain[0].Content == "" {
+			t.Fatalf("expected untouched results, got %s", out)
+		}
+	})
+
+	t.Run("no budget", func(t *testing.T) {
+		out, err := encodeWithinBudget("json", 0, len(results), stages...)
+		if err != nil || strings.Contains(out, "truncated") {
+			t.Fatalf("expected no truncation without a budget, got %s (%v)", out, err)
+		}
+	})
+
+	t.Run("drops content", func(t *testing.T) {
+		out, err := encodeWithinBudget("json", 400, len(results), stages...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := decodeBudgeted(t, out)
+		if !b.Truncated || b.Returned != 5 || b.Available != 5 || len(b.Omitted) != 1 || b.Omitted[0] != omitContent {
+			t.Fatalf("unexpected budgeted output: %+v", b)
+		}
+		if strings.Contains(out, "func body") || !strings.Contains(out, "core/indexing/chunking") {
+			t.Fatalf("expected content dropped and annotations kept, got %s", out)
+		}
+		if embedder.EstimateTokens(out) > 400 {
+			t.Fatalf("output exceeds budget: %d tokens", embedder.EstimateTokens(out))
+		}
+	})
+
+	t.Run("drops results", func(t *testing.T) {
+		out, err := encodeWithinBudget("json", 120, len(results), stages...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := decodeBudgeted(t, out)
+		if b.Returned == 0 || b.Returned >= 5 || len(b.Omitted) != 2 {
+			t.Fatalf("expected some results dropped, got %+v", b)
+		}
+		if strings.Contains(out, "core/indexing/chunking") {
+			t.Fatalf("expected annotations dropped, got %s", out)
+		}
+		if embedder.EstimateTokens(out) > 120 {
+			t.Fatalf("output exceeds budget: %d tokens", embedder.EstimateTokens(out))
+		}
+	})
+}
+
+func TestEncodeWithinBudget_TraceCallers(t *testing.T) {
+	result := trace.TraceResult{Query: "Login", Mode: "fast", Symbol: &trace.Symbol{Name: "Login"}}
+	for i := 0; i < 20; i++ {
+		result.Callers = append(result.Callers, trace.CallerInfo{
+			Symbol:   trace.Symbol{Name: fmt.Sprintf("caller%d", i), File: "a.go", Line: i + 1, Docstring: "Handles a request."},
+			CallSite: trace.CallSite{File: "a.go", Line: i + 2, Context: strings.Repeat("x", 200)},
+		})
+	}
+
+	out, err := encodeWithinBudget("json", 300, len(result.Callers), traceBudgetStages(result, false, callersOutput)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := decodeBudgeted(t, out)
+	if b.Returned >= 20 || b.Available != 20 || b.Omitted[0] != omitContext {
+		t.Fatalf("unexpected budgeted output: %+v", b)
+	}
+	if strings.Contains(out, "Handles a request") || strings.Contains(out, "xxxx") {
+		t.Fatalf("expected context and docstrings dropped, got %s", out)
+	}
+	if len(result.Callers[0].Symbol.Docstring) == 0 {
+		t.Fatal("budgeting must not modify the original result")
+	}
+}
+
+func TestEncodeWithinLimits_AutoCompact(t *testing.T) {
+	results := budgetTestResults(5)
+	stages := searchBudgetStages(results, false)
+
+	t.Run("under threshold", func(t *testing.T) {
+		out, err := encodeWithinLimits("json", responseLimits{autoCompact: 1_000_000}, len(results), searchCompactHint, stages...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var plain []SearchResult
+		if err := json.Unmarshal([]byte(out), &plain); err != nil || len(plain) != 5 || plain[0].Content == "" {
+			t.Fatalf("expected untouched results, got %s", out)
+		}
+	})
+
+	t.Run("over threshold", func(t *testing.T) {
+		out, err := encodeWithinLimits("json", responseLimits{autoCompact: 400}, len(results), searchCompactHint, stages...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var compacted struct {
+			Compacted bool                  `json:"compacted"`
+			Hint      string                `json:"hint"`
+			Results   []SearchResultCompact `json:"results"`
+		}
+		if err := json.Unmarshal([]byte(out), &compacted); err != nil {
+			t.Fatalf("expected auto-compacted output, got %s: %v", out, err)
+		}
+		if !compacted.Compacted || compacted.Hint != searchCompactHint || len(compacted.Results) != 5 {
+			t.Fatalf("unexpected auto-compacted output: %s", out)
+		}
+		if strings.Contains(out, "func body") || !strings.Contains(out, "core/indexing/chunking") {
+			t.Fatalf("expected content dropped and annotations kept, got %s", out)
+		}
+	})
+
+	t.Run("max_tokens takes precedence", func(t *testing.T) {
+		out, err := encodeWithinLimits("json", responseLimits{maxTokens: 120, autoCompact: 400}, len(results), searchCompactHint, stages...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := decodeBudgeted(t, out); !b.Truncated || strings.Contains(out, "hint") {
+			t.Fatalf("expected budgeted output, got %s", out)
+		}
+	})
+}
+
+func TestResponseLimits(t *testing.T) {
+	request := func(args map[string]any) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+	}
+	s := &Server{}
+
+	if got := s.responseLimits(request(map[string]any{})); got.autoCompact != config.DefaultMCPAutoCompactTokens {
+		t.Errorf("expected default auto-compact threshold, got %+v", got)
+	}
+	if got := s.responseLimits(request(map[string]any{"compact": false})); got.autoCompact != 0 {
+		t.Errorf("explicit compact must disable auto-compact, got %+v", got)
+	}
+	if got := s.responseLimits(request(map[string]any{"max_tokens": 500})); got.autoCompact != 0 || got.maxTokens != 500 {
+		t.Errorf("max_tokens must disable auto-compact, got %+v", got)
+	}
+
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.MCP.AutoCompactTokens = -1
+	if err := cfg.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	s = &Server{projectRoot: dir}
+	if got := s.responseLimits(request(map[string]any{})); got.autoCompact != 0 {
+		t.Errorf("negative threshold must disable auto-compact, got %+v", got)
+	}
+}
+
+func TestGraphBudgetStages_KeepsReachedNodes(t *testing.T) {
+	result := trace.TraceResult{Graph: &trace.CallGraph{
+		Root: "A",
+		Nodes: map[string]trace.Symbol{
+			"A": {Name: "A", Docstring: "doc"}, "B": {Name: "B"}, "C": {Name: "C"},
+		},
+		Edges: []trace.CallEdge{{Caller: "A", Callee: "B"}, {Caller: "B", Callee: "C"}},
+	}}
+
+	trimmed := graphBudgetStages(result)[1].render(1).(trace.TraceResult)
+	if len(trimmed.Graph.Edges) != 1 || len(trimmed.Graph.Nodes) != 2 {
+		t.Fatalf("expected 1 edge and its 2 nodes, got %+v", trimmed.Graph)
+	}
+	if trimmed.Graph.Nodes["A"].Docstring != "" || result.Graph.Nodes["A"].Docstring != "doc" {
+		t.Fatal("expected docstrings dropped from the copy only")
+	}
+	if traceResultCount(result) != 2 {
+		t.Fatalf("traceResultCount = %d, want 2", traceResultCount(result))
+	}
+}
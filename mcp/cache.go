@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// maxCacheEntries bounds each session cache; the oldest entry is evicted
+// first.
+const maxCacheEntries = 256
+
+// sessionCache keeps query embeddings and search results for the lifetime
+// of the server, so an agent repeating or refining a query does not pay
+// embedder and index load latency every time. Entries expire after a TTL,
+// and search results are also dropped when the index version they were
+// computed from changes.
+type sessionCache struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	embeddings map[string]cacheEntry
+	results    map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   any
+	version string
+	stored  time.Time
+	expires time.Time
+}
+
+func newSessionCache() *sessionCache {
+	return &sessionCache{
+		now:        time.Now,
+		embeddings: make(map[string]cacheEntry),
+		results:    make(map[string]cacheEntry),
+	}
+}
+
+func (c *sessionCache) get(entries map[string]cacheEntry, key, version string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := entries[key]
+	if !ok {
+		return nil, false
+	}
+	if entry.version != version || !c.now().Before(entry.expires) {
+		delete(entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *sessionCache) put(entries map[string]cacheEntry, key, version string, value any, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if _, ok := entries[key]; !ok && len(entries) >= maxCacheEntries {
+		oldest := ""
+		for k, e := range entries {
+			if oldest == "" || e.stored.Before(entries[oldest].stored) {
+				oldest = k
+			}
+		}
+		delete(entries, oldest)
+	}
+	entries[key] = cacheEntry{value: value, version: version, stored: now, expires: now.Add(ttl)}
+}
+
+// searchResults returns cached results for a search key computed from the
+// given index version.
+func (c *sessionCache) searchResults(key, version string) ([]store.SearchResult, bool) {
+	if c == nil {
+		return nil, false
+	}
+	v, ok := c.get(c.results, key, version)
+	if !ok {
+		return nil, false
+	}
+	return v.([]store.SearchResult), true
+}
+
+func (c *sessionCache) putSearchResults(key, version string, results []store.SearchResult, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+	c.put(c.results, key, version, results, ttl)
+}
+
+// invalidateResults drops every cached search result. Query embeddings do
+// not depend on the index and are kept.
+func (c *sessionCache) invalidateResults() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.results)
+}
+
+// cachingEmbedder serves query embeddings from a session cache. Batch
+// embedding is only used for indexing and is passed through.
+type cachingEmbedder struct {
+	embedder.Embedder
+	cache *sessionCache
+	model string // identifies the embedding model in cache keys
+	ttl   time.Duration
+}
+
+// wrapEmbedder returns emb with its query embeddings cached for ttl.
+func (c *sessionCache) wrapEmbedder(emb embedder.Embedder, cfg config.EmbedderConfig, ttl time.Duration) embedder.Embedder {
+	if c == nil || ttl <= 0 {
+		return emb
+	}
+	model := strings.Join([]string{cfg.Provider, cfg.Endpoint, cfg.Model, fmt.Sprint(cfg.GetDimensions())}, "|")
+	return &cachingEmbedder{Embedder: emb, cache: c, model: model, ttl: ttl}
+}
+
+func (e *cachingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	key := e.model + "\x00" + text
+	if v, ok := e.cache.get(e.cache.embeddings, key, ""); ok {
+		return v.([]float32), nil
+	}
+	vec, err := e.Embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	e.cache.put(e.cache.embeddings, key, "", vec, e.ttl)
+	return vec, nil
+}
+
+// cacheTTL returns the cache lifetime configured for a project.
+func cacheTTL(cfg *config.Config) time.Duration {
+	if cfg == nil || cfg.MCP.CacheTTLSeconds < 0 {
+		return 0
+	}
+	if cfg.MCP.CacheTTLSeconds == 0 {
+		return config.DefaultMCPCacheTTLSeconds * time.Second
+	}
+	return time.Duration(cfg.MCP.CacheTTLSeconds) * time.Second
+}
+
+// workspaceCacheTTL returns the cache lifetime for workspace searches: the
+// local project's setting when the server runs in one, the default
+// otherwise.
+func (s *Server) workspaceCacheTTL() time.Duration {
+	if s.projectRoot != "" {
+		if cfg, err := config.Load(s.projectRoot); err == nil {
+			return cacheTTL(cfg)
+		}
+	}
+	return config.DefaultMCPCacheTTLSeconds * time.Second
+}
+
+// searchCacheKey identifies a search by everything that affects its raw
+// results.
+func searchCacheKey(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+type countingEmbedder struct {
+	calls int
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.calls++
+	return []float32{float32(len(text))}, nil
+}
+
+func (e *countingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, nil
+}
+
+func (e *countingEmbedder) Dimensions() int { return 1 }
+
+func (e *countingEmbedder) Close() error { return nil }
+
+func TestSessionCache_Embeddings(t *testing.T) {
+	cache := newSessionCache()
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	inner := &countingEmbedder{}
+	emb := cache.wrapEmbedder(inner, config.EmbedderConfig{Provider: "ollama", Model: "nomic-embed-text"}, time.Minute)
+	for i := 0; i < 3; i++ {
+		if _, err := emb.Embed(context.Background(), "auth flow"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected one embedder call for a repeated query, got %d", inner.calls)
+	}
+
+	other := cache.wrapEmbedder(inner, config.EmbedderConfig{Provider: "ollama", Model: "other-model"}, time.Minute)
+	if _, err := other.Embed(context.Background(), "auth flow"); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("expected embeddings to be cached per model, got %d calls", inner.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := emb.Embed(context.Background(), "auth flow"); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 3 {
+		t.Fatalf("expected an expired embedding to be recomputed, got %d calls", inner.calls)
+	}
+}
+
+func TestSessionCache_SearchResults(t *testing.T) {
+	cache := newSessionCache()
+	results := []store.SearchResult{{Chunk: store.Chunk{FilePath: "a.go"}, Score: 0.9}}
+
+	cache.putSearchResults("q", "v1", results, time.Minute)
+	if got, ok := cache.searchResults("q", "v1"); !ok || len(got) != 1 {
+		t.Fatalf("expected a cache hit, got %v %v", got, ok)
+	}
+	if _, ok := cache.searchResults("q", "v2"); ok {
+		t.Fatal("expected a miss once the index version changes")
+	}
+	if _, ok := cache.searchResults("q", "v1"); ok {
+		t.Fatal("expected the stale entry to be dropped")
+	}
+
+	cache.putSearchResults("q", "v1", results, 0)
+	if _, ok := cache.searchResults("q", "v1"); ok {
+		t.Fatal("expected nothing cached with caching disabled")
+	}
+
+	for i := 0; i < maxCacheEntries+10; i++ {
+		cache.putSearchResults(fmt.Sprintf("q%d", i), "v1", results, time.Minute)
+	}
+	if len(cache.results) != maxCacheEntries {
+		t.Fatalf("expected the cache to stay bounded at %d entries, got %d", maxCacheEntries, len(cache.results))
+	}
+
+	cache.invalidateResults()
+	if len(cache.results) != 0 {
+		t.Fatal("expected invalidateResults to drop every result")
+	}
+
+	var nilCache *sessionCache
+	nilCache.putSearchResults("q", "v1", results, time.Minute)
+	if _, ok := nilCache.searchResults("q", "v1"); ok {
+		t.Fatal("a nil cache must never hit")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	if got := cacheTTL(&config.Config{MCP: config.MCPConfig{CacheTTLSeconds: 30}}); got != 30*time.Second {
+		t.Errorf("expected 30s, got %v", got)
+	}
+	if got := cacheTTL(&config.Config{MCP: config.MCPConfig{CacheTTLSeconds: -1}}); got != 0 {
+		t.Errorf("expected caching disabled, got %v", got)
+	}
+}
+
+func TestHandleSearch_ServesRepeatedQueryFromCache(t *testing.T) {
+	var embedCalls atomic.Int32
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		embedCalls.Add(1)
+		_, _ = w.Write([]byte(`{"embedding":[1,0,0]}`))
+	}))
+	defer ollama.Close()
+
+	root := t.TempDir()
+	dims := 3
+	cfg := config.DefaultConfig()
+	cfg.Embedder = config.EmbedderConfig{Provider: "ollama", Endpoint: ollama.URL, Model: "test", Dimensions: &dims}
+	if err := cfg.Save(root); err != nil {
+		t.Fatal(err)
+	}
+	gob := store.NewGOBStore(config.GetIndexPath(root))
+	if err := gob.SaveChunks(context.Background(), []store.Chunk{{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 2, Content: "func A() {}", Vector: []float32{1, 0, 0}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gob.Persist(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	version := indexVersion(root)
+
+	s, err := NewServer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"query": "func A"}}}
+	for i := 0; i < 2; i++ {
+		result, err := s.handleSearch(context.Background(), req)
+		if err != nil || result.IsError {
+			t.Fatalf("search failed: %v %+v", err, result)
+		}
+	}
+
+	if got := embedCalls.Load(); got != 1 {
+		t.Fatalf("expected the repeated query to be served from cache, got %d embedder calls", got)
+	}
+	if indexVersion(root) != version {
+		t.Fatal("searching must not rewrite the index")
+	}
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/daemon"
+	"github.com/yoanbernabeu/grepai/git"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// staleSampleSize is how many indexed files grepai_index_status re-hashes
+// in verbose mode to estimate how stale an index is.
+const staleSampleSize = 50
+
+// IndexHealth is the verbose grepai_index_status report telling an agent
+// whether a project's index can be trusted.
+type IndexHealth struct {
+	LastIndexed  string `json:"last_indexed"`
+	SampledFiles int    `json:"sampled_files"`
+	StaleFiles   int    `json:"stale_files"` // sampled files changed or deleted since they were indexed
+	Watcher      string `json:"watcher"`     // running | stopped | in_process
+	WatcherPID   int    `json:"watcher_pid,omitempty"`
+	SymbolsReady bool   `json:"symbols_ready"`
+	RPGReady     bool   `json:"rpg_ready"`
+}
+
+// formatLastIndexed returns the time a project was last indexed: the
+// watcher's last index time when recorded, the newest chunk otherwise.
+func formatLastIndexed(lastIndexTime, lastUpdated time.Time) string {
+	if lastIndexTime.IsZero() {
+		lastIndexTime = lastUpdated
+	}
+	if lastIndexTime.IsZero() {
+		return "never"
+	}
+	return lastIndexTime.Format("2006-01-02 15:04:05")
+}
+
+// sampleStaleFiles re-hashes up to staleSampleSize of the files indexed
+// under pathPrefix, spread evenly over the sorted file list, and counts
+// those whose content no longer matches the index. Paths in st are
+// pathPrefix followed by the file path relative to projectRoot.
+func sampleStaleFiles(ctx context.Context, st store.VectorStore, projectRoot, pathPrefix string) (sampled, stale int, err error) {
+	docs, err := st.ListDocuments(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	paths := make([]string, 0, len(docs))
+	for _, p := range docs {
+		if strings.HasPrefix(p, pathPrefix) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	step := 1
+	if len(paths) > staleSampleSize {
+		step = len(paths) / staleSampleSize
+	}
+	for i := 0; i < len(paths) && sampled < staleSampleSize; i += step {
+		doc, err := st.GetDocument(ctx, paths[i])
+		if err != nil || doc == nil {
+			continue
+		}
+		sampled++
+		rel := strings.TrimPrefix(paths[i], pathPrefix)
+		hash, err := indexer.HashFile(filepath.Join(projectRoot, filepath.FromSlash(rel)))
+		if err != nil || hash != doc.Hash {
+			stale++
+		}
+	}
+	return sampled, stale, nil
+}
+
+// projectWatcherPID returns the PID of the watch daemon running for a
+// project, or 0 if none is. Like 'grepai status', it checks the log
+// directory recorded by 'grepai watch --log-dir' before the default one,
+// and the project's worktree watcher before the legacy single watcher.
+func projectWatcherPID(projectRoot string) int {
+	defaultLogDir, err := daemon.GetDefaultLogDir()
+	if err != nil {
+		return 0
+	}
+	logDirs := []string{defaultLogDir}
+	if data, err := os.ReadFile(config.GetWatchLogDirPath(projectRoot)); err == nil {
+		if hinted := filepath.Clean(strings.TrimSpace(string(data))); hinted != "." && hinted != filepath.Clean(defaultLogDir) {
+			logDirs = []string{hinted, defaultLogDir}
+		}
+	}
+
+	var worktreeID string
+	if info, err := git.Detect(projectRoot); err == nil {
+		worktreeID = info.WorktreeID
+	}
+	for _, logDir := range logDirs {
+		if worktreeID != "" {
+			if pid, _ := daemon.GetRunningWorktreePID(logDir, worktreeID); pid > 0 {
+				return pid
+			}
+		}
+		if pid, _ := daemon.GetRunningPID(logDir); pid > 0 {
+			return pid
+		}
+	}
+	return 0
+}
+
+// watcherState describes a watcher PID as reported in IndexHealth.
+func watcherState(pid int) string {
+	if pid > 0 {
+		return "running"
+	}
+	return "stopped"
+}
+
+// rpgIndexExists reports whether a project has a non-empty RPG index file.
+func rpgIndexExists(projectRoot string) bool {
+	info, err := os.Stat(config.GetRPGIndexPath(projectRoot))
+	return err == nil && info.Size() > 0
+}
+
+// workspaceProjectHealth builds the verbose status of a workspace project.
+// wsStore may be nil when the workspace store could not be opened.
+func workspaceProjectHealth(ctx context.Context, wsStore store.VectorStore, workspaceName string, p config.ProjectEntry, symbolsReady bool, watcherPID int) *IndexHealth {
+	health := &IndexHealth{
+		LastIndexed:  "never",
+		Watcher:      watcherState(watcherPID),
+		WatcherPID:   watcherPID,
+		SymbolsReady: symbolsReady,
+	}
+	if cfg, err := config.Load(p.Path); err == nil {
+		health.LastIndexed = formatLastIndexed(cfg.Watch.LastIndexTime, time.Time{})
+		health.RPGReady = cfg.RPG.Enabled && rpgIndexExists(p.Path)
+	}
+	if wsStore != nil {
+		sampled, stale, err := sampleStaleFiles(ctx, wsStore, p.Path, workspaceName+"/"+p.Name+"/")
+		if err == nil {
+			health.SampledFiles, health.StaleFiles = sampled, stale
+		}
+	}
+	return health
+}
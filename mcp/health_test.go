@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// indexFiles writes files under root and records them in st at
+// pathPrefix, hashed as they are on disk.
+func indexFiles(t *testing.T, st store.VectorStore, root, pathPrefix string, files map[string]string) {
+	t.Helper()
+	ctx := context.Background()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := indexer.HashFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := pathPrefix + name + "_0"
+		if err := st.SaveChunks(ctx, []store.Chunk{{ID: id, FilePath: pathPrefix + name, StartLine: 1, EndLine: 1, Content: content, Vector: []float32{1, 0, 0}, UpdatedAt: time.Now()}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.SaveDocument(ctx, store.Document{Path: pathPrefix + name, Hash: hash, ChunkIDs: []string{id}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSampleStaleFiles(t *testing.T) {
+	root := t.TempDir()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	indexFiles(t, st, root, "ws/api/", map[string]string{"a.go": "package a", "b.go": "package b", "c.go": "package c"})
+	indexFiles(t, st, t.TempDir(), "ws/web/", map[string]string{"d.go": "package d"})
+
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("package a // edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	sampled, stale, err := sampleStaleFiles(context.Background(), st, root, "ws/api/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sampled != 3 || stale != 2 {
+		t.Fatalf("expected 2 of 3 sampled files to be stale, got %d of %d", stale, sampled)
+	}
+}
+
+func TestFormatLastIndexed(t *testing.T) {
+	indexed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	updated := time.Date(2026, 2, 1, 12, 0, 0, 0, time.Local)
+	if got := formatLastIndexed(indexed, updated); got != "2026-03-01 12:00:00" {
+		t.Errorf("expected the watcher's last index time, got %s", got)
+	}
+	if got := formatLastIndexed(time.Time{}, updated); got != "2026-02-01 12:00:00" {
+		t.Errorf("expected the newest chunk time, got %s", got)
+	}
+	if got := formatLastIndexed(time.Time{}, time.Time{}); got != "never" {
+		t.Errorf("expected never, got %s", got)
+	}
+}
+
+func TestHandleIndexStatus_Verbose(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	if err := config.DefaultConfig().Save(root); err != nil {
+		t.Fatal(err)
+	}
+	gob := store.NewGOBStore(config.GetIndexPath(root))
+	indexFiles(t, gob, root, "", map[string]string{"a.go": "package a", "b.go": "package b"})
+	if err := gob.Persist(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "b.go"), []byte("package b // edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewServer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := func(verbose bool) IndexStatus {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"verbose": verbose}}}
+		result, err := s.handleIndexStatus(context.Background(), req)
+		if err != nil || result.IsError {
+			t.Fatalf("index status failed: %v %+v", err, result)
+		}
+		var out IndexStatus
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if out := status(false); out.Health != nil {
+		t.Errorf("expected no health report without verbose, got %+v", out.Health)
+	}
+	health := status(true).Health
+	if health == nil {
+		t.Fatal("expected a health report in verbose mode")
+	}
+	if health.SampledFiles != 2 || health.StaleFiles != 1 {
+		t.Errorf("expected 1 of 2 sampled files to be stale, got %+v", health)
+	}
+	if health.Watcher != "stopped" || health.LastIndexed == "never" {
+		t.Errorf("unexpected health report: %+v", health)
+	}
+
+	s.UseWatcherIndex(gob, nil)
+	if health := status(true).Health; health.Watcher != "in_process" {
+		t.Errorf("expected the in-process watcher to be reported, got %s", health.Watcher)
+	}
+}
+
+func TestHandleIndexStatus_Breakdown(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	if err := config.DefaultConfig().Save(root); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	gob := store.NewGOBStore(config.GetIndexPath(root))
+	indexFiles(t, gob, root, "", map[string]string{"a.go": "package a", "docs/guide.md": "# Guide", "docs/api.md": "# API"})
+	if err := gob.Persist(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewServer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"breakdown": true}}}
+	result, err := s.handleIndexStatus(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("index status failed: %v %+v", err, result)
+	}
+	var out IndexStatus
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.Directories) != 2 || out.Directories[0].Name != "docs" || out.Directories[0].Files != 2 {
+		t.Errorf("unexpected directories: %+v", out.Directories)
+	}
+	if len(out.Languages) != 2 || out.Languages[0].Name != "markdown" || out.Languages[1].Name != "go" {
+		t.Errorf("unexpected languages: %+v", out.Languages)
+	}
+}
@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// indexSignalPollInterval is how often the server checks the project's
+// index signal file for updates from the watch daemon.
+const indexSignalPollInterval = 2 * time.Second
+
+// indexVersion stamps the state of a project's index, so results computed
+// from an older index are not reused. When a watch daemon signals index
+// updates, its signal replaces the index file stamps, which also change on
+// persists that write no new data. Without a signal, backends whose index
+// is not a local file rely on the cache TTL alone.
+func indexVersion(projectRoot string) string {
+	paths := []string{config.GetConfigPath(projectRoot), config.GetRPGIndexPath(projectRoot)}
+	signal, err := os.ReadFile(config.GetIndexSignalPath(projectRoot))
+	if err != nil {
+		paths = append(paths, config.GetIndexPath(projectRoot), config.GetSymbolIndexPath(projectRoot))
+	}
+
+	stamps := []string{strings.TrimSpace(string(signal))}
+	for _, path := range paths {
+		stamps = append(stamps, fileStamp(path))
+	}
+	return strings.Join(stamps, ";")
+}
+
+// UseWatcherIndex makes the server read the project index from stores kept
+// up to date by a watcher running in the same process, instead of loading
+// it from disk. The server never closes them. Passing nil stores reverts to
+// loading the index from disk, for example when the watcher stops.
+func (s *Server) UseWatcherIndex(st store.VectorStore, symbols trace.SymbolStore) {
+	s.watcherMu.Lock()
+	defer s.watcherMu.Unlock()
+	s.watcherStore = st
+	s.watcherSymbols = symbols
+}
+
+// UseEmbedder makes the server embed project queries with emb instead of
+// creating an embedder per request. The server never closes it.
+func (s *Server) UseEmbedder(emb embedder.Embedder) {
+	s.watcherMu.Lock()
+	defer s.watcherMu.Unlock()
+	s.watcherEmbedder = emb
+}
+
+func (s *Server) usesWatcherIndex() bool {
+	s.watcherMu.RLock()
+	defer s.watcherMu.RUnlock()
+	return s.watcherStore != nil
+}
+
+// projectEmbedder returns the embedder for project queries and a func
+// releasing it.
+func (s *Server) projectEmbedder(cfg *config.Config) (embedder.Embedder, func(), error) {
+	s.watcherMu.RLock()
+	emb := s.watcherEmbedder
+	s.watcherMu.RUnlock()
+	if emb != nil {
+		return emb, func() {}, nil
+	}
+
+	emb, err := s.createEmbedder(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return emb, func() { emb.Close() }, nil
+}
+
+// loadSymbolStore returns the project's symbol index and a func releasing
+// it. A symbol index loaded from disk is persisted on release.
+func (s *Server) loadSymbolStore(ctx context.Context) (trace.SymbolStore, func(), error) {
+	s.watcherMu.RLock()
+	symbols := s.watcherSymbols
+	s.watcherMu.RUnlock()
+	if symbols != nil {
+		return projectAccessPolicy(s.projectRoot).restrictStore(symbols), func() {}, nil
+	}
+
+	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
+	if err := symbolStore.Load(ctx); err != nil {
+		return nil, nil, err
+	}
+	return projectAccessPolicy(s.projectRoot).restrictStore(symbolStore), func() { symbolStore.Close() }, nil
+}
+
+// loadProjectSymbolStore loads the symbol store of the served project for
+// tool, like loadSymbolStore, and checks that it has symbols. When the store
+// cannot be used, it returns the error result the tool must send instead.
+func (s *Server) loadProjectSymbolStore(ctx context.Context, tool string) (trace.SymbolStore, func(), *mcp.CallToolResult) {
+	if s.projectRoot == "" {
+		return nil, nil, mcp.NewToolResultError(tool + " requires a project context; use --workspace parameter or start mcp-serve from a project directory")
+	}
+	symbolStore, release, err := s.loadSymbolStore(ctx)
+	if err != nil {
+		return nil, nil, mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'grepai watch' first", err))
+	}
+	stats, err := symbolStore.GetStats(ctx)
+	if err != nil || stats.TotalSymbols == 0 {
+		release()
+		return nil, nil, mcp.NewToolResultError("symbol index is empty. Run 'grepai watch' first to build the index")
+	}
+	return symbolStore, release, nil
+}
+
+// loadWorkspaceSymbolStores loads the symbol indexes of a workspace's
+// projects, each restricted by its project's mcp.access policy.
+func loadWorkspaceSymbolStores(ctx context.Context, workspaceName, projectName string) ([]trace.SymbolStore, error) {
+	stores, err := trace.LoadWorkspaceSymbolStores(ctx, workspaceName, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return restrictWorkspaceStores(workspaceName, stores), nil
+}
+
+// projectStore returns the project's vector store, kept open across
+// requests. A GOB index is reloaded when the index version changes; other
+// backends read live data and are only reopened when the config changes.
+// A watcher's store is used as is. The returned store must not be closed by
+// the caller.
+func (s *Server) projectStore(ctx context.Context, cfg *config.Config, version string) (store.VectorStore, error) {
+	s.watcherMu.RLock()
+	watched := s.watcherStore
+	s.watcherMu.RUnlock()
+	if watched != nil {
+		return watched, nil
+	}
+
+	key := version
+	if cfg.Store.Backend != "gob" {
+		key = fileStamp(config.GetConfigPath(s.projectRoot))
+	}
+
+	s.sharedStoreMu.Lock()
+	defer s.sharedStoreMu.Unlock()
+	if s.sharedStore != nil && s.sharedStoreKey == key {
+		return s.sharedStore, nil
+	}
+
+	st, err := s.createStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.releaseStoreLocked()
+	s.sharedStore, s.sharedStoreKey = st, key
+	return st, nil
+}
+
+// releaseStoreLocked drops the shared store. GOB stores are only read by
+// the server and are released without persisting them, which closes the
+// file lazily loaded content is read from so that watch can replace it.
+func (s *Server) releaseStoreLocked() {
+	if s.sharedStore == nil {
+		return
+	}
+	if gs, ok := s.sharedStore.(*store.GOBStore); ok {
+		gs.Release()
+	} else if err := s.sharedStore.Close(); err != nil {
+		log.Printf("Warning: failed to close store: %v", err)
+	}
+	s.sharedStore, s.sharedStoreKey = nil, ""
+}
+
+// watchIndexSignal polls the project's index signal until ctx is done. When
+// the watch daemon signals an update, cached results are dropped and a
+// stale GOB index is released instead of waiting for the next request.
+func (s *Server) watchIndexSignal(ctx context.Context, interval time.Duration) {
+	if s.projectRoot == "" {
+		return
+	}
+	path := config.GetIndexSignalPath(s.projectRoot)
+	last := fileStamp(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stamp := fileStamp(path)
+			if stamp == last {
+				continue
+			}
+			last = stamp
+			s.cache.invalidateResults()
+			s.sharedStoreMu.Lock()
+			if _, ok := s.sharedStore.(*store.GOBStore); ok {
+				s.releaseStoreLocked()
+			}
+			s.sharedStoreMu.Unlock()
+		}
+	}
+}
+
+// fileStamp identifies the current content of a file by its modification
+// time and size.
+func fileStamp(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d.%d", info.ModTime().UnixNano(), info.Size())
+}
@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func writeTestIndex(t *testing.T, root string, chunks ...store.Chunk) {
+	t.Helper()
+	gob := store.NewGOBStore(config.GetIndexPath(root))
+	if err := gob.SaveChunks(context.Background(), chunks); err != nil {
+		t.Fatal(err)
+	}
+	if err := gob.Persist(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexVersion_ChangesWithIndex(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(config.GetConfigDir(root), 0755); err != nil {
+		t.Fatal(err)
+	}
+	before := indexVersion(root)
+
+	if err := os.WriteFile(config.GetIndexPath(root), []byte("index"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if after := indexVersion(root); after == before {
+		t.Fatalf("expected the version to change when the index is written, got %q", after)
+	}
+}
+
+func TestIndexVersion_FollowsWatchSignal(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(config.GetConfigDir(root), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SignalIndexUpdated(root); err != nil {
+		t.Fatal(err)
+	}
+	before := indexVersion(root)
+
+	// A persist without a signal writes no new data for readers.
+	if err := os.WriteFile(config.GetIndexPath(root), []byte("index"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := indexVersion(root); got != before {
+		t.Fatalf("expected the signal to decide the version, got %q then %q", before, got)
+	}
+
+	time.Sleep(time.Millisecond)
+	if err := config.SignalIndexUpdated(root); err != nil {
+		t.Fatal(err)
+	}
+	if got := indexVersion(root); got == before {
+		t.Fatal("expected a new signal to change the version")
+	}
+}
+
+func TestProjectStore_ReloadsGOBOnNewVersion(t *testing.T) {
+	root := t.TempDir()
+	writeTestIndex(t, root, store.Chunk{ID: "a.go_0", FilePath: "a.go", Vector: []float32{1, 0}})
+	cfg := config.DefaultConfig()
+	s := &Server{projectRoot: root}
+	ctx := context.Background()
+
+	first, err := s.projectStore(ctx, cfg, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := s.projectStore(ctx, cfg, "v1"); again != first {
+		t.Fatal("expected the store to be reused for the same index version")
+	}
+
+	writeTestIndex(t, root, store.Chunk{ID: "b.go_0", FilePath: "b.go", Vector: []float32{0, 1}})
+	reloaded, err := s.projectStore(ctx, cfg, "v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded == first {
+		t.Fatal("expected the store to be reloaded for a new index version")
+	}
+	if chunks, err := reloaded.GetAllChunks(ctx); err != nil || len(chunks) != 1 || chunks[0].FilePath != "b.go" {
+		t.Fatalf("expected the reloaded store to contain the new index, got %v %v", chunks, err)
+	}
+}
+
+func TestProjectStore_ReleasesReplacedGOB(t *testing.T) {
+	root := t.TempDir()
+	writeTestIndex(t, root, store.Chunk{ID: "a.go_0", FilePath: "a.go", Content: "package a", Vector: []float32{1, 0}})
+	cfg := config.DefaultConfig()
+	cfg.Store.GOB.LazyContent = true
+	s := &Server{projectRoot: root}
+	ctx := context.Background()
+
+	first, err := s.projectStore(ctx, cfg, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunks, err := first.GetAllChunks(ctx); err != nil || len(chunks) != 1 || chunks[0].Content != "package a" {
+		t.Fatalf("expected the lazily loaded content, got %v %v", chunks, err)
+	}
+
+	writeTestIndex(t, root, store.Chunk{ID: "b.go_0", FilePath: "b.go", Content: "package b", Vector: []float32{0, 1}})
+	if _, err := s.projectStore(ctx, cfg, "v2"); err != nil {
+		t.Fatal(err)
+	}
+	if chunks, err := first.GetAllChunks(ctx); err != nil || len(chunks) != 1 || chunks[0].Content != "" {
+		t.Fatalf("expected the replaced store's content file to be closed, got %v %v", chunks, err)
+	}
+}
+
+func TestWatchIndexSignal_InvalidatesOnSignal(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, config.ConfigDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{projectRoot: root, cache: newSessionCache()}
+	s.cache.putSearchResults("q", "v1", []store.SearchResult{{Score: 1}}, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.watchIndexSignal(ctx, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	if err := config.SignalIndexUpdated(root); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := s.cache.searchResults("q", "v1"); !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected cached results to be dropped after the watch signal")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestUseWatcherIndex(t *testing.T) {
+	root := t.TempDir()
+	s := &Server{projectRoot: root, cache: newSessionCache()}
+	ctx := context.Background()
+
+	st := store.NewGOBStore(config.GetIndexPath(root))
+	symbols := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(root))
+	s.UseWatcherIndex(st, symbols)
+
+	got, err := s.projectStore(ctx, config.DefaultConfig(), "v1")
+	if err != nil || got != st {
+		t.Fatalf("expected the watcher's store, got %v %v", got, err)
+	}
+	gotSymbols, release, err := s.loadSymbolStore(ctx)
+	if err != nil || gotSymbols != symbols {
+		t.Fatalf("expected the watcher's symbol store, got %v %v", gotSymbols, err)
+	}
+	release()
+	if !s.usesWatcherIndex() {
+		t.Fatal("expected the server to report the watcher's index")
+	}
+
+	s.UseWatcherIndex(nil, nil)
+	if s.usesWatcherIndex() {
+		t.Fatal("expected nil stores to revert to loading the index from disk")
+	}
+}
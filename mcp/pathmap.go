@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"os"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// pathMapper translates absolute paths between the server's filesystem and
+// the client's, so a client on the host can open files indexed inside a
+// devcontainer and the other way round. A nil mapper leaves paths unchanged.
+type pathMapper struct {
+	pairs []pathPair
+}
+
+// pathPair is a mapped directory: local as seen by the server, remote as
+// seen by the client.
+type pathPair struct {
+	local  string
+	remote string
+}
+
+// SetPathMappings sets the host and container roots translated in absolute
+// paths the server returns and receives.
+func (s *Server) SetPathMappings(mappings []config.PathMapping) {
+	s.paths = newPathMapper(mappings)
+}
+
+// newPathMapper orients each mapping from the server's side: container_root
+// is local when it exists here and host_root does not, or when both exist
+// and the server runs in a container.
+func newPathMapper(mappings []config.PathMapping) *pathMapper {
+	if len(mappings) == 0 {
+		return nil
+	}
+	inContainer := runningInContainer()
+	m := &pathMapper{}
+	for _, mapping := range mappings {
+		host, container := trimRoot(mapping.HostRoot), trimRoot(mapping.ContainerRoot)
+		if host == "" || container == "" {
+			continue
+		}
+		pair := pathPair{local: host, remote: container}
+		hostExists, containerExists := dirExists(host), dirExists(container)
+		if containerExists && (!hostExists || inContainer) {
+			pair = pathPair{local: container, remote: host}
+		}
+		m.pairs = append(m.pairs, pair)
+	}
+	return m
+}
+
+// toClient rewrites a server path for the client.
+func (m *pathMapper) toClient(path string) string {
+	if m == nil {
+		return path
+	}
+	for _, pair := range m.pairs {
+		if rest, ok := cutRoot(path, pair.local); ok {
+			return joinRoot(pair.remote, rest)
+		}
+	}
+	return path
+}
+
+// fromClient rewrites a client path for the server.
+func (m *pathMapper) fromClient(path string) string {
+	if m == nil {
+		return path
+	}
+	for _, pair := range m.pairs {
+		if rest, ok := cutRoot(path, pair.remote); ok {
+			return joinRoot(pair.local, rest)
+		}
+	}
+	return path
+}
+
+// toClientAll rewrites every path of paths for the client.
+func (m *pathMapper) toClientAll(paths []string) []string {
+	if m == nil {
+		return paths
+	}
+	mapped := make([]string, len(paths))
+	for i, path := range paths {
+		mapped[i] = m.toClient(path)
+	}
+	return mapped
+}
+
+// cutRoot returns the part of path below root, with its leading separator,
+// if path is root or inside it. Both separators are accepted so Windows
+// hosts can be mapped to Linux containers.
+func cutRoot(path, root string) (string, bool) {
+	if !strings.HasPrefix(path, root) {
+		return "", false
+	}
+	rest := path[len(root):]
+	if rest != "" && rest[0] != '/' && rest[0] != '\\' {
+		return "", false // a sibling such as /app-old for /app
+	}
+	return rest, true
+}
+
+// joinRoot appends rest to root using root's separator style.
+func joinRoot(root, rest string) string {
+	if strings.Contains(root, `\`) && !strings.Contains(root, "/") {
+		rest = strings.ReplaceAll(rest, "/", `\`)
+	} else {
+		rest = strings.ReplaceAll(rest, `\`, "/")
+	}
+	return root + rest
+}
+
+func trimRoot(root string) string {
+	root = strings.TrimSpace(root)
+	if len(root) > 1 {
+		root = strings.TrimRight(root, `/\`)
+	}
+	return root
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// runningInContainer reports whether the server runs in a Docker or Podman
+// container.
+func runningInContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return os.Getenv("REMOTE_CONTAINERS") != "" || os.Getenv("CODESPACES") != ""
+}
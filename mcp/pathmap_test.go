@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestPathMapper_ServerInContainer(t *testing.T) {
+	container := filepath.ToSlash(t.TempDir())
+	m := newPathMapper([]config.PathMapping{{HostRoot: "/nonexistent/host/app/", ContainerRoot: container}})
+
+	if got := m.toClient(container + "/src/main.go"); got != "/nonexistent/host/app/src/main.go" {
+		t.Errorf("toClient = %q", got)
+	}
+	if got := m.fromClient("/nonexistent/host/app/src"); got != container+"/src" {
+		t.Errorf("fromClient = %q", got)
+	}
+	if got := m.toClient(container + "-old/main.go"); got != container+"-old/main.go" {
+		t.Errorf("expected a sibling directory to stay unmapped, got %q", got)
+	}
+	if got := m.toClient("src/main.go"); got != "src/main.go" {
+		t.Errorf("expected a relative path to stay unmapped, got %q", got)
+	}
+}
+
+func TestPathMapper_WindowsHost(t *testing.T) {
+	container := filepath.ToSlash(t.TempDir())
+	m := newPathMapper([]config.PathMapping{{HostRoot: `C:\src\app`, ContainerRoot: container}})
+
+	if got := m.toClient(container + "/pkg/a.go"); got != `C:\src\app\pkg\a.go` {
+		t.Errorf("toClient = %q", got)
+	}
+	if got := m.fromClient(`C:\src\app\pkg`); got != container+"/pkg" {
+		t.Errorf("fromClient = %q", got)
+	}
+}
+
+func TestPathMapper_Nil(t *testing.T) {
+	var m *pathMapper
+	if got := m.toClient("/workspaces/app/main.go"); got != "/workspaces/app/main.go" {
+		t.Errorf("toClient = %q", got)
+	}
+	if m := newPathMapper(nil); m != nil {
+		t.Errorf("expected no mapper without mappings, got %+v", m)
+	}
+}
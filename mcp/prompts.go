@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerPrompts registers the codebase-exploration prompts. Each prompt
+// expands into instructions that chain grepai tools for a common task.
+func (s *Server) registerPrompts() {
+	s.mcpServer.AddPrompt(mcp.NewPrompt("explore_feature",
+		mcp.WithPromptDescription("Understand how a feature is implemented: find its entry points with semantic search, then follow the call graph."),
+		mcp.WithArgument("feature",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("Feature to explore, described in natural language (e.g., 'password reset flow')"),
+		),
+		mcp.WithArgument("workspace",
+			mcp.ArgumentDescription("Workspace to explore instead of the current project (optional)"),
+		),
+	), s.handleExploreFeaturePrompt)
+
+	s.mcpServer.AddPrompt(mcp.NewPrompt("find_bug_context",
+		mcp.WithPromptDescription("Gather the code relevant to a bug: locate where the symptom originates and how that code is reached."),
+		mcp.WithArgument("symptom",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("Observed behavior or error message (e.g., 'orders are charged twice on retry')"),
+		),
+		mcp.WithArgument("symbol",
+			mcp.ArgumentDescription("Function or method already suspected (optional)"),
+		),
+		mcp.WithArgument("workspace",
+			mcp.ArgumentDescription("Workspace to search instead of the current project (optional)"),
+		),
+	), s.handleFindBugContextPrompt)
+
+	s.mcpServer.AddPrompt(mcp.NewPrompt("impact_analysis",
+		mcp.WithPromptDescription("Assess what a change to a symbol affects: its callers, the data it touches, and the owners to involve."),
+		mcp.WithArgument("symbol",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("Function, method or type that will change"),
+		),
+		mcp.WithArgument("workspace",
+			mcp.ArgumentDescription("Workspace to analyze instead of the current project (optional)"),
+		),
+	), s.handleImpactAnalysisPrompt)
+}
+
+func (s *Server) handleExploreFeaturePrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	feature := strings.TrimSpace(request.Params.Arguments["feature"])
+	if feature == "" {
+		return nil, fmt.Errorf("feature argument is required")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Explore how this feature is implemented in the codebase: %s\n\n", feature)
+	b.WriteString(promptScope(request.Params.Arguments["workspace"]))
+	b.WriteString("Steps:\n")
+	fmt.Fprintf(&b, "1. Call grepai_search with query=%q and compact=true to find candidate entry points. Rephrase the query by intent (what the code does, not what it is named) if the results are off-topic.\n", feature)
+	b.WriteString("2. Read the most relevant files at the returned lines, or repeat the search with compact=false for the top results.\n")
+	b.WriteString("3. For each entry point, call grepai_trace_callees to see what it relies on and grepai_trace_callers to see where it is used. Use grepai_trace_graph with depth=2 when the flow spans several layers.\n")
+	b.WriteString("4. Stop once further calls only reach utilities or third-party code.\n\n")
+	b.WriteString("Answer with: the entry points (file:line), the main flow as an ordered list of functions, the data structures involved, and any open questions.")
+
+	return mcp.NewGetPromptResult("Explore the implementation of "+feature, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+	}), nil
+}
+
+func (s *Server) handleFindBugContextPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	symptom := strings.TrimSpace(request.Params.Arguments["symptom"])
+	if symptom == "" {
+		return nil, fmt.Errorf("symptom argument is required")
+	}
+	symbol := strings.TrimSpace(request.Params.Arguments["symbol"])
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Gather the context needed to fix this bug: %s\n\n", symptom)
+	b.WriteString(promptScope(request.Params.Arguments["workspace"]))
+	b.WriteString("Steps:\n")
+	if symbol != "" {
+		fmt.Fprintf(&b, "1. Start from %s: call grepai_trace_callers with symbol=%q to see how it is reached, and grepai_trace_callees to see what it depends on.\n", symbol, symbol)
+		fmt.Fprintf(&b, "2. Call grepai_search with query=%q to find other code related to the symptom that the call graph does not reach.\n", symptom)
+	} else {
+		fmt.Fprintf(&b, "1. Call grepai_search with query=%q to find where the symptom could originate. Also search for the error message or the state it describes.\n", symptom)
+		b.WriteString("2. Pick the most likely function and call grepai_trace_callers on it to see how it is reached, and grepai_trace_callees to see what it depends on.\n")
+	}
+	b.WriteString("3. When the bug involves shared state, call grepai_refs_writers on the fields or variables involved to find every place that modifies them.\n")
+	b.WriteString("4. Read the code along the failing path before forming a hypothesis.\n\n")
+	b.WriteString("Answer with: the code path that produces the symptom (file:line for each step), the most likely cause, and what to check or test to confirm it. Do not propose a fix before the path is established.")
+
+	return mcp.NewGetPromptResult("Find the context of a bug", []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+	}), nil
+}
+
+func (s *Server) handleImpactAnalysisPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	symbol := strings.TrimSpace(request.Params.Arguments["symbol"])
+	if symbol == "" {
+		return nil, fmt.Errorf("symbol argument is required")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Assess the impact of changing %s.\n\n", symbol)
+	b.WriteString(promptScope(request.Params.Arguments["workspace"]))
+	b.WriteString("Steps:\n")
+	fmt.Fprintf(&b, "1. Call grepai_trace_callers with symbol=%q to list direct callers, then grepai_trace_graph with depth=3 to find indirect ones. Page with limit/offset when there are many.\n", symbol)
+	fmt.Fprintf(&b, "2. Call grepai_refs_readers and grepai_refs_writers with symbol=%q when it is a type, field or variable, to find code that depends on its data.\n", symbol)
+	fmt.Fprintf(&b, "3. Call grepai_search with query=%q to catch usages the call graph misses, such as reflection, configuration or documentation.\n", symbol)
+	b.WriteString("4. Call grepai_owners on the files of the affected callers to find who should review the change.\n\n")
+	b.WriteString("Answer with: affected callers grouped by file or project, behavior that could break, tests to update or add, and the owners to involve.")
+
+	return mcp.NewGetPromptResult("Analyze the impact of changing "+symbol, []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+	}), nil
+}
+
+// promptScope returns the instruction scoping prompt tool calls to a
+// workspace, or an empty string for the current project.
+func promptScope(workspace string) string {
+	workspace = strings.TrimSpace(workspace)
+	if workspace == "" {
+		return ""
+	}
+	return fmt.Sprintf("Pass workspace=%q to every grepai tool call.\n\n", workspace)
+}
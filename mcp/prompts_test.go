@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRegisterPrompts_Listed(t *testing.T) {
+	s, err := NewServer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := s.mcpServer.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`))
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"explore_feature", "find_bug_context", "impact_analysis"} {
+		if !strings.Contains(string(data), `"name":"`+name+`"`) {
+			t.Errorf("expected prompt %s to be listed, got %s", name, data)
+		}
+	}
+}
+
+func promptText(t *testing.T, result *mcp.GetPromptResult) string {
+	t.Helper()
+	if len(result.Messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(result.Messages))
+	}
+	text, ok := result.Messages[0].Content.(mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Messages[0].Content)
+	}
+	return text.Text
+}
+
+func TestPrompts_ComposeTools(t *testing.T) {
+	s := &Server{}
+	request := func(args map[string]string) mcp.GetPromptRequest {
+		return mcp.GetPromptRequest{Params: mcp.GetPromptParams{Arguments: args}}
+	}
+
+	result, err := s.handleExploreFeaturePrompt(context.Background(), request(map[string]string{"feature": "password reset", "workspace": "acme"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := promptText(t, result)
+	for _, want := range []string{`query="password reset"`, "grepai_trace_callees", `workspace="acme"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("explore_feature prompt missing %q:\n%s", want, text)
+		}
+	}
+
+	result, err = s.handleFindBugContextPrompt(context.Background(), request(map[string]string{"symptom": "double charge", "symbol": "Charge"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := promptText(t, result); !strings.Contains(text, `grepai_trace_callers with symbol="Charge"`) || strings.Contains(text, "workspace=") {
+		t.Errorf("unexpected find_bug_context prompt:\n%s", text)
+	}
+
+	result, err = s.handleImpactAnalysisPrompt(context.Background(), request(map[string]string{"symbol": "Charge"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := promptText(t, result); !strings.Contains(text, "grepai_owners") || !strings.Contains(text, "grepai_refs_writers") {
+		t.Errorf("unexpected impact_analysis prompt:\n%s", text)
+	}
+
+	if _, err := s.handleImpactAnalysisPrompt(context.Background(), request(map[string]string{})); err == nil {
+		t.Error("expected an error without the required symbol argument")
+	}
+}
@@ -12,15 +12,19 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
-
+	"sync"
 	"time"
 
 	"github.com/alpkeskin/gotoon"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/coverage"
+	"github.com/yoanbernabeu/grepai/daemon"
 	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/git"
 	"github.com/yoanbernabeu/grepai/rpg"
 	"github.com/yoanbernabeu/grepai/search"
 	"github.com/yoanbernabeu/grepai/stats"
@@ -32,12 +36,37 @@ import (
 type Server struct {
 	mcpServer     *server.MCPServer
 	projectRoot   string
-	workspaceName string // non-empty when started via --workspace or auto-detect
+	workspaceName string // non-empty when started via --workspace, auto-detect or grepai_use_workspace
+	workspaceMu   sync.RWMutex
 	recorder      *stats.Recorder
+	telemetry     *stats.TelemetryRecorder // nil unless telemetry.enabled
+	cache         *sessionCache
+
+	// sharedStore is the project's vector store, kept open across requests
+	// by projectStore.
+	sharedStoreMu  sync.Mutex
+	sharedStore    store.VectorStore
+	sharedStoreKey string
+
+	// Index resources owned by a watcher running in the same process; see
+	// UseWatcherIndex and UseEmbedder.
+	watcherMu       sync.RWMutex
+	watcherStore    store.VectorStore
+	watcherSymbols  trace.SymbolStore
+	watcherEmbedder embedder.Embedder
+
+	// autoIndex builds a missing project index in the background; see
+	// SetAutoIndexer.
+	autoIndex autoIndexer
+
+	// paths maps absolute paths for clients outside the server's
+	// container; see SetPathMappings.
+	paths *pathMapper
 }
 
 // SearchResult is a lightweight struct for MCP output.
 type SearchResult struct {
+	ID          string  `json:"id"` // chunk ID, accepted by previous_result_ids
 	FilePath    string  `json:"file_path"`
 	StartLine   int     `json:"start_line"`
 	EndLine     int     `json:"end_line"`
@@ -45,16 +74,29 @@ type SearchResult struct {
 	Content     string  `json:"content"`
 	FeaturePath string  `json:"feature_path,omitempty"`
 	SymbolName  string  `json:"symbol_name,omitempty"`
+	SymbolKind  string  `json:"symbol_kind,omitempty"`
+	Permalink   string  `json:"permalink,omitempty"`
+	Owners      string  `json:"owners,omitempty"`
+	// Coverage is the line coverage of the result, from the coverage
+	// profile imported with 'grepai coverage import'.
+	Coverage *coverage.Coverage `json:"coverage,omitempty"`
 }
 
 // SearchResultCompact is a minimal struct for compact output (no content field).
 type SearchResultCompact struct {
+	ID          string  `json:"id"`
 	FilePath    string  `json:"file_path"`
 	StartLine   int     `json:"start_line"`
 	EndLine     int     `json:"end_line"`
 	Score       float32 `json:"score"`
 	FeaturePath string  `json:"feature_path,omitempty"`
 	SymbolName  string  `json:"symbol_name,omitempty"`
+	SymbolKind  string  `json:"symbol_kind,omitempty"`
+	Permalink   string  `json:"permalink,omitempty"`
+	Owners      string  `json:"owners,omitempty"`
+	// Coverage is the line coverage of the result, from the coverage
+	// profile imported with 'grepai coverage import'.
+	Coverage *coverage.Coverage `json:"coverage,omitempty"`
 }
 
 // CallSiteCompact is a minimal struct for compact output (no context field).
@@ -67,12 +109,14 @@ type CallSiteCompact struct {
 type CallerInfoCompact struct {
 	Symbol   trace.Symbol    `json:"symbol"`
 	CallSite CallSiteCompact `json:"call_site"`
+	Project  string          `json:"project,omitempty"`
 }
 
 // CalleeInfoCompact is a compact version of trace.CalleeInfo for compact output.
 type CalleeInfoCompact struct {
 	Symbol   trace.Symbol    `json:"symbol"`
 	CallSite CallSiteCompact `json:"call_site"`
+	Project  string          `json:"project,omitempty"`
 }
 
 type RefUsageCompact struct {
@@ -107,6 +151,17 @@ type IndexStatus struct {
 	RPGEnabled   bool   `json:"rpg_enabled"`
 	RPGNodes     int    `json:"rpg_nodes,omitempty"`
 	RPGEdges     int    `json:"rpg_edges,omitempty"`
+
+	// AutoIndex reports the background index build started by
+	// grepai_search, if any.
+	AutoIndex *AutoIndexStatus `json:"auto_index,omitempty"`
+
+	// Health is only reported in verbose mode.
+	Health *IndexHealth `json:"health,omitempty"`
+
+	// Directories and Languages are only reported in breakdown mode.
+	Directories []store.GroupStats `json:"directories,omitempty"`
+	Languages   []store.GroupStats `json:"languages,omitempty"`
 }
 
 // encodeOutput encodes data in the specified format (json or toon).
@@ -128,6 +183,8 @@ func NewServer(projectRoot string) (*Server, error) {
 	s := &Server{
 		projectRoot: projectRoot,
 		recorder:    stats.NewRecorder(projectRoot),
+		telemetry:   projectTelemetry(projectRoot),
+		cache:       newSessionCache(),
 	}
 
 	// Create MCP server
@@ -135,10 +192,12 @@ func NewServer(projectRoot string) (*Server, error) {
 		"grepai",
 		"1.0.0",
 		server.WithToolCapabilities(false),
+		server.WithPromptCapabilities(false),
 	)
 
-	// Register tools
+	// Register tools and prompts
 	s.registerTools()
+	s.registerPrompts()
 
 	return s, nil
 }
@@ -150,19 +209,56 @@ func NewServerWithWorkspace(projectRoot, workspaceName string) (*Server, error)
 		projectRoot:   projectRoot,
 		workspaceName: workspaceName,
 		recorder:      stats.NewRecorder(projectRoot),
+		telemetry:     projectTelemetry(projectRoot),
+		cache:         newSessionCache(),
 	}
 
 	s.mcpServer = server.NewMCPServer(
 		"grepai",
 		"1.0.0",
 		server.WithToolCapabilities(false),
+		server.WithPromptCapabilities(false),
 	)
 
 	s.registerTools()
+	s.registerPrompts()
 
 	return s, nil
 }
 
+// projectTelemetry returns the telemetry recorder of projectRoot, nil when
+// the project has not opted in.
+func projectTelemetry(projectRoot string) *stats.TelemetryRecorder {
+	if projectRoot == "" {
+		return nil
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil
+	}
+	return stats.NewTelemetryRecorder(projectRoot, cfg.Telemetry.Enabled)
+}
+
+// addTool registers tool, timing its calls when telemetry is enabled.
+func (s *Server) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if s.telemetry == nil {
+		s.mcpServer.AddTool(tool, handler)
+		return
+	}
+	operation := "mcp." + tool.Name
+	s.mcpServer.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := handler(ctx, request)
+		failed := err != nil || (result != nil && result.IsError)
+		go func() {
+			recordCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			_ = s.telemetry.Record(recordCtx, operation, start, failed)
+		}()
+		return result, err
+	})
+}
+
 // registerTools registers all grepai tools with the MCP server.
 func (s *Server) registerTools() {
 	// grepai_search tool
@@ -170,7 +266,7 @@ func (s *Server) registerTools() {
 		mcp.WithDescription("Semantic code search. Search your codebase using natural language queries. Returns the most relevant code chunks with file paths, line numbers, and similarity scores.\n\nExamples:\n- workspace-only mode: workspace='acme', path='src/'\n- workspace + projects mode: workspace='acme', projects='backend,shared', path='api/'"),
 		mcp.WithString("query",
 			mcp.Required(),
-			mcp.Description("Natural language search query (e.g., 'user authentication flow', 'error handling middleware')"),
+			mcp.Description("Natural language search query (e.g., 'user authentication flow', 'error handling middleware'). Field terms filter chunks: lang:go, path:internal/, symbol:Handle* (wildcards), -field:value to exclude; quote text containing spaces or colons (e.g., 'lang:go path:internal/ \"retry budget\"')"),
 		),
 		mcp.WithNumber("limit",
 			mcp.Description("Maximum number of results to return (default: 10)"),
@@ -190,15 +286,40 @@ func (s *Server) registerTools() {
 		mcp.WithString("projects",
 			mcp.Description("Comma-separated list of project names to search within workspace (requires workspace)"),
 		),
+		mcp.WithString("feature",
+			mcp.Description("RPG feature path to search within, e.g. 'auth/session' (requires RPG enabled; not supported with workspace)"),
+		),
+		mcp.WithBoolean("uncovered",
+			mcp.Description("Only return code with statements that no test runs, from the coverage profile imported with 'grepai coverage import' (not supported with workspace; default: false)"),
+		),
+		mcp.WithString("scope",
+			mcp.Description("Named scope from the project's config.yaml restricting results to its paths and languages (not supported with workspace)"),
+		),
+		mcp.WithArray("previous_result_ids",
+			mcp.Description("IDs of results from a previous grepai_search call. When set, the query re-ranks only those results instead of searching the whole index, to narrow a broad search step by step"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithNumber("max_tokens",
+			mcp.Description("Approximate token budget for the response (optional). Output over budget drops content, then annotations, then results, and is wrapped with truncated: true"),
+		),
 	)
-	s.mcpServer.AddTool(searchTool, s.handleSearch)
+	s.addTool(searchTool, s.handleSearch)
 
 	// grepai_trace_callers tool
 	traceCallersTool := mcp.NewTool("grepai_trace_callers",
 		mcp.WithDescription("Find all functions that call the specified symbol. Useful for understanding code dependencies before modifying a function."),
 		mcp.WithString("symbol",
 			mcp.Required(),
-			mcp.Description("Name of the function/method to find callers for"),
+			mcp.Description("Name of the function/method to find callers for. Accepts qualified (Receiver.Method, pkg.Func), case-insensitive, prefix and fuzzy names; ambiguous queries return a 'candidates' list"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of results to return (default: all)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of results to skip for pagination (default: 0)"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Sort results by: 'file' (default), 'line', 'project', or 'count' (symbols with most call sites first)"),
 		),
 		mcp.WithBoolean("compact",
 			mcp.Description("Return minimal output without context (default: false)"),
@@ -206,21 +327,36 @@ func (s *Server) registerTools() {
 		mcp.WithString("format",
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
+		mcp.WithString("scope",
+			mcp.Description("Named scope from the project's config.yaml keeping only results in its files (not supported with workspace)"),
+		),
 		mcp.WithString("workspace",
 			mcp.Description("Workspace name for cross-project trace (optional)"),
 		),
 		mcp.WithString("project",
 			mcp.Description("Project name within workspace (requires workspace)"),
 		),
+		mcp.WithNumber("max_tokens",
+			mcp.Description("Approximate token budget for the response (optional). Output over budget drops call-site context and docstrings, then results, and is wrapped with truncated: true"),
+		),
 	)
-	s.mcpServer.AddTool(traceCallersTool, s.handleTraceCallers)
+	s.addTool(traceCallersTool, s.handleTraceCallers)
 
 	// grepai_trace_callees tool
 	traceCalleesTool := mcp.NewTool("grepai_trace_callees",
 		mcp.WithDescription("Find all functions called by the specified symbol. Useful for understanding what a function depends on."),
 		mcp.WithString("symbol",
 			mcp.Required(),
-			mcp.Description("Name of the function/method to find callees for"),
+			mcp.Description("Name of the function/method to find callees for. Accepts qualified (Receiver.Method, pkg.Func), case-insensitive, prefix and fuzzy names; ambiguous queries return a 'candidates' list"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of results to return (default: all)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of results to skip for pagination (default: 0)"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Sort results by: 'file' (default), 'line', 'project', or 'count' (symbols with most call sites first)"),
 		),
 		mcp.WithBoolean("compact",
 			mcp.Description("Return minimal output without context (default: false)"),
@@ -228,21 +364,27 @@ func (s *Server) registerTools() {
 		mcp.WithString("format",
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
+		mcp.WithString("scope",
+			mcp.Description("Named scope from the project's config.yaml keeping only results in its files (not supported with workspace)"),
+		),
 		mcp.WithString("workspace",
 			mcp.Description("Workspace name for cross-project trace (optional)"),
 		),
 		mcp.WithString("project",
 			mcp.Description("Project name within workspace (requires workspace)"),
 		),
+		mcp.WithNumber("max_tokens",
+			mcp.Description("Approximate token budget for the response (optional). Output over budget drops call-site context and docstrings, then results, and is wrapped with truncated: true"),
+		),
 	)
-	s.mcpServer.AddTool(traceCalleesTool, s.handleTraceCallees)
+	s.addTool(traceCalleesTool, s.handleTraceCallees)
 
 	// grepai_trace_graph tool
 	traceGraphTool := mcp.NewTool("grepai_trace_graph",
 		mcp.WithDescription("Build a complete call graph around a symbol showing both callers and callees up to a specified depth."),
 		mcp.WithString("symbol",
 			mcp.Required(),
-			mcp.Description("Name of the function/method to build graph for"),
+			mcp.Description("Name of the function/method to build graph for. Accepts qualified (Receiver.Method, pkg.Func), case-insensitive, prefix and fuzzy names; ambiguous queries return a 'candidates' list"),
 		),
 		mcp.WithNumber("depth",
 			mcp.Description("Maximum depth for graph traversal (default: 2)"),
@@ -250,6 +392,41 @@ func (s *Server) registerTools() {
 		mcp.WithString("format",
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
+		mcp.WithString("scope",
+			mcp.Description("Named scope from the project's config.yaml keeping only results in its files (not supported with workspace)"),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("Workspace name for cross-project trace (optional)"),
+		),
+		mcp.WithString("project",
+			mcp.Description("Project name within workspace (requires workspace)"),
+		),
+		mcp.WithNumber("max_tokens",
+			mcp.Description("Approximate token budget for the response (optional). Output over budget drops docstrings, then edges, and is wrapped with truncated: true"),
+		),
+	)
+	s.addTool(traceGraphTool, s.handleTraceGraph)
+
+	// grepai_trace_path tool
+	tracePathTool := mcp.NewTool("grepai_trace_path",
+		mcp.WithDescription("Find call paths between two symbols (e.g. how a request handler reaches the database layer). Returns the shortest paths first with the call site of every step."),
+		mcp.WithString("from",
+			mcp.Required(),
+			mcp.Description("Symbol where the paths start. Accepts the same qualified and fuzzy names as grepai_trace_callers"),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("Symbol where the paths end. Accepts the same qualified and fuzzy names as grepai_trace_callers"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Maximum number of calls in a path (default: 6)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of paths to return (default: 5)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
+		),
 		mcp.WithString("workspace",
 			mcp.Description("Workspace name for cross-project trace (optional)"),
 		),
@@ -257,7 +434,7 @@ func (s *Server) registerTools() {
 			mcp.Description("Project name within workspace (requires workspace)"),
 		),
 	)
-	s.mcpServer.AddTool(traceGraphTool, s.handleTraceGraph)
+	s.addTool(tracePathTool, s.handleTracePath)
 
 	refsReadersTool := mcp.NewTool("grepai_refs_readers",
 		mcp.WithDescription("Find readers of a property/state symbol (non-call data usage such as store.uid reads)."),
@@ -278,7 +455,7 @@ func (s *Server) registerTools() {
 			mcp.Description("Project name within workspace (requires workspace)"),
 		),
 	)
-	s.mcpServer.AddTool(refsReadersTool, s.handleRefsReaders)
+	s.addTool(refsReadersTool, s.handleRefsReaders)
 
 	refsWritersTool := mcp.NewTool("grepai_refs_writers",
 		mcp.WithDescription("Find writers of a property/state symbol (non-call data usage such as this.uid = ...)."),
@@ -299,7 +476,122 @@ func (s *Server) registerTools() {
 			mcp.Description("Project name within workspace (requires workspace)"),
 		),
 	)
-	s.mcpServer.AddTool(refsWritersTool, s.handleRefsWriters)
+	s.addTool(refsWritersTool, s.handleRefsWriters)
+
+	traceReferencesTool := mcp.NewTool("grepai_trace_references",
+		mcp.WithDescription("Find every reference to a symbol, not only call sites: calls, property reads and writes, type usages, struct literal / new instantiations and imports. Useful before renaming or removing a type."),
+		mcp.WithString("symbol",
+			mcp.Required(),
+			mcp.Description("Symbol name to find references to. Qualified names (Receiver.Method, pkg.Type) match references to the last segment"),
+		),
+		mcp.WithString("kinds",
+			mcp.Description("Comma-separated reference kinds to return: call, read, write, type, instantiate, import (default: all)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of results to return (default: all)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of results to skip for pagination (default: 0)"),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Sort results by: 'file' (default), 'line', 'project', or 'count' (most frequent kinds first)"),
+		),
+		mcp.WithBoolean("compact",
+			mcp.Description("Return minimal output without context (default: false)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("Workspace name for cross-project references (optional)"),
+		),
+		mcp.WithString("project",
+			mcp.Description("Project name within workspace (requires workspace)"),
+		),
+	)
+	s.addTool(traceReferencesTool, s.handleTraceReferences)
+
+	findLiteralTool := mcp.NewTool("grepai_find_literal",
+		mcp.WithDescription("Find notable string literals with the functions that use them: URL routes, tables read or written by SQL queries in strings, environment variable names and feature flag keys. Useful to find the handler of a route or every reader of an env var."),
+		mcp.WithString("query",
+			mcp.Description("Text the literal contains, case-insensitive (default: every literal)"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Literal kind to return: route, sql, env or flag (default: all)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of literals to return (default: all)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of literals to skip for pagination (default: 0)"),
+		),
+		mcp.WithBoolean("compact",
+			mcp.Description("Return minimal output without context (default: false)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("Workspace name for cross-project literals (optional)"),
+		),
+		mcp.WithString("project",
+			mcp.Description("Project name within workspace (requires workspace)"),
+		),
+	)
+	s.addTool(findLiteralTool, s.handleFindLiteral)
+
+	apiRoutesTool := mcp.NewTool("grepai_api_routes",
+		mcp.WithDescription("List the HTTP endpoints declared in the code with their method, path, file, line and handler function. Covers net/http, gin, echo, chi and fiber in Go, express-style routers, FastAPI and Flask, and Spring mappings. Useful to enumerate the API surface or find the handler of an endpoint."),
+		mcp.WithString("method",
+			mcp.Description("HTTP method to return, such as GET or ANY for routes serving every method (default: all)"),
+		),
+		mcp.WithString("path",
+			mcp.Description("Text the route path contains, case-insensitive (default: every route)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of routes to return (default: all)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of routes to skip for pagination (default: 0)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("Workspace name for cross-project routes (optional)"),
+		),
+		mcp.WithString("project",
+			mcp.Description("Project name within workspace (requires workspace)"),
+		),
+	)
+	s.addTool(apiRoutesTool, s.handleAPIRoutes)
+
+	testsForTool := mcp.NewTool("grepai_tests_for",
+		mcp.WithDescription("List the tests most likely covering a function, method, type or file, best first, to decide what to run after an edit. Tests are scored by naming (TestServer_Start, server_test.go), references to the target or its callers, and semantic similarity of the test code."),
+		mcp.WithString("target",
+			mcp.Required(),
+			mcp.Description("Symbol name (e.g. 'Server.Start') or file path relative to the project root"),
+		),
+		mcp.WithBoolean("semantic",
+			mcp.Description("Use semantic similarity with the project index; ignored in workspace mode (default: true)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of tests to return (default: 10)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of tests to skip for pagination (default: 0)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("Workspace name for cross-project tests (optional)"),
+		),
+		mcp.WithString("project",
+			mcp.Description("Project name within workspace (requires workspace)"),
+		),
+	)
+	s.addTool(testsForTool, s.handleTestsFor)
 
 	refsGraphTool := mcp.NewTool("grepai_refs_graph",
 		mcp.WithDescription("Build a property/state usage graph for a symbol by combining readers and writers."),
@@ -320,12 +612,13 @@ func (s *Server) registerTools() {
 			mcp.Description("Project name within workspace (requires workspace)"),
 		),
 	)
-	s.mcpServer.AddTool(refsGraphTool, s.handleRefsGraph)
+	s.addTool(refsGraphTool, s.handleRefsGraph)
 
 	// grepai_index_status tool
 	indexStatusTool := mcp.NewTool("grepai_index_status",
 		mcp.WithDescription("Check the health and status of the grepai index. Returns statistics about indexed files, chunks, and configuration."),
-		mcp.WithBoolean("verbose", mcp.Description("Include additional debug details when available (optional).")),
+		mcp.WithBoolean("verbose", mcp.Description("Include a health report per project: last indexed time, stale files among a sample of indexed files, watcher state, and symbol/RPG readiness (optional).")),
+		mcp.WithBoolean("breakdown", mcp.Description("Include file and chunk counts by top-level directory (by project for workspaces) and by language, largest first (optional).")),
 		mcp.WithString("format",
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
@@ -333,7 +626,7 @@ func (s *Server) registerTools() {
 			mcp.Description("Workspace name to check status for (optional)"),
 		),
 	)
-	s.mcpServer.AddTool(indexStatusTool, s.handleIndexStatus)
+	s.addTool(indexStatusTool, s.handleIndexStatus)
 
 	// grepai_list_workspaces tool
 	listWorkspacesTool := mcp.NewTool("grepai_list_workspaces",
@@ -342,7 +635,7 @@ func (s *Server) registerTools() {
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
 	)
-	s.mcpServer.AddTool(listWorkspacesTool, s.handleListWorkspaces)
+	s.addTool(listWorkspacesTool, s.handleListWorkspaces)
 
 	// grepai_list_projects tool
 	listProjectsTool := mcp.NewTool("grepai_list_projects",
@@ -354,7 +647,39 @@ func (s *Server) registerTools() {
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
 	)
-	s.mcpServer.AddTool(listProjectsTool, s.handleListProjects)
+	s.addTool(listProjectsTool, s.handleListProjects)
+
+	// grepai_use_workspace tool
+	useWorkspaceTool := mcp.NewTool("grepai_use_workspace",
+		mcp.WithDescription("Set the default workspace for the rest of this session. Tools that accept a workspace parameter use it when the parameter is omitted. Pass an empty workspace to return to the project the server was started in."),
+		mcp.WithString("workspace",
+			mcp.Required(),
+			mcp.Description("Workspace name (see grepai_list_workspaces), or an empty string to clear the default"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
+		),
+	)
+	s.addTool(useWorkspaceTool, s.handleUseWorkspace)
+
+	// grepai_owners tool
+	ownersTool := mcp.NewTool("grepai_owners",
+		mcp.WithDescription("Find the owners of a file or directory from the repository's CODEOWNERS file. Use this to answer who owns a piece of code."),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("File or directory path, relative to the project root"),
+		),
+		mcp.WithString("workspace",
+			mcp.Description("Workspace name; the path is then relative to the given project (optional)"),
+		),
+		mcp.WithString("project",
+			mcp.Description("Project name within the workspace (required with workspace)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
+		),
+	)
+	s.addTool(ownersTool, s.handleOwners)
 
 	// grepai_rpg_search tool
 	rpgSearchTool := mcp.NewTool("grepai_rpg_search",
@@ -376,7 +701,7 @@ func (s *Server) registerTools() {
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
 	)
-	s.mcpServer.AddTool(rpgSearchTool, s.handleRPGSearch)
+	s.addTool(rpgSearchTool, s.handleRPGSearch)
 
 	// grepai_rpg_fetch tool
 	rpgFetchTool := mcp.NewTool("grepai_rpg_fetch",
@@ -389,7 +714,7 @@ func (s *Server) registerTools() {
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
 	)
-	s.mcpServer.AddTool(rpgFetchTool, s.handleRPGFetch)
+	s.addTool(rpgFetchTool, s.handleRPGFetch)
 
 	// grepai_rpg_explore tool
 	rpgExploreTool := mcp.NewTool("grepai_rpg_explore",
@@ -414,7 +739,7 @@ func (s *Server) registerTools() {
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
 	)
-	s.mcpServer.AddTool(rpgExploreTool, s.handleRPGExplore)
+	s.addTool(rpgExploreTool, s.handleRPGExplore)
 
 	// grepai_stats tool
 	statsTool := mcp.NewTool("grepai_stats",
@@ -426,7 +751,7 @@ func (s *Server) registerTools() {
 			mcp.Description("Max days in history (default: 30, only used when history=true)"),
 		),
 	)
-	s.mcpServer.AddTool(statsTool, s.handleStats)
+	s.addTool(statsTool, s.handleStats)
 }
 
 // handleSearch handles the grepai_search tool call.
@@ -443,14 +768,15 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 
 	compact := request.GetBool("compact", false)
 	format := request.GetString("format", "json")
-	path := request.GetString("path", "")
-	workspace := request.GetString("workspace", "")
-	projects := request.GetString("projects", "")
-
+	limits := s.responseLimits(request)
+	path := s.paths.fromClient(request.GetString("path", ""))
 	// Auto-inject workspace when server is in workspace mode
-	if workspace == "" && s.workspaceName != "" {
-		workspace = s.workspaceName
-	}
+	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
+	projects := request.GetString("projects", "")
+	feature := strings.TrimSpace(request.GetString("feature", ""))
+	uncovered := request.GetBool("uncovered", false)
+	scopeName := strings.TrimSpace(request.GetString("scope", ""))
+	previousIDs := request.GetStringSlice("previous_result_ids", nil)
 
 	// Validate format
 	if format != "json" && format != "toon" {
@@ -459,7 +785,16 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 
 	// Workspace mode
 	if workspace != "" {
-		return s.handleWorkspaceSearch(ctx, query, limit, compact, format, path, workspace, projects)
+		if feature != "" {
+			return mcp.NewToolResultError("feature filter is not supported in workspace mode"), nil
+		}
+		if uncovered {
+			return mcp.NewToolResultError("uncovered filter is not supported in workspace mode"), nil
+		}
+		if scopeName != "" {
+			return mcp.NewToolResultError("scope is not supported in workspace mode"), nil
+		}
+		return s.handleWorkspaceSearch(ctx, query, limit, compact, format, path, workspace, projects, previousIDs, limits)
 	}
 
 	// Load configuration
@@ -475,30 +810,55 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 		}
 		return mcp.NewToolResultError(fmt.Sprintf("failed to load configuration: %v", err)), nil
 	}
-
-	// Initialize embedder
-	emb, err := s.createEmbedder(cfg)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to initialize embedder: %v", err)), nil
-	}
-	defer emb.Close()
-
-	// Initialize store
-	st, err := s.createStore(ctx, cfg)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to initialize store: %v", err)), nil
+	if err := config.CheckProjectIndexMetadata(s.projectRoot, cfg); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	defer st.Close()
 
-	// Create searcher and search
-	searcher := search.NewSearcher(st, emb, cfg.Search)
 	normalizedPath, err := search.NormalizeProjectPathPrefix(path, s.projectRoot)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("invalid path parameter: %v", err)), nil
 	}
-	results, err := searcher.Search(ctx, query, limit, normalizedPath)
+	var scope *config.Scope
+	scopeKey := ""
+	if scopeName != "" {
+		def, err := cfg.GetScope(scopeName)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		scope = &def
+		scopeKey = fmt.Sprintf("%+v", def)
+	}
+
+	// Repeated searches against an unchanged index are served from the
+	// session cache without loading the embedder or the store.
+	ttl := cacheTTL(cfg)
+	version := indexVersion(s.projectRoot)
+	profile, err := coverage.Load(config.GetCoveragePath(s.projectRoot))
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
+		log.Printf("Warning: %v", err)
+	}
+	if uncovered && profile == nil {
+		return mcp.NewToolResultError("uncovered filter requires coverage: run 'grepai coverage import <profile>' first"), nil
+	}
+	// Results depend on the profile when filtered or boosted by coverage.
+	coverageStamp := ""
+	if uncovered || cfg.Search.Boost.Uncovered != 0 {
+		coverageStamp = fileStamp(config.GetCoveragePath(s.projectRoot))
+	}
+	cacheKey := searchCacheKey(s.projectRoot, query, strconv.Itoa(limit), normalizedPath, feature, strconv.FormatBool(uncovered), coverageStamp, scopeKey, strings.Join(previousIDs, "\x01"))
+	// A watcher's in-memory index changes without a new version until it
+	// is persisted, so its results are not cached.
+	results, cached := s.cache.searchResults(cacheKey, version)
+	if s.usesWatcherIndex() {
+		ttl, cached = 0, false
+	}
+	if !cached {
+		var errResult *mcp.CallToolResult
+		results, errResult = s.searchProject(ctx, cfg, version, query, limit, normalizedPath, feature, profile, uncovered, scope, previousIDs, ttl)
+		if errResult != nil {
+			return errResult, nil
+		}
+		s.cache.putSearchResults(cacheKey, version, results, ttl)
 	}
 
 	// RPG enrichment
@@ -528,41 +888,33 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 		}
 	}
 
-	var data any
-	if compact {
-		searchResultsCompact := make([]SearchResultCompact, len(results))
-		for i, r := range results {
-			searchResultsCompact[i] = SearchResultCompact{
-				FilePath:  r.Chunk.FilePath,
-				StartLine: r.Chunk.StartLine,
-				EndLine:   r.Chunk.EndLine,
-				Score:     r.Score,
-			}
-			if info, ok := rpgData[i]; ok {
-				searchResultsCompact[i].FeaturePath = info.featurePath
-				searchResultsCompact[i].SymbolName = info.symbolName
-			}
+	links := search.NewProjectPermalinks(s.projectRoot, cfg)
+	owners := search.NewProjectCodeOwners(s.projectRoot)
+
+	searchResults := make([]SearchResult, len(results))
+	for i, r := range results {
+		searchResults[i] = SearchResult{
+			ID:        r.Chunk.ID,
+			FilePath:  r.Chunk.FilePath,
+			StartLine: r.Chunk.StartLine,
+			EndLine:   r.Chunk.EndLine,
+			Score:     r.Score,
+			Content:   r.Chunk.Content,
+			Permalink: links.Link(r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine),
+			Owners:    owners.Owners(r.Chunk.FilePath),
+			Coverage:  profile.Range(r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine),
 		}
-		data = searchResultsCompact
-	} else {
-		searchResults := make([]SearchResult, len(results))
-		for i, r := range results {
-			searchResults[i] = SearchResult{
-				FilePath:  r.Chunk.FilePath,
-				StartLine: r.Chunk.StartLine,
-				EndLine:   r.Chunk.EndLine,
-				Score:     r.Score,
-				Content:   r.Chunk.Content,
-			}
-			if info, ok := rpgData[i]; ok {
-				searchResults[i].FeaturePath = info.featurePath
-				searchResults[i].SymbolName = info.symbolName
-			}
+		if info, ok := rpgData[i]; ok {
+			searchResults[i].FeaturePath = info.featurePath
+			searchResults[i].SymbolName = info.symbolName
+		}
+		if r.SymbolName != "" {
+			searchResults[i].SymbolName = r.SymbolName
+			searchResults[i].SymbolKind = r.SymbolKind
 		}
-		data = searchResults
 	}
 
-	output, err := encodeOutput(data, format)
+	output, err := encodeWithinLimits(format, limits, len(searchResults), searchCompactHint, searchBudgetStages(searchResults, compact)...)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
 	}
@@ -571,8 +923,77 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 	return mcp.NewToolResultText(output), nil
 }
 
+// searchProject runs a search against the project index at version, or
+// re-ranks the chunks in previousIDs when set. Results are boosted by their
+// coverage in profile, when set, and with uncovered only code no test runs
+// is returned. With a scope only code in it is returned. It
+// returns a tool error result when the search cannot run.
+func (s *Server) searchProject(ctx context.Context, cfg *config.Config, version, query string, limit int, pathPrefix, feature string, profile *coverage.Profile, uncovered bool, scope *config.Scope, previousIDs []string, ttl time.Duration) ([]store.SearchResult, *mcp.CallToolResult) {
+	// Initialize embedder
+	emb, release, err := s.projectEmbedder(cfg)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("failed to initialize embedder: %v", err))
+	}
+	defer release()
+
+	// Initialize store
+	st, err := s.projectStore(ctx, cfg, version)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("failed to initialize store: %v", err))
+	}
+
+	// Create searcher and search
+	docsEmb, err := embedder.NewDocsFromConfig(cfg)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("failed to initialize docs embedder: %v", err))
+	}
+	if docsEmb != nil {
+		defer docsEmb.Close()
+		docsEmb = s.cache.wrapEmbedder(docsEmb, *cfg.Embedder.Docs, ttl)
+	}
+	searcher := search.NewProjectSearcher(st, s.cache.wrapEmbedder(emb, cfg.Embedder, ttl), docsEmb, cfg)
+	// The symbol store is only read here; it is not released because closing persists it.
+	if symbolStore, _, err := s.loadSymbolStore(ctx); err == nil {
+		searcher.SetSymbolStore(symbolStore)
+	}
+	searcher.SetConfidenceObserver(search.NewMissObserver(s.projectRoot, cfg.Insights))
+	searcher.SetCoverage(profile)
+	var keep func(store.Chunk) bool
+	if feature != "" {
+		var errResult *mcp.CallToolResult
+		keep, errResult = s.featureChunkFilter(ctx, feature)
+		if errResult != nil {
+			return nil, errResult
+		}
+	}
+	if uncovered {
+		keep = search.UncoveredFilter(profile, keep)
+	}
+	if scope != nil {
+		keep = search.AndChunkFilters(keep, search.ScopeFilter(*scope))
+	}
+	if keep = newAccessPolicy(cfg.MCP.Access).keepChunk(keep); keep != nil {
+		searcher.SetChunkFilter(keep)
+	}
+	var results []store.SearchResult
+	if len(previousIDs) > 0 {
+		results, err = searcher.Refine(ctx, query, previousIDs, limit, pathPrefix)
+	} else {
+		results, err = searcher.Search(ctx, query, limit, pathPrefix)
+	}
+	// Only check for a missing index when the search finds nothing, so
+	// regular searches do not pay for index stats.
+	if (err != nil || len(results) == 0) && indexMissing(ctx, st) {
+		return nil, s.indexMissingResult(cfg)
+	}
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err))
+	}
+	return results, nil
+}
+
 // handleWorkspaceSearch handles workspace-level search via MCP.
-func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit int, compact bool, format, pathPrefix, workspaceName, projectsStr string) (*mcp.CallToolResult, error) {
+func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit int, compact bool, format, pathPrefix, workspaceName, projectsStr string, previousIDs []string, limits responseLimits) (*mcp.CallToolResult, error) {
 	// Load workspace config
 	wsCfg, err := config.LoadWorkspaceConfig()
 	if err != nil {
@@ -594,7 +1015,7 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 		if len(selected) == 0 {
 			selected = listWorkspaceProjectNames(ws.Projects)
 		}
-		return mcp.NewToolResultError(buildWorkspacePathValidationError(pathPrefix, selected, workspaceProjectRoots(selectWorkspaceProjects(ws, selected)), workspacePathExamples(selectWorkspaceProjects(ws, selected)), err.Error())), nil
+		return mcp.NewToolResultError(buildWorkspacePathValidationError(pathPrefix, selected, s.paths.toClientAll(workspaceProjectRoots(selectWorkspaceProjects(ws, selected))), workspacePathExamples(selectWorkspaceProjects(ws, selected)), err.Error())), nil
 	}
 	if validationErr := validateWorkspacePathForProjects(normalizedPath, ws, resolvedProjects); validationErr != "" {
 		return mcp.NewToolResultError(validationErr), nil
@@ -604,6 +1025,9 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 	if err := config.ValidateWorkspaceBackend(ws); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if err := config.CheckWorkspaceIndexMetadata(ws); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	// Initialize embedder
 	emb, err := s.createWorkspaceEmbedder(ws)
@@ -619,34 +1043,39 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 	}
 	defer st.Close()
 
-	// Create searcher with default config
-	searchCfg := config.SearchConfig{
-		Hybrid: config.HybridConfig{Enabled: false, K: 60},
-		Boost:  config.DefaultConfig().Search.Boost,
+	cachedEmb := s.cache.wrapEmbedder(emb, ws.Embedder, s.workspaceCacheTTL())
+	searcher, err := search.NewWorkspaceSearcher(st, cachedEmb, ws)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if keep := workspaceChunkFilter(ws); keep != nil {
+		searcher.SetChunkFilter(keep)
 	}
-	searcher := search.NewSearcher(st, emb, searchCfg)
+	searcher.SetConfidenceObserver(search.NewWorkspaceMissObserver(ws))
 
-	// Construct full path prefix for database query. Database stores paths as:
-	// workspaceName/projectName/relativePath. When a single project is specified,
-	// include it in the path prefix to push filtering to database level. If no
-	// project is specified but a user path is provided, we must search using the
-	// workspace prefix and perform post-filtering to match the relative path
-	// across any project (since project name sits between workspace and user path).
+	// Searches retrieve each selected project under the path prefix, or
+	// are routed to the most relevant projects of large workspaces.
+	// Refining re-ranks given chunks, filtered below. Database stores paths
+	// as workspaceName/projectName/relativePath, so a user path is only part
+	// of the prefix when a single project is specified.
 	fullPathPrefix := ws.Name + "/"
 	singleProject := ""
 	if len(resolvedProjects) == 1 {
 		singleProject = resolvedProjects[0]
-		fullPathPrefix += singleProject + "/"
-	}
-
-	// Add path prefix if provided
-	if normalizedPath != "" {
-		fullPathPrefix += normalizedPath
+		fullPathPrefix += singleProject + "/" + normalizedPath
 	}
 
 	// Search
 	var results []store.SearchResult
-	results, err = searcher.Search(ctx, query, limit, fullPathPrefix)
+	if len(previousIDs) > 0 {
+		results, err = searcher.Refine(ctx, query, previousIDs, limit, fullPathPrefix)
+	} else {
+		results, _, err = searcher.SearchWorkspace(ctx, query, limit, search.WorkspaceSearchOptions{
+			Projects:   resolvedProjects,
+			PathPrefix: normalizedPath,
+			Route:      true,
+		})
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
@@ -700,7 +1129,7 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 				buildWorkspacePathValidationError(
 					pathPrefix,
 					selected,
-					workspaceProjectRoots(projects),
+					s.paths.toClientAll(workspaceProjectRoots(projects)),
 					workspacePathExamples(projects),
 					"no indexed files matched this path prefix in selected projects",
 				),
@@ -708,33 +1137,24 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 		}
 	}
 
-	var data any
-	if compact {
-		searchResultsCompact := make([]SearchResultCompact, len(results))
-		for i, r := range results {
-			searchResultsCompact[i] = SearchResultCompact{
-				FilePath:  r.Chunk.FilePath,
-				StartLine: r.Chunk.StartLine,
-				EndLine:   r.Chunk.EndLine,
-				Score:     r.Score,
-			}
-		}
-		data = searchResultsCompact
-	} else {
-		searchResults := make([]SearchResult, len(results))
-		for i, r := range results {
-			searchResults[i] = SearchResult{
-				FilePath:  r.Chunk.FilePath,
-				StartLine: r.Chunk.StartLine,
-				EndLine:   r.Chunk.EndLine,
-				Score:     r.Score,
-				Content:   r.Chunk.Content,
-			}
+	links := search.NewWorkspacePermalinks(ws)
+	owners := search.NewWorkspaceCodeOwners(ws)
+
+	searchResults := make([]SearchResult, len(results))
+	for i, r := range results {
+		searchResults[i] = SearchResult{
+			ID:        r.Chunk.ID,
+			FilePath:  r.Chunk.FilePath,
+			StartLine: r.Chunk.StartLine,
+			EndLine:   r.Chunk.EndLine,
+			Score:     r.Score,
+			Content:   r.Chunk.Content,
+			Permalink: links.Link(r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine),
+			Owners:    owners.Owners(r.Chunk.FilePath),
 		}
-		data = searchResults
 	}
 
-	output, err := encodeOutput(data, format)
+	output, err := encodeWithinLimits(format, limits, len(searchResults), searchCompactHint, searchBudgetStages(searchResults, compact)...)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
 	}
@@ -1000,44 +1420,63 @@ func (s *Server) createWorkspaceEmbedder(ws *config.Workspace) (embedder.Embedde
 
 // createWorkspaceStore creates a vector store based on workspace configuration.
 func (s *Server) createWorkspaceStore(ctx context.Context, ws *config.Workspace) (store.VectorStore, error) {
-	projectID := "workspace:" + ws.Name
-
-	switch ws.Store.Backend {
-	case "postgres":
-		return store.NewPostgresStore(ctx, ws.Store.Postgres.DSN, projectID, ws.Embedder.GetDimensions())
-	case "qdrant":
-		collectionName := ws.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = "workspace_" + ws.Name
-		}
-		return store.NewQdrantStore(ctx, ws.Store.Qdrant.Endpoint, ws.Store.Qdrant.Port, ws.Store.Qdrant.UseTLS, collectionName, ws.Store.Qdrant.APIKey, ws.Embedder.GetDimensions())
-	default:
-		return nil, fmt.Errorf("unsupported backend for workspace: %s", ws.Store.Backend)
-	}
+	return store.NewFromWorkspaceConfig(ctx, ws)
 }
 
 // resolveWorkspace returns the effective workspace name, auto-injecting from server config.
 func (s *Server) resolveWorkspace(workspace string) string {
-	if workspace == "" && s.workspaceName != "" {
-		return s.workspaceName
+	if workspace != "" {
+		return workspace
 	}
-	return workspace
+	s.workspaceMu.RLock()
+	defer s.workspaceMu.RUnlock()
+	return s.workspaceName
 }
 
-// enrichTraceSymbols enriches trace symbols with RPG feature paths.
-// It loads the RPG store once and enriches all provided symbols in one pass.
-func (s *Server) enrichTraceSymbols(ctx context.Context, symbols ...*trace.Symbol) {
+// dispatchEnabled reports whether interface methods are resolved to their
+// implementations, following trace.dispatch in the project config.
+func (s *Server) dispatchEnabled() bool {
 	if s.projectRoot == "" {
-		return
+		return trace.DispatchEnabled("")
 	}
 	cfg, err := config.Load(s.projectRoot)
-	if err != nil || !cfg.RPG.Enabled {
-		return
+	if err != nil {
+		return trace.DispatchEnabled("")
 	}
-	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(s.projectRoot))
-	if err := rpgStore.Load(ctx); err != nil {
-		log.Printf("Warning: RPG enrichment unavailable for trace: %v", err)
-		return
+	return trace.DispatchEnabled(cfg.Trace.Dispatch)
+}
+
+// dispatchStores returns the stores whose interface methods are resolved
+// to their implementations: workspace project stores follow their own
+// project config, others that of the server's project.
+func (s *Server) dispatchStores(stores []trace.SymbolStore) []trace.SymbolStore {
+	stores = trace.DispatchStores(stores)
+	if s.dispatchEnabled() {
+		return stores
+	}
+	kept := stores[:0]
+	for _, ss := range stores {
+		if _, ok := ss.(*trace.ProjectSymbolStore); ok {
+			kept = append(kept, ss)
+		}
+	}
+	return kept
+}
+
+// enrichTraceSymbols enriches trace symbols with RPG feature paths.
+// It loads the RPG store once and enriches all provided symbols in one pass.
+func (s *Server) enrichTraceSymbols(ctx context.Context, symbols ...*trace.Symbol) {
+	if s.projectRoot == "" {
+		return
+	}
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil || !cfg.RPG.Enabled {
+		return
+	}
+	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(s.projectRoot))
+	if err := rpgStore.Load(ctx); err != nil {
+		log.Printf("Warning: RPG enrichment unavailable for trace: %v", err)
+		return
 	}
 	defer rpgStore.Close()
 
@@ -1061,6 +1500,101 @@ func (s *Server) enrichTraceSymbols(ctx context.Context, symbols ...*trace.Symbo
 	}
 }
 
+// attachTraceOwners sets the CODEOWNERS owners of trace symbols. Workspace
+// symbols are skipped, since their paths are relative to other projects.
+func (s *Server) attachTraceOwners(stores []trace.SymbolStore, symbols ...*trace.Symbol) {
+	if s.projectRoot == "" {
+		return
+	}
+	for _, ss := range stores {
+		if trace.ProjectOf(ss) != "" {
+			return
+		}
+	}
+	owners := search.NewProjectCodeOwners(s.projectRoot)
+	if owners == nil {
+		return
+	}
+	for _, sym := range symbols {
+		if sym != nil && sym.File != "" {
+			sym.Owners = owners.Owners(sym.File)
+		}
+	}
+}
+
+// attachTraceCoverage sets the line coverage of trace symbols from the
+// project's imported coverage profile. Workspace symbols are skipped, as in
+// attachTraceOwners.
+func (s *Server) attachTraceCoverage(stores []trace.SymbolStore, symbols ...*trace.Symbol) {
+	if s.projectRoot == "" {
+		return
+	}
+	for _, ss := range stores {
+		if trace.ProjectOf(ss) != "" {
+			return
+		}
+	}
+	profile, err := coverage.Load(config.GetCoveragePath(s.projectRoot))
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if profile == nil {
+		return
+	}
+	for _, sym := range symbols {
+		if sym != nil && sym.File != "" && sym.Line > 0 {
+			sym.Coverage = profile.Range(sym.File, sym.Line, sym.EndLine)
+		}
+	}
+}
+
+// ambiguousTraceToolResult returns the disambiguation list for a query that
+// matched several distinct symbols.
+func ambiguousTraceToolResult(query string, res trace.SymbolResolution, format string) (*mcp.CallToolResult, error) {
+	result := trace.TraceResult{
+		Query:      query,
+		Mode:       "fast",
+		Match:      res.Match,
+		Candidates: res.Candidates,
+	}
+	output, err := encodeOutput(result, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// tracePageOptions reads the limit/offset/sort parameters of trace tools.
+func tracePageOptions(request mcp.CallToolRequest) trace.PageOptions {
+	return trace.PageOptions{
+		Sort:   strings.ToLower(strings.TrimSpace(request.GetString("sort", trace.SortByFile))),
+		Limit:  request.GetInt("limit", 0),
+		Offset: request.GetInt("offset", 0),
+	}
+}
+
+// traceScopeFilter reads the scope parameter of trace tools and returns a
+// filter keeping the files in that scope, or nil without one. Scopes come
+// from the project's configuration, so workspace mode rejects them.
+func (s *Server) traceScopeFilter(request mcp.CallToolRequest, workspace string) (func(string) bool, *mcp.CallToolResult) {
+	name := strings.TrimSpace(request.GetString("scope", ""))
+	if name == "" {
+		return nil, nil
+	}
+	if workspace != "" {
+		return nil, mcp.NewToolResultError("scope is not supported in workspace mode")
+	}
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("failed to load configuration: %v", err))
+	}
+	scope, err := cfg.GetScope(name)
+	if err != nil {
+		return nil, mcp.NewToolResultError(err.Error())
+	}
+	return search.ScopeFileFilter(scope), nil
+}
+
 // handleTraceCallers handles the grepai_trace_callers tool call.
 func (s *Server) handleTraceCallers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	symbolName, err := request.RequireString("symbol")
@@ -1069,6 +1603,7 @@ func (s *Server) handleTraceCallers(ctx context.Context, request mcp.CallToolReq
 	}
 
 	compact := request.GetBool("compact", false)
+	limits := s.responseLimits(request)
 	format := request.GetString("format", "json")
 	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
 	project := request.GetString("project", "")
@@ -1078,56 +1613,73 @@ func (s *Server) handleTraceCallers(ctx context.Context, request mcp.CallToolReq
 		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
 	}
 
+	page := tracePageOptions(request)
+	if err := page.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	keepFile, errResult := s.traceScopeFilter(request, workspace)
+	if errResult != nil {
+		return errResult, nil
+	}
+
 	// Workspace mode
 	if workspace != "" {
-		stores, loadErr := trace.LoadWorkspaceSymbolStores(ctx, workspace, project)
+		stores, loadErr := loadWorkspaceSymbolStores(ctx, workspace, project)
 		if loadErr != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", loadErr)), nil
 		}
 		defer trace.CloseSymbolStores(stores)
 
-		return s.handleTraceCallersFromStores(ctx, symbolName, compact, format, stores)
+		return s.handleTraceCallersFromStores(ctx, symbolName, compact, format, page, keepFile, limits, stores)
 	}
 
 	// Single-project mode
-	if s.projectRoot == "" {
-		return mcp.NewToolResultError("trace requires a project context; use --workspace parameter or start mcp-serve from a project directory"), nil
-	}
-
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'grepai watch' first", err)), nil
-	}
-	defer symbolStore.Close()
-
-	stats, err := symbolStore.GetStats(ctx)
-	if err != nil || stats.TotalSymbols == 0 {
-		return mcp.NewToolResultError("symbol index is empty. Run 'grepai watch' first to build the index"), nil
+	symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "trace")
+	if errResult != nil {
+		return errResult, nil
 	}
+	defer release()
 
-	return s.handleTraceCallersFromStores(ctx, symbolName, compact, format, []trace.SymbolStore{symbolStore})
+	return s.handleTraceCallersFromStores(ctx, symbolName, compact, format, page, keepFile, limits, []trace.SymbolStore{symbolStore})
 }
 
 // handleTraceCallersFromStores handles callers lookup across one or more symbol stores.
-func (s *Server) handleTraceCallersFromStores(ctx context.Context, symbolName string, compact bool, format string, stores []trace.SymbolStore) (*mcp.CallToolResult, error) {
+// A non-nil keepFile drops the callers outside a scope.
+func (s *Server) handleTraceCallersFromStores(ctx context.Context, symbolName string, compact bool, format string, page trace.PageOptions, keepFile func(string) bool, limits responseLimits, stores []trace.SymbolStore) (*mcp.CallToolResult, error) {
+	res, err := trace.ResolveSymbol(ctx, stores, symbolName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to resolve symbol: %v", err)), nil
+	}
+	if res.Ambiguous() {
+		return ambiguousTraceToolResult(symbolName, res, format)
+	}
+	name := symbolName
+	if res.Name != "" {
+		name = res.Name
+	}
+
 	// Aggregate results across stores
 	var firstSymbol *trace.Symbol
 	var allRefs []trace.Reference
+	var refProjects []string
 
 	for _, ss := range stores {
-		symbols, err := ss.LookupSymbol(ctx, symbolName)
+		symbols, err := ss.LookupSymbol(ctx, name)
 		if err != nil {
-			log.Printf("Warning: failed to lookup symbol %q: %v", symbolName, err)
+			log.Printf("Warning: failed to lookup symbol %q: %v", name, err)
 		}
 		if len(symbols) > 0 && firstSymbol == nil {
 			sym := symbols[0]
 			firstSymbol = &sym
 		}
-		refs, err := ss.LookupCallers(ctx, symbolName)
+		refs, err := ss.LookupCallers(ctx, name)
 		if err != nil {
-			log.Printf("Warning: failed to lookup callers of %q: %v", symbolName, err)
+			log.Printf("Warning: failed to lookup callers of %q: %v", name, err)
 		}
 		allRefs = append(allRefs, refs...)
+		for range refs {
+			refProjects = append(refProjects, trace.ProjectOf(ss))
+		}
 	}
 
 	if firstSymbol == nil {
@@ -1139,102 +1691,62 @@ func (s *Server) handleTraceCallersFromStores(ctx context.Context, symbolName st
 		return mcp.NewToolResultText(output), nil
 	}
 
-	var data any
-	if compact {
-		resultCompact := struct {
-			Query   string              `json:"query"`
-			Mode    string              `json:"mode"`
-			Symbol  *trace.Symbol       `json:"symbol,omitempty"`
-			Callers []CallerInfoCompact `json:"callers,omitempty"`
-		}{
-			Query:   symbolName,
-			Mode:    "fast",
-			Symbol:  firstSymbol,
-			Callers: make([]CallerInfoCompact, 0, len(allRefs)),
-		}
-
-		for _, ref := range allRefs {
-			var callerSym trace.Symbol
-			for _, ss := range stores {
-				callerSyms, err := ss.LookupSymbol(ctx, ref.CallerName)
-				if err != nil {
-					log.Printf("Warning: failed to lookup caller symbol %q: %v", ref.CallerName, err)
-				}
-				if len(callerSyms) > 0 {
-					callerSym = callerSyms[0]
-					break
-				}
-			}
-			if callerSym.Name == "" {
-				callerSym = trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
-			}
-			resultCompact.Callers = append(resultCompact.Callers, CallerInfoCompact{
-				Symbol: callerSym,
-				CallSite: CallSiteCompact{
-					File: ref.File,
-					Line: ref.Line,
-				},
-			})
-		}
-
-		// Enrich with RPG
-		symPtrs := []*trace.Symbol{resultCompact.Symbol}
-		for i := range resultCompact.Callers {
-			symPtrs = append(symPtrs, &resultCompact.Callers[i].Symbol)
-		}
-		s.enrichTraceSymbols(ctx, symPtrs...)
-
-		data = resultCompact
-	} else {
-		result := trace.TraceResult{
-			Query:  symbolName,
-			Mode:   "fast",
-			Symbol: firstSymbol,
-		}
-		for _, ref := range allRefs {
-			var callerSym trace.Symbol
-			for _, ss := range stores {
-				callerSyms, err := ss.LookupSymbol(ctx, ref.CallerName)
-				if err != nil {
-					log.Printf("Warning: failed to lookup caller symbol %q: %v", ref.CallerName, err)
-				}
-				if len(callerSyms) > 0 {
-					callerSym = callerSyms[0]
-					break
-				}
+	result := trace.TraceResult{
+		Query:  symbolName,
+		Mode:   "fast",
+		Symbol: firstSymbol,
+	}
+	for i, ref := range allRefs {
+		var callerSym trace.Symbol
+		for _, ss := range stores {
+			callerSyms, err := ss.LookupSymbol(ctx, ref.CallerName)
+			if err != nil {
+				log.Printf("Warning: failed to lookup caller symbol %q: %v", ref.CallerName, err)
 			}
-			if callerSym.Name == "" {
-				callerSym = trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
+			if len(callerSyms) > 0 {
+				callerSym = callerSyms[0]
+				break
 			}
-			result.Callers = append(result.Callers, trace.CallerInfo{
-				Symbol: callerSym,
-				CallSite: trace.CallSite{
-					File:    ref.File,
-					Line:    ref.Line,
-					Context: ref.Context,
-				},
-			})
 		}
-
-		// Enrich with RPG
-		symPtrs := []*trace.Symbol{result.Symbol}
-		for i := range result.Callers {
-			symPtrs = append(symPtrs, &result.Callers[i].Symbol)
+		if callerSym.Name == "" {
+			callerSym = trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
+		}
+		result.Callers = append(result.Callers, trace.CallerInfo{
+			Symbol: callerSym,
+			CallSite: trace.CallSite{
+				File:    ref.File,
+				Line:    ref.Line,
+				Context: ref.Context,
+			},
+			Project: refProjects[i],
+		})
+	}
+	trace.ApplyResolution(&result, res)
+	if dispatch := s.dispatchStores(stores); len(dispatch) > 0 && (res.Symbol == nil || res.Symbol.Abstract) {
+		impls, err := trace.CollectImplementations(ctx, dispatch, name)
+		if err != nil {
+			log.Printf("Warning: failed to resolve implementations of %q: %v", name, err)
 		}
-		s.enrichTraceSymbols(ctx, symPtrs...)
+		result.Implementations = impls
+	}
+	trace.FilterFiles(&result, keepFile)
+	trace.ApplyPage(&result, page)
 
-		data = result
+	// Enrich with RPG
+	symPtrs := []*trace.Symbol{result.Symbol}
+	for i := range result.Callers {
+		symPtrs = append(symPtrs, &result.Callers[i].Symbol)
 	}
+	s.enrichTraceSymbols(ctx, symPtrs...)
+	s.attachTraceOwners(stores, symPtrs...)
+	s.attachTraceCoverage(stores, symPtrs...)
 
-	output, err := encodeOutput(data, format)
+	output, err := encodeWithinLimits(format, limits, len(result.Callers), traceCompactHint, traceBudgetStages(result, compact, callersOutput)...)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
 	}
 
-	resultCount := 0
-	if tr, ok := data.(trace.TraceResult); ok {
-		resultCount = len(tr.Callers)
-	}
+	resultCount := len(result.Callers)
 	s.recordMCPStats(stats.TraceCallers, mcpOutputMode(compact, format), resultCount, output)
 	return mcp.NewToolResultText(output), nil
 }
@@ -1247,6 +1759,7 @@ func (s *Server) handleTraceCallees(ctx context.Context, request mcp.CallToolReq
 	}
 
 	compact := request.GetBool("compact", false)
+	limits := s.responseLimits(request)
 	format := request.GetString("format", "json")
 	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
 	project := request.GetString("project", "")
@@ -1256,56 +1769,73 @@ func (s *Server) handleTraceCallees(ctx context.Context, request mcp.CallToolReq
 		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
 	}
 
+	page := tracePageOptions(request)
+	if err := page.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	keepFile, errResult := s.traceScopeFilter(request, workspace)
+	if errResult != nil {
+		return errResult, nil
+	}
+
 	// Workspace mode
 	if workspace != "" {
-		stores, loadErr := trace.LoadWorkspaceSymbolStores(ctx, workspace, project)
+		stores, loadErr := loadWorkspaceSymbolStores(ctx, workspace, project)
 		if loadErr != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", loadErr)), nil
 		}
 		defer trace.CloseSymbolStores(stores)
 
-		return s.handleTraceCalleesFromStores(ctx, symbolName, compact, format, stores)
+		return s.handleTraceCalleesFromStores(ctx, symbolName, compact, format, page, keepFile, limits, stores)
 	}
 
 	// Single-project mode
-	if s.projectRoot == "" {
-		return mcp.NewToolResultError("trace requires a project context; use --workspace parameter or start mcp-serve from a project directory"), nil
-	}
-
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'grepai watch' first", err)), nil
-	}
-	defer symbolStore.Close()
-
-	stats, err := symbolStore.GetStats(ctx)
-	if err != nil || stats.TotalSymbols == 0 {
-		return mcp.NewToolResultError("symbol index is empty. Run 'grepai watch' first to build the index"), nil
+	symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "trace")
+	if errResult != nil {
+		return errResult, nil
 	}
+	defer release()
 
-	return s.handleTraceCalleesFromStores(ctx, symbolName, compact, format, []trace.SymbolStore{symbolStore})
+	return s.handleTraceCalleesFromStores(ctx, symbolName, compact, format, page, keepFile, limits, []trace.SymbolStore{symbolStore})
 }
 
 // handleTraceCalleesFromStores handles callees lookup across one or more symbol stores.
-func (s *Server) handleTraceCalleesFromStores(ctx context.Context, symbolName string, compact bool, format string, stores []trace.SymbolStore) (*mcp.CallToolResult, error) {
+// A non-nil keepFile drops the callees outside a scope.
+func (s *Server) handleTraceCalleesFromStores(ctx context.Context, symbolName string, compact bool, format string, page trace.PageOptions, keepFile func(string) bool, limits responseLimits, stores []trace.SymbolStore) (*mcp.CallToolResult, error) {
+	res, err := trace.ResolveSymbol(ctx, stores, symbolName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to resolve symbol: %v", err)), nil
+	}
+	if res.Ambiguous() {
+		return ambiguousTraceToolResult(symbolName, res, format)
+	}
+	name := symbolName
+	if res.Name != "" {
+		name = res.Name
+	}
+
 	var firstSymbol *trace.Symbol
 	var allRefs []trace.Reference
+	var refProjects []string
 
 	for _, ss := range stores {
-		symbols, err := ss.LookupSymbol(ctx, symbolName)
+		symbols, err := ss.LookupSymbol(ctx, name)
 		if err != nil {
-			log.Printf("Warning: failed to lookup symbol %q: %v", symbolName, err)
+			log.Printf("Warning: failed to lookup symbol %q: %v", name, err)
 		}
 		if len(symbols) > 0 {
 			if firstSymbol == nil {
 				sym := symbols[0]
 				firstSymbol = &sym
 			}
-			refs, err := ss.LookupCallees(ctx, symbolName, symbols[0].File)
+			refs, err := ss.LookupCallees(ctx, name, symbols[0].File)
 			if err != nil {
-				log.Printf("Warning: failed to lookup callees of %q: %v", symbolName, err)
+				log.Printf("Warning: failed to lookup callees of %q: %v", name, err)
 			}
 			allRefs = append(allRefs, refs...)
+			for range refs {
+				refProjects = append(refProjects, trace.ProjectOf(ss))
+			}
 		}
 	}
 
@@ -1318,106 +1848,133 @@ func (s *Server) handleTraceCalleesFromStores(ctx context.Context, symbolName st
 		return mcp.NewToolResultText(output), nil
 	}
 
-	var data any
-	if compact {
-		resultCompact := struct {
-			Query   string              `json:"query"`
-			Mode    string              `json:"mode"`
-			Symbol  *trace.Symbol       `json:"symbol,omitempty"`
-			Callees []CalleeInfoCompact `json:"callees,omitempty"`
-		}{
-			Query:   symbolName,
-			Mode:    "fast",
-			Symbol:  firstSymbol,
-			Callees: make([]CalleeInfoCompact, 0, len(allRefs)),
-		}
-
-		for _, ref := range allRefs {
-			var calleeSym trace.Symbol
-			for _, ss := range stores {
-				calleeSyms, err := ss.LookupSymbol(ctx, ref.SymbolName)
-				if err != nil {
-					log.Printf("Warning: failed to lookup callee symbol %q: %v", ref.SymbolName, err)
-				}
-				if len(calleeSyms) > 0 {
-					calleeSym = calleeSyms[0]
-					break
-				}
-			}
-			if calleeSym.Name == "" {
-				calleeSym = trace.Symbol{Name: ref.SymbolName}
-			}
-			resultCompact.Callees = append(resultCompact.Callees, CalleeInfoCompact{
-				Symbol: calleeSym,
-				CallSite: CallSiteCompact{
-					File: ref.File,
-					Line: ref.Line,
-				},
-			})
-		}
-
-		// Enrich with RPG
-		symPtrs := []*trace.Symbol{resultCompact.Symbol}
-		for i := range resultCompact.Callees {
-			symPtrs = append(symPtrs, &resultCompact.Callees[i].Symbol)
-		}
-		s.enrichTraceSymbols(ctx, symPtrs...)
-
-		data = resultCompact
-	} else {
-		result := trace.TraceResult{
-			Query:  symbolName,
-			Mode:   "fast",
-			Symbol: firstSymbol,
-		}
-		for _, ref := range allRefs {
-			var calleeSym trace.Symbol
-			for _, ss := range stores {
-				calleeSyms, err := ss.LookupSymbol(ctx, ref.SymbolName)
-				if err != nil {
-					log.Printf("Warning: failed to lookup callee symbol %q: %v", ref.SymbolName, err)
-				}
-				if len(calleeSyms) > 0 {
-					calleeSym = calleeSyms[0]
-					break
-				}
+	result := trace.TraceResult{
+		Query:  symbolName,
+		Mode:   "fast",
+		Symbol: firstSymbol,
+	}
+	for i, ref := range allRefs {
+		var calleeSym trace.Symbol
+		for _, ss := range stores {
+			calleeSyms, err := ss.LookupSymbol(ctx, ref.SymbolName)
+			if err != nil {
+				log.Printf("Warning: failed to lookup callee symbol %q: %v", ref.SymbolName, err)
 			}
-			if calleeSym.Name == "" {
-				calleeSym = trace.Symbol{Name: ref.SymbolName}
+			if len(calleeSyms) > 0 {
+				calleeSym = calleeSyms[0]
+				break
 			}
-			result.Callees = append(result.Callees, trace.CalleeInfo{
-				Symbol: calleeSym,
-				CallSite: trace.CallSite{
-					File:    ref.File,
-					Line:    ref.Line,
-					Context: ref.Context,
-				},
-			})
-		}
-
-		// Enrich with RPG
-		symPtrs := []*trace.Symbol{result.Symbol}
-		for i := range result.Callees {
-			symPtrs = append(symPtrs, &result.Callees[i].Symbol)
 		}
-		s.enrichTraceSymbols(ctx, symPtrs...)
+		if calleeSym.Name == "" {
+			calleeSym = trace.Symbol{Name: ref.SymbolName}
+		}
+		result.Callees = append(result.Callees, trace.CalleeInfo{
+			Symbol: calleeSym,
+			CallSite: trace.CallSite{
+				File:    ref.File,
+				Line:    ref.Line,
+				Context: ref.Context,
+			},
+			Project: refProjects[i],
+		})
+	}
+	trace.ApplyResolution(&result, res)
+	trace.FilterFiles(&result, keepFile)
+	trace.ApplyPage(&result, page)
 
-		data = result
+	// Enrich with RPG
+	symPtrs := []*trace.Symbol{result.Symbol}
+	for i := range result.Callees {
+		symPtrs = append(symPtrs, &result.Callees[i].Symbol)
 	}
+	s.enrichTraceSymbols(ctx, symPtrs...)
+	s.attachTraceOwners(stores, symPtrs...)
+	s.attachTraceCoverage(stores, symPtrs...)
 
-	output, err := encodeOutput(data, format)
+	output, err := encodeWithinLimits(format, limits, len(result.Callees), traceCompactHint, traceBudgetStages(result, compact, calleesOutput)...)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
 	}
 
-	resultCount := 0
-	if tr, ok := data.(trace.TraceResult); ok {
-		resultCount = len(tr.Callees)
-	}
+	resultCount := len(result.Callees)
 	s.recordMCPStats(stats.TraceCallees, mcpOutputMode(compact, format), resultCount, output)
 	return mcp.NewToolResultText(output), nil
 }
 
+// callersOutput renders a caller result, dropping call-site context when compact.
+func callersOutput(result trace.TraceResult, compact bool) any {
+	if !compact {
+		return result
+	}
+	resultCompact := struct {
+		Query   string              `json:"query"`
+		Mode    string              `json:"mode"`
+		Match   string              `json:"match,omitempty"`
+		Symbol  *trace.Symbol       `json:"symbol,omitempty"`
+		Callers []CallerInfoCompact `json:"callers,omitempty"`
+		Total   int                 `json:"total,omitempty"`
+		Offset  int                 `json:"offset,omitempty"`
+
+		Implementations []trace.Implementation `json:"implementations,omitempty"`
+	}{
+		Query:   result.Query,
+		Mode:    result.Mode,
+		Match:   result.Match,
+		Symbol:  result.Symbol,
+		Callers: make([]CallerInfoCompact, 0, len(result.Callers)),
+		Total:   result.Total,
+		Offset:  result.Offset,
+
+		Implementations: result.Implementations,
+	}
+	for _, c := range result.Callers {
+		resultCompact.Callers = append(resultCompact.Callers, CallerInfoCompact{
+			Symbol: c.Symbol,
+			CallSite: CallSiteCompact{
+				File: c.CallSite.File,
+				Line: c.CallSite.Line,
+			},
+			Project: c.Project,
+		})
+	}
+	return resultCompact
+}
+
+// calleesOutput renders a callee result, dropping call-site context when compact.
+func calleesOutput(result trace.TraceResult, compact bool) any {
+	if !compact {
+		return result
+	}
+	resultCompact := struct {
+		Query   string              `json:"query"`
+		Mode    string              `json:"mode"`
+		Match   string              `json:"match,omitempty"`
+		Symbol  *trace.Symbol       `json:"symbol,omitempty"`
+		Callees []CalleeInfoCompact `json:"callees,omitempty"`
+		Total   int                 `json:"total,omitempty"`
+		Offset  int                 `json:"offset,omitempty"`
+	}{
+		Query:   result.Query,
+		Mode:    result.Mode,
+		Match:   result.Match,
+		Symbol:  result.Symbol,
+		Callees: make([]CalleeInfoCompact, 0, len(result.Callees)),
+		Total:   result.Total,
+		Offset:  result.Offset,
+	}
+	for _, c := range result.Callees {
+		resultCompact.Callees = append(resultCompact.Callees, CalleeInfoCompact{
+			Symbol: c.Symbol,
+			CallSite: CallSiteCompact{
+				File: c.CallSite.File,
+				Line: c.CallSite.Line,
+			},
+			Project: c.Project,
+		})
+	}
+	return resultCompact
+}
+
 // handleTraceGraph handles the grepai_trace_graph tool call.
 func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	symbolName, err := request.RequireString("symbol")
@@ -1431,6 +1988,7 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 	}
 
 	format := request.GetString("format", "json")
+	limits := s.responseLimits(request)
 	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
 	project := request.GetString("project", "")
 
@@ -1438,17 +1996,33 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 	if format != "json" && format != "toon" {
 		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
 	}
+	keepFile, errResult := s.traceScopeFilter(request, workspace)
+	if errResult != nil {
+		return errResult, nil
+	}
 
 	// Workspace mode: merge call graphs across projects
 	if workspace != "" {
-		stores, loadErr := trace.LoadWorkspaceSymbolStores(ctx, workspace, project)
+		stores, loadErr := loadWorkspaceSymbolStores(ctx, workspace, project)
 		if loadErr != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", loadErr)), nil
 		}
 		defer trace.CloseSymbolStores(stores)
 
+		res, resErr := trace.ResolveSymbol(ctx, stores, symbolName)
+		if resErr != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve symbol: %v", resErr)), nil
+		}
+		if res.Ambiguous() {
+			return ambiguousTraceToolResult(symbolName, res, format)
+		}
+		name := symbolName
+		if res.Name != "" {
+			name = res.Name
+		}
+
 		merged := &trace.CallGraph{
-			Root:  symbolName,
+			Root:  name,
 			Nodes: make(map[string]trace.Symbol),
 			Edges: []trace.CallEdge{},
 			Depth: depth,
@@ -1456,7 +2030,7 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 		edgeSeen := make(map[string]bool)
 
 		for _, ss := range stores {
-			graph, graphErr := ss.GetCallGraph(ctx, symbolName, depth)
+			graph, graphErr := ss.GetCallGraph(ctx, name, depth)
 			if graphErr != nil {
 				continue
 			}
@@ -1479,8 +2053,9 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 			Mode:  "fast",
 			Graph: merged,
 		}
+		trace.ApplyResolution(&result, res)
 
-		output, encErr := encodeOutput(result, format)
+		output, encErr := encodeWithinBudget(format, limits.maxTokens, traceResultCount(result), graphBudgetStages(result)...)
 		if encErr != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", encErr)), nil
 		}
@@ -1488,22 +2063,25 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 	}
 
 	// Single-project mode
-	if s.projectRoot == "" {
-		return mcp.NewToolResultError("trace requires a project context; use --workspace parameter or start mcp-serve from a project directory"), nil
+	symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "trace")
+	if errResult != nil {
+		return errResult, nil
 	}
+	defer release()
 
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'grepai watch' first", err)), nil
+	res, err := trace.ResolveSymbol(ctx, []trace.SymbolStore{symbolStore}, symbolName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to resolve symbol: %v", err)), nil
 	}
-	defer symbolStore.Close()
-
-	symStats, err := symbolStore.GetStats(ctx)
-	if err != nil || symStats.TotalSymbols == 0 {
-		return mcp.NewToolResultError("symbol index is empty. Run 'grepai watch' first to build the index"), nil
+	if res.Ambiguous() {
+		return ambiguousTraceToolResult(symbolName, res, format)
+	}
+	name := symbolName
+	if res.Name != "" {
+		name = res.Name
 	}
 
-	graph, err := symbolStore.GetCallGraph(ctx, symbolName, depth)
+	graph, err := symbolStore.GetCallGraph(ctx, name, depth)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to build call graph: %v", err)), nil
 	}
@@ -1513,6 +2091,8 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 		Mode:  "fast",
 		Graph: graph,
 	}
+	trace.ApplyResolution(&result, res)
+	trace.FilterFiles(&result, keepFile)
 
 	// Enrich graph nodes with RPG
 	if result.Graph != nil {
@@ -1528,12 +2108,14 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 			symPtrs = append(symPtrs, &entries[len(entries)-1].sym)
 		}
 		s.enrichTraceSymbols(ctx, symPtrs...)
+		s.attachTraceOwners([]trace.SymbolStore{symbolStore}, symPtrs...)
+		s.attachTraceCoverage([]trace.SymbolStore{symbolStore}, symPtrs...)
 		for _, e := range entries {
 			result.Graph.Nodes[e.name] = e.sym
 		}
 	}
 
-	output, err := encodeOutput(result, format)
+	output, err := encodeWithinBudget(format, limits.maxTokens, traceResultCount(result), graphBudgetStages(result)...)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
 	}
@@ -1546,6 +2128,79 @@ func (s *Server) handleTraceGraph(ctx context.Context, request mcp.CallToolReque
 	return mcp.NewToolResultText(output), nil
 }
 
+// handleTracePath handles the grepai_trace_path tool call.
+func (s *Server) handleTracePath(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	from, err := request.RequireString("from")
+	if err != nil {
+		return mcp.NewToolResultError("from parameter is required"), nil
+	}
+	to, err := request.RequireString("to")
+	if err != nil {
+		return mcp.NewToolResultError("to parameter is required"), nil
+	}
+
+	opts := trace.PathOptions{
+		MaxDepth: request.GetInt("depth", trace.DefaultPathDepth),
+		Limit:    request.GetInt("limit", trace.DefaultPathLimit),
+	}
+	format := request.GetString("format", "json")
+	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
+	project := request.GetString("project", "")
+
+	if format != "json" && format != "toon" {
+		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
+	}
+
+	var stores []trace.SymbolStore
+	if workspace != "" {
+		stores, err = loadWorkspaceSymbolStores(ctx, workspace, project)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", err)), nil
+		}
+		defer trace.CloseSymbolStores(stores)
+	} else {
+		symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "trace")
+		if errResult != nil {
+			return errResult, nil
+		}
+		defer release()
+		stores = []trace.SymbolStore{symbolStore}
+	}
+
+	return s.handleTracePathFromStores(ctx, from, to, opts, format, stores)
+}
+
+// handleTracePathFromStores resolves both endpoints and searches the call
+// graph of the given stores for paths between them.
+func (s *Server) handleTracePathFromStores(ctx context.Context, from, to string, opts trace.PathOptions, format string, stores []trace.SymbolStore) (*mcp.CallToolResult, error) {
+	names := make([]string, 0, 2)
+	for _, query := range []string{from, to} {
+		res, err := trace.ResolveSymbol(ctx, stores, query)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to resolve symbol: %v", err)), nil
+		}
+		if res.Ambiguous() {
+			return ambiguousTraceToolResult(query, res, format)
+		}
+		name := query
+		if res.Name != "" {
+			name = res.Name
+		}
+		names = append(names, name)
+	}
+
+	result, err := trace.FindCallPaths(ctx, stores, names[0], names[1], opts)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to find call paths: %v", err)), nil
+	}
+
+	output, err := encodeOutput(result, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
 func (s *Server) handleRefsReaders(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return s.handleRefsByKind(ctx, request, trace.RefKindRead)
 }
@@ -1571,24 +2226,17 @@ func (s *Server) handleRefsGraph(ctx context.Context, request mcp.CallToolReques
 
 	var stores []trace.SymbolStore
 	if workspace != "" {
-		stores, err = trace.LoadWorkspaceSymbolStores(ctx, workspace, project)
+		stores, err = loadWorkspaceSymbolStores(ctx, workspace, project)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", err)), nil
 		}
 		defer trace.CloseSymbolStores(stores)
 	} else {
-		if s.projectRoot == "" {
-			return mcp.NewToolResultError("refs requires a project context; use --workspace parameter or start mcp-serve from a project directory"), nil
-		}
-		symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
-		if err := symbolStore.Load(ctx); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'grepai watch' first", err)), nil
-		}
-		defer symbolStore.Close()
-		stats, err := symbolStore.GetStats(ctx)
-		if err != nil || stats.TotalSymbols == 0 {
-			return mcp.NewToolResultError("symbol index is empty. Run 'grepai watch' first to build the index"), nil
+		symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "refs")
+		if errResult != nil {
+			return errResult, nil
 		}
+		defer release()
 		stores = []trace.SymbolStore{symbolStore}
 	}
 
@@ -1691,7 +2339,7 @@ func (s *Server) handleRefsByKind(ctx context.Context, request mcp.CallToolReque
 
 	// Workspace mode
 	if workspace != "" {
-		stores, loadErr := trace.LoadWorkspaceSymbolStores(ctx, workspace, project)
+		stores, loadErr := loadWorkspaceSymbolStores(ctx, workspace, project)
 		if loadErr != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", loadErr)), nil
 		}
@@ -1700,20 +2348,11 @@ func (s *Server) handleRefsByKind(ctx context.Context, request mcp.CallToolReque
 	}
 
 	// Single-project mode
-	if s.projectRoot == "" {
-		return mcp.NewToolResultError("refs requires a project context; use --workspace parameter or start mcp-serve from a project directory"), nil
-	}
-
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
-	if err := symbolStore.Load(ctx); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to load symbol index: %v. Run 'grepai watch' first", err)), nil
-	}
-	defer symbolStore.Close()
-
-	stats, err := symbolStore.GetStats(ctx)
-	if err != nil || stats.TotalSymbols == 0 {
-		return mcp.NewToolResultError("symbol index is empty. Run 'grepai watch' first to build the index"), nil
+	symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "refs")
+	if errResult != nil {
+		return errResult, nil
 	}
+	defer release()
 
 	return s.handleRefsFromStores(ctx, symbolName, kind, compact, format, []trace.SymbolStore{symbolStore})
 }
@@ -1790,9 +2429,269 @@ func (s *Server) handleRefsFromStores(ctx context.Context, symbolName string, ki
 	return mcp.NewToolResultText(output), nil
 }
 
-func resolveRefCallerSymbol(ss trace.SymbolStore, ctx context.Context, ref trace.Reference) trace.Symbol {
-	if ref.CallerName == "" || ref.CallerName == "<top-level>" {
-		return trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
+// handleTraceReferences handles the grepai_trace_references tool call.
+func (s *Server) handleTraceReferences(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	symbolName, err := request.RequireString("symbol")
+	if err != nil {
+		return mcp.NewToolResultError("symbol parameter is required"), nil
+	}
+
+	compact := request.GetBool("compact", false)
+	format := request.GetString("format", "json")
+	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
+	project := request.GetString("project", "")
+	page := tracePageOptions(request)
+
+	if format != "json" && format != "toon" {
+		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
+	}
+	if err := page.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	kinds, err := trace.ParseReferenceKinds(request.GetString("kinds", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var stores []trace.SymbolStore
+	if workspace != "" {
+		stores, err = loadWorkspaceSymbolStores(ctx, workspace, project)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", err)), nil
+		}
+		defer trace.CloseSymbolStores(stores)
+	} else {
+		symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "trace references")
+		if errResult != nil {
+			return errResult, nil
+		}
+		defer release()
+		stores = []trace.SymbolStore{symbolStore}
+	}
+
+	result, err := trace.FindReferences(ctx, stores, symbolName, kinds, page)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if compact {
+		for i := range result.References {
+			result.References[i].Context = ""
+		}
+	}
+
+	output, err := encodeOutput(result, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// handleFindLiteral handles the grepai_find_literal tool call.
+func (s *Server) handleFindLiteral(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query := request.GetString("query", "")
+	compact := request.GetBool("compact", false)
+	format := request.GetString("format", "json")
+	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
+	project := request.GetString("project", "")
+	page := tracePageOptions(request)
+
+	if format != "json" && format != "toon" {
+		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
+	}
+	if err := page.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	category, err := trace.ParseLiteralCategory(request.GetString("kind", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var stores []trace.SymbolStore
+	if workspace != "" {
+		stores, err = loadWorkspaceSymbolStores(ctx, workspace, project)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", err)), nil
+		}
+		defer trace.CloseSymbolStores(stores)
+	} else {
+		symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "find literal")
+		if errResult != nil {
+			return errResult, nil
+		}
+		defer release()
+		stores = []trace.SymbolStore{symbolStore}
+	}
+
+	result, err := trace.FindLiterals(ctx, stores, query, category, page)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if compact {
+		for i := range result.Literals {
+			for j := range result.Literals[i].Usages {
+				result.Literals[i].Usages[j].Context = ""
+			}
+		}
+	}
+
+	output, err := encodeOutput(result, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// handleAPIRoutes handles the grepai_api_routes tool call.
+func (s *Server) handleAPIRoutes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	method := request.GetString("method", "")
+	path := request.GetString("path", "")
+	format := request.GetString("format", "json")
+	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
+	project := request.GetString("project", "")
+	page := tracePageOptions(request)
+
+	if format != "json" && format != "toon" {
+		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
+	}
+	if err := page.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var stores []trace.SymbolStore
+	if workspace != "" {
+		var err error
+		stores, err = loadWorkspaceSymbolStores(ctx, workspace, project)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", err)), nil
+		}
+		defer trace.CloseSymbolStores(stores)
+	} else {
+		symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "api routes")
+		if errResult != nil {
+			return errResult, nil
+		}
+		defer release()
+		stores = []trace.SymbolStore{symbolStore}
+	}
+
+	result, err := trace.FindRoutes(ctx, stores, method, path, page)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	output, err := encodeOutput(result, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// handleTestsFor handles the grepai_tests_for tool call.
+func (s *Server) handleTestsFor(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	target, err := request.RequireString("target")
+	if err != nil {
+		return mcp.NewToolResultError("target parameter is required"), nil
+	}
+	semantic := request.GetBool("semantic", true)
+	format := request.GetString("format", "json")
+	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
+	project := request.GetString("project", "")
+	page := trace.PageOptions{Limit: request.GetInt("limit", 10), Offset: request.GetInt("offset", 0)}
+
+	if format != "json" && format != "toon" {
+		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
+	}
+	if err := page.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var stores []trace.SymbolStore
+	if workspace != "" {
+		stores, err = loadWorkspaceSymbolStores(ctx, workspace, project)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace symbol stores: %v", err)), nil
+		}
+		defer trace.CloseSymbolStores(stores)
+		semantic = false
+	} else {
+		symbolStore, release, errResult := s.loadProjectSymbolStore(ctx, "tests for")
+		if errResult != nil {
+			return errResult, nil
+		}
+		defer release()
+		stores = []trace.SymbolStore{symbolStore}
+	}
+
+	testTarget, err := trace.ResolveTestTarget(ctx, stores, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Semantic similarity is a bonus: without an embedder or index, tests
+	// are still found by names and references.
+	var similar []trace.SimilarTest
+	if semantic {
+		similar, _ = s.similarTests(ctx, testTarget.Query())
+	}
+
+	result, err := trace.FindTestsFor(ctx, stores, testTarget, similar, page)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	output, err := encodeOutput(result, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode results: %v", err)), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// similarTests searches the project index for test code similar to query.
+// Boosting is left out, since it penalizes test files.
+func (s *Server) similarTests(ctx context.Context, query string) ([]trace.SimilarTest, error) {
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.CheckProjectIndexMetadata(s.projectRoot, cfg); err != nil {
+		return nil, err
+	}
+	emb, release, err := s.projectEmbedder(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	st, err := s.projectStore(ctx, cfg, indexVersion(s.projectRoot))
+	if err != nil {
+		return nil, err
+	}
+	docsEmb, err := embedder.NewDocsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	searcher := search.NewSearcher(st, emb, config.SearchConfig{})
+	if docsEmb != nil {
+		defer docsEmb.Close()
+		searcher.SetDocsEmbedder(docsEmb, cfg.Embedder.Docs.Model)
+	}
+	searcher.SetChunkFilter(newAccessPolicy(cfg.MCP.Access).keepChunk(func(c store.Chunk) bool {
+		return trace.IsTestFile(c.FilePath)
+	}))
+	results, err := searcher.Search(ctx, query, 20, "")
+	if err != nil {
+		return nil, err
+	}
+	similar := make([]trace.SimilarTest, len(results))
+	for i, r := range results {
+		similar[i] = trace.SimilarTest{File: r.Chunk.FilePath, StartLine: r.Chunk.StartLine, EndLine: r.Chunk.EndLine, Score: r.Score}
+	}
+	return similar, nil
+}
+
+func resolveRefCallerSymbol(ss trace.SymbolStore, ctx context.Context, ref trace.Reference) trace.Symbol {
+	if ref.CallerName == "" || ref.CallerName == "<top-level>" {
+		return trace.Symbol{Name: ref.CallerName, File: ref.CallerFile, Line: ref.CallerLine}
 	}
 
 	candidates, err := ss.LookupSymbol(ctx, ref.CallerName)
@@ -1814,6 +2713,11 @@ type WorkspaceIndexStatus struct {
 	Projects  []WorkspaceProjectStatus `json:"projects"`
 	Provider  string                   `json:"provider"`
 	Model     string                   `json:"model"`
+
+	// Directories, grouping files by project, and Languages are only
+	// reported in breakdown mode.
+	Directories []store.GroupStats `json:"directories,omitempty"`
+	Languages   []store.GroupStats `json:"languages,omitempty"`
 }
 
 // WorkspaceProjectStatus represents the status of a single project in a workspace.
@@ -1822,11 +2726,16 @@ type WorkspaceProjectStatus struct {
 	Path         string `json:"path"`
 	SymbolsReady bool   `json:"symbols_ready"`
 	TotalSymbols int    `json:"total_symbols"`
+
+	// Health is only reported in verbose mode.
+	Health *IndexHealth `json:"health,omitempty"`
 }
 
 // handleIndexStatus handles the grepai_index_status tool call.
 func (s *Server) handleIndexStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	format := request.GetString("format", "json")
+	verbose := request.GetBool("verbose", false)
+	breakdown := request.GetBool("breakdown", false)
 	workspace := s.resolveWorkspace(request.GetString("workspace", ""))
 
 	// Validate format
@@ -1855,10 +2764,40 @@ func (s *Server) handleIndexStatus(ctx context.Context, request mcp.CallToolRequ
 			Model:     ws.Embedder.Model,
 		}
 
+		// Verbose mode samples each project's files from the shared
+		// workspace store, and breakdown mode lists them.
+		var wsStore store.VectorStore
+		if verbose || breakdown {
+			if wsStore, err = s.createWorkspaceStore(ctx, ws); err != nil {
+				log.Printf("Warning: failed to open workspace store: %v", err)
+			} else {
+				defer wsStore.Close()
+			}
+		}
+		watcherPID := 0
+		if verbose {
+			if logDir, err := daemon.GetDefaultLogDir(); err == nil {
+				watcherPID, _ = daemon.GetRunningWorkspacePID(logDir, ws.Name)
+			}
+		}
+		if breakdown && wsStore != nil {
+			if files, err := wsStore.ListFilesWithStats(ctx); err != nil {
+				log.Printf("Warning: failed to list workspace files: %v", err)
+			} else {
+				// Paths start with the workspace name, then the project.
+				for i := range files {
+					files[i].Path = strings.TrimPrefix(files[i].Path, ws.Name+"/")
+				}
+				var wsStats store.IndexStats
+				wsStats.AddBreakdown(files)
+				wsStatus.Directories, wsStatus.Languages = wsStats.Directories, wsStats.Languages
+			}
+		}
+
 		for _, p := range ws.Projects {
 			ps := WorkspaceProjectStatus{
 				Name: p.Name,
-				Path: p.Path,
+				Path: s.paths.toClient(p.Path),
 			}
 			ss := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(p.Path))
 			if loadErr := ss.Load(ctx); loadErr == nil {
@@ -1868,6 +2807,9 @@ func (s *Server) handleIndexStatus(ctx context.Context, request mcp.CallToolRequ
 				}
 				ss.Close()
 			}
+			if verbose {
+				ps.Health = workspaceProjectHealth(ctx, wsStore, ws.Name, p, ps.SymbolsReady, watcherPID)
+			}
 			wsStatus.Projects = append(wsStatus.Projects, ps)
 		}
 
@@ -1890,11 +2832,10 @@ func (s *Server) handleIndexStatus(ctx context.Context, request mcp.CallToolRequ
 	}
 
 	// Initialize store
-	st, err := s.createStore(ctx, cfg)
+	st, err := s.projectStore(ctx, cfg, indexVersion(s.projectRoot))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to initialize store: %v", err)), nil
 	}
-	defer st.Close()
 
 	// Get stats
 	stats, err := st.GetStats(ctx)
@@ -1903,13 +2844,12 @@ func (s *Server) handleIndexStatus(ctx context.Context, request mcp.CallToolRequ
 	}
 
 	// Check symbol index
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(s.projectRoot))
 	symbolsReady := false
-	if err := symbolStore.Load(ctx); err == nil {
+	if symbolStore, release, err := s.loadSymbolStore(ctx); err == nil {
 		if symbolStats, err := symbolStore.GetStats(ctx); err == nil && symbolStats.TotalSymbols > 0 {
 			symbolsReady = true
 		}
-		symbolStore.Close()
+		release()
 	}
 
 	status := IndexStatus{
@@ -1920,6 +2860,7 @@ func (s *Server) handleIndexStatus(ctx context.Context, request mcp.CallToolRequ
 		Provider:     cfg.Embedder.Provider,
 		Model:        cfg.Embedder.Model,
 		SymbolsReady: symbolsReady,
+		AutoIndex:    s.autoIndexStatus(),
 	}
 
 	// Check RPG status
@@ -1935,6 +2876,33 @@ func (s *Server) handleIndexStatus(ctx context.Context, request mcp.CallToolRequ
 		rpgSt.Close()
 	}
 
+	if breakdown {
+		files, err := st.ListFilesWithStats(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list files: %v", err)), nil
+		}
+		stats.AddBreakdown(files)
+		status.Directories, status.Languages = stats.Directories, stats.Languages
+	}
+
+	if verbose {
+		health := &IndexHealth{
+			LastIndexed:  formatLastIndexed(cfg.Watch.LastIndexTime, stats.LastUpdated),
+			SymbolsReady: symbolsReady,
+			RPGReady:     status.RPGEnabled,
+		}
+		if health.SampledFiles, health.StaleFiles, err = sampleStaleFiles(ctx, st, s.projectRoot, ""); err != nil {
+			log.Printf("Warning: failed to sample indexed files: %v", err)
+		}
+		if s.usesWatcherIndex() {
+			health.Watcher = "in_process"
+		} else {
+			health.WatcherPID = projectWatcherPID(s.projectRoot)
+			health.Watcher = watcherState(health.WatcherPID)
+		}
+		status.Health = health
+	}
+
 	output, err := encodeOutput(status, format)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to encode status: %v", err)), nil
@@ -2018,7 +2986,7 @@ func (s *Server) handleListProjects(ctx context.Context, request mcp.CallToolReq
 	for _, proj := range wsEntry.Projects {
 		projects = append(projects, ProjectInfo{
 			Name: proj.Name,
-			Path: proj.Path,
+			Path: s.paths.toClient(proj.Path),
 		})
 	}
 
@@ -2030,6 +2998,150 @@ func (s *Server) handleListProjects(ctx context.Context, request mcp.CallToolReq
 	return mcp.NewToolResultText(output), nil
 }
 
+// UseWorkspaceResult is the output of the grepai_use_workspace tool.
+type UseWorkspaceResult struct {
+	Workspace string   `json:"workspace"`
+	Previous  string   `json:"previous,omitempty"`
+	Projects  []string `json:"projects,omitempty"`
+}
+
+// handleUseWorkspace handles the grepai_use_workspace tool call.
+func (s *Server) handleUseWorkspace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+	if _, ok := args["workspace"]; !ok {
+		return mcp.NewToolResultError("workspace parameter is required"), nil
+	}
+	workspace := strings.TrimSpace(request.GetString("workspace", ""))
+
+	format := request.GetString("format", "json")
+	if format != "json" && format != "toon" {
+		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
+	}
+
+	result := UseWorkspaceResult{Workspace: workspace}
+	if workspace == "" {
+		if s.projectRoot == "" {
+			return mcp.NewToolResultError("cannot clear the workspace: mcp-serve was not started in a project"), nil
+		}
+	} else {
+		wsCfg, err := config.LoadWorkspaceConfig()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace config: %v", err)), nil
+		}
+		if wsCfg == nil {
+			return mcp.NewToolResultError("no workspaces configured"), nil
+		}
+		ws, err := wsCfg.GetWorkspace(workspace)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("workspace not found: %v", err)), nil
+		}
+		result.Projects = listWorkspaceProjectNames(ws.Projects)
+	}
+
+	s.workspaceMu.Lock()
+	result.Previous = s.workspaceName
+	s.workspaceName = workspace
+	s.workspaceMu.Unlock()
+
+	output, err := encodeOutput(result, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode result: %v", err)), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
+// OwnersResult is the output of the grepai_owners tool.
+type OwnersResult struct {
+	Path       string   `json:"path"`
+	Owners     []string `json:"owners"`
+	Rule       string   `json:"rule,omitempty"`
+	Codeowners string   `json:"codeowners,omitempty"`
+}
+
+// handleOwners handles the grepai_owners tool call.
+func (s *Server) handleOwners(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return mcp.NewToolResultError("path parameter is required"), nil
+	}
+	path = s.paths.fromClient(path)
+
+	format := request.GetString("format", "json")
+	if format != "json" && format != "toon" {
+		return mcp.NewToolResultError("format must be 'json' or 'toon'"), nil
+	}
+
+	// The project's own root is used unless a workspace project is named.
+	root := s.projectRoot
+	workspace := request.GetString("workspace", "")
+	project := request.GetString("project", "")
+	if workspace != "" || (project != "" && s.resolveWorkspace("") != "") {
+		workspace = s.resolveWorkspace(workspace)
+		if project == "" {
+			return mcp.NewToolResultError("project parameter is required with workspace"), nil
+		}
+		wsCfg, err := config.LoadWorkspaceConfig()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to load workspace config: %v", err)), nil
+		}
+		if wsCfg == nil {
+			return mcp.NewToolResultError("no workspaces configured"), nil
+		}
+		ws, err := wsCfg.GetWorkspace(workspace)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("workspace not found: %v", err)), nil
+		}
+		root = ""
+		for _, p := range ws.Projects {
+			if p.Name == project {
+				root = p.Path
+				break
+			}
+		}
+		if root == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("project %q not found in workspace %q", project, workspace)), nil
+		}
+	}
+	if root == "" {
+		return mcp.NewToolResultError("owners lookup requires a project context; use the workspace and project parameters or start mcp-serve from a project directory"), nil
+	}
+
+	relPath := path
+	if filepath.IsAbs(path) {
+		if relPath, err = filepath.Rel(root, path); err != nil || strings.HasPrefix(relPath, "..") {
+			return mcp.NewToolResultError(fmt.Sprintf("path %q is outside the project", path)), nil
+		}
+	}
+	relPath = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(relPath)), "./")
+	if !projectAccessPolicy(root).allowed(relPath) {
+		return mcp.NewToolResultError(fmt.Sprintf("path %q is hidden by mcp.access", relPath)), nil
+	}
+
+	codeowners, err := git.LoadCodeowners(root)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read CODEOWNERS: %v", err)), nil
+	}
+	if codeowners == nil {
+		return mcp.NewToolResultError("no CODEOWNERS file found (looked in .github/, the repository root and docs/)"), nil
+	}
+
+	result := OwnersResult{
+		Path:       relPath,
+		Owners:     codeowners.Owners(relPath),
+		Rule:       codeowners.Rule(relPath),
+		Codeowners: codeowners.File,
+	}
+	if result.Owners == nil {
+		result.Owners = []string{}
+	}
+
+	output, err := encodeOutput(result, format)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode owners: %v", err)), nil
+	}
+	return mcp.NewToolResultText(output), nil
+}
+
 // createEmbedder creates an embedder based on configuration.
 func (s *Server) createEmbedder(cfg *config.Config) (embedder.Embedder, error) {
 	return embedder.NewFromConfig(cfg)
@@ -2037,38 +3149,56 @@ func (s *Server) createEmbedder(cfg *config.Config) (embedder.Embedder, error) {
 
 // createStore creates a vector store based on configuration.
 func (s *Server) createStore(ctx context.Context, cfg *config.Config) (store.VectorStore, error) {
-	switch cfg.Store.Backend {
-	case "gob":
-		indexPath := config.GetIndexPath(s.projectRoot)
-		gobStore := store.NewGOBStore(indexPath)
-		if err := gobStore.Load(ctx); err != nil {
-			return nil, fmt.Errorf("failed to load index: %w", err)
-		}
-		return gobStore, nil
-	case "postgres":
-		return store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, s.projectRoot, cfg.Embedder.GetDimensions())
-	case "qdrant":
-		collectionName := cfg.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = store.SanitizeCollectionName(s.projectRoot)
+	return store.NewFromConfig(ctx, cfg, s.projectRoot)
+}
+
+// CallTool runs the tool registered under name with args, the way an MCP
+// client call would. It returns the tool's text output and whether the tool
+// reported an error.
+func (s *Server) CallTool(ctx context.Context, name string, args map[string]any) (string, bool, error) {
+	tool, ok := s.mcpServer.ListTools()[name]
+	if !ok {
+		return "", false, fmt.Errorf("unknown tool %q", name)
+	}
+	result, err := tool.Handler(ctx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: name, Arguments: args},
+	})
+	if err != nil {
+		return "", false, err
+	}
+	var text strings.Builder
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text.WriteString(textContent.Text)
 		}
-		return store.NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
-	default:
-		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
 	}
+	return text.String(), result.IsError, nil
 }
 
 // Serve starts the MCP server using stdio transport.
 func (s *Server) Serve() error {
+	return s.ServeContext(context.Background())
+}
+
+// ServeContext starts the MCP server using stdio transport and returns when
+// stdin is closed or ctx is done.
+func (s *Server) ServeContext(ctx context.Context) error {
 	// Create stdio server with title fix wrapper
 	stdioServer := server.NewStdioServer(s.mcpServer)
 
 	// Wrap stdout to intercept and fix responses
 	fixedStdout := &titleFixWriter{Writer: os.Stdout}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.watchIndexSignal(ctx, indexSignalPollInterval)
+
 	// Start listening with fixed stdout
-	ctx := context.Background()
-	return stdioServer.Listen(ctx, os.Stdin, fixedStdout)
+	err := stdioServer.Listen(ctx, os.Stdin, fixedStdout)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
 }
 
 // titleFixWriter wraps io.Writer to fix tool titles in responses
@@ -2138,6 +3268,25 @@ func formatBytes(b int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
+// featureChunkFilter returns a filter keeping chunks from files that the RPG
+// graph places under the given feature path, or a tool error result.
+func (s *Server) featureChunkFilter(ctx context.Context, feature string) (func(store.Chunk) bool, *mcp.CallToolResult) {
+	rpgSt, qe, err := s.tryLoadRPG(ctx)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("feature filter unavailable: %v", err))
+	}
+	if rpgSt == nil {
+		return nil, mcp.NewToolResultError("feature filter requires RPG to be enabled and indexed (rpg.enabled: true)")
+	}
+	defer rpgSt.Close()
+
+	files := qe.FilesInFeature(feature)
+	if len(files) == 0 {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("no files found under feature %q", feature))
+	}
+	return func(c store.Chunk) bool { return files[c.FilePath] }, nil
+}
+
 // tryLoadRPG attempts to load the RPG store. Returns nil values if RPG is disabled or unavailable.
 func (s *Server) tryLoadRPG(ctx context.Context) (rpg.RPGStore, *rpg.QueryEngine, error) {
 	if s.projectRoot == "" {
@@ -2162,7 +3311,7 @@ func (s *Server) tryLoadRPG(ctx context.Context) (rpg.RPGStore, *rpg.QueryEngine
 		rpgStore.Close()
 		return nil, nil, nil
 	}
-	qe := rpg.NewQueryEngine(graph)
+	qe := rpg.NewQueryEngine(newAccessPolicy(cfg.MCP.Access).restrictGraph(graph))
 	return rpgStore, qe, nil
 }
 
@@ -281,3 +281,65 @@ func TestHandleSearch_NoWorkspaceHint_WhenNoWorkspacesConfigured(t *testing.T) {
 		t.Fatalf("workspace hint should not appear when no workspaces are configured: %s", textContent.Text)
 	}
 }
+
+func TestHandleUseWorkspace_SwitchesSessionDefault(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("USERPROFILE", homeDir)
+
+	cfg := &config.WorkspaceConfig{
+		Version: 1,
+		Workspaces: map[string]config.Workspace{
+			"backend": {
+				Name:     "backend",
+				Projects: []config.ProjectEntry{{Name: "api", Path: "/tmp/backend-api"}},
+			},
+		},
+	}
+	if err := config.SaveWorkspaceConfig(cfg); err != nil {
+		t.Fatalf("failed to save workspace config: %v", err)
+	}
+
+	s := &Server{projectRoot: "/tmp/project"}
+	call := func(args map[string]any) string {
+		t.Helper()
+		result, err := s.handleUseWorkspace(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Arguments: args},
+		})
+		if err != nil {
+			t.Fatalf("handleUseWorkspace returned error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	out := call(map[string]any{"workspace": "backend"})
+	var res UseWorkspaceResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("failed to decode response %q: %v", out, err)
+	}
+	if res.Workspace != "backend" || res.Previous != "" || len(res.Projects) != 1 || res.Projects[0] != "api" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if got := s.resolveWorkspace(""); got != "backend" {
+		t.Fatalf("resolveWorkspace fallback = %q, want backend", got)
+	}
+	if got := s.resolveWorkspace("other"); got != "other" {
+		t.Fatalf("explicit workspace should win, got %q", got)
+	}
+
+	if out := call(map[string]any{"workspace": "missing"}); !strings.Contains(out, "workspace not found") {
+		t.Fatalf("expected unknown workspace error, got %q", out)
+	}
+	if got := s.resolveWorkspace(""); got != "backend" {
+		t.Fatalf("failed switch must keep the default, got %q", got)
+	}
+
+	call(map[string]any{"workspace": ""})
+	if got := s.resolveWorkspace(""); got != "" {
+		t.Fatalf("expected cleared workspace, got %q", got)
+	}
+
+	if out := call(map[string]any{}); !strings.Contains(out, "workspace parameter is required") {
+		t.Fatalf("expected missing parameter error, got %q", out)
+	}
+}
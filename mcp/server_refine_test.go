@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestHandleSearch_PreviousResultIDs(t *testing.T) {
+	root := newUnindexedProject(t, false)
+	ctx := context.Background()
+	gob := store.NewGOBStore(config.GetIndexPath(root))
+	if err := gob.SaveChunks(ctx, []store.Chunk{
+		{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 2, Content: "func A() {}", Vector: []float32{1, 0, 0}},
+		{ID: "b.go_0", FilePath: "b.go", StartLine: 1, EndLine: 2, Content: "func B() {}", Vector: []float32{0.6, 0.8, 0}},
+		{ID: "c.go_0", FilePath: "c.go", StartLine: 1, EndLine: 2, Content: "func C() {}", Vector: []float32{0, 1, 0}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"a.go", "b.go", "c.go"} {
+		if err := gob.SaveDocument(ctx, store.Document{Path: path, ChunkIDs: []string{path + "_0"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gob.Persist(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewServer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{
+		"query":               "func",
+		"compact":             true,
+		"previous_result_ids": []any{"c.go_0", "b.go_0"},
+	}}}
+	result, err := s.handleSearch(ctx, req)
+	if err != nil || result.IsError {
+		t.Fatalf("search failed: %v %+v", err, result)
+	}
+
+	var results []SearchResultCompact
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != "b.go_0" || results[1].ID != "c.go_0" {
+		t.Fatalf("expected only the previous results, re-ranked, got %+v", results)
+	}
+}
@@ -3,6 +3,8 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +13,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/coverage"
 	"github.com/yoanbernabeu/grepai/store"
 	"github.com/yoanbernabeu/grepai/trace"
 )
@@ -605,6 +608,40 @@ func TestHandleRefsTools_return_expected_readers_and_graph(t *testing.T) {
 	}
 }
 
+func TestHandleTraceReferences(t *testing.T) {
+	projectRoot := seedRefsTestStore(t)
+	s := &Server{projectRoot: projectRoot}
+
+	result, err := s.handleTraceReferences(context.Background(), refsTestRequest(map[string]any{
+		"symbol":  "state.uid",
+		"compact": true,
+	}))
+	if err != nil {
+		t.Fatalf("handleTraceReferences returned error: %v", err)
+	}
+	var payload trace.ReferencesResult
+	if err := json.Unmarshal([]byte(textResultPayload(t, result)), &payload); err != nil {
+		t.Fatalf("failed to decode references payload: %v", err)
+	}
+	if payload.Total != 2 || payload.Counts[trace.RefKindRead] != 1 || payload.Counts[trace.RefKindWrite] != 1 {
+		t.Fatalf("expected one read and one write, got %+v", payload)
+	}
+	if ref := payload.References[0]; ref.Line != 12 || ref.Caller != "uidConsumer" || ref.Context != "" {
+		t.Fatalf("unexpected compact reference: %+v", ref)
+	}
+
+	result, err = s.handleTraceReferences(context.Background(), refsTestRequest(map[string]any{
+		"symbol": "uid",
+		"kinds":  "calls",
+	}))
+	if err != nil {
+		t.Fatalf("handleTraceReferences returned error: %v", err)
+	}
+	if got := textResultPayload(t, result); !result.IsError || !strings.Contains(got, "invalid kind") {
+		t.Fatalf("expected invalid kind error, got %q", got)
+	}
+}
+
 func TestValidateWorkspacePathForProjects_should_return_structured_hint_for_invalid_path(t *testing.T) {
 	projectRoot := filepath.Join(t.TempDir(), "ubermap_agent")
 	if err := os.MkdirAll(filepath.Join(projectRoot, "MM32", "src"), 0755); err != nil {
@@ -826,7 +863,7 @@ func TestHandleTraceCallersFromStores_should_aggregate_across_stores(t *testing.
 	s := &Server{}
 	stores := []trace.SymbolStore{store1, store2}
 
-	result, err := s.handleTraceCallersFromStores(ctx, "Login", false, "json", stores)
+	result, err := s.handleTraceCallersFromStores(ctx, "Login", false, "json", trace.PageOptions{}, nil, responseLimits{}, stores)
 	if err != nil {
 		t.Fatalf("handleTraceCallersFromStores returned error: %v", err)
 	}
@@ -881,7 +918,7 @@ func TestHandleTraceCalleesFromStores_should_aggregate_across_stores(t *testing.
 	s := &Server{}
 	stores := []trace.SymbolStore{store1, store2}
 
-	result, err := s.handleTraceCalleesFromStores(ctx, "HandleRequest", false, "json", stores)
+	result, err := s.handleTraceCalleesFromStores(ctx, "HandleRequest", false, "json", trace.PageOptions{}, nil, responseLimits{}, stores)
 	if err != nil {
 		t.Fatalf("handleTraceCalleesFromStores returned error: %v", err)
 	}
@@ -900,3 +937,208 @@ func TestHandleTraceCalleesFromStores_should_aggregate_across_stores(t *testing.
 		t.Errorf("expected result to contain callee 'SendResponse', got: %s", text)
 	}
 }
+
+func TestHandleTraceCallersFromStores_should_sort_and_paginate(t *testing.T) {
+	ctx := context.Background()
+	store1 := trace.NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	store2 := trace.NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+
+	if err := store1.SaveFile(ctx, "/web/auth.go", []trace.Symbol{
+		{Name: "Login", Kind: "function", File: "/web/auth.go", Line: 10, Language: "go"},
+	}, []trace.Reference{
+		{SymbolName: "Login", File: "/web/z.go", Line: 3, CallerName: "Zeta", CallerFile: "/web/z.go", CallerLine: 1},
+	}); err != nil {
+		t.Fatalf("store1.SaveFile failed: %v", err)
+	}
+	if err := store2.SaveFile(ctx, "/api/auth.go", []trace.Symbol{
+		{Name: "Login", Kind: "function", File: "/api/auth.go", Line: 10, Language: "go"},
+	}, []trace.Reference{
+		{SymbolName: "Login", File: "/api/a.go", Line: 8, CallerName: "Alpha", CallerFile: "/api/a.go", CallerLine: 1},
+		{SymbolName: "Login", File: "/api/a.go", Line: 4, CallerName: "Alpha", CallerFile: "/api/a.go", CallerLine: 1},
+	}); err != nil {
+		t.Fatalf("store2.SaveFile failed: %v", err)
+	}
+
+	s := &Server{}
+	stores := []trace.SymbolStore{
+		&trace.ProjectSymbolStore{SymbolStore: store1, Project: "web"},
+		&trace.ProjectSymbolStore{SymbolStore: store2, Project: "api"},
+	}
+
+	result, err := s.handleTraceCallersFromStores(ctx, "Login", false, "json", trace.PageOptions{Sort: trace.SortByProject, Limit: 2, Offset: 1}, nil, responseLimits{}, stores)
+	if err != nil {
+		t.Fatalf("handleTraceCallersFromStores returned error: %v", err)
+	}
+
+	var decoded trace.TraceResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &decoded); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if decoded.Total != 3 || decoded.Offset != 1 {
+		t.Fatalf("total/offset = %d/%d, want 3/1", decoded.Total, decoded.Offset)
+	}
+	if len(decoded.Callers) != 2 {
+		t.Fatalf("callers = %d, want 2", len(decoded.Callers))
+	}
+	if decoded.Callers[0].Project != "api" || decoded.Callers[0].CallSite.Line != 8 {
+		t.Fatalf("unexpected first caller: %+v", decoded.Callers[0])
+	}
+	if decoded.Callers[1].Project != "web" || decoded.Callers[1].Symbol.Name != "Zeta" {
+		t.Fatalf("unexpected second caller: %+v", decoded.Callers[1])
+	}
+}
+
+func TestHandleTracePathFromStores_should_return_shortest_path(t *testing.T) {
+	ctx := context.Background()
+	store := trace.NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	if err := store.SaveFile(ctx, "/app/handler.go", []trace.Symbol{
+		{Name: "HandleRequest", Kind: "function", File: "/app/handler.go", Line: 1, Language: "go"},
+		{Name: "QueryRow", Kind: "function", File: "/app/db.go", Line: 1, Language: "go"},
+	}, []trace.Reference{
+		{SymbolName: "LoadUser", File: "/app/handler.go", Line: 3, CallerName: "HandleRequest", CallerFile: "/app/handler.go"},
+		{SymbolName: "QueryRow", File: "/app/handler.go", Line: 12, CallerName: "LoadUser", CallerFile: "/app/handler.go"},
+	}); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+
+	s := &Server{}
+	result, err := s.handleTracePathFromStores(ctx, "handlerequest", "QueryRow", trace.PathOptions{}, "json", []trace.SymbolStore{store})
+	if err != nil {
+		t.Fatalf("handleTracePathFromStores returned error: %v", err)
+	}
+
+	var decoded trace.PathResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &decoded); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if decoded.From != "HandleRequest" {
+		t.Fatalf("from = %q, want resolved HandleRequest", decoded.From)
+	}
+	if len(decoded.Paths) != 1 || len(decoded.Paths[0].Steps) != 2 {
+		t.Fatalf("unexpected paths: %+v", decoded.Paths)
+	}
+	if step := decoded.Paths[0].Steps[1]; step.Caller != "LoadUser" || step.Line != 12 {
+		t.Fatalf("unexpected last step: %+v", step)
+	}
+}
+
+func TestHandleOwners_should_map_path_to_codeowners(t *testing.T) {
+	projectRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectRoot, ".github"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	codeowners := "* @org/core\n/docs/ @org/docs\n/vendor/\n"
+	if err := os.WriteFile(filepath.Join(projectRoot, ".github", "CODEOWNERS"), []byte(codeowners), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{projectRoot: projectRoot}
+
+	result, err := s.handleOwners(context.Background(), refsTestRequest(map[string]any{"path": "./docs/guide.md"}))
+	if err != nil {
+		t.Fatalf("handleOwners returned error: %v", err)
+	}
+	var owners OwnersResult
+	if err := json.Unmarshal([]byte(textResultPayload(t, result)), &owners); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if owners.Path != "docs/guide.md" || len(owners.Owners) != 1 || owners.Owners[0] != "@org/docs" || owners.Rule != "/docs/" {
+		t.Fatalf("unexpected owners result: %+v", owners)
+	}
+
+	result, err = s.handleOwners(context.Background(), refsTestRequest(map[string]any{"path": "vendor/lib.go"}))
+	if err != nil {
+		t.Fatalf("handleOwners returned error: %v", err)
+	}
+	if got := textResultPayload(t, result); !strings.Contains(got, `"owners": []`) {
+		t.Fatalf("expected explicitly unowned path, got %s", got)
+	}
+}
+
+func TestHandleOwners_should_report_missing_codeowners(t *testing.T) {
+	s := &Server{projectRoot: t.TempDir()}
+
+	result, err := s.handleOwners(context.Background(), refsTestRequest(map[string]any{"path": "main.go"}))
+	if err != nil {
+		t.Fatalf("handleOwners returned error: %v", err)
+	}
+	if got := textResultPayload(t, result); !strings.Contains(got, "no CODEOWNERS file found") {
+		t.Fatalf("expected missing CODEOWNERS error, got %q", got)
+	}
+}
+
+func TestAttachTraceCoverage(t *testing.T) {
+	projectRoot := t.TempDir()
+	if err := os.MkdirAll(config.GetConfigDir(projectRoot), 0755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	profile, err := coverage.ParseLCOV(strings.NewReader("SF:app.go\nDA:3,1\nDA:4,0\nend_of_record\n"), projectRoot)
+	if err != nil {
+		t.Fatalf("failed to parse coverage: %v", err)
+	}
+	if err := profile.Save(config.GetCoveragePath(projectRoot)); err != nil {
+		t.Fatalf("failed to save coverage: %v", err)
+	}
+
+	s := &Server{projectRoot: projectRoot}
+	symbolStore := trace.NewGOBSymbolStore(filepath.Join(projectRoot, "symbols.gob"))
+	covered := &trace.Symbol{Name: "Run", File: "app.go", Line: 2, EndLine: 5}
+	unknown := &trace.Symbol{Name: "Stop", File: "other.go", Line: 2}
+	s.attachTraceCoverage([]trace.SymbolStore{symbolStore}, covered, unknown, nil)
+	if covered.Coverage == nil || *covered.Coverage != (coverage.Coverage{Covered: 1, Lines: 2}) {
+		t.Errorf("expected 1 of 2 lines covered, got %+v", covered.Coverage)
+	}
+	if unknown.Coverage != nil {
+		t.Errorf("expected no coverage for a file outside the profile, got %+v", unknown.Coverage)
+	}
+
+	workspaceSym := &trace.Symbol{Name: "Run", File: "app.go", Line: 2, EndLine: 5}
+	s.attachTraceCoverage([]trace.SymbolStore{&trace.ProjectSymbolStore{SymbolStore: symbolStore, Project: "api"}}, workspaceSym)
+	if workspaceSym.Coverage != nil {
+		t.Errorf("expected workspace symbols to be skipped, got %+v", workspaceSym.Coverage)
+	}
+}
+
+func TestHandleSearch_Scope(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"embedding":[1,0,0]}`))
+	}))
+	defer ollama.Close()
+
+	root := t.TempDir()
+	dims := 3
+	cfg := config.DefaultConfig()
+	cfg.Embedder = config.EmbedderConfig{Provider: "ollama", Endpoint: ollama.URL, Model: "test", Dimensions: &dims}
+	cfg.Scopes = map[string]config.Scope{"src": {ExcludeTests: true}}
+	if err := cfg.Save(root); err != nil {
+		t.Fatal(err)
+	}
+	gob := store.NewGOBStore(config.GetIndexPath(root))
+	if err := gob.SaveChunks(context.Background(), []store.Chunk{
+		{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 2, Content: "func A() {}", Vector: []float32{1, 0, 0}},
+		{ID: "a_test.go_0", FilePath: "a_test.go", StartLine: 1, EndLine: 2, Content: "func TestA() {}", Vector: []float32{1, 0, 0}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gob.Persist(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewServer(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"query": "func A", "scope": "src"}}}
+	result, err := s.handleSearch(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("search failed: %v %+v", err, result)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, `"a.go"`) || strings.Contains(text, "a_test.go") {
+		t.Errorf("expected only the non-test chunk, got %s", text)
+	}
+
+	req.Params.Arguments = map[string]any{"query": "func A", "scope": "docs"}
+	if result, _ := s.handleSearch(context.Background(), req); !result.IsError {
+		t.Error("expected an error for an unknown scope")
+	}
+}
@@ -0,0 +1,66 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client calls tools on a remote grepai server.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns a client for the server at baseURL, e.g.
+// "http://index.internal:8765", authenticating with token.
+func NewClient(baseURL, token string) *Client {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// CallTool runs the tool name on the server and returns its text output.
+// Tool errors are returned as errors carrying the tool's message.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (string, error) {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+toolsPath+name, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach remote server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded Response
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return "", fmt.Errorf("invalid response from remote server (HTTP %d): %w", resp.StatusCode, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return "", fmt.Errorf("remote server rejected the token; check %s", TokenEnv)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "", fmt.Errorf("remote server is rate limiting this token (retry after %ss): %s", resp.Header.Get("Retry-After"), decoded.Error)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("%s", decoded.Error)
+	}
+	return decoded.Output, nil
+}
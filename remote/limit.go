@@ -0,0 +1,105 @@
+package remote
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limits caps how much of the server each token can use. Zero fields
+// disable the corresponding cap.
+type Limits struct {
+	// RequestsPerMinute is the sustained request rate of a token.
+	RequestsPerMinute int
+	// Burst is how many requests a token can make at once before being held
+	// to RequestsPerMinute. Defaults to min(RequestsPerMinute, 10).
+	Burst int
+	// MaxConcurrent is the number of requests of a token served in parallel.
+	MaxConcurrent int
+}
+
+func (l Limits) enabled() bool {
+	return l.RequestsPerMinute > 0 || l.MaxConcurrent > 0
+}
+
+func (l Limits) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(min(l.RequestsPerMinute, 10))
+}
+
+// Limiter enforces Limits per token with a token bucket and a count of
+// in-flight requests. The HTTP and gRPC servers share one, so a token has
+// the same budget whichever API it calls. A nil Limiter admits every
+// request.
+type Limiter struct {
+	limits Limits
+	now    func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*clientUsage
+}
+
+type clientUsage struct {
+	allowance float64 // requests available now
+	updated   time.Time
+	active    int
+}
+
+// NewLimiter returns a limiter enforcing limits, or nil when they set no
+// cap.
+func NewLimiter(limits Limits) *Limiter {
+	if !limits.enabled() {
+		return nil
+	}
+	return &Limiter{
+		limits:  limits,
+		now:     time.Now,
+		clients: make(map[string]*clientUsage),
+	}
+}
+
+// Acquire admits a request of key. On success it returns the function
+// releasing the request's concurrency slot; otherwise it returns how long
+// the client should wait before retrying.
+func (l *Limiter) Acquire(key string) (release func(), retryAfter time.Duration, ok bool) {
+	if l == nil {
+		return func() {}, 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	usage, found := l.clients[key]
+	if !found {
+		usage = &clientUsage{allowance: l.limits.burst(), updated: now}
+		l.clients[key] = usage
+	}
+
+	if l.limits.MaxConcurrent > 0 && usage.active >= l.limits.MaxConcurrent {
+		return nil, time.Second, false
+	}
+	if l.limits.RequestsPerMinute > 0 {
+		perSecond := float64(l.limits.RequestsPerMinute) / 60
+		usage.allowance = math.Min(l.limits.burst(), usage.allowance+now.Sub(usage.updated).Seconds()*perSecond)
+		usage.updated = now
+		if usage.allowance < 1 {
+			return nil, time.Duration((1 - usage.allowance) / perSecond * float64(time.Second)), false
+		}
+		usage.allowance--
+	}
+
+	usage.active++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		usage.active--
+	}, 0, true
+}
+
+// RetryAfterSeconds formats d for a Retry-After header, rounding up to
+// whole seconds.
+func RetryAfterSeconds(d time.Duration) int {
+	return max(1, int(math.Ceil(d.Seconds())))
+}
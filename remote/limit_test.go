@@ -0,0 +1,47 @@
+package remote
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_RefillsOverTime(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewLimiter(Limits{RequestsPerMinute: 30, Burst: 1})
+	l.now = func() time.Time { return now }
+
+	if _, _, ok := l.Acquire("a"); !ok {
+		t.Fatal("expected the first request to be admitted")
+	}
+	_, retryAfter, ok := l.Acquire("a")
+	if ok {
+		t.Fatal("expected the second request to be limited")
+	}
+	if retryAfter != 2*time.Second {
+		t.Errorf("retryAfter = %v, want 2s at 30 requests per minute", retryAfter)
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, _, ok := l.Acquire("a"); !ok {
+		t.Error("expected a request to be admitted after the refill")
+	}
+}
+
+func TestLimiter_MaxConcurrent(t *testing.T) {
+	l := NewLimiter(Limits{MaxConcurrent: 1})
+
+	release, _, ok := l.Acquire("a")
+	if !ok {
+		t.Fatal("expected the first request to be admitted")
+	}
+	if _, _, ok := l.Acquire("a"); ok {
+		t.Error("expected a second concurrent request to be refused")
+	}
+	if _, _, ok := l.Acquire("b"); !ok {
+		t.Error("expected another token to be admitted")
+	}
+	release()
+	if _, _, ok := l.Acquire("a"); !ok {
+		t.Error("expected a request to be admitted once the first finished")
+	}
+}
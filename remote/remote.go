@@ -0,0 +1,165 @@
+// Package remote serves grepai tools over HTTP, so search and trace commands
+// can query the index of a central 'grepai serve --listen' instance instead
+// of opening a store locally.
+//
+// The protocol is JSON over HTTP: POST /v1/tools/{name} with the tool
+// arguments as a JSON object returns a Response. Requests must carry one of
+// the server's tokens as "Authorization: Bearer <token>". Requests over a
+// token's Limits are answered 429 with a Retry-After header.
+package remote
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TokenEnv holds the client's token. On the server, it holds the
+	// accepted tokens, comma-separated.
+	TokenEnv = "GREPAI_REMOTE_TOKEN"
+	// URLEnv holds the default server URL of --remote.
+	URLEnv = "GREPAI_REMOTE"
+
+	toolsPath  = "/v1/tools/"
+	healthPath = "/v1/health"
+
+	maxRequestBytes = 1 << 20
+)
+
+// ToolCaller runs a grepai tool, returning its text output and whether the
+// tool reported an error.
+type ToolCaller interface {
+	CallTool(ctx context.Context, name string, args map[string]any) (string, bool, error)
+}
+
+// Response is the body of every tools response. Output is set on success,
+// Error otherwise.
+type Response struct {
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// blockedTools change session state that would leak between clients.
+var blockedTools = map[string]bool{
+	"grepai_use_workspace": true,
+}
+
+// NewHandler returns the HTTP handler serving tools to clients presenting
+// one of the comma-separated tokens, within the limits of limiter per token.
+func NewHandler(tools ToolCaller, tokens string, limiter *Limiter) http.Handler {
+	accepted := Tokens(tokens)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc(toolsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+			return
+		}
+		token, ok := MatchToken(r.Header.Get("Authorization"), accepted)
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, Response{Error: "invalid or missing token"})
+			return
+		}
+		release, retryAfter, ok := limiter.Acquire(token)
+		if !ok {
+			seconds := RetryAfterSeconds(retryAfter)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			writeJSON(w, http.StatusTooManyRequests, Response{Error: fmt.Sprintf("rate limit exceeded; retry in %ds", seconds)})
+			return
+		}
+		defer release()
+		name := strings.TrimPrefix(r.URL.Path, toolsPath)
+		if name == "" || strings.Contains(name, "/") || blockedTools[name] {
+			writeJSON(w, http.StatusNotFound, Response{Error: "unknown tool " + name})
+			return
+		}
+
+		args := map[string]any{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&args); err != nil {
+			writeJSON(w, http.StatusBadRequest, Response{Error: "invalid arguments: " + err.Error()})
+			return
+		}
+
+		output, isError, err := tools.CallTool(r.Context(), name, args)
+		switch {
+		case err != nil:
+			writeJSON(w, http.StatusInternalServerError, Response{Error: err.Error()})
+		case isError:
+			writeJSON(w, http.StatusUnprocessableEntity, Response{Error: output})
+		default:
+			writeJSON(w, http.StatusOK, Response{Output: output})
+		}
+	})
+	return mux
+}
+
+// Tokens splits a comma-separated list of tokens, as held by TokenEnv on
+// the server.
+func Tokens(value string) []string {
+	var tokens []string
+	for _, token := range strings.Split(value, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// MatchToken returns the token of tokens that the authorization header
+// value presents as a bearer token.
+func MatchToken(header string, tokens []string) (string, bool) {
+	got, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || got == "" {
+		return "", false
+	}
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// ListenAndServe serves handler on addr until ctx is done, then shuts down
+// gracefully.
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Warning: remote server shutdown: %v", err)
+		}
+	}()
+	log.Printf("Listening for remote clients on %s", listener.Addr())
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package remote
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeTools struct {
+	calls []string
+}
+
+func (f *fakeTools) CallTool(ctx context.Context, name string, args map[string]any) (string, bool, error) {
+	f.calls = append(f.calls, name)
+	if args["query"] == "" {
+		return "query parameter is required", true, nil
+	}
+	return `[{"file_path":"` + args["query"].(string) + `.go"}]`, false, nil
+}
+
+func TestClient_CallTool(t *testing.T) {
+	tools := &fakeTools{}
+	srv := httptest.NewServer(NewHandler(tools, "secret", NewLimiter(Limits{})))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "secret")
+	out, err := client.CallTool(context.Background(), "grepai_search", map[string]any{"query": "auth"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if out != `[{"file_path":"auth.go"}]` {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	_, err = client.CallTool(context.Background(), "grepai_search", map[string]any{"query": ""})
+	if err == nil || err.Error() != "query parameter is required" {
+		t.Errorf("expected the tool error, got %v", err)
+	}
+}
+
+func TestClient_RejectsWrongToken(t *testing.T) {
+	tools := &fakeTools{}
+	srv := httptest.NewServer(NewHandler(tools, "secret", NewLimiter(Limits{})))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "guess").CallTool(context.Background(), "grepai_search", map[string]any{"query": "auth"})
+	if err == nil || !strings.Contains(err.Error(), TokenEnv) {
+		t.Errorf("expected a token error, got %v", err)
+	}
+	if len(tools.calls) != 0 {
+		t.Errorf("expected no tool call without a valid token, got %v", tools.calls)
+	}
+}
+
+func TestHandler_BlocksSessionTools(t *testing.T) {
+	tools := &fakeTools{}
+	srv := httptest.NewServer(NewHandler(tools, "secret", NewLimiter(Limits{})))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "secret").CallTool(context.Background(), "grepai_use_workspace", map[string]any{})
+	if err == nil {
+		t.Error("expected grepai_use_workspace to be refused")
+	}
+	if len(tools.calls) != 0 {
+		t.Errorf("expected no tool call, got %v", tools.calls)
+	}
+}
+
+func TestHandler_AcceptsSeveralTokens(t *testing.T) {
+	tools := &fakeTools{}
+	srv := httptest.NewServer(NewHandler(tools, "alpha, beta", NewLimiter(Limits{})))
+	defer srv.Close()
+
+	for _, token := range []string{"alpha", "beta"} {
+		if _, err := NewClient(srv.URL, token).CallTool(context.Background(), "grepai_search", map[string]any{"query": "auth"}); err != nil {
+			t.Errorf("token %q: unexpected error %v", token, err)
+		}
+	}
+	if _, err := NewClient(srv.URL, "alpha, beta").CallTool(context.Background(), "grepai_search", map[string]any{"query": "auth"}); err == nil {
+		t.Error("expected the token list itself to be rejected")
+	}
+}
+
+func TestHandler_RateLimitsPerToken(t *testing.T) {
+	tools := &fakeTools{}
+	srv := httptest.NewServer(NewHandler(tools, "alpha,beta", NewLimiter(Limits{RequestsPerMinute: 60, Burst: 2})))
+	defer srv.Close()
+
+	alpha := NewClient(srv.URL, "alpha")
+	for i := 0; i < 2; i++ {
+		if _, err := alpha.CallTool(context.Background(), "grepai_search", map[string]any{"query": "auth"}); err != nil {
+			t.Fatalf("request %d: unexpected error %v", i, err)
+		}
+	}
+	_, err := alpha.CallTool(context.Background(), "grepai_search", map[string]any{"query": "auth"})
+	if err == nil || !strings.Contains(err.Error(), "retry after 1s") {
+		t.Errorf("expected a rate limit error with a retry hint, got %v", err)
+	}
+
+	// Other tokens keep their own budget.
+	if _, err := NewClient(srv.URL, "beta").CallTool(context.Background(), "grepai_search", map[string]any{"query": "auth"}); err != nil {
+		t.Errorf("expected beta to be served, got %v", err)
+	}
+}
@@ -0,0 +1,353 @@
+package rpg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Export formats supported by Export.
+const (
+	ExportDOT     = "dot"
+	ExportMermaid = "mermaid"
+	ExportJSON    = "json"
+)
+
+// ExportOptions controls which part of the graph is exported.
+type ExportOptions struct {
+	// Feature restricts the export to the subtree under this feature path
+	// (e.g. "auth" or "auth/session"). Empty exports the whole graph.
+	Feature string
+	// IncludeChunks adds chunk nodes and maps_to_chunk edges, which are
+	// omitted by default to keep diagrams readable.
+	IncludeChunks bool
+}
+
+// ExportCluster is a feature (area, category or subcategory) grouping
+// implementation nodes in an exported graph.
+type ExportCluster struct {
+	ID      string   `json:"id"`
+	Kind    NodeKind `json:"kind"`
+	Feature string   `json:"feature"`
+	Parent  string   `json:"parent,omitempty"`
+	Members []string `json:"members,omitempty"`
+}
+
+// ExportGraph is the format-independent view of an exported graph. Feature
+// hierarchy is expressed by clusters; Edges hold the remaining relationships.
+type ExportGraph struct {
+	Clusters []ExportCluster `json:"clusters"`
+	Nodes    []*Node         `json:"nodes"`
+	Edges    []*Edge         `json:"edges"`
+}
+
+// BuildExportGraph clusters implementation nodes under their feature and
+// collects the edges between exported nodes. Output order is deterministic.
+func BuildExportGraph(g *Graph, opts ExportOptions) *ExportGraph {
+	prefix := strings.Trim(strings.ToLower(strings.TrimSpace(opts.Feature)), "/")
+	inScope := func(feature string) bool {
+		f := strings.ToLower(feature)
+		return prefix == "" || f == prefix || strings.HasPrefix(f, prefix+"/")
+	}
+
+	// Each implementation node belongs to one cluster: files to their deepest
+	// feature parent, symbols to their file's cluster, chunks to their symbol's.
+	clusterOf := make(map[string]string)
+	parentOf := make(map[string]string)
+	for _, e := range g.Edges {
+		from, to := g.GetNode(e.From), g.GetNode(e.To)
+		if from == nil || to == nil || e.Type != EdgeFeatureParent {
+			continue
+		}
+		if isHierarchyKind(to.Kind) {
+			parentOf[to.ID] = from.ID
+			continue
+		}
+		if current := g.GetNode(clusterOf[to.ID]); current == nil || deeperFeature(from, current) {
+			clusterOf[to.ID] = from.ID
+		}
+	}
+	resolveCluster := func(n *Node) string {
+		for depth := 0; depth < 3 && n != nil; depth++ {
+			if id, ok := clusterOf[n.ID]; ok {
+				return id
+			}
+			var container *Node
+			for _, e := range g.GetIncoming(n.ID) {
+				if e.Type == EdgeContains || e.Type == EdgeMapsToChunk {
+					container = g.GetNode(e.From)
+					break
+				}
+			}
+			n = container
+		}
+		return ""
+	}
+
+	out := &ExportGraph{Clusters: []ExportCluster{}, Nodes: []*Node{}, Edges: []*Edge{}}
+	clusters := make(map[string]*ExportCluster)
+	included := make(map[string]bool)
+
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		n := g.Nodes[id]
+		if isHierarchyKind(n.Kind) {
+			if inScope(n.Feature) {
+				clusters[id] = &ExportCluster{ID: id, Kind: n.Kind, Feature: n.Feature}
+			}
+		}
+	}
+	for _, id := range ids {
+		n := g.Nodes[id]
+		if isHierarchyKind(n.Kind) || (n.Kind == KindChunk && !opts.IncludeChunks) {
+			continue
+		}
+		clusterID := resolveCluster(n)
+		c := clusters[clusterID]
+		if c == nil && prefix != "" {
+			continue
+		}
+		included[id] = true
+		out.Nodes = append(out.Nodes, n)
+		if c != nil {
+			c.Members = append(c.Members, id)
+		}
+	}
+
+	for _, id := range ids {
+		c, ok := clusters[id]
+		if !ok {
+			continue
+		}
+		if _, ok := clusters[parentOf[id]]; ok {
+			c.Parent = parentOf[id]
+		}
+		out.Clusters = append(out.Clusters, *c)
+	}
+
+	for _, e := range g.Edges {
+		if e.Type == EdgeFeatureParent || !included[e.From] || !included[e.To] {
+			continue
+		}
+		out.Edges = append(out.Edges, e)
+	}
+	sort.SliceStable(out.Edges, func(i, j int) bool {
+		a, b := out.Edges[i], out.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+	return out
+}
+
+// Export writes the graph in the given format (dot, mermaid or json).
+func Export(w io.Writer, g *Graph, format string, opts ExportOptions) error {
+	eg := BuildExportGraph(g, opts)
+	switch strings.ToLower(format) {
+	case ExportDOT:
+		return writeDOT(w, eg)
+	case ExportMermaid:
+		return writeMermaid(w, eg)
+	case ExportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(eg)
+	default:
+		return fmt.Errorf("unsupported export format %q: must be dot, mermaid or json", format)
+	}
+}
+
+func isHierarchyKind(kind NodeKind) bool {
+	return kind == KindArea || kind == KindCategory || kind == KindSubcategory
+}
+
+func deeperFeature(a, b *Node) bool {
+	da, db := strings.Count(a.Feature, "/"), strings.Count(b.Feature, "/")
+	if da != db {
+		return da > db
+	}
+	return a.ID < b.ID
+}
+
+// exportLabel is the display label of an implementation node.
+func exportLabel(n *Node) string {
+	switch n.Kind {
+	case KindSymbol:
+		if n.Receiver != "" {
+			return n.Receiver + "." + n.SymbolName
+		}
+		return n.SymbolName
+	case KindFile:
+		return n.Path
+	case KindChunk:
+		return fmt.Sprintf("%s:%d-%d", n.Path, n.StartLine, n.EndLine)
+	}
+	return n.ID
+}
+
+// lastSegment returns the final element of a feature path.
+func lastSegment(feature string) string {
+	if idx := strings.LastIndex(feature, "/"); idx >= 0 {
+		return feature[idx+1:]
+	}
+	return feature
+}
+
+// childClusters indexes clusters by parent ID ("" for roots).
+func childClusters(eg *ExportGraph) map[string][]ExportCluster {
+	children := make(map[string][]ExportCluster)
+	for _, c := range eg.Clusters {
+		children[c.Parent] = append(children[c.Parent], c)
+	}
+	return children
+}
+
+func writeDOT(w io.Writer, eg *ExportGraph) error {
+	var b strings.Builder
+	b.WriteString("digraph rpg {\n")
+	b.WriteString("  compound=true;\n  rankdir=LR;\n  node [fontname=\"Helvetica\"];\n")
+
+	nodes := make(map[string]*Node, len(eg.Nodes))
+	clustered := make(map[string]bool)
+	for _, n := range eg.Nodes {
+		nodes[n.ID] = n
+	}
+	children := childClusters(eg)
+	counter := 0
+
+	var writeCluster func(c ExportCluster, indent string)
+	writeCluster = func(c ExportCluster, indent string) {
+		fmt.Fprintf(&b, "%ssubgraph cluster_%d {\n", indent, counter)
+		counter++
+		fmt.Fprintf(&b, "%s  label=%s;\n", indent, dotQuote(lastSegment(c.Feature)))
+		fmt.Fprintf(&b, "%s  tooltip=%s;\n", indent, dotQuote(c.Feature))
+		for _, child := range children[c.ID] {
+			writeCluster(child, indent+"  ")
+		}
+		for _, id := range c.Members {
+			clustered[id] = true
+			fmt.Fprintf(&b, "%s  %s;\n", indent, dotNode(nodes[id]))
+		}
+		fmt.Fprintf(&b, "%s}\n", indent)
+	}
+	for _, c := range children[""] {
+		writeCluster(c, "  ")
+	}
+	for _, n := range eg.Nodes {
+		if !clustered[n.ID] {
+			fmt.Fprintf(&b, "  %s;\n", dotNode(n))
+		}
+	}
+	for _, e := range eg.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(e.From), dotQuote(e.To), dotEdgeStyle(e.Type))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotNode(n *Node) string {
+	shape := "ellipse"
+	switch n.Kind {
+	case KindFile:
+		shape = "note"
+	case KindChunk:
+		shape = "point"
+	}
+	return fmt.Sprintf("%s [label=%s, shape=%s]", dotQuote(n.ID), dotQuote(exportLabel(n)), shape)
+}
+
+func dotEdgeStyle(t EdgeType) string {
+	switch t {
+	case EdgeContains, EdgeMapsToChunk:
+		return fmt.Sprintf("label=%q, style=dotted", t)
+	case EdgeSemanticSim:
+		return fmt.Sprintf("label=%q, style=dashed, dir=none", t)
+	default:
+		return fmt.Sprintf("label=%q", t)
+	}
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func writeMermaid(w io.Writer, eg *ExportGraph) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	// Mermaid IDs must be simple identifiers, so nodes and clusters are numbered.
+	nodeIDs := make(map[string]string, len(eg.Nodes))
+	nodes := make(map[string]*Node, len(eg.Nodes))
+	for i, n := range eg.Nodes {
+		nodeIDs[n.ID] = fmt.Sprintf("n%d", i)
+		nodes[n.ID] = n
+	}
+	clustered := make(map[string]bool)
+	children := childClusters(eg)
+	counter := 0
+
+	var writeCluster func(c ExportCluster, indent string)
+	writeCluster = func(c ExportCluster, indent string) {
+		fmt.Fprintf(&b, "%ssubgraph c%d[%s]\n", indent, counter, mermaidQuote(lastSegment(c.Feature)))
+		counter++
+		for _, child := range children[c.ID] {
+			writeCluster(child, indent+"  ")
+		}
+		for _, id := range c.Members {
+			clustered[id] = true
+			fmt.Fprintf(&b, "%s  %s\n", indent, mermaidNode(nodeIDs[id], nodes[id]))
+		}
+		fmt.Fprintf(&b, "%send\n", indent)
+	}
+	for _, c := range children[""] {
+		writeCluster(c, "  ")
+	}
+	for _, n := range eg.Nodes {
+		if !clustered[n.ID] {
+			fmt.Fprintf(&b, "  %s\n", mermaidNode(nodeIDs[n.ID], n))
+		}
+	}
+	for _, e := range eg.Edges {
+		arrow := "-->"
+		switch e.Type {
+		case EdgeContains, EdgeMapsToChunk:
+			arrow = "-.->"
+		case EdgeSemanticSim:
+			arrow = "-.-"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", nodeIDs[e.From], arrow, e.Type, nodeIDs[e.To])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidNode(id string, n *Node) string {
+	label := mermaidQuote(exportLabel(n))
+	switch n.Kind {
+	case KindFile:
+		return fmt.Sprintf("%s[%s]", id, label)
+	case KindChunk:
+		return fmt.Sprintf("%s((%s))", id, label)
+	default:
+		return fmt.Sprintf("%s(%s)", id, label)
+	}
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
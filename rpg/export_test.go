@@ -0,0 +1,143 @@
+package rpg
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func newExportTestGraph() *Graph {
+	g := NewGraph()
+	nodes := []*Node{
+		{ID: "area:auth", Kind: KindArea, Feature: "auth"},
+		{ID: "cat:auth/session", Kind: KindCategory, Feature: "auth/session"},
+		{ID: "area:billing", Kind: KindArea, Feature: "billing"},
+		{ID: "file:auth/login.go", Kind: KindFile, Path: "auth/login.go"},
+		{ID: "file:billing/invoice.go", Kind: KindFile, Path: "billing/invoice.go"},
+		{ID: "sym:auth/login.go:Login", Kind: KindSymbol, Path: "auth/login.go", SymbolName: "Login"},
+		{ID: "sym:billing/invoice.go:Charge", Kind: KindSymbol, Path: "billing/invoice.go", SymbolName: "Charge", Receiver: "Invoice"},
+		{ID: "chunk:auth/login.go:1", Kind: KindChunk, Path: "auth/login.go", StartLine: 1, EndLine: 20},
+	}
+	for _, n := range nodes {
+		g.AddNode(n)
+	}
+	edges := []*Edge{
+		{From: "area:auth", To: "cat:auth/session", Type: EdgeFeatureParent},
+		{From: "area:auth", To: "file:auth/login.go", Type: EdgeFeatureParent},
+		{From: "cat:auth/session", To: "file:auth/login.go", Type: EdgeFeatureParent},
+		{From: "area:billing", To: "file:billing/invoice.go", Type: EdgeFeatureParent},
+		{From: "file:auth/login.go", To: "sym:auth/login.go:Login", Type: EdgeContains},
+		{From: "file:billing/invoice.go", To: "sym:billing/invoice.go:Charge", Type: EdgeContains},
+		{From: "sym:billing/invoice.go:Charge", To: "sym:auth/login.go:Login", Type: EdgeInvokes},
+		{From: "sym:auth/login.go:Login", To: "chunk:auth/login.go:1", Type: EdgeMapsToChunk},
+	}
+	for _, e := range edges {
+		g.AddEdge(e)
+	}
+	return g
+}
+
+func TestBuildExportGraph(t *testing.T) {
+	eg := BuildExportGraph(newExportTestGraph(), ExportOptions{})
+
+	if len(eg.Clusters) != 3 {
+		t.Fatalf("expected 3 clusters, got %d", len(eg.Clusters))
+	}
+	clusters := make(map[string]ExportCluster)
+	for _, c := range eg.Clusters {
+		clusters[c.ID] = c
+	}
+	session := clusters["cat:auth/session"]
+	if session.Parent != "area:auth" {
+		t.Errorf("session parent = %q, want area:auth", session.Parent)
+	}
+	// The file goes to its deepest feature, and its symbol follows it.
+	if strings.Join(session.Members, ",") != "file:auth/login.go,sym:auth/login.go:Login" {
+		t.Errorf("session members = %v", session.Members)
+	}
+	if len(clusters["area:auth"].Members) != 0 {
+		t.Errorf("area:auth should have no direct members, got %v", clusters["area:auth"].Members)
+	}
+
+	for _, n := range eg.Nodes {
+		if n.Kind == KindChunk {
+			t.Errorf("chunk nodes should be excluded by default")
+		}
+	}
+	for _, e := range eg.Edges {
+		if e.Type == EdgeFeatureParent || e.Type == EdgeMapsToChunk {
+			t.Errorf("unexpected %s edge in export", e.Type)
+		}
+	}
+	if len(eg.Edges) != 3 {
+		t.Errorf("expected 3 edges, got %d", len(eg.Edges))
+	}
+
+	withChunks := BuildExportGraph(newExportTestGraph(), ExportOptions{IncludeChunks: true})
+	if len(withChunks.Nodes) != len(eg.Nodes)+1 || len(withChunks.Edges) != 4 {
+		t.Errorf("expected chunk node and maps_to_chunk edge with IncludeChunks")
+	}
+}
+
+func TestBuildExportGraph_FeatureFilter(t *testing.T) {
+	eg := BuildExportGraph(newExportTestGraph(), ExportOptions{Feature: "Auth"})
+
+	if len(eg.Clusters) != 2 {
+		t.Fatalf("expected 2 auth clusters, got %d", len(eg.Clusters))
+	}
+	if len(eg.Nodes) != 2 {
+		t.Fatalf("expected file and symbol in auth, got %d nodes", len(eg.Nodes))
+	}
+	// The invokes edge from billing crosses the filter and is dropped.
+	if len(eg.Edges) != 1 || eg.Edges[0].Type != EdgeContains {
+		t.Errorf("expected only the contains edge, got %v", eg.Edges)
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	g := newExportTestGraph()
+
+	var dot bytes.Buffer
+	if err := Export(&dot, g, ExportDOT, ExportOptions{}); err != nil {
+		t.Fatalf("dot export failed: %v", err)
+	}
+	for _, want := range []string{"digraph rpg {", "subgraph cluster_", `label="session"`, `"Invoice.Charge"`, `[label="invokes"]`} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("dot output missing %q:\n%s", want, dot.String())
+		}
+	}
+
+	var mermaid bytes.Buffer
+	if err := Export(&mermaid, g, ExportMermaid, ExportOptions{}); err != nil {
+		t.Fatalf("mermaid export failed: %v", err)
+	}
+	for _, want := range []string{"flowchart LR", `subgraph c0["auth"]`, `["auth/login.go"]`, "-->|invokes|"} {
+		if !strings.Contains(mermaid.String(), want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, mermaid.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := Export(&out, g, ExportJSON, ExportOptions{}); err != nil {
+		t.Fatalf("json export failed: %v", err)
+	}
+	var decoded ExportGraph
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(decoded.Clusters) != 3 || len(decoded.Edges) != 3 {
+		t.Errorf("unexpected json export: %d clusters, %d edges", len(decoded.Clusters), len(decoded.Edges))
+	}
+
+	// Exports are deterministic.
+	var again bytes.Buffer
+	_ = Export(&again, g, ExportDOT, ExportOptions{})
+	if again.String() != dot.String() {
+		t.Error("dot export is not deterministic")
+	}
+
+	if err := Export(&out, g, "svg", ExportOptions{}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
@@ -81,8 +81,36 @@ func (e *LLMExtractor) GenerateSummary(ctx context.Context, name, contextStr str
 	return e.callCompletion(ctx, systemPrompt, userPrompt)
 }
 
+// maxSummaryInput is how much of a file SummarizeFile sends to the LLM.
+const maxSummaryInput = 8000
+
+// SummarizeFile calls the LLM to describe in a sentence or two what the file
+// at path is for. Only the beginning of long files is sent.
+func (e *LLMExtractor) SummarizeFile(ctx context.Context, path, content string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+
+	if len(content) > maxSummaryInput {
+		content = strings.ToValidUTF8(content[:maxSummaryInput], "")
+	}
+	systemPrompt := "You are a code analysis assistant. Describe in one or two sentences the purpose of the provided file within its project, to help retrieve its parts in a search. Output ONLY the description."
+	userPrompt := fmt.Sprintf("File: %s\n\n%s", path, content)
+	return e.callCompletion(ctx, systemPrompt, userPrompt)
+}
+
+// Complete sends a chat completion with systemPrompt and userPrompt to the
+// LLM and returns its reply, at most maxTokens long. Unlike feature
+// extraction, it has no fallback.
+func (e *LLMExtractor) Complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int) (string, error) {
+	return e.complete(ctx, systemPrompt, userPrompt, maxTokens)
+}
+
 // callCompletion makes an OpenAI-compatible chat completion API call.
 func (e *LLMExtractor) callCompletion(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return e.complete(ctx, systemPrompt, userPrompt, 100) // Increased for summaries
+}
+
+func (e *LLMExtractor) complete(ctx context.Context, systemPrompt, userPrompt string, maxTokens int) (string, error) {
 	// Build request body (OpenAI chat completion format)
 	reqBody := map[string]any{
 		"model": e.cfg.Model,
@@ -90,7 +118,7 @@ func (e *LLMExtractor) callCompletion(ctx context.Context, systemPrompt, userPro
 			{"role": "system", "content": systemPrompt},
 			{"role": "user", "content": userPrompt},
 		},
-		"max_tokens":  100, // Increased for summaries
+		"max_tokens":  maxTokens,
 		"temperature": 0,
 	}
 
@@ -1,7 +1,12 @@
 package rpg
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -109,3 +114,63 @@ func TestParseAtomicFeatureResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestLLMExtractorSummarizeFile(t *testing.T) {
+	var got struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":" Serves the HTTP API. "}}]}`))
+	}))
+	defer server.Close()
+
+	e := NewLLMExtractor(LLMExtractorConfig{Model: "small", Endpoint: server.URL + "/v1/"})
+	content := strings.Repeat("x", maxSummaryInput+100)
+	summary, err := e.SummarizeFile(context.Background(), "api/server.go", content)
+	if err != nil {
+		t.Fatalf("SummarizeFile failed: %v", err)
+	}
+	if summary != "Serves the HTTP API." {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if got.Model != "small" || len(got.Messages) != 2 {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	user := got.Messages[1].Content
+	if !strings.HasPrefix(user, "File: api/server.go\n\n") || len(user) > maxSummaryInput+100 {
+		t.Errorf("expected the path and a truncated file in the prompt, got %d bytes", len(user))
+	}
+}
+
+func TestLLMExtractorComplete(t *testing.T) {
+	var got struct {
+		MaxTokens int `json:"max_tokens"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"It refreshes in auth.go:12."}}]}`))
+	}))
+	defer server.Close()
+
+	e := NewLLMExtractor(LLMExtractorConfig{Model: "large", Endpoint: server.URL})
+	answer, err := e.Complete(context.Background(), "system", "question", 1024)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if answer != "It refreshes in auth.go:12." || got.MaxTokens != 1024 {
+		t.Errorf("unexpected answer %q with max_tokens %d", answer, got.MaxTokens)
+	}
+}
@@ -455,6 +455,24 @@ func (qe *QueryEngine) getFeaturePathWithDepth(nodeID string, depth int) string
 	return ""
 }
 
+// FilesInFeature returns the paths of files whose feature path equals
+// featurePath or lies under it (e.g. "auth" matches "auth/session"). Matching
+// is case-insensitive and on whole path segments.
+func (qe *QueryEngine) FilesInFeature(featurePath string) map[string]bool {
+	prefix := strings.Trim(strings.ToLower(strings.TrimSpace(featurePath)), "/")
+	files := make(map[string]bool)
+	if prefix == "" {
+		return files
+	}
+	for _, n := range qe.graph.GetNodesByKind(KindFile) {
+		fp := strings.ToLower(qe.getFeaturePath(n.ID))
+		if fp == prefix || strings.HasPrefix(fp, prefix+"/") {
+			files[n.Path] = true
+		}
+	}
+	return files
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if strings.TrimSpace(v) != "" {
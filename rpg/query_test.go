@@ -913,3 +913,36 @@ func TestExplore_UsesStartCodeEntitiesAndEntityFilter(t *testing.T) {
 		t.Fatalf("expected symbol node to be included with function filter, got %+v", result.Nodes)
 	}
 }
+
+func TestFilesInFeature(t *testing.T) {
+	g := NewGraph()
+	session := &Node{ID: "cat:auth/session", Kind: KindCategory, Feature: "auth/session"}
+	sessionStore := &Node{ID: "sub:auth/session-store", Kind: KindSubcategory, Feature: "auth/session-store"}
+	billing := &Node{ID: "cat:billing/invoices", Kind: KindCategory, Feature: "billing/invoices"}
+	login := &Node{ID: "file:auth/login.go", Kind: KindFile, Path: "auth/login.go"}
+	cookies := &Node{ID: "file:auth/cookies.go", Kind: KindFile, Path: "auth/cookies.go"}
+	invoice := &Node{ID: "file:billing/invoice.go", Kind: KindFile, Path: "billing/invoice.go"}
+	for _, n := range []*Node{session, sessionStore, billing, login, cookies, invoice} {
+		g.AddNode(n)
+	}
+	g.AddEdge(&Edge{From: session.ID, To: login.ID, Type: EdgeFeatureParent})
+	g.AddEdge(&Edge{From: sessionStore.ID, To: cookies.ID, Type: EdgeFeatureParent})
+	g.AddEdge(&Edge{From: billing.ID, To: invoice.ID, Type: EdgeFeatureParent})
+
+	qe := NewQueryEngine(g)
+
+	files := qe.FilesInFeature("Auth")
+	if len(files) != 2 || !files["auth/login.go"] || !files["auth/cookies.go"] {
+		t.Fatalf("FilesInFeature(Auth) = %v", files)
+	}
+
+	// Matching is on whole segments: "auth/session" must not match "auth/session-store".
+	files = qe.FilesInFeature("auth/session/")
+	if len(files) != 1 || !files["auth/login.go"] {
+		t.Fatalf("FilesInFeature(auth/session) = %v", files)
+	}
+
+	if files := qe.FilesInFeature(""); len(files) != 0 {
+		t.Fatalf("expected no files for empty feature, got %v", files)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"github.com/yoanbernabeu/grepai/internal/fileutil"
+	"github.com/yoanbernabeu/grepai/internal/indexformat"
 )
 
 // GOBRPGStore implements RPGStore using GOB encoding.
@@ -18,6 +19,7 @@ type GOBRPGStore struct {
 	lockPath  string
 	graph     *Graph
 	mu        sync.RWMutex
+	tooNew    error // see indexformat.ErrTooNew
 }
 
 type gobRPGData struct {
@@ -73,6 +75,12 @@ func (s *GOBRPGStore) loadUnlocked() error {
 	if err := gob.NewDecoder(file).Decode(&data); err != nil {
 		return fmt.Errorf("failed to decode rpg index: %w", err)
 	}
+	// The graph is derived from the other indexes, so older versions are
+	// rebuilt rather than upgraded; newer ones must not be overwritten.
+	if err := indexformat.Check(s.indexPath, data.Version, CurrentRPGIndexVersion); err != nil {
+		s.tooNew = err
+		return err
+	}
 
 	if data.Version != CurrentRPGIndexVersion {
 		hasData := len(data.Nodes) > 0 || len(data.Edges) > 0
@@ -129,6 +137,9 @@ func (s *GOBRPGStore) Persist(ctx context.Context) error {
 }
 
 func (s *GOBRPGStore) persistUnlocked() error {
+	if s.tooNew != nil {
+		return s.tooNew
+	}
 	data := gobRPGData{
 		Version: CurrentRPGIndexVersion,
 		Nodes:   s.graph.Nodes,
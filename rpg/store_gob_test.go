@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/yoanbernabeu/grepai/internal/indexformat"
 )
 
 func TestGOBRPGStore_PersistLoad(t *testing.T) {
@@ -492,3 +494,47 @@ func TestGOBRPGStore_LoadLegacyEmptyVersionlessFile(t *testing.T) {
 		t.Fatalf("expected empty graph edges, got %d", len(store.GetGraph().Edges))
 	}
 }
+
+func TestGOBRPGStore_LoadFutureVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	indexPath := filepath.Join(tmpDir, "rpg.gob")
+
+	file, err := os.Create(indexPath)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	future := gobRPGData{
+		Version: CurrentRPGIndexVersion + 1,
+		Nodes:   map[string]*Node{"node1": {ID: "node1", Kind: KindFile, Path: "server.go"}},
+	}
+	if err := gob.NewEncoder(file).Encode(future); err != nil {
+		file.Close()
+		t.Fatalf("failed to encode future payload: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("failed to close test file: %v", err)
+	}
+	before, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+
+	store := NewGOBRPGStore(indexPath)
+	err = store.Load(context.Background())
+	if !errors.Is(err, indexformat.ErrTooNew) {
+		t.Fatalf("expected ErrTooNew, got %v", err)
+	}
+	if errors.Is(err, ErrRPGIndexOutdated) {
+		t.Fatal("a newer index must not be reported as outdated")
+	}
+	if err := store.Persist(context.Background()); !errors.Is(err, indexformat.ErrTooNew) {
+		t.Fatalf("expected Persist to refuse overwriting a newer index, got %v", err)
+	}
+	after, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Error("expected the newer index file to be left untouched")
+	}
+}
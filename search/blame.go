@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/git"
+)
+
+// Blames annotates search results with the last change to their lines, using
+// git blame of the project each result belongs to. A nil *Blames returns no
+// changes.
+type Blames struct {
+	project *git.Blamer
+
+	workspace string
+	projects  map[string]config.ProjectEntry
+	blamers   map[string]*git.Blamer
+}
+
+// NewProjectBlames returns blame lookups for a single project, cached in the
+// project's .grepai directory.
+func NewProjectBlames(projectRoot string) *Blames {
+	return &Blames{project: git.NewBlamer(projectRoot, config.GetBlameCachePath(projectRoot))}
+}
+
+// NewWorkspaceBlames returns blame lookups for workspace search results.
+func NewWorkspaceBlames(ws *config.Workspace) *Blames {
+	projects := make(map[string]config.ProjectEntry, len(ws.Projects))
+	for _, p := range ws.Projects {
+		projects[p.Name] = p
+	}
+	return &Blames{
+		workspace: ws.Name,
+		projects:  projects,
+		blamers:   make(map[string]*git.Blamer),
+	}
+}
+
+// LastChange returns the last committed change to a result's lines, or nil.
+func (b *Blames) LastChange(ctx context.Context, filePath string, startLine, endLine int) *git.LastChange {
+	if b == nil {
+		return nil
+	}
+	if b.projects == nil {
+		return b.project.LastChange(ctx, filePath, startLine, endLine)
+	}
+
+	name, relPath, ok := splitWorkspacePath(b.workspace, filePath)
+	if !ok {
+		return nil
+	}
+	blamer, loaded := b.blamers[name]
+	if !loaded {
+		if project, known := b.projects[name]; known {
+			// Projects without a .grepai directory are only cached in memory.
+			cachePath := ""
+			if info, err := os.Stat(config.GetConfigDir(project.Path)); err == nil && info.IsDir() {
+				cachePath = config.GetBlameCachePath(project.Path)
+			}
+			blamer = git.NewBlamer(project.Path, cachePath)
+		}
+		b.blamers[name] = blamer
+	}
+	return blamer.LastChange(ctx, relPath, startLine, endLine)
+}
+
+// Save persists the blame caches of all projects looked up.
+func (b *Blames) Save() error {
+	if b == nil {
+		return nil
+	}
+	if b.projects == nil {
+		return b.project.Save()
+	}
+	var errs []error
+	for _, blamer := range b.blamers {
+		if err := blamer.Save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
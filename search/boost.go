@@ -2,7 +2,6 @@ package search
 
 import (
 	"sort"
-	"strings"
 
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/store"
@@ -31,25 +30,5 @@ func ApplyBoost(results []store.SearchResult, boostCfg config.BoostConfig) []sto
 // computeBoostFactor calculates the combined boost factor for a file path.
 // Multiple matching rules are multiplied together.
 func computeBoostFactor(filePath string, boostCfg config.BoostConfig) float32 {
-	factor := float32(1.0)
-
-	for _, rule := range boostCfg.Penalties {
-		if matchesPattern(filePath, rule.Pattern) {
-			factor *= rule.Factor
-		}
-	}
-
-	for _, rule := range boostCfg.Bonuses {
-		if matchesPattern(filePath, rule.Pattern) {
-			factor *= rule.Factor
-		}
-	}
-
-	return factor
-}
-
-// matchesPattern checks if a file path contains the given pattern.
-// Patterns are simple substring matches (case-sensitive).
-func matchesPattern(filePath, pattern string) bool {
-	return strings.Contains(filePath, pattern)
+	return boostCfg.Factor(filePath)
 }
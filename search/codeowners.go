@@ -0,0 +1,73 @@
+package search
+
+import (
+	"log"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/git"
+)
+
+// CodeOwners resolves the CODEOWNERS owners of search results, using the
+// repository of the project each result belongs to. A nil *CodeOwners
+// returns no owners.
+type CodeOwners struct {
+	project *git.Codeowners
+
+	workspace string
+	projects  map[string]config.ProjectEntry
+	files     map[string]*git.Codeowners
+}
+
+// NewProjectCodeOwners returns owners for a single project, or nil when its
+// repository has no CODEOWNERS file.
+func NewProjectCodeOwners(projectRoot string) *CodeOwners {
+	owners, err := git.LoadCodeowners(projectRoot)
+	if err != nil {
+		log.Printf("Warning: failed to read CODEOWNERS: %v", err)
+	}
+	if owners == nil {
+		return nil
+	}
+	return &CodeOwners{project: owners}
+}
+
+// NewWorkspaceCodeOwners returns owners for workspace search results.
+func NewWorkspaceCodeOwners(ws *config.Workspace) *CodeOwners {
+	projects := make(map[string]config.ProjectEntry, len(ws.Projects))
+	for _, p := range ws.Projects {
+		projects[p.Name] = p
+	}
+	return &CodeOwners{
+		workspace: ws.Name,
+		projects:  projects,
+		files:     make(map[string]*git.Codeowners),
+	}
+}
+
+// Owners returns the owners of a result's file, space-separated as in the
+// CODEOWNERS file, or an empty string.
+func (c *CodeOwners) Owners(filePath string) string {
+	if c == nil {
+		return ""
+	}
+	if c.projects == nil {
+		return strings.Join(c.project.Owners(filePath), " ")
+	}
+
+	name, relPath, ok := splitWorkspacePath(c.workspace, filePath)
+	if !ok {
+		return ""
+	}
+	owners, loaded := c.files[name]
+	if !loaded {
+		if project, known := c.projects[name]; known {
+			var err error
+			if owners, err = git.LoadCodeowners(project.Path); err != nil {
+				log.Printf("Warning: failed to read CODEOWNERS of project %s: %v", name, err)
+			}
+		}
+		c.files[name] = owners
+	}
+	return strings.Join(owners.Owners(relPath), " ")
+}
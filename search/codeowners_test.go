@@ -0,0 +1,38 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestWorkspaceCodeOwners_UsesProjectCodeowners(t *testing.T) {
+	owned := t.TempDir()
+	if err := os.WriteFile(filepath.Join(owned, "CODEOWNERS"), []byte("*.go @org/backend @alice\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	unowned := t.TempDir()
+
+	owners := NewWorkspaceCodeOwners(&config.Workspace{
+		Name: "ws",
+		Projects: []config.ProjectEntry{
+			{Name: "api", Path: owned},
+			{Name: "web", Path: unowned},
+		},
+	})
+
+	if got := owners.Owners("ws/api/handlers/user.go"); got != "@org/backend @alice" {
+		t.Errorf("Owners() = %q", got)
+	}
+	for _, path := range []string{"ws/api/README.md", "ws/web/app.go", "other/api/a.go"} {
+		if got := owners.Owners(path); got != "" {
+			t.Errorf("Owners(%q) = %q, want empty", path, got)
+		}
+	}
+
+	if NewProjectCodeOwners(unowned) != nil {
+		t.Error("expected nil project owners without a CODEOWNERS file")
+	}
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"sort"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/coverage"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// ApplyCoverageBoost multiplies the score of each result by the uncovered
+// factor of boostCfg in proportion to the share of its lines with
+// statements that no test runs according to profile, and re-sorts the
+// results. Results without statements in the profile keep their score.
+func ApplyCoverageBoost(results []store.SearchResult, profile *coverage.Profile, boostCfg config.BoostConfig) []store.SearchResult {
+	if !boostCfg.Enabled || boostCfg.Uncovered == 0 || profile == nil || len(results) == 0 {
+		return results
+	}
+
+	for i := range results {
+		c := profile.Range(results[i].Chunk.FilePath, results[i].Chunk.StartLine, results[i].Chunk.EndLine)
+		if c == nil {
+			continue
+		}
+		uncovered := float32(c.Lines-c.Covered) / float32(c.Lines)
+		results[i].Score *= 1 + (boostCfg.Uncovered-1)*uncovered
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
+
+// UncoveredFilter returns a chunk filter keeping the chunks accepted by keep,
+// when set, whose lines have statements and none run by tests according to
+// profile. Chunks of files the profile does not know are dropped.
+func UncoveredFilter(profile *coverage.Profile, keep func(store.Chunk) bool) func(store.Chunk) bool {
+	return func(c store.Chunk) bool {
+		if keep != nil && !keep(c) {
+			return false
+		}
+		return profile.Uncovered(c.FilePath, c.StartLine, c.EndLine)
+	}
+}
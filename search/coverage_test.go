@@ -0,0 +1,74 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/coverage"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestUncoveredFilter(t *testing.T) {
+	profile, err := coverage.ParseLCOV(strings.NewReader("SF:app.js\nDA:2,1\nDA:3,1\nDA:8,0\nDA:9,0\nend_of_record\n"), "")
+	if err != nil {
+		t.Fatalf("failed to parse coverage: %v", err)
+	}
+
+	keep := UncoveredFilter(profile, nil)
+	for _, tc := range []struct {
+		chunk store.Chunk
+		want  bool
+	}{
+		{store.Chunk{FilePath: "app.js", StartLine: 1, EndLine: 4}, false},
+		{store.Chunk{FilePath: "app.js", StartLine: 7, EndLine: 10}, true},
+		{store.Chunk{FilePath: "app.js", StartLine: 3, EndLine: 8}, false},
+		{store.Chunk{FilePath: "app.js", StartLine: 4, EndLine: 6}, false},
+		{store.Chunk{FilePath: "other.js", StartLine: 1, EndLine: 10}, false},
+	} {
+		if got := keep(tc.chunk); got != tc.want {
+			t.Errorf("keep(%s:%d-%d) = %v, want %v", tc.chunk.FilePath, tc.chunk.StartLine, tc.chunk.EndLine, got, tc.want)
+		}
+	}
+
+	onlyOther := UncoveredFilter(profile, func(c store.Chunk) bool { return c.FilePath == "other.js" })
+	if onlyOther(store.Chunk{FilePath: "app.js", StartLine: 7, EndLine: 10}) {
+		t.Error("expected the wrapped filter to apply")
+	}
+}
+
+func TestApplyCoverageBoost(t *testing.T) {
+	profile, err := coverage.ParseLCOV(strings.NewReader("SF:app.js\nDA:2,1\nDA:3,1\nDA:8,0\nDA:9,0\nend_of_record\n"), "")
+	if err != nil {
+		t.Fatalf("failed to parse coverage: %v", err)
+	}
+	newResults := func() []store.SearchResult {
+		return []store.SearchResult{
+			{Chunk: store.Chunk{ID: "covered", FilePath: "app.js", StartLine: 1, EndLine: 4}, Score: 0.9},
+			{Chunk: store.Chunk{ID: "half", FilePath: "app.js", StartLine: 3, EndLine: 8}, Score: 0.8},
+			{Chunk: store.Chunk{ID: "uncovered", FilePath: "app.js", StartLine: 7, EndLine: 10}, Score: 0.7},
+			{Chunk: store.Chunk{ID: "unknown", FilePath: "other.js", StartLine: 1, EndLine: 10}, Score: 0.6},
+		}
+	}
+
+	results := ApplyCoverageBoost(newResults(), profile, config.BoostConfig{Enabled: true, Uncovered: 1.5})
+	want := map[string]float32{"uncovered": 1.05, "half": 1.0, "covered": 0.9, "unknown": 0.6}
+	for i, id := range []string{"uncovered", "half", "covered", "unknown"} {
+		if results[i].Chunk.ID != id || math.Abs(float64(results[i].Score-want[id])) > 1e-6 {
+			t.Errorf("result %d = %s (%.3f), want %s (%.3f)", i, results[i].Chunk.ID, results[i].Score, id, want[id])
+		}
+	}
+
+	for _, cfg := range []config.BoostConfig{
+		{Enabled: true},
+		{Enabled: false, Uncovered: 1.5},
+	} {
+		if results := ApplyCoverageBoost(newResults(), profile, cfg); results[0].Chunk.ID != "covered" || results[0].Score != 0.9 {
+			t.Errorf("expected %+v to leave scores alone, got %+v", cfg, results[0])
+		}
+	}
+	if results := ApplyCoverageBoost(newResults(), nil, config.BoostConfig{Enabled: true, Uncovered: 1.5}); results[0].Chunk.ID != "covered" {
+		t.Errorf("expected no profile to leave scores alone, got %+v", results[0])
+	}
+}
@@ -0,0 +1,83 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/stats"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// NewMissObserver returns an observer recording the searches of the project
+// at projectRoot whose confidence is below the threshold of cfg, as shown by
+// 'grepai insights'. It returns nil when insights are disabled.
+func NewMissObserver(projectRoot string, cfg config.InsightsConfig) ConfidenceObserver {
+	rec := stats.NewInsightsRecorder(projectRoot, cfg.Enabled)
+	if rec == nil {
+		return nil
+	}
+	minScore := cfg.GetMinScore()
+	return func(query string, confidence float32, results []store.SearchResult) {
+		if float64(confidence) >= minScore {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_ = rec.RecordMiss(ctx, query, float64(confidence), resultFiles(results))
+	}
+}
+
+// NewWorkspaceMissObserver returns an observer recording the searches of ws
+// in each of its projects that enables insights, below the project's
+// threshold, with the results in the project. The follow-up edits recorded
+// by the project's watcher are then linked to them. It returns nil when no
+// project enables insights.
+func NewWorkspaceMissObserver(ws *config.Workspace) ConfidenceObserver {
+	type projectObserver struct {
+		prefix  string
+		observe ConfidenceObserver
+	}
+	var observers []projectObserver
+	for _, p := range ws.Projects {
+		if !config.Exists(p.Path) {
+			continue
+		}
+		cfg, err := config.Load(p.Path)
+		if err != nil {
+			continue
+		}
+		if observe := NewMissObserver(p.Path, cfg.Insights); observe != nil {
+			observers = append(observers, projectObserver{prefix: ws.Name + "/" + p.Name + "/", observe: observe})
+		}
+	}
+	if len(observers) == 0 {
+		return nil
+	}
+	return func(query string, confidence float32, results []store.SearchResult) {
+		for _, o := range observers {
+			var own []store.SearchResult
+			for _, r := range results {
+				if rel, ok := strings.CutPrefix(r.Chunk.FilePath, o.prefix); ok {
+					r.Chunk.FilePath = rel
+					own = append(own, r)
+				}
+			}
+			o.observe(query, confidence, own)
+		}
+	}
+}
+
+// resultFiles returns the files of results, best first, without duplicates.
+func resultFiles(results []store.SearchResult) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, r := range results {
+		if !seen[r.Chunk.FilePath] {
+			seen[r.Chunk.FilePath] = true
+			files = append(files, r.Chunk.FilePath)
+		}
+	}
+	return files
+}
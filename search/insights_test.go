@@ -0,0 +1,101 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/stats"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestSearcherConfidenceObserver(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "a.go_0", FilePath: "a.go", Vector: []float32{0.6, 0.8}},
+		{ID: "b.go_0", FilePath: "b.go", Vector: []float32{0, 1}},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	for _, hybrid := range []bool{false, true} {
+		searcher := NewSearcher(st, fixedEmbedder{}, config.SearchConfig{Hybrid: config.HybridConfig{Enabled: hybrid, K: 60}})
+		var got float32
+		calls := 0
+		searcher.SetConfidenceObserver(func(query string, confidence float32, results []store.SearchResult) {
+			calls++
+			got = confidence
+		})
+		if _, err := searcher.Search(ctx, "run", 2, ""); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		// The confidence is the similarity of the nearest chunk, also when
+		// hybrid search ranks results by RRF.
+		if calls != 1 || got < 0.59 || got > 0.61 {
+			t.Errorf("hybrid=%v: expected one observation with confidence 0.6, got %d with %v", hybrid, calls, got)
+		}
+	}
+}
+
+func TestNewMissObserver(t *testing.T) {
+	root := t.TempDir()
+	if NewMissObserver(root, config.InsightsConfig{}) != nil {
+		t.Fatal("expected no observer when insights are disabled")
+	}
+
+	observe := NewMissObserver(root, config.InsightsConfig{Enabled: true, MinScore: 0.7})
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "a.go"}},
+		{Chunk: store.Chunk{FilePath: "b.go"}},
+		{Chunk: store.Chunk{FilePath: "a.go"}},
+	}
+	observe("confident", 0.9, results)
+	observe("unsure", 0.4, results)
+
+	signals, err := stats.ReadSignals(stats.InsightsPath(root))
+	if err != nil {
+		t.Fatalf("ReadSignals failed: %v", err)
+	}
+	if len(signals) != 1 {
+		t.Fatalf("expected only the low-confidence search to be recorded, got %+v", signals)
+	}
+	s := signals[0]
+	if s.Type != stats.SignalMiss || s.Query != "unsure" || len(s.Results) != 2 || s.Results[0] != "a.go" || s.Results[1] != "b.go" {
+		t.Errorf("unexpected miss %+v", s)
+	}
+}
+
+func TestNewWorkspaceMissObserver(t *testing.T) {
+	api, web := t.TempDir(), t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Insights.Enabled = true
+	if err := cfg.Save(api); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	ws := &config.Workspace{Name: "ws", Projects: []config.ProjectEntry{
+		{Name: "api", Path: api},
+		{Name: "web", Path: web},
+	}}
+
+	observe := NewWorkspaceMissObserver(ws)
+	if observe == nil {
+		t.Fatal("expected an observer when a project enables insights")
+	}
+	observe("unsure", 0.2, []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "ws/web/app.ts"}},
+		{Chunk: store.Chunk{FilePath: "ws/api/limit.go"}},
+	})
+
+	signals, err := stats.ReadSignals(stats.InsightsPath(api))
+	if err != nil {
+		t.Fatalf("ReadSignals failed: %v", err)
+	}
+	if len(signals) != 1 || len(signals[0].Results) != 1 || signals[0].Results[0] != "limit.go" {
+		t.Errorf("expected the miss recorded with the project's own results, got %+v", signals)
+	}
+	if signals, _ := stats.ReadSignals(stats.InsightsPath(web)); len(signals) != 0 {
+		t.Errorf("expected nothing recorded for a project without insights, got %+v", signals)
+	}
+}
@@ -0,0 +1,99 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// SetProjectNormalization normalizes the scores of workspace results per
+// project with method, config.ScoreNormalizationZScore or
+// config.ScoreNormalizationMinMax. SearchWorkspace then retrieves each
+// project on its own, so every project is normalized over its full fetch
+// window rather than over the few results it placed in a shared top-K. An
+// empty method or "none" keeps raw scores.
+func (s *Searcher) SetProjectNormalization(workspace, method string) error {
+	switch method {
+	case "", config.ScoreNormalizationNone:
+		s.normalize = nil
+	case config.ScoreNormalizationZScore, config.ScoreNormalizationMinMax:
+		s.normalize = func(results []store.SearchResult) []store.SearchResult {
+			return NormalizeProjectScores(workspace, results, method)
+		}
+	default:
+		return fmt.Errorf("unknown score normalization %q: must be none, zscore or minmax", method)
+	}
+	return nil
+}
+
+// NormalizeProjectScores rescales the scores of workspace results within
+// each project, so projects whose content scores high against every query
+// don't crowd out the others, and sorts the results by the new scores.
+//
+// minmax maps each project's scores to [0, 1]. zscore standardizes them and
+// maps the z-scores to (0, 1) with the logistic function, so scores stay
+// positive. A project whose results all score the same has nothing to
+// rescale and keeps its raw scores. Equal normalized scores, such as the
+// best result of every project under minmax, are ordered by raw score.
+// Results outside the workspace are grouped together.
+func NormalizeProjectScores(workspace string, results []store.SearchResult, method string) []store.SearchResult {
+	raw := make([]float32, len(results))
+	byProject := make(map[string][]int)
+	for i, r := range results {
+		raw[i] = r.Score
+		project, _, _ := splitWorkspacePath(workspace, r.Chunk.FilePath)
+		byProject[project] = append(byProject[project], i)
+	}
+
+	for _, indexes := range byProject {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		var sum float64
+		for _, i := range indexes {
+			score := float64(results[i].Score)
+			lo, hi = math.Min(lo, score), math.Max(hi, score)
+			sum += score
+		}
+		mean := sum / float64(len(indexes))
+		var variance float64
+		for _, i := range indexes {
+			d := float64(results[i].Score) - mean
+			variance += d * d
+		}
+		std := math.Sqrt(variance / float64(len(indexes)))
+
+		if hi == lo {
+			continue
+		}
+		for _, i := range indexes {
+			score := float64(results[i].Score)
+			var normalized float64
+			if method == config.ScoreNormalizationMinMax {
+				normalized = (score - lo) / (hi - lo)
+			} else {
+				normalized = 1 / (1 + math.Exp(-(score-mean)/std))
+			}
+			results[i].Score = float32(normalized)
+		}
+	}
+
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return raw[i] > raw[j]
+	})
+	sorted := make([]store.SearchResult, len(results))
+	for k, i := range order {
+		sorted[k] = results[i]
+	}
+	copy(results, sorted)
+	return results
+}
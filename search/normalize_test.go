@@ -0,0 +1,74 @@
+package search
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func normalizeFixture() []store.SearchResult {
+	// The docs project scores high against everything; its best result is
+	// no better within docs than the api project's best is within api.
+	return []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "ws/docs/a.md"}, Score: 0.92},
+		{Chunk: store.Chunk{FilePath: "ws/docs/b.md"}, Score: 0.90},
+		{Chunk: store.Chunk{FilePath: "ws/docs/c.md"}, Score: 0.88},
+		{Chunk: store.Chunk{FilePath: "ws/api/x.go"}, Score: 0.70},
+		{Chunk: store.Chunk{FilePath: "ws/api/y.go"}, Score: 0.50},
+		{Chunk: store.Chunk{FilePath: "ws/web/z.ts"}, Score: 0.60},
+	}
+}
+
+func TestNormalizeProjectScores_MinMax(t *testing.T) {
+	results := NormalizeProjectScores("ws", normalizeFixture(), config.ScoreNormalizationMinMax)
+
+	scores := make(map[string]float32)
+	for _, r := range results {
+		scores[r.Chunk.FilePath] = r.Score
+	}
+	for path, want := range map[string]float32{
+		"ws/docs/a.md": 1, "ws/docs/b.md": 0.5, "ws/docs/c.md": 0,
+		"ws/api/x.go": 1, "ws/api/y.go": 0,
+		"ws/web/z.ts": 0.6,
+	} {
+		if math.Abs(float64(scores[path]-want)) > 1e-5 {
+			t.Errorf("%s: expected %v, got %v", path, want, scores[path])
+		}
+	}
+	if results[0].Chunk.FilePath != "ws/docs/a.md" || results[1].Chunk.FilePath != "ws/api/x.go" {
+		t.Errorf("expected the tied project bests ordered by raw score, got %+v", results)
+	}
+	if results[2].Chunk.FilePath != "ws/web/z.ts" {
+		t.Errorf("expected the single web result to keep its raw score, got %+v", results)
+	}
+}
+
+func TestNormalizeProjectScores_ZScore(t *testing.T) {
+	results := NormalizeProjectScores("ws", normalizeFixture(), config.ScoreNormalizationZScore)
+	for i, r := range results {
+		if r.Score <= 0 || r.Score >= 1 {
+			t.Errorf("expected scores in (0, 1), got %v for %s", r.Score, r.Chunk.FilePath)
+		}
+		if i > 0 && r.Score > results[i-1].Score {
+			t.Errorf("expected results sorted by score, got %+v", results)
+		}
+	}
+	if results[1].Chunk.FilePath != "ws/api/x.go" || results[2].Chunk.FilePath != "ws/web/z.ts" {
+		t.Errorf("expected the api and web results ahead of the other docs results, got %+v", results)
+	}
+}
+
+func TestSetProjectNormalization(t *testing.T) {
+	s := &Searcher{}
+	if err := s.SetProjectNormalization("ws", "rank"); err == nil {
+		t.Error("expected an error for an unknown normalization")
+	}
+	if err := s.SetProjectNormalization("ws", config.ScoreNormalizationZScore); err != nil || s.normalize == nil {
+		t.Errorf("expected zscore to be set, got %v", err)
+	}
+	if err := s.SetProjectNormalization("ws", config.ScoreNormalizationNone); err != nil || s.normalize != nil {
+		t.Errorf("expected none to clear normalization, got %v", err)
+	}
+}
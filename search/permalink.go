@@ -0,0 +1,79 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/git"
+)
+
+// Permalinks links search results to hosted code using the
+// repository.url_template of the project each result belongs to. A nil
+// *Permalinks returns no links.
+type Permalinks struct {
+	project *git.Permalinker
+
+	// Workspace results are stored as workspaceName/projectName/relativePath
+	// and linked with each project's own template, resolved on first use.
+	workspace string
+	projects  map[string]config.ProjectEntry
+	linkers   map[string]*git.Permalinker
+}
+
+// NewProjectPermalinks returns permalinks for a single project, or nil when
+// the project has no URL template.
+func NewProjectPermalinks(projectRoot string, cfg *config.Config) *Permalinks {
+	if cfg == nil || cfg.Repository.URLTemplate == "" {
+		return nil
+	}
+	return &Permalinks{project: git.NewPermalinker(cfg.Repository.URLTemplate, projectRoot)}
+}
+
+// NewWorkspacePermalinks returns permalinks for workspace search results.
+func NewWorkspacePermalinks(ws *config.Workspace) *Permalinks {
+	projects := make(map[string]config.ProjectEntry, len(ws.Projects))
+	for _, p := range ws.Projects {
+		projects[p.Name] = p
+	}
+	return &Permalinks{
+		workspace: ws.Name,
+		projects:  projects,
+		linkers:   make(map[string]*git.Permalinker),
+	}
+}
+
+// Link returns the permalink for a result, or an empty string.
+func (p *Permalinks) Link(filePath string, startLine, endLine int) string {
+	if p == nil {
+		return ""
+	}
+	if p.projects == nil {
+		return p.project.Link(filePath, startLine, endLine)
+	}
+
+	name, relPath, ok := splitWorkspacePath(p.workspace, filePath)
+	if !ok {
+		return ""
+	}
+	linker, loaded := p.linkers[name]
+	if !loaded {
+		if project, known := p.projects[name]; known {
+			if cfg, err := config.Load(project.Path); err == nil && cfg.Repository.URLTemplate != "" {
+				linker = git.NewPermalinker(cfg.Repository.URLTemplate, project.Path)
+			}
+		}
+		p.linkers[name] = linker
+	}
+	return linker.Link(relPath, startLine, endLine)
+}
+
+// splitWorkspacePath splits a workspace result path,
+// workspaceName/projectName/relativePath, into its project and the path
+// within the project.
+func splitWorkspacePath(workspace, filePath string) (project, relPath string, ok bool) {
+	rest, ok := strings.CutPrefix(filePath, workspace+"/")
+	if !ok {
+		return "", "", false
+	}
+	return strings.Cut(rest, "/")
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestWorkspacePermalinks_UsesProjectTemplate(t *testing.T) {
+	linked := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Repository.URLTemplate = "https://code.example.com/api/{path}#{start_line}"
+	if err := cfg.Save(linked); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	unlinked := t.TempDir()
+	if err := config.DefaultConfig().Save(unlinked); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	links := NewWorkspacePermalinks(&config.Workspace{
+		Name: "ws",
+		Projects: []config.ProjectEntry{
+			{Name: "api", Path: linked},
+			{Name: "web", Path: unlinked},
+		},
+	})
+
+	if got := links.Link("ws/api/handlers/user.go", 12, 30); got != "https://code.example.com/api/handlers/user.go#12" {
+		t.Errorf("Link() = %q", got)
+	}
+	for _, path := range []string{"ws/web/app.ts", "ws/missing/a.go", "other/api/a.go"} {
+		if got := links.Link(path, 1, 2); got != "" {
+			t.Errorf("Link(%q) = %q, want empty", path, got)
+		}
+	}
+
+	if NewProjectPermalinks(unlinked, config.DefaultConfig()) != nil {
+		t.Error("expected nil project permalinks without a template")
+	}
+}
@@ -0,0 +1,357 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// Query fields filtering the chunks a search returns.
+const (
+	FieldLang   = "lang"
+	FieldPath   = "path"
+	FieldSymbol = "symbol"
+)
+
+// QueryFields lists the fields of the query syntax.
+var QueryFields = []string{FieldLang, FieldPath, FieldSymbol}
+
+var identifierRe = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// FieldFilter is a field:value term of a query. A negated term, written
+// -field:value, excludes the chunks it matches.
+type FieldFilter struct {
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Negate bool   `json:"negate,omitempty"`
+}
+
+// Query is a search query split into its semantic text and field filters.
+type Query struct {
+	Text    string        `json:"text"`
+	Filters []FieldFilter `json:"filters,omitempty"`
+}
+
+// ParseQuery parses the query syntax shared by every search:
+//
+//	lang:go path:internal/ symbol:Handle* "retry budget"
+//
+// Terms of the form field:value, with field one of QueryFields, filter
+// chunks; every other term, and text in double quotes, is the semantic part
+// of the query. Paths are relative to the project root, or to the root of
+// each project in a workspace. Values may be quoted too, as in path:"my dir/". Terms with
+// an unknown field, such as "std::vector" or "http://host", are text.
+func ParseQuery(raw string) Query {
+	var q Query
+	var text []string
+	for _, term := range splitQueryTerms(raw) {
+		if term.quoted {
+			text = append(text, term.value)
+			continue
+		}
+		if f, ok := parseFieldTerm(term.value); ok {
+			q.Filters = append(q.Filters, f)
+			continue
+		}
+		text = append(text, term.value)
+	}
+	q.Text = strings.Join(text, " ")
+	return q
+}
+
+// parseFieldTerm parses a [-]field:value term.
+func parseFieldTerm(term string) (FieldFilter, bool) {
+	negate := strings.HasPrefix(term, "-")
+	field, value, ok := strings.Cut(strings.TrimPrefix(term, "-"), ":")
+	if !ok || value == "" {
+		return FieldFilter{}, false
+	}
+	field = strings.ToLower(field)
+	for _, known := range QueryFields {
+		if field == known {
+			return FieldFilter{Field: field, Value: strings.Trim(value, `"`), Negate: negate}, true
+		}
+	}
+	return FieldFilter{}, false
+}
+
+type queryTerm struct {
+	value  string
+	quoted bool
+}
+
+// splitQueryTerms splits a query on whitespace outside double quotes. A
+// term made of quoted text only is marked quoted; quotes inside a term,
+// as in path:"my dir/", are kept for parseFieldTerm.
+func splitQueryTerms(raw string) []queryTerm {
+	var terms []queryTerm
+	var b strings.Builder
+	inQuotes, quotedOnly := false, false
+	flush := func() {
+		if b.Len() > 0 {
+			value := b.String()
+			if quotedOnly {
+				value = strings.Trim(value, `"`)
+			}
+			if value != "" {
+				terms = append(terms, queryTerm{value: value, quoted: quotedOnly})
+			}
+		}
+		b.Reset()
+		quotedOnly = false
+	}
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			if !inQuotes && b.Len() == 0 {
+				quotedOnly = true
+			}
+			inQuotes = !inQuotes
+			b.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	return terms
+}
+
+// Validate checks a query with filters also has text to search for.
+func (q Query) Validate() error {
+	if len(q.Filters) > 0 && strings.TrimSpace(q.Text) == "" {
+		return fmt.Errorf("query has no search text besides its %s filters", strings.Join(QueryFields, ", "))
+	}
+	return nil
+}
+
+// Keep returns a chunk filter applying the query's field filters, or nil
+// when it has none. Terms of one field match if any of them does; every
+// field must match, and no negated term. Path terms match from the start of
+// the file path and symbol terms match identifiers in the chunk text; a
+// Searcher matches them against its index instead.
+func (q Query) Keep() func(store.Chunk) bool {
+	return q.keep(fieldMatcher{})
+}
+
+// fieldMatcher matches field terms against an index.
+type fieldMatcher struct {
+	// workspace, when set, makes path terms relative to the root of each
+	// project of that workspace.
+	workspace string
+	// definitions maps the value of a symbol term to the symbols it names,
+	// by file path. Terms missing from it match identifiers in the chunk
+	// text.
+	definitions map[string]map[string][]trace.Symbol
+}
+
+func (q Query) keep(m fieldMatcher) func(store.Chunk) bool {
+	if len(q.Filters) == 0 {
+		return nil
+	}
+	return func(c store.Chunk) bool {
+		matched := make(map[string]bool)
+		wanted := make(map[string]bool)
+		for _, f := range q.Filters {
+			ok := m.matches(f, c)
+			if f.Negate {
+				if ok {
+					return false
+				}
+				continue
+			}
+			wanted[f.Field] = true
+			matched[f.Field] = matched[f.Field] || ok
+		}
+		for field := range wanted {
+			if !matched[field] {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func (m fieldMatcher) matches(f FieldFilter, c store.Chunk) bool {
+	switch f.Field {
+	case FieldLang:
+		return chunkLanguageIs(c.FilePath, f.Value)
+	case FieldPath:
+		filePath := filepath.ToSlash(c.FilePath)
+		if m.workspace != "" {
+			if _, rel, ok := splitWorkspacePath(m.workspace, filePath); ok {
+				filePath = rel
+			}
+		}
+		return strings.HasPrefix(filePath, cleanPathTerm(f.Value))
+	case FieldSymbol:
+		if defs, ok := m.definitions[f.Value]; ok {
+			for _, sym := range defs[c.FilePath] {
+				if sym.Line <= c.EndLine && max(sym.Line, sym.EndLine) >= c.StartLine {
+					return true
+				}
+			}
+			return false
+		}
+		for _, id := range identifierRe.FindAllString(c.Content, -1) {
+			if symbolMatches(f.Value, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// symbolMatches reports whether name matches a symbol term, a glob pattern
+// such as "Handle*".
+func symbolMatches(pattern, name string) bool {
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// pathPrefix returns the prefix of the query's path term, which a store can
+// apply before ranking, or "" unless the query has exactly one path term and
+// it is not negated.
+func (q Query) pathPrefix() string {
+	var prefix string
+	for _, f := range q.Filters {
+		if f.Field != FieldPath {
+			continue
+		}
+		if f.Negate || prefix != "" {
+			return ""
+		}
+		prefix = cleanPathTerm(f.Value)
+	}
+	return prefix
+}
+
+// cleanPathTerm returns the value of a path term as a slash-separated path
+// relative to the project root.
+func cleanPathTerm(value string) string {
+	return strings.TrimLeft(strings.TrimPrefix(filepath.ToSlash(value), "./"), "/")
+}
+
+// chunkLanguageIs reports whether a file is in lang, given by name, such as
+// "python", or by extension, with or without the dot, such as "py".
+func chunkLanguageIs(filePath, lang string) bool {
+	lang = strings.ToLower(lang)
+	ext := langdetect.NameExt(filePath)
+	if ext == "" {
+		ext = strings.ToLower(filepath.Ext(filePath))
+	}
+	return langdetect.Language(ext) == lang || ext == "."+strings.TrimPrefix(lang, ".")
+}
+
+// queryFilter returns the chunk filter of q combined with the searcher's
+// own. Symbol terms are resolved through the symbol index of the searched
+// project, or of each workspace project, and match the chunks overlapping
+// the definitions they name; without a symbol index they match identifiers
+// in the chunk text.
+func (s *Searcher) queryFilter(ctx context.Context, q Query) (func(store.Chunk) bool, error) {
+	var m fieldMatcher
+	if s.workspace != nil {
+		m.workspace = s.workspace.Name
+	}
+	var sources []symbolSource
+	loaded := false
+	for _, f := range q.Filters {
+		if f.Field != FieldSymbol {
+			continue
+		}
+		if !loaded {
+			var err error
+			if sources, err = s.symbolSources(ctx); err != nil {
+				return nil, err
+			}
+			loaded = true
+		}
+		if len(sources) == 0 {
+			break
+		}
+		if _, ok := m.definitions[f.Value]; ok {
+			continue
+		}
+		defs, err := resolveSymbolTerm(ctx, sources, f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve symbol:%s: %w", f.Value, err)
+		}
+		if m.definitions == nil {
+			m.definitions = make(map[string]map[string][]trace.Symbol)
+		}
+		m.definitions[f.Value] = defs
+	}
+	return AndChunkFilters(s.filter, q.keep(m)), nil
+}
+
+// symbolSource is a symbol index and the prefix its file paths take in the
+// paths of the searched chunks.
+type symbolSource struct {
+	store  trace.SymbolStore
+	prefix string
+}
+
+// symbolSources returns the symbol index set with SetSymbolStore or, in a
+// workspace, the symbol index of each project that has one.
+func (s *Searcher) symbolSources(ctx context.Context) ([]symbolSource, error) {
+	if s.symbols != nil {
+		return []symbolSource{{store: s.symbols}}, nil
+	}
+	if s.workspace == nil {
+		return nil, nil
+	}
+	var sources []symbolSource
+	for _, p := range s.workspace.Projects {
+		indexPath := config.GetSymbolIndexPath(p.Path)
+		if _, err := os.Stat(indexPath); err != nil {
+			continue
+		}
+		// The index is only read, so it is not closed: closing persists it.
+		ss := trace.NewGOBSymbolStore(indexPath)
+		if err := ss.Load(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load symbol index for project %s: %w", p.Name, err)
+		}
+		sources = append(sources, symbolSource{store: ss, prefix: s.workspace.Name + "/" + p.Name + "/"})
+	}
+	return sources, nil
+}
+
+// resolveSymbolTerm returns the symbols a symbol term names, by chunk file
+// path. Patterns match symbol names, or Receiver.Name for methods.
+func resolveSymbolTerm(ctx context.Context, sources []symbolSource, pattern string) (map[string][]trace.Symbol, error) {
+	defs := make(map[string][]trace.Symbol)
+	for _, src := range sources {
+		var symbols []trace.Symbol
+		var err error
+		if strings.ContainsAny(pattern, `*?[\.`) {
+			var all []trace.Symbol
+			all, err = src.store.ListSymbols(ctx)
+			for _, sym := range all {
+				if symbolMatches(pattern, sym.Name) || (sym.Receiver != "" && symbolMatches(pattern, sym.Receiver+"."+sym.Name)) {
+					symbols = append(symbols, sym)
+				}
+			}
+		} else {
+			symbols, err = src.store.LookupSymbol(ctx, pattern)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, sym := range symbols {
+			file := src.prefix + filepath.ToSlash(sym.File)
+			defs[file] = append(defs[file], sym)
+		}
+	}
+	return defs, nil
+}
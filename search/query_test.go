@@ -0,0 +1,187 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Query
+	}{
+		{
+			raw: `lang:go path:internal/ symbol:Handle* "retry budget"`,
+			want: Query{Text: "retry budget", Filters: []FieldFilter{
+				{Field: FieldLang, Value: "go"},
+				{Field: FieldPath, Value: "internal/"},
+				{Field: FieldSymbol, Value: "Handle*"},
+			}},
+		},
+		{
+			raw: `rate limiting -path:vendor/ PATH:"my dir/"`,
+			want: Query{Text: "rate limiting", Filters: []FieldFilter{
+				{Field: FieldPath, Value: "vendor/", Negate: true},
+				{Field: FieldPath, Value: "my dir/"},
+			}},
+		},
+		{
+			raw:  `std::vector resize "lang:go" http://host path:`,
+			want: Query{Text: "std::vector resize lang:go http://host path:"},
+		},
+	}
+	for _, tt := range tests {
+		if got := ParseQuery(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+
+	if err := ParseQuery("lang:go path:cmd/").Validate(); err == nil {
+		t.Error("expected an error for a query with filters only")
+	}
+	if err := ParseQuery("").Validate(); err != nil {
+		t.Errorf("expected an empty query without filters to be valid, got %v", err)
+	}
+}
+
+func TestQueryKeep(t *testing.T) {
+	keep := ParseQuery(`lang:go lang:py path:internal/ -path:internal/legacy symbol:Handle* retry`).Keep()
+	for _, tt := range []struct {
+		chunk store.Chunk
+		want  bool
+	}{
+		{store.Chunk{FilePath: "internal/api/server.go", Content: "func HandleRetry() {}"}, true},
+		{store.Chunk{FilePath: "internal/worker.py", Content: "def HandleJob(): pass"}, true},
+		{store.Chunk{FilePath: "internal/api/server.ts", Content: "function HandleRetry() {}"}, false},
+		{store.Chunk{FilePath: "cmd/internal/main.go", Content: "func HandleRetry() {}"}, false},
+		{store.Chunk{FilePath: "internal/legacy/old.go", Content: "func HandleRetry() {}"}, false},
+		{store.Chunk{FilePath: "internal/api/client.go", Content: "func Retry() {}"}, false},
+	} {
+		if got := keep(tt.chunk); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.chunk.FilePath, tt.want, got)
+		}
+	}
+
+	if ParseQuery("retry budget").Keep() != nil {
+		t.Error("expected no filter for a query without field terms")
+	}
+
+	for raw, want := range map[string]string{
+		"path:./internal/ retry":                "internal/",
+		"path:internal/ -path:internal/x retry": "",
+		"path:internal/ path:cmd/ retry":        "",
+		"lang:go retry":                         "",
+	} {
+		if got := ParseQuery(raw).pathPrefix(); got != want {
+			t.Errorf("pathPrefix(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestSearcherQueryFilters(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "api/handler.go_0", FilePath: "api/handler.go", Content: "func HandleLogin() {}", Vector: []float32{1, 0}},
+		{ID: "web/login.ts_0", FilePath: "web/login.ts", Content: "function HandleLogin() {}", Vector: []float32{1, 0}},
+		{ID: "api/session.go_0", FilePath: "api/session.go", Content: "func newSession() {}", Vector: []float32{0.9, 0.1}},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	for _, path := range []string{"api/handler.go", "web/login.ts", "api/session.go"} {
+		if err := st.SaveDocument(ctx, store.Document{Path: path, ChunkIDs: []string{path + "_0"}}); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+	}
+	searcher := NewSearcher(st, fixedEmbedder{}, config.SearchConfig{})
+
+	results, err := searcher.Search(ctx, "lang:go symbol:Handle* login", 5, "")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ID != "api/handler.go_0" {
+		t.Errorf("expected only the Go handler, got %+v", results)
+	}
+
+	results, err = searcher.Refine(ctx, "-path:api/ login", []string{"api/handler.go_0", "web/login.ts_0", "api/session.go_0"}, 5, "")
+	if err != nil {
+		t.Fatalf("Refine failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ID != "web/login.ts_0" {
+		t.Errorf("expected only the web chunk, got %+v", results)
+	}
+
+	if _, err := searcher.Search(ctx, "lang:go", 5, ""); err == nil {
+		t.Error("expected an error for a query without text")
+	}
+}
+
+func TestSearcherQueryFilters_Index(t *testing.T) {
+	ctx := context.Background()
+	ws := &config.Workspace{
+		Name:     "ws",
+		Projects: []config.ProjectEntry{{Name: "api", Path: t.TempDir()}, {Name: "web", Path: t.TempDir()}},
+	}
+	ss := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(ws.Projects[0].Path))
+	if err := ss.SaveFile(ctx, "internal/handler.go", []trace.Symbol{
+		{Name: "HandleLogin", Kind: trace.KindFunction, File: "internal/handler.go", Line: 10, EndLine: 20},
+	}, nil); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	if err := ss.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	st := &prefixRecordingStore{GOBStore: store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))}
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		// Only the first chunk holds the definition; the second calls it.
+		{ID: "ws/api/internal/handler.go_0", FilePath: "ws/api/internal/handler.go", StartLine: 10, EndLine: 20, Content: "func HandleLogin() {}", Vector: []float32{1, 0}},
+		{ID: "ws/api/internal/routes.go_0", FilePath: "ws/api/internal/routes.go", StartLine: 1, EndLine: 5, Content: "HandleLogin()", Vector: []float32{1, 0}},
+		{ID: "ws/web/internal/login.ts_0", FilePath: "ws/web/internal/login.ts", StartLine: 1, EndLine: 5, Content: "function HandleLogin() {}", Vector: []float32{1, 0}},
+		{ID: "ws/web/cmd/internal/main.go_0", FilePath: "ws/web/cmd/internal/main.go", StartLine: 1, EndLine: 5, Content: "func main() {}", Vector: []float32{1, 0}},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	searcher, err := NewWorkspaceSearcher(st, keywordEmbedder{}, ws)
+	if err != nil {
+		t.Fatalf("NewWorkspaceSearcher failed: %v", err)
+	}
+
+	results, _, err := searcher.SearchWorkspace(ctx, "symbol:Handle* retry", 5, WorkspaceSearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchWorkspace failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ID != "ws/api/internal/handler.go_0" {
+		t.Errorf("expected only the chunk defining the symbol, got %+v", results)
+	}
+
+	// The path term is applied by the store, relative to each project root.
+	st.prefixes = nil
+	results, _, err = searcher.SearchWorkspace(ctx, "path:internal/ retry", 5, WorkspaceSearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchWorkspace failed: %v", err)
+	}
+	if strings.Join(st.prefixes, ",") != "ws/api/internal/,ws/web/internal/" {
+		t.Errorf("expected the path term in the store prefixes, got %v", st.prefixes)
+	}
+	if len(results) != 3 {
+		t.Errorf("expected the chunks under each project's internal/, got %+v", results)
+	}
+
+	// A path term outside the searched prefix matches nothing.
+	st.prefixes = nil
+	results, _, err = searcher.SearchWorkspace(ctx, "path:cmd/ retry", 5, WorkspaceSearchOptions{PathPrefix: "internal/"})
+	if err != nil {
+		t.Fatalf("SearchWorkspace failed: %v", err)
+	}
+	if len(results) != 0 || len(st.prefixes) != 0 {
+		t.Errorf("expected no search, got %v and %+v", st.prefixes, results)
+	}
+}
@@ -0,0 +1,203 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// Refine re-ranks the chunks of a previous result set against query and
+// returns the best limit of them, without running a new retrieval over the
+// whole index. ids are chunk IDs as returned with the previous results;
+// unknown IDs are skipped. A zero limit keeps every chunk. Chunks outside
+// pathPrefix or rejected by the chunk filter or the query's field filters
+// are dropped.
+func (s *Searcher) Refine(ctx context.Context, query string, ids []string, limit int, pathPrefix string) ([]store.SearchResult, error) {
+	q := ParseQuery(query)
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	filter, err := s.queryFilter(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := ResolveChunks(ctx, s.store, ids)
+	if err != nil {
+		return nil, err
+	}
+	kept := chunks[:0]
+	for _, c := range chunks {
+		if pathPrefix != "" && !strings.HasPrefix(c.FilePath, pathPrefix) {
+			continue
+		}
+		if filter != nil && !filter(c) {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	chunks = kept
+	if len(chunks) == 0 {
+		return []store.SearchResult{}, nil
+	}
+
+	// Chunks of the docs embedder are ranked against the docs embedding of
+	// the query, and both rankings fused.
+	var code, docs []store.Chunk
+	for _, c := range chunks {
+		if s.isDocsChunk(c) {
+			docs = append(docs, c)
+		} else {
+			code = append(code, c)
+		}
+	}
+	var lists [][]store.SearchResult
+	for _, group := range []struct {
+		emb    embedder.Embedder
+		chunks []store.Chunk
+	}{{s.embedder, code}, {s.docsEmb, docs}} {
+		if len(group.chunks) == 0 {
+			continue
+		}
+		ranked, err := rankChunks(ctx, group.emb, q.Text, group.chunks)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, ranked)
+	}
+	results := s.fuse(len(chunks), lists...)
+
+	if s.hybridCfg.Enabled {
+		textResults := TextSearch(ctx, chunks, q.Text, len(chunks), pathPrefix)
+		results = ReciprocalRankFusion(s.rrfK(), len(chunks), append(lists, textResults)...)
+	}
+
+	results = ApplyBoost(results, s.boostCfg)
+
+	if s.normalize != nil {
+		results = s.normalize(results)
+	}
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	if s.symbols != nil {
+		AnnotateSymbols(ctx, results, s.symbols)
+	}
+
+	return results, nil
+}
+
+// rankChunks orders chunks by the similarity of their vectors to the
+// embedding of query by emb, the embedder of the chunks.
+func rankChunks(ctx context.Context, emb embedder.Embedder, query string, chunks []store.Chunk) ([]store.SearchResult, error) {
+	queryVector, err := emb.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := embedMissingVectors(ctx, emb, chunks, len(queryVector)); err != nil {
+		return nil, err
+	}
+
+	results := make([]store.SearchResult, len(chunks))
+	for i, c := range chunks {
+		results[i] = store.SearchResult{Chunk: c, Score: cosineSimilarity(queryVector, c.Vector)}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results, nil
+}
+
+// embedMissingVectors embeds the content of chunks whose stored vector is
+// missing or does not match the query dimensions, which happens with
+// backends that do not return vectors with chunks.
+func embedMissingVectors(ctx context.Context, emb embedder.Embedder, chunks []store.Chunk, dims int) error {
+	var missing []int
+	var texts []string
+	for i, c := range chunks {
+		if len(c.Vector) != dims {
+			missing = append(missing, i)
+			texts = append(texts, c.Content)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	vectors, err := emb.EmbedBatch(ctx, texts)
+	if err != nil {
+		return err
+	}
+	for i, idx := range missing {
+		if i < len(vectors) {
+			chunks[idx].Vector = vectors[i]
+		}
+	}
+	return nil
+}
+
+// ResolveChunks returns the chunks with the given IDs, in the order of ids.
+// Chunk IDs are the chunk's file path followed by _<index> (and
+// _<sub-index> for split chunks) and a content hash (see indexer.ChunkID),
+// so each ID is looked up among the chunks of its file. IDs that are not in the store are skipped.
+func ResolveChunks(ctx context.Context, st store.VectorStore, ids []string) ([]store.Chunk, error) {
+	byFile := make(map[string]map[string]store.Chunk)
+	fileChunks := func(path string) (map[string]store.Chunk, error) {
+		if chunks, ok := byFile[path]; ok {
+			return chunks, nil
+		}
+		list, err := st.GetChunksForFile(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		chunks := make(map[string]store.Chunk, len(list))
+		for _, c := range list {
+			chunks[c.ID] = c
+		}
+		byFile[path] = chunks
+		return chunks, nil
+	}
+
+	seen := make(map[string]bool, len(ids))
+	var result []store.Chunk
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		for _, path := range indexer.ChunkIDFilePaths(id) {
+			chunks, err := fileChunks(path)
+			if err != nil {
+				return nil, err
+			}
+			if c, ok := chunks[id]; ok {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestSearcherRefine(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "retry_test.go_0", FilePath: "retry_test.go", StartLine: 1, EndLine: 10, Vector: []float32{0.2, 0.8}},
+		{ID: "net/retry.go_0", FilePath: "net/retry.go", StartLine: 1, EndLine: 10, Vector: []float32{0.5, 0.5}},
+		{ID: "net/retry.go_1_0", FilePath: "net/retry.go", StartLine: 11, EndLine: 20, Vector: []float32{1, 0}},
+		{ID: "net/dial.go_0", FilePath: "net/dial.go", StartLine: 1, EndLine: 10, Vector: []float32{0.9, 0.1}},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	for path, ids := range map[string][]string{
+		"retry_test.go": {"retry_test.go_0"},
+		"net/retry.go":  {"net/retry.go_0", "net/retry.go_1_0"},
+		"net/dial.go":   {"net/dial.go_0"},
+	} {
+		if err := st.SaveDocument(ctx, store.Document{Path: path, ChunkIDs: ids}); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+	}
+
+	searcher := NewSearcher(st, fixedEmbedder{}, config.SearchConfig{})
+	previous := []string{"retry_test.go_0", "net/retry.go_0", "net/retry.go_1_0", "gone.go_3"}
+
+	results, err := searcher.Refine(ctx, "only the retry logic", previous, 2, "")
+	if err != nil {
+		t.Fatalf("Refine failed: %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Chunk.ID)
+	}
+	// net/dial.go is closer to the query but was not in the previous results.
+	if want := []string{"net/retry.go_1_0", "net/retry.go_0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Refine = %v, want %v", got, want)
+	}
+
+	results, err = searcher.Refine(ctx, "retry", previous, 0, "net/")
+	if err != nil {
+		t.Fatalf("Refine failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected the path prefix to keep 2 chunks, got %d", len(results))
+	}
+}
+
+func TestResolveChunks_ContentHashIDs(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	ids := []string{
+		indexer.ChunkID("auth/login.go", 0, "1a2b3c4d5e6f7a8b"),
+		indexer.ChunkID("auth/login.go_1", 2, "abcdef0123456789"),
+	}
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: ids[0], FilePath: "auth/login.go", StartLine: 1, EndLine: 10},
+		{ID: ids[1], FilePath: "auth/login.go", StartLine: 11, EndLine: 20},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := st.SaveDocument(ctx, store.Document{Path: "auth/login.go", ChunkIDs: ids}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+
+	chunks, err := ResolveChunks(ctx, st, []string{ids[1], ids[0], "auth/login.go_5_000000000000"})
+	if err != nil {
+		t.Fatalf("ResolveChunks failed: %v", err)
+	}
+	var got []string
+	for _, c := range chunks {
+		got = append(got, c.ID)
+	}
+	if want := []string{ids[1], ids[0]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveChunks = %v, want %v", got, want)
+	}
+}
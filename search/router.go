@@ -0,0 +1,282 @@
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+	"github.com/yoanbernabeu/grepai/git"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// maxRouterSummaryBytes caps the README excerpt a project is routed by.
+const maxRouterSummaryBytes = 2000
+
+// readmeNames are the files read, in order, for a project's summary.
+var readmeNames = []string{"README.md", "README", "README.rst", "README.txt", "readme.md"}
+
+// ProjectRoute is the relevance of a workspace project to a query.
+type ProjectRoute struct {
+	Project string  `json:"project"`
+	Score   float32 `json:"score"`
+}
+
+// routerCache holds the summary embeddings of a workspace's projects, keyed
+// by project name. A summary is embedded again when its hash changes.
+type routerCache struct {
+	Projects map[string]routerEntry `json:"projects"`
+}
+
+type routerEntry struct {
+	Hash   string    `json:"hash"`
+	Vector []float32 `json:"vector"`
+}
+
+// RouteProjects ranks the projects of ws by the similarity of their summary
+// to queryVector, the embedding of a query by emb, and returns the best
+// ws.Router.Top() of them, most relevant first. Summary embeddings are
+// cached in the global config directory, so only projects whose summary
+// changed are embedded again.
+func RouteProjects(ctx context.Context, emb embedder.Embedder, ws *config.Workspace, queryVector []float32) ([]ProjectRoute, error) {
+	names := make([]string, len(ws.Projects))
+	hashes := make([]string, len(ws.Projects))
+	texts := make([]string, len(ws.Projects))
+	for i, p := range ws.Projects {
+		names[i] = p.Name
+		texts[i] = ProjectSummary(p)
+		sum := sha256.Sum256([]byte(ws.Embedder.Provider + "\x00" + ws.Embedder.Model + "\x00" + texts[i]))
+		hashes[i] = hex.EncodeToString(sum[:])
+	}
+
+	cachePath, err := config.GetRouterCachePath(ws.Name)
+	if err != nil {
+		return nil, err
+	}
+	cache := loadRouterCache(cachePath)
+
+	var missing []int
+	var missingTexts []string
+	for i, name := range names {
+		if entry, ok := cache.Projects[name]; !ok || entry.Hash != hashes[i] {
+			missing = append(missing, i)
+			missingTexts = append(missingTexts, texts[i])
+		}
+	}
+	if len(missing) > 0 {
+		vectors, err := emb.EmbedBatch(ctx, missingTexts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed project summaries: %w", err)
+		}
+		for j, i := range missing {
+			if j < len(vectors) {
+				cache.Projects[names[i]] = routerEntry{Hash: hashes[i], Vector: vectors[j]}
+			}
+		}
+		known := make(map[string]bool, len(names))
+		for _, name := range names {
+			known[name] = true
+		}
+		for name := range cache.Projects {
+			if !known[name] {
+				delete(cache.Projects, name)
+			}
+		}
+		if err := saveRouterCache(cachePath, cache); err != nil {
+			log.Printf("Warning: failed to save router cache: %v", err)
+		}
+	}
+
+	routes := make([]ProjectRoute, len(names))
+	for i, name := range names {
+		routes[i] = ProjectRoute{Project: name, Score: cosineSimilarity(queryVector, cache.Projects[name].Vector)}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Score > routes[j].Score
+	})
+	if top := ws.Router.Top(); len(routes) > top {
+		routes = routes[:top]
+	}
+	return routes, nil
+}
+
+// RoutedProjects returns the project names of routes.
+func RoutedProjects(routes []ProjectRoute) []string {
+	names := make([]string, len(routes))
+	for i, r := range routes {
+		names[i] = r.Project
+	}
+	return names
+}
+
+// ProjectSummary returns the text a project is routed by: its name, the
+// summary set on its entry or else the start of its README, and the
+// CODEOWNERS owners of its top-level files and directories, so queries
+// naming a team reach the projects it owns.
+func ProjectSummary(p config.ProjectEntry) string {
+	var b strings.Builder
+	b.WriteString("Project: " + p.Name + "\n")
+	if p.Summary != "" {
+		b.WriteString(p.Summary + "\n")
+	} else if readme := readProjectReadme(p.Path); readme != "" {
+		b.WriteString(readme + "\n")
+	}
+	if owners := projectOwners(p.Path); len(owners) > 0 {
+		b.WriteString("Owners: " + strings.Join(owners, " ") + "\n")
+	}
+	return b.String()
+}
+
+// readProjectReadme returns the start of a project's README, or an empty
+// string when it has none.
+func readProjectReadme(projectRoot string) string {
+	for _, name := range readmeNames {
+		data, err := os.ReadFile(filepath.Join(projectRoot, name))
+		if err != nil {
+			continue
+		}
+		if len(data) > maxRouterSummaryBytes {
+			data = data[:maxRouterSummaryBytes]
+		}
+		return strings.TrimSpace(strings.ToValidUTF8(string(data), ""))
+	}
+	return ""
+}
+
+// projectOwners returns the distinct CODEOWNERS owners of the top-level
+// entries of a project, sorted.
+func projectOwners(projectRoot string) []string {
+	owners, err := git.LoadCodeowners(projectRoot)
+	if err != nil || owners == nil {
+		return nil
+	}
+	entries, err := os.ReadDir(projectRoot)
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var result []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		for _, owner := range owners.Owners(name) {
+			if !seen[owner] {
+				seen[owner] = true
+				result = append(result, owner)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// WorkspaceSearchOptions select the part of a workspace a search covers.
+type WorkspaceSearchOptions struct {
+	// Projects are the projects searched, or all of them when empty.
+	Projects []string
+	// PathPrefix restricts results to paths under it in each project.
+	PathPrefix string
+	// Route narrows a search of all projects to the ones most relevant to
+	// the query when the workspace router is on.
+	Route bool
+}
+
+// SearchWorkspace returns the chunks of the searcher's workspace most
+// similar to query, and the routes taken when the search was routed. The
+// query is embedded once, for routing and retrieval.
+//
+// A search of some projects, under a path prefix, with a path term or
+// normalizing scores retrieves each project separately, with the project's path prefix applied
+// by the store, so every project fills its own ranking before they are
+// merged. Other searches retrieve the whole workspace at once.
+func (s *Searcher) SearchWorkspace(ctx context.Context, query string, limit int, opts WorkspaceSearchOptions) ([]store.SearchResult, []ProjectRoute, error) {
+	ws := s.workspace
+	if ws == nil {
+		return nil, nil, fmt.Errorf("searcher has no workspace")
+	}
+	q, queryVector, err := s.embedQuery(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projects := opts.Projects
+	var routes []ProjectRoute
+	if len(projects) == 0 && opts.Route && ws.Router.Routes(len(ws.Projects)) {
+		routes, err = RouteProjects(ctx, s.embedder, ws, queryVector)
+		if err != nil {
+			log.Printf("Warning: project routing skipped: %v", err)
+			routes = nil
+		}
+		projects = RoutedProjects(routes)
+	}
+
+	prefixes := []string{ws.Name + "/"}
+	if len(projects) > 0 || opts.PathPrefix != "" || q.pathPrefix() != "" || s.normalize != nil {
+		if len(projects) == 0 {
+			for _, p := range ws.Projects {
+				projects = append(projects, p.Name)
+			}
+		}
+		prefixes = make([]string, len(projects))
+		for i, project := range projects {
+			prefixes[i] = ws.Name + "/" + project + "/" + opts.PathPrefix
+		}
+	}
+
+	results, err := s.searchPrefixes(ctx, query, q, queryVector, limit, prefixes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, routes, nil
+}
+
+// AndChunkFilters returns a filter keeping chunks accepted by both a and b.
+// A nil filter accepts every chunk.
+func AndChunkFilters(a, b func(store.Chunk) bool) func(store.Chunk) bool {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return func(c store.Chunk) bool {
+		return a(c) && b(c)
+	}
+}
+
+func loadRouterCache(path string) *routerCache {
+	cache := &routerCache{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, cache); err != nil {
+			log.Printf("Warning: ignoring unreadable router cache %s: %v", path, err)
+		}
+	}
+	if cache.Projects == nil {
+		cache.Projects = make(map[string]routerEntry)
+	}
+	return cache
+}
+
+func saveRouterCache(path string, cache *routerCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,235 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// countingEmbedder counts the texts embedded in batches.
+type countingEmbedder struct {
+	keywordEmbedder
+	batched int
+}
+
+func (e *countingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e.batched += len(texts)
+	return e.keywordEmbedder.EmbedBatch(ctx, texts)
+}
+
+func TestRouteProjects(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	mkProject := func(name, readme string) config.ProjectEntry {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if readme != "" {
+			if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(readme), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return config.ProjectEntry{Name: name, Path: dir}
+	}
+	ws := &config.Workspace{
+		Name: "ws",
+		Projects: []config.ProjectEntry{
+			mkProject("billing", "Invoices and payments."),
+			mkProject("gateway", "HTTP client with retry and backoff."),
+			mkProject("web", ""),
+		},
+		Router: config.RouterConfig{Enabled: true, TopProjects: 1},
+	}
+	ws.Projects[2].Summary = "Page cache for the storefront."
+
+	emb := &countingEmbedder{}
+	route := func(query string) ([]ProjectRoute, error) {
+		queryVector, _ := emb.Embed(context.Background(), query)
+		return RouteProjects(context.Background(), emb, ws, queryVector)
+	}
+	routes, err := route("retry policy")
+	if err != nil {
+		t.Fatalf("RouteProjects failed: %v", err)
+	}
+	if len(routes) != 1 || routes[0].Project != "gateway" {
+		t.Fatalf("expected the gateway project, got %+v", routes)
+	}
+	if emb.batched != 3 {
+		t.Errorf("expected 3 summaries embedded, got %d", emb.batched)
+	}
+
+	routes, err = route("cache invalidation")
+	if err != nil {
+		t.Fatalf("RouteProjects failed: %v", err)
+	}
+	if routes[0].Project != "web" {
+		t.Errorf("expected the summary to route to web, got %+v", routes)
+	}
+	if emb.batched != 3 {
+		t.Errorf("expected cached summaries to be reused, got %d embedded", emb.batched)
+	}
+
+	if err := os.WriteFile(filepath.Join(ws.Projects[0].Path, "README.md"), []byte("Retry failed payments."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := route("retry"); err != nil {
+		t.Fatalf("RouteProjects failed: %v", err)
+	}
+	if emb.batched != 4 {
+		t.Errorf("expected only the changed summary embedded again, got %d embedded", emb.batched)
+	}
+}
+
+func TestProjectSummary_Owners(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "payments"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("* @org/platform\n/payments/ @org/billing\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	summary := ProjectSummary(config.ProjectEntry{Name: "shop", Path: dir})
+	want := "Project: shop\nOwners: @org/billing @org/platform\n"
+	if summary != want {
+		t.Errorf("expected %q, got %q", want, summary)
+	}
+}
+
+// prefixRecordingStore records the path prefixes searches are made with.
+type prefixRecordingStore struct {
+	*store.GOBStore
+	prefixes []string
+}
+
+func (s *prefixRecordingStore) Search(ctx context.Context, queryVector []float32, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
+	s.prefixes = append(s.prefixes, opts.PathPrefix)
+	return s.GOBStore.Search(ctx, queryVector, limit, opts)
+}
+
+// embedCountingEmbedder counts the queries embedded one at a time.
+type embedCountingEmbedder struct {
+	keywordEmbedder
+	embeds int
+}
+
+func (e *embedCountingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.embeds++
+	return e.keywordEmbedder.Embed(ctx, text)
+}
+
+func TestSearcher_SearchWorkspace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+	ws := &config.Workspace{
+		Name: "ws",
+		Projects: []config.ProjectEntry{
+			{Name: "billing", Path: t.TempDir(), Summary: "Invoices and payments."},
+			{Name: "gateway", Path: t.TempDir(), Summary: "HTTP client with retry and backoff."},
+			{Name: "web", Path: t.TempDir(), Summary: "Page cache."},
+		},
+		Router: config.RouterConfig{Enabled: true, TopProjects: 1},
+	}
+	st := &prefixRecordingStore{GOBStore: store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))}
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "ws/billing/pay.go_0", FilePath: "ws/billing/pay.go", Vector: []float32{1, 0}},
+		{ID: "ws/gateway/client.go_0", FilePath: "ws/gateway/client.go", Vector: []float32{1, 1}},
+		{ID: "ws/gateway/src/retry.go_0", FilePath: "ws/gateway/src/retry.go", Vector: []float32{1, 0}},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	emb := &embedCountingEmbedder{}
+	searcher, err := NewWorkspaceSearcher(st, emb, ws)
+	if err != nil {
+		t.Fatalf("NewWorkspaceSearcher failed: %v", err)
+	}
+
+	// The routed project is searched with its prefix, so billing's closer
+	// chunk cannot crowd it out, and the query is embedded once.
+	results, routes, err := searcher.SearchWorkspace(ctx, "retry policy", 5, WorkspaceSearchOptions{Route: true})
+	if err != nil {
+		t.Fatalf("SearchWorkspace failed: %v", err)
+	}
+	if len(routes) != 1 || routes[0].Project != "gateway" {
+		t.Fatalf("expected the gateway route, got %+v", routes)
+	}
+	if len(results) != 2 || results[0].Chunk.FilePath != "ws/gateway/src/retry.go" {
+		t.Fatalf("expected only gateway chunks, got %+v", results)
+	}
+	if len(st.prefixes) != 1 || st.prefixes[0] != "ws/gateway/" {
+		t.Errorf("expected one search under ws/gateway/, got %v", st.prefixes)
+	}
+	if emb.embeds != 1 {
+		t.Errorf("expected the query to be embedded once, got %d", emb.embeds)
+	}
+
+	// Explicit projects are searched one by one under the path prefix.
+	st.prefixes = nil
+	results, routes, err = searcher.SearchWorkspace(ctx, "retry", 5, WorkspaceSearchOptions{Projects: []string{"billing", "gateway"}, PathPrefix: "src/", Route: true})
+	if err != nil {
+		t.Fatalf("SearchWorkspace failed: %v", err)
+	}
+	if routes != nil {
+		t.Errorf("expected no routing with explicit projects, got %+v", routes)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "ws/gateway/src/retry.go" {
+		t.Fatalf("expected the chunk under src/, got %+v", results)
+	}
+	if strings.Join(st.prefixes, ",") != "ws/billing/src/,ws/gateway/src/" {
+		t.Errorf("unexpected search prefixes %v", st.prefixes)
+	}
+
+	cfg := config.RouterConfig{Enabled: true}
+	if cfg.Routes(config.DefaultRouterTopProjects) || !cfg.Routes(config.DefaultRouterTopProjects+1) {
+		t.Error("expected routing only with more projects than the router keeps")
+	}
+}
+
+func TestSearcher_SearchWorkspaceNormalizesPerProject(t *testing.T) {
+	ctx := context.Background()
+	ws := &config.Workspace{
+		Name: "ws",
+		Projects: []config.ProjectEntry{
+			{Name: "docs", Path: t.TempDir()},
+			{Name: "api", Path: t.TempDir()},
+		},
+		ScoreNormalization: config.ScoreNormalizationMinMax,
+	}
+	st := &prefixRecordingStore{GOBStore: store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))}
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "ws/docs/a.md_0", FilePath: "ws/docs/a.md", Vector: []float32{1, 0}},
+		{ID: "ws/docs/b.md_0", FilePath: "ws/docs/b.md", Vector: []float32{1, 0.1}},
+		{ID: "ws/docs/c.md_0", FilePath: "ws/docs/c.md", Vector: []float32{1, 0.2}},
+		{ID: "ws/docs/d.md_0", FilePath: "ws/docs/d.md", Vector: []float32{1, 0.3}},
+		{ID: "ws/api/x.go_0", FilePath: "ws/api/x.go", Vector: []float32{1, 1}},
+		{ID: "ws/api/y.go_0", FilePath: "ws/api/y.go", Vector: []float32{1, 2}},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	searcher, err := NewWorkspaceSearcher(st, keywordEmbedder{}, ws)
+	if err != nil {
+		t.Fatalf("NewWorkspaceSearcher failed: %v", err)
+	}
+
+	// Every docs chunk outscores the api ones, so a shared top-K would hold
+	// docs only; retrieved on its own, api's best ties docs' best.
+	results, _, err := searcher.SearchWorkspace(ctx, "retry", 2, WorkspaceSearchOptions{})
+	if err != nil {
+		t.Fatalf("SearchWorkspace failed: %v", err)
+	}
+	if strings.Join(st.prefixes, ",") != "ws/docs/,ws/api/" {
+		t.Errorf("expected one search per project, got %v", st.prefixes)
+	}
+	if len(results) != 2 || results[0].Chunk.FilePath != "ws/docs/a.md" || results[1].Chunk.FilePath != "ws/api/x.go" {
+		t.Fatalf("expected each project's best result, got %+v", results)
+	}
+}
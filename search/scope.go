@@ -0,0 +1,43 @@
+package search
+
+import (
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// ScopeQuery returns the field filters of a scope, as if its paths and
+// languages were written in a query.
+func ScopeQuery(scope config.Scope) Query {
+	var q Query
+	for _, p := range scope.Paths {
+		q.Filters = append(q.Filters, FieldFilter{Field: FieldPath, Value: p})
+	}
+	for _, lang := range scope.Languages {
+		q.Filters = append(q.Filters, FieldFilter{Field: FieldLang, Value: lang})
+	}
+	for _, p := range scope.ExcludePaths {
+		q.Filters = append(q.Filters, FieldFilter{Field: FieldPath, Value: p, Negate: true})
+	}
+	return q
+}
+
+// ScopeFileFilter returns a filter keeping the files in scope.
+func ScopeFileFilter(scope config.Scope) func(file string) bool {
+	keep := ScopeQuery(scope).Keep()
+	return func(file string) bool {
+		if scope.ExcludeTests && trace.IsTestFile(file) {
+			return false
+		}
+		return keep == nil || keep(store.Chunk{FilePath: file})
+	}
+}
+
+// ScopeFilter returns a search chunk filter keeping the chunks of files in
+// scope.
+func ScopeFilter(scope config.Scope) func(store.Chunk) bool {
+	keepFile := ScopeFileFilter(scope)
+	return func(c store.Chunk) bool {
+		return keepFile(c.FilePath)
+	}
+}
@@ -0,0 +1,32 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestScopeFileFilter(t *testing.T) {
+	keep := ScopeFileFilter(config.Scope{
+		Paths:        []string{"internal/", "cmd/"},
+		Languages:    []string{"go"},
+		ExcludePaths: []string{"internal/testdata/"},
+		ExcludeTests: true,
+	})
+	for file, want := range map[string]bool{
+		"internal/api/server.go":       true,
+		"cmd/grepai/main.go":           true,
+		"internal/api/server_test.go":  false,
+		"internal/testdata/fixture.go": false,
+		"internal/web/app.ts":          false,
+		"docs/guide.go":                false,
+	} {
+		if got := keep(file); got != want {
+			t.Errorf("%s: expected %v, got %v", file, want, got)
+		}
+	}
+
+	if keep := ScopeFileFilter(config.Scope{}); !keep("anything/at/all.py") {
+		t.Error("expected an empty scope to keep every file")
+	}
+}
@@ -2,20 +2,41 @@ package search
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/coverage"
 	"github.com/yoanbernabeu/grepai/embedder"
 	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
 )
 
+// filteredFetchFactor widens retrieval when a chunk filter is set.
+const filteredFetchFactor = 5
+
 type Searcher struct {
 	store     store.VectorStore
 	embedder  embedder.Embedder
+	docsEmb   embedder.Embedder // embeds queries for documentation chunks
+	docsModel string
 	boostCfg  config.BoostConfig
 	hybridCfg config.HybridConfig
 	dedupCfg  config.DedupConfig
+	symbols   trace.SymbolStore
+	filter    func(store.Chunk) bool
+	normalize func([]store.SearchResult) []store.SearchResult
+	workspace *config.Workspace
+	observe   ConfidenceObserver
+	coverage  *coverage.Profile
 }
 
+// ConfidenceObserver is told the results of each search and its confidence:
+// the similarity of the chunk nearest to the query, before boosts and
+// filters, or 0 when the index has none.
+type ConfidenceObserver func(query string, confidence float32, results []store.SearchResult)
+
 func NewSearcher(st store.VectorStore, emb embedder.Embedder, searchCfg config.SearchConfig) *Searcher {
 	return &Searcher{
 		store:     st,
@@ -26,8 +47,99 @@ func NewSearcher(st store.VectorStore, emb embedder.Embedder, searchCfg config.S
 	}
 }
 
+// WorkspaceConfig returns the search configuration used for workspaces,
+// which have no search section of their own: the project defaults, with
+// hybrid search enabled when st ranks text on the server, since client-side
+// fusion would load every chunk of the workspace.
+func WorkspaceConfig(st store.VectorStore) config.SearchConfig {
+	_, serverText := st.(store.TextSearcher)
+	return config.SearchConfig{
+		Hybrid: config.HybridConfig{Enabled: serverText, K: 60},
+		Boost:  config.DefaultConfig().Search.Boost,
+	}
+}
+
+// NewProjectSearcher returns the searcher of a project configured by cfg:
+// st is searched with emb, and documentation chunks with docsEmb when the
+// project embeds them with a model of their own (docsEmb is nil otherwise).
+func NewProjectSearcher(st store.VectorStore, emb, docsEmb embedder.Embedder, cfg *config.Config) *Searcher {
+	s := NewSearcher(st, emb, cfg.Search)
+	if docsEmb != nil && cfg.Embedder.Docs != nil {
+		s.SetDocsEmbedder(docsEmb, cfg.Embedder.Docs.Model)
+	}
+	return s
+}
+
+// NewWorkspaceSearcher returns the searcher of st, the store shared by the
+// projects of ws, searched with emb and normalizing scores per project as
+// ws configures. SearchWorkspace searches a part of ws.
+func NewWorkspaceSearcher(st store.VectorStore, emb embedder.Embedder, ws *config.Workspace) (*Searcher, error) {
+	s := NewSearcher(st, emb, WorkspaceConfig(st))
+	s.workspace = ws
+	if err := s.SetProjectNormalization(ws.Name, ws.ScoreNormalization); err != nil {
+		return nil, fmt.Errorf("workspace %q: %w", ws.Name, err)
+	}
+	return s, nil
+}
+
+// SetSymbolStore enables annotating results with their enclosing symbol.
+func (s *Searcher) SetSymbolStore(ss trace.SymbolStore) {
+	s.symbols = ss
+}
+
+// SetDocsEmbedder makes the searcher embed queries with emb too, to rank
+// the documentation chunks that the indexer tagged with model, and fuse that
+// ranking with the ranking of code chunks.
+func (s *Searcher) SetDocsEmbedder(emb embedder.Embedder, model string) {
+	s.docsEmb = emb
+	s.docsModel = model
+}
+
+// SetConfidenceObserver makes the searcher call observe after each search.
+func (s *Searcher) SetConfidenceObserver(observe ConfidenceObserver) {
+	s.observe = observe
+}
+
+// SetCoverage makes the searcher boost results by their test coverage in
+// profile, as the uncovered factor of the boost configuration sets.
+func (s *Searcher) SetCoverage(profile *coverage.Profile) {
+	s.coverage = profile
+}
+
+// SetChunkFilter restricts results to chunks accepted by keep. The filter is
+// applied after retrieval, so more candidates are fetched to fill the limit.
+func (s *Searcher) SetChunkFilter(keep func(store.Chunk) bool) {
+	s.filter = keep
+}
+
+// Search returns the chunks most similar to query. The query may combine
+// field filters with its text, as described in ParseQuery; a path term is
+// applied by the store along with pathPrefix.
 func (s *Searcher) Search(ctx context.Context, query string, limit int, pathPrefix string) ([]store.SearchResult, error) {
-	queryVector, err := s.embedder.Embed(ctx, query)
+	q, queryVector, err := s.embedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.searchPrefixes(ctx, query, q, queryVector, limit, []string{pathPrefix})
+}
+
+// embedQuery parses query and embeds its text.
+func (s *Searcher) embedQuery(ctx context.Context, query string) (Query, []float32, error) {
+	q := ParseQuery(query)
+	if err := q.Validate(); err != nil {
+		return q, nil, err
+	}
+	queryVector, err := s.embedder.Embed(ctx, q.Text)
+	if err != nil {
+		return q, nil, err
+	}
+	return q, queryVector, nil
+}
+
+// searchPrefixes ranks the chunks under each path prefix separately, with
+// the prefix applied by the store, and merges the rankings by score.
+func (s *Searcher) searchPrefixes(ctx context.Context, query string, q Query, queryVector []float32, limit int, prefixes []string) ([]store.SearchResult, error) {
+	filter, err := s.queryFilter(ctx, q)
 	if err != nil {
 		return nil, err
 	}
@@ -36,22 +148,35 @@ func (s *Searcher) Search(ctx context.Context, query string, limit int, pathPref
 	if s.dedupCfg.Enabled {
 		fetchMultiplier = 4
 	}
+	if filter != nil {
+		fetchMultiplier *= filteredFetchFactor
+	}
 	fetchLimit := limit * fetchMultiplier
 
 	var results []store.SearchResult
-
-	if s.hybridCfg.Enabled {
-		results, err = s.hybridSearch(ctx, query, queryVector, fetchLimit, pathPrefix)
-	} else {
-		results, err = s.store.Search(ctx, queryVector, fetchLimit, store.SearchOptions{PathPrefix: pathPrefix})
+	var confidence float32
+	term := q.pathPrefix()
+	for _, prefix := range prefixes {
+		if term != "" {
+			narrowed, ok := s.narrowPrefix(prefix, term)
+			if !ok {
+				continue
+			}
+			prefix = narrowed
+		}
+		ranked, top, err := s.rank(ctx, q.Text, queryVector, fetchLimit, prefix, filter)
+		if err != nil {
+			return nil, err
+		}
+		confidence = max(confidence, top)
+		results = append(results, ranked...)
 	}
-
-	if err != nil {
-		return nil, err
+	if len(prefixes) > 1 {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Score > results[j].Score
+		})
 	}
 
-	results = ApplyBoost(results, s.boostCfg)
-
 	if s.dedupCfg.Enabled {
 		results = DeduplicateByFile(results)
 	}
@@ -60,27 +185,175 @@ func (s *Searcher) Search(ctx context.Context, query string, limit int, pathPref
 		results = results[:limit]
 	}
 
+	if s.symbols != nil {
+		AnnotateSymbols(ctx, results, s.symbols)
+	}
+
+	if s.observe != nil {
+		s.observe(query, confidence, results)
+	}
+
 	return results, nil
 }
 
-// hybridSearch combines vector search and text search using RRF.
-func (s *Searcher) hybridSearch(ctx context.Context, query string, queryVector []float32, limit int, pathPrefix string) ([]store.SearchResult, error) {
-	vectorResults, err := s.store.Search(ctx, queryVector, limit, store.SearchOptions{PathPrefix: pathPrefix})
+// narrowPrefix combines a search path prefix with the prefix of a path
+// term, relative to the project the search prefix is in, so the store
+// applies both. It reports false when no path has both prefixes. A search
+// prefix spanning several workspace projects is kept as is.
+func (s *Searcher) narrowPrefix(prefix, term string) (string, bool) {
+	full := term
+	if s.workspace != nil {
+		project, _, ok := splitWorkspacePath(s.workspace.Name, prefix)
+		if !ok {
+			return prefix, true
+		}
+		full = s.workspace.Name + "/" + project + "/" + term
+	}
+	switch {
+	case strings.HasPrefix(full, prefix):
+		return full, true
+	case strings.HasPrefix(prefix, full):
+		return prefix, true
+	}
+	return "", false
+}
+
+// rank returns the chunks under pathPrefix nearest to the query, filtered,
+// boosted and normalized, and the similarity of the nearest one before
+// boosts and filters.
+func (s *Searcher) rank(ctx context.Context, text string, queryVector []float32, fetchLimit int, pathPrefix string, filter func(store.Chunk) bool) ([]store.SearchResult, float32, error) {
+	lists, err := s.vectorSearch(ctx, text, queryVector, fetchLimit, pathPrefix)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	var confidence float32
+	for _, list := range lists {
+		if len(list) > 0 && list[0].Score > confidence {
+			confidence = list[0].Score
+		}
 	}
 
-	allChunks, err := s.store.GetAllChunks(ctx)
+	var results []store.SearchResult
+	if s.hybridCfg.Enabled {
+		results, err = s.hybridSearch(ctx, text, lists, fetchLimit, pathPrefix)
+		if err != nil {
+			return nil, 0, err
+		}
+	} else {
+		results = s.fuse(fetchLimit, lists...)
+	}
+
+	if filter != nil {
+		kept := results[:0]
+		for _, r := range results {
+			if filter(r.Chunk) {
+				kept = append(kept, r)
+			}
+		}
+		results = kept
+	}
+
+	results = ApplyBoost(results, s.boostCfg)
+	results = ApplyCoverageBoost(results, s.coverage, s.boostCfg)
+
+	if s.normalize != nil {
+		results = s.normalize(results)
+	}
+	return results, confidence, nil
+}
+
+// AnnotateSymbols sets SymbolName and SymbolKind on each result from the
+// symbol enclosing its chunk. Lookup failures leave results unannotated.
+func AnnotateSymbols(ctx context.Context, results []store.SearchResult, ss trace.SymbolStore) {
+	byFile := make(map[string][]trace.Symbol)
+	for i := range results {
+		chunk := results[i].Chunk
+		symbols, ok := byFile[chunk.FilePath]
+		if !ok {
+			symbols, _ = ss.GetSymbolsForFile(ctx, chunk.FilePath)
+			byFile[chunk.FilePath] = symbols
+		}
+		if sym := trace.EnclosingSymbol(symbols, chunk.StartLine, chunk.EndLine); sym != nil {
+			results[i].SymbolName = sym.Name
+			if sym.Receiver != "" {
+				results[i].SymbolName = strings.TrimLeft(sym.Receiver, "*") + "." + sym.Name
+			}
+			results[i].SymbolKind = string(sym.Kind)
+		}
+	}
+}
+
+// vectorSearch returns the rankings of the chunks nearest to the query:
+// a single ranking, or with a docs embedder, the code chunks nearest to
+// queryVector and the documentation chunks nearest to the docs embedding of
+// query, whose scores are not comparable.
+func (s *Searcher) vectorSearch(ctx context.Context, query string, queryVector []float32, limit int, pathPrefix string) ([][]store.SearchResult, error) {
+	opts := store.SearchOptions{PathPrefix: pathPrefix}
+	if s.docsEmb == nil {
+		results, err := s.store.Search(ctx, queryVector, limit, opts)
+		if err != nil {
+			return nil, err
+		}
+		return [][]store.SearchResult{results}, nil
+	}
+
+	docsVector, err := s.docsEmb.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	codeOpts, docsOpts := opts, opts
+	codeOpts.ExcludeModel = s.docsModel
+	docsOpts.Model = s.docsModel
+	codeResults, err := s.store.Search(ctx, queryVector, limit, codeOpts)
+	if err != nil {
+		return nil, err
+	}
+	docsResults, err := s.store.Search(ctx, docsVector, limit, docsOpts)
 	if err != nil {
 		return nil, err
 	}
+	return [][]store.SearchResult{codeResults, docsResults}, nil
+}
+
+// isDocsChunk reports whether c was embedded with the docs embedder.
+func (s *Searcher) isDocsChunk(c store.Chunk) bool {
+	return s.docsEmb != nil && c.Model == s.docsModel
+}
+
+// fuse merges rankings with RRF, returning a single ranking unchanged.
+func (s *Searcher) fuse(limit int, lists ...[]store.SearchResult) []store.SearchResult {
+	if len(lists) == 1 {
+		return lists[0]
+	}
+	return ReciprocalRankFusion(s.rrfK(), limit, lists...)
+}
 
-	textResults := TextSearch(ctx, allChunks, query, limit, pathPrefix)
+// rrfK returns the RRF constant of hybrid search, which also fuses the
+// rankings of code and documentation chunks.
+func (s *Searcher) rrfK() float32 {
+	if s.hybridCfg.K <= 0 {
+		return 60
+	}
+	return s.hybridCfg.K
+}
 
-	k := s.hybridCfg.K
-	if k <= 0 {
-		k = 60
+// hybridSearch combines the rankings of vector search with text search
+// using RRF.
+func (s *Searcher) hybridSearch(ctx context.Context, query string, vectorLists [][]store.SearchResult, limit int, pathPrefix string) ([]store.SearchResult, error) {
+	var textResults []store.SearchResult
+	if ts, ok := s.store.(store.TextSearcher); ok {
+		var err error
+		textResults, err = ts.SearchText(ctx, query, limit, store.SearchOptions{PathPrefix: pathPrefix})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		allChunks, err := s.store.GetAllChunks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		textResults = TextSearch(ctx, allChunks, query, limit, pathPrefix)
 	}
 
-	return ReciprocalRankFusion(k, limit, vectorResults, textResults), nil
+	return ReciprocalRankFusion(s.rrfK(), limit, append(vectorLists, textResults)...), nil
 }
@@ -0,0 +1,166 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func TestAnnotateSymbols(t *testing.T) {
+	ctx := context.Background()
+	symbols := trace.NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	if err := symbols.SaveFile(ctx, "auth.go", []trace.Symbol{
+		{Name: "HandleAuth", Kind: trace.KindFunction, File: "auth.go", Line: 10, EndLine: 30},
+		{Name: "Validate", Kind: trace.KindMethod, File: "auth.go", Line: 40, EndLine: 50, Receiver: "*Token"},
+	}, nil); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "auth.go", StartLine: 12, EndLine: 20}},
+		{Chunk: store.Chunk{FilePath: "auth.go", StartLine: 41, EndLine: 45}},
+		{Chunk: store.Chunk{FilePath: "other.go", StartLine: 1, EndLine: 5}},
+	}
+	AnnotateSymbols(ctx, results, symbols)
+
+	if results[0].SymbolName != "HandleAuth" || results[0].SymbolKind != "function" {
+		t.Errorf("unexpected annotation: %q (%q)", results[0].SymbolName, results[0].SymbolKind)
+	}
+	if results[1].SymbolName != "Token.Validate" || results[1].SymbolKind != "method" {
+		t.Errorf("unexpected annotation: %q (%q)", results[1].SymbolName, results[1].SymbolKind)
+	}
+	if results[2].SymbolName != "" {
+		t.Errorf("expected no annotation for unindexed file, got %q", results[2].SymbolName)
+	}
+}
+
+type fixedEmbedder struct{}
+
+func (fixedEmbedder) Embed(context.Context, string) ([]float32, error) { return []float32{1, 0}, nil }
+func (fixedEmbedder) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+func (fixedEmbedder) Dimensions() int { return 2 }
+func (fixedEmbedder) Close() error    { return nil }
+
+func TestSearcherChunkFilter(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "a", FilePath: "auth/login.go", StartLine: 1, EndLine: 10, Vector: []float32{0.9, 0.1}},
+		{ID: "b", FilePath: "billing/invoice.go", StartLine: 1, EndLine: 10, Vector: []float32{1, 0}},
+		{ID: "c", FilePath: "auth/session.go", StartLine: 1, EndLine: 10, Vector: []float32{0.5, 0.5}},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	searcher := NewSearcher(st, fixedEmbedder{}, config.SearchConfig{})
+	searcher.SetChunkFilter(func(c store.Chunk) bool { return c.FilePath != "billing/invoice.go" })
+
+	results, err := searcher.Search(ctx, "login", 2, "")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Chunk.FilePath == "billing/invoice.go" {
+			t.Fatalf("filtered chunk returned: %+v", r.Chunk)
+		}
+	}
+}
+
+// serverTextStore is a GOB store that ranks text on the "server" and fails
+// if hybrid search falls back to loading every chunk.
+type serverTextStore struct {
+	*store.GOBStore
+	queries []string
+}
+
+func (s *serverTextStore) SearchText(_ context.Context, query string, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
+	s.queries = append(s.queries, query+"|"+opts.PathPrefix)
+	return []store.SearchResult{{Chunk: store.Chunk{ID: "text", FilePath: "auth/text.go"}, Score: 7}}, nil
+}
+
+func (s *serverTextStore) GetAllChunks(context.Context) ([]store.Chunk, error) {
+	return nil, errors.New("GetAllChunks should not be called")
+}
+
+func TestHybridSearchUsesServerTextSearch(t *testing.T) {
+	ctx := context.Background()
+	st := &serverTextStore{GOBStore: store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))}
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "vec", FilePath: "auth/vec.go", Vector: []float32{1, 0}},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	cfg := WorkspaceConfig(st)
+	if !cfg.Hybrid.Enabled {
+		t.Fatal("expected hybrid search for a store with server-side text search")
+	}
+	results, err := NewSearcher(st, fixedEmbedder{}, cfg).Search(ctx, "login", 5, "auth/")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected vector and text results to be fused, got %+v", results)
+	}
+	if len(st.queries) != 1 || st.queries[0] != "login|auth/" {
+		t.Errorf("unexpected text queries: %v", st.queries)
+	}
+
+	if WorkspaceConfig(st.GOBStore).Hybrid.Enabled {
+		t.Error("expected hybrid search off for a store without server-side text search")
+	}
+}
+
+type docsEmbedder struct{ fixedEmbedder }
+
+func (docsEmbedder) Embed(context.Context, string) ([]float32, error) { return []float32{0, 1}, nil }
+
+func TestSearcherDocsEmbedder(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "a.go_0", FilePath: "a.go", Vector: []float32{1, 0}},
+		{ID: "b.go_0", FilePath: "b.go", Vector: []float32{0, 1}},
+		{ID: "a.md_0", FilePath: "a.md", Vector: []float32{0, 1}, Model: "docs-model"},
+		{ID: "b.md_0", FilePath: "b.md", Vector: []float32{1, 0}, Model: "docs-model"},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	for _, path := range []string{"a.go", "b.go", "a.md", "b.md"} {
+		if err := st.SaveDocument(ctx, store.Document{Path: path, ChunkIDs: []string{path + "_0"}}); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+	}
+
+	searcher := NewSearcher(st, fixedEmbedder{}, config.SearchConfig{})
+	searcher.SetDocsEmbedder(docsEmbedder{}, "docs-model")
+
+	// Each embedding of the query ranks the chunks of its own model: a.go
+	// for code and a.md for docs, although b.md is nearer the code embedding.
+	want := map[string]bool{"a.go_0": true, "a.md_0": true}
+	results, err := searcher.Search(ctx, "run", 2, "")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || !want[results[0].Chunk.ID] || !want[results[1].Chunk.ID] {
+		t.Fatalf("expected the best code and docs chunks, got %+v", results)
+	}
+
+	results, err = searcher.Refine(ctx, "run", []string{"a.go_0", "b.go_0", "a.md_0", "b.md_0"}, 2, "")
+	if err != nil {
+		t.Fatalf("Refine failed: %v", err)
+	}
+	if len(results) != 2 || !want[results[0].Chunk.ID] || !want[results[1].Chunk.ID] {
+		t.Fatalf("expected refine to rank each model with its own query embedding, got %+v", results)
+	}
+}
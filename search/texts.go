@@ -0,0 +1,31 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yoanbernabeu/grepai/embedder"
+)
+
+// RankVectors scores vectors, embedded ahead of time with emb, by their
+// semantic similarity to query: the cosine similarity with the query's
+// embedding. Only the query is embedded. Scores are in the order of
+// vectors; it fails when they do not have the query's dimensions, which
+// means they were embedded with another model.
+func RankVectors(ctx context.Context, emb embedder.Embedder, query string, vectors [][]float32) ([]float32, error) {
+	if len(vectors) == 0 {
+		return nil, nil
+	}
+	queryVector, err := emb.Embed(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float32, len(vectors))
+	for i, vector := range vectors {
+		if len(vector) != len(queryVector) {
+			return nil, fmt.Errorf("vectors have %d dimensions but the query %d: they were embedded with another model", len(vector), len(queryVector))
+		}
+		scores[i] = cosineSimilarity(queryVector, vector)
+	}
+	return scores, nil
+}
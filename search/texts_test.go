@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// keywordEmbedder embeds a text on two axes: mentions of retries and of
+// caching.
+type keywordEmbedder struct{}
+
+func (keywordEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	var v [2]float32
+	if strings.Contains(text, "retry") {
+		v[0] = 1
+	}
+	if strings.Contains(text, "cache") {
+		v[1] = 1
+	}
+	return v[:], nil
+}
+func (e keywordEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.Embed(ctx, text)
+	}
+	return vectors, nil
+}
+func (keywordEmbedder) Dimensions() int { return 2 }
+func (keywordEmbedder) Close() error    { return nil }
+
+func TestRankVectors(t *testing.T) {
+	ctx := context.Background()
+	emb := keywordEmbedder{}
+	vectors, _ := emb.EmbedBatch(ctx, []string{
+		"invalidate the cache on logout",
+		"retry with backoff instead of sleeping",
+	})
+	scores, err := RankVectors(ctx, emb, "flaky retry logic", vectors)
+	if err != nil {
+		t.Fatalf("RankVectors failed: %v", err)
+	}
+	if len(scores) != 2 || scores[1] <= scores[0] {
+		t.Errorf("expected the retry text to score higher, got %v", scores)
+	}
+
+	if scores, err := RankVectors(ctx, emb, "retry", nil); err != nil || scores != nil {
+		t.Errorf("expected no scores for no vectors, got %v, %v", scores, err)
+	}
+	if _, err := RankVectors(ctx, emb, "retry", [][]float32{{1, 0, 0}}); err == nil {
+		t.Error("expected an error for vectors of another model")
+	}
+}
@@ -0,0 +1,197 @@
+package stats
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// CostFileName is the name of the NDJSON spend file inside .grepai/.
+const CostFileName = "cost.json"
+
+// CostLockFileName is the name of the lock file of the spend file.
+const CostLockFileName = "cost.json.lock"
+
+// costRollupSize is the size in bytes past which the spend file is rolled
+// up by RollupSpend after a spend is recorded.
+const costRollupSize = 64 << 10
+
+// Spend is the estimated usage of a paid embedding provider by one
+// operation, such as the initial scan of a watch session.
+type Spend struct {
+	Timestamp string  `json:"timestamp"` // RFC3339 UTC
+	Operation string  `json:"operation"` // "scan" | "watch"
+	Provider  string  `json:"provider"`
+	Model     string  `json:"model"`
+	Tokens    int64   `json:"tokens"`
+	CostUSD   float64 `json:"cost_usd"`
+}
+
+// MonthSpend aggregates the spend of one calendar month.
+type MonthSpend struct {
+	Month   string  `json:"month"` // YYYY-MM
+	Tokens  int64   `json:"tokens"`
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// CostPath returns the absolute path of the spend file.
+func CostPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ".grepai", CostFileName)
+}
+
+// TokenCost returns the cost in USD of tokens at pricePerMillion USD per
+// million tokens.
+func TokenCost(tokens int64, pricePerMillion float64) float64 {
+	return float64(tokens) * pricePerMillion / 1_000_000
+}
+
+// CostRecorder appends spend to the spend file of a project. A nil
+// *CostRecorder records nothing, which is how free providers are handled.
+type CostRecorder struct {
+	path     string
+	lockPath string
+	provider string
+	model    string
+	price    float64
+}
+
+// NewCostRecorder returns a recorder of the spend of projectRoot on model
+// of provider, priced at pricePerMillion USD per million tokens. It returns
+// nil for providers with no token cost or when there is no project.
+func NewCostRecorder(projectRoot, provider, model string, pricePerMillion float64) *CostRecorder {
+	if projectRoot == "" || !IsCloudProvider(provider) {
+		return nil
+	}
+	return &CostRecorder{
+		path:     CostPath(projectRoot),
+		lockPath: filepath.Join(projectRoot, ".grepai", CostLockFileName),
+		provider: provider,
+		model:    model,
+		price:    pricePerMillion,
+	}
+}
+
+// Cost returns the cost in USD of tokens at the recorder's price.
+func (r *CostRecorder) Cost(tokens int64) float64 {
+	if r == nil {
+		return 0
+	}
+	return TokenCost(tokens, r.price)
+}
+
+// Record appends the spend of tokens by operation. Nothing is recorded for
+// zero tokens.
+func (r *CostRecorder) Record(ctx context.Context, operation string, tokens int64) error {
+	if r == nil || tokens <= 0 {
+		return nil
+	}
+	s := Spend{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Operation: operation,
+		Provider:  r.provider,
+		Model:     r.model,
+		Tokens:    tokens,
+		CostUSD:   r.Cost(tokens),
+	}
+	if err := appendEntry(ctx, r.path, r.lockPath, s); err != nil {
+		return err
+	}
+	if info, err := os.Stat(r.path); err != nil || info.Size() < costRollupSize {
+		return nil
+	}
+	return rewriteEntries(r.path, r.lockPath, func(spend []Spend) []Spend {
+		return RollupSpend(spend, time.Now())
+	})
+}
+
+// RollupSpend sums the spend of each operation, provider and model per
+// month before now's month, per day before now's day and per hour before
+// now's hour, which bounds the size of the spend file while keeping its
+// totals per month. Entries with an unparseable timestamp are kept as is.
+func RollupSpend(spend []Spend, now time.Time) []Spend {
+	type key struct {
+		period                     time.Time
+		operation, provider, model string
+	}
+	var out []Spend
+	index := make(map[key]int)
+	for _, s := range spend {
+		t, err := time.Parse(time.RFC3339, s.Timestamp)
+		if err != nil {
+			out = append(out, s)
+			continue
+		}
+		period, ok := spendPeriod(t, now)
+		if !ok {
+			out = append(out, s)
+			continue
+		}
+		k := key{period, s.Operation, s.Provider, s.Model}
+		if i, ok := index[k]; ok {
+			out[i].Tokens += s.Tokens
+			out[i].CostUSD += s.CostUSD
+			continue
+		}
+		index[k] = len(out)
+		s.Timestamp = period.Format(time.RFC3339)
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Timestamp < out[j].Timestamp
+	})
+	return out
+}
+
+// spendPeriod returns the start of the period spend at t is rolled up into,
+// or false when it is kept as is.
+func spendPeriod(t, now time.Time) (time.Time, bool) {
+	t, now = t.UTC(), now.UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch {
+	case t.Before(month):
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), true
+	case t.Before(day):
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+	case t.Before(now.Truncate(time.Hour)):
+		return t.Truncate(time.Hour), true
+	}
+	return t, false
+}
+
+// ReadSpend reads all spend from the spend file at path.
+// Returns an empty slice (not an error) when the file does not exist.
+func ReadSpend(path string) ([]Spend, error) {
+	return readEntries[Spend](path)
+}
+
+// SummarizeSpendByMonth aggregates spend per calendar month, oldest first.
+// Entries with an unparseable timestamp are skipped.
+func SummarizeSpendByMonth(spend []Spend) []MonthSpend {
+	byMonth := make(map[string]*MonthSpend)
+	for _, s := range spend {
+		t, err := time.Parse(time.RFC3339, s.Timestamp)
+		if err != nil {
+			continue
+		}
+		month := t.UTC().Format("2006-01")
+		m, ok := byMonth[month]
+		if !ok {
+			m = &MonthSpend{Month: month}
+			byMonth[month] = m
+		}
+		m.Tokens += s.Tokens
+		m.CostUSD += s.CostUSD
+	}
+
+	months := make([]MonthSpend, 0, len(byMonth))
+	for _, m := range byMonth {
+		months = append(months, *m)
+	}
+	sort.Slice(months, func(i, j int) bool {
+		return months[i].Month < months[j].Month
+	})
+	return months
+}
@@ -0,0 +1,161 @@
+package stats_test
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/stats"
+)
+
+func TestCostRecorder_FreeProvider(t *testing.T) {
+	dir := t.TempDir()
+	rec := stats.NewCostRecorder(dir, "ollama", "nomic-embed-text", 0)
+	if rec != nil {
+		t.Fatal("expected a nil recorder for a free provider")
+	}
+	if err := rec.Record(context.Background(), "scan", 1000); err != nil {
+		t.Fatalf("nil recorder Record: %v", err)
+	}
+	spend, err := stats.ReadSpend(stats.CostPath(dir))
+	if err != nil {
+		t.Fatalf("ReadSpend: %v", err)
+	}
+	if len(spend) != 0 {
+		t.Errorf("expected no spend, got %d entries", len(spend))
+	}
+}
+
+func TestCostRecorder_RecordAndRead(t *testing.T) {
+	dir := t.TempDir()
+	rec := stats.NewCostRecorder(dir, "openai", "text-embedding-3-small", 0.02)
+	ctx := context.Background()
+	if err := rec.Record(ctx, "scan", 2_000_000); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := rec.Record(ctx, "watch", 0); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	spend, err := stats.ReadSpend(stats.CostPath(dir))
+	if err != nil {
+		t.Fatalf("ReadSpend: %v", err)
+	}
+	if len(spend) != 1 {
+		t.Fatalf("expected 1 entry (zero tokens are not recorded), got %d", len(spend))
+	}
+	s := spend[0]
+	if s.Operation != "scan" || s.Provider != "openai" || s.Model != "text-embedding-3-small" || s.Tokens != 2_000_000 {
+		t.Errorf("unexpected spend %+v", s)
+	}
+	if math.Abs(s.CostUSD-0.04) > 1e-9 {
+		t.Errorf("expected a cost of $0.04, got %v", s.CostUSD)
+	}
+}
+
+func TestSummarizeSpendByMonth(t *testing.T) {
+	spend := []stats.Spend{
+		{Timestamp: "2026-02-03T10:00:00Z", Tokens: 100, CostUSD: 0.5},
+		{Timestamp: "2026-01-31T23:00:00Z", Tokens: 10, CostUSD: 0.25},
+		{Timestamp: "2026-02-20T10:00:00Z", Tokens: 50, CostUSD: 0.25},
+		{Timestamp: "not a time", Tokens: 1000, CostUSD: 9},
+	}
+	months := stats.SummarizeSpendByMonth(spend)
+	if len(months) != 2 {
+		t.Fatalf("expected 2 months, got %+v", months)
+	}
+	if months[0].Month != "2026-01" || months[0].Tokens != 10 {
+		t.Errorf("unexpected first month %+v", months[0])
+	}
+	if months[1].Month != "2026-02" || months[1].Tokens != 150 || months[1].CostUSD != 0.75 {
+		t.Errorf("unexpected second month %+v", months[1])
+	}
+}
+
+func TestRollupSpend(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC)
+	spend := []stats.Spend{
+		{Timestamp: "2026-01-05T10:00:00Z", Operation: "watch", Provider: "openai", Model: "m", Tokens: 10, CostUSD: 0.1},
+		{Timestamp: "2026-01-20T10:00:00Z", Operation: "watch", Provider: "openai", Model: "m", Tokens: 20, CostUSD: 0.2},
+		{Timestamp: "2026-01-21T10:00:00Z", Operation: "scan", Provider: "openai", Model: "m", Tokens: 5, CostUSD: 0.05},
+		{Timestamp: "2026-03-09T08:00:00Z", Operation: "watch", Provider: "openai", Model: "m", Tokens: 1, CostUSD: 0.01},
+		{Timestamp: "2026-03-09T09:00:00Z", Operation: "watch", Provider: "openai", Model: "m", Tokens: 2, CostUSD: 0.02},
+		{Timestamp: "2026-03-10T12:10:00Z", Operation: "watch", Provider: "openai", Model: "m", Tokens: 3, CostUSD: 0.03},
+		{Timestamp: "2026-03-10T12:40:00Z", Operation: "watch", Provider: "openai", Model: "m", Tokens: 4, CostUSD: 0.04},
+		{Timestamp: "2026-03-10T14:05:00Z", Operation: "watch", Provider: "openai", Model: "m", Tokens: 6, CostUSD: 0.06},
+		{Timestamp: "2026-03-10T14:20:00Z", Operation: "watch", Provider: "openai", Model: "m", Tokens: 7, CostUSD: 0.07},
+		{Timestamp: "bogus", Operation: "watch", Tokens: 1},
+	}
+
+	got := stats.RollupSpend(spend, now)
+	want := []struct {
+		timestamp, operation string
+		tokens               int64
+	}{
+		{"2026-01-01T00:00:00Z", "watch", 30},
+		{"2026-01-01T00:00:00Z", "scan", 5},
+		{"2026-03-09T00:00:00Z", "watch", 3},
+		{"2026-03-10T12:00:00Z", "watch", 7},
+		{"2026-03-10T14:05:00Z", "watch", 6},
+		{"2026-03-10T14:20:00Z", "watch", 7},
+		{"bogus", "watch", 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].Timestamp != w.timestamp || got[i].Operation != w.operation || got[i].Tokens != w.tokens {
+			t.Errorf("entry %d: expected %+v, got %+v", i, w, got[i])
+		}
+	}
+
+	before := stats.SummarizeSpendByMonth(spend)
+	after := stats.SummarizeSpendByMonth(got)
+	if len(before) != len(after) {
+		t.Fatalf("expected monthly totals to be kept, got %+v and %+v", before, after)
+	}
+	for i := range before {
+		if before[i].Tokens != after[i].Tokens || math.Abs(before[i].CostUSD-after[i].CostUSD) > 1e-9 {
+			t.Errorf("month %s: expected %+v, got %+v", before[i].Month, before[i], after[i])
+		}
+	}
+}
+
+func TestCostRecorder_RollsUpLargeFile(t *testing.T) {
+	dir := t.TempDir()
+	rec := stats.NewCostRecorder(dir, "openai", "text-embedding-3-small", 0.02)
+	ctx := context.Background()
+
+	// Spend of a past month, large enough to trigger the roll-up.
+	var sb strings.Builder
+	for i := 0; i < 2000; i++ {
+		sb.WriteString(`{"timestamp":"2020-01-02T03:04:05Z","operation":"watch","provider":"openai","model":"text-embedding-3-small","tokens":10,"cost_usd":0.0002}` + "\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(stats.CostPath(dir)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stats.CostPath(dir), []byte(sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Record(ctx, "watch", 100); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	spend, err := stats.ReadSpend(stats.CostPath(dir))
+	if err != nil {
+		t.Fatalf("ReadSpend: %v", err)
+	}
+	if len(spend) != 2 {
+		t.Fatalf("expected the past month rolled up next to the new spend, got %d entries", len(spend))
+	}
+	if spend[0].Timestamp != "2020-01-01T00:00:00Z" || spend[0].Tokens != 20_000 {
+		t.Errorf("unexpected rolled up spend %+v", spend[0])
+	}
+	if spend[1].Tokens != 100 {
+		t.Errorf("unexpected new spend %+v", spend[1])
+	}
+}
@@ -0,0 +1,339 @@
+package stats
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// InsightsFileName is the name of the NDJSON search signals file inside
+// .grepai/.
+const InsightsFileName = "insights.json"
+
+// InsightsLockFileName is the name of the lock file of the search signals
+// file.
+const InsightsLockFileName = "insights.json.lock"
+
+// MissFollowUpWindow is how long after a search miss the files edited in the
+// project are taken as what the search was looking for.
+const MissFollowUpWindow = 30 * time.Minute
+
+// PriorityMaxAge is how long after its edit a file edited after a search
+// miss stays a re-index priority, unless it is re-indexed before.
+const PriorityMaxAge = 7 * 24 * time.Hour
+
+// InsightsRetention is how long search signals are kept.
+const InsightsRetention = 90 * 24 * time.Hour
+
+// MaxSignals is the number of most recent search signals kept.
+const MaxSignals = 5000
+
+// insightsPruneSize is the size in bytes past which the search signals file
+// is pruned by PruneSignals after a signal is recorded.
+const insightsPruneSize = 256 << 10
+
+// Search signal types.
+const (
+	SignalMiss    = "miss"    // a search whose results had a low confidence
+	SignalEdit    = "edit"    // a file edited after a miss
+	SignalReindex = "reindex" // a file re-indexed first after edits following misses
+)
+
+// SearchSignal is a search miss, or a file edited after one. Unlike other
+// stats, it holds the query.
+type SearchSignal struct {
+	Timestamp  string   `json:"timestamp"` // RFC3339 UTC
+	Type       string   `json:"type"`      // "miss" | "edit" | "reindex"
+	Query      string   `json:"query"`
+	Confidence float64  `json:"confidence,omitempty"` // miss: similarity of the nearest chunk
+	Results    []string `json:"results,omitempty"`    // miss: files of the results, best first
+	Path       string   `json:"path,omitempty"`       // edit, reindex: the file
+}
+
+// QueryMiss aggregates the misses of one query.
+type QueryMiss struct {
+	Query      string   `json:"query"`
+	Count      int      `json:"count"`
+	Confidence float64  `json:"confidence"` // average over the misses
+	LastSeen   string   `json:"last_seen"`
+	Edited     []string `json:"edited"` // files edited after the misses
+}
+
+// FilePriority is a file edited after search misses, which the index
+// likely represents poorly.
+type FilePriority struct {
+	Path   string `json:"path"`
+	Misses int    `json:"misses"` // queries that missed before the file was edited
+	Found  int    `json:"found"`  // of those, queries that had the file among their results
+}
+
+// Insights is the aggregated view of the search signals of a project.
+type Insights struct {
+	Misses []QueryMiss    `json:"misses"`
+	Files  []FilePriority `json:"files"`
+}
+
+// InsightsPath returns the absolute path of the search signals file.
+func InsightsPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ".grepai", InsightsFileName)
+}
+
+// InsightsRecorder appends search signals to the search signals file of a
+// project. A nil *InsightsRecorder records nothing, which is how disabled
+// insights are represented.
+type InsightsRecorder struct {
+	path     string
+	lockPath string
+}
+
+// NewInsightsRecorder returns a recorder writing to the search signals file
+// of projectRoot, or nil when enabled is false or there is no project.
+func NewInsightsRecorder(projectRoot string, enabled bool) *InsightsRecorder {
+	if !enabled || projectRoot == "" {
+		return nil
+	}
+	return &InsightsRecorder{
+		path:     InsightsPath(projectRoot),
+		lockPath: filepath.Join(projectRoot, ".grepai", InsightsLockFileName),
+	}
+}
+
+// Path returns the path of the file the recorder writes to.
+func (r *InsightsRecorder) Path() string {
+	if r == nil {
+		return ""
+	}
+	return r.path
+}
+
+// RecordMiss appends a miss of query, whose nearest chunk had a similarity
+// of confidence and whose results are in files.
+func (r *InsightsRecorder) RecordMiss(ctx context.Context, query string, confidence float64, files []string) error {
+	if r == nil {
+		return nil
+	}
+	return r.record(ctx, SearchSignal{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Type:       SignalMiss,
+		Query:      query,
+		Confidence: confidence,
+		Results:    files,
+	})
+}
+
+// RecordEdit appends the edit of path after a miss of query.
+func (r *InsightsRecorder) RecordEdit(ctx context.Context, query, path string) error {
+	if r == nil {
+		return nil
+	}
+	return r.record(ctx, SearchSignal{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Type:      SignalEdit,
+		Query:     query,
+		Path:      path,
+	})
+}
+
+// RecordReindex appends the re-indexing of paths, which ends their re-index
+// priority until they are edited after a miss again.
+func (r *InsightsRecorder) RecordReindex(ctx context.Context, paths []string) error {
+	if r == nil {
+		return nil
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, path := range paths {
+		if err := appendEntry(ctx, r.path, r.lockPath, SearchSignal{Timestamp: now, Type: SignalReindex, Path: path}); err != nil {
+			return err
+		}
+	}
+	return r.prune()
+}
+
+// record appends signal, then prunes the file when it grew large.
+func (r *InsightsRecorder) record(ctx context.Context, signal SearchSignal) error {
+	if err := appendEntry(ctx, r.path, r.lockPath, signal); err != nil {
+		return err
+	}
+	return r.prune()
+}
+
+// prune drops old signals once the file grew past insightsPruneSize.
+func (r *InsightsRecorder) prune() error {
+	if info, err := os.Stat(r.path); err != nil || info.Size() < insightsPruneSize {
+		return nil
+	}
+	return rewriteEntries(r.path, r.lockPath, func(signals []SearchSignal) []SearchSignal {
+		return PruneSignals(signals, time.Now())
+	})
+}
+
+// PruneSignals returns the signals recorded within InsightsRetention of now,
+// at most the MaxSignals most recent ones. Signals with an unparseable
+// timestamp are dropped.
+func PruneSignals(signals []SearchSignal, now time.Time) []SearchSignal {
+	since := now.Add(-InsightsRetention)
+	kept := make([]SearchSignal, 0, len(signals))
+	for _, s := range signals {
+		t, err := time.Parse(time.RFC3339, s.Timestamp)
+		if err != nil || t.Before(since) {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if len(kept) > MaxSignals {
+		kept = kept[len(kept)-MaxSignals:]
+	}
+	return kept
+}
+
+// ReadSignals reads all search signals from the file at path.
+// Returns an empty slice (not an error) when the file does not exist.
+func ReadSignals(path string) ([]SearchSignal, error) {
+	return readEntries[SearchSignal](path)
+}
+
+// RecentMisses returns the misses of signals recorded after since.
+func RecentMisses(signals []SearchSignal, since time.Time) []SearchSignal {
+	var recent []SearchSignal
+	for _, s := range signals {
+		if s.Type != SignalMiss {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s.Timestamp)
+		if err != nil || t.Before(since) {
+			continue
+		}
+		recent = append(recent, s)
+	}
+	return recent
+}
+
+// PriorityFiles returns the files edited after misses since since and not
+// re-indexed after their last such edit, edited after the most misses first.
+func PriorityFiles(signals []SearchSignal, since time.Time) []string {
+	edited := make(map[string]string) // path -> timestamp of the last edit
+	misses := make(map[string]map[string]bool)
+	reindexed := make(map[string]string)
+	for _, s := range signals {
+		if s.Path == "" {
+			continue
+		}
+		switch s.Type {
+		case SignalEdit:
+			t, err := time.Parse(time.RFC3339, s.Timestamp)
+			if err != nil || t.Before(since) {
+				continue
+			}
+			if s.Timestamp > edited[s.Path] {
+				edited[s.Path] = s.Timestamp
+			}
+			if misses[s.Path] == nil {
+				misses[s.Path] = make(map[string]bool)
+			}
+			misses[s.Path][s.Query] = true
+		case SignalReindex:
+			if s.Timestamp > reindexed[s.Path] {
+				reindexed[s.Path] = s.Timestamp
+			}
+		}
+	}
+
+	var files []string
+	for path, last := range edited {
+		// Timestamps have a one second resolution, so an edit recorded in
+		// the second of a re-index is taken as re-indexed.
+		if reindexed[path] >= last {
+			continue
+		}
+		files = append(files, path)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if len(misses[a]) != len(misses[b]) {
+			return len(misses[a]) > len(misses[b])
+		}
+		return a < b
+	})
+	return files
+}
+
+// SummarizeInsights aggregates signals per query, most frequent misses
+// first, and per edited file, files edited after the most misses first.
+func SummarizeInsights(signals []SearchSignal) Insights {
+	byQuery := make(map[string]*QueryMiss)
+	confidence := make(map[string]float64)
+	results := make(map[string]map[string]bool)
+	edited := make(map[string]map[string]bool)
+	for _, s := range signals {
+		switch s.Type {
+		case SignalMiss:
+			m, ok := byQuery[s.Query]
+			if !ok {
+				m = &QueryMiss{Query: s.Query}
+				byQuery[s.Query] = m
+				results[s.Query] = make(map[string]bool)
+			}
+			m.Count++
+			confidence[s.Query] += s.Confidence
+			if s.Timestamp > m.LastSeen {
+				m.LastSeen = s.Timestamp
+			}
+			for _, file := range s.Results {
+				results[s.Query][file] = true
+			}
+		case SignalEdit:
+			if s.Path == "" {
+				continue
+			}
+			if edited[s.Query] == nil {
+				edited[s.Query] = make(map[string]bool)
+			}
+			edited[s.Query][s.Path] = true
+		}
+	}
+
+	insights := Insights{Misses: make([]QueryMiss, 0, len(byQuery)), Files: []FilePriority{}}
+	files := make(map[string]*FilePriority)
+	for query, m := range byQuery {
+		m.Confidence = confidence[query] / float64(m.Count)
+		m.Edited = make([]string, 0, len(edited[query]))
+		for path := range edited[query] {
+			m.Edited = append(m.Edited, path)
+			f, ok := files[path]
+			if !ok {
+				f = &FilePriority{Path: path}
+				files[path] = f
+			}
+			f.Misses++
+			if results[query][path] {
+				f.Found++
+			}
+		}
+		sort.Strings(m.Edited)
+		insights.Misses = append(insights.Misses, *m)
+	}
+	sort.Slice(insights.Misses, func(i, j int) bool {
+		a, b := insights.Misses[i], insights.Misses[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.LastSeen != b.LastSeen {
+			return a.LastSeen > b.LastSeen
+		}
+		return a.Query < b.Query
+	})
+
+	for _, f := range files {
+		insights.Files = append(insights.Files, *f)
+	}
+	sort.Slice(insights.Files, func(i, j int) bool {
+		a, b := insights.Files[i], insights.Files[j]
+		if a.Misses != b.Misses {
+			return a.Misses > b.Misses
+		}
+		return a.Path < b.Path
+	})
+	return insights
+}
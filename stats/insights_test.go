@@ -0,0 +1,111 @@
+package stats_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/stats"
+)
+
+func TestInsightsRecorder_Disabled(t *testing.T) {
+	rec := stats.NewInsightsRecorder(t.TempDir(), false)
+	if rec != nil {
+		t.Fatal("expected a nil recorder when insights are disabled")
+	}
+	if err := rec.RecordMiss(context.Background(), "query", 0.1, nil); err != nil {
+		t.Fatalf("nil recorder RecordMiss: %v", err)
+	}
+}
+
+func TestInsightsRecorder_RecordAndSummarize(t *testing.T) {
+	dir := t.TempDir()
+	rec := stats.NewInsightsRecorder(dir, true)
+	ctx := context.Background()
+	for _, err := range []error{
+		rec.RecordMiss(ctx, "rate limiting", 0.3, []string{"api/limit.go", "api/server.go"}),
+		rec.RecordMiss(ctx, "rate limiting", 0.5, nil),
+		rec.RecordMiss(ctx, "session expiry", 0.2, []string{"auth/session.go"}),
+		rec.RecordEdit(ctx, "rate limiting", "api/limit.go"),
+		rec.RecordEdit(ctx, "session expiry", "api/limit.go"),
+		rec.RecordEdit(ctx, "session expiry", "auth/token.go"),
+	} {
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	signals, err := stats.ReadSignals(stats.InsightsPath(dir))
+	if err != nil {
+		t.Fatalf("ReadSignals: %v", err)
+	}
+	insights := stats.SummarizeInsights(signals)
+
+	if len(insights.Misses) != 2 {
+		t.Fatalf("expected 2 queries, got %+v", insights.Misses)
+	}
+	m := insights.Misses[0]
+	if m.Query != "rate limiting" || m.Count != 2 || m.Confidence != 0.4 || len(m.Edited) != 1 {
+		t.Errorf("unexpected first miss %+v", m)
+	}
+
+	if len(insights.Files) != 2 {
+		t.Fatalf("expected 2 files, got %+v", insights.Files)
+	}
+	f := insights.Files[0]
+	if f.Path != "api/limit.go" || f.Misses != 2 || f.Found != 1 {
+		t.Errorf("unexpected first file %+v", f)
+	}
+	if insights.Files[1].Path != "auth/token.go" || insights.Files[1].Found != 0 {
+		t.Errorf("unexpected second file %+v", insights.Files[1])
+	}
+}
+
+func TestRecentMisses(t *testing.T) {
+	now := time.Now().UTC()
+	signals := []stats.SearchSignal{
+		{Timestamp: now.Add(-time.Hour).Format(time.RFC3339), Type: stats.SignalMiss, Query: "old"},
+		{Timestamp: now.Format(time.RFC3339), Type: stats.SignalMiss, Query: "new"},
+		{Timestamp: now.Format(time.RFC3339), Type: stats.SignalEdit, Query: "new", Path: "a.go"},
+	}
+	recent := stats.RecentMisses(signals, now.Add(-stats.MissFollowUpWindow))
+	if len(recent) != 1 || recent[0].Query != "new" {
+		t.Errorf("expected the recent miss only, got %+v", recent)
+	}
+}
+
+func TestPriorityFiles(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) string { return now.Add(-d).Format(time.RFC3339) }
+	signals := []stats.SearchSignal{
+		{Timestamp: at(time.Hour), Type: stats.SignalEdit, Query: "q1", Path: "a.go"},
+		{Timestamp: at(time.Hour), Type: stats.SignalEdit, Query: "q2", Path: "a.go"},
+		{Timestamp: at(2 * time.Hour), Type: stats.SignalEdit, Query: "q1", Path: "b.go"},
+		{Timestamp: at(3 * time.Hour), Type: stats.SignalEdit, Query: "q1", Path: "c.go"},
+		{Timestamp: at(30 * time.Minute), Type: stats.SignalReindex, Path: "c.go"},
+		{Timestamp: at(10 * 24 * time.Hour), Type: stats.SignalEdit, Query: "q1", Path: "old.go"},
+	}
+
+	got := stats.PriorityFiles(signals, now.Add(-stats.PriorityMaxAge))
+	if strings.Join(got, ",") != "a.go,b.go" {
+		t.Errorf("expected recent, not re-indexed files by misses, got %v", got)
+	}
+}
+
+func TestPruneSignals(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	signals := []stats.SearchSignal{
+		{Timestamp: now.Add(-stats.InsightsRetention - time.Hour).Format(time.RFC3339), Type: stats.SignalMiss, Query: "old"},
+		{Timestamp: "bogus", Type: stats.SignalMiss, Query: "bogus"},
+	}
+	for i := 0; i < stats.MaxSignals+1; i++ {
+		signals = append(signals, stats.SearchSignal{Timestamp: now.Format(time.RFC3339), Type: stats.SignalMiss, Query: fmt.Sprint(i)})
+	}
+
+	got := stats.PruneSignals(signals, now)
+	if len(got) != stats.MaxSignals || got[0].Query != "1" {
+		t.Errorf("expected the %d most recent signals, got %d starting with %q", stats.MaxSignals, len(got), got[0].Query)
+	}
+}
@@ -15,7 +15,12 @@ import (
 // Malformed lines are skipped with a warning to stderr.
 // Returns an empty slice (not an error) when the file does not exist.
 func ReadAll(statsPath string) ([]Entry, error) {
-	f, err := os.Open(statsPath)
+	return readEntries[Entry](statsPath)
+}
+
+// readEntries reads the NDJSON file at path as entries of type T.
+func readEntries[T any](path string) ([]T, error) {
+	f, err := os.Open(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return nil, nil
@@ -24,7 +29,7 @@ func ReadAll(statsPath string) ([]Entry, error) {
 	}
 	defer f.Close()
 
-	var entries []Entry
+	var entries []T
 	scanner := bufio.NewScanner(f)
 	lineNum := 0
 	for scanner.Scan() {
@@ -33,7 +38,7 @@ func ReadAll(statsPath string) ([]Entry, error) {
 		if line == "" {
 			continue
 		}
-		var e Entry
+		var e T
 		if err := json.Unmarshal([]byte(line), &e); err != nil {
 			fmt.Fprintf(os.Stderr, "stats: skipping malformed line %d: %v\n", lineNum, err)
 			continue
@@ -1,6 +1,7 @@
 package stats
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -27,38 +28,44 @@ func NewRecorder(projectRoot string) *Recorder {
 // If the context is canceled or the write fails, the error is returned
 // but the caller is expected to discard it (fire-and-forget pattern).
 func (r *Recorder) Record(ctx context.Context, e Entry) error {
+	return appendEntry(ctx, r.statsPath, r.lockPath, e)
+}
+
+// appendEntry appends v as one JSON line to path under the lock file at
+// lockPath.
+func appendEntry(ctx context.Context, path, lockPath string, v any) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	line, err := json.Marshal(e)
+	line, err := json.Marshal(v)
 	if err != nil {
 		return fmt.Errorf("stats: marshal entry: %w", err)
 	}
 	line = append(line, '\n')
 
-	lockFile, err := os.OpenFile(r.lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
 	if err != nil {
 		// Proceed without locking rather than failing the caller.
-		return r.appendLine(line)
+		return appendLine(path, line)
 	}
 	defer lockFile.Close()
 
 	if err := flockExclusive(lockFile); err != nil {
-		return r.appendLine(line)
+		return appendLine(path, line)
 	}
 	defer func() { _ = funlock(lockFile) }()
 
-	return r.appendLine(line)
+	return appendLine(path, line)
 }
 
-func (r *Recorder) appendLine(line []byte) error {
-	if err := os.MkdirAll(filepath.Dir(r.statsPath), 0o755); err != nil {
+func appendLine(path string, line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return fmt.Errorf("stats: create dir: %w", err)
 	}
-	f, err := os.OpenFile(r.statsPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
 	if err != nil {
 		return fmt.Errorf("stats: open file: %w", err)
 	}
@@ -67,3 +74,50 @@ func (r *Recorder) appendLine(line []byte) error {
 	_, err = f.Write(line)
 	return err
 }
+
+// rewriteEntries replaces the entries of the NDJSON file at path with
+// rewrite's result under the lock file at lockPath. The file is replaced
+// atomically, so readers see either the old or the new entries.
+func rewriteEntries[T any](path, lockPath string, rewrite func([]T) []T) error {
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("stats: open lock: %w", err)
+	}
+	defer lockFile.Close()
+	if err := flockExclusive(lockFile); err != nil {
+		return fmt.Errorf("stats: lock: %w", err)
+	}
+	defer func() { _ = funlock(lockFile) }()
+
+	entries, err := readEntries[T](path)
+	if err != nil {
+		return err
+	}
+	entries = rewrite(entries)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("stats: create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	w := bufio.NewWriter(tmp)
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("stats: marshal entry: %w", err)
+		}
+		_, _ = w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("stats: write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("stats: write: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("stats: replace file: %w", err)
+	}
+	return nil
+}
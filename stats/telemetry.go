@@ -0,0 +1,167 @@
+package stats
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// TelemetryFileName is the name of the NDJSON telemetry file inside .grepai/.
+const TelemetryFileName = "telemetry.json"
+
+// TelemetryLockFileName is the name of the lock file of the telemetry file.
+const TelemetryLockFileName = "telemetry.json.lock"
+
+// TelemetryRetention is how long timings are kept.
+const TelemetryRetention = 30 * 24 * time.Hour
+
+// MaxTimings is the number of most recent timings kept.
+const MaxTimings = 20000
+
+// telemetryPruneSize is the size in bytes past which the telemetry file is
+// pruned by PruneTimings after a timing is recorded.
+const telemetryPruneSize = 1 << 20
+
+// Timing is one timed operation recorded by opt-in telemetry. It holds no
+// query, path or other project content.
+type Timing struct {
+	Timestamp  string  `json:"timestamp"` // RFC3339 UTC
+	Operation  string  `json:"operation"` // e.g. "cli.search", "watch.index_file", "mcp.grepai_search"
+	DurationMs float64 `json:"duration_ms"`
+	Failed     bool    `json:"failed,omitempty"`
+}
+
+// OperationSummary aggregates the timings of one operation.
+type OperationSummary struct {
+	Operation string  `json:"operation"`
+	Count     int     `json:"count"`
+	Failures  int     `json:"failures"`
+	AvgMs     float64 `json:"avg_ms"`
+	P50Ms     float64 `json:"p50_ms"`
+	P95Ms     float64 `json:"p95_ms"`
+	MaxMs     float64 `json:"max_ms"`
+}
+
+// TelemetryPath returns the absolute path of the telemetry file.
+func TelemetryPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ".grepai", TelemetryFileName)
+}
+
+// TelemetryRecorder appends timings to the local telemetry file. It never
+// sends anything over the network. A nil *TelemetryRecorder records nothing,
+// which is how disabled telemetry is represented.
+type TelemetryRecorder struct {
+	path     string
+	lockPath string
+}
+
+// NewTelemetryRecorder returns a recorder writing to the telemetry file of
+// projectRoot, or nil when enabled is false or there is no project.
+func NewTelemetryRecorder(projectRoot string, enabled bool) *TelemetryRecorder {
+	if !enabled || projectRoot == "" {
+		return nil
+	}
+	return &TelemetryRecorder{
+		path:     TelemetryPath(projectRoot),
+		lockPath: filepath.Join(projectRoot, ".grepai", TelemetryLockFileName),
+	}
+}
+
+// Record appends the timing of operation, which started at start and
+// failed when failed is set.
+func (r *TelemetryRecorder) Record(ctx context.Context, operation string, start time.Time, failed bool) error {
+	if r == nil {
+		return nil
+	}
+	t := Timing{
+		Timestamp:  start.UTC().Format(time.RFC3339),
+		Operation:  operation,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		Failed:     failed,
+	}
+	if err := appendEntry(ctx, r.path, r.lockPath, t); err != nil {
+		return err
+	}
+	return r.prune()
+}
+
+// prune drops old timings once the file grew past telemetryPruneSize.
+func (r *TelemetryRecorder) prune() error {
+	if info, err := os.Stat(r.path); err != nil || info.Size() < telemetryPruneSize {
+		return nil
+	}
+	return rewriteEntries(r.path, r.lockPath, func(timings []Timing) []Timing {
+		return PruneTimings(timings, time.Now())
+	})
+}
+
+// PruneTimings returns the timings recorded within TelemetryRetention of
+// now, at most the MaxTimings most recent ones. Timings with an unparseable
+// timestamp are dropped.
+func PruneTimings(timings []Timing, now time.Time) []Timing {
+	since := now.Add(-TelemetryRetention)
+	kept := make([]Timing, 0, len(timings))
+	for _, t := range timings {
+		ts, err := time.Parse(time.RFC3339, t.Timestamp)
+		if err != nil || ts.Before(since) {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	if len(kept) > MaxTimings {
+		kept = kept[len(kept)-MaxTimings:]
+	}
+	return kept
+}
+
+// ReadTimings reads all timings from the telemetry file at path.
+// Returns an empty slice (not an error) when the file does not exist.
+func ReadTimings(path string) ([]Timing, error) {
+	return readEntries[Timing](path)
+}
+
+// SummarizeTimings aggregates timings per operation, slowest average first.
+func SummarizeTimings(timings []Timing) []OperationSummary {
+	durations := make(map[string][]float64)
+	failures := make(map[string]int)
+	for _, t := range timings {
+		durations[t.Operation] = append(durations[t.Operation], t.DurationMs)
+		if t.Failed {
+			failures[t.Operation]++
+		}
+	}
+
+	summaries := make([]OperationSummary, 0, len(durations))
+	for op, ds := range durations {
+		sort.Float64s(ds)
+		total := 0.0
+		for _, d := range ds {
+			total += d
+		}
+		summaries = append(summaries, OperationSummary{
+			Operation: op,
+			Count:     len(ds),
+			Failures:  failures[op],
+			AvgMs:     total / float64(len(ds)),
+			P50Ms:     percentile(ds, 50),
+			P95Ms:     percentile(ds, 95),
+			MaxMs:     ds[len(ds)-1],
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].AvgMs != summaries[j].AvgMs {
+			return summaries[i].AvgMs > summaries[j].AvgMs
+		}
+		return summaries[i].Operation < summaries[j].Operation
+	})
+	return summaries
+}
+
+// percentile returns the nearest-rank percentile p of sorted.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,95 @@
+package stats_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/stats"
+)
+
+func TestTelemetryRecorder_Disabled(t *testing.T) {
+	dir := t.TempDir()
+	rec := stats.NewTelemetryRecorder(dir, false)
+	if rec != nil {
+		t.Fatal("expected a nil recorder when telemetry is disabled")
+	}
+	if err := rec.Record(context.Background(), "cli.search", time.Now(), false); err != nil {
+		t.Fatalf("nil recorder Record: %v", err)
+	}
+	timings, err := stats.ReadTimings(stats.TelemetryPath(dir))
+	if err != nil {
+		t.Fatalf("ReadTimings: %v", err)
+	}
+	if len(timings) != 0 {
+		t.Errorf("expected no timings, got %d", len(timings))
+	}
+}
+
+func TestTelemetryRecorder_RecordAndRead(t *testing.T) {
+	dir := t.TempDir()
+	rec := stats.NewTelemetryRecorder(dir, true)
+	ctx := context.Background()
+	start := time.Now().Add(-20 * time.Millisecond)
+	if err := rec.Record(ctx, "cli.search", start, false); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := rec.Record(ctx, "cli.search", start, true); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	timings, err := stats.ReadTimings(stats.TelemetryPath(dir))
+	if err != nil {
+		t.Fatalf("ReadTimings: %v", err)
+	}
+	if len(timings) != 2 {
+		t.Fatalf("expected 2 timings, got %d", len(timings))
+	}
+	if timings[0].Operation != "cli.search" || timings[0].DurationMs < 20 {
+		t.Errorf("unexpected timing %+v", timings[0])
+	}
+	if timings[0].Failed || !timings[1].Failed {
+		t.Errorf("expected only the second timing to fail, got %+v", timings)
+	}
+}
+
+func TestSummarizeTimings(t *testing.T) {
+	var timings []stats.Timing
+	for i := 1; i <= 20; i++ {
+		timings = append(timings, stats.Timing{Operation: "cli.search", DurationMs: float64(i * 10)})
+	}
+	timings = append(timings,
+		stats.Timing{Operation: "watch.full_scan", DurationMs: 5000},
+		stats.Timing{Operation: "watch.full_scan", DurationMs: 3000, Failed: true},
+	)
+
+	summaries := stats.SummarizeTimings(timings)
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 operations, got %d", len(summaries))
+	}
+	scan := summaries[0]
+	if scan.Operation != "watch.full_scan" || scan.Count != 2 || scan.Failures != 1 || scan.AvgMs != 4000 || scan.MaxMs != 5000 {
+		t.Errorf("unexpected full scan summary %+v", scan)
+	}
+	search := summaries[1]
+	if search.P50Ms != 100 || search.P95Ms != 190 || search.MaxMs != 200 || search.AvgMs != 105 {
+		t.Errorf("unexpected search summary %+v", search)
+	}
+}
+
+func TestPruneTimings(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	timings := []stats.Timing{
+		{Timestamp: now.Add(-stats.TelemetryRetention - time.Hour).Format(time.RFC3339), Operation: "old"},
+		{Timestamp: "bogus", Operation: "bogus"},
+	}
+	for i := 0; i < stats.MaxTimings+1; i++ {
+		timings = append(timings, stats.Timing{Timestamp: now.Format(time.RFC3339), Operation: fmt.Sprint(i)})
+	}
+
+	got := stats.PruneTimings(timings, now)
+	if len(got) != stats.MaxTimings || got[0].Operation != "1" {
+		t.Errorf("expected the %d most recent timings, got %d starting with %q", stats.MaxTimings, len(got), got[0].Operation)
+	}
+}
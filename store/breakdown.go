@@ -0,0 +1,74 @@
+package store
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
+)
+
+// RootGroup names the group of files at the top of the index, outside any
+// directory.
+const RootGroup = "."
+
+// FileLanguage returns the language of the file at filePath as derived from
+// its name or extension, or "other" when it has no extension.
+func FileLanguage(filePath string) string {
+	ext := langdetect.NameExt(filePath)
+	if ext == "" {
+		ext = strings.ToLower(path.Ext(filePath))
+	}
+	if lang := langdetect.Language(ext); lang != "" {
+		return lang
+	}
+	if ext == "" || ext == "." {
+		return "other"
+	}
+	return ext[1:]
+}
+
+// TopLevelDir returns the first directory of the slash-separated filePath,
+// or RootGroup for files at the top.
+func TopLevelDir(filePath string) string {
+	filePath = strings.TrimLeft(filePath, "/")
+	if idx := strings.Index(filePath, "/"); idx > 0 {
+		return filePath[:idx]
+	}
+	return RootGroup
+}
+
+// AddBreakdown sets Directories and Languages from files, as returned by
+// ListFilesWithStats. Groups are sorted by chunk count, largest first.
+func (s *IndexStats) AddBreakdown(files []FileStats) {
+	s.Directories = groupFiles(files, TopLevelDir)
+	s.Languages = groupFiles(files, FileLanguage)
+}
+
+func groupFiles(files []FileStats, key func(string) string) []GroupStats {
+	byName := make(map[string]*GroupStats)
+	var groups []*GroupStats
+	for _, f := range files {
+		name := key(f.Path)
+		g, ok := byName[name]
+		if !ok {
+			g = &GroupStats{Name: name}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		g.Files++
+		g.Chunks += f.ChunkCount
+	}
+
+	out := make([]GroupStats, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Chunks != out[j].Chunks {
+			return out[i].Chunks > out[j].Chunks
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFileLanguage(t *testing.T) {
+	tests := map[string]string{
+		"cli/status.go":        "go",
+		"web/App.TSX":          "typescript",
+		"include/util.h":       "c",
+		"docs/config.yml":      "yaml",
+		"scripts/build.gradle": "gradle",
+		"Makefile":             "make",
+		"deploy/Dockerfile":    "dockerfile",
+		"LICENSE":              "other",
+		"src/.env":             "env",
+	}
+	for input, want := range tests {
+		if got := FileLanguage(input); got != want {
+			t.Errorf("FileLanguage(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestTopLevelDir(t *testing.T) {
+	tests := map[string]string{
+		"cli/status.go":  "cli",
+		"/abs/path/x.go": "abs",
+		"main.go":        RootGroup,
+	}
+	for input, want := range tests {
+		if got := TopLevelDir(input); got != want {
+			t.Errorf("TopLevelDir(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestIndexStatsAddBreakdown(t *testing.T) {
+	stats := &IndexStats{}
+	stats.AddBreakdown([]FileStats{
+		{Path: "cli/status.go", ChunkCount: 4},
+		{Path: "cli/README.md", ChunkCount: 1},
+		{Path: "store/gob.go", ChunkCount: 6},
+		{Path: "main.go", ChunkCount: 1},
+	})
+
+	wantDirs := []GroupStats{
+		{Name: "store", Files: 1, Chunks: 6},
+		{Name: "cli", Files: 2, Chunks: 5},
+		{Name: RootGroup, Files: 1, Chunks: 1},
+	}
+	if !reflect.DeepEqual(stats.Directories, wantDirs) {
+		t.Errorf("unexpected directories: %+v", stats.Directories)
+	}
+	wantLangs := []GroupStats{
+		{Name: "go", Files: 3, Chunks: 11},
+		{Name: "markdown", Files: 1, Chunks: 1},
+	}
+	if !reflect.DeepEqual(stats.Languages, wantLangs) {
+		t.Errorf("unexpected languages: %+v", stats.Languages)
+	}
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// NewFromConfig opens the vector store configured in cfg for the project at
+// projectRoot. A gob index is loaded from disk. This factory function
+// centralizes backend initialization for CLI commands, the MCP server and
+// the client package.
+func NewFromConfig(ctx context.Context, cfg *config.Config, projectRoot string) (VectorStore, error) {
+	dimensions := cfg.Embedder.GetDimensions()
+	switch cfg.Store.Backend {
+	case "gob":
+		gobStore := NewGOBStore(config.GetIndexPath(projectRoot))
+		gobStore.SetLazyContent(cfg.Store.GOB.LazyContent)
+		if err := gobStore.Load(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load index: %w", err)
+		}
+		return gobStore, nil
+	case "postgres":
+		st, err := NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, dimensions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+		}
+		return st, nil
+	}
+	return newServerStore(ctx, cfg.Store, projectRoot, dimensions)
+}
+
+// NewFromWorkspaceConfig opens the vector store shared by the projects of
+// ws. Workspaces need a server backend; gob is not supported.
+func NewFromWorkspaceConfig(ctx context.Context, ws *config.Workspace) (VectorStore, error) {
+	dimensions := ws.Embedder.GetDimensions()
+	switch ws.Store.Backend {
+	case "postgres":
+		st, err := NewPostgresStore(ctx, ws.Store.Postgres.DSN, "workspace:"+ws.Name, dimensions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+		}
+		return st, nil
+	case "qdrant", "weaviate", "redis", "opensearch":
+		return newServerStore(ctx, ws.Store, "workspace_"+ws.Name, dimensions)
+	default:
+		return nil, fmt.Errorf("unsupported backend for workspace: %s", ws.Store.Backend)
+	}
+}
+
+// newServerStore opens the Qdrant, Weaviate, Redis or OpenSearch store of
+// cfg. Collection, class and index names default to ones derived from name.
+func newServerStore(ctx context.Context, cfg config.StoreConfig, name string, dimensions int) (VectorStore, error) {
+	switch cfg.Backend {
+	case "qdrant":
+		collectionName := cfg.Qdrant.Collection
+		if collectionName == "" {
+			collectionName = SanitizeCollectionName(name)
+		}
+		st, err := NewQdrantStore(ctx, cfg.Qdrant.Endpoint, cfg.Qdrant.Port, cfg.Qdrant.TLSEnabled(), collectionName, cfg.Qdrant.APIKey, dimensions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to qdrant: %w", err)
+		}
+		return st, nil
+	case "weaviate":
+		className := cfg.Weaviate.Class
+		if className == "" {
+			className = WeaviateClassName(name)
+		}
+		st, err := NewWeaviateStore(ctx, cfg.Weaviate.Endpoint, cfg.Weaviate.APIKey, className, dimensions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to weaviate: %w", err)
+		}
+		return st, nil
+	case "redis":
+		indexName := cfg.Redis.Index
+		if indexName == "" {
+			indexName = RedisIndexName(name)
+		}
+		st, err := NewRedisStore(ctx, cfg.Redis.URL, indexName, dimensions, cfg.Redis.TTL())
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		return st, nil
+	case "opensearch":
+		indexName := cfg.OpenSearch.Index
+		if indexName == "" {
+			indexName = OpenSearchIndexName(name)
+		}
+		st, err := NewOpenSearchStore(ctx, cfg.OpenSearch.Endpoint, cfg.OpenSearch.Username, cfg.OpenSearch.Password, indexName, dimensions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to opensearch: %w", err)
+		}
+		return st, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestNewFromConfigUnknownBackend(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Store.Backend = "unknown"
+
+	_, err := NewFromConfig(context.Background(), cfg, t.TempDir())
+	if err == nil {
+		t.Fatal("NewFromConfig() should fail for unknown backend")
+	}
+	if !strings.Contains(err.Error(), "unknown storage backend") {
+		t.Fatalf("NewFromConfig() error = %q, want message containing %q", err.Error(), "unknown storage backend")
+	}
+}
+
+func TestNewFromConfigGOB(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Store.Backend = "gob"
+
+	st, err := NewFromConfig(context.Background(), cfg, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	defer st.Close()
+	if _, ok := st.(*GOBStore); !ok {
+		t.Fatalf("NewFromConfig() = %T, want *GOBStore", st)
+	}
+}
+
+func TestNewFromWorkspaceConfigUnsupportedBackend(t *testing.T) {
+	ws := &config.Workspace{
+		Name: "ws",
+		Store: config.StoreConfig{
+			Backend: "gob",
+		},
+	}
+
+	_, err := NewFromWorkspaceConfig(context.Background(), ws)
+	if err == nil {
+		t.Fatal("NewFromWorkspaceConfig() should fail for unsupported backend")
+	}
+	if !strings.Contains(err.Error(), "unsupported backend for workspace") {
+		t.Fatalf("NewFromWorkspaceConfig() error = %q, want message containing %q", err.Error(), "unsupported backend for workspace")
+	}
+}
@@ -1,17 +1,22 @@
 package store
 
 import (
+	"bufio"
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/yoanbernabeu/grepai/internal/fileutil"
+	"github.com/yoanbernabeu/grepai/internal/indexformat"
 )
 
 type GOBStore struct {
@@ -20,11 +25,86 @@ type GOBStore struct {
 	chunks    map[string]Chunk    // id -> chunk
 	documents map[string]Document // path -> document
 	mu        sync.RWMutex
+	tooNew    error // see indexformat.ErrTooNew
+
+	// lazy keeps chunk content on disk after Load. spans then locates the
+	// content of the loaded chunks in the content section of content,
+	// which starts at contentBase; chunks saved since hold their content.
+	lazy        bool
+	spans       map[string]contentSpan
+	content     *os.File
+	contentBase int64
+}
+
+// gobFormatVersion is the format version of the files GOBStore writes.
+// Files written before formats were versioned decode as version 0.
+//
+// From version 2 on, chunks are written without their content, which
+// follows the gob value as a content section located by gobData.Content.
+// Version 1 chunks hold their content and have no spans, which Load reads
+// the same way, so no upgrade step is needed.
+//
+// From version 3 on, chunk IDs end with the start of the content hash (see
+// indexer.ChunkID).
+const gobFormatVersion = 3
+
+// gobUpgrades migrate older index files on load, keyed by the version they
+// upgrade from. Version 0 files only lack FormatVersion.
+var gobUpgrades = map[int]indexformat.Upgrade[gobData]{
+	2: hashChunkIDs,
+}
+
+// chunkIDHashLen is how many hex digits of the content hash a chunk ID
+// keeps, as in indexer.ChunkID.
+const chunkIDHashLen = 12
+
+// hashChunkIDs appends the content hash to chunk IDs written before IDs
+// carried it, so chunks keep matching the IDs the indexer now derives.
+func hashChunkIDs(data *gobData) error {
+	renamed := make(map[string]string)
+	for id, chunk := range data.Chunks {
+		if len(chunk.ContentHash) < chunkIDHashLen {
+			continue
+		}
+		suffix := "_" + chunk.ContentHash[:chunkIDHashLen]
+		if strings.HasSuffix(id, suffix) {
+			continue
+		}
+		renamed[id] = id + suffix
+	}
+	for oldID, newID := range renamed {
+		chunk := data.Chunks[oldID]
+		delete(data.Chunks, oldID)
+		chunk.ID = newID
+		data.Chunks[newID] = chunk
+		if span, ok := data.Content[oldID]; ok {
+			delete(data.Content, oldID)
+			data.Content[newID] = span
+		}
+	}
+	for path, doc := range data.Documents {
+		for i, id := range doc.ChunkIDs {
+			if newID, ok := renamed[id]; ok {
+				doc.ChunkIDs[i] = newID
+			}
+		}
+		data.Documents[path] = doc
+	}
+	return nil
 }
 
 type gobData struct {
-	Chunks    map[string]Chunk
-	Documents map[string]Document
+	FormatVersion int
+	Chunks        map[string]Chunk
+	Documents     map[string]Document
+	Content       map[string]contentSpan
+}
+
+// contentSpan locates the content of a chunk in the content section of an
+// index file, relative to the start of the section.
+type contentSpan struct {
+	Offset int64
+	Length int64
 }
 
 func NewGOBStore(indexPath string) *GOBStore {
@@ -33,34 +113,105 @@ func NewGOBStore(indexPath string) *GOBStore {
 		lockPath:  indexPath + ".lock",
 		chunks:    make(map[string]Chunk),
 		documents: make(map[string]Document),
+		spans:     make(map[string]contentSpan),
 	}
 }
 
+// SetLazyContent makes Load leave chunk content on disk, so that a loaded
+// index holds little more than its vectors and content is read only for
+// the chunks a call returns. It must be called before Load.
+func (s *GOBStore) SetLazyContent(lazy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lazy = lazy
+}
+
 func (s *GOBStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	for _, chunk := range chunks {
 		s.chunks[chunk.ID] = chunk
+		delete(s.spans, chunk.ID)
 	}
 
 	return nil
 }
 
+// ReplaceFile swaps a file's chunks and document under one lock, so readers
+// and the next Persist see either the old file or the new one.
+func (s *GOBStore) ReplaceFile(ctx context.Context, doc Document, chunks []Chunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteFileChunks(doc.Path)
+	for _, chunk := range chunks {
+		s.chunks[chunk.ID] = chunk
+		delete(s.spans, chunk.ID)
+	}
+	s.documents[doc.Path] = doc
+	return nil
+}
+
 func (s *GOBStore) DeleteByFile(ctx context.Context, filePath string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.deleteFileChunks(filePath)
+	return nil
+}
+
+func (s *GOBStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, filePath := range filePaths {
+		s.deleteFileChunks(filePath)
+	}
+	return nil
+}
+
+func (s *GOBStore) DeleteChunks(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.chunks, id)
+		delete(s.spans, id)
+	}
+	return nil
+}
+
+func (s *GOBStore) DeleteByProject(ctx context.Context, project string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := projectPrefix(project)
+	for id, chunk := range s.chunks {
+		if strings.HasPrefix(chunk.FilePath, prefix) {
+			delete(s.chunks, id)
+			delete(s.spans, id)
+		}
+	}
+	for path := range s.documents {
+		if strings.HasPrefix(path, prefix) {
+			delete(s.documents, path)
+		}
+	}
+	return nil
+}
+
+// deleteFileChunks removes the chunks of a file's document.
+func (s *GOBStore) deleteFileChunks(filePath string) {
 	doc, ok := s.documents[filePath]
 	if !ok {
-		return nil
+		return
 	}
 
 	for _, chunkID := range doc.ChunkIDs {
 		delete(s.chunks, chunkID)
+		delete(s.spans, chunkID)
 	}
-
-	return nil
 }
 
 func (s *GOBStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
@@ -74,6 +225,9 @@ func (s *GOBStore) Search(ctx context.Context, queryVector []float32, limit int,
 		if opts.PathPrefix != "" && !strings.HasPrefix(chunk.FilePath, opts.PathPrefix) {
 			continue
 		}
+		if !opts.keepsModel(chunk.Model) {
+			continue
+		}
 		score := cosineSimilarity(queryVector, chunk.Vector)
 		results = append(results, SearchResult{
 			Chunk: chunk,
@@ -90,9 +244,29 @@ func (s *GOBStore) Search(ctx context.Context, queryVector []float32, limit int,
 		results = results[:limit]
 	}
 
+	for i := range results {
+		if err := s.fillContent(&results[i].Chunk); err != nil {
+			return nil, err
+		}
+	}
+
 	return results, nil
 }
 
+// fillContent reads the content of a chunk loaded lazily.
+func (s *GOBStore) fillContent(chunk *Chunk) error {
+	span, ok := s.spans[chunk.ID]
+	if !ok || s.content == nil {
+		return nil
+	}
+	buf := make([]byte, span.Length)
+	if _, err := s.content.ReadAt(buf, s.contentBase+span.Offset); err != nil {
+		return fmt.Errorf("failed to read content of chunk %s: %w", chunk.ID, err)
+	}
+	chunk.Content = string(buf)
+	return nil
+}
+
 func (s *GOBStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -165,16 +339,32 @@ func (s *GOBStore) loadUnlocked() error {
 		}
 		return fmt.Errorf("failed to open index file: %w", err)
 	}
-	defer file.Close()
+	keepOpen := false
+	defer func() {
+		if !keepOpen {
+			file.Close()
+		}
+	}()
 
+	// The decoder reads through a byte reader, so it does not read ahead
+	// and the count is where the content section starts.
+	reader := &countingReader{r: bufio.NewReader(file)}
 	var data gobData
-	decoder := gob.NewDecoder(file)
+	decoder := gob.NewDecoder(reader)
 	if err := decoder.Decode(&data); err != nil {
 		return fmt.Errorf("failed to decode index: %w", err)
 	}
+	if err := indexformat.Migrate(s.indexPath, &data, data.FormatVersion, gobFormatVersion, gobUpgrades); err != nil {
+		if errors.Is(err, indexformat.ErrTooNew) {
+			s.tooNew = err
+		}
+		return err
+	}
 
+	s.closeContent()
 	s.chunks = data.Chunks
 	s.documents = data.Documents
+	s.spans = data.Content
 
 	if s.chunks == nil {
 		s.chunks = make(map[string]Chunk)
@@ -182,13 +372,44 @@ func (s *GOBStore) loadUnlocked() error {
 	if s.documents == nil {
 		s.documents = make(map[string]Document)
 	}
+	if s.spans == nil {
+		s.spans = make(map[string]contentSpan)
+	}
+
+	if s.lazy && len(s.spans) > 0 {
+		s.content, s.contentBase = file, reader.n
+		keepOpen = true
+		return nil
+	}
+
+	section, err := io.ReadAll(reader.r)
+	if err != nil {
+		return fmt.Errorf("failed to read index content: %w", err)
+	}
+	for id, span := range s.spans {
+		if span.Offset+span.Length > int64(len(section)) {
+			return fmt.Errorf("failed to read content of chunk %s: index file is truncated", id)
+		}
+		chunk := s.chunks[id]
+		chunk.Content = string(section[span.Offset : span.Offset+span.Length])
+		s.chunks[id] = chunk
+	}
+	s.spans = make(map[string]contentSpan)
 
 	return nil
 }
 
+// closeContent closes the file lazily loaded content is read from.
+func (s *GOBStore) closeContent() {
+	if s.content != nil {
+		_ = s.content.Close()
+		s.content = nil
+	}
+}
+
 func (s *GOBStore) Persist(ctx context.Context) error {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	if err := fileutil.EnsureParentDir(s.indexPath); err != nil {
 		return fmt.Errorf("failed to prepare index directory: %w", err)
@@ -213,29 +434,133 @@ func (s *GOBStore) Persist(ctx context.Context) error {
 	return s.persistUnlocked()
 }
 
-// persistUnlocked performs the actual persist without any locking.
+// persistUnlocked performs the actual persist without any locking. The file
+// is written to a temporary file and moved into place, so that stores
+// reading content lazily from the previous file are not disturbed.
 func (s *GOBStore) persistUnlocked() error {
-	file, err := os.Create(s.indexPath)
-	if err != nil {
-		return fmt.Errorf("failed to create index file: %w", err)
+	if s.tooNew != nil {
+		return s.tooNew
+	}
+
+	ids := make([]string, 0, len(s.chunks))
+	for id := range s.chunks {
+		ids = append(ids, id)
 	}
-	defer file.Close()
+	sort.Strings(ids)
 
 	data := gobData{
-		Chunks:    s.chunks,
-		Documents: s.documents,
+		FormatVersion: gobFormatVersion,
+		Chunks:        make(map[string]Chunk, len(s.chunks)),
+		Documents:     s.documents,
+		Content:       make(map[string]contentSpan, len(s.chunks)),
+	}
+	var offset int64
+	for _, id := range ids {
+		chunk := s.chunks[id]
+		length := int64(len(chunk.Content))
+		if span, ok := s.spans[id]; ok {
+			length = span.Length
+		}
+		data.Content[id] = contentSpan{Offset: offset, Length: length}
+		offset += length
+		chunk.Content = ""
+		data.Chunks[id] = chunk
 	}
 
-	encoder := gob.NewEncoder(file)
-	if err := encoder.Encode(data); err != nil {
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.indexPath), filepath.Base(s.indexPath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create index temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	cleanupTemp := true
+	defer func() {
+		if cleanupTemp {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	writer := &countingWriter{w: bufio.NewWriter(tmpFile)}
+	if err := gob.NewEncoder(writer).Encode(data); err != nil {
+		_ = tmpFile.Close()
 		return fmt.Errorf("failed to encode index: %w", err)
 	}
+	contentBase := writer.n
+	for _, id := range ids {
+		if err := s.writeContent(writer.w, id); err != nil {
+			_ = tmpFile.Close()
+			return err
+		}
+	}
+	if err := writer.w.Flush(); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to sync index temp file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close index temp file: %w", err)
+	}
+
+	// Windows cannot replace a file that is still open.
+	s.closeContent()
+	if err := fileutil.ReplaceFileAtomically(tmpPath, s.indexPath); err != nil {
+		if len(s.spans) > 0 {
+			// Keep reading lazily loaded content from the previous file.
+			if file, openErr := os.Open(s.indexPath); openErr == nil {
+				s.content = file
+			}
+		}
+		return fmt.Errorf("failed to replace index file: %w", err)
+	}
+	cleanupTemp = false
+	if !s.lazy {
+		return nil
+	}
+
+	// Content now lives in the new file only.
+	file, err := os.Open(s.indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to reopen index file: %w", err)
+	}
+	s.content, s.contentBase = file, contentBase
+	s.chunks, s.spans = data.Chunks, data.Content
 
 	return nil
 }
 
+// writeContent writes the content of a chunk to w, from memory or, for a
+// chunk loaded lazily, from the file it was loaded from.
+func (s *GOBStore) writeContent(w io.Writer, id string) error {
+	span, ok := s.spans[id]
+	if !ok {
+		_, err := io.WriteString(w, s.chunks[id].Content)
+		return err
+	}
+	if s.content == nil {
+		return fmt.Errorf("failed to read content of chunk %s: index file is closed", id)
+	}
+	if _, err := io.Copy(w, io.NewSectionReader(s.content, s.contentBase+span.Offset, span.Length)); err != nil {
+		return fmt.Errorf("failed to copy content of chunk %s: %w", id, err)
+	}
+	return nil
+}
+
 func (s *GOBStore) Close() error {
-	return s.Persist(context.Background())
+	err := s.Persist(context.Background())
+	s.Release()
+	return err
+}
+
+// Release closes the file a lazily loaded store reads content from,
+// without persisting the store. The store must not be used afterwards. A
+// store dropped without Close or Release has the file closed when it is
+// garbage collected.
+func (s *GOBStore) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeContent()
 }
 
 func (s *GOBStore) Stats() (numDocs int, numChunks int) {
@@ -296,6 +621,9 @@ func (s *GOBStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chu
 	chunks := make([]Chunk, 0, len(doc.ChunkIDs))
 	for _, id := range doc.ChunkIDs {
 		if chunk, ok := s.chunks[id]; ok {
+			if err := s.fillContent(&chunk); err != nil {
+				return nil, err
+			}
 			chunks = append(chunks, chunk)
 		}
 	}
@@ -308,6 +636,9 @@ func (s *GOBStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 
 	chunks := make([]Chunk, 0, len(s.chunks))
 	for _, chunk := range s.chunks {
+		if err := s.fillContent(&chunk); err != nil {
+			return nil, err
+		}
 		chunks = append(chunks, chunk)
 	}
 	return chunks, nil
@@ -329,6 +660,38 @@ func (s *GOBStore) LookupByContentHash(ctx context.Context, contentHash string)
 	return nil, false, nil
 }
 
+// countingReader counts the bytes read from a buffered reader.
+type countingReader struct {
+	r *bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// countingWriter counts the bytes written to a buffered writer.
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
 // cosineSimilarity calculates the cosine similarity between two vectors
 func cosineSimilarity(a, b []float32) float32 {
 	if len(a) != len(b) {
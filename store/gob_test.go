@@ -2,10 +2,16 @@ package store
 
 import (
 	"context"
+	"encoding/gob"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/yoanbernabeu/grepai/internal/indexformat"
 )
 
 func TestGOBStore_SaveAndSearchChunks(t *testing.T) {
@@ -444,3 +450,268 @@ func TestGOBStore_FileLocking(t *testing.T) {
 		t.Errorf("Expected chunk ID c1, got %s", chunks[0].ID)
 	}
 }
+
+func TestGOBStore_LoadUnversionedFile(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+
+	// Files written before format versioning have no FormatVersion field.
+	legacy := struct {
+		Chunks    map[string]Chunk
+		Documents map[string]Document
+	}{
+		Chunks:    map[string]Chunk{"a.go_0": {ID: "a.go_0", FilePath: "a.go", Content: "package a", Vector: []float32{1, 0}}},
+		Documents: map[string]Document{"a.go": {Path: "a.go", ChunkIDs: []string{"a.go_0"}}},
+	}
+	writeGOB(t, indexPath, legacy)
+
+	st := NewGOBStore(indexPath)
+	if err := st.Load(ctx); err != nil {
+		t.Fatalf("failed to load unversioned index: %v", err)
+	}
+	chunks, err := st.GetChunksForFile(ctx, "a.go")
+	if err != nil {
+		t.Fatalf("failed to get chunks: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Content != "package a" {
+		t.Fatalf("expected the legacy chunk, got %+v", chunks)
+	}
+
+	// Persisting upgrades the file to the current version.
+	if err := st.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist: %v", err)
+	}
+	var data gobData
+	readGOB(t, indexPath, &data)
+	if data.FormatVersion != gobFormatVersion {
+		t.Errorf("expected format version %d after persist, got %d", gobFormatVersion, data.FormatVersion)
+	}
+}
+
+func TestGOBStore_LoadHashesChunkIDs(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	hash := "0123456789abcdef0123"
+	writeGOB(t, indexPath, gobData{
+		FormatVersion: 2,
+		Chunks: map[string]Chunk{
+			"a.go_0":              {ID: "a.go_0", FilePath: "a.go", Content: "package a", ContentHash: hash, Vector: []float32{1, 0}},
+			"a.go_1_fedcba987654": {ID: "a.go_1_fedcba987654", FilePath: "a.go", Content: "func A()", ContentHash: "fedcba9876543210"},
+		},
+		Documents: map[string]Document{"a.go": {Path: "a.go", ChunkIDs: []string{"a.go_0", "a.go_1_fedcba987654"}}},
+	})
+
+	st := NewGOBStore(indexPath)
+	if err := st.Load(ctx); err != nil {
+		t.Fatalf("failed to load version 2 index: %v", err)
+	}
+	doc, err := st.GetDocument(ctx, "a.go")
+	if err != nil || doc == nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	want := []string{"a.go_0_0123456789ab", "a.go_1_fedcba987654"}
+	if !reflect.DeepEqual(doc.ChunkIDs, want) {
+		t.Errorf("expected chunk IDs %v, got %v", want, doc.ChunkIDs)
+	}
+	chunks, err := st.GetChunksForFile(ctx, "a.go")
+	if err != nil {
+		t.Fatalf("failed to get chunks: %v", err)
+	}
+	for _, c := range chunks {
+		if c.ID == want[0] && c.Content != "package a" {
+			t.Errorf("expected the renamed chunk to keep its content, got %q", c.Content)
+		}
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %+v", chunks)
+	}
+}
+
+func TestGOBStore_LoadFutureVersion(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	writeGOB(t, indexPath, gobData{
+		FormatVersion: gobFormatVersion + 1,
+		Chunks:        map[string]Chunk{"a.go_0": {ID: "a.go_0", FilePath: "a.go"}},
+	})
+
+	st := NewGOBStore(indexPath)
+	err := st.Load(ctx)
+	if !errors.Is(err, indexformat.ErrTooNew) {
+		t.Fatalf("expected ErrTooNew, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "upgrade grepai") {
+		t.Errorf("expected the error to say how to fix it, got %v", err)
+	}
+	if err := st.Persist(ctx); !errors.Is(err, indexformat.ErrTooNew) {
+		t.Fatalf("expected Persist to refuse overwriting a newer index, got %v", err)
+	}
+	var data gobData
+	readGOB(t, indexPath, &data)
+	if data.FormatVersion != gobFormatVersion+1 || len(data.Chunks) != 1 {
+		t.Errorf("expected the newer index to be left untouched, got %+v", data)
+	}
+}
+
+func writeGOB(t *testing.T, path string, v any) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+	if err := gob.NewEncoder(file).Encode(v); err != nil {
+		t.Fatalf("failed to encode %s: %v", path, err)
+	}
+}
+
+func readGOB(t *testing.T, path string, v any) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+	if err := gob.NewDecoder(file).Decode(v); err != nil {
+		t.Fatalf("failed to decode %s: %v", path, err)
+	}
+}
+
+func TestGOBStore_LazyContent(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+
+	writer := NewGOBStore(indexPath)
+	if err := writer.SaveChunks(ctx, []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", Content: "package a", Vector: []float32{1, 0}},
+		{ID: "b.go_0", FilePath: "b.go", Content: "package b", Vector: []float32{0, 1}},
+	}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	for _, doc := range []Document{
+		{Path: "a.go", ChunkIDs: []string{"a.go_0"}},
+		{Path: "b.go", ChunkIDs: []string{"b.go_0"}},
+	} {
+		if err := writer.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+	if err := writer.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist: %v", err)
+	}
+
+	var data gobData
+	readGOB(t, indexPath, &data)
+	if data.Chunks["a.go_0"].Content != "" || len(data.Content) != 2 {
+		t.Fatalf("expected content in its own section, got %+v", data)
+	}
+
+	st := NewGOBStore(indexPath)
+	st.SetLazyContent(true)
+	if err := st.Load(ctx); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	defer st.Release()
+	for id, chunk := range st.chunks {
+		if chunk.Content != "" {
+			t.Errorf("expected %s to be loaded without content", id)
+		}
+	}
+
+	results, err := st.Search(ctx, []float32{1, 0}, 1, SearchOptions{})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.Content != "package a" {
+		t.Fatalf("expected the top result with its content, got %+v", results)
+	}
+
+	// Persisting keeps the content of loaded chunks and writes new ones.
+	if err := st.SaveChunks(ctx, []Chunk{{ID: "b.go_0", FilePath: "b.go", Content: "package b2", Vector: []float32{0, 1}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := st.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist: %v", err)
+	}
+	if chunk := st.chunks["b.go_0"]; chunk.Content != "" {
+		t.Errorf("expected persisted content to be dropped from memory, got %q", chunk.Content)
+	}
+	chunks, err := st.GetChunksForFile(ctx, "b.go")
+	if err != nil {
+		t.Fatalf("failed to get chunks: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Content != "package b2" {
+		t.Fatalf("expected the updated chunk, got %+v", chunks)
+	}
+
+	reloaded := NewGOBStore(indexPath)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	for id, want := range map[string]string{"a.go_0": "package a", "b.go_0": "package b2"} {
+		if got := reloaded.chunks[id].Content; got != want {
+			t.Errorf("%s: expected %q, got %q", id, want, got)
+		}
+	}
+}
+
+func TestGOBStore_DeleteByFilesAndProject(t *testing.T) {
+	ctx := context.Background()
+	st := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	for _, path := range []string{"ws/api/a.go", "ws/api/b.go", "ws/web/c.go", "ws/apis/d.go"} {
+		if err := st.SaveChunks(ctx, []Chunk{{ID: path + "_0", FilePath: path, Vector: []float32{1, 0}}}); err != nil {
+			t.Fatalf("failed to save chunks: %v", err)
+		}
+		if err := st.SaveDocument(ctx, Document{Path: path, ChunkIDs: []string{path + "_0"}}); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+
+	if err := st.DeleteByFiles(ctx, []string{"ws/web/c.go", "missing.go"}); err != nil {
+		t.Fatalf("DeleteByFiles failed: %v", err)
+	}
+	if err := st.DeleteByProject(ctx, "ws/api"); err != nil {
+		t.Fatalf("DeleteByProject failed: %v", err)
+	}
+
+	if docs, chunks := st.Stats(); docs != 2 || chunks != 1 {
+		t.Errorf("expected 2 documents and 1 chunk left, got %d and %d", docs, chunks)
+	}
+	if _, ok := st.chunks["ws/apis/d.go_0"]; !ok {
+		t.Error("expected ws/apis to be kept when deleting ws/api")
+	}
+	if doc, _ := st.GetDocument(ctx, "ws/api/a.go"); doc != nil {
+		t.Error("expected the project's documents to be deleted")
+	}
+}
+
+func TestGOBStore_ReplaceFile(t *testing.T) {
+	ctx := context.Background()
+	st := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := st.SaveChunks(ctx, []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", Content: "old 0"},
+		{ID: "a.go_1", FilePath: "a.go", Content: "old 1"},
+	}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := st.SaveDocument(ctx, Document{Path: "a.go", Hash: "old", ChunkIDs: []string{"a.go_0", "a.go_1"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	err := ReplaceFile(ctx, st, Document{Path: "a.go", Hash: "new", ChunkIDs: []string{"a.go_0"}},
+		[]Chunk{{ID: "a.go_0", FilePath: "a.go", Content: "new 0"}})
+	if err != nil {
+		t.Fatalf("ReplaceFile failed: %v", err)
+	}
+
+	chunks, err := st.GetChunksForFile(ctx, "a.go")
+	if err != nil {
+		t.Fatalf("GetChunksForFile failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Content != "new 0" {
+		t.Errorf("expected only the new chunk, got %+v", chunks)
+	}
+	if doc, _ := st.GetDocument(ctx, "a.go"); doc == nil || doc.Hash != "new" {
+		t.Errorf("expected the new document, got %+v", doc)
+	}
+}
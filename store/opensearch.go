@@ -0,0 +1,724 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OpenSearchStore stores chunks in an OpenSearch index that holds both the
+// analyzed chunk text and a k-NN vector field, so text and vector retrieval
+// both run on the server. Document metadata lives in a second index.
+type OpenSearchStore struct {
+	client     *http.Client
+	endpoint   string
+	username   string
+	password   string
+	index      string
+	docIndex   string
+	dimensions int
+}
+
+// openSearchPageSize is the number of hits fetched per page when listing a
+// whole index.
+const openSearchPageSize = 1000
+
+// openSearchChunkSource is a chunk as stored in the chunk index.
+type openSearchChunkSource struct {
+	ChunkID     string    `json:"chunk_id"`
+	FilePath    string    `json:"file_path"`
+	StartLine   int       `json:"start_line"`
+	EndLine     int       `json:"end_line"`
+	Content     string    `json:"content"`
+	Vector      []float32 `json:"vector,omitempty"`
+	Hash        string    `json:"hash"`
+	ContentHash string    `json:"content_hash"`
+	Model       string    `json:"model,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (c openSearchChunkSource) chunk() Chunk {
+	return Chunk{
+		ID:          c.ChunkID,
+		FilePath:    c.FilePath,
+		StartLine:   c.StartLine,
+		EndLine:     c.EndLine,
+		Content:     c.Content,
+		Vector:      c.Vector,
+		Hash:        c.Hash,
+		ContentHash: c.ContentHash,
+		Model:       c.Model,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+type openSearchDocument struct {
+	Path     string    `json:"path"`
+	Hash     string    `json:"hash"`
+	ModTime  time.Time `json:"mod_time"`
+	ChunkIDs []string  `json:"chunk_ids"`
+}
+
+type openSearchHit struct {
+	ID     string          `json:"_id"`
+	Score  float32         `json:"_score"`
+	Source json.RawMessage `json:"_source"`
+	Sort   []any           `json:"sort"`
+}
+
+type openSearchResponse struct {
+	Hits struct {
+		Hits []openSearchHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// openSearchError is a non-2xx response from OpenSearch.
+type openSearchError struct {
+	status  int
+	message string
+}
+
+func (e *openSearchError) Error() string {
+	return fmt.Sprintf("opensearch returned %d: %s", e.status, e.message)
+}
+
+func isOpenSearchNotFound(err error) bool {
+	var oerr *openSearchError
+	return errors.As(err, &oerr) && oerr.status == http.StatusNotFound
+}
+
+// OpenSearchIndexName turns name, such as a project path, into a valid
+// OpenSearch index name.
+func OpenSearchIndexName(name string) string {
+	var b strings.Builder
+	b.WriteString("grepai_")
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// NewOpenSearchStore connects to the OpenSearch cluster at endpoint and
+// creates index, and its companion document index, unless they exist.
+// Username and password are sent with basic auth when set.
+func NewOpenSearchStore(ctx context.Context, endpoint, username, password, index string, dimensions int) (*OpenSearchStore, error) {
+	if index == "" {
+		return nil, fmt.Errorf("opensearch index name is required")
+	}
+	s := &OpenSearchStore{
+		client:     &http.Client{Timeout: 60 * time.Second},
+		endpoint:   httpBaseURL(endpoint),
+		username:   username,
+		password:   password,
+		index:      index,
+		docIndex:   index + "_documents",
+		dimensions: dimensions,
+	}
+
+	if err := s.ensureIndices(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *OpenSearchStore) ensureIndices(ctx context.Context) error {
+	chunkIndex := map[string]any{
+		"settings": map[string]any{
+			"index": map[string]any{"knn": true},
+			// Split identifiers such as parseConfig or parse_config into
+			// their words while keeping the original token, so BM25 matches
+			// both forms.
+			"analysis": map[string]any{
+				"filter": map[string]any{
+					"grepai_code_split": map[string]any{
+						"type":              "word_delimiter",
+						"preserve_original": true,
+					},
+				},
+				"analyzer": map[string]any{
+					"grepai_code": map[string]any{
+						"type":      "custom",
+						"tokenizer": "whitespace",
+						"filter":    []string{"grepai_code_split", "lowercase"},
+					},
+				},
+			},
+		},
+		"mappings": map[string]any{
+			"properties": map[string]any{
+				"chunk_id":     map[string]any{"type": "keyword"},
+				"file_path":    map[string]any{"type": "keyword"},
+				"start_line":   map[string]any{"type": "integer"},
+				"end_line":     map[string]any{"type": "integer"},
+				"content":      map[string]any{"type": "text", "analyzer": "grepai_code"},
+				"hash":         map[string]any{"type": "keyword"},
+				"content_hash": map[string]any{"type": "keyword"},
+				"model":        map[string]any{"type": "keyword"},
+				"updated_at":   map[string]any{"type": "date"},
+				"vector": map[string]any{
+					"type":      "knn_vector",
+					"dimension": s.dimensions,
+					"method": map[string]any{
+						"name":       "hnsw",
+						"space_type": "cosinesimil",
+						"engine":     "lucene",
+					},
+				},
+			},
+		},
+	}
+	docIndex := map[string]any{
+		"mappings": map[string]any{
+			"properties": map[string]any{
+				"path":      map[string]any{"type": "keyword"},
+				"hash":      map[string]any{"type": "keyword"},
+				"mod_time":  map[string]any{"type": "date"},
+				"chunk_ids": map[string]any{"type": "keyword"},
+			},
+		},
+	}
+
+	created, err := s.ensureIndex(ctx, s.index, chunkIndex)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ensureIndex(ctx, s.docIndex, docIndex); err != nil {
+		return err
+	}
+	if created {
+		return nil
+	}
+	return s.checkDimensions(ctx)
+}
+
+// ensureIndex creates index with body unless it exists, and reports whether
+// it was created.
+func (s *OpenSearchStore) ensureIndex(ctx context.Context, index string, body map[string]any) (bool, error) {
+	err := s.do(ctx, http.MethodHead, "/"+index, nil, nil)
+	if err == nil {
+		return false, nil
+	}
+	if !isOpenSearchNotFound(err) {
+		return false, fmt.Errorf("failed to check index %s: %w", index, err)
+	}
+	if err := s.do(ctx, http.MethodPut, "/"+index, body, nil); err != nil {
+		return false, fmt.Errorf("failed to create index %s: %w", index, err)
+	}
+	return true, nil
+}
+
+// checkDimensions compares the dimension of the index's vector field with
+// the configured dimensions, since an index built by another embedding model
+// rejects every write and search.
+func (s *OpenSearchStore) checkDimensions(ctx context.Context) error {
+	if s.dimensions <= 0 {
+		return nil
+	}
+	var mappings map[string]struct {
+		Mappings struct {
+			Properties map[string]struct {
+				Type      string `json:"type"`
+				Dimension int    `json:"dimension"`
+			} `json:"properties"`
+		} `json:"mappings"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/"+s.index+"/_mapping", nil, &mappings); err != nil {
+		return fmt.Errorf("failed to read index %s: %w", s.index, err)
+	}
+	for _, m := range mappings {
+		vector, ok := m.Mappings.Properties["vector"]
+		if !ok {
+			continue
+		}
+		if vector.Type != "knn_vector" {
+			return fmt.Errorf("index %q has a %q vector field; grepai needs knn_vector, use another index", s.index, vector.Type)
+		}
+		if vector.Dimension != s.dimensions {
+			return fmt.Errorf("index %q has %d-dimensional vectors but the embedder is configured for %d; set embedder.dimensions to %d or use another index", s.index, vector.Dimension, s.dimensions, vector.Dimension)
+		}
+	}
+	return nil
+}
+
+// do sends a request to the OpenSearch API and decodes the response into out
+// when it is non-nil. A []byte body is sent as is, as newline-delimited JSON.
+func (s *OpenSearchStore) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+		contentType = "application/x-ndjson"
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.username != "" || s.password != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach opensearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &openSearchError{status: resp.StatusCode, message: strings.TrimSpace(string(msg))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// search runs a search request against index.
+func (s *OpenSearchStore) search(ctx context.Context, index string, body map[string]any) ([]openSearchHit, error) {
+	var resp openSearchResponse
+	if err := s.do(ctx, http.MethodPost, "/"+index+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Hits.Hits, nil
+}
+
+// eachHit pages through every hit of query in index, sorted by the keyword
+// field sortField, and calls fn for each.
+func (s *OpenSearchStore) eachHit(ctx context.Context, index string, query map[string]any, sortField string, excludeVector bool, fn func(openSearchHit) error) error {
+	var after []any
+	for {
+		body := map[string]any{
+			"size":  openSearchPageSize,
+			"query": query,
+			"sort":  []any{map[string]any{sortField: "asc"}},
+		}
+		if excludeVector {
+			body["_source"] = map[string]any{"excludes": []string{"vector"}}
+		}
+		if after != nil {
+			body["search_after"] = after
+		}
+		hits, err := s.search(ctx, index, body)
+		if err != nil {
+			return err
+		}
+		for _, hit := range hits {
+			if err := fn(hit); err != nil {
+				return err
+			}
+		}
+		if len(hits) < openSearchPageSize {
+			return nil
+		}
+		after = hits[len(hits)-1].Sort
+	}
+}
+
+// chunkHits decodes the chunks of hits, scoring each with score.
+func chunkHits(hits []openSearchHit, score func(float32) float32) ([]SearchResult, error) {
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		var src openSearchChunkSource
+		if err := json.Unmarshal(hit.Source, &src); err != nil {
+			return nil, fmt.Errorf("failed to decode chunk: %w", err)
+		}
+		results = append(results, SearchResult{Chunk: src.chunk(), Score: score(hit.Score)})
+	}
+	return results, nil
+}
+
+func pathPrefixFilter(prefix string) []any {
+	if prefix == "" {
+		return []any{}
+	}
+	return []any{map[string]any{"prefix": map[string]any{"file_path": prefix}}}
+}
+
+func termQuery(field, value string) map[string]any {
+	return map[string]any{"term": map[string]any{field: value}}
+}
+
+func (s *OpenSearchStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, chunk := range chunks {
+		action := map[string]any{"index": map[string]any{"_index": s.index, "_id": chunk.ID}}
+		source := openSearchChunkSource{
+			ChunkID:     chunk.ID,
+			FilePath:    chunk.FilePath,
+			StartLine:   chunk.StartLine,
+			EndLine:     chunk.EndLine,
+			Content:     sanitizeUTF8(chunk.Content),
+			Vector:      chunk.Vector,
+			Hash:        chunk.Hash,
+			ContentHash: chunk.ContentHash,
+			Model:       chunk.Model,
+			UpdatedAt:   chunk.UpdatedAt,
+		}
+		if err := enc.Encode(action); err != nil {
+			return fmt.Errorf("failed to encode chunk: %w", err)
+		}
+		if err := enc.Encode(source); err != nil {
+			return fmt.Errorf("failed to encode chunk: %w", err)
+		}
+	}
+
+	// Wait for a refresh so that a following DeleteByFile or search sees
+	// the chunks.
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string `json:"_id"`
+			Error *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/_bulk?refresh=wait_for", buf.Bytes(), &resp); err != nil {
+		return fmt.Errorf("failed to save chunks: %w", err)
+	}
+	if resp.Errors {
+		for _, item := range resp.Items {
+			for _, result := range item {
+				if result.Error != nil {
+					return fmt.Errorf("failed to save chunk %s: %s: %s", result.ID, result.Error.Type, result.Error.Reason)
+				}
+			}
+		}
+		return fmt.Errorf("failed to save chunks")
+	}
+	return nil
+}
+
+func (s *OpenSearchStore) DeleteByFile(ctx context.Context, filePath string) error {
+	body := map[string]any{"query": termQuery("file_path", filePath)}
+	if err := s.do(ctx, http.MethodPost, "/"+s.index+"/_delete_by_query?refresh=true&conflicts=proceed", body, nil); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *OpenSearchStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	body := map[string]any{"query": map[string]any{"terms": map[string]any{"file_path": filePaths}}}
+	if err := s.do(ctx, http.MethodPost, "/"+s.index+"/_delete_by_query?refresh=true&conflicts=proceed", body, nil); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *OpenSearchStore) DeleteChunks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	body := map[string]any{"query": map[string]any{"terms": map[string]any{"chunk_id": ids}}}
+	if err := s.do(ctx, http.MethodPost, "/"+s.index+"/_delete_by_query?refresh=true&conflicts=proceed", body, nil); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *OpenSearchStore) DeleteByProject(ctx context.Context, project string) error {
+	prefix := projectPrefix(project)
+	body := map[string]any{"query": map[string]any{"prefix": map[string]any{"file_path": prefix}}}
+	if err := s.do(ctx, http.MethodPost, "/"+s.index+"/_delete_by_query?refresh=true&conflicts=proceed", body, nil); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	body = map[string]any{"query": map[string]any{"prefix": map[string]any{"path": prefix}}}
+	if err := s.do(ctx, http.MethodPost, "/"+s.docIndex+"/_delete_by_query?refresh=true&conflicts=proceed", body, nil); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+func (s *OpenSearchStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got: %d", limit)
+	}
+
+	knn := map[string]any{"vector": queryVector, "k": limit}
+	if opts.PathPrefix != "" || opts.Model != "" || opts.ExcludeModel != "" {
+		filter := map[string]any{"filter": pathPrefixFilter(opts.PathPrefix)}
+		if opts.Model != "" {
+			filter["filter"] = append(filter["filter"].([]any), termQuery("model", opts.Model))
+		}
+		if opts.ExcludeModel != "" {
+			filter["must_not"] = []any{termQuery("model", opts.ExcludeModel)}
+		}
+		knn["filter"] = map[string]any{"bool": filter}
+	}
+	hits, err := s.search(ctx, s.index, map[string]any{
+		"size":    limit,
+		"_source": map[string]any{"excludes": []string{"vector"}},
+		"query":   map[string]any{"knn": map[string]any{"vector": knn}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	// cosinesimil scores are (1 + cosine similarity) / 2; report the cosine
+	// similarity like the other stores.
+	return chunkHits(hits, func(score float32) float32 { return 2*score - 1 })
+}
+
+// SearchText ranks chunks by BM25 relevance of their content to query.
+func (s *OpenSearchStore) SearchText(ctx context.Context, query string, limit int, opts SearchOptions) ([]SearchResult, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got: %d", limit)
+	}
+
+	hits, err := s.search(ctx, s.index, map[string]any{
+		"size":    limit,
+		"_source": map[string]any{"excludes": []string{"vector"}},
+		"query": map[string]any{"bool": map[string]any{
+			"must":   map[string]any{"match": map[string]any{"content": query}},
+			"filter": pathPrefixFilter(opts.PathPrefix),
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search text: %w", err)
+	}
+	return chunkHits(hits, func(score float32) float32 { return score })
+}
+
+func (s *OpenSearchStore) docPath(filePath string) string {
+	return "/" + s.docIndex + "/_doc/" + url.PathEscape(filePath)
+}
+
+func (s *OpenSearchStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
+	var resp struct {
+		Found  bool               `json:"found"`
+		Source openSearchDocument `json:"_source"`
+	}
+	err := s.do(ctx, http.MethodGet, s.docPath(filePath), nil, &resp)
+	if isOpenSearchNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	if !resp.Found {
+		return nil, nil
+	}
+	doc := resp.Source
+	return &Document{Path: doc.Path, Hash: doc.Hash, ModTime: doc.ModTime, ChunkIDs: doc.ChunkIDs}, nil
+}
+
+func (s *OpenSearchStore) SaveDocument(ctx context.Context, doc Document) error {
+	chunkIDs := doc.ChunkIDs
+	if chunkIDs == nil {
+		chunkIDs = []string{}
+	}
+	body := openSearchDocument{Path: doc.Path, Hash: doc.Hash, ModTime: doc.ModTime, ChunkIDs: chunkIDs}
+	if err := s.do(ctx, http.MethodPut, s.docPath(doc.Path)+"?refresh=wait_for", body, nil); err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+	return nil
+}
+
+func (s *OpenSearchStore) DeleteDocument(ctx context.Context, filePath string) error {
+	err := s.do(ctx, http.MethodDelete, s.docPath(filePath)+"?refresh=wait_for", nil, nil)
+	if err != nil && !isOpenSearchNotFound(err) {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// listDocuments returns the metadata of every document.
+func (s *OpenSearchStore) listDocuments(ctx context.Context) ([]openSearchDocument, error) {
+	var docs []openSearchDocument
+	err := s.eachHit(ctx, s.docIndex, map[string]any{"match_all": map[string]any{}}, "path", false, func(hit openSearchHit) error {
+		var doc openSearchDocument
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			return fmt.Errorf("failed to decode document: %w", err)
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+	return docs, err
+}
+
+func (s *OpenSearchStore) ListDocuments(ctx context.Context) ([]string, error) {
+	docs, err := s.listDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	paths := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		paths = append(paths, doc.Path)
+	}
+	return paths, nil
+}
+
+func (s *OpenSearchStore) Load(ctx context.Context) error {
+	return nil
+}
+
+func (s *OpenSearchStore) Persist(ctx context.Context) error {
+	return nil
+}
+
+func (s *OpenSearchStore) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func (s *OpenSearchStore) count(ctx context.Context, index string) (int, error) {
+	var resp struct {
+		Count int `json:"count"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/"+index+"/_count", nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+func (s *OpenSearchStore) GetStats(ctx context.Context) (*IndexStats, error) {
+	stats := &IndexStats{}
+	var err error
+	if stats.TotalChunks, err = s.count(ctx, s.index); err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	if stats.TotalFiles, err = s.count(ctx, s.docIndex); err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	var agg struct {
+		Aggregations struct {
+			LastUpdated struct {
+				Value *float64 `json:"value"`
+			} `json:"last_updated"`
+		} `json:"aggregations"`
+	}
+	body := map[string]any{
+		"size": 0,
+		"aggs": map[string]any{"last_updated": map[string]any{"max": map[string]any{"field": "updated_at"}}},
+	}
+	if err := s.do(ctx, http.MethodPost, "/"+s.index+"/_search", body, &agg); err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	if v := agg.Aggregations.LastUpdated.Value; v != nil {
+		stats.LastUpdated = time.UnixMilli(int64(*v)).UTC()
+	}
+
+	var storeStats struct {
+		All struct {
+			Primaries struct {
+				Store struct {
+					SizeInBytes int64 `json:"size_in_bytes"`
+				} `json:"store"`
+			} `json:"primaries"`
+		} `json:"_all"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/"+s.index+","+s.docIndex+"/_stats/store", nil, &storeStats); err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+	stats.IndexSize = storeStats.All.Primaries.Store.SizeInBytes
+	return stats, nil
+}
+
+func (s *OpenSearchStore) ListFilesWithStats(ctx context.Context) ([]FileStats, error) {
+	docs, err := s.listDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	stats := make([]FileStats, 0, len(docs))
+	for _, doc := range docs {
+		stats = append(stats, FileStats{
+			Path:       doc.Path,
+			ChunkCount: len(doc.ChunkIDs),
+			ModTime:    doc.ModTime,
+		})
+	}
+	return stats, nil
+}
+
+// eachChunk pages through the chunks matching query, sorted by ID.
+func (s *OpenSearchStore) eachChunk(ctx context.Context, query map[string]any, fn func(Chunk)) error {
+	return s.eachHit(ctx, s.index, query, "chunk_id", false, func(hit openSearchHit) error {
+		var src openSearchChunkSource
+		if err := json.Unmarshal(hit.Source, &src); err != nil {
+			return fmt.Errorf("failed to decode chunk: %w", err)
+		}
+		fn(src.chunk())
+		return nil
+	})
+}
+
+func (s *OpenSearchStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chunk, error) {
+	var chunks []Chunk
+	if err := s.eachChunk(ctx, termQuery("file_path", filePath), func(c Chunk) { chunks = append(chunks, c) }); err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+func (s *OpenSearchStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
+	var chunks []Chunk
+	if err := s.eachChunk(ctx, map[string]any{"match_all": map[string]any{}}, func(c Chunk) { chunks = append(chunks, c) }); err != nil {
+		return nil, fmt.Errorf("failed to get all chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// LookupByContentHash searches OpenSearch for a chunk matching the content
+// hash.
+func (s *OpenSearchStore) LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error) {
+	if contentHash == "" {
+		return nil, false, nil
+	}
+
+	hits, err := s.search(ctx, s.index, map[string]any{
+		"size":    1,
+		"_source": []string{"vector"},
+		"query":   termQuery("content_hash", contentHash),
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lookup by content hash: %w", err)
+	}
+	if len(hits) == 0 {
+		return nil, false, nil
+	}
+	var src openSearchChunkSource
+	if err := json.Unmarshal(hits[0].Source, &src); err != nil {
+		return nil, false, fmt.Errorf("failed to decode chunk: %w", err)
+	}
+	if len(src.Vector) == 0 {
+		return nil, false, nil
+	}
+	return src.Vector, true, nil
+}
@@ -0,0 +1,476 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOpenSearch serves the parts of the OpenSearch API used by
+// OpenSearchStore, keeping documents in memory. Term and match_all queries
+// are evaluated; knn and match queries return every chunk with score.
+type fakeOpenSearch struct {
+	mu       sync.Mutex
+	mappings map[string]map[string]any
+	docs     map[string]map[string]json.RawMessage // index -> id -> source
+	searches []map[string]any
+	score    float32
+	auth     string
+}
+
+func newFakeOpenSearch(t *testing.T) (*fakeOpenSearch, *httptest.Server) {
+	t.Helper()
+	f := &fakeOpenSearch{
+		mappings: make(map[string]map[string]any),
+		docs:     make(map[string]map[string]json.RawMessage),
+		score:    0.875,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeOpenSearch) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, pass, ok := r.BasicAuth(); ok {
+		f.auth = user + ":" + pass
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	writeJSON := func(v any) { _ = json.NewEncoder(w).Encode(v) }
+	switch {
+	case r.URL.Path == "/_bulk":
+		f.bulk(r, writeJSON)
+	case len(parts) == 1 && r.Method == http.MethodHead:
+		if f.mappings[parts[0]] == nil {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		var body struct {
+			Mappings map[string]any `json:"mappings"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.mappings[parts[0]] = body.Mappings
+		f.docs[parts[0]] = make(map[string]json.RawMessage)
+		writeJSON(map[string]any{"acknowledged": true})
+	case len(parts) == 2 && parts[1] == "_mapping":
+		writeJSON(map[string]any{parts[0]: map[string]any{"mappings": f.mappings[parts[0]]}})
+	case len(parts) == 2 && parts[1] == "_count":
+		writeJSON(map[string]any{"count": len(f.docs[parts[0]])})
+	case len(parts) == 3 && parts[1] == "_stats":
+		writeJSON(map[string]any{"_all": map[string]any{"primaries": map[string]any{"store": map[string]any{"size_in_bytes": 2048}}}})
+	case len(parts) == 2 && parts[1] == "_delete_by_query":
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for id, src := range f.docs[parts[0]] {
+			if f.matches(body["query"].(map[string]any), src) {
+				delete(f.docs[parts[0]], id)
+			}
+		}
+		writeJSON(map[string]any{"deleted": 0})
+	case len(parts) == 2 && parts[1] == "_search":
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.searches = append(f.searches, body)
+		writeJSON(f.search(parts[0], body))
+	case len(parts) == 3 && parts[1] == "_doc":
+		id, _ := url.PathUnescape(parts[2])
+		src, ok := f.docs[parts[0]][id]
+		switch r.Method {
+		case http.MethodPut:
+			var buf bytes.Buffer
+			_, _ = buf.ReadFrom(r.Body)
+			f.docs[parts[0]][id] = buf.Bytes()
+			writeJSON(map[string]any{"result": "created"})
+		case http.MethodDelete:
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(f.docs[parts[0]], id)
+			writeJSON(map[string]any{"result": "deleted"})
+		default:
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				writeJSON(map[string]any{"found": false})
+				return
+			}
+			writeJSON(map[string]any{"found": true, "_source": src})
+		}
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.String(), http.StatusBadRequest)
+	}
+}
+
+func (f *fakeOpenSearch) bulk(r *http.Request, writeJSON func(any)) {
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	var items []any
+	errors := false
+	for scanner.Scan() {
+		var action struct {
+			Index struct {
+				Index string `json:"_index"`
+				ID    string `json:"_id"`
+			} `json:"index"`
+		}
+		_ = json.Unmarshal(scanner.Bytes(), &action)
+		if !scanner.Scan() {
+			break
+		}
+		item := map[string]any{"_id": action.Index.ID, "status": 201}
+		if strings.Contains(scanner.Text(), `"start_line":-1`) {
+			errors = true
+			item["error"] = map[string]any{"type": "mapper_parsing_exception", "reason": "bad start_line"}
+		} else {
+			f.docs[action.Index.Index][action.Index.ID] = append(json.RawMessage(nil), scanner.Bytes()...)
+		}
+		items = append(items, map[string]any{"index": item})
+	}
+	writeJSON(map[string]any{"errors": errors, "items": items})
+}
+
+// matches evaluates term, terms, prefix and match_all queries against src.
+func (f *fakeOpenSearch) matches(query map[string]any, src json.RawMessage) bool {
+	var fields map[string]any
+	_ = json.Unmarshal(src, &fields)
+	if terms, ok := query["terms"].(map[string]any); ok {
+		for field, values := range terms {
+			for _, want := range values.([]any) {
+				if fields[field] == want {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if prefix, ok := query["prefix"].(map[string]any); ok {
+		for field, want := range prefix {
+			value, _ := fields[field].(string)
+			if !strings.HasPrefix(value, want.(string)) {
+				return false
+			}
+		}
+		return true
+	}
+	term, ok := query["term"].(map[string]any)
+	if !ok {
+		return true
+	}
+	for field, want := range term {
+		if fields[field] != want {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *fakeOpenSearch) search(index string, body map[string]any) map[string]any {
+	query, _ := body["query"].(map[string]any)
+	_, isKNN := query["knn"]
+	_, isBool := query["bool"]
+
+	var ids []string
+	for id, src := range f.docs[index] {
+		if isKNN || isBool || f.matches(query, src) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if after, ok := body["search_after"].([]any); ok {
+		start := sort.SearchStrings(ids, after[0].(string))
+		for start < len(ids) && ids[start] <= after[0].(string) {
+			start++
+		}
+		ids = ids[start:]
+	}
+	if size := int(body["size"].(float64)); len(ids) > size {
+		ids = ids[:size]
+	}
+
+	hits := []any{}
+	for _, id := range ids {
+		hits = append(hits, map[string]any{"_id": id, "_score": f.score, "_source": f.docs[index][id], "sort": []any{id}})
+	}
+	return map[string]any{
+		"hits":         map[string]any{"hits": hits},
+		"aggregations": map[string]any{"last_updated": map[string]any{"value": 1767323045000.0}},
+	}
+}
+
+func TestOpenSearchIndexName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/Users/test/Project", "grepai__users_test_project"},
+		{"workspace_team", "grepai_workspace_team"},
+		{"my-repo.v2", "grepai_my-repo_v2"},
+	}
+
+	for _, tt := range tests {
+		if got := OpenSearchIndexName(tt.input); got != tt.expected {
+			t.Errorf("OpenSearchIndexName(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestNewOpenSearchStore_CreatesIndices(t *testing.T) {
+	f, srv := newFakeOpenSearch(t)
+
+	s, err := NewOpenSearchStore(context.Background(), srv.URL+"/", "admin", "secret", "grepai_test", 4)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	if f.mappings["grepai_test"] == nil || f.mappings["grepai_test_documents"] == nil {
+		t.Fatalf("expected chunk and document indices, got %v", f.mappings)
+	}
+	if f.auth != "admin:secret" {
+		t.Errorf("expected basic auth, got %q", f.auth)
+	}
+}
+
+func TestNewOpenSearchStore_DimensionMismatch(t *testing.T) {
+	_, srv := newFakeOpenSearch(t)
+	ctx := context.Background()
+
+	if _, err := NewOpenSearchStore(ctx, srv.URL, "", "", "grepai_test", 3); err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if _, err := NewOpenSearchStore(ctx, srv.URL, "", "", "grepai_test", 3); err != nil {
+		t.Fatalf("expected matching dimensions to open, got %v", err)
+	}
+	_, err := NewOpenSearchStore(ctx, srv.URL, "", "", "grepai_test", 768)
+	if err == nil || !strings.Contains(err.Error(), "has 3-dimensional vectors but the embedder is configured for 768") {
+		t.Fatalf("expected dimension mismatch error, got %v", err)
+	}
+}
+
+func TestOpenSearchStore_ChunksAndDocuments(t *testing.T) {
+	_, srv := newFakeOpenSearch(t)
+	ctx := context.Background()
+
+	s, err := NewOpenSearchStore(ctx, srv.URL, "", "", "grepai_test", 2)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	chunks := []Chunk{
+		{ID: "src/a.go_0", FilePath: "src/a.go", StartLine: 1, EndLine: 10, Content: "package a", Vector: []float32{1, 0}, ContentHash: "h1", UpdatedAt: now},
+		{ID: "src/a.go_1", FilePath: "src/a.go", StartLine: 11, EndLine: 20, Content: "func A()", Vector: []float32{0, 1}, UpdatedAt: now},
+		{ID: "lib/b.go_0", FilePath: "lib/b.go", StartLine: 1, EndLine: 5, Content: "package b", Vector: []float32{1, 1}, UpdatedAt: now},
+	}
+	if err := s.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	doc := Document{Path: "src/a.go", Hash: "h", ModTime: now, ChunkIDs: []string{"src/a.go_0", "src/a.go_1"}}
+	if err := s.SaveDocument(ctx, doc); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	fileChunks, err := s.GetChunksForFile(ctx, "src/a.go")
+	if err != nil {
+		t.Fatalf("failed to get chunks: %v", err)
+	}
+	if len(fileChunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(fileChunks))
+	}
+
+	got, err := s.GetDocument(ctx, "src/a.go")
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if got == nil || got.Hash != "h" || !got.ModTime.Equal(now) || len(got.ChunkIDs) != 2 {
+		t.Fatalf("unexpected document: %+v", got)
+	}
+
+	vector, found, err := s.LookupByContentHash(ctx, "h1")
+	if err != nil || !found || len(vector) != 2 {
+		t.Fatalf("expected to find vector by content hash, got %v %v %v", vector, found, err)
+	}
+
+	stats, err := s.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if stats.TotalChunks != 3 || stats.TotalFiles != 1 || stats.IndexSize != 2048 || stats.LastUpdated.Year() != 2026 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if err := s.DeleteByFile(ctx, "src/a.go"); err != nil {
+		t.Fatalf("failed to delete file: %v", err)
+	}
+	all, err := s.GetAllChunks(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all chunks: %v", err)
+	}
+	if len(all) != 1 || all[0].ID != "lib/b.go_0" || len(all[0].Vector) != 2 {
+		t.Fatalf("expected only lib/b.go to remain, got %+v", all)
+	}
+
+	if err := s.DeleteDocument(ctx, "src/a.go"); err != nil {
+		t.Fatalf("failed to delete document: %v", err)
+	}
+	if err := s.DeleteDocument(ctx, "src/a.go"); err != nil {
+		t.Fatalf("deleting a missing document should succeed: %v", err)
+	}
+	paths, err := s.ListDocuments(ctx)
+	if err != nil {
+		t.Fatalf("failed to list documents: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Fatalf("expected no documents, got %v", paths)
+	}
+}
+
+func TestOpenSearchStore_DeleteByFilesAndProject(t *testing.T) {
+	_, srv := newFakeOpenSearch(t)
+	ctx := context.Background()
+
+	s, err := NewOpenSearchStore(ctx, srv.URL, "", "", "grepai_test", 2)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	for _, path := range []string{"ws/api/a.go", "ws/api/b.go", "ws/web/c.go", "ws/apis/d.go"} {
+		if err := s.SaveChunks(ctx, []Chunk{{ID: path + "_0", FilePath: path, Vector: []float32{1, 0}}}); err != nil {
+			t.Fatalf("failed to save chunks: %v", err)
+		}
+		if err := s.SaveDocument(ctx, Document{Path: path, ChunkIDs: []string{path + "_0"}}); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+
+	if err := s.DeleteByFiles(ctx, []string{"ws/web/c.go", "ws/apis/d.go"}); err != nil {
+		t.Fatalf("DeleteByFiles failed: %v", err)
+	}
+	if err := s.DeleteByProject(ctx, "ws/api"); err != nil {
+		t.Fatalf("DeleteByProject failed: %v", err)
+	}
+
+	all, err := s.GetAllChunks(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all chunks: %v", err)
+	}
+	if len(all) != 0 {
+		t.Errorf("expected no chunks left, got %+v", all)
+	}
+	paths, err := s.ListDocuments(ctx)
+	if err != nil {
+		t.Fatalf("failed to list documents: %v", err)
+	}
+	sort.Strings(paths)
+	if len(paths) != 2 || paths[0] != "ws/apis/d.go" || paths[1] != "ws/web/c.go" {
+		t.Errorf("expected only the documents outside ws/api to remain, got %v", paths)
+	}
+}
+
+func TestOpenSearchStore_GetAllChunksPaginates(t *testing.T) {
+	_, srv := newFakeOpenSearch(t)
+	ctx := context.Background()
+
+	s, err := NewOpenSearchStore(ctx, srv.URL, "", "", "grepai_test", 1)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	chunks := make([]Chunk, openSearchPageSize+5)
+	for i := range chunks {
+		chunks[i] = Chunk{ID: fmt.Sprintf("a.go_%d", i), FilePath: "a.go", Vector: []float32{1}}
+	}
+	if err := s.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	all, err := s.GetAllChunks(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all chunks: %v", err)
+	}
+	if len(all) != len(chunks) {
+		t.Fatalf("expected %d chunks, got %d", len(chunks), len(all))
+	}
+}
+
+func TestOpenSearchStore_SaveChunksReportsItemErrors(t *testing.T) {
+	_, srv := newFakeOpenSearch(t)
+	ctx := context.Background()
+
+	s, err := NewOpenSearchStore(ctx, srv.URL, "", "", "grepai_test", 1)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	err = s.SaveChunks(ctx, []Chunk{{ID: "bad", FilePath: "a.go", StartLine: -1, Vector: []float32{1}}})
+	if err == nil || !strings.Contains(err.Error(), "failed to save chunk bad: mapper_parsing_exception: bad start_line") {
+		t.Fatalf("expected the item error, got %v", err)
+	}
+}
+
+func TestOpenSearchStore_Search(t *testing.T) {
+	f, srv := newFakeOpenSearch(t)
+	ctx := context.Background()
+
+	s, err := NewOpenSearchStore(ctx, srv.URL, "", "", "grepai_test", 2)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := s.SaveChunks(ctx, []Chunk{{ID: "src/a.go_0", FilePath: "src/a.go", Content: "parseConfig", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	results, err := s.Search(ctx, []float32{0.5, 1}, 5, SearchOptions{PathPrefix: "src/"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "src/a.go" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].Score != 0.75 {
+		t.Errorf("expected cosinesimil score 0.875 to become cosine 0.75, got %v", results[0].Score)
+	}
+	query, _ := json.Marshal(f.searches[len(f.searches)-1])
+	for _, want := range []string{`"k":5`, `"prefix":{"file_path":"src/"}`, `"excludes":["vector"]`} {
+		if !strings.Contains(string(query), want) {
+			t.Errorf("expected knn query to contain %s, got %s", want, query)
+		}
+	}
+
+	if _, err := s.Search(ctx, []float32{0.5, 1}, 5, SearchOptions{Model: "docs", ExcludeModel: "code"}); err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	query, _ = json.Marshal(f.searches[len(f.searches)-1])
+	for _, want := range []string{`"filter":[{"term":{"model":"docs"}}]`, `"must_not":[{"term":{"model":"code"}}]`} {
+		if !strings.Contains(string(query), want) {
+			t.Errorf("expected knn query to contain %s, got %s", want, query)
+		}
+	}
+
+	results, err = s.SearchText(ctx, "parse config", 3, SearchOptions{PathPrefix: "src/"})
+	if err != nil {
+		t.Fatalf("failed to search text: %v", err)
+	}
+	if len(results) != 1 || results[0].Score != 0.875 {
+		t.Fatalf("unexpected text results: %+v", results)
+	}
+	query, _ = json.Marshal(f.searches[len(f.searches)-1])
+	for _, want := range []string{`"match":{"content":"parse config"}`, `"prefix":{"file_path":"src/"}`, `"size":3`} {
+		if !strings.Contains(string(query), want) {
+			t.Errorf("expected text query to contain %s, got %s", want, query)
+		}
+	}
+
+	if _, err := s.Search(ctx, []float32{0.5, 1}, 0, SearchOptions{}); err == nil {
+		t.Error("expected an error for a non-positive limit")
+	}
+}
@@ -36,6 +36,21 @@ func NewPostgresStore(ctx context.Context, dsn string, projectID string, vectorD
 	return store, nil
 }
 
+// Ping checks that the database is reachable.
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	if err := s.pool.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to reach postgres: %w", err)
+	}
+	return nil
+}
+
+// Reconnect closes the pooled connections, which may be broken after a
+// database restart, and checks that a new one can be opened.
+func (s *PostgresStore) Reconnect(ctx context.Context) error {
+	s.pool.Reset()
+	return s.Ping(ctx)
+}
+
 func (s *PostgresStore) ensureSchema(ctx context.Context) error {
 	queries := []string{
 		`CREATE EXTENSION IF NOT EXISTS vector`,
@@ -62,6 +77,7 @@ func (s *PostgresStore) ensureSchema(ctx context.Context) error {
 		)`,
 		`ALTER TABLE chunks ADD COLUMN IF NOT EXISTS content_hash TEXT DEFAULT ''`,
 		`CREATE INDEX IF NOT EXISTS idx_chunks_content_hash ON chunks(content_hash) WHERE content_hash != ''`,
+		`ALTER TABLE chunks ADD COLUMN IF NOT EXISTS model TEXT DEFAULT ''`,
 		buildEnsureVectorSQL(s.dimensions),
 		// Migrate chunks primary key from (id) to (project_id, id) so that
 		// worktrees sharing the same database get their own chunk rows instead
@@ -89,31 +105,46 @@ func (s *PostgresStore) ensureSchema(ctx context.Context) error {
 	return nil
 }
 
-func (s *PostgresStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
-	if len(chunks) == 0 {
-		return nil
-	}
-
-	batch := &pgx.Batch{}
+const (
+	upsertChunkSQL = `INSERT INTO chunks (id, project_id, file_path, start_line, end_line, content, vector, hash, content_hash, model, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	ON CONFLICT (project_id, id) DO UPDATE SET
+		file_path = EXCLUDED.file_path,
+		start_line = EXCLUDED.start_line,
+		end_line = EXCLUDED.end_line,
+		content = EXCLUDED.content,
+		vector = EXCLUDED.vector,
+		hash = EXCLUDED.hash,
+		content_hash = EXCLUDED.content_hash,
+		model = EXCLUDED.model,
+		updated_at = EXCLUDED.updated_at`
+
+	upsertDocumentSQL = `INSERT INTO documents (path, project_id, hash, mod_time, chunk_ids)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (project_id, path) DO UPDATE SET
+		hash = EXCLUDED.hash,
+		mod_time = EXCLUDED.mod_time,
+		chunk_ids = EXCLUDED.chunk_ids`
+)
 
+// queueChunks queues the upserts of chunks on batch.
+func (s *PostgresStore) queueChunks(batch *pgx.Batch, chunks []Chunk) {
 	for _, chunk := range chunks {
 		vec := pgvector.NewVector(chunk.Vector)
-		batch.Queue(
-			`INSERT INTO chunks (id, project_id, file_path, start_line, end_line, content, vector, hash, content_hash, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-			ON CONFLICT (project_id, id) DO UPDATE SET
-				file_path = EXCLUDED.file_path,
-				start_line = EXCLUDED.start_line,
-				end_line = EXCLUDED.end_line,
-				content = EXCLUDED.content,
-				vector = EXCLUDED.vector,
-				hash = EXCLUDED.hash,
-				content_hash = EXCLUDED.content_hash,
-				updated_at = EXCLUDED.updated_at`,
+		batch.Queue(upsertChunkSQL,
 			chunk.ID, s.projectID, chunk.FilePath, chunk.StartLine, chunk.EndLine,
-			chunk.Content, vec, chunk.Hash, chunk.ContentHash, chunk.UpdatedAt,
+			chunk.Content, vec, chunk.Hash, chunk.ContentHash, chunk.Model, chunk.UpdatedAt,
 		)
 	}
+}
+
+func (s *PostgresStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	s.queueChunks(batch, chunks)
 
 	results := s.pool.SendBatch(ctx, batch)
 	defer results.Close()
@@ -127,6 +158,37 @@ func (s *PostgresStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
 	return nil
 }
 
+// ReplaceFile deletes a file's chunks, saves the new ones and its document
+// in one transaction.
+func (s *PostgresStore) ReplaceFile(ctx context.Context, doc Document, chunks []Chunk) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	batch := &pgx.Batch{}
+	batch.Queue(`DELETE FROM chunks WHERE project_id = $1 AND file_path = $2`, s.projectID, doc.Path)
+	s.queueChunks(batch, chunks)
+	batch.Queue(upsertDocumentSQL, doc.Path, s.projectID, doc.Hash, doc.ModTime, doc.ChunkIDs)
+
+	results := tx.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+			return fmt.Errorf("failed to replace file: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit file replacement: %w", err)
+	}
+	return nil
+}
+
 func (s *PostgresStore) DeleteByFile(ctx context.Context, filePath string) error {
 	_, err := s.pool.Exec(ctx,
 		`DELETE FROM chunks WHERE project_id = $1 AND file_path = $2`,
@@ -138,10 +200,63 @@ func (s *PostgresStore) DeleteByFile(ctx context.Context, filePath string) error
 	return nil
 }
 
+func (s *PostgresStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM chunks WHERE project_id = $1 AND file_path = ANY($2)`,
+		s.projectID, filePaths,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteChunks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM chunks WHERE project_id = $1 AND id = ANY($2)`,
+		s.projectID, ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteByProject(ctx context.Context, project string) error {
+	prefix := projectPrefix(project)
+
+	// A batch runs in a single implicit transaction.
+	batch := &pgx.Batch{}
+	batch.Queue(
+		`DELETE FROM chunks WHERE project_id = $1 AND left(file_path, length($2::text)) = $2::text`,
+		s.projectID, prefix,
+	)
+	batch.Queue(
+		`DELETE FROM documents WHERE project_id = $1 AND left(path, length($2::text)) = $2::text`,
+		s.projectID, prefix,
+	)
+
+	results := s.pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to delete project: %w", err)
+		}
+	}
+	return nil
+}
+
 func (s *PostgresStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
 	vec := pgvector.NewVector(queryVector)
 
-	query := `SELECT id, file_path, start_line, end_line, content, vector, hash, updated_at,
+	query := `SELECT id, file_path, start_line, end_line, content, vector, hash, COALESCE(model, ''), updated_at,
 		1 - (vector <=> $1) as score
 	FROM chunks
 	WHERE project_id = $2`
@@ -155,6 +270,16 @@ func (s *PostgresStore) Search(ctx context.Context, queryVector []float32, limit
 		args = append(args, opts.PathPrefix+"%")
 		nextParam++
 	}
+	if opts.Model != "" {
+		query += ` AND COALESCE(model, '') = $` + fmt.Sprintf("%d", nextParam)
+		args = append(args, opts.Model)
+		nextParam++
+	}
+	if opts.ExcludeModel != "" {
+		query += ` AND COALESCE(model, '') <> $` + fmt.Sprintf("%d", nextParam)
+		args = append(args, opts.ExcludeModel)
+		nextParam++
+	}
 
 	query += ` ORDER BY vector <=> $1
 	LIMIT $` + fmt.Sprintf("%d", nextParam)
@@ -174,7 +299,7 @@ func (s *PostgresStore) Search(ctx context.Context, queryVector []float32, limit
 
 		if err := rows.Scan(
 			&chunk.ID, &chunk.FilePath, &chunk.StartLine, &chunk.EndLine,
-			&chunk.Content, &vec, &chunk.Hash, &chunk.UpdatedAt, &score,
+			&chunk.Content, &vec, &chunk.Hash, &chunk.Model, &chunk.UpdatedAt, &score,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
@@ -210,13 +335,7 @@ func (s *PostgresStore) GetDocument(ctx context.Context, filePath string) (*Docu
 }
 
 func (s *PostgresStore) SaveDocument(ctx context.Context, doc Document) error {
-	_, err := s.pool.Exec(ctx,
-		`INSERT INTO documents (path, project_id, hash, mod_time, chunk_ids)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (project_id, path) DO UPDATE SET
-			hash = EXCLUDED.hash,
-			mod_time = EXCLUDED.mod_time,
-			chunk_ids = EXCLUDED.chunk_ids`,
+	_, err := s.pool.Exec(ctx, upsertDocumentSQL,
 		doc.Path, s.projectID, doc.Hash, doc.ModTime, doc.ChunkIDs,
 	)
 	if err != nil {
@@ -328,7 +447,7 @@ func (s *PostgresStore) ListFilesWithStats(ctx context.Context) ([]FileStats, er
 
 func (s *PostgresStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chunk, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, file_path, start_line, end_line, content, hash, updated_at
+		`SELECT id, file_path, start_line, end_line, content, hash, COALESCE(content_hash, ''), COALESCE(model, ''), updated_at
 		FROM chunks WHERE project_id = $1 AND file_path = $2
 		ORDER BY start_line`,
 		s.projectID, filePath,
@@ -341,7 +460,7 @@ func (s *PostgresStore) GetChunksForFile(ctx context.Context, filePath string) (
 	var chunks []Chunk
 	for rows.Next() {
 		var c Chunk
-		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.UpdatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.ContentHash, &c.Model, &c.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan chunk: %w", err)
 		}
 		chunks = append(chunks, c)
@@ -352,7 +471,7 @@ func (s *PostgresStore) GetChunksForFile(ctx context.Context, filePath string) (
 
 func (s *PostgresStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, file_path, start_line, end_line, content, hash, updated_at
+		`SELECT id, file_path, start_line, end_line, content, hash, COALESCE(model, ''), updated_at
 		FROM chunks WHERE project_id = $1`,
 		s.projectID,
 	)
@@ -364,7 +483,7 @@ func (s *PostgresStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 	var chunks []Chunk
 	for rows.Next() {
 		var c Chunk
-		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.UpdatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.Model, &c.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan chunk: %w", err)
 		}
 		chunks = append(chunks, c)
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -73,6 +74,24 @@ func NewQdrantStore(ctx context.Context, endpoint string, port int, useTLS bool,
 	return store, nil
 }
 
+// Ping checks that the Qdrant server is reachable.
+func (s *QdrantStore) Ping(ctx context.Context) error {
+	if _, err := s.client.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("failed to reach qdrant: %w", err)
+	}
+	return nil
+}
+
+// Reconnect makes the gRPC connection retry now instead of waiting out its
+// connection backoff, and checks that the server answers.
+func (s *QdrantStore) Reconnect(ctx context.Context) error {
+	if conn := s.client.GetConnection(); conn != nil {
+		conn.ResetConnectBackoff()
+		conn.Connect()
+	}
+	return s.Ping(ctx)
+}
+
 func (s *QdrantStore) ensureCollection(ctx context.Context) error {
 	exists, err := s.client.CollectionExists(ctx, s.collectionName)
 	if err != nil {
@@ -93,6 +112,14 @@ func (s *QdrantStore) ensureCollection(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("failed to create collection: %w", err)
 		}
+	} else {
+		info, err := s.client.GetCollectionInfo(ctx, s.collectionName)
+		if err != nil {
+			return fmt.Errorf("failed to get collection info: %w", err)
+		}
+		if err := checkCollectionVectors(s.collectionName, info.GetConfig().GetParams().GetVectorsConfig(), s.dimensions); err != nil {
+			return err
+		}
 	}
 
 	// Create field index for content_hash to enable efficient lookups.
@@ -102,7 +129,36 @@ func (s *QdrantStore) ensureCollection(ctx context.Context) error {
 		FieldName:      "content_hash",
 		FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeKeyword),
 	})
+	// The same goes for dirs, which DeleteByProject filters on.
+	_, _ = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+		CollectionName: s.collectionName,
+		FieldName:      "dirs",
+		FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeKeyword),
+	})
+
+	return nil
+}
 
+// checkCollectionVectors checks that an existing collection stores a single
+// unnamed vector of the configured size, as grepai writes points that way.
+// Searching a collection built by another embedding model would fail on
+// every query, so the mismatch is reported when the store is opened.
+func checkCollectionVectors(collection string, vectors *qdrant.VectorsConfig, dimensions int) error {
+	if named := vectors.GetParamsMap().GetMap(); len(named) > 0 {
+		names := make([]string, 0, len(named))
+		for name, params := range named {
+			names = append(names, fmt.Sprintf("%s (%d)", name, params.GetSize()))
+		}
+		sort.Strings(names)
+		return fmt.Errorf("collection %q uses named vectors %s but grepai requires a single unnamed vector; use another collection", collection, strings.Join(names, ", "))
+	}
+	params := vectors.GetParams()
+	if params == nil || dimensions <= 0 {
+		return nil
+	}
+	if size := int(params.GetSize()); size != dimensions {
+		return fmt.Errorf("collection %q has %d-dimensional vectors but the embedder is configured for %d; set embedder.dimensions to %d or use another collection", collection, size, dimensions, size)
+	}
 	return nil
 }
 
@@ -151,6 +207,37 @@ func (s *QdrantStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
 	return nil
 }
 
+// ReplaceFile replaces a file's points without a window where the file has
+// none: the new points are upserted first, then the file's other points
+// deleted. Both steps are idempotent, so a crash between them leaves stale
+// points that the next replacement of the file removes.
+func (s *QdrantStore) ReplaceFile(ctx context.Context, doc Document, chunks []Chunk) error {
+	if err := s.SaveChunks(ctx, chunks); err != nil {
+		return err
+	}
+
+	filter := &qdrant.Filter{
+		Must: []*qdrant.Condition{qdrant.NewMatch("file_path", doc.Path)},
+	}
+	if len(chunks) > 0 {
+		ids := make([]*qdrant.PointId, len(chunks))
+		for i, chunk := range chunks {
+			ids[i] = qdrant.NewID(s.getUUIDForChunk(chunk.ID).String())
+		}
+		filter.MustNot = []*qdrant.Condition{qdrant.NewHasID(ids...)}
+	}
+
+	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: s.collectionName,
+		Points:         qdrant.NewPointsSelectorFilter(filter),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete stale points: %w", err)
+	}
+
+	return nil
+}
+
 func (s *QdrantStore) buildChunkPayload(chunk Chunk) (map[string]*qdrant.Value, error) {
 	payload := make(map[string]*qdrant.Value)
 
@@ -184,7 +271,13 @@ func (s *QdrantStore) buildChunkPayload(chunk Chunk) (map[string]*qdrant.Value,
 		return nil, fmt.Errorf("failed to create updated_at value: %w", err)
 	}
 
+	dirsVal, err := qdrant.NewValue(qdrantDirs(chunk.FilePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dirs value: %w", err)
+	}
+
 	payload["file_path"] = filePathVal
+	payload["dirs"] = dirsVal
 	payload["start_line"] = startLineVal
 	payload["end_line"] = endLineVal
 	payload["content"] = contentVal
@@ -199,14 +292,85 @@ func (s *QdrantStore) buildChunkPayload(chunk Chunk) (map[string]*qdrant.Value,
 		payload["content_hash"] = contentHashVal
 	}
 
+	if chunk.Model != "" {
+		modelVal, err := qdrant.NewValue(chunk.Model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create model value: %w", err)
+		}
+		payload["model"] = modelVal
+	}
+
 	return payload, nil
 }
 
+// qdrantDirs returns the directories of filePath, outermost first and each
+// with a trailing slash: "ws/api/main.go" is in "ws/" and "ws/api/". Qdrant
+// has no prefix match on keywords, so points store them to be matched by
+// directory.
+func qdrantDirs(filePath string) []interface{} {
+	dirs := []interface{}{}
+	for i, r := range filePath {
+		if r == '/' {
+			dirs = append(dirs, filePath[:i+1])
+		}
+	}
+	return dirs
+}
+
 func (s *QdrantStore) DeleteByFile(ctx context.Context, filePath string) error {
+	return s.deleteMatching(ctx, qdrant.NewMatch("file_path", filePath))
+}
+
+func (s *QdrantStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	return s.deleteMatching(ctx, qdrant.NewMatchKeywords("file_path", filePaths...))
+}
+
+func (s *QdrantStore) DeleteChunks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	pointIDs := make([]*qdrant.PointId, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = qdrant.NewID(s.getUUIDForChunk(id).String())
+	}
+	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: s.collectionName,
+		Points:         qdrant.NewPointsSelectorIDs(pointIDs),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+	return nil
+}
+
+// DeleteByProject deletes the points of the files under project with a
+// filter on their directories. Points saved before they stored their
+// directories are listed with a filter on that and deleted by file.
+func (s *QdrantStore) DeleteByProject(ctx context.Context, project string) error {
+	prefix := projectPrefix(project)
+	if err := s.deleteMatching(ctx, qdrant.NewMatch("dirs", prefix)); err != nil {
+		return err
+	}
+	paths, err := s.filePaths(ctx, qdrant.NewIsEmpty("dirs"))
+	if err != nil {
+		return err
+	}
+	var matching []string
+	for _, path := range paths {
+		if strings.HasPrefix(path, prefix) {
+			matching = append(matching, path)
+		}
+	}
+	return s.DeleteByFiles(ctx, matching)
+}
+
+// deleteMatching deletes the points matching cond.
+func (s *QdrantStore) deleteMatching(ctx context.Context, cond *qdrant.Condition) error {
 	filter := &qdrant.Filter{
-		Must: []*qdrant.Condition{
-			qdrant.NewMatch("file_path", filePath),
-		},
+		Must: []*qdrant.Condition{cond},
 	}
 
 	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
@@ -220,6 +384,52 @@ func (s *QdrantStore) DeleteByFile(ctx context.Context, filePath string) error {
 	return nil
 }
 
+// filePaths returns the distinct file paths of the points matching cond,
+// scrolling through all of them.
+func (s *QdrantStore) filePaths(ctx context.Context, cond *qdrant.Condition) ([]string, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	var offset *qdrant.PointId
+	for {
+		points, next, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: s.collectionName,
+			Filter:         &qdrant.Filter{Must: []*qdrant.Condition{cond}},
+			Offset:         offset,
+			Limit:          qdrant.PtrOf(uint32(1000)),
+			WithPayload:    qdrant.NewWithPayloadInclude("file_path"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list file paths: %w", err)
+		}
+		for _, point := range points {
+			if val, ok := point.Payload["file_path"]; ok && !seen[val.GetStringValue()] {
+				seen[val.GetStringValue()] = true
+				paths = append(paths, val.GetStringValue())
+			}
+		}
+		if next == nil {
+			return paths, nil
+		}
+		offset = next
+	}
+}
+
+// qdrantModelFilter returns the payload filter for the model filters of
+// opts, or nil when there are none.
+func qdrantModelFilter(opts SearchOptions) *qdrant.Filter {
+	if opts.Model == "" && opts.ExcludeModel == "" {
+		return nil
+	}
+	filter := &qdrant.Filter{}
+	if opts.Model != "" {
+		filter.Must = []*qdrant.Condition{qdrant.NewMatch("model", opts.Model)}
+	}
+	if opts.ExcludeModel != "" {
+		filter.MustNot = []*qdrant.Condition{qdrant.NewMatch("model", opts.ExcludeModel)}
+	}
+	return filter
+}
+
 func (s *QdrantStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be positive, got: %d", limit)
@@ -244,8 +454,9 @@ func (s *QdrantStore) Search(ctx context.Context, queryVector []float32, limit i
 	searchResult, err := s.client.Query(ctx, &qdrant.QueryPoints{
 		CollectionName: s.collectionName,
 		Query:          qdrant.NewQuery(queryVector...),
+		Filter:         qdrantModelFilter(opts),
 		Limit:          qdrant.PtrOf(fetchLimitU64),
-		WithPayload:    qdrant.NewWithPayloadInclude("file_path", "start_line", "end_line", "content", "hash", "updated_at"),
+		WithPayload:    qdrant.NewWithPayloadInclude("file_path", "start_line", "end_line", "content", "hash", "model", "updated_at"),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
@@ -259,6 +470,9 @@ func (s *QdrantStore) Search(ctx context.Context, queryVector []float32, limit i
 		if opts.PathPrefix != "" && !strings.HasPrefix(chunk.FilePath, opts.PathPrefix) {
 			continue
 		}
+		if !opts.keepsModel(chunk.Model) {
+			continue
+		}
 
 		results = append(results, SearchResult{
 			Chunk: *chunk,
@@ -300,6 +514,9 @@ func (s *QdrantStore) parseChunkPayload(payload map[string]*qdrant.Value) *Chunk
 	if val, ok := payload["content_hash"]; ok {
 		chunk.ContentHash = val.GetStringValue()
 	}
+	if val, ok := payload["model"]; ok {
+		chunk.Model = val.GetStringValue()
+	}
 
 	return chunk
 }
@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -72,6 +74,37 @@ func TestParseHost(t *testing.T) {
 	}
 }
 
+func TestCheckCollectionVectors(t *testing.T) {
+	tests := []struct {
+		name    string
+		vectors *qdrant.VectorsConfig
+		wantErr string
+	}{
+		{"matching size", qdrant.NewVectorsConfig(&qdrant.VectorParams{Size: 768}), ""},
+		{"size mismatch", qdrant.NewVectorsConfig(&qdrant.VectorParams{Size: 1536}), "has 1536-dimensional vectors but the embedder is configured for 768"},
+		{"named vectors", qdrant.NewVectorsConfigMap(map[string]*qdrant.VectorParams{
+			"text":  {Size: 768},
+			"image": {Size: 512},
+		}), "named vectors image (512), text (768)"},
+		{"no config", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCollectionVectors("grepai", tt.vectors, 768)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 // TestGetUUIDForChunk tests that UUID generation is deterministic for the same chunk ID
 func TestGetUUIDForChunk(t *testing.T) {
 	store := &QdrantStore{}
@@ -200,6 +233,17 @@ func TestParseChunkPayload(t *testing.T) {
 	}
 }
 
+func TestQdrantDirs(t *testing.T) {
+	got := qdrantDirs("ws/api/src/main.go")
+	want := []interface{}{"ws/", "ws/api/", "ws/api/src/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("qdrantDirs() = %v, want %v", got, want)
+	}
+	if got := qdrantDirs("main.go"); len(got) != 0 {
+		t.Errorf("expected no directories for a root file, got %v", got)
+	}
+}
+
 // TestBuildChunkPayload tests building of Qdrant payloads from chunks
 func TestBuildChunkPayload(t *testing.T) {
 	store := &QdrantStore{}
@@ -0,0 +1,221 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HealthChecker is an optional interface for stores backed by a database
+// server, letting long-running sessions survive a server restart.
+type HealthChecker interface {
+	// Ping checks that the server is reachable.
+	Ping(ctx context.Context) error
+
+	// Reconnect drops the connections to the server and opens new ones,
+	// returning an error while the server is still unreachable.
+	Reconnect(ctx context.Context) error
+}
+
+// ReconnectingStore wraps a store backed by a server. When an operation
+// fails while the server is unreachable, it reconnects with backoff until
+// the server is back or the operation's context is done, then retries the
+// operation once. Errors returned while the server is reachable are passed
+// through.
+type ReconnectingStore struct {
+	store   VectorStore
+	health  HealthChecker
+	backoff func(attempt int) time.Duration
+	onRetry func(attempt int, wait time.Duration, err error)
+	mu      sync.Mutex // one operation reconnects at a time
+}
+
+// NewReconnectingStore wraps st when it implements HealthChecker and returns
+// it unchanged otherwise. backoff gives the wait before each reconnection
+// attempt, counted from 1; onRetry, when set, is called before each wait.
+func NewReconnectingStore(st VectorStore, backoff func(attempt int) time.Duration, onRetry func(attempt int, wait time.Duration, err error)) VectorStore {
+	health, ok := st.(HealthChecker)
+	if !ok {
+		return st
+	}
+	return &ReconnectingStore{store: st, health: health, backoff: backoff, onRetry: onRetry}
+}
+
+// do runs op, retrying it once after a reconnection when it failed because
+// the server was unreachable.
+func (r *ReconnectingStore) do(ctx context.Context, op func() error) error {
+	err := op()
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if r.health.Ping(ctx) == nil {
+		return err
+	}
+	if recoverErr := r.reconnect(ctx, err); recoverErr != nil {
+		return err
+	}
+	return op()
+}
+
+// reconnect waits for the server to come back. cause is the error that
+// showed it was unreachable.
+func (r *ReconnectingStore) reconnect(ctx context.Context, cause error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another operation may have reconnected while this one waited.
+	if r.health.Ping(ctx) == nil {
+		return nil
+	}
+	for attempt := 1; ; attempt++ {
+		wait := r.backoff(attempt)
+		if r.onRetry != nil {
+			r.onRetry(attempt, wait, cause)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		err := r.health.Reconnect(ctx)
+		if err == nil {
+			return nil
+		}
+		cause = err
+	}
+}
+
+func (r *ReconnectingStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	return r.do(ctx, func() error { return r.store.SaveChunks(ctx, chunks) })
+}
+
+func (r *ReconnectingStore) DeleteByFile(ctx context.Context, filePath string) error {
+	return r.do(ctx, func() error { return r.store.DeleteByFile(ctx, filePath) })
+}
+
+func (r *ReconnectingStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	return r.do(ctx, func() error { return r.store.DeleteByFiles(ctx, filePaths) })
+}
+
+func (r *ReconnectingStore) DeleteChunks(ctx context.Context, ids []string) error {
+	return r.do(ctx, func() error { return r.store.DeleteChunks(ctx, ids) })
+}
+
+func (r *ReconnectingStore) DeleteByProject(ctx context.Context, project string) error {
+	return r.do(ctx, func() error { return r.store.DeleteByProject(ctx, project) })
+}
+
+// ReplaceFile replaces the file in the wrapped store, as one operation when
+// it is a FileReplacer.
+func (r *ReconnectingStore) ReplaceFile(ctx context.Context, doc Document, chunks []Chunk) error {
+	return r.do(ctx, func() error { return ReplaceFile(ctx, r.store, doc, chunks) })
+}
+
+func (r *ReconnectingStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	var results []SearchResult
+	err := r.do(ctx, func() (err error) {
+		results, err = r.store.Search(ctx, queryVector, limit, opts)
+		return err
+	})
+	return results, err
+}
+
+func (r *ReconnectingStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
+	var doc *Document
+	err := r.do(ctx, func() (err error) {
+		doc, err = r.store.GetDocument(ctx, filePath)
+		return err
+	})
+	return doc, err
+}
+
+func (r *ReconnectingStore) SaveDocument(ctx context.Context, doc Document) error {
+	return r.do(ctx, func() error { return r.store.SaveDocument(ctx, doc) })
+}
+
+func (r *ReconnectingStore) DeleteDocument(ctx context.Context, filePath string) error {
+	return r.do(ctx, func() error { return r.store.DeleteDocument(ctx, filePath) })
+}
+
+func (r *ReconnectingStore) ListDocuments(ctx context.Context) ([]string, error) {
+	var paths []string
+	err := r.do(ctx, func() (err error) {
+		paths, err = r.store.ListDocuments(ctx)
+		return err
+	})
+	return paths, err
+}
+
+func (r *ReconnectingStore) Load(ctx context.Context) error {
+	return r.do(ctx, func() error { return r.store.Load(ctx) })
+}
+
+func (r *ReconnectingStore) Persist(ctx context.Context) error {
+	return r.do(ctx, func() error { return r.store.Persist(ctx) })
+}
+
+func (r *ReconnectingStore) Close() error {
+	return r.store.Close()
+}
+
+func (r *ReconnectingStore) GetStats(ctx context.Context) (*IndexStats, error) {
+	var stats *IndexStats
+	err := r.do(ctx, func() (err error) {
+		stats, err = r.store.GetStats(ctx)
+		return err
+	})
+	return stats, err
+}
+
+func (r *ReconnectingStore) ListFilesWithStats(ctx context.Context) ([]FileStats, error) {
+	var files []FileStats
+	err := r.do(ctx, func() (err error) {
+		files, err = r.store.ListFilesWithStats(ctx)
+		return err
+	})
+	return files, err
+}
+
+func (r *ReconnectingStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chunk, error) {
+	var chunks []Chunk
+	err := r.do(ctx, func() (err error) {
+		chunks, err = r.store.GetChunksForFile(ctx, filePath)
+		return err
+	})
+	return chunks, err
+}
+
+func (r *ReconnectingStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
+	var chunks []Chunk
+	err := r.do(ctx, func() (err error) {
+		chunks, err = r.store.GetAllChunks(ctx)
+		return err
+	})
+	return chunks, err
+}
+
+// LookupByContentHash looks vectors up in the wrapped store when it is an
+// EmbeddingCache, and finds none otherwise.
+func (r *ReconnectingStore) LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error) {
+	cache, ok := r.store.(EmbeddingCache)
+	if !ok {
+		return nil, false, nil
+	}
+	var vector []float32
+	var found bool
+	err := r.do(ctx, func() (err error) {
+		vector, found, err = cache.LookupByContentHash(ctx, contentHash)
+		return err
+	})
+	return vector, found, err
+}
+
+func (r *ReconnectingStore) Ping(ctx context.Context) error {
+	return r.health.Ping(ctx)
+}
+
+func (r *ReconnectingStore) Reconnect(ctx context.Context) error {
+	return r.health.Reconnect(ctx)
+}
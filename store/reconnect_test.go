@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// flakyStore is a GOB store behind a server that is down until Reconnect
+// has been called upAfter times.
+type flakyStore struct {
+	*GOBStore
+	down       bool
+	reconnects int
+	upAfter    int
+}
+
+var errUnreachable = errors.New("connection refused")
+
+func (f *flakyStore) Ping(ctx context.Context) error {
+	if f.down {
+		return errUnreachable
+	}
+	return nil
+}
+
+func (f *flakyStore) Reconnect(ctx context.Context) error {
+	f.reconnects++
+	if f.reconnects >= f.upAfter {
+		f.down = false
+	}
+	return f.Ping(ctx)
+}
+
+func (f *flakyStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	if f.down {
+		return errUnreachable
+	}
+	return f.GOBStore.SaveChunks(ctx, chunks)
+}
+
+func TestReconnectingStore(t *testing.T) {
+	ctx := context.Background()
+	flaky := &flakyStore{GOBStore: NewGOBStore(filepath.Join(t.TempDir(), "index.gob")), down: true, upAfter: 3}
+
+	var waits []time.Duration
+	st := NewReconnectingStore(flaky, func(attempt int) time.Duration {
+		return time.Duration(attempt) * time.Millisecond
+	}, func(attempt int, wait time.Duration, err error) {
+		waits = append(waits, wait)
+	})
+
+	if err := st.SaveChunks(ctx, []Chunk{{ID: "a.go_0", FilePath: "a.go"}}); err != nil {
+		t.Fatalf("expected the save to succeed after reconnecting, got %v", err)
+	}
+	if flaky.reconnects != 3 || len(waits) != 3 || waits[2] != 3*time.Millisecond {
+		t.Errorf("expected 3 reconnection attempts with growing waits, got %d and %v", flaky.reconnects, waits)
+	}
+	if _, chunks := flaky.Stats(); chunks != 1 {
+		t.Errorf("expected the chunk to be saved, got %d chunks", chunks)
+	}
+
+	// A server that stays down fails the operation once the context ends.
+	flaky.down, flaky.reconnects, flaky.upAfter = true, 0, 1000
+	cancelCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := st.SaveChunks(cancelCtx, nil); !errors.Is(err, errUnreachable) {
+		t.Errorf("expected the original error, got %v", err)
+	}
+
+	if NewReconnectingStore(flaky.GOBStore, nil, nil) != VectorStore(flaky.GOBStore) {
+		t.Error("expected stores without health checks to be returned unchanged")
+	}
+}
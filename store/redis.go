@@ -0,0 +1,1028 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// RedisStore stores chunks as Redis hashes indexed by a RediSearch vector
+// index. Each file's chunk keys are kept in a set so they can be deleted
+// without a query, and every key can expire after a TTL for short-lived
+// indexes such as CI runs.
+type RedisStore struct {
+	pool       *redisPool
+	index      string
+	prefix     string
+	dimensions int
+	ttl        time.Duration
+}
+
+// RedisIndexName turns name, such as a project path, into a RediSearch index
+// name. Keys of the index are prefixed with the name and a colon.
+func RedisIndexName(name string) string {
+	var b strings.Builder
+	b.WriteString("grepai_")
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+var redisChunkFields = []string{"chunk_id", "file_path", "start_line", "end_line", "content", "hash", "content_hash", "model", "updated_at"}
+
+// NewRedisStore connects to the Redis server at rawURL, such as
+// redis://:password@localhost:6379/0 or rediss:// for TLS, and creates the
+// vector index unless it exists. A positive ttl makes every key written by
+// the store expire after that duration.
+func NewRedisStore(ctx context.Context, rawURL, index string, dimensions int, ttl time.Duration) (*RedisStore, error) {
+	if index == "" {
+		return nil, fmt.Errorf("redis index name is required")
+	}
+	pool, err := newRedisPool(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &RedisStore{
+		pool:       pool,
+		index:      index,
+		prefix:     index + ":",
+		dimensions: dimensions,
+		ttl:        ttl,
+	}
+	if err := s.ensureIndex(ctx); err != nil {
+		pool.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *RedisStore) chunkKey(chunkID string) string { return s.prefix + "chunk:" + chunkID }
+func (s *RedisStore) fileKey(filePath string) string { return s.prefix + "file:" + filePath }
+func (s *RedisStore) docKey(filePath string) string  { return s.prefix + "doc:" + filePath }
+func (s *RedisStore) docsKey() string                { return s.prefix + "docs" }
+
+func (s *RedisStore) ensureIndex(ctx context.Context) error {
+	info, err := s.pool.do(ctx, "FT.INFO", s.index)
+	if err == nil {
+		if err := s.checkDimensions(info); err != nil {
+			return err
+		}
+		return s.ensureModelField(ctx, info)
+	}
+	if !isRedisUnknownIndex(err) {
+		return fmt.Errorf("failed to check index %s: %w", s.index, err)
+	}
+	if s.dimensions <= 0 {
+		return fmt.Errorf("dimensions must be positive, got: %d", s.dimensions)
+	}
+
+	_, err = s.pool.do(ctx, "FT.CREATE", s.index, "ON", "HASH", "PREFIX", "1", s.prefix+"chunk:",
+		"SCHEMA",
+		"content_hash", "TAG",
+		"model", "TAG",
+		"vector", "VECTOR", "HNSW", "6", "TYPE", "FLOAT32", "DIM", strconv.Itoa(s.dimensions), "DISTANCE_METRIC", "COSINE",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create index %s: %w", s.index, err)
+	}
+	return nil
+}
+
+// ensureModelField adds the model tag, which searches filter on, to an
+// index created before it was part of the schema.
+func (s *RedisStore) ensureModelField(ctx context.Context, info any) error {
+	if redisIndexHasAttribute(info, "model") {
+		return nil
+	}
+	if _, err := s.pool.do(ctx, "FT.ALTER", s.index, "SCHEMA", "ADD", "model", "TAG"); err != nil {
+		return fmt.Errorf("failed to add model to index %s: %w", s.index, err)
+	}
+	return nil
+}
+
+// redisIndexHasAttribute reports whether an FT.INFO reply lists an
+// attribute named name.
+func redisIndexHasAttribute(info any, name string) bool {
+	fields, ok := info.([]any)
+	if !ok {
+		return false
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, _ := fields[i].(string); key != "attributes" {
+			continue
+		}
+		attrs, _ := fields[i+1].([]any)
+		for _, attr := range attrs {
+			items, _ := attr.([]any)
+			for j := 0; j+1 < len(items); j += 2 {
+				key, _ := items[j].(string)
+				value, _ := items[j+1].(string)
+				if (key == "identifier" || key == "attribute") && value == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// checkDimensions compares the vector dimension of an existing index, as
+// reported by FT.INFO, with the configured dimensions.
+func (s *RedisStore) checkDimensions(info any) error {
+	dim := redisIndexDim(info)
+	if dim == 0 || s.dimensions <= 0 || dim == s.dimensions {
+		return nil
+	}
+	return fmt.Errorf("index %q has %d-dimensional vectors but the embedder is configured for %d; set embedder.dimensions to %d or use another index", s.index, dim, s.dimensions, dim)
+}
+
+// redisIndexDim finds the dimension of the vector attribute in an FT.INFO
+// reply, or returns 0. The attribute layout differs between RediSearch
+// versions, so any DIM key under the attributes is accepted.
+func redisIndexDim(info any) int {
+	fields, ok := info.([]any)
+	if !ok {
+		return 0
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if name, _ := fields[i].(string); name != "attributes" {
+			continue
+		}
+		attrs, _ := fields[i+1].([]any)
+		for _, attr := range attrs {
+			if dim := findRedisDim(attr); dim > 0 {
+				return dim
+			}
+		}
+	}
+	return 0
+}
+
+func findRedisDim(v any) int {
+	items, ok := v.([]any)
+	if !ok {
+		return 0
+	}
+	for i, item := range items {
+		if name, _ := item.(string); strings.EqualFold(name, "dim") && i+1 < len(items) {
+			switch d := items[i+1].(type) {
+			case int64:
+				return int(d)
+			case string:
+				n, _ := strconv.Atoi(d)
+				return n
+			}
+		}
+		if dim := findRedisDim(item); dim > 0 {
+			return dim
+		}
+	}
+	return 0
+}
+
+// expireCommands returns the commands applying the store's TTL to keys,
+// or none without a TTL.
+func (s *RedisStore) expireCommands(keys ...string) [][]any {
+	if s.ttl <= 0 {
+		return nil
+	}
+	seconds := strconv.FormatInt(int64(math.Ceil(s.ttl.Seconds())), 10)
+	cmds := make([][]any, len(keys))
+	for i, key := range keys {
+		cmds[i] = []any{"EXPIRE", key, seconds}
+	}
+	return cmds
+}
+
+func encodeRedisVector(vector []float32) string {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return string(buf)
+}
+
+func decodeRedisVector(data string) []float32 {
+	if len(data) == 0 || len(data)%4 != 0 {
+		return nil
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32([]byte(data[4*i : 4*i+4])))
+	}
+	return vector
+}
+
+func (s *RedisStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	var cmds [][]any
+	for _, chunk := range chunks {
+		key := s.chunkKey(chunk.ID)
+		cmds = append(cmds, []any{"HSET", key,
+			"chunk_id", chunk.ID,
+			"file_path", chunk.FilePath,
+			"start_line", strconv.Itoa(chunk.StartLine),
+			"end_line", strconv.Itoa(chunk.EndLine),
+			"content", sanitizeUTF8(chunk.Content),
+			"hash", chunk.Hash,
+			"content_hash", chunk.ContentHash,
+			"model", chunk.Model,
+			"updated_at", chunk.UpdatedAt.Format(time.RFC3339Nano),
+			"vector", encodeRedisVector(chunk.Vector),
+		}, []any{"SADD", s.fileKey(chunk.FilePath), key})
+		cmds = append(cmds, s.expireCommands(key, s.fileKey(chunk.FilePath))...)
+	}
+	if _, err := s.pool.pipeline(ctx, cmds); err != nil {
+		return fmt.Errorf("failed to save chunks: %w", err)
+	}
+	return nil
+}
+
+// parseRedisChunk builds a chunk from alternating field names and values.
+func parseRedisChunk(fields []any) Chunk {
+	var chunk Chunk
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, _ := fields[i].(string)
+		value, _ := fields[i+1].(string)
+		switch name {
+		case "chunk_id":
+			chunk.ID = value
+		case "file_path":
+			chunk.FilePath = value
+		case "start_line":
+			chunk.StartLine, _ = strconv.Atoi(value)
+		case "end_line":
+			chunk.EndLine, _ = strconv.Atoi(value)
+		case "content":
+			chunk.Content = value
+		case "hash":
+			chunk.Hash = value
+		case "content_hash":
+			chunk.ContentHash = value
+		case "model":
+			chunk.Model = value
+		case "updated_at":
+			chunk.UpdatedAt, _ = time.Parse(time.RFC3339Nano, value)
+		case "vector":
+			chunk.Vector = decodeRedisVector(value)
+		}
+	}
+	return chunk
+}
+
+func (s *RedisStore) DeleteByFile(ctx context.Context, filePath string) error {
+	reply, err := s.pool.do(ctx, "SMEMBERS", s.fileKey(filePath))
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	keys := []any{"DEL", s.fileKey(filePath)}
+	for _, key := range redisStrings(reply) {
+		keys = append(keys, key)
+	}
+	if _, err := s.pool.do(ctx, keys...); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	if len(filePaths) == 0 {
+		return nil
+	}
+	members := make([][]any, len(filePaths))
+	for i, filePath := range filePaths {
+		members[i] = []any{"SMEMBERS", s.fileKey(filePath)}
+	}
+	replies, err := s.pool.pipeline(ctx, members)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	keys := []any{"DEL"}
+	for i, filePath := range filePaths {
+		keys = append(keys, s.fileKey(filePath))
+		for _, key := range redisStrings(replies[i]) {
+			keys = append(keys, key)
+		}
+	}
+	if _, err := s.pool.do(ctx, keys...); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+// DeleteChunks deletes the hashes of the chunks and removes them from the
+// sets of their files.
+func (s *RedisStore) DeleteChunks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	gets := make([][]any, len(ids))
+	for i, id := range ids {
+		gets[i] = []any{"HGET", s.chunkKey(id), "file_path"}
+	}
+	replies, err := s.pool.pipeline(ctx, gets)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	del := []any{"DEL"}
+	var cmds [][]any
+	for i, id := range ids {
+		key := s.chunkKey(id)
+		if filePath, ok := replies[i].(string); ok {
+			cmds = append(cmds, []any{"SREM", s.fileKey(filePath), key})
+		}
+		del = append(del, key)
+	}
+	if _, err := s.pool.pipeline(ctx, append(cmds, del)); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) DeleteByProject(ctx context.Context, project string) error {
+	prefix := projectPrefix(project)
+	fileKeys, err := s.scanKeys(ctx, redisGlobEscape(s.fileKey(prefix))+"*")
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	filePaths := make([]string, len(fileKeys))
+	for i, key := range fileKeys {
+		filePaths[i] = strings.TrimPrefix(key, s.fileKey(""))
+	}
+	if err := s.DeleteByFiles(ctx, filePaths); err != nil {
+		return err
+	}
+
+	docs, err := s.ListDocuments(ctx)
+	if err != nil {
+		return err
+	}
+	del := []any{"DEL"}
+	srem := []any{"SREM", s.docsKey()}
+	for _, doc := range docs {
+		if strings.HasPrefix(doc, prefix) {
+			del = append(del, s.docKey(doc))
+			srem = append(srem, doc)
+		}
+	}
+	if len(srem) == 2 {
+		return nil
+	}
+	if _, err := s.pool.pipeline(ctx, [][]any{del, srem}); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got: %d", limit)
+	}
+
+	// Fetch more results to account for filtering by path prefix
+	fetchLimit := limit
+	if opts.PathPrefix != "" {
+		fetchLimit = limit * 2
+	}
+
+	args := []any{"FT.SEARCH", s.index, redisModelFilter(opts) + "=>[KNN " + strconv.Itoa(fetchLimit) + " @vector $vec AS score]",
+		"PARAMS", "2", "vec", encodeRedisVector(queryVector),
+		"SORTBY", "score", "LIMIT", "0", strconv.Itoa(fetchLimit),
+		"RETURN", strconv.Itoa(len(redisChunkFields) + 1)}
+	for _, field := range redisChunkFields {
+		args = append(args, field)
+	}
+	args = append(args, "score", "DIALECT", "2")
+
+	reply, err := s.pool.do(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	results := make([]SearchResult, 0, limit)
+	for _, fields := range redisSearchDocs(reply) {
+		chunk := parseRedisChunk(fields)
+		if opts.PathPrefix != "" && !strings.HasPrefix(chunk.FilePath, opts.PathPrefix) {
+			continue
+		}
+		if !opts.keepsModel(chunk.Model) {
+			continue
+		}
+		results = append(results, SearchResult{
+			Chunk: chunk,
+			// The score is the cosine distance, 1 - cosine similarity.
+			Score: 1 - float32(redisFieldFloat(fields, "score")),
+		})
+		if len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// redisModelFilter returns the pre-filter of a KNN query for the model
+// filters of opts, or * when there are none.
+func redisModelFilter(opts SearchOptions) string {
+	var parts []string
+	if opts.Model != "" {
+		parts = append(parts, "@model:{"+escapeRedisTag(opts.Model)+"}")
+	}
+	if opts.ExcludeModel != "" {
+		parts = append(parts, "-@model:{"+escapeRedisTag(opts.ExcludeModel)+"}")
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// escapeRedisTag escapes the punctuation and spaces RediSearch treats as
+// separators in a tag value.
+func escapeRedisTag(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// redisSearchDocs returns the field lists of the documents in an FT.SEARCH
+// reply: the total, then each key followed by its fields.
+func redisSearchDocs(reply any) [][]any {
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return nil
+	}
+	var docs [][]any
+	for i := 1; i+1 < len(items); i += 2 {
+		if fields, ok := items[i+1].([]any); ok {
+			docs = append(docs, fields)
+		}
+	}
+	return docs
+}
+
+func redisFieldFloat(fields []any, name string) float64 {
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, _ := fields[i].(string); key == name {
+			value, _ := fields[i+1].(string)
+			f, _ := strconv.ParseFloat(value, 64)
+			return f
+		}
+	}
+	return 0
+}
+
+func redisStrings(reply any) []string {
+	items, _ := reply.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
+func (s *RedisStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
+	reply, err := s.pool.do(ctx, "HGETALL", s.docKey(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	fields, _ := reply.([]any)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	doc := &Document{Path: filePath, ChunkIDs: []string{}}
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, _ := fields[i].(string)
+		value, _ := fields[i+1].(string)
+		switch name {
+		case "hash":
+			doc.Hash = value
+		case "mod_time":
+			doc.ModTime, _ = time.Parse(time.RFC3339Nano, value)
+		case "chunk_ids":
+			if value != "" {
+				doc.ChunkIDs = strings.Split(value, "\n")
+			}
+		}
+	}
+	return doc, nil
+}
+
+func (s *RedisStore) SaveDocument(ctx context.Context, doc Document) error {
+	key := s.docKey(doc.Path)
+	cmds := [][]any{
+		{"HSET", key,
+			"hash", doc.Hash,
+			"mod_time", doc.ModTime.Format(time.RFC3339Nano),
+			"chunk_ids", strings.Join(doc.ChunkIDs, "\n"),
+		},
+		{"SADD", s.docsKey(), doc.Path},
+	}
+	if _, err := s.pool.pipeline(ctx, append(cmds, s.expireCommands(key, s.docsKey())...)); err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) DeleteDocument(ctx context.Context, filePath string) error {
+	cmds := [][]any{{"DEL", s.docKey(filePath)}, {"SREM", s.docsKey(), filePath}}
+	if _, err := s.pool.pipeline(ctx, cmds); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisStore) ListDocuments(ctx context.Context) ([]string, error) {
+	reply, err := s.pool.do(ctx, "SMEMBERS", s.docsKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	paths := redisStrings(reply)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (s *RedisStore) Load(ctx context.Context) error {
+	return nil
+}
+
+func (s *RedisStore) Persist(ctx context.Context) error {
+	return nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.pool.close()
+}
+
+func (s *RedisStore) GetStats(ctx context.Context) (*IndexStats, error) {
+	info, err := s.pool.do(ctx, "FT.INFO", s.index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index info: %w", err)
+	}
+	files, err := s.pool.do(ctx, "SCARD", s.docsKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	stats := &IndexStats{LastUpdated: time.Now()}
+	if n, ok := files.(int64); ok {
+		stats.TotalFiles = int(n)
+	}
+	if fields, ok := info.([]any); ok {
+		for i := 0; i+1 < len(fields); i += 2 {
+			name, _ := fields[i].(string)
+			switch name {
+			case "num_docs":
+				stats.TotalChunks = redisInt(fields[i+1])
+			case "total_index_memory_sz_mb", "vector_index_sz_mb":
+				if mb, err := strconv.ParseFloat(fmt.Sprint(fields[i+1]), 64); err == nil {
+					stats.IndexSize += int64(mb * 1024 * 1024)
+				}
+			}
+		}
+	}
+	return stats, nil
+}
+
+func redisInt(v any) int {
+	switch n := v.(type) {
+	case int64:
+		return int(n)
+	case string:
+		i, _ := strconv.Atoi(n)
+		return i
+	}
+	return 0
+}
+
+func (s *RedisStore) ListFilesWithStats(ctx context.Context) ([]FileStats, error) {
+	paths, err := s.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]FileStats, 0, len(paths))
+	for _, path := range paths {
+		doc, err := s.GetDocument(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		stats = append(stats, FileStats{Path: path, ChunkCount: len(doc.ChunkIDs), ModTime: doc.ModTime})
+	}
+	return stats, nil
+}
+
+// chunksForKeys loads the chunks stored at keys, skipping expired ones.
+func (s *RedisStore) chunksForKeys(ctx context.Context, keys []string) ([]Chunk, error) {
+	cmds := make([][]any, len(keys))
+	for i, key := range keys {
+		cmds[i] = []any{"HGETALL", key}
+	}
+	replies, err := s.pool.pipeline(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]Chunk, 0, len(keys))
+	for _, reply := range replies {
+		if fields, _ := reply.([]any); len(fields) > 0 {
+			chunks = append(chunks, parseRedisChunk(fields))
+		}
+	}
+	return chunks, nil
+}
+
+func (s *RedisStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chunk, error) {
+	reply, err := s.pool.do(ctx, "SMEMBERS", s.fileKey(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	chunks, err := s.chunksForKeys(ctx, redisStrings(reply))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+func (s *RedisStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
+	keys, err := s.scanKeys(ctx, redisGlobEscape(s.prefix+"chunk:")+"*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all chunks: %w", err)
+	}
+	chunks, err := s.chunksForKeys(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// scanKeys returns the keys matching a glob pattern.
+func (s *RedisStore) scanKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := s.pool.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", "1000")
+		if err != nil {
+			return nil, err
+		}
+		page, _ := reply.([]any)
+		if len(page) != 2 {
+			return nil, fmt.Errorf("unexpected SCAN reply")
+		}
+		cursor, _ = page[0].(string)
+		keys = append(keys, redisStrings(page[1])...)
+		if cursor == "0" {
+			return keys, nil
+		}
+	}
+}
+
+func redisGlobEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// LookupByContentHash searches the index for a chunk with the content hash.
+func (s *RedisStore) LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error) {
+	// Content hashes are hex digests, which need no escaping in a tag query.
+	if contentHash == "" || strings.ContainsFunc(contentHash, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F')
+	}) {
+		return nil, false, nil
+	}
+
+	reply, err := s.pool.do(ctx, "FT.SEARCH", s.index, "@content_hash:{"+contentHash+"}",
+		"LIMIT", "0", "1", "RETURN", "1", "vector", "DIALECT", "2")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lookup by content hash: %w", err)
+	}
+	docs := redisSearchDocs(reply)
+	if len(docs) == 0 {
+		return nil, false, nil
+	}
+	vector := parseRedisChunk(docs[0]).Vector
+	if len(vector) == 0 {
+		return nil, false, nil
+	}
+	return vector, true, nil
+}
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+func isRedisUnknownIndex(err error) bool {
+	var rerr redisError
+	if !errors.As(err, &rerr) {
+		return false
+	}
+	msg := strings.ToLower(string(rerr))
+	return strings.Contains(msg, "unknown index") || strings.Contains(msg, "no such index")
+}
+
+// redisMaxIdle is how many connections redisPool keeps open between
+// commands.
+const redisMaxIdle = 4
+
+// redisPipelineSize is how many commands redisPool sends in one write.
+const redisPipelineSize = 256
+
+// redisPool hands out connections to the server at a URL, dialing new ones
+// as needed. A connection that fails with an I/O error or a timeout may have
+// a reply left unread, so it is closed rather than reused.
+type redisPool struct {
+	url string
+
+	mu     sync.Mutex
+	idle   []*respConn
+	closed bool
+}
+
+// newRedisPool connects to the server at rawURL, so a bad URL or an
+// unreachable server is reported at once.
+func newRedisPool(ctx context.Context, rawURL string) (*redisPool, error) {
+	c, err := dialRedis(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	p := &redisPool{url: rawURL}
+	p.put(c)
+	return p, nil
+}
+
+func (p *redisPool) get(ctx context.Context) (*respConn, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("redis store is closed")
+	}
+	if n := len(p.idle); n > 0 {
+		c := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return c, nil
+	}
+	p.mu.Unlock()
+	return dialRedis(ctx, p.url)
+}
+
+func (p *redisPool) put(c *respConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || len(p.idle) >= redisMaxIdle {
+		c.close()
+		return
+	}
+	p.idle = append(p.idle, c)
+}
+
+// do sends a command and returns its reply: a string, an int64, nil, a
+// []any of replies, or a redisError.
+func (p *redisPool) do(ctx context.Context, args ...any) (any, error) {
+	replies, err := p.pipeline(ctx, [][]any{args})
+	if err != nil {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// pipeline sends cmds in writes of up to redisPipelineSize commands and
+// returns their replies in order. An error reply fails the pipeline once
+// the replies of its batch are read.
+func (p *redisPool) pipeline(ctx context.Context, cmds [][]any) ([]any, error) {
+	replies := make([]any, 0, len(cmds))
+	for start := 0; start < len(cmds); start += redisPipelineSize {
+		end := min(start+redisPipelineSize, len(cmds))
+		c, err := p.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		batch, err := c.pipeline(ctx, cmds[start:end])
+		var rerr redisError
+		if err != nil && !errors.As(err, &rerr) {
+			c.close()
+			return nil, err
+		}
+		p.put(c)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, batch...)
+	}
+	return replies, nil
+}
+
+func (p *redisPool) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	var errs []error
+	for _, c := range p.idle {
+		errs = append(errs, c.close())
+	}
+	p.idle = nil
+	return errors.Join(errs...)
+}
+
+// respConn is a minimal RESP2 client connection, used by one goroutine at a
+// time.
+type respConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+func newRESPConn(conn net.Conn) *respConn {
+	return &respConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+}
+
+// dialRedis connects to the server at rawURL and authenticates and selects
+// the database it names.
+func dialRedis(ctx context.Context, rawURL string) (*respConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid redis URL %q: scheme must be redis or rediss", rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if u.Scheme == "rediss" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname()}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	c := newRESPConn(conn)
+
+	var setup [][]any
+	if password, ok := u.User.Password(); ok {
+		args := []any{"AUTH", password}
+		if user := u.User.Username(); user != "" {
+			args = []any{"AUTH", user, password}
+		}
+		setup = append(setup, args)
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" && db != "0" {
+		setup = append(setup, []any{"SELECT", db})
+	}
+	for _, args := range setup {
+		if _, err := c.pipeline(ctx, [][]any{args}); err != nil {
+			c.close()
+			if args[0] == "AUTH" {
+				return nil, fmt.Errorf("failed to authenticate to redis: %w", err)
+			}
+			return nil, fmt.Errorf("failed to select redis database %s: %w", args[1], err)
+		}
+	}
+	return c, nil
+}
+
+// pipeline writes cmds at once and reads their replies in order. Every
+// reply is read even after an error reply, which is then returned, so the
+// connection stays usable; any other error leaves it out of sync.
+func (c *respConn) pipeline(ctx context.Context, cmds [][]any) ([]any, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetDeadline(deadline)
+	} else {
+		_ = c.conn.SetDeadline(time.Time{})
+	}
+	// Cancelling ctx interrupts a blocked read or write.
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	for _, args := range cmds {
+		if err := writeRESPCommand(c.w, args); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	replies := make([]any, len(cmds))
+	var replyErr error
+	for i := range cmds {
+		reply, err := readRESPReply(c.r)
+		if err != nil {
+			return nil, err
+		}
+		if rerr, ok := reply.(redisError); ok && replyErr == nil {
+			replyErr = rerr
+		}
+		replies[i] = reply
+	}
+	if replyErr != nil {
+		return nil, replyErr
+	}
+	return replies, nil
+}
+
+func (c *respConn) close() error {
+	return c.conn.Close()
+}
+
+func writeRESPCommand(w *bufio.Writer, args []any) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		str := fmt.Sprint(arg)
+		if _, err := fmt.Fprintf(w, "$%d\r\n%s\r\n", len(str), str); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRESPReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("invalid RESP reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return redisError(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid RESP bulk length: %w", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid RESP array length: %w", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			item, err := readRESPReply(r)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unsupported RESP reply type %q", line[0])
+	}
+}
@@ -0,0 +1,520 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves the Redis and RediSearch commands used by RedisStore,
+// keeping hashes and sets in memory. FT.SEARCH returns every chunk hash with
+// a fixed score.
+type fakeRedis struct {
+	mu       sync.Mutex
+	hashes   map[string]map[string]string
+	sets     map[string]map[string]bool
+	ttls     map[string]string
+	indexDim string
+	commands []string
+	searches []string
+	conns    int
+}
+
+func newFakeRedis(t *testing.T) (*fakeRedis, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeRedis{
+		hashes: make(map[string]map[string]string),
+		sets:   make(map[string]map[string]bool),
+		ttls:   make(map[string]string),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.conns++
+			f.mu.Unlock()
+			go f.serve(conn)
+		}
+	}()
+	return f, "redis://" + ln.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := readRESPReply(r)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		if args[0] == "SLOW" {
+			// Reply after the client has given up.
+			time.Sleep(100 * time.Millisecond)
+		}
+		f.mu.Lock()
+		out := f.handle(args)
+		f.mu.Unlock()
+		if _, err := conn.Write([]byte(encodeRESP(out))); err != nil {
+			return
+		}
+	}
+}
+
+func encodeRESP(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "$-1\r\n"
+	case redisError:
+		return "-" + string(v) + "\r\n"
+	case int:
+		return fmt.Sprintf(":%d\r\n", v)
+	case string:
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case []any:
+		var b strings.Builder
+		fmt.Fprintf(&b, "*%d\r\n", len(v))
+		for _, item := range v {
+			b.WriteString(encodeRESP(item))
+		}
+		return b.String()
+	}
+	panic(fmt.Sprintf("unsupported reply %T", v))
+}
+
+func (f *fakeRedis) handle(args []string) any {
+	f.commands = append(f.commands, args[0])
+	switch strings.ToUpper(args[0]) {
+	case "FT.INFO":
+		if f.indexDim == "" {
+			return redisError("Unknown index name")
+		}
+		return []any{"index_name", args[1], "attributes", []any{
+			[]any{"identifier", "vector", "type", "VECTOR", "algorithm", "HNSW", "dim", f.indexDim},
+		}, "num_docs", fmt.Sprint(f.countPrefix(":chunk:"))}
+	case "FT.CREATE":
+		for i, arg := range args {
+			if arg == "DIM" {
+				f.indexDim = args[i+1]
+			}
+		}
+		return "OK"
+	case "FT.ALTER", "SLOW":
+		return "OK"
+	case "FT.SEARCH":
+		f.searches = append(f.searches, args[2])
+		var keys []string
+		for key := range f.hashes {
+			if strings.Contains(key, ":chunk:") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		out := []any{len(keys)}
+		for _, key := range keys {
+			fields := []any{"score", "0.25"}
+			for name, value := range f.hashes[key] {
+				fields = append(fields, name, value)
+			}
+			out = append(out, key, fields)
+		}
+		return out
+	case "HSET":
+		h := f.hashes[args[1]]
+		if h == nil {
+			h = make(map[string]string)
+			f.hashes[args[1]] = h
+		}
+		for i := 2; i+1 < len(args); i += 2 {
+			h[args[i]] = args[i+1]
+		}
+		return len(args[2:]) / 2
+	case "HGETALL":
+		var out []any
+		for name, value := range f.hashes[args[1]] {
+			out = append(out, name, value)
+		}
+		return out
+	case "SADD":
+		s := f.sets[args[1]]
+		if s == nil {
+			s = make(map[string]bool)
+			f.sets[args[1]] = s
+		}
+		for _, member := range args[2:] {
+			s[member] = true
+		}
+		return len(args[2:])
+	case "SREM":
+		for _, member := range args[2:] {
+			delete(f.sets[args[1]], member)
+		}
+		return len(args[2:])
+	case "SMEMBERS":
+		out := []any{}
+		for member := range f.sets[args[1]] {
+			out = append(out, member)
+		}
+		return out
+	case "SCARD":
+		return len(f.sets[args[1]])
+	case "DEL":
+		for _, key := range args[1:] {
+			delete(f.hashes, key)
+			delete(f.sets, key)
+		}
+		return len(args[1:])
+	case "EXPIRE":
+		f.ttls[args[1]] = args[2]
+		return 1
+	case "SCAN":
+		pattern := strings.TrimSuffix(strings.ReplaceAll(args[3], `\`, ""), "*")
+		out := []any{}
+		for key := range f.hashes {
+			if strings.HasPrefix(key, pattern) {
+				out = append(out, key)
+			}
+		}
+		for key := range f.sets {
+			if strings.HasPrefix(key, pattern) {
+				out = append(out, key)
+			}
+		}
+		return []any{"0", out}
+	}
+	return redisError("ERR unknown command " + args[0])
+}
+
+func (f *fakeRedis) countPrefix(part string) int {
+	n := 0
+	for key := range f.hashes {
+		if strings.Contains(key, part) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRedisIndexName(t *testing.T) {
+	if got := RedisIndexName("/home/user/my repo"); got != "grepai__home_user_my_repo" {
+		t.Errorf("unexpected index name %q", got)
+	}
+}
+
+func TestRedisVectorEncoding(t *testing.T) {
+	vector := []float32{0.5, -1.25, 3}
+	got := decodeRedisVector(encodeRedisVector(vector))
+	if len(got) != len(vector) {
+		t.Fatalf("expected %d values, got %d", len(vector), len(got))
+	}
+	for i := range vector {
+		if got[i] != vector[i] {
+			t.Errorf("value %d: expected %v, got %v", i, vector[i], got[i])
+		}
+	}
+}
+
+func TestNewRedisStore_InvalidURL(t *testing.T) {
+	if _, err := NewRedisStore(context.Background(), "http://localhost:6379", "idx", 3, 0); err == nil {
+		t.Fatal("expected an error for a non-redis URL")
+	}
+}
+
+func TestNewRedisStore_DimensionMismatch(t *testing.T) {
+	f, url := newFakeRedis(t)
+	ctx := context.Background()
+
+	s, err := NewRedisStore(ctx, url, "grepai_test", 3, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	s.Close()
+	if f.indexDim != "3" {
+		t.Fatalf("expected index created with 3 dimensions, got %q", f.indexDim)
+	}
+
+	_, err = NewRedisStore(ctx, url, "grepai_test", 768, 0)
+	if err == nil || !strings.Contains(err.Error(), "has 3-dimensional vectors but the embedder is configured for 768") {
+		t.Fatalf("expected dimension mismatch error, got %v", err)
+	}
+}
+
+func TestRedisStore_ChunksAndDocuments(t *testing.T) {
+	_, url := newFakeRedis(t)
+	ctx := context.Background()
+
+	s, err := NewRedisStore(ctx, url, "grepai_test", 2, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	chunks := []Chunk{
+		{ID: "src/a.go_0", FilePath: "src/a.go", StartLine: 1, EndLine: 10, Content: "package a", Vector: []float32{1, 0}, ContentHash: "ab12", UpdatedAt: now},
+		{ID: "src/a.go_1", FilePath: "src/a.go", StartLine: 11, EndLine: 20, Content: "func A()", Vector: []float32{0, 1}, UpdatedAt: now},
+		{ID: "lib/b.go_0", FilePath: "lib/b.go", StartLine: 1, EndLine: 5, Content: "package b", Vector: []float32{1, 1}, UpdatedAt: now},
+	}
+	if err := s.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := s.SaveDocument(ctx, Document{Path: "src/a.go", Hash: "h", ModTime: now, ChunkIDs: []string{"src/a.go_0", "src/a.go_1"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	fileChunks, err := s.GetChunksForFile(ctx, "src/a.go")
+	if err != nil {
+		t.Fatalf("failed to get chunks: %v", err)
+	}
+	if len(fileChunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(fileChunks))
+	}
+
+	doc, err := s.GetDocument(ctx, "src/a.go")
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if doc == nil || doc.Hash != "h" || !doc.ModTime.Equal(now) || len(doc.ChunkIDs) != 2 {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	results, err := s.Search(ctx, []float32{1, 0}, 5, SearchOptions{PathPrefix: "src/"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results under src/, got %d", len(results))
+	}
+	if results[0].Score != 0.75 {
+		t.Errorf("expected score 0.75, got %v", results[0].Score)
+	}
+
+	if err := s.DeleteByFile(ctx, "src/a.go"); err != nil {
+		t.Fatalf("failed to delete file: %v", err)
+	}
+	all, err := s.GetAllChunks(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all chunks: %v", err)
+	}
+	if len(all) != 1 || all[0].ID != "lib/b.go_0" || len(all[0].Vector) != 2 {
+		t.Fatalf("expected only lib/b.go to remain, got %+v", all)
+	}
+
+	if err := s.DeleteDocument(ctx, "src/a.go"); err != nil {
+		t.Fatalf("failed to delete document: %v", err)
+	}
+	paths, err := s.ListDocuments(ctx)
+	if err != nil {
+		t.Fatalf("failed to list documents: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Fatalf("expected no documents, got %v", paths)
+	}
+}
+
+func TestRedisStore_SearchModelFilter(t *testing.T) {
+	f, url := newFakeRedis(t)
+	ctx := context.Background()
+
+	s, err := NewRedisStore(ctx, url, "grepai_test", 2, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	chunks := []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", Vector: []float32{1, 0}, Model: "nomic-embed-text"},
+		{ID: "README.md_0", FilePath: "README.md", Vector: []float32{1, 0}, Model: "docs:v1"},
+	}
+	if err := s.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	results, err := s.Search(ctx, []float32{1, 0}, 5, SearchOptions{Model: "docs:v1"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "README.md" {
+		t.Fatalf("expected only the docs chunk, got %+v", results)
+	}
+	if want := `(@model:{docs\:v1})=>[KNN`; !strings.HasPrefix(f.searches[len(f.searches)-1], want) {
+		t.Errorf("expected query to start with %s, got %s", want, f.searches[len(f.searches)-1])
+	}
+
+	results, err = s.Search(ctx, []float32{1, 0}, 5, SearchOptions{ExcludeModel: "docs:v1"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "a.go" {
+		t.Fatalf("expected only the code chunk, got %+v", results)
+	}
+	if want := `(-@model:{docs\:v1})=>[KNN`; !strings.HasPrefix(f.searches[len(f.searches)-1], want) {
+		t.Errorf("expected query to start with %s, got %s", want, f.searches[len(f.searches)-1])
+	}
+}
+
+func TestRedisStore_DropsConnectionAfterTimeout(t *testing.T) {
+	f, url := newFakeRedis(t)
+	ctx := context.Background()
+
+	s, err := NewRedisStore(ctx, url, "grepai_test", 2, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.pool.do(timeoutCtx, "SLOW"); err == nil {
+		t.Fatal("expected the command to time out")
+	}
+
+	// The late reply to SLOW must not be read as the reply to SCARD.
+	reply, err := s.pool.do(ctx, "SCARD", s.docsKey())
+	if err != nil {
+		t.Fatalf("failed to run a command after the timeout: %v", err)
+	}
+	if n, ok := reply.(int64); !ok || n != 0 {
+		t.Errorf("expected SCARD to return 0, got %#v", reply)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conns != 2 {
+		t.Errorf("expected the timed-out connection to be replaced, got %d connections", f.conns)
+	}
+}
+
+func TestRedisStore_PipelineKeepsConnectionOnErrorReply(t *testing.T) {
+	f, url := newFakeRedis(t)
+	ctx := context.Background()
+
+	s, err := NewRedisStore(ctx, url, "grepai_test", 2, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	_, err = s.pool.pipeline(ctx, [][]any{{"BOGUS"}, {"SCARD", s.docsKey()}})
+	if err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Fatalf("expected the error reply, got %v", err)
+	}
+	if _, err := s.pool.do(ctx, "SCARD", s.docsKey()); err != nil {
+		t.Fatalf("failed to run a command after an error reply: %v", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conns != 1 {
+		t.Errorf("expected the connection to be reused, got %d connections", f.conns)
+	}
+}
+
+func TestRedisIndexHasAttribute(t *testing.T) {
+	info := []any{"index_name", "idx", "attributes", []any{
+		[]any{"identifier", "content_hash", "attribute", "content_hash", "type", "TAG"},
+		[]any{"identifier", "model", "attribute", "model", "type", "TAG"},
+	}}
+	if !redisIndexHasAttribute(info, "model") {
+		t.Error("expected model to be found")
+	}
+	if redisIndexHasAttribute(info, "vector") {
+		t.Error("expected vector not to be found")
+	}
+}
+
+func TestRedisStore_DeleteByFilesAndProject(t *testing.T) {
+	_, url := newFakeRedis(t)
+	ctx := context.Background()
+
+	s, err := NewRedisStore(ctx, url, "grepai_test", 2, 0)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	for _, path := range []string{"ws/api/a.go", "ws/api/b.go", "ws/web/c.go", "ws/apis/d.go"} {
+		if err := s.SaveChunks(ctx, []Chunk{{ID: path + "_0", FilePath: path, Vector: []float32{1, 0}}}); err != nil {
+			t.Fatalf("failed to save chunks: %v", err)
+		}
+		if err := s.SaveDocument(ctx, Document{Path: path, ChunkIDs: []string{path + "_0"}}); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+
+	if err := s.DeleteByFiles(ctx, []string{"ws/web/c.go", "ws/apis/d.go"}); err != nil {
+		t.Fatalf("DeleteByFiles failed: %v", err)
+	}
+	if err := s.DeleteByProject(ctx, "ws/api"); err != nil {
+		t.Fatalf("DeleteByProject failed: %v", err)
+	}
+
+	all, err := s.GetAllChunks(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all chunks: %v", err)
+	}
+	if len(all) != 0 {
+		t.Errorf("expected no chunks left, got %+v", all)
+	}
+	paths, err := s.ListDocuments(ctx)
+	if err != nil {
+		t.Fatalf("failed to list documents: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "ws/apis/d.go" || paths[1] != "ws/web/c.go" {
+		t.Errorf("expected only the documents outside ws/api to remain, got %v", paths)
+	}
+}
+
+func TestRedisStore_TTL(t *testing.T) {
+	f, url := newFakeRedis(t)
+	ctx := context.Background()
+
+	s, err := NewRedisStore(ctx, url, "grepai_test", 2, 90*time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.SaveChunks(ctx, []Chunk{{ID: "a.go_0", FilePath: "a.go", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := s.SaveDocument(ctx, Document{Path: "a.go", ChunkIDs: []string{"a.go_0"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	for _, key := range []string{"grepai_test:chunk:a.go_0", "grepai_test:file:a.go", "grepai_test:doc:a.go", "grepai_test:docs"} {
+		if f.ttls[key] != "5400" {
+			t.Errorf("expected %s to expire in 5400s, got %q", key, f.ttls[key])
+		}
+	}
+}
+
+func TestRedisIndexDim(t *testing.T) {
+	info := []any{"index_name", "idx", "attributes", []any{
+		[]any{"identifier", "content_hash", "type", "TAG"},
+		[]any{"identifier", "vector", "type", "VECTOR", "index", []any{"dim", int64(1536)}},
+	}}
+	if got := redisIndexDim(info); got != 1536 {
+		t.Errorf("expected 1536, got %d", got)
+	}
+	if got := redisIndexDim([]any{"index_name", "idx"}); got != 0 {
+		t.Errorf("expected 0 without attributes, got %d", got)
+	}
+}
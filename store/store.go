@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -15,7 +16,10 @@ type Chunk struct {
 	Vector      []float32 `json:"vector"`
 	Hash        string    `json:"hash"`
 	ContentHash string    `json:"content_hash"` // SHA256 of raw content (path-independent)
-	UpdatedAt   time.Time `json:"updated_at"`
+	// Model is the model of the docs embedder for documentation chunks
+	// embedded with it, and empty for chunks of the main embedder.
+	Model     string    `json:"model,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Document represents a file with its chunks
@@ -30,11 +34,31 @@ type Document struct {
 type SearchResult struct {
 	Chunk Chunk   `json:"chunk"`
 	Score float32 `json:"score"`
+
+	// SymbolName and SymbolKind describe the function, method or type
+	// enclosing the chunk, when a symbol index is available.
+	SymbolName string `json:"symbol_name,omitempty"`
+	SymbolKind string `json:"symbol_kind,omitempty"`
 }
 
 // SearchOptions contains optional filters for vector search queries.
 type SearchOptions struct {
 	PathPrefix string
+
+	// Model keeps only chunks embedded with that model, and ExcludeModel
+	// drops them, so rankings of chunks embedded with different models
+	// stay apart.
+	Model        string
+	ExcludeModel string
+}
+
+// keepsModel reports whether a chunk embedded with model passes the model
+// filters of o.
+func (o SearchOptions) keepsModel(model string) bool {
+	if o.Model != "" && model != o.Model {
+		return false
+	}
+	return o.ExcludeModel == "" || model != o.ExcludeModel
 }
 
 // IndexStats contains statistics about the index
@@ -43,6 +67,18 @@ type IndexStats struct {
 	TotalChunks int       `json:"total_chunks"`
 	IndexSize   int64     `json:"index_size"` // bytes
 	LastUpdated time.Time `json:"last_updated"`
+
+	// Directories and Languages break the index down by top-level
+	// directory and by language. Stores leave them empty; see AddBreakdown.
+	Directories []GroupStats `json:"directories,omitempty"`
+	Languages   []GroupStats `json:"languages,omitempty"`
+}
+
+// GroupStats contains file and chunk counts for a group of indexed files.
+type GroupStats struct {
+	Name   string `json:"name"`
+	Files  int    `json:"files"`
+	Chunks int    `json:"chunks"`
 }
 
 // FileStats contains statistics for a single file
@@ -60,6 +96,17 @@ type VectorStore interface {
 	// DeleteByFile removes all chunks for a given file path
 	DeleteByFile(ctx context.Context, filePath string) error
 
+	// DeleteByFiles removes all chunks for the given file paths at once
+	DeleteByFiles(ctx context.Context, filePaths []string) error
+
+	// DeleteChunks removes the chunks with the given IDs, as the store
+	// returns them, leaving the other chunks of their files in place
+	DeleteChunks(ctx context.Context, ids []string) error
+
+	// DeleteByProject removes the chunks and document metadata of every file
+	// under project, a path prefix such as "workspace/project"
+	DeleteByProject(ctx context.Context, project string) error
+
 	// Search finds the most similar chunks to a query vector
 	Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error)
 
@@ -97,6 +144,11 @@ type VectorStore interface {
 	GetAllChunks(ctx context.Context) ([]Chunk, error)
 }
 
+// projectPrefix returns the prefix of the paths of the files under project.
+func projectPrefix(project string) string {
+	return strings.TrimSuffix(project, "/") + "/"
+}
+
 // EmbeddingCache is an optional interface that VectorStore implementations can
 // provide to enable content-addressed embedding deduplication. When a store
 // implements this interface, the indexer will look up existing embeddings by
@@ -107,3 +159,37 @@ type EmbeddingCache interface {
 	// Returns (vector, true, nil) if found, (nil, false, nil) if not found.
 	LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error)
 }
+
+// FileReplacer is an optional interface that VectorStore implementations can
+// provide to replace a file's chunks and document as one operation, so that
+// a crash midway cannot leave the file half-indexed with dangling chunks.
+type FileReplacer interface {
+	// ReplaceFile deletes the chunks of doc.Path, then saves chunks and doc.
+	ReplaceFile(ctx context.Context, doc Document, chunks []Chunk) error
+}
+
+// ReplaceFile replaces the chunks and document of doc.Path in st. Stores
+// implementing FileReplacer do it as one operation; for the others, the old
+// chunks are deleted, then the new chunks and the document saved in turn.
+func ReplaceFile(ctx context.Context, st VectorStore, doc Document, chunks []Chunk) error {
+	if r, ok := st.(FileReplacer); ok {
+		return r.ReplaceFile(ctx, doc, chunks)
+	}
+	if err := st.DeleteByFile(ctx, doc.Path); err != nil {
+		return err
+	}
+	if err := st.SaveChunks(ctx, chunks); err != nil {
+		return err
+	}
+	return st.SaveDocument(ctx, doc)
+}
+
+// TextSearcher is an optional interface that VectorStore implementations can
+// provide to rank chunks by text relevance on the server. When a store
+// implements it, hybrid search uses it instead of loading every chunk for
+// client-side text matching.
+type TextSearcher interface {
+	// SearchText returns up to limit chunks ranked by how well their content
+	// matches query.
+	SearchText(ctx context.Context, query string, limit int, opts SearchOptions) ([]SearchResult, error)
+}
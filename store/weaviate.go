@@ -0,0 +1,767 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WeaviateStore stores chunks in a Weaviate class through its REST and
+// GraphQL APIs. Document metadata lives in a second class without vectors.
+type WeaviateStore struct {
+	client     *http.Client
+	endpoint   string
+	apiKey     string
+	class      string
+	docClass   string
+	dimensions int
+}
+
+// weaviatePageSize is the number of objects fetched per page when listing a
+// whole class.
+const weaviatePageSize = 500
+
+// weaviateQueryLimit caps filtered GraphQL queries, matching Weaviate's
+// default QUERY_MAXIMUM_RESULTS.
+const weaviateQueryLimit = 10000
+
+const weaviateChunkFields = "chunk_id file_path start_line end_line content hash content_hash model updated_at"
+
+// weaviateNamespace derives object UUIDs from chunk IDs and document paths,
+// since Weaviate only accepts UUIDs as object IDs.
+var weaviateNamespace = uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+
+// weaviateChunkProps are the properties of a chunk object.
+type weaviateChunkProps struct {
+	ChunkID     string    `json:"chunk_id"`
+	FilePath    string    `json:"file_path"`
+	StartLine   int       `json:"start_line"`
+	EndLine     int       `json:"end_line"`
+	Content     string    `json:"content"`
+	Hash        string    `json:"hash"`
+	ContentHash string    `json:"content_hash"`
+	Model       string    `json:"model"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// weaviateChunk is a chunk as returned by a GraphQL query.
+type weaviateChunk struct {
+	weaviateChunkProps
+	Additional struct {
+		Distance float32   `json:"distance"`
+		Vector   []float32 `json:"vector"`
+	} `json:"_additional"`
+}
+
+func (p weaviateChunkProps) chunk(vector []float32) Chunk {
+	return Chunk{
+		ID:          p.ChunkID,
+		FilePath:    p.FilePath,
+		StartLine:   p.StartLine,
+		EndLine:     p.EndLine,
+		Content:     p.Content,
+		Vector:      vector,
+		Hash:        p.Hash,
+		ContentHash: p.ContentHash,
+		Model:       p.Model,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+type weaviateDocument struct {
+	Path     string    `json:"path"`
+	Hash     string    `json:"hash"`
+	ModTime  time.Time `json:"mod_time"`
+	ChunkIDs []string  `json:"chunk_ids"`
+}
+
+type weaviateObject struct {
+	ID         string          `json:"id"`
+	Class      string          `json:"class,omitempty"`
+	Properties json.RawMessage `json:"properties"`
+	Vector     []float32       `json:"vector,omitempty"`
+}
+
+// weaviateError is a non-2xx response from Weaviate.
+type weaviateError struct {
+	status  int
+	message string
+}
+
+func (e *weaviateError) Error() string {
+	return fmt.Sprintf("weaviate returned %d: %s", e.status, e.message)
+}
+
+func isWeaviateNotFound(err error) bool {
+	var werr *weaviateError
+	return errors.As(err, &werr) && werr.status == http.StatusNotFound
+}
+
+// WeaviateClassName turns name, such as a project path, into a valid
+// Weaviate class name.
+func WeaviateClassName(name string) string {
+	var b strings.Builder
+	b.WriteString("Grepai_")
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// httpBaseURL returns endpoint with a scheme and without a trailing
+// slash. Endpoints without a scheme use http.
+func httpBaseURL(endpoint string) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "http://" + endpoint
+	}
+	return endpoint
+}
+
+func NewWeaviateStore(ctx context.Context, endpoint, apiKey, class string, dimensions int) (*WeaviateStore, error) {
+	if class == "" {
+		return nil, fmt.Errorf("weaviate class name is required")
+	}
+	s := &WeaviateStore{
+		client:     &http.Client{Timeout: 60 * time.Second},
+		endpoint:   httpBaseURL(endpoint),
+		apiKey:     apiKey,
+		class:      class,
+		docClass:   class + "_documents",
+		dimensions: dimensions,
+	}
+
+	if err := s.ensureSchema(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *WeaviateStore) ensureSchema(ctx context.Context) error {
+	chunkProps := []map[string]any{
+		{"name": "chunk_id", "dataType": []string{"text"}, "tokenization": "field"},
+		{"name": "file_path", "dataType": []string{"text"}, "tokenization": "field"},
+		{"name": "start_line", "dataType": []string{"int"}},
+		{"name": "end_line", "dataType": []string{"int"}},
+		{"name": "content", "dataType": []string{"text"}},
+		{"name": "hash", "dataType": []string{"text"}, "tokenization": "field"},
+		{"name": "content_hash", "dataType": []string{"text"}, "tokenization": "field"},
+		weaviateModelProp,
+		{"name": "updated_at", "dataType": []string{"date"}},
+	}
+	docProps := []map[string]any{
+		{"name": "path", "dataType": []string{"text"}, "tokenization": "field"},
+		{"name": "hash", "dataType": []string{"text"}, "tokenization": "field"},
+		{"name": "mod_time", "dataType": []string{"date"}},
+		{"name": "chunk_ids", "dataType": []string{"text[]"}, "tokenization": "field"},
+	}
+
+	created, err := s.ensureClass(ctx, s.class, chunkProps)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ensureClass(ctx, s.docClass, docProps); err != nil {
+		return err
+	}
+	if created {
+		return nil
+	}
+	if err := s.ensureProperty(ctx, s.class, weaviateModelProp); err != nil {
+		return err
+	}
+	return s.checkDimensions(ctx)
+}
+
+// weaviateModelProp is the chunk property holding the docs embedder model,
+// added to classes created before it existed.
+var weaviateModelProp = map[string]any{"name": "model", "dataType": []string{"text"}, "tokenization": "field"}
+
+// ensureProperty adds prop to class unless the class has it.
+func (s *WeaviateStore) ensureProperty(ctx context.Context, class string, prop map[string]any) error {
+	var schema struct {
+		Properties []struct {
+			Name string `json:"name"`
+		} `json:"properties"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/v1/schema/"+class, nil, &schema); err != nil {
+		return fmt.Errorf("failed to read class %s: %w", class, err)
+	}
+	for _, p := range schema.Properties {
+		if p.Name == prop["name"] {
+			return nil
+		}
+	}
+	if err := s.do(ctx, http.MethodPost, "/v1/schema/"+class+"/properties", prop, nil); err != nil {
+		return fmt.Errorf("failed to add property %s to class %s: %w", prop["name"], class, err)
+	}
+	return nil
+}
+
+// ensureClass creates class with props unless it exists, and reports whether
+// it was created.
+func (s *WeaviateStore) ensureClass(ctx context.Context, class string, props []map[string]any) (bool, error) {
+	err := s.do(ctx, http.MethodGet, "/v1/schema/"+class, nil, nil)
+	if err == nil {
+		return false, nil
+	}
+	if !isWeaviateNotFound(err) {
+		return false, fmt.Errorf("failed to check class %s: %w", class, err)
+	}
+
+	schema := map[string]any{
+		"class":             class,
+		"vectorizer":        "none",
+		"vectorIndexConfig": map[string]any{"distance": "cosine"},
+		"properties":        props,
+	}
+	if err := s.do(ctx, http.MethodPost, "/v1/schema", schema, nil); err != nil {
+		return false, fmt.Errorf("failed to create class %s: %w", class, err)
+	}
+	return true, nil
+}
+
+// checkDimensions compares the vector length of a stored chunk with the
+// configured dimensions. Weaviate fixes the length on the first insert, so an
+// index built by another embedding model fails every search.
+func (s *WeaviateStore) checkDimensions(ctx context.Context) error {
+	if s.dimensions <= 0 {
+		return nil
+	}
+	objects, _, err := s.listObjects(ctx, s.class, "", 1, true)
+	if err != nil {
+		return fmt.Errorf("failed to read class %s: %w", s.class, err)
+	}
+	if len(objects) == 0 || len(objects[0].Vector) == 0 {
+		return nil
+	}
+	if size := len(objects[0].Vector); size != s.dimensions {
+		return fmt.Errorf("class %q has %d-dimensional vectors but the embedder is configured for %d; set embedder.dimensions to %d or use another class", s.class, size, s.dimensions, size)
+	}
+	return nil
+}
+
+// do sends a request to the Weaviate API and decodes the response into out
+// when it is non-nil.
+func (s *WeaviateStore) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach weaviate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &weaviateError{status: resp.StatusCode, message: strings.TrimSpace(string(msg))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// graphql runs query and decodes its data field into out.
+func (s *WeaviateStore) graphql(ctx context.Context, query string, out any) error {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/v1/graphql", map[string]string{"query": query}, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("graphql query failed: %s", resp.Errors[0].Message)
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+// getChunks runs a GraphQL Get query on the chunk class with the given
+// arguments and additional fields.
+func (s *WeaviateStore) getChunks(ctx context.Context, args, additional string) ([]weaviateChunk, error) {
+	query := fmt.Sprintf("{ Get { %s(%s) { %s _additional { %s } } } }", s.class, args, weaviateChunkFields, additional)
+	var data struct {
+		Get map[string][]weaviateChunk `json:"Get"`
+	}
+	if err := s.graphql(ctx, query, &data); err != nil {
+		return nil, err
+	}
+	return data.Get[s.class], nil
+}
+
+// listObjects returns a page of objects of class after the object ID after,
+// and the ID to continue from.
+func (s *WeaviateStore) listObjects(ctx context.Context, class, after string, limit int, withVector bool) ([]weaviateObject, string, error) {
+	query := url.Values{}
+	query.Set("class", class)
+	query.Set("limit", strconv.Itoa(limit))
+	if after != "" {
+		query.Set("after", after)
+	}
+	if withVector {
+		query.Set("include", "vector")
+	}
+
+	var resp struct {
+		Objects []weaviateObject `json:"objects"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/v1/objects?"+query.Encode(), nil, &resp); err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(resp.Objects) == limit {
+		next = resp.Objects[len(resp.Objects)-1].ID
+	}
+	return resp.Objects, next, nil
+}
+
+// eachObject calls fn for every object of class.
+func (s *WeaviateStore) eachObject(ctx context.Context, class string, withVector bool, fn func(weaviateObject) error) error {
+	after := ""
+	for {
+		objects, next, err := s.listObjects(ctx, class, after, weaviatePageSize, withVector)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objects {
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		after = next
+	}
+}
+
+// batchObjects creates or replaces objects.
+func (s *WeaviateStore) batchObjects(ctx context.Context, objects []weaviateObject) error {
+	var results []struct {
+		ID     string `json:"id"`
+		Result struct {
+			Errors *struct {
+				Error []struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"errors"`
+		} `json:"result"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/v1/batch/objects", map[string]any{"objects": objects}, &results); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Result.Errors != nil && len(r.Result.Errors.Error) > 0 {
+			return fmt.Errorf("object %s: %s", r.ID, r.Result.Errors.Error[0].Message)
+		}
+	}
+	return nil
+}
+
+// whereText builds a GraphQL where filter matching a text property.
+func whereText(property, operator, value string) string {
+	return "where: " + textCondition(property, operator, value)
+}
+
+// textCondition returns a GraphQL condition on a text property, which
+// whereText and And operands are built from.
+func textCondition(property, operator, value string) string {
+	quoted, _ := json.Marshal(value)
+	return fmt.Sprintf("{ path: [%q], operator: %s, valueText: %s }", property, operator, quoted)
+}
+
+func formatVector(vector []float32) string {
+	parts := make([]string, len(vector))
+	for i, v := range vector {
+		parts[i] = strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func (s *WeaviateStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	objects := make([]weaviateObject, 0, len(chunks))
+	for _, chunk := range chunks {
+		props, err := json.Marshal(weaviateChunkProps{
+			ChunkID:     chunk.ID,
+			FilePath:    chunk.FilePath,
+			StartLine:   chunk.StartLine,
+			EndLine:     chunk.EndLine,
+			Content:     sanitizeUTF8(chunk.Content),
+			Hash:        chunk.Hash,
+			ContentHash: chunk.ContentHash,
+			Model:       chunk.Model,
+			UpdatedAt:   chunk.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build properties: %w", err)
+		}
+		objects = append(objects, weaviateObject{
+			ID:         uuid.NewSHA1(weaviateNamespace, []byte(chunk.ID)).String(),
+			Class:      s.class,
+			Properties: props,
+			Vector:     chunk.Vector,
+		})
+	}
+
+	if err := s.batchObjects(ctx, objects); err != nil {
+		return fmt.Errorf("failed to save chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *WeaviateStore) DeleteByFile(ctx context.Context, filePath string) error {
+	if err := s.deleteWhere(ctx, s.class, whereFilter("file_path", "Equal", filePath)); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+// weaviateDeleteBatch bounds the values of one ContainsAny delete filter.
+const weaviateDeleteBatch = 500
+
+func (s *WeaviateStore) DeleteByFiles(ctx context.Context, filePaths []string) error {
+	if err := s.deleteContainingAny(ctx, s.class, "file_path", filePaths); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *WeaviateStore) DeleteChunks(ctx context.Context, ids []string) error {
+	if err := s.deleteContainingAny(ctx, s.class, "chunk_id", ids); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+// DeleteByProject deletes the chunks and documents under project with a Like
+// filter. Like has no escape for its * and ? wildcards, so a project whose
+// name contains them has its documents listed and matched exactly instead.
+func (s *WeaviateStore) DeleteByProject(ctx context.Context, project string) error {
+	prefix := projectPrefix(project)
+	if strings.ContainsAny(prefix, "*?") {
+		docs, err := s.ListDocuments(ctx)
+		if err != nil {
+			return err
+		}
+		var paths []string
+		for _, path := range docs {
+			if strings.HasPrefix(path, prefix) {
+				paths = append(paths, path)
+			}
+		}
+		if err := s.DeleteByFiles(ctx, paths); err != nil {
+			return err
+		}
+		if err := s.deleteContainingAny(ctx, s.docClass, "path", paths); err != nil {
+			return fmt.Errorf("failed to delete documents: %w", err)
+		}
+		return nil
+	}
+	if err := s.deleteWhere(ctx, s.class, whereFilter("file_path", "Like", prefix+"*")); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	if err := s.deleteWhere(ctx, s.docClass, whereFilter("path", "Like", prefix+"*")); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+// deleteContainingAny deletes the objects of class whose property is one of
+// values, weaviateDeleteBatch values per request.
+func (s *WeaviateStore) deleteContainingAny(ctx context.Context, class, property string, values []string) error {
+	for start := 0; start < len(values); start += weaviateDeleteBatch {
+		batch := values[start:min(start+weaviateDeleteBatch, len(values))]
+		where := map[string]any{
+			"path":           []string{property},
+			"operator":       "ContainsAny",
+			"valueTextArray": batch,
+		}
+		if err := s.deleteWhere(ctx, class, where); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteWhere deletes the objects of class matching a where filter.
+func (s *WeaviateStore) deleteWhere(ctx context.Context, class string, where map[string]any) error {
+	body := map[string]any{
+		"match": map[string]any{
+			"class": class,
+			"where": where,
+		},
+	}
+	return s.do(ctx, http.MethodDelete, "/v1/batch/objects", body, nil)
+}
+
+// whereFilter returns a REST where filter on a text property.
+func whereFilter(property, operator, value string) map[string]any {
+	return map[string]any{
+		"path":      []string{property},
+		"operator":  operator,
+		"valueText": value,
+	}
+}
+
+func (s *WeaviateStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive, got: %d", limit)
+	}
+
+	args := fmt.Sprintf("nearVector: { vector: %s }, limit: %d", formatVector(queryVector), limit)
+	var conditions []string
+	if opts.PathPrefix != "" {
+		conditions = append(conditions, textCondition("file_path", "Like", opts.PathPrefix+"*"))
+	}
+	if opts.Model != "" {
+		conditions = append(conditions, textCondition("model", "Equal", opts.Model))
+	}
+	if opts.ExcludeModel != "" {
+		conditions = append(conditions, textCondition("model", "NotEqual", opts.ExcludeModel))
+	}
+	switch len(conditions) {
+	case 0:
+	case 1:
+		args += ", where: " + conditions[0]
+	default:
+		args += ", where: { operator: And, operands: [" + strings.Join(conditions, ", ") + "] }"
+	}
+	found, err := s.getChunks(ctx, args, "distance")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(found))
+	for _, c := range found {
+		// Like treats * and ? in the prefix as wildcards.
+		if opts.PathPrefix != "" && !strings.HasPrefix(c.FilePath, opts.PathPrefix) {
+			continue
+		}
+		if !opts.keepsModel(c.Model) {
+			continue
+		}
+		results = append(results, SearchResult{
+			Chunk: c.chunk(c.Additional.Vector),
+			// Cosine distance is 1 - cosine similarity.
+			Score: 1 - c.Additional.Distance,
+		})
+	}
+	return results, nil
+}
+
+func (s *WeaviateStore) documentID(filePath string) string {
+	return uuid.NewSHA1(weaviateNamespace, []byte("document:"+filePath)).String()
+}
+
+func (s *WeaviateStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
+	var obj weaviateObject
+	err := s.do(ctx, http.MethodGet, "/v1/objects/"+s.docClass+"/"+s.documentID(filePath), nil, &obj)
+	if isWeaviateNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	var doc weaviateDocument
+	if err := json.Unmarshal(obj.Properties, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	return &Document{Path: doc.Path, Hash: doc.Hash, ModTime: doc.ModTime, ChunkIDs: doc.ChunkIDs}, nil
+}
+
+func (s *WeaviateStore) SaveDocument(ctx context.Context, doc Document) error {
+	chunkIDs := doc.ChunkIDs
+	if chunkIDs == nil {
+		chunkIDs = []string{}
+	}
+	props, err := json.Marshal(weaviateDocument{Path: doc.Path, Hash: doc.Hash, ModTime: doc.ModTime, ChunkIDs: chunkIDs})
+	if err != nil {
+		return fmt.Errorf("failed to build properties: %w", err)
+	}
+	obj := weaviateObject{ID: s.documentID(doc.Path), Class: s.docClass, Properties: props}
+	if err := s.batchObjects(ctx, []weaviateObject{obj}); err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+	return nil
+}
+
+func (s *WeaviateStore) DeleteDocument(ctx context.Context, filePath string) error {
+	err := s.do(ctx, http.MethodDelete, "/v1/objects/"+s.docClass+"/"+s.documentID(filePath), nil, nil)
+	if err != nil && !isWeaviateNotFound(err) {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// listDocuments returns the metadata of every document.
+func (s *WeaviateStore) listDocuments(ctx context.Context) ([]weaviateDocument, error) {
+	var docs []weaviateDocument
+	err := s.eachObject(ctx, s.docClass, false, func(obj weaviateObject) error {
+		var doc weaviateDocument
+		if err := json.Unmarshal(obj.Properties, &doc); err != nil {
+			return fmt.Errorf("failed to decode document: %w", err)
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+	return docs, err
+}
+
+func (s *WeaviateStore) ListDocuments(ctx context.Context) ([]string, error) {
+	docs, err := s.listDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	paths := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		paths = append(paths, doc.Path)
+	}
+	return paths, nil
+}
+
+func (s *WeaviateStore) Load(ctx context.Context) error {
+	return nil
+}
+
+func (s *WeaviateStore) Persist(ctx context.Context) error {
+	return nil
+}
+
+func (s *WeaviateStore) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+func (s *WeaviateStore) GetStats(ctx context.Context) (*IndexStats, error) {
+	query := fmt.Sprintf("{ Aggregate { %s { meta { count } updated_at { maximum } } %s { meta { count } } } }", s.class, s.docClass)
+	var data struct {
+		Aggregate map[string][]struct {
+			Meta struct {
+				Count int `json:"count"`
+			} `json:"meta"`
+			UpdatedAt struct {
+				Maximum string `json:"maximum"`
+			} `json:"updated_at"`
+		} `json:"Aggregate"`
+	}
+	if err := s.graphql(ctx, query, &data); err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	stats := &IndexStats{}
+	if chunks := data.Aggregate[s.class]; len(chunks) > 0 {
+		stats.TotalChunks = chunks[0].Meta.Count
+		if t, err := time.Parse(time.RFC3339, chunks[0].UpdatedAt.Maximum); err == nil {
+			stats.LastUpdated = t
+		}
+	}
+	if docs := data.Aggregate[s.docClass]; len(docs) > 0 {
+		stats.TotalFiles = docs[0].Meta.Count
+	}
+	return stats, nil
+}
+
+func (s *WeaviateStore) ListFilesWithStats(ctx context.Context) ([]FileStats, error) {
+	docs, err := s.listDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	stats := make([]FileStats, 0, len(docs))
+	for _, doc := range docs {
+		stats = append(stats, FileStats{
+			Path:       doc.Path,
+			ChunkCount: len(doc.ChunkIDs),
+			ModTime:    doc.ModTime,
+		})
+	}
+	return stats, nil
+}
+
+func (s *WeaviateStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chunk, error) {
+	args := fmt.Sprintf("limit: %d, %s", weaviateQueryLimit, whereText("file_path", "Equal", filePath))
+	found, err := s.getChunks(ctx, args, "vector")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	chunks := make([]Chunk, 0, len(found))
+	for _, c := range found {
+		chunks = append(chunks, c.chunk(c.Additional.Vector))
+	}
+	return chunks, nil
+}
+
+func (s *WeaviateStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
+	var chunks []Chunk
+	err := s.eachObject(ctx, s.class, true, func(obj weaviateObject) error {
+		var props weaviateChunkProps
+		if err := json.Unmarshal(obj.Properties, &props); err != nil {
+			return fmt.Errorf("failed to decode chunk: %w", err)
+		}
+		chunks = append(chunks, props.chunk(obj.Vector))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all chunks: %w", err)
+	}
+	return chunks, nil
+}
+
+// LookupByContentHash searches Weaviate for a chunk matching the content hash.
+func (s *WeaviateStore) LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error) {
+	if contentHash == "" {
+		return nil, false, nil
+	}
+
+	args := "limit: 1, " + whereText("content_hash", "Equal", contentHash)
+	found, err := s.getChunks(ctx, args, "vector")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lookup by content hash: %w", err)
+	}
+	if len(found) == 0 || len(found[0].Additional.Vector) == 0 {
+		return nil, false, nil
+	}
+	return found[0].Additional.Vector, true, nil
+}
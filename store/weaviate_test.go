@@ -0,0 +1,363 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWeaviate serves the parts of the Weaviate API used by WeaviateStore,
+// keeping objects in memory. GraphQL queries are recorded and answered with
+// graphqlData.
+type fakeWeaviate struct {
+	mu          sync.Mutex
+	classes     map[string]bool
+	properties  map[string][]string                  // class -> properties added after creation
+	objects     map[string]map[string]weaviateObject // class -> id -> object
+	queries     []string
+	deletes     []map[string]any // where filters of batch deletes
+	graphqlData string
+	authHeader  string
+}
+
+func newFakeWeaviate(t *testing.T) (*fakeWeaviate, *httptest.Server) {
+	t.Helper()
+	f := &fakeWeaviate{
+		classes:    make(map[string]bool),
+		properties: make(map[string][]string),
+		objects:    make(map[string]map[string]weaviateObject),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeWeaviate) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.authHeader = r.Header.Get("Authorization")
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[1] == "schema":
+		if !f.classes[parts[2]] {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		props := make([]map[string]string, 0, len(f.properties[parts[2]]))
+		for _, name := range f.properties[parts[2]] {
+			props = append(props, map[string]string{"name": name})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"class": parts[2], "properties": props})
+	case r.Method == http.MethodPost && len(parts) == 4 && parts[1] == "schema" && parts[3] == "properties":
+		var prop struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&prop)
+		f.properties[parts[2]] = append(f.properties[parts[2]], prop.Name)
+		_ = json.NewEncoder(w).Encode(prop)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/schema":
+		var schema struct {
+			Class string `json:"class"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&schema)
+		f.classes[schema.Class] = true
+		f.objects[schema.Class] = make(map[string]weaviateObject)
+		_ = json.NewEncoder(w).Encode(schema)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/batch/objects":
+		var body struct {
+			Objects []weaviateObject `json:"objects"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		results := make([]map[string]any, 0, len(body.Objects))
+		for _, obj := range body.Objects {
+			f.objects[obj.Class][obj.ID] = obj
+			results = append(results, map[string]any{"id": obj.ID, "result": map[string]any{}})
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	case r.Method == http.MethodDelete && r.URL.Path == "/v1/batch/objects":
+		var body struct {
+			Match struct {
+				Where map[string]any `json:"where"`
+			} `json:"match"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.deletes = append(f.deletes, body.Match.Where)
+		_ = json.NewEncoder(w).Encode(map[string]any{})
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/objects":
+		class := r.URL.Query().Get("class")
+		objects := make([]weaviateObject, 0)
+		for _, obj := range f.objects[class] {
+			objects = append(objects, obj)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"objects": objects})
+	case len(parts) == 4 && parts[1] == "objects":
+		obj, ok := f.objects[parts[2]][parts[3]]
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.objects[parts[2]], parts[3])
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(obj)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/graphql":
+		var body struct {
+			Query string `json:"query"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.queries = append(f.queries, body.Query)
+		_, _ = w.Write([]byte(`{"data": ` + f.graphqlData + `}`))
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.String(), http.StatusBadRequest)
+	}
+}
+
+func TestWeaviateClassName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/Users/test/project", "Grepai__Users_test_project"},
+		{"workspace_team", "Grepai_workspace_team"},
+		{"my-repo.v2", "Grepai_my_repo_v2"},
+	}
+
+	for _, tt := range tests {
+		if got := WeaviateClassName(tt.input); got != tt.expected {
+			t.Errorf("WeaviateClassName(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestNewWeaviateStore_CreatesClasses(t *testing.T) {
+	f, srv := newFakeWeaviate(t)
+
+	s, err := NewWeaviateStore(context.Background(), srv.URL+"/", "secret", "Grepai_test", 4)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	if !f.classes["Grepai_test"] || !f.classes["Grepai_test_documents"] {
+		t.Errorf("expected chunk and document classes, got %v", f.classes)
+	}
+	if f.authHeader != "Bearer secret" {
+		t.Errorf("expected bearer API key, got %q", f.authHeader)
+	}
+}
+
+func TestNewWeaviateStore_DimensionMismatch(t *testing.T) {
+	_, srv := newFakeWeaviate(t)
+	ctx := context.Background()
+
+	s, err := NewWeaviateStore(ctx, srv.URL, "", "Grepai_test", 3)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := s.SaveChunks(ctx, []Chunk{{ID: "a.go_0", FilePath: "a.go", Vector: []float32{1, 0, 0}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	if _, err := NewWeaviateStore(ctx, srv.URL, "", "Grepai_test", 3); err != nil {
+		t.Fatalf("expected matching dimensions to open, got %v", err)
+	}
+	_, err = NewWeaviateStore(ctx, srv.URL, "", "Grepai_test", 768)
+	if err == nil || !strings.Contains(err.Error(), "has 3-dimensional vectors but the embedder is configured for 768") {
+		t.Fatalf("expected dimension mismatch error, got %v", err)
+	}
+}
+
+func TestNewWeaviateStore_AddsModelProperty(t *testing.T) {
+	f, srv := newFakeWeaviate(t)
+	ctx := context.Background()
+	// A chunk class created before chunks had a model property.
+	f.classes["Grepai_test"] = true
+	f.objects["Grepai_test"] = make(map[string]weaviateObject)
+
+	for range 2 {
+		if _, err := NewWeaviateStore(ctx, srv.URL, "", "Grepai_test", 3); err != nil {
+			t.Fatalf("failed to create store: %v", err)
+		}
+	}
+	if got := f.properties["Grepai_test"]; len(got) != 1 || got[0] != "model" {
+		t.Errorf("expected the model property to be added once, got %v", got)
+	}
+}
+
+func TestWeaviateStore_Documents(t *testing.T) {
+	_, srv := newFakeWeaviate(t)
+	ctx := context.Background()
+
+	s, err := NewWeaviateStore(ctx, srv.URL, "", "Grepai_test", 3)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	doc := Document{Path: "src/a.go", Hash: "abc", ModTime: modTime, ChunkIDs: []string{"src/a.go_0", "src/a.go_1"}}
+	if err := s.SaveDocument(ctx, doc); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	got, err := s.GetDocument(ctx, "src/a.go")
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if got == nil || got.Hash != "abc" || !got.ModTime.Equal(modTime) || len(got.ChunkIDs) != 2 {
+		t.Fatalf("unexpected document: %+v", got)
+	}
+
+	files, err := s.ListFilesWithStats(ctx)
+	if err != nil {
+		t.Fatalf("failed to list files: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "src/a.go" || files[0].ChunkCount != 2 {
+		t.Fatalf("unexpected file stats: %+v", files)
+	}
+
+	if err := s.DeleteDocument(ctx, "src/a.go"); err != nil {
+		t.Fatalf("failed to delete document: %v", err)
+	}
+	if err := s.DeleteDocument(ctx, "src/a.go"); err != nil {
+		t.Fatalf("deleting a missing document should succeed: %v", err)
+	}
+	got, err = s.GetDocument(ctx, "src/a.go")
+	if err != nil {
+		t.Fatalf("failed to get document: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("expected deleted document to be missing, got %+v", got)
+	}
+}
+
+func TestWeaviateStore_DeleteByFilesAndProject(t *testing.T) {
+	f, srv := newFakeWeaviate(t)
+	ctx := context.Background()
+
+	s, err := NewWeaviateStore(ctx, srv.URL, "", "Grepai_test", 3)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	paths := make([]string, weaviateDeleteBatch+1)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("src/%d.go", i)
+	}
+	if err := s.DeleteByFiles(ctx, paths); err != nil {
+		t.Fatalf("DeleteByFiles failed: %v", err)
+	}
+	if len(f.deletes) != 2 || f.deletes[0]["operator"] != "ContainsAny" || len(f.deletes[1]["valueTextArray"].([]any)) != 1 {
+		t.Fatalf("expected two ContainsAny batches, got %v", f.deletes)
+	}
+
+	// A project name with Like wildcards is matched exactly.
+	for _, path := range []string{"ws/a*b/x.go", "ws/axxb/y.go"} {
+		if err := s.SaveDocument(ctx, Document{Path: path}); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+	f.deletes = nil
+	if err := s.DeleteByProject(ctx, "ws/a*b"); err != nil {
+		t.Fatalf("DeleteByProject failed: %v", err)
+	}
+	for _, where := range f.deletes {
+		if where["operator"] != "ContainsAny" {
+			t.Fatalf("expected exact deletes only, got %v", f.deletes)
+		}
+		if values := where["valueTextArray"].([]any); len(values) != 1 || values[0] != "ws/a*b/x.go" {
+			t.Errorf("expected only ws/a*b/x.go to be deleted, got %v", values)
+		}
+	}
+	if len(f.deletes) != 2 {
+		t.Errorf("expected the chunks and the document to be deleted, got %v", f.deletes)
+	}
+}
+
+func TestWeaviateStore_GetAllChunks(t *testing.T) {
+	_, srv := newFakeWeaviate(t)
+	ctx := context.Background()
+
+	s, err := NewWeaviateStore(ctx, srv.URL, "", "Grepai_test", 2)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	chunk := Chunk{ID: "a.go_0", FilePath: "a.go", StartLine: 1, EndLine: 9, Content: "package a", Vector: []float32{0.5, 0.25}, ContentHash: "h1"}
+	if err := s.SaveChunks(ctx, []Chunk{chunk}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	chunks, err := s.GetAllChunks(ctx)
+	if err != nil {
+		t.Fatalf("failed to get all chunks: %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	got := chunks[0]
+	if got.ID != chunk.ID || got.FilePath != "a.go" || got.EndLine != 9 || got.ContentHash != "h1" || len(got.Vector) != 2 {
+		t.Fatalf("unexpected chunk: %+v", got)
+	}
+}
+
+func TestWeaviateStore_Search(t *testing.T) {
+	f, srv := newFakeWeaviate(t)
+	ctx := context.Background()
+
+	s, err := NewWeaviateStore(ctx, srv.URL, "", "Grepai_test", 2)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	f.graphqlData = `{"Get": {"Grepai_test": [
+		{"chunk_id": "src/a.go_0", "file_path": "src/a.go", "start_line": 1, "end_line": 5, "content": "a", "_additional": {"distance": 0.25}},
+		{"chunk_id": "src?b.go_0", "file_path": "srcXb.go", "start_line": 1, "end_line": 5, "content": "b", "_additional": {"distance": 0.5}}
+	]}}`
+
+	results, err := s.Search(ctx, []float32{0.5, 1}, 5, SearchOptions{PathPrefix: "src/"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected results outside the prefix to be dropped, got %d", len(results))
+	}
+	if results[0].Chunk.FilePath != "src/a.go" || results[0].Score != 0.75 {
+		t.Errorf("unexpected result: %+v", results[0])
+	}
+
+	query := f.queries[len(f.queries)-1]
+	for _, want := range []string{"nearVector: { vector: [0.5,1] }", "limit: 5", `operator: Like, valueText: "src/*"`} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected query to contain %q, got %s", want, query)
+		}
+	}
+
+	f.graphqlData = `{"Get": {"Grepai_test": [
+		{"chunk_id": "src/a.go_0", "file_path": "src/a.go", "model": "code", "_additional": {"distance": 0.25}},
+		{"chunk_id": "docs/a.md_0", "file_path": "docs/a.md", "model": "docs", "_additional": {"distance": 0.5}}
+	]}}`
+	results, err = s.Search(ctx, []float32{0.5, 1}, 5, SearchOptions{PathPrefix: "docs/", ExcludeModel: "code"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "docs/a.md" {
+		t.Fatalf("expected only the docs chunk, got %+v", results)
+	}
+	query = f.queries[len(f.queries)-1]
+	want := `where: { operator: And, operands: [{ path: ["file_path"], operator: Like, valueText: "docs/*" }, { path: ["model"], operator: NotEqual, valueText: "code" }] }`
+	if !strings.Contains(query, want) {
+		t.Errorf("expected query to contain %q, got %s", want, query)
+	}
+
+	if _, err := s.Search(ctx, []float32{0.5, 1}, 0, SearchOptions{}); err == nil {
+		t.Error("expected an error for a non-positive limit")
+	}
+}
@@ -0,0 +1,150 @@
+package trace
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// Dynamic dispatch resolution modes (trace.dispatch in config).
+const (
+	// DispatchNone reports only direct, name-based call relationships.
+	DispatchNone = "none"
+	// DispatchConservative links an interface method to the methods of types
+	// that declare every method of the interface in the same index.
+	DispatchConservative = "conservative"
+)
+
+// DispatchEnabled reports whether interface methods should be resolved to
+// their implementations. An empty mode means the default (conservative).
+func DispatchEnabled(mode string) bool {
+	return mode == "" || mode == DispatchConservative
+}
+
+// DispatchStores returns the stores of stores whose workspace project
+// resolves interface methods to their implementations, following its own
+// trace.dispatch. Other stores are kept.
+func DispatchStores(stores []SymbolStore) []SymbolStore {
+	kept := make([]SymbolStore, 0, len(stores))
+	for _, ss := range stores {
+		if ps, ok := ss.(*ProjectSymbolStore); ok && ps.Root != "" {
+			if cfg, err := config.Load(ps.Root); err == nil && !DispatchEnabled(cfg.Trace.Dispatch) {
+				continue
+			}
+		}
+		kept = append(kept, ss)
+	}
+	return kept
+}
+
+// Implementation is a concrete method reached through an interface method.
+type Implementation struct {
+	Interface string `json:"interface"`
+	Method    Symbol `json:"method"`
+	Project   string `json:"project,omitempty"` // Workspace project (workspace mode only)
+}
+
+// CollectImplementations gathers the implementations of the interface method
+// methodName from every store. Interfaces and implementing types are matched
+// within a store, so an implementation in another workspace project is not
+// linked.
+func CollectImplementations(ctx context.Context, stores []SymbolStore, methodName string) ([]Implementation, error) {
+	var impls []Implementation
+	for _, ss := range stores {
+		found, err := ss.LookupImplementations(ctx, methodName)
+		if err != nil {
+			return nil, err
+		}
+		for _, impl := range found {
+			impl.Project = ProjectOf(ss)
+			impls = append(impls, impl)
+		}
+	}
+	return impls, nil
+}
+
+// resolveImplementations finds, in a symbol index keyed by name, the concrete
+// Go methods named methodName whose receiver type has every method declared
+// by an interface that also declares methodName. Embedded interfaces are not
+// expanded, which can only miss implementations, never invent them.
+func resolveImplementations(symbols map[string][]Symbol, methodName string) []Implementation {
+	ifaces := make(map[string]bool)
+	for _, sym := range symbols[methodName] {
+		if sym.Abstract && sym.Language == "go" {
+			ifaces[sym.Receiver] = true
+		}
+	}
+	if len(ifaces) == 0 {
+		return nil
+	}
+
+	ifaceMethods := make(map[string][]string)
+	typeMethods := make(map[string]map[string]Symbol)
+	for name, syms := range symbols {
+		for _, sym := range syms {
+			if sym.Kind != KindMethod || sym.Language != "go" {
+				continue
+			}
+			if sym.Abstract {
+				if ifaces[sym.Receiver] {
+					ifaceMethods[sym.Receiver] = append(ifaceMethods[sym.Receiver], name)
+				}
+				continue
+			}
+			typeName := receiverTypeName(sym.Receiver)
+			if typeName == "" {
+				continue
+			}
+			if typeMethods[typeName] == nil {
+				typeMethods[typeName] = make(map[string]Symbol)
+			}
+			if _, exists := typeMethods[typeName][name]; !exists {
+				typeMethods[typeName][name] = sym
+			}
+		}
+	}
+
+	var impls []Implementation
+	for iface, required := range ifaceMethods {
+		for _, methods := range typeMethods {
+			impl, ok := methods[methodName]
+			if !ok {
+				continue
+			}
+			complete := true
+			for _, name := range required {
+				if _, ok := methods[name]; !ok {
+					complete = false
+					break
+				}
+			}
+			if complete {
+				impls = append(impls, Implementation{Interface: iface, Method: impl})
+			}
+		}
+	}
+
+	sort.Slice(impls, func(i, j int) bool {
+		a, b := impls[i], impls[j]
+		if a.Interface != b.Interface {
+			return a.Interface < b.Interface
+		}
+		if a.Method.File != b.Method.File {
+			return a.Method.File < b.Method.File
+		}
+		return a.Method.Line < b.Method.Line
+	})
+	return impls
+}
+
+// receiverTypeName strips pointer and type-parameter syntax from a Go
+// receiver ("*Store[T]" -> "Store").
+func receiverTypeName(receiver string) string {
+	name := strings.TrimLeft(strings.TrimSpace(receiver), "*")
+	if idx := strings.Index(name, "["); idx > 0 {
+		name = name[:idx]
+	}
+	return name
+}
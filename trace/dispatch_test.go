@@ -0,0 +1,81 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestLookupImplementations(t *testing.T) {
+	ctx := context.Background()
+	store := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+
+	method := func(receiver, name string, line int, abstract bool) Symbol {
+		return Symbol{Name: name, Kind: KindMethod, File: "store.go", Line: line, Receiver: receiver, Language: "go", Abstract: abstract}
+	}
+	if err := store.SaveFile(ctx, "store.go", []Symbol{
+		method("Store", "Load", 1, true),
+		method("Store", "Close", 2, true),
+		method("*GOBStore", "Load", 10, false),
+		method("*GOBStore", "Close", 11, false),
+		method("MemStore[T]", "Load", 20, false),
+		method("MemStore[T]", "Close", 21, false),
+		method("Partial", "Load", 30, false), // missing Close
+	}, nil); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+
+	impls, err := store.LookupImplementations(ctx, "Load")
+	if err != nil {
+		t.Fatalf("LookupImplementations failed: %v", err)
+	}
+	if len(impls) != 2 {
+		t.Fatalf("expected 2 implementations, got %+v", impls)
+	}
+	if impls[0].Interface != "Store" || impls[0].Method.Line != 10 || impls[1].Method.Line != 20 {
+		t.Fatalf("unexpected implementations: %+v", impls)
+	}
+
+	impls, err = store.LookupImplementations(ctx, "Partial")
+	if err != nil {
+		t.Fatalf("LookupImplementations failed: %v", err)
+	}
+	if len(impls) != 0 {
+		t.Fatalf("expected no implementations for a non-interface method, got %+v", impls)
+	}
+}
+
+func TestDispatchEnabled(t *testing.T) {
+	if !DispatchEnabled("") || !DispatchEnabled(DispatchConservative) {
+		t.Error("expected dispatch to be enabled by default and in conservative mode")
+	}
+	if DispatchEnabled(DispatchNone) {
+		t.Error("expected dispatch to be disabled in none mode")
+	}
+}
+
+func TestDispatchStores(t *testing.T) {
+	off := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Trace.Dispatch = DispatchNone
+	if err := cfg.Save(off); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	on := t.TempDir()
+	if err := config.DefaultConfig().Save(on); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	plain := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	stores := []SymbolStore{
+		&ProjectSymbolStore{SymbolStore: plain, Project: "off", Root: off},
+		&ProjectSymbolStore{SymbolStore: plain, Project: "on", Root: on},
+		plain,
+	}
+	kept := DispatchStores(stores)
+	if len(kept) != 2 || kept[0] != stores[1] || kept[1] != stores[2] {
+		t.Errorf("expected the project with trace.dispatch: none to be left out, got %+v", kept)
+	}
+}
@@ -0,0 +1,43 @@
+package trace
+
+// EnclosingSymbol returns the symbol of symbols (all from one file) that best
+// describes the line range [startLine, endLine]: the innermost definition
+// containing startLine, else the first definition starting inside the range,
+// else the nearest preceding definition whose end line is unknown (fast
+// extraction does not record end lines). Variables, constants and interface
+// method declarations are ignored. It returns nil when nothing matches.
+func EnclosingSymbol(symbols []Symbol, startLine, endLine int) *Symbol {
+	var containing, inside, preceding *Symbol
+	for i := range symbols {
+		sym := &symbols[i]
+		switch sym.Kind {
+		case KindVariable, KindConstant:
+			continue
+		}
+		if sym.Abstract || sym.Line <= 0 {
+			continue
+		}
+		switch {
+		case sym.Line <= startLine && sym.EndLine >= startLine:
+			if containing == nil || sym.Line > containing.Line {
+				containing = sym
+			}
+		case sym.Line >= startLine && sym.Line <= endLine:
+			if inside == nil || sym.Line < inside.Line {
+				inside = sym
+			}
+		case sym.Line < startLine && sym.EndLine == 0:
+			if preceding == nil || sym.Line > preceding.Line {
+				preceding = sym
+			}
+		}
+	}
+	switch {
+	case containing != nil:
+		return containing
+	case inside != nil:
+		return inside
+	default:
+		return preceding
+	}
+}
@@ -0,0 +1,38 @@
+package trace
+
+import "testing"
+
+func TestEnclosingSymbol(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "Server", Kind: KindClass, Line: 5, EndLine: 40},
+		{Name: "Start", Kind: KindMethod, Line: 10, EndLine: 20},
+		{Name: "maxConns", Kind: KindVariable, Line: 12},
+		{Name: "Load", Kind: KindMethod, Line: 3, Receiver: "Store", Abstract: true},
+		{Name: "helper", Kind: KindFunction, Line: 50},
+		{Name: "cleanup", Kind: KindFunction, Line: 70},
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       string
+	}{
+		{"innermost containing", 12, 18, "Start"},
+		{"outer containing", 25, 30, "Server"},
+		{"starts inside chunk", 45, 60, "helper"},
+		{"preceding without end line", 55, 60, "helper"},
+		{"before any symbol", 1, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EnclosingSymbol(symbols, tt.start, tt.end)
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.want {
+				t.Errorf("EnclosingSymbol(%d, %d) = %q, want %q", tt.start, tt.end, name, tt.want)
+			}
+		})
+	}
+}
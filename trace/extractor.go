@@ -2,11 +2,12 @@ package trace
 
 import (
 	"context"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
 )
 
 // RegexExtractor implements SymbolExtractor using regex patterns.
@@ -37,7 +38,7 @@ func (e *RegexExtractor) SupportedLanguages() []string {
 
 // ExtractSymbols extracts all symbol definitions from a file.
 func (e *RegexExtractor) ExtractSymbols(ctx context.Context, filePath string, content string) ([]Symbol, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
+	ext := langdetect.Ext(filePath, content)
 	patterns := e.patterns[ext]
 	if patterns == nil {
 		return nil, nil
@@ -65,12 +66,17 @@ func (e *RegexExtractor) ExtractSymbols(ctx context.Context, filePath string, co
 	for _, re := range patterns.Interfaces {
 		symbols = append(symbols, e.extractMatches(re, content, filePath, patterns.Language, KindInterface)...)
 	}
+	if patterns.Language == "go" {
+		symbols = append(symbols, extractGoInterfaceMethods(content, filePath)...)
+	}
 
 	// Extract types
 	for _, re := range patterns.Types {
 		symbols = append(symbols, e.extractMatches(re, content, filePath, patterns.Language, KindType)...)
 	}
 
+	symbols = append(symbols, extractSpanSymbols(filePath, content, patterns.Language)...)
+
 	return symbols, nil
 }
 
@@ -140,9 +146,65 @@ func (e *RegexExtractor) extractMethodMatches(re *regexp.Regexp, content string,
 	return symbols
 }
 
+var (
+	goInterfaceDecl   = regexp.MustCompile(`(?m)^type\s+([A-Za-z_][A-Za-z0-9_]*)(?:\[[^\]]*\])?\s+interface\s*\{`)
+	goInterfaceMethod = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+)
+
+// extractGoInterfaceMethods records the methods declared by Go interfaces as
+// abstract method symbols whose receiver is the interface name, so dispatch
+// resolution can match them against concrete method sets.
+func extractGoInterfaceMethods(content string, filePath string) []Symbol {
+	var symbols []Symbol
+	for _, match := range goInterfaceDecl.FindAllStringSubmatchIndex(content, -1) {
+		iface := content[match[2]:match[3]]
+		bodyStart := match[1]
+		bodyEnd := matchingBrace(content, bodyStart-1)
+		if bodyEnd < 0 {
+			continue
+		}
+		line := countLines(content[:bodyStart]) + 1
+		for _, raw := range strings.Split(content[bodyStart:bodyEnd], "\n") {
+			text := strings.TrimSpace(raw)
+			if m := goInterfaceMethod.FindStringSubmatch(text); m != nil {
+				symbols = append(symbols, Symbol{
+					Name:      m[1],
+					Kind:      KindMethod,
+					File:      filePath,
+					Line:      line,
+					Signature: text,
+					Receiver:  iface,
+					Exported:  isExported(m[1], "go"),
+					Language:  "go",
+					Abstract:  true,
+				})
+			}
+			line++
+		}
+	}
+	return symbols
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or -1.
+func matchingBrace(content string, open int) int {
+	depth := 0
+	for i := open; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 // ExtractReferences extracts all symbol references from a file.
 func (e *RegexExtractor) ExtractReferences(ctx context.Context, filePath string, content string) ([]Reference, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
+	ext := langdetect.Ext(filePath, content)
 	patterns := e.patterns[ext]
 	if patterns == nil {
 		return nil, nil
@@ -203,6 +265,11 @@ func (e *RegexExtractor) ExtractReferences(ctx context.Context, filePath string,
 	}
 
 	refs = append(refs, e.extractLanguageSpecificReferences(filePath, content, lines, patterns, functionBoundaries)...)
+	refs = append(refs, extractUsageReferences(filePath, content, lines, patterns.Language, ignored, functionBoundaries)...)
+	if !isSpanLanguage(patterns.Language) {
+		refs = append(refs, extractLiteralReferences(filePath, content, lines, patterns.Language, functionBoundaries)...)
+		refs = append(refs, extractRouteReferences(filePath, content, lines, patterns.Language, functionBoundaries)...)
+	}
 
 	return dedupeReferences(refs), nil
 }
@@ -229,6 +296,8 @@ func (e *RegexExtractor) extractLanguageSpecificReferences(filePath string, cont
 		return e.extractJSPropertyReferences(filePath, content, lines, functionBoundaries)
 	case "lua":
 		return e.extractLuaBracketKeyReferences(filePath, content, lines, patterns, functionBoundaries)
+	case "make", "dockerfile", "terraform", "sql":
+		return extractSpanReferences(filePath, content, lines, patterns.Language)
 	default:
 		return nil
 	}
@@ -1023,7 +1092,7 @@ func dedupeReferences(refs []Reference) []Reference {
 	deduped := make([]Reference, 0, len(refs))
 
 	for _, ref := range refs {
-		key := ref.SymbolName + "\x00" + ref.Kind + "\x00" + ref.File + "\x00" + ref.CallerName + "\x00" + strconv.Itoa(ref.Line) + "\x00" + strconv.Itoa(ref.CallerLine)
+		key := ref.SymbolName + "\x00" + ref.Kind + "\x00" + ref.Category + "\x00" + ref.File + "\x00" + ref.CallerName + "\x00" + strconv.Itoa(ref.Line) + "\x00" + strconv.Itoa(ref.CallerLine)
 		if seen[key] {
 			continue
 		}
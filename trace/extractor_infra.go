@@ -0,0 +1,426 @@
+package trace
+
+import (
+	"regexp"
+	"strings"
+)
+
+// makeLine is a logical Makefile line: backslash-newline continuations are
+// replaced by two spaces, so offsets in text are offsets in the file.
+type makeLine struct {
+	text   string
+	pos    int
+	recipe bool
+}
+
+// makeLines splits content into logical lines.
+func makeLines(content string) []makeLine {
+	var lines []makeLine
+	for pos := 0; pos < len(content); {
+		end := pos
+		for end < len(content) {
+			nl := strings.IndexByte(content[end:], '\n')
+			if nl < 0 {
+				end = len(content)
+				break
+			}
+			end += nl
+			if end > pos && content[end-1] == '\\' {
+				end++
+				continue
+			}
+			break
+		}
+		text := strings.ReplaceAll(content[pos:end], "\\\n", "  ")
+		lines = append(lines, makeLine{text: text, pos: pos, recipe: strings.HasPrefix(text, "\t")})
+		pos = end + 1
+	}
+	return lines
+}
+
+var (
+	makeDefineRe = regexp.MustCompile(`^\s*define\s+([A-Za-z_][\w.-]*)`)
+	makeEndefRe  = regexp.MustCompile(`^\s*endef\b`)
+	makeAssignRe = regexp.MustCompile(`^\s*(?:(?:export|override|private)\s+)*([A-Za-z_.][\w.-]*)\s*(?::{1,3}=|[?+!]?=)`)
+	makeWordRe   = regexp.MustCompile(`[^\s|]+`)
+	makeCallRe   = regexp.MustCompile(`\$[({]call[ \t]+([A-Za-z_][\w.-]*)`)
+	makeVarRe    = regexp.MustCompile(`\$[({]([A-Za-z_][\w.-]*)(?::[^)}]*)?[)}]`)
+	makeSubRe    = regexp.MustCompile(`(?:\$[({]MAKE[)}]|(?:^|[\s;&|(])make)((?:[ \t]+[^\s;&|)]+)*)`)
+)
+
+// makeDirectives start non-rule lines that makeRule would misread.
+var makeDirectives = map[string]bool{
+	"include": true, "-include": true, "sinclude": true, "vpath": true,
+	"ifeq": true, "ifneq": true, "ifdef": true, "ifndef": true, "else": true, "endif": true,
+	"export": true, "unexport": true, "override": true, "undefine": true,
+}
+
+// makeRule splits a rule line into its targets and the text after the
+// colon, holding its prerequisites. ok is false for lines that are not
+// rules, including target-specific variable assignments.
+func makeRule(text string) (targets []string, prereqs string, prereqsPos int, ok bool) {
+	if fields := strings.Fields(text); len(fields) == 0 || makeDirectives[fields[0]] {
+		return nil, "", 0, false
+	}
+	depth := 0
+	colon := -1
+	for i := 0; i < len(text) && colon < 0; i++ {
+		switch text[i] {
+		case '(', '{':
+			depth++
+		case ')', '}':
+			depth--
+		case ':':
+			if depth == 0 {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return nil, "", 0, false
+	}
+	rest := colon + 1
+	if rest < len(text) && text[rest] == ':' {
+		rest++ // double-colon rule
+	}
+	prereqs = text[rest:]
+	if i := strings.IndexByte(prereqs, ';'); i >= 0 {
+		prereqs = prereqs[:i] // inline recipe
+	}
+	if strings.Contains(prereqs, "=") {
+		return nil, "", 0, false
+	}
+	for _, t := range strings.Fields(text[:colon]) {
+		if isMakeTargetName(t) {
+			targets = append(targets, t)
+		}
+	}
+	return targets, prereqs, rest, true
+}
+
+// isMakeTargetName reports whether t names a target others can depend on:
+// neither a pattern, a computed name nor a special target like .PHONY.
+func isMakeTargetName(t string) bool {
+	if strings.ContainsAny(t, "$%") {
+		return false
+	}
+	if strings.HasPrefix(t, ".") && strings.ToUpper(t) == t && len(t) > 1 {
+		return false
+	}
+	return true
+}
+
+// stripMakeComment cuts a # comment off a non-recipe line.
+func stripMakeComment(text string) string {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '#' && (i == 0 || text[i-1] != '\\') {
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// makeDecls returns the targets, define macros and variables of a Makefile.
+// A target's body runs to the last line of its recipe.
+func makeDecls(content string) []spanDecl {
+	var decls []spanDecl
+	lines := makeLines(content)
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		if l.recipe {
+			continue
+		}
+		text := stripMakeComment(l.text)
+		lineEnd := l.pos + len(l.text)
+
+		if m := makeDefineRe.FindStringSubmatch(text); m != nil {
+			end := len(content)
+			for i+1 < len(lines) {
+				i++
+				if makeEndefRe.MatchString(lines[i].text) {
+					end = lines[i].pos + len(lines[i].text)
+					break
+				}
+			}
+			decls = append(decls, spanDecl{name: m[1], kind: KindFunction, start: l.pos, end: end})
+			continue
+		}
+		if m := makeAssignRe.FindStringSubmatch(text); m != nil {
+			decls = append(decls, spanDecl{name: m[1], kind: KindVariable, start: l.pos, end: lineEnd})
+			continue
+		}
+		targets, _, _, ok := makeRule(text)
+		if !ok {
+			continue
+		}
+		// The rule ends with its last recipe line; blank and comment lines
+		// may come in between.
+		for j := i + 1; j < len(lines) && (lines[j].recipe || strings.TrimSpace(stripMakeComment(lines[j].text)) == ""); j++ {
+			if lines[j].recipe {
+				lineEnd = lines[j].pos + len(lines[j].text)
+			}
+		}
+		for _, t := range targets {
+			decls = append(decls, spanDecl{name: t, kind: KindFunction, start: l.pos, end: lineEnd})
+		}
+	}
+	return decls
+}
+
+// makeReferences records prerequisites, sub-make targets and $(call) macros
+// as calls, and $(VAR) expansions as reads.
+func makeReferences(content string, add func(name string, pos int, kind string)) {
+	inDefine := false
+	for _, l := range makeLines(content) {
+		text := l.text
+		switch {
+		case inDefine:
+			inDefine = !makeEndefRe.MatchString(text)
+			addMakeRecipeReferences(l, add)
+		case l.recipe:
+			if strings.HasPrefix(strings.TrimLeft(text, " \t@-+"), "#") {
+				continue
+			}
+			addMakeRecipeReferences(l, add)
+		default:
+			text = stripMakeComment(text)
+			if makeDefineRe.MatchString(text) {
+				inDefine = true
+				continue
+			}
+			if targets, prereqs, at, ok := makeRule(text); ok && len(targets) > 0 {
+				for _, w := range makeWordRe.FindAllStringIndex(prereqs, -1) {
+					name := prereqs[w[0]:w[1]]
+					if strings.ContainsAny(name, "$%") {
+						continue
+					}
+					add(name, l.pos+at+w[0], RefKindCall)
+				}
+			}
+		}
+		addMakeExpansionReferences(text, l.pos, add)
+	}
+}
+
+// addMakeRecipeReferences records the targets of recursive make
+// invocations in a recipe or macro line.
+func addMakeRecipeReferences(l makeLine, add func(name string, pos int, kind string)) {
+	for _, m := range makeSubRe.FindAllStringSubmatchIndex(l.text, -1) {
+		addMakeSubTargets(l.text, m[2], m[3], l.pos, add)
+	}
+}
+
+// addMakeExpansionReferences records $(call macro) as calls and $(VAR) as
+// reads.
+func addMakeExpansionReferences(text string, base int, add func(name string, pos int, kind string)) {
+	for _, m := range makeCallRe.FindAllStringSubmatchIndex(text, -1) {
+		add(text[m[2]:m[3]], base+m[2], RefKindCall)
+	}
+	for _, m := range makeVarRe.FindAllStringSubmatchIndex(text, -1) {
+		if name := text[m[2]:m[3]]; name != "MAKE" {
+			add(name, base+m[2], RefKindRead)
+		}
+	}
+}
+
+// addMakeSubTargets records the targets named by the arguments of a
+// recursive make invocation, skipping options and variable overrides.
+func addMakeSubTargets(text string, start, end, base int, add func(name string, pos int, kind string)) {
+	if start < 0 {
+		return
+	}
+	args := text[start:end]
+	skipNext := false
+	for _, w := range makeWordRe.FindAllStringIndex(args, -1) {
+		arg := args[w[0]:w[1]]
+		switch {
+		case skipNext:
+			skipNext = false
+		case arg == "-C" || arg == "-f" || arg == "-I" || arg == "-o" || arg == "-W":
+			skipNext = true
+		case strings.HasPrefix(arg, "-"), strings.ContainsAny(arg, "=$\"'`"):
+		default:
+			add(arg, base+start+w[0], RefKindCall)
+		}
+	}
+}
+
+var (
+	dockerFromRe     = regexp.MustCompile(`(?im)^[ \t]*FROM[ \t]+(?:--\S+[ \t]+)*(\S+)(?:[ \t]+AS[ \t]+(\S+))?`)
+	dockerStageRefRe = regexp.MustCompile(`(?i)--(?:from|mount=\S*?from)=([^\s,]+)`)
+)
+
+// dockerfileDecls returns the named build stages of a Dockerfile. A stage
+// runs to the next FROM instruction.
+func dockerfileDecls(content string) []spanDecl {
+	var decls []spanDecl
+	froms := dockerFromRe.FindAllStringSubmatchIndex(content, -1)
+	for i, m := range froms {
+		end := len(content)
+		if i+1 < len(froms) {
+			end = froms[i+1][0]
+		}
+		if m[4] >= 0 {
+			decls = append(decls, spanDecl{name: content[m[4]:m[5]], kind: KindClass, start: m[0], end: end})
+		}
+	}
+	return decls
+}
+
+// dockerfileReferences records stages built FROM another stage, or copying
+// or mounting files from one, as calls. Images from a registry are not
+// references.
+func dockerfileReferences(content string, decls []spanDecl, add func(name string, pos int, kind string)) {
+	stages := make(map[string]string, len(decls))
+	for _, d := range decls {
+		stages[strings.ToLower(d.name)] = d.name
+	}
+	ref := func(start, end int) {
+		if name, ok := stages[strings.ToLower(content[start:end])]; ok {
+			add(name, start, RefKindCall)
+		}
+	}
+	for _, m := range dockerFromRe.FindAllStringSubmatchIndex(content, -1) {
+		ref(m[2], m[3])
+	}
+	for _, m := range dockerStageRefRe.FindAllStringSubmatchIndex(content, -1) {
+		ref(m[2], m[3])
+	}
+}
+
+var (
+	terraformBlockRe = regexp.MustCompile(`(?m)^(resource|data|module|variable|output|locals)((?:[ \t]+"[^"\n]*")*)[ \t]*\{`)
+	terraformLabelRe = regexp.MustCompile(`"([^"\n]*)"`)
+	terraformLocalRe = regexp.MustCompile(`(?m)^[ \t]*([A-Za-z_][\w-]*)[ \t]*=`)
+
+	terraformNamedRefRe    = regexp.MustCompile(`\b(var|local|module)\.([A-Za-z_][\w-]*)`)
+	terraformDataRefRe     = regexp.MustCompile(`\bdata\.([A-Za-z_][\w-]*)\.([A-Za-z_][\w-]*)`)
+	terraformResourceRefRe = regexp.MustCompile(`(^|[^\w.\-/])([a-z][a-z0-9]*_[a-z0-9_]*)\.([A-Za-z_][\w-]*)`)
+)
+
+// terraformDecls returns the blocks of a Terraform file and the values of
+// its locals blocks.
+func terraformDecls(content string) []spanDecl {
+	var decls []spanDecl
+	for _, m := range terraformBlockRe.FindAllStringSubmatchIndex(content, -1) {
+		block := content[m[2]:m[3]]
+		var labels []string
+		for _, l := range terraformLabelRe.FindAllStringSubmatch(content[m[4]:m[5]], -1) {
+			labels = append(labels, l[1])
+		}
+		end := matchingBrace(content, m[1]-1)
+		if end < 0 {
+			end = len(content)
+		} else {
+			end++
+		}
+
+		var name string
+		var kind SymbolKind
+		switch {
+		case block == "resource" && len(labels) == 2:
+			name, kind = labels[0]+"."+labels[1], KindType
+		case block == "data" && len(labels) == 2:
+			name, kind = "data."+labels[0]+"."+labels[1], KindType
+		case block == "module" && len(labels) == 1:
+			name, kind = "module."+labels[0], KindClass
+		case block == "variable" && len(labels) == 1:
+			name, kind = "var."+labels[0], KindVariable
+		case block == "output" && len(labels) == 1:
+			name, kind = "output."+labels[0], KindConstant
+		case block == "locals" && len(labels) == 0:
+			decls = append(decls, terraformLocals(content, m[1], end)...)
+			continue
+		default:
+			continue
+		}
+		decls = append(decls, spanDecl{name: name, kind: kind, start: m[0], end: end})
+	}
+	return decls
+}
+
+// terraformLocals returns the top-level attributes of the locals block body
+// content[start:end]. Each value runs to the next attribute.
+func terraformLocals(content string, start, end int) []spanDecl {
+	var decls []spanDecl
+	depth := 0
+	pos := start
+	for _, line := range strings.SplitAfter(content[start:end], "\n") {
+		if depth == 0 {
+			if m := terraformLocalRe.FindStringSubmatchIndex(line); m != nil {
+				if n := len(decls); n > 0 {
+					decls[n-1].end = pos
+				}
+				decls = append(decls, spanDecl{name: "local." + line[m[2]:m[3]], kind: KindVariable, start: pos + m[2], end: end - 1})
+			}
+		}
+		depth += strings.Count(line, "{") + strings.Count(line, "[") + strings.Count(line, "(")
+		depth -= strings.Count(line, "}") + strings.Count(line, "]") + strings.Count(line, ")")
+		pos += len(line)
+	}
+	return decls
+}
+
+// terraformReferences records references to modules, resources and data
+// sources as calls and to variables and locals as reads, in expressions and
+// string interpolations alike.
+func terraformReferences(content string, add func(name string, pos int, kind string)) {
+	comments := terraformCommentMask(content)
+	for _, m := range terraformNamedRefRe.FindAllStringSubmatchIndex(content, -1) {
+		if comments[m[0]] {
+			continue
+		}
+		kind := RefKindRead
+		if content[m[2]:m[3]] == "module" {
+			kind = RefKindCall
+		}
+		add(content[m[0]:m[1]], m[0], kind)
+	}
+	for _, m := range terraformDataRefRe.FindAllStringSubmatchIndex(content, -1) {
+		if !comments[m[0]] {
+			add(content[m[0]:m[1]], m[0], RefKindCall)
+		}
+	}
+	for _, m := range terraformResourceRefRe.FindAllStringSubmatchIndex(content, -1) {
+		// "name_with.extension" is a file name, not a resource.
+		if comments[m[4]] || m[1] < len(content) && content[m[1]] == '"' {
+			continue
+		}
+		add(content[m[4]:m[7]], m[4], RefKindCall)
+	}
+}
+
+// terraformCommentMask marks the bytes of #, // and /* */ comments. Strings
+// are not masked: references in interpolations count.
+func terraformCommentMask(content string) []bool {
+	mask := make([]bool, len(content))
+	inString := false
+	for i := 0; i < len(content); i++ {
+		ch := content[i]
+		switch {
+		case inString:
+			if ch == '\\' {
+				i++
+			} else if ch == '"' || ch == '\n' {
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+		case ch == '#' || ch == '/' && i+1 < len(content) && content[i+1] == '/':
+			for ; i < len(content) && content[i] != '\n'; i++ {
+				mask[i] = true
+			}
+		case ch == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			stop := len(content)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				mask[i] = true
+			}
+			i--
+		}
+	}
+	return mask
+}
@@ -0,0 +1,212 @@
+package trace
+
+import "testing"
+
+func TestRegexExtractor_Makefile(t *testing.T) {
+	content := `GO ?= go
+BIN := bin/app
+
+.PHONY: all build test
+
+# Build everything.
+all: build docs
+
+build: generate \
+	vet
+	$(GO) build -o $(BIN) ./...
+
+test: build
+	$(MAKE) -C sub integration
+	$(call notify,tests)
+
+define notify
+	@echo "$(1) done"
+endef
+
+%.o: %.c
+	cc -c $<
+`
+	symbols := spanSymbols(t, "Makefile", content)
+	for name, kind := range map[string]SymbolKind{
+		"all": KindFunction, "build": KindFunction, "test": KindFunction,
+		"notify": KindFunction, "GO": KindVariable, "BIN": KindVariable,
+	} {
+		sym, ok := symbols[name]
+		if !ok {
+			t.Errorf("expected symbol %s, got %v", name, symbols)
+			continue
+		}
+		if sym.Kind != kind || sym.Language != "make" {
+			t.Errorf("symbol %s: got kind %s language %s, want %s make", name, sym.Kind, sym.Language, kind)
+		}
+	}
+	for _, name := range []string{".PHONY", "%.o"} {
+		if _, ok := symbols[name]; ok {
+			t.Errorf("did not expect symbol %s", name)
+		}
+	}
+	if build := symbols["build"]; build.Line != 9 || build.EndLine != 11 {
+		t.Errorf("build: got lines %d-%d, want 9-11", build.Line, build.EndLine)
+	}
+
+	refs := spanRefKeys(t, "Makefile", content)
+	for _, want := range []refKey{
+		{"build", RefKindCall, "all"},
+		{"docs", RefKindCall, "all"},
+		{"generate", RefKindCall, "build"},
+		{"vet", RefKindCall, "build"},
+		{"GO", RefKindRead, "build"},
+		{"BIN", RefKindRead, "build"},
+		{"build", RefKindCall, "test"},
+		{"integration", RefKindCall, "test"},
+		{"notify", RefKindCall, "test"},
+	} {
+		if !refs[want] {
+			t.Errorf("missing reference %+v in %v", want, refs)
+		}
+	}
+	for _, unwanted := range []refKey{
+		{"sub", RefKindCall, "test"},
+		{"Build", RefKindCall, "<top-level>"},
+	} {
+		if refs[unwanted] {
+			t.Errorf("unexpected reference %+v", unwanted)
+		}
+	}
+}
+
+func TestRegexExtractor_Dockerfile(t *testing.T) {
+	content := `# syntax=docker/dockerfile:1
+FROM golang:1.22 AS builder
+RUN go build -o /app ./cmd/app
+
+FROM builder AS tester
+RUN go test ./...
+
+FROM --platform=linux/amd64 alpine:3.19 AS runtime
+COPY --from=builder /app /app
+RUN --mount=type=cache,from=tester,target=/cache true
+COPY --from=nginx:latest /etc/nginx /etc/nginx
+`
+	symbols := spanSymbols(t, "build/Dockerfile.prod", content)
+	for _, name := range []string{"builder", "tester", "runtime"} {
+		if sym, ok := symbols[name]; !ok || sym.Kind != KindClass || sym.Language != "dockerfile" {
+			t.Errorf("expected dockerfile stage %s, got %+v", name, symbols[name])
+		}
+	}
+	if builder := symbols["builder"]; builder.Line != 2 || builder.EndLine != 4 {
+		t.Errorf("builder: got lines %d-%d, want 2-4", builder.Line, builder.EndLine)
+	}
+
+	refs := spanRefKeys(t, "build/Dockerfile.prod", content)
+	for _, want := range []refKey{
+		{"builder", RefKindCall, "tester"},
+		{"builder", RefKindCall, "runtime"},
+		{"tester", RefKindCall, "runtime"},
+	} {
+		if !refs[want] {
+			t.Errorf("missing reference %+v in %v", want, refs)
+		}
+	}
+	for ref := range refs {
+		if ref.name == "golang:1.22" || ref.name == "nginx:latest" || ref.name == "alpine:3.19" {
+			t.Errorf("unexpected reference to image %s", ref.name)
+		}
+	}
+}
+
+func TestRegexExtractor_Terraform(t *testing.T) {
+	content := `variable "region" {
+  type = string
+}
+
+locals {
+  bucket_name = "logs-${var.region}"
+  tags = {
+    team = "infra"
+  }
+}
+
+# module.legacy is gone.
+module "vpc" {
+  source = "./modules/vpc"
+  region = var.region
+}
+
+data "aws_ami" "ubuntu" {
+  most_recent = true
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket    = local.bucket_name
+  subnet_id = module.vpc.private_subnets[0]
+  user_data = file("${path.module}/user_data.sh")
+}
+
+resource "aws_instance" "web" {
+  ami        = data.aws_ami.ubuntu.id
+  depends_on = [aws_s3_bucket.logs]
+}
+
+output "bucket_arn" {
+  value = aws_s3_bucket.logs.arn
+}
+`
+	symbols := spanSymbols(t, "main.tf", content)
+	for name, kind := range map[string]SymbolKind{
+		"var.region":          KindVariable,
+		"local.bucket_name":   KindVariable,
+		"local.tags":          KindVariable,
+		"module.vpc":          KindClass,
+		"data.aws_ami.ubuntu": KindType,
+		"aws_s3_bucket.logs":  KindType,
+		"aws_instance.web":    KindType,
+		"output.bucket_arn":   KindConstant,
+	} {
+		sym, ok := symbols[name]
+		if !ok {
+			t.Errorf("expected symbol %s, got %v", name, symbols)
+			continue
+		}
+		if sym.Kind != kind || sym.Language != "terraform" {
+			t.Errorf("symbol %s: got kind %s language %s, want %s terraform", name, sym.Kind, sym.Language, kind)
+		}
+	}
+	if _, ok := symbols["local.team"]; ok {
+		t.Error("nested attribute team should not be a local")
+	}
+	if logs := symbols["aws_s3_bucket.logs"]; logs.Line != 22 || logs.EndLine != 26 {
+		t.Errorf("aws_s3_bucket.logs: got lines %d-%d, want 22-26", logs.Line, logs.EndLine)
+	}
+
+	refs := spanRefKeys(t, "main.tf", content)
+	for _, want := range []refKey{
+		{"var.region", RefKindRead, "local.bucket_name"},
+		{"var.region", RefKindRead, "module.vpc"},
+		{"local.bucket_name", RefKindRead, "aws_s3_bucket.logs"},
+		{"module.vpc", RefKindCall, "aws_s3_bucket.logs"},
+		{"data.aws_ami.ubuntu", RefKindCall, "aws_instance.web"},
+		{"aws_s3_bucket.logs", RefKindCall, "aws_instance.web"},
+		{"aws_s3_bucket.logs", RefKindCall, "output.bucket_arn"},
+	} {
+		if !refs[want] {
+			t.Errorf("missing reference %+v in %v", want, refs)
+		}
+	}
+	for ref := range refs {
+		if ref.name == "module.legacy" || ref.name == "user_data.sh" {
+			t.Errorf("unexpected reference %+v", ref)
+		}
+	}
+}
+
+func TestIsEntryPoint_MakeTargets(t *testing.T) {
+	target := Symbol{Name: "build", Kind: KindFunction, Language: "make", Signature: "build: generate"}
+	if !IsEntryPoint(target) {
+		t.Error("make targets should be entry points")
+	}
+	macro := Symbol{Name: "notify", Kind: KindFunction, Language: "make", Signature: "define notify"}
+	if IsEntryPoint(macro) {
+		t.Error("make macros should not be entry points")
+	}
+}
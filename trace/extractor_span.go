@@ -0,0 +1,108 @@
+package trace
+
+// spanDecl is a declaration found by a language-specific parser rather than
+// by a pattern, with the extent of its body, which makes it the caller of
+// the references inside.
+type spanDecl struct {
+	name  string
+	pkg   string
+	kind  SymbolKind
+	start int
+	end   int
+}
+
+// spanDecls returns the declarations of content in a language parsed into
+// spans, or nil for other languages.
+func spanDecls(content string, lang string) []spanDecl {
+	switch lang {
+	case "make":
+		return makeDecls(content)
+	case "dockerfile":
+		return dockerfileDecls(content)
+	case "terraform":
+		return terraformDecls(content)
+	case "sql":
+		return sqlDecls(content)
+	default:
+		return nil
+	}
+}
+
+// isSpanLanguage reports whether lang is parsed into spans by spanDecls.
+func isSpanLanguage(lang string) bool {
+	switch lang {
+	case "make", "dockerfile", "terraform", "sql":
+		return true
+	}
+	return false
+}
+
+// extractSpanSymbols returns the symbols declared by a Makefile (targets,
+// macros and variables), a Dockerfile (named build stages), a Terraform
+// file (resources, data sources, modules, variables, locals and outputs) or
+// a SQL file (tables, views, types, functions, procedures and triggers).
+// Terraform symbols are named as Terraform expressions refer to them, such
+// as aws_s3_bucket.logs, module.vpc or var.region; SQL symbols by their
+// unqualified name, with the schema as package.
+func extractSpanSymbols(filePath string, content string, lang string) []Symbol {
+	decls := spanDecls(content, lang)
+	symbols := make([]Symbol, 0, len(decls))
+	for _, d := range decls {
+		end := d.end
+		if end > d.start && content[end-1] == '\n' {
+			end--
+		}
+		symbols = append(symbols, Symbol{
+			Name:      d.name,
+			Kind:      d.kind,
+			File:      filePath,
+			Line:      countLines(content[:d.start]) + 1,
+			EndLine:   countLines(content[:end]) + 1,
+			Signature: extractSignature(content, d.start, d.start),
+			Package:   d.pkg,
+			Exported:  true,
+			Language:  lang,
+		})
+	}
+	return symbols
+}
+
+// extractSpanReferences returns the references of a file parsed into spans.
+// Dependencies between make targets, Dockerfile stages and Terraform blocks
+// are recorded as calls, so trace callers and callees follow them; SQL
+// table accesses are reads and writes, and routine invocations calls.
+func extractSpanReferences(filePath string, content string, lines []string, lang string) []Reference {
+	decls := spanDecls(content, lang)
+	boundaries := spanBoundaries(content, decls)
+
+	var refs []Reference
+	add := func(name string, pos int, kind string) {
+		refs = append(refs, buildDataReference(filePath, content, lines, name, pos, kind, boundaries))
+	}
+	switch lang {
+	case "make":
+		makeReferences(content, add)
+	case "dockerfile":
+		dockerfileReferences(content, decls, add)
+	case "terraform":
+		terraformReferences(content, add)
+	case "sql":
+		sqlReferences(content, add)
+	}
+	return refs
+}
+
+// spanBoundaries returns the function boundaries of decls, the declarations
+// of content.
+func spanBoundaries(content string, decls []spanDecl) []functionBoundary {
+	boundaries := make([]functionBoundary, 0, len(decls))
+	for _, d := range decls {
+		boundaries = append(boundaries, functionBoundary{
+			Name:     d.name,
+			StartPos: d.start,
+			EndPos:   d.end,
+			Line:     countLines(content[:d.start]) + 1,
+		})
+	}
+	return boundaries
+}
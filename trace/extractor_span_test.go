@@ -0,0 +1,41 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+// refKey identifies a reference by symbol, kind and caller in tests.
+type refKey struct {
+	name, kind, caller string
+}
+
+// spanRefKeys returns the references extracted from a file of a language
+// parsed into spans.
+func spanRefKeys(t *testing.T, filePath, content string) map[refKey]bool {
+	t.Helper()
+	refs, err := NewRegexExtractor().ExtractReferences(context.Background(), filePath, content)
+	if err != nil {
+		t.Fatalf("ExtractReferences failed: %v", err)
+	}
+	keys := make(map[refKey]bool, len(refs))
+	for _, r := range refs {
+		keys[refKey{r.SymbolName, r.Kind, r.CallerName}] = true
+	}
+	return keys
+}
+
+// spanSymbols returns the symbols extracted from a file of a language
+// parsed into spans, by name.
+func spanSymbols(t *testing.T, filePath, content string) map[string]Symbol {
+	t.Helper()
+	symbols, err := NewRegexExtractor().ExtractSymbols(context.Background(), filePath, content)
+	if err != nil {
+		t.Fatalf("ExtractSymbols failed: %v", err)
+	}
+	byName := make(map[string]Symbol, len(symbols))
+	for _, s := range symbols {
+		byName[s.Name] = s
+	}
+	return byName
+}
@@ -0,0 +1,294 @@
+package trace
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sqlIdent matches a bare, "double-quoted", `backquoted` or [bracketed] SQL
+// identifier; sqlName one qualified by up to two schema or database names.
+const (
+	sqlIdent = "(?:\"[^\"\\n]+\"|`[^`\\n]+`|\\[[^\\]\\n]+\\]|[A-Za-z_][\\w$]*)"
+	sqlName  = "(" + sqlIdent + "(?:\\s*\\.\\s*" + sqlIdent + "){0,2})"
+)
+
+var (
+	sqlIdentRe  = regexp.MustCompile(sqlIdent)
+	sqlCreateRe = regexp.MustCompile(`(?i)\bCREATE\s+(?:OR\s+(?:REPLACE|ALTER)\s+)?` +
+		`(?:(?:TEMP|TEMPORARY|UNLOGGED|GLOBAL|LOCAL|MATERIALIZED|RECURSIVE|CONSTRAINT|DEFINER\s*=\s*\S+|ALGORITHM\s*=\s*\w+|SQL\s+SECURITY\s+\w+)\s+)*` +
+		`(TABLE|VIEW|TYPE|FUNCTION|PROCEDURE|PROC|TRIGGER)\s+(?:IF\s+NOT\s+EXISTS\s+)?` + sqlName)
+	sqlReadRe       = regexp.MustCompile(`(?i)\b(?:FROM|JOIN)\s+(?:ONLY\s+)?` + sqlName)
+	sqlWriteRe      = regexp.MustCompile(`(?i)\b(?:(?:INSERT|REPLACE)\s+(?:IGNORE\s+)?INTO|MERGE\s+INTO|UPDATE|DELETE\s+FROM|TRUNCATE(?:\s+TABLE)?)\s+(?:ONLY\s+)?` + sqlName)
+	sqlForeignKeyRe = regexp.MustCompile(`(?i)\bREFERENCES\s+` + sqlName)
+	sqlInvokeRe     = regexp.MustCompile(`(?i)\b(?:CALL|EXEC|EXECUTE(?:\s+(?:FUNCTION|PROCEDURE))?|PERFORM)\s+` + sqlName)
+	sqlCallRe       = regexp.MustCompile(sqlName + `\s*\(`)
+)
+
+// sqlKinds are the symbol kinds of the objects CREATE statements declare.
+var sqlKinds = map[string]SymbolKind{
+	"TABLE":     KindType,
+	"VIEW":      KindType,
+	"TYPE":      KindType,
+	"FUNCTION":  KindFunction,
+	"PROCEDURE": KindFunction,
+	"PROC":      KindFunction,
+	"TRIGGER":   KindFunction,
+}
+
+// sqlReserved are keywords, built-in functions and type names that a
+// regex would otherwise take for table names or routine calls. Names are
+// compared uppercase.
+var sqlReserved = toSet(
+	"SELECT", "FROM", "WHERE", "JOIN", "ON", "USING", "AS", "AND", "OR", "NOT", "IN", "IS",
+	"EXISTS", "BETWEEN", "LIKE", "ILIKE", "ANY", "ALL", "SOME", "CASE", "WHEN", "THEN", "ELSE",
+	"END", "IF", "BEGIN", "DECLARE", "RETURN", "RETURNS", "RETURNING", "VALUES", "VALUE", "SET",
+	"INTO", "TABLE", "VIEW", "FUNCTION", "PROCEDURE", "TRIGGER", "INDEX", "KEY", "PRIMARY",
+	"FOREIGN", "REFERENCES", "UNIQUE", "CHECK", "CONSTRAINT", "DEFAULT", "NULL", "OVER",
+	"PARTITION", "FILTER", "WITHIN", "GROUP", "ORDER", "BY", "HAVING", "LIMIT", "OFFSET",
+	"UNION", "EXCEPT", "INTERSECT", "LATERAL", "WITH", "RECURSIVE", "LOOP", "WHILE", "FOR",
+	"FOREACH", "EXECUTE", "EXEC", "CALL", "PERFORM", "RAISE", "ROW", "ROWS", "ARRAY", "CAST",
+	"CONVERT", "EXTRACT", "LANGUAGE", "DO", "NOTHING", "CONFLICT", "DUAL", "UNNEST",
+	"GENERATE_SERIES", "INSERT", "UPDATE", "DELETE", "MERGE", "TRUNCATE", "CREATE", "ALTER",
+	"DROP", "GRANT", "REVOKE", "TO", "OUT", "INOUT", "VARIADIC", "SETOF", "TRUE", "FALSE",
+	"COUNT", "SUM", "AVG", "MIN", "MAX", "COALESCE", "NULLIF", "GREATEST", "LEAST", "ABS",
+	"ROUND", "FLOOR", "CEIL", "CEILING", "LENGTH", "LOWER", "UPPER", "TRIM", "LTRIM", "RTRIM",
+	"SUBSTRING", "SUBSTR", "REPLACE", "CONCAT", "CONCAT_WS", "FORMAT", "POSITION", "NOW",
+	"DATE_TRUNC", "DATE_PART", "TO_CHAR", "TO_DATE", "TO_TIMESTAMP", "AGE", "STRING_AGG",
+	"ARRAY_AGG", "JSON_AGG", "JSONB_AGG", "JSON_BUILD_OBJECT", "JSONB_BUILD_OBJECT",
+	"ROW_NUMBER", "RANK", "DENSE_RANK", "LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE", "IFNULL",
+	"ISNULL", "NVL", "IIF", "GETDATE", "DATEADD", "DATEDIFF", "CURRENT_DATE",
+	"CURRENT_TIMESTAMP", "INT", "INTEGER", "SMALLINT", "BIGINT", "DECIMAL", "NUMERIC",
+	"FLOAT", "REAL", "DOUBLE", "CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "CHARACTER", "VARYING",
+	"TEXT", "BINARY", "VARBINARY", "BIT", "TIME", "TIMESTAMP", "TIMESTAMPTZ", "INTERVAL",
+	"DATE", "DATETIME", "DATETIME2", "BOOLEAN", "BOOL", "UUID", "JSON", "JSONB", "SERIAL",
+	"BIGSERIAL", "ENUM",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// sqlNoCallBefore are the words after which a name followed by a
+// parenthesis is a table or declaration, not a routine call.
+var sqlNoCallBefore = toSet(
+	"TABLE", "INTO", "VIEW", "FUNCTION", "PROCEDURE", "PROC", "TRIGGER", "TYPE", "INDEX",
+	"ON", "REFERENCES", "EXISTS", "KEY", "CALL", "EXEC", "EXECUTE", "PERFORM", "AS", "USING",
+)
+
+// sqlKindOf returns the kind of object a CREATE statement declares, such as
+// "TABLE" or "TRIGGER", uppercased, or "" for other statements.
+func sqlKindOf(statement string) string {
+	m := sqlCreateRe.FindStringSubmatch(statement)
+	if m == nil {
+		return ""
+	}
+	return strings.ToUpper(m[1])
+}
+
+// sqlUnqualify returns the last part of a qualified SQL name, unquoted, and
+// its schema, if any.
+func sqlUnqualify(qualified string) (name, schema string) {
+	parts := sqlIdentRe.FindAllString(qualified, -1)
+	for i, p := range parts {
+		if len(p) >= 2 && strings.ContainsRune("\"`[", rune(p[0])) {
+			parts[i] = p[1 : len(p)-1]
+		}
+	}
+	if len(parts) == 0 {
+		return "", ""
+	}
+	name = parts[len(parts)-1]
+	if len(parts) > 1 {
+		schema = parts[len(parts)-2]
+	}
+	return name, schema
+}
+
+// sqlDecls returns the tables, views, types, functions, procedures and
+// triggers CREATE statements declare. Each runs to the end of its
+// statement.
+func sqlDecls(content string) []spanDecl {
+	mask := sqlCommentMask(content)
+	var decls []spanDecl
+	for _, m := range sqlCreateRe.FindAllStringSubmatchIndex(content, -1) {
+		if mask[m[0]] {
+			continue
+		}
+		name, schema := sqlUnqualify(content[m[4]:m[5]])
+		if name == "" {
+			continue
+		}
+		decls = append(decls, spanDecl{
+			name:  name,
+			pkg:   schema,
+			kind:  sqlKinds[strings.ToUpper(content[m[2]:m[3]])],
+			start: m[0],
+			end:   sqlStatementEnd(content, m[1], mask),
+		})
+	}
+	return decls
+}
+
+// sqlReferences records table accesses as reads and writes, foreign keys
+// as type usages and routine invocations as calls.
+func sqlReferences(content string, add func(name string, pos int, kind string)) {
+	mask := sqlCommentMask(content)
+	seen := make(map[int]bool)
+	apply := func(re *regexp.Regexp, kind string, keep func(m []int) bool) {
+		for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+			start := m[2]
+			if mask[m[0]] || seen[start] || keep != nil && !keep(m) {
+				continue
+			}
+			name, _ := sqlUnqualify(content[m[2]:m[3]])
+			if name == "" || sqlReserved[strings.ToUpper(name)] {
+				continue
+			}
+			seen[start] = true
+			add(name, start, kind)
+		}
+	}
+
+	apply(sqlWriteRe, RefKindWrite, func(m []int) bool {
+		// ON UPDATE CASCADE, FOR UPDATE and BEFORE UPDATE are not writes.
+		if strings.EqualFold(content[m[0]:m[0]+6], "UPDATE") {
+			switch sqlPrevWord(content, m[0]) {
+			case "ON", "FOR", "OR", "BEFORE", "AFTER", "OF", "DO":
+				return false
+			}
+		}
+		return true
+	})
+	// FROM generate_series(...) reads from a function, not a table.
+	apply(sqlReadRe, RefKindRead, func(m []int) bool {
+		return !followedBy(content, m[3], '(')
+	})
+	apply(sqlForeignKeyRe, RefKindType, nil)
+	apply(sqlInvokeRe, RefKindCall, nil)
+	apply(sqlCallRe, RefKindCall, func(m []int) bool {
+		return !sqlNoCallBefore[sqlPrevWord(content, m[2])]
+	})
+}
+
+// sqlPrevWord returns the word before pos, uppercased.
+func sqlPrevWord(content string, pos int) string {
+	end := pos
+	for end > 0 && (content[end-1] == ' ' || content[end-1] == '\t' || content[end-1] == '\n' || content[end-1] == '\r') {
+		end--
+	}
+	start := end
+	for start > 0 && isIdentPartASCII(content[start-1]) {
+		start--
+	}
+	return strings.ToUpper(content[start:end])
+}
+
+// sqlCommentMask marks the bytes of -- and /* */ comments and of
+// single-quoted string literals. Dollar-quoted function bodies are left
+// unmasked so the references inside them count.
+func sqlCommentMask(content string) []bool {
+	mask := make([]bool, len(content))
+	for i := 0; i < len(content); i++ {
+		switch {
+		case content[i] == '-' && i+1 < len(content) && content[i+1] == '-':
+			for ; i < len(content) && content[i] != '\n'; i++ {
+				mask[i] = true
+			}
+		case content[i] == '/' && i+1 < len(content) && content[i+1] == '*':
+			stop := len(content)
+			if end := strings.Index(content[i+2:], "*/"); end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				mask[i] = true
+			}
+			i--
+		case content[i] == '\'':
+			mask[i] = true
+			for i++; i < len(content); i++ {
+				mask[i] = true
+				if content[i] == '\'' {
+					if i+1 < len(content) && content[i+1] == '\'' {
+						i++
+						mask[i] = true
+						continue
+					}
+					break
+				}
+			}
+		}
+	}
+	return mask
+}
+
+var (
+	sqlDollarQuoteRe = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+	sqlGoLineRe      = regexp.MustCompile(`(?im)^[ \t]*GO[ \t]*$`)
+)
+
+// sqlStatementEnd returns the offset past the end of the statement running
+// through start: its top-level semicolon, or a T-SQL GO line. Semicolons in
+// dollar-quoted bodies and BEGIN ... END blocks do not end it.
+func sqlStatementEnd(content string, start int, mask []bool) int {
+	depth := 0
+	for i := start; i < len(content); i++ {
+		if mask[i] {
+			continue
+		}
+		c := content[i]
+		switch {
+		case c == '$':
+			if tag := sqlDollarQuoteRe.FindString(content[i:]); tag != "" {
+				end := strings.Index(content[i+len(tag):], tag)
+				if end < 0 {
+					return len(content)
+				}
+				i += len(tag) + end + len(tag) - 1
+			}
+		case c == ';' && depth <= 0:
+			return i + 1
+		case c == '\n' && depth <= 0:
+			if loc := sqlGoLineRe.FindStringIndex(content[i+1:]); loc != nil && loc[0] == 0 {
+				return i + 1
+			}
+		case isIdentPartASCII(c) && (i == 0 || !isIdentPartASCII(content[i-1])):
+			end := i
+			for end < len(content) && isIdentPartASCII(content[end]) {
+				end++
+			}
+			switch strings.ToUpper(content[i:end]) {
+			case "BEGIN":
+				next := strings.ToUpper(sqlNextWord(content, end))
+				if next != "TRANSACTION" && next != "WORK" && next != "TRAN" {
+					depth++
+				}
+			case "CASE":
+				depth++
+			case "END":
+				switch strings.ToUpper(sqlNextWord(content, end)) {
+				case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+				default:
+					depth--
+				}
+			}
+			i = end - 1
+		}
+	}
+	return len(content)
+}
+
+// sqlNextWord returns the word after pos.
+func sqlNextWord(content string, pos int) string {
+	for pos < len(content) && (content[pos] == ' ' || content[pos] == '\t' || content[pos] == '\n' || content[pos] == '\r') {
+		pos++
+	}
+	end := pos
+	for end < len(content) && isIdentPartASCII(content[end]) {
+		end++
+	}
+	return content[pos:end]
+}
@@ -0,0 +1,105 @@
+package trace
+
+import "testing"
+
+func TestRegexExtractor_SQL(t *testing.T) {
+	content := `-- Schema for orders.
+CREATE TABLE IF NOT EXISTS public.users (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL UNIQUE
+);
+
+CREATE TABLE orders (
+    id BIGINT PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON UPDATE CASCADE,
+    total NUMERIC(10, 2)
+);
+
+CREATE OR REPLACE VIEW "active_users" AS
+SELECT u.id, COUNT(o.id) FROM users u JOIN orders o ON o.user_id = u.id GROUP BY u.id;
+
+CREATE FUNCTION billing.close_order(order_id BIGINT) RETURNS void AS $$
+BEGIN
+    UPDATE orders SET total = compute_total(order_id) WHERE id = order_id;
+    INSERT INTO audit_log (message) VALUES ('closed; from users');
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER orders_audit BEFORE UPDATE ON orders
+FOR EACH ROW EXECUTE FUNCTION log_change();
+
+DELETE FROM sessions WHERE expires_at < NOW();
+CALL billing.close_order(42);
+`
+	symbols := spanSymbols(t, "db/schema.sql", content)
+	for name, kind := range map[string]SymbolKind{
+		"users":        KindType,
+		"orders":       KindType,
+		"active_users": KindType,
+		"close_order":  KindFunction,
+		"orders_audit": KindFunction,
+	} {
+		sym, ok := symbols[name]
+		if !ok {
+			t.Errorf("expected symbol %s, got %v", name, symbols)
+			continue
+		}
+		if sym.Kind != kind || sym.Language != "sql" {
+			t.Errorf("symbol %s: got kind %s language %s, want %s sql", name, sym.Kind, sym.Language, kind)
+		}
+	}
+	if users := symbols["users"]; users.Package != "public" || users.Line != 2 || users.EndLine != 5 {
+		t.Errorf("users: got package %q lines %d-%d, want public 2-5", users.Package, users.Line, users.EndLine)
+	}
+	if fn := symbols["close_order"]; fn.Package != "billing" || fn.Line != 16 || fn.EndLine != 21 {
+		t.Errorf("close_order: got package %q lines %d-%d, want billing 16-21", fn.Package, fn.Line, fn.EndLine)
+	}
+
+	refs := spanRefKeys(t, "db/schema.sql", content)
+	for _, want := range []refKey{
+		{"users", RefKindType, "orders"},
+		{"users", RefKindRead, "active_users"},
+		{"orders", RefKindRead, "active_users"},
+		{"orders", RefKindWrite, "close_order"},
+		{"compute_total", RefKindCall, "close_order"},
+		{"audit_log", RefKindWrite, "close_order"},
+		{"log_change", RefKindCall, "orders_audit"},
+		{"sessions", RefKindWrite, "<top-level>"},
+		{"close_order", RefKindCall, "<top-level>"},
+	} {
+		if !refs[want] {
+			t.Errorf("missing reference %+v in %v", want, refs)
+		}
+	}
+	for ref := range refs {
+		switch {
+		case ref.name == "sessions" && ref.kind == RefKindRead,
+			ref.name == "orders" && ref.kind == RefKindWrite && ref.caller != "close_order",
+			ref.name == "users" && ref.caller == "close_order",
+			ref.name == "COUNT", ref.name == "VARCHAR", ref.name == "NOW", ref.name == "audit_log" && ref.kind == RefKindCall:
+			t.Errorf("unexpected reference %+v", ref)
+		}
+	}
+}
+
+func TestSQLStatementEnd_TSQL(t *testing.T) {
+	content := "CREATE PROCEDURE dbo.archive AS\nBEGIN\n  DELETE FROM orders;\n  IF @@ROWCOUNT > 0 BEGIN PRINT 'x'; END\nEND\nGO\nSELECT 1;\n"
+	decls := sqlDecls(content)
+	if len(decls) != 1 || decls[0].name != "archive" || decls[0].pkg != "dbo" {
+		t.Fatalf("expected procedure archive in dbo, got %+v", decls)
+	}
+	if got := content[decls[0].start:decls[0].end]; got[len(got)-4:] != "END\n" {
+		t.Errorf("procedure should end before GO, got %q", got)
+	}
+}
+
+func TestIsEntryPoint_SQLTriggers(t *testing.T) {
+	trigger := Symbol{Name: "orders_audit", Kind: KindFunction, Language: "sql", Signature: "CREATE TRIGGER orders_audit BEFORE UPDATE ON orders"}
+	if !IsEntryPoint(trigger) {
+		t.Error("SQL triggers should be entry points")
+	}
+	fn := Symbol{Name: "close_order", Kind: KindFunction, Language: "sql", Signature: "CREATE FUNCTION close_order(id BIGINT) RETURNS void AS $$"}
+	if IsEntryPoint(fn) {
+		t.Error("SQL functions should not be entry points")
+	}
+}
@@ -34,6 +34,11 @@ func TestRegexExtractor_SupportedLanguages(t *testing.T) {
 		".fs":   true,
 		".fsx":  true,
 		".fsi":  true,
+
+		".mk":         true,
+		".dockerfile": true,
+		".tf":         true,
+		".sql":        true,
 	}
 
 	for _, lang := range langs {
@@ -48,6 +53,19 @@ func TestRegexExtractor_SupportedLanguages(t *testing.T) {
 	}
 }
 
+func TestRegexExtractor_ExtractSymbols_ExtensionlessScript(t *testing.T) {
+	extractor := NewRegexExtractor()
+	content := "#!/usr/bin/env python3\n\ndef deploy(env):\n    pass\n"
+
+	symbols, err := extractor.ExtractSymbols(context.Background(), "bin/deploy", content)
+	if err != nil {
+		t.Fatalf("ExtractSymbols failed: %v", err)
+	}
+	if len(symbols) != 1 || symbols[0].Name != "deploy" || symbols[0].Language != "python" {
+		t.Errorf("expected python function deploy, got %+v", symbols)
+	}
+}
+
 func TestRegexExtractor_ExtractSymbols_C(t *testing.T) {
 	extractor := NewRegexExtractor()
 	ctx := context.Background()
@@ -1469,3 +1487,37 @@ func TestRegexExtractor_ExtractReferences_FiltersBuiltinAndVueInternalNoise(t *t
 		t.Fatalf("expected builtins/vue internals filtered, got max=%v log=%v keys=%v $refs=%v", hasMax, hasLog, hasKeys, hasRefs)
 	}
 }
+
+func TestRegexExtractor_ExtractSymbols_GoInterfaceMethods(t *testing.T) {
+	extractor := NewRegexExtractor()
+	content := `package store
+
+type Store interface {
+	// Load reads the index.
+	Load(ctx context.Context) error
+	Close() error
+}
+
+type Empty interface{}
+`
+	symbols, err := extractor.ExtractSymbols(context.Background(), "store.go", content)
+	if err != nil {
+		t.Fatalf("ExtractSymbols failed: %v", err)
+	}
+
+	var methods []Symbol
+	for _, sym := range symbols {
+		if sym.Abstract {
+			methods = append(methods, sym)
+		}
+	}
+	if len(methods) != 2 {
+		t.Fatalf("expected 2 interface methods, got %+v", methods)
+	}
+	if methods[0].Name != "Load" || methods[0].Receiver != "Store" || methods[0].Kind != KindMethod || methods[0].Line != 5 {
+		t.Errorf("unexpected first interface method: %+v", methods[0])
+	}
+	if methods[1].Name != "Close" || methods[1].Line != 6 {
+		t.Errorf("unexpected second interface method: %+v", methods[1])
+	}
+}
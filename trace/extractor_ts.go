@@ -5,7 +5,6 @@ package trace
 import (
 	"context"
 	"fmt"
-	"path/filepath"
 	"strings"
 
 	sitter "github.com/smacker/go-tree-sitter"
@@ -16,6 +15,7 @@ import (
 	"github.com/smacker/go-tree-sitter/python"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
 	"github.com/yoanbernabeu/grepai/fsharp"
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
 )
 
 // TreeSitterExtractor implements SymbolExtractor using tree-sitter AST parsing.
@@ -68,7 +68,7 @@ func (e *TreeSitterExtractor) SupportedLanguages() []string {
 
 // ExtractSymbols extracts all symbol definitions from a file using tree-sitter.
 func (e *TreeSitterExtractor) ExtractSymbols(ctx context.Context, filePath string, content string) ([]Symbol, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
+	ext := langdetect.Ext(filePath, content)
 	parser, ok := e.parsers[ext]
 	if !ok {
 		return nil, nil
@@ -142,7 +142,12 @@ func (e *TreeSitterExtractor) extractGoSymbol(node *sitter.Node, nodeType string
 			if receiverNode != nil {
 				for i := 0; i < int(receiverNode.ChildCount()); i++ {
 					child := receiverNode.Child(i)
-					if child.Type() == "type_identifier" || child.Type() == "pointer_type" {
+					if child.Type() == "parameter_declaration" {
+						if typeNode := child.ChildByFieldName("type"); typeNode != nil {
+							child = typeNode
+						}
+					}
+					if child.Type() == "type_identifier" || child.Type() == "pointer_type" || child.Type() == "generic_type" {
 						receiver = child.Content(content)
 						break
 					}
@@ -215,12 +220,46 @@ func (e *TreeSitterExtractor) extractGoSymbol(node *sitter.Node, nodeType string
 						Language:  "go",
 						Docstring: finalDoc,
 					})
+
+					if kind == KindInterface {
+						*symbols = append(*symbols, goInterfaceMethodSymbols(typeNode, name, content, filePath)...)
+					}
 				}
 			}
 		}
 	}
 }
 
+// goInterfaceMethodSymbols returns the methods declared by an interface type
+// as abstract method symbols whose receiver is the interface name.
+func goInterfaceMethodSymbols(ifaceNode *sitter.Node, iface string, content []byte, filePath string) []Symbol {
+	var symbols []Symbol
+	for i := 0; i < int(ifaceNode.NamedChildCount()); i++ {
+		elem := ifaceNode.NamedChild(i)
+		if elem.Type() != "method_spec" && elem.Type() != "method_elem" {
+			continue
+		}
+		nameNode := elem.ChildByFieldName("name")
+		if nameNode == nil {
+			continue
+		}
+		name := nameNode.Content(content)
+		symbols = append(symbols, Symbol{
+			Name:      name,
+			Kind:      KindMethod,
+			File:      filePath,
+			Line:      int(elem.StartPoint().Row) + 1,
+			Signature: truncateSignature(elem.Content(content)),
+			Receiver:  iface,
+			Exported:  isExported(name, "go"),
+			Language:  "go",
+			Docstring: extractDocstring(elem, content),
+			Abstract:  true,
+		})
+	}
+	return symbols
+}
+
 // extractDocstring looks for comment nodes immediately preceding the given node.
 func extractDocstring(node *sitter.Node, content []byte) string {
 	var comments []string
@@ -634,7 +673,7 @@ func hasOnlyAbstractMembers(node *sitter.Node) bool {
 
 // ExtractReferences extracts all symbol references from a file.
 func (e *TreeSitterExtractor) ExtractReferences(ctx context.Context, filePath string, content string) ([]Reference, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
+	ext := langdetect.Ext(filePath, content)
 	parser, ok := e.parsers[ext]
 	if !ok {
 		return nil, nil
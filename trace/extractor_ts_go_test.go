@@ -0,0 +1,49 @@
+//go:build treesitter
+
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTreeSitterExtractor_ExtractSymbols_GoInterfaceMethods(t *testing.T) {
+	extractor, err := NewTreeSitterExtractor()
+	if err != nil {
+		t.Fatalf("NewTreeSitterExtractor failed: %v", err)
+	}
+
+	content := `package store
+
+type Store interface {
+	Load(ctx context.Context) error
+	Close() error
+}
+
+type GOBStore struct{}
+
+func (s *GOBStore) Load(ctx context.Context) error { return nil }
+`
+	symbols, err := extractor.ExtractSymbols(context.Background(), "store.go", content)
+	if err != nil {
+		t.Fatalf("ExtractSymbols failed: %v", err)
+	}
+
+	var abstract, concrete []Symbol
+	for _, sym := range symbols {
+		if sym.Kind != KindMethod {
+			continue
+		}
+		if sym.Abstract {
+			abstract = append(abstract, sym)
+		} else {
+			concrete = append(concrete, sym)
+		}
+	}
+	if len(abstract) != 2 || abstract[0].Name != "Load" || abstract[0].Receiver != "Store" || abstract[0].Line != 4 {
+		t.Fatalf("unexpected interface methods: %+v", abstract)
+	}
+	if len(concrete) != 1 || concrete[0].Receiver != "*GOBStore" {
+		t.Fatalf("unexpected concrete methods: %+v", concrete)
+	}
+}
@@ -0,0 +1,415 @@
+package trace
+
+import (
+	"regexp"
+	"strings"
+)
+
+// usageRule records usage references of kind for the name captured by the
+// last group of re, or for every name in the comma-separated list it
+// captures when used as a list rule.
+type usageRule struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+var (
+	goInstantiateRe = regexp.MustCompile(`(==|!=|<=|>=|&&|:=|[=(,:{&\[]|\breturn)\s*(?:\[\])?(?:[A-Za-z_]\w*\.)?([A-Za-z_]\w*)\s*\{`)
+	goTypePrefixRe  = regexp.MustCompile(`(?:\*|\[\]|\]|\.\(|\bchan[ \t]+|\bmap\[|\)[ \t]+)(?:[A-Za-z_]\w*\.)?([A-Za-z_]\w*)`)
+	goTypeFieldRe   = regexp.MustCompile(`\b([A-Za-z_]\w*)[ \t]+(?:\.\.\.)?(?:[A-Za-z_]\w*\.)?([A-Z]\w*)\b`)
+	goImportRe      = regexp.MustCompile(`(?m)^\s*import\s*(\(|(?:[A-Za-z_.]\w*\s+)?"[^"]*")`)
+	goImportSpecRe  = regexp.MustCompile(`^\s*(?:([A-Za-z_.]\w*)\s+)?"([^"]*)"`)
+)
+
+var goUsageStopWords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true, "nil": true, "true": true, "false": true, "iota": true,
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+var jsUsageRules = []usageRule{
+	{RefKindInstantiate, regexp.MustCompile(`\bnew\s+(?:[A-Za-z_$][\w$]*\.)*([A-Za-z_$][\w$]*)`)},
+	{RefKindType, regexp.MustCompile(`\b(?:extends|instanceof)\s+(?:[A-Za-z_$][\w$]*\.)*([A-Za-z_$][\w$]*)`)},
+}
+
+var tsUsageRules = append([]usageRule{
+	{RefKindType, regexp.MustCompile(`(?:[:<|&]|\bas|\bkeyof|\bsatisfies)\s*(?:[A-Za-z_$][\w$]*\.)*([A-Z][\w$]*)`)},
+}, jsUsageRules...)
+
+var tsUsageListRules = []usageRule{
+	{RefKindType, regexp.MustCompile(`\bimplements\s+([\w$.,<>\s]+?)\s*\{`)},
+}
+
+var (
+	jsImportRe  = regexp.MustCompile(`\bimport\s+(?:type\s+)?([\w$*{},\s]+?)\s+from\s+['"]`)
+	jsRequireRe = regexp.MustCompile(`\b(?:const|let|var)\s+(\{[^}]*\}|[A-Za-z_$][\w$]*)\s*=\s*require\s*\(`)
+)
+
+var pythonUsageRules = []usageRule{
+	{RefKindType, regexp.MustCompile(`(?::|->)[ \t]*(?:[A-Za-z_]\w*\.)*([A-Z]\w*)`)},
+	{RefKindType, regexp.MustCompile(`\b(?:Optional|List|Dict|Set|Tuple|Type|Sequence|Iterable|Union|list|dict|set|tuple|type)\[\s*(?:[A-Za-z_]\w*\.)*([A-Z]\w*)`)},
+}
+
+var pythonUsageListRules = []usageRule{
+	{RefKindType, regexp.MustCompile(`(?m)^[ \t]*class\s+\w+\s*\(([^)]*)\)`)},
+	{RefKindType, regexp.MustCompile(`\b(?:isinstance|issubclass)\s*\([^,()]+,\s*\(?([\w.,\s]+)\)?\s*\)`)},
+}
+
+var (
+	pythonFromImportRe = regexp.MustCompile(`(?m)^[ \t]*from\s+[\w.]+\s+import\s+(\([^)]*\)|[^\n#]+)`)
+	pythonImportRe     = regexp.MustCompile(`(?m)^[ \t]*import\s+([^\n#]+)`)
+)
+
+var javaUsageRules = []usageRule{
+	{RefKindInstantiate, regexp.MustCompile(`\bnew\s+(?:[A-Za-z_]\w*\.)*([A-Za-z_]\w*)`)},
+	{RefKindType, regexp.MustCompile(`\b(?:extends|throws|instanceof)\s+(?:[A-Za-z_]\w*\.)*([A-Z]\w*)`)},
+	{RefKindType, regexp.MustCompile(`\b([A-Z]\w*)(?:<[^<>;()]*>)?(?:\[\])*[ \t]+[a-z_]\w*\s*[=;,):]`)},
+	{RefKindType, regexp.MustCompile(`<\s*(?:[A-Za-z_]\w*\.)*([A-Z]\w*)`)},
+	{RefKindType, regexp.MustCompile(`\(\s*([A-Z]\w*)\s*\)\s*[\w(]`)},
+}
+
+var javaUsageListRules = []usageRule{
+	{RefKindType, regexp.MustCompile(`\bimplements\s+([\w.,<>\s]+?)\s*\{`)},
+}
+
+var csharpUsageListRules = []usageRule{
+	{RefKindType, regexp.MustCompile(`\b(?:class|struct|interface|record)\s+\w+(?:<[^>]*>)?\s*:\s*([\w.,<>\s]+?)\s*(?:\{|\bwhere\b)`)},
+}
+
+var phpUsageRules = []usageRule{
+	{RefKindInstantiate, regexp.MustCompile(`\bnew\s+\\?(?:[A-Za-z_]\w*\\)*([A-Za-z_]\w*)`)},
+	{RefKindType, regexp.MustCompile(`\b(?:extends|instanceof)\s+\\?(?:[A-Za-z_]\w*\\)*([A-Z]\w*)`)},
+	{RefKindType, regexp.MustCompile(`[(,]\s*\??\\?(?:[A-Za-z_]\w*\\)*([A-Z]\w*)\s+&?\.{0,3}\$`)},
+	{RefKindType, regexp.MustCompile(`\)\s*:\s*\??\\?(?:[A-Za-z_]\w*\\)*([A-Z]\w*)`)},
+	{RefKindType, regexp.MustCompile(`\b([A-Z]\w*)::`)},
+}
+
+var phpUsageListRules = []usageRule{
+	{RefKindType, regexp.MustCompile(`\bimplements\s+([\w\\,\s]+?)\s*\{`)},
+}
+
+var (
+	javaImportRe   = regexp.MustCompile(`(?m)^[ \t]*import\s+(?:static\s+)?([\w.]+)\s*;`)
+	csharpImportRe = regexp.MustCompile(`(?m)^[ \t]*(?:global\s+)?using\s+(?:static\s+)?(?:\w+\s*=\s*)?([\w.]+)\s*;`)
+	phpImportRe    = regexp.MustCompile(`(?m)^[ \t]*use\s+(?:function\s+|const\s+)?\\?([\w\\]+)(?:\s+as\s+\w+)?\s*;`)
+)
+
+var usageStopWords = map[string]map[string]bool{
+	"go": goUsageStopWords,
+	"javascript": {
+		"function": true, "class": true, "this": true, "super": true, "true": true, "false": true,
+		"null": true, "undefined": true,
+	},
+	"typescript": {
+		"function": true, "class": true, "this": true, "super": true, "true": true, "false": true,
+		"null": true, "undefined": true, "const": true,
+	},
+	"python": {"None": true, "True": true, "False": true},
+	"java":   {"Override": true, "Deprecated": true, "SuppressWarnings": true},
+	"php":    {"self": true, "static": true, "parent": true},
+}
+
+// extractUsageReferences finds references that are not calls or data
+// accesses: type usages, instantiations (struct literals, new expressions)
+// and imports. Like the rest of the regex extractor it trades precision for
+// speed; the symbol name recorded for an import is the imported name, or
+// the package name for whole-package imports.
+func extractUsageReferences(filePath, content string, lines []string, lang string, ignored []bool, functionBoundaries []functionBoundary) []Reference {
+	var refs []Reference
+	stop := usageStopWords[lang]
+	add := func(name string, pos int, kind string) {
+		name = strings.TrimSpace(name)
+		if name == "" || stop[name] || IsKeyword(name, lang) {
+			return
+		}
+		refs = append(refs, buildDataReference(filePath, content, lines, name, pos, kind, functionBoundaries))
+	}
+	apply := func(rules []usageRule) {
+		for _, rule := range rules {
+			for _, m := range rule.re.FindAllStringSubmatchIndex(content, -1) {
+				start, end := m[len(m)-2], m[len(m)-1]
+				if start < 0 || ignored[start] {
+					continue
+				}
+				add(content[start:end], start, rule.kind)
+			}
+		}
+	}
+	applyLists := func(rules []usageRule, separator string) {
+		for _, rule := range rules {
+			for _, m := range rule.re.FindAllStringSubmatchIndex(content, -1) {
+				start, end := m[len(m)-2], m[len(m)-1]
+				if start < 0 || ignored[m[0]] {
+					continue
+				}
+				for _, name := range splitUsageList(content[start:end], separator) {
+					add(name, start, rule.kind)
+				}
+			}
+		}
+	}
+
+	switch lang {
+	case "go":
+		extractGoUsageReferences(content, ignored, add)
+	case "javascript":
+		apply(jsUsageRules)
+		extractJSImports(content, ignored, add)
+	case "typescript":
+		apply(tsUsageRules)
+		applyLists(tsUsageListRules, ".")
+		extractJSImports(content, ignored, add)
+	case "python":
+		apply(pythonUsageRules)
+		applyLists(pythonUsageListRules, ".")
+		extractPythonImports(content, ignored, add)
+	case "java":
+		apply(javaUsageRules)
+		applyLists(javaUsageListRules, ".")
+		extractQualifiedImports(javaImportRe, ".", content, ignored, add)
+	case "csharp":
+		apply(javaUsageRules)
+		applyLists(csharpUsageListRules, ".")
+		extractQualifiedImports(csharpImportRe, ".", content, ignored, add)
+	case "php":
+		apply(phpUsageRules)
+		applyLists(phpUsageListRules, `\`)
+		extractQualifiedImports(phpImportRe, `\`, content, ignored, add)
+	}
+	return refs
+}
+
+// extractGoUsageReferences records Go composite literals, type usages and
+// imports through add.
+func extractGoUsageReferences(content string, ignored []bool, add func(name string, pos int, kind string)) {
+	for _, m := range goInstantiateRe.FindAllStringSubmatchIndex(content, -1) {
+		op := content[m[2]:m[3]]
+		// Comparisons and boolean operators precede blocks, not literals.
+		if op == "==" || op == "!=" || op == "<=" || op == ">=" || op == "&&" {
+			continue
+		}
+		if op == "&" && m[2] > 0 && content[m[2]-1] == '&' {
+			continue
+		}
+		if ignored[m[4]] {
+			continue
+		}
+		add(content[m[4]:m[5]], m[4], RefKindInstantiate)
+	}
+
+	for _, m := range goTypePrefixRe.FindAllStringSubmatchIndex(content, -1) {
+		if ignored[m[2]] || followedBy(content, m[3], '(') {
+			continue
+		}
+		if content[m[0]] == '*' && !goPointerTypeContext(content, m[0]) {
+			continue
+		}
+		add(content[m[2]:m[3]], m[2], RefKindType)
+	}
+	for _, m := range goTypeFieldRe.FindAllStringSubmatchIndex(content, -1) {
+		if ignored[m[4]] || goUsageStopWords[content[m[2]:m[3]]] || followedBy(content, m[5], '(') {
+			continue
+		}
+		add(content[m[4]:m[5]], m[4], RefKindType)
+	}
+
+	for _, m := range goImportRe.FindAllStringSubmatchIndex(content, -1) {
+		if ignored[m[0]] {
+			continue
+		}
+		if content[m[2]:m[3]] != "(" {
+			addGoImport(content[m[2]:m[3]], m[2], add)
+			continue
+		}
+		end := strings.IndexByte(content[m[3]:], ')')
+		if end < 0 {
+			continue
+		}
+		pos := m[3]
+		for _, line := range strings.SplitAfter(content[m[3]:m[3]+end], "\n") {
+			addGoImport(line, pos, add)
+			pos += len(line)
+		}
+	}
+}
+
+// goPointerTypeContext reports whether the * at pos starts a pointer type,
+// as in `s *Server`, `[]*Item` or `func() (*T, error)`, rather than a
+// dereference.
+func goPointerTypeContext(content string, pos int) bool {
+	end := pos
+	for end > 0 && (content[end-1] == ' ' || content[end-1] == '\t') {
+		end--
+	}
+	if end == 0 {
+		return false
+	}
+	if c := content[end-1]; c == ']' || c == ')' {
+		return true
+	}
+	// A parenthesized result list: func f() (*T, error).
+	if content[end-1] == '(' {
+		return prevNonSpaceByte(content, end-1) == ')'
+	}
+	start := end
+	for start > 0 && isIdentPartASCII(content[start-1]) {
+		start--
+	}
+	return start < end && end < pos && !goUsageStopWords[content[start:end]]
+}
+
+func addGoImport(spec string, pos int, add func(name string, pos int, kind string)) {
+	m := goImportSpecRe.FindStringSubmatch(spec)
+	if m == nil {
+		return
+	}
+	name := m[1]
+	if name == "" {
+		name = m[2][strings.LastIndex(m[2], "/")+1:]
+	}
+	if name == "_" || name == "." {
+		return
+	}
+	add(name, pos, RefKindImport)
+}
+
+// extractJSImports records the names bound by ES module imports and
+// CommonJS require destructuring. Renamed imports record the original name.
+func extractJSImports(content string, ignored []bool, add func(name string, pos int, kind string)) {
+	for _, m := range jsImportRe.FindAllStringSubmatchIndex(content, -1) {
+		if ignored[m[0]] {
+			continue
+		}
+		for _, name := range jsBoundNames(content[m[2]:m[3]]) {
+			add(name, m[0], RefKindImport)
+		}
+	}
+	for _, m := range jsRequireRe.FindAllStringSubmatchIndex(content, -1) {
+		if ignored[m[0]] {
+			continue
+		}
+		for _, name := range jsBoundNames(content[m[2]:m[3]]) {
+			add(name, m[0], RefKindImport)
+		}
+	}
+}
+
+// jsBoundNames returns the imported names of an import clause such as
+// `Default, { a, b as c, type D }` or `* as ns`.
+func jsBoundNames(clause string) []string {
+	var names []string
+	braced := ""
+	if open := strings.IndexByte(clause, '{'); open >= 0 {
+		if end := strings.IndexByte(clause[open:], '}'); end >= 0 {
+			braced = clause[open+1 : open+end]
+			clause = clause[:open] + clause[open+end+1:]
+		}
+	}
+	for _, part := range strings.Split(braced, ",") {
+		fields := strings.Fields(part)
+		if len(fields) > 0 && fields[0] == "type" {
+			fields = fields[1:]
+		}
+		if len(fields) > 0 {
+			names = append(names, strings.TrimSuffix(fields[0], ":"))
+		}
+	}
+	for _, part := range strings.Split(clause, ",") {
+		fields := strings.Fields(part)
+		switch {
+		case len(fields) == 3 && fields[0] == "*" && fields[1] == "as":
+			names = append(names, fields[2])
+		case len(fields) == 1 && fields[0] != "*":
+			names = append(names, fields[0])
+		}
+	}
+	return names
+}
+
+// extractPythonImports records names imported with `from m import a, b`
+// and modules imported with `import a.b`.
+func extractPythonImports(content string, ignored []bool, add func(name string, pos int, kind string)) {
+	for _, m := range pythonFromImportRe.FindAllStringSubmatchIndex(content, -1) {
+		if ignored[m[2]] {
+			continue
+		}
+		list := strings.Trim(content[m[2]:m[3]], "()")
+		for _, item := range strings.Split(list, ",") {
+			if fields := strings.Fields(item); len(fields) > 0 && fields[0] != "*" {
+				add(fields[0], m[2], RefKindImport)
+			}
+		}
+	}
+	for _, m := range pythonImportRe.FindAllStringSubmatchIndex(content, -1) {
+		if ignored[m[2]] {
+			continue
+		}
+		for _, item := range strings.Split(content[m[2]:m[3]], ",") {
+			if fields := strings.Fields(item); len(fields) > 0 {
+				module := fields[0]
+				add(module[strings.LastIndex(module, ".")+1:], m[2], RefKindImport)
+			}
+		}
+	}
+}
+
+// extractQualifiedImports records the last segment of imports matched by
+// re, such as java.util.List or App\Models\User.
+func extractQualifiedImports(re *regexp.Regexp, separator, content string, ignored []bool, add func(name string, pos int, kind string)) {
+	for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+		if ignored[m[2]] {
+			continue
+		}
+		path := content[m[2]:m[3]]
+		add(path[strings.LastIndex(path, separator)+len(separator):], m[2], RefKindImport)
+	}
+}
+
+// splitUsageList splits a comma-separated list of type names, dropping
+// generic arguments, qualifiers and keyword arguments such as metaclass=.
+func splitUsageList(list, separator string) []string {
+	var names []string
+	depth := 0
+	var b strings.Builder
+	flush := func() {
+		name := strings.TrimSpace(b.String())
+		b.Reset()
+		if name == "" || strings.Contains(name, "=") {
+			return
+		}
+		names = append(names, name[strings.LastIndex(name, separator)+len(separator):])
+	}
+	for _, r := range list {
+		switch {
+		case r == '<' || r == '[':
+			depth++
+		case r == '>' || r == ']':
+			depth--
+		case r == ',' && depth == 0:
+			flush()
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	return names
+}
+
+// followedBy reports whether the first non-blank byte at or after pos is c.
+func followedBy(content string, pos int, c byte) bool {
+	for ; pos < len(content); pos++ {
+		switch content[pos] {
+		case ' ', '\t':
+			continue
+		default:
+			return content[pos] == c
+		}
+	}
+	return false
+}
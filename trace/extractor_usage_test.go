@@ -0,0 +1,206 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+func usageKinds(t *testing.T, file, content string) map[string]map[string]bool {
+	t.Helper()
+	refs, err := NewRegexExtractor().ExtractReferences(context.Background(), file, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]map[string]bool)
+	for _, ref := range refs {
+		if kinds[ref.Kind] == nil {
+			kinds[ref.Kind] = make(map[string]bool)
+		}
+		kinds[ref.Kind][ref.SymbolName] = true
+	}
+	return kinds
+}
+
+func TestExtractUsageReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string][]string
+		notWant map[string][]string
+	}{
+		{
+			name: "go",
+			file: "main.go",
+			content: `package main
+
+import (
+	"context"
+	cfgpkg "github.com/acme/app/config"
+	_ "embed"
+)
+
+import "strings"
+
+// Server is built with Options{} in comments only.
+type Server struct {
+	Store  store.VectorStore
+	opts   *Options
+	items  []Item
+	byName map[string]Item
+}
+
+func NewServer(ctx context.Context, o Options) (*Server, error) {
+	if a == b {
+		return nil, nil
+	}
+	s := &Server{opts: &o}
+	s.items = []Item{{Name: "x"}}
+	if v, ok := any(s).(Handler); ok && ready {
+		_ = v
+	}
+	return s, nil
+}
+`,
+			want: map[string][]string{
+				RefKindImport:      {"context", "cfgpkg", "strings"},
+				RefKindInstantiate: {"Server", "Item"},
+				RefKindType:        {"VectorStore", "Options", "Item", "Context", "Server", "Handler"},
+			},
+			notWant: map[string][]string{
+				RefKindImport:      {"_", "embed"},
+				RefKindInstantiate: {"b", "ready"},
+				RefKindType:        {"string", "error"},
+			},
+		},
+		{
+			name: "typescript",
+			file: "app.ts",
+			content: `import Default, { UserService, Repo as R, type Config } from './services';
+import * as utils from "./utils";
+const { readFile } = require('fs');
+
+class AdminService extends BaseService implements Auditable, Disposable {
+  private repo: Repository<User>;
+  run(cfg: Config): Result {
+    const svc = new UserService();
+    return svc.find() as Result;
+  }
+}
+`,
+			want: map[string][]string{
+				RefKindImport:      {"Default", "UserService", "Repo", "Config", "utils", "readFile"},
+				RefKindInstantiate: {"UserService"},
+				RefKindType:        {"BaseService", "Auditable", "Disposable", "Repository", "User", "Config", "Result"},
+			},
+			notWant: map[string][]string{
+				RefKindImport: {"R"},
+			},
+		},
+		{
+			name: "python",
+			file: "app.py",
+			content: `from app.models import User, Order as O
+import os.path, json
+
+class Admin(User, metaclass=ABCMeta):
+    def load(self, repo: Repository) -> Optional[Order]:
+        if isinstance(repo, (SqlRepo, MemoryRepo)):
+            return None
+`,
+			want: map[string][]string{
+				RefKindImport: {"User", "Order", "path", "json"},
+				RefKindType:   {"User", "Repository", "Order", "SqlRepo", "MemoryRepo"},
+			},
+			notWant: map[string][]string{
+				RefKindType: {"ABCMeta", "None"},
+			},
+		},
+		{
+			name: "java",
+			file: "App.java",
+			content: `import java.util.List;
+import static com.acme.Util.helper;
+
+public class App extends Base implements Runnable, Closeable {
+    private List<User> users = new ArrayList<>();
+    public Order place(Cart cart) throws PaymentException {
+        Order order = new Order(cart);
+        return (Order) order;
+    }
+}
+`,
+			want: map[string][]string{
+				RefKindImport:      {"List", "helper"},
+				RefKindInstantiate: {"ArrayList", "Order"},
+				RefKindType:        {"Base", "Runnable", "Closeable", "User", "Cart", "Order", "PaymentException"},
+			},
+		},
+		{
+			name: "php",
+			file: "app.php",
+			content: `<?php
+use App\Models\User;
+
+class UserController extends Controller implements HasMiddleware {
+    public function show(Request $request): Response {
+        $user = new User();
+        return UserResource::make($user);
+    }
+}
+`,
+			want: map[string][]string{
+				RefKindImport:      {"User"},
+				RefKindInstantiate: {"User"},
+				RefKindType:        {"Controller", "HasMiddleware", "Request", "Response", "UserResource"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kinds := usageKinds(t, tt.file, tt.content)
+			for kind, names := range tt.want {
+				for _, name := range names {
+					if !kinds[kind][name] {
+						t.Errorf("expected %s reference to %s, got %v", kind, name, kinds[kind])
+					}
+				}
+			}
+			for kind, names := range tt.notWant {
+				for _, name := range names {
+					if kinds[kind][name] {
+						t.Errorf("unexpected %s reference to %s", kind, name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSaveFile_UsageReferencesAddNoCallEdges(t *testing.T) {
+	ctx := context.Background()
+	ss := NewGOBSymbolStore(t.TempDir() + "/symbols.gob")
+	refs := []Reference{
+		{SymbolName: "Options", Kind: RefKindType, File: "a.go", Line: 3, CallerName: "NewServer"},
+		{SymbolName: "validate", Kind: RefKindCall, File: "a.go", Line: 4, CallerName: "NewServer"},
+	}
+	if err := ss.SaveFile(ctx, "a.go", nil, refs); err != nil {
+		t.Fatal(err)
+	}
+
+	callees, err := ss.LookupCallees(ctx, "NewServer", "a.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(callees) != 1 || callees[0].SymbolName != "validate" {
+		t.Fatalf("expected only the call to be a callee, got %+v", callees)
+	}
+	all, err := ss.LookupReferences(ctx, "Options")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Kind != RefKindType {
+		t.Fatalf("expected the type reference to be stored, got %+v", all)
+	}
+}
@@ -0,0 +1,345 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Literal categories recorded by extractLiteralReferences.
+const (
+	LiteralRoute = "route" // a URL route such as "/api/users/{id}"
+	LiteralSQL   = "sql"   // a table named by an SQL query in a string
+	LiteralEnv   = "env"   // an environment variable name
+	LiteralFlag  = "flag"  // a feature flag key
+)
+
+// LiteralCategories lists the literal categories FindLiterals reports.
+var LiteralCategories = []string{LiteralRoute, LiteralSQL, LiteralEnv, LiteralFlag}
+
+var (
+	// Environment lookups by name: os.Getenv("X"), ENV["X"], process.env.X.
+	envCallRe  = regexp.MustCompile(`\b(?:[Gg]etenv|LookupEnv|[Ss]etenv|Unsetenv|GetEnvironmentVariable|SetEnvironmentVariable|environ\.get|ENV\.fetch|env::var(?:_os)?|env!|env\.get|env)\s*\(\s*["'` + "`" + `]([A-Za-z_][A-Za-z0-9_]*)["'` + "`" + `]`)
+	envIndexRe = regexp.MustCompile(`(?:\b(?:environ|ENV|process\.env)|\$_ENV|\$_SERVER)\s*\[\s*["']([A-Za-z_][A-Za-z0-9_]*)["']\s*\]`)
+	envPropRe  = regexp.MustCompile(`\b(?:process\.env|import\.meta\.env)\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+	// flagCallRe matches a call whose first string argument, possibly after
+	// a context argument, may be a feature flag key.
+	flagCallRe = regexp.MustCompile(`\b([A-Za-z_]\w*)\s*\(\s*(?:[\w.&*]+\s*,\s*)?["'` + "`" + `]([\w.\-:/]+)["'` + "`" + `]`)
+
+	routeLiteralRe = regexp.MustCompile(`^(?:(?:GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)\s+)?/[\w\-.~:{}<>*@$+=/\[\]]*$`)
+	routeFileExtRe = regexp.MustCompile(`\.[A-Za-z0-9]{1,5}$`)
+	sqlLiteralRe   = regexp.MustCompile(`(?is)^\s*(?:SELECT\b.+\bFROM|(?:INSERT|REPLACE)\s+(?:IGNORE\s+)?INTO|UPDATE\s+\S+\s+SET|DELETE\s+FROM|MERGE\s+INTO|TRUNCATE|WITH\s+\w+\s+AS\s*\()\b`)
+	sqlQueryHintRe = regexp.MustCompile(`(?i)\bWHERE\b|[*,.=]`)
+)
+
+// routeFilesystemRoots are first path segments of filesystem paths, which
+// look like routes.
+var routeFilesystemRoots = toSet("bin", "dev", "etc", "home", "lib", "Library", "mnt", "opt", "private", "proc", "root", "sbin", "srv", "sys", "System", "tmp", "Users", "usr", "var")
+
+// Languages whose line comments start with # or --; the others use // and
+// /* */, and PHP and Terraform both.
+var (
+	hashCommentLanguages = toSet("python", "ruby", "shell", "perl", "r", "yaml", "make", "dockerfile")
+	dashCommentLanguages = toSet("lua", "sql")
+)
+
+// stringLiteral is the value of a string literal and its offset in the file.
+type stringLiteral struct {
+	pos   int
+	value string
+}
+
+// extractLiteralReferences records the notable string literals of a file as
+// literal references: URL routes, the tables of SQL queries, environment
+// variable names and feature flag keys. The caller of each is the enclosing
+// function.
+func extractLiteralReferences(filePath, content string, lines []string, lang string, functionBoundaries []functionBoundary) []Reference {
+	literals, comments := scanStringLiterals(content, lang)
+
+	var refs []Reference
+	add := func(value string, pos int, category string) {
+		ref := buildDataReference(filePath, content, lines, value, pos, RefKindLiteral, functionBoundaries)
+		ref.Category = category
+		refs = append(refs, ref)
+	}
+
+	// Literals named by an environment lookup or a flag check are not
+	// classified again by their value.
+	claimed := make(map[int]bool)
+	for _, re := range []*regexp.Regexp{envCallRe, envIndexRe, envPropRe} {
+		for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+			if comments[m[0]] {
+				continue
+			}
+			add(content[m[2]:m[3]], m[2], LiteralEnv)
+			claimed[m[2]] = true
+		}
+	}
+	for _, m := range flagCallRe.FindAllStringSubmatchIndex(content, -1) {
+		if comments[m[0]] || claimed[m[4]] || !isFlagFunction(content[m[2]:m[3]]) {
+			continue
+		}
+		add(content[m[4]:m[5]], m[4], LiteralFlag)
+		claimed[m[4]] = true
+	}
+
+	for _, lit := range literals {
+		if claimed[lit.pos] {
+			continue
+		}
+		if isRouteLiteral(lit.value) {
+			add(lit.value, lit.pos, LiteralRoute)
+			continue
+		}
+		if isSQLLiteral(lit.value) {
+			sqlReferences(lit.value, func(name string, pos int, kind string) {
+				if kind == RefKindRead || kind == RefKindWrite {
+					add(name, lit.pos+pos, LiteralSQL)
+				}
+			})
+		}
+	}
+	return refs
+}
+
+// scanStringLiterals returns the string literals of content with a mask of
+// its comments. Single- and double-quoted literals end at the line; backtick
+// literals may span lines.
+func scanStringLiterals(content, lang string) ([]stringLiteral, []bool) {
+	comments := make([]bool, len(content))
+	hashComments := hashCommentLanguages[lang] || lang == "php" || lang == "terraform"
+	slashComments := !hashCommentLanguages[lang] && !dashCommentLanguages[lang]
+	escapedBackticks := lang == "javascript" || lang == "typescript"
+
+	var literals []stringLiteral
+	for i := 0; i < len(content); i++ {
+		ch := content[i]
+		next := byte(0)
+		if i+1 < len(content) {
+			next = content[i+1]
+		}
+
+		lineComment := slashComments && ch == '/' && next == '/' ||
+			hashComments && ch == '#' ||
+			dashCommentLanguages[lang] && ch == '-' && next == '-'
+		if lineComment || slashComments && ch == '/' && next == '*' {
+			end := len(content)
+			if lineComment {
+				if nl := strings.IndexByte(content[i:], '\n'); nl >= 0 {
+					end = i + nl
+				}
+			} else if j := strings.Index(content[i+2:], "*/"); j >= 0 {
+				end = i + 2 + j + 2
+			}
+			for j := i; j < end; j++ {
+				comments[j] = true
+			}
+			i = end - 1
+			continue
+		}
+
+		if ch != '"' && ch != '\'' && ch != '`' {
+			continue
+		}
+		// A Rust quote not closing a character literal starts a lifetime.
+		if lang == "rust" && ch == '\'' && next != '\\' && (i+2 >= len(content) || content[i+2] != '\'') {
+			continue
+		}
+		escapes := ch != '`' || escapedBackticks
+		end := -1
+		for j := i + 1; j < len(content); j++ {
+			c := content[j]
+			if c == '\\' && escapes {
+				j++
+				continue
+			}
+			if c == ch {
+				end = j
+				break
+			}
+			if c == '\n' && ch != '`' {
+				break
+			}
+		}
+		if end < 0 {
+			if nl := strings.IndexByte(content[i:], '\n'); nl >= 0 && ch != '`' {
+				i += nl
+				continue
+			}
+			break
+		}
+		literals = append(literals, stringLiteral{pos: i + 1, value: content[i+1 : end]})
+		i = end
+	}
+	return literals, comments
+}
+
+// isRouteLiteral reports whether value is a URL route, optionally after an
+// HTTP method, and not a file path.
+func isRouteLiteral(value string) bool {
+	if !routeLiteralRe.MatchString(value) || strings.Contains(value, "//") || routeFileExtRe.MatchString(value) {
+		return false
+	}
+	path := value[strings.IndexByte(value, '/')+1:]
+	if !strings.ContainsAny(path, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+		return false
+	}
+	root, _, _ := strings.Cut(path, "/")
+	return !routeFilesystemRoots[root]
+}
+
+// isSQLLiteral reports whether value is an SQL query. A lowercase select
+// must also have a column list or WHERE clause to tell it from prose such
+// as "select a user from the list".
+func isSQLLiteral(value string) bool {
+	if !sqlLiteralRe.MatchString(value) {
+		return false
+	}
+	verb := strings.Fields(value)[0]
+	if strings.EqualFold(verb, "SELECT") && verb != "SELECT" {
+		return sqlQueryHintRe.MatchString(value)
+	}
+	return true
+}
+
+// isFlagFunction reports whether a function named name looks up feature
+// flags, as in isEnabled("x"), boolVariation("x") or getFeatureFlag("x").
+func isFlagFunction(name string) bool {
+	n := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	for _, word := range []string{"feature", "variation", "experiment"} {
+		if strings.Contains(n, word) {
+			return true
+		}
+	}
+	switch n {
+	case "flag", "getflag", "useflag", "flagenabled", "isflagenabled", "isenabled", "ison":
+		return true
+	}
+	return false
+}
+
+// LiteralUsage is one use of a string literal found by FindLiterals.
+type LiteralUsage struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Context string `json:"context,omitempty"`
+	Caller  string `json:"caller,omitempty"` // enclosing symbol, empty at top level
+	Project string `json:"project,omitempty"`
+}
+
+// Literal is a string literal value with its uses.
+type Literal struct {
+	Value    string         `json:"value"`
+	Category string         `json:"category"`
+	Usages   []LiteralUsage `json:"usages"`
+}
+
+// LiteralsResult is the output of a literal query.
+type LiteralsResult struct {
+	Query    string         `json:"query"`
+	Category string         `json:"category,omitempty"`
+	Total    int            `json:"total"`
+	Offset   int            `json:"offset,omitempty"`
+	Counts   map[string]int `json:"counts"` // literals per category
+	Literals []Literal      `json:"literals"`
+}
+
+// ParseLiteralCategory validates a literal category. An empty category
+// selects every category.
+func ParseLiteralCategory(category string) (string, error) {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		return "", nil
+	}
+	for _, c := range LiteralCategories {
+		if category == c {
+			return category, nil
+		}
+	}
+	return "", fmt.Errorf("invalid kind %q: must be one of %s", category, strings.Join(LiteralCategories, ", "))
+}
+
+// FindLiterals returns the string literals across stores whose value
+// contains query, case-insensitively, with the symbols that use them. An
+// empty query matches every literal; category restricts the result to one
+// category. Literals are sorted by value, and page applies to them rather
+// than to their usages.
+func FindLiterals(ctx context.Context, stores []SymbolStore, query, category string, page PageOptions) (*LiteralsResult, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(query)
+
+	type literalKey struct{ value, category string }
+	byKey := make(map[literalKey]*Literal)
+	seen := make(map[LiteralUsage]map[literalKey]bool)
+	for _, ss := range stores {
+		found, err := ss.ListLiterals(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list literals: %w", err)
+		}
+		project := ProjectOf(ss)
+		for _, ref := range found {
+			if category != "" && ref.Category != category {
+				continue
+			}
+			if !strings.Contains(strings.ToLower(ref.SymbolName), needle) {
+				continue
+			}
+			key := literalKey{ref.SymbolName, ref.Category}
+			caller := ref.CallerName
+			if caller == "<top-level>" {
+				caller = ""
+			}
+			usage := LiteralUsage{File: ref.File, Line: ref.Line, Context: ref.Context, Caller: caller, Project: project}
+			if seen[usage][key] {
+				continue
+			}
+			if seen[usage] == nil {
+				seen[usage] = make(map[literalKey]bool)
+			}
+			seen[usage][key] = true
+			lit := byKey[key]
+			if lit == nil {
+				lit = &Literal{Value: ref.SymbolName, Category: ref.Category}
+				byKey[key] = lit
+			}
+			lit.Usages = append(lit.Usages, usage)
+		}
+	}
+
+	result := &LiteralsResult{Query: query, Category: category, Counts: make(map[string]int), Literals: []Literal{}}
+	literals := make([]Literal, 0, len(byKey))
+	for _, lit := range byKey {
+		sort.Slice(lit.Usages, func(i, j int) bool {
+			a, b := lit.Usages[i], lit.Usages[j]
+			if a.Project != b.Project {
+				return a.Project < b.Project
+			}
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.Line < b.Line
+		})
+		result.Counts[lit.Category]++
+		literals = append(literals, *lit)
+	}
+	sort.Slice(literals, func(i, j int) bool {
+		if literals[i].Value != literals[j].Value {
+			return literals[i].Value < literals[j].Value
+		}
+		return literals[i].Category < literals[j].Category
+	})
+
+	result.Total = len(literals)
+	if paged := pageSlice(literals, page); paged != nil {
+		result.Literals = paged
+	}
+	if result.Total > 0 {
+		result.Offset = page.Offset
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+// literalKey identifies a literal reference by value, category and caller
+// in tests.
+type literalKey struct {
+	value, category, caller string
+}
+
+func extractLiteralKeys(t *testing.T, filePath, content string) map[literalKey]bool {
+	t.Helper()
+	refs, err := NewRegexExtractor().ExtractReferences(context.Background(), filePath, content)
+	if err != nil {
+		t.Fatalf("ExtractReferences failed: %v", err)
+	}
+	keys := make(map[literalKey]bool)
+	for _, r := range refs {
+		if r.Kind == RefKindLiteral {
+			keys[literalKey{r.SymbolName, r.Category, r.CallerName}] = true
+		}
+	}
+	return keys
+}
+
+func TestExtractLiteralReferences_Go(t *testing.T) {
+	content := `package api
+
+import "os"
+
+// Routes are registered in "/api/legacy" no more.
+func Register(mux *Mux) {
+	mux.HandleFunc("/api/users/{id}", getUser)
+	mux.HandleFunc("GET /health", health)
+	cfg := loadConfig("/etc/app/config.yaml")
+	_ = "/static/app.js"
+}
+
+func getUser(w Writer, r *Request) {
+	dsn := os.Getenv("DATABASE_URL")
+	if flags.IsEnabled(ctx, "new-profile") {
+		db.Query(` + "`" + `
+			SELECT u.id FROM users u
+			JOIN teams t ON t.id = u.team_id` + "`" + `, dsn)
+	}
+	db.Exec("UPDATE audit_log SET seen = true")
+	fmt.Println("select the user from the list")
+}
+`
+	keys := extractLiteralKeys(t, "api/routes.go", content)
+	for _, want := range []literalKey{
+		{"/api/users/{id}", LiteralRoute, "Register"},
+		{"GET /health", LiteralRoute, "Register"},
+		{"DATABASE_URL", LiteralEnv, "getUser"},
+		{"new-profile", LiteralFlag, "getUser"},
+		{"users", LiteralSQL, "getUser"},
+		{"teams", LiteralSQL, "getUser"},
+		{"audit_log", LiteralSQL, "getUser"},
+	} {
+		if !keys[want] {
+			t.Errorf("missing literal %+v in %v", want, keys)
+		}
+	}
+	for key := range keys {
+		switch key.value {
+		case "/api/legacy", "/etc/app/config.yaml", "/static/app.js", "the":
+			t.Errorf("unexpected literal %+v", key)
+		}
+	}
+}
+
+func TestExtractLiteralReferences_ScriptLanguages(t *testing.T) {
+	python := `import os
+
+# Don't read "/api/old" here.
+def handler():
+    token = os.environ["API_TOKEN"]
+    region = os.environ.get('AWS_REGION')
+    return client.get("/v1/orders")
+`
+	keys := extractLiteralKeys(t, "app.py", python)
+	for _, want := range []literalKey{
+		{"API_TOKEN", LiteralEnv, "handler"},
+		{"AWS_REGION", LiteralEnv, "handler"},
+		{"/v1/orders", LiteralRoute, "handler"},
+	} {
+		if !keys[want] {
+			t.Errorf("python: missing literal %+v in %v", want, keys)
+		}
+	}
+	if keys[literalKey{"/api/old", LiteralRoute, "<top-level>"}] {
+		t.Error("python: literal in a comment should be skipped")
+	}
+
+	js := `export function boot() {
+  const url = process.env.API_URL;
+  if (ldClient.boolVariation('dark-mode', false)) {
+    router.get('/settings/:tab', showSettings);
+  }
+}
+`
+	keys = extractLiteralKeys(t, "boot.js", js)
+	for _, want := range []literalKey{
+		{"API_URL", LiteralEnv, "boot"},
+		{"dark-mode", LiteralFlag, "boot"},
+		{"/settings/:tab", LiteralRoute, "boot"},
+	} {
+		if !keys[want] {
+			t.Errorf("javascript: missing literal %+v in %v", want, keys)
+		}
+	}
+}
+
+func TestGOBSymbolStore_Literals(t *testing.T) {
+	ctx := context.Background()
+	indexPath := t.TempDir() + "/symbols.gob"
+	store := NewGOBSymbolStore(indexPath)
+	if err := store.SaveFile(ctx, "api.go", nil, []Reference{
+		{SymbolName: "/api/users", Kind: RefKindLiteral, Category: LiteralRoute, File: "api.go", Line: 3, CallerName: "Register"},
+		{SymbolName: "listUsers", Kind: RefKindCall, File: "api.go", Line: 3, CallerName: "Register"},
+	}); err != nil {
+		t.Fatalf("failed to save file: %v", err)
+	}
+	if err := store.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist: %v", err)
+	}
+
+	reloaded := NewGOBSymbolStore(indexPath)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	literals, err := reloaded.ListLiterals(ctx)
+	if err != nil || len(literals) != 1 || literals[0].Category != LiteralRoute {
+		t.Fatalf("expected the route literal, got %+v, %v", literals, err)
+	}
+	if refs, _ := reloaded.LookupReferences(ctx, "/api/users"); len(refs) != 0 {
+		t.Errorf("literals should not be symbol references, got %+v", refs)
+	}
+	edges, _ := reloaded.GetCallEdges(ctx)
+	if len(edges) != 1 || edges[0].Callee != "listUsers" {
+		t.Errorf("expected only the call edge to listUsers, got %+v", edges)
+	}
+
+	if err := reloaded.DeleteFile(ctx, "api.go"); err != nil {
+		t.Fatalf("failed to delete file: %v", err)
+	}
+	if literals, _ := reloaded.ListLiterals(ctx); len(literals) != 0 {
+		t.Errorf("expected literals of the deleted file to be removed, got %+v", literals)
+	}
+}
+
+func TestFindLiterals(t *testing.T) {
+	ctx := context.Background()
+	api := NewGOBSymbolStore(t.TempDir() + "/symbols.gob")
+	web := NewGOBSymbolStore(t.TempDir() + "/symbols.gob")
+	if err := api.SaveFile(ctx, "api.go", nil, []Reference{
+		{SymbolName: "/api/users", Kind: RefKindLiteral, Category: LiteralRoute, File: "api.go", Line: 3, CallerName: "Register"},
+		{SymbolName: "USERS_DB", Kind: RefKindLiteral, Category: LiteralEnv, File: "api.go", Line: 9, CallerName: "<top-level>"},
+		{SymbolName: "orders", Kind: RefKindLiteral, Category: LiteralSQL, File: "api.go", Line: 12, CallerName: "listOrders"},
+	}); err != nil {
+		t.Fatalf("failed to save file: %v", err)
+	}
+	if err := web.SaveFile(ctx, "client.ts", nil, []Reference{
+		{SymbolName: "/api/users", Kind: RefKindLiteral, Category: LiteralRoute, File: "client.ts", Line: 7, CallerName: "fetchUsers"},
+	}); err != nil {
+		t.Fatalf("failed to save file: %v", err)
+	}
+	stores := []SymbolStore{
+		&ProjectSymbolStore{SymbolStore: api, Project: "api"},
+		&ProjectSymbolStore{SymbolStore: web, Project: "web"},
+	}
+
+	result, err := FindLiterals(ctx, stores, "users", "", PageOptions{})
+	if err != nil {
+		t.Fatalf("FindLiterals failed: %v", err)
+	}
+	if result.Total != 2 || result.Literals[0].Value != "/api/users" || result.Literals[1].Value != "USERS_DB" {
+		t.Fatalf("expected /api/users and USERS_DB, got %+v", result.Literals)
+	}
+	route := result.Literals[0]
+	if len(route.Usages) != 2 || route.Usages[0].Project != "api" || route.Usages[1].Caller != "fetchUsers" {
+		t.Errorf("expected the route used in both projects, got %+v", route.Usages)
+	}
+	if env := result.Literals[1]; env.Usages[0].Caller != "" {
+		t.Errorf("expected a top-level use without caller, got %+v", env.Usages[0])
+	}
+
+	result, err = FindLiterals(ctx, stores, "", LiteralSQL, PageOptions{})
+	if err != nil {
+		t.Fatalf("FindLiterals failed: %v", err)
+	}
+	if result.Total != 1 || result.Literals[0].Value != "orders" {
+		t.Errorf("expected only the orders table, got %+v", result.Literals)
+	}
+
+	result, err = FindLiterals(ctx, stores, "", "", PageOptions{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("FindLiterals failed: %v", err)
+	}
+	if result.Total != 3 || len(result.Literals) != 1 || result.Literals[0].Value != "USERS_DB" {
+		t.Errorf("expected the second of three literals, got %+v", result)
+	}
+
+	if _, err := ParseLiteralCategory("url"); err == nil {
+		t.Error("expected an error for an unknown literal kind")
+	}
+}
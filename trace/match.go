@@ -0,0 +1,233 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Match kinds reported in SymbolMatch, from strongest to weakest.
+const (
+	MatchExact           = "exact"
+	MatchQualified       = "qualified"
+	MatchCaseInsensitive = "case_insensitive"
+	MatchPrefix          = "prefix"
+	MatchFuzzy           = "fuzzy"
+)
+
+var matchRank = map[string]int{
+	MatchExact:           0,
+	MatchQualified:       1,
+	MatchCaseInsensitive: 2,
+	MatchPrefix:          3,
+	MatchFuzzy:           4,
+}
+
+// SymbolMatch is a symbol that matched a lookup query.
+type SymbolMatch struct {
+	Symbol Symbol `json:"symbol"`
+	Match  string `json:"match"`
+	Score  int    `json:"score"` // Lower is better within the same match kind
+}
+
+// SymbolResolution is the outcome of resolving a user query to a symbol name.
+type SymbolResolution struct {
+	// Name is the symbol name to trace. Empty when nothing matched or the
+	// query is ambiguous.
+	Name string
+	// Symbol is the specific definition selected by a qualified query, if any.
+	Symbol *Symbol
+	// Match is the kind of match that produced Name.
+	Match string
+	// Candidates lists the competing symbols when the query is ambiguous.
+	Candidates []SymbolMatch
+}
+
+// ApplyResolution records a non-exact match on result and pins the target
+// symbol when a qualified query selected a specific definition.
+func ApplyResolution(result *TraceResult, res SymbolResolution) {
+	if res.Match != "" && res.Match != MatchExact {
+		result.Match = res.Match
+	}
+	if res.Symbol != nil && result.Symbol != nil {
+		sym := *res.Symbol
+		result.Symbol = &sym
+	}
+}
+
+// Ambiguous reports whether the query matched several distinct symbol names.
+func (r SymbolResolution) Ambiguous() bool {
+	return r.Name == "" && len(r.Candidates) > 0
+}
+
+// MatchSymbol reports how sym matches query, trying exact, qualified
+// (Receiver.Method or pkg.Func), case-insensitive, prefix and fuzzy
+// (subsequence) matching in that order.
+func MatchSymbol(query string, sym Symbol) (SymbolMatch, bool) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return SymbolMatch{}, false
+	}
+	if sym.Name == query {
+		return SymbolMatch{Symbol: sym, Match: MatchExact}, true
+	}
+
+	if qualifier, name, ok := splitQualified(query); ok {
+		if strings.EqualFold(sym.Name, name) && matchesQualifier(sym, qualifier) {
+			return SymbolMatch{Symbol: sym, Match: MatchQualified}, true
+		}
+		return SymbolMatch{}, false
+	}
+
+	lowerName := strings.ToLower(sym.Name)
+	lowerQuery := strings.ToLower(query)
+	if lowerName == lowerQuery {
+		return SymbolMatch{Symbol: sym, Match: MatchCaseInsensitive}, true
+	}
+	if strings.HasPrefix(lowerName, lowerQuery) {
+		return SymbolMatch{Symbol: sym, Match: MatchPrefix, Score: len(lowerName) - len(lowerQuery)}, true
+	}
+	if gaps, ok := subsequenceGaps(lowerQuery, lowerName); ok {
+		return SymbolMatch{Symbol: sym, Match: MatchFuzzy, Score: gaps}, true
+	}
+	return SymbolMatch{}, false
+}
+
+// splitQualified splits "Qualifier.Name" (also "Qualifier::Name") at the last
+// separator.
+func splitQualified(query string) (string, string, bool) {
+	for _, sep := range []string{"::", "."} {
+		if idx := strings.LastIndex(query, sep); idx > 0 && idx+len(sep) < len(query) {
+			return query[:idx], query[idx+len(sep):], true
+		}
+	}
+	return "", "", false
+}
+
+// matchesQualifier checks a qualifier against the receiver, package, parent
+// directory or file name of a symbol.
+func matchesQualifier(sym Symbol, qualifier string) bool {
+	// Only the innermost qualifier is compared (pkg.Type.Method -> Type).
+	if _, last, ok := splitQualified(qualifier); ok {
+		qualifier = last
+	}
+	receiver := strings.TrimLeft(sym.Receiver, "*&")
+	if idx := strings.IndexAny(receiver, "[<"); idx > 0 {
+		receiver = receiver[:idx]
+	}
+	candidates := []string{receiver, sym.Package}
+	if sym.File != "" {
+		candidates = append(candidates,
+			filepath.Base(filepath.Dir(filepath.FromSlash(sym.File))),
+			strings.TrimSuffix(filepath.Base(sym.File), filepath.Ext(sym.File)),
+		)
+	}
+	for _, c := range candidates {
+		if c != "" && strings.EqualFold(c, qualifier) {
+			return true
+		}
+	}
+	return false
+}
+
+// subsequenceGaps reports whether every rune of query appears in name in
+// order, and how many runes of name were skipped between the first and last
+// matched rune.
+func subsequenceGaps(query, name string) (int, bool) {
+	q := []rune(query)
+	if len(q) == 0 {
+		return 0, false
+	}
+	qi, first, last := 0, -1, -1
+	for i, r := range []rune(name) {
+		if qi < len(q) && r == q[qi] {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			qi++
+		}
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return (last - first + 1) - len(q), true
+}
+
+// SortSymbolMatches orders matches by match kind, score, then name and location.
+func SortSymbolMatches(matches []SymbolMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if matchRank[a.Match] != matchRank[b.Match] {
+			return matchRank[a.Match] < matchRank[b.Match]
+		}
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		if a.Symbol.Name != b.Symbol.Name {
+			return a.Symbol.Name < b.Symbol.Name
+		}
+		if a.Symbol.File != b.Symbol.File {
+			return a.Symbol.File < b.Symbol.File
+		}
+		return a.Symbol.Line < b.Symbol.Line
+	})
+}
+
+// maxCandidates bounds the disambiguation list returned to callers.
+const maxCandidates = 20
+
+// ResolveSymbol resolves a trace query against one or more stores. An exact
+// name hit in any store always wins. Otherwise the strongest match kind is
+// used: if all its matches share one name that name is returned, else the
+// matches are returned as candidates for disambiguation.
+func ResolveSymbol(ctx context.Context, stores []SymbolStore, query string) (SymbolResolution, error) {
+	for _, ss := range stores {
+		symbols, err := ss.LookupSymbol(ctx, query)
+		if err != nil {
+			return SymbolResolution{}, err
+		}
+		if len(symbols) > 0 {
+			return SymbolResolution{Name: query, Match: MatchExact}, nil
+		}
+	}
+
+	var matches []SymbolMatch
+	for _, ss := range stores {
+		found, err := ss.SearchSymbols(ctx, query, 0)
+		if err != nil {
+			return SymbolResolution{}, err
+		}
+		matches = append(matches, found...)
+	}
+	if len(matches) == 0 {
+		return SymbolResolution{}, nil
+	}
+	SortSymbolMatches(matches)
+
+	best := matches[0].Match
+	var tier []SymbolMatch
+	names := make(map[string]bool)
+	for _, m := range matches {
+		if m.Match != best {
+			break
+		}
+		tier = append(tier, m)
+		names[m.Symbol.Name] = true
+	}
+
+	if len(names) == 1 {
+		res := SymbolResolution{Name: tier[0].Symbol.Name, Match: best}
+		if best == MatchQualified && len(tier) == 1 {
+			sym := tier[0].Symbol
+			res.Symbol = &sym
+		}
+		return res, nil
+	}
+
+	if len(tier) > maxCandidates {
+		tier = tier[:maxCandidates]
+	}
+	return SymbolResolution{Match: best, Candidates: tier}, nil
+}
@@ -0,0 +1,81 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchSymbol(t *testing.T) {
+	method := Symbol{Name: "Search", Kind: KindMethod, File: "store/gob.go", Receiver: "*GOBStore"}
+	fn := Symbol{Name: "NewServer", Kind: KindFunction, File: "mcp/server.go"}
+
+	tests := []struct {
+		query string
+		sym   Symbol
+		want  string
+		ok    bool
+	}{
+		{"Search", method, MatchExact, true},
+		{"GOBStore.Search", method, MatchQualified, true},
+		{"store.GOBStore.Search", method, MatchQualified, true},
+		{"OtherStore.Search", method, "", false},
+		{"mcp.NewServer", fn, MatchQualified, true},
+		{"server.NewServer", fn, MatchQualified, true},
+		{"newserver", fn, MatchCaseInsensitive, true},
+		{"newser", fn, MatchPrefix, true},
+		{"nwsrv", fn, MatchFuzzy, true},
+		{"xyz", fn, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			m, ok := MatchSymbol(tt.query, tt.sym)
+			if ok != tt.ok {
+				t.Fatalf("MatchSymbol(%q) ok = %v, want %v", tt.query, ok, tt.ok)
+			}
+			if ok && m.Match != tt.want {
+				t.Fatalf("MatchSymbol(%q) = %q, want %q", tt.query, m.Match, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveSymbol(t *testing.T) {
+	ctx := context.Background()
+	store := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	if err := store.SaveFile(ctx, "server.go", []Symbol{
+		{Name: "HandleRequest", Kind: KindFunction, File: "server.go", Line: 10},
+		{Name: "HandleResponse", Kind: KindFunction, File: "server.go", Line: 20},
+		{Name: "Close", Kind: KindMethod, File: "server.go", Line: 30, Receiver: "*Server"},
+		{Name: "Close", Kind: KindMethod, File: "server.go", Line: 40, Receiver: "*Client"},
+	}, nil); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	stores := []SymbolStore{store}
+
+	res, err := ResolveSymbol(ctx, stores, "HandleRequest")
+	if err != nil || res.Name != "HandleRequest" || res.Match != MatchExact {
+		t.Fatalf("exact: got %+v, err %v", res, err)
+	}
+
+	res, _ = ResolveSymbol(ctx, stores, "handlerequest")
+	if res.Name != "HandleRequest" || res.Match != MatchCaseInsensitive {
+		t.Fatalf("case-insensitive: got %+v", res)
+	}
+
+	res, _ = ResolveSymbol(ctx, stores, "Client.Close")
+	if res.Name != "Close" || res.Symbol == nil || res.Symbol.Line != 40 {
+		t.Fatalf("qualified: got %+v", res)
+	}
+
+	res, _ = ResolveSymbol(ctx, stores, "Handle")
+	if !res.Ambiguous() || len(res.Candidates) != 2 || res.Match != MatchPrefix {
+		t.Fatalf("ambiguous prefix: got %+v", res)
+	}
+
+	res, _ = ResolveSymbol(ctx, stores, "Missing")
+	if res.Name != "" || res.Ambiguous() {
+		t.Fatalf("no match: got %+v", res)
+	}
+}
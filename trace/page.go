@@ -0,0 +1,195 @@
+package trace
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Sort keys accepted for caller/callee listings.
+const (
+	SortByFile    = "file"
+	SortByLine    = "line"
+	SortByProject = "project"
+	SortByCount   = "count"
+)
+
+// PageOptions controls ordering and pagination of caller/callee listings.
+// A zero Limit means no limit.
+type PageOptions struct {
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// Validate checks that the sort key is known and bounds are non-negative.
+func (o PageOptions) Validate() error {
+	switch o.Sort {
+	case "", SortByFile, SortByLine, SortByProject, SortByCount:
+	default:
+		return fmt.Errorf("invalid sort %q: must be one of file, line, project, count", o.Sort)
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("limit must be >= 0")
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("offset must be >= 0")
+	}
+	return nil
+}
+
+// ApplyPage sorts the callers and callees of result and trims them to the
+// requested page. Total is set to the number of entries before trimming so
+// clients can tell whether more pages exist.
+func ApplyPage(result *TraceResult, opts PageOptions) {
+	if result == nil {
+		return
+	}
+	if len(result.Callers) > 0 {
+		keys := make([]callSortKey, len(result.Callers))
+		for i, c := range result.Callers {
+			keys[i] = callSortKey{project: c.Project, name: c.Symbol.Name, file: c.CallSite.File, line: c.CallSite.Line}
+		}
+		order := sortedOrder(keys, opts.Sort)
+		sorted := make([]CallerInfo, len(order))
+		for i, idx := range order {
+			sorted[i] = result.Callers[idx]
+		}
+		result.Total = len(sorted)
+		result.Callers = pageSlice(sorted, opts)
+	}
+	if len(result.Callees) > 0 {
+		keys := make([]callSortKey, len(result.Callees))
+		for i, c := range result.Callees {
+			keys[i] = callSortKey{project: c.Project, name: c.Symbol.Name, file: c.CallSite.File, line: c.CallSite.Line}
+		}
+		order := sortedOrder(keys, opts.Sort)
+		sorted := make([]CalleeInfo, len(order))
+		for i, idx := range order {
+			sorted[i] = result.Callees[idx]
+		}
+		result.Total = len(sorted)
+		result.Callees = pageSlice(sorted, opts)
+	}
+	if result.Total > 0 {
+		result.Offset = opts.Offset
+	}
+}
+
+// FilterFiles drops the callers, callees, implementations and graph nodes
+// of result whose file keep rejects, before paging. Callers are kept by the
+// file of their call site, the others by the file defining their symbol;
+// the graph root and the edges between kept nodes stay.
+func FilterFiles(result *TraceResult, keep func(file string) bool) {
+	if result == nil || keep == nil {
+		return
+	}
+	callers := result.Callers[:0]
+	for _, c := range result.Callers {
+		if keep(c.CallSite.File) {
+			callers = append(callers, c)
+		}
+	}
+	result.Callers = callers
+	callees := result.Callees[:0]
+	for _, c := range result.Callees {
+		if keep(c.Symbol.File) {
+			callees = append(callees, c)
+		}
+	}
+	result.Callees = callees
+	impls := result.Implementations[:0]
+	for _, impl := range result.Implementations {
+		if keep(impl.Method.File) {
+			impls = append(impls, impl)
+		}
+	}
+	result.Implementations = impls
+
+	if g := result.Graph; g != nil {
+		for name, node := range g.Nodes {
+			if name != g.Root && !keep(node.File) {
+				delete(g.Nodes, name)
+			}
+		}
+		edges := g.Edges[:0]
+		for _, e := range g.Edges {
+			_, callerKept := g.Nodes[e.Caller]
+			_, calleeKept := g.Nodes[e.Callee]
+			if callerKept && calleeKept {
+				edges = append(edges, e)
+			}
+		}
+		g.Edges = edges
+	}
+}
+
+type callSortKey struct {
+	project string
+	name    string
+	file    string
+	line    int
+}
+
+// sortedOrder returns the indexes of keys in the requested order. Every sort
+// falls back to project, file, line and name so the result does not depend on
+// the order in which stores returned their entries.
+func sortedOrder(keys []callSortKey, by string) []int {
+	counts := make(map[string]int)
+	if by == SortByCount {
+		for _, k := range keys {
+			counts[k.project+"\x00"+k.name]++
+		}
+	}
+
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := keys[order[i]], keys[order[j]]
+		switch by {
+		case SortByLine:
+			if a.line != b.line {
+				return a.line < b.line
+			}
+		case SortByProject:
+			if a.project != b.project {
+				return a.project < b.project
+			}
+		case SortByCount:
+			ca, cb := counts[a.project+"\x00"+a.name], counts[b.project+"\x00"+b.name]
+			if ca != cb {
+				return ca > cb
+			}
+			if a.name != b.name {
+				return a.name < b.name
+			}
+		}
+		return a.less(b)
+	})
+	return order
+}
+
+func (a callSortKey) less(b callSortKey) bool {
+	if a.file != b.file {
+		return a.file < b.file
+	}
+	if a.line != b.line {
+		return a.line < b.line
+	}
+	if a.name != b.name {
+		return a.name < b.name
+	}
+	return a.project < b.project
+}
+
+func pageSlice[T any](items []T, opts PageOptions) []T {
+	if opts.Offset >= len(items) {
+		return nil
+	}
+	items = items[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(items) {
+		items = items[:opts.Limit]
+	}
+	return items
+}
@@ -0,0 +1,137 @@
+package trace
+
+import "testing"
+
+func callersFixture() []CallerInfo {
+	return []CallerInfo{
+		{Symbol: Symbol{Name: "Beta"}, CallSite: CallSite{File: "b.go", Line: 20}, Project: "api"},
+		{Symbol: Symbol{Name: "Alpha"}, CallSite: CallSite{File: "a.go", Line: 30}, Project: "web"},
+		{Symbol: Symbol{Name: "Beta"}, CallSite: CallSite{File: "b.go", Line: 5}, Project: "api"},
+		{Symbol: Symbol{Name: "Gamma"}, CallSite: CallSite{File: "c.go", Line: 1}, Project: "api"},
+	}
+}
+
+func callerNames(callers []CallerInfo) []string {
+	names := make([]string, len(callers))
+	for i, c := range callers {
+		names[i] = c.Symbol.Name + "@" + c.CallSite.File
+	}
+	return names
+}
+
+func TestApplyPage_SortKeys(t *testing.T) {
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"Alpha@a.go", "Beta@b.go", "Beta@b.go", "Gamma@c.go"}},
+		{SortByFile, []string{"Alpha@a.go", "Beta@b.go", "Beta@b.go", "Gamma@c.go"}},
+		{SortByLine, []string{"Gamma@c.go", "Beta@b.go", "Beta@b.go", "Alpha@a.go"}},
+		{SortByProject, []string{"Beta@b.go", "Beta@b.go", "Gamma@c.go", "Alpha@a.go"}},
+		{SortByCount, []string{"Beta@b.go", "Beta@b.go", "Alpha@a.go", "Gamma@c.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			result := TraceResult{Callers: callersFixture()}
+			ApplyPage(&result, PageOptions{Sort: tt.sort})
+			got := callerNames(result.Callers)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("order = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyPage_StableAcrossInputOrder(t *testing.T) {
+	a := TraceResult{Callers: callersFixture()}
+	reversed := callersFixture()
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	b := TraceResult{Callers: reversed}
+
+	ApplyPage(&a, PageOptions{Sort: SortByCount})
+	ApplyPage(&b, PageOptions{Sort: SortByCount})
+	for i := range a.Callers {
+		if a.Callers[i] != b.Callers[i] {
+			t.Fatalf("ordering depends on input order: %v vs %v", a.Callers, b.Callers)
+		}
+	}
+}
+
+func TestApplyPage_LimitOffset(t *testing.T) {
+	result := TraceResult{Callers: callersFixture()}
+	ApplyPage(&result, PageOptions{Limit: 2, Offset: 1})
+
+	if result.Total != 4 {
+		t.Fatalf("Total = %d, want 4", result.Total)
+	}
+	if result.Offset != 1 {
+		t.Fatalf("Offset = %d, want 1", result.Offset)
+	}
+	if len(result.Callers) != 2 || result.Callers[0].CallSite.Line != 5 {
+		t.Fatalf("unexpected page: %+v", result.Callers)
+	}
+
+	past := TraceResult{Callees: []CalleeInfo{{Symbol: Symbol{Name: "X"}}}}
+	ApplyPage(&past, PageOptions{Offset: 5})
+	if len(past.Callees) != 0 || past.Total != 1 {
+		t.Fatalf("offset past end should return empty page with total, got %+v", past)
+	}
+}
+
+func TestPageOptionsValidate(t *testing.T) {
+	if err := (PageOptions{Sort: "count", Limit: 10}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (PageOptions{Sort: "name"}).Validate(); err == nil {
+		t.Fatal("expected error for unknown sort key")
+	}
+	if err := (PageOptions{Limit: -1}).Validate(); err == nil {
+		t.Fatal("expected error for negative limit")
+	}
+	if err := (PageOptions{Offset: -1}).Validate(); err == nil {
+		t.Fatal("expected error for negative offset")
+	}
+}
+
+func TestFilterFiles(t *testing.T) {
+	result := TraceResult{
+		Callers: callersFixture(),
+		Callees: []CalleeInfo{
+			{Symbol: Symbol{Name: "Save", File: "store/save.go"}, CallSite: CallSite{File: "a.go"}},
+			{Symbol: Symbol{Name: "Println"}, CallSite: CallSite{File: "a.go"}},
+		},
+		Graph: &CallGraph{
+			Root: "Alpha",
+			Nodes: map[string]Symbol{
+				"Alpha": {Name: "Alpha", File: "a_test.go"},
+				"Beta":  {Name: "Beta", File: "b.go"},
+				"Gamma": {Name: "Gamma", File: "c.go"},
+			},
+			Edges: []CallEdge{{Caller: "Alpha", Callee: "Beta"}, {Caller: "Beta", Callee: "Gamma"}},
+		},
+	}
+	FilterFiles(&result, func(file string) bool { return file != "" && file != "c.go" })
+
+	if got := callerNames(result.Callers); len(got) != 3 || got[0] != "Beta@b.go" {
+		t.Errorf("expected the callers outside c.go, got %v", got)
+	}
+	if len(result.Callees) != 1 || result.Callees[0].Symbol.Name != "Save" {
+		t.Errorf("expected only the callee with a kept file, got %+v", result.Callees)
+	}
+	if _, ok := result.Graph.Nodes["Alpha"]; !ok {
+		t.Error("expected the graph root to be kept")
+	}
+	if _, ok := result.Graph.Nodes["Gamma"]; ok || len(result.Graph.Edges) != 1 {
+		t.Errorf("expected Gamma and its edge dropped, got %+v", result.Graph)
+	}
+
+	FilterFiles(&result, nil)
+	if len(result.Callers) != 3 {
+		t.Error("expected a nil filter to keep every caller")
+	}
+}
@@ -0,0 +1,194 @@
+package trace
+
+import (
+	"context"
+	"sort"
+)
+
+// Defaults for call-path searches.
+const (
+	DefaultPathDepth = 6
+	DefaultPathLimit = 5
+)
+
+// maxPathExpansions bounds the work done enumerating paths in very dense
+// call graphs. The search returns the paths found so far when exceeded.
+const maxPathExpansions = 200000
+
+// PathOptions configures a call-path search.
+type PathOptions struct {
+	// MaxDepth is the maximum number of calls in a path.
+	MaxDepth int
+	// Limit is the maximum number of paths returned.
+	Limit int
+}
+
+// PathStep is a single call along a call path.
+type PathStep struct {
+	Caller  string `json:"caller"`
+	Callee  string `json:"callee"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Project string `json:"project,omitempty"` // Workspace project (workspace mode only)
+}
+
+// CallPath is a chain of calls leading from one symbol to another.
+type CallPath struct {
+	Length  int        `json:"length"`
+	Symbols []string   `json:"symbols"`
+	Steps   []PathStep `json:"steps"`
+}
+
+// PathResult is the output of a call-path search.
+type PathResult struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	MaxDepth  int        `json:"max_depth"`
+	Paths     []CallPath `json:"paths"`
+	Truncated bool       `json:"truncated,omitempty"` // Search stopped early on a very dense graph
+}
+
+// FindCallPaths returns the shortest call paths from one symbol to another,
+// shortest first, following caller -> callee edges from all stores. Paths
+// never visit the same symbol twice.
+func FindCallPaths(ctx context.Context, stores []SymbolStore, from, to string, opts PathOptions) (PathResult, error) {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultPathDepth
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPathLimit
+	}
+	result := PathResult{From: from, To: to, MaxDepth: opts.MaxDepth, Paths: []CallPath{}}
+
+	graph, err := loadPathGraph(ctx, stores)
+	if err != nil {
+		return result, err
+	}
+
+	// Distance from every symbol to the target, used to prune the search to
+	// edges that can still reach it within the remaining depth.
+	dist := map[string]int{to: 0}
+	queue := []string{to}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if dist[node] >= opts.MaxDepth {
+			continue
+		}
+		for _, caller := range graph.callers[node] {
+			if _, ok := dist[caller]; !ok {
+				dist[caller] = dist[node] + 1
+				queue = append(queue, caller)
+			}
+		}
+	}
+	shortest, ok := dist[from]
+	if !ok {
+		return result, nil
+	}
+
+	s := &pathSearch{graph: graph, dist: dist, to: to, limit: opts.Limit, onPath: map[string]bool{from: true}}
+	for length := shortest; length <= opts.MaxDepth && len(s.paths) < s.limit && !s.truncated; length++ {
+		s.walk(from, length, nil)
+	}
+	result.Paths = s.paths
+	result.Truncated = s.truncated
+	return result, nil
+}
+
+type pathGraph struct {
+	callees map[string][]PathStep
+	callers map[string][]string
+}
+
+// loadPathGraph merges the call edges of all stores. Only the first call site
+// (by project, file and line) of each caller -> callee pair is kept.
+func loadPathGraph(ctx context.Context, stores []SymbolStore) (*pathGraph, error) {
+	var steps []PathStep
+	for _, ss := range stores {
+		edges, err := ss.GetCallEdges(ctx)
+		if err != nil {
+			return nil, err
+		}
+		project := ProjectOf(ss)
+		for _, e := range edges {
+			steps = append(steps, PathStep{Caller: e.Caller, Callee: e.Callee, File: e.File, Line: e.Line, Project: project})
+		}
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		a, b := steps[i], steps[j]
+		if a.Caller != b.Caller {
+			return a.Caller < b.Caller
+		}
+		if a.Callee != b.Callee {
+			return a.Callee < b.Callee
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	g := &pathGraph{callees: make(map[string][]PathStep), callers: make(map[string][]string)}
+	for i, step := range steps {
+		if i > 0 && steps[i-1].Caller == step.Caller && steps[i-1].Callee == step.Callee {
+			continue
+		}
+		g.callees[step.Caller] = append(g.callees[step.Caller], step)
+		g.callers[step.Callee] = append(g.callers[step.Callee], step.Caller)
+	}
+	return g, nil
+}
+
+type pathSearch struct {
+	graph      *pathGraph
+	dist       map[string]int
+	to         string
+	limit      int
+	onPath     map[string]bool
+	paths      []CallPath
+	expansions int
+	truncated  bool
+}
+
+// walk enumerates paths from node to the target using exactly remaining calls.
+func (s *pathSearch) walk(node string, remaining int, steps []PathStep) {
+	if len(s.paths) >= s.limit || s.truncated {
+		return
+	}
+	if remaining == 0 {
+		if node == s.to {
+			s.paths = append(s.paths, newCallPath(steps, node))
+		}
+		return
+	}
+	for _, step := range s.graph.callees[node] {
+		if d, ok := s.dist[step.Callee]; !ok || d > remaining-1 || s.onPath[step.Callee] {
+			continue
+		}
+		s.expansions++
+		if s.expansions > maxPathExpansions {
+			s.truncated = true
+			return
+		}
+		s.onPath[step.Callee] = true
+		s.walk(step.Callee, remaining-1, append(steps, step))
+		delete(s.onPath, step.Callee)
+		if len(s.paths) >= s.limit || s.truncated {
+			return
+		}
+	}
+}
+
+func newCallPath(steps []PathStep, last string) CallPath {
+	path := CallPath{Length: len(steps), Steps: make([]PathStep, len(steps))}
+	copy(path.Steps, steps)
+	for _, step := range steps {
+		path.Symbols = append(path.Symbols, step.Caller)
+	}
+	path.Symbols = append(path.Symbols, last)
+	return path
+}
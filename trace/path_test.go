@@ -0,0 +1,91 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newPathTestStore(t *testing.T, calls [][2]string) *GOBSymbolStore {
+	t.Helper()
+	ctx := context.Background()
+	store := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	var refs []Reference
+	for i, c := range calls {
+		refs = append(refs, Reference{SymbolName: c[1], File: "app.go", Line: i + 1, CallerName: c[0], CallerFile: "app.go"})
+	}
+	if err := store.SaveFile(ctx, "app.go", nil, refs); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	return store
+}
+
+func TestFindCallPaths(t *testing.T) {
+	ctx := context.Background()
+	store := newPathTestStore(t, [][2]string{
+		{"Handler", "Service"},
+		{"Service", "Repo"},
+		{"Repo", "Exec"},
+		{"Handler", "Cache"},
+		{"Cache", "Exec"},
+		{"Handler", "Log"},
+		{"Exec", "Handler"}, // cycle back to the start
+	})
+
+	result, err := FindCallPaths(ctx, []SymbolStore{store}, "Handler", "Exec", PathOptions{})
+	if err != nil {
+		t.Fatalf("FindCallPaths failed: %v", err)
+	}
+	var got [][]string
+	for _, p := range result.Paths {
+		got = append(got, p.Symbols)
+	}
+	want := [][]string{
+		{"Handler", "Cache", "Exec"},
+		{"Handler", "Service", "Repo", "Exec"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("paths = %v, want %v", got, want)
+	}
+	if step := result.Paths[0].Steps[1]; step.Caller != "Cache" || step.Callee != "Exec" || step.Line != 5 {
+		t.Fatalf("unexpected call site: %+v", step)
+	}
+
+	result, err = FindCallPaths(ctx, []SymbolStore{store}, "Handler", "Exec", PathOptions{MaxDepth: 2, Limit: 5})
+	if err != nil {
+		t.Fatalf("FindCallPaths failed: %v", err)
+	}
+	if len(result.Paths) != 1 {
+		t.Fatalf("paths within depth 2 = %d, want 1", len(result.Paths))
+	}
+
+	result, err = FindCallPaths(ctx, []SymbolStore{store}, "Log", "Exec", PathOptions{})
+	if err != nil {
+		t.Fatalf("FindCallPaths failed: %v", err)
+	}
+	if len(result.Paths) != 0 {
+		t.Fatalf("expected no path from Log, got %v", result.Paths)
+	}
+}
+
+func TestFindCallPathsAcrossProjects(t *testing.T) {
+	ctx := context.Background()
+	web := newPathTestStore(t, [][2]string{{"Submit", "CallAPI"}})
+	api := newPathTestStore(t, [][2]string{{"CallAPI", "Save"}})
+	stores := []SymbolStore{
+		&ProjectSymbolStore{SymbolStore: web, Project: "web"},
+		&ProjectSymbolStore{SymbolStore: api, Project: "api"},
+	}
+
+	result, err := FindCallPaths(ctx, stores, "Submit", "Save", PathOptions{Limit: 1})
+	if err != nil {
+		t.Fatalf("FindCallPaths failed: %v", err)
+	}
+	if len(result.Paths) != 1 || result.Paths[0].Length != 2 {
+		t.Fatalf("unexpected paths: %+v", result.Paths)
+	}
+	if p := result.Paths[0]; p.Steps[0].Project != "web" || p.Steps[1].Project != "api" {
+		t.Fatalf("unexpected step projects: %+v", p.Steps)
+	}
+}
@@ -1,6 +1,10 @@
 package trace
 
-import "regexp"
+import (
+	"regexp"
+
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
+)
 
 // LanguagePatterns holds regex patterns for a specific language.
 type LanguagePatterns struct {
@@ -55,6 +59,11 @@ var languagePatterns = map[string]*LanguagePatterns{
 	".fs":   fsharpPatterns,
 	".fsx":  fsharpPatterns,
 	".fsi":  fsharpPatterns,
+
+	langdetect.ExtMakefile:   makePatterns,
+	langdetect.ExtDockerfile: dockerfilePatterns,
+	".tf":                    terraformPatterns,
+	".sql":                   sqlPatterns,
 }
 
 // Go patterns
@@ -640,6 +649,29 @@ var fsharpPatterns = &LanguagePatterns{
 	MethodCall:   regexp.MustCompile(`\.([a-zA-Z_][a-zA-Z0-9_']*)\s*\(`),
 }
 
+// Makefile, Dockerfile, Terraform and SQL symbols and references are
+// extracted by extractSpanSymbols and extractSpanReferences: their names
+// span several tokens, or references depend on what the file declares.
+var makePatterns = &LanguagePatterns{
+	Extension: langdetect.ExtMakefile,
+	Language:  "make",
+}
+
+var dockerfilePatterns = &LanguagePatterns{
+	Extension: langdetect.ExtDockerfile,
+	Language:  "dockerfile",
+}
+
+var terraformPatterns = &LanguagePatterns{
+	Extension: ".tf",
+	Language:  "terraform",
+}
+
+var sqlPatterns = &LanguagePatterns{
+	Extension: ".sql",
+	Language:  "sql",
+}
+
 // IsKeyword checks if a name is a language keyword.
 func IsKeyword(name string, lang string) bool {
 	if kw, ok := languageKeywords[lang]; ok {
@@ -0,0 +1,137 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ReferenceKinds lists the reference kinds FindReferences reports.
+var ReferenceKinds = []string{RefKindCall, RefKindRead, RefKindWrite, RefKindType, RefKindInstantiate, RefKindImport}
+
+// SymbolReference is one usage of a symbol found by FindReferences.
+type SymbolReference struct {
+	Kind    string `json:"kind"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Context string `json:"context,omitempty"`
+	Caller  string `json:"caller,omitempty"` // enclosing symbol, empty at top level
+	Project string `json:"project,omitempty"`
+}
+
+// ReferencesResult is the output of a references query.
+type ReferencesResult struct {
+	Query      string            `json:"query"`
+	Total      int               `json:"total"`
+	Offset     int               `json:"offset,omitempty"`
+	Counts     map[string]int    `json:"counts"` // references per kind, before pagination
+	References []SymbolReference `json:"references"`
+}
+
+// ParseReferenceKinds parses a comma-separated list of reference kinds.
+// An empty list selects every kind.
+func ParseReferenceKinds(list string) ([]string, error) {
+	var kinds []string
+	for _, k := range strings.Split(list, ",") {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" {
+			continue
+		}
+		known := false
+		for _, rk := range ReferenceKinds {
+			if k == rk {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("invalid kind %q: must be one of %s", k, strings.Join(ReferenceKinds, ", "))
+		}
+		kinds = append(kinds, k)
+	}
+	return kinds, nil
+}
+
+// FindReferences returns every reference to name across stores: calls,
+// property reads and writes, type usages, instantiations and imports.
+// References are recorded under the bare symbol name, so a qualified query
+// such as Server.Start matches references to Start. kinds restricts the
+// result to the given kinds; empty means all. A call and an instantiation
+// recorded on the same line (new Foo() in JavaScript) are reported once,
+// as the instantiation.
+func FindReferences(ctx context.Context, stores []SymbolStore, name string, kinds []string, page PageOptions) (*ReferencesResult, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	query := name
+	if i := strings.LastIndex(name, "."); i >= 0 && i < len(name)-1 {
+		name = name[i+1:]
+	}
+	wanted := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		wanted[k] = true
+	}
+
+	type lineKey struct {
+		project, file string
+		line          int
+	}
+	instantiated := make(map[lineKey]bool)
+	seen := make(map[SymbolReference]bool)
+	var refs []SymbolReference
+	for _, ss := range stores {
+		found, err := ss.LookupReferences(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup references of %q: %w", name, err)
+		}
+		project := ProjectOf(ss)
+		for _, ref := range found {
+			kind := ref.Kind
+			if kind == "" {
+				kind = RefKindCall
+			}
+			if kind == RefKindInstantiate {
+				instantiated[lineKey{project, ref.File, ref.Line}] = true
+			}
+			caller := ref.CallerName
+			if caller == "<top-level>" {
+				caller = ""
+			}
+			r := SymbolReference{Kind: kind, File: ref.File, Line: ref.Line, Context: ref.Context, Caller: caller, Project: project}
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			refs = append(refs, r)
+		}
+	}
+
+	result := &ReferencesResult{Query: query, Counts: make(map[string]int), References: []SymbolReference{}}
+	var kept []SymbolReference
+	var keys []callSortKey
+	for _, r := range refs {
+		if r.Kind == RefKindCall && instantiated[lineKey{r.Project, r.File, r.Line}] {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[r.Kind] {
+			continue
+		}
+		result.Counts[r.Kind]++
+		kept = append(kept, r)
+		keys = append(keys, callSortKey{project: r.Project, name: r.Kind, file: r.File, line: r.Line})
+	}
+
+	order := sortedOrder(keys, page.Sort)
+	sorted := make([]SymbolReference, len(order))
+	for i, idx := range order {
+		sorted[i] = kept[idx]
+	}
+	result.Total = len(sorted)
+	if paged := pageSlice(sorted, page); paged != nil {
+		result.References = paged
+	}
+	if result.Total > 0 {
+		result.Offset = page.Offset
+	}
+	return result, nil
+}
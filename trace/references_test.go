@@ -0,0 +1,72 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFindReferences(t *testing.T) {
+	ctx := context.Background()
+	api := NewGOBSymbolStore(t.TempDir() + "/symbols.gob")
+	web := NewGOBSymbolStore(t.TempDir() + "/symbols.gob")
+	if err := api.SaveFile(ctx, "server.go", nil, []Reference{
+		{SymbolName: "Options", Kind: RefKindType, File: "server.go", Line: 10, CallerName: "NewServer"},
+		{SymbolName: "Options", Kind: RefKindInstantiate, File: "server.go", Line: 12, CallerName: "NewServer"},
+		{SymbolName: "Other", Kind: RefKindType, File: "server.go", Line: 13, CallerName: "NewServer"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := web.SaveFile(ctx, "app.ts", nil, []Reference{
+		{SymbolName: "Options", Kind: RefKindImport, File: "app.ts", Line: 1, CallerName: "<top-level>"},
+		{SymbolName: "Options", Kind: RefKindCall, File: "app.ts", Line: 5, CallerName: "main"},
+		{SymbolName: "Options", Kind: RefKindInstantiate, File: "app.ts", Line: 5, CallerName: "main"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	stores := []SymbolStore{
+		&ProjectSymbolStore{SymbolStore: api, Project: "api"},
+		&ProjectSymbolStore{SymbolStore: web, Project: "web"},
+	}
+
+	result, err := FindReferences(ctx, stores, "pkg.Options", nil, PageOptions{Sort: SortByProject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 4 || len(result.References) != 4 {
+		t.Fatalf("expected 4 references, got %+v", result)
+	}
+	if result.Counts[RefKindCall] != 0 || result.Counts[RefKindInstantiate] != 2 {
+		t.Errorf("expected new Options() to count as one instantiation, got %v", result.Counts)
+	}
+	first, last := result.References[0], result.References[3]
+	if first.Project != "api" || first.Line != 10 || last.Project != "web" || last.Line != 5 {
+		t.Errorf("unexpected order: %+v", result.References)
+	}
+	if ref := result.References[2]; ref.Kind != RefKindImport || ref.Caller != "" {
+		t.Errorf("expected a top-level import without caller, got %+v", ref)
+	}
+
+	result, err = FindReferences(ctx, stores, "Options", []string{RefKindType, RefKindImport}, PageOptions{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 || len(result.References) != 1 || result.Offset != 1 {
+		t.Fatalf("expected the second of 2 filtered references, got %+v", result)
+	}
+	if result.References[0].File != "server.go" {
+		t.Errorf("expected references sorted by file, got %+v", result.References)
+	}
+}
+
+func TestParseReferenceKinds(t *testing.T) {
+	kinds, err := ParseReferenceKinds(" Type, import,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kinds) != 2 || kinds[0] != RefKindType || kinds[1] != RefKindImport {
+		t.Errorf("unexpected kinds: %v", kinds)
+	}
+	if _, err := ParseReferenceKinds("calls"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
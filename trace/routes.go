@@ -0,0 +1,309 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RouteAnyMethod is the method of routes that serve every HTTP method.
+const RouteAnyMethod = "ANY"
+
+var (
+	// net/http, with Go 1.22 method patterns: mux.HandleFunc("GET /users/{id}", h).
+	goHandleRe = regexp.MustCompile(`\.(?:HandleFunc|Handle)\s*\(\s*"(?:([A-Z]+)\s+)?(/[^"\s]*)"`)
+	// gin, echo, chi and fiber: r.GET("/users", h), r.Get("/users", h).
+	// The handler argument tells them from HTTP clients and caches, such as
+	// client.Get("/users").
+	goMethodRe = regexp.MustCompile(`\.(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|Any|Get|Post|Put|Patch|Delete|Head|Options)\s*\(\s*"(/[^"]*)"`)
+	// express, koa-router and fastify: app.get('/users', h). The receiver
+	// must look like an app or router, so HTTP clients such as
+	// axios.get('/users') are left out.
+	jsRouteRe = regexp.MustCompile(`\b(?:app|router|server|api|routes|\w+Router|\w+App)\.(get|post|put|patch|delete|head|options|all)\s*\(\s*['"` + "`" + `](/[^'"` + "`" + `]*)['"` + "`" + `]`)
+	// FastAPI and Flask decorators: @app.get("/users"), @bp.route("/users", methods=["POST"]).
+	pyRouteRe   = regexp.MustCompile(`(?m)^[ \t]*@\w+(?:\.\w+)*\.(get|post|put|patch|delete|head|options|route|api_route)\s*\(\s*['"](/[^'"]*)['"]([^\n]*)`)
+	pyMethodsRe = regexp.MustCompile(`methods\s*=\s*[\[(]([^\])]*)[\])]`)
+	pyDefRe     = regexp.MustCompile(`^\s*(?:@[^\n]*\n\s*)*(?:async\s+)?def\s+(\w+)`)
+	// Spring: @GetMapping("/users/{id}"), @RequestMapping(value = "/api", method = RequestMethod.POST).
+	springMappingRe = regexp.MustCompile(`@(Get|Post|Put|Patch|Delete|Request)Mapping\b(?:\s*\(([^)]*)\))?`)
+	springPathRe    = regexp.MustCompile(`"([^"]*)"`)
+	springMethodRe  = regexp.MustCompile(`RequestMethod\.([A-Z]+)`)
+	springClassRe   = regexp.MustCompile(`^(?:\s*@\w+(?:\s*\([^)]*\))?)*\s*(?:(?:public|protected|private|abstract|final|open|data)\s+)*class\s`)
+	springHandlerRe = regexp.MustCompile(`^(?:\s*@\w+(?:\s*\([^)]*\))?)*\s*(?:(?:public|protected|private|static|final|synchronized|suspend|override|open)\s+)*(?:fun\s+(\w+)|[\w<>\[\],.?]+\s+(\w+))\s*\(`)
+
+	routeHandlerRe = regexp.MustCompile(`^(?:[\w.]+\(\s*)?([\w.]+)\s*\)?$`)
+)
+
+// extractRouteReferences records the HTTP endpoints a file declares as route
+// references: net/http, gin, echo, chi and fiber registrations in Go,
+// express-style routers in JavaScript and TypeScript, FastAPI and Flask
+// decorators in Python, and Spring mappings in Java and Kotlin.
+func extractRouteReferences(filePath, content string, lines []string, lang string, functionBoundaries []functionBoundary) []Reference {
+	_, comments := scanStringLiterals(content, lang)
+
+	var refs []Reference
+	add := func(method, path, handler string, pos int) {
+		ref := buildDataReference(filePath, content, lines, path, pos, RefKindRoute, functionBoundaries)
+		ref.Category = method
+		ref.Handler = handler
+		refs = append(refs, ref)
+	}
+
+	switch lang {
+	case "go":
+		for _, m := range goHandleRe.FindAllStringSubmatchIndex(content, -1) {
+			if comments[m[0]] {
+				continue
+			}
+			method := RouteAnyMethod
+			if m[2] >= 0 {
+				method = content[m[2]:m[3]]
+			}
+			add(method, content[m[4]:m[5]], routeHandler(content, m[1]), m[0])
+		}
+		for _, m := range goMethodRe.FindAllStringSubmatchIndex(content, -1) {
+			if comments[m[0]] || len(callArgs(content, m[1])) == 0 {
+				continue
+			}
+			add(strings.ToUpper(content[m[2]:m[3]]), content[m[4]:m[5]], routeHandler(content, m[1]), m[0])
+		}
+	case "javascript", "typescript":
+		for _, m := range jsRouteRe.FindAllStringSubmatchIndex(content, -1) {
+			if comments[m[0]] {
+				continue
+			}
+			method := strings.ToUpper(content[m[2]:m[3]])
+			if method == "ALL" {
+				method = RouteAnyMethod
+			}
+			add(method, content[m[4]:m[5]], routeHandler(content, m[1]), m[0])
+		}
+	case "python":
+		for _, m := range pyRouteRe.FindAllStringSubmatchIndex(content, -1) {
+			if comments[m[2]] {
+				continue
+			}
+			handler := ""
+			if d := pyDefRe.FindStringSubmatch(content[m[1]:]); d != nil {
+				handler = d[1]
+			}
+			for _, method := range pythonRouteMethods(content[m[2]:m[3]], content[m[6]:m[7]]) {
+				add(method, content[m[4]:m[5]], handler, m[0])
+			}
+		}
+	case "java", "kotlin":
+		prefix := ""
+		for _, m := range springMappingRe.FindAllStringSubmatchIndex(content, -1) {
+			if comments[m[0]] {
+				continue
+			}
+			args := ""
+			if m[4] >= 0 {
+				args = content[m[4]:m[5]]
+			}
+			path := ""
+			if p := springPathRe.FindStringSubmatch(args); p != nil {
+				path = p[1]
+			}
+			rest := content[m[1]:]
+			// A mapping on the controller class prefixes its methods.
+			if springClassRe.MatchString(rest) {
+				prefix = path
+				continue
+			}
+			method := strings.ToUpper(content[m[2]:m[3]])
+			if method == "REQUEST" {
+				method = RouteAnyMethod
+				if rm := springMethodRe.FindStringSubmatch(args); rm != nil {
+					method = rm[1]
+				}
+			}
+			handler := ""
+			if h := springHandlerRe.FindStringSubmatch(rest); h != nil {
+				handler = h[1] + h[2]
+			}
+			add(method, joinRoutePath(prefix, path), handler, m[0])
+		}
+	}
+	return refs
+}
+
+// routeHandler returns the name of the handler passed as the last argument
+// of the route registration call whose path literal ends at pos, or "" for
+// an inline function.
+func routeHandler(content string, pos int) string {
+	args := callArgs(content, pos)
+	if len(args) == 0 {
+		return ""
+	}
+	m := routeHandlerRe.FindStringSubmatch(strings.TrimSpace(args[len(args)-1]))
+	if m == nil {
+		return ""
+	}
+	name := m[1]
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// callArgs returns the arguments of a call after the one ending at pos, up
+// to the closing parenthesis.
+func callArgs(content string, pos int) []string {
+	var args []string
+	depth := 0
+	start := -1
+	for i := pos; i < len(content); i++ {
+		switch c := content[i]; c {
+		case '"', '\'', '`':
+			end := strings.IndexByte(content[i+1:], c)
+			if end < 0 {
+				return nil
+			}
+			i += end + 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				if start >= 0 {
+					args = append(args, content[start:i])
+				}
+				return args
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				if start >= 0 {
+					args = append(args, content[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+	return nil
+}
+
+// pythonRouteMethods returns the methods of a FastAPI or Flask decorator
+// named decorator, whose arguments after the path are rest.
+func pythonRouteMethods(decorator, rest string) []string {
+	if decorator != "route" && decorator != "api_route" {
+		return []string{strings.ToUpper(decorator)}
+	}
+	m := pyMethodsRe.FindStringSubmatch(rest)
+	if m == nil {
+		return []string{"GET"}
+	}
+	var methods []string
+	for _, method := range strings.Split(m[1], ",") {
+		method = strings.ToUpper(strings.Trim(strings.TrimSpace(method), `"'`))
+		if method != "" {
+			methods = append(methods, method)
+		}
+	}
+	if len(methods) == 0 {
+		return []string{"GET"}
+	}
+	return methods
+}
+
+// joinRoutePath joins a controller prefix and a route path.
+func joinRoutePath(prefix, path string) string {
+	joined := strings.TrimRight(prefix, "/")
+	if path = strings.TrimLeft(path, "/"); path != "" {
+		joined += "/" + path
+	}
+	if !strings.HasPrefix(joined, "/") {
+		joined = "/" + joined
+	}
+	return joined
+}
+
+// Route is an HTTP endpoint found by FindRoutes.
+type Route struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler,omitempty"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Caller  string `json:"caller,omitempty"` // function registering the route, empty at top level
+	Project string `json:"project,omitempty"`
+}
+
+// RoutesResult is the output of a routes query.
+type RoutesResult struct {
+	Total  int            `json:"total"`
+	Offset int            `json:"offset,omitempty"`
+	Counts map[string]int `json:"counts"` // routes per method
+	Routes []Route        `json:"routes"`
+}
+
+// FindRoutes returns the HTTP endpoints across stores, sorted by path and
+// method. method keeps the routes of one method, case-insensitively, and
+// path those whose path contains it; empty values keep every route.
+func FindRoutes(ctx context.Context, stores []SymbolStore, method, path string, page PageOptions) (*RoutesResult, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	method = strings.ToUpper(method)
+	path = strings.ToLower(path)
+
+	seen := make(map[Route]bool)
+	var routes []Route
+	for _, ss := range stores {
+		found, err := ss.ListRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list routes: %w", err)
+		}
+		project := ProjectOf(ss)
+		for _, ref := range found {
+			if method != "" && ref.Category != method {
+				continue
+			}
+			if !strings.Contains(strings.ToLower(ref.SymbolName), path) {
+				continue
+			}
+			caller := ref.CallerName
+			if caller == "<top-level>" {
+				caller = ""
+			}
+			route := Route{Method: ref.Category, Path: ref.SymbolName, Handler: ref.Handler, File: ref.File, Line: ref.Line, Caller: caller, Project: project}
+			if seen[route] {
+				continue
+			}
+			seen[route] = true
+			routes = append(routes, route)
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	result := &RoutesResult{Counts: make(map[string]int), Routes: []Route{}}
+	for _, r := range routes {
+		result.Counts[r.Method]++
+	}
+	result.Total = len(routes)
+	if paged := pageSlice(routes, page); paged != nil {
+		result.Routes = paged
+	}
+	if result.Total > 0 {
+		result.Offset = page.Offset
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+// routeKey identifies a route reference in tests.
+type routeKey struct {
+	method, path, handler string
+}
+
+func extractRouteKeys(t *testing.T, filePath, content string) map[routeKey]int {
+	t.Helper()
+	refs, err := NewRegexExtractor().ExtractReferences(context.Background(), filePath, content)
+	if err != nil {
+		t.Fatalf("ExtractReferences failed: %v", err)
+	}
+	keys := make(map[routeKey]int)
+	for _, r := range refs {
+		if r.Kind == RefKindRoute {
+			keys[routeKey{r.Category, r.SymbolName, r.Handler}] = r.Line
+		}
+	}
+	return keys
+}
+
+func assertRoutes(t *testing.T, got map[routeKey]int, want map[routeKey]int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("expected %d routes, got %v", len(want), got)
+	}
+	for key, line := range want {
+		if got[key] != line {
+			t.Errorf("expected route %+v on line %d, got %v", key, line, got)
+		}
+	}
+}
+
+func TestExtractRouteReferences_Go(t *testing.T) {
+	content := `package api
+
+func Register(mux *http.ServeMux, r *gin.Engine, h *Handlers) {
+	mux.HandleFunc("GET /users/{id}", h.GetUser)
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/static/", http.StripPrefix("/static/", files))
+	// r.GET("/old", h.Old)
+	r.POST("/users", auth, h.CreateUser)
+	r.DELETE("/users/:id", func(c *gin.Context) {
+		c.Status(204)
+	})
+	resp, err := client.Get("/users")
+	cached := cache.Get("/users/1")
+}
+`
+	assertRoutes(t, extractRouteKeys(t, "api/routes.go", content), map[routeKey]int{
+		{"GET", "/users/{id}", "GetUser"}: 4,
+		{"ANY", "/metrics", ""}:           5,
+		{"ANY", "/static/", ""}:           6,
+		{"POST", "/users", "CreateUser"}:  8,
+		{"DELETE", "/users/:id", ""}:      9,
+	})
+}
+
+func TestExtractRouteReferences_JavaScript(t *testing.T) {
+	content := `const router = express.Router();
+
+router.get('/orders/:id', requireAuth, orders.show);
+app.all("/health", health);
+axios.get('/orders/1');
+`
+	assertRoutes(t, extractRouteKeys(t, "routes.js", content), map[routeKey]int{
+		{"GET", "/orders/:id", "show"}: 3,
+		{"ANY", "/health", "health"}:   4,
+	})
+}
+
+func TestExtractRouteReferences_Python(t *testing.T) {
+	content := `from fastapi import FastAPI
+
+app = FastAPI()
+
+@app.get("/items/{item_id}")
+async def read_item(item_id: int):
+    return {}
+
+@bp.route("/login", methods=["GET", "POST"])
+@login_required
+def login():
+    pass
+`
+	assertRoutes(t, extractRouteKeys(t, "main.py", content), map[routeKey]int{
+		{"GET", "/items/{item_id}", "read_item"}: 5,
+		{"GET", "/login", "login"}:               9,
+		{"POST", "/login", "login"}:              9,
+	})
+}
+
+func TestExtractRouteReferences_Spring(t *testing.T) {
+	content := `@RestController
+@RequestMapping("/api/users")
+public class UserController {
+
+    @GetMapping("/{id}")
+    public User get(@PathVariable Long id) {
+        return service.find(id);
+    }
+
+    @RequestMapping(value = "/search", method = RequestMethod.POST)
+    @ResponseBody
+    public List<User> search(@RequestBody Query query) {
+        return service.search(query);
+    }
+
+    @DeleteMapping
+    public void deleteAll() {}
+}
+`
+	assertRoutes(t, extractRouteKeys(t, "UserController.java", content), map[routeKey]int{
+		{"GET", "/api/users/{id}", "get"}:       5,
+		{"POST", "/api/users/search", "search"}: 10,
+		{"DELETE", "/api/users", "deleteAll"}:   16,
+	})
+}
+
+func TestFindRoutes(t *testing.T) {
+	ctx := context.Background()
+	store := NewGOBSymbolStore(t.TempDir() + "/symbols.gob")
+	if err := store.SaveFile(ctx, "api.go", nil, []Reference{
+		{SymbolName: "/users", Kind: RefKindRoute, Category: "POST", Handler: "CreateUser", File: "api.go", Line: 8, CallerName: "Register"},
+		{SymbolName: "/users", Kind: RefKindRoute, Category: "GET", Handler: "ListUsers", File: "api.go", Line: 7, CallerName: "Register"},
+		{SymbolName: "/health", Kind: RefKindRoute, Category: "ANY", File: "api.go", Line: 5, CallerName: "<top-level>"},
+	}); err != nil {
+		t.Fatalf("failed to save file: %v", err)
+	}
+	stores := []SymbolStore{store}
+
+	result, err := FindRoutes(ctx, stores, "", "", PageOptions{})
+	if err != nil {
+		t.Fatalf("FindRoutes failed: %v", err)
+	}
+	if result.Total != 3 || result.Routes[0].Path != "/health" || result.Routes[1].Method != "GET" || result.Routes[2].Handler != "CreateUser" {
+		t.Fatalf("expected routes sorted by path and method, got %+v", result.Routes)
+	}
+	if result.Routes[0].Caller != "" || result.Counts["GET"] != 1 {
+		t.Errorf("unexpected caller or counts: %+v", result)
+	}
+
+	result, err = FindRoutes(ctx, stores, "post", "USER", PageOptions{})
+	if err != nil {
+		t.Fatalf("FindRoutes failed: %v", err)
+	}
+	if result.Total != 1 || result.Routes[0].Handler != "CreateUser" {
+		t.Errorf("expected only POST /users, got %+v", result.Routes)
+	}
+	if refs, _ := store.LookupReferences(ctx, "/users"); len(refs) != 0 {
+		t.Errorf("routes should not be symbol references, got %+v", refs)
+	}
+}
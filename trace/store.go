@@ -3,6 +3,7 @@ package trace
 import (
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/yoanbernabeu/grepai/internal/fileutil"
+	"github.com/yoanbernabeu/grepai/internal/indexformat"
 )
 
 // GOBSymbolStore implements SymbolStore using GOB encoding.
@@ -20,9 +22,32 @@ type GOBSymbolStore struct {
 	fileIndex         map[string]bool
 	fileContentHashes map[string]string
 	mu                sync.RWMutex
+	tooNew            error // see indexformat.ErrTooNew
+}
+
+// symbolFormatVersion is the format version of the files GOBSymbolStore
+// writes. Files written before formats were versioned decode as version 0.
+const symbolFormatVersion = 4
+
+// symbolUpgrades migrate older symbol index files on load, keyed by the
+// version they upgrade from. Version 0 files only lack FormatVersion.
+// Version 1 files have no literal references and version 3 files no route
+// references; version 2 files are extracted again too.
+var symbolUpgrades = map[int]indexformat.Upgrade[gobSymbolData]{
+	1: forgetContentHashes,
+	2: forgetContentHashes,
+	3: forgetContentHashes,
+}
+
+// forgetContentHashes makes the next scan extract every file again, to
+// record references older versions did not.
+func forgetContentHashes(data *gobSymbolData) error {
+	data.FileContentHashes = nil
+	return nil
 }
 
 type gobSymbolData struct {
+	FormatVersion     int
 	Index             SymbolIndex
 	FileIndex         map[string]bool
 	FileContentHashes map[string]string
@@ -36,6 +61,8 @@ func NewGOBSymbolStore(indexPath string) *GOBSymbolStore {
 		index: &SymbolIndex{
 			Symbols:    make(map[string][]Symbol),
 			References: make(map[string][]Reference),
+			Literals:   make(map[string][]Reference),
+			Routes:     make(map[string][]Reference),
 			CallGraph:  []CallEdge{},
 			Version:    1,
 		},
@@ -78,6 +105,12 @@ func (s *GOBSymbolStore) loadUnlocked() error {
 	if err := gob.NewDecoder(file).Decode(&data); err != nil {
 		return fmt.Errorf("failed to decode symbol index: %w", err)
 	}
+	if err := indexformat.Migrate(s.indexPath, &data, data.FormatVersion, symbolFormatVersion, symbolUpgrades); err != nil {
+		if errors.Is(err, indexformat.ErrTooNew) {
+			s.tooNew = err
+		}
+		return err
+	}
 
 	s.index = &data.Index
 	s.fileIndex = data.FileIndex
@@ -89,6 +122,12 @@ func (s *GOBSymbolStore) loadUnlocked() error {
 	if s.index.References == nil {
 		s.index.References = make(map[string][]Reference)
 	}
+	if s.index.Literals == nil {
+		s.index.Literals = make(map[string][]Reference)
+	}
+	if s.index.Routes == nil {
+		s.index.Routes = make(map[string][]Reference)
+	}
 	if s.index.CallGraph == nil {
 		s.index.CallGraph = []CallEdge{}
 	}
@@ -127,8 +166,12 @@ func (s *GOBSymbolStore) Persist(ctx context.Context) error {
 }
 
 func (s *GOBSymbolStore) persistUnlocked() error {
+	if s.tooNew != nil {
+		return s.tooNew
+	}
 	s.index.UpdatedAt = time.Now()
 	data := gobSymbolData{
+		FormatVersion:     symbolFormatVersion,
 		Index:             *s.index,
 		FileIndex:         s.fileIndex,
 		FileContentHashes: s.fileContentHashes,
@@ -185,13 +228,24 @@ func (s *GOBSymbolStore) SaveFileWithContentHash(ctx context.Context, filePath s
 		s.index.Symbols[sym.Name] = append(s.index.Symbols[sym.Name], sym)
 	}
 
-	// Add new references
+	// Add new references, keeping literals and routes apart from
+	// symbol references
 	for _, ref := range refs {
-		s.index.References[ref.SymbolName] = append(s.index.References[ref.SymbolName], ref)
+		switch ref.Kind {
+		case RefKindLiteral:
+			s.index.Literals[ref.SymbolName] = append(s.index.Literals[ref.SymbolName], ref)
+		case RefKindRoute:
+			s.index.Routes[ref.SymbolName] = append(s.index.Routes[ref.SymbolName], ref)
+		default:
+			s.index.References[ref.SymbolName] = append(s.index.References[ref.SymbolName], ref)
+		}
 	}
 
 	// Build call graph edges
 	for _, ref := range refs {
+		if IsUsageReferenceKind(ref.Kind) || isIndexedApart(ref.Kind) {
+			continue
+		}
 		if ref.CallerName != "" && ref.CallerName != "<top-level>" {
 			s.index.CallGraph = append(s.index.CallGraph, CallEdge{
 				Caller:   ref.CallerName,
@@ -212,6 +266,12 @@ func (s *GOBSymbolStore) SaveFileWithContentHash(ctx context.Context, filePath s
 	return nil
 }
 
+// isIndexedApart reports whether references of kind are kept apart from
+// symbol references: literals and routes.
+func isIndexedApart(kind string) bool {
+	return kind == RefKindLiteral || kind == RefKindRoute
+}
+
 // DeleteFile removes all symbols and references for a file.
 func (s *GOBSymbolStore) DeleteFile(ctx context.Context, filePath string) error {
 	s.mu.Lock()
@@ -236,18 +296,20 @@ func (s *GOBSymbolStore) deleteFileUnlocked(filePath string) {
 		}
 	}
 
-	// Remove references from this file
-	for name, refs := range s.index.References {
-		filtered := make([]Reference, 0, len(refs))
-		for _, ref := range refs {
-			if ref.File != filePath {
-				filtered = append(filtered, ref)
+	// Remove references, literals and routes from this file
+	for _, byName := range []map[string][]Reference{s.index.References, s.index.Literals, s.index.Routes} {
+		for name, refs := range byName {
+			filtered := make([]Reference, 0, len(refs))
+			for _, ref := range refs {
+				if ref.File != filePath {
+					filtered = append(filtered, ref)
+				}
+			}
+			if len(filtered) == 0 {
+				delete(byName, name)
+			} else {
+				byName[name] = filtered
 			}
-		}
-		if len(filtered) == 0 {
-			delete(s.index.References, name)
-		} else {
-			s.index.References[name] = filtered
 		}
 	}
 
@@ -276,6 +338,27 @@ func (s *GOBSymbolStore) LookupSymbol(ctx context.Context, name string) ([]Symbo
 	return symbols, nil
 }
 
+// SearchSymbols finds symbols matching a query using qualified,
+// case-insensitive, prefix and fuzzy matching.
+func (s *GOBSymbolStore) SearchSymbols(ctx context.Context, query string, limit int) ([]SymbolMatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []SymbolMatch
+	for _, symbols := range s.index.Symbols {
+		for _, sym := range symbols {
+			if m, ok := MatchSymbol(query, sym); ok {
+				matches = append(matches, m)
+			}
+		}
+	}
+	SortSymbolMatches(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
 // LookupCallers finds all references/callers of a symbol.
 func (s *GOBSymbolStore) LookupCallers(ctx context.Context, symbolName string) ([]Reference, error) {
 	s.mu.RLock()
@@ -355,6 +438,62 @@ func (s *GOBSymbolStore) LookupWriters(ctx context.Context, symbolName string) (
 	return filterByReferenceKinds(refs, RefKindWrite), nil
 }
 
+// LookupReferences finds all references to a symbol name regardless of kind.
+func (s *GOBSymbolStore) LookupReferences(ctx context.Context, symbolName string) ([]Reference, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	refs := s.index.References[symbolName]
+	result := make([]Reference, len(refs))
+	copy(result, refs)
+	return result, nil
+}
+
+// LookupImplementations finds concrete methods named methodName whose
+// receiver type implements an interface declaring that method.
+func (s *GOBSymbolStore) LookupImplementations(ctx context.Context, methodName string) ([]Implementation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return resolveImplementations(s.index.Symbols, methodName), nil
+}
+
+// ListSymbols returns every symbol definition in the index.
+func (s *GOBSymbolStore) ListSymbols(ctx context.Context) ([]Symbol, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Symbol
+	for _, symbols := range s.index.Symbols {
+		result = append(result, symbols...)
+	}
+	return result, nil
+}
+
+// ListLiterals returns every literal reference in the index.
+func (s *GOBSymbolStore) ListLiterals(ctx context.Context) ([]Reference, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Reference
+	for _, refs := range s.index.Literals {
+		result = append(result, refs...)
+	}
+	return result, nil
+}
+
+// ListRoutes returns every route reference in the index.
+func (s *GOBSymbolStore) ListRoutes(ctx context.Context) ([]Reference, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Reference
+	for _, refs := range s.index.Routes {
+		result = append(result, refs...)
+	}
+	return result, nil
+}
+
 func filterByReferenceKinds(refs []Reference, kinds ...string) []Reference {
 	if len(refs) == 0 {
 		return []Reference{}
@@ -511,6 +650,15 @@ func (s *GOBSymbolStore) Close() error {
 	return s.Persist(context.Background())
 }
 
+// Release drops the loaded index without persisting it, for stores that
+// were only read. The store must not be used afterwards.
+func (s *GOBSymbolStore) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index = &SymbolIndex{}
+	s.fileIndex, s.fileContentHashes = nil, nil
+}
+
 // GetStats returns statistics about the symbol index.
 func (s *GOBSymbolStore) GetStats(ctx context.Context) (*SymbolStats, error) {
 	s.mu.RLock()
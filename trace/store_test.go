@@ -2,10 +2,14 @@ package trace
 
 import (
 	"context"
+	"encoding/gob"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/yoanbernabeu/grepai/internal/indexformat"
 )
 
 func TestGOBSymbolStore_should_load_empty_when_no_file_exists(t *testing.T) {
@@ -646,3 +650,70 @@ func TestGOBSymbolStore_ReferenceKindFilters(t *testing.T) {
 		t.Fatalf("expected only write refs, got %+v", writers)
 	}
 }
+
+func TestGOBSymbolStore_LoadVersions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unversioned file loads", func(t *testing.T) {
+		indexPath := filepath.Join(t.TempDir(), "symbols.gob")
+		legacy := struct {
+			Index     SymbolIndex
+			FileIndex map[string]bool
+		}{
+			Index:     SymbolIndex{Symbols: map[string][]Symbol{"Login": {{Name: "Login", File: "auth.go", Line: 3}}}},
+			FileIndex: map[string]bool{"auth.go": true},
+		}
+		encodeSymbolFile(t, indexPath, legacy)
+
+		store := NewGOBSymbolStore(indexPath)
+		if err := store.Load(ctx); err != nil {
+			t.Fatalf("failed to load unversioned symbol index: %v", err)
+		}
+		symbols, err := store.LookupSymbol(ctx, "Login")
+		if err != nil || len(symbols) != 1 {
+			t.Fatalf("expected the legacy symbol, got %v, %v", symbols, err)
+		}
+	})
+
+	t.Run("older file forgets content hashes", func(t *testing.T) {
+		indexPath := filepath.Join(t.TempDir(), "symbols.gob")
+		encodeSymbolFile(t, indexPath, gobSymbolData{
+			FormatVersion:     1,
+			FileIndex:         map[string]bool{"auth.go": true},
+			FileContentHashes: map[string]string{"auth.go": "abc"},
+		})
+
+		store := NewGOBSymbolStore(indexPath)
+		if err := store.Load(ctx); err != nil {
+			t.Fatalf("failed to load version 1 symbol index: %v", err)
+		}
+		if _, ok := store.GetFileContentHash("auth.go"); ok {
+			t.Error("expected the content hash to be dropped so the file is extracted again")
+		}
+	})
+
+	t.Run("newer file is refused and kept", func(t *testing.T) {
+		indexPath := filepath.Join(t.TempDir(), "symbols.gob")
+		encodeSymbolFile(t, indexPath, gobSymbolData{FormatVersion: symbolFormatVersion + 1})
+
+		store := NewGOBSymbolStore(indexPath)
+		if err := store.Load(ctx); !errors.Is(err, indexformat.ErrTooNew) {
+			t.Fatalf("expected ErrTooNew, got %v", err)
+		}
+		if err := store.Persist(ctx); !errors.Is(err, indexformat.ErrTooNew) {
+			t.Fatalf("expected Persist to refuse overwriting a newer index, got %v", err)
+		}
+	})
+}
+
+func encodeSymbolFile(t *testing.T, path string, v any) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+	if err := gob.NewEncoder(file).Encode(v); err != nil {
+		t.Fatalf("failed to encode %s: %v", path, err)
+	}
+}
@@ -0,0 +1,416 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Reasons a test is reported by FindTestsFor, with their weights in the
+// test's score.
+const (
+	TestReasonName     = "name"     // the test is named after the target
+	TestReasonFile     = "file"     // the test file is named after the target's file
+	TestReasonCalls    = "calls"    // the test references the target
+	TestReasonIndirect = "indirect" // the test calls a caller of the target
+	TestReasonSimilar  = "similar"  // the test code is semantically similar to the target
+)
+
+const (
+	testNameWeight       = 1.0
+	testNamePrefixWeight = 0.7
+	testFileWeight       = 0.5
+	testFileOtherWeight  = 0.3 // test file named after the target's file, in another directory
+	testCallsWeight      = 1.0
+	testIndirectWeight   = 0.4
+	testSimilarWeight    = 0.6 // multiplied by the similarity
+
+	// maxIndirectCallers bounds the callers of the target whose own test
+	// callers are looked up.
+	maxIndirectCallers = 20
+)
+
+// TestTarget is the symbol or file whose tests FindTestsFor looks for.
+type TestTarget struct {
+	// Name is the symbol name, or the file path for a file target.
+	Name string
+	// File is set when the target is a file.
+	File string
+	// Symbols are the definitions of the symbol, or the functions, methods
+	// and types of the file.
+	Symbols []Symbol
+}
+
+// Query returns text describing the target, to find similar test code by
+// semantic search.
+func (t *TestTarget) Query() string {
+	if t.File == "" {
+		if len(t.Symbols) > 0 && t.Symbols[0].Signature != "" {
+			return "test " + t.Symbols[0].Signature
+		}
+		return "test " + t.Name
+	}
+	parts := []string{"tests for", filepath.Base(t.File)}
+	for i, sym := range t.Symbols {
+		if i == 10 {
+			break
+		}
+		parts = append(parts, sym.Name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// SimilarTest is a chunk of test code found by semantic search for a
+// target, with its similarity.
+type SimilarTest struct {
+	File      string
+	StartLine int
+	EndLine   int
+	Score     float32
+}
+
+// TestCandidate is a test likely to cover a target.
+type TestCandidate struct {
+	Name    string   `json:"name,omitempty"` // test function, empty for a whole test file
+	File    string   `json:"file"`
+	Line    int      `json:"line,omitempty"`
+	Project string   `json:"project,omitempty"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// TestsForResult is the output of a tests-for query.
+type TestsForResult struct {
+	Target  string          `json:"target"`
+	File    string          `json:"file,omitempty"`    // set when the target is a file
+	Symbols []string        `json:"symbols,omitempty"` // target symbols whose references were followed
+	Total   int             `json:"total"`
+	Offset  int             `json:"offset,omitempty"`
+	Tests   []TestCandidate `json:"tests"`
+}
+
+// ResolveTestTarget resolves query to an indexed file or, failing that, to
+// a symbol, using the matching of trace commands.
+func ResolveTestTarget(ctx context.Context, stores []SymbolStore, query string) (*TestTarget, error) {
+	file := filepath.ToSlash(filepath.Clean(query))
+	for _, ss := range stores {
+		if !ss.IsFileIndexed(file) {
+			continue
+		}
+		target := &TestTarget{Name: file, File: file}
+		for _, s := range stores {
+			symbols, err := s.GetSymbolsForFile(ctx, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get symbols: %w", err)
+			}
+			for _, sym := range symbols {
+				if sym.Kind == KindVariable || sym.Kind == KindConstant || sym.Abstract {
+					continue
+				}
+				if sym.Kind == KindMethod && implicitMethods[sym.Name] {
+					continue
+				}
+				target.Symbols = append(target.Symbols, sym)
+			}
+		}
+		return target, nil
+	}
+
+	res, err := ResolveSymbol(ctx, stores, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve symbol: %w", err)
+	}
+	if res.Ambiguous() {
+		names := make([]string, len(res.Candidates))
+		for i, c := range res.Candidates {
+			names[i] = c.Symbol.Name
+		}
+		return nil, fmt.Errorf("%q is ambiguous: did you mean %s?", query, strings.Join(names, ", "))
+	}
+	if res.Name == "" {
+		return nil, fmt.Errorf("no file or symbol %q in the symbol index", query)
+	}
+
+	target := &TestTarget{Name: res.Name}
+	if res.Symbol != nil {
+		target.Symbols = []Symbol{*res.Symbol}
+		return target, nil
+	}
+	for _, ss := range stores {
+		symbols, err := ss.LookupSymbol(ctx, res.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up symbol: %w", err)
+		}
+		target.Symbols = append(target.Symbols, symbols...)
+	}
+	return target, nil
+}
+
+// testCollector accumulates test candidates, keeping the best weight of
+// each reason per test.
+type testCollector struct {
+	tests   map[string]*TestCandidate
+	weights map[string]map[string]float64
+}
+
+func (c *testCollector) add(project, file, name string, line int, reason string, weight float64) {
+	key := project + "\x00" + file + "\x00" + name
+	test := c.tests[key]
+	if test == nil {
+		test = &TestCandidate{Name: name, File: file, Line: line, Project: project}
+		c.tests[key] = test
+		c.weights[key] = make(map[string]float64)
+	}
+	if test.Line == 0 || line > 0 && line < test.Line {
+		test.Line = line
+	}
+	if weight > c.weights[key][reason] {
+		c.weights[key][reason] = weight
+	}
+}
+
+// FindTestsFor returns the tests most likely to cover target, best first.
+// Tests are found by name, by test file name, by references to the target
+// or to its callers, and in similar, the result of a semantic search for
+// the target's tests; each signal adds to a test's score.
+func FindTestsFor(ctx context.Context, stores []SymbolStore, target *TestTarget, similar []SimilarTest, page PageOptions) (*TestsForResult, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	c := &testCollector{tests: make(map[string]*TestCandidate), weights: make(map[string]map[string]float64)}
+
+	names := make(map[string]bool)
+	var subjects []string
+	for _, sym := range target.Symbols {
+		names[sym.Name] = true
+		if sym.Receiver != "" {
+			subjects = append(subjects, strings.ToLower(strings.TrimLeft(sym.Receiver, "*")+sym.Name))
+		}
+		subjects = append(subjects, strings.ToLower(sym.Name))
+	}
+	targetFiles := make(map[string]bool)
+	if target.File != "" {
+		targetFiles[target.File] = true
+	}
+	for _, sym := range target.Symbols {
+		targetFiles[sym.File] = true
+	}
+
+	// Test functions named after the target, and test files named after
+	// the target's files.
+	testFiles := make(map[string]string)
+	for _, ss := range stores {
+		symbols, err := ss.ListSymbols(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list symbols: %w", err)
+		}
+		project := ProjectOf(ss)
+		for _, sym := range symbols {
+			if !IsTestFile(sym.File) || sym.Kind != KindFunction && sym.Kind != KindMethod {
+				continue
+			}
+			testFiles[sym.File] = project
+			if weight := testNameWeightFor(sym.Name, subjects); weight > 0 {
+				c.add(project, sym.File, sym.Name, sym.Line, TestReasonName, weight)
+			}
+		}
+	}
+
+	// Tests referencing the target, or calling one of its callers.
+	callers := make(map[string]bool)
+	var callerNames []string
+	for name := range names {
+		for _, ss := range stores {
+			refs, err := ss.LookupReferences(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up references: %w", err)
+			}
+			project := ProjectOf(ss)
+			for _, ref := range refs {
+				if ref.Kind != "" && ref.Kind != RefKindCall && !IsUsageReferenceKind(ref.Kind) {
+					continue
+				}
+				if IsTestFile(ref.File) {
+					testFiles[ref.File] = project
+					c.add(project, ref.File, testCallerName(ref), ref.CallerLine, TestReasonCalls, testCallsWeight)
+					continue
+				}
+				if ref.CallerName != "" && ref.CallerName != "<top-level>" && !names[ref.CallerName] && !callers[ref.CallerName] {
+					callers[ref.CallerName] = true
+					callerNames = append(callerNames, ref.CallerName)
+				}
+			}
+		}
+	}
+	sort.Strings(callerNames)
+	if len(callerNames) > maxIndirectCallers {
+		callerNames = callerNames[:maxIndirectCallers]
+	}
+	for _, name := range callerNames {
+		for _, ss := range stores {
+			refs, err := ss.LookupCallers(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up callers: %w", err)
+			}
+			project := ProjectOf(ss)
+			for _, ref := range refs {
+				if IsTestFile(ref.File) {
+					testFiles[ref.File] = project
+					c.add(project, ref.File, testCallerName(ref), ref.CallerLine, TestReasonIndirect, testIndirectWeight)
+				}
+			}
+		}
+	}
+
+	// Test code similar to the target.
+	for _, hit := range similar {
+		if !IsTestFile(hit.File) {
+			continue
+		}
+		for _, ss := range stores {
+			if !ss.IsFileIndexed(hit.File) {
+				continue
+			}
+			symbols, err := ss.GetSymbolsForFile(ctx, hit.File)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get symbols: %w", err)
+			}
+			name, line := "", 0
+			if sym := EnclosingSymbol(symbols, hit.StartLine, hit.EndLine); sym != nil {
+				name, line = sym.Name, sym.Line
+			}
+			testFiles[hit.File] = ProjectOf(ss)
+			c.add(ProjectOf(ss), hit.File, name, line, TestReasonSimilar, testSimilarWeight*float64(hit.Score))
+			break
+		}
+	}
+
+	// A test file named after a target file backs the tests found in it,
+	// and is reported as a whole when none were.
+	for file, project := range testFiles {
+		weight := testFileWeightFor(file, targetFiles)
+		if weight == 0 {
+			continue
+		}
+		found := false
+		for key, test := range c.tests {
+			if test.Project == project && test.File == file {
+				found = true
+				if weight > c.weights[key][TestReasonFile] {
+					c.weights[key][TestReasonFile] = weight
+				}
+			}
+		}
+		if !found {
+			c.add(project, file, "", 0, TestReasonFile, weight)
+		}
+	}
+
+	tests := make([]TestCandidate, 0, len(c.tests))
+	for key, test := range c.tests {
+		for _, reason := range []string{TestReasonName, TestReasonFile, TestReasonCalls, TestReasonIndirect, TestReasonSimilar} {
+			if weight, ok := c.weights[key][reason]; ok {
+				test.Score += weight
+				test.Reasons = append(test.Reasons, reason)
+			}
+		}
+		tests = append(tests, *test)
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		a, b := tests[i], tests[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	result := &TestsForResult{Target: target.Name, File: target.File, Total: len(tests), Tests: []TestCandidate{}}
+	for name := range names {
+		result.Symbols = append(result.Symbols, name)
+	}
+	sort.Strings(result.Symbols)
+	if paged := pageSlice(tests, page); paged != nil {
+		result.Tests = paged
+	}
+	if result.Total > 0 {
+		result.Offset = page.Offset
+	}
+	return result, nil
+}
+
+// testCallerName returns the test function making ref, or "" when it is
+// made at the top level of the test file.
+func testCallerName(ref Reference) string {
+	if ref.CallerName == "<top-level>" {
+		return ""
+	}
+	return ref.CallerName
+}
+
+// testNameWeightFor returns the weight of a test named name for a target
+// with the given lowercase subjects (Name and ReceiverName): a test named
+// after a subject, such as TestServer_Start or test_start, or one starting
+// with it, such as TestStartTwice.
+func testNameWeightFor(name string, subjects []string) float64 {
+	norm := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	norm = strings.TrimPrefix(norm, "test")
+	norm = strings.TrimSuffix(norm, "test")
+	if norm == "" {
+		return 0
+	}
+	weight := 0.0
+	for _, subject := range subjects {
+		switch {
+		case norm == subject:
+			return testNameWeight
+		case len(subject) >= 4 && strings.HasPrefix(norm, subject):
+			weight = testNamePrefixWeight
+		}
+	}
+	return weight
+}
+
+// testFileWeightFor returns the weight of a test file for the target files:
+// foo_test.go, foo.test.ts, test_foo.py or FooTest.java for foo.go, foo.ts,
+// foo.py or Foo.java, with less weight in another directory.
+func testFileWeightFor(testFile string, targetFiles map[string]bool) float64 {
+	subject := testFileSubject(testFile)
+	weight := 0.0
+	for file := range targetFiles {
+		if IsTestFile(file) || fileStem(file) != subject {
+			continue
+		}
+		if filepath.Dir(file) == filepath.Dir(testFile) {
+			return testFileWeight
+		}
+		weight = testFileOtherWeight
+	}
+	return weight
+}
+
+// testFileSubject returns the lowercase stem of the file a test file is
+// named after.
+func testFileSubject(file string) string {
+	stem := fileStem(file)
+	for _, suffix := range []string{".test", ".spec", "_test", "_spec", "-test", "-spec", "tests", "test", "spec"} {
+		if s := strings.TrimSuffix(stem, suffix); s != stem && s != "" {
+			stem = s
+			break
+		}
+	}
+	return strings.TrimPrefix(stem, "test_")
+}
+
+// fileStem returns the lowercase base name of file without its extension.
+func fileStem(file string) string {
+	base := strings.ToLower(filepath.Base(file))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
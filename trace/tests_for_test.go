@@ -0,0 +1,145 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+func newTestsForStore(t *testing.T) SymbolStore {
+	t.Helper()
+	ctx := context.Background()
+	store := NewGOBSymbolStore(t.TempDir() + "/symbols.gob")
+	files := []struct {
+		path    string
+		symbols []Symbol
+		refs    []Reference
+	}{
+		{
+			path: "server/server.go",
+			symbols: []Symbol{
+				{Name: "Start", Kind: KindMethod, Receiver: "*Server", File: "server/server.go", Line: 10, Signature: "func (s *Server) Start() error", Language: "go"},
+				{Name: "Run", Kind: KindFunction, File: "server/server.go", Line: 30, Language: "go"},
+			},
+			refs: []Reference{
+				{SymbolName: "Start", Kind: RefKindCall, File: "server/server.go", Line: 31, CallerName: "Run", CallerFile: "server/server.go", CallerLine: 30},
+			},
+		},
+		{
+			path: "server/server_test.go",
+			symbols: []Symbol{
+				{Name: "TestServer_Start", Kind: KindFunction, File: "server/server_test.go", Line: 5, Language: "go"},
+				{Name: "TestRun", Kind: KindFunction, File: "server/server_test.go", Line: 20, Language: "go"},
+				{Name: "TestShutdown", Kind: KindFunction, File: "server/server_test.go", Line: 40, Language: "go"},
+			},
+			refs: []Reference{
+				{SymbolName: "Start", Kind: RefKindCall, File: "server/server_test.go", Line: 7, CallerName: "TestServer_Start", CallerFile: "server/server_test.go", CallerLine: 5},
+				{SymbolName: "Run", Kind: RefKindCall, File: "server/server_test.go", Line: 22, CallerName: "TestRun", CallerFile: "server/server_test.go", CallerLine: 20},
+			},
+		},
+		{
+			path: "web/app.test.ts",
+			refs: []Reference{
+				{SymbolName: "Start", Kind: RefKindCall, File: "web/app.test.ts", Line: 3, CallerName: "<top-level>", CallerFile: "web/app.test.ts"},
+			},
+		},
+	}
+	for _, f := range files {
+		if err := store.SaveFile(ctx, f.path, f.symbols, f.refs); err != nil {
+			t.Fatalf("failed to save file: %v", err)
+		}
+	}
+	return store
+}
+
+func TestFindTestsFor_Symbol(t *testing.T) {
+	ctx := context.Background()
+	stores := []SymbolStore{newTestsForStore(t)}
+
+	target, err := ResolveTestTarget(ctx, stores, "Server.Start")
+	if err != nil {
+		t.Fatalf("ResolveTestTarget failed: %v", err)
+	}
+	if target.File != "" || len(target.Symbols) != 1 || target.Query() != "test func (s *Server) Start() error" {
+		t.Fatalf("expected the Start method, got %+v", target)
+	}
+
+	similar := []SimilarTest{{File: "server/server_test.go", StartLine: 41, EndLine: 45, Score: 0.5}, {File: "server/server.go", StartLine: 10, EndLine: 12, Score: 0.9}}
+	result, err := FindTestsFor(ctx, stores, target, similar, PageOptions{})
+	if err != nil {
+		t.Fatalf("FindTestsFor failed: %v", err)
+	}
+	if result.Total != 4 {
+		t.Fatalf("expected 4 tests, got %+v", result.Tests)
+	}
+	want := []struct {
+		name    string
+		reasons []string
+	}{
+		{"TestServer_Start", []string{TestReasonName, TestReasonFile, TestReasonCalls}},
+		{"", []string{TestReasonCalls}},
+		{"TestRun", []string{TestReasonFile, TestReasonIndirect}},
+		{"TestShutdown", []string{TestReasonFile, TestReasonSimilar}},
+	}
+	for i, w := range want {
+		got := result.Tests[i]
+		if got.Name != w.name || len(got.Reasons) != len(w.reasons) {
+			t.Errorf("test %d: expected %s with %v, got %+v", i, w.name, w.reasons, got)
+			continue
+		}
+		for j, reason := range w.reasons {
+			if got.Reasons[j] != reason {
+				t.Errorf("test %d: expected reasons %v, got %v", i, w.reasons, got.Reasons)
+			}
+		}
+	}
+	if result.Tests[1].File != "web/app.test.ts" {
+		t.Errorf("expected the whole TypeScript test file, got %+v", result.Tests[1])
+	}
+}
+
+func TestFindTestsFor_File(t *testing.T) {
+	ctx := context.Background()
+	stores := []SymbolStore{newTestsForStore(t)}
+
+	target, err := ResolveTestTarget(ctx, stores, "./server/server.go")
+	if err != nil {
+		t.Fatalf("ResolveTestTarget failed: %v", err)
+	}
+	if target.File != "server/server.go" || len(target.Symbols) != 2 {
+		t.Fatalf("expected the file with its two functions, got %+v", target)
+	}
+
+	result, err := FindTestsFor(ctx, stores, target, nil, PageOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("FindTestsFor failed: %v", err)
+	}
+	if result.Total != 3 || len(result.Tests) != 2 {
+		t.Fatalf("expected 2 of 3 tests, got %+v", result)
+	}
+	if result.Tests[0].Name != "TestServer_Start" || result.Tests[1].Name != "TestRun" {
+		t.Errorf("expected TestServer_Start then TestRun, got %+v", result.Tests)
+	}
+
+	if _, err := ResolveTestTarget(ctx, stores, "missing.go"); err == nil {
+		t.Error("expected an error for an unknown target")
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	for file, want := range map[string]bool{
+		"pkg/server_test.go":            true,
+		"src/app.spec.ts":               true,
+		"tests/test_api.py":             true,
+		"spec/user_spec.rb":             true,
+		"src/__tests__/app.js":          true,
+		"src/test/UserServiceTest.java": true,
+		"Tests/ParserTests.cs":          true,
+		"pkg/server.go":                 false,
+		"src/latest.java":               false,
+		"src/Contest.py":                false,
+	} {
+		if got := IsTestFile(file); got != want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", file, got, want)
+		}
+	}
+}
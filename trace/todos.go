@@ -0,0 +1,290 @@
+package trace
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+	"github.com/yoanbernabeu/grepai/internal/indexformat"
+	"github.com/yoanbernabeu/grepai/internal/langdetect"
+)
+
+// TodoTags lists the comment tags recorded as todos.
+var TodoTags = []string{"TODO", "FIXME", "HACK", "XXX"}
+
+// todoRe matches a tag, an optional "(owner)" and the rest of the line.
+var todoRe = regexp.MustCompile(`\b(TODO|FIXME|HACK|XXX)\b(?:\([^)\n]*\))?:?[ \t]*([^\n]*)`)
+
+// Todo is a TODO, FIXME, HACK or XXX comment.
+type Todo struct {
+	Tag    string `json:"tag"`
+	Text   string `json:"text"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Symbol string `json:"symbol,omitempty"` // enclosing function, empty at top level
+	// Set by callers: the last author of the line from git blame, and the
+	// semantic similarity to a search query.
+	Owner string  `json:"owner,omitempty"`
+	Score float32 `json:"score,omitempty"`
+	// Vector is the embedding of EmbeddingText, set by the indexer.
+	Vector []float32 `json:"-"`
+}
+
+// EmbeddingText returns the text a todo is embedded from.
+func (t Todo) EmbeddingText() string {
+	return t.Tag + ": " + t.Text
+}
+
+// ExtractTodos returns the TODO, FIXME, HACK and XXX comments of a file in
+// any language. Comments are recognized with the syntax of the file's
+// language, // and /* */ when it is not known, and the enclosing function is
+// set for languages the extractor parses. Tags must be uppercase.
+func (e *RegexExtractor) ExtractTodos(filePath, content string) []Todo {
+	matches := todoRe.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	ext := langdetect.Ext(filePath, content)
+	lang := langdetect.Language(ext)
+	var boundaries []functionBoundary
+	if patterns := e.patterns[ext]; patterns != nil {
+		lang = patterns.Language
+		if isSpanLanguage(lang) {
+			boundaries = spanBoundaries(content, spanDecls(content, lang))
+		} else {
+			boundaries = e.buildFunctionBoundaries(content, patterns)
+		}
+	}
+	_, comments := scanStringLiterals(content, lang)
+
+	var todos []Todo
+	for _, m := range matches {
+		if !comments[m[0]] {
+			continue
+		}
+		text := content[m[4]:m[5]]
+		if end := strings.Index(text, "*/"); end >= 0 {
+			text = text[:end]
+		}
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "-->"))
+		tag := content[m[2]:m[3]]
+		if text == "" {
+			text = tag
+		}
+		todo := Todo{Tag: tag, Text: text, File: filePath, Line: countLines(content[:m[0]]) + 1}
+		if caller := findContainingFunction(m[0], boundaries); caller.Name != "<top-level>" {
+			todo.Symbol = caller.Name
+		}
+		todos = append(todos, todo)
+	}
+	return todos
+}
+
+// ParseTodoTags parses a comma-separated list of todo tags,
+// case-insensitively. An empty list selects every tag.
+func ParseTodoTags(list string) ([]string, error) {
+	var tags []string
+	for _, t := range strings.Split(list, ",") {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		known := false
+		for _, tt := range TodoTags {
+			if t == tt {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("invalid tag %q: must be one of %s", t, strings.Join(TodoTags, ", "))
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// todoFormatVersion is the format version of the files TodoStore writes.
+const todoFormatVersion = 1
+
+type gobTodoData struct {
+	FormatVersion int
+	Files         map[string]todoFile
+}
+
+// todoFile is the todos of a file and the hash of the content they were
+// extracted from.
+type todoFile struct {
+	Hash  string
+	Todos []Todo
+}
+
+// TodoStore is the todo index of a project: the todos of every indexed
+// file with their embeddings, so that a search only embeds its query. The
+// indexer fills it as it indexes files. It is safe for concurrent use.
+type TodoStore struct {
+	path string
+
+	mu     sync.RWMutex
+	files  map[string]todoFile
+	exists bool // the index was loaded from or written to disk
+	dirty  bool
+	tooNew error // see indexformat.ErrTooNew
+}
+
+// NewTodoStore creates a todo store kept at path.
+func NewTodoStore(path string) *TodoStore {
+	return &TodoStore{path: path, files: make(map[string]todoFile)}
+}
+
+// Load reads the index from disk. A missing file leaves the store empty.
+func (s *TodoStore) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open todo index: %w", err)
+	}
+	defer file.Close()
+
+	var data gobTodoData
+	if err := gob.NewDecoder(file).Decode(&data); err != nil {
+		return fmt.Errorf("failed to decode todo index: %w", err)
+	}
+	if err := indexformat.Check(s.path, data.FormatVersion, todoFormatVersion); err != nil {
+		if errors.Is(err, indexformat.ErrTooNew) {
+			s.tooNew = err
+		}
+		return err
+	}
+	s.files = data.Files
+	if s.files == nil {
+		s.files = make(map[string]todoFile)
+	}
+	s.exists = true
+	return nil
+}
+
+// Persist writes the index to disk when it changed since it was loaded or
+// last written.
+func (s *TodoStore) Persist(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	if s.tooNew != nil {
+		return s.tooNew
+	}
+	if err := fileutil.EnsureParentDir(s.path); err != nil {
+		return fmt.Errorf("failed to prepare todo index directory: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create todo index temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	data := gobTodoData{FormatVersion: todoFormatVersion, Files: s.files}
+	if err := gob.NewEncoder(tmpFile).Encode(data); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to encode todo index: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close todo index temp file: %w", err)
+	}
+	if err := fileutil.ReplaceFileAtomically(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace todo index file: %w", err)
+	}
+	s.exists = true
+	s.dirty = false
+	return nil
+}
+
+// Exists reports whether the index was built: loaded from or written to
+// disk.
+func (s *TodoStore) Exists() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.exists
+}
+
+// HasFile reports whether the todos of a file are in the index.
+func (s *TodoStore) HasFile(path string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.files[path]
+	return ok
+}
+
+// Current reports whether the index holds the todos of a file's content
+// with the given hash.
+func (s *TodoStore) Current(path, hash string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.files[path]
+	return ok && f.Hash == hash
+}
+
+// SaveFile replaces the todos of a file, extracted from its content with
+// the given hash.
+func (s *TodoStore) SaveFile(path, hash string, todos []Todo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[path] = todoFile{Hash: hash, Todos: todos}
+	s.dirty = true
+}
+
+// DeleteFile removes the todos of a file.
+func (s *TodoStore) DeleteFile(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[path]; ok {
+		delete(s.files, path)
+		s.dirty = true
+	}
+}
+
+// Todos returns the todos of the index, sorted by file and line. tags
+// restricts the result to the given tags; empty means all.
+func (s *TodoStore) Todos(tags []string) []Todo {
+	wanted := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		wanted[t] = true
+	}
+
+	s.mu.RLock()
+	todos := []Todo{}
+	for _, f := range s.files {
+		for _, todo := range f.Todos {
+			if len(wanted) == 0 || wanted[todo.Tag] {
+				todos = append(todos, todo)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(todos, func(i, j int) bool {
+		if todos[i].File != todos[j].File {
+			return todos[i].File < todos[j].File
+		}
+		return todos[i].Line < todos[j].Line
+	})
+	return todos
+}
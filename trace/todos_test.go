@@ -0,0 +1,89 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractTodos(t *testing.T) {
+	content := `package retry
+
+// TODO(alice): replace the fixed sleep with backoff
+func Do(fn func() error) error {
+	msg := "TODO: not a comment"
+	/* FIXME handle context cancellation */
+	return fn() // HACK: ignore errors for now
+}
+`
+	todos := NewRegexExtractor().ExtractTodos("retry.go", content)
+	want := []Todo{
+		{Tag: "TODO", Text: "replace the fixed sleep with backoff", File: "retry.go", Line: 3},
+		{Tag: "FIXME", Text: "handle context cancellation", File: "retry.go", Line: 6, Symbol: "Do"},
+		{Tag: "HACK", Text: "ignore errors for now", File: "retry.go", Line: 7, Symbol: "Do"},
+	}
+	if len(todos) != len(want) {
+		t.Fatalf("expected %d todos, got %+v", len(want), todos)
+	}
+	for i, todo := range todos {
+		if todo.Tag != want[i].Tag || todo.Text != want[i].Text || todo.File != want[i].File || todo.Line != want[i].Line || todo.Symbol != want[i].Symbol {
+			t.Errorf("todo %d = %+v, want %+v", i, todo, want[i])
+		}
+	}
+
+	sql := "-- TODO: index orders.customer_id\nCREATE TABLE orders (id int);\n"
+	if todos := NewRegexExtractor().ExtractTodos("schema.sql", sql); len(todos) != 1 || todos[0].Text != "index orders.customer_id" {
+		t.Errorf("expected the SQL todo, got %+v", todos)
+	}
+
+	// Languages without symbol extraction are covered too.
+	yaml := "retries: 3 # FIXME: read from the environment\n"
+	if todos := NewRegexExtractor().ExtractTodos("deploy.yaml", yaml); len(todos) != 1 || todos[0].Tag != "FIXME" || todos[0].Symbol != "" {
+		t.Errorf("expected the YAML todo, got %+v", todos)
+	}
+}
+
+func TestTodoStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "todos.gob")
+	store := NewTodoStore(path)
+	store.SaveFile("b.go", "h1", []Todo{
+		{Tag: "FIXME", Text: "handle cancellation", File: "b.go", Line: 9, Symbol: "Do", Vector: []float32{1, 0}},
+	})
+	store.SaveFile("a.go", "h2", []Todo{
+		{Tag: "TODO", Text: "use backoff", File: "a.go", Line: 3, Vector: []float32{0, 1}},
+	})
+	store.SaveFile("c.go", "h3", nil)
+	if err := store.Persist(ctx); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	loaded := NewTodoStore(path)
+	if err := loaded.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !loaded.Exists() || !loaded.Current("c.go", "h3") || loaded.Current("a.go", "h1") {
+		t.Error("expected the files and hashes to be loaded")
+	}
+	todos := loaded.Todos(nil)
+	if len(todos) != 2 || todos[0].File != "a.go" || todos[1].Symbol != "Do" || len(todos[1].Vector) != 2 {
+		t.Fatalf("expected both todos sorted by file with their vectors, got %+v", todos)
+	}
+
+	tags, err := ParseTodoTags("fixme")
+	if err != nil {
+		t.Fatalf("ParseTodoTags failed: %v", err)
+	}
+	if todos := loaded.Todos(tags); len(todos) != 1 || todos[0].Tag != "FIXME" {
+		t.Errorf("expected only the FIXME, got %+v", todos)
+	}
+
+	loaded.DeleteFile("b.go")
+	if loaded.HasFile("b.go") || len(loaded.Todos(nil)) != 1 {
+		t.Error("expected the file's todos to be removed")
+	}
+
+	if _, err := ParseTodoTags("NOTE"); err == nil {
+		t.Error("expected an error for an unknown tag")
+	}
+}
@@ -3,7 +3,11 @@ package trace
 
 import (
 	"context"
+	"strings"
 	"time"
+
+	"github.com/yoanbernabeu/grepai/coverage"
+	"github.com/yoanbernabeu/grepai/git"
 )
 
 // SymbolKind represents the type of symbol.
@@ -33,6 +37,20 @@ type Symbol struct {
 	Language    string     `json:"language"`
 	Docstring   string     `json:"docstring,omitempty"`    // Documentation/comment for the symbol
 	FeaturePath string     `json:"feature_path,omitempty"` // RPG semantic hierarchy path (populated when RPG enabled)
+	Abstract    bool       `json:"abstract,omitempty"`     // Method declared by an interface (Receiver is the interface)
+
+	Owners     string             `json:"owners,omitempty"`      // CODEOWNERS owners of the symbol's file, space-separated
+	LastChange *git.LastChange    `json:"last_change,omitempty"` // Last commit touching the symbol (populated with --blame)
+	Coverage   *coverage.Coverage `json:"coverage,omitempty"`    // Line coverage of the symbol (populated from an imported coverage profile)
+}
+
+// Declaration returns the signature of the symbol without the brace opening
+// its body, or its kind and name when the extractor recorded none.
+func (s Symbol) Declaration() string {
+	if sig := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s.Signature), "{")); sig != "" {
+		return sig
+	}
+	return string(s.Kind) + " " + s.Name
 }
 
 // Reference represents a usage/call of a symbol.
@@ -46,14 +64,40 @@ type Reference struct {
 	CallerName string `json:"caller_name"`
 	CallerFile string `json:"caller_file"`
 	CallerLine int    `json:"caller_line"`
+	// Category classifies literal references (route, sql, env or flag),
+	// and holds the HTTP method of route references.
+	Category string `json:"category,omitempty"`
+	// Handler is the handler function of route references.
+	Handler string `json:"handler,omitempty"`
 }
 
 const (
 	RefKindCall  = "call"
 	RefKindRead  = "read"
 	RefKindWrite = "write"
+
+	// Usage reference kinds record non-call uses of a symbol. They do not
+	// add call graph edges.
+	RefKindType        = "type"        // the symbol is used as a type
+	RefKindInstantiate = "instantiate" // a struct literal or new expression creates the symbol
+	RefKindImport      = "import"      // an import brings the symbol or package into scope
+
+	// RefKindLiteral records a notable string literal, such as a URL route
+	// or an environment variable name, under its value. Literal references
+	// are kept apart from symbol references; see FindLiterals.
+	RefKindLiteral = "literal"
+
+	// RefKindRoute records an HTTP endpoint under its path, with the method
+	// as category and the handler function; see FindRoutes.
+	RefKindRoute = "route"
 )
 
+// IsUsageReferenceKind reports whether kind is one of the usage reference
+// kinds.
+func IsUsageReferenceKind(kind string) bool {
+	return kind == RefKindType || kind == RefKindInstantiate || kind == RefKindImport
+}
+
 // CallEdge represents a caller -> callee relationship.
 type CallEdge struct {
 	Caller   string `json:"caller"`
@@ -67,6 +111,8 @@ type CallEdge struct {
 type SymbolIndex struct {
 	Symbols    map[string][]Symbol    `json:"symbols"`
 	References map[string][]Reference `json:"references"`
+	Literals   map[string][]Reference `json:"literals,omitempty"`
+	Routes     map[string][]Reference `json:"routes,omitempty"`
 	CallGraph  []CallEdge             `json:"call_graph"`
 	UpdatedAt  time.Time              `json:"updated_at"`
 	Version    int                    `json:"version"`
@@ -80,18 +126,27 @@ type TraceResult struct {
 	Callers []CallerInfo `json:"callers,omitempty"`
 	Callees []CalleeInfo `json:"callees,omitempty"`
 	Graph   *CallGraph   `json:"graph,omitempty"`
+	Total   int          `json:"total,omitempty"`  // Callers/callees before pagination
+	Offset  int          `json:"offset,omitempty"` // Index of the first returned caller/callee
+
+	Match      string        `json:"match,omitempty"`      // Set when the query was resolved by non-exact matching
+	Candidates []SymbolMatch `json:"candidates,omitempty"` // Competing symbols when the query is ambiguous
+
+	Implementations []Implementation `json:"implementations,omitempty"` // Concrete methods reached by dynamic dispatch
 }
 
 // CallerInfo represents a function that calls the target.
 type CallerInfo struct {
 	Symbol   Symbol   `json:"symbol"`
 	CallSite CallSite `json:"call_site"`
+	Project  string   `json:"project,omitempty"` // Workspace project (workspace mode only)
 }
 
 // CalleeInfo represents a function called by the target.
 type CalleeInfo struct {
 	Symbol   Symbol   `json:"symbol"`
 	CallSite CallSite `json:"call_site"`
+	Project  string   `json:"project,omitempty"` // Workspace project (workspace mode only)
 }
 
 // CallSite represents the location of a function call.
@@ -150,6 +205,10 @@ type SymbolStore interface {
 	// LookupSymbol finds symbol definitions by name.
 	LookupSymbol(ctx context.Context, name string) ([]Symbol, error)
 
+	// SearchSymbols finds symbols matching a query using qualified,
+	// case-insensitive, prefix and fuzzy matching. A limit <= 0 means no limit.
+	SearchSymbols(ctx context.Context, query string, limit int) ([]SymbolMatch, error)
+
 	// LookupCallers finds all references/callers of a symbol.
 	LookupCallers(ctx context.Context, symbolName string) ([]Reference, error)
 
@@ -162,6 +221,22 @@ type SymbolStore interface {
 	// LookupWriters finds property/data writers for a symbol name.
 	LookupWriters(ctx context.Context, symbolName string) ([]Reference, error)
 
+	// LookupReferences finds all references to a symbol name regardless of kind.
+	LookupReferences(ctx context.Context, symbolName string) ([]Reference, error)
+
+	// LookupImplementations finds concrete methods named methodName whose
+	// receiver type implements an interface declaring that method.
+	LookupImplementations(ctx context.Context, methodName string) ([]Implementation, error)
+
+	// ListSymbols returns every symbol definition in the index.
+	ListSymbols(ctx context.Context) ([]Symbol, error)
+
+	// ListLiterals returns every literal reference in the index.
+	ListLiterals(ctx context.Context) ([]Reference, error)
+
+	// ListRoutes returns every route reference in the index.
+	ListRoutes(ctx context.Context) ([]Reference, error)
+
 	// GetCallGraph builds a call graph from a starting symbol.
 	GetCallGraph(ctx context.Context, symbolName string, depth int) (*CallGraph, error)
 
@@ -0,0 +1,19 @@
+package trace
+
+import "testing"
+
+func TestSymbolDeclaration(t *testing.T) {
+	for _, tc := range []struct {
+		sym  Symbol
+		want string
+	}{
+		{Symbol{Name: "Login", Kind: KindFunction, Signature: "func Login(user string) error {"}, "func Login(user string) error"},
+		{Symbol{Name: "Session", Kind: KindClass, Signature: "  class Session  "}, "class Session"},
+		{Symbol{Name: "retries", Kind: KindVariable}, "variable retries"},
+		{Symbol{Name: "Store", Kind: KindInterface, Signature: "{"}, "interface Store"},
+	} {
+		if got := tc.sym.Declaration(); got != tc.want {
+			t.Errorf("Declaration() of %s = %q, want %q", tc.sym.Name, got, tc.want)
+		}
+	}
+}
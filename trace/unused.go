@@ -0,0 +1,208 @@
+package trace
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UnusedOptions configures dead code detection.
+type UnusedOptions struct {
+	// IncludeUnexported also reports unexported symbols.
+	IncludeUnexported bool
+	// IncludeEntryPoints disables the built-in entry-point filter.
+	IncludeEntryPoints bool
+	// IgnorePatterns are glob patterns matched against symbol names and
+	// file paths; matching symbols are never reported.
+	IgnorePatterns []string
+}
+
+// UnusedSymbol is a symbol with no references outside its own definition.
+type UnusedSymbol struct {
+	Symbol  Symbol `json:"symbol"`
+	Project string `json:"project,omitempty"`
+}
+
+// UnusedResult is the output of a dead code query.
+type UnusedResult struct {
+	Total   int            `json:"total"`
+	Symbols []UnusedSymbol `json:"symbols"`
+}
+
+// implicitMethods are invoked through interfaces or reflection and rarely
+// appear as direct call references.
+var implicitMethods = map[string]bool{
+	"String": true, "Error": true, "Format": true, "GoString": true,
+	"MarshalJSON": true, "UnmarshalJSON": true, "MarshalText": true, "UnmarshalText": true,
+	"MarshalYAML": true, "UnmarshalYAML": true, "MarshalBinary": true, "UnmarshalBinary": true,
+	"Len": true, "Less": true, "Swap": true, "Unwrap": true, "Is": true, "As": true,
+	"ServeHTTP": true, "Read": true, "Write": true, "Close": true,
+}
+
+// handlerSignatureHints mark functions that are registered as HTTP handlers
+// rather than called directly.
+var handlerSignatureHints = []string{
+	"http.ResponseWriter",
+	"*http.Request",
+	"gin.Context",
+	"echo.Context",
+	"fiber.Ctx",
+}
+
+// IsEntryPoint reports whether sym is likely invoked by a runtime, test
+// runner or framework rather than by code in the index.
+func IsEntryPoint(sym Symbol) bool {
+	switch sym.Name {
+	case "main", "init", "__init__", "__main__", "TestMain":
+		return true
+	}
+	if IsTestFile(sym.File) {
+		return true
+	}
+	// Make targets are run from the command line; define macros are not.
+	if sym.Language == "make" && sym.Kind == KindFunction && !strings.HasPrefix(sym.Signature, "define") {
+		return true
+	}
+	// SQL triggers are fired by the database.
+	if sym.Language == "sql" && sqlKindOf(sym.Signature) == "TRIGGER" {
+		return true
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+		if strings.HasPrefix(sym.Name, prefix) && sym.Language == "go" {
+			return true
+		}
+	}
+	if sym.Kind == KindMethod && implicitMethods[sym.Name] {
+		return true
+	}
+	for _, hint := range handlerSignatureHints {
+		if strings.Contains(sym.Signature, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTestFile reports whether file holds tests, judging by its name.
+func IsTestFile(file string) bool {
+	name := filepath.Base(file)
+	base := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(base, "_test.go"),
+		strings.Contains(base, ".test."),
+		strings.Contains(base, ".spec."),
+		strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py"),
+		strings.HasSuffix(base, "_test.py"),
+		strings.HasSuffix(base, "_test.rb"),
+		strings.HasSuffix(base, "_spec.rb"),
+		strings.Contains("/"+filepath.ToSlash(file), "/__tests__/"):
+		return true
+	}
+	switch ext := filepath.Ext(name); ext {
+	case ".java", ".kt", ".cs", ".scala":
+		stem := strings.TrimSuffix(name, ext)
+		return strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests") || strings.HasSuffix(stem, "Spec")
+	}
+	return false
+}
+
+func matchesIgnorePattern(sym Symbol, patterns []string) bool {
+	file := filepath.ToSlash(sym.File)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, sym.Name); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FindUnused reports functions and methods that are never referenced.
+// References are aggregated across all stores, so in workspace mode a symbol
+// used only by another project is not reported. References located at the
+// symbol's own definition or coming from its own body (recursion) are ignored.
+func FindUnused(ctx context.Context, stores []SymbolStore, opts UnusedOptions) (UnusedResult, error) {
+	refsByName := make(map[string][]Reference)
+	lookupRefs := func(name string) ([]Reference, error) {
+		if refs, ok := refsByName[name]; ok {
+			return refs, nil
+		}
+		var all []Reference
+		for _, ss := range stores {
+			refs, err := ss.LookupReferences(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, refs...)
+		}
+		refsByName[name] = all
+		return all, nil
+	}
+
+	result := UnusedResult{Symbols: []UnusedSymbol{}}
+	for _, ss := range stores {
+		symbols, err := ss.ListSymbols(ctx)
+		if err != nil {
+			return result, err
+		}
+		for _, sym := range symbols {
+			if sym.Kind != KindFunction && sym.Kind != KindMethod || sym.Abstract {
+				continue
+			}
+			if !opts.IncludeUnexported && !sym.Exported {
+				continue
+			}
+			if !opts.IncludeEntryPoints && IsEntryPoint(sym) {
+				continue
+			}
+			if matchesIgnorePattern(sym, opts.IgnorePatterns) {
+				continue
+			}
+
+			refs, err := lookupRefs(sym.Name)
+			if err != nil {
+				return result, err
+			}
+			if hasExternalReference(sym, refs) {
+				continue
+			}
+			result.Symbols = append(result.Symbols, UnusedSymbol{Symbol: sym, Project: ProjectOf(ss)})
+		}
+	}
+
+	sort.Slice(result.Symbols, func(i, j int) bool {
+		a, b := result.Symbols[i], result.Symbols[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Symbol.File != b.Symbol.File {
+			return a.Symbol.File < b.Symbol.File
+		}
+		if a.Symbol.Line != b.Symbol.Line {
+			return a.Symbol.Line < b.Symbol.Line
+		}
+		return a.Symbol.Name < b.Symbol.Name
+	})
+	result.Total = len(result.Symbols)
+	return result, nil
+}
+
+func hasExternalReference(sym Symbol, refs []Reference) bool {
+	for _, ref := range refs {
+		if ref.File == sym.File && ref.Line == sym.Line {
+			continue
+		}
+		if ref.CallerName == sym.Name && ref.CallerFile == sym.File {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package trace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindUnused(t *testing.T) {
+	ctx := context.Background()
+	store := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+
+	symbols := []Symbol{
+		{Name: "Used", Kind: KindFunction, File: "lib.go", Line: 1, Exported: true, Language: "go"},
+		{Name: "Dead", Kind: KindFunction, File: "lib.go", Line: 10, Exported: true, Language: "go"},
+		{Name: "Recursive", Kind: KindFunction, File: "lib.go", Line: 20, Exported: true, Language: "go"},
+		{Name: "helper", Kind: KindFunction, File: "lib.go", Line: 30, Exported: false, Language: "go"},
+		{Name: "String", Kind: KindMethod, File: "lib.go", Line: 40, Exported: true, Receiver: "T", Language: "go"},
+		{Name: "HandleIndex", Kind: KindFunction, File: "lib.go", Line: 50, Exported: true, Language: "go",
+			Signature: "func HandleIndex(w http.ResponseWriter, r *http.Request)"},
+		{Name: "Generated", Kind: KindFunction, File: "gen/api.go", Line: 1, Exported: true, Language: "go"},
+		{Name: "Config", Kind: KindType, File: "lib.go", Line: 60, Exported: true, Language: "go"},
+		{Name: "main", Kind: KindFunction, File: "main.go", Line: 1, Language: "go"},
+	}
+	refs := []Reference{
+		{SymbolName: "Used", File: "main.go", Line: 3, CallerName: "main", CallerFile: "main.go"},
+		{SymbolName: "Recursive", File: "lib.go", Line: 22, CallerName: "Recursive", CallerFile: "lib.go"},
+	}
+	if err := store.SaveFile(ctx, "lib.go", symbols, refs); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+
+	result, err := FindUnused(ctx, []SymbolStore{store}, UnusedOptions{IgnorePatterns: []string{"gen/*"}})
+	if err != nil {
+		t.Fatalf("FindUnused failed: %v", err)
+	}
+	got := unusedNames(result)
+	want := []string{"Dead", "Recursive"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unused = %v, want %v", got, want)
+	}
+	if result.Total != 2 {
+		t.Fatalf("total = %d, want 2", result.Total)
+	}
+
+	result, err = FindUnused(ctx, []SymbolStore{store}, UnusedOptions{IncludeUnexported: true, IncludeEntryPoints: true})
+	if err != nil {
+		t.Fatalf("FindUnused failed: %v", err)
+	}
+	if got := unusedNames(result); len(got) != 7 {
+		t.Fatalf("unused with all options = %v, want 7 symbols", got)
+	}
+}
+
+func TestFindUnusedAcrossProjects(t *testing.T) {
+	ctx := context.Background()
+	lib := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	app := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+
+	if err := lib.SaveFile(ctx, "lib.go", []Symbol{
+		{Name: "Shared", Kind: KindFunction, File: "lib.go", Line: 1, Exported: true, Language: "go"},
+	}, nil); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	if err := app.SaveFile(ctx, "app.go", nil, []Reference{
+		{SymbolName: "Shared", File: "app.go", Line: 5, CallerName: "Run", CallerFile: "app.go"},
+	}); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+
+	stores := []SymbolStore{&ProjectSymbolStore{SymbolStore: lib, Project: "lib"}}
+	result, err := FindUnused(ctx, stores, UnusedOptions{})
+	if err != nil {
+		t.Fatalf("FindUnused failed: %v", err)
+	}
+	if result.Total != 1 || result.Symbols[0].Project != "lib" {
+		t.Fatalf("expected Shared to be unused within lib only, got %+v", result)
+	}
+
+	stores = append(stores, &ProjectSymbolStore{SymbolStore: app, Project: "app"})
+	result, err = FindUnused(ctx, stores, UnusedOptions{})
+	if err != nil {
+		t.Fatalf("FindUnused failed: %v", err)
+	}
+	if result.Total != 0 {
+		t.Fatalf("expected no unused symbols in workspace, got %v", unusedNames(result))
+	}
+}
+
+func TestIsEntryPoint(t *testing.T) {
+	tests := []struct {
+		sym  Symbol
+		want bool
+	}{
+		{Symbol{Name: "init", Language: "go"}, true},
+		{Symbol{Name: "TestFoo", Language: "go"}, true},
+		{Symbol{Name: "Helper", File: "pkg/foo_test.go", Language: "go"}, true},
+		{Symbol{Name: "render", File: "ui/button.spec.ts", Language: "typescript"}, true},
+		{Symbol{Name: "Error", Kind: KindMethod, Language: "go"}, true},
+		{Symbol{Name: "Error", Kind: KindFunction, Language: "go"}, false},
+		{Symbol{Name: "Process", Language: "go"}, false},
+	}
+	for _, tt := range tests {
+		if got := IsEntryPoint(tt.sym); got != tt.want {
+			t.Errorf("IsEntryPoint(%+v) = %v, want %v", tt.sym, got, tt.want)
+		}
+	}
+}
+
+func unusedNames(result UnusedResult) []string {
+	names := make([]string, 0, len(result.Symbols))
+	for _, u := range result.Symbols {
+		names = append(names, u.Symbol.Name)
+	}
+	return names
+}
@@ -7,8 +7,26 @@ import (
 	"github.com/yoanbernabeu/grepai/config"
 )
 
+// ProjectSymbolStore is a SymbolStore tagged with the workspace project it
+// was loaded for.
+type ProjectSymbolStore struct {
+	SymbolStore
+	Project string
+	Root    string // project directory, empty when unknown
+}
+
+// ProjectOf returns the workspace project name of a store loaded by
+// LoadWorkspaceSymbolStores, or "" for plain stores.
+func ProjectOf(ss SymbolStore) string {
+	if ps, ok := ss.(*ProjectSymbolStore); ok {
+		return ps.Project
+	}
+	return ""
+}
+
 // LoadWorkspaceSymbolStores loads GOBSymbolStores for workspace projects.
 // If projectName is non-empty, only that project's store is loaded.
+// Each returned store is a *ProjectSymbolStore.
 func LoadWorkspaceSymbolStores(ctx context.Context, workspaceName, projectName string) ([]SymbolStore, error) {
 	wsCfg, err := config.LoadWorkspaceConfig()
 	if err != nil {
@@ -48,11 +66,26 @@ func LoadWorkspaceSymbolStores(ctx context.Context, workspaceName, projectName s
 			CloseSymbolStores(stores)
 			return nil, fmt.Errorf("failed to load symbol index for project %s: %w", p.Name, err)
 		}
-		stores = append(stores, ss)
+		stores = append(stores, &ProjectSymbolStore{SymbolStore: ss, Project: p.Name, Root: p.Path})
 	}
 	return stores, nil
 }
 
+// ReleaseSymbolStores releases symbol stores that were only read: GOB
+// stores are released without persisting them, others are closed.
+func ReleaseSymbolStores(stores []SymbolStore) {
+	for _, s := range stores {
+		if ps, ok := s.(*ProjectSymbolStore); ok {
+			s = ps.SymbolStore
+		}
+		if gs, ok := s.(*GOBSymbolStore); ok {
+			gs.Release()
+			continue
+		}
+		s.Close()
+	}
+}
+
 // CloseSymbolStores closes all symbol stores in the slice.
 func CloseSymbolStores(stores []SymbolStore) {
 	for _, s := range stores {
@@ -0,0 +1,222 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// backend delivers file system events for the directories added to it.
+// Like inotify, a directory's events cover its direct entries only.
+type backend interface {
+	Add(dir string) error
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+	Close() error
+}
+
+// errWatchLimit is returned by a backend that cannot watch more directories.
+var errWatchLimit = errors.New("file watch limit reached")
+
+// notifyBackend uses the platform's native events through fsnotify.
+type notifyBackend struct {
+	fsw        *fsnotify.Watcher
+	maxWatches int // 0 means the OS limit
+	watches    int
+}
+
+func newNotifyBackend(maxWatches int) (*notifyBackend, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &notifyBackend{fsw: fsw, maxWatches: maxWatches}, nil
+}
+
+func (b *notifyBackend) Add(dir string) error {
+	if b.maxWatches > 0 && b.watches >= b.maxWatches {
+		return errWatchLimit
+	}
+	if err := b.fsw.Add(dir); err != nil {
+		// inotify reports an exhausted fs.inotify.max_user_watches as ENOSPC,
+		// kqueue runs out of file descriptors.
+		if errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE) {
+			return errWatchLimit
+		}
+		return err
+	}
+	b.watches++
+	return nil
+}
+
+func (b *notifyBackend) Events() <-chan fsnotify.Event { return b.fsw.Events }
+func (b *notifyBackend) Errors() <-chan error          { return b.fsw.Errors }
+func (b *notifyBackend) Close() error                  { return b.fsw.Close() }
+
+// pollEntry is the state of a directory entry at the last scan.
+type pollEntry struct {
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+// pollBackend detects changes by listing the watched directories at a fixed
+// interval. It works where native events are unreliable or missing, such as
+// network filesystems and bind mounts in containers.
+type pollBackend struct {
+	interval time.Duration
+	events   chan fsnotify.Event
+	errors   chan error
+	done     chan struct{}
+	once     sync.Once
+
+	mu   sync.Mutex
+	dirs map[string]map[string]pollEntry
+}
+
+func newPollBackend(interval time.Duration) *pollBackend {
+	b := &pollBackend{
+		interval: interval,
+		events:   make(chan fsnotify.Event, 100),
+		errors:   make(chan error, 1),
+		done:     make(chan struct{}),
+		dirs:     make(map[string]map[string]pollEntry),
+	}
+	go b.run()
+	return b
+}
+
+func (b *pollBackend) Add(dir string) error {
+	entries, err := listDir(dir)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.dirs[dir] = entries
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *pollBackend) Events() <-chan fsnotify.Event { return b.events }
+func (b *pollBackend) Errors() <-chan error          { return b.errors }
+
+func (b *pollBackend) Close() error {
+	b.once.Do(func() { close(b.done) })
+	return nil
+}
+
+func (b *pollBackend) run() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			for _, event := range b.poll() {
+				select {
+				case b.events <- event:
+				case <-b.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// poll rescans every watched directory and returns the changes since the
+// previous scan. A directory that no longer exists is reported as removed
+// entries and stops being watched.
+func (b *pollBackend) poll() []fsnotify.Event {
+	b.mu.Lock()
+	dirs := make([]string, 0, len(b.dirs))
+	for dir := range b.dirs {
+		dirs = append(dirs, dir)
+	}
+	b.mu.Unlock()
+
+	var events []fsnotify.Event
+	for _, dir := range dirs {
+		current, err := listDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			continue // Transient error: keep the previous state
+		}
+
+		b.mu.Lock()
+		previous, ok := b.dirs[dir]
+		if !ok {
+			b.mu.Unlock()
+			continue
+		}
+		if current == nil {
+			delete(b.dirs, dir)
+		} else {
+			b.dirs[dir] = current
+		}
+		b.mu.Unlock()
+
+		events = append(events, diffEntries(dir, previous, current)...)
+	}
+	return events
+}
+
+// diffEntries returns the events turning the previous listing of dir into
+// the current one.
+func diffEntries(dir string, previous, current map[string]pollEntry) []fsnotify.Event {
+	var events []fsnotify.Event
+	for name, entry := range current {
+		old, existed := previous[name]
+		switch {
+		case !existed:
+			events = append(events, fsnotify.Event{Name: filepath.Join(dir, name), Op: fsnotify.Create})
+		case !entry.dir && (entry.size != old.size || !entry.modTime.Equal(old.modTime)):
+			events = append(events, fsnotify.Event{Name: filepath.Join(dir, name), Op: fsnotify.Write})
+		}
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			events = append(events, fsnotify.Event{Name: filepath.Join(dir, name), Op: fsnotify.Remove})
+		}
+	}
+	return events
+}
+
+// listDir returns the entries of dir keyed by name.
+func listDir(dir string) (map[string]pollEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	listing := make(map[string]pollEntry, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed since the listing
+		}
+		listing[entry.Name()] = pollEntry{dir: entry.IsDir(), size: info.Size(), modTime: info.ModTime()}
+	}
+	return listing, nil
+}
+
+// watchLimitHint explains how to get past the watch limit.
+func watchLimitHint(maxWatches int) string {
+	if maxWatches > 0 {
+		return fmt.Sprintf("watch.max_watches (%d) is reached; raise it, exclude large directories in .grepaiignore, or set watch.backend: poll", maxWatches)
+	}
+	if runtime.GOOS == "linux" {
+		limit := "unknown"
+		if data, err := os.ReadFile("/proc/sys/fs/inotify/max_user_watches"); err == nil {
+			limit = strings.TrimSpace(string(data))
+		}
+		return fmt.Sprintf("the inotify watch limit (fs.inotify.max_user_watches = %s) is reached; raise it with 'sudo sysctl fs.inotify.max_user_watches=524288', exclude large directories in .grepaiignore, or set watch.backend: poll", limit)
+	}
+	return "the open file limit is reached; raise it with 'ulimit -n', exclude large directories in .grepaiignore, or set watch.backend: poll"
+}
@@ -0,0 +1,102 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestPollBackend_DetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	removed := filepath.Join(dir, "removed.go")
+	for _, path := range []string{existing, removed} {
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	b := newPollBackend(time.Hour) // polled by hand
+	defer b.Close()
+	if err := b.Add(dir); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if events := b.poll(); len(events) != 0 {
+		t.Fatalf("expected no events without changes, got %v", events)
+	}
+
+	created := filepath.Join(dir, "created.go")
+	if err := os.WriteFile(created, []byte("package x\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(existing, []byte("package x\n\nfunc F() {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Remove(removed); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+
+	got := make(map[string]fsnotify.Op)
+	for _, event := range b.poll() {
+		got[event.Name] = event.Op
+	}
+	want := map[string]fsnotify.Op{
+		created:  fsnotify.Create,
+		existing: fsnotify.Write,
+		removed:  fsnotify.Remove,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for name, op := range want {
+		if got[name] != op {
+			t.Errorf("%s: expected %v, got %v", filepath.Base(name), op, got[name])
+		}
+	}
+}
+
+func TestPollBackend_RemovedDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pkg")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	file := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(file, []byte("package x\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	b := newPollBackend(time.Hour)
+	defer b.Close()
+	if err := b.Add(dir); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("failed to remove dir: %v", err)
+	}
+
+	events := b.poll()
+	if len(events) != 1 || events[0].Name != file || events[0].Op != fsnotify.Remove {
+		t.Fatalf("expected a remove event for %s, got %v", file, events)
+	}
+	if events := b.poll(); len(events) != 0 {
+		t.Errorf("expected the removed directory to stop being watched, got %v", events)
+	}
+}
+
+func TestNotifyBackend_MaxWatches(t *testing.T) {
+	b, err := newNotifyBackend(1)
+	if err != nil {
+		t.Skipf("native file events unavailable: %v", err)
+	}
+	defer b.Close()
+
+	if err := b.Add(t.TempDir()); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := b.Add(t.TempDir()); err != errWatchLimit {
+		t.Errorf("expected errWatchLimit past max_watches, got %v", err)
+	}
+}
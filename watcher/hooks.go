@@ -0,0 +1,199 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// Hook event names, sent as the "event" field of the payload.
+const (
+	HookFileIndexed  = "file_indexed"
+	HookScanComplete = "scan_complete"
+	HookError        = "error"
+)
+
+const (
+	defaultHookTimeout = 10 * time.Second
+	// maxConcurrentHooks bounds the hooks running at once so a burst of file
+	// events cannot spawn an unbounded number of requests or processes.
+	maxConcurrentHooks = 4
+)
+
+// HookEvent is the JSON payload delivered to hooks.
+type HookEvent struct {
+	Event     string    `json:"event"`
+	Project   string    `json:"project"`
+	File      string    `json:"file,omitempty"`
+	Action    string    `json:"action,omitempty"` // indexed or removed (file_indexed)
+	Chunks    int       `json:"chunks,omitempty"`
+	Files     int       `json:"files,omitempty"` // files indexed (scan_complete)
+	Removed   int       `json:"removed,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Hooks runs the configured watch hooks and forwards project-level errors to
+// the desktop notifier. Hooks run asynchronously and their failures are only
+// logged, so a slow or broken hook never stalls indexing. A nil *Hooks is
+// valid and does nothing.
+type Hooks struct {
+	cfg      config.WatchHooksConfig
+	notifier *Notifier
+	client   *http.Client
+	sem      chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewHooks returns a runner for cfg, or nil when neither a hook nor a
+// notifier is configured. notifier may be nil.
+func NewHooks(cfg config.WatchHooksConfig, notifier *Notifier) *Hooks {
+	if len(cfg.OnFileIndexed) == 0 && len(cfg.OnScanComplete) == 0 && len(cfg.OnError) == 0 && notifier == nil {
+		return nil
+	}
+	return &Hooks{
+		cfg:      cfg,
+		notifier: notifier,
+		client:   &http.Client{},
+		sem:      make(chan struct{}, maxConcurrentHooks),
+	}
+}
+
+// FileIndexed fires on_file_indexed hooks after a file was indexed or removed.
+func (h *Hooks) FileIndexed(project, file, action string, chunks int) {
+	if h == nil {
+		return
+	}
+	h.fire(h.cfg.OnFileIndexed, HookEvent{Event: HookFileIndexed, Project: project, File: file, Action: action, Chunks: chunks})
+}
+
+// ScanComplete fires on_scan_complete hooks after the initial scan.
+func (h *Hooks) ScanComplete(project string, filesIndexed, filesRemoved, chunks int) {
+	if h == nil {
+		return
+	}
+	h.fire(h.cfg.OnScanComplete, HookEvent{Event: HookScanComplete, Project: project, Files: filesIndexed, Removed: filesRemoved, Chunks: chunks})
+}
+
+// Error fires on_error hooks. file may be empty for project-level errors,
+// which are also sent to the desktop notifier.
+func (h *Hooks) Error(project, file string, err error) {
+	if h == nil || err == nil {
+		return
+	}
+	if file == "" {
+		h.notifier.SessionError(project, err.Error())
+	}
+	h.fire(h.cfg.OnError, HookEvent{Event: HookError, Project: project, File: file, Error: err.Error()})
+}
+
+// Wait blocks until all running hooks and notifications have finished.
+func (h *Hooks) Wait() {
+	if h == nil {
+		return
+	}
+	h.wg.Wait()
+	h.notifier.Wait()
+}
+
+func (h *Hooks) fire(hooks []config.WatchHook, event HookEvent) {
+	if len(hooks) == 0 {
+		return
+	}
+	event.Timestamp = time.Now().UTC()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Warning: failed to encode %s hook payload: %v", event.Event, err)
+		return
+	}
+
+	for _, hook := range hooks {
+		h.wg.Add(1)
+		go func(hook config.WatchHook) {
+			defer h.wg.Done()
+			h.sem <- struct{}{}
+			defer func() { <-h.sem }()
+
+			if err := h.run(hook, event, payload); err != nil {
+				log.Printf("Warning: %s hook %s failed: %v", event.Event, hookTarget(hook), err)
+			}
+		}(hook)
+	}
+}
+
+func (h *Hooks) run(hook config.WatchHook, event HookEvent, payload []byte) error {
+	timeout := defaultHookTimeout
+	if hook.TimeoutMs > 0 {
+		timeout = time.Duration(hook.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if hook.URL != "" {
+		return h.post(ctx, hook, payload)
+	}
+	return runHookCommand(ctx, hook.Command, event, payload)
+}
+
+func (h *Hooks) post(ctx context.Context, hook config.WatchHook, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "grepai-watch")
+	for k, v := range hook.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// runHookCommand runs command through the platform shell with the payload on
+// stdin and the main event fields in GREPAI_* environment variables.
+func runHookCommand(ctx context.Context, command string, event HookEvent, payload []byte) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"GREPAI_EVENT="+event.Event,
+		"GREPAI_PROJECT="+event.Project,
+		"GREPAI_FILE="+event.File,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+		}
+		return err
+	}
+	return nil
+}
+
+func hookTarget(hook config.WatchHook) string {
+	if hook.URL != "" {
+		return hook.URL
+	}
+	return fmt.Sprintf("%q", hook.Command)
+}
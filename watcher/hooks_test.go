@@ -0,0 +1,103 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestNewHooks_NilWithoutHooks(t *testing.T) {
+	h := NewHooks(config.WatchHooksConfig{}, nil)
+	if h != nil {
+		t.Fatal("expected nil runner when no hook is configured")
+	}
+	// A nil runner is safe to use.
+	h.FileIndexed("/p", "main.go", "indexed", 1)
+	h.ScanComplete("/p", 1, 0, 1)
+	h.Error("/p", "", errors.New("boom"))
+	h.Wait()
+}
+
+func TestHooks_PostsJSON(t *testing.T) {
+	var mu sync.Mutex
+	var got []HookEvent
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev HookEvent
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		mu.Lock()
+		got = append(got, ev)
+		auth = r.Header.Get("Authorization")
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	t.Setenv("HOOK_TOKEN", "secret")
+	h := NewHooks(config.WatchHooksConfig{
+		OnFileIndexed: []config.WatchHook{{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer ${HOOK_TOKEN}"}}},
+		OnError:       []config.WatchHook{{URL: srv.URL}},
+	}, nil)
+	h.FileIndexed("/proj", "main.go", "indexed", 3)
+	h.ScanComplete("/proj", 10, 0, 42) // no on_scan_complete hook configured
+	h.Error("/proj", "bad.go", errors.New("embedder unavailable"))
+	h.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(got))
+	}
+	byEvent := map[string]HookEvent{}
+	for _, ev := range got {
+		byEvent[ev.Event] = ev
+	}
+	indexed := byEvent[HookFileIndexed]
+	if indexed.File != "main.go" || indexed.Action != "indexed" || indexed.Chunks != 3 || indexed.Project != "/proj" {
+		t.Errorf("unexpected file_indexed payload: %+v", indexed)
+	}
+	if byEvent[HookError].Error != "embedder unavailable" {
+		t.Errorf("unexpected error payload: %+v", byEvent[HookError])
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("expected expanded Authorization header, got %q", auth)
+	}
+}
+
+func TestHooks_RunsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+	out := filepath.Join(t.TempDir(), "event.json")
+	h := NewHooks(config.WatchHooksConfig{
+		OnScanComplete: []config.WatchHook{{Command: `cat > "` + out + `"; printf "\n%s" "$GREPAI_EVENT" >> "` + out + `"`}},
+	}, nil)
+	h.ScanComplete("/proj", 5, 1, 20)
+	h.Wait()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook command did not run: %v", err)
+	}
+	lines := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	var ev HookEvent
+	if err := json.Unmarshal([]byte(lines[0]), &ev); err != nil {
+		t.Fatalf("invalid payload on stdin: %v", err)
+	}
+	if ev.Event != HookScanComplete || ev.Files != 5 || ev.Removed != 1 || ev.Chunks != 20 {
+		t.Errorf("unexpected scan_complete payload: %+v", ev)
+	}
+	if len(lines) != 2 || lines[1] != HookScanComplete {
+		t.Errorf("expected GREPAI_EVENT in environment, got %q", data)
+	}
+}
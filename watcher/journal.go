@@ -0,0 +1,253 @@
+package watcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+)
+
+// Journal records file events on disk before they are processed, so that
+// events received by a watcher that crashes before its index is persisted
+// are replayed on restart rather than found again, or missed, by comparing
+// modification times.
+//
+// An event stays in the journal from Record until a Checkpoint after it was
+// marked Done, which the caller does once the index holding its changes has
+// been persisted. All methods are safe for concurrent use, and a nil
+// *Journal records nothing.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	seq     uint64
+	entries map[string]*journalEntry
+	dirty   bool // entries were marked done since the last checkpoint
+	replay  []FileEvent
+}
+
+type journalEntry struct {
+	event       FileEvent
+	seq         uint64 // order of the last record, for rewrites
+	outstanding int    // records not yet marked done
+}
+
+type journalLine struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// OpenJournal opens the journal at path, creating it if needed. The events
+// left by a previous session are returned by Replay.
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path, entries: make(map[string]*journalEntry)}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	if err := fileutil.EnsureParentDir(path); err != nil {
+		return nil, err
+	}
+	// Rewriting the journal drops a line a crash may have cut short, which
+	// new events would otherwise be appended to.
+	if err := j.rewrite(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// load reads the events of a previous session, keeping the last event of
+// each file. Lines cut short by a crash are skipped.
+func (j *Journal) load() error {
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read watch journal: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var line journalLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || line.Path == "" {
+			continue
+		}
+		eventType, ok := parseEventType(line.Type)
+		if !ok {
+			continue
+		}
+		j.seq++
+		j.entries[line.Path] = &journalEntry{event: FileEvent{Type: eventType, Path: line.Path}, seq: j.seq, outstanding: 1}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read watch journal: %w", err)
+	}
+
+	for _, entry := range j.sortedEntries() {
+		j.replay = append(j.replay, entry.event)
+	}
+	return nil
+}
+
+// Replay returns the events of the previous session that were not
+// checkpointed, in the order they were received, one per file. They stay in
+// the journal until marked Done and checkpointed.
+func (j *Journal) Replay() []FileEvent {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.replay
+}
+
+// Record appends event to the journal.
+func (j *Journal) Record(event FileEvent) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.seq++
+	entry, ok := j.entries[event.Path]
+	if !ok {
+		entry = &journalEntry{}
+		j.entries[event.Path] = entry
+	}
+	entry.event = event
+	entry.seq = j.seq
+	entry.outstanding++
+
+	data, err := json.Marshal(journalLine{Type: event.Type.String(), Path: event.Path})
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write watch journal: %w", err)
+	}
+	return nil
+}
+
+// Done marks a recorded event as processed. It leaves the journal at the
+// next Checkpoint.
+func (j *Journal) Done(event FileEvent) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if entry, ok := j.entries[event.Path]; ok && entry.outstanding > 0 {
+		entry.outstanding--
+		j.dirty = true
+	}
+}
+
+// Checkpoint drops the events marked done from the journal. Call it once
+// the changes of those events have been persisted.
+func (j *Journal) Checkpoint() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if !j.dirty {
+		return nil
+	}
+	for path, entry := range j.entries {
+		if entry.outstanding == 0 {
+			delete(j.entries, path)
+		}
+	}
+
+	if err := j.rewrite(); err != nil {
+		return err
+	}
+	j.dirty = false
+	return nil
+}
+
+// rewrite replaces the journal file with the entries left and reopens it
+// for appending.
+func (j *Journal) rewrite() error {
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), ".watch-journal-*")
+	if err != nil {
+		return fmt.Errorf("failed to rewrite watch journal: %w", err)
+	}
+	tmpPath := tmp.Name()
+	w := bufio.NewWriter(tmp)
+	for _, entry := range j.sortedEntries() {
+		data, err := json.Marshal(journalLine{Type: entry.event.Type.String(), Path: entry.event.Path})
+		if err == nil {
+			_, _ = w.Write(append(data, '\n'))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rewrite watch journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rewrite watch journal: %w", err)
+	}
+
+	// The append handle is closed first, as Windows cannot replace an open file.
+	if j.file != nil {
+		j.file.Close()
+	}
+	replaceErr := fileutil.ReplaceFileAtomically(tmpPath, j.path)
+	if replaceErr != nil {
+		os.Remove(tmpPath)
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open watch journal: %w", err)
+	}
+	j.file = f
+	if replaceErr != nil {
+		return fmt.Errorf("failed to rewrite watch journal: %w", replaceErr)
+	}
+	return nil
+}
+
+// Close closes the journal file, keeping the events not checkpointed for
+// the next session.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// sortedEntries returns the entries in the order they were last recorded.
+func (j *Journal) sortedEntries() []*journalEntry {
+	entries := make([]*journalEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].seq < entries[b].seq
+	})
+	return entries
+}
+
+func parseEventType(s string) (EventType, bool) {
+	for _, t := range []EventType{EventCreate, EventModify, EventDelete, EventRename} {
+		if t.String() == s {
+			return t, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,76 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournal_ReplaysEventsNotCheckpointed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".grepai", "watch-journal.jsonl")
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+
+	created := FileEvent{Type: EventCreate, Path: "a.go"}
+	modified := FileEvent{Type: EventModify, Path: "b.go"}
+	deleted := FileEvent{Type: EventDelete, Path: "a.go"}
+	for _, event := range []FileEvent{created, modified, deleted} {
+		if err := j.Record(event); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	// b.go is handled and persisted; a.go is handled once of its two events.
+	j.Done(modified)
+	j.Done(created)
+	if err := j.Checkpoint(); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// A crash leaves a partial line behind.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"type":"MODI`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	j, err = OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	defer j.Close()
+	if got, want := j.Replay(), []FileEvent{deleted}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected replay %v, got %v", want, got)
+	}
+
+	j.Done(deleted)
+	if err := j.Checkpoint(); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("expected an empty journal after replay, got %q", data)
+	}
+}
+
+func TestJournal_Nil(t *testing.T) {
+	var j *Journal
+	if err := j.Record(FileEvent{Path: "a.go"}); err != nil {
+		t.Errorf("expected a nil journal to record nothing, got %v", err)
+	}
+	j.Done(FileEvent{Path: "a.go"})
+	if err := j.Checkpoint(); err != nil || j.Replay() != nil || j.Close() != nil {
+		t.Error("expected a nil journal to be a no-op")
+	}
+}
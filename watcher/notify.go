@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+)
+
+const (
+	defaultNotifyFailureThreshold = 3
+	defaultNotifyCooldown         = 5 * time.Minute
+	notifyTimeout                 = 5 * time.Second
+
+	// notifyBreakerOpenFor is how long the embedder circuit breaker stays
+	// open before letting a request through again.
+	notifyBreakerOpenFor = 30 * time.Second
+)
+
+// Notifier sends desktop notifications when a watch session fails or the
+// circuit breaker of its embedder opens (typically an unreachable
+// embedder), so background daemons do not fail silently. Notifications of
+// the same kind for the same project are rate-limited. A nil *Notifier is
+// valid and does nothing.
+type Notifier struct {
+	threshold int
+	cooldown  time.Duration
+	send      func(title, message string) error
+	now       func() time.Time
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	wg       sync.WaitGroup
+}
+
+// NewNotifier returns a notifier for cfg, or nil when notifications are disabled.
+func NewNotifier(cfg config.WatchNotificationsConfig) *Notifier {
+	if !cfg.Enabled {
+		return nil
+	}
+	n := &Notifier{
+		threshold: cfg.FailureThreshold,
+		cooldown:  time.Duration(cfg.CooldownSec) * time.Second,
+		send:      sendDesktopNotification,
+		now:       time.Now,
+		lastSent:  make(map[string]time.Time),
+	}
+	if n.threshold == 0 {
+		n.threshold = defaultNotifyFailureThreshold
+	}
+	if n.cooldown == 0 {
+		n.cooldown = defaultNotifyCooldown
+	}
+	return n
+}
+
+// SessionError reports a watch session entering the error state.
+func (n *Notifier) SessionError(project, note string) {
+	if n == nil {
+		return
+	}
+	n.notify("session:"+project, "grepai watch error", fmt.Sprintf("%s: %s", filepath.Base(project), note))
+}
+
+// WrapEmbedder returns emb behind a circuit breaker that opens once
+// failure_threshold requests in a row failed, and notifies when it opens and
+// when the embedder recovers. It returns emb when n is nil.
+func (n *Notifier) WrapEmbedder(project string, emb embedder.Embedder) embedder.Embedder {
+	if n == nil {
+		return emb
+	}
+	name := filepath.Base(project)
+	breaker := embedder.NewCircuitBreaker(n.threshold, notifyBreakerOpenFor, n.now, func(open bool, err error) {
+		if open {
+			n.notify("embedder:"+project, "grepai indexing paused",
+				fmt.Sprintf("%s: %d embedding requests in a row failed: %v", name, n.threshold, err))
+			return
+		}
+		n.notify("embedder-recovered:"+project, "grepai indexing resumed", fmt.Sprintf("%s: the embedder is reachable again", name))
+	})
+	return embedder.WithCircuitBreaker(emb, breaker)
+}
+
+// Wait blocks until all pending notifications have been sent.
+func (n *Notifier) Wait() {
+	if n == nil {
+		return
+	}
+	n.wg.Wait()
+}
+
+func (n *Notifier) notify(key, title, message string) {
+	n.mu.Lock()
+	now := n.now()
+	if last, ok := n.lastSent[key]; ok && now.Sub(last) < n.cooldown {
+		n.mu.Unlock()
+		return
+	}
+	n.lastSent[key] = now
+	n.mu.Unlock()
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		if err := n.send(title, message); err != nil {
+			log.Printf("Warning: failed to send desktop notification: %v", err)
+		}
+	}()
+}
+
+// sendDesktopNotification shows a notification with the platform tool:
+// osascript on macOS, PowerShell toasts on Windows and notify-send elsewhere.
+// The text is passed through the environment to avoid quoting issues.
+func sendDesktopNotification(title, message string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	cmd := desktopNotifyCommand(ctx, runtime.GOOS, title, message)
+	cmd.Env = append(os.Environ(), "GREPAI_NOTIFY_TITLE="+title, "GREPAI_NOTIFY_MESSAGE="+message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%s: %w: %s", filepath.Base(cmd.Path), err, out)
+		}
+		return fmt.Errorf("%s: %w", filepath.Base(cmd.Path), err)
+	}
+	return nil
+}
+
+func desktopNotifyCommand(ctx context.Context, goos, title, message string) *exec.Cmd {
+	switch goos {
+	case "darwin":
+		return exec.CommandContext(ctx, "osascript", "-e",
+			`display notification (system attribute "GREPAI_NOTIFY_MESSAGE") with title (system attribute "GREPAI_NOTIFY_TITLE")`)
+	case "windows":
+		script := `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:GREPAI_NOTIFY_TITLE)) | Out-Null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:GREPAI_NOTIFY_MESSAGE)) | Out-Null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('grepai').Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+		return exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	default:
+		return exec.CommandContext(ctx, "notify-send", "--app-name=grepai", title, message)
+	}
+}
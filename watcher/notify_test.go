@@ -0,0 +1,178 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/embedder"
+)
+
+type recordedNotification struct {
+	title   string
+	message string
+}
+
+// testClock is a clock advanced by hand.
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestNotifier(t *testing.T, cfg config.WatchNotificationsConfig) (*Notifier, *testClock, func() []recordedNotification) {
+	t.Helper()
+	cfg.Enabled = true
+	n := NewNotifier(cfg)
+	clock := &testClock{now: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)}
+	n.now = clock.Now
+	var mu sync.Mutex
+	var sent []recordedNotification
+	n.send = func(title, message string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, recordedNotification{title, message})
+		return nil
+	}
+	return n, clock, func() []recordedNotification {
+		n.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedNotification(nil), sent...)
+	}
+}
+
+type unreachableEmbedder struct{ err error }
+
+func (e *unreachableEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return []float32{1}, nil
+}
+
+func (e *unreachableEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return make([][]float32, len(texts)), nil
+}
+
+func (e *unreachableEmbedder) Dimensions() int { return 1 }
+
+func (e *unreachableEmbedder) Close() error { return nil }
+
+func TestNewNotifier_DisabledIsNil(t *testing.T) {
+	n := NewNotifier(config.WatchNotificationsConfig{})
+	if n != nil {
+		t.Fatal("expected nil notifier when disabled")
+	}
+	n.SessionError("/p", "boom")
+	n.Wait()
+	emb := &unreachableEmbedder{}
+	if n.WrapEmbedder("/p", emb) != embedder.Embedder(emb) {
+		t.Error("expected a nil notifier to leave the embedder unwrapped")
+	}
+}
+
+func TestNotifier_WrapEmbedderNotifiesCircuitBreaker(t *testing.T) {
+	n, clock, sent := newTestNotifier(t, config.WatchNotificationsConfig{FailureThreshold: 2})
+	ctx := context.Background()
+	inner := &unreachableEmbedder{err: errors.New("connection refused")}
+	emb := n.WrapEmbedder("/work/api", inner)
+
+	_, _ = emb.Embed(ctx, "a")
+	if got := sent(); len(got) != 0 {
+		t.Fatalf("expected no notification below threshold, got %v", got)
+	}
+
+	_, _ = emb.Embed(ctx, "b")
+	got := sent()
+	if len(got) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(got))
+	}
+	if got[0].title != "grepai indexing paused" || !strings.Contains(got[0].message, "api: 2 embedding requests in a row failed: connection refused") {
+		t.Errorf("unexpected notification %+v", got[0])
+	}
+
+	// The open breaker fails fast without notifying again.
+	if _, err := emb.Embed(ctx, "c"); !errors.Is(err, embedder.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if got := sent(); len(got) != 1 {
+		t.Fatalf("expected an open breaker not to notify again, got %d", len(got))
+	}
+
+	clock.Advance(notifyBreakerOpenFor)
+	inner.err = nil
+	if _, err := emb.Embed(ctx, "d"); err != nil {
+		t.Fatalf("expected the embedder to recover, got %v", err)
+	}
+	got = sent()
+	if len(got) != 2 || got[1].title != "grepai indexing resumed" {
+		t.Fatalf("expected a recovery notification, got %v", got)
+	}
+}
+
+func TestNotifier_SessionErrorCooldownPerProject(t *testing.T) {
+	n, clock, sent := newTestNotifier(t, config.WatchNotificationsConfig{CooldownSec: 60})
+
+	n.SessionError("/work/api", "session stopped unexpectedly")
+	n.SessionError("/work/api", "session stopped unexpectedly")
+	n.SessionError("/work/web", "watcher failed")
+
+	got := sent()
+	if len(got) != 2 {
+		t.Fatalf("expected one notification per project, got %d", len(got))
+	}
+	if got[0].title != "grepai watch error" {
+		t.Errorf("unexpected title %q", got[0].title)
+	}
+
+	clock.Advance(time.Minute)
+	n.SessionError("/work/api", "session stopped unexpectedly")
+	if got := sent(); len(got) != 3 {
+		t.Fatalf("expected a notification once the cooldown elapsed, got %d", len(got))
+	}
+}
+
+func TestHooks_ProjectErrorsNotifyImmediately(t *testing.T) {
+	n, _, sent := newTestNotifier(t, config.WatchNotificationsConfig{FailureThreshold: 5})
+	h := NewHooks(config.WatchHooksConfig{}, n)
+
+	h.Error("/work/api", "a.go", errors.New("connection refused"))
+	h.Wait()
+	if got := sent(); len(got) != 0 {
+		t.Fatalf("expected file errors not to notify, got %v", got)
+	}
+
+	h.Error("/work/api", "", errors.New("initial scan failed"))
+	h.Wait()
+	got := sent()
+	if len(got) != 1 || !strings.Contains(got[0].message, "api: initial scan failed") {
+		t.Fatalf("expected the project error to notify, got %v", got)
+	}
+}
+
+func TestDesktopNotifyCommand(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		goos string
+		bin  string
+	}{
+		{"darwin", "osascript"},
+		{"linux", "notify-send"},
+		{"freebsd", "notify-send"},
+		{"windows", "powershell"},
+	}
+	for _, tt := range tests {
+		cmd := desktopNotifyCommand(ctx, tt.goos, "title", "message")
+		if cmd.Args[0] != tt.bin {
+			t.Errorf("%s: expected %s, got %s", tt.goos, tt.bin, cmd.Args[0])
+		}
+	}
+}
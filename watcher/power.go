@@ -0,0 +1,199 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// defaultPowerSampleInterval is how often a Throttle samples the power state.
+const defaultPowerSampleInterval = 15 * time.Second
+
+// powerProfileMaxLoad is, per power profile, the 1-minute load average per
+// CPU at or above which background indexing is throttled. Profiles missing
+// from the map never throttle.
+var powerProfileMaxLoad = map[string]float64{
+	config.PowerProfileBalanced: 1.0,
+	config.PowerProfileSaver:    0.5,
+}
+
+// PowerState is a sample of the machine's power source and CPU load.
+type PowerState struct {
+	OnBattery  bool
+	LoadPerCPU float64 // 1-minute load average divided by the CPU count; 0 if unknown
+}
+
+// ReadPowerState samples the power source and CPU load. Values the platform
+// cannot provide are left zero, so they never cause throttling. Linux and
+// macOS are supported.
+func ReadPowerState() PowerState {
+	var state PowerState
+	switch runtime.GOOS {
+	case "linux":
+		state.OnBattery = linuxOnBattery("/sys/class/power_supply")
+		if data, err := os.ReadFile("/proc/loadavg"); err == nil {
+			state.LoadPerCPU = parseLoadAverage(string(data)) / float64(runtime.NumCPU())
+		}
+	case "darwin":
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, "pmset", "-g", "batt").Output(); err == nil {
+			state.OnBattery = strings.Contains(string(out), "'Battery Power'")
+		}
+		if out, err := exec.CommandContext(ctx, "sysctl", "-n", "vm.loadavg").Output(); err == nil {
+			state.LoadPerCPU = parseLoadAverage(strings.Trim(strings.TrimSpace(string(out)), "{ }")) / float64(runtime.NumCPU())
+		}
+	}
+	return state
+}
+
+// linuxOnBattery reports whether a battery is discharging while no mains
+// supply is online.
+func linuxOnBattery(supplyDir string) bool {
+	entries, err := os.ReadDir(supplyDir)
+	if err != nil {
+		return false
+	}
+	discharging := false
+	for _, entry := range entries {
+		dir := filepath.Join(supplyDir, entry.Name())
+		switch readTrimmed(filepath.Join(dir, "type")) {
+		case "Mains":
+			if readTrimmed(filepath.Join(dir, "online")) == "1" {
+				return false
+			}
+		case "Battery":
+			if readTrimmed(filepath.Join(dir, "status")) == "Discharging" {
+				discharging = true
+			}
+		}
+	}
+	return discharging
+}
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// parseLoadAverage returns the first field of a load average line such as
+// "1.52 1.20 0.98 2/1234 5678".
+func parseLoadAverage(line string) float64 {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return 0
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return load
+}
+
+// Throttle tells the watcher when to slow down background indexing, per the
+// watch power profile: while the machine is on battery or its CPU load is
+// high, backfill pauses and embedding runs one request at a time. File
+// events are still indexed. A nil *Throttle never throttles.
+type Throttle struct {
+	maxLoad  float64
+	interval time.Duration
+	sample   func() PowerState
+
+	mu       sync.Mutex
+	reason   string
+	onChange []func(reason string)
+}
+
+// NewThrottle returns a throttle for the power profile, or nil when the
+// profile never throttles.
+func NewThrottle(profile string) *Throttle {
+	maxLoad, ok := powerProfileMaxLoad[profile]
+	if !ok {
+		return nil
+	}
+	return &Throttle{
+		maxLoad:  maxLoad,
+		interval: defaultPowerSampleInterval,
+		sample:   ReadPowerState,
+	}
+}
+
+// OnChange registers fn to be called with the new reason whenever the
+// throttle engages or, with an empty reason, releases.
+func (t *Throttle) OnChange(fn func(reason string)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onChange = append(t.onChange, fn)
+}
+
+// Start samples the power state now and then periodically until ctx is done.
+func (t *Throttle) Start(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	t.update(t.sample())
+	go func() {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.update(t.sample())
+			}
+		}
+	}()
+}
+
+// Reason returns why background indexing is throttled, or "" when it is not.
+func (t *Throttle) Reason() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.reason
+}
+
+// Throttled reports whether background indexing should slow down.
+func (t *Throttle) Throttled() bool {
+	return t.Reason() != ""
+}
+
+func (t *Throttle) update(state PowerState) {
+	reason := ""
+	switch {
+	case state.OnBattery:
+		reason = "on battery"
+	case state.LoadPerCPU >= t.maxLoad:
+		reason = fmt.Sprintf("CPU load %.2f per core", state.LoadPerCPU)
+	}
+
+	t.mu.Lock()
+	changed := (reason == "") != (t.reason == "")
+	t.reason = reason
+	callbacks := append([]func(string){}, t.onChange...)
+	t.mu.Unlock()
+
+	if changed {
+		for _, fn := range callbacks {
+			fn(reason)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func writeSupply(t *testing.T, dir, name string, files map[string]string) {
+	t.Helper()
+	supply := filepath.Join(dir, name)
+	if err := os.MkdirAll(supply, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(supply, file), []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLinuxOnBattery(t *testing.T) {
+	onBattery := t.TempDir()
+	writeSupply(t, onBattery, "AC", map[string]string{"type": "Mains", "online": "0"})
+	writeSupply(t, onBattery, "BAT0", map[string]string{"type": "Battery", "status": "Discharging"})
+	if !linuxOnBattery(onBattery) {
+		t.Error("expected on battery with a discharging battery and mains offline")
+	}
+
+	plugged := t.TempDir()
+	writeSupply(t, plugged, "AC", map[string]string{"type": "Mains", "online": "1"})
+	writeSupply(t, plugged, "BAT0", map[string]string{"type": "Battery", "status": "Charging"})
+	if linuxOnBattery(plugged) {
+		t.Error("expected not on battery with mains online")
+	}
+
+	if linuxOnBattery(filepath.Join(t.TempDir(), "missing")) {
+		t.Error("expected not on battery without power supply information")
+	}
+}
+
+func TestParseLoadAverage(t *testing.T) {
+	if got := parseLoadAverage("1.52 1.20 0.98 2/1234 5678"); got != 1.52 {
+		t.Errorf("parseLoadAverage = %v, want 1.52", got)
+	}
+	if got := parseLoadAverage(""); got != 0 {
+		t.Errorf("parseLoadAverage(empty) = %v, want 0", got)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	if NewThrottle(config.PowerProfilePerformance) != nil || NewThrottle("") != nil {
+		t.Fatal("performance profile must not throttle")
+	}
+	var nilThrottle *Throttle
+	if nilThrottle.Throttled() {
+		t.Fatal("nil throttle must not throttle")
+	}
+
+	state := PowerState{LoadPerCPU: 0.2}
+	th := NewThrottle(config.PowerProfileBalanced)
+	th.sample = func() PowerState { return state }
+	var changes []string
+	th.OnChange(func(reason string) { changes = append(changes, reason) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	th.Start(ctx)
+	if th.Throttled() {
+		t.Fatal("expected no throttling under low load on mains")
+	}
+
+	state = PowerState{LoadPerCPU: 1.5}
+	th.update(th.sample())
+	if th.Reason() != "CPU load 1.50 per core" {
+		t.Errorf("Reason = %q, want high CPU load", th.Reason())
+	}
+	state = PowerState{OnBattery: true, LoadPerCPU: 1.5}
+	th.update(th.sample())
+	if th.Reason() != "on battery" {
+		t.Errorf("Reason = %q, want on battery", th.Reason())
+	}
+	state = PowerState{}
+	th.update(th.sample())
+
+	if len(changes) != 2 || changes[0] == "" || changes[1] != "" {
+		t.Errorf("changes = %q, want one engage and one release", changes)
+	}
+}
@@ -0,0 +1,97 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+)
+
+// Priority orders tasks in a WorkQueue.
+type Priority int
+
+const (
+	// PriorityHigh is for work a user is waiting on, such as a saved file.
+	PriorityHigh Priority = iota
+	// PriorityLow is for background work such as the initial scan backfill.
+	PriorityLow
+)
+
+// DefaultHighPriorityBurst is how many high-priority tasks may run in a row
+// while low-priority tasks are waiting.
+const DefaultHighPriorityBurst = 8
+
+// WorkQueue runs tasks one at a time, high priority first. So that a steady
+// stream of file events cannot stall the backfill forever, one low-priority
+// task runs after every burst of consecutive high-priority tasks when both
+// are waiting. Tasks of the same priority run in the order they were pushed.
+type WorkQueue struct {
+	mu     sync.Mutex
+	high   []func(context.Context)
+	low    []func(context.Context)
+	burst  int
+	streak int
+}
+
+// NewWorkQueue creates a queue that runs at most burst high-priority tasks
+// in a row while low-priority work is pending.
+func NewWorkQueue(burst int) *WorkQueue {
+	if burst < 1 {
+		burst = DefaultHighPriorityBurst
+	}
+	return &WorkQueue{burst: burst}
+}
+
+// Push adds a task. It is safe to call from any goroutine, including while
+// Run is executing tasks.
+func (q *WorkQueue) Push(p Priority, task func(context.Context)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if p == PriorityHigh {
+		q.high = append(q.high, task)
+	} else {
+		q.low = append(q.low, task)
+	}
+}
+
+// Pending returns the number of waiting tasks per priority.
+func (q *WorkQueue) Pending() (high, low int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.high), len(q.low)
+}
+
+// Run runs tasks until the queue is empty or ctx is canceled.
+func (q *WorkQueue) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		task := q.next()
+		if task == nil {
+			return nil
+		}
+		task(ctx)
+	}
+}
+
+func (q *WorkQueue) next() func(context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.low) > 0 && (len(q.high) == 0 || q.streak >= q.burst) {
+		task := q.low[0]
+		q.low = q.low[1:]
+		q.streak = 0
+		return task
+	}
+	if len(q.high) > 0 {
+		task := q.high[0]
+		q.high = q.high[1:]
+		if len(q.low) > 0 {
+			q.streak++
+		} else {
+			q.streak = 0
+		}
+		return task
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWorkQueue_HighPriorityFirst(t *testing.T) {
+	q := NewWorkQueue(DefaultHighPriorityBurst)
+	var order []string
+	record := func(name string) func(context.Context) {
+		return func(context.Context) { order = append(order, name) }
+	}
+
+	q.Push(PriorityLow, record("backfill-1"))
+	q.Push(PriorityLow, record("backfill-2"))
+	q.Push(PriorityHigh, record("event-1"))
+	q.Push(PriorityHigh, record("event-2"))
+
+	if err := q.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := "event-1,event-2,backfill-1,backfill-2"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("expected order %s, got %s", want, got)
+	}
+}
+
+func TestWorkQueue_EventPushedDuringBackfillPreempts(t *testing.T) {
+	q := NewWorkQueue(DefaultHighPriorityBurst)
+	var order []string
+	q.Push(PriorityLow, func(context.Context) {
+		order = append(order, "backfill-1")
+		q.Push(PriorityHigh, func(context.Context) { order = append(order, "event") })
+	})
+	q.Push(PriorityLow, func(context.Context) { order = append(order, "backfill-2") })
+
+	if err := q.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := "backfill-1,event,backfill-2"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("expected order %s, got %s", want, got)
+	}
+}
+
+func TestWorkQueue_BurstLetsBackfillProgress(t *testing.T) {
+	q := NewWorkQueue(2)
+	var order []string
+	for i := 1; i <= 2; i++ {
+		name := fmt.Sprintf("backfill-%d", i)
+		q.Push(PriorityLow, func(context.Context) { order = append(order, name) })
+	}
+	for i := 1; i <= 5; i++ {
+		name := fmt.Sprintf("event-%d", i)
+		q.Push(PriorityHigh, func(context.Context) { order = append(order, name) })
+	}
+
+	if err := q.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := "event-1,event-2,backfill-1,event-3,event-4,backfill-2,event-5"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("expected order %s, got %s", want, got)
+	}
+}
+
+func TestWorkQueue_RunStopsOnCanceledContext(t *testing.T) {
+	q := NewWorkQueue(DefaultHighPriorityBurst)
+	ran := false
+	q.Push(PriorityLow, func(context.Context) { ran = true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := q.Run(ctx); err == nil {
+		t.Fatal("expected context error")
+	}
+	if ran {
+		t.Error("no task should run after cancellation")
+	}
+	if high, low := q.Pending(); high != 0 || low != 1 {
+		t.Errorf("expected the task to stay queued, got high=%d low=%d", high, low)
+	}
+}
@@ -2,6 +2,7 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"path/filepath"
@@ -10,7 +11,9 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
 )
 
 type EventType int
@@ -29,86 +32,223 @@ type FileEvent struct {
 
 type Watcher struct {
 	root       string
-	watcher    *fsnotify.Watcher
+	backend    backend
 	ignore     *indexer.IgnoreMatcher
 	debounceMs int
 	events     chan FileEvent
 	done       chan struct{}
 
+	// Backend selection, see SetBackend
+	mode         string
+	pollInterval time.Duration
+	maxWatches   int
+	limitHit     bool
+
+	// Symlinked directories, see SetFollowSymlinks
+	followSymlinks string
+	watchedDirs    map[string]bool // real paths of the watched directories
+
 	// Debouncing state
 	pending   map[string]FileEvent
 	pendingMu sync.Mutex
 	timer     *time.Timer
+
+	journal *Journal
 }
 
 func NewWatcher(root string, ignore *indexer.IgnoreMatcher, debounceMs int) (*Watcher, error) {
-	fsw, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
-	}
-
 	return &Watcher{
-		root:       root,
-		watcher:    fsw,
-		ignore:     ignore,
-		debounceMs: debounceMs,
-		events:     make(chan FileEvent, 100),
-		done:       make(chan struct{}),
-		pending:    make(map[string]FileEvent),
+		root:           root,
+		ignore:         ignore,
+		debounceMs:     debounceMs,
+		events:         make(chan FileEvent, 100),
+		done:           make(chan struct{}),
+		pending:        make(map[string]FileEvent),
+		mode:           config.WatchBackendAuto,
+		pollInterval:   time.Duration(config.DefaultWatchPollIntervalMs) * time.Millisecond,
+		followSymlinks: indexer.FollowSymlinksNone,
 	}, nil
 }
 
+// SetBackend selects how file events are detected: auto, native or poll.
+// maxWatches caps the directories watched natively (0 means the OS limit);
+// in auto mode, reaching it switches the project to polling. It must be
+// called before Start.
+func (w *Watcher) SetBackend(mode string, pollInterval time.Duration, maxWatches int) {
+	if mode != "" {
+		w.mode = mode
+	}
+	if pollInterval > 0 {
+		w.pollInterval = pollInterval
+	}
+	w.maxWatches = maxWatches
+}
+
+// SetFollowSymlinks sets how symlinked directories are handled, with the
+// same policy as the scanner's (indexer.follow_symlinks), so that the
+// directories it indexes through a link are watched under the link's path.
+// It must be called before Start.
+func (w *Watcher) SetFollowSymlinks(policy string) {
+	if policy == "" {
+		policy = indexer.FollowSymlinksNone
+	}
+	w.followSymlinks = policy
+}
+
 func (w *Watcher) Start(ctx context.Context) error {
+	if w.mode == config.WatchBackendPoll {
+		w.backend = newPollBackend(w.pollInterval)
+	} else {
+		b, err := newNotifyBackend(w.maxWatches)
+		if err != nil {
+			if w.mode != config.WatchBackendAuto {
+				return err
+			}
+			log.Printf("Native file events unavailable (%v), polling every %s", err, w.pollInterval)
+			w.backend = newPollBackend(w.pollInterval)
+		} else {
+			w.backend = b
+		}
+	}
+
 	// Add root directory and all subdirectories
+	w.watchedDirs = make(map[string]bool)
 	if err := w.addRecursive(w.root); err != nil {
 		return err
 	}
 
+	if w.limitHit && w.mode == config.WatchBackendAuto {
+		if _, native := w.backend.(*notifyBackend); native {
+			log.Printf("Watching %s by polling every %s instead", w.root, w.pollInterval)
+			_ = w.backend.Close()
+			w.backend = newPollBackend(w.pollInterval)
+			w.watchedDirs = make(map[string]bool)
+			if err := w.addRecursive(w.root); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Start event processing
 	go w.processEvents(ctx)
 
 	return nil
 }
 
+// SetJournal records every event in j before it is delivered on Events.
+// The consumer marks events done in j once processed. It must be called
+// before Start.
+func (w *Watcher) SetJournal(j *Journal) {
+	w.journal = j
+}
+
 func (w *Watcher) Events() <-chan FileEvent {
 	return w.events
 }
 
 func (w *Watcher) Close() error {
 	close(w.done)
-	return w.watcher.Close()
+	if w.backend == nil {
+		return nil
+	}
+	return w.backend.Close()
+}
+
+// symlinkedDir is a symlink to a directory found while adding directories.
+// Links are followed after the regular tree so real paths take precedence,
+// as in the scanner.
+type symlinkedDir struct {
+	relPath string
+	target  string // resolved target path
 }
 
+// addRecursive watches root and its subdirectories, following symlinked
+// directories according to the follow policy. A real directory is watched
+// once, under its own path or that of the first link reaching it.
 func (w *Watcher) addRecursive(root string) error {
-	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	relRoot, err := filepath.Rel(w.root, root)
+	if err != nil {
+		return nil
+	}
+	realRoot := root
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		realRoot = resolved
+	}
+	rootReal := w.root
+	if resolved, err := filepath.EvalSymlinks(w.root); err == nil {
+		rootReal = resolved
+	}
+
+	var pending []symlinkedDir
+	if err := w.addTree(root, realRoot, relRoot, &pending); err != nil {
+		return err
+	}
+	for len(pending) > 0 {
+		link := pending[0]
+		pending = pending[1:]
+		if w.watchedDirs[fileutil.PathKey(link.target)] {
+			continue // Already watched under its real path or another link
+		}
+		if w.followSymlinks == indexer.FollowSymlinksSafe && !fileutil.IsWithinDir(rootReal, link.target) {
+			continue
+		}
+		if err := w.addTree(link.target, link.target, link.relPath, &pending); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTree watches dir, whose real path is realDir and whose path relative
+// to the project root is relDir, and its subdirectories under the project
+// root's spelling of their path. Symlinked directories are appended to
+// pending.
+func (w *Watcher) addTree(dir, realDir, relDir string, pending *[]symlinkedDir) error {
+	cycles := fileutil.NewDirCycleGuard()
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip inaccessible paths
 		}
 
-		relPath, err := filepath.Rel(w.root, path)
+		rel, err := filepath.Rel(dir, path)
 		if err != nil {
 			return nil
 		}
+		relPath := filepath.Join(relDir, rel)
+		watchPath := filepath.Join(w.root, relPath)
 
 		// Handle directories: use ShouldSkipDir to respect .grepaiignore negations
 		if info.IsDir() {
 			if w.ignore.ShouldSkipDir(relPath) {
 				return filepath.SkipDir
 			}
+			if cycles.IsCycle(path) {
+				log.Printf("Skipping directory cycle at %s", path)
+				return filepath.SkipDir
+			}
+			w.watchedDirs[fileutil.PathKey(filepath.Join(realDir, rel))] = true
 			// Directory is not skipped; watch it if not individually ignored
 			if !w.ignore.ShouldIgnore(relPath) {
-				if err := w.watcher.Add(path); err != nil {
-					log.Printf("Failed to watch %s: %v", path, err)
+				if err := w.backend.Add(watchPath); err != nil {
+					if errors.Is(err, errWatchLimit) {
+						// Further directories would fail too
+						if !w.limitHit {
+							w.limitHit = true
+							log.Printf("Cannot watch %s: %s", watchPath, watchLimitHint(w.maxWatches))
+						}
+						return filepath.SkipAll
+					}
+					log.Printf("Failed to watch %s: %v", watchPath, err)
 				}
 			}
 			return nil
 		}
 
-		// Skip ignored files
-		if w.ignore.ShouldIgnore(relPath) {
-			return nil
+		if info.Mode()&os.ModeSymlink != 0 && w.followSymlinks != indexer.FollowSymlinksNone {
+			if target, ok := fileutil.SymlinkedDirTarget(path); ok && !w.ignore.ShouldSkipDir(relPath) {
+				*pending = append(*pending, symlinkedDir{relPath: relPath, target: target})
+			}
 		}
-
 		return nil
 	})
 }
@@ -120,12 +260,12 @@ func (w *Watcher) processEvents(ctx context.Context) {
 			return
 		case <-w.done:
 			return
-		case event, ok := <-w.watcher.Events:
+		case event, ok := <-w.backend.Events():
 			if !ok {
 				return
 			}
 			w.handleEvent(event)
-		case err, ok := <-w.watcher.Errors:
+		case err, ok := <-w.backend.Errors():
 			if !ok {
 				return
 			}
@@ -150,7 +290,7 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 
 	// Check if it's a supported file
 	ext := strings.ToLower(filepath.Ext(event.Name))
-	if !indexer.SupportedExtensions[ext] {
+	if !indexer.SupportedExtensions[ext] && !indexer.HandlerExtensions[ext] {
 		// Check if it's a directory (for watching new directories)
 		info, err := os.Stat(event.Name)
 		if err != nil || !info.IsDir() {
@@ -216,6 +356,9 @@ func (w *Watcher) flush() {
 	w.pendingMu.Unlock()
 
 	for _, event := range events {
+		if err := w.journal.Record(event); err != nil {
+			log.Printf("Warning: failed to journal event for %s: %v", event.Path, err)
+		}
 		select {
 		case w.events <- event:
 		default:
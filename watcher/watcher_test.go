@@ -0,0 +1,90 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/yoanbernabeu/grepai/indexer"
+)
+
+// recordingBackend records the directories added to it.
+type recordingBackend struct {
+	dirs []string
+}
+
+func (b *recordingBackend) Add(dir string) error {
+	b.dirs = append(b.dirs, dir)
+	return nil
+}
+
+func (b *recordingBackend) Events() <-chan fsnotify.Event { return nil }
+func (b *recordingBackend) Errors() <-chan error          { return nil }
+func (b *recordingBackend) Close() error                  { return nil }
+
+func TestWatcher_AddRecursiveFollowsSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	for _, dir := range []string{filepath.Join(root, "lib", "sub"), filepath.Join(outside, "pkg")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+	}
+	links := map[string]string{
+		filepath.Join(root, "linked"):   filepath.Join(root, "lib"),
+		filepath.Join(root, "external"): outside,
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+	ignore, err := indexer.NewIgnoreMatcher(root, nil, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{indexer.FollowSymlinksNone, []string{".", "lib", "lib/sub"}},
+		{indexer.FollowSymlinksSafe, []string{".", "lib", "lib/sub"}},
+		{indexer.FollowSymlinksAll, []string{".", "external", "external/pkg", "lib", "lib/sub"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			w, err := NewWatcher(root, ignore, 100)
+			if err != nil {
+				t.Fatalf("NewWatcher failed: %v", err)
+			}
+			w.SetFollowSymlinks(tt.policy)
+			b := &recordingBackend{}
+			w.backend = b
+			w.watchedDirs = make(map[string]bool)
+			if err := w.addRecursive(root); err != nil {
+				t.Fatalf("addRecursive failed: %v", err)
+			}
+
+			var got []string
+			for _, dir := range b.dirs {
+				rel, err := filepath.Rel(root, dir)
+				if err != nil {
+					t.Fatalf("unexpected watched directory %s", dir)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("watched %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("watched %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}